@@ -3,18 +3,20 @@ package app
 import (
 	"context"
 	"crypto/subtle"
-	"database/sql"
 	"encoding/base64"
 	"errors"
 	"fmt"
 	"net"
 	"net/http"
+	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -22,20 +24,29 @@ import (
 	"constructor-script-backend/internal/authorization"
 	"constructor-script-backend/internal/background"
 	"constructor-script-backend/internal/config"
+	"constructor-script-backend/internal/constants"
+	"constructor-script-backend/internal/events"
 	"constructor-script-backend/internal/handlers"
 	"constructor-script-backend/internal/middleware"
 	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/openapi"
 	"constructor-script-backend/internal/plugin"
 	_ "constructor-script-backend/internal/plugin/builtin"
+	"constructor-script-backend/internal/plugin/external"
+	"constructor-script-backend/internal/plugin/migrations"
 	pluginregistry "constructor-script-backend/internal/plugin/registry"
 	pluginruntime "constructor-script-backend/internal/plugin/runtime"
 	"constructor-script-backend/internal/repository"
+	"constructor-script-backend/internal/secrets"
 	"constructor-script-backend/internal/seed"
 	"constructor-script-backend/internal/service"
 	"constructor-script-backend/internal/theme"
+	"constructor-script-backend/internal/version"
 	"constructor-script-backend/pkg/cache"
+	"constructor-script-backend/pkg/geoip"
+	"constructor-script-backend/pkg/lang"
 	"constructor-script-backend/pkg/logger"
-	"constructor-script-backend/pkg/utils"
+	"constructor-script-backend/pkg/media"
 	archivehandlers "constructor-script-backend/plugins/archive/handlers"
 	archiveservice "constructor-script-backend/plugins/archive/service"
 	bloghandlers "constructor-script-backend/plugins/blog/handlers"
@@ -43,11 +54,34 @@ import (
 	blogservice "constructor-script-backend/plugins/blog/service"
 	coursehandlers "constructor-script-backend/plugins/courses/handlers"
 	courseservice "constructor-script-backend/plugins/courses/service"
+	eventhandlers "constructor-script-backend/plugins/events/handlers"
+	eventservice "constructor-script-backend/plugins/events/service"
 	forumhandlers "constructor-script-backend/plugins/forum/handlers"
 	forumservice "constructor-script-backend/plugins/forum/service"
 	languageservice "constructor-script-backend/plugins/language/service"
+	newsletterhandlers "constructor-script-backend/plugins/newsletter/handlers"
+	newsletterservice "constructor-script-backend/plugins/newsletter/service"
+	producthandlers "constructor-script-backend/plugins/products/handlers"
+	productservice "constructor-script-backend/plugins/products/service"
 )
 
+// apiV1ListsDeprecatedAt is when the v1 list routes with page and limit in the
+// body were deprecated in favour of the /api/v2 pagination envelope.
+var apiV1ListsDeprecatedAt = time.Date(2026, time.October, 15, 0, 0, 0, 0, time.UTC)
+
+// sitemapEvents change the URLs listed in the sitemaps.
+var sitemapEvents = []string{
+	events.PostPublished,
+	events.PostUpdated,
+	events.PostDeleted,
+	events.PageCreated,
+	events.PageUpdated,
+	events.PageDeleted,
+	events.ForumQuestionCreated,
+	events.PluginActivated,
+	events.PluginDeactivated,
+}
+
 type Options struct {
 	ThemesDir    string
 	DefaultTheme string
@@ -58,9 +92,10 @@ type Application struct {
 	cfg     *config.Config
 	options Options
 
-	db        *gorm.DB
-	cache     *cache.Cache
-	scheduler *background.Scheduler
+	db          *gorm.DB
+	slowQueries *logger.SlowQueryLog
+	cache       *cache.Cache
+	scheduler   *background.Scheduler
 
 	repositories   repositoryContainer
 	services       serviceContainer
@@ -70,10 +105,14 @@ type Application struct {
 	themeManager     *theme.Manager
 	pluginManager    *plugin.Manager
 	pluginRuntime    *pluginruntime.Runtime
+	externalPlugins  *external.Host
+	events           *events.Bus
 	rateLimitManager *middleware.RateLimitManager
 	templateHandler  *handlers.TemplateHandler
 	router           *gin.Engine
 	server           *http.Server
+	redirectServer   *http.Server
+	secrets          *secrets.Resolver
 }
 
 type repositoryContainer struct {
@@ -94,6 +133,7 @@ type repositoryContainer struct {
 	CourseTopic         repository.CourseTopicRepository
 	CoursePackage       repository.CoursePackageRepository
 	CoursePackageAccess repository.CoursePackageAccessRepository
+	CourseAnnouncement  repository.CourseAnnouncementRepository
 	CourseTest          repository.CourseTestRepository
 	ForumCategory       repository.ForumCategoryRepository
 	ForumQuestion       repository.ForumQuestionRepository
@@ -102,6 +142,32 @@ type repositoryContainer struct {
 	ArchiveDirectory    repository.ArchiveDirectoryRepository
 	ArchiveFile         repository.ArchiveFileRepository
 	ForumAnswerVote     repository.ForumAnswerVoteRepository
+	ThemeTemplate       repository.ThemeTemplateRepository
+	Webhook             repository.WebhookRepository
+	EmailDelivery       repository.EmailDeliveryRepository
+	EmailSuppression    repository.EmailSuppressionRepository
+	BlockRule           repository.BlockRuleRepository
+	DataExport          repository.DataExportRepository
+	AccountDeletion     repository.AccountDeletionRepository
+	UserIdentity        repository.UserIdentityRepository
+	TwoFactor           repository.TwoFactorRepository
+	Consent             repository.ConsentRepository
+	Revision            repository.RevisionRepository
+	DigestSubscription  repository.DigestSubscriptionRepository
+	Notification        repository.NotificationRepository
+	NotificationPrefs   repository.NotificationPreferenceRepository
+	DeliveryToken       repository.DeliveryTokenRepository
+	APIUsage            repository.APIUsageRepository
+	AuditLog            repository.AuditLogRepository
+	StatusIncident      repository.StatusIncidentRepository
+	Announcement        repository.AnnouncementRepository
+	SocialShare         repository.SocialShareRepository
+	Newsletter          repository.NewsletterRepository
+	Event               repository.EventRepository
+	Product             repository.ProductRepository
+	Media               repository.MediaRepository
+	RemoteUpload        repository.RemoteUploadRepository
+	SlugRedirect        repository.SlugRedirectRepository
 }
 
 type serviceContainer struct {
@@ -112,6 +178,10 @@ type serviceContainer struct {
 	Comment          *blogservice.CommentService
 	Search           *blogservice.SearchService
 	Upload           *service.UploadService
+	ImageTransform   *service.ImageTransformService
+	Accessibility    *service.AccessibilityService
+	Embed            *service.EmbedService
+	UploadGC         *service.UploadGarbageCollector
 	Backup           *service.BackupService
 	Page             *service.PageService
 	Setup            *service.SetupService
@@ -121,8 +191,32 @@ type serviceContainer struct {
 	Menu             *service.MenuService
 	Theme            *service.ThemeService
 	Advertising      *service.AdvertisingService
+	RateLimit        *service.RateLimitService
+	BlockRule        *service.BlockRuleService
+	APIQuota         *service.APIQuotaService
+	Headless         *service.HeadlessService
+	Alert            *service.AlertService
+	CSP              *service.ContentSecurityPolicyService
+	Digest           *service.DigestService
+	Notification     *service.NotificationService
+	AccountData      *service.AccountDataService
+	Consent          *service.ConsentService
+	Revision         *service.RevisionService
+	Sanitization     *service.SanitizationService
+	Bulk             *service.BulkService
+	SettingsExport   *service.SettingsExportService
+	AuditLog         *service.AuditLogService
+	Stats            *service.StatsService
+	LiveUpdate       *service.LiveUpdateService
+	Status           *service.StatusService
+	Announcement     *service.AnnouncementService
 	Plugin           *service.PluginService
 	Font             *service.FontService
+	Webhook          *service.WebhookService
+	DeliveryToken    *service.DeliveryTokenService
+	SocialShare      *service.SocialShareService
+	Translation      *service.MachineTranslationService
+	Payment          *service.PaymentService
 	CourseVideo      *courseservice.VideoService
 	CourseContent    *courseservice.ContentService
 	CourseTopic      *courseservice.TopicService
@@ -134,6 +228,12 @@ type serviceContainer struct {
 	ArchiveDirectory *archiveservice.DirectoryService
 	ArchiveFile      *archiveservice.FileService
 	ForumAnswer      *forumservice.AnswerService
+
+	NewsletterSubscriber *newsletterservice.SubscriberService
+	NewsletterCampaign   *newsletterservice.CampaignService
+	Event                *eventservice.EventService
+	Product              *productservice.ProductService
+	ProductOrder         *productservice.OrderService
 }
 
 type handlerContainer struct {
@@ -143,6 +243,10 @@ type handlerContainer struct {
 	Comment          *bloghandlers.CommentHandler
 	Search           *bloghandlers.SearchHandler
 	Upload           *handlers.UploadHandler
+	Image            *handlers.ImageHandler
+	Accessibility    *handlers.AccessibilityHandler
+	Embed            *handlers.EmbedHandler
+	UploadGC         *handlers.UploadGCHandler
 	Backup           *handlers.BackupHandler
 	Page             *handlers.PageHandler
 	PageBuilder      *handlers.PageBuilderHandler
@@ -153,8 +257,35 @@ type handlerContainer struct {
 	SEO              *handlers.SEOHandler
 	Theme            *handlers.ThemeHandler
 	Advertising      *handlers.AdvertisingHandler
+	RateLimit        *handlers.RateLimitHandler
+	BlockRule        *handlers.BlockRuleHandler
+	APIQuota         *handlers.APIQuotaHandler
+	Manifest         *handlers.ManifestHandler
+	Headless         *handlers.HeadlessHandler
+	Alert            *handlers.AlertHandler
+	CSP              *handlers.ContentSecurityPolicyHandler
+	Digest           *handlers.DigestHandler
+	Notification     *handlers.NotificationHandler
+	AccountData      *handlers.AccountDataHandler
+	Consent          *handlers.ConsentHandler
+	Revision         *handlers.RevisionHandler
+	Sanitization     *handlers.SanitizationHandler
+	Bulk             *handlers.BulkHandler
+	SettingsExport   *handlers.SettingsExportHandler
+	AuditLog         *handlers.AuditLogHandler
+	Status           *handlers.StatusHandler
+	Announcement     *handlers.AnnouncementHandler
+	LiveUpdate       *handlers.LiveUpdateHandler
 	Plugin           *handlers.PluginHandler
 	Font             *handlers.FontHandler
+	Webhook          *handlers.WebhookHandler
+	EmailDelivery    *handlers.EmailDeliveryHandler
+	EmailFeedback    *handlers.EmailFeedbackHandler
+	DeliveryToken    *handlers.DeliveryTokenHandler
+	SocialShare      *handlers.SocialShareHandler
+	Payment          *handlers.PaymentHandler
+	Scheduler        *handlers.SchedulerHandler
+	Diagnostics      *handlers.DiagnosticsHandler
 	CourseVideo      *coursehandlers.VideoHandler
 	CourseContent    *coursehandlers.ContentHandler
 	CourseTopic      *coursehandlers.TopicHandler
@@ -168,6 +299,14 @@ type handlerContainer struct {
 	ArchiveFile      *archivehandlers.FileHandler
 	ArchivePublic    *archivehandlers.PublicHandler
 	ForumAnswer      *forumhandlers.AnswerHandler
+
+	NewsletterSubscriber *newsletterhandlers.SubscriberHandler
+	NewsletterCampaign   *newsletterhandlers.CampaignHandler
+	NewsletterPublic     *newsletterhandlers.PublicHandler
+	Event                *eventhandlers.EventHandler
+	EventPublic          *eventhandlers.PublicHandler
+	Product              *producthandlers.ProductHandler
+	ProductPublic        *producthandlers.PublicHandler
 }
 
 func New(cfg *config.Config, opts Options) (*Application, error) {
@@ -198,6 +337,10 @@ func New(cfg *config.Config, opts Options) (*Application, error) {
 		options: opts,
 	}
 
+	if err := app.initSecrets(); err != nil {
+		return nil, err
+	}
+
 	if err := app.initDatabase(); err != nil {
 		return nil, err
 	}
@@ -215,6 +358,9 @@ func New(cfg *config.Config, opts Options) (*Application, error) {
 
 	// Initialize rate limit manager with application context
 	app.rateLimitManager = middleware.NewRateLimitManager(context.Background())
+	if app.cache.Shared() {
+		app.rateLimitManager.SetStore(app.cache)
+	}
 
 	app.scheduler = background.NewScheduler(background.SchedulerConfig{})
 	app.scheduler.Start(context.Background())
@@ -247,6 +393,7 @@ func New(cfg *config.Config, opts Options) (*Application, error) {
 	}
 
 	app.pluginRuntime = pluginruntime.New()
+	app.events = events.NewBus()
 
 	app.initServices()
 
@@ -284,6 +431,7 @@ func New(cfg *config.Config, opts Options) (*Application, error) {
 	if err := app.initRouter(); err != nil {
 		return nil, err
 	}
+	app.initCachePrewarm()
 
 	// Configure server timeouts based on config
 	// Default values allow for large file uploads (up to 2GB)
@@ -311,16 +459,25 @@ func New(cfg *config.Config, opts Options) (*Application, error) {
 		MaxHeaderBytes: 1 << 20, // 1MB for headers
 	}
 
+	if err := app.initTLS(); err != nil {
+		return nil, err
+	}
+
 	cleanupNeeded = false
 	return app, nil
 }
 
 func (a *Application) Run() error {
 	logger.Info("Server starting", map[string]interface{}{
-		"port":        a.cfg.Port,
+		"port":        strings.TrimPrefix(a.server.Addr, ":"),
+		"tls":         a.server.TLSConfig != nil,
 		"environment": a.cfg.Environment,
 	})
 
+	if a.redirectServer != nil {
+		go a.runRedirectServer()
+		return a.server.ListenAndServeTLS("", "")
+	}
 	return a.server.ListenAndServe()
 }
 
@@ -330,6 +487,9 @@ func (a *Application) Shutdown(ctx context.Context) error {
 			return err
 		}
 	}
+	if err := a.shutdownRedirectServer(ctx); err != nil {
+		logger.Error(err, "Failed to stop HTTP redirect server", nil)
+	}
 
 	if a.rateLimitManager != nil {
 		if err := a.rateLimitManager.Shutdown(); err != nil {
@@ -347,12 +507,19 @@ func (a *Application) Shutdown(ctx context.Context) error {
 		a.services.Backup.ShutdownAutoBackups()
 	}
 
+	if a.services.APIQuota != nil {
+		if err := a.services.APIQuota.FlushUsage(ctx); err != nil {
+			logger.Error(err, "Failed to flush API usage", nil)
+		}
+	}
+
 	// Clean up plugin runtime to free memory
 	if a.pluginRuntime != nil {
 		if err := a.pluginRuntime.Clear(); err != nil {
 			logger.Error(err, "Failed to clear plugin runtime", nil)
 		}
 	}
+	a.externalPlugins.Shutdown()
 
 	if a.cache != nil {
 		if err := a.cache.Close(); err != nil {
@@ -376,21 +543,43 @@ func (a *Application) Router() *gin.Engine {
 func (a *Application) initDatabase() error {
 	logger.Info("Connecting to database", nil)
 
+	slowThreshold := time.Duration(a.cfg.DBSlowQueryThreshold) * time.Millisecond
+	gormLoggerOptions := []logger.GormLoggerOption{logger.WithSlowThreshold(slowThreshold)}
+	if slowThreshold > 0 {
+		a.slowQueries = logger.NewSlowQueryLog(0)
+		gormLoggerOptions = append(gormLoggerOptions, logger.WithSlowQueryLog(a.slowQueries))
+	}
+
 	db, err := gorm.Open(postgres.Open(a.cfg.DatabaseURL), &gorm.Config{
-		Logger: logger.NewGormLogger(),
+		Logger: logger.NewGormLogger(gormLoggerOptions...),
 	})
 	if err != nil {
 		return fmt.Errorf("failed to connect to database: %w", err)
 	}
+	if err := db.Use(middleware.QueryCounter{}); err != nil {
+		return fmt.Errorf("failed to install query counter: %w", err)
+	}
+	statementTimeout := time.Duration(a.cfg.DBStatementTimeout) * time.Second
+	if err := db.Use(middleware.StatementTimeout{Timeout: statementTimeout}); err != nil {
+		return fmt.Errorf("failed to install statement timeout: %w", err)
+	}
 
 	sqlDB, err := db.DB()
 	if err != nil {
 		return fmt.Errorf("failed to get database instance: %w", err)
 	}
 
-	sqlDB.SetMaxIdleConns(10)
-	sqlDB.SetMaxOpenConns(100)
-	sqlDB.SetConnMaxLifetime(time.Hour)
+	sqlDB.SetMaxOpenConns(a.cfg.DBMaxOpenConns)
+	sqlDB.SetMaxIdleConns(a.cfg.DBMaxIdleConns)
+	sqlDB.SetConnMaxLifetime(time.Duration(a.cfg.DBConnMaxLifetime) * time.Second)
+	sqlDB.SetConnMaxIdleTime(time.Duration(a.cfg.DBConnMaxIdleTime) * time.Second)
+
+	if err := prometheus.Register(collectors.NewDBStatsCollector(sqlDB, a.cfg.DBName)); err != nil {
+		var registered prometheus.AlreadyRegisteredError
+		if !errors.As(err, &registered) {
+			logger.Error(err, "Failed to register database pool metrics", nil)
+		}
+	}
 
 	a.db = db
 	return nil
@@ -405,53 +594,14 @@ func (a *Application) runMigrations() error {
 
 	migrator := a.db.Migrator()
 
-	if migrator.HasTable(&models.ForumCategory{}) {
-		indexes := []string{"idx_forum_categories_name", "idx_forum_categories_slug"}
-		for _, indexName := range indexes {
-			if migrator.HasIndex(&models.ForumCategory{}, indexName) {
-				if err := migrator.DropIndex(&models.ForumCategory{}, indexName); err != nil {
-					logger.Warn("Failed to drop legacy forum category index", map[string]interface{}{
-						"index": indexName,
-						"error": err.Error(),
-					})
-				}
-			}
-		}
-	}
-
-	// Fix comment foreign key constraints to cascade delete
-	if migrator.HasTable(&models.Comment{}) {
-		// Drop old constraints without cascade (tolerate absence in existing DBs)
-		legacyConstraints := []string{
-			"fk_users_comments",
-			"fk_posts_comments",
-			"fk_comments_parent_id",
-		}
-		for _, constraint := range legacyConstraints {
-			query := fmt.Sprintf(`ALTER TABLE "comments" DROP CONSTRAINT IF EXISTS "%s"`, constraint)
-			if err := a.db.Exec(query).Error; err != nil {
-				logger.Warn("Failed to drop legacy comment constraint", map[string]interface{}{
-					"constraint": constraint,
-					"error":      err.Error(),
-				})
-				continue
-			}
-			logger.Info("Dropped legacy comment foreign key constraint", map[string]interface{}{
-				"constraint": constraint,
-			})
-		}
+	if err := migrations.EnsureTable(a.db); err != nil {
+		return fmt.Errorf("failed to prepare plugin migrations: %w", err)
 	}
-
-	if migrator.HasTable(&models.CourseTopicStep{}) {
-		if err := a.db.Exec("ALTER TABLE course_topic_steps ADD COLUMN IF NOT EXISTS test_id bigint").Error; err != nil {
-			return fmt.Errorf("failed to ensure course topic step test reference: %w", err)
-		}
-		if err := a.db.Exec("ALTER TABLE course_topic_steps ADD COLUMN IF NOT EXISTS content_id bigint").Error; err != nil {
-			return fmt.Errorf("failed to ensure course topic step content reference: %w", err)
-		}
+	inactivePlugins, err := a.inactivePluginSchemas()
+	if err != nil {
+		return err
 	}
-
-	if err := a.ensureCourseSlugs(migrator); err != nil {
+	if err := migrations.Run(a.db, migrations.StagePreSchema, inactivePlugins); err != nil {
 		return err
 	}
 
@@ -472,59 +622,49 @@ func (a *Application) runMigrations() error {
 		&models.ArchiveFile{},
 		&models.Tag{},
 		&models.Comment{},
-		&models.ForumCategory{},
-		&models.ForumQuestion{},
-		&models.ForumAnswer{},
-		&models.ForumQuestionVote{},
-		&models.ForumAnswerVote{},
-		&models.CourseVideo{},
-		&models.CourseTopic{},
-		&models.CourseContent{},
-		&models.CoursePackage{},
-		&models.CourseTopicVideo{},
-		&models.CoursePackageTopic{},
-		&models.CoursePackageAccess{},
-		&models.CourseTest{},
-		&models.CourseTestQuestion{},
-		&models.CourseTestQuestionOption{},
-		&models.CourseTopicStep{},
-		&models.CourseTestResult{},
 		&models.Setting{},
+		&models.SlugRedirect{},
+		&models.ThemeTemplateOverride{},
 		&models.SocialLink{},
 		&models.MenuItem{},
 		&models.Plugin{},
 		&models.SetupProgress{},
+		&models.Webhook{},
+		&models.WebhookDelivery{},
+		&models.EmailDelivery{},
+		&models.EmailSuppression{},
+		&models.BlockRule{},
+		&models.DigestSubscription{},
+		&models.Notification{},
+		&models.NotificationPreference{},
+		&models.DataExport{},
+		&models.AccountDeletionRequest{},
+		&models.UserIdentity{},
+		&models.UserTwoFactor{},
+		&models.TwoFactorRecoveryCode{},
+		&models.ConsentRecord{},
+		&models.Revision{},
+		&models.DeliveryToken{},
+		&models.APIUsage{},
+		&models.AuditLog{},
+		&models.BackupRestore{},
+		&models.StatusIncident{},
+		&models.Announcement{},
+		&models.AnnouncementDismissal{},
+		&models.SocialAccount{},
+		&models.SocialShare{},
+		&models.MediaMetadata{},
+		&models.RemoteUpload{},
 	); err != nil {
 		return fmt.Errorf("failed to migrate database: %w", err)
 	}
 
-	if migrator.HasTable(&models.CourseTopicStep{}) {
-		if err := a.db.Exec("CREATE INDEX IF NOT EXISTS idx_course_topic_steps_test_id ON course_topic_steps(test_id)").Error; err != nil {
-			return fmt.Errorf("failed to ensure course topic step test index: %w", err)
-		}
-		if err := a.db.Exec("CREATE INDEX IF NOT EXISTS idx_course_topic_steps_content_id ON course_topic_steps(content_id)").Error; err != nil {
-			return fmt.Errorf("failed to ensure course topic step content index: %w", err)
-		}
-	}
-
-	if err := a.migrateTopicVideosToSteps(); err != nil {
+	if err := migrations.MigrateTables(a.db, inactivePlugins); err != nil {
 		return err
 	}
 
-	if err := a.db.Exec(`
-                UPDATE posts
-                SET publish_at = COALESCE(publish_at, created_at)
-                WHERE publish_at IS NULL AND published = TRUE
-        `).Error; err != nil {
-		return fmt.Errorf("failed to backfill post publish_at: %w", err)
-	}
-
-	if err := a.db.Exec(`
-                UPDATE posts
-                SET published_at = COALESCE(published_at, publish_at, created_at)
-                WHERE published = TRUE
-        `).Error; err != nil {
-		return fmt.Errorf("failed to backfill post published_at: %w", err)
+	if err := migrations.Run(a.db, migrations.StagePostSchema, inactivePlugins); err != nil {
+		return err
 	}
 
 	if err := a.db.Exec(`
@@ -576,155 +716,6 @@ func (a *Application) runMigrations() error {
 	return nil
 }
 
-func (a *Application) ensureCourseSlugs(migrator gorm.Migrator) error {
-	if err := a.ensureCourseTopicSlugs(migrator); err != nil {
-		return err
-	}
-
-	if err := a.ensureCoursePackageSlugs(migrator); err != nil {
-		return err
-	}
-
-	return nil
-}
-
-func (a *Application) ensureCourseTopicSlugs(migrator gorm.Migrator) error {
-	if !migrator.HasTable(&models.CourseTopic{}) {
-		return nil
-	}
-
-	if !migrator.HasColumn(&models.CourseTopic{}, "slug") {
-		if err := a.db.Exec("ALTER TABLE course_topics ADD COLUMN slug text").Error; err != nil {
-			return fmt.Errorf("failed to add course topic slug column: %w", err)
-		}
-	}
-
-	if err := a.ensureSlugsForTable("course_topics", "topic", "idx_course_topics_slug"); err != nil {
-		return fmt.Errorf("failed to ensure course topic slugs: %w", err)
-	}
-
-	return nil
-}
-
-func (a *Application) ensureCoursePackageSlugs(migrator gorm.Migrator) error {
-	if !migrator.HasTable(&models.CoursePackage{}) {
-		return nil
-	}
-
-	if !migrator.HasColumn(&models.CoursePackage{}, "slug") {
-		if err := a.db.Exec("ALTER TABLE course_packages ADD COLUMN slug text").Error; err != nil {
-			return fmt.Errorf("failed to add course package slug column: %w", err)
-		}
-	}
-
-	if err := a.ensureSlugsForTable("course_packages", "package", "idx_course_packages_slug"); err != nil {
-		return fmt.Errorf("failed to ensure course package slugs: %w", err)
-	}
-
-	return nil
-}
-
-func (a *Application) ensureSlugsForTable(tableName, fallbackPrefix, indexName string) error {
-	return a.db.Transaction(func(tx *gorm.DB) error {
-		type slugRow struct {
-			ID        uint
-			Title     string
-			Slug      sql.NullString
-			DeletedAt gorm.DeletedAt
-		}
-
-		var rows []slugRow
-		if err := tx.Table(tableName).Select("id, title, slug, deleted_at").Order("id ASC").Find(&rows).Error; err != nil {
-			return fmt.Errorf("failed to load %s rows for slug backfill: %w", tableName, err)
-		}
-
-		existing := make(map[string]struct{}, len(rows))
-
-		ensureSlug := func(row slugRow, enforceUnique bool) error {
-			base := strings.TrimSpace(strings.ToLower(row.Slug.String))
-			if base == "" {
-				generated := utils.GenerateSlug(row.Title)
-				base = strings.TrimSpace(strings.ToLower(generated))
-			}
-
-			if base == "" {
-				base = fmt.Sprintf("%s-%d", fallbackPrefix, row.ID)
-			}
-
-			candidate := base
-			if enforceUnique {
-				suffix := 0
-				for {
-					var attempt string
-					switch suffix {
-					case 0:
-						attempt = base
-					case 1:
-						attempt = fmt.Sprintf("%s-%d", base, row.ID)
-					default:
-						attempt = fmt.Sprintf("%s-%d-%d", base, row.ID, suffix)
-					}
-
-					if _, exists := existing[attempt]; !exists {
-						candidate = attempt
-						break
-					}
-
-					suffix++
-				}
-			}
-
-			current := strings.TrimSpace(row.Slug.String)
-			currentLower := strings.TrimSpace(strings.ToLower(row.Slug.String))
-			if !row.Slug.Valid || currentLower != candidate || current != candidate || row.Slug.String != current {
-				if err := tx.Exec(fmt.Sprintf("UPDATE %s SET slug = ? WHERE id = ?", tableName), candidate, row.ID).Error; err != nil {
-					return fmt.Errorf("failed to update %s slug for id %d: %w", tableName, row.ID, err)
-				}
-				current = candidate
-			}
-
-			if enforceUnique {
-				existing[current] = struct{}{}
-			}
-
-			return nil
-		}
-
-		for _, row := range rows {
-			if row.DeletedAt.Valid {
-				continue
-			}
-			if err := ensureSlug(row, true); err != nil {
-				return err
-			}
-		}
-
-		for _, row := range rows {
-			if !row.DeletedAt.Valid {
-				continue
-			}
-			if err := ensureSlug(row, false); err != nil {
-				return err
-			}
-		}
-
-		if err := tx.Exec(fmt.Sprintf("ALTER TABLE %s ALTER COLUMN slug SET NOT NULL", tableName)).Error; err != nil {
-			return fmt.Errorf("failed to enforce NOT NULL on %s slug column: %w", tableName, err)
-		}
-
-		if err := tx.Exec(fmt.Sprintf("DROP INDEX IF EXISTS %s", indexName)).Error; err != nil {
-			return fmt.Errorf("failed to drop legacy %s slug index: %w", tableName, err)
-		}
-
-		createStmt := fmt.Sprintf("CREATE UNIQUE INDEX %s ON %s (slug) WHERE deleted_at IS NULL", indexName, tableName)
-		if err := tx.Exec(createStmt).Error; err != nil {
-			return fmt.Errorf("failed to ensure unique index for %s slug column: %w", tableName, err)
-		}
-
-		return nil
-	})
-}
-
 func (a *Application) createIndexes() error {
 	if a.db == nil {
 		return fmt.Errorf("database connection is not initialized")
@@ -757,68 +748,13 @@ func (a *Application) createIndexes() error {
 	return nil
 }
 
-func (a *Application) migrateTopicVideosToSteps() error {
-	if a.db == nil {
-		return fmt.Errorf("database connection is not initialized")
-	}
-
-	migrator := a.db.Migrator()
-	if !migrator.HasTable(&models.CourseTopicVideo{}) {
-		return nil
-	}
-	if !migrator.HasTable(&models.CourseTopicStep{}) {
-		return nil
-	}
-
-	var stepCount int64
-	if err := a.db.Model(&models.CourseTopicStep{}).Count(&stepCount).Error; err != nil {
-		return fmt.Errorf("failed to count course topic steps: %w", err)
-	}
-	if stepCount > 0 {
-		return nil
-	}
-
-	var legacyCount int64
-	if err := a.db.Model(&models.CourseTopicVideo{}).Count(&legacyCount).Error; err != nil {
-		return fmt.Errorf("failed to count legacy topic videos: %w", err)
-	}
-	if legacyCount == 0 {
-		return nil
-	}
-
-	var links []models.CourseTopicVideo
-	if err := a.db.Order("topic_id ASC, position ASC").Find(&links).Error; err != nil {
-		return fmt.Errorf("failed to load legacy topic videos: %w", err)
-	}
-	if len(links) == 0 {
-		return nil
-	}
-
-	if err := a.db.Transaction(func(tx *gorm.DB) error {
-		for _, link := range links {
-			videoID := link.VideoID
-			step := models.CourseTopicStep{
-				CreatedAt: link.CreatedAt,
-				UpdatedAt: link.UpdatedAt,
-				TopicID:   link.TopicID,
-				StepType:  models.CourseTopicStepTypeVideo,
-				Position:  link.Position,
-				VideoID:   &videoID,
-			}
-			if err := tx.Create(&step).Error; err != nil {
-				return err
-			}
-		}
-		return nil
-	}); err != nil {
-		return fmt.Errorf("failed to migrate topic videos to steps: %w", err)
+func (a *Application) initCache() {
+	memory := cache.MemoryOptions{
+		Entries: a.cfg.CacheMemoryEntries,
+		TTL:     time.Duration(a.cfg.CacheMemoryTTL) * time.Second,
 	}
 
-	return nil
-}
-
-func (a *Application) initCache() {
-	if !a.cfg.EnableCache || !a.cfg.EnableRedis {
+	if !a.cfg.EnableCache {
 		disabledCache, err := cache.NewCache("", false)
 		if err != nil {
 			logger.Error(err, "Failed to initialize disabled cache", nil)
@@ -828,10 +764,20 @@ func (a *Application) initCache() {
 		return
 	}
 
-	cacheInstance, err := cache.NewCache(a.cfg.RedisURL, true)
+	if !a.cfg.EnableRedis {
+		memoryCache, err := cache.NewLayeredCache("", false, memory)
+		if err != nil {
+			logger.Error(err, "Failed to initialize in-process cache", nil)
+			return
+		}
+		a.cache = memoryCache
+		return
+	}
+
+	cacheInstance, err := cache.NewLayeredCache(a.cfg.RedisURL, true, memory)
 	if err != nil {
-		logger.Error(err, "Failed to initialize Redis cache, caching disabled", map[string]interface{}{"redis_url": a.cfg.RedisURL})
-		fallbackCache, fallbackErr := cache.NewCache("", false)
+		logger.Error(err, "Failed to initialize Redis cache, using the in-process cache only", map[string]interface{}{"redis_url": a.cfg.RedisURL})
+		fallbackCache, fallbackErr := cache.NewLayeredCache("", false, memory)
 		if fallbackErr != nil {
 			logger.Error(fallbackErr, "Failed to initialize fallback cache", nil)
 			return
@@ -844,6 +790,7 @@ func (a *Application) initCache() {
 }
 
 func (a *Application) initRepositories() {
+	settings := secrets.NewSettingRepository(repository.NewSettingRepository(a.db), a.secrets, service.SecretSettingKeys())
 	a.repositories = repositoryContainer{
 		User:                repository.NewUserRepository(a.db),
 		PasswordResetToken:  repository.NewPasswordResetTokenRepository(a.db),
@@ -853,7 +800,7 @@ func (a *Application) initRepositories() {
 		Comment:             repository.NewCommentRepository(a.db),
 		Search:              repository.NewSearchRepository(a.db),
 		Page:                repository.NewPageRepository(a.db),
-		Setting:             repository.NewSettingRepository(a.db),
+		Setting:             settings,
 		SocialLink:          repository.NewSocialLinkRepository(a.db),
 		Menu:                repository.NewMenuRepository(a.db),
 		Plugin:              repository.NewPluginRepository(a.db),
@@ -862,6 +809,7 @@ func (a *Application) initRepositories() {
 		CourseTopic:         repository.NewCourseTopicRepository(a.db),
 		CoursePackage:       repository.NewCoursePackageRepository(a.db),
 		CoursePackageAccess: repository.NewCoursePackageAccessRepository(a.db),
+		CourseAnnouncement:  repository.NewCourseAnnouncementRepository(a.db),
 		CourseTest:          repository.NewCourseTestRepository(a.db),
 		ForumCategory:       repository.NewForumCategoryRepository(a.db),
 		ForumQuestion:       repository.NewForumQuestionRepository(a.db),
@@ -870,7 +818,34 @@ func (a *Application) initRepositories() {
 		ForumAnswer:         repository.NewForumAnswerRepository(a.db),
 		ForumQuestionVote:   repository.NewForumQuestionVoteRepository(a.db),
 		ForumAnswerVote:     repository.NewForumAnswerVoteRepository(a.db),
-	}
+		ThemeTemplate:       repository.NewThemeTemplateRepository(a.db),
+		Webhook:             repository.NewWebhookRepository(a.db),
+		EmailDelivery:       repository.NewEmailDeliveryRepository(a.db),
+		EmailSuppression:    repository.NewEmailSuppressionRepository(a.db),
+		BlockRule:           repository.NewBlockRuleRepository(a.db),
+		DataExport:          repository.NewDataExportRepository(a.db),
+		AccountDeletion:     repository.NewAccountDeletionRepository(a.db),
+		UserIdentity:        repository.NewUserIdentityRepository(a.db),
+		TwoFactor:           repository.NewTwoFactorRepository(a.db),
+		Consent:             repository.NewConsentRepository(a.db),
+		Revision:            repository.NewRevisionRepository(a.db),
+		DigestSubscription:  repository.NewDigestSubscriptionRepository(a.db),
+		Notification:        repository.NewNotificationRepository(a.db),
+		NotificationPrefs:   repository.NewNotificationPreferenceRepository(a.db),
+		DeliveryToken:       repository.NewDeliveryTokenRepository(a.db),
+		APIUsage:            repository.NewAPIUsageRepository(a.db),
+		AuditLog:            repository.NewAuditLogRepository(a.db),
+		StatusIncident:      repository.NewStatusIncidentRepository(a.db),
+		Announcement:        repository.NewAnnouncementRepository(a.db),
+		SocialShare:         repository.NewSocialShareRepository(a.db),
+		Newsletter:          repository.NewNewsletterRepository(a.db),
+		Event:               repository.NewEventRepository(a.db),
+		Product:             repository.NewProductRepository(a.db),
+		Media:               repository.NewMediaRepository(a.db),
+		RemoteUpload:        repository.NewRemoteUploadRepository(a.db),
+		SlugRedirect:        repository.NewSlugRedirectRepository(a.db),
+	}
+	settings.Preload()
 }
 
 func (a *Application) initThemeManager() error {
@@ -912,6 +887,12 @@ func (a *Application) initThemeManager() error {
 		}
 	}
 
+	if a.cfg.EnableAssetPipeline {
+		if err := manager.EnableAssetPipeline(a.cfg.EnableAssetMinification); err != nil {
+			logger.Error(err, "Failed to build theme asset pipeline; falling back to unversioned assets", nil)
+		}
+	}
+
 	a.themeManager = manager
 	return nil
 }
@@ -926,8 +907,26 @@ func (a *Application) initPluginManager() error {
 	return nil
 }
 
+// newStatusService sets up the public status page with the components it reports.
+func (a *Application) newStatusService() *service.StatusService {
+	status := service.NewStatusService(a.repositories.StatusIncident)
+	status.AddComponent("Website", func(ctx context.Context) error { return nil })
+	status.AddComponent("Database", func(ctx context.Context) error {
+		sqlDB, err := a.db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	})
+	if a.cache.UsesRedis() {
+		status.AddComponent("Cache", a.cache.Ping)
+	}
+	return status
+}
+
 func (a *Application) initServices() {
 	uploadService := service.NewUploadService(a.cfg.UploadDir)
+	uploadService.SetMetadataRepository(a.repositories.Media)
 	var languageService *languageservice.LanguageService
 	setupService := service.NewSetupService(a.repositories.User, a.repositories.Setting, uploadService, languageService)
 
@@ -935,6 +934,7 @@ func (a *Application) initServices() {
 	if setupService != nil && a.db != nil {
 		setupService.SetDB(a.db)
 	}
+	setupService.SetThemeManager(a.themeManager)
 
 	subtitleDefaults := models.SubtitleSettings{}
 	if a.cfg != nil {
@@ -962,6 +962,23 @@ func (a *Application) initServices() {
 
 	service.ConfigureUploadSubtitles(uploadService, subtitleSettings)
 
+	uploadService.SetScheduler(a.scheduler)
+	if a.cfg != nil {
+		uploadService.SetImageSanitization(a.cfg.SanitizeImageUploads)
+		uploadService.SetVideoProcessing(a.cfg.VideoPosterFrames)
+		formats := make([]media.ImageFormat, 0, len(a.cfg.ImageVariantFormats))
+		for _, format := range a.cfg.ImageVariantFormats {
+			formats = append(formats, media.ImageFormat(format))
+		}
+		uploadService.ConfigureImageVariants(service.ImageVariantConfig{
+			Widths:  a.cfg.ImageVariantWidths,
+			Formats: formats,
+			Quality: a.cfg.ImageVariantQuality,
+		})
+		a.configureDirectUploads(uploadService)
+		a.configureCDN(uploadService)
+	}
+
 	backupOptions := service.BackupOptions{UploadDir: a.cfg.UploadDir}
 
 	if key := strings.TrimSpace(a.cfg.BackupEncryptionKey); key != "" {
@@ -975,33 +992,22 @@ func (a *Application) initServices() {
 		}
 	}
 
-	if a.cfg.BackupS3Enabled {
-		endpoint := strings.TrimSpace(a.cfg.BackupS3Endpoint)
-		accessKey := strings.TrimSpace(a.cfg.BackupS3AccessKey)
-		secretKey := strings.TrimSpace(a.cfg.BackupS3SecretKey)
-		bucket := strings.TrimSpace(a.cfg.BackupS3Bucket)
+	a.configureBackupStorage(&backupOptions)
 
-		if endpoint == "" || accessKey == "" || secretKey == "" || bucket == "" {
-			logger.Warn("Incomplete S3 backup configuration; remote uploads disabled", map[string]interface{}{
-				"endpoint_configured": endpoint != "",
-				"bucket_configured":   bucket != "",
-				"access_configured":   accessKey != "" && secretKey != "",
-			})
-		} else {
-			backupOptions.S3 = &service.BackupS3Config{
-				Endpoint:  endpoint,
-				AccessKey: accessKey,
-				SecretKey: secretKey,
-				Bucket:    bucket,
-				Region:    strings.TrimSpace(a.cfg.BackupS3Region),
-				UseSSL:    a.cfg.BackupS3UseSSL,
-				Prefix:    strings.Trim(a.cfg.BackupS3Prefix, "/"),
-			}
+	if a.cfg.BackupPGDump {
+		backupOptions.Dump = &service.BackupDumpConfig{
+			Command:     a.cfg.BackupPGDumpPath,
+			DatabaseURL: a.cfg.DatabaseURL,
 		}
 	}
 
 	backupService := service.NewBackupService(a.db, a.repositories.Setting, backupOptions)
-	emailService := service.NewEmailService(a.cfg, a.repositories.Setting)
+	emailService := service.NewEmailService(a.cfg, a.repositories.Setting, a.themeManager)
+	emailService.SetQueue(a.repositories.EmailDelivery, a.scheduler)
+	emailService.SetSuppressionList(a.repositories.EmailSuppression)
+	emailService.ResumePending()
+	backupService.SetEventBus(a.events)
+	backupService.SetAlertEmail(emailService, a.cfg.BackupAlertEmail)
 
 	authService := service.NewAuthService(
 		a.repositories.User,
@@ -1012,24 +1018,101 @@ func (a *Application) initServices() {
 		a.cfg.JWTSecret,
 		a.cfg,
 	)
+	authService.SetEventBus(a.events)
+	authService.SetIdentityRepository(a.repositories.UserIdentity)
+	authService.SetTwoFactorRepository(a.repositories.TwoFactor)
 	pageService := service.NewPageService(a.repositories.Page, a.cache, a.themeManager)
+	pageService.SetEventBus(a.events)
+	pageService.SetSlugRedirects(a.repositories.SlugRedirect)
+	revisionService := service.NewRevisionService(a.repositories.Revision, a.repositories.Setting)
+	pageService.SetRevisions(revisionService)
 	homepageService := service.NewHomepageService(a.repositories.Setting, a.repositories.Page)
 	socialLinkService := service.NewSocialLinkService(a.repositories.SocialLink)
 	menuService := service.NewMenuService(a.repositories.Menu)
 	advertisingService := service.NewAdvertisingService(a.repositories.Setting)
+	rateLimitService := service.NewRateLimitService(a.repositories.Setting)
+	blockRuleService := service.NewBlockRuleService(a.repositories.BlockRule)
+	blockRuleService.SetAutoBan(
+		a.cfg.RateLimitBanThreshold,
+		time.Duration(a.cfg.RateLimitBanWindow)*time.Second,
+		time.Duration(a.cfg.RateLimitBanMinutes)*time.Minute,
+	)
+	if a.cfg.GeoIPDatabase != "" {
+		if reader, err := geoip.Open(a.cfg.GeoIPDatabase); err != nil {
+			logger.Error(err, "Failed to open GeoIP database", map[string]interface{}{"path": a.cfg.GeoIPDatabase})
+		} else {
+			blockRuleService.SetGeoIP(reader)
+		}
+	}
+	if a.rateLimitManager != nil {
+		a.rateLimitManager.SetViolationHandler(blockRuleService.RecordRateLimitViolation)
+	}
 	fontService := service.NewFontService(a.repositories.Setting)
+	webhookService := service.NewWebhookService(a.repositories.Webhook, a.scheduler)
+	webhookService.Subscribe(a.events)
+	webhookService.ResumePending()
+	headlessService := service.NewHeadlessService(a.repositories.Setting, a.scheduler)
+	headlessService.Subscribe(a.events)
+	alertService := service.NewAlertService(a.repositories.Setting, middleware.RequestTotals)
+	alertService.SetEmailService(emailService)
+	alertService.Subscribe(a.events)
+	socialShareService := service.NewSocialShareService(a.repositories.SocialShare, a.repositories.Post, a.scheduler, func() string {
+		return setupService.SiteURL(a.cfg.SiteURL)
+	})
+	socialShareService.Subscribe(a.events)
+	socialShareService.ResumePending()
+	paymentService := service.NewPaymentService(a.cfg, setupService)
+	paymentService.SetEventBus(a.events)
+	liveUpdateService := service.NewLiveUpdateService()
+	liveUpdateService.Subscribe(a.events)
+
+	translator, err := service.NewMachineTranslator(a.cfg.TranslationProvider, a.cfg.TranslationAPIKey, service.MachineTranslatorOptions{
+		Model:    a.cfg.TranslationModel,
+		Endpoint: a.cfg.TranslationEndpoint,
+	})
+	if err != nil {
+		logger.Error(err, "Machine translation is disabled", nil)
+	}
+	machineTranslationService := service.NewMachineTranslationService(a.cfg.TranslationProvider, translator)
 
 	themeService := service.NewThemeService(
 		a.repositories.Setting,
+		a.repositories.ThemeTemplate,
 		a.themeManager,
 		a.options.DefaultTheme,
 	)
 
 	pluginService := service.NewPluginService(
 		a.repositories.Plugin,
+		a.repositories.Setting,
 		a.pluginManager,
 		a.pluginRuntime,
 	)
+	if pluginService != nil {
+		if err := pluginService.SetRemoteSources(a.cfg.PluginMarketplaceURL, a.cfg.PluginSigningPublicKey); err != nil {
+			logger.Error(err, "Invalid plugin signing key; remote plugin installation is disabled", nil)
+		}
+		pluginService.SetEventBus(a.events)
+		pluginService.SetSchemaInstaller(a.installPluginSchema)
+	}
+
+	settingsExportService := service.NewSettingsExportService(a.db, service.SettingsExportServices{
+		Setup:       setupService,
+		Advertising: advertisingService,
+		Fonts:       fontService,
+		SocialLinks: socialLinkService,
+		Menus:       menuService,
+		Backup:      backupService,
+		Plugins:     pluginService,
+	})
+
+	imageTransformService := service.NewImageTransformService(a.cfg.UploadDir, a.cfg.ImageCacheDir, service.ImageTransformConfig{
+		MaxDimension:  a.cfg.ImageTransformMaxDimension,
+		Quality:       a.cfg.ImageVariantQuality,
+		Concurrency:   a.cfg.ImageTransformConcurrency,
+		CacheMaxBytes: int64(a.cfg.ImageCacheMaxMB) << 20,
+		SigningSecret: a.cfg.JWTSecret,
+	})
 
 	a.services = serviceContainer{
 		Auth:           authService,
@@ -1039,6 +1122,14 @@ func (a *Application) initServices() {
 		Comment:        nil,
 		Search:         nil,
 		Upload:         uploadService,
+		ImageTransform: imageTransformService,
+		Accessibility:  service.NewAccessibilityService(uploadService, a.repositories.Post, a.repositories.Page),
+		Embed:          service.NewEmbedService(a.cache),
+		UploadGC: service.NewUploadGarbageCollector(uploadService, a.repositories.Media, service.UploadGCConfig{
+			GracePeriod:   time.Duration(a.cfg.UploadGCGraceDays) * 24 * time.Hour,
+			Mode:          service.UploadGCMode(a.cfg.UploadGCMode),
+			QuarantineDir: a.cfg.UploadQuarantineDir,
+		}),
 		Backup:         backupService,
 		Page:           pageService,
 		Setup:          setupService,
@@ -1048,8 +1139,32 @@ func (a *Application) initServices() {
 		Menu:           menuService,
 		Theme:          themeService,
 		Advertising:    advertisingService,
+		RateLimit:      rateLimitService,
+		BlockRule:      blockRuleService,
+		APIQuota:       service.NewAPIQuotaService(a.repositories.Setting, a.repositories.APIUsage, a.cache),
+		Headless:       headlessService,
+		Alert:          alertService,
+		CSP:            service.NewContentSecurityPolicyService(a.repositories.Setting),
+		Digest:         service.NewDigestService(a.repositories.DigestSubscription, a.repositories.User, emailService),
+		Notification:   service.NewNotificationService(a.repositories.Notification, a.repositories.NotificationPrefs),
+		AccountData:    service.NewAccountDataService(a.repositories.DataExport, a.repositories.AccountDeletion, a.repositories.User, a.repositories.Setting, a.scheduler),
+		Consent:        service.NewConsentService(a.repositories.Consent, a.repositories.Setting),
+		Revision:       revisionService,
+		Sanitization:   service.NewSanitizationService(a.repositories.Setting),
+		Bulk:           service.NewBulkService(a.db, a.events),
+		SettingsExport: settingsExportService,
+		AuditLog:       service.NewAuditLogService(a.repositories.AuditLog, a.repositories.Setting),
+		Stats:          service.NewStatsService(a.db, a.cache, middleware.DailyRequests),
+		LiveUpdate:     liveUpdateService,
+		Status:         a.newStatusService(),
+		Announcement:   service.NewAnnouncementService(a.repositories.Announcement),
 		Plugin:         pluginService,
+		Payment:        paymentService,
 		Font:           fontService,
+		Webhook:        webhookService,
+		DeliveryToken:  service.NewDeliveryTokenService(a.repositories.DeliveryToken),
+		SocialShare:    socialShareService,
+		Translation:    machineTranslationService,
 		CourseVideo:    nil,
 		CourseContent:  nil,
 		CourseTopic:    nil,
@@ -1061,21 +1176,409 @@ func (a *Application) initServices() {
 		ForumAnswer:    nil,
 	}
 
+	a.services.AccountData.SetNotificationService(a.services.Notification)
+
 	a.registerPluginServiceBindings()
+	a.registerBulkResources()
 
 	backupService.InitializeAutoBackups()
+	a.scheduleUploadGC()
+	a.scheduleAuditLogPrune()
+	a.scheduleAPIUsageFlush()
+	a.scheduleBlockRuleMaintenance()
+	a.scheduleEmailDeliveryPrune()
+	a.scheduleErrorRateAlerts()
+	a.scheduleActivityDigests()
+	a.scheduleNotificationPrune()
+	a.scheduleDataExportPrune()
+	a.scheduleConsentPrune()
+	a.scheduleRevisionPrune()
+	a.scheduleBackupVerification()
+}
+
+// configureDirectUploads enables browser uploads straight to object storage when the
+// media bucket is configured.
+func (a *Application) configureDirectUploads(uploadService *service.UploadService) {
+	if !a.cfg.MediaS3Enabled {
+		return
+	}
+	direct, err := service.NewDirectUploadService(uploadService, a.repositories.RemoteUpload, service.DirectUploadConfig{
+		Endpoint:  a.cfg.MediaS3Endpoint,
+		AccessKey: strings.TrimSpace(a.cfg.MediaS3AccessKey),
+		SecretKey: strings.TrimSpace(a.cfg.MediaS3SecretKey),
+		Bucket:    a.cfg.MediaS3Bucket,
+		Region:    a.cfg.MediaS3Region,
+		UseSSL:    a.cfg.MediaS3UseSSL,
+		Prefix:    a.cfg.MediaS3Prefix,
+		PublicURL: a.cfg.MediaS3PublicURL,
+		Expiry:    time.Duration(a.cfg.MediaS3UploadExpiry) * time.Minute,
+	}, a.cfg.JWTSecret)
+	if err != nil {
+		logger.Warn("Incomplete media bucket configuration; direct uploads disabled", map[string]interface{}{"error": err.Error()})
+		return
+	}
+	uploadService.SetDirectUploads(direct)
+}
+
+// configureBackupStorage sets the object storage that receives a copy of every
+// automatic backup, chosen by BACKUP_STORAGE.
+func (a *Application) configureBackupStorage(options *service.BackupOptions) {
+	storage := a.cfg.BackupStorage
+	if storage == "" && a.cfg.BackupS3Enabled {
+		storage = service.BackupLocationS3
+	}
+
+	switch storage {
+	case "":
+		return
+	case service.BackupLocationS3:
+		endpoint := strings.TrimSpace(a.cfg.BackupS3Endpoint)
+		accessKey := strings.TrimSpace(a.cfg.BackupS3AccessKey)
+		secretKey := strings.TrimSpace(a.cfg.BackupS3SecretKey)
+		bucket := strings.TrimSpace(a.cfg.BackupS3Bucket)
+
+		if endpoint == "" || accessKey == "" || secretKey == "" || bucket == "" {
+			logger.Warn("Incomplete S3 backup configuration; remote uploads disabled", map[string]interface{}{
+				"endpoint_configured": endpoint != "",
+				"bucket_configured":   bucket != "",
+				"access_configured":   accessKey != "" && secretKey != "",
+			})
+			return
+		}
+		options.S3 = &service.BackupS3Config{
+			Endpoint:  endpoint,
+			AccessKey: accessKey,
+			SecretKey: secretKey,
+			Bucket:    bucket,
+			Region:    strings.TrimSpace(a.cfg.BackupS3Region),
+			UseSSL:    a.cfg.BackupS3UseSSL,
+			Prefix:    strings.Trim(a.cfg.BackupS3Prefix, "/"),
+		}
+	case service.BackupLocationGCS:
+		bucket := a.cfg.BackupGCSBucket
+		if bucket == "" || a.cfg.BackupGCSCredentialsFile == "" {
+			logger.Warn("Incomplete GCS backup configuration; remote uploads disabled", map[string]interface{}{
+				"bucket_configured":      bucket != "",
+				"credentials_configured": a.cfg.BackupGCSCredentialsFile != "",
+			})
+			return
+		}
+		credentials, err := os.ReadFile(a.cfg.BackupGCSCredentialsFile)
+		if err != nil {
+			logger.Error(err, "Failed to read GCS backup credentials; remote uploads disabled", map[string]interface{}{"path": a.cfg.BackupGCSCredentialsFile})
+			return
+		}
+		options.GCS = &service.BackupGCSConfig{
+			Bucket:      bucket,
+			Prefix:      strings.Trim(a.cfg.BackupGCSPrefix, "/"),
+			Credentials: credentials,
+		}
+	case service.BackupLocationAzure:
+		account := a.cfg.BackupAzureAccount
+		container := a.cfg.BackupAzureContainer
+		if account == "" || a.cfg.BackupAzureKey == "" || container == "" {
+			logger.Warn("Incomplete Azure backup configuration; remote uploads disabled", map[string]interface{}{
+				"account_configured":   account != "",
+				"key_configured":       a.cfg.BackupAzureKey != "",
+				"container_configured": container != "",
+			})
+			return
+		}
+		options.Azure = &service.BackupAzureConfig{
+			Account:   account,
+			Key:       a.cfg.BackupAzureKey,
+			Container: container,
+			Prefix:    strings.Trim(a.cfg.BackupAzurePrefix, "/"),
+			Endpoint:  a.cfg.BackupAzureEndpoint,
+		}
+	default:
+		logger.Warn("Unknown backup storage; remote uploads disabled", map[string]interface{}{"storage": storage})
+	}
+}
+
+// configureCDN serves public uploads through the configured CDN.
+func (a *Application) configureCDN(uploadService *service.UploadService) {
+	if strings.TrimSpace(a.cfg.CDNBaseURL) == "" {
+		return
+	}
+	cdn, err := service.NewCDN(service.CDNConfig{
+		BaseURL:                  a.cfg.CDNBaseURL,
+		PurgeProvider:            a.cfg.CDNPurgeProvider,
+		BunnyAPIKey:              a.cfg.CDNBunnyAPIKey,
+		CloudFrontDistributionID: a.cfg.CDNCloudFrontDistributionID,
+		CloudFrontAccessKey:      a.cfg.CDNCloudFrontAccessKey,
+		CloudFrontSecretKey:      a.cfg.CDNCloudFrontSecretKey,
+	})
+	if err != nil {
+		logger.Warn("Invalid CDN configuration; uploads are served directly", map[string]interface{}{"error": err.Error()})
+		return
+	}
+	uploadService.SetCDN(cdn)
+	logger.Info("Serving uploads through CDN", map[string]interface{}{"base_url": cdn.BaseURL()})
+}
+
+// scheduleUploadGC registers the recurring upload garbage collection when enabled.
+func (a *Application) scheduleUploadGC() {
+	if a.cfg == nil || !a.cfg.UploadGCEnabled || a.scheduler == nil {
+		return
+	}
+
+	collector := a.services.UploadGC
+	err := a.scheduler.ScheduleRecurring(background.RecurringJob{
+		Name:     "upload_gc",
+		Schedule: a.cfg.UploadGCSchedule,
+		Timeout:  30 * time.Minute,
+		Run: func(ctx context.Context) error {
+			_, err := collector.Run(ctx, false)
+			return err
+		},
+	})
+	if err != nil {
+		logger.Error(err, "Failed to schedule upload garbage collection", nil)
+	}
+}
+
+// scheduleAuditLogPrune removes audit log entries past their retention every night.
+func (a *Application) scheduleAuditLogPrune() {
+	if a.scheduler == nil || a.services.AuditLog == nil {
+		return
+	}
+
+	auditLog := a.services.AuditLog
+	err := a.scheduler.ScheduleRecurring(background.RecurringJob{
+		Name:     "audit_log_prune",
+		Schedule: "15 4 * * *",
+		Timeout:  30 * time.Minute,
+		Run: func(ctx context.Context) error {
+			_, err := auditLog.Prune(ctx)
+			return err
+		},
+	})
+	if err != nil {
+		logger.Error(err, "Failed to schedule audit log pruning", nil)
+	}
+}
+
+// scheduleEmailDeliveryPrune removes old entries from the email send log daily.
+func (a *Application) scheduleEmailDeliveryPrune() {
+	if a.scheduler == nil || a.services.Email == nil {
+		return
+	}
+
+	email := a.services.Email
+	err := a.scheduler.ScheduleRecurring(background.RecurringJob{
+		Name:     "email_delivery_prune",
+		Schedule: "45 4 * * *",
+		Timeout:  10 * time.Minute,
+		Run: func(ctx context.Context) error {
+			_, err := email.PruneDeliveries(ctx)
+			return err
+		},
+	})
+	if err != nil {
+		logger.Error(err, "Failed to schedule email log pruning", nil)
+	}
+}
+
+// scheduleErrorRateAlerts checks the error rate for spikes every minute.
+func (a *Application) scheduleErrorRateAlerts() {
+	if a.scheduler == nil || a.services.Alert == nil {
+		return
+	}
+
+	err := a.scheduler.ScheduleRecurring(background.RecurringJob{
+		Name:     "error_rate_alerts",
+		Schedule: "@every 1m",
+		Timeout:  30 * time.Second,
+		Run:      a.services.Alert.CheckErrorRate,
+	})
+	if err != nil {
+		logger.Error(err, "Failed to schedule error rate alerts", nil)
+	}
+}
+
+// scheduleActivityDigests sends the daily and weekly activity digests every
+// morning.
+func (a *Application) scheduleActivityDigests() {
+	if a.scheduler == nil || a.services.Digest == nil {
+		return
+	}
+
+	digest := a.services.Digest
+	err := a.scheduler.ScheduleRecurring(background.RecurringJob{
+		Name:     "activity_digests",
+		Schedule: "0 7 * * *",
+		Timeout:  30 * time.Minute,
+		Run: func(ctx context.Context) error {
+			_, err := digest.SendDue(ctx)
+			return err
+		},
+	})
+	if err != nil {
+		logger.Error(err, "Failed to schedule activity digests", nil)
+	}
+}
+
+// scheduleNotificationPrune removes old read in-app notifications daily.
+func (a *Application) scheduleNotificationPrune() {
+	if a.scheduler == nil || a.services.Notification == nil {
+		return
+	}
+
+	notifications := a.services.Notification
+	err := a.scheduler.ScheduleRecurring(background.RecurringJob{
+		Name:     "notification_prune",
+		Schedule: "15 5 * * *",
+		Timeout:  10 * time.Minute,
+		Run: func(ctx context.Context) error {
+			_, err := notifications.Prune(ctx)
+			return err
+		},
+	})
+	if err != nil {
+		logger.Error(err, "Failed to schedule notification pruning", nil)
+	}
+}
+
+// scheduleAPIUsageFlush writes the buffered API usage to the database every minute.
+func (a *Application) scheduleAPIUsageFlush() {
+	if a.scheduler == nil || a.services.APIQuota == nil {
+		return
+	}
+
+	err := a.scheduler.ScheduleRecurring(background.RecurringJob{
+		Name:     "api_usage_flush",
+		Schedule: "@every 1m",
+		Timeout:  time.Minute,
+		Run:      a.services.APIQuota.FlushUsage,
+	})
+	if err != nil {
+		logger.Error(err, "Failed to schedule API usage flushing", nil)
+	}
+}
+
+// scheduleBlockRuleMaintenance stores the block rule hit counters and removes
+// expired bans every minute.
+func (a *Application) scheduleBlockRuleMaintenance() {
+	if a.scheduler == nil || a.services.BlockRule == nil {
+		return
+	}
+
+	err := a.scheduler.ScheduleRecurring(background.RecurringJob{
+		Name:     "block_rule_maintenance",
+		Schedule: "@every 1m",
+		Timeout:  time.Minute,
+		Run:      a.services.BlockRule.MaintainRules,
+	})
+	if err != nil {
+		logger.Error(err, "Failed to schedule block rule maintenance", nil)
+	}
+}
+
+// scheduleDataExportPrune removes data export archives once they expire.
+func (a *Application) scheduleDataExportPrune() {
+	if a.scheduler == nil || a.services.AccountData == nil {
+		return
+	}
+
+	err := a.scheduler.ScheduleRecurring(background.RecurringJob{
+		Name:     "data_export_prune",
+		Schedule: "@every 1h",
+		Timeout:  time.Minute,
+		Run:      a.services.AccountData.PruneExports,
+	})
+	if err != nil {
+		logger.Error(err, "Failed to schedule data export pruning", nil)
+	}
+}
+
+// scheduleConsentPrune removes consent records once they are no longer kept.
+func (a *Application) scheduleConsentPrune() {
+	if a.scheduler == nil || a.services.Consent == nil {
+		return
+	}
+
+	consent := a.services.Consent
+	err := a.scheduler.ScheduleRecurring(background.RecurringJob{
+		Name:     "consent_record_prune",
+		Schedule: "45 4 * * *",
+		Timeout:  30 * time.Minute,
+		Run: func(context.Context) error {
+			_, err := consent.PruneRecords()
+			return err
+		},
+	})
+	if err != nil {
+		logger.Error(err, "Failed to schedule consent record pruning", nil)
+	}
+}
+
+// scheduleRevisionPrune removes revisions older than the age set in the
+// revision settings.
+func (a *Application) scheduleRevisionPrune() {
+	if a.scheduler == nil || a.services.Revision == nil {
+		return
+	}
+
+	revisions := a.services.Revision
+	err := a.scheduler.ScheduleRecurring(background.RecurringJob{
+		Name:     "content_revision_prune",
+		Schedule: "15 5 * * *",
+		Timeout:  30 * time.Minute,
+		Run: func(context.Context) error {
+			_, err := revisions.Prune()
+			return err
+		},
+	})
+	if err != nil {
+		logger.Error(err, "Failed to schedule revision pruning", nil)
+	}
+}
+
+// scheduleBackupVerification re-reads the latest backup archive on
+// BACKUP_VERIFY_SCHEDULE so a corrupt archive is noticed before it is needed.
+func (a *Application) scheduleBackupVerification() {
+	if a.scheduler == nil || a.services.Backup == nil || a.cfg.BackupVerifySchedule == "" {
+		return
+	}
+
+	backups := a.services.Backup
+	err := a.scheduler.ScheduleRecurring(background.RecurringJob{
+		Name:     "backup_verify",
+		Schedule: a.cfg.BackupVerifySchedule,
+		Timeout:  30 * time.Minute,
+		Run: func(ctx context.Context) error {
+			_, err := backups.VerifyLatest(ctx)
+			if errors.Is(err, service.ErrNoBackups) {
+				return nil
+			}
+			return err
+		},
+	})
+	if err != nil {
+		logger.Error(err, "Failed to schedule backup verification", nil)
+	}
 }
 
 func (a *Application) initHandlers() error {
 	commentGuard := bloghandlers.NewCommentGuard(a.cfg)
+	manifestHandler := handlers.NewManifestHandler(a.cfg, a.services.Setup, func() *languageservice.LanguageService {
+		return a.services.Language
+	}, a.services.Payment, a.pluginManager, a.pluginRuntime, a.themeManager)
+
+	authHandler := handlers.NewAuthHandler(a.services.Auth)
 
 	a.handlers = handlerContainer{
-		Auth:             handlers.NewAuthHandler(a.services.Auth),
+		Auth:             authHandler,
 		Category:         bloghandlers.NewCategoryHandler(nil),
 		Post:             bloghandlers.NewPostHandler(nil),
 		Comment:          bloghandlers.NewCommentHandler(nil, a.services.Auth, commentGuard),
 		Search:           bloghandlers.NewSearchHandler(nil),
 		Upload:           handlers.NewUploadHandler(a.services.Upload),
+		Image:            handlers.NewImageHandler(a.services.ImageTransform),
+		Accessibility:    handlers.NewAccessibilityHandler(a.services.Accessibility),
+		Embed:            handlers.NewEmbedHandler(a.services.Embed),
+		UploadGC:         handlers.NewUploadGCHandler(a.services.UploadGC),
 		Backup:           handlers.NewBackupHandler(a.services.Backup),
 		Page:             handlers.NewPageHandler(a.services.Page),
 		PageBuilder:      handlers.NewPageBuilderHandler(a.services.Page),
@@ -1085,7 +1588,33 @@ func (a *Application) initHandlers() error {
 		Menu:             handlers.NewMenuHandler(a.services.Menu),
 		SEO:              handlers.NewSEOHandler(nil, a.services.Page, nil, a.services.Setup, a.services.Language, a.cfg),
 		Advertising:      handlers.NewAdvertisingHandler(a.services.Advertising),
+		RateLimit:        handlers.NewRateLimitHandler(a.services.RateLimit),
+		BlockRule:        handlers.NewBlockRuleHandler(a.services.BlockRule),
+		APIQuota:         handlers.NewAPIQuotaHandler(a.services.APIQuota, a.services.DeliveryToken),
+		Manifest:         manifestHandler,
+		Headless:         handlers.NewHeadlessHandler(a.services.Headless),
+		Alert:            handlers.NewAlertHandler(a.services.Alert),
+		CSP:              handlers.NewContentSecurityPolicyHandler(a.services.CSP, a.cfg, a.services.Advertising),
+		Digest:           handlers.NewDigestHandler(a.services.Digest),
+		Notification:     handlers.NewNotificationHandler(a.services.Notification),
+		AccountData:      handlers.NewAccountDataHandler(a.services.AccountData, authHandler),
+		Consent:          handlers.NewConsentHandler(a.services.Consent),
+		Revision:         handlers.NewRevisionHandler(a.services.Revision),
+		Sanitization:     handlers.NewSanitizationHandler(a.services.Sanitization),
+		Bulk:             handlers.NewBulkHandler(a.services.Bulk),
+		AuditLog:         handlers.NewAuditLogHandler(a.services.AuditLog),
+		Status:           handlers.NewStatusHandler(a.services.Status),
+		Announcement:     handlers.NewAnnouncementHandler(a.services.Announcement),
+		LiveUpdate:       handlers.NewLiveUpdateHandler(a.services.LiveUpdate, time.Duration(a.cfg.ServerWriteTimeout)*time.Second),
 		Plugin:           handlers.NewPluginHandler(a.services.Plugin),
+		Webhook:          handlers.NewWebhookHandler(a.services.Webhook),
+		EmailDelivery:    handlers.NewEmailDeliveryHandler(a.services.Email),
+		EmailFeedback:    handlers.NewEmailFeedbackHandler(a.services.Email, a.cfg.EmailFeedbackSecret),
+		DeliveryToken:    handlers.NewDeliveryTokenHandler(a.services.DeliveryToken),
+		SocialShare:      handlers.NewSocialShareHandler(a.services.SocialShare),
+		Payment:          handlers.NewPaymentHandler(a.services.Payment),
+		Scheduler:        handlers.NewSchedulerHandler(a.scheduler),
+		Diagnostics:      handlers.NewDiagnosticsHandler(a.slowQueries, time.Duration(a.cfg.DBSlowQueryThreshold)*time.Millisecond),
 		CourseVideo:      coursehandlers.NewVideoHandler(nil),
 		CourseContent:    coursehandlers.NewContentHandler(nil),
 		CourseTopic:      coursehandlers.NewTopicHandler(nil),
@@ -1099,7 +1628,16 @@ func (a *Application) initHandlers() error {
 		ArchiveFile:      archivehandlers.NewFileHandler(nil),
 		ArchivePublic:    archivehandlers.NewPublicHandler(nil, nil),
 		ForumAnswer:      forumhandlers.NewAnswerHandler(nil),
+
+		NewsletterSubscriber: newsletterhandlers.NewSubscriberHandler(nil),
+		NewsletterCampaign:   newsletterhandlers.NewCampaignHandler(nil),
+		NewsletterPublic:     newsletterhandlers.NewPublicHandler(nil, nil),
+		Event:                eventhandlers.NewEventHandler(nil),
+		EventPublic:          eventhandlers.NewPublicHandler(nil, nil, models.SiteSettings{}),
+		Product:              producthandlers.NewProductHandler(nil, nil),
+		ProductPublic:        producthandlers.NewPublicHandler(nil, nil, ""),
 	}
+	a.handlers.SettingsExport = handlers.NewSettingsExportHandler(a.services.SettingsExport, a.handlers.Setup)
 
 	templateHandler, err := handlers.NewTemplateHandler(
 		nil,
@@ -1130,6 +1668,27 @@ func (a *Application) initHandlers() error {
 	}
 
 	a.templateHandler = templateHandler
+	a.templateHandler.SetUploadService(a.services.Upload)
+	a.templateHandler.SetImageTransformService(a.services.ImageTransform)
+	a.templateHandler.SetEmbedService(a.services.Embed)
+	a.templateHandler.SetStatusService(a.services.Status)
+	a.templateHandler.SetAnnouncementService(a.services.Announcement)
+	a.templateHandler.SetConsentService(a.services.Consent)
+	a.templateHandler.SetSanitizationService(a.services.Sanitization)
+	if a.services.Theme != nil {
+		a.templateHandler.SetTemplateOverrides(a.services.Theme)
+	}
+	if a.handlers.PageBuilder != nil {
+		a.handlers.PageBuilder.SetTemplateHandler(a.templateHandler)
+	}
+	a.handlers.SEO.SetMachineTranslationService(a.services.Translation)
+	a.handlers.SEO.SetMenuService(a.services.Menu)
+	a.handlers.SEO.SetThemeManager(a.themeManager)
+	for _, name := range sitemapEvents {
+		a.events.Subscribe(name, func(context.Context, events.Event) {
+			a.handlers.SEO.InvalidateSitemaps()
+		})
+	}
 
 	a.handlers.Font = handlers.NewFontHandler(a.services.Font)
 
@@ -1161,9 +1720,11 @@ func (a *Application) initRouter() error {
 
 	router.Use(logger.GinRecovery(true))
 	router.Use(middleware.RequestIDMiddleware())
+	router.Use(middleware.ErrorEnvelopeMiddleware("/api/"))
 	router.Use(logger.GinLogger())
-	router.Use(middleware.SecurityHeadersMiddleware(a.cfg, a.services.Advertising))
-	router.Use(middleware.MetricsMiddleware())
+	router.Use(middleware.BlockRulesMiddleware(a.services.BlockRule, a.cfg.GeoIPCountryHeader))
+	router.Use(middleware.SecurityHeadersMiddleware(a.cfg, a.services.CSP, a.services.Advertising))
+	router.Use(middleware.MetricsMiddleware(a.cfg.DBQueryBudget))
 
 	// Set rate limit manager in context for all requests
 	router.Use(func(c *gin.Context) {
@@ -1174,22 +1735,44 @@ func (a *Application) initRouter() error {
 	})
 
 	router.Use(middleware.RateLimitMiddleware(a.cfg))
+	router.Use(middleware.RateLimitPolicyMiddleware(a.services.RateLimit, a.cfg.JWTSecret))
+	router.Use(middleware.APIQuotaMiddleware(a.services.APIQuota, a.cfg.JWTSecret))
 	router.Use(middleware.CSRFMiddleware())
 
 	router.Use(cors.New(cors.Config{
 		AllowOrigins:     a.cfg.CORSOrigins,
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", middleware.IdempotencyKeyHeader},
+		ExposeHeaders:    []string{"Content-Length", middleware.IdempotentReplayedHeader},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
 
 	router.Use(middleware.SetupMiddleware(a.services.Setup, a.cfg))
+	router.Use(middleware.CanonicalURLMiddleware(func() string {
+		return a.services.Setup.SiteURL(a.cfg.SiteURL)
+	}, a.cfg.EnforceCanonicalHost,
+		"/api", "/health", "/metrics", "/debug", "/static", "/uploads", "/img", theme.ThemeAssetsPrefix, "/ext", "/.well-known",
+	))
 	router.Use(middleware.LanguageNegotiationMiddleware(func() *languageservice.LanguageService {
 		return a.services.Language
 	}))
 
+	// Registered before the CDN rewriter so that the ETag covers the rewritten body.
+	router.Use(middleware.ConditionalGetMiddleware(
+		"/admin", "/api/v1/admin", "/profile", "/api/v1/profile", "/setup", "/api/v1/setup",
+		"/uploads", "/static", "/img", "/health", "/metrics", "/debug",
+	))
+
+	cdn := a.services.Upload.CDN()
+	if cdn != nil {
+		// Editors post URLs back to the server, so they keep seeing local paths.
+		router.Use(middleware.CDNRewriteMiddleware(cdn,
+			"/admin", "/api/v1/admin", "/profile", "/api/v1/profile", "/setup", "/api/v1/setup",
+			"/uploads", "/static", "/img",
+		))
+	}
+
 	if a.themeManager != nil {
 		if active := a.themeManager.Active(); active != nil {
 			logger.Info("Active theme loaded", map[string]interface{}{"theme": active.Slug})
@@ -1203,22 +1786,63 @@ func (a *Application) initRouter() error {
 		})
 	})
 
-	router.GET("/metrics", middleware.NoIndexMiddleware(), a.metricsHandler())
+	router.GET("/metrics", middleware.NoIndexMiddleware(), a.metricsAccess(), a.metricsHandler())
+
+	if a.cfg.EnableProfiling {
+		debug := router.Group("/debug")
+		debug.Use(middleware.NoIndexMiddleware(), a.metricsAccess())
+		{
+			debug.GET("/pprof/*profile", handlers.Pprof)
+			debug.POST("/pprof/symbol", handlers.Pprof)
+			debug.GET("/goroutines", handlers.GoroutineDump)
+			debug.GET("/runtime", handlers.RuntimeStats)
+		}
+	}
 
 	if a.themeManager != nil {
-		router.StaticFS("/static", theme.NewFileSystem(a.themeManager, "./static"))
+		static := router.Group("/static")
+		static.Use(middleware.StaticCacheMiddleware("/static", a.themeManager.IsFingerprintedAsset))
+		staticFS := theme.NewFileSystem(a.themeManager, "./static")
+		serveStatic := func(c *gin.Context) {
+			middleware.ServeFile(c, staticFS, c.Param("filepath"))
+		}
+		static.GET("/*filepath", serveStatic)
+		static.HEAD("/*filepath", serveStatic)
+
+		themeAssets := router.Group(strings.TrimSuffix(theme.ThemeAssetsPrefix, "/"))
+		themeAssets.Use(middleware.StaticCacheMiddleware(theme.ThemeAssetsPrefix, a.themeManager.IsThemeAssetFingerprinted))
+		themeAssets.GET("/:theme/*filepath", a.serveThemeAsset)
+		themeAssets.HEAD("/:theme/*filepath", a.serveThemeAsset)
 	} else {
-		router.Static("/static", "./static")
+		static := router.Group("/static")
+		static.Use(middleware.StaticCacheMiddleware("/static", nil))
+		serveStatic := func(c *gin.Context) {
+			middleware.ServeFile(c, http.Dir("./static"), c.Param("filepath"))
+		}
+		static.GET("/*filepath", serveStatic)
+		static.HEAD("/*filepath", serveStatic)
 	}
 	uploads := router.Group("/uploads")
 	uploads.Use(middleware.UploadsProtection())
+	if cdn != nil {
+		uploads.Use(middleware.CDNOriginMiddleware())
+	} else {
+		uploads.Use(middleware.RevalidateUploadsMiddleware())
+	}
 	uploads.GET("/*filepath", a.serveUpload)
 	uploads.HEAD("/*filepath", a.serveUpload)
-	router.StaticFile("/favicon.ico", "./favicon.ico")
+	router.GET("/img/:transform/*path", a.handlers.Image.Serve)
+	serveFavicon := func(c *gin.Context) {
+		middleware.ServeFile(c, http.Dir("."), "favicon.ico")
+	}
+	router.GET("/favicon.ico", middleware.StaticCacheMiddleware("", nil), serveFavicon)
+	router.HEAD("/favicon.ico", middleware.StaticCacheMiddleware("", nil), serveFavicon)
 
 	if a.handlers.SEO != nil {
 		router.GET("/sitemap.xml", a.handlers.SEO.Sitemap)
+		router.GET("/sitemaps/:file", a.handlers.SEO.SitemapSection)
 		router.GET("/robots.txt", a.handlers.SEO.Robots)
+		a.handlers.SEO.SetRenderer(router)
 	}
 
 	router.GET("/.well-known/appspecific/com.chrome.devtools.json", middleware.NoIndexMiddleware(), func(c *gin.Context) {
@@ -1255,40 +1879,84 @@ func (a *Application) initRouter() error {
 		})
 	})
 
-	router.GET("/", a.templateHandler.RenderIndex)
+	// pages are the rendered site, which headless mode hands over to the frontend.
+	// Sign-in, setup and the admin stay, as do feeds and links sent by email.
+	pages := router.Group("", middleware.HeadlessMiddleware(a.services.Headless))
+	pages.GET("/", a.templateHandler.RenderIndex)
 	router.GET("/login", a.templateHandler.RenderLogin)
-	router.GET("/register", a.templateHandler.RenderRegister)
+	pages.GET("/register", a.templateHandler.RenderRegister)
 	router.GET("/forgot-password", a.templateHandler.RenderForgotPassword)
 	router.GET("/reset-password", a.templateHandler.RenderPasswordReset)
 	router.GET("/setup", a.templateHandler.RenderSetup)
 	router.GET("/setup/key-required", a.templateHandler.RenderSetupKeyRequired)
-	router.GET("/profile", a.templateHandler.RenderProfile)
-	router.GET("/courses/checkout/success", a.templateHandler.RenderCourseCheckoutSuccess)
-	router.GET("/courses/checkout/cancel", a.templateHandler.RenderCourseCheckoutCancel)
+	pages.GET("/profile", a.templateHandler.RenderProfile)
+	pages.GET("/courses/checkout/success", a.templateHandler.RenderCourseCheckoutSuccess)
+	pages.GET("/courses/checkout/cancel", a.templateHandler.RenderCourseCheckoutCancel)
 	router.GET("/checkout/success", func(c *gin.Context) {
 		c.Redirect(http.StatusMovedPermanently, "/courses/checkout/success")
 	})
 	router.GET("/checkout/cancel", func(c *gin.Context) {
 		c.Redirect(http.StatusMovedPermanently, "/courses/checkout/cancel")
 	})
-	router.GET("/courses/:slug", a.templateHandler.RenderCourse)
+	pages.GET("/courses/:slug", a.templateHandler.RenderCourse)
+	pages.GET("/courses/:slug/topics/:topic", a.templateHandler.RenderCourseTopic)
 	router.GET("/admin", a.templateHandler.RenderAdmin)
-	router.GET("/blog/post/:slug", a.templateHandler.RenderPost)
-	router.GET("/page/:slug", a.templateHandler.RenderPage)
-	router.GET("/blog", a.templateHandler.RenderBlog)
-	router.GET("/search", a.templateHandler.RenderSearch)
-	router.GET("/forum", a.templateHandler.RenderForum)
-	router.GET("/forum/:slug", a.templateHandler.RenderForumQuestion)
-	router.GET("/category/:slug", a.templateHandler.RenderCategory)
-	router.GET("/tag/:slug", a.templateHandler.RenderTag)
-	router.GET("/archive", a.templateHandler.RenderArchive)
-	router.GET("/archive/*path", a.templateHandler.RenderArchivePath)
+	pages.GET("/blog/post/:slug", a.templateHandler.RenderPost)
+	pages.GET("/page/:slug", a.templateHandler.RenderPage)
+	pages.GET("/blog", a.templateHandler.RenderBlog)
+	pages.GET("/search", a.templateHandler.RenderSearch)
+	pages.GET("/forum", a.templateHandler.RenderForum)
+	pages.GET("/forum/:slug", a.templateHandler.RenderForumQuestion)
+	pages.GET("/category/:slug", a.templateHandler.RenderCategory)
+	pages.GET("/tag/:slug", a.templateHandler.RenderTag)
+	pages.GET("/archive", a.templateHandler.RenderArchive)
+	pages.GET("/archive/*path", a.templateHandler.RenderArchivePath)
+	router.Any("/ext/:slug/*path", a.externalPlugins.ServeRoute)
+	pages.GET("/status", a.templateHandler.RenderStatus)
+	pages.GET("/events", a.templateHandler.RenderEvents)
+	router.GET("/events.ics", a.handlers.EventPublic.Feed)
+	pages.GET("/events/:slug", a.templateHandler.RenderEvent)
+	router.GET("/events/:slug/calendar.ics", a.handlers.EventPublic.EventFeed)
+	pages.GET("/products/checkout/success", a.templateHandler.RenderProductCheckoutSuccess)
+	pages.GET("/products/checkout/cancel", a.templateHandler.RenderProductCheckoutCancel)
+	router.GET("/products/download/:token", a.handlers.ProductPublic.Download)
+	router.GET("/newsletter/confirm/:token", a.handlers.NewsletterPublic.Confirm)
+	router.GET("/newsletter/unsubscribe/:token", a.handlers.NewsletterPublic.Unsubscribe)
+	router.POST("/newsletter/unsubscribe/:token", a.handlers.NewsletterPublic.Unsubscribe)
+	router.GET("/digest/unsubscribe/:token", a.handlers.Digest.Unsubscribe)
+	router.POST("/digest/unsubscribe/:token", a.handlers.Digest.Unsubscribe)
+	router.GET("/newsletter/o/:token", a.handlers.NewsletterPublic.Open)
+	router.GET("/newsletter/c/:token/:index", a.handlers.NewsletterPublic.Click)
+
+	// apiDocs builds the OpenAPI document from the routes below; each group ends
+	// with the scope its middleware enforces.
+	apiDocs := openapi.NewRegistry(router, openapi.Options{
+		Info:       openapi.Info{Title: "Constructor Script API", Version: version.Version},
+		Prefix:     "/api/v1",
+		AuthCookie: constants.AuthTokenCookieName,
+	})
+	apiDocsHandler := handlers.NewOpenAPIHandler(apiDocs, "/api/v1/openapi.json")
+
+	// idempotent guards the routes whose retries would create a second post,
+	// grant or payment session.
+	idempotent := middleware.IdempotencyMiddleware(middleware.NewIdempotencyStore(a.cache))
+
+	// legacy marks the v1 routes replaced in /api/v2.
+	legacy := middleware.DeprecatedRoute(middleware.APIDeprecation{
+		Since:    apiV1ListsDeprecatedAt,
+		Sunset:   a.cfg.APIV1Sunset,
+		Disabled: a.cfg.LegacyAPIDisabled,
+	})
 
 	v1 := router.Group("/api/v1")
 	v1.Use(middleware.NoIndexMiddleware())
 	{
 		public := v1.Group("")
 		{
+			if a.cfg.EnableOpenAPI {
+				public.GET("/openapi.json", apiDocsHandler.Spec)
+			}
+			public.GET("/manifest", a.handlers.Manifest.Get)
 			public.GET("/setup/status", a.handlers.Setup.Status)
 			public.GET("/setup/progress", a.handlers.Setup.GetStepProgress)
 			public.POST("/setup/step", a.handlers.Setup.SaveStep)
@@ -1296,12 +1964,19 @@ func (a *Application) initRouter() error {
 			public.POST("/setup", a.handlers.Setup.Complete)
 			public.POST("/register", a.handlers.Auth.Register)
 			public.POST("/login", a.handlers.Auth.Login)
+			public.POST("/login/2fa", a.handlers.Auth.LoginTwoFactor)
+			public.POST("/login/2fa/enroll", a.handlers.Auth.LoginTwoFactorEnroll)
 			public.POST("/logout", a.handlers.Auth.Logout)
 			public.POST("/refresh", a.handlers.Auth.RefreshToken)
 			public.POST("/password/forgot", a.handlers.Auth.RequestPasswordReset)
 			public.POST("/password/reset", a.handlers.Auth.ResetPassword)
+			public.GET("/auth/oauth/providers", a.handlers.Auth.OAuthProviders)
+			public.GET("/auth/oauth/:provider", a.handlers.Auth.OAuthStart)
+			public.GET("/auth/oauth/:provider/callback", a.handlers.Auth.OAuthCallback)
+			public.GET("/consent", a.handlers.Consent.State)
+			public.PUT("/consent", a.handlers.Consent.Update)
 
-			public.GET("/posts", a.handlers.Post.GetAll)
+			public.GET("/posts", legacy, a.handlers.Post.GetAll)
 			public.GET("/posts/:id", a.handlers.Post.GetByID)
 			public.GET("/posts/slug/:slug", a.handlers.Post.GetBySlug)
 
@@ -1317,16 +1992,47 @@ func (a *Application) initRouter() error {
 			public.GET("/search", a.handlers.Search.Search)
 
 			public.GET("/tags", a.handlers.Post.GetAllTags)
-			public.GET("/tags/:slug/posts", a.handlers.Post.GetPostsByTag)
+			public.GET("/tags/:slug/posts", legacy, a.handlers.Post.GetPostsByTag)
 			public.POST("/courses/checkout/webhook", a.handlers.CourseCheckout.HandleWebhook)
-			public.GET("/forum/questions", a.handlers.ForumQuestion.List)
+			public.POST("/payments/webhook", a.handlers.Payment.Webhook)
+			public.POST("/email/feedback/:token", a.handlers.EmailFeedback.Receive)
+			public.GET("/forum/questions", legacy, a.handlers.ForumQuestion.List)
 			public.GET("/forum/questions/:id", a.handlers.ForumQuestion.GetByID)
 			public.GET("/forum/categories", a.handlers.ForumCategory.List)
 			public.GET("/forum/categories/:id", a.handlers.ForumCategory.GetByID)
 			public.GET("/archive/tree", a.handlers.ArchivePublic.Tree)
 			public.GET("/archive/directories/*path", a.handlers.ArchivePublic.GetDirectory)
 			public.GET("/archive/files/*path", a.handlers.ArchivePublic.GetFile)
-		}
+			public.POST("/newsletter/subscribe", a.handlers.NewsletterPublic.Subscribe)
+			public.GET("/status", a.handlers.Status.Current)
+			public.GET("/events", a.handlers.EventPublic.Occurrences)
+			public.GET("/events/:slug", a.handlers.EventPublic.GetBySlug)
+			public.GET("/products", a.handlers.ProductPublic.List)
+			public.POST("/products/checkout/verify", a.handlers.ProductPublic.Verify)
+			public.GET("/products/:slug", a.handlers.ProductPublic.GetBySlug)
+			public.POST("/products/:slug/checkout", idempotent, a.handlers.ProductPublic.Checkout)
+		}
+		apiDocs.Describe(openapi.Scope{})
+
+		// delivery serves published content to static site builds and apps holding
+		// a delivery token, with limits per token instead of per address.
+		delivery := v1.Group("/delivery")
+		delivery.Use(middleware.DeliveryTokenMiddleware(a.services.DeliveryToken, a.services.APIQuota, a.cfg))
+		{
+			delivery.GET("/posts", a.handlers.Post.GetAll)
+			delivery.GET("/posts/:id", a.handlers.Post.GetByID)
+			delivery.GET("/posts/slug/:slug", a.handlers.Post.GetBySlug)
+			delivery.GET("/pages", a.handlers.Page.GetAll)
+			delivery.GET("/pages/:id", a.handlers.Page.GetByID)
+			delivery.GET("/pages/slug/:slug", a.handlers.Page.GetBySlug)
+			delivery.GET("/categories", a.handlers.Category.GetAll)
+			delivery.GET("/categories/:id", a.handlers.Category.GetByID)
+			delivery.GET("/tags", a.handlers.Post.GetAllTags)
+			delivery.GET("/tags/:slug/posts", a.handlers.Post.GetPostsByTag)
+			delivery.GET("/search", a.handlers.Search.Search)
+			delivery.GET("/usage", a.handlers.APIQuota.DeliveryUsage)
+		}
+		apiDocs.Describe(openapi.Scope{DeliveryToken: true})
 
 		protected := v1.Group("")
 		protected.Use(middleware.AuthMiddleware(a.cfg.JWTSecret))
@@ -1336,10 +2042,31 @@ func (a *Application) initRouter() error {
 			protected.DELETE("/comments/:id", a.handlers.Comment.Delete)
 
 			protected.GET("/profile", a.handlers.Auth.GetProfile)
+			protected.GET("/profile/api-usage", a.handlers.APIQuota.ProfileUsage)
+			protected.GET("/profile/digest", a.handlers.Digest.Get)
+			protected.PUT("/profile/digest", a.handlers.Digest.Update)
+			protected.GET("/profile/notifications", a.handlers.Notification.Preferences)
+			protected.PUT("/profile/notifications", a.handlers.Notification.UpdatePreferences)
+			protected.GET("/profile/identities", a.handlers.Auth.Identities)
+			protected.POST("/auth/oauth/:provider/link", a.handlers.Auth.OAuthLink)
+			protected.DELETE("/auth/oauth/:provider", a.handlers.Auth.OAuthUnlink)
+			protected.GET("/profile/two-factor", a.handlers.Auth.TwoFactorStatus)
+			protected.POST("/profile/two-factor", a.handlers.Auth.EnrollTwoFactor)
+			protected.POST("/profile/two-factor/confirm", a.handlers.Auth.ConfirmTwoFactor)
+			protected.POST("/profile/two-factor/recovery-codes", a.handlers.Auth.RegenerateRecoveryCodes)
+			protected.DELETE("/profile/two-factor", a.handlers.Auth.DisableTwoFactor)
+			protected.POST("/profile/export", a.handlers.AccountData.RequestExport)
+			protected.GET("/profile/exports", a.handlers.AccountData.ListExports)
+			protected.GET("/profile/exports/:id/download", a.handlers.AccountData.DownloadExport)
+			protected.GET("/notifications", a.handlers.Notification.List)
+			protected.POST("/notifications/read", a.handlers.Notification.MarkAllRead)
+			protected.POST("/notifications/:id/read", a.handlers.Notification.MarkRead)
+			protected.POST("/announcements/:id/dismiss", a.handlers.Announcement.Dismiss)
 			protected.PUT("/profile", a.handlers.Auth.UpdateProfile)
+			protected.DELETE("/profile", a.handlers.AccountData.DeleteAccount)
 			protected.POST("/profile/avatar", middleware.UploadRateLimitMiddleware(a.cfg), a.handlers.Auth.UploadAvatar)
 			protected.PUT("/profile/password", a.handlers.Auth.ChangePassword)
-			protected.POST("/courses/checkout", a.handlers.CourseCheckout.CreateSession)
+			protected.POST("/courses/checkout", idempotent, a.handlers.CourseCheckout.CreateSession)
 			protected.POST("/courses/checkout/verify", a.handlers.CourseCheckout.VerifySession)
 			protected.GET("/courses/packages/:id", a.handlers.CoursePackage.GetForUser)
 			protected.GET("/courses/tests/:id", a.handlers.CourseTest.Get)
@@ -1354,28 +2081,37 @@ func (a *Application) initRouter() error {
 			protected.DELETE("/forum/answers/:id", a.handlers.ForumAnswer.Delete)
 			protected.POST("/forum/answers/:id/vote", a.handlers.ForumAnswer.Vote)
 		}
+		apiDocs.Describe(openapi.Scope{Auth: true})
 
 		admin := v1.Group("/admin")
 		admin.Use(middleware.AuthMiddleware(a.cfg.JWTSecret))
+		admin.Use(middleware.AuditLogMiddleware(a.services.AuditLog))
 
 		content := admin.Group("")
 		content.Use(middleware.RequirePermissions(authorization.PermissionManageAllContent))
 		{
-			content.POST("/posts", a.handlers.Post.Create)
+			content.POST("/posts", idempotent, a.handlers.Post.Create)
 			content.PUT("/posts/:id", a.handlers.Post.Update)
 			content.DELETE("/posts/:id", a.handlers.Post.Delete)
 			content.GET("/posts", a.handlers.Post.GetAllAdmin)
 			content.GET("/posts/:id/analytics", a.handlers.Post.GetAnalytics)
 
-			content.POST("/pages", a.handlers.Page.Create)
+			content.POST("/pages", idempotent, a.handlers.Page.Create)
 			content.PUT("/pages/:id", a.handlers.Page.Update)
 			content.DELETE("/pages/:id", a.handlers.Page.Delete)
 			content.GET("/pages", a.handlers.Page.GetAllAdmin)
 			content.POST("/pages/sections/padding", a.handlers.Page.UpdateAllSectionPadding)
 
+			content.POST("/bulk", idempotent, a.handlers.Bulk.Execute)
+
 			// Enhanced page builder endpoints
 			content.GET("/pages/:id/builder", a.handlers.PageBuilder.GetPageBuilder)
 			content.POST("/pages/:id/duplicate", a.handlers.PageBuilder.DuplicatePage)
+			content.GET("/pages/:id/revisions", a.handlers.Revision.ListPages)
+			content.GET("/posts/:id/revisions", a.handlers.Revision.ListPosts)
+			content.GET("/revisions/:id", a.handlers.Revision.Get)
+			content.GET("/revisions/:id/diff", a.handlers.Revision.Diff)
+			content.POST("/revisions/:id/restore", a.handlers.Revision.Restore)
 			content.POST("/pages/:id/sections/reorder", a.handlers.PageBuilder.ReorderSections)
 			content.POST("/pages/:id/sections", a.handlers.PageBuilder.AddSection)
 			content.PUT("/pages/:id/sections/:sectionId", a.handlers.PageBuilder.UpdateSection)
@@ -1391,16 +2127,27 @@ func (a *Application) initRouter() error {
 			uploads := content.Group("")
 			uploads.Use(middleware.UploadRateLimitMiddleware(a.cfg))
 			{
-				uploads.POST("/upload", a.handlers.Upload.Upload)
+				uploads.POST("/upload", idempotent, a.handlers.Upload.Upload)
+				uploads.POST("/uploads/direct", idempotent, a.handlers.Upload.PresignDirect)
 			}
 			content.GET("/uploads", a.handlers.Upload.List)
 			content.DELETE("/uploads", a.handlers.Upload.Delete)
 			content.PUT("/uploads/rename", a.handlers.Upload.Rename)
+			content.PUT("/uploads/metadata", a.handlers.Upload.UpdateMetadata)
+			content.POST("/uploads/direct/complete", a.handlers.Upload.CompleteDirect)
+			content.GET("/accessibility/images", a.handlers.Accessibility.ImageReport)
+			content.POST("/embeds/resolve", a.handlers.Embed.Resolve)
+			content.GET("/seo/audit", a.handlers.SEO.Audit)
+			content.GET("/translations/status", a.handlers.SEO.TranslationStatus)
+			content.POST("/translations/suggest", a.handlers.SEO.SuggestTranslation)
+			content.GET("/translations/export", a.handlers.SEO.ExportTranslations)
+			content.POST("/translations/import", a.handlers.SEO.ImportTranslations)
 
 			content.POST("/categories", a.handlers.Category.Create)
 			content.PUT("/categories/:id", a.handlers.Category.Update)
 			content.DELETE("/categories/:id", a.handlers.Category.Delete)
 
+			content.GET("/forum/questions", a.handlers.ForumQuestion.ListAdmin)
 			content.GET("/forum/categories", a.handlers.ForumCategory.List)
 			content.GET("/forum/categories/:id", a.handlers.ForumCategory.GetByID)
 			content.POST("/forum/categories", a.handlers.ForumCategory.Create)
@@ -1433,11 +2180,16 @@ func (a *Application) initRouter() error {
 			content.DELETE("/courses/tests/:id", a.handlers.CourseTest.Delete)
 			content.GET("/courses/tests", a.handlers.CourseTest.List)
 			content.GET("/courses/tests/:id", a.handlers.CourseTest.Get)
+			content.GET("/courses/tests/:id/results", a.handlers.CourseTest.ListResults)
 
 			content.POST("/courses/packages", a.handlers.CoursePackage.Create)
 			content.PUT("/courses/packages/:id", a.handlers.CoursePackage.Update)
 			content.PUT("/courses/packages/:id/topics", a.handlers.CoursePackage.UpdateTopics)
-			content.POST("/courses/packages/:id/grants", a.handlers.CoursePackage.GrantToUser)
+			content.GET("/courses/packages/:id/grants", a.handlers.CoursePackage.ListGrants)
+			content.POST("/courses/packages/:id/grants", idempotent, a.handlers.CoursePackage.GrantToUser)
+			content.GET("/courses/packages/:id/announcements", a.handlers.CoursePackage.ListAnnouncements)
+			content.POST("/courses/packages/:id/announcements", a.handlers.CoursePackage.CreateAnnouncement)
+			content.DELETE("/courses/announcements/:id", a.handlers.CoursePackage.DeleteAnnouncement)
 			content.DELETE("/courses/packages/:id", a.handlers.CoursePackage.Delete)
 			content.GET("/courses/packages", a.handlers.CoursePackage.List)
 			content.GET("/courses/packages/:id", a.handlers.CoursePackage.Get)
@@ -1454,8 +2206,32 @@ func (a *Application) initRouter() error {
 			content.PUT("/archive/files/:id", a.handlers.ArchiveFile.Update)
 			content.DELETE("/archive/files/:id", a.handlers.ArchiveFile.Delete)
 
+			content.GET("/newsletter/subscribers", a.handlers.NewsletterSubscriber.List)
+			content.POST("/newsletter/subscribers", a.handlers.NewsletterSubscriber.Create)
+			content.PUT("/newsletter/subscribers/:id", a.handlers.NewsletterSubscriber.Update)
+			content.DELETE("/newsletter/subscribers/:id", a.handlers.NewsletterSubscriber.Delete)
+			content.GET("/newsletter/campaigns", a.handlers.NewsletterCampaign.List)
+			content.GET("/newsletter/campaigns/:id", a.handlers.NewsletterCampaign.Get)
+			content.GET("/newsletter/campaigns/:id/preview", a.handlers.NewsletterCampaign.Preview)
+			content.POST("/newsletter/campaigns", a.handlers.NewsletterCampaign.Create)
+			content.PUT("/newsletter/campaigns/:id", a.handlers.NewsletterCampaign.Update)
+			content.DELETE("/newsletter/campaigns/:id", a.handlers.NewsletterCampaign.Delete)
+			content.GET("/events", a.handlers.Event.List)
+			content.GET("/events/:id", a.handlers.Event.Get)
+			content.POST("/events", a.handlers.Event.Create)
+			content.PUT("/events/:id", a.handlers.Event.Update)
+			content.DELETE("/events/:id", a.handlers.Event.Delete)
+			content.GET("/products", a.handlers.Product.List)
+			content.GET("/products/orders", a.handlers.Product.ListOrders)
+			content.POST("/products/orders/:id/resend", a.handlers.Product.ResendDownload)
+			content.GET("/products/:id", a.handlers.Product.Get)
+			content.POST("/products", a.handlers.Product.Create)
+			content.PUT("/products/:id", a.handlers.Product.Update)
+			content.DELETE("/products/:id", a.handlers.Product.Delete)
+
 			content.DELETE("/tags/:id", a.handlers.Post.DeleteTag)
 		}
+		apiDocs.Describe(adminScope(authorization.PermissionManageAllContent))
 
 		publish := admin.Group("")
 		publish.Use(middleware.RequirePermissions(authorization.PermissionPublishContent))
@@ -1464,7 +2240,9 @@ func (a *Application) initRouter() error {
 			publish.PUT("/posts/:id/unpublish", a.handlers.Post.UnpublishPost)
 			publish.PUT("/pages/:id/publish", a.handlers.Page.PublishPage)
 			publish.PUT("/pages/:id/unpublish", a.handlers.Page.UnpublishPage)
+			publish.POST("/newsletter/campaigns/:id/send", a.handlers.NewsletterCampaign.Send)
 		}
+		apiDocs.Describe(adminScope(authorization.PermissionPublishContent))
 
 		users := admin.Group("")
 		users.Use(middleware.RequirePermissions(authorization.PermissionManageUsers))
@@ -1474,7 +2252,11 @@ func (a *Application) initRouter() error {
 			users.DELETE("/users/:id", a.handlers.Auth.DeleteUser)
 			users.PUT("/users/:id/role", a.handlers.Auth.UpdateUserRole)
 			users.PUT("/users/:id/status", a.handlers.Auth.UpdateUserStatus)
+			users.DELETE("/users/:id/two-factor", a.handlers.Auth.ResetUserTwoFactor)
+			users.GET("/settings/two-factor", a.handlers.Auth.GetTwoFactorSettings)
+			users.PUT("/settings/two-factor", a.handlers.Auth.UpdateTwoFactorSettings)
 		}
+		apiDocs.Describe(adminScope(authorization.PermissionManageUsers))
 
 		comments := admin.Group("")
 		comments.Use(middleware.RequirePermissions(authorization.PermissionModerateComments))
@@ -1484,6 +2266,7 @@ func (a *Application) initRouter() error {
 			comments.PUT("/comments/:id/approve", a.handlers.Comment.ApproveComment)
 			comments.PUT("/comments/:id/reject", a.handlers.Comment.RejectComment)
 		}
+		apiDocs.Describe(adminScope(authorization.PermissionModerateComments))
 
 		settings := admin.Group("")
 		settings.Use(middleware.RequirePermissions(authorization.PermissionManageSettings))
@@ -1493,6 +2276,17 @@ func (a *Application) initRouter() error {
 			settings.GET("/settings/email", a.handlers.Setup.GetEmailSettings)
 			settings.PUT("/settings/email", a.handlers.Setup.UpdateEmailSettings)
 			settings.POST("/settings/email/test", a.handlers.Setup.TestEmailSettings)
+			settings.GET("/email/deliveries", a.handlers.EmailDelivery.List)
+			settings.POST("/email/deliveries/:id/resend", a.handlers.EmailDelivery.Resend)
+			settings.GET("/email/suppressions", a.handlers.EmailDelivery.Suppressions)
+			settings.POST("/email/suppressions", a.handlers.EmailDelivery.Suppress)
+			settings.DELETE("/email/suppressions/:id", a.handlers.EmailDelivery.Unsuppress)
+			settings.GET("/scheduler/jobs", a.handlers.Scheduler.ListJobs)
+			settings.GET("/diagnostics/slow-queries", a.handlers.Diagnostics.SlowQueries)
+			settings.GET("/settings/logging", handlers.GetLoggingSettings)
+			settings.PUT("/settings/logging", handlers.UpdateLoggingSettings)
+			settings.GET("/uploads/orphans", a.handlers.UploadGC.Preview)
+			settings.POST("/uploads/orphans/collect", a.handlers.UploadGC.Collect)
 			settings.GET("/settings/homepage", a.handlers.Homepage.Get)
 			settings.PUT("/settings/homepage", a.handlers.Homepage.Update)
 
@@ -1507,6 +2301,56 @@ func (a *Application) initRouter() error {
 			settings.GET("/settings/advertising", a.handlers.Advertising.Get)
 			settings.PUT("/settings/advertising", a.handlers.Advertising.Update)
 
+			settings.GET("/settings/rate-limits", a.handlers.RateLimit.Get)
+			settings.PUT("/settings/rate-limits", a.handlers.RateLimit.Update)
+			settings.GET("/block-rules", a.handlers.BlockRule.List)
+			settings.POST("/block-rules", a.handlers.BlockRule.Create)
+			settings.DELETE("/block-rules/:id", a.handlers.BlockRule.Delete)
+			settings.GET("/settings/oauth", a.handlers.Auth.GetOAuthSettings)
+			settings.PUT("/settings/oauth", a.handlers.Auth.UpdateOAuthSettings)
+			settings.GET("/settings/account-deletion", a.handlers.AccountData.GetSettings)
+			settings.PUT("/settings/account-deletion", a.handlers.AccountData.UpdateSettings)
+			settings.GET("/account-deletions", a.handlers.AccountData.ListDeletionRequests)
+			settings.POST("/account-deletions/:id/approve", a.handlers.AccountData.ApproveDeletion)
+			settings.POST("/account-deletions/:id/reject", a.handlers.AccountData.RejectDeletion)
+			settings.GET("/settings/api-quotas", a.handlers.APIQuota.Get)
+			settings.PUT("/settings/api-quotas", a.handlers.APIQuota.Update)
+			settings.GET("/api-usage", a.handlers.APIQuota.Usage)
+
+			settings.GET("/settings/headless", a.handlers.Headless.Get)
+			settings.PUT("/settings/headless", a.handlers.Headless.Update)
+			settings.POST("/settings/headless/build", a.handlers.Headless.TriggerBuild)
+			settings.GET("/settings/alerts", a.handlers.Alert.Get)
+			settings.PUT("/settings/alerts", a.handlers.Alert.Update)
+			settings.POST("/settings/alerts/test", a.handlers.Alert.Test)
+			settings.GET("/settings/consent", a.handlers.Consent.GetSettings)
+			settings.PUT("/settings/consent", a.handlers.Consent.UpdateSettings)
+			settings.GET("/consent-records", a.handlers.Consent.Records)
+			settings.GET("/settings/revisions", a.handlers.Revision.GetSettings)
+			settings.PUT("/settings/revisions", a.handlers.Revision.UpdateSettings)
+			settings.GET("/settings/sanitization", a.handlers.Sanitization.GetSettings)
+			settings.PUT("/settings/sanitization", a.handlers.Sanitization.UpdateSettings)
+			settings.GET("/settings/csp", a.handlers.CSP.Get)
+			settings.PUT("/settings/csp", a.handlers.CSP.Update)
+			settings.POST("/settings/csp/preview", a.handlers.CSP.Preview)
+			settings.GET("/settings/export", a.handlers.SettingsExport.Export)
+			settings.PUT("/settings/export", a.handlers.SettingsExport.Import)
+
+			settings.GET("/audit-logs", a.handlers.AuditLog.List)
+			settings.GET("/audit-logs/:id", a.handlers.AuditLog.Get)
+			settings.GET("/settings/audit-log", a.handlers.AuditLog.GetSettings)
+			settings.PUT("/settings/audit-log", a.handlers.AuditLog.UpdateSettings)
+
+			settings.GET("/status/incidents", a.handlers.Status.ListIncidents)
+			settings.POST("/status/incidents", a.handlers.Status.CreateIncident)
+			settings.PUT("/status/incidents/:id", a.handlers.Status.UpdateIncident)
+			settings.DELETE("/status/incidents/:id", a.handlers.Status.DeleteIncident)
+
+			settings.GET("/announcements", a.handlers.Announcement.List)
+			settings.POST("/announcements", a.handlers.Announcement.Create)
+			settings.PUT("/announcements/:id", a.handlers.Announcement.Update)
+			settings.DELETE("/announcements/:id", a.handlers.Announcement.Delete)
+
 			settings.GET("/social-links", a.handlers.SocialLink.List)
 			settings.POST("/social-links", a.handlers.SocialLink.Create)
 			settings.PUT("/social-links/:id", a.handlers.SocialLink.Update)
@@ -1524,20 +2368,29 @@ func (a *Application) initRouter() error {
 			settings.PUT("/menu-items/:id", a.handlers.Menu.Update)
 			settings.DELETE("/menu-items/:id", a.handlers.Menu.Delete)
 
-			settings.GET("/stats", handlers.GetStatistics(a.db))
+			settings.GET("/stats", handlers.GetStatistics(a.services.Stats))
+			settings.GET("/live", a.handlers.LiveUpdate.Stream)
 
 			if a.cache != nil {
 				settings.DELETE("/cache", handlers.ClearCache(a.cache))
 			}
 		}
+		apiDocs.Describe(adminScope(authorization.PermissionManageSettings))
 
 		themes := admin.Group("")
 		themes.Use(middleware.RequirePermissions(authorization.PermissionManageThemes))
 		{
 			themes.GET("/themes", a.handlers.Theme.List)
+			themes.GET("/themes/settings/export", a.handlers.Theme.ExportSettings)
+			themes.POST("/themes/settings/import", a.handlers.Theme.ImportSettings)
 			themes.PUT("/themes/:slug/activate", a.handlers.Theme.Activate)
 			themes.PUT("/themes/:slug/reload", a.handlers.Theme.Reload)
+			themes.GET("/themes/:slug/templates", a.handlers.Theme.ListTemplates)
+			themes.GET("/themes/:slug/templates/:name", a.handlers.Theme.GetTemplate)
+			themes.PUT("/themes/:slug/templates/:name", a.handlers.Theme.UpdateTemplate)
+			themes.DELETE("/themes/:slug/templates/:name", a.handlers.Theme.DeleteTemplate)
 		}
+		apiDocs.Describe(adminScope(authorization.PermissionManageThemes))
 
 		plugins := admin.Group("")
 		plugins.Use(middleware.RequirePermissions(authorization.PermissionManagePlugins))
@@ -1547,22 +2400,70 @@ func (a *Application) initRouter() error {
 			plugins.PUT("/plugins/:slug/activate", a.handlers.Plugin.Activate)
 			plugins.PUT("/plugins/:slug/deactivate", a.handlers.Plugin.Deactivate)
 			plugins.DELETE("/plugins/:slug", a.handlers.Plugin.Delete)
+			plugins.GET("/plugins/:slug/settings", a.handlers.Plugin.GetSettings)
+			plugins.PUT("/plugins/:slug/settings", a.handlers.Plugin.UpdateSettings)
+		}
+		apiDocs.Describe(adminScope(authorization.PermissionManagePlugins))
+
+		integrations := admin.Group("")
+		integrations.Use(middleware.RequirePermissions(authorization.PermissionManageIntegrations))
+		{
+			integrations.GET("/webhooks", a.handlers.Webhook.List)
+			integrations.POST("/webhooks", a.handlers.Webhook.Create)
+			integrations.PUT("/webhooks/:id", a.handlers.Webhook.Update)
+			integrations.DELETE("/webhooks/:id", a.handlers.Webhook.Delete)
+			integrations.POST("/webhooks/:id/rotate-secret", a.handlers.Webhook.RotateSecret)
+			integrations.GET("/webhooks/:id/deliveries", a.handlers.Webhook.Deliveries)
+			integrations.POST("/webhooks/:id/deliveries/:deliveryId/redeliver", a.handlers.Webhook.Redeliver)
+			integrations.GET("/delivery-tokens", a.handlers.DeliveryToken.List)
+			integrations.POST("/delivery-tokens", a.handlers.DeliveryToken.Create)
+			integrations.PUT("/delivery-tokens/:id", a.handlers.DeliveryToken.Update)
+			integrations.DELETE("/delivery-tokens/:id", a.handlers.DeliveryToken.Delete)
+			integrations.GET("/social/accounts", a.handlers.SocialShare.List)
+			integrations.POST("/social/accounts", a.handlers.SocialShare.Create)
+			integrations.PUT("/social/accounts/:id", a.handlers.SocialShare.Update)
+			integrations.DELETE("/social/accounts/:id", a.handlers.SocialShare.Delete)
+			integrations.POST("/social/accounts/:id/shares", a.handlers.SocialShare.Share)
+			integrations.GET("/social/shares", a.handlers.SocialShare.Shares)
+			if a.cfg.EnableOpenAPI && a.cfg.EnableSwaggerUI {
+				integrations.GET("/docs", apiDocsHandler.SwaggerUI)
+			}
 		}
+		apiDocs.Describe(adminScope(authorization.PermissionManageIntegrations))
 
 		backups := admin.Group("")
 		backups.Use(middleware.RequirePermissions(authorization.PermissionManageBackups))
 		{
+			backups.GET("/backups", a.handlers.Backup.List)
+			backups.DELETE("/backups/archives/:location/:name", a.handlers.Backup.Delete)
 			backups.GET("/backups/settings", a.handlers.Backup.GetSettings)
 			backups.PUT("/backups/settings", a.handlers.Backup.UpdateSettings)
+			backups.GET("/backups/restores", a.handlers.Backup.ListRestores)
+			backups.GET("/backups/restores/:id", a.handlers.Backup.GetRestore)
 
 			// Backup export/import operations with rate limiting
 			backupOps := backups.Group("")
 			backupOps.Use(middleware.BackupRateLimitMiddleware(a.cfg))
 			{
 				backupOps.GET("/backups/export", a.handlers.Backup.Export)
+				backupOps.POST("/backups/verify", a.handlers.Backup.Verify)
+				backupOps.GET("/backups/archives/:location/:name", a.handlers.Backup.Download)
 				backupOps.POST("/backups/import", a.handlers.Backup.Import)
+				backupOps.GET("/backups/site-export", a.handlers.Backup.ExportSite)
+				backupOps.POST("/backups/site-import", a.handlers.Backup.ImportSite)
 			}
 		}
+		apiDocs.Describe(adminScope(authorization.PermissionManageBackups))
+	}
+
+	// v2 holds the routes whose responses changed shape. Until they have handlers
+	// of their own, the v1 handlers serve them through a compatibility layer.
+	v2 := router.Group("/api/v2")
+	v2.Use(middleware.NoIndexMiddleware())
+	{
+		v2.GET("/posts", middleware.V2List("posts"), a.handlers.Post.GetAll)
+		v2.GET("/tags/:slug/posts", middleware.V2List("posts"), a.handlers.Post.GetPostsByTag)
+		v2.GET("/forum/questions", middleware.V2List("questions"), a.handlers.ForumQuestion.List)
 	}
 
 	router.NoRoute(func(c *gin.Context) {
@@ -1575,6 +2476,14 @@ func (a *Application) initRouter() error {
 			return
 		}
 
+		if a.redispatchLanguagePrefix(c) {
+			return
+		}
+
+		if middleware.HandleHeadless(c, a.services.Headless) {
+			return
+		}
+
 		if a.templateHandler != nil {
 			if a.templateHandler.TryRenderPage(c) {
 				return
@@ -1593,6 +2502,42 @@ func (a *Application) initRouter() error {
 	return nil
 }
 
+// redispatchLanguagePrefix serves translated content under /<language>/... by
+// routing the request again without the prefix. The language is kept on the request
+// context, where the language middleware and the template handler pick it up.
+func (a *Application) redispatchLanguagePrefix(c *gin.Context) bool {
+	languageService := a.services.Language
+	if languageService == nil || a.router == nil || lang.PathLanguage(c.Request.Context()) != "" {
+		return false
+	}
+
+	defaultLanguage, supported, err := languageService.Resolve("", nil)
+	if err != nil {
+		logger.Error(err, "Failed to resolve site languages", nil)
+	}
+	code, rest := lang.SplitPathPrefix(c.Request.URL.Path, defaultLanguage, supported)
+	if code == "" {
+		return false
+	}
+
+	c.Request = c.Request.WithContext(lang.WithPathLanguage(c.Request.Context(), code))
+	c.Request.URL.Path, c.Request.URL.RawPath = rest, ""
+	a.router.HandleContext(c)
+	return true
+}
+
+// serveThemeAsset serves static files of any installed theme so pages assigned to a
+// theme other than the active one can load their own stylesheets and scripts.
+func (a *Application) serveThemeAsset(c *gin.Context) {
+	themeValue, ok := a.themeManager.Resolve(c.Param("theme"))
+	if !ok {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	middleware.ServeFile(c, themeValue.StaticFS(), c.Param("filepath"))
+}
+
 func (a *Application) serveUpload(c *gin.Context) {
 	path := strings.TrimPrefix(strings.TrimSpace(c.Param("filepath")), "/")
 	if path == "" {
@@ -1624,7 +2569,7 @@ func (a *Application) serveUpload(c *gin.Context) {
 		return
 	}
 
-	c.File(absTarget)
+	middleware.ServeFile(c, http.Dir(absRoot), filepath.ToSlash(cleanPath))
 }
 
 func (a *Application) initPluginRuntime() error {
@@ -1632,7 +2577,8 @@ func (a *Application) initPluginRuntime() error {
 		a.pluginRuntime = pluginruntime.New()
 	}
 
-	for slug, factory := range pluginregistry.All() {
+	factories := pluginregistry.All()
+	for slug, factory := range factories {
 		feature, err := factory(a)
 		if err != nil {
 			logger.Error(err, "Failed to initialize plugin feature", map[string]interface{}{"slug": slug})
@@ -1641,6 +2587,28 @@ func (a *Application) initPluginRuntime() error {
 		a.pluginRuntime.Register(slug, feature)
 	}
 
+	// Plugins without a compiled-in feature may declare an out-of-process runtime.
+	a.externalPlugins = external.NewHost(a.templateHandler, a.events)
+	if a.cfg.PluginUID >= 0 || a.cfg.PluginGID >= 0 {
+		if err := a.externalPlugins.SetUser(a.cfg.PluginUID, a.cfg.PluginGID); err != nil {
+			logger.Error(err, "Invalid plugin user; out-of-process plugins are disabled", nil)
+		}
+	}
+	if a.services.Plugin != nil {
+		a.services.Plugin.SetExternalFeatureFactory(a.externalPlugins.Feature)
+	}
+	if a.pluginManager != nil {
+		for _, entry := range a.pluginManager.List() {
+			if entry == nil || !entry.Metadata.IsExternal() {
+				continue
+			}
+			if _, builtin := factories[entry.Slug]; builtin {
+				continue
+			}
+			a.pluginRuntime.Register(entry.Slug, a.externalPlugins.Feature(entry))
+		}
+	}
+
 	if a.services.Plugin != nil {
 		if err := a.services.Plugin.ApplyRuntimeState(); err != nil {
 			return err
@@ -1650,9 +2618,44 @@ func (a *Application) initPluginRuntime() error {
 	return nil
 }
 
+// registerBulkResources makes pages, menu items and, while the blog plugin is
+// active, posts available to the bulk API.
+func (a *Application) registerBulkResources() {
+	a.services.Bulk.Register("pages", func() service.BulkResource {
+		return a.services.Page.BulkResource()
+	})
+	a.services.Bulk.Register("menu_items", func() service.BulkResource {
+		return a.services.Menu.BulkResource()
+	})
+	a.services.Bulk.Register("posts", func() service.BulkResource {
+		return service.NewPostBulkResource(a.services.Post)
+	})
+}
+
+// adminScope documents an admin route group guarded by RequirePermissions(perms...).
+func adminScope(perms ...authorization.Permission) openapi.Scope {
+	scope := openapi.Scope{Auth: true}
+	for _, perm := range perms {
+		scope.Permissions = append(scope.Permissions, string(perm))
+	}
+	return scope
+}
+
 func (a *Application) metricsHandler() gin.HandlerFunc {
 	promHandler := promhttp.Handler()
+	return func(c *gin.Context) {
+		if !a.cfg.EnableMetrics {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		promHandler.ServeHTTP(c.Writer, c.Request)
+	}
+}
 
+// metricsAccess admits requests from METRICS_ALLOWED_IPS or with the metrics basic
+// auth credentials; with neither configured, only loopback clients are admitted.
+// It guards /metrics and the /debug profiling endpoints.
+func (a *Application) metricsAccess() gin.HandlerFunc {
 	allowedExact := make(map[string]struct{})
 	var allowedNetworks []*net.IPNet
 
@@ -1691,26 +2694,19 @@ func (a *Application) metricsHandler() gin.HandlerFunc {
 	ipConfigured := len(allowedExact) > 0 || len(allowedNetworks) > 0
 
 	return func(c *gin.Context) {
-		if !a.cfg.EnableMetrics {
-			c.AbortWithStatus(http.StatusNotFound)
-			return
-		}
-
 		clientIPStr := c.ClientIP()
 		clientIP := net.ParseIP(clientIPStr)
 
 		if ipConfigured {
 			if clientIP != nil {
 				if _, ok := allowedExact[clientIP.String()]; ok {
-					promHandler.ServeHTTP(c.Writer, c.Request)
-					c.Abort()
+					c.Next()
 					return
 				}
 
 				for _, network := range allowedNetworks {
 					if network.Contains(clientIP) {
-						promHandler.ServeHTTP(c.Writer, c.Request)
-						c.Abort()
+						c.Next()
 						return
 					}
 				}
@@ -1726,8 +2722,7 @@ func (a *Application) metricsHandler() gin.HandlerFunc {
 			username, password, ok := c.Request.BasicAuth()
 			if ok && subtle.ConstantTimeCompare([]byte(username), []byte(authUser)) == 1 &&
 				subtle.ConstantTimeCompare([]byte(password), []byte(authPassword)) == 1 {
-				promHandler.ServeHTTP(c.Writer, c.Request)
-				c.Abort()
+				c.Next()
 				return
 			}
 
@@ -1737,8 +2732,7 @@ func (a *Application) metricsHandler() gin.HandlerFunc {
 		}
 
 		if clientIP != nil && clientIP.IsLoopback() {
-			promHandler.ServeHTTP(c.Writer, c.Request)
-			c.Abort()
+			c.Next()
 			return
 		}
 
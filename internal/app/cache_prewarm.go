@@ -0,0 +1,127 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"constructor-script-backend/internal/background"
+	"constructor-script-backend/internal/events"
+	"constructor-script-backend/pkg/logger"
+)
+
+const (
+	cachePrewarmJob = "cache_prewarm"
+	// cachePrewarmDelay collects the invalidations of a bulk edit into one prewarm.
+	cachePrewarmDelay = 5 * time.Second
+)
+
+// cachePrewarmPaths are the pages that are expensive to build and visited first
+// after a publish.
+var cachePrewarmPaths = []string{"/", "/blog", "/sitemap.xml"}
+
+// cachePrewarmEvents invalidate the cached data behind cachePrewarmPaths.
+var cachePrewarmEvents = []string{
+	events.PostPublished,
+	events.PostUpdated,
+	events.PostDeleted,
+	events.PageCreated,
+	events.PageUpdated,
+	events.PageDeleted,
+}
+
+// initCachePrewarm renders the expensive pages again shortly after content changes,
+// and on CachePrewarmSchedule, so that the caches they read from are filled before
+// a visitor needs them.
+func (a *Application) initCachePrewarm() {
+	if !a.cfg.EnableCache || a.scheduler == nil || a.router == nil {
+		return
+	}
+
+	for _, name := range cachePrewarmEvents {
+		a.events.Subscribe(name, func(context.Context, events.Event) {
+			a.schedulePrewarm()
+		})
+	}
+
+	if a.cfg.CachePrewarmSchedule == "" {
+		return
+	}
+	err := a.scheduler.ScheduleRecurring(background.RecurringJob{
+		Name:     cachePrewarmJob,
+		Schedule: a.cfg.CachePrewarmSchedule,
+		Timeout:  2 * time.Minute,
+		Run:      a.prewarmCaches,
+	})
+	if err != nil {
+		logger.Error(err, "Failed to schedule cache refresh", nil)
+	}
+}
+
+func (a *Application) schedulePrewarm() {
+	err := a.scheduler.ScheduleUnique(background.Job{
+		Name:    cachePrewarmJob,
+		Delay:   cachePrewarmDelay,
+		Timeout: 2 * time.Minute,
+		Run:     a.prewarmCaches,
+	})
+	if err != nil && !errors.Is(err, background.ErrJobAlreadyScheduled) {
+		logger.Error(err, "Failed to schedule cache prewarm", nil)
+	}
+}
+
+// prewarmCaches requests each page through the router as an anonymous visitor on
+// a loopback host, which the canonical host redirect leaves alone.
+func (a *Application) prewarmCaches(ctx context.Context) error {
+	if enabled, _ := a.services.Headless.HeadlessMode(); enabled {
+		return nil
+	}
+
+	var failed []string
+	for _, path := range cachePrewarmPaths {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost"+path, nil)
+		if err != nil {
+			return err
+		}
+		req.RemoteAddr = "127.0.0.1:0"
+
+		writer := &prewarmResponseWriter{header: make(http.Header)}
+		a.router.ServeHTTP(writer, req)
+		if writer.status >= http.StatusInternalServerError {
+			failed = append(failed, fmt.Sprintf("%s (%d)", path, writer.status))
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("prewarm failed for %v", failed)
+	}
+	logger.Debug("Prewarmed page caches", map[string]interface{}{"paths": cachePrewarmPaths})
+	return nil
+}
+
+// prewarmResponseWriter discards the body; only the work of building it matters.
+type prewarmResponseWriter struct {
+	header http.Header
+	status int
+}
+
+func (w *prewarmResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *prewarmResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *prewarmResponseWriter) Write(data []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return len(data), nil
+}
@@ -0,0 +1,36 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestPrewarmCachesRequestsEachPage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	var visited []string
+	for _, path := range cachePrewarmPaths {
+		path := path
+		router.GET(path, func(c *gin.Context) {
+			visited = append(visited, path+"@"+c.Request.Host)
+			if path == "/sitemap.xml" {
+				c.Status(http.StatusInternalServerError)
+				return
+			}
+			c.String(http.StatusOK, "ok")
+		})
+	}
+
+	a := &Application{router: router}
+	err := a.prewarmCaches(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "/sitemap.xml (500)") {
+		t.Fatalf("expected the failing page to be reported, got %v", err)
+	}
+	if len(visited) != len(cachePrewarmPaths) || visited[0] != "/@localhost" {
+		t.Fatalf("unexpected requests %v", visited)
+	}
+}
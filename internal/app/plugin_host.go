@@ -6,6 +6,7 @@ import (
 
 	"constructor-script-backend/internal/background"
 	"constructor-script-backend/internal/config"
+	"constructor-script-backend/internal/events"
 	"constructor-script-backend/internal/handlers"
 	"constructor-script-backend/internal/plugin/host"
 	"constructor-script-backend/internal/repository"
@@ -21,10 +22,21 @@ import (
 	courseapi "constructor-script-backend/plugins/courses/api"
 	coursehandlers "constructor-script-backend/plugins/courses/handlers"
 	courseservice "constructor-script-backend/plugins/courses/service"
+	eventsapi "constructor-script-backend/plugins/events/api"
+	eventhandlers "constructor-script-backend/plugins/events/handlers"
+	eventservice "constructor-script-backend/plugins/events/service"
 	forumapi "constructor-script-backend/plugins/forum/api"
 	forumhandlers "constructor-script-backend/plugins/forum/handlers"
 	forumservice "constructor-script-backend/plugins/forum/service"
 	languageservice "constructor-script-backend/plugins/language/service"
+	newsletterapi "constructor-script-backend/plugins/newsletter/api"
+	newsletterhandlers "constructor-script-backend/plugins/newsletter/handlers"
+	newsletterservice "constructor-script-backend/plugins/newsletter/service"
+	productsapi "constructor-script-backend/plugins/products/api"
+	producthandlers "constructor-script-backend/plugins/products/handlers"
+	productservice "constructor-script-backend/plugins/products/service"
+
+	"gorm.io/gorm"
 )
 
 type applicationRepositoryAccess struct {
@@ -182,6 +194,13 @@ func (a *Application) Config() *config.Config {
 	return a.cfg
 }
 
+func (a *Application) Database() *gorm.DB {
+	if a == nil {
+		return nil
+	}
+	return a.db
+}
+
 func (a *Application) Cache() *cache.Cache {
 	if a == nil {
 		return nil
@@ -196,6 +215,13 @@ func (a *Application) Scheduler() *background.Scheduler {
 	return a.scheduler
 }
 
+func (a *Application) Events() *events.Bus {
+	if a == nil {
+		return nil
+	}
+	return a.events
+}
+
 func (a *Application) ThemeManager() *theme.Manager {
 	if a == nil {
 		return nil
@@ -289,6 +315,13 @@ func (r applicationRepositoryAccess) Setting() repository.SettingRepository {
 	return r.app.repositories.Setting
 }
 
+func (r applicationRepositoryAccess) SlugRedirect() repository.SlugRedirectRepository {
+	if r.app == nil {
+		return nil
+	}
+	return r.app.repositories.SlugRedirect
+}
+
 func (r applicationRepositoryAccess) User() repository.UserRepository {
 	if r.app == nil {
 		return nil
@@ -331,6 +364,13 @@ func (r applicationRepositoryAccess) CoursePackageAccess() repository.CoursePack
 	return r.app.repositories.CoursePackageAccess
 }
 
+func (r applicationRepositoryAccess) CourseAnnouncement() repository.CourseAnnouncementRepository {
+	if r.app == nil {
+		return nil
+	}
+	return r.app.repositories.CourseAnnouncement
+}
+
 func (r applicationRepositoryAccess) CourseTest() repository.CourseTestRepository {
 	if r.app == nil {
 		return nil
@@ -380,6 +420,27 @@ func (r applicationRepositoryAccess) ArchiveFile() repository.ArchiveFileReposit
 	return r.app.repositories.ArchiveFile
 }
 
+func (r applicationRepositoryAccess) Newsletter() repository.NewsletterRepository {
+	if r.app == nil {
+		return nil
+	}
+	return r.app.repositories.Newsletter
+}
+
+func (r applicationRepositoryAccess) Event() repository.EventRepository {
+	if r.app == nil {
+		return nil
+	}
+	return r.app.repositories.Event
+}
+
+func (r applicationRepositoryAccess) Product() repository.ProductRepository {
+	if r.app == nil {
+		return nil
+	}
+	return r.app.repositories.Product
+}
+
 func (r applicationRepositoryAccess) ForumAnswerVote() repository.ForumAnswerVoteRepository {
 	if r.app == nil {
 		return nil
@@ -422,6 +483,13 @@ func (s applicationCoreServices) Menu() *service.MenuService {
 	return s.app.services.Menu
 }
 
+func (s applicationCoreServices) Plugins() *service.PluginService {
+	if s.app == nil {
+		return nil
+	}
+	return s.app.services.Plugin
+}
+
 func (s applicationCoreServices) Upload() *service.UploadService {
 	if s.app == nil {
 		return nil
@@ -429,6 +497,41 @@ func (s applicationCoreServices) Upload() *service.UploadService {
 	return s.app.services.Upload
 }
 
+func (s applicationCoreServices) Email() *service.EmailService {
+	if s.app == nil {
+		return nil
+	}
+	return s.app.services.Email
+}
+
+func (s applicationCoreServices) Payments() *service.PaymentService {
+	if s.app == nil {
+		return nil
+	}
+	return s.app.services.Payment
+}
+
+func (s applicationCoreServices) Digest() *service.DigestService {
+	if s.app == nil {
+		return nil
+	}
+	return s.app.services.Digest
+}
+
+func (s applicationCoreServices) Notification() *service.NotificationService {
+	if s.app == nil {
+		return nil
+	}
+	return s.app.services.Notification
+}
+
+func (s applicationCoreServices) Revision() *service.RevisionService {
+	if s.app == nil {
+		return nil
+	}
+	return s.app.services.Revision
+}
+
 func (s applicationCoreServices) Advertising() *service.AdvertisingService {
 	if s.app == nil {
 		return nil
@@ -811,6 +914,126 @@ func (a *Application) registerPluginServiceBindings() {
 			}
 		},
 	)
+
+	a.pluginBindings.register(
+		registryKindServices,
+		newsletterapi.Namespace,
+		newsletterapi.ServiceSubscriber,
+		func() any {
+			if a == nil {
+				return nil
+			}
+			return a.services.NewsletterSubscriber
+		},
+		func(value any) {
+			if a == nil {
+				return
+			}
+			if value == nil {
+				a.services.NewsletterSubscriber = nil
+				return
+			}
+			if svc, ok := value.(*newsletterservice.SubscriberService); ok {
+				a.services.NewsletterSubscriber = svc
+			}
+		},
+	)
+
+	a.pluginBindings.register(
+		registryKindServices,
+		newsletterapi.Namespace,
+		newsletterapi.ServiceCampaign,
+		func() any {
+			if a == nil {
+				return nil
+			}
+			return a.services.NewsletterCampaign
+		},
+		func(value any) {
+			if a == nil {
+				return
+			}
+			if value == nil {
+				a.services.NewsletterCampaign = nil
+				return
+			}
+			if svc, ok := value.(*newsletterservice.CampaignService); ok {
+				a.services.NewsletterCampaign = svc
+			}
+		},
+	)
+
+	a.pluginBindings.register(
+		registryKindServices,
+		eventsapi.Namespace,
+		eventsapi.ServiceEvent,
+		func() any {
+			if a == nil {
+				return nil
+			}
+			return a.services.Event
+		},
+		func(value any) {
+			if a == nil {
+				return
+			}
+			if value == nil {
+				a.services.Event = nil
+				return
+			}
+			if svc, ok := value.(*eventservice.EventService); ok {
+				a.services.Event = svc
+			}
+		},
+	)
+
+	a.pluginBindings.register(
+		registryKindServices,
+		productsapi.Namespace,
+		productsapi.ServiceProduct,
+		func() any {
+			if a == nil {
+				return nil
+			}
+			return a.services.Product
+		},
+		func(value any) {
+			if a == nil {
+				return
+			}
+			if value == nil {
+				a.services.Product = nil
+				return
+			}
+			if svc, ok := value.(*productservice.ProductService); ok {
+				a.services.Product = svc
+			}
+		},
+	)
+
+	a.pluginBindings.register(
+		registryKindServices,
+		productsapi.Namespace,
+		productsapi.ServiceOrder,
+		func() any {
+			if a == nil {
+				return nil
+			}
+			return a.services.ProductOrder
+		},
+		func(value any) {
+			if a == nil {
+				return
+			}
+			if value == nil {
+				a.services.ProductOrder = nil
+				return
+			}
+			if svc, ok := value.(*productservice.OrderService); ok {
+				a.services.ProductOrder = svc
+			}
+		},
+	)
 }
 
 // registerPluginHandlerBindings configures handler registry adapters for built-in plugins.
@@ -1222,4 +1445,172 @@ func (a *Application) registerPluginHandlerBindings() {
 			}
 		},
 	)
+
+	a.pluginBindings.register(
+		registryKindHandlers,
+		newsletterapi.Namespace,
+		newsletterapi.HandlerSubscriber,
+		func() any {
+			if a == nil {
+				return nil
+			}
+			return a.handlers.NewsletterSubscriber
+		},
+		func(value any) {
+			if a == nil {
+				return
+			}
+			if value == nil {
+				a.handlers.NewsletterSubscriber = nil
+				return
+			}
+			if handler, ok := value.(*newsletterhandlers.SubscriberHandler); ok {
+				a.handlers.NewsletterSubscriber = handler
+			}
+		},
+	)
+
+	a.pluginBindings.register(
+		registryKindHandlers,
+		newsletterapi.Namespace,
+		newsletterapi.HandlerCampaign,
+		func() any {
+			if a == nil {
+				return nil
+			}
+			return a.handlers.NewsletterCampaign
+		},
+		func(value any) {
+			if a == nil {
+				return
+			}
+			if value == nil {
+				a.handlers.NewsletterCampaign = nil
+				return
+			}
+			if handler, ok := value.(*newsletterhandlers.CampaignHandler); ok {
+				a.handlers.NewsletterCampaign = handler
+			}
+		},
+	)
+
+	a.pluginBindings.register(
+		registryKindHandlers,
+		newsletterapi.Namespace,
+		newsletterapi.HandlerPublic,
+		func() any {
+			if a == nil {
+				return nil
+			}
+			return a.handlers.NewsletterPublic
+		},
+		func(value any) {
+			if a == nil {
+				return
+			}
+			if value == nil {
+				a.handlers.NewsletterPublic = nil
+				return
+			}
+			if handler, ok := value.(*newsletterhandlers.PublicHandler); ok {
+				a.handlers.NewsletterPublic = handler
+			}
+		},
+	)
+
+	a.pluginBindings.register(
+		registryKindHandlers,
+		eventsapi.Namespace,
+		eventsapi.HandlerEvent,
+		func() any {
+			if a == nil {
+				return nil
+			}
+			return a.handlers.Event
+		},
+		func(value any) {
+			if a == nil {
+				return
+			}
+			if value == nil {
+				a.handlers.Event = nil
+				return
+			}
+			if handler, ok := value.(*eventhandlers.EventHandler); ok {
+				a.handlers.Event = handler
+			}
+		},
+	)
+
+	a.pluginBindings.register(
+		registryKindHandlers,
+		eventsapi.Namespace,
+		eventsapi.HandlerPublic,
+		func() any {
+			if a == nil {
+				return nil
+			}
+			return a.handlers.EventPublic
+		},
+		func(value any) {
+			if a == nil {
+				return
+			}
+			if value == nil {
+				a.handlers.EventPublic = nil
+				return
+			}
+			if handler, ok := value.(*eventhandlers.PublicHandler); ok {
+				a.handlers.EventPublic = handler
+			}
+		},
+	)
+
+	a.pluginBindings.register(
+		registryKindHandlers,
+		productsapi.Namespace,
+		productsapi.HandlerProduct,
+		func() any {
+			if a == nil {
+				return nil
+			}
+			return a.handlers.Product
+		},
+		func(value any) {
+			if a == nil {
+				return
+			}
+			if value == nil {
+				a.handlers.Product = nil
+				return
+			}
+			if handler, ok := value.(*producthandlers.ProductHandler); ok {
+				a.handlers.Product = handler
+			}
+		},
+	)
+
+	a.pluginBindings.register(
+		registryKindHandlers,
+		productsapi.Namespace,
+		productsapi.HandlerPublic,
+		func() any {
+			if a == nil {
+				return nil
+			}
+			return a.handlers.ProductPublic
+		},
+		func(value any) {
+			if a == nil {
+				return
+			}
+			if value == nil {
+				a.handlers.ProductPublic = nil
+				return
+			}
+			if handler, ok := value.(*producthandlers.PublicHandler); ok {
+				a.handlers.ProductPublic = handler
+			}
+		},
+	)
 }
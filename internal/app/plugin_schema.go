@@ -0,0 +1,38 @@
+package app
+
+import (
+	"fmt"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/plugin/migrations"
+)
+
+// inactivePluginSchemas returns the plugins owning tables that are not active. Their
+// tables and migrations are left alone at startup, so purged tables are not
+// recreated; activating the plugin installs them again.
+func (a *Application) inactivePluginSchemas() (map[string]bool, error) {
+	active := make(map[string]bool)
+	if a.db.Migrator().HasTable(&models.Plugin{}) {
+		var slugs []string
+		if err := a.db.Model(&models.Plugin{}).Where("active = ?", true).Pluck("slug", &slugs).Error; err != nil {
+			return nil, fmt.Errorf("failed to load active plugins: %w", err)
+		}
+		for _, slug := range slugs {
+			active[slug] = true
+		}
+	}
+
+	inactive := make(map[string]bool)
+	for _, slug := range migrations.TableOwners() {
+		if !active[slug] {
+			inactive[slug] = true
+		}
+	}
+	return inactive, nil
+}
+
+// installPluginSchema creates the tables of a plugin and applies its migrations
+// when it is activated.
+func (a *Application) installPluginSchema(slug string) error {
+	return migrations.Install(a.db, slug)
+}
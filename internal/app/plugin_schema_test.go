@@ -0,0 +1,123 @@
+package app
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/plugin"
+	"constructor-script-backend/internal/plugin/migrations"
+	pluginruntime "constructor-script-backend/internal/plugin/runtime"
+	"constructor-script-backend/internal/repository"
+	"constructor-script-backend/internal/service"
+)
+
+type purgeTestNote struct {
+	ID   uint
+	Body string
+}
+
+// purgeTestFeature drops its table on Uninstall, like the bundled plugins do.
+type purgeTestFeature struct {
+	db   *gorm.DB
+	slug string
+}
+
+func (f *purgeTestFeature) Activate() error   { return nil }
+func (f *purgeTestFeature) Deactivate() error { return nil }
+
+func (f *purgeTestFeature) Uninstall() error {
+	return f.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Migrator().DropTable(&purgeTestNote{}); err != nil {
+			return err
+		}
+		return migrations.Forget(tx, f.slug)
+	})
+}
+
+func TestPurgedPluginStaysPurgedAfterRestart(t *testing.T) {
+	const slug = "purge-notes"
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{
+		Logger: gormlogger.Default.LogMode(gormlogger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Plugin{}, &models.Setting{}); err != nil {
+		t.Fatalf("migrate core tables: %v", err)
+	}
+	if err := migrations.EnsureTable(db); err != nil {
+		t.Fatalf("prepare plugin migrations: %v", err)
+	}
+	migrations.RegisterTables(slug, &purgeTestNote{})
+
+	pluginsDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(pluginsDir, slug), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	manifest := `{"name":"Notes","slug":"` + slug + `","version":"1.0.0"}`
+	if err := os.WriteFile(filepath.Join(pluginsDir, slug, "plugin.json"), []byte(manifest), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	manager, err := plugin.NewManager(pluginsDir)
+	if err != nil {
+		t.Fatalf("plugin manager: %v", err)
+	}
+
+	runtime := pluginruntime.New()
+	runtime.Register(slug, &purgeTestFeature{db: db, slug: slug})
+
+	a := &Application{db: db}
+	settings := repository.NewSettingRepository(db)
+	plugins := service.NewPluginService(repository.NewPluginRepository(db), settings, manager, runtime)
+	plugins.SetSchemaInstaller(a.installPluginSchema)
+
+	if _, err := plugins.Activate(slug); err != nil {
+		t.Fatalf("activate: %v", err)
+	}
+	if !db.Migrator().HasTable(&purgeTestNote{}) {
+		t.Fatal("expected activation to create the plugin table")
+	}
+	if err := settings.Set("plugins."+slug+".settings", `{"greeting":"hi"}`); err != nil {
+		t.Fatalf("store settings: %v", err)
+	}
+
+	if _, err := plugins.Deactivate(slug, true); err != nil {
+		t.Fatalf("deactivate with purge: %v", err)
+	}
+	if db.Migrator().HasTable(&purgeTestNote{}) {
+		t.Fatal("expected the purge to drop the plugin table")
+	}
+	if _, err := settings.Get("plugins." + slug + ".settings"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("expected the purge to remove the plugin settings, got %v", err)
+	}
+
+	// A restart migrates only the tables of active plugins.
+	skip, err := a.inactivePluginSchemas()
+	if err != nil {
+		t.Fatalf("inactive plugins: %v", err)
+	}
+	if !skip[slug] {
+		t.Fatalf("expected the purged plugin to be skipped, got %v", skip)
+	}
+	if err := migrations.MigrateTables(db, skip); err != nil {
+		t.Fatalf("migrate plugin tables: %v", err)
+	}
+	if db.Migrator().HasTable(&purgeTestNote{}) {
+		t.Fatal("expected the purged plugin table to stay dropped after a restart")
+	}
+
+	if _, err := plugins.Activate(slug); err != nil {
+		t.Fatalf("reactivate: %v", err)
+	}
+	if !db.Migrator().HasTable(&purgeTestNote{}) {
+		t.Fatal("expected reactivation to recreate the plugin table")
+	}
+}
@@ -0,0 +1,49 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"constructor-script-backend/internal/secrets"
+)
+
+func (a *Application) initSecrets() error {
+	cfg := a.cfg
+	a.secrets = secrets.NewResolver(secrets.Options{
+		FileDir:         cfg.SecretsFileDir,
+		VaultAddr:       cfg.SecretsVaultAddr,
+		VaultToken:      cfg.SecretsVaultToken,
+		VaultNamespace:  cfg.SecretsVaultNamespace,
+		AWSRegion:       cfg.SecretsAWSRegion,
+		AWSAccessKey:    cfg.SecretsAWSAccessKey,
+		AWSSecretKey:    cfg.SecretsAWSSecretKey,
+		AWSSessionToken: cfg.SecretsAWSSessionToken,
+		GCPAccessToken:  cfg.SecretsGCPAccessToken,
+	})
+
+	// Credentials from the environment are resolved once, before anything
+	// reads them. A reference that cannot be resolved stops the startup.
+	fields := []struct {
+		env   string
+		value *string
+	}{
+		{"STRIPE_SECRET_KEY", &cfg.StripeSecretKey},
+		{"STRIPE_PUBLISHABLE_KEY", &cfg.StripePublishableKey},
+		{"STRIPE_WEBHOOK_SECRET", &cfg.StripeWebhookSecret},
+		{"OPENAI_API_KEY", &cfg.OpenAIAPIKey},
+		{"TRANSLATION_API_KEY", &cfg.TranslationAPIKey},
+		{"SMTP_PASSWORD", &cfg.SMTPPassword},
+		{"BACKUP_ENCRYPTION_KEY", &cfg.BackupEncryptionKey},
+	}
+	for _, field := range fields {
+		if !secrets.IsReference(*field.value) {
+			continue
+		}
+		secret, err := a.secrets.Resolve(context.Background(), *field.value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", field.env, err)
+		}
+		*field.value = secret
+	}
+	return nil
+}
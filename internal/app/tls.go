@@ -0,0 +1,120 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+
+	"constructor-script-backend/internal/config"
+	"constructor-script-backend/pkg/logger"
+)
+
+// initTLS moves the server to HTTPS with certificates from an ACME certificate
+// authority, and sets up the HTTP listener that answers its challenges and
+// redirects everything else.
+func (a *Application) initTLS() error {
+	cfg := a.cfg
+	if !cfg.TLSACMEEnabled {
+		return nil
+	}
+
+	domains := tlsDomains(cfg)
+	if len(domains) == 0 {
+		return errors.New("TLS_ACME_ENABLED requires TLS_DOMAINS, SITE_DOMAIN or an https SITE_URL")
+	}
+
+	manager := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		Cache:      autocert.DirCache(cfg.TLSCacheDir),
+		HostPolicy: autocert.HostWhitelist(domains...),
+		Email:      cfg.TLSACMEEmail,
+	}
+	if cfg.TLSACMEDirectoryURL != "" {
+		manager.Client = &acme.Client{DirectoryURL: cfg.TLSACMEDirectoryURL}
+	}
+
+	a.server.Addr = ":" + cfg.TLSPort
+	a.server.TLSConfig = manager.TLSConfig()
+	a.redirectServer = &http.Server{
+		Addr:              ":" + cfg.TLSHTTPPort,
+		Handler:           manager.HTTPHandler(httpsRedirect(cfg.TLSPort)),
+		ReadHeaderTimeout: 10 * time.Second,
+		IdleTimeout:       time.Minute,
+	}
+
+	logger.Info("Automatic TLS enabled", map[string]interface{}{
+		"domains":   domains,
+		"tls_port":  cfg.TLSPort,
+		"http_port": cfg.TLSHTTPPort,
+	})
+	return nil
+}
+
+// tlsDomains returns the hosts certificates are requested for: TLS_DOMAINS, or
+// else the host of the site URL when it uses HTTPS.
+func tlsDomains(cfg *config.Config) []string {
+	var domains []string
+	seen := make(map[string]bool)
+	add := func(domain string) {
+		domain = strings.ToLower(strings.TrimSpace(domain))
+		if domain == "" || seen[domain] {
+			return
+		}
+		seen[domain] = true
+		domains = append(domains, domain)
+	}
+
+	for _, domain := range cfg.TLSDomains {
+		add(domain)
+	}
+	if len(domains) == 0 {
+		if parsed, err := url.Parse(strings.TrimSpace(cfg.SiteURL)); err == nil && parsed.Scheme == "https" {
+			add(parsed.Hostname())
+		}
+	}
+	return domains
+}
+
+// httpsRedirect sends GET and HEAD requests to the same URL over HTTPS on
+// port. Other methods are refused, so a form is never posted in plain text
+// and replayed.
+func httpsRedirect(port string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, "Use HTTPS", http.StatusBadRequest)
+			return
+		}
+
+		host := r.Host
+		if hostname, _, err := net.SplitHostPort(host); err == nil {
+			host = hostname
+		}
+		if port != "" && port != "443" {
+			host = net.JoinHostPort(host, port)
+		}
+		target := url.URL{Scheme: "https", Host: host, Path: r.URL.Path, RawPath: r.URL.RawPath, RawQuery: r.URL.RawQuery}
+		http.Redirect(w, r, target.String(), http.StatusMovedPermanently)
+	})
+}
+
+// runRedirectServer serves ACME challenges and HTTPS redirects until the
+// application shuts down.
+func (a *Application) runRedirectServer() {
+	if err := a.redirectServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Error(err, "HTTP redirect server stopped", map[string]interface{}{"addr": a.redirectServer.Addr})
+	}
+}
+
+func (a *Application) shutdownRedirectServer(ctx context.Context) error {
+	if a.redirectServer == nil {
+		return nil
+	}
+	return a.redirectServer.Shutdown(ctx)
+}
@@ -0,0 +1,40 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"constructor-script-backend/internal/config"
+)
+
+func TestTLSDomains(t *testing.T) {
+	if got := tlsDomains(&config.Config{TLSDomains: []string{"Example.com", "www.example.com", "example.com"}, SiteURL: "https://other.example"}); !reflect.DeepEqual(got, []string{"example.com", "www.example.com"}) {
+		t.Fatalf("expected the configured domains, got %v", got)
+	}
+	if got := tlsDomains(&config.Config{SiteURL: "https://site.example:8443/blog"}); !reflect.DeepEqual(got, []string{"site.example"}) {
+		t.Fatalf("expected the host of the site URL, got %v", got)
+	}
+	if got := tlsDomains(&config.Config{SiteURL: "http://localhost:8081"}); len(got) != 0 {
+		t.Fatalf("expected no domain for a plain HTTP site URL, got %v", got)
+	}
+}
+
+func TestHTTPSRedirect(t *testing.T) {
+	cases := []struct {
+		port, method, target, location string
+		status                         int
+	}{
+		{"443", http.MethodGet, "http://site.example/blog?page=2", "https://site.example/blog?page=2", http.StatusMovedPermanently},
+		{"8443", http.MethodHead, "http://site.example:8080/", "https://site.example:8443/", http.StatusMovedPermanently},
+		{"443", http.MethodPost, "http://site.example/api/v1/login", "", http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		recorder := httptest.NewRecorder()
+		httpsRedirect(tc.port).ServeHTTP(recorder, httptest.NewRequest(tc.method, tc.target, nil))
+		if recorder.Code != tc.status || recorder.Header().Get("Location") != tc.location {
+			t.Fatalf("%s %s: got %d %q", tc.method, tc.target, recorder.Code, recorder.Header().Get("Location"))
+		}
+	}
+}
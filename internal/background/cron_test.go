@@ -0,0 +1,43 @@
+package background
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronScheduleNext(t *testing.T) {
+	base := time.Date(2024, time.March, 15, 10, 30, 0, 0, time.UTC) // Friday
+
+	cases := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2024, time.March, 15, 10, 31, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, time.March, 15, 10, 45, 0, 0, time.UTC)},
+		{"0 8 * * *", time.Date(2024, time.March, 16, 8, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2024, time.March, 16, 0, 0, 0, 0, time.UTC)},
+		{"0 9 * * mon-wed", time.Date(2024, time.March, 18, 9, 0, 0, 0, time.UTC)},
+		{"0 0 1 jan *", time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 31 * *", time.Date(2024, time.March, 31, 0, 0, 0, 0, time.UTC)},
+		{"30 6 1 * 7", time.Date(2024, time.March, 17, 6, 30, 0, 0, time.UTC)},
+		{"@every 90m", base.Add(90 * time.Minute)},
+	}
+
+	for _, tc := range cases {
+		schedule, err := ParseCron(tc.expr)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.expr, err)
+		}
+		if got := schedule.Next(base); !got.Equal(tc.want) {
+			t.Fatalf("%s: expected %s, got %s", tc.expr, tc.want, got)
+		}
+	}
+}
+
+func TestParseCronRejectsInvalidExpressions(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "*/0 * * * *", "5-1 * * * *", "@every 0s"} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Fatalf("expected %q to be rejected", expr)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package background
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"constructor-script-backend/pkg/logger"
+)
+
+// RecurringJob runs on a cron schedule until it is unscheduled. Runs are queued on
+// the scheduler workers; a run is skipped while the previous one is still going.
+type RecurringJob struct {
+	Name     string
+	Schedule string
+	Run      func(ctx context.Context) error
+	Timeout  time.Duration
+}
+
+// RecurringJobStatus describes a registered recurring job.
+type RecurringJobStatus struct {
+	Name         string     `json:"name"`
+	Schedule     string     `json:"schedule"`
+	LastRunAt    *time.Time `json:"last_run_at,omitempty"`
+	LastDuration string     `json:"last_duration,omitempty"`
+	LastError    string     `json:"last_error,omitempty"`
+	NextRunAt    *time.Time `json:"next_run_at,omitempty"`
+	Running      bool       `json:"running"`
+}
+
+type recurringEntry struct {
+	job      RecurringJob
+	schedule *CronSchedule
+	timer    *time.Timer
+	next     time.Time
+
+	lastRun      *time.Time
+	lastDuration time.Duration
+	lastError    string
+	running      bool
+}
+
+const recurringJobPrefix = "cron:"
+
+// ScheduleRecurring registers job, replacing any recurring job with the same name.
+func (s *Scheduler) ScheduleRecurring(job RecurringJob) error {
+	job.Name = strings.TrimSpace(job.Name)
+	if job.Name == "" {
+		return errors.New("job name is required")
+	}
+	if job.Run == nil {
+		return errors.New("job runner is required")
+	}
+
+	schedule, err := ParseCron(job.Schedule)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.recurring == nil {
+		s.recurring = make(map[string]*recurringEntry)
+	}
+	if existing, ok := s.recurring[job.Name]; ok && existing.timer != nil {
+		existing.timer.Stop()
+	}
+
+	entry := &recurringEntry{job: job, schedule: schedule}
+	s.recurring[job.Name] = entry
+	if s.started {
+		s.armLocked(entry)
+	}
+	return nil
+}
+
+// Unschedule removes a recurring job. A run that is already in progress completes.
+func (s *Scheduler) Unschedule(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.recurring[strings.TrimSpace(name)]
+	if !ok {
+		return
+	}
+	if entry.timer != nil {
+		entry.timer.Stop()
+	}
+	delete(s.recurring, entry.job.Name)
+}
+
+// RecurringJobs lists the registered recurring jobs ordered by name.
+func (s *Scheduler) RecurringJobs() []RecurringJobStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := make([]RecurringJobStatus, 0, len(s.recurring))
+	for _, entry := range s.recurring {
+		status := RecurringJobStatus{
+			Name:      entry.job.Name,
+			Schedule:  entry.job.Schedule,
+			LastError: entry.lastError,
+			Running:   entry.running,
+		}
+		if entry.lastRun != nil {
+			lastRun := *entry.lastRun
+			status.LastRunAt = &lastRun
+			status.LastDuration = entry.lastDuration.Round(time.Millisecond).String()
+		}
+		if !entry.next.IsZero() && s.started {
+			next := entry.next
+			status.NextRunAt = &next
+		}
+		statuses = append(statuses, status)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+func (s *Scheduler) armRecurringLocked() {
+	for _, entry := range s.recurring {
+		s.armLocked(entry)
+	}
+}
+
+func (s *Scheduler) stopRecurringLocked() {
+	for _, entry := range s.recurring {
+		if entry.timer != nil {
+			entry.timer.Stop()
+			entry.timer = nil
+		}
+	}
+}
+
+func (s *Scheduler) armLocked(entry *recurringEntry) {
+	now := time.Now()
+	entry.next = entry.schedule.Next(now)
+	if entry.next.IsZero() {
+		return
+	}
+	entry.timer = time.AfterFunc(entry.next.Sub(now), func() { s.fireRecurring(entry) })
+}
+
+func (s *Scheduler) fireRecurring(entry *recurringEntry) {
+	s.mu.Lock()
+	if !s.started || s.recurring[entry.job.Name] != entry {
+		s.mu.Unlock()
+		return
+	}
+	s.armLocked(entry)
+	s.mu.Unlock()
+
+	err := s.ScheduleUnique(Job{
+		Name:    recurringJobPrefix + entry.job.Name,
+		Timeout: entry.job.Timeout,
+		Run: func(ctx context.Context) error {
+			return s.runRecurring(ctx, entry)
+		},
+	})
+	if err != nil && !errors.Is(err, ErrJobAlreadyScheduled) {
+		logger.Warn("Failed to queue recurring job", map[string]interface{}{"job": entry.job.Name, "error": err.Error()})
+	}
+}
+
+func (s *Scheduler) runRecurring(ctx context.Context, entry *recurringEntry) error {
+	start := time.Now().UTC()
+
+	s.mu.Lock()
+	entry.running = true
+	s.mu.Unlock()
+
+	err := entry.job.Run(ctx)
+
+	s.mu.Lock()
+	entry.running = false
+	entry.lastRun = &start
+	entry.lastDuration = time.Since(start)
+	entry.lastError = ""
+	if err != nil {
+		entry.lastError = err.Error()
+	}
+	s.mu.Unlock()
+
+	return err
+}
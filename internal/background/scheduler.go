@@ -51,6 +51,7 @@ type Scheduler struct {
 	jobWG    sync.WaitGroup
 
 	activeJobs map[string]struct{}
+	recurring  map[string]*recurringEntry
 }
 
 type scheduledJob struct {
@@ -106,6 +107,7 @@ func NewScheduler(cfg SchedulerConfig) *Scheduler {
 		config:     cfg,
 		queue:      make(chan scheduledJob, cfg.QueueSize),
 		activeJobs: make(map[string]struct{}),
+		recurring:  make(map[string]*recurringEntry),
 	}
 }
 
@@ -124,6 +126,8 @@ func (s *Scheduler) Start(ctx context.Context) {
 		s.workerWG.Add(1)
 		go s.worker()
 	}
+
+	s.armRecurringLocked()
 }
 
 func (s *Scheduler) worker() {
@@ -318,6 +322,7 @@ func (s *Scheduler) Shutdown(ctx context.Context) error {
 		return nil
 	}
 	cancel := s.cancel
+	s.stopRecurringLocked()
 	s.mu.Unlock()
 
 	if cancel != nil {
@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"constructor-script-backend/pkg/lang"
 )
@@ -23,6 +24,18 @@ type Config struct {
 	DBSSLMode   string
 	DatabaseURL string
 
+	// Connection pool. Lifetimes and the statement timeout are in seconds; a
+	// statement timeout of zero leaves statements without a deadline.
+	DBMaxOpenConns     int
+	DBMaxIdleConns     int
+	DBConnMaxLifetime  int
+	DBConnMaxIdleTime  int
+	DBStatementTimeout int
+
+	// Statements slower than this many milliseconds are logged and aggregated in
+	// the slow query report; zero disables both.
+	DBSlowQueryThreshold int
+
 	// Redis
 	EnableRedis bool
 	RedisURL    string
@@ -39,6 +52,18 @@ type Config struct {
 	ServerWriteTimeout int // in seconds, default 300 (5 minutes)
 	ServerIdleTimeout  int // in seconds, default 120 (2 minutes)
 
+	// TLSACMEEnabled serves HTTPS on TLSPort with certificates from an ACME
+	// certificate authority (Let's Encrypt by default) for TLSDomains, and
+	// redirects HTTP on TLSHTTPPort to it. Port is then unused. Certificates are
+	// kept in TLSCacheDir.
+	TLSACMEEnabled      bool
+	TLSDomains          []string
+	TLSACMEEmail        string
+	TLSACMEDirectoryURL string
+	TLSCacheDir         string
+	TLSPort             string
+	TLSHTTPPort         string
+
 	// CORS
 	CORSOrigins []string
 
@@ -51,6 +76,48 @@ type Config struct {
 	// Upload
 	UploadDir     string
 	MaxUploadSize int64
+	// Strip EXIF/GPS metadata from photos and scripts from SVGs on upload.
+	SanitizeImageUploads bool
+	// VideoPosterFrames extracts durations and poster frames from uploaded videos with ffmpeg.
+	VideoPosterFrames bool
+
+	// Garbage collection of uploads that no content references.
+	UploadGCEnabled     bool
+	UploadGCSchedule    string
+	UploadGCGraceDays   int
+	UploadGCMode        string
+	UploadQuarantineDir string
+
+	// Direct uploads: browsers PUT videos and documents straight to an S3-compatible bucket.
+	MediaS3Enabled      bool
+	MediaS3Endpoint     string
+	MediaS3AccessKey    string
+	MediaS3SecretKey    string
+	MediaS3Bucket       string
+	MediaS3Region       string
+	MediaS3UseSSL       bool
+	MediaS3Prefix       string
+	MediaS3PublicURL    string
+	MediaS3UploadExpiry int
+
+	// CDN serving public uploads. Purging needs either Bunny or CloudFront credentials.
+	CDNBaseURL                  string
+	CDNPurgeProvider            string
+	CDNBunnyAPIKey              string
+	CDNCloudFrontDistributionID string
+	CDNCloudFrontAccessKey      string
+	CDNCloudFrontSecretKey      string
+
+	// Image variants generated for uploaded images. An empty width list disables them.
+	ImageVariantWidths  []int
+	ImageVariantFormats []string
+	ImageVariantQuality int
+
+	// On-demand resizing via /img/<transform>/<path>.
+	ImageCacheDir              string
+	ImageCacheMaxMB            int
+	ImageTransformMaxDimension int
+	ImageTransformConcurrency  int
 
 	// Subtitles
 	SubtitleGenerationEnabled bool
@@ -62,17 +129,37 @@ type Config struct {
 	OpenAIAPIKey              string
 	OpenAIModel               string
 
+	// Machine translation drafts for editors
+	TranslationProvider string
+	TranslationAPIKey   string
+	TranslationModel    string
+	TranslationEndpoint string
+
 	// Email
 	SMTPHost     string
 	SMTPPort     string
 	SMTPUsername string
 	SMTPPassword string
 	SMTPFrom     string
+	// EmailFeedbackSecret is the token in the URL mail providers post bounces
+	// and complaints to; empty disables the endpoint.
+	EmailFeedbackSecret string
 
 	// Rate Limiting
 	RateLimitRequests int
 	RateLimitWindow   int
 	RateLimitBurst    int
+	// RateLimitBanThreshold rate limited requests within RateLimitBanWindow
+	// seconds ban the address for RateLimitBanMinutes; 0 disables bans.
+	RateLimitBanThreshold int
+	RateLimitBanWindow    int
+	RateLimitBanMinutes   int
+
+	// GeoIPDatabase is a MaxMind country database for country block rules.
+	// GeoIPCountryHeader names a header a CDN sets to the visitor's country,
+	// such as CF-IPCountry, which is trusted instead when present.
+	GeoIPDatabase      string
+	GeoIPCountryHeader string
 
 	// Critical Operations Rate Limiting
 	UploadRateLimitRequests int
@@ -94,6 +181,43 @@ type Config struct {
 	EnableEmail       bool
 	EnableMetrics     bool
 	EnableCompression bool
+	// EnableProfiling serves pprof, goroutine dumps and runtime stats under /debug,
+	// behind the same IP and basic auth gating as /metrics.
+	EnableProfiling bool
+	// EnableOpenAPI serves the OpenAPI document of /api/v1 at /api/v1/openapi.json;
+	// EnableSwaggerUI adds a Swagger UI for it at /api/v1/admin/docs.
+	EnableOpenAPI   bool
+	EnableSwaggerUI bool
+
+	// APIV1Sunset is announced in the Sunset header of the /api/v1 routes that
+	// /api/v2 replaces; zero leaves the date open. LegacyAPIDisabled retires those
+	// routes, which then answer 410 Gone.
+	APIV1Sunset       time.Time
+	LegacyAPIDisabled bool
+
+	// In-process cache tier in front of Redis (or alone without it). TTL is in
+	// seconds and bounds staleness across instances sharing Redis.
+	CacheMemoryEntries int
+	CacheMemoryTTL     int
+	// CachePrewarmSchedule re-renders the homepage, blog index and sitemap on a cron
+	// schedule, on top of the prewarm after every publish; empty disables it.
+	CachePrewarmSchedule string
+
+	// DBQueryBudget is the number of database statements one request may run before
+	// it is logged as a likely N+1 query; zero disables the warning.
+	DBQueryBudget int
+
+	// Theme assets
+	EnableAssetPipeline     bool
+	EnableAssetMinification bool
+
+	// Plugins
+	PluginMarketplaceURL   string
+	PluginSigningPublicKey string
+	// PluginUID and PluginGID name the unprivileged user out-of-process plugins run
+	// as; negative when unset, which keeps such plugins from starting.
+	PluginUID int
+	PluginGID int
 
 	// Metrics security
 	MetricsBasicAuthUsername string
@@ -101,14 +225,16 @@ type Config struct {
 	MetricsAllowedIPs        []string
 
 	// Site Meta
-	SiteName           string
-	SiteDescription    string
-	SiteURL            string
-	SiteDomain         string
-	SiteFavicon        string
-	SiteLogo           string
-	DefaultLanguage    string
-	SupportedLanguages []string
+	SiteName        string
+	SiteDescription string
+	SiteURL         string
+	SiteDomain      string
+	// EnforceCanonicalHost redirects requests to the scheme and host of the site URL.
+	EnforceCanonicalHost bool
+	SiteFavicon          string
+	SiteLogo             string
+	DefaultLanguage      string
+	SupportedLanguages   []string
 
 	// Backup
 	BackupEncryptionKey string
@@ -120,6 +246,27 @@ type Config struct {
 	BackupS3Region      string
 	BackupS3UseSSL      bool
 	BackupS3Prefix      string
+	// BackupStorage selects the object storage that receives automatic backups:
+	// s3, gcs or azure. Empty means s3 when BackupS3Enabled is set, and none
+	// otherwise. BackupGCSCredentialsFile is the JSON key of a service account
+	// with access to BackupGCSBucket.
+	BackupStorage            string
+	BackupGCSBucket          string
+	BackupGCSPrefix          string
+	BackupGCSCredentialsFile string
+	BackupAzureAccount       string
+	BackupAzureKey           string
+	BackupAzureContainer     string
+	BackupAzurePrefix        string
+	BackupAzureEndpoint      string
+	// BackupPGDump adds a pg_dump of the database to every archive, running
+	// BackupPGDumpPath.
+	BackupPGDump     bool
+	BackupPGDumpPath string
+	// BackupVerifySchedule is the cron schedule of the job that checks the latest
+	// archive; empty disables it. BackupAlertEmail receives failed checks.
+	BackupVerifySchedule string
+	BackupAlertEmail     string
 
 	// Payments
 	StripeSecretKey          string
@@ -129,6 +276,21 @@ type Config struct {
 	CourseCheckoutCancelURL  string
 	CourseCheckoutCurrency   string
 
+	// Secrets. The Stripe keys, the OpenAI and translation API keys, the SMTP
+	// password and the backup encryption key may hold a reference to a secret
+	// kept in Vault, AWS KMS, Google Cloud KMS or a file, which is resolved at
+	// startup with these credentials. File references must point into
+	// SecretsFileDir.
+	SecretsFileDir         string
+	SecretsVaultAddr       string
+	SecretsVaultToken      string
+	SecretsVaultNamespace  string
+	SecretsAWSRegion       string
+	SecretsAWSAccessKey    string
+	SecretsAWSSecretKey    string
+	SecretsAWSSessionToken string
+	SecretsGCPAccessToken  string
+
 	// Setup Security
 	SetupKey string
 }
@@ -151,6 +313,14 @@ func New() *Config {
 		DBName:     getEnv("DB_NAME", "constructor"),
 		DBSSLMode:  getEnv("DB_SSLMODE", "disable"),
 
+		DBMaxOpenConns:     getEnvAsInt("DB_MAX_OPEN_CONNS", 100),
+		DBMaxIdleConns:     getEnvAsInt("DB_MAX_IDLE_CONNS", 10),
+		DBConnMaxLifetime:  getEnvAsInt("DB_CONN_MAX_LIFETIME", 3600),
+		DBConnMaxIdleTime:  getEnvAsInt("DB_CONN_MAX_IDLE_TIME", 300),
+		DBStatementTimeout: getEnvAsInt("DB_STATEMENT_TIMEOUT", 30),
+
+		DBSlowQueryThreshold: getEnvAsInt("DB_SLOW_QUERY_THRESHOLD_MS", 200),
+
 		// Redis
 		EnableRedis: getEnvAsBool("ENABLE_REDIS", true),
 		RedisURL:    getEnv("REDIS_URL", "localhost:6379"),
@@ -167,6 +337,14 @@ func New() *Config {
 		ServerWriteTimeout: getEnvAsInt("SERVER_WRITE_TIMEOUT", 300), // 5 minutes for large file downloads
 		ServerIdleTimeout:  getEnvAsInt("SERVER_IDLE_TIMEOUT", 120),  // 2 minutes for keep-alive connections
 
+		TLSACMEEnabled:      getEnvAsBool("TLS_ACME_ENABLED", false),
+		TLSDomains:          getEnvAsSlice("TLS_DOMAINS"),
+		TLSACMEEmail:        strings.TrimSpace(getEnv("TLS_ACME_EMAIL", "")),
+		TLSACMEDirectoryURL: strings.TrimSpace(getEnv("TLS_ACME_DIRECTORY_URL", "")),
+		TLSCacheDir:         getEnv("TLS_CACHE_DIR", "./certs"),
+		TLSPort:             getEnv("TLS_PORT", "443"),
+		TLSHTTPPort:         getEnv("TLS_HTTP_PORT", "80"),
+
 		// CORS
 		CORSOrigins: strings.Split(getEnv("CORS_ORIGINS", "http://localhost:3000,http://localhost:8080"), ","),
 
@@ -177,8 +355,44 @@ func New() *Config {
 		CourseAssetTokenTTLMinutes: getEnvAsInt("COURSE_ASSET_TOKEN_TTL_MINUTES", 10),
 
 		// Upload
-		UploadDir:     getEnv("UPLOAD_DIR", "./uploads"),
-		MaxUploadSize: getEnvAsInt64("MAX_UPLOAD_SIZE", 2*1024*1024*1024), // 2GB default, configurable via env
+		UploadDir:            getEnv("UPLOAD_DIR", "./uploads"),
+		MaxUploadSize:        getEnvAsInt64("MAX_UPLOAD_SIZE", 2*1024*1024*1024), // 2GB default, configurable via env
+		SanitizeImageUploads: getEnvAsBool("UPLOAD_SANITIZE_IMAGES", true),
+		VideoPosterFrames:    getEnvAsBool("UPLOAD_VIDEO_POSTERS", true),
+
+		UploadGCEnabled:     getEnvAsBool("UPLOAD_GC_ENABLED", false),
+		UploadGCSchedule:    getEnv("UPLOAD_GC_SCHEDULE", "30 3 * * *"),
+		UploadGCGraceDays:   getEnvAsInt("UPLOAD_GC_GRACE_DAYS", 30),
+		UploadGCMode:        strings.ToLower(getEnv("UPLOAD_GC_MODE", "quarantine")),
+		UploadQuarantineDir: getEnv("UPLOAD_QUARANTINE_DIR", "./quarantine/uploads"),
+
+		MediaS3Enabled:      getEnvAsBool("MEDIA_S3_ENABLED", false),
+		MediaS3Endpoint:     getEnv("MEDIA_S3_ENDPOINT", ""),
+		MediaS3AccessKey:    getEnv("MEDIA_S3_ACCESS_KEY", ""),
+		MediaS3SecretKey:    getEnv("MEDIA_S3_SECRET_KEY", ""),
+		MediaS3Bucket:       getEnv("MEDIA_S3_BUCKET", ""),
+		MediaS3Region:       getEnv("MEDIA_S3_REGION", ""),
+		MediaS3UseSSL:       getEnvAsBool("MEDIA_S3_USE_SSL", true),
+		MediaS3Prefix:       getEnv("MEDIA_S3_PREFIX", "uploads"),
+		MediaS3PublicURL:    getEnv("MEDIA_S3_PUBLIC_URL", ""),
+		MediaS3UploadExpiry: getEnvAsInt("MEDIA_S3_UPLOAD_EXPIRY_MINUTES", 30),
+
+		CDNBaseURL:                  getEnv("CDN_BASE_URL", ""),
+		CDNPurgeProvider:            strings.ToLower(getEnv("CDN_PURGE_PROVIDER", "")),
+		CDNBunnyAPIKey:              getEnv("CDN_BUNNY_API_KEY", ""),
+		CDNCloudFrontDistributionID: getEnv("CDN_CLOUDFRONT_DISTRIBUTION_ID", ""),
+		CDNCloudFrontAccessKey:      getEnv("CDN_CLOUDFRONT_ACCESS_KEY", ""),
+		CDNCloudFrontSecretKey:      getEnv("CDN_CLOUDFRONT_SECRET_KEY", ""),
+
+		// Image variants
+		ImageVariantWidths:  parseImageVariantWidths(getEnvAsSlice("IMAGE_VARIANT_WIDTHS")),
+		ImageVariantFormats: parseImageVariantFormats(getEnvAsSlice("IMAGE_VARIANT_FORMATS")),
+		ImageVariantQuality: getEnvAsInt("IMAGE_VARIANT_QUALITY", 80),
+
+		ImageCacheDir:              getEnv("IMAGE_CACHE_DIR", "./cache/images"),
+		ImageCacheMaxMB:            getEnvAsInt("IMAGE_CACHE_MAX_MB", 1024),
+		ImageTransformMaxDimension: getEnvAsInt("IMAGE_TRANSFORM_MAX_DIMENSION", 2560),
+		ImageTransformConcurrency:  getEnvAsInt("IMAGE_TRANSFORM_CONCURRENCY", 0),
 
 		// Subtitles
 		SubtitleGenerationEnabled: getEnvAsBool("SUBTITLE_GENERATION_ENABLED", false),
@@ -190,18 +404,32 @@ func New() *Config {
 		OpenAIAPIKey:              strings.TrimSpace(getEnv("OPENAI_API_KEY", "")),
 		OpenAIModel:               strings.TrimSpace(getEnv("OPENAI_MODEL", "whisper-1")),
 
+		// Machine translation
+		TranslationProvider: strings.ToLower(strings.TrimSpace(getEnv("TRANSLATION_PROVIDER", ""))),
+		TranslationAPIKey:   strings.TrimSpace(getEnv("TRANSLATION_API_KEY", "")),
+		TranslationModel:    strings.TrimSpace(getEnv("TRANSLATION_MODEL", "")),
+		TranslationEndpoint: strings.TrimSpace(getEnv("TRANSLATION_ENDPOINT", "")),
+
 		// Email
-		SMTPHost:     getEnv("SMTP_HOST", ""),
-		SMTPPort:     getEnv("SMTP_PORT", "587"),
-		SMTPUsername: getEnv("SMTP_USERNAME", ""),
-		SMTPPassword: getEnv("SMTP_PASSWORD", ""),
-		SMTPFrom:     getEnv("SMTP_FROM", "noreply@constructor-script.com"),
+		SMTPHost:            getEnv("SMTP_HOST", ""),
+		SMTPPort:            getEnv("SMTP_PORT", "587"),
+		SMTPUsername:        getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:        getEnv("SMTP_PASSWORD", ""),
+		SMTPFrom:            getEnv("SMTP_FROM", "noreply@constructor-script.com"),
+		EmailFeedbackSecret: strings.TrimSpace(getEnv("EMAIL_FEEDBACK_SECRET", "")),
 
 		// Rate Limiting
 		RateLimitRequests: getEnvAsInt("RATE_LIMIT_REQUESTS", 100),
 		RateLimitWindow:   getEnvAsInt("RATE_LIMIT_WINDOW", 60),
 		RateLimitBurst:    getEnvAsInt("RATE_LIMIT_BURST", 0),
 
+		RateLimitBanThreshold: getEnvAsInt("RATE_LIMIT_BAN_THRESHOLD", 0),
+		RateLimitBanWindow:    getEnvAsInt("RATE_LIMIT_BAN_WINDOW", 600),
+		RateLimitBanMinutes:   getEnvAsInt("RATE_LIMIT_BAN_MINUTES", 60),
+
+		GeoIPDatabase:      strings.TrimSpace(getEnv("GEOIP_DATABASE", "")),
+		GeoIPCountryHeader: strings.TrimSpace(getEnv("GEOIP_COUNTRY_HEADER", "")),
+
 		// Critical Operations Rate Limiting
 		UploadRateLimitRequests: getEnvAsInt("UPLOAD_RATE_LIMIT_REQUESTS", 10),
 		UploadRateLimitWindow:   getEnvAsInt("UPLOAD_RATE_LIMIT_WINDOW", 300),
@@ -222,6 +450,29 @@ func New() *Config {
 		EnableEmail:       true,
 		EnableMetrics:     getEnvAsBool("ENABLE_METRICS", true),
 		EnableCompression: getEnvAsBool("ENABLE_COMPRESSION", true),
+		EnableProfiling:   getEnvAsBool("ENABLE_PROFILING", false),
+		EnableOpenAPI:     getEnvAsBool("ENABLE_OPENAPI", true),
+		EnableSwaggerUI:   getEnvAsBool("ENABLE_SWAGGER_UI", false),
+
+		APIV1Sunset:       getEnvAsDate("API_V1_SUNSET"),
+		LegacyAPIDisabled: getEnvAsBool("LEGACY_API_DISABLED", false),
+
+		CacheMemoryEntries: getEnvAsInt("CACHE_MEMORY_ENTRIES", 2000),
+		CacheMemoryTTL:     getEnvAsInt("CACHE_MEMORY_TTL", 30),
+
+		CachePrewarmSchedule: strings.TrimSpace(getEnv("CACHE_PREWARM_SCHEDULE", "@every 15m")),
+
+		DBQueryBudget: getEnvAsInt("DB_QUERY_BUDGET", 40),
+
+		// Theme assets
+		EnableAssetPipeline:     getEnvAsBool("ENABLE_ASSET_PIPELINE", true),
+		EnableAssetMinification: getEnvAsBool("ENABLE_ASSET_MINIFICATION", true),
+
+		// Plugins
+		PluginMarketplaceURL:   strings.TrimSpace(getEnv("PLUGIN_MARKETPLACE_URL", "")),
+		PluginSigningPublicKey: strings.TrimSpace(getEnv("PLUGIN_SIGNING_PUBLIC_KEY", "")),
+		PluginUID:              getEnvAsInt("PLUGIN_UID", -1),
+		PluginGID:              getEnvAsInt("PLUGIN_GID", -1),
 
 		// Metrics security
 		MetricsBasicAuthUsername: getEnv("METRICS_BASIC_AUTH_USERNAME", ""),
@@ -229,23 +480,39 @@ func New() *Config {
 		MetricsAllowedIPs:        getEnvAsSlice("METRICS_ALLOWED_IPS"),
 
 		// Site Meta
-		SiteName:            getEnv("SITE_NAME", "Constructor Script"),
-		SiteDescription:     getEnv("SITE_DESCRIPTION", "Platform for building modern, high-performance websites using Go and templates."),
-		SiteDomain:          getEnv("SITE_DOMAIN", ""),
-		SiteURL:             resolveSiteURL(getEnv("SITE_DOMAIN", ""), getEnv("SITE_URL", "")),
-		SiteFavicon:         getEnv("SITE_FAVICON", "/favicon.ico"),
-		SiteLogo:            getEnv("SITE_LOGO", "/static/icons/logo.svg"),
-		DefaultLanguage:     defaultLanguage,
-		SupportedLanguages:  supportedLanguages, // Backup
-		BackupEncryptionKey: getEnv("BACKUP_ENCRYPTION_KEY", ""),
-		BackupS3Enabled:     getEnvAsBool("BACKUP_S3_ENABLED", false),
-		BackupS3Endpoint:    getEnv("BACKUP_S3_ENDPOINT", ""),
-		BackupS3AccessKey:   getEnv("BACKUP_S3_ACCESS_KEY", ""),
-		BackupS3SecretKey:   getEnv("BACKUP_S3_SECRET_KEY", ""),
-		BackupS3Bucket:      getEnv("BACKUP_S3_BUCKET", ""),
-		BackupS3Region:      getEnv("BACKUP_S3_REGION", ""),
-		BackupS3UseSSL:      getEnvAsBool("BACKUP_S3_USE_SSL", true),
-		BackupS3Prefix:      getEnv("BACKUP_S3_PREFIX", ""),
+		SiteName:             getEnv("SITE_NAME", "Constructor Script"),
+		SiteDescription:      getEnv("SITE_DESCRIPTION", "Platform for building modern, high-performance websites using Go and templates."),
+		SiteDomain:           getEnv("SITE_DOMAIN", ""),
+		SiteURL:              resolveSiteURL(getEnv("SITE_DOMAIN", ""), getEnv("SITE_URL", "")),
+		EnforceCanonicalHost: getEnvAsBool("ENFORCE_CANONICAL_HOST", false),
+		SiteFavicon:          getEnv("SITE_FAVICON", "/favicon.ico"),
+		SiteLogo:             getEnv("SITE_LOGO", "/static/icons/logo.svg"),
+		DefaultLanguage:      defaultLanguage,
+		SupportedLanguages:   supportedLanguages, // Backup
+		BackupEncryptionKey:  getEnv("BACKUP_ENCRYPTION_KEY", ""),
+		BackupS3Enabled:      getEnvAsBool("BACKUP_S3_ENABLED", false),
+		BackupS3Endpoint:     getEnv("BACKUP_S3_ENDPOINT", ""),
+		BackupS3AccessKey:    getEnv("BACKUP_S3_ACCESS_KEY", ""),
+		BackupS3SecretKey:    getEnv("BACKUP_S3_SECRET_KEY", ""),
+		BackupS3Bucket:       getEnv("BACKUP_S3_BUCKET", ""),
+		BackupS3Region:       getEnv("BACKUP_S3_REGION", ""),
+		BackupS3UseSSL:       getEnvAsBool("BACKUP_S3_USE_SSL", true),
+		BackupS3Prefix:       getEnv("BACKUP_S3_PREFIX", ""),
+
+		BackupStorage:            strings.ToLower(strings.TrimSpace(getEnv("BACKUP_STORAGE", ""))),
+		BackupGCSBucket:          strings.TrimSpace(getEnv("BACKUP_GCS_BUCKET", "")),
+		BackupGCSPrefix:          getEnv("BACKUP_GCS_PREFIX", ""),
+		BackupGCSCredentialsFile: strings.TrimSpace(getEnv("BACKUP_GCS_CREDENTIALS_FILE", "")),
+		BackupAzureAccount:       strings.TrimSpace(getEnv("BACKUP_AZURE_ACCOUNT", "")),
+		BackupAzureKey:           strings.TrimSpace(getEnv("BACKUP_AZURE_KEY", "")),
+		BackupAzureContainer:     strings.TrimSpace(getEnv("BACKUP_AZURE_CONTAINER", "")),
+		BackupAzurePrefix:        getEnv("BACKUP_AZURE_PREFIX", ""),
+		BackupAzureEndpoint:      strings.TrimSpace(getEnv("BACKUP_AZURE_ENDPOINT", "")),
+		BackupPGDump:             getEnvAsBool("BACKUP_PG_DUMP", false),
+		BackupPGDumpPath:         strings.TrimSpace(getEnv("BACKUP_PG_DUMP_PATH", "pg_dump")),
+
+		BackupVerifySchedule: strings.TrimSpace(getEnv("BACKUP_VERIFY_SCHEDULE", "30 5 * * *")),
+		BackupAlertEmail:     strings.TrimSpace(getEnv("BACKUP_ALERT_EMAIL", "")),
 
 		// Payments
 		StripeSecretKey:        strings.TrimSpace(getEnv("STRIPE_SECRET_KEY", "")),
@@ -253,6 +520,17 @@ func New() *Config {
 		StripeWebhookSecret:    strings.TrimSpace(getEnv("STRIPE_WEBHOOK_SECRET", "")),
 		CourseCheckoutCurrency: strings.ToLower(strings.TrimSpace(getEnv("COURSE_CURRENCY", "usd"))),
 
+		// Secrets
+		SecretsFileDir:         strings.TrimSpace(getEnv("SECRETS_FILE_DIR", "/run/secrets")),
+		SecretsVaultAddr:       strings.TrimSpace(getEnv("VAULT_ADDR", "")),
+		SecretsVaultToken:      strings.TrimSpace(getEnv("VAULT_TOKEN", "")),
+		SecretsVaultNamespace:  strings.TrimSpace(getEnv("VAULT_NAMESPACE", "")),
+		SecretsAWSRegion:       strings.TrimSpace(getEnvFirst([]string{"AWS_REGION", "AWS_DEFAULT_REGION"}, "")),
+		SecretsAWSAccessKey:    strings.TrimSpace(getEnv("AWS_ACCESS_KEY_ID", "")),
+		SecretsAWSSecretKey:    strings.TrimSpace(getEnv("AWS_SECRET_ACCESS_KEY", "")),
+		SecretsAWSSessionToken: strings.TrimSpace(getEnv("AWS_SESSION_TOKEN", "")),
+		SecretsGCPAccessToken:  strings.TrimSpace(getEnv("GCP_ACCESS_TOKEN", "")),
+
 		// Setup Security
 		SetupKey: getEnv("SETUP_KEY", ""),
 	}
@@ -270,6 +548,11 @@ func New() *Config {
 		}
 	}
 
+	// The OpenAI translation provider can share the subtitle API key.
+	if c.TranslationProvider == "openai" && c.TranslationAPIKey == "" {
+		c.TranslationAPIKey = c.OpenAIAPIKey
+	}
+
 	successURL := strings.TrimSpace(getEnv("COURSE_CHECKOUT_SUCCESS_URL", ""))
 	cancelURL := strings.TrimSpace(getEnv("COURSE_CHECKOUT_CANCEL_URL", ""))
 
@@ -406,6 +689,21 @@ func getEnvAsInt64(key string, defaultValue int64) int64 {
 	return value
 }
 
+// getEnvAsDate reads a YYYY-MM-DD date as midnight UTC, or zero when it is unset
+// or malformed.
+func getEnvAsDate(key string) time.Time {
+	valueStr, ok := getEnvWithPresence(key)
+	if !ok {
+		return time.Time{}
+	}
+	value, err := time.Parse("2006-01-02", strings.TrimSpace(valueStr))
+	if err != nil {
+		log.Printf("WARNING: Ignoring %s=%q, expected a date such as 2027-01-31", key, valueStr)
+		return time.Time{}
+	}
+	return value
+}
+
 func getEnvAsFloat32Pointer(key string) *float32 {
 	valueStr, ok := getEnvWithPresence(key)
 	if !ok {
@@ -510,6 +808,39 @@ func getEnvAsBool(key string, defaultValue bool) bool {
 	return valueStr == "true" || valueStr == "1"
 }
 
+// parseImageVariantWidths returns the default widths when the variable is unset and
+// no widths, disabling variants, when it is set but empty.
+func parseImageVariantWidths(values []string) []int {
+	if values == nil {
+		return []int{320, 640, 1024, 1600}
+	}
+
+	widths := make([]int, 0, len(values))
+	for _, value := range values {
+		width, err := strconv.Atoi(value)
+		if err != nil || width <= 0 {
+			continue
+		}
+		widths = append(widths, width)
+	}
+	return widths
+}
+
+func parseImageVariantFormats(values []string) []string {
+	if values == nil {
+		return []string{"avif", "webp"}
+	}
+
+	formats := make([]string, 0, len(values))
+	for _, value := range values {
+		switch format := strings.ToLower(value); format {
+		case "avif", "webp":
+			formats = append(formats, format)
+		}
+	}
+	return formats
+}
+
 func getEnvAsSlice(key string) []string {
 	value, ok := getEnvWithPresence(key)
 	if !ok {
@@ -5,6 +5,14 @@ import (
 	"testing"
 )
 
+// newTestConfig loads the configuration from a temporary working directory,
+// so the JWT secret generated without JWT_SECRET is not written to the tree.
+func newTestConfig(t *testing.T) *Config {
+	t.Helper()
+	t.Chdir(t.TempDir())
+	return New()
+}
+
 func unsetEnv(t *testing.T, key string) {
 	t.Helper()
 	original, existed := os.LookupEnv(key)
@@ -25,7 +33,7 @@ func TestSubtitleGenerationAutoEnablesWithAPIKey(t *testing.T) {
 	t.Setenv("OPENAI_API_KEY", "test-openai-key")
 	t.Setenv("SUBTITLE_PROVIDER", "openai")
 
-	cfg := New()
+	cfg := newTestConfig(t)
 	if !cfg.SubtitleGenerationEnabled {
 		t.Fatalf("expected subtitle generation to auto-enable when API key is provided")
 	}
@@ -35,7 +43,7 @@ func TestSubtitleGenerationRespectsExplicitDisable(t *testing.T) {
 	t.Setenv("OPENAI_API_KEY", "test-openai-key")
 	t.Setenv("SUBTITLE_GENERATION_ENABLED", "false")
 
-	cfg := New()
+	cfg := newTestConfig(t)
 	if cfg.SubtitleGenerationEnabled {
 		t.Fatalf("expected subtitle generation to remain disabled when flag explicitly set")
 	}
@@ -45,7 +53,7 @@ func TestSubtitleGenerationRemainsDisabledWithoutAPIKey(t *testing.T) {
 	unsetEnv(t, "SUBTITLE_GENERATION_ENABLED")
 	unsetEnv(t, "OPENAI_API_KEY")
 
-	cfg := New()
+	cfg := newTestConfig(t)
 	if cfg.SubtitleGenerationEnabled {
 		t.Fatalf("expected subtitle generation to remain disabled without API key")
 	}
@@ -54,7 +62,7 @@ func TestSubtitleGenerationRemainsDisabledWithoutAPIKey(t *testing.T) {
 func TestFrameAncestorsDefaultsToSelf(t *testing.T) {
 	unsetEnv(t, "CSP_FRAME_ANCESTORS")
 
-	cfg := New()
+	cfg := newTestConfig(t)
 	if len(cfg.CSPFrameAncestors) != 1 || cfg.CSPFrameAncestors[0] != "'self'" {
 		t.Fatalf("expected frame ancestors to default to 'self', got %#v", cfg.CSPFrameAncestors)
 	}
@@ -63,7 +71,7 @@ func TestFrameAncestorsDefaultsToSelf(t *testing.T) {
 func TestFrameAncestorsNormalizeValues(t *testing.T) {
 	t.Setenv("CSP_FRAME_ANCESTORS", "self, https://example.com , 'none'")
 
-	cfg := New()
+	cfg := newTestConfig(t)
 	if len(cfg.CSPFrameAncestors) != 2 {
 		t.Fatalf("expected two normalized frame ancestor entries, got %#v", cfg.CSPFrameAncestors)
 	}
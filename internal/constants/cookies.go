@@ -3,4 +3,5 @@ package constants
 const (
 	AuthTokenCookieName = "auth_token"
 	CSRFTokenCookieName = "csrf_token"
+	ConsentCookieName   = "cookie_consent"
 )
@@ -0,0 +1,85 @@
+package csvexport
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newContext(target string) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	c.Request = httptest.NewRequest(http.MethodGet, target, nil)
+	return c, recorder
+}
+
+func TestWriterStreamsRows(t *testing.T) {
+	c, recorder := newContext("/api/v1/admin/users?format=CSV")
+	if !Requested(c) {
+		t.Fatal("expected format=CSV to request an export")
+	}
+
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.FixedZone("CET", 3600))
+	var parent *uint
+	export := New(c, "users", "id", "created_at", "name", "parent_id", "active")
+	if err := export.Row(uint(7), created, "=HYPERLINK(\"x\")", parent, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := export.Finish(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if recorder.Code != http.StatusOK || !strings.HasPrefix(recorder.Header().Get("Content-Type"), "text/csv") {
+		t.Fatalf("unexpected response %d %q", recorder.Code, recorder.Header().Get("Content-Type"))
+	}
+	if disposition := recorder.Header().Get("Content-Disposition"); !strings.Contains(disposition, `filename="users-`) {
+		t.Fatalf("unexpected disposition %q", disposition)
+	}
+	want := byteOrderMark + "id,created_at,name,parent_id,active\n7,2026-01-02T02:04:05Z,\"'=HYPERLINK(\"\"x\"\")\",,true\n"
+	if body := recorder.Body.String(); body != want {
+		t.Fatalf("unexpected body %q", body)
+	}
+}
+
+func TestFinishReturnsErrorsBeforeTheFirstRow(t *testing.T) {
+	c, recorder := newContext("/export?format=csv")
+	failure := errors.New("database unavailable")
+
+	export := New(c, "comments", "id")
+	if err := export.Finish(failure); !errors.Is(err, failure) {
+		t.Fatalf("expected the error to be returned, got %v", err)
+	}
+	if recorder.Body.Len() != 0 {
+		t.Fatalf("expected nothing to be written, got %q", recorder.Body.String())
+	}
+}
+
+func TestFinishMarksTruncatedExports(t *testing.T) {
+	c, recorder := newContext("/export?format=csv")
+
+	export := New(c, "comments", "id")
+	export.Row(1)
+	if err := export.Finish(errors.New("connection reset")); err != nil {
+		t.Fatalf("expected the error to be written into the file, got %v", err)
+	}
+	if body := recorder.Body.String(); !strings.HasSuffix(body, "1\nexport failed: connection reset\n") {
+		t.Fatalf("unexpected body %q", body)
+	}
+}
+
+func TestEmptyExportSendsHeader(t *testing.T) {
+	c, recorder := newContext("/export?format=csv")
+
+	if err := New(c, "grants", "id", "email").Finish(nil); err != nil {
+		t.Fatal(err)
+	}
+	if body := recorder.Body.String(); body != byteOrderMark+"id,email\n" {
+		t.Fatalf("unexpected body %q", body)
+	}
+}
@@ -0,0 +1,141 @@
+// Package events provides the in-process event bus used to notify subscribers
+// (webhooks, out-of-process plugins) about changes in the CMS.
+package events
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"constructor-script-backend/pkg/logger"
+)
+
+// Wildcard subscribes a handler to every event.
+const Wildcard = "*"
+
+// Event describes something that happened in the CMS.
+type Event struct {
+	Name       string                 `json:"event"`
+	OccurredAt time.Time              `json:"occurred_at"`
+	Payload    map[string]interface{} `json:"payload,omitempty"`
+}
+
+// Handler receives published events. Handlers run on their own goroutine and must not
+// assume the publisher is still waiting.
+type Handler func(ctx context.Context, event Event)
+
+type subscription struct {
+	id      uint64
+	handler Handler
+}
+
+// Bus fans published events out to subscribers.
+type Bus struct {
+	mu       sync.RWMutex
+	nextID   uint64
+	handlers map[string][]subscription
+
+	// target is set on deferred buses, which hold events in held until Flush.
+	target *Bus
+	held   []heldEvent
+}
+
+type heldEvent struct {
+	ctx     context.Context
+	name    string
+	payload map[string]interface{}
+}
+
+// NewBus creates an empty event bus.
+func NewBus() *Bus {
+	return &Bus{handlers: make(map[string][]subscription)}
+}
+
+// Subscribe registers handler for the named event (or Wildcard) and returns a
+// function that removes the subscription.
+func (b *Bus) Subscribe(name string, handler Handler) func() {
+	cleaned := strings.ToLower(strings.TrimSpace(name))
+	if b == nil || cleaned == "" || handler == nil {
+		return func() {}
+	}
+
+	b.mu.Lock()
+	b.nextID++
+	id := b.nextID
+	b.handlers[cleaned] = append(b.handlers[cleaned], subscription{id: id, handler: handler})
+	b.mu.Unlock()
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		subs := b.handlers[cleaned]
+		for i, sub := range subs {
+			if sub.id == id {
+				b.handlers[cleaned] = append(subs[:i:i], subs[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// Deferred returns a bus that holds the events published on it until Flush
+// hands them to b, for changes that only happen once a transaction commits.
+func (b *Bus) Deferred() *Bus {
+	return &Bus{handlers: make(map[string][]subscription), target: b}
+}
+
+// Flush publishes the events held by a deferred bus, in order, and forgets
+// them. Events of a rolled back transaction are never flushed.
+func (b *Bus) Flush() {
+	if b == nil || b.target == nil {
+		return
+	}
+	b.mu.Lock()
+	held := b.held
+	b.held = nil
+	b.mu.Unlock()
+
+	for _, event := range held {
+		b.target.Publish(event.ctx, event.name, event.payload)
+	}
+}
+
+// Publish delivers the event asynchronously to its subscribers. A panicking handler
+// is logged and does not affect other subscribers.
+func (b *Bus) Publish(ctx context.Context, name string, payload map[string]interface{}) {
+	cleaned := strings.ToLower(strings.TrimSpace(name))
+	if b == nil || cleaned == "" {
+		return
+	}
+	if b.target != nil {
+		b.mu.Lock()
+		b.held = append(b.held, heldEvent{ctx: ctx, name: cleaned, payload: payload})
+		b.mu.Unlock()
+		return
+	}
+
+	event := Event{Name: cleaned, OccurredAt: time.Now().UTC(), Payload: payload}
+
+	b.mu.RLock()
+	targets := make([]subscription, 0, len(b.handlers[cleaned])+len(b.handlers[Wildcard]))
+	targets = append(targets, b.handlers[cleaned]...)
+	targets = append(targets, b.handlers[Wildcard]...)
+	b.mu.RUnlock()
+
+	// Handlers outlive the request that published the event.
+	deliveryCtx := context.WithoutCancel(ctx)
+	for _, sub := range targets {
+		go func(handler Handler) {
+			defer func() {
+				if recovered := recover(); recovered != nil {
+					logger.Warn("Event handler panicked", map[string]interface{}{
+						"event": event.Name,
+						"panic": recovered,
+					})
+				}
+			}()
+			handler(deliveryCtx, event)
+		}(sub.handler)
+	}
+}
@@ -0,0 +1,46 @@
+package events
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDeferredBusHoldsEventsUntilFlush(t *testing.T) {
+	bus := NewBus()
+	received := make(chan string, 4)
+	bus.Subscribe(Wildcard, func(_ context.Context, event Event) {
+		received <- event.Name
+	})
+
+	deferred := bus.Deferred()
+	deferred.Publish(context.Background(), PageCreated, nil)
+	deferred.Publish(context.Background(), PageUpdated, nil)
+
+	select {
+	case name := <-received:
+		t.Fatalf("expected no delivery before Flush, got %s", name)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	deferred.Flush()
+	names := map[string]bool{}
+	for i := 0; i < 2; i++ {
+		select {
+		case name := <-received:
+			names[name] = true
+		case <-time.After(time.Second):
+			t.Fatal("expected the held events after Flush")
+		}
+	}
+	if !names[PageCreated] || !names[PageUpdated] {
+		t.Fatalf("unexpected events %v", names)
+	}
+
+	deferred.Flush()
+	select {
+	case name := <-received:
+		t.Fatalf("expected Flush to forget delivered events, got %s", name)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
@@ -0,0 +1,50 @@
+package events
+
+// Core event names.
+const (
+	PluginActivated   = "plugin.activated"
+	PluginDeactivated = "plugin.deactivated"
+
+	PageCreated = "page.created"
+	PageUpdated = "page.updated"
+	PageDeleted = "page.deleted"
+
+	PostCreated   = "post.created"
+	PostUpdated   = "post.updated"
+	PostPublished = "post.published"
+	PostDeleted   = "post.deleted"
+
+	CommentCreated = "comment.created"
+
+	ForumQuestionCreated = "forum.question_created"
+
+	CheckoutCompleted = "checkout.completed"
+
+	UserRegistered = "user.registered"
+
+	BackupCompleted          = "backup.completed"
+	BackupFailed             = "backup.failed"
+	BackupVerificationFailed = "backup.verification_failed"
+)
+
+// Names lists the core event names, used to validate subscriptions.
+func Names() []string {
+	return []string{
+		PluginActivated,
+		PluginDeactivated,
+		PageCreated,
+		PageUpdated,
+		PageDeleted,
+		PostCreated,
+		PostUpdated,
+		PostPublished,
+		PostDeleted,
+		CommentCreated,
+		ForumQuestionCreated,
+		CheckoutCompleted,
+		UserRegistered,
+		BackupCompleted,
+		BackupFailed,
+		BackupVerificationFailed,
+	}
+}
@@ -0,0 +1,184 @@
+// Package fieldset trims list responses to what a client asks for. ?fields=
+// names the fields to return and ?include= the relations, the nested objects
+// such as an author or the sections of a post, that are the bulk of a payload:
+//
+//	GET /api/v1/posts?fields=id,title,slug,published_at&include=category
+//
+// Without either parameter responses are unchanged.
+package fieldset
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Schema lists what a model can return.
+type Schema struct {
+	fields    map[string]bool
+	relations map[string]bool
+}
+
+// NewSchema reads the fields of model, a struct, from its JSON tags. relations
+// are the fields that ?include= controls.
+func NewSchema(model interface{}, relations ...string) *Schema {
+	schema := &Schema{fields: make(map[string]bool), relations: make(map[string]bool)}
+	collectFields(reflect.TypeOf(model), schema.fields)
+	for _, relation := range relations {
+		if !schema.fields[relation] {
+			panic(fmt.Sprintf("fieldset: %s has no field %q", reflect.TypeOf(model), relation))
+		}
+		schema.relations[relation] = true
+	}
+	return schema
+}
+
+func collectFields(t reflect.Type, fields map[string]bool) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if field.Anonymous && name == "" {
+			collectFields(field.Type, fields)
+			continue
+		}
+		if !field.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = true
+	}
+}
+
+// Error reports names a schema does not know.
+type Error struct {
+	Param   string
+	Unknown []string
+	Allowed []string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("unknown %s %s; allowed: %s", e.Param, strings.Join(e.Unknown, ", "), strings.Join(e.Allowed, ", "))
+}
+
+// Selection is what a request asked for. The zero Selection keeps everything.
+type Selection struct {
+	schema *Schema
+	// fields is nil when every field is kept.
+	fields map[string]bool
+	// include is nil when every relation is kept.
+	include map[string]bool
+}
+
+// FromQuery reads ?fields= and ?include= for schema.
+func FromQuery(c *gin.Context, schema *Schema) (Selection, error) {
+	selection := Selection{schema: schema}
+
+	if value, ok := c.GetQuery("fields"); ok {
+		names := splitNames(value)
+		if unknown := schema.unknown(names, schema.fields); len(unknown) > 0 {
+			return Selection{}, &Error{Param: "fields", Unknown: unknown, Allowed: sortedNames(schema.fields)}
+		}
+		selection.fields = map[string]bool{"id": true}
+		for _, name := range names {
+			selection.fields[name] = true
+		}
+	}
+
+	if value, ok := c.GetQuery("include"); ok {
+		names := splitNames(value)
+		if unknown := schema.unknown(names, schema.relations); len(unknown) > 0 {
+			return Selection{}, &Error{Param: "include", Unknown: unknown, Allowed: sortedNames(schema.relations)}
+		}
+		selection.include = make(map[string]bool, len(names))
+		for _, name := range names {
+			selection.include[name] = true
+		}
+	}
+
+	return selection, nil
+}
+
+func splitNames(value string) []string {
+	var names []string
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func (s *Schema) unknown(names []string, known map[string]bool) []string {
+	var unknown []string
+	for _, name := range names {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	return unknown
+}
+
+func sortedNames(set map[string]bool) []string {
+	names := make([]string, 0, len(set))
+	for name := range set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsZero reports whether the selection keeps everything.
+func (s Selection) IsZero() bool {
+	return s.fields == nil && s.include == nil
+}
+
+// keeps reports whether name stays in the response. A relation stays when
+// ?include= names it, or when ?fields= does and ?include= is absent.
+func (s Selection) keeps(name string) bool {
+	if s.schema.relations[name] {
+		if s.include != nil && s.include[name] {
+			return true
+		}
+		if s.include != nil && s.fields == nil {
+			return false
+		}
+	}
+	return s.fields == nil || s.fields[name]
+}
+
+// Apply returns items, a slice of models, with only the selected fields, or
+// items itself when the selection keeps everything.
+func (s Selection) Apply(items interface{}) (interface{}, error) {
+	if s.IsZero() {
+		return items, nil
+	}
+
+	encoded, err := json.Marshal(items)
+	if err != nil {
+		return nil, err
+	}
+	var objects []map[string]json.RawMessage
+	if err := json.Unmarshal(encoded, &objects); err != nil {
+		return nil, err
+	}
+	for _, object := range objects {
+		for name := range object {
+			if !s.keeps(name) {
+				delete(object, name)
+			}
+		}
+	}
+	if objects == nil {
+		objects = []map[string]json.RawMessage{}
+	}
+	return objects, nil
+}
@@ -0,0 +1,104 @@
+package fieldset
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type testAuthor struct {
+	Name string `json:"name"`
+}
+
+type testBase struct {
+	ID uint `json:"id"`
+}
+
+type testPost struct {
+	testBase
+	Title   string     `json:"title"`
+	Secret  string     `json:"-"`
+	Author  testAuthor `json:"author"`
+	Tags    []string   `json:"tags,omitempty"`
+	Content string     `json:"content"`
+}
+
+var testSchema = NewSchema(testPost{}, "author", "tags")
+
+func selectFields(t *testing.T, query string) []string {
+	t.Helper()
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/posts?"+query, nil)
+
+	selection, err := FromQuery(c, testSchema)
+	if err != nil {
+		t.Fatalf("%s: %v", query, err)
+	}
+	result, err := selection.Apply([]testPost{{testBase: testBase{ID: 1}, Title: "A", Author: testAuthor{Name: "x"}, Tags: []string{"go"}, Content: "long"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded, _ := json.Marshal(result)
+	var objects []map[string]interface{}
+	if err := json.Unmarshal(encoded, &objects); err != nil || len(objects) != 1 {
+		t.Fatalf("%s: unexpected result %s", query, encoded)
+	}
+	var names []string
+	for name := range objects[0] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestSelectionApply(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cases := map[string][]string{
+		"":                                {"author", "content", "id", "tags", "title"},
+		"fields=title":                    {"id", "title"},
+		"fields=title,author":             {"author", "id", "title"},
+		"include=tags":                    {"content", "id", "tags", "title"},
+		"include=":                        {"content", "id", "title"},
+		"fields=title&include=author":     {"author", "id", "title"},
+		"fields=title,%20tags&include=":   {"id", "tags", "title"},
+		"fields=id,content&include=tags,": {"content", "id", "tags"},
+	}
+	for query, want := range cases {
+		if got := selectFields(t, query); !reflect.DeepEqual(got, want) {
+			t.Errorf("%q: got %v, want %v", query, got, want)
+		}
+	}
+}
+
+func TestFromQueryRejectsUnknownNames(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	for _, query := range []string{"fields=title,secret", "include=content"} {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("GET", "/posts?"+query, nil)
+
+		_, err := FromQuery(c, testSchema)
+		var selectionErr *Error
+		if !errors.As(err, &selectionErr) || len(selectionErr.Unknown) != 1 {
+			t.Errorf("%q: got %v, want an error naming one field", query, err)
+		}
+	}
+}
+
+func TestSelectionApplyKeepsEmptyLists(t *testing.T) {
+	selection := Selection{schema: testSchema, fields: map[string]bool{"id": true}}
+	result, err := selection.Apply([]testPost{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if encoded, _ := json.Marshal(result); string(encoded) != "[]" {
+		t.Fatalf("expected an empty list, got %s", encoded)
+	}
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"net/http"
+
+	"constructor-script-backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AccessibilityHandler struct {
+	accessibilityService *service.AccessibilityService
+}
+
+func NewAccessibilityHandler(accessibilityService *service.AccessibilityService) *AccessibilityHandler {
+	return &AccessibilityHandler{accessibilityService: accessibilityService}
+}
+
+// ImageReport lists images without alt text in the media library and in content.
+func (h *AccessibilityHandler) ImageReport(c *gin.Context) {
+	report, err := h.accessibilityService.ImageReport()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"report": report})
+}
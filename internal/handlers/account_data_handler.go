@@ -0,0 +1,261 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/service"
+	"constructor-script-backend/pkg/apierror"
+	"constructor-script-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type AccountDataHandler struct {
+	service *service.AccountDataService
+	auth    *AuthHandler
+}
+
+// NewAccountDataHandler serves data exports and account deletion. The auth
+// handler signs users out once their account is deleted.
+func NewAccountDataHandler(svc *service.AccountDataService, auth *AuthHandler) *AccountDataHandler {
+	return &AccountDataHandler{service: svc, auth: auth}
+}
+
+func (h *AccountDataHandler) RequestExport(c *gin.Context) {
+	if h.service == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Data export not available"})
+		return
+	}
+
+	export, err := h.service.RequestExport(c.GetUint("user_id"))
+	if err != nil {
+		if errors.Is(err, service.ErrDataExportInProgress) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		logger.ErrorContext(c.Request.Context(), err, "Failed to start data export", nil)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start data export"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"export": export})
+}
+
+func (h *AccountDataHandler) ListExports(c *gin.Context) {
+	if h.service == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Data export not available"})
+		return
+	}
+
+	exports, err := h.service.Exports(c.GetUint("user_id"))
+	if err != nil {
+		logger.ErrorContext(c.Request.Context(), err, "Failed to list data exports", nil)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list data exports"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"exports": exports})
+}
+
+func (h *AccountDataHandler) DownloadExport(c *gin.Context) {
+	if h.service == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Data export not available"})
+		return
+	}
+
+	id, ok := parseWebhookID(c, "id")
+	if !ok {
+		return
+	}
+
+	export, err := h.service.Export(c.GetUint("user_id"), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Data export not found"})
+		case errors.Is(err, service.ErrDataExportNotReady):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			logger.ErrorContext(c.Request.Context(), err, "Failed to load data export", map[string]interface{}{"id": id})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load data export"})
+		}
+		return
+	}
+
+	filename := fmt.Sprintf("account-data-%s.zip", export.CreatedAt.Format("2006-01-02"))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "application/zip", export.Archive)
+}
+
+// DeleteAccount deletes the signed-in user's account, or files a deletion
+// request when an admin has to approve it.
+func (h *AccountDataHandler) DeleteAccount(c *gin.Context) {
+	if h.service == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Account deletion not available"})
+		return
+	}
+
+	var req models.DeleteAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BindError(c, err)
+		return
+	}
+
+	deleted, request, err := h.service.DeleteAccount(c.Request.Context(), c.GetUint("user_id"), req)
+	if err != nil {
+		var validationErr *service.AccountDataValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+		case errors.Is(err, service.ErrIncorrectPassword):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrAdminAccountDeletion):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrAccountDeletionPending):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			logger.ErrorContext(c.Request.Context(), err, "Failed to delete account", nil)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete account"})
+		}
+		return
+	}
+
+	if !deleted {
+		c.JSON(http.StatusAccepted, gin.H{
+			"message": "Account deletion requested; an administrator will review it",
+			"request": request,
+		})
+		return
+	}
+
+	if h.auth != nil {
+		h.auth.clearAuthCookie(c)
+		h.auth.clearCSRFCookie(c)
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Account deleted"})
+}
+
+func (h *AccountDataHandler) GetSettings(c *gin.Context) {
+	if h.service == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Account deletion not available"})
+		return
+	}
+
+	settings, err := h.service.GetSettings()
+	if err != nil {
+		logger.Error(err, "Failed to load account deletion settings", nil)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load account deletion settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"settings": settings})
+}
+
+func (h *AccountDataHandler) UpdateSettings(c *gin.Context) {
+	if h.service == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Account deletion not available"})
+		return
+	}
+
+	var req models.UpdateAccountDeletionSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BindError(c, err)
+		return
+	}
+
+	settings, err := h.service.UpdateSettings(req)
+	if err != nil {
+		var validationErr *service.AccountDataValidationError
+		if errors.As(err, &validationErr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		logger.Error(err, "Failed to update account deletion settings", nil)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update account deletion settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Account deletion settings updated", "settings": settings})
+}
+
+func (h *AccountDataHandler) ListDeletionRequests(c *gin.Context) {
+	if h.service == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Account deletion not available"})
+		return
+	}
+
+	requests, err := h.service.DeletionRequests(c.Query("status"))
+	if err != nil {
+		var validationErr *service.AccountDataValidationError
+		if errors.As(err, &validationErr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		logger.ErrorContext(c.Request.Context(), err, "Failed to list account deletion requests", nil)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list account deletion requests"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"requests": requests})
+}
+
+func (h *AccountDataHandler) ApproveDeletion(c *gin.Context) {
+	if h.service == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Account deletion not available"})
+		return
+	}
+
+	id, ok := parseWebhookID(c, "id")
+	if !ok {
+		return
+	}
+
+	request, err := h.service.ApproveDeletion(c.Request.Context(), id, c.GetUint("user_id"))
+	if err != nil {
+		h.reviewError(c, err, id)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Account deleted", "request": request})
+}
+
+func (h *AccountDataHandler) RejectDeletion(c *gin.Context) {
+	if h.service == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Account deletion not available"})
+		return
+	}
+
+	id, ok := parseWebhookID(c, "id")
+	if !ok {
+		return
+	}
+
+	request, err := h.service.RejectDeletion(id, c.GetUint("user_id"))
+	if err != nil {
+		h.reviewError(c, err, id)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Account deletion request rejected", "request": request})
+}
+
+func (h *AccountDataHandler) reviewError(c *gin.Context, err error, id uint) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Account deletion request not found"})
+	case errors.Is(err, service.ErrAccountDeletionReviewed):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		logger.ErrorContext(c.Request.Context(), err, "Failed to review account deletion request", map[string]interface{}{"id": id})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to review account deletion request"})
+	}
+}
@@ -6,6 +6,7 @@ import (
 
 	"constructor-script-backend/internal/models"
 	"constructor-script-backend/internal/service"
+	"constructor-script-backend/pkg/apierror"
 	"constructor-script-backend/pkg/logger"
 
 	"github.com/gin-gonic/gin"
@@ -48,7 +49,7 @@ func (h *AdvertisingHandler) Update(c *gin.Context) {
 
 	var req models.UpdateAdvertisingSettingsRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.BindError(c, err)
 		return
 	}
 
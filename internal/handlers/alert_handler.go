@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/service"
+	"constructor-script-backend/pkg/apierror"
+	"constructor-script-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AlertHandler struct {
+	service *service.AlertService
+}
+
+func NewAlertHandler(svc *service.AlertService) *AlertHandler {
+	return &AlertHandler{service: svc}
+}
+
+func (h *AlertHandler) Get(c *gin.Context) {
+	if h.service == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Alert service not available"})
+		return
+	}
+
+	settings, err := h.service.GetSettings()
+	if err != nil {
+		logger.Error(err, "Failed to load alert settings", nil)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load alert settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"settings": settings,
+		"alerts":   h.service.AlertNames(),
+	})
+}
+
+func (h *AlertHandler) Update(c *gin.Context) {
+	if h.service == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Alert service not available"})
+		return
+	}
+
+	var req models.UpdateAlertSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BindError(c, err)
+		return
+	}
+
+	settings, err := h.service.UpdateSettings(req)
+	if err != nil {
+		var validationErr *service.AlertValidationError
+		if errors.As(err, &validationErr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		logger.Error(err, "Failed to update alert settings", nil)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update alert settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Alert settings updated",
+		"settings": settings,
+	})
+}
+
+// Test posts a test alert to every channel.
+func (h *AlertHandler) Test(c *gin.Context) {
+	if h.service == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Alert service not available"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"channels": h.service.Test(c.Request.Context())})
+}
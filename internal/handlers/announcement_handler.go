@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/service"
+	"constructor-script-backend/pkg/apierror"
+	"constructor-script-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type AnnouncementHandler struct {
+	service *service.AnnouncementService
+}
+
+func NewAnnouncementHandler(announcementService *service.AnnouncementService) *AnnouncementHandler {
+	return &AnnouncementHandler{service: announcementService}
+}
+
+func (h *AnnouncementHandler) List(c *gin.Context) {
+	if h.service == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Announcement service not available"})
+		return
+	}
+
+	announcements, err := h.service.List()
+	if err != nil {
+		logger.Error(err, "Failed to load announcements", nil)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load announcements"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"announcements": announcements})
+}
+
+func (h *AnnouncementHandler) Create(c *gin.Context) {
+	if h.service == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Announcement service not available"})
+		return
+	}
+
+	var req models.CreateAnnouncementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BindError(c, err)
+		return
+	}
+
+	announcement, err := h.service.Create(req)
+	if err != nil {
+		h.writeError(c, err, "Failed to create announcement")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"announcement": announcement})
+}
+
+func (h *AnnouncementHandler) Update(c *gin.Context) {
+	if h.service == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Announcement service not available"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid announcement ID"})
+		return
+	}
+
+	var req models.UpdateAnnouncementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BindError(c, err)
+		return
+	}
+
+	announcement, err := h.service.Update(uint(id), req)
+	if err != nil {
+		h.writeError(c, err, "Failed to update announcement")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"announcement": announcement})
+}
+
+func (h *AnnouncementHandler) Delete(c *gin.Context) {
+	if h.service == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Announcement service not available"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid announcement ID"})
+		return
+	}
+
+	if err := h.service.Delete(uint(id)); err != nil {
+		h.writeError(c, err, "Failed to delete announcement")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Announcement deleted"})
+}
+
+// Dismiss hides an announcement from the signed-in user on every device.
+// POST /api/v1/announcements/:id/dismiss
+func (h *AnnouncementHandler) Dismiss(c *gin.Context) {
+	if h.service == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Announcement service not available"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid announcement ID"})
+		return
+	}
+
+	if err := h.service.Dismiss(c.GetUint("user_id"), uint(id)); err != nil {
+		h.writeError(c, err, "Failed to dismiss announcement")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Announcement dismissed"})
+}
+
+func (h *AnnouncementHandler) writeError(c *gin.Context, err error, message string) {
+	var validationErr *service.AnnouncementValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+	case errors.Is(err, service.ErrAnnouncementNotDismissible):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Announcement not found"})
+	default:
+		logger.Error(err, message, nil)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
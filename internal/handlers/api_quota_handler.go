@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"constructor-script-backend/internal/middleware"
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/service"
+	"constructor-script-backend/pkg/apierror"
+	"constructor-script-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxAPIUsageDays matches how long the daily usage is kept.
+const maxAPIUsageDays = 90
+
+type APIQuotaHandler struct {
+	service *service.APIQuotaService
+	tokens  *service.DeliveryTokenService
+}
+
+func NewAPIQuotaHandler(svc *service.APIQuotaService, tokens *service.DeliveryTokenService) *APIQuotaHandler {
+	return &APIQuotaHandler{service: svc, tokens: tokens}
+}
+
+func (h *APIQuotaHandler) Get(c *gin.Context) {
+	if h.service == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "API quota service not available"})
+		return
+	}
+
+	settings, err := h.service.GetSettings()
+	if err != nil {
+		logger.Error(err, "Failed to load API quota settings", nil)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load API quota settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"settings": settings})
+}
+
+func (h *APIQuotaHandler) Update(c *gin.Context) {
+	if h.service == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "API quota service not available"})
+		return
+	}
+
+	var req models.UpdateAPIQuotaSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BindError(c, err)
+		return
+	}
+
+	settings, err := h.service.UpdateSettings(req)
+	if err != nil {
+		logger.Error(err, "Failed to update API quota settings", nil)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update API quota settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "API quota settings updated",
+		"settings": settings,
+	})
+}
+
+// Usage lists the daily API usage of every user and delivery token, or of the
+// subject named by ?subject=, such as "user:12" or "token:3".
+func (h *APIQuotaHandler) Usage(c *gin.Context) {
+	if h.service == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "API quota service not available"})
+		return
+	}
+
+	days, ok := apiUsageDays(c)
+	if !ok {
+		return
+	}
+
+	usage, err := h.service.Usage(c.Request.Context(), strings.TrimSpace(c.Query("subject")), days)
+	if err != nil {
+		logger.ErrorContext(c.Request.Context(), err, "Failed to load API usage", nil)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load API usage"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"usage":                 usage,
+		"user_requests_per_day": h.service.UserRequestsPerDay(),
+	})
+}
+
+// ProfileUsage reports the quota and API usage of the signed-in user.
+func (h *APIQuotaHandler) ProfileUsage(c *gin.Context) {
+	if h.service == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "API quota service not available"})
+		return
+	}
+
+	subject := "user:" + strconv.FormatUint(uint64(c.GetUint("user_id")), 10)
+	h.report(c, subject, h.service.UserRequestsPerDay())
+}
+
+// DeliveryUsage reports the quota and API usage of the delivery token of the
+// request.
+func (h *APIQuotaHandler) DeliveryUsage(c *gin.Context) {
+	if h.service == nil || h.tokens == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "API quota service not available"})
+		return
+	}
+
+	id := middleware.DeliveryTokenID(c)
+	token, err := h.tokens.Get(id)
+	if err != nil {
+		logger.ErrorContext(c.Request.Context(), err, "Failed to load delivery token", map[string]interface{}{"token_id": id})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load API usage"})
+		return
+	}
+
+	h.report(c, middleware.DeliveryTokenSubject(id), token.RequestsPerDay)
+}
+
+func (h *APIQuotaHandler) report(c *gin.Context, subject string, limit int) {
+	days, ok := apiUsageDays(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.service.Report(c.Request.Context(), subject, limit, days)
+	if err != nil {
+		logger.ErrorContext(c.Request.Context(), err, "Failed to load API usage", map[string]interface{}{"subject": subject})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load API usage"})
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, report)
+}
+
+func apiUsageDays(c *gin.Context) (int, bool) {
+	days, err := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if err != nil || days < 1 || days > maxAPIUsageDays {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "days must be a number from 1 to 90"})
+		return 0, false
+	}
+	return days, true
+}
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/service"
+	"constructor-script-backend/pkg/apierror"
+	"constructor-script-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type AuditLogHandler struct {
+	service *service.AuditLogService
+}
+
+func NewAuditLogHandler(svc *service.AuditLogService) *AuditLogHandler {
+	return &AuditLogHandler{service: svc}
+}
+
+// List returns audit log entries, newest first. It accepts user_id, resource,
+// method, status, and from/to as RFC 3339 times or YYYY-MM-DD dates; a date in to
+// includes the whole day.
+func (h *AuditLogHandler) List(c *gin.Context) {
+	if h.service == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Audit log service not available"})
+		return
+	}
+
+	filter := models.AuditLogFilter{
+		Resource: c.Query("resource"),
+		Method:   c.Query("method"),
+	}
+	filter.Page, _ = strconv.Atoi(c.DefaultQuery("page", "1"))
+	filter.Limit, _ = strconv.Atoi(c.DefaultQuery("limit", "50"))
+
+	if value := c.Query("user_id"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user_id"})
+			return
+		}
+		filter.UserID = uint(id)
+	}
+	if value := c.Query("status"); value != "" {
+		status, err := strconv.Atoi(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid status"})
+			return
+		}
+		filter.Status = status
+	}
+
+	var ok bool
+	if filter.From, ok = parseAuditLogTime(c, "from", false); !ok {
+		return
+	}
+	if filter.To, ok = parseAuditLogTime(c, "to", true); !ok {
+		return
+	}
+
+	entries, total, err := h.service.List(&filter)
+	if err != nil {
+		logger.ErrorContext(c.Request.Context(), err, "Failed to list audit log", nil)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load audit log"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"entries": entries,
+		"total":   total,
+		"page":    filter.Page,
+		"limit":   filter.Limit,
+	})
+}
+
+func parseAuditLogTime(c *gin.Context, name string, endOfDay bool) (*time.Time, bool) {
+	value := strings.TrimSpace(c.Query(name))
+	if value == "" {
+		return nil, true
+	}
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return &parsed, true
+	}
+	parsed, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + name + " time"})
+		return nil, false
+	}
+	if endOfDay {
+		parsed = parsed.AddDate(0, 0, 1)
+	}
+	return &parsed, true
+}
+
+func (h *AuditLogHandler) Get(c *gin.Context) {
+	if h.service == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Audit log service not available"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid audit log id"})
+		return
+	}
+
+	entry, err := h.service.Get(uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "audit log entry not found"})
+			return
+		}
+		logger.ErrorContext(c.Request.Context(), err, "Failed to load audit log entry", map[string]interface{}{"id": id})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load audit log entry"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"entry": entry})
+}
+
+func (h *AuditLogHandler) GetSettings(c *gin.Context) {
+	if h.service == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Audit log service not available"})
+		return
+	}
+
+	settings, err := h.service.GetSettings()
+	if err != nil {
+		logger.Error(err, "Failed to load audit log settings", nil)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load audit log settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"settings": settings})
+}
+
+func (h *AuditLogHandler) UpdateSettings(c *gin.Context) {
+	if h.service == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Audit log service not available"})
+		return
+	}
+
+	var req models.UpdateAuditLogSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BindError(c, err)
+		return
+	}
+
+	settings, err := h.service.UpdateSettings(req)
+	if err != nil {
+		var validationErr *service.AuditLogValidationError
+		if errors.As(err, &validationErr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		logger.Error(err, "Failed to update audit log settings", nil)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update audit log settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Audit log settings updated",
+		"settings": settings,
+	})
+}
@@ -2,8 +2,11 @@ package handlers
 
 import (
 	"constructor-script-backend/internal/constants"
+	"constructor-script-backend/internal/csvexport"
+	"constructor-script-backend/internal/middleware"
 	"constructor-script-backend/internal/models"
 	"constructor-script-backend/internal/service"
+	"constructor-script-backend/pkg/apierror"
 	"crypto/rand"
 	"encoding/base64"
 	"errors"
@@ -134,12 +137,22 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	token, user, err := h.authService.Login(req)
+	token, user, challenge, err := h.authService.Login(req)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 		return
 	}
+	if challenge != nil {
+		c.JSON(http.StatusOK, challenge)
+		return
+	}
 
+	h.respondWithSession(c, token, user, nil)
+}
+
+// respondWithSession sets the session cookies of a signed-in user and returns
+// the token.
+func (h *AuthHandler) respondWithSession(c *gin.Context, token string, user *models.User, recoveryCodes []string) {
 	csrfToken, err := generateCSRFToken()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate CSRF token"})
@@ -150,9 +163,10 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	h.setCSRFCookie(c, csrfToken, authTokenTTLSeconds)
 
 	c.JSON(http.StatusOK, models.AuthResponse{
-		Token:     token,
-		User:      *user,
-		CSRFToken: csrfToken,
+		Token:         token,
+		User:          *user,
+		CSRFToken:     csrfToken,
+		RecoveryCodes: recoveryCodes,
 	})
 }
 
@@ -171,6 +185,11 @@ func (h *AuthHandler) GetAllUsers(c *gin.Context) {
 		}
 	}
 
+	if csvexport.Requested(c) {
+		h.exportUsers(c, query)
+		return
+	}
+
 	users, err := h.authService.GetAllUsers(query, limit)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -180,6 +199,23 @@ func (h *AuthHandler) GetAllUsers(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"users": users})
 }
 
+// exportUsers streams every user matching query as CSV. Unlike the JSON list,
+// a search is not capped.
+func (h *AuthHandler) exportUsers(c *gin.Context, query string) {
+	export := csvexport.New(c, "users", "id", "created_at", "username", "email", "role", "status")
+	err := h.authService.UserBatches(query, func(users []models.User) error {
+		for _, user := range users {
+			if err := export.Row(user.ID, user.CreatedAt, user.Username, user.Email, string(user.Role), user.Status); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err := export.Finish(err); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
 func (h *AuthHandler) DeleteUser(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
@@ -187,6 +223,7 @@ func (h *AuthHandler) DeleteUser(c *gin.Context) {
 		return
 	}
 
+	h.auditUserBefore(c, uint(id))
 	if err := h.authService.DeleteUser(uint(id)); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -195,6 +232,20 @@ func (h *AuthHandler) DeleteUser(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "user deleted successfully"})
 }
 
+// auditUserBefore records the account an admin is about to change for the audit log.
+func (h *AuthHandler) auditUserBefore(c *gin.Context, id uint) {
+	user, err := h.authService.GetUserByID(id)
+	if err != nil || user == nil {
+		return
+	}
+	middleware.SetAuditBefore(c, gin.H{
+		"username": user.Username,
+		"email":    user.Email,
+		"role":     user.Role,
+		"status":   user.Status,
+	})
+}
+
 func (h *AuthHandler) UpdateUserRole(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
@@ -207,10 +258,11 @@ func (h *AuthHandler) UpdateUserRole(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.BindError(c, err)
 		return
 	}
 
+	h.auditUserBefore(c, uint(id))
 	if err := h.authService.UpdateUserRole(uint(id), req.Role); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -0,0 +1,240 @@
+package handlers
+
+import (
+	"errors"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/service"
+	"constructor-script-backend/pkg/apierror"
+	"constructor-script-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	oauthStateCookieName = "oauth_state"
+	oauthStateCookiePath = "/api/v1/auth/oauth"
+	oauthStateMaxAge     = 10 * 60
+)
+
+// oauthLoginErrors are the messages the login page shows for the error codes
+// OAuth callbacks redirect with.
+var oauthLoginErrors = map[string]string{
+	"oauth_state":      service.ErrOAuthInvalidState.Error(),
+	"oauth_no_account": "No account is linked to this login. Sign in with your password and link it from your profile.",
+	"oauth_taken":      service.ErrOAuthIdentityTaken.Error(),
+	"oauth_failed":     "Signing in with that provider failed. Please try again.",
+}
+
+// oauthRedirectPage sends the browser on after a login. A page is used instead
+// of a redirect so the next request is same-site and carries the SameSite
+// strict auth cookie.
+var oauthRedirectPage = template.Must(template.New("oauth").Parse(
+	`<!doctype html><html><head><meta charset="utf-8"><meta http-equiv="refresh" content="0;url={{.}}"><title>Signing in…</title></head>` +
+		`<body><p><a href="{{.}}">Continue</a></p></body></html>`))
+
+// setOAuthStateCookie stores the login state for the callback. It is lax, not
+// strict, because the callback is a navigation from the provider's site. It is
+// secure whenever the callback is reached over HTTPS, which behind a TLS
+// terminating proxy the request itself does not show.
+func (h *AuthHandler) setOAuthStateCookie(c *gin.Context, value string, maxAge int) {
+	secure := requestScheme(c.Request) == "https" || strings.HasPrefix(h.authService.OAuthRedirectURI(""), "https://")
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oauthStateCookieName, value, maxAge, oauthStateCookiePath, "", secure, true)
+}
+
+func (h *AuthHandler) oauthFailure(c *gin.Context, err error) {
+	code := "oauth_failed"
+	switch {
+	case errors.Is(err, service.ErrOAuthInvalidState):
+		code = "oauth_state"
+	case errors.Is(err, service.ErrOAuthNoAccount):
+		code = "oauth_no_account"
+	case errors.Is(err, service.ErrOAuthIdentityTaken):
+		code = "oauth_taken"
+	case errors.Is(err, service.ErrOAuthProviderNotFound), errors.Is(err, service.ErrOAuthUnavailable):
+	default:
+		logger.ErrorContext(c.Request.Context(), err, "OAuth login failed", map[string]interface{}{"provider": c.Param("provider")})
+	}
+	c.Redirect(http.StatusFound, "/login?error="+code)
+}
+
+// OAuthProviders lists the providers visitors can sign in with.
+func (h *AuthHandler) OAuthProviders(c *gin.Context) {
+	providers, err := h.authService.OAuthProviders()
+	if err != nil {
+		logger.ErrorContext(c.Request.Context(), err, "Failed to list login providers", nil)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list login providers"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"providers": providers})
+}
+
+// OAuthStart sends the visitor to the provider to sign in.
+func (h *AuthHandler) OAuthStart(c *gin.Context) {
+	authURL, state, err := h.authService.StartOAuth(c.Request.Context(), c.Param("provider"), 0, c.Query("next"))
+	if err != nil {
+		h.oauthFailure(c, err)
+		return
+	}
+	h.setOAuthStateCookie(c, state, oauthStateMaxAge)
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// OAuthLink starts linking a provider to the signed-in user. It returns the
+// provider URL for the client to navigate to.
+func (h *AuthHandler) OAuthLink(c *gin.Context) {
+	next := c.Query("next")
+	if next == "" {
+		next = "/profile"
+	}
+	authURL, state, err := h.authService.StartOAuth(c.Request.Context(), c.Param("provider"), c.GetUint("user_id"), next)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrOAuthProviderNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrOAuthUnavailable):
+			c.JSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+		default:
+			logger.ErrorContext(c.Request.Context(), err, "Failed to start linking login provider", nil)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start linking login provider"})
+		}
+		return
+	}
+	h.setOAuthStateCookie(c, state, oauthStateMaxAge)
+	c.JSON(http.StatusOK, gin.H{"url": authURL})
+}
+
+// OAuthCallback finishes a login or link when the provider sends the user
+// back.
+func (h *AuthHandler) OAuthCallback(c *gin.Context) {
+	state, _ := c.Cookie(oauthStateCookieName)
+	h.setOAuthStateCookie(c, "", -1)
+
+	if providerError := c.Query("error"); providerError != "" {
+		logger.Info("OAuth login cancelled at provider", map[string]interface{}{
+			"provider": c.Param("provider"),
+			"error":    providerError,
+		})
+		c.Redirect(http.StatusFound, "/login?error=oauth_failed")
+		return
+	}
+
+	result, err := h.authService.CompleteOAuth(c.Request.Context(), c.Param("provider"), c.Query("code"), c.Query("state"), state)
+	if err != nil {
+		h.oauthFailure(c, err)
+		return
+	}
+
+	if result.Linked {
+		next := result.Next
+		if next == "" {
+			next = "/profile"
+		}
+		c.Redirect(http.StatusFound, next)
+		return
+	}
+
+	if result.Challenge != nil {
+		h.setTwoFactorChallengeCookie(c, result.Challenge.ChallengeToken, int(time.Until(result.Challenge.ExpiresAt).Seconds()))
+		step := "verify"
+		if result.Challenge.EnrollmentRequired {
+			step = "enroll"
+		}
+		query := url.Values{"two_factor": {step}}
+		if result.Next != "" {
+			query.Set("redirect", result.Next)
+		}
+		c.Redirect(http.StatusFound, "/login?"+query.Encode())
+		return
+	}
+
+	csrfToken, err := generateCSRFToken()
+	if err != nil {
+		h.oauthFailure(c, err)
+		return
+	}
+	h.setAuthCookie(c, result.Token, authTokenTTLSeconds)
+	h.setCSRFCookie(c, csrfToken, authTokenTTLSeconds)
+
+	next := result.Next
+	if next == "" {
+		next = "/profile"
+	}
+	c.Header("Cache-Control", "no-store")
+	c.Status(http.StatusOK)
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	if err := oauthRedirectPage.Execute(c.Writer, next); err != nil {
+		logger.ErrorContext(c.Request.Context(), err, "Failed to render login redirect", nil)
+	}
+}
+
+// OAuthUnlink removes a provider from the signed-in user.
+func (h *AuthHandler) OAuthUnlink(c *gin.Context) {
+	if err := h.authService.UnlinkIdentity(c.GetUint("user_id"), c.Param("provider")); err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Login provider is not linked"})
+		case errors.Is(err, service.ErrOAuthUnavailable):
+			c.JSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+		default:
+			logger.ErrorContext(c.Request.Context(), err, "Failed to unlink login provider", nil)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlink login provider"})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Login provider unlinked"})
+}
+
+// Identities lists the providers linked to the signed-in user.
+func (h *AuthHandler) Identities(c *gin.Context) {
+	identities, err := h.authService.UserIdentities(c.GetUint("user_id"))
+	if err != nil {
+		logger.ErrorContext(c.Request.Context(), err, "Failed to list linked login providers", nil)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list linked login providers"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"identities": identities})
+}
+
+func (h *AuthHandler) GetOAuthSettings(c *gin.Context) {
+	settings, err := h.authService.GetOAuthSettings()
+	if err != nil {
+		logger.Error(err, "Failed to load login provider settings", nil)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load login provider settings"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"settings": settings, "callback_url": h.authService.OAuthRedirectURI("{slug}")})
+}
+
+func (h *AuthHandler) UpdateOAuthSettings(c *gin.Context) {
+	var req models.UpdateOAuthSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BindError(c, err)
+		return
+	}
+
+	settings, err := h.authService.UpdateOAuthSettings(req)
+	if err != nil {
+		var validationErr *service.OAuthValidationError
+		if errors.As(err, &validationErr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		logger.Error(err, "Failed to update login provider settings", nil)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update login provider settings"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Login providers updated",
+		"settings":     settings,
+		"callback_url": h.authService.OAuthRedirectURI("{slug}"),
+	})
+}
@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/service"
+	"constructor-script-backend/pkg/apierror"
+	"constructor-script-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	twoFactorChallengeCookieName = "two_factor_challenge"
+	twoFactorChallengeCookiePath = "/api/v1/login/2fa"
+)
+
+// setTwoFactorChallengeCookie keeps the challenge of an OAuth login, which
+// reaches the login page through a redirect rather than a JSON response.
+func (h *AuthHandler) setTwoFactorChallengeCookie(c *gin.Context, value string, maxAge int) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(twoFactorChallengeCookieName, value, maxAge, twoFactorChallengeCookiePath, "", c.Request.TLS != nil, true)
+}
+
+func (h *AuthHandler) challengeToken(c *gin.Context, token string) string {
+	if token != "" {
+		return token
+	}
+	cookie, _ := c.Cookie(twoFactorChallengeCookieName)
+	return cookie
+}
+
+// twoFactorError answers with the status matching err and reports whether
+// there was an error.
+func (h *AuthHandler) twoFactorError(c *gin.Context, err error, message string) bool {
+	if err == nil {
+		return false
+	}
+	var validationErr *service.TwoFactorValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+	case errors.Is(err, service.ErrTwoFactorUnavailable):
+		c.JSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrTwoFactorInvalidChallenge),
+		errors.Is(err, service.ErrInvalidTwoFactorCode),
+		errors.Is(err, service.ErrIncorrectPassword):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrTwoFactorLocked):
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrTwoFactorAlreadyEnabled),
+		errors.Is(err, service.ErrTwoFactorNotEnrolled),
+		errors.Is(err, service.ErrTwoFactorRequired):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	default:
+		logger.ErrorContext(c.Request.Context(), err, message, nil)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+	return true
+}
+
+// LoginTwoFactor finishes a login with an authenticator or recovery code.
+func (h *AuthHandler) LoginTwoFactor(c *gin.Context) {
+	var req models.TwoFactorLoginRequest
+	if err := bindAuthRequest(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	token, user, recoveryCodes, err := h.authService.CompleteTwoFactorLogin(h.challengeToken(c, req.ChallengeToken), req.Code)
+	if h.twoFactorError(c, err, "Failed to sign in") {
+		return
+	}
+	h.setTwoFactorChallengeCookie(c, "", -1)
+	h.respondWithSession(c, token, user, recoveryCodes)
+}
+
+// LoginTwoFactorEnroll sets up an authenticator during a login for a role that
+// requires one.
+func (h *AuthHandler) LoginTwoFactorEnroll(c *gin.Context) {
+	var req models.TwoFactorChallengeRequest
+	if err := bindAuthRequest(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	enrollment, err := h.authService.BeginChallengeEnrollment(h.challengeToken(c, req.ChallengeToken))
+	if h.twoFactorError(c, err, "Failed to set up two-factor authentication") {
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, enrollment)
+}
+
+// TwoFactorStatus reports the signed-in user's two-factor authentication.
+func (h *AuthHandler) TwoFactorStatus(c *gin.Context) {
+	status, err := h.authService.TwoFactorStatus(c.GetUint("user_id"))
+	if h.twoFactorError(c, err, "Failed to load two-factor authentication") {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"two_factor": status})
+}
+
+// EnrollTwoFactor starts setting up an authenticator for the signed-in user.
+func (h *AuthHandler) EnrollTwoFactor(c *gin.Context) {
+	enrollment, err := h.authService.BeginTwoFactorEnrollment(c.GetUint("user_id"))
+	if h.twoFactorError(c, err, "Failed to set up two-factor authentication") {
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, enrollment)
+}
+
+// ConfirmTwoFactor turns two-factor authentication on once the user enters a
+// code from their new authenticator.
+func (h *AuthHandler) ConfirmTwoFactor(c *gin.Context) {
+	var req models.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BindError(c, err)
+		return
+	}
+
+	codes, err := h.authService.ConfirmTwoFactor(c.GetUint("user_id"), req.Code)
+	if h.twoFactorError(c, err, "Failed to enable two-factor authentication") {
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": codes})
+}
+
+// RegenerateRecoveryCodes replaces the signed-in user's recovery codes.
+func (h *AuthHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	var req models.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BindError(c, err)
+		return
+	}
+
+	codes, err := h.authService.RegenerateRecoveryCodes(c.GetUint("user_id"), req.Code)
+	if h.twoFactorError(c, err, "Failed to create recovery codes") {
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": codes})
+}
+
+// DisableTwoFactor turns two-factor authentication off for the signed-in user.
+func (h *AuthHandler) DisableTwoFactor(c *gin.Context) {
+	var req models.DisableTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BindError(c, err)
+		return
+	}
+
+	err := h.authService.DisableTwoFactor(c.GetUint("user_id"), req.Password)
+	if h.twoFactorError(c, err, "Failed to disable two-factor authentication") {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+// ResetUserTwoFactor removes the authenticator of a user who lost theirs.
+func (h *AuthHandler) ResetUserTwoFactor(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	h.auditUserBefore(c, uint(id))
+	err = h.authService.ResetTwoFactor(uint(id))
+	if h.twoFactorError(c, err, "Failed to reset two-factor authentication") {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication reset"})
+}
+
+func (h *AuthHandler) GetTwoFactorSettings(c *gin.Context) {
+	settings, err := h.authService.GetTwoFactorSettings()
+	if h.twoFactorError(c, err, "Failed to load two-factor settings") {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"settings": settings})
+}
+
+func (h *AuthHandler) UpdateTwoFactorSettings(c *gin.Context) {
+	var req models.UpdateTwoFactorSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BindError(c, err)
+		return
+	}
+
+	settings, err := h.authService.UpdateTwoFactorSettings(req)
+	if h.twoFactorError(c, err, "Failed to save two-factor settings") {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"settings": settings})
+}
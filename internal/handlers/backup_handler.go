@@ -8,8 +8,10 @@ import (
 	"strings"
 	"time"
 
+	"constructor-script-backend/internal/middleware"
 	"constructor-script-backend/internal/models"
 	"constructor-script-backend/internal/service"
+	"constructor-script-backend/pkg/apierror"
 	"constructor-script-backend/pkg/logger"
 
 	"github.com/gin-gonic/gin"
@@ -48,7 +50,7 @@ func (h *BackupHandler) UpdateSettings(c *gin.Context) {
 	var req models.UpdateBackupSettingsRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		logger.Error(err, "Failed to parse backup settings request", nil)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		apierror.BindError(c, err)
 		return
 	}
 
@@ -68,6 +70,101 @@ func (h *BackupHandler) UpdateSettings(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Backup settings updated", "settings": settings})
 }
 
+// List returns the stored backups, local and in object storage, together with the
+// automatic backup settings including the last and next run.
+func (h *BackupHandler) List(c *gin.Context) {
+	if h == nil || h.service == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Backup service not available"})
+		return
+	}
+
+	backups, err := h.service.ListBackups(c.Request.Context())
+	if err != nil {
+		logger.Error(err, "Failed to list backups", nil)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list backups"})
+		return
+	}
+
+	settings, err := h.service.GetAutoSettings()
+	if err != nil {
+		logger.Error(err, "Failed to load backup settings", nil)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load backup settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"backups":      backups,
+		"settings":     settings,
+		"verification": h.service.LastVerification(),
+	})
+}
+
+// Verify checks the latest stored backup now instead of waiting for the scheduled
+// verification.
+func (h *BackupHandler) Verify(c *gin.Context) {
+	if h == nil || h.service == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Backup service not available"})
+		return
+	}
+
+	result, err := h.service.VerifyLatest(c.Request.Context())
+	if errors.Is(err, service.ErrNoBackups) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No stored backups to verify"})
+		return
+	}
+	// A failed check is a result, not a request error; it is reported in the body.
+	c.JSON(http.StatusOK, gin.H{"verification": result})
+}
+
+// Download streams a stored backup archive.
+func (h *BackupHandler) Download(c *gin.Context) {
+	if h == nil || h.service == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Backup service not available"})
+		return
+	}
+
+	reader, backup, err := h.service.OpenBackup(c.Request.Context(), c.Param("location"), c.Param("name"))
+	if err != nil {
+		if errors.Is(err, service.ErrBackupNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Backup not found"})
+			return
+		}
+		logger.Error(err, "Failed to open backup", map[string]interface{}{"location": c.Param("location"), "name": c.Param("name")})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open backup"})
+		return
+	}
+	defer reader.Close()
+
+	contentType := "application/zip"
+	if backup.Encrypted {
+		contentType = "application/octet-stream"
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", backup.Name))
+	c.Header("X-Backup-Encrypted", strconv.FormatBool(backup.Encrypted))
+	c.DataFromReader(http.StatusOK, backup.Size, contentType, reader, nil)
+}
+
+// Delete removes a stored backup archive.
+func (h *BackupHandler) Delete(c *gin.Context) {
+	if h == nil || h.service == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Backup service not available"})
+		return
+	}
+
+	if err := h.service.DeleteBackup(c.Request.Context(), c.Param("location"), c.Param("name")); err != nil {
+		if errors.Is(err, service.ErrBackupNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Backup not found"})
+			return
+		}
+		logger.Error(err, "Failed to delete backup", map[string]interface{}{"location": c.Param("location"), "name": c.Param("name")})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete backup"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Backup deleted"})
+}
+
 func (h *BackupHandler) Export(c *gin.Context) {
 	if h == nil || h.service == nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Backup service not available"})
@@ -147,7 +244,14 @@ func (h *BackupHandler) Import(c *gin.Context) {
 	}
 	defer uploaded.Close()
 
-	summary, restoreErr := h.service.RestoreArchive(c.Request.Context(), uploaded, fileHeader.Size)
+	summary, restoreErr := h.service.RestoreArchive(c.Request.Context(), uploaded, fileHeader.Size, service.BackupRestoreOptions{
+		Filename: fileHeader.Filename,
+		UserID:   c.GetUint("user_id"),
+		Username: c.GetString("username"),
+	})
+	if summary.RestoreID != 0 {
+		middleware.SetAuditBefore(c, gin.H{"restore_id": summary.RestoreID, "archive": fileHeader.Filename})
+	}
 	if restoreErr != nil {
 		status := http.StatusInternalServerError
 		errorMsg := "Failed to restore backup"
@@ -182,6 +286,7 @@ func (h *BackupHandler) Import(c *gin.Context) {
 		"social_links":   summary.SocialLinks,
 		"post_tags":      summary.PostTags,
 		"uploads":        summary.Uploads,
+		"restore_id":     summary.RestoreID,
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -189,3 +294,112 @@ func (h *BackupHandler) Import(c *gin.Context) {
 		"summary": responseSummary,
 	})
 }
+
+// ListRestores returns the latest restores, without their changes.
+func (h *BackupHandler) ListRestores(c *gin.Context) {
+	if h == nil || h.service == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Backup service not available"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	restores, err := h.service.ListRestores(limit)
+	if err != nil {
+		logger.ErrorContext(c.Request.Context(), err, "Failed to list backup restores", nil)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list backup restores"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"restores": restores})
+}
+
+// GetRestore returns a restore with the rows and uploads it added, removed and
+// changed.
+func (h *BackupHandler) GetRestore(c *gin.Context) {
+	if h == nil || h.service == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Backup service not available"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid restore ID"})
+		return
+	}
+
+	restore, err := h.service.GetRestore(uint(id))
+	if err != nil {
+		if errors.Is(err, service.ErrBackupRestoreNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Restore not found"})
+			return
+		}
+		logger.ErrorContext(c.Request.Context(), err, "Failed to load backup restore", nil)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load backup restore"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"restore": restore})
+}
+
+// ExportSite downloads a portable bundle of the site for another instance.
+func (h *BackupHandler) ExportSite(c *gin.Context) {
+	if h == nil || h.service == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Backup service not available"})
+		return
+	}
+
+	archive, err := h.service.ExportSite(c.Request.Context())
+	if err != nil {
+		logger.ErrorContext(c.Request.Context(), err, "Failed to export site", nil)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export site"})
+		return
+	}
+	defer archive.Close()
+
+	c.Header("Content-Type", archive.ContentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", archive.Filename))
+	http.ServeContent(c.Writer, c.Request, archive.Filename, archive.Summary.GeneratedAt, archive.File())
+}
+
+// ImportSite merges an uploaded site export into this site. Authors without an
+// account here are replaced by the importing user; overwrite_settings=true lets
+// the bundle replace existing settings and theme overrides.
+func (h *BackupHandler) ImportSite(c *gin.Context) {
+	if h == nil || h.service == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Backup service not available"})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Export file is required"})
+		return
+	}
+	overwrite, _ := strconv.ParseBool(c.PostForm("overwrite_settings"))
+
+	uploaded, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to open uploaded file"})
+		return
+	}
+	defer uploaded.Close()
+
+	report, err := h.service.ImportSite(c.Request.Context(), uploaded, service.SiteImportOptions{
+		FallbackAuthorID:  c.GetUint("user_id"),
+		OverwriteSettings: overwrite,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidSiteExport):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrBackupVersion):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Export format is not supported"})
+		default:
+			logger.ErrorContext(c.Request.Context(), err, "Failed to import site export", nil)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import site export"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Site export imported", "report": report})
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/service"
+	"constructor-script-backend/pkg/apierror"
+	"constructor-script-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type BlockRuleHandler struct {
+	service *service.BlockRuleService
+}
+
+func NewBlockRuleHandler(svc *service.BlockRuleService) *BlockRuleHandler {
+	return &BlockRuleHandler{service: svc}
+}
+
+func (h *BlockRuleHandler) List(c *gin.Context) {
+	if h.service == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Block rules not available"})
+		return
+	}
+
+	rules, err := h.service.List()
+	if err != nil {
+		logger.ErrorContext(c.Request.Context(), err, "Failed to list block rules", nil)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list block rules"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rules": rules})
+}
+
+func (h *BlockRuleHandler) Create(c *gin.Context) {
+	if h.service == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Block rules not available"})
+		return
+	}
+
+	var req models.CreateBlockRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BindError(c, err)
+		return
+	}
+
+	rule, err := h.service.Create(req)
+	if err != nil {
+		var validationErr *service.BlockRuleValidationError
+		if errors.As(err, &validationErr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		logger.ErrorContext(c.Request.Context(), err, "Failed to create block rule", nil)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create block rule"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"rule": rule})
+}
+
+func (h *BlockRuleHandler) Delete(c *gin.Context) {
+	if h.service == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Block rules not available"})
+		return
+	}
+
+	id, ok := parseWebhookID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Block rule not found"})
+			return
+		}
+		logger.ErrorContext(c.Request.Context(), err, "Failed to delete block rule", map[string]interface{}{"id": id})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete block rule"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Block rule deleted"})
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"constructor-script-backend/internal/authorization"
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/service"
+	"constructor-script-backend/pkg/apierror"
+	"constructor-script-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bulkResourcePermissions lists the resources whose own endpoints need more
+// than the content permission the bulk route requires.
+var bulkResourcePermissions = map[string]authorization.Permission{
+	"menu_items": authorization.PermissionManageSettings,
+}
+
+type BulkHandler struct {
+	service *service.BulkService
+}
+
+func NewBulkHandler(svc *service.BulkService) *BulkHandler {
+	return &BulkHandler{service: svc}
+}
+
+// Execute applies a batch of operations in one transaction and reports the
+// result of each.
+func (h *BulkHandler) Execute(c *gin.Context) {
+	if h.service == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Bulk service not available"})
+		return
+	}
+
+	var req models.BulkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BindError(c, err)
+		return
+	}
+
+	roleValue, _ := c.Get("role")
+	role, _ := authorization.ParseUserRole(roleValue)
+	for i, op := range req.Operations {
+		if permission, ok := bulkResourcePermissions[op.Resource]; ok && !authorization.RoleHasPermission(role, permission) {
+			c.JSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("operation %d: %s requires the %s permission", i, op.Resource, permission)})
+			return
+		}
+	}
+
+	actor := service.BulkActor{
+		UserID:       c.GetUint("user_id"),
+		CanManageAll: authorization.RoleHasPermission(role, authorization.PermissionManageAllContent),
+	}
+	report, err := h.service.Execute(c.Request.Context(), req, actor)
+	if err != nil {
+		if errors.Is(err, service.ErrBulkFailed) {
+			failed := failedBulkResult(report)
+			c.JSON(http.StatusUnprocessableEntity, gin.H{
+				"error":     fmt.Sprintf("operation %d failed: %s; no changes were made", failed.Index, failed.Error),
+				"committed": false,
+				"results":   report.Results,
+			})
+			return
+		}
+
+		logger.Error(err, "Failed to execute bulk request", nil)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to execute bulk request"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+func failedBulkResult(report service.BulkReport) service.BulkResult {
+	for _, result := range report.Results {
+		if result.Status == service.BulkFailed {
+			return result
+		}
+	}
+	return service.BulkResult{}
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"constructor-script-backend/internal/constants"
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/service"
+	"constructor-script-backend/pkg/apierror"
+	"constructor-script-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ConsentHandler struct {
+	service *service.ConsentService
+}
+
+func NewConsentHandler(svc *service.ConsentService) *ConsentHandler {
+	return &ConsentHandler{service: svc}
+}
+
+// State returns the current visitor's consent so themes can decide which
+// scripts to load.
+func (h *ConsentHandler) State(c *gin.Context) {
+	if h.service == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Consent service not available"})
+		return
+	}
+
+	cookie, _ := c.Cookie(constants.ConsentCookieName)
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, gin.H{"consent": h.service.State(cookie)})
+}
+
+// Update records the choice the visitor made in the consent banner. The cookie
+// is readable by scripts so themes can check it without a request.
+func (h *ConsentHandler) Update(c *gin.Context) {
+	if h.service == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Consent service not available"})
+		return
+	}
+
+	var req models.UpdateConsentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BindError(c, err)
+		return
+	}
+
+	cookie, _ := c.Cookie(constants.ConsentCookieName)
+	state, value, err := h.service.Record(cookie, req)
+	if err != nil {
+		var validationErr *service.ConsentValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+		case errors.Is(err, service.ErrConsentDisabled):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			logger.ErrorContext(c.Request.Context(), err, "Failed to record consent", nil)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record consent"})
+		}
+		return
+	}
+
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(constants.ConsentCookieName, value, service.ConsentCookieMaxAge, "/", "", c.Request.TLS != nil, false)
+	c.JSON(http.StatusOK, gin.H{"message": "Consent saved", "consent": state})
+}
+
+func (h *ConsentHandler) GetSettings(c *gin.Context) {
+	if h.service == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Consent service not available"})
+		return
+	}
+
+	settings, err := h.service.GetSettings()
+	if err != nil {
+		logger.Error(err, "Failed to load consent settings", nil)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load consent settings"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"settings": settings})
+}
+
+func (h *ConsentHandler) UpdateSettings(c *gin.Context) {
+	if h.service == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Consent service not available"})
+		return
+	}
+
+	var req models.UpdateConsentSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BindError(c, err)
+		return
+	}
+
+	settings, err := h.service.UpdateSettings(req)
+	if err != nil {
+		var validationErr *service.ConsentValidationError
+		if errors.As(err, &validationErr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		logger.Error(err, "Failed to update consent settings", nil)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update consent settings"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Consent settings updated", "settings": settings})
+}
+
+// Records lists the choices a visitor made, identified by the visitor ID in
+// their consent cookie.
+func (h *ConsentHandler) Records(c *gin.Context) {
+	if h.service == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Consent service not available"})
+		return
+	}
+
+	visitor := c.Query("visitor")
+	if visitor == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "visitor is required"})
+		return
+	}
+
+	records, err := h.service.Records(visitor)
+	if err != nil {
+		logger.Error(err, "Failed to list consent records", nil)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list consent records"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"records": records})
+}
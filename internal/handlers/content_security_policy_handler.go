@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"constructor-script-backend/internal/config"
+	"constructor-script-backend/internal/middleware"
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/service"
+	"constructor-script-backend/pkg/apierror"
+	"constructor-script-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ContentSecurityPolicyHandler struct {
+	service *service.ContentSecurityPolicyService
+	config  *config.Config
+	sources []middleware.ContentSecurityPolicySource
+}
+
+// NewContentSecurityPolicyHandler manages the admin CSP settings. The config
+// and sources are those the security headers are built from, for previews.
+func NewContentSecurityPolicyHandler(svc *service.ContentSecurityPolicyService, cfg *config.Config, sources ...middleware.ContentSecurityPolicySource) *ContentSecurityPolicyHandler {
+	return &ContentSecurityPolicyHandler{service: svc, config: cfg, sources: sources}
+}
+
+func (h *ContentSecurityPolicyHandler) Get(c *gin.Context) {
+	if h.service == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Content security policy service not available"})
+		return
+	}
+
+	settings, err := h.service.GetSettings()
+	if err != nil {
+		logger.Error(err, "Failed to load content security policy settings", nil)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load content security policy settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"settings": settings,
+		"preview":  middleware.PreviewContentSecurityPolicy(h.config, settings, h.sources...),
+	})
+}
+
+func (h *ContentSecurityPolicyHandler) Update(c *gin.Context) {
+	if h.service == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Content security policy service not available"})
+		return
+	}
+
+	var req models.UpdateContentSecurityPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BindError(c, err)
+		return
+	}
+
+	settings, err := h.service.UpdateSettings(req)
+	if err != nil {
+		var validationErr *service.ContentSecurityPolicyValidationError
+		if errors.As(err, &validationErr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		logger.Error(err, "Failed to update content security policy settings", nil)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update content security policy settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Content security policy updated",
+		"settings": settings,
+		"preview":  middleware.PreviewContentSecurityPolicy(h.config, settings, h.sources...),
+	})
+}
+
+// Preview validates settings and returns the headers they would produce,
+// without saving them.
+func (h *ContentSecurityPolicyHandler) Preview(c *gin.Context) {
+	if h.service == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Content security policy service not available"})
+		return
+	}
+
+	var req models.UpdateContentSecurityPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BindError(c, err)
+		return
+	}
+
+	settings, err := h.service.ValidateSettings(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"settings": settings,
+		"preview":  middleware.PreviewContentSecurityPolicy(h.config, settings, h.sources...),
+	})
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"runtime/debug"
+	rpprof "runtime/pprof"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Pprof serves the net/http/pprof endpoints mounted at /debug/pprof/*profile.
+func Pprof(c *gin.Context) {
+	switch strings.Trim(c.Param("profile"), "/") {
+	case "cmdline":
+		pprof.Cmdline(c.Writer, c.Request)
+	case "profile":
+		pprof.Profile(c.Writer, c.Request)
+	case "symbol":
+		pprof.Symbol(c.Writer, c.Request)
+	case "trace":
+		pprof.Trace(c.Writer, c.Request)
+	default:
+		// Index lists the profiles and serves the named ones, such as heap or
+		// goroutine, from the rest of the path.
+		pprof.Index(c.Writer, c.Request)
+	}
+}
+
+// GoroutineDump writes the stack of every goroutine as plain text.
+func GoroutineDump(c *gin.Context) {
+	profile := rpprof.Lookup("goroutine")
+	if profile == nil {
+		c.String(http.StatusInternalServerError, "goroutine profile unavailable")
+		return
+	}
+	c.Header("Content-Type", "text/plain; charset=utf-8")
+	c.Status(http.StatusOK)
+	_ = profile.WriteTo(c.Writer, 2)
+}
+
+// RuntimeStats reports memory, garbage collector and scheduler figures.
+func RuntimeStats(c *gin.Context) {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	var gc debug.GCStats
+	gc.PauseQuantiles = make([]time.Duration, 5)
+	debug.ReadGCStats(&gc)
+
+	quantiles := make([]float64, len(gc.PauseQuantiles))
+	for index, pause := range gc.PauseQuantiles {
+		quantiles[index] = float64(pause) / float64(time.Millisecond)
+	}
+
+	var lastGC *time.Time
+	if !gc.LastGC.IsZero() {
+		lastGC = &gc.LastGC
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"go_version": runtime.Version(),
+		"goroutines": runtime.NumGoroutine(),
+		"gomaxprocs": runtime.GOMAXPROCS(0),
+		"cpus":       runtime.NumCPU(),
+		"memory": gin.H{
+			"heap_alloc_bytes":    mem.HeapAlloc,
+			"heap_inuse_bytes":    mem.HeapInuse,
+			"heap_idle_bytes":     mem.HeapIdle,
+			"heap_released_bytes": mem.HeapReleased,
+			"heap_objects":        mem.HeapObjects,
+			"stack_inuse_bytes":   mem.StackInuse,
+			"sys_bytes":           mem.Sys,
+			"total_alloc_bytes":   mem.TotalAlloc,
+			"mallocs":             mem.Mallocs,
+			"frees":               mem.Frees,
+		},
+		"gc": gin.H{
+			"count":              gc.NumGC,
+			"last":               lastGC,
+			"pause_total_ms":     float64(gc.PauseTotal) / float64(time.Millisecond),
+			"pause_quantiles_ms": quantiles,
+			"next_target_bytes":  mem.NextGC,
+			"cpu_fraction":       mem.GCCPUFraction,
+		},
+	})
+}
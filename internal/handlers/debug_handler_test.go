@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestDebugEndpoints(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/debug/pprof/*profile", Pprof)
+	router.GET("/debug/goroutines", GoroutineDump)
+	router.GET("/debug/runtime", RuntimeStats)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		return recorder
+	}
+
+	if recorder := get("/debug/pprof/"); recorder.Code != http.StatusOK || !strings.Contains(recorder.Body.String(), "goroutine") {
+		t.Errorf("pprof index: %d %q", recorder.Code, recorder.Body.String())
+	}
+	if recorder := get("/debug/pprof/heap?debug=1"); recorder.Code != http.StatusOK || !strings.Contains(recorder.Body.String(), "heap profile") {
+		t.Errorf("heap profile: %d", recorder.Code)
+	}
+	if recorder := get("/debug/goroutines"); !strings.Contains(recorder.Body.String(), "TestDebugEndpoints") {
+		t.Errorf("goroutine dump does not include the test goroutine")
+	}
+
+	recorder := get("/debug/runtime")
+	var stats struct {
+		Goroutines int `json:"goroutines"`
+		GC         struct {
+			PauseQuantiles []float64 `json:"pause_quantiles_ms"`
+		} `json:"gc"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &stats); err != nil {
+		t.Fatalf("runtime stats: %v", err)
+	}
+	if stats.Goroutines == 0 || len(stats.GC.PauseQuantiles) != 5 {
+		t.Errorf("runtime stats = %+v", stats)
+	}
+}
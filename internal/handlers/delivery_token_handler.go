@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/service"
+	"constructor-script-backend/pkg/apierror"
+	"constructor-script-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type DeliveryTokenHandler struct {
+	service *service.DeliveryTokenService
+}
+
+func NewDeliveryTokenHandler(service *service.DeliveryTokenService) *DeliveryTokenHandler {
+	return &DeliveryTokenHandler{service: service}
+}
+
+func deliveryTokenErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrDeliveryTokenRepositoryUnavailable):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, service.ErrInvalidDeliveryToken):
+		return http.StatusBadRequest
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func (h *DeliveryTokenHandler) List(c *gin.Context) {
+	if h == nil || h.service == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Delivery token service not available"})
+		return
+	}
+
+	tokens, err := h.service.List()
+	if err != nil {
+		logger.ErrorContext(c.Request.Context(), err, "Failed to load delivery tokens", nil)
+		c.JSON(deliveryTokenErrorStatus(err), gin.H{"error": "Failed to load delivery tokens"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tokens": tokens})
+}
+
+// Create returns the new token together with its value, which is shown only once.
+func (h *DeliveryTokenHandler) Create(c *gin.Context) {
+	if h == nil || h.service == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Delivery token service not available"})
+		return
+	}
+
+	var req models.CreateDeliveryTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BindError(c, err)
+		return
+	}
+
+	token, value, err := h.service.Create(req)
+	if err != nil {
+		logger.ErrorContext(c.Request.Context(), err, "Failed to create delivery token", nil)
+		c.JSON(deliveryTokenErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"token": token, "value": value})
+}
+
+func (h *DeliveryTokenHandler) Update(c *gin.Context) {
+	if h == nil || h.service == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Delivery token service not available"})
+		return
+	}
+
+	id, ok := parseWebhookID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.UpdateDeliveryTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BindError(c, err)
+		return
+	}
+
+	token, err := h.service.Update(id, req)
+	if err != nil {
+		logger.ErrorContext(c.Request.Context(), err, "Failed to update delivery token", map[string]interface{}{"id": id})
+		c.JSON(deliveryTokenErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"token": token})
+}
+
+func (h *DeliveryTokenHandler) Delete(c *gin.Context) {
+	if h == nil || h.service == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Delivery token service not available"})
+		return
+	}
+
+	id, ok := parseWebhookID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(id); err != nil {
+		logger.ErrorContext(c.Request.Context(), err, "Failed to delete delivery token", map[string]interface{}{"id": id})
+		c.JSON(deliveryTokenErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Delivery token deleted"})
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"constructor-script-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maxSlowQueryReportLimit = 200
+
+type DiagnosticsHandler struct {
+	slowQueries   *logger.SlowQueryLog
+	slowThreshold time.Duration
+}
+
+func NewDiagnosticsHandler(slowQueries *logger.SlowQueryLog, slowThreshold time.Duration) *DiagnosticsHandler {
+	return &DiagnosticsHandler{slowQueries: slowQueries, slowThreshold: slowThreshold}
+}
+
+// SlowQueries reports the statements slower than the threshold since the process
+// started, grouped by normalized SQL and call site. It accepts sort (total, max,
+// average, count or recent) and limit.
+func (h *DiagnosticsHandler) SlowQueries(c *gin.Context) {
+	if h == nil || h.slowQueries == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Slow query logging is disabled"})
+		return
+	}
+
+	sortBy := c.DefaultQuery("sort", "total")
+	switch sortBy {
+	case "total", "max", "average", "count", "recent":
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "sort must be one of total, max, average, count or recent"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive number"})
+		return
+	}
+	if limit > maxSlowQueryReportLimit {
+		limit = maxSlowQueryReportLimit
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"threshold_ms": h.slowThreshold.Milliseconds(),
+		"since":        h.slowQueries.Since(),
+		"sort":         sortBy,
+		"queries":      h.slowQueries.Report(sortBy, limit),
+	})
+}
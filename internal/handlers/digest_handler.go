@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/service"
+	"constructor-script-backend/pkg/apierror"
+	"constructor-script-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// DigestHandler lets users choose their activity digest and unsubscribe from it.
+type DigestHandler struct {
+	service *service.DigestService
+}
+
+func NewDigestHandler(svc *service.DigestService) *DigestHandler {
+	return &DigestHandler{service: svc}
+}
+
+// Get returns the digest preferences of the signed-in user.
+// GET /api/v1/profile/digest
+func (h *DigestHandler) Get(c *gin.Context) {
+	if h == nil || h.service == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Digest service not available"})
+		return
+	}
+
+	subscription, err := h.service.Preferences(c.GetUint("user_id"))
+	if err != nil {
+		logger.ErrorContext(c.Request.Context(), err, "Failed to load digest preferences", nil)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load digest preferences"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"digest": subscription})
+}
+
+// Update sets how often the signed-in user receives a digest.
+// PUT /api/v1/profile/digest {"frequency": "off|daily|weekly"}
+func (h *DigestHandler) Update(c *gin.Context) {
+	if h == nil || h.service == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Digest service not available"})
+		return
+	}
+
+	var req models.UpdateDigestPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BindError(c, err)
+		return
+	}
+
+	subscription, err := h.service.UpdatePreferences(c.GetUint("user_id"), req.Frequency)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidDigestFrequency) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		logger.ErrorContext(c.Request.Context(), err, "Failed to update digest preferences", nil)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update digest preferences"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"digest": subscription})
+}
+
+// Unsubscribe handles both the link in digest emails (GET) and one-click
+// unsubscribe requests from mail clients (POST).
+func (h *DigestHandler) Unsubscribe(c *gin.Context) {
+	if h == nil || h.service == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Digest service not available"})
+		return
+	}
+
+	err := h.service.Unsubscribe(c.Param("token"))
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		logger.ErrorContext(c.Request.Context(), err, "Failed to unsubscribe from digests", nil)
+	}
+	if c.Request.Method == http.MethodPost {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "unknown unsubscribe link"})
+		case err != nil:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unsubscribe"})
+		default:
+			c.JSON(http.StatusOK, gin.H{"message": "unsubscribed"})
+		}
+		return
+	}
+
+	if err != nil {
+		c.Redirect(http.StatusSeeOther, "/?digest=invalid")
+		return
+	}
+	c.Redirect(http.StatusSeeOther, "/?digest=unsubscribed")
+}
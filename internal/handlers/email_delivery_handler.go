@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/repository"
+	"constructor-script-backend/internal/service"
+	"constructor-script-backend/pkg/apierror"
+	"constructor-script-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// EmailDeliveryHandler exposes the email send log to admins.
+type EmailDeliveryHandler struct {
+	service *service.EmailService
+}
+
+func NewEmailDeliveryHandler(service *service.EmailService) *EmailDeliveryHandler {
+	return &EmailDeliveryHandler{service: service}
+}
+
+func emailDeliveryErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrEmailQueueUnavailable), errors.Is(err, service.ErrEmailSuppressionsDisabled):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// List returns the most recent emails, newest first. Filtered by recipient, it
+// also says whether the address is suppressed.
+// GET /api/v1/admin/email/deliveries?status=failed&recipient=&template=&limit=50
+func (h *EmailDeliveryHandler) List(c *gin.Context) {
+	if h == nil || h.service == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Email service not available"})
+		return
+	}
+
+	status := c.Query("status")
+	switch status {
+	case "", models.EmailDeliveryPending, models.EmailDeliverySent, models.EmailDeliveryFailed,
+		models.EmailDeliveryBounced, models.EmailDeliveryComplained, models.EmailDeliverySuppressed:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be pending, sent, failed, bounced, complained or suppressed"})
+		return
+	}
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	deliveries, err := h.service.Deliveries(repository.EmailDeliveryFilter{
+		Status:    status,
+		Recipient: c.Query("recipient"),
+		Template:  c.Query("template"),
+		Limit:     limit,
+	})
+	if err != nil {
+		logger.ErrorContext(c.Request.Context(), err, "Failed to load email deliveries", nil)
+		c.JSON(emailDeliveryErrorStatus(err), gin.H{"error": "Failed to load email deliveries"})
+		return
+	}
+
+	response := gin.H{"deliveries": deliveries}
+	if recipient := strings.TrimSpace(c.Query("recipient")); recipient != "" {
+		suppression, err := h.service.Suppression(recipient)
+		if err != nil && !errors.Is(err, service.ErrEmailSuppressionsDisabled) {
+			logger.ErrorContext(c.Request.Context(), err, "Failed to load email suppression", nil)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load email deliveries"})
+			return
+		}
+		response["suppression"] = suppression
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Resend queues a copy of an earlier email.
+// POST /api/v1/admin/email/deliveries/:id/resend
+func (h *EmailDeliveryHandler) Resend(c *gin.Context) {
+	if h == nil || h.service == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Email service not available"})
+		return
+	}
+
+	id, ok := parseWebhookID(c, "id")
+	if !ok {
+		return
+	}
+
+	delivery, err := h.service.Resend(id)
+	if err != nil {
+		logger.ErrorContext(c.Request.Context(), err, "Failed to resend email", map[string]interface{}{"id": id})
+		c.JSON(emailDeliveryErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"delivery": delivery})
+}
+
+// Suppressions lists the addresses no email is sent to.
+// GET /api/v1/admin/email/suppressions?email=
+func (h *EmailDeliveryHandler) Suppressions(c *gin.Context) {
+	if h == nil || h.service == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Email service not available"})
+		return
+	}
+
+	suppressions, err := h.service.Suppressions(c.Query("email"))
+	if err != nil {
+		logger.ErrorContext(c.Request.Context(), err, "Failed to load email suppressions", nil)
+		c.JSON(emailDeliveryErrorStatus(err), gin.H{"error": "Failed to load email suppressions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"suppressions": suppressions})
+}
+
+// Suppress stops all email to an address.
+// POST /api/v1/admin/email/suppressions {"email": "...", "detail": "..."}
+func (h *EmailDeliveryHandler) Suppress(c *gin.Context) {
+	if h == nil || h.service == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Email service not available"})
+		return
+	}
+
+	var req models.CreateEmailSuppressionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BindError(c, err)
+		return
+	}
+
+	suppression, err := h.service.Suppress(req)
+	if err != nil {
+		logger.ErrorContext(c.Request.Context(), err, "Failed to suppress email address", nil)
+		c.JSON(emailDeliveryErrorStatus(err), gin.H{"error": "Failed to suppress email address"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"suppression": suppression})
+}
+
+// Unsuppress lets email be sent to an address again.
+// DELETE /api/v1/admin/email/suppressions/:id
+func (h *EmailDeliveryHandler) Unsuppress(c *gin.Context) {
+	if h == nil || h.service == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Email service not available"})
+		return
+	}
+
+	id, ok := parseWebhookID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.Unsuppress(id); err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.ErrorContext(c.Request.Context(), err, "Failed to remove email suppression", map[string]interface{}{"id": id})
+		}
+		c.JSON(emailDeliveryErrorStatus(err), gin.H{"error": "Failed to remove email suppression"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Email address removed from the suppression list"})
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"io"
+	"net/http"
+
+	"constructor-script-backend/internal/service"
+	"constructor-script-backend/pkg/logger"
+	"constructor-script-backend/pkg/mail"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maxEmailFeedbackBytes = 1 << 20
+
+// EmailFeedbackHandler receives bounce and complaint webhooks from the mail
+// provider. The secret in the URL is the only authentication, since providers
+// sign their webhooks in different ways.
+type EmailFeedbackHandler struct {
+	service *service.EmailService
+	secret  string
+}
+
+func NewEmailFeedbackHandler(svc *service.EmailService, secret string) *EmailFeedbackHandler {
+	return &EmailFeedbackHandler{service: svc, secret: secret}
+}
+
+// Receive records the bounces and complaints of a provider webhook.
+// POST /api/v1/email/feedback/:token
+func (h *EmailFeedbackHandler) Receive(c *gin.Context) {
+	if h == nil || h.service == nil || h.secret == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(c.Param("token")), []byte(h.secret)) != 1 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxEmailFeedbackBytes))
+	if err != nil {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Payload too large"})
+		return
+	}
+
+	feedback, err := mail.ParseFeedback(body)
+	if err != nil {
+		logger.Warn("Rejected email feedback webhook", map[string]interface{}{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	recorded, err := h.service.HandleFeedback(c.Request.Context(), feedback)
+	if err != nil {
+		if errors.Is(err, service.ErrEmailSuppressionsDisabled) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Email suppression list not available"})
+			return
+		}
+		// A non-2xx response makes the provider deliver the webhook again.
+		logger.ErrorContext(c.Request.Context(), err, "Failed to record email feedback", nil)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record email feedback"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"recorded": recorded})
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"constructor-script-backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type EmbedHandler struct {
+	embedService *service.EmbedService
+}
+
+func NewEmbedHandler(embedService *service.EmbedService) *EmbedHandler {
+	return &EmbedHandler{embedService: embedService}
+}
+
+// Resolve looks up a link through oEmbed so the editor can preview an embed element.
+func (h *EmbedHandler) Resolve(c *gin.Context) {
+	var request struct {
+		URL string `json:"url" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "url is required"})
+		return
+	}
+
+	embed, err := h.embedService.Resolve(c.Request.Context(), request.URL)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrUnsupportedEmbed):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrEmbedUnavailable):
+			c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"embed": embed})
+}
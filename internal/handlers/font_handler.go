@@ -6,6 +6,7 @@ import (
 
 	"constructor-script-backend/internal/models"
 	"constructor-script-backend/internal/service"
+	"constructor-script-backend/pkg/apierror"
 	"constructor-script-backend/pkg/logger"
 
 	"github.com/gin-gonic/gin"
@@ -47,7 +48,7 @@ func (h *FontHandler) Create(c *gin.Context) {
 
 	var req models.CreateFontAssetRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.BindError(c, err)
 		return
 	}
 
@@ -83,7 +84,7 @@ func (h *FontHandler) Update(c *gin.Context) {
 
 	var req models.UpdateFontAssetRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.BindError(c, err)
 		return
 	}
 
@@ -136,7 +137,7 @@ func (h *FontHandler) Reorder(c *gin.Context) {
 
 	var req models.ReorderFontAssetsRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.BindError(c, err)
 		return
 	}
 
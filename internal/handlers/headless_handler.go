@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/service"
+	"constructor-script-backend/pkg/apierror"
+	"constructor-script-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HeadlessHandler struct {
+	service *service.HeadlessService
+}
+
+func NewHeadlessHandler(svc *service.HeadlessService) *HeadlessHandler {
+	return &HeadlessHandler{service: svc}
+}
+
+func (h *HeadlessHandler) Get(c *gin.Context) {
+	if h.service == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Headless service not available"})
+		return
+	}
+
+	settings, err := h.service.GetSettings()
+	if err != nil {
+		logger.Error(err, "Failed to load headless settings", nil)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load headless settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"settings":    settings,
+		"build_hooks": h.service.BuildHookStatuses(),
+	})
+}
+
+func (h *HeadlessHandler) Update(c *gin.Context) {
+	if h.service == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Headless service not available"})
+		return
+	}
+
+	var req models.UpdateHeadlessSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BindError(c, err)
+		return
+	}
+
+	settings, err := h.service.UpdateSettings(req)
+	if err != nil {
+		var validationErr *service.HeadlessValidationError
+		if errors.As(err, &validationErr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		logger.Error(err, "Failed to update headless settings", nil)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update headless settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Headless settings updated",
+		"settings": settings,
+	})
+}
+
+// TriggerBuild posts to the build hooks without waiting for a content change.
+func (h *HeadlessHandler) TriggerBuild(c *gin.Context) {
+	if h.service == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Headless service not available"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"build_hooks": h.service.TriggerBuild(c.Request.Context())})
+}
@@ -4,9 +4,12 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"constructor-script-backend/internal/models"
 	"constructor-script-backend/internal/service"
+	"constructor-script-backend/pkg/apierror"
+	"constructor-script-backend/pkg/lang"
 	"constructor-script-backend/pkg/logger"
 
 	"github.com/gin-gonic/gin"
@@ -41,9 +44,17 @@ func (h *HomepageHandler) Get(c *gin.Context) {
 		return
 	}
 
+	languages, err := h.homepageService.ListLanguageSelections()
+	if err != nil {
+		logger.Error(err, "Failed to load language homepages", nil)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load homepage selection"})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"homepage": selection,
-		"options":  options,
+		"homepage":  selection,
+		"languages": languages,
+		"options":   options,
 	})
 }
 
@@ -55,7 +66,12 @@ func (h *HomepageHandler) Update(c *gin.Context) {
 
 	var req models.UpdateHomepageRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.BindError(c, err)
+		return
+	}
+
+	if strings.TrimSpace(req.Language) != "" {
+		h.updateLanguageHomepage(c, req)
 		return
 	}
 
@@ -107,9 +123,64 @@ func (h *HomepageHandler) Update(c *gin.Context) {
 		return
 	}
 
+	languages, err := h.homepageService.ListLanguageSelections()
+	if err != nil {
+		logger.Error(err, "Failed to load language homepages", nil)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load homepage selection"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   message,
+		"homepage":  selection,
+		"languages": languages,
+		"options":   options,
+	})
+}
+
+// updateLanguageHomepage sets or clears the homepage of req.Language.
+func (h *HomepageHandler) updateLanguageHomepage(c *gin.Context, req models.UpdateHomepageRequest) {
+	language, err := lang.Normalize(req.Language)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "language must be a valid language code"})
+		return
+	}
+	var message string
+
+	if req.PageID == nil || *req.PageID == 0 {
+		if err := h.homepageService.ClearLanguageHomepage(language); err != nil {
+			logger.Error(err, "Failed to clear language homepage", map[string]interface{}{"language": language})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update homepage"})
+			return
+		}
+		message = fmt.Sprintf("Homepage for %s cleared. The site-wide homepage is used instead.", language)
+	} else {
+		pageID := *req.PageID
+		result, err := h.homepageService.SetLanguageHomepage(language, pageID)
+		if err != nil {
+			switch {
+			case errors.Is(err, gorm.ErrRecordNotFound):
+				c.JSON(http.StatusNotFound, gin.H{"error": "Page not found"})
+			case errors.Is(err, service.ErrHomepagePageNotPublished), errors.Is(err, service.ErrHomepagePageScheduled):
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			default:
+				logger.Error(err, "Failed to update language homepage", map[string]interface{}{"page_id": pageID, "language": language})
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update homepage"})
+			}
+			return
+		}
+		message = fmt.Sprintf("\"%s\" set as the homepage for %s.", result.Title, result.Language)
+	}
+
+	languages, err := h.homepageService.ListLanguageSelections()
+	if err != nil {
+		logger.Error(err, "Failed to load language homepages", nil)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load homepage selection"})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"message":  message,
-		"homepage": selection,
-		"options":  options,
+		"message":   message,
+		"languages": languages,
 	})
 }
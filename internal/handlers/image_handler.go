@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"constructor-script-backend/internal/service"
+	"constructor-script-backend/pkg/logger"
+)
+
+type ImageHandler struct {
+	service *service.ImageTransformService
+}
+
+func NewImageHandler(service *service.ImageTransformService) *ImageHandler {
+	return &ImageHandler{service: service}
+}
+
+// Serve answers GET /img/<transform>/<path>, e.g. /img/w=640,f=webp,s=<signature>/photo.jpg,
+// with the upload resized as requested.
+func (h *ImageHandler) Serve(c *gin.Context) {
+	if h == nil || h.service == nil {
+		c.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	transform, err := h.service.ParseTransform(c.Param("transform"))
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, service.ErrImageTooLarge) {
+			status = http.StatusUnprocessableEntity
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	path := strings.TrimPrefix(c.Param("path"), "/")
+	image, err := h.service.Transform(c.Request.Context(), path, transform)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrImageSignatureInvalid):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrUploadNotFound):
+			c.AbortWithStatus(http.StatusNotFound)
+		case errors.Is(err, service.ErrUnsupportedUpload):
+			c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "file is not a resizable image"})
+		case errors.Is(err, service.ErrImageTooLarge):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		default:
+			logger.Error(err, "Failed to transform image", map[string]interface{}{"path": path, "transform": transform.String()})
+			c.AbortWithStatus(http.StatusInternalServerError)
+		}
+		return
+	}
+	defer image.Content.Close()
+
+	c.Header("Content-Type", image.ContentType)
+	c.Header("ETag", image.ETag)
+	c.Header("Cache-Control", "public, max-age=604800")
+	http.ServeContent(c.Writer, c.Request, "", time.Time{}, image.Content)
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"constructor-script-backend/internal/service"
+	"constructor-script-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// liveUpdateHeartbeat keeps proxies from closing an idle stream.
+	liveUpdateHeartbeat = 25 * time.Second
+	// liveUpdateRetry is how long browsers wait before reconnecting, in
+	// milliseconds.
+	liveUpdateRetry = 3000
+	// liveUpdateMargin ends a stream this long before the server write timeout
+	// would cut it, so the browser reconnects cleanly instead.
+	liveUpdateMargin = 15 * time.Second
+)
+
+type LiveUpdateHandler struct {
+	service  *service.LiveUpdateService
+	lifetime time.Duration
+}
+
+// NewLiveUpdateHandler streams the updates of service. writeTimeout is the write
+// timeout of the server, which bounds how long a single stream may stay open.
+func NewLiveUpdateHandler(service *service.LiveUpdateService, writeTimeout time.Duration) *LiveUpdateHandler {
+	lifetime := writeTimeout - liveUpdateMargin
+	if lifetime < time.Minute {
+		lifetime = time.Minute
+	}
+	return &LiveUpdateHandler{service: service, lifetime: lifetime}
+}
+
+// Stream sends the dashboard events as Server-Sent Events. Each event is named
+// after the bus event and carries it as JSON; a client reconnecting with
+// Last-Event-ID, or the last_event_id query parameter, first receives the events
+// it missed.
+func (h *LiveUpdateHandler) Stream(c *gin.Context) {
+	if h == nil || h.service == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Live updates not available"})
+		return
+	}
+
+	lastID := c.GetHeader("Last-Event-ID")
+	if lastID == "" {
+		lastID = c.Query("last_event_id")
+	}
+	since, _ := strconv.ParseUint(strings.TrimSpace(lastID), 10, 64)
+
+	missed, updates, stop := h.service.Listen(since)
+	defer stop()
+
+	header := c.Writer.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-store")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no")
+	header.Del("Content-Encoding")
+	c.Status(http.StatusOK)
+
+	fmt.Fprintf(c.Writer, "retry: %d\n\n", liveUpdateRetry)
+	for _, update := range missed {
+		if !writeLiveUpdate(c, update) {
+			return
+		}
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(liveUpdateHeartbeat)
+	defer heartbeat.Stop()
+	deadline := time.NewTimer(h.lifetime)
+	defer deadline.Stop()
+
+	for {
+		select {
+		case update, ok := <-updates:
+			if !ok || !writeLiveUpdate(c, update) {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := c.Writer.WriteString(": ping\n\n"); err != nil {
+				return
+			}
+		case <-deadline.C:
+			return
+		case <-c.Request.Context().Done():
+			return
+		}
+		c.Writer.Flush()
+	}
+}
+
+func writeLiveUpdate(c *gin.Context, update service.LiveUpdate) bool {
+	data, err := json.Marshal(update.Event)
+	if err != nil {
+		logger.ErrorContext(c.Request.Context(), err, "Failed to encode live update", map[string]interface{}{"event": update.Event.Name})
+		return true
+	}
+	_, err = fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", update.ID, update.Event.Name, data)
+	return err == nil
+}
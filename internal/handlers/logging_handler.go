@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"net/http"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/pkg/apierror"
+	"constructor-script-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetLoggingSettings returns the log level and output format in use.
+func GetLoggingSettings(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"settings": currentLoggingSettings()})
+}
+
+// UpdateLoggingSettings changes the log level and output format of the running
+// process. The change is not persisted: a restart returns to LOG_LEVEL and
+// LOG_FORMAT.
+func UpdateLoggingSettings(c *gin.Context) {
+	var req models.UpdateLoggingSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BindError(c, err)
+		return
+	}
+	if req.Level == nil && req.Format == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "level or format is required"})
+		return
+	}
+
+	// Parse both before applying either, so an invalid value changes nothing.
+	var err error
+	level := logger.Level()
+	if req.Level != nil {
+		if level, err = logger.ParseLevel(*req.Level); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	format := logger.CurrentFormat()
+	if req.Format != nil {
+		if format, err = logger.ParseFormat(*req.Format); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	if level != logger.Level() {
+		if err := logger.SetLevel(level); err != nil {
+			logger.Error(err, "Failed to change log level", nil)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to change log level"})
+			return
+		}
+	}
+	if format != logger.CurrentFormat() {
+		if err := logger.SetFormat(format); err != nil {
+			logger.Error(err, "Failed to change log format", nil)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to change log format"})
+			return
+		}
+	}
+
+	settings := currentLoggingSettings()
+	logger.Info("Logging settings changed", map[string]interface{}{"level": settings.Level, "format": settings.Format})
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Logging settings updated",
+		"settings": settings,
+	})
+}
+
+func currentLoggingSettings() models.LoggingSettings {
+	return models.LoggingSettings{
+		Level:  logger.Level().String(),
+		Format: string(logger.CurrentFormat()),
+	}
+}
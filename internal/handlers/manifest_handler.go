@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"net/http"
+
+	"constructor-script-backend/internal/config"
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/plugin"
+	pluginruntime "constructor-script-backend/internal/plugin/runtime"
+	"constructor-script-backend/internal/service"
+	"constructor-script-backend/internal/theme"
+	"constructor-script-backend/internal/version"
+	"constructor-script-backend/pkg/logger"
+	languageservice "constructor-script-backend/plugins/language/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// manifestAPIVersions lists the API versions the server answers.
+var manifestAPIVersions = []string{"v1", "v2"}
+
+// manifestPluginCapabilities are the capabilities each plugin provides while it is
+// active.
+var manifestPluginCapabilities = map[string][]string{
+	"blog":       {"posts", "comments", "search"},
+	"forum":      {"forum"},
+	"courses":    {"courses"},
+	"events":     {"events"},
+	"newsletter": {"newsletter"},
+	"products":   {"products"},
+	"archive":    {"archive"},
+	"language":   {"translations"},
+}
+
+type ManifestHandler struct {
+	config   *config.Config
+	setup    *service.SetupService
+	language func() *languageservice.LanguageService
+	payments *service.PaymentService
+	plugins  *plugin.Manager
+	runtime  *pluginruntime.Runtime
+	themes   *theme.Manager
+}
+
+// NewManifestHandler describes the site from its settings and the active plugins
+// and theme. language is called on every request, since the language plugin
+// provides its service only while it is active.
+func NewManifestHandler(
+	cfg *config.Config,
+	setup *service.SetupService,
+	language func() *languageservice.LanguageService,
+	payments *service.PaymentService,
+	plugins *plugin.Manager,
+	runtime *pluginruntime.Runtime,
+	themes *theme.Manager,
+) *ManifestHandler {
+	return &ManifestHandler{
+		config:   cfg,
+		setup:    setup,
+		language: language,
+		payments: payments,
+		plugins:  plugins,
+		runtime:  runtime,
+		themes:   themes,
+	}
+}
+
+// Get returns the site manifest.
+// GET /api/v1/manifest
+func (h *ManifestHandler) Get(c *gin.Context) {
+	var language *languageservice.LanguageService
+	if h.language != nil {
+		language = h.language()
+	}
+	site, err := ResolveSiteSettings(h.config, h.setup, language)
+	if err != nil {
+		logger.ErrorContext(c.Request.Context(), err, "Failed to resolve site settings", nil)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load site manifest"})
+		return
+	}
+
+	manifest := models.SiteManifest{
+		Name:         site.Name,
+		URL:          site.URL,
+		Version:      version.Version,
+		APIVersions:  manifestAPIVersions,
+		Languages:    manifestLanguages(site.DefaultLanguage, site.SupportedLanguages),
+		Plugins:      []models.ManifestPlugin{},
+		Capabilities: h.capabilities(),
+	}
+	if h.themes != nil {
+		if active := h.themes.Active(); active != nil {
+			manifest.Theme = active.Slug
+		}
+	}
+
+	for _, slug := range h.runtime.ActiveSlugs() {
+		entry := models.ManifestPlugin{Slug: slug, Name: slug}
+		if found, ok := h.plugins.Resolve(slug); ok {
+			entry.Name, entry.Version = found.Metadata.Name, found.Metadata.Version
+		}
+		manifest.Plugins = append(manifest.Plugins, entry)
+		for _, capability := range manifestPluginCapabilities[slug] {
+			manifest.Capabilities[capability] = true
+		}
+	}
+	manifest.Capabilities["checkout"] = (manifest.Capabilities["courses"] || manifest.Capabilities["products"]) &&
+		h.payments.Settings().SecretKey != ""
+
+	c.Header("Cache-Control", "public, max-age=60")
+	c.JSON(http.StatusOK, manifest)
+}
+
+// capabilities returns every capability, with those of the core set.
+func (h *ManifestHandler) capabilities() map[string]bool {
+	capabilities := map[string]bool{
+		"pages":        true,
+		"registration": true,
+		"delivery_api": true,
+		"openapi":      h.config != nil && h.config.EnableOpenAPI,
+		"checkout":     false,
+	}
+	for _, provided := range manifestPluginCapabilities {
+		for _, capability := range provided {
+			capabilities[capability] = false
+		}
+	}
+	return capabilities
+}
+
+func manifestLanguages(defaultLanguage string, supported []string) []string {
+	languages := []string{}
+	if defaultLanguage != "" {
+		languages = append(languages, defaultLanguage)
+	}
+	for _, code := range supported {
+		if code != "" && code != defaultLanguage {
+			languages = append(languages, code)
+		}
+	}
+	return languages
+}
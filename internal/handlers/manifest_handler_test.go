@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"constructor-script-backend/internal/config"
+	"constructor-script-backend/internal/models"
+	pluginruntime "constructor-script-backend/internal/plugin/runtime"
+
+	"github.com/gin-gonic/gin"
+)
+
+type noopFeature struct{}
+
+func (noopFeature) Activate() error   { return nil }
+func (noopFeature) Deactivate() error { return nil }
+
+func TestManifestReportsActivePlugins(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	runtime := pluginruntime.New()
+	runtime.Register("forum", noopFeature{})
+	runtime.Register("blog", noopFeature{})
+	if err := runtime.Activate("forum"); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{SiteName: "Example", DefaultLanguage: "en", SupportedLanguages: []string{"de", "en"}, EnableOpenAPI: true}
+	handler := NewManifestHandler(cfg, nil, nil, nil, nil, runtime, nil)
+	router := gin.New()
+	router.GET("/api/v1/manifest", handler.Get)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/manifest", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d", recorder.Code)
+	}
+
+	var manifest models.SiteManifest
+	if err := json.Unmarshal(recorder.Body.Bytes(), &manifest); err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Name != "Example" || len(manifest.Languages) != 2 || manifest.Languages[0] != "en" {
+		t.Fatalf("unexpected manifest %+v", manifest)
+	}
+	if len(manifest.Plugins) != 1 || manifest.Plugins[0].Slug != "forum" {
+		t.Fatalf("expected only the active plugin, got %+v", manifest.Plugins)
+	}
+	capabilities := manifest.Capabilities
+	if !capabilities["forum"] || !capabilities["openapi"] {
+		t.Fatalf("expected forum and openapi, got %v", capabilities)
+	}
+	if enabled, listed := capabilities["comments"]; !listed || enabled {
+		t.Fatalf("expected comments to be listed as unavailable, got %v", capabilities)
+	}
+	if capabilities["checkout"] {
+		t.Fatal("expected no checkout without Stripe and a shop")
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"constructor-script-backend/internal/models"
 	"constructor-script-backend/internal/service"
+	"constructor-script-backend/pkg/apierror"
 	"constructor-script-backend/pkg/logger"
 
 	"github.com/gin-gonic/gin"
@@ -27,7 +28,13 @@ func (h *MenuHandler) List(c *gin.Context) {
 		return
 	}
 
-	items, err := h.service.List()
+	var items []models.MenuItem
+	var err error
+	if language := c.Query("language"); language != "" {
+		items, err = h.service.ListForLanguage(language)
+	} else {
+		items, err = h.service.List()
+	}
 	if err != nil {
 		logger.Error(err, "Failed to load menu items", nil)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load menu items"})
@@ -45,7 +52,7 @@ func (h *MenuHandler) Create(c *gin.Context) {
 
 	var req models.CreateMenuItemRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.BindError(c, err)
 		return
 	}
 
@@ -74,7 +81,7 @@ func (h *MenuHandler) Update(c *gin.Context) {
 
 	var req models.UpdateMenuItemRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.BindError(c, err)
 		return
 	}
 
@@ -100,7 +107,7 @@ func (h *MenuHandler) Reorder(c *gin.Context) {
 
 	var req models.ReorderMenuItemsRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.BindError(c, err)
 		return
 	}
 
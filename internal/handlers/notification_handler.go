@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/service"
+	"constructor-script-backend/pkg/apierror"
+	"constructor-script-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// NotificationHandler serves the in-app notifications of the signed-in user.
+type NotificationHandler struct {
+	service *service.NotificationService
+}
+
+func NewNotificationHandler(svc *service.NotificationService) *NotificationHandler {
+	return &NotificationHandler{service: svc}
+}
+
+// List returns the newest notifications and the unread count.
+// GET /api/v1/notifications?unread=true
+func (h *NotificationHandler) List(c *gin.Context) {
+	if h == nil || h.service == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Notification service not available"})
+		return
+	}
+
+	unreadOnly, _ := strconv.ParseBool(c.Query("unread"))
+	list, err := h.service.List(c.GetUint("user_id"), unreadOnly)
+	if err != nil {
+		logger.ErrorContext(c.Request.Context(), err, "Failed to load notifications", nil)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load notifications"})
+		return
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+// MarkRead marks one notification as read.
+// POST /api/v1/notifications/:id/read
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	if h == nil || h.service == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Notification service not available"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid notification ID"})
+		return
+	}
+
+	if err := h.service.MarkRead(c.GetUint("user_id"), uint(id)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Notification not found"})
+			return
+		}
+		logger.ErrorContext(c.Request.Context(), err, "Failed to mark notification as read", nil)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mark notification as read"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Notification marked as read"})
+}
+
+// MarkAllRead marks every notification of the user as read.
+// POST /api/v1/notifications/read
+func (h *NotificationHandler) MarkAllRead(c *gin.Context) {
+	if h == nil || h.service == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Notification service not available"})
+		return
+	}
+
+	if err := h.service.MarkAllRead(c.GetUint("user_id")); err != nil {
+		logger.ErrorContext(c.Request.Context(), err, "Failed to mark notifications as read", nil)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mark notifications as read"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Notifications marked as read"})
+}
+
+// Preferences returns the notification preferences matrix of the user.
+// GET /api/v1/profile/notifications
+func (h *NotificationHandler) Preferences(c *gin.Context) {
+	if h == nil || h.service == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Notification service not available"})
+		return
+	}
+
+	preferences, err := h.service.Preferences(c.GetUint("user_id"))
+	if err != nil {
+		logger.ErrorContext(c.Request.Context(), err, "Failed to load notification preferences", nil)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load notification preferences"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"preferences": preferences})
+}
+
+// UpdatePreferences turns notifications on or off per event and channel.
+// PUT /api/v1/profile/notifications {"preferences": {"forum_answer": {"email": false}}}
+func (h *NotificationHandler) UpdatePreferences(c *gin.Context) {
+	if h == nil || h.service == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Notification service not available"})
+		return
+	}
+
+	var req models.UpdateNotificationPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BindError(c, err)
+		return
+	}
+
+	preferences, err := h.service.UpdatePreferences(c.GetUint("user_id"), req)
+	if err != nil {
+		var validationErr *service.NotificationPreferenceValidationError
+		if errors.As(err, &validationErr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+		logger.ErrorContext(c.Request.Context(), err, "Failed to update notification preferences", nil)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notification preferences"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"preferences": preferences})
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"html/template"
+	"net/http"
+
+	"constructor-script-backend/internal/constants"
+	"constructor-script-backend/internal/openapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+// swaggerUIVersion pins the Swagger UI release loaded from jsDelivr, which the
+// content security policy already admits.
+const swaggerUIVersion = "5.17.14"
+
+var swaggerUITemplate = template.Must(template.New("swagger-ui").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="robots" content="noindex, nofollow">
+<title>{{.Title}}</title>
+<link rel="stylesheet" href="https://cdn.jsdelivr.net/npm/swagger-ui-dist@{{.Version}}/swagger-ui.css">
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="https://cdn.jsdelivr.net/npm/swagger-ui-dist@{{.Version}}/swagger-ui-bundle.js"></script>
+<script>
+window.ui = SwaggerUIBundle({
+  url: {{.SpecURL}},
+  dom_id: "#swagger-ui",
+  withCredentials: true,
+  requestInterceptor: function (request) {
+    var match = document.cookie.match(new RegExp("(?:^|; )" + {{.CSRFCookie}} + "=([^;]*)"));
+    if (match) {
+      request.headers["X-CSRF-Token"] = decodeURIComponent(match[1]);
+    }
+    return request;
+  }
+});
+</script>
+</body>
+</html>
+`))
+
+type OpenAPIHandler struct {
+	registry *openapi.Registry
+	specURL  string
+}
+
+// NewOpenAPIHandler serves the document of registry; specURL is where Spec is
+// mounted, for the Swagger UI to load.
+func NewOpenAPIHandler(registry *openapi.Registry, specURL string) *OpenAPIHandler {
+	return &OpenAPIHandler{registry: registry, specURL: specURL}
+}
+
+// Spec returns the OpenAPI document of the API.
+func (h *OpenAPIHandler) Spec(c *gin.Context) {
+	if h == nil || h.registry == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "API documentation not available"})
+		return
+	}
+	c.JSON(http.StatusOK, h.registry.Document())
+}
+
+// SwaggerUI serves a Swagger UI page for the document. Requests made from it
+// carry the auth cookie of the signed-in admin and the CSRF header the cookie
+// requires.
+func (h *OpenAPIHandler) SwaggerUI(c *gin.Context) {
+	if h == nil || h.registry == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "API documentation not available"})
+		return
+	}
+
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(http.StatusOK)
+	_ = swaggerUITemplate.Execute(c.Writer, map[string]string{
+		"Title":      h.registry.Document().Info.Title,
+		"Version":    swaggerUIVersion,
+		"SpecURL":    h.specURL,
+		"CSRFCookie": constants.CSRFTokenCookieName,
+	})
+}
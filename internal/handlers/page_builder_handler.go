@@ -1,18 +1,21 @@
 package handlers
 
 import (
+	"bytes"
 	"net/http"
 	"strconv"
 
 	"constructor-script-backend/internal/models"
 	"constructor-script-backend/internal/service"
+	"constructor-script-backend/pkg/apierror"
 	"constructor-script-backend/pkg/logger"
 
 	"github.com/gin-gonic/gin"
 )
 
 type PageBuilderHandler struct {
-	pageService *service.PageService
+	pageService     *service.PageService
+	templateHandler *TemplateHandler
 }
 
 func NewPageBuilderHandler(pageService *service.PageService) *PageBuilderHandler {
@@ -21,6 +24,12 @@ func NewPageBuilderHandler(pageService *service.PageService) *PageBuilderHandler
 	}
 }
 
+// SetTemplateHandler lets previews render pages through the public templates so
+// the generated structured data can be validated.
+func (h *PageBuilderHandler) SetTemplateHandler(templateHandler *TemplateHandler) {
+	h.templateHandler = templateHandler
+}
+
 // GetPageBuilder returns page data optimized for the page builder UI.
 // GET /api/admin/pages/:id/builder
 func (h *PageBuilderHandler) GetPageBuilder(c *gin.Context) {
@@ -78,7 +87,7 @@ func (h *PageBuilderHandler) ReorderSections(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		apierror.BindError(c, err)
 		return
 	}
 
@@ -103,7 +112,7 @@ func (h *PageBuilderHandler) AddSection(c *gin.Context) {
 
 	var req models.AddSectionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		apierror.BindError(c, err)
 		return
 	}
 
@@ -134,7 +143,7 @@ func (h *PageBuilderHandler) UpdateSection(c *gin.Context) {
 
 	var req models.UpdateSectionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		apierror.BindError(c, err)
 		return
 	}
 
@@ -229,7 +238,7 @@ func (h *PageBuilderHandler) CreateFromTemplate(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		apierror.BindError(c, err)
 		return
 	}
 
@@ -245,7 +254,9 @@ func (h *PageBuilderHandler) CreateFromTemplate(c *gin.Context) {
 	c.JSON(http.StatusCreated, gin.H{"page": page})
 }
 
-// PreviewPage returns page data formatted for preview mode.
+// PreviewPage returns page data formatted for preview mode. When the page can be
+// rendered, the JSON-LD it produces is validated and reported under
+// "structured_data" so broken markup is caught before the page goes live.
 // GET /api/admin/pages/:id/preview
 func (h *PageBuilderHandler) PreviewPage(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
@@ -260,10 +271,43 @@ func (h *PageBuilderHandler) PreviewPage(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	response := gin.H{
 		"page":         page,
 		"preview_mode": true,
-	})
+	}
+	if h.templateHandler != nil {
+		response["structured_data"] = h.previewStructuredData(c, page)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *PageBuilderHandler) previewStructuredData(c *gin.Context, page *models.Page) gin.H {
+	status, document := h.templateHandler.RenderPageDocument(c, page)
+	report := gin.H{
+		"status":   status,
+		"valid":    false,
+		"errors":   0,
+		"warnings": 0,
+		"blocks":   []service.StructuredDataResult{},
+	}
+
+	blocks, err := service.ValidateStructuredDocument(bytes.NewReader(document))
+	if err != nil {
+		logger.Error(err, "Failed to validate page structured data", map[string]interface{}{"page_id": page.ID})
+		return report
+	}
+
+	errorCount, warningCount := 0, 0
+	for _, block := range blocks {
+		errorCount += len(block.Errors)
+		warningCount += len(block.Warnings)
+	}
+	report["valid"] = status < http.StatusBadRequest && errorCount == 0
+	report["errors"] = errorCount
+	report["warnings"] = warningCount
+	report["blocks"] = blocks
+	return report
 }
 
 // ValidatePageSlug checks if a slug is available.
@@ -275,7 +319,7 @@ func (h *PageBuilderHandler) ValidatePageSlug(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		apierror.BindError(c, err)
 		return
 	}
 
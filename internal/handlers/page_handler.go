@@ -1,8 +1,11 @@
 package handlers
 
 import (
+	"constructor-script-backend/internal/fieldset"
+	"constructor-script-backend/internal/middleware"
 	"constructor-script-backend/internal/models"
 	"constructor-script-backend/internal/service"
+	"constructor-script-backend/pkg/apierror"
 	"constructor-script-backend/pkg/logger"
 	"net/http"
 	"strconv"
@@ -23,7 +26,7 @@ func (h *PageHandler) Create(c *gin.Context) {
 	var req models.CreatePageRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		logger.Error(err, "Failed to parse create page request", nil)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		apierror.BindError(c, err)
 		return
 	}
 
@@ -47,7 +50,7 @@ func (h *PageHandler) Update(c *gin.Context) {
 	var req models.UpdatePageRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		logger.Error(err, "Failed to parse update page request", nil)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		apierror.BindError(c, err)
 		return
 	}
 
@@ -102,6 +105,7 @@ func (h *PageHandler) GetByID(c *gin.Context) {
 		return
 	}
 
+	middleware.SetLastModified(c, page.UpdatedAt)
 	c.JSON(http.StatusOK, gin.H{"page": page})
 }
 
@@ -114,10 +118,20 @@ func (h *PageHandler) GetBySlug(c *gin.Context) {
 		return
 	}
 
+	middleware.SetLastModified(c, page.UpdatedAt)
 	c.JSON(http.StatusOK, gin.H{"page": page})
 }
 
+// pageFields is what ?fields= and ?include= select from on page lists.
+var pageFields = fieldset.NewSchema(models.Page{}, "sections")
+
 func (h *PageHandler) GetAll(c *gin.Context) {
+	selection, err := fieldset.FromQuery(c, pageFields)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	pages, err := h.pageService.GetAll()
 	if err != nil {
 		logger.Error(err, "Failed to retrieve all pages", nil)
@@ -125,10 +139,23 @@ func (h *PageHandler) GetAll(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"pages": pages})
+	items, err := selection.Apply(pages)
+	if err != nil {
+		logger.Error(err, "Failed to select page fields", nil)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve pages"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"pages": items})
 }
 
 func (h *PageHandler) GetAllAdmin(c *gin.Context) {
+	selection, err := fieldset.FromQuery(c, pageFields)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	pages, err := h.pageService.GetAllAdmin()
 	if err != nil {
 		logger.Error(err, "Failed to retrieve all admin pages", nil)
@@ -136,14 +163,21 @@ func (h *PageHandler) GetAllAdmin(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"pages": pages})
+	items, err := selection.Apply(pages)
+	if err != nil {
+		logger.Error(err, "Failed to select page fields", nil)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve pages"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"pages": items})
 }
 
 func (h *PageHandler) UpdateAllSectionPadding(c *gin.Context) {
 	var req models.UpdateAllPageSectionsPaddingRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		logger.Error(err, "Failed to parse update section padding request", nil)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		apierror.BindError(c, err)
 		return
 	}
 
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"constructor-script-backend/internal/payments"
+	"constructor-script-backend/internal/service"
+	"constructor-script-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PaymentHandler struct {
+	service *service.PaymentService
+}
+
+func NewPaymentHandler(service *service.PaymentService) *PaymentHandler {
+	return &PaymentHandler{service: service}
+}
+
+// Webhook receives Stripe checkout events for every feature that sells through the
+// payments module and hands paid sessions to the matching fulfiller.
+func (h *PaymentHandler) Webhook(c *gin.Context) {
+	if h == nil || h.service == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "payments are not configured"})
+		return
+	}
+
+	signature := strings.TrimSpace(c.GetHeader("Stripe-Signature"))
+	if signature == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing stripe signature"})
+		return
+	}
+
+	payload, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read webhook payload"})
+		return
+	}
+
+	err = h.service.HandleWebhook(c.Request.Context(), payload, signature)
+	switch {
+	case err == nil:
+		c.Status(http.StatusOK)
+	case errors.Is(err, service.ErrPaymentWebhookNotConfigured), errors.Is(err, payments.ErrCheckoutDisabled):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "stripe webhook not configured"})
+	case errors.Is(err, service.ErrInvalidPaymentWebhook):
+		logger.Warn("Rejected payment webhook", map[string]interface{}{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid webhook"})
+	default:
+		// A non-2xx response makes Stripe retry the delivery later.
+		logger.Error(err, "Failed to fulfil paid checkout session", nil)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fulfil checkout session"})
+	}
+}
@@ -3,8 +3,12 @@ package handlers
 import (
 	"errors"
 	"net/http"
+	"strconv"
+	"strings"
 
+	"constructor-script-backend/internal/models"
 	"constructor-script-backend/internal/service"
+	"constructor-script-backend/pkg/apierror"
 	"constructor-script-backend/pkg/logger"
 
 	"github.com/gin-gonic/gin"
@@ -48,6 +52,11 @@ func (h *PluginHandler) Install(c *gin.Context) {
 		return
 	}
 
+	if strings.HasPrefix(c.ContentType(), "application/json") {
+		h.installRemote(c)
+		return
+	}
+
 	file, header, err := c.Request.FormFile("file")
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "plugin archive is required"})
@@ -57,23 +66,50 @@ func (h *PluginHandler) Install(c *gin.Context) {
 
 	info, err := h.service.Install(file, header.Size, header.Filename)
 	if err != nil {
-		status := http.StatusInternalServerError
-		switch {
-		case errors.Is(err, service.ErrPluginManagerUnavailable):
-			status = http.StatusServiceUnavailable
-		case errors.Is(err, service.ErrPluginRepositoryUnavailable):
-			status = http.StatusServiceUnavailable
-		case errors.Is(err, service.ErrInvalidPluginPackage):
-			status = http.StatusBadRequest
-		}
 		logger.ErrorContext(ctx, err, "Failed to install plugin", map[string]interface{}{"filename": header.Filename})
-		c.JSON(status, gin.H{"error": err.Error()})
+		c.JSON(pluginInstallErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusCreated, gin.H{"plugin": info})
 }
 
+func (h *PluginHandler) installRemote(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req models.InstallPluginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BindError(c, err)
+		return
+	}
+
+	info, err := h.service.InstallRemote(ctx, req)
+	if err != nil {
+		logger.ErrorContext(ctx, err, "Failed to install remote plugin", map[string]interface{}{"url": req.URL, "slug": req.Slug})
+		c.JSON(pluginInstallErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"plugin": info})
+}
+
+func pluginInstallErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrPluginManagerUnavailable):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, service.ErrPluginRepositoryUnavailable):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, service.ErrInvalidPluginPackage):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrPluginNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrPluginIncompatible):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
 func (h *PluginHandler) Activate(c *gin.Context) {
 	ctx := c.Request.Context()
 
@@ -111,7 +147,8 @@ func (h *PluginHandler) Deactivate(c *gin.Context) {
 	}
 
 	slug := c.Param("slug")
-	info, err := h.service.Deactivate(slug)
+	purge, _ := strconv.ParseBool(c.Query("purge"))
+	info, err := h.service.Deactivate(slug, purge)
 	if err != nil {
 		status := http.StatusInternalServerError
 		switch {
@@ -139,7 +176,8 @@ func (h *PluginHandler) Delete(c *gin.Context) {
 	}
 
 	slug := c.Param("slug")
-	info, err := h.service.Delete(slug)
+	purge, _ := strconv.ParseBool(c.Query("purge"))
+	info, err := h.service.Delete(slug, purge)
 	if err != nil {
 		status := http.StatusInternalServerError
 		switch {
@@ -157,3 +195,62 @@ func (h *PluginHandler) Delete(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"plugin": info})
 }
+
+func (h *PluginHandler) GetSettings(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	if h == nil || h.service == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "plugin service unavailable"})
+		return
+	}
+
+	slug := c.Param("slug")
+	settings, err := h.service.GetSettings(slug)
+	if err != nil {
+		logger.ErrorContext(ctx, err, "Failed to load plugin settings", map[string]interface{}{"slug": slug})
+		c.JSON(pluginSettingsErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"settings": settings})
+}
+
+func (h *PluginHandler) UpdateSettings(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	if h == nil || h.service == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "plugin service unavailable"})
+		return
+	}
+
+	var req struct {
+		Values map[string]interface{} `json:"values" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BindError(c, err)
+		return
+	}
+
+	slug := c.Param("slug")
+	settings, err := h.service.UpdateSettings(slug, req.Values)
+	if err != nil {
+		logger.ErrorContext(ctx, err, "Failed to update plugin settings", map[string]interface{}{"slug": slug})
+		c.JSON(pluginSettingsErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"settings": settings})
+}
+
+func pluginSettingsErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrPluginManagerUnavailable):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, service.ErrPluginNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrInvalidPluginSettings):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
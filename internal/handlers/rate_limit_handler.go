@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/service"
+	"constructor-script-backend/pkg/apierror"
+	"constructor-script-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RateLimitHandler struct {
+	service *service.RateLimitService
+}
+
+func NewRateLimitHandler(svc *service.RateLimitService) *RateLimitHandler {
+	return &RateLimitHandler{service: svc}
+}
+
+func (h *RateLimitHandler) Get(c *gin.Context) {
+	if h.service == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Rate limit service not available"})
+		return
+	}
+
+	settings, err := h.service.GetSettings()
+	if err != nil {
+		logger.Error(err, "Failed to load rate limit settings", nil)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load rate limit settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"settings": settings})
+}
+
+func (h *RateLimitHandler) Update(c *gin.Context) {
+	if h.service == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Rate limit service not available"})
+		return
+	}
+
+	var req models.UpdateRateLimitSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BindError(c, err)
+		return
+	}
+
+	settings, err := h.service.UpdateSettings(req)
+	if err != nil {
+		var validationErr *service.RateLimitValidationError
+		if errors.As(err, &validationErr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		logger.Error(err, "Failed to update rate limit settings", nil)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update rate limit settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Rate limit settings updated",
+		"settings": settings,
+	})
+}
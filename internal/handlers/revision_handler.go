@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/service"
+	"constructor-script-backend/pkg/apierror"
+	"constructor-script-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type RevisionHandler struct {
+	service *service.RevisionService
+}
+
+func NewRevisionHandler(svc *service.RevisionService) *RevisionHandler {
+	return &RevisionHandler{service: svc}
+}
+
+func (h *RevisionHandler) available(c *gin.Context) bool {
+	if h.service == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Revision service not available"})
+		return false
+	}
+	return true
+}
+
+func parseRevisionID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid revision id"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// ListPosts lists the revisions of a post, newest first.
+func (h *RevisionHandler) ListPosts(c *gin.Context) {
+	h.list(c, models.RevisionContentPost)
+}
+
+// ListPages lists the revisions of a page, newest first.
+func (h *RevisionHandler) ListPages(c *gin.Context) {
+	h.list(c, models.RevisionContentPage)
+}
+
+func (h *RevisionHandler) list(c *gin.Context, contentType string) {
+	if !h.available(c) {
+		return
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + contentType + " id"})
+		return
+	}
+
+	revisions, err := h.service.List(contentType, uint(id))
+	if err != nil {
+		logger.Error(err, "Failed to list revisions", map[string]interface{}{"content_type": contentType, "content_id": id})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list revisions"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"revisions": revisions})
+}
+
+func (h *RevisionHandler) Get(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+	id, ok := parseRevisionID(c)
+	if !ok {
+		return
+	}
+
+	revision, err := h.service.Get(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Revision not found"})
+			return
+		}
+		logger.Error(err, "Failed to load revision", map[string]interface{}{"revision_id": id})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load revision"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"revision": revision})
+}
+
+// Diff compares a revision with the one before it, or with the revision in
+// the compare query parameter.
+func (h *RevisionHandler) Diff(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+	id, ok := parseRevisionID(c)
+	if !ok {
+		return
+	}
+	var compareID uint64
+	if raw := strings.TrimSpace(c.Query("compare")); raw != "" {
+		parsed, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid compare revision id"})
+			return
+		}
+		compareID = parsed
+	}
+
+	diff, err := h.service.Diff(id, uint(compareID))
+	if err != nil {
+		var validationErr *service.RevisionValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Revision not found"})
+		default:
+			logger.Error(err, "Failed to compare revisions", map[string]interface{}{"revision_id": id})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compare revisions"})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"diff": diff})
+}
+
+// Restore writes a revision back to its post or page.
+func (h *RevisionHandler) Restore(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+	id, ok := parseRevisionID(c)
+	if !ok {
+		return
+	}
+
+	revision, err := h.service.Restore(id)
+	if err != nil {
+		errMsg := err.Error()
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Revision or its content not found"})
+		case errors.Is(err, service.ErrRevisionNotRestorable), strings.Contains(errMsg, "already exists"):
+			c.JSON(http.StatusConflict, gin.H{"error": errMsg})
+		default:
+			logger.Error(err, "Failed to restore revision", map[string]interface{}{"revision_id": id})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore revision"})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Revision restored",
+		"content_type": revision.ContentType,
+		"content_id":   revision.ContentID,
+	})
+}
+
+func (h *RevisionHandler) GetSettings(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+
+	settings, err := h.service.GetSettings()
+	if err != nil {
+		logger.Error(err, "Failed to load revision settings", nil)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load revision settings"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"settings": settings})
+}
+
+func (h *RevisionHandler) UpdateSettings(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+
+	var req models.RevisionSettings
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BindError(c, err)
+		return
+	}
+
+	settings, err := h.service.UpdateSettings(req)
+	if err != nil {
+		var validationErr *service.RevisionValidationError
+		if errors.As(err, &validationErr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		logger.Error(err, "Failed to update revision settings", nil)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update revision settings"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Revision settings updated", "settings": settings})
+}
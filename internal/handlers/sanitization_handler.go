@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/service"
+	"constructor-script-backend/pkg/apierror"
+	"constructor-script-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SanitizationHandler struct {
+	service *service.SanitizationService
+}
+
+func NewSanitizationHandler(svc *service.SanitizationService) *SanitizationHandler {
+	return &SanitizationHandler{service: svc}
+}
+
+func (h *SanitizationHandler) GetSettings(c *gin.Context) {
+	if h.service == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Sanitization service not available"})
+		return
+	}
+
+	settings, err := h.service.GetSettings()
+	if err != nil {
+		logger.Error(err, "Failed to load sanitization settings", nil)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load sanitization settings"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"settings": settings})
+}
+
+func (h *SanitizationHandler) UpdateSettings(c *gin.Context) {
+	if h.service == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Sanitization service not available"})
+		return
+	}
+
+	var req models.SanitizationSettings
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BindError(c, err)
+		return
+	}
+
+	settings, err := h.service.UpdateSettings(req)
+	if err != nil {
+		var validationErr *service.SanitizationValidationError
+		if errors.As(err, &validationErr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+			return
+		}
+
+		logger.Error(err, "Failed to update sanitization settings", nil)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update sanitization settings"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Sanitization settings updated", "settings": settings})
+}
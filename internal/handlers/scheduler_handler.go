@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"net/http"
+
+	"constructor-script-backend/internal/background"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SchedulerHandler struct {
+	scheduler *background.Scheduler
+}
+
+func NewSchedulerHandler(scheduler *background.Scheduler) *SchedulerHandler {
+	return &SchedulerHandler{scheduler: scheduler}
+}
+
+// ListJobs returns the recurring jobs registered by the core and plugins with their
+// last and next run.
+func (h *SchedulerHandler) ListJobs(c *gin.Context) {
+	if h == nil || h.scheduler == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Scheduler not available"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"jobs":        h.scheduler.RecurringJobs(),
+		"active_jobs": h.scheduler.ActiveJobCount(),
+	})
+}
@@ -24,6 +24,7 @@ func (h *TemplateHandler) GetAvailableSections(c *gin.Context) {
 				"image",
 				"image_group",
 				"file_group",
+				"embed",
 				"list",
 				"search",
 				"posts_list",
@@ -1,33 +1,117 @@
 package handlers
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"constructor-script-backend/internal/config"
-	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/middleware"
 	"constructor-script-backend/internal/service"
+	"constructor-script-backend/internal/theme"
+	"constructor-script-backend/pkg/apierror"
+	"constructor-script-backend/pkg/lang"
 	"constructor-script-backend/pkg/logger"
 	blogservice "constructor-script-backend/plugins/blog/service"
+	courseservice "constructor-script-backend/plugins/courses/service"
+	forumservice "constructor-script-backend/plugins/forum/service"
 	languageservice "constructor-script-backend/plugins/language/service"
 
 	"github.com/gin-gonic/gin"
 )
 
+const (
+	sitemapXMLNS      = "http://www.sitemaps.org/schemas/sitemap/0.9"
+	sitemapImageXMLNS = "http://www.google.com/schemas/sitemap-image/1.1"
+	sitemapXHTMLXMLNS = "http://www.w3.org/1999/xhtml"
+	// sitemapMaxURLs is the protocol's limit per file; larger sections are split.
+	sitemapMaxURLs = 50000
+	// sitemapCacheTTL bounds how long rendered sitemaps are served after content
+	// changed on another instance; changes on this one drop them at once.
+	sitemapCacheTTL = 5 * time.Minute
+	// sitemapIndexFile keys the sitemap index among the rendered sitemaps.
+	sitemapIndexFile = "sitemap.xml"
+)
+
+type sitemapImage struct {
+	Loc string `xml:"image:loc"`
+}
+
+type sitemapAlternate struct {
+	Rel      string `xml:"rel,attr"`
+	Hreflang string `xml:"hreflang,attr"`
+	Href     string `xml:"href,attr"`
+}
+
 type sitemapURL struct {
-	Loc        string `xml:"loc"`
-	LastMod    string `xml:"lastmod,omitempty"`
-	ChangeFreq string `xml:"changefreq,omitempty"`
-	Priority   string `xml:"priority,omitempty"`
+	Loc        string             `xml:"loc"`
+	LastMod    string             `xml:"lastmod,omitempty"`
+	ChangeFreq string             `xml:"changefreq,omitempty"`
+	Priority   string             `xml:"priority,omitempty"`
+	Images     []sitemapImage     `xml:"image:image,omitempty"`
+	Alternates []sitemapAlternate `xml:"xhtml:link,omitempty"`
+
+	modified       time.Time
+	language       string
+	translationKey string
 }
 
 type sitemapURLSet struct {
-	XMLName xml.Name     `xml:"urlset"`
-	XMLNS   string       `xml:"xmlns,attr"`
-	URLs    []sitemapURL `xml:"url"`
+	XMLName    xml.Name     `xml:"urlset"`
+	XMLNS      string       `xml:"xmlns,attr"`
+	XMLNSImage string       `xml:"xmlns:image,attr,omitempty"`
+	XMLNSXHTML string       `xml:"xmlns:xhtml,attr,omitempty"`
+	URLs       []sitemapURL `xml:"url"`
+}
+
+type sitemapIndexEntry struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name            `xml:"sitemapindex"`
+	XMLNS    string              `xml:"xmlns,attr"`
+	Sitemaps []sitemapIndexEntry `xml:"sitemap"`
+}
+
+// sitemapSection is one group of URLs published as /sitemaps/<name>-<page>.xml.
+type sitemapSection struct {
+	Name string
+	URLs []sitemapURL
+}
+
+// sitemapDocument is a rendered sitemap file with the hash it is served under.
+type sitemapDocument struct {
+	body     []byte
+	etag     string
+	modified time.Time
+}
+
+type sitemapDocuments struct {
+	builtAt time.Time
+	files   map[string]sitemapDocument
+}
+
+func (s sitemapSection) pageCount() int {
+	return (len(s.URLs) + sitemapMaxURLs - 1) / sitemapMaxURLs
+}
+
+func (s sitemapSection) page(number int) []sitemapURL {
+	start := (number - 1) * sitemapMaxURLs
+	end := start + sitemapMaxURLs
+	if end > len(s.URLs) {
+		end = len(s.URLs)
+	}
+	return s.URLs[start:end]
 }
 
 // SEOHandler provides responses for SEO-focused endpoints like sitemap.xml and
@@ -36,9 +120,18 @@ type SEOHandler struct {
 	postService     *blogservice.PostService
 	pageService     *service.PageService
 	categoryService *blogservice.CategoryService
+	questionService *forumservice.QuestionService
+	packageService  *courseservice.PackageService
 	setupService    *service.SetupService
 	languageService *languageservice.LanguageService
+	translator      *service.MachineTranslationService
+	menuService     *service.MenuService
+	themeManager    *theme.Manager
 	config          *config.Config
+	renderer        http.Handler
+
+	sitemapMu sync.Mutex
+	sitemaps  *sitemapDocuments
 }
 
 // NewSEOHandler creates a new SEO handler with the required dependencies.
@@ -67,6 +160,25 @@ func (h *SEOHandler) SetBlogServices(postService *blogservice.PostService, categ
 	}
 	h.postService = postService
 	h.categoryService = categoryService
+	h.InvalidateSitemaps()
+}
+
+// SetForumService updates the service backing the forum question sitemap.
+func (h *SEOHandler) SetForumService(questionService *forumservice.QuestionService) {
+	if h == nil {
+		return
+	}
+	h.questionService = questionService
+	h.InvalidateSitemaps()
+}
+
+// SetCoursePackageService updates the service backing the course sitemap.
+func (h *SEOHandler) SetCoursePackageService(packageService *courseservice.PackageService) {
+	if h == nil {
+		return
+	}
+	h.packageService = packageService
+	h.InvalidateSitemaps()
 }
 
 // SetLanguageService updates the language service dependency used by the SEO handler.
@@ -77,122 +189,616 @@ func (h *SEOHandler) SetLanguageService(languageService *languageservice.Languag
 	h.languageService = languageService
 }
 
-// Sitemap renders an XML sitemap that includes the key public sections of the
-// site along with all published posts, pages, categories and tags.
-func (h *SEOHandler) Sitemap(c *gin.Context) {
-	if h.postService == nil || h.categoryService == nil {
-		c.String(http.StatusServiceUnavailable, "Posts plugin is not active")
+// SetMachineTranslationService sets the provider used for translation drafts.
+func (h *SEOHandler) SetMachineTranslationService(translator *service.MachineTranslationService) {
+	if h == nil {
 		return
 	}
+	h.translator = translator
+}
 
-	siteSettings, err := ResolveSiteSettings(h.config, h.setupService, h.languageService)
+// SetRenderer sets the handler used to render public pages for SEO audits.
+func (h *SEOHandler) SetRenderer(renderer http.Handler) {
+	if h == nil {
+		return
+	}
+	h.renderer = renderer
+}
+
+// Audit renders the page at the given path internally and reports SEO problems
+// such as a missing meta description, duplicate H1s or invalid structured data.
+// GET /api/v1/admin/seo/audit?path=/about
+func (h *SEOHandler) Audit(c *gin.Context) {
+	path := strings.TrimSpace(c.Query("path"))
+	if path == "" || !strings.HasPrefix(path, "/") || strings.HasPrefix(path, "//") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "path must be a site-relative URL such as /about"})
+		return
+	}
+	if strings.HasPrefix(path, "/api/") || path == "/admin" || strings.HasPrefix(path, "/admin/") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "only public pages can be audited"})
+		return
+	}
+	if h.renderer == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "page renderer unavailable"})
+		return
+	}
+
+	request, err := http.NewRequestWithContext(c.Request.Context(), http.MethodGet, path, nil)
 	if err != nil {
-		logger.Error(err, "Failed to resolve site settings", nil)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid path"})
+		return
+	}
+	request.Host = c.Request.Host
+	request.RemoteAddr = c.Request.RemoteAddr
+	request.Header.Set("Accept", "text/html")
+	// Forwarding the editor's cookies renders the page as they would see it.
+	for _, cookie := range c.Request.Cookies() {
+		request.AddCookie(cookie)
 	}
 
-	baseURL := h.normalizedBaseURL(siteSettings.URL)
-	if baseURL == "" {
-		c.String(http.StatusInternalServerError, "Unable to determine site URL")
+	recorder := httptest.NewRecorder()
+	h.renderer.ServeHTTP(recorder, request)
+
+	if location := recorder.Header().Get("Location"); recorder.Code >= 300 && recorder.Code < 400 {
+		c.JSON(http.StatusOK, gin.H{"report": nil, "redirect": location, "status": recorder.Code})
 		return
 	}
 
-	posts, err := h.postService.ListPublishedForSitemap()
+	report, err := service.AuditHTML(path, recorder.Code, recorder.Body)
 	if err != nil {
-		logger.Error(err, "Failed to load posts for sitemap", nil)
-		c.String(http.StatusInternalServerError, "Failed to build sitemap")
+		logger.Error(err, "Failed to audit page", map[string]interface{}{"path": path})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to audit page"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"report": report})
+}
+
+// TranslationStatus reports, for every supported language, which posts and pages
+// are translated, missing a translation or translated from an older version of the
+// default-language content. It requires the language plugin.
+// GET /api/v1/admin/translations/status
+func (h *SEOHandler) TranslationStatus(c *gin.Context) {
+	if h.languageService == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Language plugin is not active"})
 		return
 	}
 
-	pages, err := h.pageService.GetAll()
+	defaultLanguage, supported, err := h.languageService.Resolve("", nil)
 	if err != nil {
-		logger.Error(err, "Failed to load pages for sitemap", nil)
-		c.String(http.StatusInternalServerError, "Failed to build sitemap")
+		logger.Error(err, "Failed to resolve site languages", nil)
+	}
+
+	var items []service.TranslationItem
+	if h.pageService != nil {
+		pages, err := h.pageService.GetAllAdmin()
+		if err != nil {
+			logger.Error(err, "Failed to load pages for translation status", nil)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load pages"})
+			return
+		}
+		for _, page := range pages {
+			items = append(items, service.TranslationItem{
+				Type:           service.TranslationContentPage,
+				ID:             page.ID,
+				Title:          page.Title,
+				Path:           h.localizedPath(page.Language, page.Path, defaultLanguage),
+				Language:       page.Language,
+				TranslationKey: page.TranslationKey,
+				Published:      page.Published,
+				UpdatedAt:      page.UpdatedAt,
+			})
+		}
+	}
+	if h.postService != nil {
+		posts, err := h.postService.ListTranslationSummaries()
+		if err != nil {
+			logger.Error(err, "Failed to load posts for translation status", nil)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load posts"})
+			return
+		}
+		for _, post := range posts {
+			items = append(items, service.TranslationItem{
+				Type:           service.TranslationContentPost,
+				ID:             post.ID,
+				Title:          post.Title,
+				Path:           h.localizedPath(post.Language, postPath(post), defaultLanguage),
+				Language:       post.Language,
+				TranslationKey: post.TranslationKey,
+				Published:      post.Published,
+				UpdatedAt:      post.UpdatedAt,
+			})
+		}
+	}
+
+	c.JSON(http.StatusOK, service.BuildTranslationStatus(defaultLanguage, supported, items))
+}
+
+type translationSuggestRequest struct {
+	service.TranslationSuggestionRequest
+	Type string `json:"type"`
+	ID   uint   `json:"id"`
+}
+
+// SuggestTranslation returns a machine translated draft of a post, a page or the
+// given fields. Nothing is saved; the editor reviews the draft and creates the
+// translation themselves. Explicit fields win over the stored content, so drafts
+// can include unsaved edits.
+func (h *SEOHandler) SuggestTranslation(c *gin.Context) {
+	if h.translator == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Machine translation is not configured"})
 		return
 	}
 
-	categories, err := h.categoryService.GetAll()
+	var req translationSuggestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BindError(c, err)
+		return
+	}
+
+	if req.Type != "" || req.ID != 0 {
+		language, fields, status, err := h.translationSource(req.Type, req.ID)
+		if err != nil {
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		if len(req.Fields) == 0 {
+			req.Fields = fields
+		}
+		if strings.TrimSpace(req.SourceLanguage) == "" {
+			req.SourceLanguage = language
+		}
+	}
+	if strings.TrimSpace(req.SourceLanguage) == "" && h.languageService != nil {
+		req.SourceLanguage, _ = h.languageService.Defaults()
+	}
+
+	suggestion, err := h.translator.Suggest(c.Request.Context(), req.TranslationSuggestionRequest)
 	if err != nil {
-		logger.Error(err, "Failed to load categories for sitemap", nil)
-		c.String(http.StatusInternalServerError, "Failed to build sitemap")
+		status := http.StatusBadGateway
+		switch {
+		case errors.Is(err, service.ErrInvalidTranslationRequest):
+			status = http.StatusBadRequest
+		case errors.Is(err, service.ErrMachineTranslationNotConfigured):
+			status = http.StatusServiceUnavailable
+		default:
+			logger.ErrorContext(c.Request.Context(), err, "Machine translation failed", map[string]interface{}{"type": req.Type, "id": req.ID})
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"suggestion": suggestion})
+}
+
+// translationSource loads the language and translatable fields of a post or page.
+func (h *SEOHandler) translationSource(contentType string, id uint) (string, map[string]string, int, error) {
+	if id == 0 {
+		return "", nil, http.StatusBadRequest, errors.New("id is required")
+	}
+
+	switch contentType {
+	case service.TranslationContentPost:
+		if h.postService == nil {
+			return "", nil, http.StatusServiceUnavailable, errors.New("blog plugin is not active")
+		}
+		post, err := h.postService.GetByID(id)
+		if err != nil {
+			return "", nil, http.StatusNotFound, errors.New("post not found")
+		}
+		return post.Language, map[string]string{
+			"title":       post.Title,
+			"description": post.Description,
+			"excerpt":     post.Excerpt,
+			"content":     post.Content,
+		}, http.StatusOK, nil
+	case service.TranslationContentPage:
+		if h.pageService == nil {
+			return "", nil, http.StatusServiceUnavailable, errors.New("pages are not available")
+		}
+		page, err := h.pageService.GetByID(id)
+		if err != nil {
+			return "", nil, http.StatusNotFound, errors.New("page not found")
+		}
+		return page.Language, map[string]string{
+			"title":       page.Title,
+			"description": page.Description,
+			"content":     page.Content,
+		}, http.StatusOK, nil
+	default:
+		return "", nil, http.StatusBadRequest, errors.New("type must be post or page")
+	}
+}
+
+// Sitemap renders a sitemap index linking one sitemap per section of the site.
+// Sections over the 50,000 URL limit are split across several files.
+func (h *SEOHandler) Sitemap(c *gin.Context) {
+	documents, ok := h.loadSitemaps(c)
+	if !ok {
+		return
+	}
+	writeSitemap(c, documents.files[sitemapIndexFile])
+}
+
+// SitemapSection renders one page of a section sitemap, such as posts-1.xml.
+func (h *SEOHandler) SitemapSection(c *gin.Context) {
+	name, number, ok := parseSitemapFilename(c.Param("file"))
+	if !ok {
+		c.Status(http.StatusNotFound)
 		return
 	}
 
-	tags, err := h.postService.GetTagsInUse()
+	documents, ok := h.loadSitemaps(c)
+	if !ok {
+		return
+	}
+	document, found := documents.files[fmt.Sprintf("%s-%d.xml", name, number)]
+	if !found {
+		c.Status(http.StatusNotFound)
+		return
+	}
+	writeSitemap(c, document)
+}
+
+// writeSitemap sends a rendered sitemap with its ETag and Last-Modified, which
+// ConditionalGetMiddleware answers If-None-Match and If-Modified-Since with.
+func writeSitemap(c *gin.Context, document sitemapDocument) {
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.Header("ETag", document.etag)
+	middleware.SetLastModified(c, document.modified)
+	c.Data(http.StatusOK, "application/xml; charset=utf-8", document.body)
+}
+
+// InvalidateSitemaps drops the rendered sitemaps after content changed, so the
+// next request builds them again.
+func (h *SEOHandler) InvalidateSitemaps() {
+	if h == nil {
+		return
+	}
+	h.sitemapMu.Lock()
+	h.sitemaps = nil
+	h.sitemapMu.Unlock()
+}
+
+// loadSitemaps returns the rendered sitemaps, building them when they are missing
+// or older than sitemapCacheTTL. Concurrent requests wait for one build instead
+// of each querying every section. It writes an error response and returns false
+// on failure.
+func (h *SEOHandler) loadSitemaps(c *gin.Context) (*sitemapDocuments, bool) {
+	h.sitemapMu.Lock()
+	defer h.sitemapMu.Unlock()
+
+	if h.sitemaps != nil && time.Since(h.sitemaps.builtAt) < sitemapCacheTTL {
+		return h.sitemaps, true
+	}
+
+	baseURL, sections, ok := h.loadSitemapSections(c)
+	if !ok {
+		return nil, false
+	}
+	documents, err := h.renderSitemaps(baseURL, sections)
 	if err != nil {
-		logger.Error(err, "Failed to load tags for sitemap", nil)
+		logger.Error(err, "Failed to render sitemaps", nil)
 		c.String(http.StatusInternalServerError, "Failed to build sitemap")
-		return
+		return nil, false
+	}
+	h.sitemaps = documents
+	return documents, true
+}
+
+// renderSitemaps renders the index and every section page of the sitemap.
+func (h *SEOHandler) renderSitemaps(baseURL string, sections []sitemapSection) (*sitemapDocuments, error) {
+	documents := &sitemapDocuments{builtAt: time.Now(), files: make(map[string]sitemapDocument)}
+
+	index := sitemapIndex{XMLNS: sitemapXMLNS}
+	var indexModified time.Time
+	for _, section := range sections {
+		for number := 1; number <= section.pageCount(); number++ {
+			urls := section.page(number)
+			response := sitemapURLSet{XMLNS: sitemapXMLNS, URLs: urls}
+			var lastMod time.Time
+			for _, entry := range urls {
+				if entry.modified.After(lastMod) {
+					lastMod = entry.modified
+				}
+				if len(entry.Images) > 0 {
+					response.XMLNSImage = sitemapImageXMLNS
+				}
+				if len(entry.Alternates) > 0 {
+					response.XMLNSXHTML = sitemapXHTMLXMLNS
+				}
+			}
+			if lastMod.After(indexModified) {
+				indexModified = lastMod
+			}
+
+			file := fmt.Sprintf("%s-%d.xml", section.Name, number)
+			document, err := newSitemapDocument(response, lastMod)
+			if err != nil {
+				return nil, fmt.Errorf("render %s: %w", file, err)
+			}
+			documents.files[file] = document
+
+			index.Sitemaps = append(index.Sitemaps, sitemapIndexEntry{
+				Loc:     h.joinURL(baseURL, "/sitemaps/"+file),
+				LastMod: h.formatLastMod(lastMod),
+			})
+		}
 	}
 
-	urls := []sitemapURL{
+	document, err := newSitemapDocument(index, indexModified)
+	if err != nil {
+		return nil, fmt.Errorf("render sitemap index: %w", err)
+	}
+	documents.files[sitemapIndexFile] = document
+	return documents, nil
+}
+
+func newSitemapDocument(content interface{}, modified time.Time) (sitemapDocument, error) {
+	body, err := xml.Marshal(content)
+	if err != nil {
+		return sitemapDocument{}, err
+	}
+	sum := sha256.Sum256(body)
+	return sitemapDocument{
+		body:     body,
+		etag:     `"` + hex.EncodeToString(sum[:16]) + `"`,
+		modified: modified,
+	}, nil
+}
+
+func parseSitemapFilename(file string) (string, int, bool) {
+	base, ok := strings.CutSuffix(strings.TrimSpace(file), ".xml")
+	if !ok {
+		return "", 0, false
+	}
+	separator := strings.LastIndex(base, "-")
+	if separator <= 0 {
+		return "", 0, false
+	}
+	number, err := strconv.Atoi(base[separator+1:])
+	if err != nil || number < 1 {
+		return "", 0, false
+	}
+	return base[:separator], number, true
+}
+
+// loadSitemapSections resolves the site URL and collects the URLs of every active
+// section. It writes an error response and returns false on failure.
+func (h *SEOHandler) loadSitemapSections(c *gin.Context) (string, []sitemapSection, bool) {
+	siteSettings, err := ResolveSiteSettings(h.config, h.setupService, h.languageService)
+	if err != nil {
+		logger.Error(err, "Failed to resolve site settings", nil)
+	}
+
+	baseURL := h.normalizedBaseURL(siteSettings.URL)
+	if baseURL == "" {
+		c.String(http.StatusInternalServerError, "Unable to determine site URL")
+		return "", nil, false
+	}
+
+	sections, err := h.sitemapSections(baseURL, siteSettings.DefaultLanguage)
+	if err != nil {
+		logger.Error(err, "Failed to load sitemap entries", nil)
+		c.String(http.StatusInternalServerError, "Failed to build sitemap")
+		return "", nil, false
+	}
+	return baseURL, sections, true
+}
+
+func (h *SEOHandler) sitemapSections(baseURL, defaultLanguage string) ([]sitemapSection, error) {
+	pages := sitemapSection{Name: "pages", URLs: []sitemapURL{
 		{Loc: baseURL + "/", ChangeFreq: "daily", Priority: "1.0"},
-		{Loc: h.joinURL(baseURL, "/blog"), ChangeFreq: "daily", Priority: "0.8"},
+	}}
+	if h.postService != nil {
+		pages.URLs = append(pages.URLs, sitemapURL{Loc: h.joinURL(baseURL, "/blog"), ChangeFreq: "daily", Priority: "0.8"})
 	}
 
-	for _, post := range posts {
-		loc := h.joinURL(baseURL, h.postPath(post))
-		lastMod := post.UpdatedAt
-		if lastMod.IsZero() {
-			lastMod = post.CreatedAt
+	if h.pageService != nil {
+		items, err := h.pageService.GetAll()
+		if err != nil {
+			return nil, fmt.Errorf("load pages: %w", err)
+		}
+		for _, page := range items {
+			if page.Slug == "" && strings.TrimSpace(page.Path) == "" {
+				continue
+			}
+			if page.NoIndex || page.SitemapExclude {
+				continue
+			}
+
+			path := strings.TrimSpace(page.Path)
+			if path == "" {
+				path = fmt.Sprintf("/page/%s", page.Slug)
+			}
+
+			entry := h.sitemapEntry(baseURL, h.localizedPath(page.Language, path, defaultLanguage), page.UpdatedAt, "monthly", "0.6", page.FeaturedImg)
+			entry.language, entry.translationKey = page.Language, page.TranslationKey
+			pages.URLs = append(pages.URLs, entry)
 		}
+		h.linkSitemapTranslations(pages.URLs, defaultLanguage)
+	}
+	sections := []sitemapSection{pages}
 
-		urls = append(urls, sitemapURL{
-			Loc:        loc,
-			LastMod:    h.formatLastMod(lastMod),
-			ChangeFreq: "weekly",
-			Priority:   "0.7",
-		})
+	if h.postService != nil {
+		posts, err := h.postService.ListPublishedForSitemap()
+		if err != nil {
+			return nil, fmt.Errorf("load posts: %w", err)
+		}
+		section := sitemapSection{Name: "posts"}
+		for _, post := range posts {
+			if post.NoIndex || post.SitemapExclude {
+				continue
+			}
+			lastMod := post.UpdatedAt
+			if lastMod.IsZero() {
+				lastMod = post.CreatedAt
+			}
+			entry := h.sitemapEntry(baseURL, h.localizedPath(post.Language, postPath(post), defaultLanguage), lastMod, "weekly", "0.7", post.FeaturedImg)
+			entry.language, entry.translationKey = post.Language, post.TranslationKey
+			section.URLs = append(section.URLs, entry)
+		}
+		h.linkSitemapTranslations(section.URLs, defaultLanguage)
+		sections = append(sections, section)
 	}
 
-	for _, page := range pages {
-		if page.Slug == "" && strings.TrimSpace(page.Path) == "" {
-			continue
+	if h.categoryService != nil && h.postService != nil {
+		categories, err := h.categoryService.GetAll()
+		if err != nil {
+			return nil, fmt.Errorf("load categories: %w", err)
+		}
+		tags, err := h.postService.GetTagsInUse()
+		if err != nil {
+			return nil, fmt.Errorf("load tags: %w", err)
 		}
 
-		path := strings.TrimSpace(page.Path)
-		if path == "" {
-			path = fmt.Sprintf("/page/%s", page.Slug)
+		section := sitemapSection{Name: "taxonomies"}
+		for _, category := range categories {
+			if category.Slug == "" || category.NoIndex || category.SitemapExclude {
+				continue
+			}
+			section.URLs = append(section.URLs, h.sitemapEntry(baseURL, fmt.Sprintf("/category/%s", category.Slug), category.UpdatedAt, "weekly", "0.5", ""))
 		}
+		for _, tag := range tags {
+			if tag.Slug == "" {
+				continue
+			}
+			section.URLs = append(section.URLs, h.sitemapEntry(baseURL, fmt.Sprintf("/tag/%s", tag.Slug), tag.UpdatedAt, "weekly", "0.4", ""))
+		}
+		sections = append(sections, section)
+	}
 
-		urls = append(urls, sitemapURL{
-			Loc:        h.joinURL(baseURL, path),
-			LastMod:    h.formatLastMod(page.UpdatedAt),
-			ChangeFreq: "monthly",
-			Priority:   "0.6",
-		})
+	if h.questionService != nil {
+		questions, err := h.questionService.ListForSitemap()
+		if err != nil {
+			return nil, fmt.Errorf("load forum questions: %w", err)
+		}
+		section := sitemapSection{Name: "forum", URLs: []sitemapURL{
+			{Loc: h.joinURL(baseURL, "/forum"), ChangeFreq: "daily", Priority: "0.6"},
+		}}
+		for _, question := range questions {
+			if question.Slug == "" {
+				continue
+			}
+			section.URLs = append(section.URLs, h.sitemapEntry(baseURL, fmt.Sprintf("/forum/%s", question.Slug), question.UpdatedAt, "weekly", "0.5", ""))
+		}
+		sections = append(sections, section)
 	}
 
-	for _, category := range categories {
-		if category.Slug == "" {
-			continue
+	if h.packageService != nil {
+		packages, err := h.packageService.ListForSitemap()
+		if err != nil {
+			return nil, fmt.Errorf("load courses: %w", err)
 		}
+		section := sitemapSection{Name: "courses"}
+		for _, pkg := range packages {
+			if pkg.Slug == "" {
+				continue
+			}
+			section.URLs = append(section.URLs, h.sitemapEntry(baseURL, fmt.Sprintf("/courses/%s", pkg.Slug), pkg.UpdatedAt, "weekly", "0.6", pkg.ImageURL))
+		}
+		sections = append(sections, section)
+	}
 
-		urls = append(urls, sitemapURL{
-			Loc:        h.joinURL(baseURL, fmt.Sprintf("/category/%s", category.Slug)),
-			ChangeFreq: "weekly",
-			Priority:   "0.5",
-		})
+	// Sections without entries would only produce empty files.
+	populated := sections[:0]
+	for _, section := range sections {
+		if len(section.URLs) > 0 {
+			populated = append(populated, section)
+		}
 	}
+	return populated, nil
+}
 
-	for _, tag := range tags {
-		if tag.Slug == "" {
-			continue
+// localizedPath prefixes the path of non-default-language content with its
+// language, matching the URLs the template handler serves it under.
+func (h *SEOHandler) localizedPath(language, path, defaultLanguage string) string {
+	if h.languageService == nil {
+		return path
+	}
+	if defaultLanguage = strings.TrimSpace(defaultLanguage); defaultLanguage == "" {
+		defaultLanguage = lang.Default
+	}
+	return lang.LocalizePath(path, strings.TrimSpace(language), defaultLanguage)
+}
+
+// linkSitemapTranslations adds xhtml:link alternates to entries that share a
+// translation key, mirroring the hreflang links rendered on the pages themselves.
+// It does nothing unless the language plugin is active.
+func (h *SEOHandler) linkSitemapTranslations(urls []sitemapURL, defaultLanguage string) {
+	if h.languageService == nil {
+		return
+	}
+	defaultLanguage = strings.TrimSpace(defaultLanguage)
+	if defaultLanguage == "" {
+		defaultLanguage = lang.Default
+	}
+
+	groups := make(map[string][]int)
+	for i, entry := range urls {
+		if entry.translationKey != "" {
+			groups[entry.translationKey] = append(groups[entry.translationKey], i)
 		}
+	}
 
-		urls = append(urls, sitemapURL{
-			Loc:        h.joinURL(baseURL, fmt.Sprintf("/tag/%s", tag.Slug)),
-			ChangeFreq: "weekly",
-			Priority:   "0.4",
-		})
+	for _, members := range groups {
+		alternates := make([]sitemapAlternate, 0, len(members)+1)
+		seen := make(map[string]struct{}, len(members))
+		xDefault := ""
+		for _, i := range members {
+			code := urls[i].language
+			if code == "" {
+				code = defaultLanguage
+			}
+			if _, exists := seen[code]; exists {
+				continue
+			}
+			seen[code] = struct{}{}
+			alternates = append(alternates, sitemapAlternate{Rel: "alternate", Hreflang: code, Href: urls[i].Loc})
+			if code == defaultLanguage {
+				xDefault = urls[i].Loc
+			}
+		}
+		if len(alternates) < 2 {
+			continue
+		}
+		if xDefault != "" {
+			alternates = append(alternates, sitemapAlternate{Rel: "alternate", Hreflang: "x-default", Href: xDefault})
+		}
+		for _, i := range members {
+			urls[i].Alternates = alternates
+		}
 	}
+}
 
-	response := sitemapURLSet{
-		XMLNS: "http://www.sitemaps.org/schemas/sitemap/0.9",
-		URLs:  urls,
+func (h *SEOHandler) sitemapEntry(baseURL, path string, lastMod time.Time, changeFreq, priority, image string) sitemapURL {
+	entry := sitemapURL{
+		Loc:        h.joinURL(baseURL, path),
+		LastMod:    h.formatLastMod(lastMod),
+		ChangeFreq: changeFreq,
+		Priority:   priority,
+		modified:   lastMod,
 	}
+	if loc := h.absoluteURL(baseURL, image); loc != "" {
+		entry.Images = []sitemapImage{{Loc: loc}}
+	}
+	return entry
+}
 
-	c.Header("Cache-Control", "public, max-age=3600")
-	c.XML(http.StatusOK, response)
+// absoluteURL resolves a stored image reference against the site URL. External
+// images are kept as they are.
+func (h *SEOHandler) absoluteURL(baseURL, raw string) string {
+	trimmed := strings.TrimSpace(raw)
+	switch {
+	case trimmed == "":
+		return ""
+	case strings.HasPrefix(trimmed, "http://"), strings.HasPrefix(trimmed, "https://"):
+		return trimmed
+	case strings.HasPrefix(trimmed, "//"):
+		return ""
+	default:
+		return h.joinURL(baseURL, trimmed)
+	}
 }
 
 // Robots renders a robots.txt file that guides crawlers and references the
@@ -216,6 +822,10 @@ func (h *SEOHandler) Robots(c *gin.Context) {
 		"Disallow: /profile",
 		"Disallow: /api/",
 	}
+	for _, path := range h.noIndexPaths() {
+		// The $ anchor keeps /about from also blocking /about-us.
+		lines = append(lines, fmt.Sprintf("Disallow: %s$", path))
+	}
 
 	if sitemapURL != "" {
 		lines = append(lines, fmt.Sprintf("Sitemap: %s", sitemapURL))
@@ -226,6 +836,50 @@ func (h *SEOHandler) Robots(c *gin.Context) {
 	c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(body))
 }
 
+// noIndexPaths lists the published pages, posts and categories flagged noindex so
+// robots.txt can keep crawlers away from them. Failures are logged and skipped.
+func (h *SEOHandler) noIndexPaths() []string {
+	var paths []string
+
+	if h.pageService != nil {
+		pages, err := h.pageService.GetAll()
+		if err != nil {
+			logger.Error(err, "Failed to load pages for robots.txt", nil)
+		}
+		for _, page := range pages {
+			if page.NoIndex && strings.TrimSpace(page.Path) != "" && page.Path != "/" {
+				paths = append(paths, page.Path)
+			}
+		}
+	}
+
+	if h.postService != nil {
+		posts, err := h.postService.ListPublishedForSitemap()
+		if err != nil {
+			logger.Error(err, "Failed to load posts for robots.txt", nil)
+		}
+		for _, post := range posts {
+			if post.NoIndex {
+				paths = append(paths, postPath(post))
+			}
+		}
+	}
+
+	if h.categoryService != nil {
+		categories, err := h.categoryService.GetAll()
+		if err != nil {
+			logger.Error(err, "Failed to load categories for robots.txt", nil)
+		}
+		for _, category := range categories {
+			if category.NoIndex && category.Slug != "" {
+				paths = append(paths, fmt.Sprintf("/category/%s", category.Slug))
+			}
+		}
+	}
+
+	return paths
+}
+
 func (h *SEOHandler) normalizedBaseURL(raw string) string {
 	trimmed := strings.TrimSpace(raw)
 	if trimmed == "" {
@@ -243,13 +897,6 @@ func (h *SEOHandler) joinURL(base, path string) string {
 	return base + path
 }
 
-func (h *SEOHandler) postPath(post models.Post) string {
-	if post.Slug != "" {
-		return fmt.Sprintf("/blog/post/%s", post.Slug)
-	}
-	return fmt.Sprintf("/blog/post/%d", post.ID)
-}
-
 func (h *SEOHandler) formatLastMod(value time.Time) string {
 	if value.IsZero() {
 		return ""
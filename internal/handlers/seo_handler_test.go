@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"constructor-script-backend/internal/config"
+	"constructor-script-backend/internal/middleware"
+	languageservice "constructor-script-backend/plugins/language/service"
+)
+
+func TestSitemapIndexLinksSectionSitemaps(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := NewSEOHandler(nil, nil, nil, nil, nil, &config.Config{SiteURL: "https://example.com/"})
+	router := gin.New()
+	router.GET("/sitemap.xml", handler.Sitemap)
+	router.GET("/sitemaps/:file", handler.SitemapSection)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/sitemap.xml", nil))
+	if recorder.Code != http.StatusOK || !strings.Contains(recorder.Body.String(), "<sitemapindex") ||
+		!strings.Contains(recorder.Body.String(), "<loc>https://example.com/sitemaps/pages-1.xml</loc>") {
+		t.Fatalf("unexpected sitemap index: %d %s", recorder.Code, recorder.Body.String())
+	}
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/sitemaps/pages-1.xml", nil))
+	if recorder.Code != http.StatusOK || !strings.Contains(recorder.Body.String(), "<loc>https://example.com/</loc>") {
+		t.Fatalf("unexpected pages sitemap: %d %s", recorder.Code, recorder.Body.String())
+	}
+
+	for _, path := range []string{"/sitemaps/pages-2.xml", "/sitemaps/posts-1.xml", "/sitemaps/pages.xml"} {
+		recorder = httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		if recorder.Code != http.StatusNotFound {
+			t.Fatalf("expected %s to be missing, got %d", path, recorder.Code)
+		}
+	}
+}
+
+func TestSitemapAnswersConditionalRequests(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := NewSEOHandler(nil, nil, nil, nil, nil, &config.Config{SiteURL: "https://example.com/"})
+	router := gin.New()
+	router.Use(middleware.ConditionalGetMiddleware())
+	router.GET("/sitemap.xml", handler.Sitemap)
+
+	get := func(etag string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, "/sitemap.xml", nil)
+		if etag != "" {
+			request.Header.Set("If-None-Match", etag)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	first := get("")
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || etag == "" {
+		t.Fatalf("expected a tagged sitemap, got %d %v", first.Code, first.Header())
+	}
+	built := handler.sitemaps
+
+	if recorder := get(etag); recorder.Code != http.StatusNotModified || recorder.Body.Len() != 0 {
+		t.Fatalf("expected 304 for a matching ETag, got %d", recorder.Code)
+	}
+	if handler.sitemaps != built {
+		t.Fatal("expected the rendered sitemaps to be reused")
+	}
+
+	handler.InvalidateSitemaps()
+	if recorder := get(etag); recorder.Code != http.StatusNotModified || handler.sitemaps == built {
+		t.Fatalf("expected a rebuilt but unchanged sitemap to keep its ETag, got %d", recorder.Code)
+	}
+}
+
+func TestSitemapSectionPagesAndImages(t *testing.T) {
+	handler := &SEOHandler{}
+	section := sitemapSection{Name: "posts"}
+	for i := 0; i < sitemapMaxURLs+1; i++ {
+		section.URLs = append(section.URLs, sitemapURL{})
+	}
+	if section.pageCount() != 2 || len(section.page(1)) != sitemapMaxURLs || len(section.page(2)) != 1 {
+		t.Fatalf("unexpected pagination: %d pages", section.pageCount())
+	}
+
+	modified := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	entry := handler.sitemapEntry("https://example.com", "/blog/post/hello", modified, "weekly", "0.7", "/uploads/hello.jpg")
+	body, err := xml.Marshal(sitemapURLSet{XMLNS: sitemapXMLNS, XMLNSImage: sitemapImageXMLNS, URLs: []sitemapURL{entry}})
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	for _, want := range []string{
+		`xmlns:image="http://www.google.com/schemas/sitemap-image/1.1"`,
+		`<lastmod>2024-05-01T12:00:00Z</lastmod>`,
+		`<image:image><image:loc>https://example.com/uploads/hello.jpg</image:loc></image:image>`,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Fatalf("expected %s in %s", want, body)
+		}
+	}
+}
+
+func TestSitemapLinksTranslations(t *testing.T) {
+	handler := &SEOHandler{languageService: languageservice.NewLanguageService(&config.Config{}, nil)}
+	urls := []sitemapURL{
+		{Loc: "https://example.com/about", translationKey: "about"},
+		{Loc: "https://example.com/de/uber-uns", language: "de", translationKey: "about"},
+		{Loc: "https://example.com/contact"},
+	}
+	handler.linkSitemapTranslations(urls, "en")
+
+	body, err := xml.Marshal(sitemapURLSet{XMLNS: sitemapXMLNS, XMLNSXHTML: sitemapXHTMLXMLNS, URLs: urls})
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	for _, fragment := range []string{
+		`xmlns:xhtml="http://www.w3.org/1999/xhtml"`,
+		`<xhtml:link rel="alternate" hreflang="de" href="https://example.com/de/uber-uns"></xhtml:link>`,
+		`<xhtml:link rel="alternate" hreflang="x-default" href="https://example.com/about"></xhtml:link>`,
+	} {
+		if !strings.Contains(string(body), fragment) {
+			t.Fatalf("expected %q in %s", fragment, body)
+		}
+	}
+	if len(urls[0].Alternates) != 3 || len(urls[2].Alternates) != 0 {
+		t.Fatalf("unexpected alternates: %+v", urls)
+	}
+}
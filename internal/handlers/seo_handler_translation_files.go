@@ -0,0 +1,655 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/service"
+	"constructor-script-backend/internal/theme"
+	"constructor-script-backend/pkg/lang"
+	"constructor-script-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// What a translation export covers: posts and pages, UI strings, or both.
+const (
+	translationScopeAll     = "all"
+	translationScopeContent = "content"
+	translationScopeStrings = "strings"
+)
+
+// maxTranslationFileSize caps uploaded translation files.
+const maxTranslationFileSize = 32 << 20
+
+var (
+	translationPostFields = []string{"title", "description", "excerpt", "content"}
+	translationPageFields = []string{"title", "description", "content"}
+)
+
+// translationCatalog is every translatable string of the site for one target
+// language, with what is needed to write imported translations back.
+type translationCatalog struct {
+	target  string
+	units   []service.TranslationUnit
+	current map[string]service.TranslationTarget
+
+	posts       map[uint]*models.Post
+	postTargets map[uint]*models.Post
+	pages       map[uint]*models.Page
+	pageTargets map[uint]*models.Page
+	menuItems   map[uint]models.MenuItem
+	site        models.SiteSettings
+	theme       *theme.Theme
+}
+
+func (c *translationCatalog) add(id, note, source, target string, updatedAt time.Time) {
+	if strings.TrimSpace(source) == "" {
+		return
+	}
+	c.units = append(c.units, service.TranslationUnit{ID: id, Source: source, Target: target, Note: note})
+	c.current[id] = service.TranslationTarget{Source: source, Target: target, UpdatedAt: updatedAt}
+}
+
+// SetMenuService sets the menu service whose titles are exported for translation.
+func (h *SEOHandler) SetMenuService(menuService *service.MenuService) {
+	if h == nil {
+		return
+	}
+	h.menuService = menuService
+}
+
+// SetThemeManager sets the theme manager whose UI strings are exported for
+// translation.
+func (h *SEOHandler) SetThemeManager(manager *theme.Manager) {
+	if h == nil {
+		return
+	}
+	h.themeManager = manager
+}
+
+// ExportTranslations downloads the default-language content and UI strings as an
+// XLIFF 1.2 or gettext PO file for translating into ?target. Existing
+// translations are filled in, so agencies only work on what is missing or
+// outdated. ?scope=content or ?scope=strings narrows the export.
+// GET /api/v1/admin/translations/export
+func (h *SEOHandler) ExportTranslations(c *gin.Context) {
+	if h.languageService == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Language plugin is not active"})
+		return
+	}
+
+	format := strings.ToLower(strings.TrimSpace(c.DefaultQuery("format", service.TranslationFormatXLIFF)))
+	if format != service.TranslationFormatXLIFF && format != service.TranslationFormatPO {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be xliff or po"})
+		return
+	}
+	scope := strings.ToLower(strings.TrimSpace(c.DefaultQuery("scope", translationScopeAll)))
+	if scope != translationScopeAll && scope != translationScopeContent && scope != translationScopeStrings {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "scope must be all, content or strings"})
+		return
+	}
+
+	defaultLanguage, target, status, err := h.translationLanguages(c.Query("target"))
+	if err != nil {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	catalog, err := h.translationCatalog(defaultLanguage, target, scope)
+	if err != nil {
+		logger.Error(err, "Failed to collect translatable content", map[string]interface{}{"target": target})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to collect translatable content"})
+		return
+	}
+
+	data, err := service.EncodeTranslationFile(format, service.TranslationFile{
+		SourceLanguage: defaultLanguage,
+		TargetLanguage: target,
+		ExportedAt:     time.Now().UTC(),
+		Units:          catalog.units,
+	})
+	if err != nil {
+		logger.Error(err, "Failed to encode translation file", nil)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export translations"})
+		return
+	}
+
+	extension, contentType := ".xlf", "application/x-xliff+xml"
+	if format == service.TranslationFormatPO {
+		extension, contentType = ".po", "text/x-gettext-translation"
+	}
+	filename := fmt.Sprintf("translations-%s-%s%s", defaultLanguage, target, extension)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, contentType+"; charset=utf-8", data)
+}
+
+// ImportTranslations applies a completed XLIFF or PO file uploaded as "file".
+// Units whose source text changed since the export, or whose translation was
+// edited on the site after it, are reported as conflicts and left alone unless
+// the form sends force=true. Missing post and page translations are created as
+// drafts.
+// POST /api/v1/admin/translations/import
+func (h *SEOHandler) ImportTranslations(c *gin.Context) {
+	if h.languageService == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Language plugin is not active"})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Translation file is required"})
+		return
+	}
+	if fileHeader.Size > maxTranslationFileSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Translation file is too large"})
+		return
+	}
+	uploaded, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to open uploaded file"})
+		return
+	}
+	defer uploaded.Close()
+
+	data, err := io.ReadAll(io.LimitReader(uploaded, maxTranslationFileSize))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read uploaded file"})
+		return
+	}
+
+	format := strings.ToLower(strings.TrimSpace(c.PostForm("format")))
+	if format == "" {
+		format = service.DetectTranslationFormat(fileHeader.Filename, data)
+	}
+	file, err := service.DecodeTranslationFile(format, data)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	requested := strings.TrimSpace(c.PostForm("target"))
+	if requested == "" {
+		requested = file.TargetLanguage
+	} else if file.TargetLanguage != "" && lang.Match(requested, []string{file.TargetLanguage}) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("file translates into %s, not %s", file.TargetLanguage, requested)})
+		return
+	}
+	defaultLanguage, target, status, err := h.translationLanguages(requested)
+	if err != nil {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	catalog, err := h.translationCatalog(defaultLanguage, target, translationScopeAll)
+	if err != nil {
+		logger.Error(err, "Failed to collect translatable content", map[string]interface{}{"target": target})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to collect translatable content"})
+		return
+	}
+
+	force, _ := strconv.ParseBool(c.PostForm("force"))
+	plan := service.PlanTranslationImport(*file, catalog.current, force)
+	applied, created, failures := h.applyTranslations(catalog, plan.Apply, c.GetUint("user_id"))
+
+	c.JSON(http.StatusOK, gin.H{
+		"target_language": target,
+		"applied":         applied,
+		"created":         created,
+		"conflicts":       plan.Conflicts,
+		"unchanged":       plan.Unchanged,
+		"untranslated":    plan.Untranslated,
+		"unknown":         plan.Unknown,
+		"errors":          failures,
+	})
+}
+
+// translationLanguages resolves the default language and validates target.
+func (h *SEOHandler) translationLanguages(requested string) (string, string, int, error) {
+	defaultLanguage, supported, err := h.languageService.Resolve("", nil)
+	if err != nil {
+		logger.Error(err, "Failed to resolve site languages", nil)
+	}
+
+	target, err := lang.Normalize(requested)
+	if err != nil {
+		return "", "", http.StatusBadRequest, errors.New("target must be a valid language code")
+	}
+	if target == defaultLanguage {
+		return "", "", http.StatusBadRequest, errors.New("target must differ from the default language")
+	}
+	if len(supported) > 0 && lang.Match(target, supported) == "" {
+		return "", "", http.StatusBadRequest, fmt.Errorf("%s is not a supported language", target)
+	}
+	return defaultLanguage, target, http.StatusOK, nil
+}
+
+// translationCatalog collects the translatable strings of the site in scope.
+func (h *SEOHandler) translationCatalog(defaultLanguage, target, scope string) (*translationCatalog, error) {
+	catalog := &translationCatalog{
+		target:      target,
+		current:     make(map[string]service.TranslationTarget),
+		posts:       make(map[uint]*models.Post),
+		postTargets: make(map[uint]*models.Post),
+		pages:       make(map[uint]*models.Page),
+		pageTargets: make(map[uint]*models.Page),
+		menuItems:   make(map[uint]models.MenuItem),
+	}
+	isSource := func(language string) bool {
+		return language == "" || language == defaultLanguage
+	}
+
+	if scope != translationScopeStrings {
+		if err := h.collectPostTranslations(catalog, isSource); err != nil {
+			return nil, err
+		}
+		if err := h.collectPageTranslations(catalog, isSource); err != nil {
+			return nil, err
+		}
+	}
+	if scope == translationScopeContent {
+		return catalog, nil
+	}
+
+	if h.menuService != nil {
+		items, err := h.menuService.List()
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			if !isSource(item.Language) {
+				continue
+			}
+			catalog.menuItems[item.ID] = item
+			catalog.add(fmt.Sprintf("menu:%d:title", item.ID), "Menu item", item.Title, item.Translations[target], item.UpdatedAt)
+		}
+	}
+
+	site, err := ResolveSiteSettings(h.config, h.setupService, h.languageService)
+	if err != nil {
+		return nil, err
+	}
+	catalog.site = site
+	translated := site.Translations[target]
+	catalog.add("site:name", "Site name", site.Name, translated.Name, time.Time{})
+	catalog.add("site:description", "Site description", site.Description, translated.Description, time.Time{})
+	catalog.add("site:footer_text", "Footer text", site.FooterText, translated.FooterText, time.Time{})
+
+	if h.themeManager != nil {
+		if active := h.themeManager.Active(); active != nil {
+			catalog.theme = active
+			source := active.Strings(defaultLanguage, defaultLanguage)
+			existing := active.LocaleStrings(target)
+			keys := make([]string, 0, len(source))
+			for key := range source {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				catalog.add("theme:"+key, "Theme string "+key, source[key], existing[key], time.Time{})
+			}
+		}
+	}
+
+	return catalog, nil
+}
+
+func (h *SEOHandler) collectPostTranslations(catalog *translationCatalog, isSource func(string) bool) error {
+	if h.postService == nil {
+		return nil
+	}
+	summaries, err := h.postService.ListTranslationSummaries()
+	if err != nil {
+		return err
+	}
+
+	targets := make(map[string]uint)
+	for _, summary := range summaries {
+		if summary.TranslationKey != "" && summary.Language == catalog.target {
+			targets[summary.TranslationKey] = summary.ID
+		}
+	}
+
+	for _, summary := range summaries {
+		if !isSource(summary.Language) {
+			continue
+		}
+		post, err := h.postService.GetByID(summary.ID)
+		if err != nil {
+			return err
+		}
+		catalog.posts[post.ID] = post
+
+		translation := &models.Post{}
+		if id, ok := targets[post.TranslationKey]; ok && post.TranslationKey != "" {
+			if translation, err = h.postService.GetByID(id); err != nil {
+				return err
+			}
+			catalog.postTargets[post.ID] = translation
+		}
+
+		source := postTranslationFields(post)
+		existing := postTranslationFields(translation)
+		for _, field := range translationPostFields {
+			id := fmt.Sprintf("post:%d:%s", post.ID, field)
+			catalog.add(id, fmt.Sprintf("Post %q, %s", post.Title, field), source[field], existing[field], translation.UpdatedAt)
+		}
+	}
+	return nil
+}
+
+func (h *SEOHandler) collectPageTranslations(catalog *translationCatalog, isSource func(string) bool) error {
+	if h.pageService == nil {
+		return nil
+	}
+	pages, err := h.pageService.GetAllAdmin()
+	if err != nil {
+		return err
+	}
+
+	targets := make(map[string]int)
+	for i := range pages {
+		if pages[i].TranslationKey != "" && pages[i].Language == catalog.target {
+			targets[pages[i].TranslationKey] = i
+		}
+	}
+
+	for i := range pages {
+		page := &pages[i]
+		if !isSource(page.Language) {
+			continue
+		}
+		catalog.pages[page.ID] = page
+
+		translation := &models.Page{}
+		if index, ok := targets[page.TranslationKey]; ok && page.TranslationKey != "" {
+			translation = &pages[index]
+			catalog.pageTargets[page.ID] = translation
+		}
+
+		source := pageTranslationFields(page)
+		existing := pageTranslationFields(translation)
+		for _, field := range translationPageFields {
+			id := fmt.Sprintf("page:%d:%s", page.ID, field)
+			catalog.add(id, fmt.Sprintf("Page %q, %s", page.Title, field), source[field], existing[field], translation.UpdatedAt)
+		}
+	}
+	return nil
+}
+
+func postTranslationFields(post *models.Post) map[string]string {
+	return map[string]string{
+		"title":       post.Title,
+		"description": post.Description,
+		"excerpt":     post.Excerpt,
+		"content":     post.Content,
+	}
+}
+
+func pageTranslationFields(page *models.Page) map[string]string {
+	return map[string]string{
+		"title":       page.Title,
+		"description": page.Description,
+		"content":     page.Content,
+	}
+}
+
+type translationImportError struct {
+	ID    string `json:"id"`
+	Error string `json:"error"`
+}
+
+// applyTranslations writes units back to the content they came from. It returns
+// the number of units applied, the drafts created and the units that failed.
+func (h *SEOHandler) applyTranslations(catalog *translationCatalog, units []service.TranslationUnit, userID uint) (int, []service.TranslationItem, []translationImportError) {
+	posts := make(map[uint]map[string]string)
+	pages := make(map[uint]map[string]string)
+	menus := make(map[uint]string)
+	site := make(map[string]string)
+	themeStrings := make(theme.Strings)
+	unitIDs := make(map[string][]string)
+
+	for _, unit := range units {
+		kind, rest, _ := strings.Cut(unit.ID, ":")
+		switch kind {
+		case "post", "page":
+			idPart, field, _ := strings.Cut(rest, ":")
+			id, err := strconv.ParseUint(idPart, 10, 64)
+			if err != nil {
+				continue
+			}
+			group := posts
+			if kind == "page" {
+				group = pages
+			}
+			if group[uint(id)] == nil {
+				group[uint(id)] = make(map[string]string)
+			}
+			group[uint(id)][field] = unit.Target
+			groupKey := kind + ":" + idPart
+			unitIDs[groupKey] = append(unitIDs[groupKey], unit.ID)
+		case "menu":
+			idPart, _, _ := strings.Cut(rest, ":")
+			if id, err := strconv.ParseUint(idPart, 10, 64); err == nil {
+				menus[uint(id)] = unit.Target
+				unitIDs["menu:"+idPart] = []string{unit.ID}
+			}
+		case "site":
+			site[rest] = unit.Target
+			unitIDs["site"] = append(unitIDs["site"], unit.ID)
+		case "theme":
+			themeStrings[rest] = unit.Target
+			unitIDs["theme"] = append(unitIDs["theme"], unit.ID)
+		}
+	}
+
+	applied := 0
+	created := []service.TranslationItem{}
+	failures := []translationImportError{}
+	record := func(groupKey string, err error) {
+		if err == nil {
+			applied += len(unitIDs[groupKey])
+			return
+		}
+		for _, id := range unitIDs[groupKey] {
+			failures = append(failures, translationImportError{ID: id, Error: err.Error()})
+		}
+	}
+
+	for _, id := range sortedTranslationIDs(posts) {
+		item, err := h.applyPostTranslation(catalog, id, posts[id], userID)
+		if item != nil {
+			created = append(created, *item)
+		}
+		record(fmt.Sprintf("post:%d", id), err)
+	}
+	for _, id := range sortedTranslationIDs(pages) {
+		item, err := h.applyPageTranslation(catalog, id, pages[id])
+		if item != nil {
+			created = append(created, *item)
+		}
+		record(fmt.Sprintf("page:%d", id), err)
+	}
+
+	for id, title := range menus {
+		item := catalog.menuItems[id]
+		translations := models.LocalizedText{}
+		for code, value := range item.Translations {
+			translations[code] = value
+		}
+		translations[catalog.target] = title
+		_, err := h.menuService.Update(id, models.UpdateMenuItemRequest{
+			Title:        item.Title,
+			URL:          item.URL,
+			Translations: &translations,
+		})
+		record(fmt.Sprintf("menu:%d", id), err)
+	}
+
+	if len(site) > 0 {
+		translations := models.SiteTranslations{}
+		for code, value := range catalog.site.Translations {
+			translations[code] = value
+		}
+		translation := translations[catalog.target]
+		for field, value := range site {
+			switch field {
+			case "name":
+				translation.Name = value
+			case "description":
+				translation.Description = value
+			case "footer_text":
+				translation.FooterText = value
+			}
+		}
+		translations[catalog.target] = translation
+		record("site", h.setupService.UpdateSiteTranslations(translations))
+	}
+
+	if len(themeStrings) > 0 {
+		record("theme", catalog.theme.SaveStrings(catalog.target, themeStrings))
+	}
+
+	return applied, created, failures
+}
+
+// applyPostTranslation updates the translation of post sourceID, or creates it
+// as a draft when there is none yet.
+func (h *SEOHandler) applyPostTranslation(catalog *translationCatalog, sourceID uint, fields map[string]string, userID uint) (*service.TranslationItem, error) {
+	source := catalog.posts[sourceID]
+	if translation := catalog.postTargets[sourceID]; translation != nil {
+		req := models.UpdatePostRequest{}
+		for field, value := range fields {
+			value := value
+			switch field {
+			case "title":
+				req.Title = &value
+			case "description":
+				req.Description = &value
+			case "excerpt":
+				req.Excerpt = &value
+			case "content":
+				req.Content = &value
+			}
+		}
+		_, err := h.postService.Update(translation.ID, req, userID, true)
+		return nil, err
+	}
+
+	if strings.TrimSpace(fields["title"]) == "" {
+		return nil, errors.New("a translated title is needed to create the translation")
+	}
+	key, err := h.ensurePostTranslationKey(source, userID)
+	if err != nil {
+		return nil, err
+	}
+	post, err := h.postService.Create(models.CreatePostRequest{
+		Title:          fields["title"],
+		Description:    fields["description"],
+		Excerpt:        fields["excerpt"],
+		Content:        fields["content"],
+		CategoryID:     source.CategoryID,
+		FeaturedImg:    source.FeaturedImg,
+		Template:       source.Template,
+		Language:       catalog.target,
+		TranslationKey: key,
+	}, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &service.TranslationItem{
+		Type:           service.TranslationContentPost,
+		ID:             post.ID,
+		Title:          post.Title,
+		Language:       post.Language,
+		TranslationKey: post.TranslationKey,
+		UpdatedAt:      post.UpdatedAt,
+	}, nil
+}
+
+// applyPageTranslation updates the translation of page sourceID, or creates it
+// as a draft when there is none yet.
+func (h *SEOHandler) applyPageTranslation(catalog *translationCatalog, sourceID uint, fields map[string]string) (*service.TranslationItem, error) {
+	source := catalog.pages[sourceID]
+	if translation := catalog.pageTargets[sourceID]; translation != nil {
+		req := models.UpdatePageRequest{}
+		for field, value := range fields {
+			value := value
+			switch field {
+			case "title":
+				req.Title = &value
+			case "description":
+				req.Description = &value
+			case "content":
+				req.Content = &value
+			}
+		}
+		_, err := h.pageService.Update(translation.ID, req)
+		return nil, err
+	}
+
+	if strings.TrimSpace(fields["title"]) == "" {
+		return nil, errors.New("a translated title is needed to create the translation")
+	}
+	key := source.TranslationKey
+	if key == "" {
+		key = source.Slug
+		if _, err := h.pageService.Update(source.ID, models.UpdatePageRequest{TranslationKey: &key}); err != nil {
+			return nil, err
+		}
+	}
+	page, err := h.pageService.Create(models.CreatePageRequest{
+		Title:          fields["title"],
+		Description:    fields["description"],
+		Content:        fields["content"],
+		FeaturedImg:    source.FeaturedImg,
+		Template:       source.Template,
+		Theme:          source.Theme,
+		Layout:         source.Layout,
+		HideHeader:     source.HideHeader,
+		Language:       catalog.target,
+		TranslationKey: key,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &service.TranslationItem{
+		Type:           service.TranslationContentPage,
+		ID:             page.ID,
+		Title:          page.Title,
+		Path:           page.Path,
+		Language:       page.Language,
+		TranslationKey: page.TranslationKey,
+		UpdatedAt:      page.UpdatedAt,
+	}, nil
+}
+
+// ensurePostTranslationKey gives post a translation key, its slug, so a new
+// translation can be linked to it.
+func (h *SEOHandler) ensurePostTranslationKey(post *models.Post, userID uint) (string, error) {
+	if post.TranslationKey != "" {
+		return post.TranslationKey, nil
+	}
+	key := post.Slug
+	if _, err := h.postService.Update(post.ID, models.UpdatePostRequest{TranslationKey: &key}, userID, true); err != nil {
+		return "", err
+	}
+	post.TranslationKey = key
+	return key, nil
+}
+
+func sortedTranslationIDs(groups map[uint]map[string]string) []uint {
+	ids := make([]uint, 0, len(groups))
+	for id := range groups {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/service"
+	"constructor-script-backend/pkg/apierror"
+	"constructor-script-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SettingsExportHandler struct {
+	service *service.SettingsExportService
+	setup   *SetupHandler
+}
+
+// NewSettingsExportHandler serves settings documents; setup supplies the site
+// settings defaults from the configuration.
+func NewSettingsExportHandler(service *service.SettingsExportService, setup *SetupHandler) *SettingsExportHandler {
+	return &SettingsExportHandler{service: service, setup: setup}
+}
+
+func (h *SettingsExportHandler) siteDefaults() models.SiteSettings {
+	if h.setup == nil {
+		return models.SiteSettings{}
+	}
+	return h.setup.defaultSiteSettings()
+}
+
+// Export returns the settings document itself, unwrapped, so that it can be
+// saved and sent back to Import as is.
+func (h *SettingsExportHandler) Export(c *gin.Context) {
+	if h == nil || h.service == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Settings export service not available"})
+		return
+	}
+
+	doc, err := h.service.Export(h.siteDefaults())
+	if err != nil {
+		logger.ErrorContext(c.Request.Context(), err, "Failed to export settings", nil)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, doc)
+}
+
+func (h *SettingsExportHandler) Import(c *gin.Context) {
+	if h == nil || h.service == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Settings export service not available"})
+		return
+	}
+
+	var doc models.SettingsDocument
+	if err := c.ShouldBindJSON(&doc); err != nil {
+		apierror.BindError(c, err)
+		return
+	}
+
+	result, err := h.service.Import(doc, h.siteDefaults())
+	if err != nil {
+		var importErr *service.SettingsImportError
+		if errors.As(err, &importErr) && importErr.Invalid {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "section": importErr.Section, "imported": result.Imported})
+			return
+		}
+		logger.ErrorContext(c.Request.Context(), err, "Failed to import settings", nil)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import settings", "imported": result.Imported})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
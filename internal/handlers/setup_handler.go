@@ -9,6 +9,7 @@ import (
 	"constructor-script-backend/internal/config"
 	"constructor-script-backend/internal/models"
 	"constructor-script-backend/internal/service"
+	"constructor-script-backend/pkg/apierror"
 	"constructor-script-backend/pkg/lang"
 	"constructor-script-backend/pkg/logger"
 	blogservice "constructor-script-backend/plugins/blog/service"
@@ -110,7 +111,7 @@ func (h *SetupHandler) SaveStep(c *gin.Context) {
 
 	var req models.SetupStepRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		h.handleValidationError(c, err)
+		apierror.BindError(c, err)
 		return
 	}
 
@@ -179,7 +180,7 @@ func (h *SetupHandler) Complete(c *gin.Context) {
 
 	var req models.SetupRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		h.handleValidationError(c, err)
+		apierror.BindError(c, err)
 		return
 	}
 
@@ -401,7 +402,7 @@ func (h *SetupHandler) UpdateSiteSettings(c *gin.Context) {
 
 	var req models.UpdateSiteSettingsRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.BindError(c, err)
 		return
 	}
 
@@ -458,7 +459,7 @@ func (h *SetupHandler) UpdateEmailSettings(c *gin.Context) {
 
 	var req models.UpdateEmailSettingsRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.BindError(c, err)
 		return
 	}
 
@@ -512,7 +513,7 @@ func (h *SetupHandler) TestEmailSettings(c *gin.Context) {
 
 	var req models.UpdateEmailSettingsRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.BindError(c, err)
 		return
 	}
 
@@ -701,20 +702,3 @@ func (h *SetupHandler) validateSetupRequest(req *models.SetupRequest) error {
 
 	return nil
 }
-
-// handleValidationError processes validation errors and returns user-friendly messages
-func (h *SetupHandler) handleValidationError(c *gin.Context, err error) {
-	if err == nil {
-		return
-	}
-
-	// Extract field-specific errors from gin binding errors
-	message := "Validation failed"
-	if err.Error() != "" {
-		message = err.Error()
-	}
-
-	c.JSON(http.StatusBadRequest, gin.H{
-		"error": message,
-	})
-}
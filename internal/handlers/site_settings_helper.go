@@ -44,6 +44,7 @@ func ResolveSiteSettings(cfg *config.Config, setupService *service.SetupService,
 		CourseCheckoutSuccessURL: cfg.CourseCheckoutSuccessURL,
 		CourseCheckoutCancelURL:  cfg.CourseCheckoutCancelURL,
 		CourseCheckoutCurrency:   strings.ToLower(strings.TrimSpace(cfg.CourseCheckoutCurrency)),
+		AllowColorSchemeToggle:   true,
 		Subtitles: models.SubtitleSettings{
 			Enabled:       cfg.SubtitleGenerationEnabled,
 			Provider:      strings.TrimSpace(cfg.SubtitleProvider),
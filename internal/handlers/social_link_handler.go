@@ -7,6 +7,7 @@ import (
 
 	"constructor-script-backend/internal/models"
 	"constructor-script-backend/internal/service"
+	"constructor-script-backend/pkg/apierror"
 	"constructor-script-backend/pkg/logger"
 
 	"github.com/gin-gonic/gin"
@@ -45,7 +46,7 @@ func (h *SocialLinkHandler) Create(c *gin.Context) {
 
 	var req models.CreateSocialLinkRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.BindError(c, err)
 		return
 	}
 
@@ -74,7 +75,7 @@ func (h *SocialLinkHandler) Update(c *gin.Context) {
 
 	var req models.UpdateSocialLinkRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.BindError(c, err)
 		return
 	}
 
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/service"
+	"constructor-script-backend/pkg/apierror"
+	"constructor-script-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type SocialShareHandler struct {
+	service *service.SocialShareService
+}
+
+func NewSocialShareHandler(service *service.SocialShareService) *SocialShareHandler {
+	return &SocialShareHandler{service: service}
+}
+
+func socialShareErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrSocialShareRepositoryUnavailable):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, service.ErrInvalidSocialAccount):
+		return http.StatusBadRequest
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func (h *SocialShareHandler) List(c *gin.Context) {
+	if h == nil || h.service == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Social sharing not available"})
+		return
+	}
+
+	accounts, err := h.service.ListAccounts()
+	if err != nil {
+		logger.ErrorContext(c.Request.Context(), err, "Failed to load social accounts", nil)
+		c.JSON(socialShareErrorStatus(err), gin.H{"error": "Failed to load social accounts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"accounts": accounts, "networks": h.service.Networks()})
+}
+
+func (h *SocialShareHandler) Create(c *gin.Context) {
+	if h == nil || h.service == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Social sharing not available"})
+		return
+	}
+
+	var req models.CreateSocialAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BindError(c, err)
+		return
+	}
+
+	account, err := h.service.CreateAccount(req)
+	if err != nil {
+		logger.ErrorContext(c.Request.Context(), err, "Failed to create social account", nil)
+		c.JSON(socialShareErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"account": account})
+}
+
+func (h *SocialShareHandler) Update(c *gin.Context) {
+	if h == nil || h.service == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Social sharing not available"})
+		return
+	}
+
+	id, ok := parseWebhookID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.UpdateSocialAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BindError(c, err)
+		return
+	}
+
+	account, err := h.service.UpdateAccount(id, req)
+	if err != nil {
+		logger.ErrorContext(c.Request.Context(), err, "Failed to update social account", map[string]interface{}{"id": id})
+		c.JSON(socialShareErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"account": account})
+}
+
+func (h *SocialShareHandler) Delete(c *gin.Context) {
+	if h == nil || h.service == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Social sharing not available"})
+		return
+	}
+
+	id, ok := parseWebhookID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteAccount(id); err != nil {
+		logger.ErrorContext(c.Request.Context(), err, "Failed to delete social account", map[string]interface{}{"id": id})
+		c.JSON(socialShareErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Social account deleted"})
+}
+
+// Shares returns the send log. The optional account_id query parameter limits it
+// to one account.
+func (h *SocialShareHandler) Shares(c *gin.Context) {
+	if h == nil || h.service == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Social sharing not available"})
+		return
+	}
+
+	var accountID uint
+	if raw := c.Query("account_id"); raw != "" {
+		value, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid account_id"})
+			return
+		}
+		accountID = uint(value)
+	}
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	shares, err := h.service.Shares(accountID, limit)
+	if err != nil {
+		logger.ErrorContext(c.Request.Context(), err, "Failed to load social shares", nil)
+		c.JSON(socialShareErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"shares": shares})
+}
+
+// Share queues a post for an account by hand, e.g. to retry a failed share.
+func (h *SocialShareHandler) Share(c *gin.Context) {
+	if h == nil || h.service == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Social sharing not available"})
+		return
+	}
+
+	id, ok := parseWebhookID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req struct {
+		PostID uint `json:"post_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BindError(c, err)
+		return
+	}
+
+	share, err := h.service.SharePost(id, req.PostID)
+	if err != nil {
+		logger.ErrorContext(c.Request.Context(), err, "Failed to share post", map[string]interface{}{"id": id, "post_id": req.PostID})
+		c.JSON(socialShareErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"share": share})
+}
@@ -1,171 +1,37 @@
 package handlers
 
 import (
-	"constructor-script-backend/internal/models"
 	"net/http"
-	"time"
+	"strconv"
+
+	"constructor-script-backend/internal/service"
+	"constructor-script-backend/pkg/logger"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
-func GetStatistics(db *gorm.DB) gin.HandlerFunc {
+// GetStatistics returns the admin dashboard figures. days sets the length of the
+// activity trend, from 1 to 90 days, and defaults to 30.
+func GetStatistics(stats *service.StatsService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		now := time.Now().UTC()
-
-		var stats struct {
-			TotalPosts          int64 `json:"total_posts"`
-			PublishedPosts      int64 `json:"published_posts"`
-			TotalUsers          int64 `json:"total_users"`
-			TotalCategories     int64 `json:"total_categories"`
-			TotalComments       int64 `json:"total_comments"`
-			TotalTags           int64 `json:"total_tags"`
-			TotalViews          int64 `json:"total_views"`
-			PostsLast24Hours    int64 `json:"posts_last_24_hours"`
-			PostsLast7Days      int64 `json:"posts_last_7_days"`
-			CommentsLast24Hours int64 `json:"comments_last_24_hours"`
-			CommentsLast7Days   int64 `json:"comments_last_7_days"`
-			UsersLast7Days      int64 `json:"users_last_7_days"`
-		}
-
-		db.Model(&models.Post{}).Count(&stats.TotalPosts)
-		db.Model(&models.Post{}).
-			Where("published = ? AND (publish_at IS NULL OR publish_at <= ?)", true, now).
-			Count(&stats.PublishedPosts)
-		db.Model(&models.User{}).Count(&stats.TotalUsers)
-		db.Model(&models.Category{}).Count(&stats.TotalCategories)
-		db.Model(&models.Comment{}).Count(&stats.TotalComments)
-		db.Model(&models.Tag{}).Count(&stats.TotalTags)
-		db.Model(&models.Post{}).Select("COALESCE(SUM(views), 0)").Row().Scan(&stats.TotalViews)
-
-		twentyFourHoursAgo := now.Add(-24 * time.Hour)
-		sevenDaysAgo := now.AddDate(0, 0, -7)
-
-		db.Model(&models.Post{}).
-			Where("COALESCE(published_at, publish_at, created_at) >= ?", twentyFourHoursAgo).
-			Count(&stats.PostsLast24Hours)
-		db.Model(&models.Post{}).
-			Where("COALESCE(published_at, publish_at, created_at) >= ?", sevenDaysAgo).
-			Count(&stats.PostsLast7Days)
-		db.Model(&models.Comment{}).
-			Where("created_at >= ?", twentyFourHoursAgo).
-			Count(&stats.CommentsLast24Hours)
-		db.Model(&models.Comment{}).
-			Where("created_at >= ?", sevenDaysAgo).
-			Count(&stats.CommentsLast7Days)
-		db.Model(&models.User{}).
-			Where("created_at >= ?", sevenDaysAgo).
-			Count(&stats.UsersLast7Days)
-
-			// Additional statistics
-		var popularPosts []struct {
-			ID    uint   `json:"id"`
-			Title string `json:"title"`
-			Views int    `json:"views"`
-		}
-
-		db.Model(&models.Post{}).
-			Select("id, title, views").
-			Where("published = ? AND (publish_at IS NULL OR publish_at <= ?)", true, now).
-			Order("views DESC").
-			Limit(5).
-			Scan(&popularPosts)
-
-		type timeBucket struct {
-			Period time.Time
-			Count  int64
-		}
-
-		startOfToday := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
-		windowStart := startOfToday.AddDate(0, 0, -29)
-
-		var postBuckets []timeBucket
-		db.Model(&models.Post{}).
-			Select("DATE_TRUNC('day', COALESCE(published_at, publish_at, created_at)) AS period, COUNT(*) AS count").
-			Where("COALESCE(published_at, publish_at, created_at) >= ?", windowStart).
-			Group("period").
-			Order("period").
-			Scan(&postBuckets)
-
-		var commentBuckets []timeBucket
-		db.Model(&models.Comment{}).
-			Select("DATE_TRUNC('day', created_at) AS period, COUNT(*) AS count").
-			Where("created_at >= ?", windowStart).
-			Group("period").
-			Order("period").
-			Scan(&commentBuckets)
-
-		var viewBuckets []timeBucket
-		db.Model(&models.Post{}).
-			Select("DATE_TRUNC('day', COALESCE(published_at, publish_at, created_at)) AS period, COALESCE(SUM(views), 0) AS count").
-			Where("COALESCE(published_at, publish_at, created_at) >= ?", windowStart).
-			Group("period").
-			Order("period").
-			Scan(&viewBuckets)
-
-		var userBuckets []timeBucket
-		db.Model(&models.User{}).
-			Select("DATE_TRUNC('day', created_at) AS period, COUNT(*) AS count").
-			Where("created_at >= ?", windowStart).
-			Group("period").
-			Order("period").
-			Scan(&userBuckets)
-
-		postCounts := make(map[string]int64, len(postBuckets))
-		for _, bucket := range postBuckets {
-			key := bucket.Period.UTC().Format("2006-01-02")
-			postCounts[key] = bucket.Count
-		}
-
-		commentCounts := make(map[string]int64, len(commentBuckets))
-		for _, bucket := range commentBuckets {
-			key := bucket.Period.UTC().Format("2006-01-02")
-			commentCounts[key] = bucket.Count
-		}
-
-		viewCounts := make(map[string]int64, len(viewBuckets))
-		for _, bucket := range viewBuckets {
-			key := bucket.Period.UTC().Format("2006-01-02")
-			viewCounts[key] = bucket.Count
+		if stats == nil {
+			c.JSON(http.StatusNotImplemented, gin.H{"error": "Stats service not available"})
+			return
 		}
 
-		userCounts := make(map[string]int64, len(userBuckets))
-		for _, bucket := range userBuckets {
-			key := bucket.Period.UTC().Format("2006-01-02")
-			userCounts[key] = bucket.Count
+		days, err := strconv.Atoi(c.DefaultQuery("days", "30"))
+		if err != nil || days < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "days must be a positive number"})
+			return
 		}
 
-		activityTrend := make([]gin.H, 0, 30)
-		for day := 0; day < 30; day++ {
-			point := windowStart.AddDate(0, 0, day)
-			key := point.Format("2006-01-02")
-			activityTrend = append(activityTrend, gin.H{
-				"period":   point.Format(time.RFC3339),
-				"posts":    postCounts[key],
-				"comments": commentCounts[key],
-				"views":    viewCounts[key],
-				"users":    userCounts[key],
-			})
+		dashboard, err := stats.Dashboard(days)
+		if err != nil {
+			logger.ErrorContext(c.Request.Context(), err, "Failed to load dashboard statistics", nil)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load statistics"})
+			return
 		}
 
-		var recentUsers []struct {
-			ID       uint   `json:"id"`
-			Username string `json:"username"`
-			Email    string `json:"email"`
-			Role     string `json:"role"`
-		}
-
-		db.Model(&models.User{}).
-			Select("id, username, email, role").
-			Order("created_at DESC").
-			Limit(5).
-			Scan(&recentUsers)
-
-		c.JSON(http.StatusOK, gin.H{
-			"statistics":     stats,
-			"popular_posts":  popularPosts,
-			"recent_users":   recentUsers,
-			"activity_trend": activityTrend,
-		})
+		c.JSON(http.StatusOK, dashboard)
 	}
 }
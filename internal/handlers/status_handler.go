@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/service"
+	"constructor-script-backend/pkg/apierror"
+	"constructor-script-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type StatusHandler struct {
+	service *service.StatusService
+}
+
+func NewStatusHandler(statusService *service.StatusService) *StatusHandler {
+	return &StatusHandler{service: statusService}
+}
+
+// Current returns the public site status: overall state, component health and the
+// active, upcoming and recently resolved incidents.
+func (h *StatusHandler) Current(c *gin.Context) {
+	if h.service == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Status service not available"})
+		return
+	}
+
+	status, err := h.service.Current(c.Request.Context())
+	if err != nil {
+		logger.ErrorContext(c.Request.Context(), err, "Failed to load site status", nil)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load site status"})
+		return
+	}
+
+	c.Header("Cache-Control", "no-cache")
+	c.JSON(http.StatusOK, status)
+}
+
+func (h *StatusHandler) ListIncidents(c *gin.Context) {
+	if h.service == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Status service not available"})
+		return
+	}
+
+	incidents, err := h.service.ListIncidents()
+	if err != nil {
+		logger.Error(err, "Failed to load status incidents", nil)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load incidents"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"incidents": incidents})
+}
+
+func (h *StatusHandler) CreateIncident(c *gin.Context) {
+	if h.service == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Status service not available"})
+		return
+	}
+
+	var req models.CreateStatusIncidentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BindError(c, err)
+		return
+	}
+
+	incident, err := h.service.CreateIncident(req)
+	if err != nil {
+		h.writeError(c, err, "Failed to create incident")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"incident": incident})
+}
+
+func (h *StatusHandler) UpdateIncident(c *gin.Context) {
+	if h.service == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Status service not available"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid incident ID"})
+		return
+	}
+
+	var req models.UpdateStatusIncidentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BindError(c, err)
+		return
+	}
+
+	incident, err := h.service.UpdateIncident(uint(id), req)
+	if err != nil {
+		h.writeError(c, err, "Failed to update incident")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"incident": incident})
+}
+
+func (h *StatusHandler) DeleteIncident(c *gin.Context) {
+	if h.service == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Status service not available"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid incident ID"})
+		return
+	}
+
+	if err := h.service.DeleteIncident(uint(id)); err != nil {
+		h.writeError(c, err, "Failed to delete incident")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Incident deleted"})
+}
+
+func (h *StatusHandler) writeError(c *gin.Context, err error, message string) {
+	var validationErr *service.StatusValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Incident not found"})
+	default:
+		logger.Error(err, message, nil)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
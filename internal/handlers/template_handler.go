@@ -9,6 +9,7 @@ import (
 	"sync"
 
 	"constructor-script-backend/internal/config"
+	"constructor-script-backend/internal/models"
 	"constructor-script-backend/internal/sections"
 	"constructor-script-backend/internal/service"
 	"constructor-script-backend/internal/theme"
@@ -17,11 +18,11 @@ import (
 	archiveservice "constructor-script-backend/plugins/archive/service"
 	blogservice "constructor-script-backend/plugins/blog/service"
 	courseservice "constructor-script-backend/plugins/courses/service"
+	eventservice "constructor-script-backend/plugins/events/service"
 	forumservice "constructor-script-backend/plugins/forum/service"
 	languageservice "constructor-script-backend/plugins/language/service"
 
 	"github.com/gin-gonic/gin"
-	"github.com/microcosm-cc/bluemonday"
 )
 
 type TemplateHandler struct {
@@ -37,6 +38,8 @@ type TemplateHandler struct {
 	socialLinkService     *service.SocialLinkService
 	menuService           *service.MenuService
 	advertisingService    *service.AdvertisingService
+	announcementService   *service.AnnouncementService
+	consentService        *service.ConsentService
 	coursePackageSvc      *courseservice.PackageService
 	courseCheckoutSvc     *courseservice.CheckoutService
 	courseMaterialProtect *courseservice.MaterialProtection
@@ -45,17 +48,31 @@ type TemplateHandler struct {
 	forumCategorySvc      *forumservice.CategoryService
 	archiveDirectorySvc   *archiveservice.DirectoryService
 	archiveFileSvc        *archiveservice.FileService
+	eventSvc              *eventservice.EventService
+	uploadService         *service.UploadService
+	imageTransform        *service.ImageTransformService
+	embedService          *service.EmbedService
+	statusService         *service.StatusService
 	fontService           *service.FontService
 	templates             *template.Template
 	templatesMu           sync.RWMutex
 	currentTheme          string
 	themeManager          *theme.Manager
 	config                *config.Config
-	sanitizer             *bluemonday.Policy
+	sanitization          *service.SanitizationService
 	sectionRegistry       interface {
 		Register(sectionType string, renderer sections.Renderer) error
 		Get(sectionType string) (sections.Renderer, bool)
 	}
+	templateOverrides TemplateOverrideProvider
+	themeSectionsMu   sync.Mutex
+	themeSectionTypes map[string]struct{}
+	themeTemplates    map[string]*template.Template
+}
+
+// TemplateOverrideProvider supplies database-stored template sources that replace theme files.
+type TemplateOverrideProvider interface {
+	TemplateOverrides(themeSlug string) (map[string]string, error)
 }
 
 func NewTemplateHandler(
@@ -82,10 +99,6 @@ func NewTemplateHandler(
 	cfg *config.Config,
 	themeManager *theme.Manager,
 ) (*TemplateHandler, error) {
-	policy := bluemonday.UGCPolicy()
-	policy.AllowAttrs("class", "id").Globally()
-	policy.AllowAttrs("style").OnElements("span", "div", "p")
-
 	handler := &TemplateHandler{
 		postService:         postService,
 		categoryService:     categoryService,
@@ -109,7 +122,6 @@ func NewTemplateHandler(
 		archiveFileSvc:      archiveFileService,
 		themeManager:        themeManager,
 		config:              cfg,
-		sanitizer:           policy,
 	}
 
 	handler.sectionRegistry = sections.DefaultRegistryWithMetadata()
@@ -230,6 +242,20 @@ func (h *TemplateHandler) SetArchiveServices(directoryService *archiveservice.Di
 	h.archiveFileSvc = fileService
 }
 
+// SetTemplateOverrides configures the source of per-template overrides and forces the
+// template set to be rebuilt on the next render.
+func (h *TemplateHandler) SetTemplateOverrides(provider TemplateOverrideProvider) {
+	if h == nil {
+		return
+	}
+
+	h.templatesMu.Lock()
+	h.templateOverrides = provider
+	h.templates = nil
+	h.themeTemplates = nil
+	h.templatesMu.Unlock()
+}
+
 func (h *TemplateHandler) blogEnabled() bool {
 	return h != nil && h.postService != nil
 }
@@ -290,21 +316,70 @@ func (h *TemplateHandler) reloadTemplates() error {
 		return errors.New("no active theme")
 	}
 
-	tmpl := template.New("").Funcs(utils.GetTemplateFuncs(h.themeManager.AssetModTime))
-	templates, err := tmpl.ParseGlob(filepath.Join(active.TemplatesDir, "*.html"))
+	templates, err := h.buildTemplateSet(active, utils.GetTemplateFuncsWithAssets(h.themeManager.AssetURL, h.themeManager.AssetModTime))
 	if err != nil {
 		return err
 	}
 
 	h.templatesMu.Lock()
 	h.templates = templates
-	h.currentTheme = active.Slug
+	h.currentTheme = templateSetKey(active)
+	h.themeTemplates = nil
 	h.templatesMu.Unlock()
 
+	h.syncThemeSections(active)
+
 	logger.Info("Loaded templates", map[string]interface{}{"theme": active.Slug})
 	return nil
 }
 
+func (h *TemplateHandler) buildTemplateSet(themeValue *theme.Theme, funcs template.FuncMap) (*template.Template, error) {
+	h.addImageFuncs(funcs)
+	h.addSanitizeFuncs(funcs)
+	templates, err := template.New("").Funcs(funcs).ParseGlob(filepath.Join(themeValue.TemplatesDir, "*.html"))
+	if err != nil {
+		return nil, err
+	}
+
+	h.parseThemeSectionTemplates(templates, themeValue)
+	return h.applyTemplateOverrides(templates, themeValue.Slug), nil
+}
+
+// applyTemplateOverrides returns templates with the stored overrides of the theme
+// applied. Each override is parsed into a clone that replaces the set only on
+// success, since a failed Parse still leaves an empty template under its name.
+func (h *TemplateHandler) applyTemplateOverrides(templates *template.Template, themeSlug string) *template.Template {
+	h.templatesMu.RLock()
+	provider := h.templateOverrides
+	h.templatesMu.RUnlock()
+
+	if provider == nil {
+		return templates
+	}
+
+	overrides, err := provider.TemplateOverrides(themeSlug)
+	if err != nil {
+		logger.Error(err, "Failed to load template overrides", map[string]interface{}{"theme": themeSlug})
+		return templates
+	}
+
+	for name, source := range overrides {
+		candidate, err := templates.Clone()
+		if err == nil {
+			_, err = candidate.New(name).Parse(source)
+		}
+		if err != nil {
+			logger.Error(err, "Failed to apply template override", map[string]interface{}{
+				"theme":    themeSlug,
+				"template": name,
+			})
+			continue
+		}
+		templates = candidate
+	}
+	return templates
+}
+
 func (h *TemplateHandler) ReloadTemplates() error {
 	if h == nil {
 		return errors.New("template handler not configured")
@@ -312,7 +387,11 @@ func (h *TemplateHandler) ReloadTemplates() error {
 	return h.reloadTemplates()
 }
 
-func (h *TemplateHandler) templateClone() (*template.Template, error) {
+// templateSet returns the compiled templates of the active theme. The set is parsed
+// once per theme version and shared by every render, which only looks up and
+// executes templates in it; html/template is safe to execute concurrently. Nothing
+// may add or parse templates in a shared set, and reloading replaces it instead.
+func (h *TemplateHandler) templateSet() (*template.Template, error) {
 	if h.themeManager == nil {
 		return nil, errors.New("theme manager not configured")
 	}
@@ -322,38 +401,84 @@ func (h *TemplateHandler) templateClone() (*template.Template, error) {
 		return nil, errors.New("no active theme")
 	}
 
-	needsReload := false
-
 	h.templatesMu.RLock()
-	if h.templates == nil || h.currentTheme != active.Slug {
-		needsReload = true
-	}
+	templates := h.templates
+	current := h.currentTheme
 	h.templatesMu.RUnlock()
 
-	if needsReload {
+	if templates == nil || current != templateSetKey(active) {
 		if err := h.reloadTemplates(); err != nil {
 			return nil, err
 		}
+		h.templatesMu.RLock()
+		templates = h.templates
+		h.templatesMu.RUnlock()
 	}
 
-	h.templatesMu.RLock()
-	defer h.templatesMu.RUnlock()
-	if h.templates == nil {
+	if templates == nil {
 		return nil, errors.New("templates not loaded")
 	}
+	return templates, nil
+}
+
+// templateSetFor returns the template set of an installed theme other than the active
+// one, used for pages that select their own theme. Assets referenced through the asset
+// helper resolve to that theme's static files.
+func (h *TemplateHandler) templateSetFor(themeSlug string) (*template.Template, error) {
+	themeSlug = strings.ToLower(strings.TrimSpace(themeSlug))
+	if themeSlug == "" || h.themeManager == nil {
+		return h.templateSet()
+	}
 
-	return h.templates.Clone()
+	active := h.themeManager.Active()
+	if active != nil && active.Slug == themeSlug {
+		return h.templateSet()
+	}
+
+	themeValue, ok := h.themeManager.Resolve(themeSlug)
+	if !ok {
+		logger.Warn("Page theme is not installed, falling back to the active theme", map[string]interface{}{"theme": themeSlug})
+		return h.templateSet()
+	}
+
+	key := templateSetKey(themeValue)
+	h.templatesMu.RLock()
+	cached := h.themeTemplates[key]
+	h.templatesMu.RUnlock()
+
+	if cached == nil {
+		templates, err := h.buildTemplateSet(themeValue, utils.GetTemplateFuncsWithAssets(themeValue.AssetURL, themeValue.AssetModTime))
+		if err != nil {
+			return nil, err
+		}
+
+		h.templatesMu.Lock()
+		if h.themeTemplates == nil {
+			h.themeTemplates = make(map[string]*template.Template)
+		}
+		h.themeTemplates[key] = templates
+		h.templatesMu.Unlock()
+		cached = templates
+	}
+
+	return cached, nil
+}
+
+// templateSetKey identifies a compiled template set, so that installing a new
+// version of a theme compiles its templates again.
+func templateSetKey(themeValue *theme.Theme) string {
+	return themeValue.Slug + "@" + themeValue.Metadata.Version
 }
 
 // SanitizeHTML makes TemplateHandler compatible with sections.RenderContext.
 func (h *TemplateHandler) SanitizeHTML(input string) string {
-	if h == nil || h.sanitizer == nil {
+	if h == nil {
 		return input
 	}
-	return h.sanitizer.Sanitize(input)
+	return h.sanitization.Sanitize(models.SanitizeSectionParagraph, input)
 }
 
-// CloneTemplates makes TemplateHandler compatible with sections.RenderContext.
-func (h *TemplateHandler) CloneTemplates() (*template.Template, error) {
-	return h.templateClone()
+// Templates makes TemplateHandler compatible with sections.RenderContext.
+func (h *TemplateHandler) Templates() (*template.Template, error) {
+	return h.templateSet()
 }
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/service"
+	"constructor-script-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetAnnouncementService enables the site-wide announcement bars.
+func (h *TemplateHandler) SetAnnouncementService(announcementService *service.AnnouncementService) {
+	if h == nil {
+		return
+	}
+	h.announcementService = announcementService
+}
+
+// liveAnnouncements returns every announcement that is currently scheduled.
+// applyAnnouncements narrows them down once the visitor is known.
+func (h *TemplateHandler) liveAnnouncements() []models.Announcement {
+	if h.announcementService == nil {
+		return nil
+	}
+
+	live, err := h.announcementService.Live()
+	if err != nil {
+		logger.Error(err, "Failed to load announcements", nil)
+		return nil
+	}
+	return live
+}
+
+// applyAnnouncements keeps the announcements meant for the current visitor that
+// they have not dismissed. It runs after addUserContext.
+func (h *TemplateHandler) applyAnnouncements(c *gin.Context, data gin.H) {
+	live, _ := data["Announcements"].([]models.Announcement)
+	if len(live) == 0 {
+		delete(data, "Announcements")
+		return
+	}
+
+	user, _ := data["CurrentUser"].(*models.User)
+	visible, err := h.announcementService.Visible(live, user)
+	if err != nil {
+		logger.ErrorContext(c.Request.Context(), err, "Failed to filter announcements", nil)
+		delete(data, "Announcements")
+		return
+	}
+	data["Announcements"] = visible
+}
@@ -94,13 +94,7 @@ func (h *TemplateHandler) sanitizeCommentContent(content string) template.HTML {
 		return ""
 	}
 
-	if h.sanitizer == nil {
-		escaped := template.HTMLEscapeString(content)
-		escaped = strings.ReplaceAll(escaped, "\n", "<br />")
-		return template.HTML(escaped)
-	}
-
-	sanitized := h.sanitizer.Sanitize(content)
+	sanitized := h.sanitization.Sanitize(models.SanitizeComments, content)
 	sanitized = strings.ReplaceAll(sanitized, "\n", "<br />")
 	return template.HTML(sanitized)
 }
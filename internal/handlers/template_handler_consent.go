@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"html/template"
+	"strings"
+
+	"constructor-script-backend/internal/constants"
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// consentTemplateData is what the consent banner and the page head need about
+// the visitor's consent.
+type consentTemplateData struct {
+	models.ConsentState
+	AnalyticsSnippets []template.HTML
+}
+
+// SetConsentService makes ads and analytics wait for the visitor's consent.
+func (h *TemplateHandler) SetConsentService(consentService *service.ConsentService) {
+	if h == nil {
+		return
+	}
+	h.consentService = consentService
+}
+
+// applyConsent removes the ads a visitor has not agreed to and adds the
+// analytics snippets they have.
+func (h *TemplateHandler) applyConsent(c *gin.Context, data gin.H) {
+	if h.consentService == nil {
+		return
+	}
+
+	cookie, _ := c.Cookie(constants.ConsentCookieName)
+	state := h.consentService.State(cookie)
+	if !state.Allowed(models.ConsentCategoryAdvertising) {
+		data["Advertising"] = advertisingTemplateData{Placements: make(map[string][]template.HTML)}
+	}
+
+	consent := consentTemplateData{ConsentState: state}
+	for _, snippet := range h.consentService.AnalyticsSnippets(state) {
+		if trimmed := strings.TrimSpace(snippet); trimmed != "" {
+			consent.AnalyticsSnippets = append(consent.AnalyticsSnippets, template.HTML(trimmed))
+		}
+	}
+	data["Consent"] = consent
+}
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/pkg/logger"
+	courseservice "constructor-script-backend/plugins/courses/service"
+)
+
+// initialCourseTopic returns the index of the first topic with lessons, which the
+// course player opens on load, or -1 when no topic has any.
+func initialCourseTopic(topics []models.CourseTopic) int {
+	for index, topic := range topics {
+		if len(topic.Steps) > 0 {
+			return index
+		}
+	}
+	return -1
+}
+
+// courseTopicOutline keeps what the player needs to list a topic's lessons: titles,
+// types and video durations. Descriptions, sections, files and test questions are
+// left for RenderCourseTopic.
+func courseTopicOutline(topic models.CourseTopic) models.CourseTopic {
+	outline := models.CourseTopic{
+		ID:          topic.ID,
+		Title:       topic.Title,
+		Slug:        topic.Slug,
+		Summary:     topic.Summary,
+		Description: topic.Description,
+		Outline:     true,
+	}
+
+	outline.Steps = make([]models.CourseTopicStep, len(topic.Steps))
+	for index, step := range topic.Steps {
+		lesson := models.CourseTopicStep{
+			ID:        step.ID,
+			TopicID:   step.TopicID,
+			StepType:  step.StepType,
+			Position:  step.Position,
+			VideoID:   step.VideoID,
+			TestID:    step.TestID,
+			ContentID: step.ContentID,
+		}
+		if step.Video != nil {
+			lesson.Video = &models.CourseVideo{ID: step.Video.ID, Title: step.Video.Title, DurationSeconds: step.Video.DurationSeconds}
+		}
+		if step.Content != nil {
+			lesson.Content = &models.CourseContent{ID: step.Content.ID, Title: step.Content.Title}
+		}
+		if step.Test != nil {
+			lesson.Test = &models.CourseTest{ID: step.Test.ID, Title: step.Test.Title}
+		}
+		outline.Steps[index] = lesson
+	}
+	return outline
+}
+
+// renderCourseTopicSections renders the sections of every lesson in topic into
+// their SectionsHTML and returns the scripts the sections need.
+func (h *TemplateHandler) renderCourseTopicSections(c *gin.Context, topic *models.CourseTopic) []string {
+	var scripts []string
+	for stepIndex := range topic.Steps {
+		step := &topic.Steps[stepIndex]
+		switch step.StepType {
+		case models.CourseTopicStepTypeVideo:
+			if step.Video == nil {
+				continue
+			}
+			html, sectionScripts := h.renderSectionsWithPrefix(step.Video.Sections, "course-player", c)
+			step.Video.SectionsHTML = string(html)
+			scripts = appendScripts(scripts, sectionScripts)
+		case models.CourseTopicStepTypeContent:
+			if step.Content == nil {
+				continue
+			}
+			html, sectionScripts := h.renderSectionsWithPrefix(step.Content.Sections, "course-player", c)
+			step.Content.SectionsHTML = string(html)
+			scripts = appendScripts(scripts, sectionScripts)
+		}
+	}
+	return scripts
+}
+
+// RenderCourseTopic returns one topic of a course the user has access to, with its
+// sections rendered and its files signed, for the course player to load when the
+// topic is opened.
+func (h *TemplateHandler) RenderCourseTopic(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "authentication required"})
+		return
+	}
+	if h.coursePackageSvc == nil || h.courseMaterialProtect == nil || !h.courseMaterialProtect.Enabled() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "course materials are temporarily unavailable"})
+		return
+	}
+
+	identifier := strings.TrimSpace(c.Param("slug"))
+	course, err := h.coursePackageSvc.GetForUserByIdentifier(identifier, user.ID)
+	if err == nil && course == nil {
+		err = gorm.ErrRecordNotFound
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "course not found"})
+		case courseservice.IsValidationError(err):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			logger.Error(err, "Failed to load course topic for user", map[string]interface{}{"course_identifier": identifier, "user_id": user.ID})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load course"})
+		}
+		return
+	}
+
+	topicParam := strings.TrimSpace(c.Param("topic"))
+	topicID, _ := strconv.ParseUint(topicParam, 10, 64)
+	var topic *models.CourseTopic
+	for index := range course.Package.Topics {
+		candidate := &course.Package.Topics[index]
+		if (topicID > 0 && uint64(candidate.ID) == topicID) || (topicID == 0 && strings.EqualFold(candidate.Slug, topicParam)) {
+			topic = candidate
+			break
+		}
+	}
+	if topic == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "topic not found"})
+		return
+	}
+
+	scripts := h.renderCourseTopicSections(c, topic)
+
+	// Signing works on a whole course; hand it one holding only this topic.
+	single := &models.UserCoursePackage{Package: course.Package, Access: course.Access}
+	single.Package.Topics = []models.CourseTopic{*topic}
+	single = h.courseMaterialProtect.ProtectCourseForUser(single, user.ID)
+
+	c.Header("Cache-Control", "private, no-store")
+	c.JSON(http.StatusOK, gin.H{"topic": single.Package.Topics[0], "scripts": scripts})
+}
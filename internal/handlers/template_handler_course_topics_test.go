@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"testing"
+
+	"constructor-script-backend/internal/models"
+)
+
+func TestCourseTopicOutlineKeepsOnlyLessonTitles(t *testing.T) {
+	topic := models.CourseTopic{
+		ID:    4,
+		Title: "Advanced",
+		Steps: []models.CourseTopicStep{
+			{ID: 1, StepType: models.CourseTopicStepTypeVideo, Video: &models.CourseVideo{
+				ID: 7, Title: "Deep dive", DurationSeconds: 90, FileURL: "/uploads/deep.mp4",
+				Sections: models.PostSections{{Type: "text"}},
+			}},
+			{ID: 2, StepType: models.CourseTopicStepTypeTest, Test: &models.CourseTest{
+				ID: 3, Title: "Quiz", Questions: []models.CourseTestQuestion{{Prompt: "Why?"}},
+			}},
+		},
+	}
+
+	outline := courseTopicOutline(topic)
+	if !outline.Outline || outline.ID != 4 || len(outline.Steps) != 2 {
+		t.Fatalf("unexpected outline %+v", outline)
+	}
+	video := outline.Steps[0].Video
+	if video == nil || video.Title != "Deep dive" || video.DurationSeconds != 90 || video.FileURL != "" || len(video.Sections) != 0 {
+		t.Fatalf("video lesson must keep only its title and duration, got %+v", video)
+	}
+	if test := outline.Steps[1].Test; test == nil || test.Title != "Quiz" || len(test.Questions) != 0 {
+		t.Fatalf("test lesson must not carry its questions, got %+v", test)
+	}
+	if topic.Steps[0].Video.FileURL == "" {
+		t.Fatal("the outline must not modify the original topic")
+	}
+
+	if index := initialCourseTopic([]models.CourseTopic{{ID: 1}, topic}); index != 1 {
+		t.Fatalf("expected the first topic with lessons, got %d", index)
+	}
+}
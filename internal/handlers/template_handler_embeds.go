@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"context"
+
+	"constructor-script-backend/internal/service"
+)
+
+// SetEmbedService lets the embed section element resolve links through oEmbed.
+func (h *TemplateHandler) SetEmbedService(embedService *service.EmbedService) {
+	if h == nil {
+		return
+	}
+	h.embedService = embedService
+}
+
+// ResolveEmbed implements sections.EmbedResolver.
+func (h *TemplateHandler) ResolveEmbed(url string) (*service.Embed, bool) {
+	if h == nil || h.embedService == nil {
+		return nil, false
+	}
+	embed, err := h.embedService.Resolve(context.Background(), url)
+	if err != nil {
+		return nil, false
+	}
+	return embed, true
+}
@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/pkg/logger"
+	eventservice "constructor-script-backend/plugins/events/service"
+)
+
+const (
+	eventsMonthLayout      = "2006-01"
+	eventsUpcomingLimit    = 10
+	eventDetailUpcomingMax = 5
+)
+
+type eventOccurrenceView struct {
+	Title     string
+	Slug      string
+	Summary   string
+	Location  string
+	Image     string
+	DateLabel string
+	TimeLabel string
+	StartTime string
+	StartISO  string
+	EndISO    string
+	AllDay    bool
+}
+
+type eventCalendarDay struct {
+	Day         int
+	InMonth     bool
+	IsToday     bool
+	Occurrences []eventOccurrenceView
+}
+
+// SetEventService updates the events service used by the template handler.
+func (h *TemplateHandler) SetEventService(eventService *eventservice.EventService) {
+	if h == nil {
+		return
+	}
+	h.eventSvc = eventService
+}
+
+func (h *TemplateHandler) eventsEnabled() bool {
+	return h != nil && h.eventSvc != nil
+}
+
+func (h *TemplateHandler) ensureEventsAvailable(c *gin.Context) bool {
+	if !h.eventsEnabled() {
+		if c != nil {
+			h.renderError(c, http.StatusServiceUnavailable, "Events unavailable", "The events plugin is not active.")
+		}
+		return false
+	}
+	return true
+}
+
+// RenderEvents renders the public calendar for the month given by ?month=YYYY-MM,
+// defaulting to the current month, followed by the list of upcoming events.
+func (h *TemplateHandler) RenderEvents(c *gin.Context) {
+	if !h.ensureEventsAvailable(c) {
+		return
+	}
+
+	now := time.Now()
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if raw := strings.TrimSpace(c.Query("month")); raw != "" {
+		parsed, err := time.Parse(eventsMonthLayout, raw)
+		if err != nil {
+			h.renderError(c, http.StatusBadRequest, "Invalid month", "The month must be given as YYYY-MM.")
+			return
+		}
+		month = parsed
+	}
+	nextMonth := month.AddDate(0, 1, 0)
+
+	// Widen the window by a day on each side so events in far-off time zones still
+	// land on the right calendar day.
+	occurrences, err := h.eventSvc.Occurrences(month.Add(-24*time.Hour), nextMonth.Add(24*time.Hour), 0)
+	if err != nil {
+		logger.Error(err, "Failed to load event occurrences", map[string]interface{}{"month": month.Format(eventsMonthLayout)})
+		h.renderError(c, http.StatusInternalServerError, "Events unavailable", "We couldn't load the calendar right now.")
+		return
+	}
+
+	upcoming, err := h.eventSvc.Upcoming(eventsUpcomingLimit)
+	if err != nil {
+		logger.Error(err, "Failed to load upcoming events", nil)
+		h.renderError(c, http.StatusInternalServerError, "Events unavailable", "We couldn't load upcoming events right now.")
+		return
+	}
+
+	title := "Events"
+	description := "Upcoming events and the full calendar."
+	canonical := "/events"
+	if c.Query("month") != "" {
+		canonical += "?month=" + month.Format(eventsMonthLayout)
+	}
+
+	h.renderTemplate(c, "events", title, description, gin.H{
+		"MonthLabel":    month.Format("January 2006"),
+		"PrevMonth":     month.AddDate(0, -1, 0).Format(eventsMonthLayout),
+		"NextMonth":     nextMonth.Format(eventsMonthLayout),
+		"Weekdays":      []string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"},
+		"Weeks":         buildEventCalendar(month, occurrences, now),
+		"Upcoming":      buildEventOccurrenceViews(upcoming),
+		"FeedURL":       "/events.ics",
+		"EventsEnabled": true,
+		"Styles":        []string{"/static/css/sections/events.css"},
+		"Canonical":     canonical,
+	})
+}
+
+// RenderEvent renders a single published event together with its next occurrences.
+func (h *TemplateHandler) RenderEvent(c *gin.Context) {
+	if !h.ensureEventsAvailable(c) {
+		return
+	}
+
+	slug := strings.TrimSpace(c.Param("slug"))
+	event, err := h.eventSvc.GetBySlug(slug, false)
+	if err != nil {
+		if errors.Is(err, eventservice.ErrEventNotFound) {
+			h.renderError(c, http.StatusNotFound, "Event not found", "The event you are looking for does not exist.")
+			return
+		}
+		logger.Error(err, "Failed to load event", map[string]interface{}{"slug": slug})
+		h.renderError(c, http.StatusInternalServerError, "Events unavailable", "We couldn't load this event right now.")
+		return
+	}
+
+	now := time.Now()
+	next := eventservice.ExpandEvent(event, now, now.AddDate(2, 0, 0), eventDetailUpcomingMax)
+
+	first := models.EventOccurrence{Event: event, StartsAt: event.StartsAt, EndsAt: event.EndsAt}
+	if len(next) > 0 {
+		first = next[0]
+	}
+
+	description := event.Summary
+	if description == "" {
+		description = event.Title
+	}
+
+	h.renderTemplate(c, "event", event.Title, description, gin.H{
+		"Event":         event,
+		"Description":   h.sanitizeHTML(models.SanitizePageContent, event.Description),
+		"When":          newEventOccurrenceView(first),
+		"Upcoming":      buildEventOccurrenceViews(next),
+		"Recurring":     event.Recurrence != "",
+		"FeedURL":       "/events/" + event.Slug + "/calendar.ics",
+		"EventsEnabled": true,
+		"Styles":        []string{"/static/css/sections/events.css"},
+		"Canonical":     "/events/" + event.Slug,
+	})
+}
+
+func buildEventCalendar(month time.Time, occurrences []models.EventOccurrence, now time.Time) [][]eventCalendarDay {
+	byDay := make(map[string][]eventOccurrenceView)
+	for _, occurrence := range occurrences {
+		key := occurrence.StartsAt.Format("2006-01-02")
+		byDay[key] = append(byDay[key], newEventOccurrenceView(occurrence))
+	}
+
+	offset := (int(month.Weekday()) + 6) % 7
+	start := month.AddDate(0, 0, -offset)
+	today := now.Format("2006-01-02")
+
+	var weeks [][]eventCalendarDay
+	for day := start; ; {
+		week := make([]eventCalendarDay, 0, 7)
+		for i := 0; i < 7; i++ {
+			key := day.Format("2006-01-02")
+			week = append(week, eventCalendarDay{
+				Day:         day.Day(),
+				InMonth:     day.Month() == month.Month(),
+				IsToday:     key == today,
+				Occurrences: byDay[key],
+			})
+			day = day.AddDate(0, 0, 1)
+		}
+		weeks = append(weeks, week)
+		if day.Month() != month.Month() {
+			break
+		}
+	}
+	return weeks
+}
+
+func buildEventOccurrenceViews(occurrences []models.EventOccurrence) []eventOccurrenceView {
+	views := make([]eventOccurrenceView, 0, len(occurrences))
+	for _, occurrence := range occurrences {
+		views = append(views, newEventOccurrenceView(occurrence))
+	}
+	return views
+}
+
+func newEventOccurrenceView(occurrence models.EventOccurrence) eventOccurrenceView {
+	view := eventOccurrenceView{
+		StartISO: occurrence.StartsAt.Format(time.RFC3339),
+		EndISO:   occurrence.EndsAt.Format(time.RFC3339),
+	}
+	if event := occurrence.Event; event != nil {
+		view.Title = event.Title
+		view.Slug = event.Slug
+		view.Summary = event.Summary
+		view.Location = event.Location
+		view.Image = event.Image
+		view.AllDay = event.AllDay
+	}
+
+	start, end := occurrence.StartsAt, occurrence.EndsAt
+	sameDay := start.Format("2006-01-02") == end.Format("2006-01-02")
+	if sameDay {
+		view.DateLabel = start.Format("Monday, January 2, 2006")
+	} else {
+		view.DateLabel = start.Format("January 2") + " – " + end.Format("January 2, 2006")
+	}
+
+	if !view.AllDay {
+		view.StartTime = start.Format("15:04")
+		view.TimeLabel = start.Format("15:04") + " – " + end.Format("15:04")
+		if zone, _ := start.Zone(); zone != "" {
+			view.TimeLabel += " " + zone
+		}
+	}
+	return view
+}
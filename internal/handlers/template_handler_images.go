@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"html/template"
+	"strings"
+
+	"constructor-script-backend/internal/service"
+)
+
+// SetUploadService lets templates and sections emit srcsets for generated image variants.
+func (h *TemplateHandler) SetUploadService(uploadService *service.UploadService) {
+	if h == nil {
+		return
+	}
+	h.uploadService = uploadService
+}
+
+// SetImageTransformService lets templates link to signed on-demand resizes.
+func (h *TemplateHandler) SetImageTransformService(imageTransform *service.ImageTransformService) {
+	if h == nil {
+		return
+	}
+	h.imageTransform = imageTransform
+}
+
+// ResponsiveImage implements sections.ResponsiveImageProvider.
+func (h *TemplateHandler) ResponsiveImage(url string) (service.ResponsiveImage, bool) {
+	if h == nil || h.uploadService == nil {
+		return service.ResponsiveImage{}, false
+	}
+	return h.uploadService.ResponsiveImage(url)
+}
+
+// ImageMetadata implements sections.ImageMetadataProvider.
+func (h *TemplateHandler) ImageMetadata(url string) (service.ImageMetadata, bool) {
+	if h == nil || h.uploadService == nil {
+		return service.ImageMetadata{}, false
+	}
+	return h.uploadService.ImageMetadata(url)
+}
+
+// addImageFuncs installs the image helpers backed by the upload service:
+//
+//	<img src="{{ .Image }}"{{ with srcset .Image }} srcset="{{ . }}" sizes="100vw"{{ end }}>
+//	{{ range imageSources .Image }}<source type="{{ .Type }}" srcset="{{ .Srcset }}">{{ end }}
+//	<img src="{{ resizedImage .Image "w=640,h=360,fit=cover" }}">
+//
+// srcset takes an optional format ("webp", "avif") and defaults to the upload's own.
+// resizedImage signs the URL, as the resizer only renders signed transforms.
+func (h *TemplateHandler) addImageFuncs(funcs template.FuncMap) {
+	if funcs == nil {
+		return
+	}
+
+	funcs["srcset"] = func(url string, format ...string) template.Srcset {
+		if h.uploadService == nil {
+			return ""
+		}
+		requested := ""
+		if len(format) > 0 {
+			requested = strings.ToLower(strings.TrimSpace(format[0]))
+		}
+		return template.Srcset(h.uploadService.ImageSrcset(url, requested))
+	}
+	funcs["resizedImage"] = func(url, transform string) string {
+		if h.imageTransform == nil {
+			return url
+		}
+		return h.imageTransform.URL(url, transform)
+	}
+	funcs["imageSources"] = func(url string) []service.ImageSource {
+		responsive, ok := h.ResponsiveImage(url)
+		if !ok {
+			return nil
+		}
+		return responsive.Sources
+	}
+}
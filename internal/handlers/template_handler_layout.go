@@ -10,9 +10,11 @@ import (
 	"strings"
 	"unicode"
 
+	"constructor-script-backend/internal/middleware"
 	"constructor-script-backend/internal/models"
 	"constructor-script-backend/internal/payments/stripe"
 	"constructor-script-backend/internal/service"
+	"constructor-script-backend/internal/theme"
 	"constructor-script-backend/pkg/lang"
 	"constructor-script-backend/pkg/logger"
 	"constructor-script-backend/pkg/utils"
@@ -36,6 +38,17 @@ func (d advertisingTemplateData) hasPlacements() bool {
 	return len(d.Placements) > 0
 }
 
+type colorSchemeTemplateData struct {
+	Default     string
+	Initial     string
+	AllowToggle bool
+	Variants    []string
+	VariantList string
+	Styles      template.CSS
+	// Nonce is the CSP nonce of the response, for the inline script.
+	Nonce string
+}
+
 type courseCheckoutTemplateData struct {
 	Enabled        bool
 	Endpoint       string
@@ -45,7 +58,7 @@ type courseCheckoutTemplateData struct {
 func (h *TemplateHandler) basePageData(title, description string, extra gin.H) gin.H {
 	site := h.siteSettings()
 
-	headerMenu, footerMenu := splitMenuItems(site.MenuItems)
+	headerMenu, footerMenu := splitMenuItems(models.MenuItemsForLanguage(site.MenuItems, site.DefaultLanguage))
 
 	advertising := h.advertisingTemplateData()
 
@@ -79,6 +92,7 @@ func (h *TemplateHandler) basePageData(title, description string, extra gin.H) g
 			"ContactEmail":       site.ContactEmail,
 			"SocialLinks":        site.SocialLinks,
 			"MenuItems":          site.MenuItems,
+			"Translations":       site.Translations,
 			"HeaderMenuItems":    headerMenu,
 			"FooterMenuItems":    footerMenu,
 			"DefaultLanguage":    site.DefaultLanguage,
@@ -89,16 +103,49 @@ func (h *TemplateHandler) basePageData(title, description string, extra gin.H) g
 		"SearchQuery":    "",
 		"SearchType":     "all",
 		"Advertising":    advertising,
+		"Announcements":  h.liveAnnouncements(),
 		"CourseCheckout": checkoutData,
+		"ColorScheme":    h.colorSchemeTemplateData(site, h.pageTheme(extra)),
 	}
 
 	for k, v := range extra {
 		data[k] = v
 	}
+	data["Robots"] = robotsDirectives(data)
 
 	return data
 }
 
+const indexFollowDirectives = "index, follow"
+
+// contentRobots converts the per-content robots flags of a post, page or category
+// into a robots directive string. It returns "" when both are unset.
+func contentRobots(noIndex, noFollow bool) string {
+	if !noIndex && !noFollow {
+		return ""
+	}
+	directives := []string{"index", "follow"}
+	if noIndex {
+		directives[0] = "noindex"
+	}
+	if noFollow {
+		directives[1] = "nofollow"
+	}
+	return strings.Join(directives, ", ")
+}
+
+// robotsDirectives resolves the robots meta content for a page. NoIndex blocks
+// the page entirely; otherwise Robots carries the content's own directives.
+func robotsDirectives(data gin.H) string {
+	if noIndex, ok := data["NoIndex"].(bool); ok && noIndex {
+		return "noindex, nofollow"
+	}
+	if robots := strings.TrimSpace(getString(data, "Robots")); robots != "" {
+		return robots
+	}
+	return indexFollowDirectives
+}
+
 func (h *TemplateHandler) siteSettings() models.SiteSettings {
 	settings, err := ResolveSiteSettings(h.config, h.setupService, h.languageService)
 	if err != nil {
@@ -165,6 +212,37 @@ func (h *TemplateHandler) siteSettings() models.SiteSettings {
 	return settings
 }
 
+// colorSchemeTemplateData resolves the palette variant rendered into data-theme. When the
+// configured scheme is "system" the initial value is light and the inline head script
+// switches to dark before first paint if the visitor prefers it.
+// colorSchemeTemplateData describes the palette variants of the theme used to render
+// the page; a nil theme selects the active one.
+func (h *TemplateHandler) colorSchemeTemplateData(site models.SiteSettings, active *theme.Theme) colorSchemeTemplateData {
+	if active == nil && h.themeManager != nil {
+		active = h.themeManager.Active()
+	}
+
+	scheme := theme.NormalizeColorScheme(site.ColorScheme)
+	if scheme == "" || !active.SupportsColorScheme(scheme) {
+		scheme = active.DefaultColorScheme()
+	}
+
+	variants := active.ColorSchemes()
+	initial := scheme
+	if initial == theme.ColorSchemeSystem {
+		initial = theme.ColorSchemeLight
+	}
+
+	return colorSchemeTemplateData{
+		Default:     scheme,
+		Initial:     initial,
+		AllowToggle: site.AllowColorSchemeToggle && len(variants) > 1,
+		Variants:    variants,
+		VariantList: strings.Join(variants, " "),
+		Styles:      template.CSS(active.ColorSchemeCSS()),
+	}
+}
+
 func (h *TemplateHandler) advertisingTemplateData() advertisingTemplateData {
 	result := advertisingTemplateData{
 		Placements: make(map[string][]template.HTML),
@@ -427,18 +505,62 @@ func (h *TemplateHandler) renderTemplate(c *gin.Context, templateName, title, de
 	h.renderWithLayout(c, layout, templateName+".html", data)
 }
 
+// applyPageTheme records the theme and layout selected for a page in the template data
+// so renderWithLayout can pick the matching template set.
+func applyPageTheme(page *models.Page, data gin.H) {
+	if page == nil || data == nil {
+		return
+	}
+	if themeSlug := strings.TrimSpace(page.Theme); themeSlug != "" {
+		data["PageTheme"] = themeSlug
+	}
+	if layout := strings.TrimSpace(page.Layout); layout != "" {
+		data["Layout"] = strings.TrimSuffix(layout, ".html") + ".html"
+	}
+}
+
+// pageTheme returns the installed theme requested via data["PageTheme"], or nil when
+// the page uses the active theme.
+func (h *TemplateHandler) pageTheme(data gin.H) *theme.Theme {
+	if h == nil || h.themeManager == nil || data == nil {
+		return nil
+	}
+
+	slug, _ := data["PageTheme"].(string)
+	if strings.TrimSpace(slug) == "" {
+		return nil
+	}
+
+	themeValue, ok := h.themeManager.Resolve(slug)
+	if !ok {
+		return nil
+	}
+	return themeValue
+}
+
 func (h *TemplateHandler) renderWithLayout(c *gin.Context, layout, content string, data gin.H) {
 	h.addUserContext(c, data)
+	h.applyAnnouncements(c, data)
+	h.applyConsent(c, data)
+	h.localizeChrome(c, data)
 	h.applySEOMetadata(c, data)
 	h.setNavigationState(c, data)
+	applyCSPNonce(c, data)
 
-	if noIndex, ok := data["NoIndex"].(bool); ok && noIndex {
-		c.Header("X-Robots-Tag", "noindex, nofollow")
+	robots := robotsDirectives(data)
+	data["Robots"] = robots
+	if robots != indexFollowDirectives {
+		c.Header("X-Robots-Tag", robots)
 	}
 
-	tmpl, err := h.templateClone()
+	themeSlug := ""
+	if pageTheme := h.pageTheme(data); pageTheme != nil {
+		themeSlug = pageTheme.Slug
+	}
+
+	tmpl, err := h.templateSetFor(themeSlug)
 	if err != nil {
-		logger.Error(err, "Failed to clone templates", nil)
+		logger.Error(err, "Failed to load templates", nil)
 		h.renderError(c, http.StatusInternalServerError, "500 - Server Error", "Template error")
 		return
 	}
@@ -476,6 +598,20 @@ func (h *TemplateHandler) renderWithLayout(c *gin.Context, layout, content strin
 	c.Data(http.StatusOK, "text/html; charset=utf-8", output)
 }
 
+// applyCSPNonce passes the nonce the Content-Security-Policy expects on inline
+// scripts to the templates, as CSPNonce.
+func applyCSPNonce(c *gin.Context, data gin.H) {
+	nonce := c.GetString(middleware.CSPNonceKey)
+	if nonce == "" {
+		return
+	}
+	data["CSPNonce"] = nonce
+	if scheme, ok := data["ColorScheme"].(colorSchemeTemplateData); ok {
+		scheme.Nonce = nonce
+		data["ColorScheme"] = scheme
+	}
+}
+
 func (h *TemplateHandler) applySEOMetadata(c *gin.Context, data gin.H) {
 	siteURL := ""
 	var siteData gin.H
@@ -488,6 +624,9 @@ func (h *TemplateHandler) applySEOMetadata(c *gin.Context, data gin.H) {
 	if siteURL == "" {
 		siteURL = h.config.SiteURL
 	}
+	// Canonical links always use the configured scheme and host, whichever one
+	// the request arrived on.
+	canonicalBase := siteURL
 
 	if normalized := h.normalizeBaseURL(siteURL, c.Request); normalized != "" {
 		siteURL = normalized
@@ -497,12 +636,22 @@ func (h *TemplateHandler) applySEOMetadata(c *gin.Context, data gin.H) {
 		siteData["URL"] = siteURL
 		siteData["Favicon"] = h.resolveAbsoluteURL(siteURL, getString(siteData, "Favicon"), c.Request)
 		siteData["Logo"] = h.resolveAbsoluteURL(siteURL, getString(siteData, "Logo"), c.Request)
-	}
 
-	if language := strings.TrimSpace(getString(data, "Language")); language == "" {
-		if siteData != nil {
-			language = strings.TrimSpace(getString(siteData, "DefaultLanguage"))
+		structuredBase := canonicalBase
+		if structuredBase == "" {
+			structuredBase = siteURL
 		}
+		data["SiteStructuredData"] = h.buildSiteStructuredData(siteData, structuredBase, c.Request.URL.Path == "/")
+	}
+
+	defaultLanguage := ""
+	if siteData != nil {
+		defaultLanguage = strings.TrimSpace(getString(siteData, "DefaultLanguage"))
+	}
+
+	language := strings.TrimSpace(getString(data, "Language"))
+	if language == "" {
+		language = defaultLanguage
 		if language == "" {
 			language = lang.Default
 		}
@@ -510,20 +659,33 @@ func (h *TemplateHandler) applySEOMetadata(c *gin.Context, data gin.H) {
 	}
 
 	if siteData != nil {
-		if locale := strings.TrimSpace(getString(siteData, "DefaultLanguage")); locale != "" {
-			data["OGLocale"] = locale
+		if _, translated := data["Translations"]; translated {
+			data["OGLocale"] = language
+		} else if defaultLanguage != "" {
+			data["OGLocale"] = defaultLanguage
 		}
 	}
 
+	translations, isContent := data["Translations"].([]contentTranslation)
+	if !isContent && !strings.Contains(robotsDirectives(data), "noindex") {
+		translations = h.pathTranslations(c.Request.URL.Path)
+	}
+	if alternates := h.buildLanguageAlternates(siteURL, defaultLanguage, translations, c.Request); len(alternates) > 0 {
+		data["Alternates"] = alternates
+	}
+
+	current := lang.PathLanguage(c.Request.Context())
+	if current == "" {
+		current = language
+	}
+	if links := h.languageLinks(c.Request.URL.Path, current, translations); len(links) > 0 {
+		data["LanguageLinks"] = links
+	}
+
 	title, _ := data["Title"].(string)
 	description, _ := data["Description"].(string)
 
-	canonical := strings.TrimSpace(getString(data, "Canonical"))
-	if canonical == "" {
-		canonical = h.buildCanonicalURL(siteURL, c.Request.URL)
-	} else {
-		canonical = h.resolveAbsoluteURL(siteURL, canonical, c.Request)
-	}
+	canonical := h.buildCanonicalURL(canonicalBase, getString(data, "Canonical"), c.Request)
 	data["Canonical"] = canonical
 
 	ogURL := strings.TrimSpace(getString(data, "OGURL"))
@@ -763,42 +925,53 @@ func requestHost(r *http.Request) string {
 	return ""
 }
 
-func (h *TemplateHandler) buildCanonicalURL(base string, requestURL *url.URL) string {
-	if requestURL == nil {
-		return strings.TrimSuffix(base, "/")
+// buildCanonicalURL normalizes a canonical link, falling back to the request URL
+// when value is empty. Fragments, tracking parameters and trailing slashes are
+// dropped, and links on the site's own host are rebased onto base so every page
+// agrees on one scheme and host. Links to other hosts are kept as they are.
+func (h *TemplateHandler) buildCanonicalURL(base, value string, r *http.Request) string {
+	var target url.URL
+	if value = strings.TrimSpace(value); value != "" {
+		parsed, err := url.Parse(value)
+		if err != nil {
+			return value
+		}
+		target = *parsed
+	} else if r != nil && r.URL != nil {
+		target = *r.URL
 	}
 
-	cleaned := *requestURL
-	cleaned.Fragment = ""
+	base = strings.TrimSuffix(strings.TrimSpace(base), "/")
+	baseURL, _ := url.Parse(base)
+	if target.Host != "" {
+		sameSite := baseURL != nil && strings.EqualFold(target.Host, baseURL.Host)
+		if !sameSite && !strings.EqualFold(target.Host, requestHost(r)) {
+			return target.String()
+		}
+	}
 
-	if rawQuery := cleaned.Query(); len(rawQuery) > 0 {
-		for key := range rawQuery {
+	if query := target.Query(); len(query) > 0 {
+		for key := range query {
 			lower := strings.ToLower(key)
 			if strings.HasPrefix(lower, "utm_") || lower == "fbclid" || lower == "gclid" {
-				rawQuery.Del(key)
+				query.Del(key)
 			}
 		}
-		cleaned.RawQuery = rawQuery.Encode()
-	}
-
-	if cleaned.IsAbs() {
-		return cleaned.String()
-	}
-
-	base = strings.TrimSuffix(base, "/")
-	path := cleaned.Path
-	if path == "" {
-		path = "/"
-	} else if !strings.HasPrefix(path, "/") {
-		path = "/" + path
+		target.RawQuery = query.Encode()
 	}
 
+	path := "/" + strings.Trim(target.Path, "/")
 	canonical := path
-	if cleaned.RawQuery != "" {
-		canonical = canonical + "?" + cleaned.RawQuery
+	if target.RawQuery != "" {
+		canonical = canonical + "?" + target.RawQuery
 	}
 
-	if base == "" {
+	if base == "" || baseURL == nil || baseURL.Host == "" {
+		if r != nil {
+			if scheme, host := requestScheme(r), requestHost(r); host != "" {
+				return scheme + "://" + host + canonical
+			}
+		}
 		return canonical
 	}
 
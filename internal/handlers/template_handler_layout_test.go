@@ -38,3 +38,44 @@ func TestSetNavigationStateActivePath(t *testing.T) {
 		})
 	}
 }
+
+func TestRobotsDirectives(t *testing.T) {
+	cases := []struct {
+		data gin.H
+		want string
+	}{
+		{gin.H{}, "index, follow"},
+		{gin.H{"Robots": contentRobots(true, false)}, "noindex, follow"},
+		{gin.H{"Robots": contentRobots(false, true)}, "index, nofollow"},
+		{gin.H{"Robots": contentRobots(false, false)}, "index, follow"},
+		{gin.H{"Robots": contentRobots(false, true), "NoIndex": true}, "noindex, nofollow"},
+	}
+	for _, tc := range cases {
+		if got := robotsDirectives(tc.data); got != tc.want {
+			t.Fatalf("robotsDirectives(%v) = %q, want %q", tc.data, got, tc.want)
+		}
+	}
+}
+
+func TestBuildCanonicalURL(t *testing.T) {
+	handler := &TemplateHandler{}
+	request := httptest.NewRequest(http.MethodGet, "http://www.example.com/blog/?utm_source=x&page=2", nil)
+
+	cases := []struct {
+		base  string
+		value string
+		want  string
+	}{
+		{"https://example.com/", "", "https://example.com/blog?page=2"},
+		{"https://example.com", "/events/", "https://example.com/events"},
+		{"https://example.com", "http://example.com/forum/1#answers", "https://example.com/forum/1"},
+		{"https://example.com", "http://www.example.com/tag/go/", "https://example.com/tag/go"},
+		{"https://example.com", "https://other.org/post/", "https://other.org/post/"},
+		{"", "/about", "http://www.example.com/about"},
+	}
+	for _, tc := range cases {
+		if got := handler.buildCanonicalURL(tc.base, tc.value, request); got != tc.want {
+			t.Fatalf("buildCanonicalURL(%q, %q) = %q, want %q", tc.base, tc.value, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"strings"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/pkg/lang"
+
+	"github.com/gin-gonic/gin"
+)
+
+// localizeChrome switches the site name, description, menus and theme strings to
+// the language the page is shown in, so the header and footer match the content.
+// Anything without a translation keeps its default-language value.
+func (h *TemplateHandler) localizeChrome(c *gin.Context, data gin.H) {
+	siteData, _ := data["Site"].(gin.H)
+	defaultLanguage := ""
+	if siteData != nil {
+		defaultLanguage = strings.TrimSpace(getString(siteData, "DefaultLanguage"))
+	}
+
+	language := strings.TrimSpace(getString(data, "Language"))
+	if requested := lang.PathLanguage(c.Request.Context()); requested != "" {
+		// On a translation fallback <html lang> keeps naming the content language,
+		// but the chrome follows the language the visitor picked.
+		if _, fallback := data["TranslationFallback"]; !fallback {
+			data["Language"] = requested
+		}
+		language = requested
+	}
+	if language == "" {
+		language = defaultLanguage
+	}
+
+	// Dates and numbers follow the negotiated language, which already prefers a
+	// URL prefix or ?lang over Accept-Language.
+	if locale := c.GetString("language"); locale != "" {
+		data["Locale"] = locale
+	} else {
+		data["Locale"] = language
+	}
+
+	themeValue := h.pageTheme(data)
+	if themeValue == nil && h.themeManager != nil {
+		themeValue = h.themeManager.Active()
+	}
+	texts := themeValue.Strings(language, defaultLanguage)
+	data["T"] = texts
+
+	data["Direction"] = lang.Direction(language)
+	if lang.IsRTL(language) {
+		data["RTL"] = true
+		data["RTLStyles"] = themeValue.RTLStylesheets()
+	}
+
+	if siteData == nil {
+		return
+	}
+
+	if language != defaultLanguage {
+		if translations, ok := siteData["Translations"].(models.SiteTranslations); ok && len(translations) > 0 {
+			name := getString(siteData, "Name")
+			localized := models.SiteSettings{
+				Name:         name,
+				Description:  getString(siteData, "Description"),
+				FooterText:   getString(siteData, "FooterText"),
+				Translations: translations,
+			}.Localize(language)
+
+			siteData["Name"] = localized.Name
+			siteData["Description"] = localized.Description
+			siteData["FooterText"] = localized.FooterText
+
+			// basePageData already appended the default-language name to the title.
+			if title := getString(data, "Title"); localized.Name != name && strings.HasSuffix(title, " - "+name) {
+				data["Title"] = strings.TrimSuffix(title, name) + localized.Name
+			}
+		}
+	}
+
+	if items, ok := siteData["MenuItems"].([]models.MenuItem); ok && len(items) > 0 {
+		items = models.MenuItemsForLanguage(items, language)
+		siteData["MenuItems"] = items
+		if language != defaultLanguage {
+			items = localizeMenuItems(items, language)
+			siteData["MenuItems"] = items
+		}
+		header, footer := splitMenuItems(items)
+		for i := range footer {
+			if title, ok := texts[footerGroupStringKey(footer[i].Key)]; ok && title != "" {
+				footer[i].Title = title
+			}
+		}
+		siteData["HeaderMenuItems"] = header
+		siteData["FooterMenuItems"] = footer
+	}
+}
+
+// localizeMenuItems returns a copy of items titled in language where a
+// translation exists.
+func localizeMenuItems(items []models.MenuItem, language string) []models.MenuItem {
+	localized := make([]models.MenuItem, len(items))
+	for i, item := range items {
+		item.Title = item.Translations.Get(language, item.Title)
+		item.Label = item.Title
+		localized[i] = item
+	}
+	return localized
+}
+
+// footerGroupStringKey names the theme string holding a footer group heading,
+// e.g. "footer.groups.explore" for the "footer:explore" group.
+func footerGroupStringKey(key string) string {
+	suffix := strings.TrimPrefix(key, "footer:")
+	if suffix == "" || suffix == "footer" {
+		suffix = "default"
+	}
+	return "footer.groups." + suffix
+}
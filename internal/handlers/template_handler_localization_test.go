@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/pkg/lang"
+)
+
+func TestLocalizeChrome(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := &TemplateHandler{}
+
+	newData := func() gin.H {
+		return gin.H{
+			"Title":    "Blog - Example",
+			"Language": "en",
+			"Site": gin.H{
+				"Name":            "Example",
+				"Description":     "An example site",
+				"DefaultLanguage": "en",
+				"Translations": models.SiteTranslations{
+					"es": {Name: "Ejemplo"},
+				},
+				"MenuItems": []models.MenuItem{
+					{ID: 1, Title: "Blog", URL: "/blog", Location: "header", Translations: models.LocalizedText{"es": "Artículos"}},
+					{ID: 2, Title: "About", URL: "/about", Location: "header"},
+				},
+			},
+		}
+	}
+
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	req := httptest.NewRequest(http.MethodGet, "/blog", nil)
+	ctx.Request = req.WithContext(lang.WithPathLanguage(req.Context(), "es"))
+
+	data := newData()
+	handler.localizeChrome(ctx, data)
+
+	site := data["Site"].(gin.H)
+	if site["Name"] != "Ejemplo" {
+		t.Fatalf("expected translated site name, got %v", site["Name"])
+	}
+	if site["Description"] != "An example site" {
+		t.Fatalf("expected default description as fallback, got %v", site["Description"])
+	}
+	if data["Title"] != "Blog - Ejemplo" {
+		t.Fatalf("expected title with translated site name, got %v", data["Title"])
+	}
+	if data["Language"] != "es" {
+		t.Fatalf("expected page language from URL prefix, got %v", data["Language"])
+	}
+	header := site["HeaderMenuItems"].([]models.MenuItem)
+	if header[0].Title != "Artículos" || header[1].Title != "About" {
+		t.Fatalf("unexpected header menu %q, %q", header[0].Title, header[1].Title)
+	}
+
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/blog", nil)
+	data = newData()
+	handler.localizeChrome(ctx, data)
+	if site := data["Site"].(gin.H); site["Name"] != "Example" {
+		t.Fatalf("default language must keep the site name, got %v", site["Name"])
+	}
+}
+
+func TestLocalizeChromeSetsDirection(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := &TemplateHandler{}
+
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	req := httptest.NewRequest(http.MethodGet, "/blog", nil)
+	ctx.Request = req.WithContext(lang.WithPathLanguage(req.Context(), "ar"))
+
+	data := gin.H{"Language": "en", "Site": gin.H{"DefaultLanguage": "en"}}
+	handler.localizeChrome(ctx, data)
+	if data["Direction"] != "rtl" || data["RTL"] != true {
+		t.Fatalf("expected rtl direction for Arabic, got %v", data["Direction"])
+	}
+
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/blog", nil)
+	data = gin.H{"Language": "en", "Site": gin.H{"DefaultLanguage": "en"}}
+	handler.localizeChrome(ctx, data)
+	if data["Direction"] != "ltr" || data["RTL"] != nil {
+		t.Fatalf("expected ltr direction for English, got %v", data["Direction"])
+	}
+}
+
+func TestLocalizeChromeUsesLanguageMenuSet(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := &TemplateHandler{}
+
+	newData := func() gin.H {
+		return gin.H{
+			"Language": "en",
+			"Site": gin.H{
+				"DefaultLanguage": "en",
+				"MenuItems": []models.MenuItem{
+					{ID: 1, Title: "Blog", URL: "/blog", Location: "header"},
+					{ID: 2, Title: "Noticias", URL: "/es/noticias", Location: "header", Language: "es"},
+					{ID: 3, Title: "Privacy", URL: "/privacy", Location: "footer"},
+				},
+			},
+		}
+	}
+
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	ctx.Request = req.WithContext(lang.WithPathLanguage(req.Context(), "es-MX"))
+
+	data := newData()
+	handler.localizeChrome(ctx, data)
+	site := data["Site"].(gin.H)
+	header := site["HeaderMenuItems"].([]models.MenuItem)
+	if len(header) != 1 || header[0].ID != 2 {
+		t.Fatalf("expected the Spanish header menu, got %+v", header)
+	}
+	if footer := site["FooterMenuItems"].([]FooterMenuGroup); len(footer) != 1 || footer[0].Items[0].ID != 3 {
+		t.Fatalf("expected the shared footer menu, got %+v", footer)
+	}
+
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	data = newData()
+	handler.localizeChrome(ctx, data)
+	header = data["Site"].(gin.H)["HeaderMenuItems"].([]models.MenuItem)
+	if len(header) != 1 || header[0].ID != 1 {
+		t.Fatalf("expected the shared header menu, got %+v", header)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"html/template"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"strconv"
 	"strings"
@@ -47,10 +48,7 @@ func (h *TemplateHandler) renderSinglePost(c *gin.Context, post *models.Post) {
 	}
 
 	site := h.siteSettings()
-	canonicalPath := fmt.Sprintf("/blog/post/%s", post.Slug)
-	if post.Slug == "" {
-		canonicalPath = fmt.Sprintf("/blog/post/%d", post.ID)
-	}
+	canonicalPath := h.localizedPath(post.Language, postPath(*post))
 	canonicalURL := h.ensureAbsoluteURL(site.URL, canonicalPath)
 	if canonicalURL == "" {
 		canonicalURL = h.ensureAbsoluteURL(h.config.SiteURL, canonicalPath)
@@ -84,11 +82,13 @@ func (h *TemplateHandler) renderSinglePost(c *gin.Context, post *models.Post) {
 		"TwitterImage":   post.FeaturedImg,
 		"StructuredData": structuredData,
 		"Scripts":        scripts,
+		"Robots":         contentRobots(post.NoIndex, post.NoFollow),
 	})
 
 	if len(keywords) > 0 {
 		data["Keywords"] = strings.Join(keywords, ", ")
 	}
+	h.contentLanguageData(c, data, post.Language, h.postTranslations(post))
 
 	templateName := post.Template
 	if templateName == "" {
@@ -127,7 +127,9 @@ func (h *TemplateHandler) buildPostStructuredData(post *models.Post, site models
 		},
 	}
 
-	if langCode := strings.TrimSpace(site.DefaultLanguage); langCode != "" {
+	if langCode := strings.TrimSpace(post.Language); langCode != "" {
+		article["inLanguage"] = langCode
+	} else if langCode := strings.TrimSpace(site.DefaultLanguage); langCode != "" {
 		article["inLanguage"] = langCode
 	}
 
@@ -201,13 +203,14 @@ func (h *TemplateHandler) renderCustomPage(c *gin.Context, page *models.Page) {
 
 	var contentHTML template.HTML
 	if strings.TrimSpace(page.Content) != "" {
-		contentHTML = template.HTML(page.Content)
+		contentHTML = h.sanitizeHTML(models.SanitizePageContent, page.Content)
 	}
 
 	sectionsHTML, sectionScripts := h.renderSectionsWithPrefix(page.Sections, "page-view", c)
 
 	data := gin.H{
-		"Page": page,
+		"Page":   page,
+		"Robots": contentRobots(page.NoIndex, page.NoFollow),
 	}
 
 	if contentHTML != "" {
@@ -222,11 +225,23 @@ func (h *TemplateHandler) renderCustomPage(c *gin.Context, page *models.Page) {
 		data["Scripts"] = appendScripts(asScriptSlice(data["Scripts"]), sectionScripts)
 	}
 
+	if courses := h.catalogCoursePackages(page.Sections); len(courses) > 0 {
+		if structuredData := h.buildCourseListStructuredData(courses, h.siteSettings()); structuredData != "" {
+			data["StructuredData"] = structuredData
+		}
+	}
+
+	h.contentLanguageData(c, data, page.Language, h.pageTranslations(page))
+	if path := h.localizedPath(page.Language, page.Path); path != page.Path {
+		data["Canonical"] = path
+	}
+
 	templateName := strings.TrimSpace(page.Template)
 	if templateName == "" {
 		templateName = "page"
 	}
 
+	applyPageTheme(page, data)
 	h.renderTemplate(c, templateName, page.Title, page.Description, data)
 }
 
@@ -245,6 +260,25 @@ func (h *TemplateHandler) renderPageByTemplate(c *gin.Context, page *models.Page
 	}
 }
 
+// RenderPageDocument renders page as the public site would serve it at its own
+// path and returns the status code and HTML body instead of writing them to c.
+func (h *TemplateHandler) RenderPageDocument(c *gin.Context, page *models.Page) (int, []byte) {
+	request := c.Request.Clone(c.Request.Context())
+	request.Method = http.MethodGet
+	request.URL.Path, request.URL.RawPath, request.URL.RawQuery = page.Path, "", ""
+	request.RequestURI = page.Path
+
+	recorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(recorder)
+	ctx.Request = request
+	for key, value := range c.Keys {
+		ctx.Set(key, value)
+	}
+
+	h.renderPageByTemplate(ctx, page)
+	return recorder.Code, recorder.Body.Bytes()
+}
+
 func (h *TemplateHandler) renderPageForPath(c *gin.Context, path string) bool {
 	if h.pageService == nil {
 		return false
@@ -253,6 +287,10 @@ func (h *TemplateHandler) renderPageForPath(c *gin.Context, path string) bool {
 	page, err := h.pageService.GetByPath(path)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
+			if moved, ok := h.pageService.ResolveSlugRedirect(path); ok {
+				c.Redirect(http.StatusMovedPermanently, withRawQuery(h.localizedPath(moved.Language, moved.Path), c.Request.URL.RawQuery))
+				return true
+			}
 			return false
 		}
 		logger.Error(err, "Failed to load page", map[string]interface{}{"path": path})
@@ -260,6 +298,13 @@ func (h *TemplateHandler) renderPageForPath(c *gin.Context, path string) bool {
 		return true
 	}
 
+	if target := h.translationRedirect(c, page.Language, page.Path, func() []contentTranslation {
+		return h.pageTranslations(page)
+	}); target != "" {
+		c.Redirect(http.StatusMovedPermanently, withRawQuery(target, c.Request.URL.RawQuery))
+		return true
+	}
+
 	h.renderPageByTemplate(c, page)
 	return true
 }
@@ -272,9 +317,19 @@ func (h *TemplateHandler) TryRenderPage(c *gin.Context) bool {
 	return h.renderPageForPath(c, path)
 }
 
+// withRawQuery carries the query string of the original request over to a redirect.
+func withRawQuery(path, rawQuery string) string {
+	if rawQuery == "" {
+		return path
+	}
+	return path + "?" + rawQuery
+}
+
 func (h *TemplateHandler) RenderIndex(c *gin.Context) {
 	if h.homepageService != nil {
-		page, err := h.homepageService.GetActiveHomepage()
+		// Each language may have its own homepage, picked by the negotiated language.
+		c.Writer.Header().Add("Vary", "Accept-Language")
+		page, err := h.homepageService.GetActiveHomepageForLanguage(c.GetString("language"))
 		if err != nil {
 			logger.Error(err, "Failed to load configured homepage", nil)
 		} else if page != nil {
@@ -309,9 +364,19 @@ func (h *TemplateHandler) RenderPost(c *gin.Context) {
 
 	post, err := h.postService.GetBySlug(param)
 	if err != nil {
+		if renamed, ok := h.postService.ResolveSlugRedirect(param); ok {
+			c.Redirect(http.StatusMovedPermanently, withRawQuery(h.localizedPath(renamed.Language, postPath(*renamed)), c.Request.URL.RawQuery))
+			return
+		}
 		h.renderError(c, http.StatusNotFound, "404 - Page Not Found", "Requested page not found")
 		return
 	}
+	if target := h.translationRedirect(c, post.Language, postPath(*post), func() []contentTranslation {
+		return h.postTranslations(post)
+	}); target != "" {
+		c.Redirect(http.StatusMovedPermanently, withRawQuery(target, c.Request.URL.RawQuery))
+		return
+	}
 	h.renderSinglePost(c, post)
 }
 
@@ -420,6 +485,7 @@ func (h *TemplateHandler) renderBlogWithPage(c *gin.Context, page *models.Page)
 		"CurrentPage": pageNumber,
 		"TotalPages":  totalPages,
 		"Pagination":  pagination,
+		"Robots":      contentRobots(page.NoIndex, page.NoFollow),
 	}
 
 	if len(tags) > 0 {
@@ -431,7 +497,7 @@ func (h *TemplateHandler) renderBlogWithPage(c *gin.Context, page *models.Page)
 	}
 
 	if strings.TrimSpace(page.Content) != "" {
-		data["Content"] = template.HTML(page.Content)
+		data["Content"] = h.sanitizeHTML(models.SanitizePageContent, page.Content)
 	}
 
 	sections, sectionScripts := h.renderSectionsWithPrefix(page.Sections, "blog", c)
@@ -463,6 +529,7 @@ func (h *TemplateHandler) renderBlogWithPage(c *gin.Context, page *models.Page)
 		templateName = "page"
 	}
 
+	applyPageTheme(page, data)
 	h.renderTemplate(c, templateName, title, description, data)
 }
 
@@ -538,9 +605,9 @@ func (h *TemplateHandler) RenderBlog(c *gin.Context) {
 }
 
 func (h *TemplateHandler) renderBlogOverviewSection(posts []models.Post, tags []models.Tag, categories []models.Category, pagination gin.H, extraSections template.HTML) template.HTML {
-	tmpl, err := h.templateClone()
+	tmpl, err := h.templateSet()
 	if err != nil {
-		logger.Error(err, "Failed to clone templates for blog overview", nil)
+		logger.Error(err, "Failed to load templates for blog overview", nil)
 		return extraSections
 	}
 
@@ -902,7 +969,7 @@ func (h *TemplateHandler) RenderForum(c *gin.Context) {
 			"Create": "/api/v1/forum/questions",
 		},
 		"Scripts":                 []string{"/static/js/forum.js"},
-		"Canonical":               h.ensureAbsoluteURL(h.config.SiteURL, canonicalPath),
+		"Canonical":               canonicalPath,
 		"ForumPath":               "/forum",
 		"ForumCategories":         categories,
 		"ForumActiveCategory":     activeCategory,
@@ -969,7 +1036,8 @@ func (h *TemplateHandler) RenderForumQuestion(c *gin.Context) {
 	if slug == "" {
 		canonicalPath = fmt.Sprintf("/forum/%d", question.ID)
 	}
-	canonicalURL := h.ensureAbsoluteURL(h.config.SiteURL, canonicalPath)
+	site := h.siteSettings()
+	canonicalURL := h.ensureAbsoluteURL(site.URL, canonicalPath)
 
 	contentSummary := truncatePlainText(strings.TrimSpace(question.Content), 160)
 	authorName := strings.TrimSpace(question.Author.Username)
@@ -984,7 +1052,6 @@ func (h *TemplateHandler) RenderForumQuestion(c *gin.Context) {
 		description = fmt.Sprintf("%s — %s", authorName, description)
 	}
 
-	site := h.siteSettings()
 	structuredData := h.buildForumStructuredData(question, site, canonicalURL)
 
 	var (
@@ -1192,6 +1259,7 @@ func (h *TemplateHandler) RenderCategory(c *gin.Context) {
 		"Pagination":  pagination,
 		"Category":    category,
 		"Canonical":   fmt.Sprintf("/category/%s", category.Slug),
+		"Robots":      contentRobots(category.NoIndex, category.NoFollow),
 	}
 
 	if len(categories) > 0 {
@@ -1342,10 +1410,29 @@ func (h *TemplateHandler) RenderLogin(c *gin.Context) {
 		redirectTo = "/profile"
 	}
 
+	var providers []models.OAuthProviderInfo
+	if h.authService != nil {
+		if list, err := h.authService.OAuthProviders(); err != nil {
+			logger.Error(err, "Failed to list login providers", nil)
+		} else {
+			providers = list
+		}
+	}
+
+	// OAuth logins that need a second factor come back to this page at the
+	// two-factor step.
+	twoFactorStep := c.Query("two_factor")
+	if twoFactorStep != "verify" && twoFactorStep != "enroll" {
+		twoFactorStep = ""
+	}
+
 	h.renderTemplate(c, "login", "Sign in", "Access your dashboard and manage your content.", gin.H{
-		"AuthAction": "/api/v1/login",
-		"RedirectTo": redirectTo,
-		"NoIndex":    true,
+		"AuthAction":     "/api/v1/login",
+		"RedirectTo":     redirectTo,
+		"OAuthProviders": providers,
+		"OAuthError":     oauthLoginErrors[c.Query("error")],
+		"TwoFactorStep":  twoFactorStep,
+		"NoIndex":        true,
 	})
 }
 
@@ -1426,7 +1513,7 @@ func (h *TemplateHandler) RenderSetupKeyRequired(c *gin.Context) {
 	c.Status(http.StatusOK)
 
 	// Try to load from theme, fallback to inline HTML
-	tmpl, err := h.templateClone()
+	tmpl, err := h.templateSet()
 	if err == nil {
 		if t := tmpl.Lookup("setup_key_required.html"); t != nil {
 			if err := t.Execute(c.Writer, nil); err == nil {
@@ -1823,6 +1910,9 @@ func applyProfileUserContext(sections models.PostSections, user *models.User, co
 				content := ensureContentMap(element)
 				content["action"] = "/api/v1/profile/password"
 				content["username"] = username
+			case "profile_notifications":
+				content := ensureContentMap(element)
+				content["action"] = "/api/v1/profile/notifications"
 			}
 		}
 		section.Elements = elements
@@ -1845,6 +1935,8 @@ func applyProfileTabSetting(section *models.Section) {
 		tab = "courses"
 	case "profile-security":
 		tab = "security"
+	case "profile-notifications":
+		tab = "notifications"
 	}
 
 	if tab == "" {
@@ -1869,6 +1961,7 @@ func buildProfileTabs(h *TemplateHandler, sections models.PostSections, c *gin.C
 	var accountHTML string
 	var securityHTML string
 	var coursesHTML string
+	var notificationsHTML string
 
 	for i := range sections {
 		section := sections[i]
@@ -1894,11 +1987,17 @@ func buildProfileTabs(h *TemplateHandler, sections models.PostSections, c *gin.C
 						securityHTML = html
 					}
 				}
+			case "profile_notifications":
+				if notificationsHTML == "" {
+					if html, _ := h.renderSectionElement(pageViewClassPrefix, elem, c); html != "" {
+						notificationsHTML = html
+					}
+				}
 			}
 		}
 	}
 
-	tabs := make([]profileTab, 0, 3)
+	tabs := make([]profileTab, 0, 4)
 
 	if accountHTML != "" {
 		tabs = append(tabs, profileTab{
@@ -1927,6 +2026,15 @@ func buildProfileTabs(h *TemplateHandler, sections models.PostSections, c *gin.C
 		})
 	}
 
+	if notificationsHTML != "" {
+		tabs = append(tabs, profileTab{
+			ID:          "notifications",
+			Label:       "Notifications",
+			Description: "Which notifications you get by email and on the site.",
+			Content:     template.HTML(notificationsHTML),
+		})
+	}
+
 	return tabs
 }
 
@@ -1988,6 +2096,24 @@ func defaultProfileSections() models.PostSections {
 				},
 			},
 		},
+		{
+			ID:       "profile-notifications",
+			Type:     "profile_notifications",
+			Order:    4,
+			Settings: map[string]interface{}{"profile_tab": "notifications"},
+			Elements: []models.SectionElement{
+				{
+					ID:    "profile-notifications",
+					Type:  "profile_notifications",
+					Order: 1,
+					Content: map[string]interface{}{
+						"title":        "Notifications",
+						"description":  "Choose what we tell you about and where.",
+						"button_label": "Save preferences",
+					},
+				},
+			},
+		},
 	}
 
 	return sections
@@ -2006,6 +2132,11 @@ type checkoutStatusPage struct {
 	Hint      string
 	Primary   checkoutStatusAction
 	Secondary checkoutStatusAction
+	// VerifyURL overrides the endpoint the success page posts the session to.
+	// When set, a download button is revealed if the response carries a link.
+	VerifyURL string
+	// StayOnPage disables the automatic redirect to the homepage.
+	StayOnPage bool
 }
 
 func (h *TemplateHandler) RenderCourseCheckoutSuccess(c *gin.Context) {
@@ -2128,46 +2259,21 @@ func (h *TemplateHandler) RenderCourse(c *gin.Context) {
 		description = strings.TrimSpace(pkg.Description)
 	}
 
+	lessonCount := 0
+	for _, topic := range pkg.Topics {
+		lessonCount += len(topic.Steps)
+	}
+
+	// Only the topic the player opens first is sent in full; the others are
+	// outlines that the player fetches from RenderCourseTopic when opened.
 	var sectionScripts []string
+	initialTopic := initialCourseTopic(pkg.Topics)
 	for topicIndex := range pkg.Topics {
-		topic := &pkg.Topics[topicIndex]
-		for stepIndex := range topic.Steps {
-			step := &topic.Steps[stepIndex]
-			switch step.StepType {
-			case models.CourseTopicStepTypeVideo:
-				if step.Video == nil {
-					continue
-				}
-				sections := step.Video.Sections
-				if len(sections) == 0 {
-					step.Video.SectionsHTML = ""
-					continue
-				}
-				html, scripts := h.renderSectionsWithPrefix(sections, "course-player", c)
-				if html != "" {
-					step.Video.SectionsHTML = string(html)
-				} else {
-					step.Video.SectionsHTML = ""
-				}
-				sectionScripts = appendScripts(sectionScripts, scripts)
-			case models.CourseTopicStepTypeContent:
-				if step.Content == nil {
-					continue
-				}
-				sections := step.Content.Sections
-				if len(sections) == 0 {
-					step.Content.SectionsHTML = ""
-					continue
-				}
-				html, scripts := h.renderSectionsWithPrefix(sections, "course-player", c)
-				if html != "" {
-					step.Content.SectionsHTML = string(html)
-				} else {
-					step.Content.SectionsHTML = ""
-				}
-				sectionScripts = appendScripts(sectionScripts, scripts)
-			}
+		if topicIndex == initialTopic {
+			sectionScripts = h.renderCourseTopicSections(c, &pkg.Topics[topicIndex])
+			continue
 		}
+		pkg.Topics[topicIndex] = courseTopicOutline(pkg.Topics[topicIndex])
 	}
 
 	if h.courseMaterialProtect != nil && h.courseMaterialProtect.Enabled() {
@@ -2186,12 +2292,6 @@ func (h *TemplateHandler) RenderCourse(c *gin.Context) {
 	if slug == "" {
 		canonicalPath = fmt.Sprintf("/courses/%d", pkg.ID)
 	}
-	canonical := h.ensureAbsoluteURL(h.config.SiteURL, canonicalPath)
-
-	lessonCount := 0
-	for _, topic := range pkg.Topics {
-		lessonCount += len(topic.Steps)
-	}
 
 	scripts := appendScripts([]string{"/static/js/course-player.js"}, sectionScripts)
 
@@ -2213,12 +2313,13 @@ func (h *TemplateHandler) RenderCourse(c *gin.Context) {
 		"Course":              course,
 		"CourseJSON":          template.JS(string(payload)),
 		"CourseEndpoint":      courseEndpoint,
+		"CourseTopicEndpoint": canonicalPath + "/topics",
 		"CourseTestEndpoint":  "/api/v1/courses/tests",
 		"CourseTopicCount":    len(pkg.Topics),
 		"CourseLessonCount":   lessonCount,
 		"CourseCanonicalPath": canonicalPath,
 		"Scripts":             scripts,
-		"Canonical":           canonical,
+		"Canonical":           canonicalPath,
 		"NoIndex":             true,
 	}
 
@@ -2319,8 +2420,7 @@ func (h *TemplateHandler) renderArchiveDirectory(c *gin.Context, pathValue strin
 		description = fmt.Sprintf("Browse files available in %s.", trimmedName)
 	}
 
-	canonicalPath := "/archive/" + directory.Path
-	canonical := h.ensureAbsoluteURL(h.config.SiteURL, canonicalPath)
+	canonical := "/archive/" + directory.Path
 
 	data := gin.H{
 		"Directory":      directory,
@@ -2396,8 +2496,7 @@ func (h *TemplateHandler) renderArchiveFile(c *gin.Context, pathValue string) {
 		description = fmt.Sprintf("Download or preview %s.", strings.TrimSpace(file.Name))
 	}
 
-	canonicalPath := "/archive/files/" + file.Path
-	canonical := h.ensureAbsoluteURL(h.config.SiteURL, canonicalPath)
+	canonical := "/archive/files/" + file.Path
 
 	data := gin.H{
 		"File":           file,
@@ -2476,6 +2575,11 @@ func (h *TemplateHandler) RenderAdmin(c *gin.Context) {
 		"BackupImport":        "/api/v1/admin/backups/import",
 	}
 
+	if h.uploadService.DirectUploads() != nil {
+		adminEndpoints["UploadDirect"] = "/api/v1/admin/uploads/direct"
+		adminEndpoints["UploadDirectComplete"] = "/api/v1/admin/uploads/direct/complete"
+	}
+
 	if coursesEnabled {
 		adminEndpoints["CourseVideos"] = "/api/v1/admin/courses/videos"
 		adminEndpoints["CourseContents"] = "/api/v1/admin/courses/contents"
@@ -2543,12 +2647,14 @@ func (h *TemplateHandler) builderScripts() []string {
 			"/static/js/admin/elements/paragraph.js",
 			"/static/js/admin/elements/image.js",
 			"/static/js/admin/elements/image-group.js",
+			"/static/js/admin/elements/embed.js",
 			"/static/js/admin/elements/feature-item.js",
 			"/static/js/admin/elements/file-group.js",
 			"/static/js/admin/elements/list.js",
 			"/static/js/admin/elements/search.js",
 			"/static/js/admin/elements/profile-account-details.js",
 			"/static/js/admin/elements/profile-security.js",
+			"/static/js/admin/elements/profile-notifications.js",
 		)
 	}
 
@@ -2649,6 +2755,13 @@ func (h *TemplateHandler) builderDefinitionsJSON() (template.JS, template.JS) {
 
 			sectionDefs[meta.Type] = def
 		}
+
+		// Theme sections describe their own settings and element support.
+		for sectionType, def := range baseSectionDefs {
+			if h.isThemeSection(sectionType) {
+				sectionDefs[sectionType] = def
+			}
+		}
 	} else if h.themeManager != nil {
 		if active := h.themeManager.Active(); active != nil {
 			if defs := active.SectionDefinitions(); len(defs) > 0 {
@@ -2746,9 +2859,12 @@ func (h *TemplateHandler) renderError(c *gin.Context, status int, title, msg str
 			"FaviconType": site.FaviconType,
 			"Logo":        site.Logo,
 		},
+		"ColorScheme": h.colorSchemeTemplateData(site, nil),
 	}
 
-	tmpl, err := h.templateClone()
+	applyCSPNonce(c, data)
+
+	tmpl, err := h.templateSet()
 	if err != nil {
 		logger.Error(err, "Failed to load error template", nil)
 		c.JSON(status, gin.H{"error": msg})
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *TemplateHandler) RenderProductCheckoutSuccess(c *gin.Context) {
+	hint := "A download link is on its way to your inbox."
+	if contact := strings.TrimSpace(h.siteSettings().ContactEmail); contact != "" {
+		hint = fmt.Sprintf("A download link is on its way to your inbox. If it does not arrive, contact %s.", contact)
+	}
+
+	h.renderCheckoutStatusPage(c, checkoutStatusPage{
+		Status:     "success",
+		Eyebrow:    "Payment confirmed",
+		Title:      "Thank you for your purchase",
+		Message:    "Your download will be ready as soon as the payment is confirmed.",
+		Hint:       hint,
+		VerifyURL:  "/api/v1/products/checkout/verify",
+		StayOnPage: true,
+		Secondary: checkoutStatusAction{
+			Label: "Back to homepage",
+			Href:  "/",
+		},
+	})
+}
+
+func (h *TemplateHandler) RenderProductCheckoutCancel(c *gin.Context) {
+	h.renderCheckoutStatusPage(c, checkoutStatusPage{
+		Status:  "cancel",
+		Eyebrow: "Checkout cancelled",
+		Title:   "Payment not completed",
+		Message: "You can restart checkout whenever you are ready.",
+		Hint:    "No charges were made.",
+		Primary: checkoutStatusAction{
+			Label: "Back to homepage",
+			Href:  "/",
+		},
+	})
+}
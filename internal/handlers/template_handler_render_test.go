@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"constructor-script-backend/internal/config"
+	"constructor-script-backend/internal/theme"
+)
+
+func newBenchmarkTemplateHandler(tb testing.TB) *TemplateHandler {
+	tb.Helper()
+	manager, err := theme.NewManager(filepath.Join("..", "..", "themes"))
+	if err != nil {
+		tb.Fatalf("load themes: %v", err)
+	}
+	if err := manager.Activate("default"); err != nil {
+		tb.Fatalf("activate theme: %v", err)
+	}
+	return &TemplateHandler{themeManager: manager, config: &config.Config{SiteName: "Example"}}
+}
+
+func TestTemplateSetIsSharedBetweenRenders(t *testing.T) {
+	handler := newBenchmarkTemplateHandler(t)
+
+	first, err := handler.templateSet()
+	if err != nil {
+		t.Fatalf("template set: %v", err)
+	}
+	second, err := handler.templateSet()
+	if err != nil {
+		t.Fatalf("template set: %v", err)
+	}
+	if first != second {
+		t.Fatal("expected the compiled set to be reused")
+	}
+
+	handler.SetTemplateOverrides(nil)
+	reloaded, err := handler.templateSet()
+	if err != nil {
+		t.Fatalf("template set: %v", err)
+	}
+	if reloaded == first {
+		t.Fatal("expected invalidation to compile a new set")
+	}
+}
+
+func BenchmarkRenderTemplate(b *testing.B) {
+	gin.SetMode(gin.TestMode)
+	handler := newBenchmarkTemplateHandler(b)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		recorder := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(recorder)
+		c.Request = httptest.NewRequest(http.MethodGet, "/login", nil)
+		handler.renderTemplate(c, "login", "Sign in", "", nil)
+		if recorder.Code != http.StatusOK {
+			b.Fatalf("unexpected status %d", recorder.Code)
+		}
+	}
+}
+
+type staticTemplateOverrides map[string]string
+
+func (o staticTemplateOverrides) TemplateOverrides(string) (map[string]string, error) {
+	return o, nil
+}
+
+func TestBrokenTemplateOverrideKeepsThemeTemplate(t *testing.T) {
+	handler := newBenchmarkTemplateHandler(t)
+	base, err := handler.templateSet()
+	if err != nil {
+		t.Fatalf("template set: %v", err)
+	}
+	original := base.Lookup("login.html")
+	if original == nil || original.Tree == nil {
+		t.Fatal("expected the theme to define login.html")
+	}
+
+	handler.SetTemplateOverrides(staticTemplateOverrides{
+		"login.html":  "{{ if }",
+		"broken.html": "{{ end }}",
+		"extra.html":  "<p>extra</p>",
+	})
+	templates, err := handler.templateSet()
+	if err != nil {
+		t.Fatalf("template set: %v", err)
+	}
+
+	login := templates.Lookup("login.html")
+	if login == nil || login.Tree == nil || login.Tree.Root.String() != original.Tree.Root.String() {
+		t.Fatal("expected a broken override to leave the theme template in place")
+	}
+	if templates.Lookup("broken.html") != nil {
+		t.Fatal("expected a broken override not to register its name")
+	}
+	if templates.Lookup("extra.html") == nil {
+		t.Fatal("expected a valid override to be applied")
+	}
+}
@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"html/template"
+
+	"constructor-script-backend/internal/service"
+)
+
+// SetSanitizationService sets the policies stored markup is cleaned with
+// before it is rendered. Without it the default policies apply.
+func (h *TemplateHandler) SetSanitizationService(sanitization *service.SanitizationService) {
+	if h == nil {
+		return
+	}
+	h.sanitization = sanitization
+}
+
+// sanitizeHTML cleans input with the policy of context, for inclusion in a
+// template.
+func (h *TemplateHandler) sanitizeHTML(context, input string) template.HTML {
+	return template.HTML(h.sanitization.Sanitize(context, input))
+}
+
+// addSanitizeFuncs lets templates clean stored markup, for example
+// {{ sanitize "forum" .Content }}.
+func (h *TemplateHandler) addSanitizeFuncs(funcs template.FuncMap) {
+	if funcs == nil {
+		return
+	}
+	funcs["sanitize"] = h.sanitizeHTML
+}
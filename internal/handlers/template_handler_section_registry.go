@@ -68,3 +68,17 @@ func (h *TemplateHandler) SectionMetadata() []sections.SectionMetadata {
 
 	return nil
 }
+
+// UnregisterSection removes a runtime-registered section and its metadata.
+func (h *TemplateHandler) UnregisterSection(sectionType string) {
+	if h == nil || h.sectionRegistry == nil {
+		return
+	}
+
+	switch reg := h.sectionRegistry.(type) {
+	case *sections.RegistryWithMetadata:
+		reg.Unregister(sectionType)
+	case *sections.Registry:
+		reg.Unregister(sectionType)
+	}
+}
@@ -584,9 +584,9 @@ func (h *TemplateHandler) renderOwnedCoursesList(prefix string, section models.S
 		containerID = fmt.Sprintf("%s-courses", prefix)
 	}
 
-	tmpl, err := h.templateClone()
+	tmpl, err := h.templateSet()
 	if err != nil {
-		logger.Error(err, "Failed to clone templates for owned course list", map[string]interface{}{"section_id": section.ID})
+		logger.Error(err, "Failed to load templates for owned course list", map[string]interface{}{"section_id": section.ID})
 		return `<p class="profile-courses__empty courses-list__empty">` + template.HTMLEscapeString(emptyMessage) + `</p>`
 	}
 
@@ -719,9 +719,9 @@ func (h *TemplateHandler) renderCourseListContent(prefix string, section models.
 		return `<p class="` + emptyClass + `">No courses available yet. Check back soon!</p>`
 	}
 
-	tmpl, err := h.templateClone()
+	tmpl, err := h.templateSet()
 	if err != nil {
-		logger.Error(err, "Failed to clone templates for course list section", map[string]interface{}{"section_id": section.ID})
+		logger.Error(err, "Failed to load templates for course list section", map[string]interface{}{"section_id": section.ID})
 		return `<p class="` + emptyClass + `">Unable to display courses at the moment.</p>`
 	}
 
@@ -924,9 +924,9 @@ func (h *TemplateHandler) renderPostListContent(prefix string, posts []models.Po
 		return `<p class="` + emptyClass + `">No posts available yet. Check back soon!</p>`
 	}
 
-	tmpl, err := h.templateClone()
+	tmpl, err := h.templateSet()
 	if err != nil {
-		logger.Error(err, "Failed to clone templates for post list section", map[string]interface{}{"prefix": prefix})
+		logger.Error(err, "Failed to load templates for post list section", map[string]interface{}{"prefix": prefix})
 		return `<p class="` + emptyClass + `">Unable to display posts at the moment.</p>`
 	}
 
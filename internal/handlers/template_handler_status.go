@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"net/http"
+
+	"constructor-script-backend/internal/service"
+	"constructor-script-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetStatusService enables the public status page.
+func (h *TemplateHandler) SetStatusService(statusService *service.StatusService) {
+	if h == nil {
+		return
+	}
+	h.statusService = statusService
+}
+
+// RenderStatus renders the public status page with component health and incidents.
+func (h *TemplateHandler) RenderStatus(c *gin.Context) {
+	if h.statusService == nil {
+		h.renderError(c, http.StatusNotFound, "Page not found", "The status page is not available.")
+		return
+	}
+
+	status, err := h.statusService.Current(c.Request.Context())
+	if err != nil {
+		logger.ErrorContext(c.Request.Context(), err, "Failed to load site status", nil)
+		h.renderError(c, http.StatusInternalServerError, "Status unavailable", "We couldn't load the site status right now.")
+		return
+	}
+
+	c.Header("Cache-Control", "no-cache")
+	h.renderTemplate(c, "status", "System status", "Current availability of the site and recent incidents.", gin.H{
+		"SiteStatus": status,
+		"Styles":     []string{"/static/css/sections/status.css"},
+		"Canonical":  "/status",
+	})
+}
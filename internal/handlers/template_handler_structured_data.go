@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"strings"
+
+	"constructor-script-backend/internal/constants"
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// buildSiteStructuredData describes the site owner as an Organization. On the
+// homepage it also emits a WebSite node with a SearchAction so search engines can
+// offer a sitelinks search box.
+func (h *TemplateHandler) buildSiteStructuredData(site gin.H, siteURL string, homepage bool) template.JS {
+	name := strings.TrimSpace(getString(site, "Name"))
+	siteURL = strings.TrimSuffix(strings.TrimSpace(siteURL), "/")
+
+	organization := map[string]interface{}{
+		"@type": "Organization",
+		"name":  name,
+	}
+	if siteURL != "" {
+		organization["@id"] = siteURL + "/#organization"
+		organization["url"] = siteURL + "/"
+	}
+	if logo := strings.TrimSpace(getString(site, "Logo")); logo != "" {
+		organization["logo"] = logo
+	}
+	if links, ok := site["SocialLinks"].([]models.SocialLink); ok {
+		var profiles []string
+		for _, link := range links {
+			if url := strings.TrimSpace(link.URL); strings.HasPrefix(url, "http") {
+				profiles = append(profiles, url)
+			}
+		}
+		if len(profiles) > 0 {
+			organization["sameAs"] = profiles
+		}
+	}
+
+	payload := map[string]interface{}{"@context": "https://schema.org"}
+	if homepage && siteURL != "" {
+		website := map[string]interface{}{
+			"@type":     "WebSite",
+			"@id":       siteURL + "/#website",
+			"name":      name,
+			"url":       siteURL + "/",
+			"publisher": map[string]interface{}{"@id": siteURL + "/#organization"},
+			"potentialAction": map[string]interface{}{
+				"@type":       "SearchAction",
+				"target":      siteURL + "/search?q={search_term_string}",
+				"query-input": "required name=search_term_string",
+			},
+		}
+		if description := strings.TrimSpace(getString(site, "Description")); description != "" {
+			website["description"] = description
+		}
+		if language := strings.TrimSpace(getString(site, "DefaultLanguage")); language != "" {
+			website["inLanguage"] = language
+		}
+		payload["@graph"] = []interface{}{organization, website}
+	} else {
+		for key, value := range organization {
+			payload[key] = value
+		}
+	}
+
+	dataBytes, err := json.Marshal(payload)
+	if err != nil {
+		logger.Error(err, "Failed to marshal site structured data", nil)
+		return ""
+	}
+	return template.JS(dataBytes)
+}
+
+// buildCourseListStructuredData describes the public course catalog shown on a page
+// as an ItemList of courses. Paid courses are also typed as Product and carry an
+// Offer, which makes them eligible for course and product rich results.
+func (h *TemplateHandler) buildCourseListStructuredData(packages []models.CoursePackage, site models.SiteSettings) template.JS {
+	if len(packages) == 0 {
+		return ""
+	}
+
+	baseURL := site.URL
+	if baseURL == "" {
+		baseURL = h.config.SiteURL
+	}
+	currency := strings.ToUpper(strings.TrimSpace(site.CourseCheckoutCurrency))
+	if currency == "" {
+		currency = "USD"
+	}
+
+	provider := map[string]interface{}{
+		"@type": "Organization",
+		"name":  site.Name,
+	}
+	if baseURL != "" {
+		provider["sameAs"] = h.ensureAbsoluteURL(baseURL, "/")
+	}
+
+	items := make([]interface{}, 0, len(packages))
+	for index, pkg := range packages {
+		description := strings.TrimSpace(pkg.MetaDescription)
+		if description == "" {
+			description = strings.TrimSpace(pkg.Summary)
+		}
+		if description == "" {
+			description = truncatePlainText(strings.TrimSpace(pkg.Description), 300)
+		}
+		if description == "" {
+			description = pkg.Title
+		}
+
+		course := map[string]interface{}{
+			"@type":       "Course",
+			"name":        pkg.Title,
+			"description": description,
+			"provider":    provider,
+		}
+		if pkg.Slug != "" {
+			course["url"] = h.ensureAbsoluteURL(baseURL, fmt.Sprintf("/courses/%s", pkg.Slug))
+		}
+		if image := h.ensureAbsoluteURL(baseURL, pkg.ImageURL); image != "" {
+			course["image"] = image
+		}
+
+		offer := map[string]interface{}{
+			"@type":         "Offer",
+			"category":      "Paid",
+			"price":         fmt.Sprintf("%.2f", float64(pkg.EffectivePriceCents())/100),
+			"priceCurrency": currency,
+			"availability":  "https://schema.org/InStock",
+		}
+		if pkg.EffectivePriceCents() <= 0 {
+			offer["category"] = "Free"
+		} else {
+			course["@type"] = []string{"Course", "Product"}
+		}
+		if url, ok := course["url"]; ok {
+			offer["url"] = url
+		}
+		course["offers"] = offer
+
+		items = append(items, map[string]interface{}{
+			"@type":    "ListItem",
+			"position": index + 1,
+			"item":     course,
+		})
+	}
+
+	dataBytes, err := json.Marshal(map[string]interface{}{
+		"@context":        "https://schema.org",
+		"@type":           "ItemList",
+		"itemListElement": items,
+	})
+	if err != nil {
+		logger.Error(err, "Failed to marshal course structured data", nil)
+		return ""
+	}
+	return template.JS(dataBytes)
+}
+
+// catalogCoursePackages returns the courses shown by the catalog course lists in
+// sections, in order and without duplicates.
+func (h *TemplateHandler) catalogCoursePackages(sections models.PostSections) []models.CoursePackage {
+	if h.coursePackageSvc == nil || !h.coursesEnabled() {
+		return nil
+	}
+
+	var catalogs []models.Section
+	for _, section := range sections {
+		if strings.TrimSpace(strings.ToLower(section.Type)) != "courses_list" {
+			continue
+		}
+		if mode := strings.TrimSpace(strings.ToLower(section.Mode)); mode != "" && mode != constants.CourseListModeCatalog {
+			continue
+		}
+		catalogs = append(catalogs, section)
+	}
+	if len(catalogs) == 0 {
+		return nil
+	}
+
+	packages, err := h.coursePackageSvc.List()
+	if err != nil {
+		logger.Error(err, "Failed to load course packages for structured data", nil)
+		return nil
+	}
+
+	seen := make(map[uint]struct{}, len(packages))
+	var shown []models.CoursePackage
+	for _, section := range catalogs {
+		settings := parseCourseListSettings(section)
+		listed := packages
+		switch settings.DisplayMode {
+		case constants.CourseListDisplaySelected:
+			listed = filterSelectedPackages(packages, settings.SelectedIdentifiers)
+		case constants.CourseListDisplayCarousel:
+			if selected := filterSelectedPackages(packages, settings.SelectedIdentifiers); len(selected) > 0 {
+				listed = selected
+			}
+		}
+		for _, pkg := range listed {
+			if _, exists := seen[pkg.ID]; exists {
+				continue
+			}
+			seen[pkg.ID] = struct{}{}
+			shown = append(shown, pkg)
+		}
+	}
+	return shown
+}
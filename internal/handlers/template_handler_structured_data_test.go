@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"constructor-script-backend/internal/config"
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/service"
+)
+
+func TestBuildSiteStructuredData(t *testing.T) {
+	handler := &TemplateHandler{config: &config.Config{}}
+	site := gin.H{"Name": "Example", "Logo": "https://example.com/logo.svg"}
+
+	homepage := string(handler.buildSiteStructuredData(site, "https://example.com/", true))
+	if problems := service.ValidateStructuredData(homepage); len(problems) > 0 {
+		t.Fatalf("invalid homepage structured data %s: %v", homepage, problems)
+	}
+	if !strings.Contains(homepage, `"SearchAction"`) || !strings.Contains(homepage, "https://example.com/search?q={search_term_string}") {
+		t.Fatalf("expected a search action on the homepage, got %s", homepage)
+	}
+
+	other := string(handler.buildSiteStructuredData(site, "https://example.com", false))
+	if problems := service.ValidateStructuredData(other); len(problems) > 0 || strings.Contains(other, "WebSite") {
+		t.Fatalf("expected only an organization, got %s (%v)", other, problems)
+	}
+}
+
+func TestBuildCourseListStructuredData(t *testing.T) {
+	handler := &TemplateHandler{config: &config.Config{}}
+	discount := int64(1500)
+	packages := []models.CoursePackage{
+		{Title: "Go basics", Slug: "go-basics", Summary: "Learn Go", PriceCents: 2500, DiscountPriceCents: &discount, ImageURL: "/uploads/go.png"},
+		{Title: "Free intro", Slug: "intro"},
+	}
+	site := models.SiteSettings{Name: "Example", URL: "https://example.com", CourseCheckoutCurrency: "eur"}
+
+	payload := string(handler.buildCourseListStructuredData(packages, site))
+	if problems := service.ValidateStructuredData(payload); len(problems) > 0 {
+		t.Fatalf("invalid course structured data %s: %v", payload, problems)
+	}
+
+	var list struct {
+		Items []struct {
+			Item struct {
+				Type   interface{}            `json:"@type"`
+				URL    string                 `json:"url"`
+				Image  string                 `json:"image"`
+				Offers map[string]interface{} `json:"offers"`
+			} `json:"item"`
+		} `json:"itemListElement"`
+	}
+	if err := json.Unmarshal([]byte(payload), &list); err != nil || len(list.Items) != 2 {
+		t.Fatalf("unexpected payload %s: %v", payload, err)
+	}
+	paid, free := list.Items[0].Item, list.Items[1].Item
+	if paid.URL != "https://example.com/courses/go-basics" || paid.Image != "https://example.com/uploads/go.png" {
+		t.Fatalf("unexpected paid course: %+v", paid)
+	}
+	if paid.Offers["price"] != "15.00" || paid.Offers["priceCurrency"] != "EUR" || paid.Offers["category"] != "Paid" {
+		t.Fatalf("unexpected paid offer: %+v", paid.Offers)
+	}
+	if _, multi := paid.Type.([]interface{}); !multi || free.Type != "Course" || free.Offers["category"] != "Free" {
+		t.Fatalf("unexpected course types: %v, %v", paid.Type, free.Type)
+	}
+}
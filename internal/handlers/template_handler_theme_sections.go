@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"bytes"
+	"html/template"
+	"strings"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/sections"
+	"constructor-script-backend/internal/theme"
+	"constructor-script-backend/pkg/logger"
+)
+
+const themeSectionCategory = "theme"
+
+// themeSectionTemplateData is passed to section templates shipped by a theme.
+type themeSectionTemplateData struct {
+	Prefix   string
+	Type     string
+	Section  models.Section
+	Settings map[string]interface{}
+	Elements []template.HTML
+	Content  template.HTML
+}
+
+// parseThemeSectionTemplates adds the template files declared by custom theme
+// sections to the page template set.
+func (h *TemplateHandler) parseThemeSectionTemplates(templates *template.Template, active *theme.Theme) {
+	for _, definition := range active.CustomSections() {
+		name := theme.SectionTemplateName(definition)
+		source, err := active.ReadSectionTemplate(definition)
+		if err != nil || name == "" {
+			logger.Error(err, "Failed to read theme section template", map[string]interface{}{
+				"theme":   active.Slug,
+				"section": definition.Type,
+			})
+			continue
+		}
+
+		if _, err := templates.New(name).Parse(source); err != nil {
+			logger.Error(err, "Failed to parse theme section template", map[string]interface{}{
+				"theme":    active.Slug,
+				"section":  definition.Type,
+				"template": name,
+			})
+		}
+	}
+}
+
+// syncThemeSections registers renderers for the custom sections of the active theme
+// and removes the ones contributed by a previously active theme. Built-in renderers
+// are never replaced.
+func (h *TemplateHandler) syncThemeSections(active *theme.Theme) {
+	if h == nil {
+		return
+	}
+
+	h.themeSectionsMu.Lock()
+	defer h.themeSectionsMu.Unlock()
+
+	if h.sectionRegistry == nil {
+		h.sectionRegistry = sections.DefaultRegistryWithMetadata()
+	}
+
+	if remover, ok := h.sectionRegistry.(interface{ Unregister(string) }); ok {
+		for sectionType := range h.themeSectionTypes {
+			remover.Unregister(sectionType)
+		}
+	}
+	h.themeSectionTypes = make(map[string]struct{})
+
+	for _, definition := range active.CustomSections() {
+		sectionType := strings.TrimSpace(strings.ToLower(definition.Type))
+		if _, exists := h.sectionRegistry.Get(sectionType); exists {
+			logger.Warn("Theme section conflicts with a built-in renderer", map[string]interface{}{
+				"theme":   active.Slug,
+				"section": sectionType,
+			})
+			continue
+		}
+
+		label := strings.TrimSpace(definition.Label)
+		if label == "" {
+			label = sectionType
+		}
+
+		desc := &sections.SectionDescriptor{
+			Renderer: h.themeSectionRenderer(definition),
+			Metadata: sections.SectionMetadata{
+				Type:        sectionType,
+				Name:        label,
+				Description: definition.Description,
+				Category:    themeSectionCategory,
+			},
+		}
+
+		var err error
+		if _, ok := h.sectionRegistry.(*sections.RegistryWithMetadata); ok {
+			err = h.RegisterSectionWithMetadata(desc)
+		} else {
+			err = h.sectionRegistry.Register(sectionType, desc.Renderer)
+		}
+		if err != nil {
+			logger.Error(err, "Failed to register theme section", map[string]interface{}{
+				"theme":   active.Slug,
+				"section": sectionType,
+			})
+			continue
+		}
+		h.themeSectionTypes[sectionType] = struct{}{}
+	}
+}
+
+// isThemeSection reports whether the section type is rendered from a theme template.
+func (h *TemplateHandler) isThemeSection(sectionType string) bool {
+	if h == nil {
+		return false
+	}
+
+	h.themeSectionsMu.Lock()
+	defer h.themeSectionsMu.Unlock()
+	_, ok := h.themeSectionTypes[strings.TrimSpace(strings.ToLower(sectionType))]
+	return ok
+}
+
+func (h *TemplateHandler) themeSectionRenderer(definition theme.SectionDefinition) sections.Renderer {
+	name := theme.SectionTemplateName(definition)
+	scripts := append([]string(nil), definition.Scripts...)
+
+	return func(ctx sections.RenderContext, prefix string, elem models.SectionElement) (string, []string) {
+		var section models.Section
+		switch value := elem.Content.(type) {
+		case models.Section:
+			section = value
+		case *models.Section:
+			if value == nil {
+				return "", nil
+			}
+			section = *value
+		default:
+			return "", nil
+		}
+
+		tmpl, err := ctx.Templates()
+		if err != nil {
+			logger.Error(err, "Failed to load templates for theme section", map[string]interface{}{"section": definition.Type})
+			return "", nil
+		}
+
+		data := themeSectionTemplateData{
+			Prefix:   prefix,
+			Type:     definition.Type,
+			Section:  section,
+			Settings: section.Settings,
+		}
+
+		resultScripts := scripts
+		var content strings.Builder
+		for _, child := range section.Elements {
+			html, childScripts := h.renderSectionElement(prefix, child, nil)
+			resultScripts = appendScripts(resultScripts, childScripts)
+			if html == "" {
+				continue
+			}
+			data.Elements = append(data.Elements, template.HTML(html))
+			content.WriteString(html)
+		}
+		data.Content = template.HTML(content.String())
+
+		var buf bytes.Buffer
+		if err := tmpl.ExecuteTemplate(&buf, name, data); err != nil {
+			logger.Error(err, "Failed to render theme section", map[string]interface{}{
+				"section":  definition.Type,
+				"template": name,
+			})
+			return "", nil
+		}
+
+		return buf.String(), resultScripts
+	}
+}
@@ -0,0 +1,276 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/pkg/lang"
+	"constructor-script-backend/pkg/logger"
+)
+
+// contentTranslation is one language version of a post or page. An empty Language
+// means the content is written in the site default language.
+type contentTranslation struct {
+	Language string
+	Path     string
+}
+
+// languageAlternate is rendered as <link rel="alternate" hreflang="..."> in the
+// document head.
+type languageAlternate struct {
+	Lang string
+	URL  string
+}
+
+// languageLink is one entry of the language switcher themes render from
+// .LanguageLinks. Translated is false when the content has no version in the
+// language and the link shows it with the chrome of that language instead.
+type languageLink struct {
+	Code       string
+	Name       string
+	URL        string
+	Current    bool
+	Translated bool
+}
+
+func postPath(post models.Post) string {
+	if post.Slug == "" {
+		return fmt.Sprintf("/blog/post/%d", post.ID)
+	}
+	return fmt.Sprintf("/blog/post/%s", post.Slug)
+}
+
+// postTranslations lists the published versions of post, including post itself,
+// when the language plugin is active and the post belongs to a translation group.
+func (h *TemplateHandler) postTranslations(post *models.Post) []contentTranslation {
+	if h.languageService == nil || h.postService == nil || post == nil || post.TranslationKey == "" {
+		return nil
+	}
+
+	posts, err := h.postService.GetTranslations(post.TranslationKey)
+	if err != nil {
+		logger.Error(err, "Failed to load post translations", map[string]interface{}{"post_id": post.ID})
+		return nil
+	}
+
+	translations := make([]contentTranslation, 0, len(posts))
+	for _, translation := range posts {
+		translations = append(translations, contentTranslation{Language: translation.Language, Path: h.localizedPath(translation.Language, postPath(translation))})
+	}
+	return translations
+}
+
+// pageTranslations lists the published versions of page, including page itself.
+func (h *TemplateHandler) pageTranslations(page *models.Page) []contentTranslation {
+	if h.languageService == nil || h.pageService == nil || page == nil || page.TranslationKey == "" {
+		return nil
+	}
+
+	pages, err := h.pageService.GetTranslations(page.TranslationKey)
+	if err != nil {
+		logger.Error(err, "Failed to load page translations", map[string]interface{}{"page_id": page.ID})
+		return nil
+	}
+
+	translations := make([]contentTranslation, 0, len(pages))
+	for _, translation := range pages {
+		translations = append(translations, contentTranslation{Language: translation.Language, Path: h.localizedPath(translation.Language, translation.Path)})
+	}
+	return translations
+}
+
+// buildLanguageAlternates turns translations into absolute hreflang links plus an
+// x-default entry pointing at the default-language version. A single language has
+// nothing to alternate with, so no links are produced.
+func (h *TemplateHandler) buildLanguageAlternates(siteURL, defaultLanguage string, translations []contentTranslation, req *http.Request) []languageAlternate {
+	if len(translations) < 2 {
+		return nil
+	}
+
+	defaultLanguage = strings.TrimSpace(defaultLanguage)
+	if defaultLanguage == "" {
+		defaultLanguage = lang.Default
+	}
+
+	alternates := make([]languageAlternate, 0, len(translations)+1)
+	seen := make(map[string]struct{}, len(translations))
+	xDefault := ""
+	for _, translation := range translations {
+		code := translation.Language
+		if code == "" {
+			code = defaultLanguage
+		}
+		if _, exists := seen[code]; exists {
+			continue
+		}
+		seen[code] = struct{}{}
+
+		href := h.resolveAbsoluteURL(siteURL, translation.Path, req)
+		alternates = append(alternates, languageAlternate{Lang: code, URL: href})
+		if code == defaultLanguage {
+			xDefault = href
+		}
+	}
+
+	if len(alternates) < 2 {
+		return nil
+	}
+	if xDefault != "" {
+		alternates = append(alternates, languageAlternate{Lang: "x-default", URL: xDefault})
+	}
+	return alternates
+}
+
+// pathTranslations lists path under every supported language. Listings, archives
+// and other pages that are not a post or page differ only in their chrome, so each
+// language prefix is a translation of its own.
+func (h *TemplateHandler) pathTranslations(path string) []contentTranslation {
+	defaultLanguage, supported, ok := h.contentLanguages()
+	if !ok || len(supported) < 2 {
+		return nil
+	}
+
+	translations := make([]contentTranslation, 0, len(supported))
+	for _, code := range supported {
+		translations = append(translations, contentTranslation{Language: code, Path: lang.LocalizePath(path, code, defaultLanguage)})
+	}
+	return translations
+}
+
+// languageLinks lists every supported language with the URL of the current page
+// in it: the translation where there is one, otherwise the current path under
+// that language's prefix.
+func (h *TemplateHandler) languageLinks(path, current string, translations []contentTranslation) []languageLink {
+	defaultLanguage, supported, ok := h.contentLanguages()
+	if !ok || len(supported) < 2 {
+		return nil
+	}
+
+	paths := make(map[string]string, len(translations))
+	for _, translation := range translations {
+		code := translation.Language
+		if code == "" {
+			code = defaultLanguage
+		}
+		if _, exists := paths[code]; !exists {
+			paths[code] = translation.Path
+		}
+	}
+
+	links := make([]languageLink, 0, len(supported))
+	for _, code := range supported {
+		url, translated := paths[code]
+		if !translated {
+			url = lang.LocalizePath(path, code, defaultLanguage)
+		}
+		links = append(links, languageLink{
+			Code:       code,
+			Name:       lang.DisplayName(code),
+			URL:        url,
+			Current:    code == current,
+			Translated: translated,
+		})
+	}
+	return links
+}
+
+// contentLanguageData records the language of a post or page and its translations
+// for applySEOMetadata. When the URL asked for a language the content has no
+// translation in, TranslationFallback names that language so themes can say the
+// page is shown in another language.
+func (h *TemplateHandler) contentLanguageData(c *gin.Context, data gin.H, language string, translations []contentTranslation) {
+	if language = strings.TrimSpace(language); language != "" {
+		data["Language"] = language
+	}
+	// Set even when empty: it marks the page as a post or page, whose other
+	// languages are its translations rather than the same path under a prefix.
+	data["Translations"] = translations
+
+	if requested := lang.PathLanguage(c.Request.Context()); requested != "" {
+		defaultLanguage, _, _ := h.contentLanguages()
+		if language == "" {
+			language = defaultLanguage
+		}
+		if requested != language {
+			data["TranslationFallback"] = requested
+		}
+	}
+}
+
+// contentLanguages returns the default and supported languages. ok is false when
+// the language plugin is inactive, in which case content is never localized.
+func (h *TemplateHandler) contentLanguages() (string, []string, bool) {
+	if h.languageService == nil {
+		return "", nil, false
+	}
+	defaultLanguage, supported, err := h.languageService.Resolve("", nil)
+	if err != nil {
+		logger.Error(err, "Failed to resolve site languages", nil)
+	}
+	return defaultLanguage, supported, true
+}
+
+// localizedPath returns the public path of content written in language: paths of
+// non-default languages carry a language prefix, e.g. /es/blog/post/hola.
+func (h *TemplateHandler) localizedPath(language, path string) string {
+	defaultLanguage, _, ok := h.contentLanguages()
+	if !ok {
+		return path
+	}
+	return lang.LocalizePath(path, strings.TrimSpace(language), defaultLanguage)
+}
+
+// translationRedirect applies the routing rules for translated content and returns
+// the path the request should be redirected to, or "" to render the content:
+//
+//   - without a language prefix, default-language content is rendered and other
+//     content is redirected to its prefixed URL;
+//   - with a prefix matching the content's language, the content is rendered;
+//   - otherwise the request goes to the translation that best matches the prefix
+//     (exact language, then same base language);
+//   - when there is none, the content is rendered as a fallback and its canonical
+//     link points at its own URL.
+func (h *TemplateHandler) translationRedirect(c *gin.Context, language, path string, translations func() []contentTranslation) string {
+	defaultLanguage, _, ok := h.contentLanguages()
+	if !ok {
+		return ""
+	}
+	if language = strings.TrimSpace(language); language == "" {
+		language = defaultLanguage
+	}
+
+	requested := lang.PathLanguage(c.Request.Context())
+	if requested == "" {
+		if language == defaultLanguage {
+			return ""
+		}
+		return lang.LocalizePath(path, language, defaultLanguage)
+	}
+	if requested == language {
+		return ""
+	}
+
+	available := translations()
+	codes := make([]string, 0, len(available))
+	for _, translation := range available {
+		code := translation.Language
+		if code == "" {
+			code = defaultLanguage
+		}
+		codes = append(codes, code)
+	}
+	match := lang.Match(requested, codes)
+	if match == "" || match == language {
+		return ""
+	}
+	for i, code := range codes {
+		if code == match {
+			return available[i].Path
+		}
+	}
+	return ""
+}
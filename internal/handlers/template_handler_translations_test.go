@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"constructor-script-backend/internal/config"
+	"constructor-script-backend/pkg/lang"
+	languageservice "constructor-script-backend/plugins/language/service"
+)
+
+func TestTranslationRedirect(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := &TemplateHandler{
+		languageService: languageservice.NewLanguageService(&config.Config{
+			DefaultLanguage:    "en",
+			SupportedLanguages: []string{"en", "es", "fr"},
+		}, nil),
+	}
+	translations := func() []contentTranslation {
+		return []contentTranslation{
+			{Language: "", Path: "/blog/post/hello"},
+			{Language: "es", Path: "/es/blog/post/hola"},
+		}
+	}
+
+	cases := []struct {
+		name      string
+		requested string
+		language  string
+		path      string
+		expected  string
+		fallback  bool
+	}{
+		{name: "default content without prefix", language: "", path: "/blog/post/hello", expected: ""},
+		{name: "translation without prefix", language: "es", path: "/blog/post/hola", expected: "/es/blog/post/hola"},
+		{name: "prefix matches content", requested: "es", language: "es", path: "/blog/post/hola", expected: ""},
+		{name: "prefix selects translation", requested: "es", language: "", path: "/blog/post/hello", expected: "/es/blog/post/hola", fallback: true},
+		{name: "missing translation falls back", requested: "fr", language: "", path: "/blog/post/hello", expected: "", fallback: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			if tc.requested != "" {
+				req = req.WithContext(lang.WithPathLanguage(req.Context(), tc.requested))
+			}
+			ctx.Request = req
+
+			if got := handler.translationRedirect(ctx, tc.language, tc.path, translations); got != tc.expected {
+				t.Fatalf("expected %q, got %q", tc.expected, got)
+			}
+
+			data := gin.H{}
+			handler.contentLanguageData(ctx, data, tc.language, nil)
+			if _, fallback := data["TranslationFallback"]; fallback != tc.fallback {
+				t.Fatalf("unexpected fallback flag %v", data["TranslationFallback"])
+			}
+		})
+	}
+}
+
+func TestLanguageLinks(t *testing.T) {
+	handler := &TemplateHandler{
+		languageService: languageservice.NewLanguageService(&config.Config{
+			DefaultLanguage:    "en",
+			SupportedLanguages: []string{"en", "es", "fr"},
+		}, nil),
+	}
+
+	links := handler.languageLinks("/blog/post/hello", "en", []contentTranslation{
+		{Language: "", Path: "/blog/post/hello"},
+		{Language: "es", Path: "/es/blog/post/hola"},
+	})
+	expected := []languageLink{
+		{Code: "en", Name: "English", URL: "/blog/post/hello", Current: true, Translated: true},
+		{Code: "es", Name: "español", URL: "/es/blog/post/hola", Translated: true},
+		{Code: "fr", Name: "français", URL: "/fr/blog/post/hello"},
+	}
+	if len(links) != len(expected) {
+		t.Fatalf("expected %d links, got %+v", len(expected), links)
+	}
+	for i := range expected {
+		if links[i] != expected[i] {
+			t.Fatalf("link %d: expected %+v, got %+v", i, expected[i], links[i])
+		}
+	}
+
+	listing := handler.languageLinks("/blog", "es", handler.pathTranslations("/blog"))
+	if len(listing) != 3 || listing[1].URL != "/es/blog" || !listing[1].Current || !listing[2].Translated {
+		t.Fatalf("unexpected listing links %+v", listing)
+	}
+}
@@ -2,11 +2,14 @@ package handlers
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 
+	"constructor-script-backend/internal/models"
 	"constructor-script-backend/internal/repository"
 	"constructor-script-backend/internal/seed"
 	"constructor-script-backend/internal/service"
+	"constructor-script-backend/pkg/apierror"
 	"constructor-script-backend/pkg/logger"
 	blogseed "constructor-script-backend/plugins/blog/seed"
 	blogservice "constructor-script-backend/plugins/blog/service"
@@ -94,27 +97,8 @@ func (h *ThemeHandler) Activate(c *gin.Context) {
 		return
 	}
 
-	if needsInitialization && (h.pageService != nil || h.menuService != nil || (h.postService != nil && h.userRepo != nil)) {
-		if activeTheme, activeErr := h.service.ActiveTheme(); activeErr == nil {
-			if h.pageService != nil {
-				seed.EnsureDefaultPages(h.pageService, activeTheme.PagesFS())
-			}
-			if h.menuService != nil {
-				seed.EnsureDefaultMenu(h.menuService, activeTheme.MenuFS())
-			}
-			if h.postService != nil && h.userRepo != nil {
-				blogseed.EnsureDefaultPosts(h.postService, h.userRepo, activeTheme.PostsFS())
-			}
-			if err := h.service.MarkInitialized(activeTheme.Slug); err != nil {
-				logger.ErrorContext(ctx, err, "Failed to mark theme defaults as applied", map[string]interface{}{"theme": activeTheme.Slug})
-			}
-		} else {
-			logger.ErrorContext(ctx, activeErr, "Failed to load active theme for defaults", nil)
-		}
-	} else if needsInitialization {
-		if err := h.service.MarkInitialized(theme.Slug); err != nil {
-			logger.ErrorContext(ctx, err, "Failed to mark theme defaults as applied", map[string]interface{}{"theme": theme.Slug})
-		}
+	if needsInitialization {
+		h.applyThemeDefaults(c, theme.Slug)
 	}
 
 	c.JSON(http.StatusOK, gin.H{"theme": theme})
@@ -188,3 +172,189 @@ func (h *ThemeHandler) Reload(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"theme": theme})
 }
+
+func (h *ThemeHandler) ListTemplates(c *gin.Context) {
+	ctx := c.Request.Context()
+	if h == nil || h.service == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "theme service unavailable"})
+		return
+	}
+
+	slug := c.Param("slug")
+	templates, err := h.service.ListTemplates(slug)
+	if err != nil {
+		logger.ErrorContext(ctx, err, "Failed to list theme templates", map[string]interface{}{"slug": slug})
+		c.JSON(themeTemplateErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"templates": templates})
+}
+
+func (h *ThemeHandler) GetTemplate(c *gin.Context) {
+	ctx := c.Request.Context()
+	if h == nil || h.service == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "theme service unavailable"})
+		return
+	}
+
+	slug := c.Param("slug")
+	name := c.Param("name")
+	detail, err := h.service.GetTemplate(slug, name)
+	if err != nil {
+		logger.ErrorContext(ctx, err, "Failed to load theme template", map[string]interface{}{"slug": slug, "template": name})
+		c.JSON(themeTemplateErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"template": detail})
+}
+
+func (h *ThemeHandler) UpdateTemplate(c *gin.Context) {
+	ctx := c.Request.Context()
+	if h == nil || h.service == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "theme service unavailable"})
+		return
+	}
+
+	var req models.UpdateThemeTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BindError(c, err)
+		return
+	}
+
+	slug := c.Param("slug")
+	name := c.Param("name")
+	detail, err := h.service.SaveTemplateOverride(slug, name, req.Content)
+	if err != nil {
+		logger.ErrorContext(ctx, err, "Failed to save theme template override", map[string]interface{}{"slug": slug, "template": name})
+		c.JSON(themeTemplateErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	h.reloadTemplates(c, detail.Theme)
+
+	c.JSON(http.StatusOK, gin.H{"template": detail})
+}
+
+func (h *ThemeHandler) DeleteTemplate(c *gin.Context) {
+	ctx := c.Request.Context()
+	if h == nil || h.service == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "theme service unavailable"})
+		return
+	}
+
+	slug := c.Param("slug")
+	name := c.Param("name")
+	if err := h.service.DeleteTemplateOverride(slug, name); err != nil {
+		logger.ErrorContext(ctx, err, "Failed to delete theme template override", map[string]interface{}{"slug": slug, "template": name})
+		c.JSON(themeTemplateErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	h.reloadTemplates(c, slug)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Template override removed"})
+}
+
+// applyThemeDefaults seeds the default pages, menu and posts of the active theme and
+// records that they were applied.
+func (h *ThemeHandler) applyThemeDefaults(c *gin.Context, slug string) {
+	ctx := c.Request.Context()
+	if h.pageService == nil && h.menuService == nil && (h.postService == nil || h.userRepo == nil) {
+		if err := h.service.MarkInitialized(slug); err != nil {
+			logger.ErrorContext(ctx, err, "Failed to mark theme defaults as applied", map[string]interface{}{"theme": slug})
+		}
+		return
+	}
+
+	activeTheme, err := h.service.ActiveTheme()
+	if err != nil {
+		logger.ErrorContext(ctx, err, "Failed to load active theme for defaults", nil)
+		return
+	}
+	if h.pageService != nil {
+		seed.EnsureDefaultPages(h.pageService, activeTheme.PagesFS())
+	}
+	if h.menuService != nil {
+		seed.EnsureDefaultMenu(h.menuService, activeTheme.MenuFS())
+	}
+	if h.postService != nil && h.userRepo != nil {
+		blogseed.EnsureDefaultPosts(h.postService, h.userRepo, activeTheme.PostsFS())
+	}
+	if err := h.service.MarkInitialized(activeTheme.Slug); err != nil {
+		logger.ErrorContext(ctx, err, "Failed to mark theme defaults as applied", map[string]interface{}{"theme": activeTheme.Slug})
+	}
+}
+
+// ExportSettings returns the settings bundle of the active theme as a downloadable file.
+func (h *ThemeHandler) ExportSettings(c *gin.Context) {
+	ctx := c.Request.Context()
+	if h == nil || h.service == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "theme service unavailable"})
+		return
+	}
+
+	bundle, err := h.service.ExportSettings(h.menuService)
+	if err != nil {
+		logger.ErrorContext(ctx, err, "Failed to export theme settings", nil)
+		c.JSON(themeTemplateErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	filename := fmt.Sprintf("theme-%s-settings-%s.json", bundle.Theme, bundle.ExportedAt.Format("20060102-150405"))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.JSON(http.StatusOK, bundle)
+}
+
+// ImportSettings applies a bundle produced by ExportSettings and activates its theme.
+func (h *ThemeHandler) ImportSettings(c *gin.Context) {
+	ctx := c.Request.Context()
+	if h == nil || h.service == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "theme service unavailable"})
+		return
+	}
+
+	var bundle models.ThemeSettingsBundle
+	if err := c.ShouldBindJSON(&bundle); err != nil {
+		apierror.BindError(c, err)
+		return
+	}
+
+	theme, needsInitialization, err := h.service.ImportSettings(bundle, h.menuService)
+	if err != nil {
+		logger.ErrorContext(ctx, err, "Failed to import theme settings", map[string]interface{}{"theme": bundle.Theme})
+		c.JSON(themeTemplateErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	if needsInitialization {
+		h.applyThemeDefaults(c, theme.Slug)
+	}
+
+	h.reloadTemplates(c, theme.Slug)
+
+	c.JSON(http.StatusOK, gin.H{"theme": theme})
+}
+
+func (h *ThemeHandler) reloadTemplates(c *gin.Context, slug string) {
+	if h.templates == nil {
+		return
+	}
+	if err := h.templates.ReloadTemplates(); err != nil {
+		logger.ErrorContext(c.Request.Context(), err, "Failed to reload templates after override change", map[string]interface{}{"theme": slug})
+	}
+}
+
+func themeTemplateErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrThemeManagerUnavailable), errors.Is(err, service.ErrThemeTemplatesNotStored):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, service.ErrThemeNotFound), errors.Is(err, service.ErrThemeTemplateNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrInvalidThemeTemplate), errors.Is(err, service.ErrInvalidThemeSettings):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"constructor-script-backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UploadGCHandler struct {
+	collector *service.UploadGarbageCollector
+}
+
+func NewUploadGCHandler(collector *service.UploadGarbageCollector) *UploadGCHandler {
+	return &UploadGCHandler{collector: collector}
+}
+
+// Preview lists the uploads the next garbage collection would remove, without
+// touching any files.
+func (h *UploadGCHandler) Preview(c *gin.Context) {
+	h.run(c, true)
+}
+
+// Collect runs garbage collection now.
+func (h *UploadGCHandler) Collect(c *gin.Context) {
+	h.run(c, false)
+}
+
+func (h *UploadGCHandler) run(c *gin.Context, dryRun bool) {
+	result, err := h.collector.Run(c.Request.Context(), dryRun)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrUploadGCRunning):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"result": result})
+}
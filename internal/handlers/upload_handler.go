@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"constructor-script-backend/internal/service"
+	"constructor-script-backend/pkg/apierror"
 	"constructor-script-backend/pkg/validator"
 
 	"github.com/gin-gonic/gin"
@@ -118,7 +119,7 @@ func (h *UploadHandler) Rename(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request payload"})
+		apierror.BindError(c, err)
 		return
 	}
 
@@ -144,7 +145,7 @@ func (h *UploadHandler) Delete(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request payload"})
+		apierror.BindError(c, err)
 		return
 	}
 
@@ -166,3 +167,34 @@ func (h *UploadHandler) Delete(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"status": "deleted"})
 }
+
+// UpdateMetadata stores the alt text and focal point of an image in the media library.
+func (h *UploadHandler) UpdateMetadata(c *gin.Context) {
+	var request struct {
+		Target string `json:"target"`
+		service.ImageMetadataUpdate
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		apierror.BindError(c, err)
+		return
+	}
+
+	upload, err := h.uploadService.UpdateImageMetadata(request.Target, request.ImageMetadataUpdate)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidFocalPoint),
+			errors.Is(err, service.ErrAltTextTooLong):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrUploadNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrMediaMetadataUnavailable):
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"upload": upload})
+}
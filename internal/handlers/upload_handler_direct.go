@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"constructor-script-backend/internal/service"
+	"constructor-script-backend/pkg/apierror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PresignDirect returns a presigned URL the browser can upload a video or document to,
+// straight to object storage.
+func (h *UploadHandler) PresignDirect(c *gin.Context) {
+	direct := h.uploadService.DirectUploads()
+	if direct == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": service.ErrDirectUploadsDisabled.Error()})
+		return
+	}
+
+	var request service.DirectUploadRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		apierror.BindError(c, err)
+		return
+	}
+
+	ticket, err := direct.Presign(request)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrUnsupportedUpload),
+			errors.Is(err, service.ErrUploadTooLarge),
+			errors.Is(err, service.ErrUploadMissing),
+			errors.Is(err, service.ErrDirectUploadCategory):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"ticket": ticket})
+}
+
+// CompleteDirect registers a file once the browser has finished uploading it.
+func (h *UploadHandler) CompleteDirect(c *gin.Context) {
+	direct := h.uploadService.DirectUploads()
+	if direct == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": service.ErrDirectUploadsDisabled.Error()})
+		return
+	}
+
+	var request struct {
+		Token string `json:"token" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		apierror.BindError(c, err)
+		return
+	}
+
+	upload, err := direct.Complete(c.Request.Context(), request.Token)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrDirectUploadTicket):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrDirectUploadIncomplete):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"upload":   upload,
+		"url":      upload.URL,
+		"filename": upload.Filename,
+		"size":     upload.Size,
+	})
+}
@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/service"
+	"constructor-script-backend/pkg/apierror"
+	"constructor-script-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type WebhookHandler struct {
+	service *service.WebhookService
+}
+
+func NewWebhookHandler(service *service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{service: service}
+}
+
+func webhookErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrWebhookRepositoryUnavailable):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, service.ErrInvalidWebhook):
+		return http.StatusBadRequest
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func parseWebhookID(c *gin.Context, param string) (uint, bool) {
+	value, err := strconv.ParseUint(c.Param(param), 10, 64)
+	if err != nil || value == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return 0, false
+	}
+	return uint(value), true
+}
+
+func (h *WebhookHandler) List(c *gin.Context) {
+	if h == nil || h.service == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Webhook service not available"})
+		return
+	}
+
+	webhooks, err := h.service.List()
+	if err != nil {
+		logger.ErrorContext(c.Request.Context(), err, "Failed to load webhooks", nil)
+		c.JSON(webhookErrorStatus(err), gin.H{"error": "Failed to load webhooks"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"webhooks": webhooks, "events": h.service.EventNames()})
+}
+
+func (h *WebhookHandler) Create(c *gin.Context) {
+	if h == nil || h.service == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Webhook service not available"})
+		return
+	}
+
+	var req models.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BindError(c, err)
+		return
+	}
+
+	webhook, secret, err := h.service.Create(req)
+	if err != nil {
+		logger.ErrorContext(c.Request.Context(), err, "Failed to create webhook", nil)
+		c.JSON(webhookErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"webhook": webhook, "secret": secret})
+}
+
+func (h *WebhookHandler) Update(c *gin.Context) {
+	if h == nil || h.service == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Webhook service not available"})
+		return
+	}
+
+	id, ok := parseWebhookID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BindError(c, err)
+		return
+	}
+
+	webhook, err := h.service.Update(id, req)
+	if err != nil {
+		logger.ErrorContext(c.Request.Context(), err, "Failed to update webhook", map[string]interface{}{"id": id})
+		c.JSON(webhookErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"webhook": webhook})
+}
+
+func (h *WebhookHandler) RotateSecret(c *gin.Context) {
+	if h == nil || h.service == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Webhook service not available"})
+		return
+	}
+
+	id, ok := parseWebhookID(c, "id")
+	if !ok {
+		return
+	}
+
+	webhook, secret, err := h.service.RotateSecret(id)
+	if err != nil {
+		logger.ErrorContext(c.Request.Context(), err, "Failed to rotate webhook secret", map[string]interface{}{"id": id})
+		c.JSON(webhookErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"webhook": webhook, "secret": secret})
+}
+
+func (h *WebhookHandler) Delete(c *gin.Context) {
+	if h == nil || h.service == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Webhook service not available"})
+		return
+	}
+
+	id, ok := parseWebhookID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(id); err != nil {
+		logger.ErrorContext(c.Request.Context(), err, "Failed to delete webhook", map[string]interface{}{"id": id})
+		c.JSON(webhookErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook deleted"})
+}
+
+func (h *WebhookHandler) Deliveries(c *gin.Context) {
+	if h == nil || h.service == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Webhook service not available"})
+		return
+	}
+
+	id, ok := parseWebhookID(c, "id")
+	if !ok {
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	deliveries, err := h.service.Deliveries(id, limit)
+	if err != nil {
+		logger.ErrorContext(c.Request.Context(), err, "Failed to load webhook deliveries", map[string]interface{}{"id": id})
+		c.JSON(webhookErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"deliveries": deliveries})
+}
+
+func (h *WebhookHandler) Redeliver(c *gin.Context) {
+	if h == nil || h.service == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Webhook service not available"})
+		return
+	}
+
+	id, ok := parseWebhookID(c, "id")
+	if !ok {
+		return
+	}
+	deliveryID, ok := parseWebhookID(c, "deliveryId")
+	if !ok {
+		return
+	}
+
+	delivery, err := h.service.Redeliver(id, deliveryID)
+	if err != nil {
+		logger.ErrorContext(c.Request.Context(), err, "Failed to redeliver webhook", map[string]interface{}{"id": id, "delivery": deliveryID})
+		c.JSON(webhookErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"delivery": delivery})
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIQuotas enforces the daily request quotas of users and delivery tokens.
+type APIQuotas interface {
+	UserRequestsPerDay() int
+	Allow(subject string, limit int) (allowed bool, remaining int64, retryAfter time.Duration)
+}
+
+// APIQuotaMiddleware counts the API requests of signed-in users against their
+// daily quota, on top of the rate limits. Admin routes are left out so that an
+// administrator cannot be locked out of raising the quota, and delivery routes
+// are counted per token by DeliveryTokenMiddleware.
+func APIQuotaMiddleware(quotas APIQuotas, jwtSecret string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		path := c.Request.URL.Path
+		if quotas == nil || !strings.HasPrefix(path, "/api/") ||
+			strings.HasPrefix(path, "/api/v1/admin/") || strings.HasPrefix(path, DeliveryAPIPrefix) {
+			c.Next()
+			return
+		}
+
+		userID, ok := requestUserID(c, jwtSecret)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		if !allowAPIQuota(c, quotas, "user:"+strconv.FormatUint(uint64(userID), 10), quotas.UserRequestsPerDay()) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// allowAPIQuota counts the request against the quota of subject and reports it
+// in the X-Quota headers. A request over the quota is answered with 429 and
+// false is returned.
+func allowAPIQuota(c *gin.Context, quotas APIQuotas, subject string, limit int) bool {
+	allowed, remaining, retryAfter := quotas.Allow(subject, limit)
+	if limit > 0 {
+		c.Header("X-Quota-Limit", strconv.Itoa(limit))
+		c.Header("X-Quota-Remaining", strconv.FormatInt(remaining, 10))
+	}
+	if allowed {
+		return true
+	}
+
+	c.Header("Retry-After", retryAfterSeconds(retryAfter))
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+		"error":            "daily API quota exceeded",
+		"requests_per_day": limit,
+	})
+	return false
+}
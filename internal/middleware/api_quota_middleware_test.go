@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type countingQuotas struct {
+	limit  int
+	counts map[string]int
+}
+
+func (q *countingQuotas) UserRequestsPerDay() int { return q.limit }
+
+func (q *countingQuotas) Allow(subject string, limit int) (bool, int64, time.Duration) {
+	q.counts[subject]++
+	remaining := int64(limit - q.counts[subject])
+	if remaining < 0 {
+		return false, 0, time.Hour
+	}
+	return true, remaining, 0
+}
+
+func TestAPIQuotaMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const secret = "quota-secret"
+	quotas := &countingQuotas{limit: 1, counts: make(map[string]int)}
+	router := gin.New()
+	router.Use(APIQuotaMiddleware(quotas, secret))
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.GET("/api/v1/posts", ok)
+	router.GET("/api/v1/admin/users", ok)
+
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": 4,
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	send := func(path string, token string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, path, nil)
+		if token != "" {
+			request.Header.Set("Authorization", "Bearer "+token)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	recorder := send("/api/v1/posts", signed)
+	if recorder.Code != http.StatusOK || recorder.Header().Get("X-Quota-Limit") != "1" || recorder.Header().Get("X-Quota-Remaining") != "0" {
+		t.Fatalf("unexpected response %d %v", recorder.Code, recorder.Header())
+	}
+	recorder = send("/api/v1/posts", signed)
+	if recorder.Code != http.StatusTooManyRequests || recorder.Header().Get("Retry-After") != "3600" {
+		t.Fatalf("expected the quota to run out, got %d %v", recorder.Code, recorder.Header())
+	}
+
+	if recorder := send("/api/v1/admin/users", signed); recorder.Code != http.StatusOK {
+		t.Fatalf("expected admin routes to skip the quota, got %d", recorder.Code)
+	}
+	if recorder := send("/api/v1/posts", ""); recorder.Code != http.StatusOK {
+		t.Fatalf("expected anonymous requests to skip the quota, got %d", recorder.Code)
+	}
+	if quotas.counts["user:4"] != 2 || len(quotas.counts) != 1 {
+		t.Fatalf("unexpected counts %v", quotas.counts)
+	}
+}
@@ -0,0 +1,153 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	apiV1Prefix = "/api/v1"
+	apiV2Prefix = "/api/v2"
+)
+
+// APIDeprecation describes the /api/v1 routes that have a replacement in /api/v2.
+type APIDeprecation struct {
+	// Since is when the routes were deprecated.
+	Since time.Time
+	// Sunset is when they are expected to stop working; zero leaves it open.
+	Sunset time.Time
+	// Disabled retires the routes: they answer 410 Gone and point to their
+	// successor.
+	Disabled bool
+}
+
+// DeprecatedRoute marks a /api/v1 route as replaced by the same path under
+// /api/v2, with the Deprecation and Sunset headers of RFC 9745 and RFC 8594 and
+// a Link to the successor.
+func DeprecatedRoute(deprecation APIDeprecation) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		successor := apiV2Prefix + strings.TrimPrefix(c.Request.URL.Path, apiV1Prefix)
+		if c.Request.URL.RawQuery != "" {
+			successor += "?" + c.Request.URL.RawQuery
+		}
+
+		if !deprecation.Since.IsZero() {
+			c.Header("Deprecation", "@"+strconv.FormatInt(deprecation.Since.Unix(), 10))
+		}
+		if !deprecation.Sunset.IsZero() {
+			c.Header("Sunset", deprecation.Sunset.UTC().Format(http.TimeFormat))
+		}
+		c.Header("Link", "<"+successor+`>; rel="successor-version"`)
+
+		if deprecation.Disabled {
+			c.AbortWithStatusJSON(http.StatusGone, gin.H{
+				"error":     "this endpoint has been retired",
+				"successor": successor,
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+// V2List serves a /api/v2 list route with the handler of its /api/v1 route. It
+// translates per_page to the limit the handler reads and rewrites the response,
+// whose items the handler puts under key next to total, page and limit, into the
+// v2 shape:
+//
+//	{"data": [...], "pagination": {"page": 1, "per_page": 10, "total": 42, "total_pages": 5}}
+//
+// Error responses pass through unchanged.
+func V2List(key string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query := c.Request.URL.Query()
+		if perPage := query.Get("per_page"); perPage != "" {
+			query.Set("limit", perPage)
+			query.Del("per_page")
+			c.Request.URL.RawQuery = query.Encode()
+		}
+
+		writer := &v2ListWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		if writer.buffer == nil {
+			return
+		}
+		body := v2ListBody(writer.buffer.Bytes(), key)
+		writer.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		_, _ = writer.ResponseWriter.Write(body)
+	}
+}
+
+// v2ListWriter holds back successful JSON responses, which gin writes in one
+// piece, until they are rewritten.
+type v2ListWriter struct {
+	gin.ResponseWriter
+	buffer *bytes.Buffer
+}
+
+func (w *v2ListWriter) Write(data []byte) (int, error) {
+	if w.buffer == nil && !w.Written() && w.Status() == http.StatusOK &&
+		strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+		w.buffer = &bytes.Buffer{}
+	}
+	if w.buffer != nil {
+		return w.buffer.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *v2ListWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+type v2Pagination struct {
+	Page       int   `json:"page"`
+	PerPage    int   `json:"per_page"`
+	Total      int64 `json:"total"`
+	TotalPages int64 `json:"total_pages"`
+}
+
+func v2ListBody(body []byte, key string) []byte {
+	var v1 struct {
+		Total int64 `json:"total"`
+		Page  int   `json:"page"`
+		Limit int   `json:"limit"`
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return body
+	}
+	items, ok := fields[key]
+	if !ok {
+		return body
+	}
+	if err := json.Unmarshal(body, &v1); err != nil {
+		return body
+	}
+	if string(items) == "null" {
+		items = json.RawMessage("[]")
+	}
+
+	pagination := v2Pagination{Page: v1.Page, PerPage: v1.Limit, Total: v1.Total}
+	if v1.Limit > 0 {
+		pagination.TotalPages = (v1.Total + int64(v1.Limit) - 1) / int64(v1.Limit)
+	}
+
+	rewritten, err := json.Marshal(struct {
+		Data       json.RawMessage `json:"data"`
+		Pagination v2Pagination    `json:"pagination"`
+	}{Data: items, Pagination: pagination})
+	if err != nil {
+		return body
+	}
+	return rewritten
+}
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestDeprecatedRoute(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	deprecation := APIDeprecation{
+		Since:  time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC),
+		Sunset: time.Date(2027, 4, 1, 0, 0, 0, 0, time.UTC),
+	}
+	newRouter := func(deprecation APIDeprecation) *gin.Engine {
+		router := gin.New()
+		router.GET("/api/v1/tags/:slug/posts", DeprecatedRoute(deprecation), func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{"posts": []string{}})
+		})
+		return router
+	}
+
+	recorder := httptest.NewRecorder()
+	newRouter(deprecation).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/tags/go/posts?page=2", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected the deprecated route to keep working, got %d", recorder.Code)
+	}
+	header := recorder.Header()
+	if header.Get("Deprecation") != "@1792022400" || header.Get("Sunset") != "Thu, 01 Apr 2027 00:00:00 GMT" {
+		t.Fatalf("unexpected deprecation headers %v", header)
+	}
+	if header.Get("Link") != `</api/v2/tags/go/posts?page=2>; rel="successor-version"` {
+		t.Fatalf("unexpected link %q", header.Get("Link"))
+	}
+
+	deprecation.Disabled = true
+	recorder = httptest.NewRecorder()
+	newRouter(deprecation).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/tags/go/posts", nil))
+	if recorder.Code != http.StatusGone {
+		t.Fatalf("expected a retired route to answer 410, got %d", recorder.Code)
+	}
+}
+
+func TestV2List(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/api/v2/posts", V2List("posts"), func(c *gin.Context) {
+		if c.Query("per_page") != "" {
+			t.Error("expected per_page to be translated")
+		}
+		if c.Query("fail") != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "bad request"})
+			return
+		}
+		limit, _ := strconv.Atoi(c.Query("limit"))
+		c.JSON(http.StatusOK, gin.H{
+			"posts": []gin.H{{"id": 1}, {"id": 2}},
+			"total": 5,
+			"page":  1,
+			"limit": limit,
+		})
+	})
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v2/posts?per_page=2", nil))
+	want := `{"data":[{"id":1},{"id":2}],"pagination":{"page":1,"per_page":2,"total":5,"total_pages":3}}`
+	if recorder.Code != http.StatusOK || recorder.Body.String() != want {
+		t.Fatalf("unexpected response %d %s", recorder.Code, recorder.Body.String())
+	}
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v2/posts?fail=1", nil))
+	if recorder.Code != http.StatusBadRequest || recorder.Body.String() != `{"error":"bad request"}` {
+		t.Fatalf("expected errors to pass through, got %d %s", recorder.Code, recorder.Body.String())
+	}
+}
@@ -0,0 +1,213 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"constructor-script-backend/internal/authorization"
+	"constructor-script-backend/internal/models"
+)
+
+const (
+	auditBeforeKey = "audit_before"
+
+	// maxAuditBodyBytes is the largest request body summarized; larger bodies, such
+	// as backup imports, are recorded without one.
+	maxAuditBodyBytes   = 64 << 10
+	maxAuditValueLength = 256
+	maxAuditListItems   = 20
+	maxAuditSummary     = 8 << 10
+)
+
+var auditSecretKeyParts = []string{"password", "secret", "token", "api_key", "apikey", "private_key", "credential"}
+
+// AuditRecorder stores the entries AuditLogMiddleware produces.
+type AuditRecorder interface {
+	RecordAudit(entry *models.AuditLog)
+}
+
+// SetAuditBefore records a summary of the state a handler is about to change, for
+// the audit entry of the request.
+func SetAuditBefore(c *gin.Context, before interface{}) {
+	if c == nil || before == nil {
+		return
+	}
+	encoded, err := json.Marshal(before)
+	if err != nil {
+		return
+	}
+	c.Set(auditBeforeKey, summarizeAuditJSON(encoded))
+}
+
+// AuditLogMiddleware records every POST, PUT, PATCH and DELETE request of the group
+// it is attached to, including the ones refused, once the handler has run. It goes
+// after AuthMiddleware so the user is known.
+func AuditLogMiddleware(recorder AuditRecorder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if recorder == nil || !isAuditedMethod(c.Request.Method) {
+			c.Next()
+			return
+		}
+
+		body := readAuditBody(c.Request)
+
+		c.Next()
+
+		entry := &models.AuditLog{
+			UserID:     c.GetUint("user_id"),
+			Username:   c.GetString("username"),
+			Method:     c.Request.Method,
+			Route:      c.FullPath(),
+			Path:       c.Request.URL.Path,
+			ResourceID: c.Param("id"),
+			Status:     c.Writer.Status(),
+			IP:         c.ClientIP(),
+			RequestID:  c.GetString("request_id"),
+		}
+		if entry.Route == "" {
+			entry.Route = entry.Path
+		}
+		entry.Resource = auditResource(entry.Route)
+		if role, ok := c.Get("role"); ok {
+			if parsed, ok := authorization.ParseUserRole(role); ok {
+				entry.Role = string(parsed)
+			}
+		}
+		entry.Before = c.GetString(auditBeforeKey)
+		entry.After = summarizeAuditRequest(c.Request, body)
+
+		recorder.RecordAudit(entry)
+	}
+}
+
+func isAuditedMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// readAuditBody reads a JSON body small enough to summarize and puts it back for
+// the handler.
+func readAuditBody(r *http.Request) []byte {
+	if r.Body == nil || r.ContentLength <= 0 || r.ContentLength > maxAuditBodyBytes {
+		return nil
+	}
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "application/json" {
+		return nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxAuditBodyBytes))
+	r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), r.Body))
+	if err != nil {
+		return nil
+	}
+	return body
+}
+
+// summarizeAuditRequest describes what the request asked to change: its JSON body,
+// or the fields and file names of a form the handler parsed.
+func summarizeAuditRequest(r *http.Request, body []byte) string {
+	if len(body) > 0 {
+		return summarizeAuditJSON(body)
+	}
+
+	form := r.MultipartForm
+	if form == nil {
+		return ""
+	}
+	summary := make(map[string]interface{}, len(form.Value)+len(form.File))
+	for name, values := range form.Value {
+		if len(values) == 1 {
+			summary[name] = values[0]
+		} else {
+			summary[name] = values
+		}
+	}
+	for name, files := range form.File {
+		names := make([]string, 0, len(files))
+		for _, file := range files {
+			names = append(names, file.Filename)
+		}
+		summary[name] = names
+	}
+	encoded, err := json.Marshal(summary)
+	if err != nil {
+		return ""
+	}
+	return summarizeAuditJSON(encoded)
+}
+
+func summarizeAuditJSON(data []byte) string {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return ""
+	}
+	encoded, err := json.Marshal(redactAuditValue(value))
+	if err != nil {
+		return ""
+	}
+	if len(encoded) > maxAuditSummary {
+		return string(encoded[:maxAuditSummary]) + "…"
+	}
+	return string(encoded)
+}
+
+func redactAuditValue(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		for key, nested := range typed {
+			if isAuditSecretKey(key) {
+				if nested != nil && nested != "" {
+					typed[key] = "[redacted]"
+				}
+				continue
+			}
+			typed[key] = redactAuditValue(nested)
+		}
+		return typed
+	case []interface{}:
+		if len(typed) > maxAuditListItems {
+			typed = append(typed[:maxAuditListItems:maxAuditListItems], "…")
+		}
+		for index, nested := range typed {
+			typed[index] = redactAuditValue(nested)
+		}
+		return typed
+	case string:
+		if len(typed) > maxAuditValueLength {
+			return strings.ToValidUTF8(typed[:maxAuditValueLength], "") + "…"
+		}
+		return typed
+	default:
+		return value
+	}
+}
+
+func isAuditSecretKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, part := range auditSecretKeyParts {
+		if strings.Contains(key, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// auditResource returns the segment after /admin/ in route.
+func auditResource(route string) string {
+	_, rest, found := strings.Cut(route, "/admin/")
+	if !found {
+		return ""
+	}
+	resource, _, _ := strings.Cut(rest, "/")
+	return resource
+}
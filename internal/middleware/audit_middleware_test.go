@@ -0,0 +1,127 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"constructor-script-backend/internal/authorization"
+	"constructor-script-backend/internal/models"
+)
+
+type auditRecorderFunc func(entry *models.AuditLog)
+
+func (f auditRecorderFunc) RecordAudit(entry *models.AuditLog) {
+	f(entry)
+}
+
+func TestAuditLogMiddlewareRecordsAdminChanges(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var entries []*models.AuditLog
+	router := gin.New()
+	router.Use(RequestIDMiddleware())
+	admin := router.Group("/api/v1/admin")
+	admin.Use(func(c *gin.Context) {
+		c.Set("user_id", uint(3))
+		c.Set("username", "editor")
+		c.Set("role", authorization.RoleAdmin)
+		c.Next()
+	})
+	admin.Use(AuditLogMiddleware(auditRecorderFunc(func(entry *models.AuditLog) {
+		entries = append(entries, entry)
+	})))
+
+	admin.GET("/users/:id", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	admin.PUT("/users/:id/role", func(c *gin.Context) {
+		var req struct {
+			Role string `json:"role"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil || req.Role != "author" {
+			c.Status(http.StatusBadRequest)
+			return
+		}
+		SetAuditBefore(c, gin.H{"role": "user"})
+		c.Status(http.StatusOK)
+	})
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Request-ID", "req-1")
+		req.RemoteAddr = "192.0.2.10:1234"
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	send(http.MethodGet, "/api/v1/admin/users/9", "")
+	if len(entries) != 0 {
+		t.Fatalf("GET was recorded: %+v", entries)
+	}
+
+	recorder := send(http.MethodPut, "/api/v1/admin/users/9/role", `{"role":"author","password":"hunter2"}`)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("handler did not get the body: status %d", recorder.Code)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+
+	entry := entries[0]
+	if entry.UserID != 3 || entry.Username != "editor" || entry.Role != "admin" {
+		t.Errorf("actor = %d %q %q", entry.UserID, entry.Username, entry.Role)
+	}
+	if entry.Route != "/api/v1/admin/users/:id/role" || entry.Resource != "users" || entry.ResourceID != "9" {
+		t.Errorf("target = %q %q %q", entry.Route, entry.Resource, entry.ResourceID)
+	}
+	if entry.Status != http.StatusOK || entry.IP != "192.0.2.10" || entry.RequestID != "req-1" {
+		t.Errorf("status/ip/request = %d %q %q", entry.Status, entry.IP, entry.RequestID)
+	}
+	if entry.Before != `{"role":"user"}` {
+		t.Errorf("Before = %q", entry.Before)
+	}
+
+	var after map[string]string
+	if err := json.Unmarshal([]byte(entry.After), &after); err != nil {
+		t.Fatalf("After is not JSON: %q", entry.After)
+	}
+	if after["role"] != "author" || after["password"] != "[redacted]" {
+		t.Errorf("After = %q", entry.After)
+	}
+}
+
+func TestRedactAuditValueShortensLongValues(t *testing.T) {
+	long := strings.Repeat("x", maxAuditValueLength+10)
+	items := make([]interface{}, maxAuditListItems+5)
+	for index := range items {
+		items[index] = "item"
+	}
+
+	redacted := redactAuditValue(map[string]interface{}{
+		"body":      long,
+		"items":     items,
+		"smtp":      map[string]interface{}{"api_key": "k", "host": "mail"},
+		"new_token": "",
+	}).(map[string]interface{})
+
+	if body := redacted["body"].(string); len(body) != maxAuditValueLength+len("…") {
+		t.Errorf("body length = %d", len(body))
+	}
+	if list := redacted["items"].([]interface{}); len(list) != maxAuditListItems+1 || list[maxAuditListItems] != "…" {
+		t.Errorf("items = %v", list)
+	}
+	smtp := redacted["smtp"].(map[string]interface{})
+	if smtp["api_key"] != "[redacted]" || smtp["host"] != "mail" {
+		t.Errorf("smtp = %v", smtp)
+	}
+	if redacted["new_token"] != "" {
+		t.Errorf("empty secret should stay empty, got %v", redacted["new_token"])
+	}
+}
@@ -11,6 +11,7 @@ import (
 
 	"constructor-script-backend/internal/authorization"
 	"constructor-script-backend/internal/constants"
+	"constructor-script-backend/pkg/logger"
 )
 
 // extractTokenFromHeader extracts JWT token from Authorization header
@@ -39,6 +40,47 @@ func extractTokenFromCookie(c *gin.Context) string {
 	return strings.TrimSpace(cookieToken)
 }
 
+func parseAuthToken(tokenString, jwtSecret string) (*jwt.Token, error) {
+	return jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(jwtSecret), nil
+	})
+}
+
+// requestUserID returns the user signed in on the request without rejecting it
+// when nobody is, for middleware that runs ahead of AuthMiddleware.
+func requestUserID(c *gin.Context, jwtSecret string) (uint, bool) {
+	if value, exists := c.Get("user_id"); exists {
+		if id, ok := value.(uint); ok && id > 0 {
+			return id, true
+		}
+	}
+
+	tokenString := extractTokenFromHeader(c)
+	if tokenString == "" {
+		tokenString = extractTokenFromCookie(c)
+	}
+	if tokenString == "" || jwtSecret == "" {
+		return 0, false
+	}
+
+	token, err := parseAuthToken(tokenString, jwtSecret)
+	if err != nil || !token.Valid {
+		return 0, false
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return 0, false
+	}
+	id, ok := claims["user_id"].(float64)
+	if !ok || id <= 0 {
+		return 0, false
+	}
+	return uint(id), true
+}
+
 func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Extract token from Authorization header first (priority)
@@ -56,13 +98,7 @@ func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
 			return
 		}
 
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
-			return []byte(jwtSecret), nil
-		})
-
+		token, err := parseAuthToken(tokenString, jwtSecret)
 		if err != nil || !token.Valid {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired token"})
 			c.Abort()
@@ -84,7 +120,10 @@ func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
 			}
 		}
 
-		c.Set("user_id", uint(claims["user_id"].(float64)))
+		userID := uint(claims["user_id"].(float64))
+		c.Set("user_id", userID)
+		// Errors logged while serving the request, and their reports, name the user.
+		c.Request = c.Request.WithContext(logger.ContextWithFields(c.Request.Context(), map[string]interface{}{"user_id": userID}))
 		c.Set("email", claims["email"].(string))
 		c.Set("username", claims["username"].(string))
 
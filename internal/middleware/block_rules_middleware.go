@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BlockRuleSource decides whether the admin block rules refuse a request.
+type BlockRuleSource interface {
+	Blocked(ip, userAgent, country string) bool
+}
+
+// BlockRulesMiddleware refuses blocked requests before any other handling.
+// countryHeader names a header a CDN sets to the visitor's country, such as
+// CF-IPCountry; without it the source looks the country up itself.
+func BlockRulesMiddleware(source BlockRuleSource, countryHeader string) gin.HandlerFunc {
+	countryHeader = strings.TrimSpace(countryHeader)
+	return func(c *gin.Context) {
+		if source == nil {
+			c.Next()
+			return
+		}
+
+		country := ""
+		if countryHeader != "" {
+			country = strings.ToUpper(strings.TrimSpace(c.GetHeader(countryHeader)))
+		}
+		if source.Blocked(c.ClientIP(), c.Request.UserAgent(), country) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "access denied"})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CanonicalURLMiddleware permanently redirects page requests to their canonical
+// form. Trailing slashes are always stripped (except from the root path). When
+// enforceHost is set, requests are also moved to the scheme and host of the site
+// URL returned by siteURL, which covers www/apex redirects and forcing HTTPS.
+// Requests to loopback hosts and paths under skipPrefixes are left alone.
+func CanonicalURLMiddleware(siteURL func() string, enforceHost bool, skipPrefixes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		method := c.Request.Method
+		if method != http.MethodGet && method != http.MethodHead {
+			c.Next()
+			return
+		}
+
+		path := c.Request.URL.Path
+		for _, prefix := range skipPrefixes {
+			if path == prefix || strings.HasPrefix(path, strings.TrimSuffix(prefix, "/")+"/") {
+				c.Next()
+				return
+			}
+		}
+
+		target := *c.Request.URL
+		target.Scheme, target.Host = "", ""
+		changed := false
+
+		if path != "/" && strings.HasSuffix(path, "/") {
+			// Leading slashes are collapsed too: a target such as //evil.com
+			// is a protocol-relative URL that browsers follow off site.
+			trimmed := "/" + strings.Trim(path, "/")
+			target.Path, target.RawPath = trimmed, ""
+			changed = true
+		}
+
+		if enforceHost && siteURL != nil && !isLoopbackHost(forwardedHost(c.Request)) {
+			if canonical, err := url.Parse(strings.TrimSpace(siteURL())); err == nil && canonical.Host != "" {
+				scheme := strings.ToLower(canonical.Scheme)
+				if scheme == "" {
+					scheme = "https"
+				}
+				if scheme != forwardedScheme(c.Request) || !strings.EqualFold(canonical.Host, forwardedHost(c.Request)) {
+					target.Scheme, target.Host = scheme, canonical.Host
+					changed = true
+				}
+			}
+		}
+
+		if !changed {
+			c.Next()
+			return
+		}
+
+		c.Redirect(http.StatusMovedPermanently, target.String())
+		c.Abort()
+	}
+}
+
+func forwardedScheme(r *http.Request) string {
+	if proto := strings.TrimSpace(r.Header.Get("X-Forwarded-Proto")); proto != "" {
+		if value := strings.ToLower(strings.TrimSpace(strings.Split(proto, ",")[0])); value != "" {
+			return value
+		}
+	}
+	if r.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
+
+func forwardedHost(r *http.Request) string {
+	if host := strings.TrimSpace(r.Header.Get("X-Forwarded-Host")); host != "" {
+		if value := strings.TrimSpace(strings.Split(host, ",")[0]); value != "" {
+			return value
+		}
+	}
+	return r.Host
+}
+
+func isLoopbackHost(host string) bool {
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		host = hostname
+	}
+	host = strings.Trim(host, "[]")
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
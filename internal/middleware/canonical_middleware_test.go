@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCanonicalURLMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(CanonicalURLMiddleware(func() string { return "https://example.com" }, true, "/api"))
+	router.GET("/*path", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+	router.POST("/*path", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+
+	cases := []struct {
+		method   string
+		target   string
+		header   map[string]string
+		location string
+	}{
+		{http.MethodGet, "http://example.com/about", map[string]string{"X-Forwarded-Proto": "https"}, ""},
+		{http.MethodGet, "http://example.com/about", nil, "https://example.com/about"},
+		{http.MethodGet, "https://www.example.com/about?x=1", map[string]string{"X-Forwarded-Proto": "https"}, "https://example.com/about?x=1"},
+		{http.MethodGet, "http://example.com/blog/", map[string]string{"X-Forwarded-Proto": "https"}, "/blog"},
+		{http.MethodGet, "http://example.com/", map[string]string{"X-Forwarded-Proto": "https"}, ""},
+		{http.MethodGet, "http://www.example.com/api/posts/", nil, ""},
+		{http.MethodGet, "http://localhost:8080/about/", nil, "/about"},
+		{http.MethodPost, "http://www.example.com/about/", nil, ""},
+		{http.MethodGet, "http://example.com//evil.com/", map[string]string{"X-Forwarded-Proto": "https"}, "/evil.com"},
+		{http.MethodGet, "http://example.com/%2Fevil.com/", map[string]string{"X-Forwarded-Proto": "https"}, "/evil.com"},
+		{http.MethodGet, "http://localhost:8080//evil.com//", nil, "/evil.com"},
+	}
+	for _, tc := range cases {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(tc.method, tc.target, nil)
+		for key, value := range tc.header {
+			request.Header.Set(key, value)
+		}
+		router.ServeHTTP(recorder, request)
+
+		if tc.location == "" {
+			if recorder.Code != http.StatusOK {
+				t.Fatalf("%s %s: expected no redirect, got %d to %q", tc.method, tc.target, recorder.Code, recorder.Header().Get("Location"))
+			}
+			continue
+		}
+		if recorder.Code != http.StatusMovedPermanently || recorder.Header().Get("Location") != tc.location {
+			t.Fatalf("%s %s: expected redirect to %q, got %d %q", tc.method, tc.target, tc.location, recorder.Code, recorder.Header().Get("Location"))
+		}
+	}
+}
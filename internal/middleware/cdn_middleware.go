@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CDNRewriter rewrites upload URLs in response bodies to point at a CDN.
+type CDNRewriter interface {
+	RewriteHTML(body []byte) []byte
+	RewriteJSON(body []byte) []byte
+}
+
+const (
+	writerUndecided = iota
+	writerBuffering
+	writerPassthrough
+)
+
+type cdnRewriteWriter struct {
+	gin.ResponseWriter
+	rewriter CDNRewriter
+	mode     int
+	rewrite  func([]byte) []byte
+	buffer   bytes.Buffer
+}
+
+// CDNRewriteMiddleware buffers HTML and JSON responses and passes them through
+// rewriter so that uploads load from the CDN. Requests under skipPrefixes, such as the
+// admin and profile editors that send URLs back to the server, are left untouched.
+func CDNRewriteMiddleware(rewriter CDNRewriter, skipPrefixes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if rewriter == nil || hasPathPrefix(c.Request.URL.Path, skipPrefixes) {
+			c.Next()
+			return
+		}
+
+		writer := &cdnRewriteWriter{ResponseWriter: c.Writer, rewriter: rewriter}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+		writer.flushBuffer()
+	}
+}
+
+// CDNOriginMiddleware lets a CDN cache uploads it pulls from this site and lets
+// browsers load them from the CDN host.
+func CDNOriginMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.Writer.Header()
+		header.Del("Pragma")
+		header.Del("Expires")
+		c.Header("Cache-Control", defaultAssetCacheControl)
+		c.Header("Cross-Origin-Resource-Policy", "cross-origin")
+		c.Next()
+	}
+}
+
+func (w *cdnRewriteWriter) decide() {
+	if w.mode != writerUndecided {
+		return
+	}
+	w.mode = writerPassthrough
+	header := w.Header()
+	if encoding := header.Get("Content-Encoding"); encoding != "" && encoding != "identity" {
+		return
+	}
+	contentType := strings.ToLower(header.Get("Content-Type"))
+	switch {
+	case strings.HasPrefix(contentType, "text/html"):
+		w.rewrite = w.rewriter.RewriteHTML
+	case strings.HasPrefix(contentType, "application/json"):
+		w.rewrite = w.rewriter.RewriteJSON
+	default:
+		return
+	}
+	w.mode = writerBuffering
+}
+
+func (w *cdnRewriteWriter) Write(data []byte) (int, error) {
+	w.decide()
+	if w.mode == writerBuffering {
+		return w.buffer.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *cdnRewriteWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Flush is a no-op while buffering; the body is sent once the handler returns.
+func (w *cdnRewriteWriter) Flush() {
+	if w.mode == writerBuffering {
+		return
+	}
+	w.ResponseWriter.Flush()
+}
+
+func (w *cdnRewriteWriter) flushBuffer() {
+	if w.mode != writerBuffering {
+		return
+	}
+	body := w.rewrite(w.buffer.Bytes())
+	w.Header().Del("Content-Length")
+	w.ResponseWriter.Write(body)
+}
+
+func hasPathPrefix(path string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if path == prefix || strings.HasPrefix(path, strings.TrimSuffix(prefix, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
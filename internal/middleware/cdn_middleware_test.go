@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type upperCaseRewriter struct{}
+
+func (upperCaseRewriter) RewriteHTML(body []byte) []byte { return bytes.ToUpper(body) }
+func (upperCaseRewriter) RewriteJSON(body []byte) []byte {
+	return bytes.ReplaceAll(body, []byte("a"), []byte("cdn"))
+}
+
+func TestCDNRewriteMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(CDNRewriteMiddleware(upperCaseRewriter{}, "/admin"))
+	router.GET("/page", func(c *gin.Context) { c.Data(http.StatusOK, "text/html; charset=utf-8", []byte("<p>hi</p>")) })
+	router.GET("/api", func(c *gin.Context) { c.JSON(http.StatusCreated, gin.H{"a": "a"}) })
+	router.GET("/admin/page", func(c *gin.Context) { c.Data(http.StatusOK, "text/html", []byte("<p>hi</p>")) })
+	router.GET("/text", func(c *gin.Context) { c.String(http.StatusOK, "hi") })
+
+	cases := map[string]struct {
+		status int
+		body   string
+	}{
+		"/page":       {http.StatusOK, "<P>HI</P>"},
+		"/api":        {http.StatusCreated, `{"cdn":"cdn"}`},
+		"/admin/page": {http.StatusOK, "<p>hi</p>"},
+		"/text":       {http.StatusOK, "hi"},
+	}
+	for path, want := range cases {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		if recorder.Code != want.status || recorder.Body.String() != want.body {
+			t.Errorf("%s: got %d %q, want %d %q", path, recorder.Code, recorder.Body.String(), want.status, want.body)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"constructor-script-backend/internal/constants"
+)
+
+// revalidateCacheControl lets browsers keep a copy of pages and API responses to
+// anonymous requests but check back with the ETag before every use.
+const revalidateCacheControl = "private, no-cache"
+
+// conditionalContentTypes are the responses given an ETag: pages, API responses,
+// and the sitemaps and feeds crawlers fetch over and over.
+var conditionalContentTypes = []string{
+	"text/html",
+	"application/json",
+	"application/xml",
+	"text/xml",
+	"application/rss+xml",
+	"application/atom+xml",
+	"application/feed+json",
+}
+
+type conditionalWriter struct {
+	gin.ResponseWriter
+	mode   int
+	buffer bytes.Buffer
+}
+
+// ConditionalGetMiddleware tags successful HTML, JSON and XML responses to GET requests
+// with an ETag hashed from the body and answers If-None-Match, or If-Modified-Since
+// where the handler called SetLastModified, with 304 Not Modified. Requests under
+// skipPrefixes are left untouched.
+func ConditionalGetMiddleware(skipPrefixes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet || hasPathPrefix(c.Request.URL.Path, skipPrefixes) {
+			c.Next()
+			return
+		}
+
+		writer := &conditionalWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+		writer.finish(c.Request)
+	}
+}
+
+// SetLastModified records when the content of the response last changed.
+func SetLastModified(c *gin.Context, modified time.Time) {
+	if modified.IsZero() {
+		return
+	}
+	c.Header("Last-Modified", modified.UTC().Format(http.TimeFormat))
+}
+
+// SetContentETag replaces the body hash with a hash of content. Handlers use it
+// when the body carries counters, such as views, that change on every request: they
+// pass a copy of the content with the counters cleared.
+func SetContentETag(c *gin.Context, content interface{}) {
+	data, err := json.Marshal(content)
+	if err != nil {
+		return
+	}
+	sum := sha256.Sum256(data)
+	c.Header("ETag", weakETag(sum[:]))
+}
+
+func weakETag(sum []byte) string {
+	return `W/"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+func (w *conditionalWriter) decide() {
+	if w.mode != writerUndecided {
+		return
+	}
+	w.mode = writerPassthrough
+	if w.Status() != http.StatusOK {
+		return
+	}
+	// Responses the handler marked no-store itself are neither cached nor
+	// revalidated, and may be streamed.
+	if cacheControl := w.Header().Get("Cache-Control"); cacheControl != noStoreCacheControl && strings.Contains(cacheControl, "no-store") {
+		return
+	}
+	contentType := strings.ToLower(w.Header().Get("Content-Type"))
+	for _, prefix := range conditionalContentTypes {
+		if strings.HasPrefix(contentType, prefix) {
+			w.mode = writerBuffering
+			return
+		}
+	}
+}
+
+func (w *conditionalWriter) Write(data []byte) (int, error) {
+	w.decide()
+	if w.mode == writerBuffering {
+		return w.buffer.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *conditionalWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Flush is a no-op while buffering; the body is sent once the handler returns.
+func (w *conditionalWriter) Flush() {
+	if w.mode == writerBuffering {
+		return
+	}
+	w.ResponseWriter.Flush()
+}
+
+func (w *conditionalWriter) finish(req *http.Request) {
+	if w.mode != writerBuffering {
+		return
+	}
+
+	header := w.Header()
+	etag := header.Get("ETag")
+	if etag == "" {
+		sum := sha256.Sum256(w.buffer.Bytes())
+		etag = weakETag(sum[:])
+		header.Set("ETag", etag)
+	}
+	// Only the global no-store default is relaxed, and only for anonymous
+	// requests: a signed-in response must not stay on a shared computer.
+	// Handlers that chose a policy keep it.
+	if header.Get("Cache-Control") == noStoreCacheControl && anonymous(req) {
+		header.Set("Cache-Control", revalidateCacheControl)
+		header.Del("Pragma")
+		header.Del("Expires")
+	}
+
+	if notModified(req, etag, header.Get("Last-Modified")) {
+		header.Del("Content-Type")
+		header.Del("Content-Length")
+		w.ResponseWriter.WriteHeader(http.StatusNotModified)
+		w.ResponseWriter.WriteHeaderNow()
+		return
+	}
+
+	header.Del("Content-Length")
+	w.ResponseWriter.Write(w.buffer.Bytes())
+}
+
+// anonymous reports whether req carries no credentials.
+func anonymous(req *http.Request) bool {
+	if req.Header.Get("Authorization") != "" {
+		return false
+	}
+	_, err := req.Cookie(constants.AuthTokenCookieName)
+	return err != nil
+}
+
+// notModified applies the precedence of RFC 9110: If-Modified-Since is only
+// consulted when the request carries no If-None-Match.
+func notModified(req *http.Request, etag, lastModified string) bool {
+	if match := req.Header.Get("If-None-Match"); match != "" {
+		return etagMatches(match, etag)
+	}
+
+	since := req.Header.Get("If-Modified-Since")
+	if since == "" || lastModified == "" {
+		return false
+	}
+	sinceTime, err := http.ParseTime(since)
+	if err != nil {
+		return false
+	}
+	modified, err := http.ParseTime(lastModified)
+	if err != nil {
+		return false
+	}
+	return !modified.After(sinceTime)
+}
+
+// etagMatches uses the weak comparison If-None-Match calls for.
+func etagMatches(header, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestConditionalGetMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	modified := time.Date(2026, 3, 5, 12, 0, 0, 0, time.UTC)
+	views := 0
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Header("Cache-Control", noStoreCacheControl)
+		c.Next()
+	})
+	router.Use(ConditionalGetMiddleware("/admin"))
+	router.GET("/page", func(c *gin.Context) { c.Data(http.StatusOK, "text/html; charset=utf-8", []byte("<p>hi</p>")) })
+	router.GET("/post", func(c *gin.Context) {
+		views++
+		SetContentETag(c, gin.H{"title": "hi"})
+		SetLastModified(c, modified)
+		c.JSON(http.StatusOK, gin.H{"title": "hi", "views": views})
+	})
+	router.GET("/missing", func(c *gin.Context) { c.JSON(http.StatusNotFound, gin.H{"error": "not found"}) })
+	router.GET("/admin/page", func(c *gin.Context) { c.Data(http.StatusOK, "text/html", []byte("<p>hi</p>")) })
+	router.GET("/private", func(c *gin.Context) {
+		c.Header("Cache-Control", "private, no-store")
+		c.JSON(http.StatusOK, gin.H{"lesson": "secret"})
+	})
+
+	get := func(path string, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	first := get("/page", nil)
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || first.Body.String() != "<p>hi</p>" || etag == "" {
+		t.Fatalf("unexpected first response %d %q etag %q", first.Code, first.Body.String(), etag)
+	}
+	if cacheControl := first.Header().Get("Cache-Control"); cacheControl != revalidateCacheControl {
+		t.Fatalf("expected Cache-Control %q, got %q", revalidateCacheControl, cacheControl)
+	}
+	for _, headers := range []map[string]string{{"Authorization": "Bearer token"}, {"Cookie": "auth_token=token"}} {
+		if signedIn := get("/page", headers); signedIn.Header().Get("Cache-Control") != noStoreCacheControl || signedIn.Header().Get("ETag") != etag {
+			t.Fatalf("expected signed-in responses to keep %q, got %q", noStoreCacheControl, signedIn.Header().Get("Cache-Control"))
+		}
+	}
+	if repeat := get("/page", map[string]string{"If-None-Match": etag}); repeat.Code != http.StatusNotModified || repeat.Body.Len() != 0 {
+		t.Fatalf("expected empty 304, got %d %q", repeat.Code, repeat.Body.String())
+	}
+	if stale := get("/page", map[string]string{"If-None-Match": `W/"other"`}); stale.Code != http.StatusOK {
+		t.Fatalf("expected 200 for a stale ETag, got %d", stale.Code)
+	}
+
+	post := get("/post", nil)
+	if repeat := get("/post", map[string]string{"If-None-Match": post.Header().Get("ETag")}); repeat.Code != http.StatusNotModified {
+		t.Fatalf("view counter must not change the ETag, got %d", repeat.Code)
+	}
+	since := modified.Add(time.Minute).Format(http.TimeFormat)
+	if repeat := get("/post", map[string]string{"If-Modified-Since": since}); repeat.Code != http.StatusNotModified {
+		t.Fatalf("expected 304 for If-Modified-Since, got %d", repeat.Code)
+	}
+	earlier := modified.Add(-time.Minute).Format(http.TimeFormat)
+	if repeat := get("/post", map[string]string{"If-Modified-Since": earlier}); repeat.Code != http.StatusOK {
+		t.Fatalf("expected 200 for content modified since, got %d", repeat.Code)
+	}
+
+	if missing := get("/missing", map[string]string{"If-None-Match": "*"}); missing.Code != http.StatusNotFound || missing.Header().Get("ETag") != "" {
+		t.Fatalf("errors must pass through, got %d etag %q", missing.Code, missing.Header().Get("ETag"))
+	}
+	if admin := get("/admin/page", nil); admin.Header().Get("ETag") != "" {
+		t.Fatal("skipped prefixes must not get an ETag")
+	}
+	if private := get("/private", nil); private.Header().Get("ETag") != "" || private.Header().Get("Cache-Control") != "private, no-store" {
+		t.Fatalf("handler no-store must pass through, got etag %q cache %q", private.Header().Get("ETag"), private.Header().Get("Cache-Control"))
+	}
+}
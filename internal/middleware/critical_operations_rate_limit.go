@@ -41,19 +41,15 @@ func UploadRateLimitMiddleware(cfg *config.Config) gin.HandlerFunc {
 			return
 		}
 
-		limiter := manager.GetCriticalOperationLimiter(
+		allowed, retryAfter := manager.AllowCriticalOperation(
 			c.ClientIP(),
 			"upload",
 			requestsPerWindow,
 			windowSeconds,
 		)
 
-		if limiter == nil {
-			c.Next()
-			return
-		}
-
-		if !limiter.Allow() {
+		if !allowed {
+			c.Header("Retry-After", retryAfterSeconds(retryAfter))
 			c.JSON(http.StatusTooManyRequests, gin.H{
 				"error":          "upload rate limit exceeded",
 				"message":        "Too many upload requests. Please try again later.",
@@ -94,19 +90,15 @@ func BackupRateLimitMiddleware(cfg *config.Config) gin.HandlerFunc {
 			return
 		}
 
-		limiter := manager.GetCriticalOperationLimiter(
+		allowed, retryAfter := manager.AllowCriticalOperation(
 			c.ClientIP(),
 			"backup",
 			requestsPerWindow,
 			windowSeconds,
 		)
 
-		if limiter == nil {
-			c.Next()
-			return
-		}
-
-		if !limiter.Allow() {
+		if !allowed {
+			c.Header("Retry-After", retryAfterSeconds(retryAfter))
 			c.JSON(http.StatusTooManyRequests, gin.H{
 				"error":          "backup rate limit exceeded",
 				"message":        "Too many backup requests. Please try again later.",
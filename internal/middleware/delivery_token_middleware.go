@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"constructor-script-backend/internal/config"
+	"constructor-script-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// DeliveryAPIPrefix is where the read-only content routes for delivery
+	// tokens live.
+	DeliveryAPIPrefix = "/api/v1/delivery/"
+	// DeliveryTokenHeader carries a delivery token; "Authorization: Bearer" is
+	// accepted too.
+	DeliveryTokenHeader = "X-Delivery-Token"
+
+	deliveryTokenContextKey = "delivery_token_id"
+	// deliveryCacheControl lets CDNs and static site builds reuse responses for a
+	// minute and serve stale copies while they refresh.
+	deliveryCacheControl = "public, max-age=60, stale-while-revalidate=300"
+)
+
+// DeliveryTokenAuthenticator resolves the value of a delivery token.
+type DeliveryTokenAuthenticator interface {
+	AuthenticateDeliveryToken(value string) (*models.DeliveryToken, bool)
+}
+
+// DeliveryTokenMiddleware admits reads carrying a valid delivery token. Such
+// requests skip the per-IP limit of RateLimitMiddleware, since a build farm or
+// app backend sends them all from few addresses, and are instead counted against
+// the allowance of their token and, when quotas is set, its daily quota. Requests
+// without a valid token still count against the IP limit before they are turned
+// away.
+func DeliveryTokenMiddleware(auth DeliveryTokenAuthenticator, quotas APIQuotas, cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			c.AbortWithStatusJSON(http.StatusMethodNotAllowed, gin.H{"error": "delivery tokens are read-only"})
+			return
+		}
+
+		var manager *RateLimitManager
+		if managerVal, exists := c.Get("rateLimitManager"); exists {
+			manager, _ = managerVal.(*RateLimitManager)
+		}
+
+		var token *models.DeliveryToken
+		ok := false
+		if auth != nil {
+			token, ok = auth.AuthenticateDeliveryToken(deliveryTokenValue(c.Request))
+		}
+		if !ok {
+			if manager != nil && cfg != nil {
+				if allowed, retryAfter := manager.AllowRequest(c.ClientIP(), cfg.RateLimitRequests, cfg.RateLimitWindow, cfg.RateLimitBurst); !allowed {
+					c.Header("Retry-After", retryAfterSeconds(retryAfter))
+					c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "too many requests, please try again later"})
+					return
+				}
+			}
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "a valid delivery token is required"})
+			return
+		}
+
+		if manager != nil {
+			policy := models.RateLimitPolicy{Name: "delivery", Requests: token.RequestsPerMinute, WindowSeconds: 60}
+			if allowed, retryAfter := manager.AllowPolicy(policy, DeliveryTokenSubject(token.ID)); !allowed {
+				c.Header("Retry-After", retryAfterSeconds(retryAfter))
+				c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+					"error":          "delivery token rate limit exceeded",
+					"max_requests":   policy.Requests,
+					"window_seconds": policy.WindowSeconds,
+				})
+				return
+			}
+		}
+
+		if quotas != nil && !allowAPIQuota(c, quotas, DeliveryTokenSubject(token.ID), token.RequestsPerDay) {
+			return
+		}
+
+		c.Set(deliveryTokenContextKey, token.ID)
+		c.Header("Cache-Control", deliveryCacheControl)
+		c.Header("Pragma", "")
+		c.Header("Expires", "")
+		c.Next()
+	}
+}
+
+// IsDeliveryRequest reports whether the request came in with a delivery token,
+// for handlers that must then hide unpublished content.
+func IsDeliveryRequest(c *gin.Context) bool {
+	return c.GetUint(deliveryTokenContextKey) != 0
+}
+
+// DeliveryTokenID returns the ID of the delivery token of the request, or zero.
+func DeliveryTokenID(c *gin.Context) uint {
+	return c.GetUint(deliveryTokenContextKey)
+}
+
+// DeliveryTokenSubject names a delivery token in rate limits and API usage.
+func DeliveryTokenSubject(id uint) string {
+	return "token:" + strconv.FormatUint(uint64(id), 10)
+}
+
+func deliveryTokenValue(r *http.Request) string {
+	if value := strings.TrimSpace(r.Header.Get(DeliveryTokenHeader)); value != "" {
+		return value
+	}
+	if value, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); found {
+		return strings.TrimSpace(value)
+	}
+	return ""
+}
@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"constructor-script-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+type staticDeliveryTokens map[string]models.DeliveryToken
+
+func (s staticDeliveryTokens) AuthenticateDeliveryToken(value string) (*models.DeliveryToken, bool) {
+	token, ok := s[value]
+	return &token, ok
+}
+
+func TestDeliveryTokenMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	manager := NewRateLimitManager(context.Background())
+	defer manager.Shutdown()
+
+	tokens := staticDeliveryTokens{"cdt_valid": {ID: 7, RequestsPerMinute: 2}}
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set("rateLimitManager", manager) })
+	router.GET("/api/v1/delivery/posts", DeliveryTokenMiddleware(tokens, nil, nil), func(c *gin.Context) {
+		if !IsDeliveryRequest(c) {
+			t.Error("expected the request to be marked as a delivery request")
+		}
+		c.JSON(http.StatusOK, gin.H{"posts": []string{}})
+	})
+
+	send := func(header, value string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, "/api/v1/delivery/posts", nil)
+		if header != "" {
+			request.Header.Set(header, value)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	if recorder := send("", ""); recorder.Code != http.StatusUnauthorized {
+		t.Fatalf("expected a request without a token to be rejected, got %d", recorder.Code)
+	}
+	if recorder := send(DeliveryTokenHeader, "cdt_unknown"); recorder.Code != http.StatusUnauthorized {
+		t.Fatalf("expected an unknown token to be rejected, got %d", recorder.Code)
+	}
+
+	recorder := send(DeliveryTokenHeader, "cdt_valid")
+	if recorder.Code != http.StatusOK || recorder.Header().Get("Cache-Control") != deliveryCacheControl {
+		t.Fatalf("unexpected response %d %v", recorder.Code, recorder.Header())
+	}
+	if recorder := send("Authorization", "Bearer cdt_valid"); recorder.Code != http.StatusOK {
+		t.Fatalf("expected a bearer token to be accepted, got %d", recorder.Code)
+	}
+	if recorder := send(DeliveryTokenHeader, "cdt_valid"); recorder.Code != http.StatusTooManyRequests || recorder.Header().Get("Retry-After") == "" {
+		t.Fatalf("expected the token allowance to run out, got %d", recorder.Code)
+	}
+}
+
+func TestDeliveryRequestsBypassGlobalRateLimit(t *testing.T) {
+	if !shouldBypassRateLimit(httptest.NewRequest(http.MethodGet, "/api/v1/delivery/posts", nil)) {
+		t.Fatal("expected delivery reads to skip the per-IP limit")
+	}
+	if shouldBypassRateLimit(httptest.NewRequest(http.MethodGet, "/api/v1/posts", nil)) {
+		t.Fatal("expected other API reads to keep the per-IP limit")
+	}
+}
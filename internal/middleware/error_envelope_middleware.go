@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"constructor-script-backend/pkg/apierror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrorEnvelopeMiddleware completes the JSON error responses of paths under
+// prefix to the apierror envelope: it adds the code matching the status unless
+// the handler named one, and the request id. Other responses pass through
+// unbuffered.
+func ErrorEnvelopeMiddleware(prefix string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !strings.HasPrefix(c.Request.URL.Path, prefix) {
+			c.Next()
+			return
+		}
+
+		writer := &errorEnvelopeWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		if writer.buffer == nil {
+			return
+		}
+		body := completeErrorEnvelope(writer.buffer.Bytes(), writer.Status(), c.GetString("request_id"))
+		writer.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		_, _ = writer.ResponseWriter.Write(body)
+	}
+}
+
+// errorEnvelopeWriter holds back the body of JSON error responses, which gin
+// writes in one piece, until the envelope is complete.
+type errorEnvelopeWriter struct {
+	gin.ResponseWriter
+	buffer *bytes.Buffer
+}
+
+func (w *errorEnvelopeWriter) Write(data []byte) (int, error) {
+	if w.buffer == nil && !w.Written() && w.Status() >= http.StatusBadRequest &&
+		strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+		w.buffer = &bytes.Buffer{}
+	}
+	if w.buffer != nil {
+		return w.buffer.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *errorEnvelopeWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func completeErrorEnvelope(body []byte, status int, requestID string) []byte {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return body
+	}
+	if _, ok := fields["error"]; !ok {
+		return body
+	}
+
+	if _, ok := fields["code"]; !ok {
+		fields["code"], _ = json.Marshal(apierror.CodeForStatus(status))
+	}
+	if _, ok := fields["request_id"]; !ok && requestID != "" {
+		fields["request_id"], _ = json.Marshal(requestID)
+	}
+
+	completed, err := json.Marshal(fields)
+	if err != nil {
+		return body
+	}
+	return completed
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestErrorEnvelopeMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestIDMiddleware(), ErrorEnvelopeMiddleware("/api/"))
+	router.GET("/api/missing", func(c *gin.Context) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
+	})
+	router.GET("/api/conflict", func(c *gin.Context) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Slug taken", "code": "slug_taken"})
+	})
+	router.GET("/api/ok", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"error": "not an error"})
+	})
+	router.GET("/page", func(c *gin.Context) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Page not found"})
+	})
+
+	serve := func(path string) (*httptest.ResponseRecorder, map[string]string) {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodGet, path, nil)
+		request.Header.Set("X-Request-ID", "req-1")
+		router.ServeHTTP(recorder, request)
+		var body map[string]string
+		if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+			t.Fatalf("%s: invalid body %q", path, recorder.Body.String())
+		}
+		return recorder, body
+	}
+
+	recorder, body := serve("/api/missing")
+	if recorder.Code != http.StatusNotFound || body["code"] != "not_found" || body["request_id"] != "req-1" || body["error"] != "Post not found" {
+		t.Fatalf("unexpected envelope %v", body)
+	}
+	if recorder.Header().Get("Content-Length") != strconv.Itoa(recorder.Body.Len()) {
+		t.Fatalf("Content-Length %q does not match the body", recorder.Header().Get("Content-Length"))
+	}
+
+	if _, body := serve("/api/conflict"); body["code"] != "slug_taken" {
+		t.Fatalf("expected the handler's code to be kept, got %v", body)
+	}
+	if _, body := serve("/api/ok"); body["code"] != "" {
+		t.Fatalf("expected successful responses to pass through, got %v", body)
+	}
+	if _, body := serve("/page"); body["code"] != "" {
+		t.Fatalf("expected paths outside the prefix to pass through, got %v", body)
+	}
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HeadlessModeSource reports whether the site runs headless and the URL of the
+// frontend that renders it then.
+type HeadlessModeSource interface {
+	HeadlessMode() (enabled bool, frontendURL string)
+}
+
+// HeadlessMiddleware guards the rendered pages. In headless mode it sends their
+// visitors to the same path on the frontend, or answers 404 when none is set.
+func HeadlessMiddleware(source HeadlessModeSource) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if HandleHeadless(c, source) {
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// HandleHeadless answers the request as HeadlessMiddleware does and reports
+// whether it did, for handlers outside a guarded group.
+func HandleHeadless(c *gin.Context, source HeadlessModeSource) bool {
+	if source == nil {
+		return false
+	}
+	enabled, frontendURL := source.HeadlessMode()
+	if !enabled {
+		return false
+	}
+
+	if frontendURL != "" {
+		c.Redirect(http.StatusFound, frontendURL+c.Request.URL.RequestURI())
+		return true
+	}
+	c.Header("X-Robots-Tag", "noindex, nofollow")
+	c.JSON(http.StatusNotFound, gin.H{
+		"error": "The site runs in headless mode; its content is served by the API",
+		"path":  c.Request.URL.Path,
+	})
+	return true
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type staticHeadlessMode struct {
+	enabled  bool
+	frontend string
+}
+
+func (m staticHeadlessMode) HeadlessMode() (bool, string) {
+	return m.enabled, m.frontend
+}
+
+func TestHeadlessMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cases := []struct {
+		name     string
+		mode     staticHeadlessMode
+		status   int
+		location string
+	}{
+		{name: "off", mode: staticHeadlessMode{}, status: http.StatusOK},
+		{name: "redirect", mode: staticHeadlessMode{enabled: true, frontend: "https://www.example.com"}, status: http.StatusFound, location: "https://www.example.com/blog?page=2"},
+		{name: "no frontend", mode: staticHeadlessMode{enabled: true}, status: http.StatusNotFound},
+	}
+	for _, tc := range cases {
+		router := gin.New()
+		router.GET("/blog", HeadlessMiddleware(tc.mode), func(c *gin.Context) { c.String(http.StatusOK, "page") })
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/blog?page=2", nil))
+		if recorder.Code != tc.status || recorder.Header().Get("Location") != tc.location {
+			t.Errorf("%s: got %d %q, want %d %q", tc.name, recorder.Code, recorder.Header().Get("Location"), tc.status, tc.location)
+		}
+	}
+}
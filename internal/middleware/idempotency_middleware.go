@@ -0,0 +1,261 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"constructor-script-backend/pkg/cache"
+	"constructor-script-backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// IdempotencyKeyHeader carries the key a client picks for a request it may
+	// retry.
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader marks responses repeated from an earlier request.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	idempotencyTTL          = 24 * time.Hour
+	idempotencyLockTTL      = 2 * time.Minute
+	idempotencyMaxKeyLength = 255
+	// idempotencyMaxBody bounds the responses kept for replay; requests with
+	// larger responses are not protected.
+	idempotencyMaxBody = 1 << 20
+)
+
+// idempotentResponse is what a replay repeats, with the fingerprint of the
+// request that produced it.
+type idempotentResponse struct {
+	Fingerprint string `json:"fingerprint"`
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type,omitempty"`
+	Location    string `json:"location,omitempty"`
+	Body        []byte `json:"body"`
+}
+
+// IdempotencyStore keeps responses for replay. With a Redis-backed cache every
+// instance shares them; otherwise they live in this process.
+type IdempotencyStore struct {
+	cache *cache.Cache
+
+	mu       sync.Mutex
+	entries  map[string]idempotencyEntry
+	inFlight map[string]time.Time
+}
+
+type idempotencyEntry struct {
+	response idempotentResponse
+	expires  time.Time
+}
+
+func NewIdempotencyStore(cacheService *cache.Cache) *IdempotencyStore {
+	store := &IdempotencyStore{
+		entries:  make(map[string]idempotencyEntry),
+		inFlight: make(map[string]time.Time),
+	}
+	if cacheService.UsesRedis() {
+		store.cache = cacheService
+	}
+	return store
+}
+
+func (s *IdempotencyStore) load(key string) (idempotentResponse, bool) {
+	if s.cache != nil {
+		var response idempotentResponse
+		if err := s.cache.Get("idempotency:"+key, &response); err != nil {
+			return idempotentResponse{}, false
+		}
+		return response, true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[key]
+	if !ok || time.Now().After(entry.expires) {
+		return idempotentResponse{}, false
+	}
+	return entry.response, true
+}
+
+func (s *IdempotencyStore) save(key string, response idempotentResponse) {
+	if s.cache != nil {
+		if err := s.cache.Set("idempotency:"+key, response, idempotencyTTL); err != nil {
+			logger.Error(err, "Failed to store idempotent response", nil)
+		}
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for existing, entry := range s.entries {
+		if now.After(entry.expires) {
+			delete(s.entries, existing)
+		}
+	}
+	s.entries[key] = idempotencyEntry{response: response, expires: now.Add(idempotencyTTL)}
+}
+
+// acquire claims key for one request at a time; a claim left by a crashed
+// request lapses after idempotencyLockTTL.
+func (s *IdempotencyStore) acquire(key string) bool {
+	if s.cache != nil {
+		// One command sets the lock and its expiry, so a crash between the two
+		// cannot leave a lock that never lapses.
+		acquired, err := s.cache.SetIfAbsent("idempotency:lock:"+key, time.Now().Unix(), idempotencyLockTTL)
+		if err != nil {
+			logger.Error(err, "Failed to lock idempotency key", nil)
+			return true
+		}
+		return acquired
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if claimed, ok := s.inFlight[key]; ok && time.Since(claimed) < idempotencyLockTTL {
+		return false
+	}
+	s.inFlight[key] = time.Now()
+	return true
+}
+
+func (s *IdempotencyStore) release(key string) {
+	if s.cache != nil {
+		_ = s.cache.Delete("idempotency:lock:" + key)
+		return
+	}
+
+	s.mu.Lock()
+	delete(s.inFlight, key)
+	s.mu.Unlock()
+}
+
+// IdempotencyMiddleware makes retries of a request carrying an Idempotency-Key
+// safe: the first request runs, and later ones with the same key, user, path
+// and body get its response again instead of running twice. A key reused with
+// a different body is rejected, as is a retry while the first is still running.
+// Server errors are not kept, so a failed request can be retried with its key.
+func IdempotencyMiddleware(store *IdempotencyStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := strings.TrimSpace(c.GetHeader(IdempotencyKeyHeader))
+		if key == "" || store == nil {
+			c.Next()
+			return
+		}
+		if len(key) > idempotencyMaxKeyLength {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%s must be at most %d characters", IdempotencyKeyHeader, idempotencyMaxKeyLength)})
+			return
+		}
+
+		scope := idempotencyScope(c, key)
+		if stored, ok := store.load(scope); ok {
+			replayIdempotentResponse(c, stored)
+			return
+		}
+
+		if !store.acquire(scope) {
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "A request with this " + IdempotencyKeyHeader + " is still in progress"})
+			return
+		}
+		defer store.release(scope)
+		// The first request may have finished between the lookup above and
+		// the claim; its response is replayed rather than running again.
+		if stored, ok := store.load(scope); ok {
+			replayIdempotentResponse(c, stored)
+			return
+		}
+
+		fingerprint := sha256.New()
+		body := io.TeeReader(c.Request.Body, fingerprint)
+		c.Request.Body = readCloser{Reader: body, Closer: c.Request.Body}
+		writer := &idempotencyWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+
+		c.Next()
+
+		// Handlers may stop short of the end of the body; the fingerprint
+		// covers all of it, as the one of a retry does.
+		_, _ = io.Copy(io.Discard, body)
+
+		status := writer.Status()
+		if writer.overflow || status >= http.StatusInternalServerError || status == http.StatusConflict || status == http.StatusTooManyRequests {
+			return
+		}
+		store.save(scope, idempotentResponse{
+			Fingerprint: requestFingerprint(c, fingerprint),
+			Status:      status,
+			ContentType: writer.Header().Get("Content-Type"),
+			Location:    writer.Header().Get("Location"),
+			Body:        writer.body.Bytes(),
+		})
+	}
+}
+
+func replayIdempotentResponse(c *gin.Context, stored idempotentResponse) {
+	fingerprint := sha256.New()
+	_, _ = io.Copy(fingerprint, c.Request.Body)
+	if requestFingerprint(c, fingerprint) != stored.Fingerprint {
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": IdempotencyKeyHeader + " was already used for a different request"})
+		return
+	}
+
+	c.Header(IdempotentReplayedHeader, "true")
+	if stored.Location != "" {
+		c.Header("Location", stored.Location)
+	}
+	c.Data(stored.Status, stored.ContentType, stored.Body)
+	c.Abort()
+}
+
+// idempotencyScope keeps the keys of different users and endpoints apart.
+func idempotencyScope(c *gin.Context, key string) string {
+	user := "anonymous"
+	if id := c.GetUint("user_id"); id != 0 {
+		user = fmt.Sprintf("user:%d", id)
+	}
+	sum := sha256.Sum256([]byte(user + "\n" + c.Request.Method + " " + c.Request.URL.Path + "\n" + key))
+	return hex.EncodeToString(sum[:])
+}
+
+func requestFingerprint(c *gin.Context, body hash.Hash) string {
+	return c.Request.URL.RawQuery + ":" + c.ContentType() + ":" + hex.EncodeToString(body.Sum(nil))
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// idempotencyWriter copies the response as it is written, up to
+// idempotencyMaxBody.
+type idempotencyWriter struct {
+	gin.ResponseWriter
+	body     bytes.Buffer
+	overflow bool
+}
+
+func (w *idempotencyWriter) Write(data []byte) (int, error) {
+	if !w.overflow {
+		if w.body.Len()+len(data) > idempotencyMaxBody {
+			w.overflow = true
+			w.body.Reset()
+		} else {
+			w.body.Write(data)
+		}
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *idempotencyWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestIdempotencyMiddlewareReplaysResponse(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	created := 0
+	router := gin.New()
+	router.POST("/posts", IdempotencyMiddleware(NewIdempotencyStore(nil)), func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		created++
+		c.JSON(http.StatusCreated, gin.H{"id": created, "title": string(body)})
+	})
+
+	send := func(key, body string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodPost, "/posts", strings.NewReader(body))
+		request.Header.Set("Content-Type", "application/json")
+		if key != "" {
+			request.Header.Set(IdempotencyKeyHeader, key)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	first := send("key-1", "hello")
+	if first.Code != http.StatusCreated || first.Header().Get(IdempotentReplayedHeader) != "" {
+		t.Fatalf("unexpected first response %d %v", first.Code, first.Header())
+	}
+
+	retry := send("key-1", "hello")
+	if retry.Code != http.StatusCreated || retry.Body.String() != first.Body.String() || retry.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Fatalf("expected a replay, got %d %s", retry.Code, retry.Body.String())
+	}
+	if created != 1 {
+		t.Fatalf("expected the handler to run once, ran %d times", created)
+	}
+
+	if reused := send("key-1", "other"); reused.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected a key reused for another body to be rejected, got %d", reused.Code)
+	}
+
+	send("key-2", "hello")
+	send("", "hello")
+	if created != 3 {
+		t.Fatalf("expected new and missing keys to run the handler, ran %d times", created)
+	}
+}
+
+func TestIdempotencyMiddlewareSkipsServerErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	calls := 0
+	router := gin.New()
+	router.POST("/checkout", IdempotencyMiddleware(NewIdempotencyStore(nil)), func(c *gin.Context) {
+		calls++
+		if calls == 1 {
+			c.JSON(http.StatusBadGateway, gin.H{"error": "payment provider unavailable"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"url": "https://checkout.example.com"})
+	})
+
+	for i := 0; i < 3; i++ {
+		request := httptest.NewRequest(http.MethodPost, "/checkout", strings.NewReader("{}"))
+		request.Header.Set(IdempotencyKeyHeader, "retry")
+		router.ServeHTTP(httptest.NewRecorder(), request)
+	}
+	if calls != 2 {
+		t.Fatalf("expected the failed request to be retried once and the success replayed, ran %d times", calls)
+	}
+}
+
+func TestIdempotencyStoreClaimsKeyOnce(t *testing.T) {
+	store := NewIdempotencyStore(nil)
+	if !store.acquire("key") || store.acquire("key") {
+		t.Fatal("expected only the first claim to succeed")
+	}
+	store.release("key")
+	if !store.acquire("key") {
+		t.Fatal("expected the key to be free after release")
+	}
+}
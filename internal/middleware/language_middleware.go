@@ -207,15 +207,20 @@ func parseAcceptLanguage(header string) []string {
 }
 
 // LanguageNegotiationMiddleware resolves the most appropriate language for the
-// incoming request using an explicit "lang" query parameter or the
-// Accept-Language header. The resolved language and the list of supported
+// incoming request using the URL language prefix (see lang.WithPathLanguage), an
+// explicit "lang" query parameter or the Accept-Language header. The resolved language and the list of supported
 // languages are stored in the request context under the keys "language" and
 // "supported_languages" respectively.
 func LanguageNegotiationMiddleware(languageServiceProvider func() *languageservice.LanguageService) gin.HandlerFunc {
 	resolver := newLanguageResolver(languageServiceProvider)
 
 	return func(c *gin.Context) {
-		language, supported := resolver.resolve(c.Query("lang"), c.GetHeader("Accept-Language"))
+		explicit := c.Query("lang")
+		if prefixed := lang.PathLanguage(c.Request.Context()); prefixed != "" {
+			explicit = prefixed
+		}
+
+		language, supported := resolver.resolve(explicit, c.GetHeader("Accept-Language"))
 		if len(supported) == 0 {
 			supported = []string{language}
 		}
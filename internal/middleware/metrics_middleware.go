@@ -1,9 +1,16 @@
 package middleware
 
 import (
-	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"constructor-script-backend/pkg/logger"
+	"constructor-script-backend/pkg/utils"
+
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
@@ -15,7 +22,7 @@ var (
 			Name: "http_requests_total",
 			Help: "Total number of HTTP requests",
 		},
-		[]string{"method", "endpoint", "status"},
+		[]string{"method", "endpoint", "status", "status_class", "plugin"},
 	)
 
 	httpRequestDuration = promauto.NewHistogramVec(
@@ -24,7 +31,7 @@ var (
 			Help:    "HTTP request duration in seconds",
 			Buckets: prometheus.DefBuckets,
 		},
-		[]string{"method", "endpoint"},
+		[]string{"method", "endpoint", "status_class", "plugin"},
 	)
 
 	httpRequestSize = promauto.NewHistogramVec(
@@ -46,28 +53,91 @@ var (
 	)
 )
 
-func MetricsMiddleware() gin.HandlerFunc {
+// unmatchedEndpoint labels requests that matched no route, so scanners probing
+// random paths cannot create a series per path.
+const unmatchedEndpoint = "unmatched"
+
+// requestActivity backs the daily request and error rates on the admin dashboard.
+var requestActivity = utils.NewDailyCounter(90)
+
+// DailyRequests returns the requests this process served, and how many of them
+// failed with a server error, on the UTC day containing day.
+func DailyRequests(day time.Time) (requests, serverErrors int64) {
+	return requestActivity.Count("requests", day), requestActivity.Count("server_errors", day)
+}
+
+// requestTotals count every request and server error since the process started.
+var requestTotals, serverErrorTotals atomic.Int64
+
+// RequestTotals returns the requests this process served since it started, and how
+// many of them failed with a server error.
+func RequestTotals() (requests, serverErrors int64) {
+	return requestTotals.Load(), serverErrorTotals.Load()
+}
+
+// handlerPlugins caches the plugin owning each route's handler, keyed by method and
+// route template.
+var handlerPlugins sync.Map
+
+// MetricsMiddleware records request metrics, labelled by route template, status
+// class and the plugin serving the route. With QueryCounter installed it also
+// records the database statements each request ran and warns about requests that
+// ran more than queryBudget of them; a budget of zero disables the warning.
+func MetricsMiddleware(queryBudget int) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
 		path := c.FullPath()
 		if path == "" {
-			path = c.Request.URL.Path
+			path = unmatchedEndpoint
 		}
+		queries := beginRequestQueries()
+		// Deferred so that a panicking handler does not leave the request counted as
+		// in flight, which would stop every later count.
+		defer func() {
+			count, ok := queries.end()
+			if !ok {
+				return
+			}
+			httpRequestDBQueries.WithLabelValues(c.Request.Method, path).Observe(float64(count))
+			if queryBudget > 0 && count > int64(queryBudget) {
+				httpRequestDBQueryBudgetExceeded.WithLabelValues(c.Request.Method, path).Inc()
+				logger.Warn("Request exceeded the database query budget", map[string]interface{}{
+					"method":  c.Request.Method,
+					"path":    path,
+					"queries": count,
+					"budget":  queryBudget,
+				})
+			}
+		}()
 
 		c.Next()
 
 		duration := time.Since(start).Seconds()
-		status := fmt.Sprintf("%d", c.Writer.Status())
+		statusCode := c.Writer.Status()
+		status := strconv.Itoa(statusCode)
+		class := statusClass(statusCode)
+		plugin := routePlugin(c, path)
+
+		requestActivity.Add("requests", 1)
+		requestTotals.Add(1)
+		if statusCode >= http.StatusInternalServerError {
+			requestActivity.Add("server_errors", 1)
+			serverErrorTotals.Add(1)
+		}
 
 		httpRequestsTotal.WithLabelValues(
 			c.Request.Method,
 			path,
 			status,
+			class,
+			plugin,
 		).Inc()
 
 		httpRequestDuration.WithLabelValues(
 			c.Request.Method,
 			path,
+			class,
+			plugin,
 		).Observe(duration)
 
 		httpRequestSize.WithLabelValues(
@@ -81,3 +151,46 @@ func MetricsMiddleware() gin.HandlerFunc {
 		).Observe(float64(c.Writer.Size()))
 	}
 }
+
+func statusClass(status int) string {
+	if status < 100 || status > 599 {
+		return "unknown"
+	}
+	return strconv.Itoa(status/100) + "xx"
+}
+
+// routePlugin names the plugin serving the request: the slug of an external plugin
+// route, the built-in plugin whose package under plugins/ defines the handler, or
+// "core".
+func routePlugin(c *gin.Context, route string) string {
+	if route == unmatchedEndpoint {
+		return "core"
+	}
+	if strings.HasPrefix(route, "/ext/:slug") {
+		if slug := c.Param("slug"); slug != "" {
+			return "ext:" + slug
+		}
+	}
+
+	key := c.Request.Method + " " + route
+	if plugin, ok := handlerPlugins.Load(key); ok {
+		return plugin.(string)
+	}
+	plugin := pluginFromHandlerName(c.HandlerName())
+	handlerPlugins.Store(key, plugin)
+	return plugin
+}
+
+// pluginFromHandlerName extracts "blog" from a handler name such as
+// constructor-script-backend/plugins/blog/handlers.(*PostHandler).Create-fm.
+func pluginFromHandlerName(name string) string {
+	_, rest, found := strings.Cut(name, "/plugins/")
+	if !found {
+		return "core"
+	}
+	plugin, _, found := strings.Cut(rest, "/")
+	if !found || plugin == "" {
+		return "core"
+	}
+	return plugin
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetricsMiddlewareLabelsRouteTemplates(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(MetricsMiddleware(0))
+	router.GET("/metrics-test/items/:id", func(c *gin.Context) {
+		c.Status(http.StatusNotFound)
+	})
+	router.Any("/ext/:slug/*path", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	for _, path := range []string{"/metrics-test/items/1", "/metrics-test/items/2", "/ext/shop/cart", "/no/such/path"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	if got := testutil.ToFloat64(httpRequestsTotal.WithLabelValues(http.MethodGet, "/metrics-test/items/:id", "404", "4xx", "core")); got != 2 {
+		t.Errorf("route template requests = %v, want 2", got)
+	}
+	if got := testutil.ToFloat64(httpRequestsTotal.WithLabelValues(http.MethodGet, "/ext/:slug/*path", "200", "2xx", "ext:shop")); got != 1 {
+		t.Errorf("external plugin requests = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(httpRequestsTotal.WithLabelValues(http.MethodGet, unmatchedEndpoint, "404", "4xx", "core")); got != 1 {
+		t.Errorf("unmatched requests = %v, want 1", got)
+	}
+}
+
+func TestPluginFromHandlerName(t *testing.T) {
+	cases := map[string]string{
+		"constructor-script-backend/plugins/blog/handlers.(*PostHandler).Create-fm": "blog",
+		"constructor-script-backend/internal/handlers.(*AuthHandler).Login-fm":      "core",
+		"constructor-script-backend/plugins/":                                       "core",
+	}
+	for name, want := range cases {
+		if got := pluginFromHandlerName(name); got != want {
+			t.Errorf("pluginFromHandlerName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
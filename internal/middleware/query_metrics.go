@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"gorm.io/gorm"
+)
+
+var (
+	dbQueriesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "db_queries_total",
+			Help: "Total number of database statements",
+		},
+		[]string{"operation"},
+	)
+
+	httpRequestDBQueries = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "http_request_db_queries",
+			Help:    "Database statements run while serving one HTTP request",
+			Buckets: []float64{1, 2, 5, 10, 20, 50, 100, 200},
+		},
+		[]string{"method", "endpoint"},
+	)
+
+	httpRequestDBQueryBudgetExceeded = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "http_request_db_query_budget_exceeded_total",
+			Help: "HTTP requests that ran more database statements than the query budget",
+		},
+		[]string{"method", "endpoint"},
+	)
+)
+
+// Queries are not tied to a request context, so a request's count is the change in
+// the process-wide total while it ran. Requests that overlapped another request are
+// not counted; on a busy server this samples the quiet moments, which is enough to
+// see an endpoint's count grow with the size of a page.
+var (
+	dbQueryCount     atomic.Int64
+	requestsInFlight atomic.Int64
+	requestsStarted  atomic.Int64
+)
+
+// QueryCounter is a gorm plugin counting every statement for MetricsMiddleware.
+type QueryCounter struct{}
+
+func (QueryCounter) Name() string {
+	return "query_counter"
+}
+
+func (QueryCounter) Initialize(db *gorm.DB) error {
+	callbacks := map[string]interface {
+		Register(name string, fn func(*gorm.DB)) error
+	}{
+		"create": db.Callback().Create().After("gorm:create"),
+		"query":  db.Callback().Query().After("gorm:query"),
+		"update": db.Callback().Update().After("gorm:update"),
+		"delete": db.Callback().Delete().After("gorm:delete"),
+		"row":    db.Callback().Row().After("gorm:row"),
+		"raw":    db.Callback().Raw().After("gorm:raw"),
+	}
+	for operation, processor := range callbacks {
+		counter := dbQueriesTotal.WithLabelValues(operation)
+		if err := processor.Register("query_counter:"+operation, func(*gorm.DB) {
+			dbQueryCount.Add(1)
+			counter.Inc()
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// requestQueries tracks the statements run during one request.
+type requestQueries struct {
+	startCount int64
+	started    int64
+	overlapped bool
+}
+
+func beginRequestQueries() requestQueries {
+	overlapped := requestsInFlight.Add(1) > 1
+	return requestQueries{
+		startCount: dbQueryCount.Load(),
+		started:    requestsStarted.Add(1),
+		overlapped: overlapped,
+	}
+}
+
+// end reports the number of statements the request ran, and false when another
+// request overlapped it and the count cannot be attributed.
+func (q requestQueries) end() (int64, bool) {
+	count := dbQueryCount.Load() - q.startCount
+	alone := !q.overlapped && requestsStarted.Load() == q.started
+	requestsInFlight.Add(-1)
+	return count, alone
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetricsMiddlewareCountsQueriesPerRequest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(MetricsMiddleware(2))
+	router.GET("/light", func(c *gin.Context) {
+		dbQueryCount.Add(1)
+		c.Status(http.StatusOK)
+	})
+	router.GET("/heavy", func(c *gin.Context) {
+		dbQueryCount.Add(5)
+		c.Status(http.StatusOK)
+	})
+
+	serve := func(path string) {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	serve("/light")
+	serve("/heavy")
+	if got := testutil.ToFloat64(httpRequestDBQueryBudgetExceeded.WithLabelValues(http.MethodGet, "/light")); got != 0 {
+		t.Fatalf("light request must stay within budget, got %v", got)
+	}
+	if got := testutil.ToFloat64(httpRequestDBQueryBudgetExceeded.WithLabelValues(http.MethodGet, "/heavy")); got != 1 {
+		t.Fatalf("expected the heavy request over budget once, got %v", got)
+	}
+
+	// Another request in flight makes the count unattributable.
+	requestsInFlight.Add(1)
+	serve("/heavy")
+	requestsInFlight.Add(-1)
+	if got := testutil.ToFloat64(httpRequestDBQueryBudgetExceeded.WithLabelValues(http.MethodGet, "/heavy")); got != 1 {
+		t.Fatalf("overlapping request must not be counted, got %v", got)
+	}
+	if inFlight := requestsInFlight.Load(); inFlight != 0 {
+		t.Fatalf("expected no requests in flight, got %d", inFlight)
+	}
+}
@@ -2,13 +2,25 @@ package middleware
 
 import (
 	"context"
+	"strconv"
 	"sync"
 	"time"
 
 	"golang.org/x/time/rate"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/pkg/logger"
 )
 
-// RateLimitManager manages rate limiters with lifecycle control
+// SlidingWindowStore counts requests in a store shared by every instance of the
+// application, such as Redis.
+type SlidingWindowStore interface {
+	AllowInWindow(key string, limit int, window time.Duration) (bool, time.Duration, error)
+}
+
+// RateLimitManager manages rate limiters with lifecycle control. With a shared
+// store set, limits are counted in it so they hold behind a load balancer; the
+// in-memory limiters remain the fallback when the store fails.
 type RateLimitManager struct {
 	visitors         map[string]*visitor
 	visitorsMu       sync.RWMutex
@@ -16,6 +28,12 @@ type RateLimitManager struct {
 	uploadLimitersMu sync.RWMutex
 	backupLimiters   map[string]*criticalOperationVisitor
 	backupLimitersMu sync.RWMutex
+	policyLimiters   map[string]*visitor
+	policyLimitersMu sync.Mutex
+	store            SlidingWindowStore
+	storeFailing     bool
+	storeMu          sync.RWMutex
+	onViolation      func(ip string)
 	ctx              context.Context
 	cancel           context.CancelFunc
 	wg               sync.WaitGroup
@@ -29,6 +47,7 @@ func NewRateLimitManager(ctx context.Context) *RateLimitManager {
 		visitors:       make(map[string]*visitor),
 		uploadLimiters: make(map[string]*criticalOperationVisitor),
 		backupLimiters: make(map[string]*criticalOperationVisitor),
+		policyLimiters: make(map[string]*visitor),
 		ctx:            managerCtx,
 		cancel:         cancel,
 	}
@@ -39,6 +58,152 @@ func NewRateLimitManager(ctx context.Context) *RateLimitManager {
 	return m
 }
 
+// SetStore counts requests in store instead of process memory. A nil store goes
+// back to the in-memory limiters.
+func (m *RateLimitManager) SetStore(store SlidingWindowStore) {
+	m.storeMu.Lock()
+	defer m.storeMu.Unlock()
+	m.store = store
+	m.storeFailing = false
+}
+
+// SetViolationHandler calls handler with the client IP of every request the
+// global and policy limits reject, so repeat offenders can be banned.
+func (m *RateLimitManager) SetViolationHandler(handler func(ip string)) {
+	m.storeMu.Lock()
+	defer m.storeMu.Unlock()
+	m.onViolation = handler
+}
+
+func (m *RateLimitManager) reportViolation(ip string) {
+	m.storeMu.RLock()
+	handler := m.onViolation
+	m.storeMu.RUnlock()
+	if handler != nil {
+		handler(ip)
+	}
+}
+
+// allowShared asks the shared store about key. It reports false in ok when there
+// is no store or it failed, and the caller should use its in-memory limiter.
+func (m *RateLimitManager) allowShared(key string, limit int, window time.Duration) (allowed bool, retryAfter time.Duration, ok bool) {
+	m.storeMu.RLock()
+	store := m.store
+	m.storeMu.RUnlock()
+	if store == nil {
+		return false, 0, false
+	}
+
+	allowed, retryAfter, err := store.AllowInWindow(key, limit, window)
+
+	// Log the transitions only; a Redis outage would otherwise log every request.
+	m.storeMu.Lock()
+	failing := m.storeFailing
+	m.storeFailing = err != nil
+	m.storeMu.Unlock()
+	if err != nil {
+		if !failing {
+			logger.Error(err, "Shared rate limit store failed, using in-memory limits", nil)
+		}
+		return false, 0, false
+	}
+	if failing {
+		logger.Info("Shared rate limit store recovered", nil)
+	}
+	return allowed, retryAfter, true
+}
+
+// AllowRequest reports whether ip may make another request under the global limit,
+// and when not, how long it should wait. The shared store enforces a sliding window
+// of requestsPerWindow, so burst only applies to the in-memory fallback.
+func (m *RateLimitManager) AllowRequest(ip string, requestsPerWindow int, windowSeconds int, burst int) (bool, time.Duration) {
+	if requestsPerWindow <= 0 {
+		return true, 0
+	}
+	if allowed, retryAfter, ok := m.allowShared("global:"+ip, requestsPerWindow, windowDuration(windowSeconds)); ok {
+		return allowed, retryAfter
+	}
+
+	limiter := m.GetVisitor(ip, requestsPerWindow, windowSeconds, burst)
+	return limiterAllows(limiter)
+}
+
+// AllowCriticalOperation is AllowRequest for the "upload" and "backup" limits.
+func (m *RateLimitManager) AllowCriticalOperation(ip string, operationType string, requestsPerWindow int, windowSeconds int) (bool, time.Duration) {
+	if requestsPerWindow <= 0 {
+		return true, 0
+	}
+	if operationType != "upload" && operationType != "backup" {
+		return true, 0
+	}
+	if allowed, retryAfter, ok := m.allowShared(operationType+":"+ip, requestsPerWindow, windowDuration(windowSeconds)); ok {
+		return allowed, retryAfter
+	}
+
+	limiter := m.GetCriticalOperationLimiter(ip, operationType, requestsPerWindow, windowSeconds)
+	return limiterAllows(limiter)
+}
+
+// AllowPolicy reports whether subject, a user or client IP key, may make another
+// request under policy.
+func (m *RateLimitManager) AllowPolicy(policy models.RateLimitPolicy, subject string) (bool, time.Duration) {
+	if policy.Requests <= 0 {
+		return true, 0
+	}
+	key := "policy:" + policy.Name + ":" + subject
+	window := windowDuration(policy.WindowSeconds)
+	if allowed, retryAfter, ok := m.allowShared(key, policy.Requests, window); ok {
+		return allowed, retryAfter
+	}
+
+	m.policyLimitersMu.Lock()
+	v, exists := m.policyLimiters[key]
+	if !exists {
+		limit := rate.Limit(float64(policy.Requests) / window.Seconds())
+		v = &visitor{limiter: rate.NewLimiter(limit, policy.Requests)}
+		m.policyLimiters[key] = v
+	}
+	v.lastSeen = time.Now()
+	m.policyLimitersMu.Unlock()
+
+	return limiterAllows(v.limiter)
+}
+
+func windowDuration(windowSeconds int) time.Duration {
+	if windowSeconds <= 0 {
+		windowSeconds = 60
+	}
+	return time.Duration(windowSeconds) * time.Second
+}
+
+// limiterAllows takes a token from limiter; when none is left it reports how long
+// until the next one.
+func limiterAllows(limiter *rate.Limiter) (bool, time.Duration) {
+	if limiter == nil {
+		return true, 0
+	}
+	reservation := limiter.Reserve()
+	if !reservation.OK() {
+		return false, 0
+	}
+	delay := reservation.Delay()
+	if delay == 0 {
+		return true, 0
+	}
+	reservation.Cancel()
+	return false, delay
+}
+
+// retryAfterSeconds formats d for the Retry-After header, rounding up so clients do
+// not come back a moment too early.
+func retryAfterSeconds(d time.Duration) string {
+	seconds := int((d + time.Second - 1) / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	return strconv.Itoa(seconds)
+}
+
 // GetVisitor retrieves or creates a rate limiter for the given IP
 func (m *RateLimitManager) GetVisitor(ip string, requestsPerWindow int, windowSeconds int, burst int) *rate.Limiter {
 	m.visitorsMu.Lock()
@@ -165,6 +330,15 @@ func (m *RateLimitManager) cleanup() {
 		}
 	}
 	m.backupLimitersMu.Unlock()
+
+	// Cleanup policy limiters (10 minute threshold)
+	m.policyLimitersMu.Lock()
+	for key, v := range m.policyLimiters {
+		if time.Since(v.lastSeen) > 10*time.Minute {
+			delete(m.policyLimiters, key)
+		}
+	}
+	m.policyLimitersMu.Unlock()
 }
 
 // Shutdown stops the cleanup goroutine and waits for it to finish
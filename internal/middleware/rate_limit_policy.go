@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"constructor-script-backend/internal/models"
+)
+
+// RateLimitPolicySource supplies the route policies configured in site settings.
+type RateLimitPolicySource interface {
+	RateLimitPolicies() []models.RateLimitPolicy
+}
+
+// RateLimitPolicyMiddleware applies every policy matching the request in addition
+// to the global limit of RateLimitMiddleware. Per-user policies count signed-in
+// users by their ID, so a user keeps one allowance across addresses and instances;
+// anonymous requests fall back to the client IP.
+func RateLimitPolicyMiddleware(source RateLimitPolicySource, jwtSecret string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if source == nil || shouldBypassRateLimit(c.Request) {
+			c.Next()
+			return
+		}
+
+		managerVal, exists := c.Get("rateLimitManager")
+		if !exists {
+			c.Next()
+			return
+		}
+		manager, ok := managerVal.(*RateLimitManager)
+		if !ok || manager == nil {
+			c.Next()
+			return
+		}
+
+		policies := source.RateLimitPolicies()
+		if len(policies) == 0 {
+			c.Next()
+			return
+		}
+
+		path := c.Request.URL.Path
+		method := c.Request.Method
+		for _, policy := range policies {
+			if !policyMatches(policy, method, path) {
+				continue
+			}
+
+			subject := "ip:" + c.ClientIP()
+			if policy.PerUser {
+				if userID, ok := requestUserID(c, jwtSecret); ok {
+					subject = "user:" + strconv.FormatUint(uint64(userID), 10)
+				}
+			}
+
+			allowed, retryAfter := manager.AllowPolicy(policy, subject)
+			if !allowed {
+				manager.reportViolation(c.ClientIP())
+				c.Header("Retry-After", retryAfterSeconds(retryAfter))
+				c.JSON(http.StatusTooManyRequests, gin.H{
+					"error":          "too many requests, please try again later",
+					"policy":         policy.Name,
+					"max_requests":   policy.Requests,
+					"window_seconds": policy.WindowSeconds,
+				})
+				c.Abort()
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
+
+func policyMatches(policy models.RateLimitPolicy, method, path string) bool {
+	if policy.PathPrefix == "" || !strings.HasPrefix(path, policy.PathPrefix) {
+		return false
+	}
+	if len(policy.Methods) == 0 {
+		return true
+	}
+	for _, allowed := range policy.Methods {
+		if strings.EqualFold(allowed, method) {
+			return true
+		}
+	}
+	return false
+}
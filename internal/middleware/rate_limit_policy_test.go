@@ -0,0 +1,146 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+
+	"constructor-script-backend/internal/models"
+)
+
+type staticPolicySource []models.RateLimitPolicy
+
+func (s staticPolicySource) RateLimitPolicies() []models.RateLimitPolicy {
+	return s
+}
+
+// countingStore stands in for Redis: a fixed window per key that never slides.
+type countingStore struct {
+	mu     sync.Mutex
+	counts map[string]int
+	err    error
+}
+
+func (s *countingStore) AllowInWindow(key string, limit int, window time.Duration) (bool, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return false, 0, s.err
+	}
+	if s.counts[key] >= limit {
+		return false, window, nil
+	}
+	s.counts[key]++
+	return true, 0, nil
+}
+
+func newPolicyTestRouter(manager *RateLimitManager, policies staticPolicySource, secret string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("rateLimitManager", manager)
+		c.Next()
+	})
+	router.Use(RateLimitPolicyMiddleware(policies, secret))
+	router.Any("/*path", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	return router
+}
+
+func TestRateLimitPolicyCountsPerUserAcrossInstances(t *testing.T) {
+	const secret = "test-secret"
+	store := &countingStore{counts: make(map[string]int)}
+	policies := staticPolicySource{{
+		Name:          "comments",
+		PathPrefix:    "/api/v1/comments",
+		Methods:       []string{"POST"},
+		Requests:      2,
+		WindowSeconds: 60,
+		PerUser:       true,
+	}}
+
+	// Two instances behind a load balancer share the store.
+	var routers []*gin.Engine
+	for i := 0; i < 2; i++ {
+		manager := NewRateLimitManager(context.Background())
+		t.Cleanup(func() { _ = manager.Shutdown() })
+		manager.SetStore(store)
+		routers = append(routers, newPolicyTestRouter(manager, policies, secret))
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": float64(7),
+		"exp":     float64(time.Now().Add(time.Hour).Unix()),
+	}).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+
+	send := func(router *gin.Engine, method, path, remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("Authorization", "Bearer "+token)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	if code := send(routers[0], http.MethodPost, "/api/v1/comments", "10.0.0.1:1").Code; code != http.StatusNoContent {
+		t.Fatalf("first request: got %d", code)
+	}
+	if code := send(routers[1], http.MethodPost, "/api/v1/comments", "10.0.0.2:1").Code; code != http.StatusNoContent {
+		t.Fatalf("second request: got %d", code)
+	}
+
+	limited := send(routers[0], http.MethodPost, "/api/v1/comments", "10.0.0.3:1")
+	if limited.Code != http.StatusTooManyRequests {
+		t.Fatalf("third request: got %d, want 429", limited.Code)
+	}
+	if got := limited.Header().Get("Retry-After"); got != "60" {
+		t.Fatalf("Retry-After = %q, want 60", got)
+	}
+
+	if code := send(routers[1], http.MethodGet, "/api/v1/comments", "10.0.0.1:1").Code; code != http.StatusNoContent {
+		t.Fatalf("GET is outside the policy: got %d", code)
+	}
+	if got := store.counts["policy:comments:user:"+strconv.Itoa(7)]; got != 2 {
+		t.Fatalf("store count = %d, want 2", got)
+	}
+}
+
+func TestRateLimitPolicyFallsBackToMemoryWhenStoreFails(t *testing.T) {
+	manager := NewRateLimitManager(context.Background())
+	t.Cleanup(func() { _ = manager.Shutdown() })
+	manager.SetStore(&countingStore{err: errors.New("connection refused")})
+
+	router := newPolicyTestRouter(manager, staticPolicySource{{
+		Name:          "search",
+		PathPrefix:    "/api/v1/search",
+		Requests:      1,
+		WindowSeconds: 60,
+	}}, "")
+
+	send := func() int {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/search?q=go", nil)
+		req.RemoteAddr = "10.0.0.1:1"
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	if code := send(); code != http.StatusNoContent {
+		t.Fatalf("first request: got %d", code)
+	}
+	if code := send(); code != http.StatusTooManyRequests {
+		t.Fatalf("second request: got %d, want 429", code)
+	}
+}
@@ -38,19 +38,16 @@ func RateLimitMiddleware(cfg *config.Config) gin.HandlerFunc {
 			return
 		}
 
-		limiter := manager.GetVisitor(
+		allowed, retryAfter := manager.AllowRequest(
 			c.ClientIP(),
 			cfg.RateLimitRequests,
 			cfg.RateLimitWindow,
 			cfg.RateLimitBurst,
 		)
 
-		if limiter == nil {
-			c.Next()
-			return
-		}
-
-		if !limiter.Allow() {
+		if !allowed {
+			manager.reportViolation(c.ClientIP())
+			c.Header("Retry-After", retryAfterSeconds(retryAfter))
 			c.JSON(http.StatusTooManyRequests, gin.H{
 				"error": "too many requests, please try again later",
 			})
@@ -86,6 +83,11 @@ func shouldBypassRateLimit(r *http.Request) bool {
 		}
 	}
 
+	// DeliveryTokenMiddleware limits these by token instead.
+	if strings.HasPrefix(path, DeliveryAPIPrefix) {
+		return true
+	}
+
 	switch path {
 	case "/favicon.ico":
 		return true
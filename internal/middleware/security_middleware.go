@@ -1,6 +1,8 @@
 package middleware
 
 import (
+	"crypto/rand"
+	"encoding/base64"
 	"sort"
 	"strings"
 
@@ -14,6 +16,19 @@ type ContentSecurityPolicySource interface {
 	ContentSecurityPolicyDirectives() models.ContentSecurityPolicyDirectives
 }
 
+// ContentSecurityPolicySettingsSource provides the admin-managed additions to
+// the policy.
+type ContentSecurityPolicySettingsSource interface {
+	ContentSecurityPolicySettings() models.ContentSecurityPolicySettings
+}
+
+// CSPNonceKey is the context key of the nonce inline scripts of the response
+// must carry, set when the nonce strategy is "per_request".
+const CSPNonceKey = "csp_nonce"
+
+// cspNoncePlaceholder stands in for the nonce in previews.
+const cspNoncePlaceholder = "{nonce}"
+
 type staticContentSecurityPolicySource struct {
 	directives models.ContentSecurityPolicyDirectives
 }
@@ -93,7 +108,14 @@ var cspDirectiveOrder = []string{
 	"frame-src",
 }
 
-func SecurityHeadersMiddleware(cfg *config.Config, sources ...ContentSecurityPolicySource) gin.HandlerFunc {
+// noStoreCacheControl is the default for every response; middleware further down
+// relaxes it for assets and revalidated pages.
+const noStoreCacheControl = "no-store, no-cache, must-revalidate, proxy-revalidate, max-age=0"
+
+// SecurityHeadersMiddleware sets the security headers of every response. The
+// Content-Security-Policy is the built-in policy extended by sources and, when
+// policy is set, by the admin-managed settings.
+func SecurityHeadersMiddleware(cfg *config.Config, policy ContentSecurityPolicySettingsSource, sources ...ContentSecurityPolicySource) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Header("X-Content-Type-Options", "nosniff")
 		c.Header("X-XSS-Protection", "1; mode=block")
@@ -105,7 +127,7 @@ func SecurityHeadersMiddleware(cfg *config.Config, sources ...ContentSecurityPol
 		c.Header("Cross-Origin-Embedder-Policy", "same-origin")
 		c.Header("Referrer-Policy", "no-referrer")
 		c.Header("Permissions-Policy", "geolocation=(), microphone=(), camera=()")
-		c.Header("Cache-Control", "no-store, no-cache, must-revalidate, proxy-revalidate, max-age=0")
+		c.Header("Cache-Control", noStoreCacheControl)
 		c.Header("Pragma", "no-cache")
 		c.Header("Expires", "0")
 
@@ -119,12 +141,73 @@ func SecurityHeadersMiddleware(cfg *config.Config, sources ...ContentSecurityPol
 			c.Writer.Header().Del("X-Frame-Options")
 		}
 
-		c.Header("Content-Security-Policy", buildContentSecurityPolicy(cfg, sources))
+		var settings models.ContentSecurityPolicySettings
+		if policy != nil {
+			settings = policy.ContentSecurityPolicySettings()
+		}
+		nonce := ""
+		if settings.NonceStrategy == models.CSPNoncePerRequest {
+			if nonce = newCSPNonce(); nonce != "" {
+				c.Set(CSPNonceKey, nonce)
+			}
+		}
+
+		enforced, reportOnly := contentSecurityPolicyHeaders(cfg, settings, nonce, sources)
+		c.Header("Content-Security-Policy", enforced)
+		if reportOnly != "" {
+			c.Header("Content-Security-Policy-Report-Only", reportOnly)
+		}
 
 		c.Next()
 	}
 }
 
+// PreviewContentSecurityPolicy returns the headers the settings would produce,
+// with a placeholder for the nonce.
+func PreviewContentSecurityPolicy(cfg *config.Config, settings models.ContentSecurityPolicySettings, sources ...ContentSecurityPolicySource) models.ContentSecurityPolicyPreview {
+	nonce := ""
+	if settings.NonceStrategy == models.CSPNoncePerRequest {
+		nonce = cspNoncePlaceholder
+	}
+	enforced, reportOnly := contentSecurityPolicyHeaders(cfg, settings, nonce, sources)
+	return models.ContentSecurityPolicyPreview{Enforced: enforced, ReportOnly: reportOnly}
+}
+
+// contentSecurityPolicyHeaders returns the Content-Security-Policy and
+// Content-Security-Policy-Report-Only values. In report-only mode the built-in
+// policy stays enforced and the extended one is only reported on.
+func contentSecurityPolicyHeaders(cfg *config.Config, settings models.ContentSecurityPolicySettings, nonce string, sources []ContentSecurityPolicySource) (string, string) {
+	extras := make(models.ContentSecurityPolicyDirectives, len(settings.Sources)+2)
+	for directive, values := range settings.Sources {
+		extras[directive] = values
+	}
+	if nonce != "" {
+		extras["script-src"] = append(append([]string(nil), extras["script-src"]...), "'nonce-"+nonce+"'")
+	}
+	if settings.ReportURI != "" {
+		extras["report-uri"] = []string{settings.ReportURI}
+	}
+	if len(extras) == 0 {
+		return buildContentSecurityPolicy(cfg, sources), ""
+	}
+
+	extended := make([]ContentSecurityPolicySource, 0, len(sources)+1)
+	extended = append(extended, sources...)
+	extended = append(extended, staticContentSecurityPolicySource{directives: extras})
+	if settings.ReportOnly {
+		return buildContentSecurityPolicy(cfg, sources), buildContentSecurityPolicy(cfg, extended)
+	}
+	return buildContentSecurityPolicy(cfg, extended), ""
+}
+
+func newCSPNonce() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return ""
+	}
+	return base64.StdEncoding.EncodeToString(buf)
+}
+
 func buildContentSecurityPolicy(cfg *config.Config, sources []ContentSecurityPolicySource) string {
 	directives := make(map[string]map[string]struct{}, len(baseContentSecurityPolicy))
 	for directive, values := range baseContentSecurityPolicy {
@@ -1,8 +1,14 @@
 package middleware
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"constructor-script-backend/internal/models"
 )
 
 func TestBuildContentSecurityPolicyAddsMediaSrc(t *testing.T) {
@@ -21,6 +27,70 @@ func TestBuildContentSecurityPolicyAddsMediaSrc(t *testing.T) {
 	}
 }
 
+type fixedContentSecurityPolicySettings models.ContentSecurityPolicySettings
+
+func (s fixedContentSecurityPolicySettings) ContentSecurityPolicySettings() models.ContentSecurityPolicySettings {
+	return models.ContentSecurityPolicySettings(s)
+}
+
+func TestSecurityHeadersApplyContentSecurityPolicySettings(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	settings := fixedContentSecurityPolicySettings{
+		Sources:       models.ContentSecurityPolicyDirectives{"script-src": {"https://cdn.example.com"}},
+		NonceStrategy: models.CSPNoncePerRequest,
+		ReportURI:     "/csp-reports",
+	}
+	serve := func(settings fixedContentSecurityPolicySettings) (http.Header, string) {
+		router := gin.New()
+		router.Use(SecurityHeadersMiddleware(nil, settings))
+		nonce := ""
+		router.GET("/", func(c *gin.Context) {
+			nonce = c.GetString(CSPNonceKey)
+			c.Status(http.StatusOK)
+		})
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+		return recorder.Header(), nonce
+	}
+
+	header, nonce := serve(settings)
+	if nonce == "" {
+		t.Fatal("expected a nonce in the context")
+	}
+	policy := parseContentSecurityPolicy(header.Get("Content-Security-Policy"))
+	for _, source := range []string{"'self'", "https://cdn.example.com", "'nonce-" + nonce + "'"} {
+		if _, ok := policy["script-src"][source]; !ok {
+			t.Fatalf("expected script-src to allow %s, got %v", source, policy["script-src"])
+		}
+	}
+	if _, ok := policy["report-uri"]["/csp-reports"]; !ok {
+		t.Fatalf("expected report-uri, got %v", policy["report-uri"])
+	}
+	if header.Get("Content-Security-Policy-Report-Only") != "" {
+		t.Fatal("expected no report-only policy")
+	}
+
+	if _, second := serve(settings); second == nonce {
+		t.Fatal("expected a new nonce for every request")
+	}
+
+	settings.ReportOnly = true
+	header, nonce = serve(settings)
+	if header.Get("Content-Security-Policy") != buildContentSecurityPolicy(nil, nil) {
+		t.Fatalf("expected the built-in policy to stay enforced, got %s", header.Get("Content-Security-Policy"))
+	}
+	reported := parseContentSecurityPolicy(header.Get("Content-Security-Policy-Report-Only"))
+	if _, ok := reported["script-src"]["'nonce-"+nonce+"'"]; !ok {
+		t.Fatalf("expected the report-only policy to carry the nonce, got %v", reported["script-src"])
+	}
+
+	preview := PreviewContentSecurityPolicy(nil, models.ContentSecurityPolicySettings(settings))
+	if !strings.Contains(preview.ReportOnly, "'nonce-{nonce}'") || preview.Enforced != buildContentSecurityPolicy(nil, nil) {
+		t.Fatalf("unexpected preview %+v", preview)
+	}
+}
+
 func parseContentSecurityPolicy(policy string) map[string]map[string]struct{} {
 	result := make(map[string]map[string]struct{})
 
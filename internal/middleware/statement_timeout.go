@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const statementTimeoutKey = "statement_timeout:restore"
+
+// StatementTimeout is a gorm plugin giving every statement a deadline, so a stuck
+// query fails instead of holding a pool connection indefinitely. Repositories do not
+// carry request contexts; statements whose context already has a deadline keep it.
+//
+// Row and Rows are left alone: their results are read after the callbacks return,
+// and cancelling the context then would close the rows under the caller.
+type StatementTimeout struct {
+	Timeout time.Duration
+}
+
+func (StatementTimeout) Name() string {
+	return "statement_timeout"
+}
+
+func (p StatementTimeout) Initialize(db *gorm.DB) error {
+	if p.Timeout <= 0 {
+		return nil
+	}
+
+	type registrar interface {
+		Register(name string, fn func(*gorm.DB)) error
+	}
+	callbacks := db.Callback()
+	hooks := map[string][2]registrar{
+		"create": {callbacks.Create().Before("gorm:create"), callbacks.Create().After("gorm:create")},
+		"query":  {callbacks.Query().Before("gorm:query"), callbacks.Query().After("gorm:query")},
+		"update": {callbacks.Update().Before("gorm:update"), callbacks.Update().After("gorm:update")},
+		"delete": {callbacks.Delete().Before("gorm:delete"), callbacks.Delete().After("gorm:delete")},
+		"raw":    {callbacks.Raw().Before("gorm:raw"), callbacks.Raw().After("gorm:raw")},
+	}
+	for operation, hook := range hooks {
+		if err := hook[0].Register("statement_timeout:start_"+operation, p.start); err != nil {
+			return err
+		}
+		if err := hook[1].Register("statement_timeout:end_"+operation, p.end); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type statementRestore struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func (p StatementTimeout) start(db *gorm.DB) {
+	ctx := db.Statement.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if _, ok := ctx.Deadline(); ok {
+		return
+	}
+
+	timed, cancel := context.WithTimeout(ctx, p.Timeout)
+	db.Statement.Settings.Store(statementTimeoutKey, statementRestore{ctx: db.Statement.Context, cancel: cancel})
+	db.Statement.Context = timed
+}
+
+// end restores the original context: gorm reuses the statement when a query is
+// built once and run several times, as with Count followed by Find.
+func (StatementTimeout) end(db *gorm.DB) {
+	value, ok := db.Statement.Settings.LoadAndDelete(statementTimeoutKey)
+	if !ok {
+		return
+	}
+	restore, ok := value.(statementRestore)
+	if !ok {
+		return
+	}
+	restore.cancel()
+	db.Statement.Context = restore.ctx
+}
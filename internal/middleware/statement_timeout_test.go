@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestStatementTimeoutBoundsEachStatement(t *testing.T) {
+	plugin := StatementTimeout{Timeout: time.Minute}
+	original := context.Background()
+	db := &gorm.DB{Statement: &gorm.Statement{Context: original}}
+
+	plugin.start(db)
+	timed := db.Statement.Context
+	if _, ok := timed.Deadline(); !ok {
+		t.Fatal("expected the statement to get a deadline")
+	}
+
+	plugin.end(db)
+	if db.Statement.Context != original {
+		t.Fatal("expected the original context to be restored for the next statement")
+	}
+	if timed.Err() == nil {
+		t.Fatal("expected the statement context to be released")
+	}
+
+	deadline := time.Now().Add(time.Second)
+	caller, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+	db.Statement.Context = caller
+	plugin.start(db)
+	if db.Statement.Context != caller {
+		t.Fatal("a caller's deadline must be kept")
+	}
+	plugin.end(db)
+	if caller.Err() != nil {
+		t.Fatal("the caller's context must not be cancelled")
+	}
+}
@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"io/fs"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	immutableAssetCacheControl = "public, max-age=31536000, immutable"
+	defaultAssetCacheControl   = "public, max-age=3600"
+	uploadCacheControl         = "public, no-cache"
+)
+
+// StaticCacheMiddleware replaces the global no-store policy for static assets.
+// Content-hashed files are cached for a year and marked immutable; other files get a
+// short public cache so that edits still propagate.
+func StaticCacheMiddleware(prefix string, isFingerprinted func(name string) bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := strings.TrimPrefix(c.Request.URL.Path, prefix)
+
+		cacheControl := defaultAssetCacheControl
+		if isFingerprinted != nil && isFingerprinted(name) {
+			cacheControl = immutableAssetCacheControl
+		}
+
+		header := c.Writer.Header()
+		header.Del("Pragma")
+		header.Del("Expires")
+		c.Header("Cache-Control", cacheControl)
+
+		c.Next()
+	}
+}
+
+// RevalidateUploadsMiddleware lets browsers keep uploaded files but check back with
+// the ETag before every use: uploads keep their name when replaced, so unlike
+// fingerprinted assets they can never be cached as immutable.
+func RevalidateUploadsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.Writer.Header()
+		header.Del("Pragma")
+		header.Del("Expires")
+		c.Header("Cache-Control", uploadCacheControl)
+		c.Next()
+	}
+}
+
+// ServeFile writes name from fsys through http.ServeContent with an ETag derived
+// from the file's size and modification time. ServeContent answers If-None-Match,
+// If-Modified-Since and Range requests, so browsers can revalidate cached files and
+// seek in video without downloading it whole. Directories are not listed.
+func ServeFile(c *gin.Context, fsys http.FileSystem, name string) {
+	if !strings.HasPrefix(name, "/") {
+		name = "/" + name
+	}
+	file, err := fsys.Open(path.Clean(name))
+	if err != nil {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil || info.IsDir() {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	if etag := fileETag(info); etag != "" && c.Writer.Header().Get("ETag") == "" {
+		c.Header("ETag", etag)
+	}
+	http.ServeContent(c.Writer, c.Request, info.Name(), info.ModTime(), file)
+}
+
+// fileETag is a strong validator so that If-Range keeps working for partial video
+// downloads. Files without a modification time, such as those built in memory by
+// the asset pipeline, get none; their fingerprinted names never change content.
+func fileETag(info fs.FileInfo) string {
+	modified := info.ModTime()
+	if modified.IsZero() || modified.Unix() <= 0 {
+		return ""
+	}
+	return `"` + strconv.FormatInt(modified.UnixNano(), 16) + "-" + strconv.FormatInt(info.Size(), 16) + `"`
+}
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestServeFileValidatorsAndRanges(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "clip.mp4"), []byte("0123456789"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "nested"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Header("Cache-Control", noStoreCacheControl)
+		c.Next()
+	})
+	router.Use(RevalidateUploadsMiddleware())
+	router.GET("/uploads/*filepath", func(c *gin.Context) {
+		ServeFile(c, http.Dir(dir), c.Param("filepath"))
+	})
+
+	get := func(path string, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	full := get("/uploads/clip.mp4", nil)
+	if full.Code != http.StatusOK || full.Body.String() != "0123456789" {
+		t.Fatalf("unexpected response %d %q", full.Code, full.Body.String())
+	}
+	if got := full.Header().Get("Cache-Control"); got != uploadCacheControl {
+		t.Fatalf("expected Cache-Control %q, got %q", uploadCacheControl, got)
+	}
+	etag := full.Header().Get("ETag")
+	if etag == "" || etag[0] != '"' {
+		t.Fatalf("expected a strong ETag, got %q", etag)
+	}
+	if full.Header().Get("Accept-Ranges") != "bytes" {
+		t.Fatalf("expected Accept-Ranges: bytes, got %q", full.Header().Get("Accept-Ranges"))
+	}
+
+	if cached := get("/uploads/clip.mp4", map[string]string{"If-None-Match": etag}); cached.Code != http.StatusNotModified {
+		t.Fatalf("expected 304 for matching ETag, got %d", cached.Code)
+	}
+
+	partial := get("/uploads/clip.mp4", map[string]string{"Range": "bytes=2-5", "If-Range": etag})
+	if partial.Code != http.StatusPartialContent || partial.Body.String() != "2345" {
+		t.Fatalf("expected 206 with bytes 2-5, got %d %q", partial.Code, partial.Body.String())
+	}
+
+	stale := get("/uploads/clip.mp4", map[string]string{"Range": "bytes=2-5", "If-Range": `"stale"`})
+	if stale.Code != http.StatusOK || stale.Body.Len() != 10 {
+		t.Fatalf("expected the whole file for a stale If-Range, got %d", stale.Code)
+	}
+
+	for _, path := range []string{"/uploads/nested", "/uploads/missing.mp4"} {
+		if recorder := get(path, nil); recorder.Code != http.StatusNotFound {
+			t.Fatalf("%s: expected 404, got %d", path, recorder.Code)
+		}
+	}
+}
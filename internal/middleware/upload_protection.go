@@ -14,6 +14,7 @@ var publicUploadExtensions = map[string]struct{}{
 	".png":  {},
 	".gif":  {},
 	".webp": {},
+	".avif": {},
 	".ico":  {},
 	".svg":  {},
 }
@@ -0,0 +1,75 @@
+package models
+
+import "time"
+
+const (
+	DataExportPending = "pending"
+	DataExportReady   = "ready"
+	DataExportFailed  = "failed"
+)
+
+// DataExport is an archive of everything the site stores about a user, built in
+// the background when they ask for it. The archive is kept in the database so
+// any instance can serve it, and removed once it expires.
+type DataExport struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	UserID      uint       `gorm:"not null;index" json:"-"`
+	Status      string     `gorm:"size:16;not null" json:"status"`
+	Error       string     `gorm:"type:text" json:"error,omitempty"`
+	Size        int64      `json:"size"`
+	Archive     []byte     `gorm:"type:bytea" json:"-"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	ExpiresAt   *time.Time `gorm:"index" json:"expires_at,omitempty"`
+}
+
+// What happens to the comments and forum posts of a deleted account.
+const (
+	AccountContentAnonymize = "anonymize"
+	AccountContentDelete    = "delete"
+)
+
+const (
+	AccountDeletionPending   = "pending"
+	AccountDeletionCompleted = "completed"
+	AccountDeletionRejected  = "rejected"
+)
+
+// AccountDeletionSettings decide how self-service account deletion works.
+// Anonymized content stays on the site under a placeholder account; deleted
+// content is removed with the replies to it. With RequireApproval users ask for
+// deletion and an admin carries it out.
+type AccountDeletionSettings struct {
+	ContentMode     string `json:"content_mode"`
+	RequireApproval bool   `json:"require_approval"`
+}
+
+type UpdateAccountDeletionSettingsRequest struct {
+	ContentMode     string `json:"content_mode"`
+	RequireApproval bool   `json:"require_approval"`
+}
+
+// AccountDeletionRequest records a user's request to delete their account. Once
+// it is carried out the username and email are cleared, so only the fact of the
+// deletion remains.
+type AccountDeletionRequest struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	UserID     uint       `gorm:"not null;index" json:"user_id"`
+	Username   string     `json:"username,omitempty"`
+	Email      string     `json:"email,omitempty"`
+	Reason     string     `gorm:"type:text" json:"reason,omitempty"`
+	Status     string     `gorm:"size:16;not null;index" json:"status"`
+	ReviewedBy *uint      `json:"reviewed_by,omitempty"`
+	ReviewedAt *time.Time `json:"reviewed_at,omitempty"`
+}
+
+// DeleteAccountRequest confirms account deletion with the user's password.
+type DeleteAccountRequest struct {
+	Password string `json:"password" binding:"required"`
+	Reason   string `json:"reason"`
+}
@@ -0,0 +1,69 @@
+package models
+
+import "time"
+
+const (
+	AnnouncementStyleInfo    = "info"
+	AnnouncementStyleSuccess = "success"
+	AnnouncementStyleWarning = "warning"
+	AnnouncementStyleDanger  = "danger"
+
+	AnnouncementAudienceAll    = "all"
+	AnnouncementAudienceGuests = "guests"
+	AnnouncementAudienceUsers  = "users"
+	AnnouncementAudienceAdmins = "admins"
+)
+
+// Announcement is a site-wide bar shown above the header of every themed page
+// while it is enabled and within its optional schedule.
+type Announcement struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Message     string     `gorm:"type:text;not null" json:"message"`
+	Style       string     `gorm:"not null;default:info" json:"style"`
+	LinkURL     string     `json:"link_url,omitempty"`
+	LinkText    string     `json:"link_text,omitempty"`
+	Audience    string     `gorm:"not null;default:all" json:"audience"`
+	Dismissible bool       `gorm:"not null;default:true" json:"dismissible"`
+	Enabled     bool       `gorm:"not null;default:true;index" json:"enabled"`
+	StartsAt    *time.Time `gorm:"index" json:"starts_at,omitempty"`
+	EndsAt      *time.Time `gorm:"index" json:"ends_at,omitempty"`
+}
+
+// AnnouncementDismissal records that a signed-in user closed an announcement.
+// Guests dismiss announcements in their browser only.
+type AnnouncementDismissal struct {
+	ID             uint      `gorm:"primarykey" json:"id"`
+	CreatedAt      time.Time `json:"created_at"`
+	UserID         uint      `gorm:"not null;uniqueIndex:idx_announcement_dismissal" json:"user_id"`
+	AnnouncementID uint      `gorm:"not null;uniqueIndex:idx_announcement_dismissal;index" json:"announcement_id"`
+}
+
+type CreateAnnouncementRequest struct {
+	Message     string     `json:"message" binding:"required"`
+	Style       string     `json:"style"`
+	LinkURL     string     `json:"link_url"`
+	LinkText    string     `json:"link_text"`
+	Audience    string     `json:"audience"`
+	Dismissible *bool      `json:"dismissible"`
+	Enabled     *bool      `json:"enabled"`
+	StartsAt    *time.Time `json:"starts_at"`
+	EndsAt      *time.Time `json:"ends_at"`
+}
+
+// UpdateAnnouncementRequest changes the fields given. ClearSchedule removes both
+// schedule bounds before StartsAt and EndsAt are applied.
+type UpdateAnnouncementRequest struct {
+	Message       *string    `json:"message"`
+	Style         *string    `json:"style"`
+	LinkURL       *string    `json:"link_url"`
+	LinkText      *string    `json:"link_text"`
+	Audience      *string    `json:"audience"`
+	Dismissible   *bool      `json:"dismissible"`
+	Enabled       *bool      `json:"enabled"`
+	StartsAt      *time.Time `json:"starts_at"`
+	EndsAt        *time.Time `json:"ends_at"`
+	ClearSchedule bool       `json:"clear_schedule"`
+}
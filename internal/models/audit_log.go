@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+// AuditLog records one change made through the admin API: who made it, from where,
+// to which route, and what it changed.
+type AuditLog struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+
+	UserID   uint   `gorm:"index" json:"user_id"`
+	Username string `json:"username"`
+	Role     string `gorm:"size:32" json:"role"`
+
+	Method string `gorm:"size:8;not null" json:"method"`
+	// Route is the matched route pattern, such as /api/v1/admin/posts/:id, and Path
+	// the requested path.
+	Route string `gorm:"not null;index" json:"route"`
+	Path  string `gorm:"not null" json:"path"`
+	// Resource is the first segment of the route after /admin, such as "posts", and
+	// ResourceID its :id parameter when it has one.
+	Resource   string `gorm:"size:64;index" json:"resource"`
+	ResourceID string `gorm:"size:64" json:"resource_id,omitempty"`
+	Status     int    `json:"status"`
+
+	// Before is the summary a handler recorded of the state it changed; After is the
+	// request body, with secrets redacted and long values shortened.
+	Before string `gorm:"type:text" json:"before,omitempty"`
+	After  string `gorm:"type:text" json:"after,omitempty"`
+
+	IP        string `gorm:"size:64" json:"ip"`
+	RequestID string `gorm:"size:64;index" json:"request_id"`
+}
+
+// AuditLogFilter narrows an audit log listing. Zero values match everything.
+type AuditLogFilter struct {
+	UserID   uint
+	Resource string
+	Method   string
+	Status   int
+	From     *time.Time
+	To       *time.Time
+	Page     int
+	Limit    int
+}
+
+type AuditLogSettings struct {
+	// RetentionDays is how long entries are kept; 0 keeps them forever.
+	RetentionDays int `json:"retention_days"`
+}
+
+type UpdateAuditLogSettingsRequest struct {
+	RetentionDays *int `json:"retention_days" binding:"required"`
+}
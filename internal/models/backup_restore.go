@@ -0,0 +1,86 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+const (
+	BackupRestoreSucceeded = "succeeded"
+	BackupRestoreFailed    = "failed"
+)
+
+// BackupRestore records one restore of a backup archive: who ran it, from which
+// archive, and which rows and uploads it added, removed or changed. Restores
+// replace the whole site, so the record is how operators find out afterwards what
+// a restore changed.
+type BackupRestore struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+
+	// UserID may no longer exist after the restore, so the username is kept too.
+	UserID   uint   `gorm:"index" json:"user_id"`
+	Username string `json:"username"`
+
+	Archive            string     `json:"archive"`
+	ArchiveSize        int64      `json:"archive_size"`
+	ArchiveSHA256      string     `gorm:"size:64" json:"archive_sha256"`
+	ArchiveGeneratedAt *time.Time `json:"archive_generated_at,omitempty"`
+	Application        string     `json:"application,omitempty"`
+	Encrypted          bool       `json:"encrypted"`
+
+	Status string `gorm:"size:16;not null;index" json:"status"`
+	Error  string `gorm:"type:text" json:"error,omitempty"`
+
+	Changes BackupRestoreChanges `gorm:"type:jsonb" json:"changes,omitempty"`
+}
+
+// BackupRestoreChanges describes a restore per table, keyed by table name, with
+// "uploads" for the upload directory.
+type BackupRestoreChanges map[string]BackupRestoreTableChanges
+
+// BackupRestoreTableChanges compares a table before and after a restore. Rows are
+// identified by id, settings by key, post tags by "post_id:tag_id" and uploads by
+// path; a row changed when its updated_at, or an upload when its size, differs.
+// Each list is cut at a limit, which Truncated reports.
+type BackupRestoreTableChanges struct {
+	Before    int      `json:"before"`
+	After     int      `json:"after"`
+	Added     []string `json:"added,omitempty"`
+	Removed   []string `json:"removed,omitempty"`
+	Changed   []string `json:"changed,omitempty"`
+	Truncated bool     `json:"truncated,omitempty"`
+}
+
+func (c BackupRestoreChanges) Value() (driver.Value, error) {
+	if len(c) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(c)
+}
+
+func (c *BackupRestoreChanges) Scan(value interface{}) error {
+	if value == nil {
+		*c = nil
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return errors.New("failed to scan BackupRestoreChanges")
+	}
+
+	var decoded BackupRestoreChanges
+	if err := json.Unmarshal(bytes, &decoded); err != nil {
+		return err
+	}
+	*c = decoded
+	return nil
+}
@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// Kinds of block rules, by what they match.
+const (
+	BlockRuleIP        = "ip"
+	BlockRuleCIDR      = "cidr"
+	BlockRuleUserAgent = "user_agent"
+	BlockRuleCountry   = "country"
+)
+
+// Block rule actions. Allow rules win over block rules, so an address can be
+// let through a blocked range or country.
+const (
+	BlockRuleActionBlock = "block"
+	BlockRuleActionAllow = "allow"
+)
+
+// Where block rules come from.
+const (
+	BlockRuleSourceManual    = "manual"
+	BlockRuleSourceRateLimit = "rate_limit"
+)
+
+// BlockRule refuses, or lets through, the requests matching it before any other
+// handling. Rules with ExpiresAt are temporary bans.
+type BlockRule struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Kind      string     `gorm:"not null;index" json:"kind"`
+	Value     string     `gorm:"not null" json:"value"`
+	Action    string     `gorm:"not null;default:block" json:"action"`
+	Note      string     `json:"note,omitempty"`
+	Source    string     `gorm:"not null;default:manual" json:"source"`
+	ExpiresAt *time.Time `gorm:"index" json:"expires_at,omitempty"`
+	Hits      int64      `gorm:"not null;default:0" json:"hits"`
+	LastHitAt *time.Time `json:"last_hit_at,omitempty"`
+}
+
+type CreateBlockRuleRequest struct {
+	Kind   string `json:"kind" binding:"required"`
+	Value  string `json:"value" binding:"required"`
+	Action string `json:"action"`
+	Note   string `json:"note"`
+	// DurationMinutes makes the rule a temporary ban.
+	DurationMinutes int `json:"duration_minutes"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import "encoding/json"
+
+// Bulk operation kinds.
+const (
+	BulkCreate = "create"
+	BulkUpdate = "update"
+	BulkDelete = "delete"
+)
+
+// BulkOperation is one change of a bulk request. Data holds the body the
+// single-record endpoint of the resource takes, such as a CreatePostRequest for
+// creating a post.
+type BulkOperation struct {
+	Op       string          `json:"op" binding:"required,oneof=create update delete"`
+	Resource string          `json:"resource" binding:"required"`
+	ID       uint            `json:"id,omitempty"`
+	Data     json.RawMessage `json:"data,omitempty"`
+}
+
+type BulkRequest struct {
+	Operations []BulkOperation `json:"operations" binding:"required,min=1,max=500,dive"`
+	// DryRun runs the operations and rolls them back, to check a batch.
+	DryRun bool `json:"dry_run"`
+}
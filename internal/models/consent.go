@@ -0,0 +1,87 @@
+package models
+
+import "time"
+
+// Built-in consent categories. Necessary cookies, such as the login session,
+// are always allowed; the others wait for the visitor to agree.
+const (
+	ConsentCategoryNecessary   = "necessary"
+	ConsentCategoryAnalytics   = "analytics"
+	ConsentCategoryAdvertising = "advertising"
+)
+
+// ConsentCategory is a group of cookies and scripts visitors accept or refuse
+// together.
+type ConsentCategory struct {
+	Key         string `json:"key"`
+	Label       string `json:"label"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required"`
+}
+
+// ConsentSettings configure the cookie consent banner. While it is disabled
+// every category counts as accepted, so ads and analytics load as before.
+type ConsentSettings struct {
+	Enabled bool `json:"enabled"`
+	// Version goes up when visitors must be asked again, for example after
+	// the policy changed. Consent given for an older version no longer counts.
+	Version    int               `json:"version"`
+	Message    string            `json:"message"`
+	PolicyURL  string            `json:"policy_url,omitempty"`
+	Categories []ConsentCategory `json:"categories"`
+	// AnalyticsSnippets are added to the page head once the visitor accepted
+	// analytics.
+	AnalyticsSnippets []string `json:"analytics_snippets,omitempty"`
+}
+
+// UpdateConsentSettingsRequest replaces the consent settings. Renew asks every
+// visitor again.
+type UpdateConsentSettingsRequest struct {
+	Enabled           bool              `json:"enabled"`
+	Message           string            `json:"message"`
+	PolicyURL         string            `json:"policy_url"`
+	Categories        []ConsentCategory `json:"categories"`
+	AnalyticsSnippets []string          `json:"analytics_snippets"`
+	Renew             bool              `json:"renew"`
+}
+
+// ConsentRecord is a choice a visitor made, kept as proof of consent. Visitors,
+// signed in or not, are told apart by the random ID stored in their consent
+// cookie.
+type ConsentRecord struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+
+	VisitorID string `gorm:"size:64;not null;index" json:"visitor_id"`
+	Version   int    `gorm:"not null" json:"version"`
+	// Accepted lists the accepted categories, separated by commas.
+	Accepted string `gorm:"size:512" json:"accepted"`
+}
+
+// UpdateConsentRequest is the choice a visitor made in the banner. Categories
+// left out are refused.
+type UpdateConsentRequest struct {
+	Categories map[string]bool `json:"categories"`
+}
+
+// ConsentState is what the theme needs to know about the current visitor's
+// consent.
+type ConsentState struct {
+	Enabled bool `json:"enabled"`
+	// Decided is false until the visitor answered the banner for the current
+	// version.
+	Decided    bool              `json:"decided"`
+	Version    int               `json:"version"`
+	Categories map[string]bool   `json:"categories"`
+	Available  []ConsentCategory `json:"available"`
+	Message    string            `json:"message,omitempty"`
+	PolicyURL  string            `json:"policy_url,omitempty"`
+}
+
+// Allowed reports whether the visitor accepted category.
+func (s ConsentState) Allowed(category string) bool {
+	if !s.Enabled {
+		return true
+	}
+	return s.Categories[category]
+}
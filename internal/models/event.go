@@ -0,0 +1,69 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Event is a calendar entry. Recurrence holds an iCalendar RRULE value such as
+// "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=10"; an empty value means a single occurrence.
+type Event struct {
+	ID        uint           `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+
+	Title       string    `gorm:"not null" json:"title"`
+	Slug        string    `gorm:"not null;uniqueIndex" json:"slug"`
+	Summary     string    `json:"summary"`
+	Description string    `gorm:"type:text" json:"description"`
+	Location    string    `json:"location"`
+	URL         string    `json:"url"`
+	Image       string    `json:"image"`
+	StartsAt    time.Time `gorm:"not null;index" json:"starts_at"`
+	EndsAt      time.Time `gorm:"not null" json:"ends_at"`
+	AllDay      bool      `gorm:"default:false" json:"all_day"`
+	Timezone    string    `json:"timezone"`
+	Recurrence  string    `json:"recurrence"`
+	Published   bool      `gorm:"default:false;index" json:"published"`
+}
+
+// EventOccurrence is a single instance of an event, expanded from its recurrence rule.
+type EventOccurrence struct {
+	Event    *Event    `json:"event"`
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+}
+
+type CreateEventRequest struct {
+	Title       string    `json:"title" binding:"required"`
+	Slug        string    `json:"slug"`
+	Summary     string    `json:"summary"`
+	Description string    `json:"description"`
+	Location    string    `json:"location"`
+	URL         string    `json:"url"`
+	Image       string    `json:"image"`
+	StartsAt    time.Time `json:"starts_at" binding:"required"`
+	EndsAt      time.Time `json:"ends_at" binding:"required"`
+	AllDay      bool      `json:"all_day"`
+	Timezone    string    `json:"timezone"`
+	Recurrence  string    `json:"recurrence"`
+	Published   *bool     `json:"published"`
+}
+
+type UpdateEventRequest struct {
+	Title       *string    `json:"title"`
+	Slug        *string    `json:"slug"`
+	Summary     *string    `json:"summary"`
+	Description *string    `json:"description"`
+	Location    *string    `json:"location"`
+	URL         *string    `json:"url"`
+	Image       *string    `json:"image"`
+	StartsAt    *time.Time `json:"starts_at"`
+	EndsAt      *time.Time `json:"ends_at"`
+	AllDay      *bool      `json:"all_day"`
+	Timezone    *string    `json:"timezone"`
+	Recurrence  *string    `json:"recurrence"`
+	Published   *bool      `json:"published"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// MediaMetadata stores editorial details for an upload in the media library. The
+// files themselves live in the upload directory, so rows are keyed by filename.
+type MediaMetadata struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Filename string `gorm:"not null;uniqueIndex" json:"filename"`
+	AltText  string `gorm:"type:text" json:"alt_text"`
+	// FocalX and FocalY locate the subject of an image as fractions of its width and
+	// height, so crops keep it in frame. Both are nil when no focal point is set.
+	FocalX *float64 `json:"focal_x,omitempty"`
+	FocalY *float64 `json:"focal_y,omitempty"`
+
+	// Video details are extracted in the background after upload.
+	DurationSeconds int    `json:"duration_seconds"`
+	Width           int    `json:"width"`
+	Height          int    `json:"height"`
+	PosterURL       string `json:"poster_url"`
+}
+
+// RemoteUpload is a file uploaded by the browser straight to object storage. It is
+// listed in the media library alongside files in the upload directory.
+type RemoteUpload struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	Filename    string `gorm:"not null;uniqueIndex" json:"filename"`
+	Key         string `gorm:"not null;uniqueIndex" json:"key"`
+	URL         string `gorm:"not null" json:"url"`
+	Size        int64  `json:"size"`
+	ContentType string `json:"content_type"`
+	Category    string `gorm:"not null" json:"category"`
+}
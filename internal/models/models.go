@@ -6,12 +6,14 @@ import (
 	"errors"
 	"net/url"
 	"path"
+	"sort"
 	"strings"
 	"time"
 
 	"gorm.io/gorm"
 
 	"constructor-script-backend/internal/authorization"
+	"constructor-script-backend/pkg/lang"
 )
 
 type User struct {
@@ -29,6 +31,8 @@ type User struct {
 
 	Status string `gorm:"default:'active'" json:"status"`
 
+	TwoFactorEnabled bool `gorm:"not null;default:false" json:"two_factor_enabled"`
+
 	Posts    []Post    `gorm:"foreignKey:AuthorID" json:"posts,omitempty"`
 	Comments []Comment `gorm:"foreignKey:AuthorID" json:"comments,omitempty"`
 }
@@ -56,6 +60,12 @@ type Category struct {
 
 	Order int `gorm:"default:0" json:"order"`
 
+	// NoIndex and NoFollow become the page's robots directives; SitemapExclude
+	// keeps the URL out of the sitemap while leaving it indexable.
+	NoIndex        bool `gorm:"default:false" json:"no_index"`
+	NoFollow       bool `gorm:"default:false" json:"no_follow"`
+	SitemapExclude bool `gorm:"default:false" json:"sitemap_exclude"`
+
 	Posts []Post `gorm:"foreignKey:CategoryID" json:"posts,omitempty"`
 }
 
@@ -88,6 +98,15 @@ type Post struct {
 	PublishedAt *time.Time `gorm:"index" json:"published_at,omitempty"`
 	Views       int        `gorm:"default:0" json:"views"`
 
+	// Language is the content's language code; empty means the site default.
+	// Posts sharing a TranslationKey are translations of each other.
+	Language       string `gorm:"size:16" json:"language,omitempty"`
+	TranslationKey string `gorm:"size:100;index" json:"translation_key,omitempty"`
+
+	NoIndex        bool `gorm:"default:false" json:"no_index"`
+	NoFollow       bool `gorm:"default:false" json:"no_follow"`
+	SitemapExclude bool `gorm:"default:false" json:"sitemap_exclude"`
+
 	Sections PostSections `gorm:"type:jsonb" json:"sections"`
 	Template string       `gorm:"default:'post'" json:"template"`
 
@@ -227,6 +246,8 @@ type CourseVideo struct {
 	FileURL         string `gorm:"not null" json:"file_url"`
 	Filename        string `gorm:"not null" json:"filename"`
 	DurationSeconds int    `gorm:"not null" json:"duration_seconds"`
+	// PosterURL is a frame extracted from the video after upload, empty until then.
+	PosterURL string `json:"poster_url"`
 
 	Sections     PostSections           `gorm:"type:jsonb" json:"sections"`
 	Attachments  CourseVideoAttachments `gorm:"type:jsonb" json:"attachments"`
@@ -260,6 +281,9 @@ type CourseTopic struct {
 
 	Videos []CourseVideo     `gorm:"-" json:"videos"`
 	Steps  []CourseTopicStep `gorm:"-" json:"steps"`
+	// Outline marks a topic whose steps carry only their titles; the course player
+	// fetches the full topic when it is opened.
+	Outline bool `gorm:"-" json:"outline,omitempty"`
 }
 
 type CoursePackage struct {
@@ -315,9 +339,34 @@ type CoursePackageAccess struct {
 	ExpiresAt *time.Time `gorm:"index" json:"expires_at,omitempty"`
 }
 
+// CourseAnnouncement is a message to everyone with access to a package. Learners
+// get it as an in-app notification, by email when Emailed is set, in the course
+// player and in their activity digests.
+type CourseAnnouncement struct {
+	ID        uint           `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+
+	PackageID uint   `gorm:"not null;index" json:"package_id"`
+	AuthorID  uint   `gorm:"not null" json:"author_id"`
+	Title     string `gorm:"not null" json:"title"`
+	Body      string `gorm:"type:text" json:"body"`
+	Emailed   bool   `gorm:"not null;default:false" json:"emailed"`
+}
+
+// CoursePackageGrant is an access grant with the account it was granted to, as
+// listed for admins.
+type CoursePackageGrant struct {
+	CoursePackageAccess
+	Username string `json:"username"`
+	Email    string `json:"email"`
+}
+
 type UserCoursePackage struct {
-	Package CoursePackage       `json:"package"`
-	Access  CoursePackageAccess `json:"access"`
+	Package       CoursePackage        `json:"package"`
+	Access        CoursePackageAccess  `json:"access"`
+	Announcements []CourseAnnouncement `json:"announcements"`
 }
 
 type CourseTopicVideo struct {
@@ -421,6 +470,14 @@ type CourseTestResult struct {
 	Answers  []byte `gorm:"type:jsonb" json:"answers"`
 }
 
+// CourseTestResultEntry is a test result with the account that submitted it, as
+// listed for admins.
+type CourseTestResultEntry struct {
+	CourseTestResult
+	Username string `json:"username"`
+	Email    string `json:"email"`
+}
+
 type CourseCheckoutRequest struct {
 	PackageID     uint   `json:"package_id" binding:"required,gt=0"`
 	CustomerEmail string `json:"customer_email" binding:"omitempty,email"`
@@ -456,6 +513,10 @@ type ResetPasswordRequest struct {
 type CreateCategoryRequest struct {
 	Name        string `json:"name" binding:"required"`
 	Description string `json:"description"`
+
+	NoIndex        bool `json:"no_index"`
+	NoFollow       bool `json:"no_follow"`
+	SitemapExclude bool `json:"sitemap_exclude"`
 }
 
 type CreateCommentRequest struct {
@@ -547,6 +608,13 @@ type GrantCoursePackageRequest struct {
 	ExpiresAt OptionalTime `json:"expires_at"`
 }
 
+type CreateCourseAnnouncementRequest struct {
+	Title string `json:"title" binding:"required,max=200"`
+	Body  string `json:"body" binding:"max=5000"`
+	// SendEmail also emails the announcement to everyone with access.
+	SendEmail bool `json:"send_email"`
+}
+
 type CourseTopicStepReference struct {
 	Type string `json:"type" binding:"required,oneof=video test content"`
 	ID   uint   `json:"id" binding:"required,gt=0"`
@@ -627,6 +695,8 @@ type AuthResponse struct {
 	Token     string `json:"token"`
 	User      User   `json:"user"`
 	CSRFToken string `json:"csrf_token,omitempty"`
+	// RecoveryCodes is set when the login set up two-factor authentication.
+	RecoveryCodes []string `json:"recovery_codes,omitempty"`
 }
 
 type PostSections []Section
@@ -680,6 +750,39 @@ type HeroContent struct {
 // Each directive maps to a slice of allowed source expressions that will be merged into the base policy.
 type ContentSecurityPolicyDirectives map[string][]string
 
+// Nonce strategies of the admin-managed Content-Security-Policy.
+const (
+	CSPNonceNone       = "none"
+	CSPNoncePerRequest = "per_request"
+)
+
+// ContentSecurityPolicySettings extends the built-in Content-Security-Policy
+// with the sources an admin allows. With ReportOnly the extended policy is
+// sent as Content-Security-Policy-Report-Only next to the built-in one, so
+// violations can be reviewed before it is enforced.
+type ContentSecurityPolicySettings struct {
+	Sources ContentSecurityPolicyDirectives `json:"sources"`
+	// NonceStrategy "per_request" adds a fresh nonce to script-src on every
+	// response. Browsers then ignore 'unsafe-inline' for scripts, so inline
+	// scripts run only when they carry the nonce.
+	NonceStrategy string `json:"nonce_strategy"`
+	ReportURI     string `json:"report_uri"`
+	ReportOnly    bool   `json:"report_only"`
+}
+
+type UpdateContentSecurityPolicyRequest struct {
+	Sources       ContentSecurityPolicyDirectives `json:"sources"`
+	NonceStrategy string                          `json:"nonce_strategy"`
+	ReportURI     string                          `json:"report_uri"`
+	ReportOnly    bool                            `json:"report_only"`
+}
+
+// ContentSecurityPolicyPreview is the header values a configuration results in.
+type ContentSecurityPolicyPreview struct {
+	Enforced   string `json:"enforced"`
+	ReportOnly string `json:"report_only,omitempty"`
+}
+
 type JSONMap map[string]interface{}
 
 func (m JSONMap) Value() (driver.Value, error) {
@@ -709,6 +812,81 @@ func (m *JSONMap) Scan(value interface{}) error {
 	return nil
 }
 
+// LocalizedText maps language codes to the translations of a short text.
+type LocalizedText map[string]string
+
+func (t LocalizedText) Value() (driver.Value, error) {
+	if len(t) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(map[string]string(t))
+}
+
+func (t *LocalizedText) Scan(value interface{}) error {
+	if value == nil {
+		*t = LocalizedText{}
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return errors.New("failed to scan LocalizedText")
+	}
+
+	var decoded map[string]string
+	if err := json.Unmarshal(bytes, &decoded); err != nil {
+		return err
+	}
+
+	*t = decoded
+	return nil
+}
+
+// Get returns the translation best matching code, or fallback when there is none.
+func (t LocalizedText) Get(code, fallback string) string {
+	if len(t) == 0 || code == "" {
+		return fallback
+	}
+	codes := make([]string, 0, len(t))
+	for candidate := range t {
+		codes = append(codes, candidate)
+	}
+	sort.Strings(codes)
+	if match := lang.Match(code, codes); match != "" {
+		if value := strings.TrimSpace(t[match]); value != "" {
+			return value
+		}
+	}
+	return fallback
+}
+
+// Normalize returns a copy keyed by normalized language codes, without blank
+// values. Invalid codes are reported as an error.
+func (t LocalizedText) Normalize() (LocalizedText, error) {
+	if len(t) == 0 {
+		return nil, nil
+	}
+	normalized := make(LocalizedText, len(t))
+	for code, value := range t {
+		key, err := lang.Normalize(code)
+		if err != nil {
+			return nil, err
+		}
+		if value = strings.TrimSpace(value); value != "" {
+			normalized[key] = value
+		}
+	}
+	if len(normalized) == 0 {
+		return nil, nil
+	}
+	return normalized, nil
+}
+
 type ImageGroupContent struct {
 	Images []ImageContent `json:"images"`
 	Layout string         `json:"layout"`
@@ -785,6 +963,13 @@ type CreatePostRequest struct {
 	Sections    []Section    `json:"sections"`
 	Template    string       `json:"template"`
 	PublishAt   OptionalTime `json:"publish_at"`
+
+	Language       string `json:"language"`
+	TranslationKey string `json:"translation_key"`
+
+	NoIndex        bool `json:"no_index"`
+	NoFollow       bool `json:"no_follow"`
+	SitemapExclude bool `json:"sitemap_exclude"`
 }
 
 type UpdatePostRequest struct {
@@ -799,6 +984,13 @@ type UpdatePostRequest struct {
 	Sections    *[]Section   `json:"sections"`
 	Template    *string      `json:"template"`
 	PublishAt   OptionalTime `json:"publish_at"`
+
+	Language       *string `json:"language"`
+	TranslationKey *string `json:"translation_key"`
+
+	NoIndex        *bool `json:"no_index"`
+	NoFollow       *bool `json:"no_follow"`
+	SitemapExclude *bool `json:"sitemap_exclude"`
 }
 
 type CreateForumQuestionRequest struct {
@@ -850,8 +1042,18 @@ type Page struct {
 	Content     string       `gorm:"type:text" json:"content"`
 	Sections    PostSections `gorm:"type:jsonb" json:"sections"`
 	Template    string       `gorm:"default:'page'" json:"template"`
+	Theme       string       `gorm:"size:100" json:"theme,omitempty"`
+	Layout      string       `gorm:"size:100" json:"layout,omitempty"`
 	HideHeader  bool         `gorm:"default:false" json:"hide_header"`
 
+	// Language and TranslationKey link translated pages, as on Post.
+	Language       string `gorm:"size:16" json:"language,omitempty"`
+	TranslationKey string `gorm:"size:100;index" json:"translation_key,omitempty"`
+
+	NoIndex        bool `gorm:"default:false" json:"no_index"`
+	NoFollow       bool `gorm:"default:false" json:"no_follow"`
+	SitemapExclude bool `gorm:"default:false" json:"sitemap_exclude"`
+
 	Order int `gorm:"default:0" json:"order"`
 }
 
@@ -865,9 +1067,18 @@ type CreatePageRequest struct {
 	Content     string       `json:"content"`
 	Sections    []Section    `json:"sections"`
 	Template    string       `json:"template"`
+	Theme       string       `json:"theme"`
+	Layout      string       `json:"layout"`
 	HideHeader  bool         `json:"hide_header"`
 	Order       int          `json:"order"`
 	PublishAt   OptionalTime `json:"publish_at"`
+
+	Language       string `json:"language"`
+	TranslationKey string `json:"translation_key"`
+
+	NoIndex        bool `json:"no_index"`
+	NoFollow       bool `json:"no_follow"`
+	SitemapExclude bool `json:"sitemap_exclude"`
 }
 
 type UpdatePageRequest struct {
@@ -879,15 +1090,42 @@ type UpdatePageRequest struct {
 	Content     *string      `json:"content"`
 	Sections    *[]Section   `json:"sections"`
 	Template    *string      `json:"template"`
+	Theme       *string      `json:"theme"`
+	Layout      *string      `json:"layout"`
 	HideHeader  *bool        `json:"hide_header"`
 	Order       *int         `json:"order"`
 	PublishAt   OptionalTime `json:"publish_at"`
+
+	Language       *string `json:"language"`
+	TranslationKey *string `json:"translation_key"`
+
+	NoIndex        *bool `json:"no_index"`
+	NoFollow       *bool `json:"no_follow"`
+	SitemapExclude *bool `json:"sitemap_exclude"`
 }
 
 type UpdateAllPageSectionsPaddingRequest struct {
 	PaddingVertical int `json:"padding_vertical"`
 }
 
+// SlugRedirect remembers a URL that a post or page was previously published under
+// so requests for it can be sent to the content's current location.
+type SlugRedirect struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Path        string `gorm:"size:512;not null;uniqueIndex" json:"path"`
+	ContentType string `gorm:"size:32;not null;index:idx_slug_redirects_content,priority:1" json:"content_type"`
+	ContentID   uint   `gorm:"not null;index:idx_slug_redirects_content,priority:2" json:"content_id"`
+}
+
+// Content types recorded in SlugRedirect.ContentType.
+const (
+	SlugRedirectPost = "post"
+	SlugRedirectPage = "page"
+)
+
 type Setting struct {
 	Key       string    `gorm:"primaryKey;size:191" json:"key"`
 	Value     string    `gorm:"type:text" json:"value"`
@@ -913,6 +1151,340 @@ type Plugin struct {
 	LastActivatedAt *time.Time `json:"last_activated_at"`
 }
 
+// StringList stores a list of strings as a JSON array.
+type StringList []string
+
+func (l StringList) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	return json.Marshal([]string(l))
+}
+
+func (l *StringList) Scan(value interface{}) error {
+	if value == nil {
+		*l = StringList{}
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return errors.New("failed to scan StringList")
+	}
+
+	var decoded []string
+	if err := json.Unmarshal(bytes, &decoded); err != nil {
+		return err
+	}
+
+	*l = decoded
+	return nil
+}
+
+// Webhook is an outgoing HTTP endpoint notified about subscribed events.
+type Webhook struct {
+	ID        uint           `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+
+	Name   string     `json:"name"`
+	URL    string     `gorm:"not null" json:"url"`
+	Secret string     `gorm:"not null" json:"-"`
+	Events StringList `gorm:"type:jsonb" json:"events"`
+	Active bool       `gorm:"default:false" json:"active"`
+}
+
+// Webhook delivery states.
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// WebhookDelivery records a single event sent (or to be sent) to a webhook.
+type WebhookDelivery struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	WebhookID      uint       `gorm:"index;not null" json:"webhook_id"`
+	Event          string     `gorm:"not null" json:"event"`
+	Payload        string     `gorm:"type:text" json:"payload"`
+	Status         string     `gorm:"index;not null" json:"status"`
+	Attempts       int        `gorm:"default:0" json:"attempts"`
+	ResponseStatus int        `json:"response_status,omitempty"`
+	ResponseBody   string     `gorm:"type:text" json:"response_body,omitempty"`
+	Error          string     `gorm:"type:text" json:"error,omitempty"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at,omitempty"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+}
+
+// Email delivery states. Bounced and complained are reported by the mail
+// provider after the message was sent; suppressed messages were never sent.
+const (
+	EmailDeliveryPending    = "pending"
+	EmailDeliverySent       = "sent"
+	EmailDeliveryFailed     = "failed"
+	EmailDeliveryBounced    = "bounced"
+	EmailDeliveryComplained = "complained"
+	EmailDeliverySuppressed = "suppressed"
+)
+
+// Reasons an address is on the suppression list.
+const (
+	EmailSuppressionBounce    = "bounce"
+	EmailSuppressionComplaint = "complaint"
+	EmailSuppressionManual    = "manual"
+)
+
+// EmailSuppression is an address no email is sent to, because it bounced
+// permanently, its owner marked a message as spam, or an admin added it.
+type EmailSuppression struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Email  string `gorm:"uniqueIndex;not null" json:"email"`
+	Reason string `gorm:"not null" json:"reason"`
+	Detail string `gorm:"type:text" json:"detail,omitempty"`
+}
+
+type CreateEmailSuppressionRequest struct {
+	Email  string `json:"email" binding:"required,email"`
+	Detail string `json:"detail"`
+}
+
+// EmailDelivery is a queued email and its send log entry. The bodies are kept so
+// failed messages can be retried, but never returned by the API, since they may
+// hold sign-in or download links.
+type EmailDelivery struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// DedupKey makes sure a message is only queued once, such as one receipt per
+	// checkout session.
+	DedupKey      *string    `gorm:"uniqueIndex" json:"-"`
+	Recipient     string     `gorm:"index;not null" json:"recipient"`
+	Subject       string     `gorm:"not null" json:"subject"`
+	Template      string     `gorm:"index" json:"template,omitempty"`
+	TextBody      string     `gorm:"type:text" json:"-"`
+	HTMLBody      string     `gorm:"type:text" json:"-"`
+	Unsubscribe   string     `json:"-"`
+	Status        string     `gorm:"index;not null" json:"status"`
+	Attempts      int        `gorm:"default:0" json:"attempts"`
+	Error         string     `gorm:"type:text" json:"error,omitempty"`
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty"`
+	SentAt        *time.Time `json:"sent_at,omitempty"`
+}
+
+// Digest frequencies.
+const (
+	DigestOff    = "off"
+	DigestDaily  = "daily"
+	DigestWeekly = "weekly"
+)
+
+// DigestSubscription holds a user's choice of activity digest. The token signs
+// the one-click unsubscribe link in every digest.
+type DigestSubscription struct {
+	ID        uint      `gorm:"primarykey" json:"-"`
+	CreatedAt time.Time `json:"-"`
+	UpdatedAt time.Time `json:"-"`
+
+	UserID           uint       `gorm:"uniqueIndex;not null" json:"-"`
+	Frequency        string     `gorm:"index;not null;default:'off'" json:"frequency"`
+	UnsubscribeToken string     `gorm:"uniqueIndex;not null" json:"-"`
+	LastSentAt       *time.Time `json:"last_sent_at,omitempty"`
+}
+
+// DigestItem is one entry of a digest section. Path is relative to the site URL.
+type DigestItem struct {
+	Title   string
+	Path    string
+	Summary string
+}
+
+// DigestSection is the activity of one kind, such as new posts, in a digest.
+type DigestSection struct {
+	Title string
+	Items []DigestItem
+}
+
+type UpdateDigestPreferencesRequest struct {
+	Frequency string `json:"frequency" binding:"required"`
+}
+
+type CreateWebhookRequest struct {
+	Name   string   `json:"name"`
+	URL    string   `json:"url" binding:"required"`
+	Events []string `json:"events" binding:"required"`
+	Active *bool    `json:"active"`
+}
+
+type UpdateWebhookRequest struct {
+	Name   *string   `json:"name"`
+	URL    *string   `json:"url"`
+	Events *[]string `json:"events"`
+	Active *bool     `json:"active"`
+}
+
+// DeliveryToken grants read-only access to published content through the
+// /api/v1/delivery routes. Unlike the JWTs of signed-in users it belongs to no
+// user, so it can be embedded in static site builds and apps. Only the hash of
+// the token is stored. A RequestsPerDay of zero means no daily quota.
+type DeliveryToken struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Name string `gorm:"not null" json:"name"`
+	// Prefix is the start of the token, shown to tell tokens apart.
+	Prefix            string     `gorm:"not null" json:"prefix"`
+	TokenHash         string     `gorm:"uniqueIndex;not null" json:"-"`
+	RequestsPerMinute int        `gorm:"not null" json:"requests_per_minute"`
+	RequestsPerDay    int        `gorm:"not null;default:0" json:"requests_per_day"`
+	Active            bool       `gorm:"not null;default:true" json:"active"`
+	ExpiresAt         *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt        *time.Time `json:"last_used_at,omitempty"`
+}
+
+type CreateDeliveryTokenRequest struct {
+	Name              string     `json:"name" binding:"required,max=100"`
+	RequestsPerMinute int        `json:"requests_per_minute" binding:"omitempty,min=1,max=100000"`
+	RequestsPerDay    int        `json:"requests_per_day" binding:"omitempty,min=0,max=100000000"`
+	ExpiresAt         *time.Time `json:"expires_at"`
+}
+
+type UpdateDeliveryTokenRequest struct {
+	Name              *string    `json:"name" binding:"omitempty,max=100"`
+	RequestsPerMinute *int       `json:"requests_per_minute" binding:"omitempty,min=1,max=100000"`
+	RequestsPerDay    *int       `json:"requests_per_day" binding:"omitempty,min=0,max=100000000"`
+	Active            *bool      `json:"active"`
+	ExpiresAt         *time.Time `json:"expires_at"`
+}
+
+// APIQuotaSettings caps the API requests each signed-in user may make per UTC
+// day, on top of the rate limits. Zero means no quota.
+type APIQuotaSettings struct {
+	UserRequestsPerDay int `json:"user_requests_per_day"`
+}
+
+type UpdateAPIQuotaSettingsRequest struct {
+	UserRequestsPerDay int `json:"user_requests_per_day" binding:"min=0,max=100000000"`
+}
+
+// APIUsage counts the API requests of one subject, "user:<id>" or
+// "token:<id>" for a delivery token, on one UTC day.
+type APIUsage struct {
+	ID        uint      `gorm:"primarykey" json:"-"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Subject  string    `gorm:"size:64;not null;uniqueIndex:idx_api_usage_subject_day,priority:1" json:"subject"`
+	Day      time.Time `gorm:"type:date;not null;uniqueIndex:idx_api_usage_subject_day,priority:2;index" json:"day"`
+	Requests int64     `gorm:"not null;default:0" json:"requests"`
+	// Rejected counts the requests refused for being over the quota.
+	Rejected int64 `gorm:"not null;default:0" json:"rejected"`
+}
+
+// APIUsageReport is the quota of one subject with its recent usage.
+type APIUsageReport struct {
+	Subject string `json:"subject"`
+	// RequestsPerDay is zero when the subject has no quota.
+	RequestsPerDay int        `json:"requests_per_day"`
+	Today          int64      `json:"today"`
+	Remaining      *int64     `json:"remaining,omitempty"`
+	ResetsAt       time.Time  `json:"resets_at"`
+	Days           []APIUsage `json:"days"`
+}
+
+// Social networks supported by post auto-sharing.
+const (
+	SocialNetworkMastodon = "mastodon"
+	SocialNetworkTwitter  = "twitter"
+	SocialNetworkLinkedIn = "linkedin"
+	SocialNetworkTelegram = "telegram"
+)
+
+// SocialAccount is a social network account newly published posts are shared to.
+type SocialAccount struct {
+	ID        uint           `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+
+	Name    string `json:"name"`
+	Network string `gorm:"size:32;not null;index" json:"network"`
+	// InstanceURL is the Mastodon server the account lives on.
+	InstanceURL string `json:"instance_url,omitempty"`
+	// Target is the LinkedIn author URN or the Telegram chat/channel ID.
+	Target      string `json:"target,omitempty"`
+	AccessToken string `gorm:"not null" json:"-"`
+	// Template is a text/template for the message; empty uses the network default.
+	Template string `gorm:"type:text" json:"template"`
+	Active   bool   `gorm:"default:false" json:"active"`
+}
+
+// Social share states.
+const (
+	SocialSharePending = "pending"
+	SocialShareSent    = "sent"
+	SocialShareFailed  = "failed"
+)
+
+// SocialShare records a post shared (or to be shared) to a social account.
+type SocialShare struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	AccountID      uint       `gorm:"index;not null" json:"account_id"`
+	Network        string     `gorm:"size:32;not null" json:"network"`
+	PostID         uint       `gorm:"index;not null" json:"post_id"`
+	Message        string     `gorm:"type:text" json:"message"`
+	Status         string     `gorm:"index;not null" json:"status"`
+	Attempts       int        `gorm:"default:0" json:"attempts"`
+	ResponseStatus int        `json:"response_status,omitempty"`
+	ResponseBody   string     `gorm:"type:text" json:"response_body,omitempty"`
+	Error          string     `gorm:"type:text" json:"error,omitempty"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at,omitempty"`
+	SentAt         *time.Time `json:"sent_at,omitempty"`
+}
+
+type CreateSocialAccountRequest struct {
+	Name        string `json:"name"`
+	Network     string `json:"network" binding:"required"`
+	InstanceURL string `json:"instance_url"`
+	Target      string `json:"target"`
+	AccessToken string `json:"access_token" binding:"required"`
+	Template    string `json:"template"`
+	Active      *bool  `json:"active"`
+}
+
+type UpdateSocialAccountRequest struct {
+	Name        *string `json:"name"`
+	InstanceURL *string `json:"instance_url"`
+	Target      *string `json:"target"`
+	AccessToken *string `json:"access_token"`
+	Template    *string `json:"template"`
+	Active      *bool   `json:"active"`
+}
+
+// InstallPluginRequest installs a plugin from a remote archive URL or from the
+// configured marketplace feed by slug.
+type InstallPluginRequest struct {
+	URL       string `json:"url"`
+	Slug      string `json:"slug"`
+	SHA256    string `json:"sha256"`
+	Signature string `json:"signature"`
+}
+
 type PluginInfo struct {
 	Slug           string     `json:"slug"`
 	Name           string     `json:"name"`
@@ -925,9 +1497,34 @@ type PluginInfo struct {
 	InstalledAt    *time.Time `json:"installed_at,omitempty"`
 	LastActiveAt   *time.Time `json:"last_active_at,omitempty"`
 	MissingFiles   bool       `json:"missing_files"`
+	HasSettings    bool       `json:"has_settings"`
 	AdditionalData JSONMap    `json:"metadata,omitempty"`
 }
 
+// SiteManifest describes what a site offers, so that frontends and apps can
+// feature-detect, for example to hide the forum when its plugin is inactive.
+type SiteManifest struct {
+	Name        string   `json:"name"`
+	URL         string   `json:"url"`
+	Version     string   `json:"version"`
+	APIVersions []string `json:"api_versions"`
+	Theme       string   `json:"theme"`
+	// Languages are the language codes content is published in, the default
+	// first.
+	Languages []string         `json:"languages"`
+	Plugins   []ManifestPlugin `json:"plugins"`
+	// Capabilities names every feature a client may look for, with whether it is
+	// available.
+	Capabilities map[string]bool `json:"capabilities"`
+}
+
+// ManifestPlugin is an active plugin in the site manifest.
+type ManifestPlugin struct {
+	Slug    string `json:"slug"`
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
 type SubtitleSettings struct {
 	Enabled       bool     `json:"enabled"`
 	Provider      string   `json:"provider"`
@@ -961,7 +1558,51 @@ type SiteSettings struct {
 	CourseCheckoutSuccessURL string           `json:"course_checkout_success_url"`
 	CourseCheckoutCancelURL  string           `json:"course_checkout_cancel_url"`
 	CourseCheckoutCurrency   string           `json:"course_checkout_currency"`
+	ColorScheme              string           `json:"color_scheme"`
+	AllowColorSchemeToggle   bool             `json:"allow_color_scheme_toggle"`
 	Subtitles                SubtitleSettings `json:"subtitles"`
+	Translations             SiteTranslations `json:"translations,omitempty"`
+}
+
+// SiteTranslation holds the site identity in one language. Empty fields fall back
+// to the default-language settings.
+type SiteTranslation struct {
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	FooterText  string `json:"footer_text,omitempty"`
+}
+
+// SiteTranslations maps language codes to translated site identity.
+type SiteTranslations map[string]SiteTranslation
+
+// Localize returns settings with the name, description and footer text of the
+// given language, keeping the default-language values where no translation exists.
+func (s SiteSettings) Localize(code string) SiteSettings {
+	match := lang.Match(code, s.Translations.Languages())
+	if match == "" {
+		return s
+	}
+	translation := s.Translations[match]
+	if value := strings.TrimSpace(translation.Name); value != "" {
+		s.Name = value
+	}
+	if value := strings.TrimSpace(translation.Description); value != "" {
+		s.Description = value
+	}
+	if value := strings.TrimSpace(translation.FooterText); value != "" {
+		s.FooterText = value
+	}
+	return s
+}
+
+// Languages lists the languages with a translation, sorted.
+func (t SiteTranslations) Languages() []string {
+	codes := make([]string, 0, len(t))
+	for code := range t {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
 }
 
 type BackupSettings struct {
@@ -979,13 +1620,64 @@ type UpdateBackupSettingsRequest struct {
 }
 
 type ThemeInfo struct {
-	Slug         string `json:"slug"`
-	Name         string `json:"name"`
-	Description  string `json:"description,omitempty"`
-	Version      string `json:"version,omitempty"`
-	Author       string `json:"author,omitempty"`
-	PreviewImage string `json:"preview_image,omitempty"`
-	Active       bool   `json:"active"`
+	Slug               string   `json:"slug"`
+	Name               string   `json:"name"`
+	Description        string   `json:"description,omitempty"`
+	Version            string   `json:"version,omitempty"`
+	Author             string   `json:"author,omitempty"`
+	PreviewImage       string   `json:"preview_image,omitempty"`
+	ColorSchemes       []string `json:"color_schemes,omitempty"`
+	DefaultColorScheme string   `json:"default_color_scheme,omitempty"`
+	Active             bool     `json:"active"`
+}
+
+type ThemeTemplateOverride struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Theme   string `gorm:"size:191;not null;uniqueIndex:idx_theme_template_overrides_theme_name,priority:1" json:"theme"`
+	Name    string `gorm:"size:191;not null;uniqueIndex:idx_theme_template_overrides_theme_name,priority:2" json:"name"`
+	Content string `gorm:"type:text;not null" json:"content"`
+}
+
+type ThemeTemplateInfo struct {
+	Name       string     `json:"name"`
+	Overridden bool       `json:"overridden"`
+	UpdatedAt  *time.Time `json:"updated_at,omitempty"`
+}
+
+type ThemeTemplateDetail struct {
+	Theme      string     `json:"theme"`
+	Name       string     `json:"name"`
+	Content    string     `json:"content"`
+	Original   string     `json:"original"`
+	Overridden bool       `json:"overridden"`
+	UpdatedAt  *time.Time `json:"updated_at,omitempty"`
+}
+
+type UpdateThemeTemplateRequest struct {
+	Content string `json:"content" binding:"required"`
+}
+
+// ThemeSettingsBundle is the portable export of the settings that belong to a theme:
+// customizer values, template overrides, the navigation menu and whether the theme's
+// default content has already been applied.
+type ThemeSettingsBundle struct {
+	Version     int                 `json:"version"`
+	Theme       string              `json:"theme" binding:"required"`
+	ExportedAt  time.Time           `json:"exported_at"`
+	Settings    map[string]string   `json:"settings,omitempty"`
+	Templates   map[string]string   `json:"templates,omitempty"`
+	Menu        []ThemeSettingsMenu `json:"menu,omitempty"`
+	Initialized bool                `json:"initialized"`
+}
+
+type ThemeSettingsMenu struct {
+	Title    string `json:"title"`
+	URL      string `json:"url"`
+	Location string `json:"location"`
+	Order    int    `json:"order"`
 }
 
 type UpdateSiteSettingsRequest struct {
@@ -1004,7 +1696,10 @@ type UpdateSiteSettingsRequest struct {
 	CourseCheckoutSuccessURL string                         `json:"course_checkout_success_url"`
 	CourseCheckoutCancelURL  string                         `json:"course_checkout_cancel_url"`
 	CourseCheckoutCurrency   string                         `json:"course_checkout_currency"`
+	ColorScheme              string                         `json:"color_scheme"`
+	AllowColorSchemeToggle   *bool                          `json:"allow_color_scheme_toggle"`
 	Subtitles                *UpdateSubtitleSettingsRequest `json:"subtitles"`
+	Translations             *SiteTranslations              `json:"translations"`
 }
 
 type EmailSettings struct {
@@ -1076,6 +1771,7 @@ type ReorderFontAssetsRequest struct {
 
 type HomepagePage struct {
 	ID        uint       `json:"id"`
+	Language  string     `json:"language,omitempty"`
 	Title     string     `json:"title"`
 	Slug      string     `json:"slug"`
 	Path      string     `json:"path"`
@@ -1087,6 +1783,9 @@ type HomepagePage struct {
 
 type UpdateHomepageRequest struct {
 	PageID *uint `json:"page_id"`
+	// Language selects the homepage of one language. Empty sets the homepage
+	// shown for every language without its own.
+	Language string `json:"language"`
 }
 
 type AdvertisingSettings struct {
@@ -1136,6 +1835,141 @@ type UpdateAdvertisingSettingsRequest struct {
 	GoogleAds *GoogleAdsSettings `json:"google_ads"`
 }
 
+// RateLimitSettings holds the route policies applied on top of the global per-IP
+// rate limit.
+type RateLimitSettings struct {
+	Policies []RateLimitPolicy `json:"policies"`
+}
+
+// RateLimitPolicy allows Requests requests per WindowSeconds to the paths starting
+// with PathPrefix, counted per signed-in user when PerUser is set and per client IP
+// otherwise. An empty Methods list matches every method.
+type RateLimitPolicy struct {
+	Name          string   `json:"name"`
+	PathPrefix    string   `json:"path_prefix"`
+	Methods       []string `json:"methods,omitempty"`
+	Requests      int      `json:"requests"`
+	WindowSeconds int      `json:"window_seconds"`
+	PerUser       bool     `json:"per_user"`
+}
+
+type UpdateRateLimitSettingsRequest struct {
+	Policies []RateLimitPolicy `json:"policies"`
+}
+
+// HeadlessSettings turns off the public HTML pages, so the site is served only
+// through the API by a separate frontend, and lists the build hooks that rebuild
+// that frontend after content changes.
+type HeadlessSettings struct {
+	Enabled bool `json:"enabled"`
+	// FrontendURL, when set, receives the visitors of disabled pages, with the
+	// same path and query.
+	FrontendURL string      `json:"frontend_url,omitempty"`
+	BuildHooks  []BuildHook `json:"build_hooks"`
+}
+
+// BuildHook is a deploy hook URL, such as a Netlify or Vercel build hook, posted
+// to after content changes. It is used whether or not the site is headless.
+type BuildHook struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// BuildHookStatus reports the last time a build hook was posted to.
+type BuildHookStatus struct {
+	Name          string     `json:"name"`
+	LastTriggered *time.Time `json:"last_triggered,omitempty"`
+	LastStatus    int        `json:"last_status,omitempty"`
+	LastError     string     `json:"last_error,omitempty"`
+}
+
+type UpdateHeadlessSettingsRequest struct {
+	Enabled     bool        `json:"enabled"`
+	FrontendURL string      `json:"frontend_url"`
+	BuildHooks  []BuildHook `json:"build_hooks"`
+}
+
+// Admin alert channel types.
+const (
+	AlertChannelSlack    = "slack"
+	AlertChannelDiscord  = "discord"
+	AlertChannelTelegram = "telegram"
+)
+
+// Admin alerts a channel can subscribe to.
+const (
+	AlertComments       = "comments"
+	AlertBackups        = "backups"
+	AlertErrorRate      = "error_rate"
+	AlertCheckouts      = "checkouts"
+	AlertForumQuestions = "forum_questions"
+)
+
+// AlertSettings sends operational alerts to the chats a team already uses.
+type AlertSettings struct {
+	Channels []AlertChannel `json:"channels"`
+	// ErrorRateThreshold is the share of requests failing with a server error,
+	// over five minutes, that is reported as a spike.
+	ErrorRateThreshold float64 `json:"error_rate_threshold"`
+	// Rules route alerts that match them to their own targets instead of the
+	// channels subscribed to the alert.
+	Rules []AlertRule `json:"rules"`
+}
+
+// AlertRule sends the alerts matching it to the channels and email addresses it
+// names. An alert matching no rule goes to the channels subscribed to it.
+type AlertRule struct {
+	Name  string `json:"name"`
+	Alert string `json:"alert"`
+	// PendingOnly limits a comments rule to comments awaiting moderation.
+	PendingOnly bool `json:"pending_only,omitempty"`
+	// ForumCategoryIDs limits a forum_questions rule to questions in these
+	// categories.
+	ForumCategoryIDs []uint `json:"forum_category_ids,omitempty"`
+	// Channels are names of alert channels.
+	Channels []string `json:"channels"`
+	Emails   []string `json:"emails"`
+}
+
+// AlertChannel is a Slack or Discord incoming webhook, or a Telegram chat posted
+// to by a bot, and the alerts it receives.
+type AlertChannel struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// URL is the incoming webhook URL of Slack and Discord channels.
+	URL string `json:"url,omitempty"`
+	// BotToken and ChatID address Telegram channels.
+	BotToken string `json:"bot_token,omitempty"`
+	ChatID   string `json:"chat_id,omitempty"`
+	// Alerts the channel subscribes to. A channel used only by rules may have
+	// none.
+	Alerts []string `json:"alerts"`
+}
+
+type UpdateAlertSettingsRequest struct {
+	Channels           []AlertChannel `json:"channels"`
+	ErrorRateThreshold float64        `json:"error_rate_threshold"`
+	Rules              []AlertRule    `json:"rules"`
+}
+
+// AlertDeliveryResult reports a test alert sent to a channel.
+type AlertDeliveryResult struct {
+	Name  string `json:"name"`
+	Error string `json:"error,omitempty"`
+}
+
+// LoggingSettings is the log level and output format in effect. They change at
+// runtime and return to the environment configuration on restart.
+type LoggingSettings struct {
+	Level  string `json:"level"`
+	Format string `json:"format"`
+}
+
+type UpdateLoggingSettingsRequest struct {
+	Level  *string `json:"level"`
+	Format *string `json:"format"`
+}
+
 func DetectFaviconType(favicon string) string {
 	const defaultType = "image/x-icon"
 
@@ -1225,6 +2059,12 @@ type MenuItem struct {
 	URL      string `gorm:"not null" json:"url"`
 	Location string `gorm:"type:varchar(32);not null;default:'header'" json:"location"`
 	Order    int    `gorm:"default:0" json:"order"`
+
+	// Translations holds the title in languages other than the default one.
+	Translations LocalizedText `gorm:"type:jsonb" json:"translations,omitempty"`
+	// Language puts the item in the menu set of one language. Items without a
+	// language are shown for languages that have no items in that location.
+	Language string `gorm:"type:varchar(16);not null;default:'';index" json:"language,omitempty"`
 }
 
 func (m *MenuItem) EnsureTextFields() {
@@ -1248,18 +2088,51 @@ func NormalizeMenuItems(items []MenuItem) []MenuItem {
 	return items
 }
 
+// MenuItemsForLanguage picks the menu set shown in language. For every location
+// the items of the best matching language win; locations without such items
+// keep the items that have no language.
+func MenuItemsForLanguage(items []MenuItem, language string) []MenuItem {
+	languages := make(map[string][]string)
+	for _, item := range items {
+		if item.Language != "" {
+			languages[item.Location] = append(languages[item.Location], item.Language)
+		}
+	}
+	if len(languages) == 0 {
+		return items
+	}
+
+	selected := make(map[string]string, len(languages))
+	for location, codes := range languages {
+		sort.Strings(codes)
+		selected[location] = lang.Match(language, codes)
+	}
+
+	result := make([]MenuItem, 0, len(items))
+	for _, item := range items {
+		if item.Language == selected[item.Location] {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
 type CreateMenuItemRequest struct {
-	Title    string `json:"title" binding:"required"`
-	URL      string `json:"url" binding:"required"`
-	Location string `json:"location"`
-	Order    *int   `json:"order"`
+	Title        string        `json:"title" binding:"required"`
+	URL          string        `json:"url" binding:"required"`
+	Location     string        `json:"location"`
+	Order        *int          `json:"order"`
+	Translations LocalizedText `json:"translations"`
+	Language     string        `json:"language"`
 }
 
 type UpdateMenuItemRequest struct {
-	Title    string  `json:"title" binding:"required"`
-	URL      string  `json:"url" binding:"required"`
-	Location *string `json:"location"`
-	Order    *int    `json:"order"`
+	Title        string         `json:"title" binding:"required"`
+	URL          string         `json:"url" binding:"required"`
+	Location     *string        `json:"location"`
+	Order        *int           `json:"order"`
+	Translations *LocalizedText `json:"translations"`
+	Language     *string        `json:"language"`
 }
 
 type MenuOrder struct {
@@ -1270,3 +2143,63 @@ type MenuOrder struct {
 type ReorderMenuItemsRequest struct {
 	Orders []MenuOrder `json:"orders"`
 }
+
+// DashboardStatistics feeds the admin dashboard: site totals, the most read posts,
+// the newest users and one activity point per day.
+type DashboardStatistics struct {
+	Statistics    StatisticsTotals `json:"statistics"`
+	PopularPosts  []PopularPost    `json:"popular_posts"`
+	RecentUsers   []RecentUser     `json:"recent_users"`
+	ActivityTrend []ActivityPoint  `json:"activity_trend"`
+}
+
+type StatisticsTotals struct {
+	TotalPosts          int64 `json:"total_posts"`
+	PublishedPosts      int64 `json:"published_posts"`
+	TotalUsers          int64 `json:"total_users"`
+	TotalCategories     int64 `json:"total_categories"`
+	TotalComments       int64 `json:"total_comments"`
+	TotalTags           int64 `json:"total_tags"`
+	TotalViews          int64 `json:"total_views"`
+	TotalRevenueCents   int64 `json:"total_revenue_cents"`
+	PostsLast24Hours    int64 `json:"posts_last_24_hours"`
+	PostsLast7Days      int64 `json:"posts_last_7_days"`
+	CommentsLast24Hours int64 `json:"comments_last_24_hours"`
+	CommentsLast7Days   int64 `json:"comments_last_7_days"`
+	UsersLast7Days      int64 `json:"users_last_7_days"`
+}
+
+type PopularPost struct {
+	ID    uint   `json:"id"`
+	Title string `json:"title"`
+	Views int    `json:"views"`
+}
+
+type RecentUser struct {
+	ID       uint   `json:"id"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	Role     string `json:"role"`
+}
+
+// ActivityPoint is one UTC day of activity. Users counts signups and Views counts
+// post views on that day. The cache and request figures are kept in memory by the
+// process serving the request, so they start over on restart and cover only that
+// instance; their rates are nil on days without lookups or requests.
+type ActivityPoint struct {
+	Period         time.Time `json:"period"`
+	Posts          int64     `json:"posts"`
+	Comments       int64     `json:"comments"`
+	Views          int64     `json:"views"`
+	Users          int64     `json:"users"`
+	ForumQuestions int64     `json:"forum_questions"`
+	ForumAnswers   int64     `json:"forum_answers"`
+	Checkouts      int64     `json:"checkouts"`
+	RevenueCents   int64     `json:"revenue_cents"`
+	CacheHits      int64     `json:"cache_hits"`
+	CacheMisses    int64     `json:"cache_misses"`
+	CacheHitRate   *float64  `json:"cache_hit_rate"`
+	Requests       int64     `json:"requests"`
+	ServerErrors   int64     `json:"server_errors"`
+	ErrorRate      *float64  `json:"error_rate"`
+}
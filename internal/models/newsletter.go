@@ -0,0 +1,126 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Newsletter subscriber states.
+const (
+	NewsletterSubscriberPending      = "pending"
+	NewsletterSubscriberConfirmed    = "confirmed"
+	NewsletterSubscriberUnsubscribed = "unsubscribed"
+)
+
+// NewsletterSubscriber is an email address on the newsletter list. Addresses stay
+// pending until the confirmation link from the opt-in email is followed.
+type NewsletterSubscriber struct {
+	ID        uint           `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+
+	Email            string     `gorm:"not null;uniqueIndex" json:"email"`
+	Name             string     `json:"name"`
+	Status           string     `gorm:"index;not null" json:"status"`
+	Source           string     `json:"source,omitempty"`
+	ConfirmToken     string     `gorm:"index" json:"-"`
+	ConfirmSentAt    *time.Time `json:"confirm_sent_at,omitempty"`
+	UnsubscribeToken string     `gorm:"not null;uniqueIndex" json:"-"`
+	ConfirmedAt      *time.Time `json:"confirmed_at,omitempty"`
+	UnsubscribedAt   *time.Time `json:"unsubscribed_at,omitempty"`
+}
+
+// Newsletter campaign states.
+const (
+	NewsletterCampaignDraft   = "draft"
+	NewsletterCampaignSending = "sending"
+	NewsletterCampaignSent    = "sent"
+)
+
+// NewsletterCampaign is a single newsletter issue. The rendered subject and body are
+// frozen when sending starts so every recipient gets the same issue and tracked links
+// keep resolving after the site content changes.
+type NewsletterCampaign struct {
+	ID        uint           `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+
+	Subject     string `gorm:"not null" json:"subject"`
+	Content     string `gorm:"type:text" json:"content"`
+	RecentPosts int    `gorm:"default:0" json:"recent_posts"`
+	Status      string `gorm:"index;not null" json:"status"`
+
+	RenderedSubject string     `json:"-"`
+	RenderedHTML    string     `gorm:"type:text" json:"-"`
+	RenderedText    string     `gorm:"type:text" json:"-"`
+	Links           StringList `gorm:"type:jsonb" json:"-"`
+	StartedAt       *time.Time `json:"started_at,omitempty"`
+	SentAt          *time.Time `json:"sent_at,omitempty"`
+
+	Stats *NewsletterCampaignStats `gorm:"-" json:"stats,omitempty"`
+}
+
+// Newsletter delivery states.
+const (
+	NewsletterDeliveryPending = "pending"
+	NewsletterDeliverySent    = "sent"
+	NewsletterDeliveryFailed  = "failed"
+)
+
+// NewsletterDelivery is one campaign email to one subscriber. Its token identifies
+// the recipient in open and click tracking URLs.
+type NewsletterDelivery struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	CampaignID   uint       `gorm:"not null;uniqueIndex:idx_newsletter_deliveries_campaign_subscriber,priority:1" json:"campaign_id"`
+	SubscriberID uint       `gorm:"not null;uniqueIndex:idx_newsletter_deliveries_campaign_subscriber,priority:2" json:"subscriber_id"`
+	Email        string     `gorm:"not null" json:"email"`
+	Token        string     `gorm:"not null;uniqueIndex" json:"-"`
+	Status       string     `gorm:"index;not null" json:"status"`
+	Error        string     `gorm:"type:text" json:"error,omitempty"`
+	SentAt       *time.Time `json:"sent_at,omitempty"`
+	OpenedAt     *time.Time `json:"opened_at,omitempty"`
+	ClickedAt    *time.Time `json:"clicked_at,omitempty"`
+	Clicks       int        `gorm:"default:0" json:"clicks"`
+}
+
+// NewsletterCampaignStats aggregates the deliveries of a campaign. Opened and Clicked
+// count unique recipients; Clicks counts every tracked click.
+type NewsletterCampaignStats struct {
+	Recipients int64   `json:"recipients"`
+	Pending    int64   `json:"pending"`
+	Sent       int64   `json:"sent"`
+	Failed     int64   `json:"failed"`
+	Opened     int64   `json:"opened"`
+	Clicked    int64   `json:"clicked"`
+	Clicks     int64   `json:"clicks"`
+	OpenRate   float64 `json:"open_rate"`
+	ClickRate  float64 `json:"click_rate"`
+}
+
+type NewsletterSubscribeRequest struct {
+	Email string `json:"email" binding:"required"`
+	Name  string `json:"name"`
+}
+
+type UpdateNewsletterSubscriberRequest struct {
+	Name   *string `json:"name"`
+	Status *string `json:"status"`
+}
+
+type CreateNewsletterCampaignRequest struct {
+	Subject     string `json:"subject" binding:"required"`
+	Content     string `json:"content"`
+	RecentPosts int    `json:"recent_posts"`
+}
+
+type UpdateNewsletterCampaignRequest struct {
+	Subject     *string `json:"subject"`
+	Content     *string `json:"content"`
+	RecentPosts *int    `json:"recent_posts"`
+}
@@ -0,0 +1,60 @@
+package models
+
+import "time"
+
+// Kinds of notifications. The kind is also the event a user sets preferences
+// for, so it is shared by in-app notifications and the emails sent for them.
+const (
+	NotificationCourseAnnouncement   = "course_announcement"
+	NotificationCourseAccessExpiring = "course_access_expiring"
+	NotificationForumAnswer          = "forum_answer"
+	NotificationPostComment          = "post_comment"
+	NotificationDataExportReady      = "data_export_ready"
+)
+
+// Channels notifications are delivered through.
+const (
+	NotificationChannelEmail = "email"
+	NotificationChannelInApp = "in_app"
+)
+
+// Notification is a message shown to a user in the site itself, such as an
+// announcement on a course they take.
+type Notification struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+
+	UserID uint       `gorm:"not null;index:idx_notifications_user_read" json:"-"`
+	Kind   string     `gorm:"not null" json:"kind"`
+	Title  string     `gorm:"not null" json:"title"`
+	Body   string     `gorm:"type:text" json:"body,omitempty"`
+	Path   string     `json:"path,omitempty"`
+	ReadAt *time.Time `gorm:"index:idx_notifications_user_read" json:"read_at,omitempty"`
+}
+
+// NotificationPreference records a user's choice for one event and channel.
+// Only choices the user made are stored; the rest follow the defaults.
+type NotificationPreference struct {
+	ID        uint      `gorm:"primarykey" json:"-"`
+	CreatedAt time.Time `json:"-"`
+	UpdatedAt time.Time `json:"-"`
+
+	UserID  uint   `gorm:"not null;uniqueIndex:idx_notification_preferences_user_event_channel" json:"-"`
+	Event   string `gorm:"not null;uniqueIndex:idx_notification_preferences_user_event_channel" json:"event"`
+	Channel string `gorm:"not null;uniqueIndex:idx_notification_preferences_user_event_channel" json:"channel"`
+	Enabled bool   `gorm:"not null" json:"enabled"`
+}
+
+// NotificationEventPreferences is one row of a user's preferences matrix: the
+// channels of an event and whether each is on.
+type NotificationEventPreferences struct {
+	Event    string          `json:"event"`
+	Label    string          `json:"label"`
+	Channels map[string]bool `json:"channels"`
+}
+
+// UpdateNotificationPreferencesRequest changes some cells of the matrix, keyed
+// by event and then channel. Cells left out keep their value.
+type UpdateNotificationPreferencesRequest struct {
+	Preferences map[string]map[string]bool `json:"preferences" binding:"required"`
+}
@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+// Kinds of OAuth login providers.
+const (
+	OAuthProviderGoogle = "google"
+	OAuthProviderGitHub = "github"
+	OAuthProviderOIDC   = "oidc"
+)
+
+// OAuthProviderSettings configures one provider users can sign in with. Slug
+// names it in the login URLs. Issuer is the OpenID Connect issuer of generic
+// providers. AllowSignup creates accounts for people without one; otherwise
+// only users with an account can sign in.
+type OAuthProviderSettings struct {
+	Slug            string   `json:"slug"`
+	Kind            string   `json:"kind"`
+	Name            string   `json:"name"`
+	Enabled         bool     `json:"enabled"`
+	ClientID        string   `json:"client_id"`
+	ClientSecret    string   `json:"client_secret,omitempty"`
+	ClientSecretSet bool     `json:"client_secret_set"`
+	Issuer          string   `json:"issuer,omitempty"`
+	Scopes          []string `json:"scopes,omitempty"`
+	AllowSignup     bool     `json:"allow_signup"`
+}
+
+type OAuthSettings struct {
+	Providers []OAuthProviderSettings `json:"providers"`
+}
+
+// UpdateOAuthSettingsRequest replaces the providers. A provider sent without a
+// client secret keeps the one stored for its slug.
+type UpdateOAuthSettingsRequest struct {
+	Providers []OAuthProviderSettings `json:"providers"`
+}
+
+// OAuthProviderInfo is what visitors see of an enabled provider.
+type OAuthProviderInfo struct {
+	Slug     string `json:"slug"`
+	Kind     string `json:"kind"`
+	Name     string `json:"name"`
+	LoginURL string `json:"login_url"`
+}
+
+// UserIdentity links a user to their account at an OAuth provider.
+type UserIdentity struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	UserID     uint       `gorm:"not null;index" json:"-"`
+	Provider   string     `gorm:"size:64;not null;uniqueIndex:idx_user_identities_provider_subject" json:"provider"`
+	Subject    string     `gorm:"not null;uniqueIndex:idx_user_identities_provider_subject" json:"-"`
+	Email      string     `json:"email,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
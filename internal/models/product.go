@@ -0,0 +1,83 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Product is a digital download sold through the payments module. The file itself is
+// an archive file, delivered to buyers through signed, expiring links.
+type Product struct {
+	ID        uint           `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+
+	Title         string       `gorm:"not null" json:"title"`
+	Slug          string       `gorm:"not null;uniqueIndex" json:"slug"`
+	Summary       string       `json:"summary"`
+	Description   string       `gorm:"type:text" json:"description"`
+	Image         string       `json:"image"`
+	PriceCents    int64        `gorm:"not null" json:"price_cents"`
+	ArchiveFileID uint         `gorm:"not null;index" json:"archive_file_id"`
+	ArchiveFile   *ArchiveFile `gorm:"foreignKey:ArchiveFileID" json:"archive_file,omitempty"`
+	Published     bool         `gorm:"default:false;index" json:"published"`
+}
+
+const (
+	ProductOrderStatusPending = "pending"
+	ProductOrderStatusPaid    = "paid"
+)
+
+// ProductOrder records a checkout for a product and, once paid, the buyer's downloads.
+type ProductOrder struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	ProductID   uint       `gorm:"not null;index" json:"product_id"`
+	Product     *Product   `gorm:"foreignKey:ProductID" json:"product,omitempty"`
+	Email       string     `gorm:"not null;index" json:"email"`
+	SessionID   string     `gorm:"index" json:"session_id"`
+	Status      string     `gorm:"not null;default:pending;index" json:"status"`
+	AmountCents int64      `json:"amount_cents"`
+	PaidAt      *time.Time `json:"paid_at,omitempty"`
+	Downloads   int        `gorm:"default:0" json:"downloads"`
+}
+
+type CreateProductRequest struct {
+	Title         string `json:"title" binding:"required"`
+	Slug          string `json:"slug"`
+	Summary       string `json:"summary"`
+	Description   string `json:"description"`
+	Image         string `json:"image"`
+	PriceCents    int64  `json:"price_cents" binding:"required"`
+	ArchiveFileID uint   `json:"archive_file_id" binding:"required"`
+	Published     *bool  `json:"published"`
+}
+
+type UpdateProductRequest struct {
+	Title         *string `json:"title"`
+	Slug          *string `json:"slug"`
+	Summary       *string `json:"summary"`
+	Description   *string `json:"description"`
+	Image         *string `json:"image"`
+	PriceCents    *int64  `json:"price_cents"`
+	ArchiveFileID *uint   `json:"archive_file_id"`
+	Published     *bool   `json:"published"`
+}
+
+type ProductCheckoutRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ProductCheckoutSession struct {
+	OrderID     uint   `json:"order_id"`
+	SessionID   string `json:"session_id"`
+	CheckoutURL string `json:"checkout_url"`
+}
+
+type VerifyProductCheckoutRequest struct {
+	SessionID string `json:"session_id" binding:"required"`
+}
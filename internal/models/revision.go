@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+const (
+	RevisionContentPost = "post"
+	RevisionContentPage = "page"
+)
+
+// Revision is a snapshot of a post or page as it was saved. A new one is
+// recorded on every create and update, so the newest revision matches the
+// current content.
+type Revision struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+
+	ContentType string       `gorm:"size:20;not null;index:idx_revisions_content,priority:1" json:"content_type"`
+	ContentID   uint         `gorm:"not null;index:idx_revisions_content,priority:2" json:"content_id"`
+	Title       string       `json:"title"`
+	Description string       `json:"description,omitempty"`
+	Excerpt     string       `json:"excerpt,omitempty"`
+	Content     string       `gorm:"type:text" json:"content,omitempty"`
+	Sections    PostSections `gorm:"type:jsonb" json:"sections,omitempty"`
+}
+
+// RevisionSettings control how much history is kept. Zero disables a limit.
+type RevisionSettings struct {
+	// MaxPerItem is how many revisions are kept for each post or page.
+	MaxPerItem int `json:"max_per_item"`
+	// MaxAgeDays removes older revisions, except the newest of each item.
+	MaxAgeDays int `json:"max_age_days"`
+}
+
+// RevisionDiff compares two revisions of the same post or page field by
+// field. Fields that did not change are left out.
+type RevisionDiff struct {
+	From    *Revision           `json:"from,omitempty"`
+	To      Revision            `json:"to"`
+	Changes []RevisionFieldDiff `json:"changes"`
+}
+
+type RevisionFieldDiff struct {
+	Field string     `json:"field"`
+	Lines []DiffLine `json:"lines"`
+}
+
+const (
+	DiffEqual  = "equal"
+	DiffInsert = "insert"
+	DiffDelete = "delete"
+)
+
+type DiffLine struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
@@ -0,0 +1,31 @@
+package models
+
+// Contexts in which user-provided markup is rendered, each with its own
+// sanitization policy.
+const (
+	SanitizeComments         = "comments"
+	SanitizeForum            = "forum"
+	SanitizePageContent      = "page_content"
+	SanitizeSectionParagraph = "section_paragraph"
+)
+
+// Base levels of a sanitization policy, from the most to the least strict.
+const (
+	SanitizeLevelText  = "text"
+	SanitizeLevelBasic = "basic"
+	SanitizeLevelRich  = "rich"
+)
+
+// SanitizationPolicy describes which markup survives in one context. The text
+// level removes every tag, the basic level keeps inline formatting, links and
+// lists, and the rich level keeps what bluemonday's UGC policy allows.
+type SanitizationPolicy struct {
+	Level        string `json:"level"`
+	AllowImages  bool   `json:"allow_images"`
+	AllowClasses bool   `json:"allow_classes"`
+	AllowStyles  bool   `json:"allow_styles"`
+}
+
+type SanitizationSettings struct {
+	Policies map[string]SanitizationPolicy `json:"policies"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// SettingsDocumentFormat is the version of SettingsDocument written by exports.
+// Imports reject other versions.
+const SettingsDocumentFormat = 1
+
+// SettingsDocument is the configuration of a site as structured JSON, for
+// promoting it between environments and keeping it in version control. Each
+// section replaces the current one on import; sections left out stay as they
+// are. Credentials and values that belong to one instance, such as the site URL
+// or the Stripe keys, are never part of it.
+type SettingsDocument struct {
+	Format      int        `json:"format" binding:"required"`
+	ExportedAt  *time.Time `json:"exported_at,omitempty"`
+	Application string     `json:"application,omitempty"`
+
+	Site        *SiteSettingsExport               `json:"site,omitempty"`
+	Advertising *UpdateAdvertisingSettingsRequest `json:"advertising,omitempty"`
+	Fonts       *[]FontAsset                      `json:"fonts,omitempty" binding:"omitempty,dive"`
+	SocialLinks *[]CreateSocialLinkRequest        `json:"social_links,omitempty" binding:"omitempty,dive"`
+	MenuItems   *[]CreateMenuItemRequest          `json:"menu_items,omitempty" binding:"omitempty,dive"`
+	Backup      *UpdateBackupSettingsRequest      `json:"backup,omitempty"`
+	// Plugins maps plugin slugs to their setting values.
+	Plugins map[string]map[string]interface{} `json:"plugins,omitempty"`
+}
+
+// SiteSettingsExport is the part of the site settings a SettingsDocument
+// carries.
+type SiteSettingsExport struct {
+	Name                     string                        `json:"name" binding:"required"`
+	Description              string                        `json:"description"`
+	Favicon                  string                        `json:"favicon"`
+	Logo                     string                        `json:"logo"`
+	FooterText               string                        `json:"footer_text" binding:"max=500"`
+	UnusedTagRetentionHours  int                           `json:"unused_tag_retention_hours" binding:"required,min=1"`
+	DefaultLanguage          string                        `json:"default_language"`
+	SupportedLanguages       []string                      `json:"supported_languages"`
+	CourseCheckoutSuccessURL string                        `json:"course_checkout_success_url"`
+	CourseCheckoutCancelURL  string                        `json:"course_checkout_cancel_url"`
+	CourseCheckoutCurrency   string                        `json:"course_checkout_currency"`
+	ColorScheme              string                        `json:"color_scheme"`
+	AllowColorSchemeToggle   bool                          `json:"allow_color_scheme_toggle"`
+	Subtitles                UpdateSubtitleSettingsRequest `json:"subtitles"`
+	Translations             SiteTranslations              `json:"translations,omitempty"`
+}
+
+// SettingsImportResult lists the sections an import replaced.
+type SettingsImportResult struct {
+	Imported []string `json:"imported"`
+}
@@ -0,0 +1,68 @@
+package models
+
+import "time"
+
+const (
+	IncidentStatusInvestigating = "investigating"
+	IncidentStatusIdentified    = "identified"
+	IncidentStatusMonitoring    = "monitoring"
+	IncidentStatusResolved      = "resolved"
+
+	IncidentImpactMinor       = "minor"
+	IncidentImpactMajor       = "major"
+	IncidentImpactMaintenance = "maintenance"
+
+	ComponentOperational = "operational"
+	ComponentDegraded    = "degraded"
+	ComponentOutage      = "outage"
+	ComponentMaintenance = "maintenance"
+)
+
+// StatusIncident is an outage or maintenance window shown on the public status
+// page. Maintenance may be announced ahead of time with StartedAt in the future.
+type StatusIncident struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Title      string     `gorm:"not null" json:"title"`
+	Message    string     `gorm:"type:text" json:"message"`
+	Status     string     `gorm:"not null;default:investigating" json:"status"`
+	Impact     string     `gorm:"not null;default:minor" json:"impact"`
+	StartedAt  time.Time  `gorm:"not null;index" json:"started_at"`
+	ResolvedAt *time.Time `gorm:"index" json:"resolved_at,omitempty"`
+}
+
+type CreateStatusIncidentRequest struct {
+	Title     string     `json:"title" binding:"required"`
+	Message   string     `json:"message"`
+	Status    string     `json:"status"`
+	Impact    string     `json:"impact"`
+	StartedAt *time.Time `json:"started_at"`
+}
+
+// UpdateStatusIncidentRequest changes the fields given. Setting status to resolved
+// stamps ResolvedAt; moving it back out of resolved clears it.
+type UpdateStatusIncidentRequest struct {
+	Title     *string    `json:"title"`
+	Message   *string    `json:"message"`
+	Status    *string    `json:"status"`
+	Impact    *string    `json:"impact"`
+	StartedAt *time.Time `json:"started_at"`
+}
+
+// ComponentHealth is the state of one part of the site as checked just now.
+type ComponentHealth struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+}
+
+// SiteStatus is what the public status page shows.
+type SiteStatus struct {
+	Status     string            `json:"status"`
+	CheckedAt  time.Time         `json:"checked_at"`
+	Components []ComponentHealth `json:"components"`
+	Active     []StatusIncident  `json:"active_incidents"`
+	Upcoming   []StatusIncident  `json:"upcoming_maintenance"`
+	Recent     []StatusIncident  `json:"recent_incidents"`
+}
@@ -0,0 +1,91 @@
+package models
+
+import (
+	"time"
+
+	"constructor-script-backend/internal/authorization"
+)
+
+// UserTwoFactor is the TOTP authenticator of a user. It is created when
+// enrollment starts and protects the account once ConfirmedAt is set.
+type UserTwoFactor struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	UserID      uint       `gorm:"not null;uniqueIndex" json:"-"`
+	Secret      string     `gorm:"size:64;not null" json:"-"`
+	ConfirmedAt *time.Time `json:"confirmed_at,omitempty"`
+	// LastUsedStep is the time step of the last accepted code, so a code
+	// cannot be used twice.
+	LastUsedStep   int64      `gorm:"not null;default:0" json:"-"`
+	FailedAttempts int        `gorm:"not null;default:0" json:"-"`
+	LockedUntil    *time.Time `json:"-"`
+}
+
+// TwoFactorRecoveryCode is a single-use code that replaces an authenticator
+// code. Only its hash is stored.
+type TwoFactorRecoveryCode struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	UserID   uint       `gorm:"not null;index" json:"-"`
+	CodeHash string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	UsedAt   *time.Time `json:"used_at,omitempty"`
+}
+
+// TwoFactorSettings list the roles that must sign in with a second factor.
+// Users with those roles are asked to set it up at their next login.
+type TwoFactorSettings struct {
+	RequiredRoles []authorization.UserRole `json:"required_roles"`
+}
+
+type UpdateTwoFactorSettingsRequest struct {
+	RequiredRoles []string `json:"required_roles"`
+}
+
+// TwoFactorEnrollment is what an authenticator app needs to be set up.
+// ProvisioningURI is an otpauth:// URI to show as a QR code.
+type TwoFactorEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+type TwoFactorStatus struct {
+	Enabled           bool       `json:"enabled"`
+	Required          bool       `json:"required"`
+	ConfirmedAt       *time.Time `json:"confirmed_at,omitempty"`
+	RecoveryCodesLeft int64      `json:"recovery_codes_left"`
+}
+
+// TwoFactorChallenge is returned by a login that needs a second step. The
+// challenge token is sent back with a code to finish signing in.
+type TwoFactorChallenge struct {
+	TwoFactorRequired bool `json:"two_factor_required"`
+	// EnrollmentRequired is set when the user's role requires two-factor
+	// authentication and they have not set it up yet.
+	EnrollmentRequired bool      `json:"enrollment_required,omitempty"`
+	ChallengeToken     string    `json:"challenge_token"`
+	ExpiresAt          time.Time `json:"expires_at"`
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// TwoFactorChallengeRequest carries the challenge of a login. Logins through
+// an OAuth provider keep it in a cookie instead.
+type TwoFactorChallengeRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+}
+
+// TwoFactorLoginRequest finishes a login. Code is an authenticator code or a
+// recovery code.
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code" binding:"required"`
+}
+
+type DisableTwoFactorRequest struct {
+	Password string `json:"password" binding:"required"`
+}
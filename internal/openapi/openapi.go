@@ -0,0 +1,314 @@
+// Package openapi describes the /api/v1 routes as an OpenAPI 3 document. Routes
+// come from the gin engine itself, so the document cannot fall behind the router;
+// what the engine does not know, such as which routes need a signed-in user and
+// which permissions, is recorded per route group with Registry.Describe.
+package openapi
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"constructor-script-backend/pkg/apierror"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	openAPIVersion = "3.0.3"
+
+	securityBearer = "bearerAuth"
+	securityCookie = "cookieAuth"
+	// securityDelivery is the delivery token of the read-only content routes.
+	securityDelivery = "deliveryToken"
+)
+
+// Scope is what a route group requires of its callers.
+type Scope struct {
+	// Auth marks routes behind AuthMiddleware.
+	Auth bool
+	// Permissions lists the permissions RequirePermissions checks, all of which
+	// the caller needs.
+	Permissions []string
+	// DeliveryToken marks routes behind DeliveryTokenMiddleware.
+	DeliveryToken bool
+}
+
+// Info is the metadata of the document.
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// Options configures the generated document.
+type Options struct {
+	Info Info
+	// Prefix selects the routes to document.
+	Prefix string
+	// AuthCookie is the name of the cookie AuthMiddleware also accepts the token
+	// from.
+	AuthCookie string
+}
+
+type routeKey struct {
+	method string
+	path   string
+}
+
+// Registry records the scope of every route and builds the document from them.
+type Registry struct {
+	engine  *gin.Engine
+	options Options
+
+	mu     sync.Mutex
+	scopes map[routeKey]Scope
+	doc    *Document
+}
+
+func NewRegistry(engine *gin.Engine, options Options) *Registry {
+	if options.Prefix == "" {
+		options.Prefix = "/api/v1"
+	}
+	return &Registry{
+		engine:  engine,
+		options: options,
+		scopes:  make(map[routeKey]Scope),
+	}
+}
+
+// Describe gives scope to every route registered since the previous call, so it
+// is called at the end of each route group, nested groups first.
+func (r *Registry) Describe(scope Scope) {
+	if r == nil || r.engine == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, route := range r.engine.Routes() {
+		key := routeKey{method: route.Method, path: route.Path}
+		if _, described := r.scopes[key]; !described {
+			r.scopes[key] = scope
+		}
+	}
+	r.doc = nil
+}
+
+// Document returns the document for the routes registered so far. It is built
+// once and rebuilt only after Describe.
+func (r *Registry) Document() *Document {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.doc == nil {
+		r.doc = buildDocument(r.engine.Routes(), r.scopes, r.options)
+	}
+	return r.doc
+}
+
+func buildDocument(routes gin.RoutesInfo, scopes map[routeKey]Scope, options Options) *Document {
+	doc := &Document{
+		OpenAPI: openAPIVersion,
+		Info:    options.Info,
+		Servers: []Server{{URL: options.Prefix}},
+		Paths:   make(map[string]PathItem),
+		Components: Components{
+			Schemas: map[string]*Schema{"Error": errorSchema()},
+			SecuritySchemes: map[string]SecurityScheme{
+				securityBearer:   {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+				securityDelivery: {Type: "apiKey", In: "header", Name: "X-Delivery-Token"},
+			},
+		},
+	}
+	if options.AuthCookie != "" {
+		doc.Components.SecuritySchemes[securityCookie] = SecurityScheme{Type: "apiKey", In: "cookie", Name: options.AuthCookie}
+	}
+
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+
+	operationIDs := make(map[string]int)
+	tags := make(map[string]struct{})
+	for _, route := range routes {
+		if !strings.HasPrefix(route.Path, options.Prefix+"/") {
+			continue
+		}
+		relative := strings.TrimPrefix(route.Path, options.Prefix)
+		path, parameters := convertPath(relative)
+		scope := scopes[routeKey{method: route.Method, path: route.Path}]
+
+		operation := &Operation{
+			OperationID: operationID(route, operationIDs),
+			Summary:     summary(route.Handler),
+			Tags:        []string{routeTag(relative)},
+			Parameters:  parameters,
+			Responses: map[string]Response{
+				"200":     {Description: "Successful response"},
+				"default": errorResponse("Error"),
+			},
+		}
+		if scope.Auth {
+			operation.Security = []map[string][]string{{securityBearer: {}}}
+			if options.AuthCookie != "" {
+				operation.Security = append(operation.Security, map[string][]string{securityCookie: {}})
+			}
+			operation.Responses["401"] = errorResponse("Not signed in")
+		}
+		if scope.DeliveryToken {
+			operation.Security = []map[string][]string{{securityDelivery: {}}}
+			operation.Responses["401"] = errorResponse("Missing or invalid delivery token")
+		}
+		if len(scope.Permissions) > 0 {
+			operation.Permissions = scope.Permissions
+			operation.Description = "Requires the " + strings.Join(scope.Permissions, ", ") + " permission."
+			operation.Responses["403"] = errorResponse("Missing permission")
+		}
+
+		item := doc.Paths[path]
+		if item == nil {
+			item = make(PathItem)
+			doc.Paths[path] = item
+		}
+		item[strings.ToLower(route.Method)] = operation
+		tags[operation.Tags[0]] = struct{}{}
+	}
+
+	for tag := range tags {
+		doc.Tags = append(doc.Tags, Tag{Name: tag})
+	}
+	sort.Slice(doc.Tags, func(i, j int) bool { return doc.Tags[i].Name < doc.Tags[j].Name })
+	return doc
+}
+
+// errorSchema describes the apierror envelope.
+func errorSchema() *Schema {
+	return &Schema{
+		Type:     "object",
+		Required: []string{"error", "code"},
+		Properties: map[string]*Schema{
+			"error":      {Type: "string", Description: "A human-readable message."},
+			"code":       {Type: "string", Description: "A stable, machine-readable code.", Enum: apierror.Codes()},
+			"request_id": {Type: "string", Description: "The X-Request-ID of the request, for finding it in the logs."},
+			"details": {
+				Type:        "array",
+				Description: "The rejected fields of an invalid request body.",
+				Items: &Schema{
+					Type:     "object",
+					Required: []string{"field", "rule", "message"},
+					Properties: map[string]*Schema{
+						"field":   {Type: "string"},
+						"rule":    {Type: "string"},
+						"param":   {Type: "string"},
+						"message": {Type: "string"},
+					},
+				},
+			},
+		},
+	}
+}
+
+func errorResponse(description string) Response {
+	return Response{
+		Description: description,
+		Content: map[string]MediaType{
+			"application/json": {Schema: &Schema{Ref: "#/components/schemas/Error"}},
+		},
+	}
+}
+
+// convertPath turns the gin parameters of path, :name and *name, into OpenAPI
+// {name} parameters.
+func convertPath(path string) (string, []Parameter) {
+	segments := strings.Split(path, "/")
+	var parameters []Parameter
+	for i, segment := range segments {
+		if len(segment) < 2 || (segment[0] != ':' && segment[0] != '*') {
+			continue
+		}
+		name := segment[1:]
+		parameter := Parameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"}}
+		if segment[0] == '*' {
+			parameter.Description = "The rest of the path; it may contain slashes."
+		} else if name == "id" || strings.HasSuffix(name, "_id") || strings.HasSuffix(name, "Id") {
+			parameter.Schema = &Schema{Type: "integer", Minimum: 1}
+		}
+		parameters = append(parameters, parameter)
+		segments[i] = "{" + name + "}"
+	}
+	return strings.Join(segments, "/"), parameters
+}
+
+// routeTag groups routes by their first segment, after /admin for admin routes.
+func routeTag(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(segments) > 1 && segments[0] == "admin" {
+		segments = segments[1:]
+	}
+	if strings.HasPrefix(segments[0], ":") || strings.HasPrefix(segments[0], "*") || segments[0] == "" {
+		return "default"
+	}
+	return segments[0]
+}
+
+// handlerPattern matches method values, such as
+// ".../internal/handlers.(*PostHandler).GetAll-fm".
+var handlerPattern = regexp.MustCompile(`\.\(\*?([A-Za-z0-9_]+)\)\.([A-Za-z0-9_]+)-fm$`)
+
+// operationID names an operation after its handler method, as in Post.GetAll,
+// or after its method and path for other handlers. Handlers serving several
+// routes get a numeric suffix.
+func operationID(route gin.RouteInfo, seen map[string]int) string {
+	id := ""
+	if match := handlerPattern.FindStringSubmatch(route.Handler); match != nil {
+		id = strings.TrimSuffix(match[1], "Handler") + "." + match[2]
+	} else {
+		var b strings.Builder
+		b.WriteString(strings.ToLower(route.Method))
+		for _, r := range route.Path {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				b.WriteRune(r)
+			} else {
+				b.WriteRune('_')
+			}
+		}
+		id = b.String()
+	}
+
+	seen[id]++
+	if count := seen[id]; count > 1 {
+		id += "_" + strconv.Itoa(count)
+	}
+	return id
+}
+
+// summary spells out the handler method, so Post.GetAll reads "Get all".
+func summary(handler string) string {
+	match := handlerPattern.FindStringSubmatch(handler)
+	if match == nil {
+		return ""
+	}
+	var words []string
+	start := 0
+	name := match[2]
+	for i := 1; i < len(name); i++ {
+		if unicode.IsUpper(rune(name[i])) && !unicode.IsUpper(rune(name[i-1])) {
+			words = append(words, name[start:i])
+			start = i
+		}
+	}
+	words = append(words, name[start:])
+	for i := 1; i < len(words); i++ {
+		if strings.ToUpper(words[i]) != words[i] {
+			words[i] = strings.ToLower(words[i])
+		}
+	}
+	return strings.Join(words, " ")
+}
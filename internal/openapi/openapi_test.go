@@ -0,0 +1,95 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PostHandler struct{}
+
+func (h *PostHandler) GetAll(c *gin.Context)  {}
+func (h *PostHandler) GetByID(c *gin.Context) {}
+func (h *PostHandler) Delete(c *gin.Context)  {}
+
+func TestRegistryDocumentsRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	registry := NewRegistry(router, Options{Info: Info{Title: "Test API", Version: "1.0.0"}, AuthCookie: "auth_token"})
+	handler := &PostHandler{}
+
+	router.GET("/health", func(c *gin.Context) {})
+	v1 := router.Group("/api/v1")
+	v1.GET("/posts", handler.GetAll)
+	v1.GET("/posts/:id", handler.GetByID)
+	v1.GET("/archive/files/*path", func(c *gin.Context) {})
+	registry.Describe(Scope{})
+	v1.DELETE("/admin/posts/:id", handler.Delete)
+	registry.Describe(Scope{Auth: true, Permissions: []string{"manage_all_content"}})
+
+	doc := registry.Document()
+	if _, ok := doc.Paths["/health"]; ok || len(doc.Paths) != 4 {
+		t.Fatalf("expected only the /api/v1 routes, got %v", doc.Paths)
+	}
+
+	list := doc.Paths["/posts"]["get"]
+	if list == nil || list.OperationID != "Post.GetAll" || list.Summary != "Get all" || list.Security != nil {
+		t.Fatalf("unexpected public operation %+v", list)
+	}
+
+	get := doc.Paths["/posts/{id}"]["get"]
+	if get == nil || get.Summary != "Get by ID" || len(get.Parameters) != 1 || get.Parameters[0].Schema.Type != "integer" {
+		t.Fatalf("unexpected operation with an id %+v", get)
+	}
+
+	file := doc.Paths["/archive/files/{path}"]["get"]
+	if file == nil || file.Parameters[0].Schema.Type != "string" || file.Tags[0] != "archive" {
+		t.Fatalf("unexpected wildcard operation %+v", file)
+	}
+	if file.OperationID != "get_api_v1_archive_files__path" {
+		t.Fatalf("unexpected operation id %q", file.OperationID)
+	}
+
+	remove := doc.Paths["/admin/posts/{id}"]["delete"]
+	if remove == nil || remove.Tags[0] != "posts" {
+		t.Fatalf("unexpected admin operation %+v", remove)
+	}
+	wantSecurity := []map[string][]string{{securityBearer: {}}, {securityCookie: {}}}
+	if !reflect.DeepEqual(remove.Security, wantSecurity) || !reflect.DeepEqual(remove.Permissions, []string{"manage_all_content"}) {
+		t.Fatalf("unexpected admin security %+v %v", remove.Security, remove.Permissions)
+	}
+	if _, ok := remove.Responses["403"]; !ok {
+		t.Fatal("expected a 403 response for a route with permissions")
+	}
+
+	encoded, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(encoded, &decoded); err != nil || decoded["openapi"] != openAPIVersion {
+		t.Fatalf("unexpected document %s", encoded)
+	}
+}
+
+func TestDescribeKeepsFirstScope(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	registry := NewRegistry(router, Options{})
+
+	router.GET("/api/v1/profile", func(c *gin.Context) {})
+	registry.Describe(Scope{Auth: true})
+	first := registry.Document()
+	router.GET("/api/v1/status", func(c *gin.Context) {})
+	registry.Describe(Scope{})
+
+	doc := registry.Document()
+	if doc == first {
+		t.Fatal("expected Describe to rebuild the document")
+	}
+	if doc.Paths["/profile"]["get"].Security == nil || doc.Paths["/status"]["get"].Security != nil {
+		t.Fatalf("unexpected scopes %+v", doc.Paths)
+	}
+}
@@ -0,0 +1,207 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrCheckoutDisabled is returned when no provider or currency is configured.
+	ErrCheckoutDisabled = errors.New("checkout is disabled")
+	// ErrInvalidAmount indicates that a line item cannot be charged.
+	ErrInvalidAmount = errors.New("checkout amount must be greater than zero")
+)
+
+// maxDescriptionLength is the longest line item description sent to providers.
+const maxDescriptionLength = 500
+
+// CheckoutConfig holds the redirect URLs and currency used for checkout sessions.
+type CheckoutConfig struct {
+	SuccessURL string
+	CancelURL  string
+	Currency   string
+}
+
+// Order describes what a customer is buying. Line items without a currency use the
+// configured one; SuccessURL and CancelURL override the configured redirects.
+type Order struct {
+	Items         []LineItem
+	CustomerEmail string
+	Metadata      map[string]string
+	SuccessURL    string
+	CancelURL     string
+}
+
+// Checkout creates provider checkout sessions for any kind of purchase. Features such
+// as course packages or products describe the order; the checkout takes care of the
+// provider call and its configuration.
+type Checkout struct {
+	mu       sync.RWMutex
+	provider Provider
+	config   CheckoutConfig
+}
+
+// NewCheckout constructs a checkout for the given provider and configuration.
+func NewCheckout(provider Provider, cfg CheckoutConfig) *Checkout {
+	checkout := &Checkout{}
+	checkout.SetProvider(provider)
+	checkout.SetConfig(cfg)
+	return checkout
+}
+
+// SetProvider swaps the payment provider.
+func (c *Checkout) SetProvider(provider Provider) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	c.provider = provider
+	c.mu.Unlock()
+}
+
+// SetConfig updates the redirect URLs and currency.
+func (c *Checkout) SetConfig(cfg CheckoutConfig) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	c.config = CheckoutConfig{
+		SuccessURL: strings.TrimSpace(cfg.SuccessURL),
+		CancelURL:  strings.TrimSpace(cfg.CancelURL),
+		Currency:   strings.ToLower(strings.TrimSpace(cfg.Currency)),
+	}
+	c.mu.Unlock()
+}
+
+// Config returns a copy of the current configuration.
+func (c *Checkout) Config() CheckoutConfig {
+	if c == nil {
+		return CheckoutConfig{}
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.config
+}
+
+// Enabled reports whether sessions can be created.
+func (c *Checkout) Enabled() bool {
+	if c == nil {
+		return false
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.provider != nil && c.config.Currency != ""
+}
+
+// CreateSession starts a one-time payment checkout session for the order.
+func (c *Checkout) CreateSession(ctx context.Context, order Order) (*Session, error) {
+	if c == nil {
+		return nil, ErrCheckoutDisabled
+	}
+
+	c.mu.RLock()
+	provider, cfg := c.provider, c.config
+	c.mu.RUnlock()
+
+	if provider == nil || cfg.Currency == "" {
+		return nil, ErrCheckoutDisabled
+	}
+
+	successURL := strings.TrimSpace(order.SuccessURL)
+	if successURL == "" {
+		successURL = cfg.SuccessURL
+	}
+	cancelURL := strings.TrimSpace(order.CancelURL)
+	if cancelURL == "" {
+		cancelURL = cfg.CancelURL
+	}
+	if successURL == "" || cancelURL == "" {
+		return nil, ErrCheckoutDisabled
+	}
+	if len(order.Items) == 0 {
+		return nil, fmt.Errorf("checkout requires at least one line item")
+	}
+
+	items := make([]LineItem, 0, len(order.Items))
+	for _, item := range order.Items {
+		if item.AmountCents <= 0 {
+			return nil, ErrInvalidAmount
+		}
+		if item.Quantity <= 0 {
+			item.Quantity = 1
+		}
+		if strings.TrimSpace(item.Currency) == "" {
+			item.Currency = cfg.Currency
+		}
+		item.Description = TruncateDescription(item.Description)
+		items = append(items, item)
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return provider.CreateCheckoutSession(ctx, CheckoutParams{
+		Mode:          ModePayment,
+		SuccessURL:    EnsureSessionIDPlaceholder(successURL),
+		CancelURL:     cancelURL,
+		CustomerEmail: strings.TrimSpace(order.CustomerEmail),
+		Metadata:      order.Metadata,
+		LineItems:     items,
+	})
+}
+
+// RetrieveSession fetches an existing checkout session from the provider.
+func (c *Checkout) RetrieveSession(ctx context.Context, sessionID string) (*SessionDetails, error) {
+	if c == nil {
+		return nil, ErrCheckoutDisabled
+	}
+	c.mu.RLock()
+	provider := c.provider
+	c.mu.RUnlock()
+	if provider == nil {
+		return nil, ErrCheckoutDisabled
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return provider.GetCheckoutSession(ctx, sessionID)
+}
+
+// IsPaid reports whether the session has been paid for.
+func IsPaid(session *SessionDetails) bool {
+	if session == nil {
+		return false
+	}
+	return strings.EqualFold(strings.TrimSpace(session.PaymentStatus), "paid") ||
+		strings.EqualFold(strings.TrimSpace(session.Status), "complete")
+}
+
+// EnsureSessionIDPlaceholder appends the provider's session id placeholder to the
+// success URL so the landing page can verify the session.
+func EnsureSessionIDPlaceholder(successURL string) string {
+	url := strings.TrimSpace(successURL)
+	if url == "" {
+		return url
+	}
+	if strings.Contains(url, "{CHECKOUT_SESSION_ID}") {
+		return url
+	}
+	separator := "?"
+	if strings.Contains(url, "?") {
+		separator = "&"
+	}
+	return url + separator + "session_id={CHECKOUT_SESSION_ID}"
+}
+
+// TruncateDescription shortens a line item description to what providers accept.
+func TruncateDescription(value string) string {
+	runes := []rune(strings.TrimSpace(value))
+	if len(runes) <= maxDescriptionLength {
+		return string(runes)
+	}
+	return string(runes[:maxDescriptionLength])
+}
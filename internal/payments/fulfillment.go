@@ -0,0 +1,94 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// MetadataKind is the session metadata key naming the feature that fulfils a purchase.
+const MetadataKind = "kind"
+
+// ErrUnhandledKind is returned when no fulfiller is registered for a session's kind.
+var ErrUnhandledKind = errors.New("no fulfiller registered for checkout session")
+
+// Fulfiller delivers a paid purchase, for example by granting access or sending a
+// download link. It must be idempotent: providers retry webhooks and customers may
+// verify a session after the webhook already arrived.
+type Fulfiller func(ctx context.Context, session *SessionDetails) error
+
+// Fulfillments routes paid checkout sessions to the feature that created them, based
+// on the MetadataKind metadata value.
+type Fulfillments struct {
+	mu         sync.RWMutex
+	fulfillers map[string]Fulfiller
+	completed  func(ctx context.Context, session *SessionDetails)
+}
+
+// NewFulfillments creates an empty fulfilment registry.
+func NewFulfillments() *Fulfillments {
+	return &Fulfillments{fulfillers: make(map[string]Fulfiller)}
+}
+
+// Register associates a fulfiller with a purchase kind, replacing any previous one.
+func (f *Fulfillments) Register(kind string, fulfiller Fulfiller) {
+	kind = strings.TrimSpace(kind)
+	if f == nil || kind == "" || fulfiller == nil {
+		return
+	}
+	f.mu.Lock()
+	f.fulfillers[kind] = fulfiller
+	f.mu.Unlock()
+}
+
+// OnCompleted calls handler after a session is fulfilled for the first time in this
+// process; retried fulfilments of the same session do not call it again.
+func (f *Fulfillments) OnCompleted(handler func(ctx context.Context, session *SessionDetails)) {
+	if f == nil {
+		return
+	}
+	f.mu.Lock()
+	f.completed = handler
+	f.mu.Unlock()
+}
+
+// Unregister removes the fulfiller for kind.
+func (f *Fulfillments) Unregister(kind string) {
+	if f == nil {
+		return
+	}
+	f.mu.Lock()
+	delete(f.fulfillers, strings.TrimSpace(kind))
+	f.mu.Unlock()
+}
+
+// Fulfill hands a paid session to the fulfiller registered for its kind.
+func (f *Fulfillments) Fulfill(ctx context.Context, session *SessionDetails) error {
+	if session == nil {
+		return fmt.Errorf("checkout session is required")
+	}
+	kind := strings.TrimSpace(session.Metadata[MetadataKind])
+	if f == nil || kind == "" {
+		return ErrUnhandledKind
+	}
+
+	f.mu.RLock()
+	fulfiller, completed := f.fulfillers[kind], f.completed
+	f.mu.RUnlock()
+	if fulfiller == nil {
+		return fmt.Errorf("%w: %s", ErrUnhandledKind, kind)
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if err := fulfiller(ctx, session); err != nil {
+		return err
+	}
+	if RecordCheckoutCompleted(session) && completed != nil {
+		completed(ctx, session)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package payments
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// completedSessionMemory bounds how many session IDs are remembered to avoid
+// counting a checkout twice when both the webhook and the return page fulfil it.
+const completedSessionMemory = 4096
+
+var checkoutsCompleted = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "constructor_script",
+	Subsystem: "payments",
+	Name:      "checkouts_completed_total",
+	Help:      "Paid checkout sessions fulfilled, by purchase kind",
+}, []string{"kind"})
+
+var completedSessions = struct {
+	sync.Mutex
+	seen  map[string]struct{}
+	order []string
+}{seen: make(map[string]struct{})}
+
+// RecordCheckoutCompleted counts a fulfilled checkout session and reports whether
+// it was counted. Sessions already counted by this process are ignored, since
+// fulfilment is retried by design.
+func RecordCheckoutCompleted(session *SessionDetails) bool {
+	if session == nil {
+		return false
+	}
+	id := strings.TrimSpace(session.ID)
+	if id != "" {
+		completedSessions.Lock()
+		if _, ok := completedSessions.seen[id]; ok {
+			completedSessions.Unlock()
+			return false
+		}
+		if len(completedSessions.order) >= completedSessionMemory {
+			delete(completedSessions.seen, completedSessions.order[0])
+			completedSessions.order = completedSessions.order[1:]
+		}
+		completedSessions.seen[id] = struct{}{}
+		completedSessions.order = append(completedSessions.order, id)
+		completedSessions.Unlock()
+	}
+
+	kind := strings.TrimSpace(session.Metadata[MetadataKind])
+	if kind == "" {
+		kind = "unknown"
+	}
+	checkoutsCompleted.WithLabelValues(kind).Inc()
+	return true
+}
@@ -0,0 +1,19 @@
+package payments
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestRecordCheckoutCompletedCountsSessionsOnce(t *testing.T) {
+	session := &SessionDetails{ID: "cs_metrics_test", Metadata: map[string]string{MetadataKind: "metrics-test"}}
+
+	RecordCheckoutCompleted(session)
+	RecordCheckoutCompleted(session)
+	RecordCheckoutCompleted(&SessionDetails{ID: "cs_metrics_test_2", Metadata: session.Metadata})
+
+	if got := testutil.ToFloat64(checkoutsCompleted.WithLabelValues("metrics-test")); got != 2 {
+		t.Fatalf("checkouts completed = %v, want 2", got)
+	}
+}
@@ -4,13 +4,68 @@ import (
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strconv"
 	"strings"
 	"time"
+
+	"constructor-script-backend/internal/payments"
 )
 
+// EventCheckoutSessionCompleted is the webhook event sent when a customer finishes checkout.
+const EventCheckoutSessionCompleted = "checkout.session.completed"
+
+// WebhookEvent is a verified Stripe webhook event carrying a checkout session.
+type WebhookEvent struct {
+	Type    string
+	Session payments.SessionDetails
+}
+
+// Completed reports whether the event signals a finished checkout session.
+func (e *WebhookEvent) Completed() bool {
+	return e != nil && strings.EqualFold(e.Type, EventCheckoutSessionCompleted)
+}
+
+type webhookPayload struct {
+	Type string `json:"type"`
+	Data struct {
+		Object struct {
+			ID            string            `json:"id"`
+			Status        string            `json:"status"`
+			PaymentStatus string            `json:"payment_status"`
+			Metadata      map[string]string `json:"metadata"`
+			CustomerEmail string            `json:"customer_email"`
+		} `json:"object"`
+	} `json:"data"`
+}
+
+// ParseWebhookEvent verifies the signature header and decodes the checkout session
+// carried by the event.
+func ParseWebhookEvent(payload []byte, header, secret string, tolerance time.Duration) (*WebhookEvent, error) {
+	if err := VerifyWebhookSignature(payload, header, secret, tolerance); err != nil {
+		return nil, err
+	}
+
+	var decoded webhookPayload
+	if err := json.Unmarshal(payload, &decoded); err != nil {
+		return nil, fmt.Errorf("invalid stripe webhook payload: %w", err)
+	}
+
+	object := decoded.Data.Object
+	return &WebhookEvent{
+		Type: decoded.Type,
+		Session: payments.SessionDetails{
+			ID:            object.ID,
+			Status:        object.Status,
+			PaymentStatus: object.PaymentStatus,
+			Metadata:      object.Metadata,
+			CustomerEmail: object.CustomerEmail,
+		},
+	}, nil
+}
+
 // VerifyWebhookSignature validates a Stripe webhook signature header against the payload.
 // It follows Stripe's recommendation: https://stripe.com/docs/webhooks/signatures
 func VerifyWebhookSignature(payload []byte, header, secret string, tolerance time.Duration) error {
@@ -0,0 +1,59 @@
+// Package backup lets plugins declare the tables they own so site backups carry
+// their data. Plugins register from init; the backup service snapshots every
+// registered table and restores it after the core content. Uploaded files need no
+// registration because the whole upload directory is archived.
+package backup
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Plugin lists the tables owned by one plugin.
+type Plugin struct {
+	Slug string
+	// Models are the gorm models of the plugin tables, parents before children, in
+	// the order they must be restored.
+	Models []interface{}
+}
+
+var (
+	mu         sync.RWMutex
+	registered = make(map[string][]interface{})
+)
+
+// Register adds the models backed up for the plugin identified by slug, parents
+// before children. It panics on an empty slug or duplicate registration so
+// mistakes surface at startup.
+func Register(slug string, models ...interface{}) {
+	cleaned := strings.ToLower(strings.TrimSpace(slug))
+	if cleaned == "" {
+		panic("backup: plugin slug is required")
+	}
+	if len(models) == 0 {
+		panic(fmt.Sprintf("backup: %s: no models given", cleaned))
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if _, exists := registered[cleaned]; exists {
+		panic(fmt.Sprintf("backup: %s: already registered", cleaned))
+	}
+	registered[cleaned] = append([]interface{}(nil), models...)
+}
+
+// Plugins returns the registered plugins ordered by slug.
+func Plugins() []Plugin {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	plugins := make([]Plugin, 0, len(registered))
+	for slug, models := range registered {
+		plugins = append(plugins, Plugin{Slug: slug, Models: append([]interface{}(nil), models...)})
+	}
+	sort.Slice(plugins, func(i, j int) bool { return plugins[i].Slug < plugins[j].Slug })
+	return plugins
+}
@@ -0,0 +1,42 @@
+package backup
+
+import "testing"
+
+type parentModel struct{ ID uint }
+type childModel struct{ ID uint }
+
+func TestRegisterKeepsModelOrder(t *testing.T) {
+	Register("test-zeta", &parentModel{})
+	Register(" Test-Alpha ", &parentModel{}, &childModel{})
+
+	alpha, zeta := -1, -1
+	plugins := Plugins()
+	for index, plugin := range plugins {
+		switch plugin.Slug {
+		case "test-alpha":
+			alpha = index
+			if len(plugin.Models) != 2 {
+				t.Fatalf("expected 2 models, got %d", len(plugin.Models))
+			}
+			if _, ok := plugin.Models[0].(*parentModel); !ok {
+				t.Fatalf("expected the parent model first, got %T", plugin.Models[0])
+			}
+		case "test-zeta":
+			zeta = index
+		}
+	}
+	if alpha < 0 || zeta < 0 || alpha > zeta {
+		t.Fatalf("expected plugins ordered by slug, got %+v", plugins)
+	}
+}
+
+func TestRegisterRejectsDuplicates(t *testing.T) {
+	Register("test-duplicate", &parentModel{})
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected a panic on duplicate registration")
+		}
+	}()
+	Register("test-duplicate", &childModel{})
+}
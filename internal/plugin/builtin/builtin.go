@@ -4,6 +4,9 @@ import (
 	_ "constructor-script-backend/plugins/archive"
 	_ "constructor-script-backend/plugins/blog"
 	_ "constructor-script-backend/plugins/courses"
+	_ "constructor-script-backend/plugins/events"
 	_ "constructor-script-backend/plugins/forum"
 	_ "constructor-script-backend/plugins/language"
+	_ "constructor-script-backend/plugins/newsletter"
+	_ "constructor-script-backend/plugins/products"
 )
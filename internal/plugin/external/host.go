@@ -0,0 +1,398 @@
+package external
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"constructor-script-backend/internal/events"
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/plugin"
+	"constructor-script-backend/internal/plugin/external/pluginpb"
+	pluginruntime "constructor-script-backend/internal/plugin/runtime"
+	"constructor-script-backend/internal/sections"
+	"constructor-script-backend/pkg/logger"
+)
+
+// RoutePrefix is the public URL prefix of routes served by out-of-process plugins.
+const RoutePrefix = "/ext/"
+
+const (
+	sectionRenderTimeout = 3 * time.Second
+	eventDeliveryTimeout = 5 * time.Second
+	routeTimeout         = 30 * time.Second
+	maxRouteBodySize     = 8 << 20
+	sectionCategory      = "plugin"
+)
+
+// SectionRegistrar is the part of the template handler used to expose plugin sections.
+type SectionRegistrar interface {
+	RegisterSectionWithMetadata(desc *sections.SectionDescriptor) error
+	UnregisterSection(sectionType string)
+}
+
+type instance struct {
+	process     *Process
+	spec        *plugin.RuntimeSpec
+	sections    []string
+	unsubscribe []func()
+}
+
+// Host manages the out-of-process plugins of the application.
+type Host struct {
+	mu         sync.RWMutex
+	instances  map[string]*instance
+	sections   SectionRegistrar
+	bus        *events.Bus
+	credential *syscall.Credential
+}
+
+// NewHost creates a host that registers plugin sections with registrar and delivers
+// events from bus.
+func NewHost(registrar SectionRegistrar, bus *events.Bus) *Host {
+	return &Host{
+		instances: make(map[string]*instance),
+		sections:  registrar,
+		bus:       bus,
+	}
+}
+
+// SetUser configures the operating system user plugin processes run as. It must be
+// an unprivileged account other than the one running the core, so plugins cannot
+// read the core's environment, configuration or secret files. Without it no
+// out-of-process plugin is started.
+func (h *Host) SetUser(uid, gid int) error {
+	if h == nil {
+		return nil
+	}
+	if uid <= 0 || gid <= 0 {
+		return fmt.Errorf("plugin user must be unprivileged: uid %d, gid %d", uid, gid)
+	}
+	if uid == os.Geteuid() {
+		return fmt.Errorf("plugin user must differ from the user running the application (uid %d)", uid)
+	}
+
+	h.mu.Lock()
+	h.credential = &syscall.Credential{Uid: uint32(uid), Gid: uint32(gid)}
+	h.mu.Unlock()
+	return nil
+}
+
+// Feature returns the runtime feature that starts and stops the plugin process.
+func (h *Host) Feature(entry *plugin.Plugin) pluginruntime.Feature {
+	if h == nil || entry == nil || entry.Metadata.Runtime == nil {
+		return nil
+	}
+
+	slug := entry.Slug
+	dir := entry.Path
+	spec := entry.Metadata.Runtime
+
+	return pluginruntime.FeatureFunc{
+		ActivateFunc: func() error {
+			return h.start(slug, dir, spec)
+		},
+		DeactivateFunc: func() error {
+			return h.stop(slug)
+		},
+	}
+}
+
+func (h *Host) start(slug, dir string, spec *plugin.RuntimeSpec) error {
+	h.mu.RLock()
+	credential := h.credential
+	h.mu.RUnlock()
+
+	process := newProcess(slug, dir, spec, credential)
+	if err := process.Start(); err != nil {
+		return err
+	}
+
+	inst := &instance{process: process, spec: spec}
+
+	for _, section := range spec.Capabilities.Sections {
+		sectionType := strings.ToLower(strings.TrimSpace(section.Type))
+		label := strings.TrimSpace(section.Label)
+		if label == "" {
+			label = sectionType
+		}
+		if h.sections == nil {
+			break
+		}
+		err := h.sections.RegisterSectionWithMetadata(&sections.SectionDescriptor{
+			Renderer: h.sectionRenderer(slug, sectionType, process),
+			Metadata: sections.SectionMetadata{
+				Type:        sectionType,
+				Name:        label,
+				Description: section.Description,
+				Category:    sectionCategory,
+			},
+		})
+		if err != nil {
+			logger.Error(err, "Failed to register plugin section", map[string]interface{}{"plugin": slug, "section": sectionType})
+			continue
+		}
+		inst.sections = append(inst.sections, sectionType)
+	}
+
+	for _, name := range spec.Capabilities.Events {
+		if h.bus == nil {
+			break
+		}
+		inst.unsubscribe = append(inst.unsubscribe, h.bus.Subscribe(name, func(ctx context.Context, event events.Event) {
+			deliverEvent(ctx, slug, process, event)
+		}))
+	}
+
+	h.mu.Lock()
+	previous := h.instances[slug]
+	h.instances[slug] = inst
+	h.mu.Unlock()
+
+	if previous != nil {
+		h.release(previous)
+	}
+	return nil
+}
+
+func (h *Host) stop(slug string) error {
+	h.mu.Lock()
+	inst := h.instances[slug]
+	delete(h.instances, slug)
+	h.mu.Unlock()
+
+	if inst == nil {
+		return nil
+	}
+	return h.release(inst)
+}
+
+func (h *Host) release(inst *instance) error {
+	for _, unsubscribe := range inst.unsubscribe {
+		unsubscribe()
+	}
+	if h.sections != nil {
+		for _, sectionType := range inst.sections {
+			h.sections.UnregisterSection(sectionType)
+		}
+	}
+	return inst.process.Stop()
+}
+
+// Shutdown stops every running plugin process.
+func (h *Host) Shutdown() {
+	if h == nil {
+		return
+	}
+
+	h.mu.Lock()
+	instances := h.instances
+	h.instances = make(map[string]*instance)
+	h.mu.Unlock()
+
+	for slug, inst := range instances {
+		if err := h.release(inst); err != nil {
+			logger.Error(err, "Failed to stop plugin process", map[string]interface{}{"plugin": slug})
+		}
+	}
+}
+
+// ServeRoute passes /ext/:slug/*path to the plugin when the route is declared in
+// its manifest. Credentials of the visitor are never forwarded and the plugin
+// cannot set cookies.
+func (h *Host) ServeRoute(c *gin.Context) {
+	if h == nil {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	slug := strings.ToLower(strings.TrimSpace(c.Param("slug")))
+	requestPath := c.Param("path")
+	if requestPath == "" {
+		requestPath = "/"
+	}
+
+	h.mu.RLock()
+	inst := h.instances[slug]
+	h.mu.RUnlock()
+
+	if inst == nil || !inst.spec.AllowsRoute(c.Request.Method, requestPath) {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+	client, err := inst.process.Client()
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "plugin is unavailable"})
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxRouteBodySize))
+	if err != nil {
+		c.AbortWithStatus(http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), routeTimeout)
+	defer cancel()
+
+	response, err := client.ServeHTTP(ctx, &pluginpb.HTTPRequest{
+		Method:     c.Request.Method,
+		Path:       requestPath,
+		RawQuery:   c.Request.URL.RawQuery,
+		Headers:    forwardedHeaders(c.Request),
+		Body:       body,
+		RemoteAddr: c.ClientIP(),
+	})
+	if err != nil {
+		logger.Warn("Plugin route failed", map[string]interface{}{"plugin": slug, "path": requestPath, "error": err.Error()})
+		c.AbortWithStatus(http.StatusBadGateway)
+		return
+	}
+
+	status := int(response.GetStatus())
+	if status == 0 {
+		status = http.StatusOK
+	}
+	if status < 100 || status > 999 {
+		c.AbortWithStatus(http.StatusBadGateway)
+		return
+	}
+
+	header := c.Writer.Header()
+	for _, field := range response.GetHeaders() {
+		name := http.CanonicalHeaderKey(field.GetName())
+		if name == "" || name == "Set-Cookie" || name == "Content-Length" || hopHeaders[name] {
+			continue
+		}
+		for _, value := range field.GetValues() {
+			header.Add(name, value)
+		}
+	}
+	c.Status(status)
+	c.Writer.Write(response.GetBody())
+}
+
+var hopHeaders = map[string]bool{
+	"Connection":          true,
+	"Keep-Alive":          true,
+	"Proxy-Authenticate":  true,
+	"Proxy-Authorization": true,
+	"Te":                  true,
+	"Trailer":             true,
+	"Transfer-Encoding":   true,
+	"Upgrade":             true,
+}
+
+// forwardedHeaders copies the request headers the plugin may see and adds the
+// usual X-Forwarded-* headers.
+func forwardedHeaders(r *http.Request) []*pluginpb.Header {
+	var headers []*pluginpb.Header
+	for name, values := range r.Header {
+		if name == "Cookie" || name == "Authorization" || hopHeaders[name] || strings.HasPrefix(name, "X-Forwarded-") {
+			continue
+		}
+		headers = append(headers, &pluginpb.Header{Name: name, Values: values})
+	}
+
+	proto := "http"
+	if r.TLS != nil {
+		proto = "https"
+	}
+	forwarded := []*pluginpb.Header{
+		{Name: "X-Forwarded-Host", Values: []string{r.Host}},
+		{Name: "X-Forwarded-Proto", Values: []string{proto}},
+	}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		forwarded = append(forwarded, &pluginpb.Header{Name: "X-Forwarded-For", Values: []string{host}})
+	}
+	return append(headers, forwarded...)
+}
+
+func (h *Host) sectionRenderer(slug, sectionType string, process *Process) sections.Renderer {
+	scriptPrefix := RoutePrefix + slug + "/"
+
+	return func(ctx sections.RenderContext, prefix string, elem models.SectionElement) (string, []string) {
+		client, err := process.Client()
+		if err != nil {
+			return "", nil
+		}
+
+		content := &structpb.Value{}
+		if err := toProtoJSON(elem.Content, content); err != nil {
+			return "", nil
+		}
+
+		requestCtx, cancel := context.WithTimeout(context.Background(), sectionRenderTimeout)
+		defer cancel()
+
+		result, err := client.RenderSection(requestCtx, &pluginpb.RenderSectionRequest{
+			Type:    sectionType,
+			Prefix:  prefix,
+			Id:      elem.ID,
+			Content: content,
+		})
+		if err != nil {
+			logger.Warn("Plugin section render failed", map[string]interface{}{"plugin": slug, "section": sectionType, "error": err.Error()})
+			return "", nil
+		}
+
+		// Scripts may only be served by the plugin's own declared routes.
+		var scripts []string
+		for _, script := range result.GetScripts() {
+			if strings.HasPrefix(script, scriptPrefix) {
+				scripts = append(scripts, script)
+			}
+		}
+
+		return ctx.SanitizeHTML(result.GetHtml()), scripts
+	}
+}
+
+func deliverEvent(ctx context.Context, slug string, process *Process, event events.Event) {
+	client, err := process.Client()
+	if err != nil {
+		logger.Warn("Failed to deliver event to plugin", map[string]interface{}{"plugin": slug, "event": event.Name, "error": err.Error()})
+		return
+	}
+
+	payload := &structpb.Struct{}
+	if event.Payload != nil {
+		if err := toProtoJSON(event.Payload, payload); err != nil {
+			return
+		}
+	}
+
+	requestCtx, cancel := context.WithTimeout(ctx, eventDeliveryTimeout)
+	defer cancel()
+
+	_, err = client.HandleEvent(requestCtx, &pluginpb.Event{
+		Name:       event.Name,
+		OccurredAt: timestamppb.New(event.OccurredAt),
+		Payload:    payload,
+	})
+	if err != nil {
+		logger.Warn("Failed to deliver event to plugin", map[string]interface{}{"plugin": slug, "event": event.Name, "error": err.Error()})
+	}
+}
+
+// toProtoJSON converts value to a well-known protobuf type through its JSON form,
+// so section content and event payloads keep the field names used everywhere else.
+func toProtoJSON(value interface{}, target json.Unmarshaler) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return target.UnmarshalJSON(data)
+}
@@ -0,0 +1,131 @@
+package external
+
+import (
+	"context"
+	"html/template"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/plugin"
+	"constructor-script-backend/internal/plugin/external/pluginpb"
+	"constructor-script-backend/internal/sections"
+)
+
+type fakePlugin struct {
+	pluginpb.UnimplementedPluginServer
+	request *pluginpb.HTTPRequest
+}
+
+func (p *fakePlugin) RenderSection(_ context.Context, req *pluginpb.RenderSectionRequest) (*pluginpb.RenderSectionResponse, error) {
+	title := req.GetContent().GetStructValue().GetFields()["title"].GetStringValue()
+	return &pluginpb.RenderSectionResponse{
+		Html:    "<h2>" + title + "</h2><script>alert(1)</script>",
+		Scripts: []string{"/ext/demo/widget.js", "https://evil.example/x.js"},
+	}, nil
+}
+
+func (p *fakePlugin) ServeHTTP(_ context.Context, req *pluginpb.HTTPRequest) (*pluginpb.HTTPResponse, error) {
+	p.request = req
+	return &pluginpb.HTTPResponse{
+		Status: http.StatusCreated,
+		Headers: []*pluginpb.Header{
+			{Name: "Content-Type", Values: []string{"text/plain"}},
+			{Name: "Set-Cookie", Values: []string{"session=stolen"}},
+		},
+		Body: []byte("ok"),
+	}, nil
+}
+
+type stripScripts struct{}
+
+func (stripScripts) SanitizeHTML(input string) string {
+	if i := strings.Index(input, "<script>"); i >= 0 {
+		return input[:i]
+	}
+	return input
+}
+func (stripScripts) Templates() (*template.Template, error) { return nil, nil }
+func (stripScripts) Services() sections.ServiceProvider     { return nil }
+
+func startFakePlugin(t *testing.T, server *fakePlugin) *Process {
+	t.Helper()
+	socket := filepath.Join(t.TempDir(), "plugin.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	grpcServer := grpc.NewServer()
+	pluginpb.RegisterPluginServer(grpcServer, server)
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient("unix://"+socket, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return &Process{slug: "demo", running: true, conn: conn, client: pluginpb.NewPluginClient(conn)}
+}
+
+func TestSectionRenderer(t *testing.T) {
+	process := startFakePlugin(t, &fakePlugin{})
+	render := (&Host{}).sectionRenderer("demo", "demo-card", process)
+
+	html, scripts := render(stripScripts{}, "section", models.SectionElement{ID: "e1", Content: map[string]interface{}{"title": "Hello"}})
+	if html != "<h2>Hello</h2>" {
+		t.Fatalf("expected the sanitized plugin markup, got %q", html)
+	}
+	if len(scripts) != 1 || scripts[0] != "/ext/demo/widget.js" {
+		t.Fatalf("expected only the plugin's own scripts, got %v", scripts)
+	}
+}
+
+func TestServeRoute(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	fake := &fakePlugin{}
+	process := startFakePlugin(t, fake)
+	spec := &plugin.RuntimeSpec{Capabilities: plugin.Capabilities{Routes: []plugin.RouteCapability{{Method: http.MethodPost, Path: "/submit"}}}}
+	host := &Host{instances: map[string]*instance{"demo": {process: process, spec: spec}}}
+
+	router := gin.New()
+	router.Any("/ext/:slug/*path", host.ServeRoute)
+
+	request := httptest.NewRequest(http.MethodPost, "/ext/demo/submit?x=1", strings.NewReader("payload"))
+	request.Header.Set("Cookie", "session=secret")
+	request.Header.Set("Authorization", "Bearer secret")
+	request.Header.Set("X-Forwarded-For", "10.0.0.1")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusCreated || recorder.Body.String() != "ok" || recorder.Header().Get("Set-Cookie") != "" {
+		t.Fatalf("unexpected response %d %q %v", recorder.Code, recorder.Body.String(), recorder.Header())
+	}
+	if fake.request.GetPath() != "/submit" || fake.request.GetRawQuery() != "x=1" || string(fake.request.GetBody()) != "payload" {
+		t.Fatalf("unexpected request %+v", fake.request)
+	}
+	for _, header := range fake.request.GetHeaders() {
+		switch header.GetName() {
+		case "Cookie", "Authorization":
+			t.Fatalf("expected %s not to be forwarded", header.GetName())
+		case "X-Forwarded-For":
+			if strings.Join(header.GetValues(), ",") == "10.0.0.1" {
+				t.Fatal("expected the client's X-Forwarded-For to be replaced")
+			}
+		}
+	}
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/ext/demo/submit", nil))
+	if recorder.Code != http.StatusNotFound {
+		t.Fatalf("expected an undeclared route to be refused, got %d", recorder.Code)
+	}
+}
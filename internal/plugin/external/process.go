@@ -0,0 +1,344 @@
+// Package external runs plugins as separate processes. The core starts the declared
+// executable under a dedicated operating system user, talks to it over gRPC on a
+// private Unix socket (see pluginpb/plugin.proto) and only uses the capabilities
+// (routes, sections, events) declared in the plugin manifest, so a misbehaving plugin
+// cannot crash the main binary or read its environment and secret files.
+package external
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"constructor-script-backend/internal/plugin"
+	"constructor-script-backend/internal/plugin/external/pluginpb"
+	"constructor-script-backend/pkg/logger"
+)
+
+const (
+	// SocketEnv is the environment variable holding the socket path the plugin must
+	// serve the pluginpb.Plugin gRPC service on.
+	SocketEnv = "CONSTRUCTOR_PLUGIN_SOCKET"
+	// SlugEnv holds the plugin slug.
+	SlugEnv = "CONSTRUCTOR_PLUGIN_SLUG"
+
+	startupTimeout    = 10 * time.Second
+	maxRestartBackoff = 30 * time.Second
+	maxMessageSize    = 16 << 20
+)
+
+var (
+	ErrProcessNotRunning = errors.New("plugin process is not running")
+	// ErrPluginUserRequired is returned when no dedicated user is configured for
+	// out-of-process plugins.
+	ErrPluginUserRequired = errors.New("out-of-process plugins require PLUGIN_UID and PLUGIN_GID to name a dedicated unprivileged user")
+)
+
+// Process supervises one plugin executable and restarts it when it exits unexpectedly.
+type Process struct {
+	slug       string
+	dir        string
+	spec       *plugin.RuntimeSpec
+	credential *syscall.Credential
+
+	mu         sync.Mutex
+	generation uint64
+	cmd        *exec.Cmd
+	exited     chan struct{}
+	socketDir  string
+	socket     string
+	running    bool
+	stopping   bool
+	conn       *grpc.ClientConn
+	client     pluginpb.PluginClient
+}
+
+func newProcess(slug, dir string, spec *plugin.RuntimeSpec, credential *syscall.Credential) *Process {
+	return &Process{slug: slug, dir: dir, spec: spec, credential: credential}
+}
+
+// Start launches the plugin and waits until its Health call succeeds. Each Start
+// begins a new generation; supervisors of earlier generations never relaunch.
+func (p *Process) Start() error {
+	p.mu.Lock()
+	p.stopping = false
+	p.generation++
+	generation := p.generation
+	p.mu.Unlock()
+	return p.launch(generation)
+}
+
+// current reports whether generation is still the one the process should run.
+// Callers hold p.mu.
+func (p *Process) current(generation uint64) bool {
+	return !p.stopping && p.generation == generation
+}
+
+func (p *Process) launch(generation uint64) error {
+	if p.credential == nil {
+		return ErrPluginUserRequired
+	}
+
+	executable, err := p.executable()
+	if err != nil {
+		return err
+	}
+
+	socketDir, err := os.MkdirTemp("", "cs-plugin-")
+	if err != nil {
+		return fmt.Errorf("failed to create plugin socket directory: %w", err)
+	}
+	// The plugin user creates the socket, and nobody else may reach it.
+	if err := os.Chown(socketDir, int(p.credential.Uid), int(p.credential.Gid)); err != nil {
+		os.RemoveAll(socketDir)
+		return fmt.Errorf("failed to hand the plugin socket directory to the plugin user: %w", err)
+	}
+	socket := filepath.Join(socketDir, "plugin.sock")
+
+	cmd := exec.Command(executable, p.spec.Args...)
+	cmd.Dir = p.dir
+	cmd.Env = p.environment(socket)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Credential: p.credential}
+	cmd.Stdout = pluginLogWriter{slug: p.slug}
+	cmd.Stderr = pluginLogWriter{slug: p.slug, stderr: true}
+
+	conn, err := grpc.NewClient("unix://"+socket,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(maxMessageSize), grpc.MaxCallSendMsgSize(maxMessageSize)),
+	)
+	if err != nil {
+		os.RemoveAll(socketDir)
+		return fmt.Errorf("failed to create plugin %s client: %w", p.slug, err)
+	}
+
+	// Starting and publishing the command happen under p.mu so a concurrent Stop
+	// either prevents the launch or sees the command and kills it.
+	p.mu.Lock()
+	if !p.current(generation) {
+		p.mu.Unlock()
+		conn.Close()
+		os.RemoveAll(socketDir)
+		return ErrProcessNotRunning
+	}
+	if err := cmd.Start(); err != nil {
+		p.mu.Unlock()
+		conn.Close()
+		os.RemoveAll(socketDir)
+		return fmt.Errorf("failed to start plugin %s: %w", p.slug, err)
+	}
+	client := pluginpb.NewPluginClient(conn)
+	exited := make(chan struct{})
+	p.cmd = cmd
+	p.exited = exited
+	p.socketDir = socketDir
+	p.socket = socket
+	p.conn = conn
+	p.client = client
+	p.mu.Unlock()
+
+	go p.supervise(generation, cmd, conn, socketDir, exited)
+
+	if err := p.waitReady(client, exited); err != nil {
+		// Detach the command first so its supervisor does not restart it.
+		p.mu.Lock()
+		if p.cmd == cmd {
+			p.cmd = nil
+		}
+		p.mu.Unlock()
+		p.kill(cmd, exited)
+		return err
+	}
+
+	p.mu.Lock()
+	if p.cmd != cmd || !p.current(generation) {
+		// Stopped or replaced while waiting for the health check.
+		p.mu.Unlock()
+		return ErrProcessNotRunning
+	}
+	p.running = true
+	p.mu.Unlock()
+
+	logger.Info("Started out-of-process plugin", map[string]interface{}{"plugin": p.slug, "pid": cmd.Process.Pid})
+	return nil
+}
+
+func (p *Process) waitReady(client pluginpb.PluginClient, exited <-chan struct{}) error {
+	deadline := time.Now().Add(startupTimeout)
+	for time.Now().Before(deadline) {
+		select {
+		case <-exited:
+			return fmt.Errorf("plugin %s exited during startup", p.slug)
+		default:
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		_, err := client.Health(ctx, &pluginpb.HealthRequest{})
+		cancel()
+		if err == nil {
+			return nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return fmt.Errorf("plugin %s did not become healthy within %s", p.slug, startupTimeout)
+}
+
+func (p *Process) supervise(generation uint64, cmd *exec.Cmd, conn *grpc.ClientConn, socketDir string, exited chan struct{}) {
+	err := cmd.Wait()
+	close(exited)
+	conn.Close()
+	os.RemoveAll(socketDir)
+
+	p.mu.Lock()
+	owned := p.cmd == cmd
+	if owned {
+		p.cmd = nil
+		p.running = false
+	}
+	relaunch := owned && p.current(generation)
+	p.mu.Unlock()
+
+	if !relaunch {
+		return
+	}
+
+	logger.Warn("Out-of-process plugin exited; restarting", map[string]interface{}{"plugin": p.slug, "error": fmt.Sprint(err)})
+
+	backoff := time.Second
+	for {
+		time.Sleep(backoff)
+
+		// A Stop, or a Stop followed by a new Start, during the backoff ends this
+		// supervisor: the new generation has its own.
+		p.mu.Lock()
+		relaunch = p.current(generation) && p.cmd == nil
+		p.mu.Unlock()
+		if !relaunch {
+			return
+		}
+
+		if err := p.launch(generation); err == nil || errors.Is(err, ErrProcessNotRunning) {
+			return
+		} else {
+			logger.Error(err, "Failed to restart out-of-process plugin", map[string]interface{}{"plugin": p.slug})
+		}
+
+		backoff *= 2
+		if backoff > maxRestartBackoff {
+			backoff = maxRestartBackoff
+		}
+	}
+}
+
+// Stop terminates the plugin process and disables automatic restarts.
+func (p *Process) Stop() error {
+	p.mu.Lock()
+	p.stopping = true
+	p.running = false
+	cmd := p.cmd
+	exited := p.exited
+	p.mu.Unlock()
+
+	p.kill(cmd, exited)
+	return nil
+}
+
+func (p *Process) kill(cmd *exec.Cmd, exited <-chan struct{}) {
+	if cmd == nil || cmd.Process == nil {
+		return
+	}
+	_ = cmd.Process.Signal(os.Interrupt)
+
+	select {
+	case <-exited:
+	case <-time.After(5 * time.Second):
+		_ = cmd.Process.Kill()
+	}
+}
+
+// Running reports whether the plugin answered its health check and has not exited.
+func (p *Process) Running() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.running
+}
+
+// Client returns the gRPC client of the running plugin.
+func (p *Process) Client() (pluginpb.PluginClient, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.running || p.client == nil {
+		return nil, ErrProcessNotRunning
+	}
+	return p.client, nil
+}
+
+func (p *Process) executable() (string, error) {
+	root, err := filepath.Abs(p.dir)
+	if err != nil {
+		return "", err
+	}
+	target := filepath.Join(root, filepath.FromSlash(p.spec.Command))
+	if resolved, err := filepath.EvalSymlinks(target); err == nil {
+		target = resolved
+	}
+	if resolvedRoot, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolvedRoot
+	}
+	if !strings.HasPrefix(target, root+string(filepath.Separator)) {
+		return "", fmt.Errorf("plugin %s command must be inside the plugin directory", p.slug)
+	}
+
+	info, err := os.Stat(target)
+	if err != nil {
+		return "", fmt.Errorf("plugin %s command not found: %w", p.slug, err)
+	}
+	if info.IsDir() || info.Mode()&0o111 == 0 {
+		return "", fmt.Errorf("plugin %s command is not executable", p.slug)
+	}
+	return target, nil
+}
+
+// environment only passes what the plugin needs; database credentials, secrets and
+// other core configuration are never inherited.
+func (p *Process) environment(socket string) []string {
+	env := []string{
+		SocketEnv + "=" + socket,
+		SlugEnv + "=" + p.slug,
+		"HOME=" + p.dir,
+	}
+	for _, key := range []string{"PATH", "TZ", "LANG", "TMPDIR"} {
+		if value, ok := os.LookupEnv(key); ok {
+			env = append(env, key+"="+value)
+		}
+	}
+	return env
+}
+
+type pluginLogWriter struct {
+	slug   string
+	stderr bool
+}
+
+func (w pluginLogWriter) Write(data []byte) (int, error) {
+	message := strings.TrimSpace(string(data))
+	if message == "" {
+		return len(data), nil
+	}
+	fields := map[string]interface{}{"plugin": w.slug}
+	if w.stderr {
+		logger.Warn(message, fields)
+	} else {
+		logger.Info(message, fields)
+	}
+	return len(data), nil
+}
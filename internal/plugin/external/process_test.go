@@ -0,0 +1,52 @@
+package external
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"constructor-script-backend/internal/plugin"
+)
+
+func TestStartRequiresPluginUser(t *testing.T) {
+	process := newProcess("demo", t.TempDir(), &plugin.RuntimeSpec{Command: "bin/plugin"}, nil)
+
+	if err := process.Start(); !errors.Is(err, ErrPluginUserRequired) {
+		t.Fatalf("expected ErrPluginUserRequired, got %v", err)
+	}
+	if process.Running() {
+		t.Fatal("expected the process not to be running")
+	}
+}
+
+func TestSetUserRejectsPrivilegedAndCoreUsers(t *testing.T) {
+	cases := []struct {
+		name     string
+		uid, gid int
+	}{
+		{"root user", 0, 1000},
+		{"root group", 1000, 0},
+		{"unset", -1, -1},
+		{"core user", os.Geteuid(), 1000},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			host := NewHost(nil, nil)
+			if err := host.SetUser(tc.uid, tc.gid); err == nil {
+				t.Fatalf("expected uid %d gid %d to be rejected", tc.uid, tc.gid)
+			}
+			if host.credential != nil {
+				t.Fatal("expected no plugin user to be configured")
+			}
+		})
+	}
+
+	host := NewHost(nil, nil)
+	uid := os.Geteuid() + 1
+	if err := host.SetUser(uid, 65534); err != nil {
+		t.Fatalf("expected an unprivileged user to be accepted: %v", err)
+	}
+	if host.credential == nil || int(host.credential.Uid) != uid || host.credential.Gid != 65534 {
+		t.Fatalf("unexpected plugin user %+v", host.credential)
+	}
+}
@@ -3,19 +3,24 @@ package host
 import (
 	"constructor-script-backend/internal/background"
 	"constructor-script-backend/internal/config"
+	"constructor-script-backend/internal/events"
 	"constructor-script-backend/internal/handlers"
 	"constructor-script-backend/internal/repository"
 	"constructor-script-backend/internal/service"
 	"constructor-script-backend/internal/theme"
 	"constructor-script-backend/pkg/cache"
 	languageservice "constructor-script-backend/plugins/language/service"
+
+	"gorm.io/gorm"
 )
 
 type Host interface {
 	Config() *config.Config
+	Database() *gorm.DB
 	Cache() *cache.Cache
 	Scheduler() *background.Scheduler
 	ThemeManager() *theme.Manager
+	Events() *events.Bus
 
 	Repositories() RepositoryAccess
 	CoreServices() CoreServiceAccess
@@ -43,12 +48,14 @@ type RepositoryAccess interface {
 	Comment() repository.CommentRepository
 	Search() repository.SearchRepository
 	Setting() repository.SettingRepository
+	SlugRedirect() repository.SlugRedirectRepository
 	User() repository.UserRepository
 CourseVideo() repository.CourseVideoRepository
 CourseContent() repository.CourseContentRepository
 CourseTopic() repository.CourseTopicRepository
 	CoursePackage() repository.CoursePackageRepository
 	CoursePackageAccess() repository.CoursePackageAccessRepository
+	CourseAnnouncement() repository.CourseAnnouncementRepository
 	CourseTest() repository.CourseTestRepository
 	ForumCategory() repository.ForumCategoryRepository
 	ForumQuestion() repository.ForumQuestionRepository
@@ -57,6 +64,9 @@ CourseTopic() repository.CourseTopicRepository
 	ForumAnswerVote() repository.ForumAnswerVoteRepository
 	ArchiveDirectory() repository.ArchiveDirectoryRepository
 	ArchiveFile() repository.ArchiveFileRepository
+	Newsletter() repository.NewsletterRepository
+	Event() repository.EventRepository
+	Product() repository.ProductRepository
 }
 
 type CoreServiceAccess interface {
@@ -67,6 +77,12 @@ type CoreServiceAccess interface {
 	Menu() *service.MenuService
 	Advertising() *service.AdvertisingService
 	Upload() *service.UploadService
+	Email() *service.EmailService
+	Payments() *service.PaymentService
+	Digest() *service.DigestService
+	Notification() *service.NotificationService
+	Revision() *service.RevisionService
+	Plugins() *service.PluginService
 	Language() *languageservice.LanguageService
 	SetLanguage(*languageservice.LanguageService)
 }
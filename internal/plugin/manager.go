@@ -27,6 +27,17 @@ type Metadata struct {
 	Description string `json:"description"`
 	Author      string `json:"author"`
 	Homepage    string `json:"homepage"`
+
+	// Requires is a version constraint the core must satisfy (for example ">=1.2.0").
+	Requires string `json:"requires,omitempty"`
+	// Dependencies maps the slugs of other plugins to the version constraint they
+	// must satisfy.
+	Dependencies map[string]string `json:"dependencies,omitempty"`
+
+	Settings []SettingDefinition `json:"settings,omitempty"`
+
+	// Runtime is set for plugins that run out of process.
+	Runtime *RuntimeSpec `json:"runtime,omitempty"`
 }
 
 // Plugin represents a plugin that is available on disk.
@@ -190,6 +201,13 @@ func loadManifest(dir string) (Metadata, error) {
 		manifest.Slug = manifest.Name
 	}
 
+	if err := ValidateSettingDefinitions(manifest.Settings); err != nil {
+		return Metadata{}, fmt.Errorf("invalid plugin settings: %w", err)
+	}
+	if err := manifest.Runtime.Validate(); err != nil {
+		return Metadata{}, fmt.Errorf("invalid plugin runtime: %w", err)
+	}
+
 	manifest.Slug = strings.ToLower(strings.TrimSpace(manifest.Slug))
 	return manifest, nil
 }
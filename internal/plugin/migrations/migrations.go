@@ -0,0 +1,325 @@
+// Package migrations lets plugins own versioned schema changes. Plugins register
+// their steps from init and the core applies them while running database migrations,
+// recording every applied version in the plugin_migrations table.
+package migrations
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"constructor-script-backend/pkg/logger"
+)
+
+// Stage controls when a migration runs relative to the core schema auto-migration.
+type Stage int
+
+const (
+	// StagePostSchema migrations run after the core models were auto-migrated. This
+	// is the default and suits data backfills and additional indexes.
+	StagePostSchema Stage = iota
+	// StagePreSchema migrations run before auto-migration, for changes that would
+	// otherwise make it fail (for example backfilling a column that becomes NOT NULL).
+	StagePreSchema
+)
+
+// Migration is a single versioned step owned by a plugin. Versions must be unique and
+// positive per plugin; steps are applied in ascending order.
+type Migration struct {
+	Version     int
+	Description string
+	Stage       Stage
+	// Repeatable steps run at every startup instead of once and are never
+	// recorded, for idempotent repairs of rows that writers outside the plugin
+	// may still leave incomplete.
+	Repeatable bool
+	Up         func(tx *gorm.DB) error
+	Down       func(tx *gorm.DB) error
+}
+
+// Record stores an applied plugin migration.
+type Record struct {
+	ID          uint      `gorm:"primarykey"`
+	Plugin      string    `gorm:"size:100;not null;uniqueIndex:idx_plugin_migrations_plugin_version,priority:1"`
+	Version     int       `gorm:"not null;uniqueIndex:idx_plugin_migrations_plugin_version,priority:2"`
+	Description string    `gorm:"size:255"`
+	AppliedAt   time.Time `gorm:"not null"`
+}
+
+// TableName keeps the table name stable regardless of the struct name.
+func (Record) TableName() string {
+	return "plugin_migrations"
+}
+
+var (
+	mu         sync.RWMutex
+	registered = make(map[string][]Migration)
+	tables     = make(map[string][]interface{})
+)
+
+// Register adds migrations for the plugin identified by slug. It panics on invalid
+// or duplicate versions so mistakes surface at startup.
+func Register(slug string, steps ...Migration) {
+	cleaned := strings.ToLower(strings.TrimSpace(slug))
+	if cleaned == "" {
+		panic("migrations: plugin slug is required")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	existing := registered[cleaned]
+	seen := make(map[int]struct{}, len(existing)+len(steps))
+	for _, step := range existing {
+		seen[step.Version] = struct{}{}
+	}
+	for _, step := range steps {
+		if step.Version <= 0 || step.Up == nil {
+			panic(fmt.Sprintf("migrations: %s: invalid migration version %d", cleaned, step.Version))
+		}
+		if _, ok := seen[step.Version]; ok {
+			panic(fmt.Sprintf("migrations: %s: duplicate migration version %d", cleaned, step.Version))
+		}
+		seen[step.Version] = struct{}{}
+		existing = append(existing, step)
+	}
+
+	sort.Slice(existing, func(i, j int) bool { return existing[i].Version < existing[j].Version })
+	registered[cleaned] = existing
+}
+
+// Plugins returns the slugs that registered migrations, sorted.
+func Plugins() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	result := make([]string, 0, len(registered))
+	for slug := range registered {
+		result = append(result, slug)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// RegisterTables declares the gorm models of the tables a plugin owns, parents before
+// children. The core creates them only while the plugin is active, so tables dropped
+// when the plugin's data is purged are not recreated at the next startup.
+func RegisterTables(slug string, models ...interface{}) {
+	cleaned := strings.ToLower(strings.TrimSpace(slug))
+	if cleaned == "" {
+		panic("migrations: plugin slug is required")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	tables[cleaned] = append(tables[cleaned], models...)
+}
+
+// TableOwners returns the slugs of the plugins that registered tables, sorted.
+func TableOwners() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	result := make([]string, 0, len(tables))
+	for slug := range tables {
+		result = append(result, slug)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func tablesFor(slug string) []interface{} {
+	mu.RLock()
+	defer mu.RUnlock()
+	return append([]interface{}(nil), tables[slug]...)
+}
+
+func stepsFor(slug string) []Migration {
+	mu.RLock()
+	defer mu.RUnlock()
+	return append([]Migration(nil), registered[slug]...)
+}
+
+// EnsureTable creates the bookkeeping table.
+func EnsureTable(db *gorm.DB) error {
+	if db == nil {
+		return errors.New("database connection is not initialized")
+	}
+	return db.AutoMigrate(&Record{})
+}
+
+// Run applies the pending migrations of every plugin for the given stage, except
+// for the plugins in skip.
+func Run(db *gorm.DB, stage Stage, skip map[string]bool) error {
+	for _, slug := range Plugins() {
+		if skip[slug] {
+			continue
+		}
+		if err := Migrate(db, slug, stage); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MigrateTables auto-migrates the registered tables of every plugin except for the
+// plugins in skip.
+func MigrateTables(db *gorm.DB, skip map[string]bool) error {
+	if db == nil {
+		return errors.New("database connection is not initialized")
+	}
+	for _, slug := range TableOwners() {
+		if skip[slug] {
+			continue
+		}
+		if err := db.AutoMigrate(tablesFor(slug)...); err != nil {
+			return fmt.Errorf("failed to migrate plugin %s tables: %w", slug, err)
+		}
+	}
+	return nil
+}
+
+// Install brings one plugin's schema up to date: its pending pre-schema steps, its
+// tables and its pending post-schema steps. It runs when a plugin is activated,
+// since the core only migrates the tables of active plugins at startup.
+func Install(db *gorm.DB, slug string) error {
+	if db == nil {
+		return errors.New("database connection is not initialized")
+	}
+	cleaned := strings.ToLower(strings.TrimSpace(slug))
+
+	if err := EnsureTable(db); err != nil {
+		return fmt.Errorf("failed to prepare plugin migrations: %w", err)
+	}
+	if err := Migrate(db, cleaned, StagePreSchema); err != nil {
+		return err
+	}
+	if models := tablesFor(cleaned); len(models) > 0 {
+		if err := db.AutoMigrate(models...); err != nil {
+			return fmt.Errorf("failed to migrate plugin %s tables: %w", cleaned, err)
+		}
+	}
+	return Migrate(db, cleaned, StagePostSchema)
+}
+
+// Migrate applies the pending migrations of one plugin for the given stage, and its
+// repeatable ones. Each step runs in its own transaction together with its
+// bookkeeping record.
+func Migrate(db *gorm.DB, slug string, stage Stage) error {
+	if db == nil {
+		return errors.New("database connection is not initialized")
+	}
+
+	applied, err := appliedVersions(db, slug)
+	if err != nil {
+		return err
+	}
+
+	for _, step := range stepsFor(slug) {
+		if step.Stage != stage {
+			continue
+		}
+		if step.Repeatable {
+			if err := db.Transaction(step.Up); err != nil {
+				return fmt.Errorf("plugin %s migration %d (%s) failed: %w", slug, step.Version, step.Description, err)
+			}
+			continue
+		}
+		if _, ok := applied[step.Version]; ok {
+			continue
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := step.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&Record{
+				Plugin:      slug,
+				Version:     step.Version,
+				Description: step.Description,
+				AppliedAt:   time.Now().UTC(),
+			}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("plugin %s migration %d (%s) failed: %w", slug, step.Version, step.Description, err)
+		}
+
+		logger.Info("Applied plugin migration", map[string]interface{}{
+			"plugin":      slug,
+			"version":     step.Version,
+			"description": step.Description,
+		})
+	}
+
+	return nil
+}
+
+// Rollback reverts the applied migrations of a plugin above target, newest first.
+// Migrations without a Down step stop the rollback with an error.
+func Rollback(db *gorm.DB, slug string, target int) error {
+	if db == nil {
+		return errors.New("database connection is not initialized")
+	}
+
+	applied, err := appliedVersions(db, slug)
+	if err != nil {
+		return err
+	}
+
+	steps := stepsFor(slug)
+	for i := len(steps) - 1; i >= 0; i-- {
+		step := steps[i]
+		if step.Version <= target {
+			break
+		}
+		if _, ok := applied[step.Version]; !ok {
+			continue
+		}
+		if step.Down == nil {
+			return fmt.Errorf("plugin %s migration %d cannot be reverted", slug, step.Version)
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := step.Down(tx); err != nil {
+				return err
+			}
+			return tx.Where("plugin = ? AND version = ?", slug, step.Version).Delete(&Record{}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("plugin %s migration %d rollback failed: %w", slug, step.Version, err)
+		}
+	}
+
+	return nil
+}
+
+// Forget removes the applied migration records of a plugin so its migrations run
+// again if it is installed later. It is used when plugin data is purged.
+func Forget(db *gorm.DB, slug string) error {
+	if db == nil {
+		return errors.New("database connection is not initialized")
+	}
+	cleaned := strings.ToLower(strings.TrimSpace(slug))
+	if err := db.Where("plugin = ?", cleaned).Delete(&Record{}).Error; err != nil {
+		return fmt.Errorf("failed to clear plugin migrations: %w", err)
+	}
+	return nil
+}
+
+func appliedVersions(db *gorm.DB, slug string) (map[int]struct{}, error) {
+	var records []Record
+	if err := db.Where("plugin = ?", slug).Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("failed to load plugin migrations: %w", err)
+	}
+
+	result := make(map[int]struct{}, len(records))
+	for _, record := range records {
+		result[record.Version] = struct{}{}
+	}
+	return result, nil
+}
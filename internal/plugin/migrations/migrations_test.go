@@ -0,0 +1,287 @@
+package migrations
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// The registry is global, so every test registers its steps under its own slug.
+var testSlugs atomic.Int64
+
+func testSlug(name string) string {
+	return fmt.Sprintf("test-%s-%d", name, testSlugs.Add(1))
+}
+
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{
+		Logger: gormlogger.Default.LogMode(gormlogger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	if err := EnsureTable(db); err != nil {
+		t.Fatalf("ensure table: %v", err)
+	}
+	return db
+}
+
+func recordedVersions(t *testing.T, db *gorm.DB, slug string) []int {
+	t.Helper()
+	var versions []int
+	if err := db.Model(&Record{}).Where("plugin = ?", slug).Order("version").Pluck("version", &versions).Error; err != nil {
+		t.Fatalf("load records: %v", err)
+	}
+	return versions
+}
+
+func noop(*gorm.DB) error { return nil }
+
+func TestRegisterPanicsOnInvalidMigrations(t *testing.T) {
+	cases := []struct {
+		name     string
+		slug     string
+		existing []Migration
+		steps    []Migration
+	}{
+		{"empty slug", " ", nil, []Migration{{Version: 1, Up: noop}}},
+		{"zero version", "", nil, []Migration{{Version: 0, Up: noop}}},
+		{"negative version", "", nil, []Migration{{Version: -1, Up: noop}}},
+		{"missing up", "", nil, []Migration{{Version: 1}}},
+		{"duplicate in one call", "", nil, []Migration{{Version: 1, Up: noop}, {Version: 1, Up: noop}}},
+		{"duplicate across calls", "", []Migration{{Version: 2, Up: noop}}, []Migration{{Version: 2, Up: noop}}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			slug := tc.slug
+			if slug == "" {
+				slug = testSlug("register")
+			}
+			if len(tc.existing) > 0 {
+				Register(slug, tc.existing...)
+			}
+
+			defer func() {
+				if recover() == nil {
+					t.Fatal("expected Register to panic")
+				}
+			}()
+			Register(slug, tc.steps...)
+		})
+	}
+}
+
+func TestMigrateAppliesStepsInVersionOrderPerStage(t *testing.T) {
+	cases := []struct {
+		name  string
+		stage Stage
+		want  []int
+	}{
+		{"post-schema", StagePostSchema, []int{1, 3, 4}},
+		{"pre-schema", StagePreSchema, []int{2}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			db := openTestDB(t)
+			slug := testSlug("order")
+
+			var ran []int
+			step := func(version int, stage Stage) Migration {
+				return Migration{Version: version, Stage: stage, Up: func(*gorm.DB) error {
+					ran = append(ran, version)
+					return nil
+				}}
+			}
+			// Registered out of order and across calls, as separate init functions would.
+			Register(slug, step(4, StagePostSchema), step(1, StagePostSchema))
+			Register(slug, step(3, StagePostSchema), step(2, StagePreSchema))
+
+			if err := Migrate(db, slug, tc.stage); err != nil {
+				t.Fatalf("migrate: %v", err)
+			}
+			if !reflect.DeepEqual(ran, tc.want) {
+				t.Fatalf("expected steps %v, ran %v", tc.want, ran)
+			}
+			if got := recordedVersions(t, db, slug); !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("expected records %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestMigrateSkipsAppliedAndRerunsRepeatableSteps(t *testing.T) {
+	db := openTestDB(t)
+	slug := testSlug("applied")
+
+	counts := make(map[int]int)
+	count := func(version int) func(*gorm.DB) error {
+		return func(*gorm.DB) error {
+			counts[version]++
+			return nil
+		}
+	}
+	Register(slug,
+		Migration{Version: 1, Up: count(1)},
+		Migration{Version: 2, Repeatable: true, Up: count(2)},
+	)
+
+	for run := 0; run < 3; run++ {
+		if err := Migrate(db, slug, StagePostSchema); err != nil {
+			t.Fatalf("migrate run %d: %v", run, err)
+		}
+	}
+
+	if counts[1] != 1 {
+		t.Fatalf("expected the versioned step to run once, ran %d times", counts[1])
+	}
+	if counts[2] != 3 {
+		t.Fatalf("expected the repeatable step to run at every migration, ran %d times", counts[2])
+	}
+	if got := recordedVersions(t, db, slug); !reflect.DeepEqual(got, []int{1}) {
+		t.Fatalf("expected only the versioned step to be recorded, got %v", got)
+	}
+}
+
+func TestMigrateDoesNotRecordFailedSteps(t *testing.T) {
+	db := openTestDB(t)
+	slug := testSlug("failed")
+
+	failure := errors.New("boom")
+	Register(slug,
+		Migration{Version: 1, Up: noop},
+		Migration{Version: 2, Up: func(*gorm.DB) error { return failure }},
+		Migration{Version: 3, Up: noop},
+	)
+
+	if err := Migrate(db, slug, StagePostSchema); !errors.Is(err, failure) {
+		t.Fatalf("expected the step error, got %v", err)
+	}
+	if got := recordedVersions(t, db, slug); !reflect.DeepEqual(got, []int{1}) {
+		t.Fatalf("expected only the steps before the failure to be recorded, got %v", got)
+	}
+}
+
+func TestRollbackStopsAtStepWithoutDown(t *testing.T) {
+	db := openTestDB(t)
+	slug := testSlug("rollback")
+
+	var reverted []int
+	down := func(version int) func(*gorm.DB) error {
+		return func(*gorm.DB) error {
+			reverted = append(reverted, version)
+			return nil
+		}
+	}
+	Register(slug,
+		Migration{Version: 1, Up: noop, Down: down(1)},
+		Migration{Version: 2, Up: noop},
+		Migration{Version: 3, Up: noop, Down: down(3)},
+		Migration{Version: 4, Up: noop, Down: down(4)},
+	)
+	if err := Migrate(db, slug, StagePostSchema); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	if err := Rollback(db, slug, 3); err != nil {
+		t.Fatalf("rollback to 3: %v", err)
+	}
+	if err := Rollback(db, slug, 0); err == nil {
+		t.Fatal("expected the rollback to stop at the step without Down")
+	}
+
+	if !reflect.DeepEqual(reverted, []int{4, 3}) {
+		t.Fatalf("expected steps 4 and 3 to be reverted, got %v", reverted)
+	}
+	if got := recordedVersions(t, db, slug); !reflect.DeepEqual(got, []int{1, 2}) {
+		t.Fatalf("expected steps 1 and 2 to stay applied, got %v", got)
+	}
+}
+
+type installWidget struct {
+	ID   uint
+	Name string
+}
+
+func TestInstallAndForget(t *testing.T) {
+	db := openTestDB(t)
+	slug := testSlug("install")
+
+	var ran []string
+	RegisterTables(slug, &installWidget{})
+	Register(slug,
+		Migration{Version: 1, Stage: StagePreSchema, Up: func(tx *gorm.DB) error {
+			ran = append(ran, "pre")
+			return nil
+		}},
+		Migration{Version: 2, Up: func(tx *gorm.DB) error {
+			if !tx.Migrator().HasTable(&installWidget{}) {
+				return errors.New("post-schema step ran before the tables were created")
+			}
+			ran = append(ran, "post")
+			return tx.Create(&installWidget{Name: "seeded"}).Error
+		}},
+	)
+
+	// Skipped at startup, as for an inactive plugin. Steps other tests registered are
+	// skipped as well.
+	skip := map[string]bool{slug: true}
+	for _, other := range Plugins() {
+		skip[other] = true
+	}
+	for _, stage := range []Stage{StagePreSchema, StagePostSchema} {
+		if err := Run(db, stage, skip); err != nil {
+			t.Fatalf("run: %v", err)
+		}
+	}
+	if err := MigrateTables(db, skip); err != nil {
+		t.Fatalf("migrate tables: %v", err)
+	}
+	if db.Migrator().HasTable(&installWidget{}) || len(ran) != 0 {
+		t.Fatalf("expected a skipped plugin to be left alone, ran %v", ran)
+	}
+
+	if err := Install(db, slug); err != nil {
+		t.Fatalf("install: %v", err)
+	}
+	if err := Install(db, slug); err != nil {
+		t.Fatalf("second install: %v", err)
+	}
+	if !reflect.DeepEqual(ran, []string{"pre", "post"}) {
+		t.Fatalf("expected each step once in stage order, ran %v", ran)
+	}
+	if got := recordedVersions(t, db, slug); !reflect.DeepEqual(got, []int{1, 2}) {
+		t.Fatalf("expected both steps to be recorded, got %v", got)
+	}
+
+	if err := db.Migrator().DropTable(&installWidget{}); err != nil {
+		t.Fatalf("drop table: %v", err)
+	}
+	if err := Forget(db, slug); err != nil {
+		t.Fatalf("forget: %v", err)
+	}
+	if got := recordedVersions(t, db, slug); len(got) != 0 {
+		t.Fatalf("expected Forget to clear the records, got %v", got)
+	}
+
+	ran = nil
+	if err := Install(db, slug); err != nil {
+		t.Fatalf("reinstall: %v", err)
+	}
+	if !reflect.DeepEqual(ran, []string{"pre", "post"}) {
+		t.Fatalf("expected a forgotten plugin to migrate again, ran %v", ran)
+	}
+	var widgets int64
+	if err := db.Model(&installWidget{}).Count(&widgets).Error; err != nil || widgets != 1 {
+		t.Fatalf("expected the reinstalled table to be seeded once, got %d (%v)", widgets, err)
+	}
+}
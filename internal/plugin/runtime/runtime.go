@@ -1,6 +1,10 @@
 package runtime
 
-import "sync"
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
 
 // Feature defines the activation lifecycle for a runtime plugin feature.
 type Feature interface {
@@ -8,6 +12,13 @@ type Feature interface {
 	Deactivate() error
 }
 
+// Uninstaller is implemented by features that own persistent data. Uninstall is
+// called on an inactive feature when an administrator asks for the plugin data to be
+// purged and must remove the tables and files the plugin created.
+type Uninstaller interface {
+	Uninstall() error
+}
+
 // Runtime coordinates activation and deactivation of runtime features.
 type Runtime struct {
 	mu        sync.RWMutex
@@ -44,6 +55,37 @@ func (r *Runtime) Register(slug string, feature Feature) {
 	delete(r.activated, slug)
 }
 
+// Active reports whether the feature identified by slug is registered and activated.
+func (r *Runtime) Active(slug string) bool {
+	if r == nil || slug == "" {
+		return false
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.activated[slug]
+}
+
+// ActiveSlugs returns the slugs of the activated features in order.
+func (r *Runtime) ActiveSlugs() []string {
+	if r == nil {
+		return nil
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	slugs := make([]string, 0, len(r.activated))
+	for slug, active := range r.activated {
+		if active {
+			slugs = append(slugs, slug)
+		}
+	}
+	sort.Strings(slugs)
+	return slugs
+}
+
 // Activate enables the feature identified by slug if it exists.
 func (r *Runtime) Activate(slug string) error {
 	if r == nil || slug == "" {
@@ -108,6 +150,32 @@ func (r *Runtime) Deactivate(slug string) error {
 	return nil
 }
 
+// Uninstall purges the data of the feature identified by slug. The feature must not be
+// active. Features that do not implement Uninstaller are left untouched.
+func (r *Runtime) Uninstall(slug string) error {
+	if r == nil || slug == "" {
+		return nil
+	}
+
+	r.mu.RLock()
+	feature, ok := r.features[slug]
+	isActivated := r.activated[slug]
+	r.mu.RUnlock()
+
+	if !ok || feature == nil {
+		return nil
+	}
+	if isActivated {
+		return fmt.Errorf("plugin %s must be deactivated before its data is purged", slug)
+	}
+
+	uninstaller, ok := feature.(Uninstaller)
+	if !ok {
+		return nil
+	}
+	return uninstaller.Uninstall()
+}
+
 // Unregister removes a feature implementation for the provided slug.
 // This cleans up memory by removing references to the feature.
 func (r *Runtime) Unregister(slug string) error {
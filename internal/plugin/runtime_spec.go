@@ -0,0 +1,104 @@
+package plugin
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// RuntimeTypeProcess runs the plugin as a separate executable that serves the
+// plugin gRPC service on a Unix socket provided by the core.
+const RuntimeTypeProcess = "process"
+
+// RuntimeSpec declares that a plugin runs out of process together with the
+// capabilities the core is allowed to use. Anything not declared is never routed to
+// the plugin.
+type RuntimeSpec struct {
+	Type         string       `json:"type"`
+	Command      string       `json:"command"`
+	Args         []string     `json:"args,omitempty"`
+	Capabilities Capabilities `json:"capabilities"`
+}
+
+// Capabilities is the surface an out-of-process plugin exposes to the core.
+type Capabilities struct {
+	Routes   []RouteCapability   `json:"routes,omitempty"`
+	Sections []SectionCapability `json:"sections,omitempty"`
+	Events   []string            `json:"events,omitempty"`
+}
+
+// RouteCapability is an HTTP route served by the plugin under /ext/<slug>/.
+type RouteCapability struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+}
+
+// SectionCapability is a page builder section rendered by the plugin.
+type SectionCapability struct {
+	Type        string `json:"type"`
+	Label       string `json:"label"`
+	Description string `json:"description,omitempty"`
+}
+
+// IsExternal reports whether the plugin runs out of process.
+func (m Metadata) IsExternal() bool {
+	return m.Runtime != nil
+}
+
+// Validate checks the runtime declaration of an out-of-process plugin.
+func (r *RuntimeSpec) Validate() error {
+	if r == nil {
+		return nil
+	}
+	if r.Type != RuntimeTypeProcess {
+		return fmt.Errorf("unsupported runtime type %q", r.Type)
+	}
+
+	command := strings.TrimSpace(r.Command)
+	cleaned := path.Clean(command)
+	if command == "" || path.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return fmt.Errorf("runtime command must be a path inside the plugin directory")
+	}
+
+	for _, route := range r.Capabilities.Routes {
+		switch strings.ToUpper(route.Method) {
+		case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		default:
+			return fmt.Errorf("route %s: unsupported method %q", route.Path, route.Method)
+		}
+		if !strings.HasPrefix(route.Path, "/") {
+			return fmt.Errorf("route %s: path must start with /", route.Path)
+		}
+	}
+
+	for _, section := range r.Capabilities.Sections {
+		if strings.TrimSpace(section.Type) == "" {
+			return fmt.Errorf("section type is required")
+		}
+	}
+	return nil
+}
+
+// AllowsRoute reports whether method and path match a declared route. Route paths may
+// end with /* to match a whole subtree.
+func (r *RuntimeSpec) AllowsRoute(method, requestPath string) bool {
+	if r == nil {
+		return false
+	}
+	for _, route := range r.Capabilities.Routes {
+		if !strings.EqualFold(route.Method, method) {
+			continue
+		}
+		if prefix, ok := strings.CutSuffix(route.Path, "/*"); ok {
+			if requestPath == prefix || strings.HasPrefix(requestPath, prefix+"/") {
+				return true
+			}
+			continue
+		}
+		if route.Path == requestPath {
+			return true
+		}
+	}
+	return false
+}
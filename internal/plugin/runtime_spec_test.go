@@ -0,0 +1,48 @@
+package plugin
+
+import "testing"
+
+func TestRuntimeSpecAllowsRoute(t *testing.T) {
+	spec := &RuntimeSpec{
+		Type:    RuntimeTypeProcess,
+		Command: "bin/plugin",
+		Capabilities: Capabilities{
+			Routes: []RouteCapability{
+				{Method: "GET", Path: "/widget"},
+				{Method: "POST", Path: "/api/*"},
+			},
+		},
+	}
+	if err := spec.Validate(); err != nil {
+		t.Fatalf("unexpected validation error: %v", err)
+	}
+
+	cases := []struct {
+		method string
+		path   string
+		want   bool
+	}{
+		{"GET", "/widget", true},
+		{"POST", "/widget", false},
+		{"GET", "/widget/extra", false},
+		{"POST", "/api/items", true},
+		{"POST", "/api", true},
+		{"POST", "/apis", false},
+		{"GET", "/api/items", false},
+	}
+
+	for _, tc := range cases {
+		if got := spec.AllowsRoute(tc.method, tc.path); got != tc.want {
+			t.Fatalf("%s %s: expected %v, got %v", tc.method, tc.path, tc.want, got)
+		}
+	}
+}
+
+func TestRuntimeSpecRejectsEscapingCommand(t *testing.T) {
+	for _, command := range []string{"", "/usr/bin/env", "../outside"} {
+		spec := &RuntimeSpec{Type: RuntimeTypeProcess, Command: command}
+		if err := spec.Validate(); err == nil {
+			t.Fatalf("expected command %q to be rejected", command)
+		}
+	}
+}
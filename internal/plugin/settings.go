@@ -0,0 +1,133 @@
+package plugin
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Setting types supported in plugin manifests.
+const (
+	SettingTypeText     = "text"
+	SettingTypeTextarea = "textarea"
+	SettingTypeURL      = "url"
+	SettingTypeNumber   = "number"
+	SettingTypeBoolean  = "boolean"
+	SettingTypeSelect   = "select"
+)
+
+// SettingDefinition describes a single option declared in the "settings" section of a
+// plugin manifest. The admin settings page is generated from these definitions and the
+// stored values are validated against them.
+type SettingDefinition struct {
+	Key         string      `json:"key"`
+	Label       string      `json:"label"`
+	Description string      `json:"description,omitempty"`
+	Type        string      `json:"type"`
+	Default     interface{} `json:"default,omitempty"`
+	Options     []string    `json:"options,omitempty"`
+	Required    bool        `json:"required,omitempty"`
+}
+
+// SettingDefinition returns the manifest definition for key.
+func (m Metadata) SettingDefinition(key string) (SettingDefinition, bool) {
+	cleaned := strings.TrimSpace(key)
+	for _, definition := range m.Settings {
+		if definition.Key == cleaned {
+			return definition, true
+		}
+	}
+	return SettingDefinition{}, false
+}
+
+// Normalize converts a submitted value to the type declared by the definition.
+func (d SettingDefinition) Normalize(value interface{}) (interface{}, error) {
+	if value == nil {
+		if d.Required {
+			return nil, fmt.Errorf("%s is required", d.Key)
+		}
+		return nil, nil
+	}
+
+	switch d.Type {
+	case SettingTypeBoolean:
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			parsed, err := strconv.ParseBool(strings.TrimSpace(v))
+			if err != nil {
+				return nil, fmt.Errorf("%s must be a boolean", d.Key)
+			}
+			return parsed, nil
+		}
+		return nil, fmt.Errorf("%s must be a boolean", d.Key)
+	case SettingTypeNumber:
+		switch v := value.(type) {
+		case float64:
+			return v, nil
+		case int:
+			return float64(v), nil
+		case string:
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				return nil, fmt.Errorf("%s must be a number", d.Key)
+			}
+			return parsed, nil
+		}
+		return nil, fmt.Errorf("%s must be a number", d.Key)
+	}
+
+	text, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("%s must be a string", d.Key)
+	}
+	text = strings.TrimSpace(text)
+	if text == "" {
+		if d.Required {
+			return nil, fmt.Errorf("%s is required", d.Key)
+		}
+		return "", nil
+	}
+
+	switch d.Type {
+	case SettingTypeURL:
+		if !strings.HasPrefix(text, "/") && !strings.HasPrefix(text, "http://") && !strings.HasPrefix(text, "https://") {
+			return nil, fmt.Errorf("%s must be an absolute URL or a path", d.Key)
+		}
+	case SettingTypeSelect:
+		for _, option := range d.Options {
+			if option == text {
+				return text, nil
+			}
+		}
+		return nil, fmt.Errorf("%s must be one of: %s", d.Key, strings.Join(d.Options, ", "))
+	}
+	return text, nil
+}
+
+// ValidateSettingDefinitions checks the settings declared by a plugin manifest.
+func ValidateSettingDefinitions(definitions []SettingDefinition) error {
+	seen := make(map[string]struct{}, len(definitions))
+	for i, definition := range definitions {
+		key := strings.TrimSpace(definition.Key)
+		if key == "" {
+			return fmt.Errorf("setting %d: key is required", i)
+		}
+		if _, exists := seen[key]; exists {
+			return fmt.Errorf("setting %s is declared more than once", key)
+		}
+		seen[key] = struct{}{}
+
+		switch definition.Type {
+		case SettingTypeText, SettingTypeTextarea, SettingTypeURL, SettingTypeNumber, SettingTypeBoolean:
+		case SettingTypeSelect:
+			if len(definition.Options) == 0 {
+				return fmt.Errorf("setting %s: select requires options", key)
+			}
+		default:
+			return fmt.Errorf("setting %s: unsupported type %q", key, definition.Type)
+		}
+	}
+	return nil
+}
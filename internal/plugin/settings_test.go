@@ -0,0 +1,40 @@
+package plugin
+
+import "testing"
+
+func TestSettingDefinitionNormalize(t *testing.T) {
+	boolean := SettingDefinition{Key: "enabled", Type: SettingTypeBoolean}
+	if value, err := boolean.Normalize("true"); err != nil || value != true {
+		t.Fatalf("expected boolean true, got %v (%v)", value, err)
+	}
+
+	selectDef := SettingDefinition{Key: "mode", Type: SettingTypeSelect, Options: []string{"a", "b"}}
+	if _, err := selectDef.Normalize("c"); err == nil {
+		t.Fatalf("expected error for value outside select options")
+	}
+
+	url := SettingDefinition{Key: "success", Type: SettingTypeURL}
+	if _, err := url.Normalize("javascript:alert(1)"); err == nil {
+		t.Fatalf("expected error for non-http URL")
+	}
+	if value, err := url.Normalize(" /done "); err != nil || value != "/done" {
+		t.Fatalf("expected trimmed path, got %v (%v)", value, err)
+	}
+
+	required := SettingDefinition{Key: "name", Type: SettingTypeText, Required: true}
+	if _, err := required.Normalize(""); err == nil {
+		t.Fatalf("expected error for empty required value")
+	}
+}
+
+func TestValidateSettingDefinitions(t *testing.T) {
+	if err := ValidateSettingDefinitions([]SettingDefinition{{Key: "a", Type: SettingTypeText}, {Key: "a", Type: SettingTypeText}}); err == nil {
+		t.Fatalf("expected error for duplicate keys")
+	}
+	if err := ValidateSettingDefinitions([]SettingDefinition{{Key: "a", Type: "color"}}); err == nil {
+		t.Fatalf("expected error for unsupported type")
+	}
+	if err := ValidateSettingDefinitions([]SettingDefinition{{Key: "a", Type: SettingTypeSelect}}); err == nil {
+		t.Fatalf("expected error for select without options")
+	}
+}
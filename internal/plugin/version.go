@@ -0,0 +1,116 @@
+package plugin
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// CompareVersions compares two dotted semantic versions and returns -1, 0 or 1.
+// Missing components are treated as zero and pre-release suffixes are ignored.
+func CompareVersions(a, b string) (int, error) {
+	left, err := parseVersion(a)
+	if err != nil {
+		return 0, err
+	}
+	right, err := parseVersion(b)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := 0; i < 3; i++ {
+		switch {
+		case left[i] < right[i]:
+			return -1, nil
+		case left[i] > right[i]:
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+// SatisfiesConstraint reports whether version matches a constraint such as
+// ">=1.2.0", "^1.4", "~2.1.0" or a comma separated combination (">=1.0, <2.0").
+// An empty constraint matches every version.
+func SatisfiesConstraint(version, constraint string) (bool, error) {
+	for _, part := range strings.Split(constraint, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" || part == "*" {
+			continue
+		}
+
+		ok, err := satisfiesSingle(version, part)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func satisfiesSingle(version, constraint string) (bool, error) {
+	for _, op := range []string{">=", "<=", ">", "<", "=", "^", "~"} {
+		if !strings.HasPrefix(constraint, op) {
+			continue
+		}
+		target := strings.TrimSpace(strings.TrimPrefix(constraint, op))
+		cmp, err := CompareVersions(version, target)
+		if err != nil {
+			return false, err
+		}
+
+		switch op {
+		case ">=":
+			return cmp >= 0, nil
+		case "<=":
+			return cmp <= 0, nil
+		case ">":
+			return cmp > 0, nil
+		case "<":
+			return cmp < 0, nil
+		case "=":
+			return cmp == 0, nil
+		}
+
+		// ^ keeps the major version, ~ keeps the major and minor version.
+		current, _ := parseVersion(version)
+		base, _ := parseVersion(target)
+		if cmp < 0 || current[0] != base[0] {
+			return false, nil
+		}
+		if op == "~" && current[1] != base[1] {
+			return false, nil
+		}
+		return true, nil
+	}
+
+	cmp, err := CompareVersions(version, constraint)
+	if err != nil {
+		return false, err
+	}
+	return cmp == 0, nil
+}
+
+func parseVersion(value string) ([3]int, error) {
+	var result [3]int
+
+	cleaned := strings.TrimPrefix(strings.TrimSpace(value), "v")
+	if idx := strings.IndexAny(cleaned, "-+"); idx >= 0 {
+		cleaned = cleaned[:idx]
+	}
+	if cleaned == "" {
+		return result, fmt.Errorf("invalid version %q", value)
+	}
+
+	parts := strings.Split(cleaned, ".")
+	if len(parts) > 3 {
+		return result, fmt.Errorf("invalid version %q", value)
+	}
+	for i, part := range parts {
+		number, err := strconv.Atoi(part)
+		if err != nil || number < 0 {
+			return result, fmt.Errorf("invalid version %q", value)
+		}
+		result[i] = number
+	}
+	return result, nil
+}
@@ -0,0 +1,35 @@
+package plugin
+
+import "testing"
+
+func TestSatisfiesConstraint(t *testing.T) {
+	cases := []struct {
+		version    string
+		constraint string
+		want       bool
+	}{
+		{"1.2.0", "", true},
+		{"1.2.0", ">=1.0", true},
+		{"1.2.0", ">=1.0, <1.2", false},
+		{"1.4.3", "^1.2", true},
+		{"2.0.0", "^1.2", false},
+		{"1.2.9", "~1.2.0", true},
+		{"1.3.0", "~1.2.0", false},
+		{"v1.0.0-beta", "=1.0.0", true},
+		{"1.0.0", "1.0", true},
+	}
+
+	for _, tc := range cases {
+		got, err := SatisfiesConstraint(tc.version, tc.constraint)
+		if err != nil {
+			t.Fatalf("%s %s: unexpected error: %v", tc.version, tc.constraint, err)
+		}
+		if got != tc.want {
+			t.Fatalf("%s %s: expected %v, got %v", tc.version, tc.constraint, tc.want, got)
+		}
+	}
+
+	if _, err := SatisfiesConstraint("1.x", ">=1.0"); err == nil {
+		t.Fatalf("expected error for invalid version")
+	}
+}
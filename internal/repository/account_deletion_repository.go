@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"constructor-script-backend/internal/authorization"
+	"constructor-script-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DeletedUserName is the placeholder account anonymized content and the posts
+// of deleted accounts are moved to. It cannot sign in.
+const DeletedUserName = "deleted-user"
+
+type AccountDeletionRepository interface {
+	Create(request *models.AccountDeletionRequest) error
+	Update(request *models.AccountDeletionRequest) error
+	Get(id uint) (*models.AccountDeletionRequest, error)
+	// PendingForUser returns the user's open request, or gorm.ErrRecordNotFound.
+	PendingForUser(userID uint) (*models.AccountDeletionRequest, error)
+	List(status string, limit int) ([]models.AccountDeletionRequest, error)
+	// EraseUser deletes the account and the rows that belong to it in one
+	// transaction. Comments and forum posts are moved to the placeholder account
+	// or deleted, by contentMode; other authored rows are always moved.
+	EraseUser(ctx context.Context, userID uint, contentMode string) error
+}
+
+type accountDeletionRepository struct {
+	db *gorm.DB
+}
+
+func NewAccountDeletionRepository(db *gorm.DB) AccountDeletionRepository {
+	return &accountDeletionRepository{db: db}
+}
+
+func (r *accountDeletionRepository) Create(request *models.AccountDeletionRequest) error {
+	return r.db.Create(request).Error
+}
+
+func (r *accountDeletionRepository) Update(request *models.AccountDeletionRequest) error {
+	return r.db.Save(request).Error
+}
+
+func (r *accountDeletionRepository) Get(id uint) (*models.AccountDeletionRequest, error) {
+	var request models.AccountDeletionRequest
+	if err := r.db.First(&request, id).Error; err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+func (r *accountDeletionRepository) PendingForUser(userID uint) (*models.AccountDeletionRequest, error) {
+	var request models.AccountDeletionRequest
+	err := r.db.Where("user_id = ? AND status = ?", userID, models.AccountDeletionPending).
+		Order("id DESC").First(&request).Error
+	if err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+func (r *accountDeletionRepository) List(status string, limit int) ([]models.AccountDeletionRequest, error) {
+	var requests []models.AccountDeletionRequest
+	query := r.db.Order("created_at DESC, id DESC")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	err := query.Find(&requests).Error
+	return requests, err
+}
+
+func (r *accountDeletionRepository) EraseUser(ctx context.Context, userID uint, contentMode string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.First(&user, userID).Error; err != nil {
+			return err
+		}
+		if user.Username == DeletedUserName {
+			return errors.New("the placeholder account cannot be deleted")
+		}
+
+		tables, err := userDataTables(tx)
+		if err != nil {
+			return err
+		}
+		content, err := communityContentTables(tx)
+		if err != nil {
+			return err
+		}
+
+		placeholderID := uint(0)
+		for _, table := range tables {
+			if !table.Erase {
+				continue
+			}
+			target := clause.Table{Name: table.Name}
+			column := clause.Column{Name: table.Column}
+
+			if table.Column == userDataColumnUser || (content[table.Name] && contentMode == models.AccountContentDelete) {
+				if err := tx.Exec("DELETE FROM ? WHERE ? = ?", target, column, userID).Error; err != nil {
+					return fmt.Errorf("failed to delete from %s: %w", table.Name, err)
+				}
+				continue
+			}
+
+			if placeholderID == 0 {
+				if placeholderID, err = placeholderAccount(tx); err != nil {
+					return err
+				}
+			}
+			if err := tx.Exec("UPDATE ? SET ? = ? WHERE ? = ?", target, column, placeholderID, column, userID).Error; err != nil {
+				return fmt.Errorf("failed to anonymize %s: %w", table.Name, err)
+			}
+		}
+
+		return tx.Unscoped().Delete(&user).Error
+	})
+}
+
+// placeholderAccount returns the account content of deleted users is moved to,
+// creating it on first use.
+func placeholderAccount(tx *gorm.DB) (uint, error) {
+	var user models.User
+	err := tx.Unscoped().Where("username = ?", DeletedUserName).First(&user).Error
+	if err == nil {
+		return user.ID, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, err
+	}
+
+	// The password is no bcrypt hash, so nobody can sign in as this account.
+	user = models.User{
+		Username: DeletedUserName,
+		Email:    DeletedUserName + "@invalid",
+		Password: "!",
+		Role:     authorization.RoleUser,
+		Status:   "deleted",
+	}
+	if err := tx.Create(&user).Error; err != nil {
+		return 0, fmt.Errorf("failed to create the placeholder account: %w", err)
+	}
+	return user.ID, nil
+}
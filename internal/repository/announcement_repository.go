@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"time"
+
+	"constructor-script-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type AnnouncementRepository interface {
+	Create(announcement *models.Announcement) error
+	Update(announcement *models.Announcement) error
+	Delete(id uint) error
+	GetByID(id uint) (*models.Announcement, error)
+	List() ([]models.Announcement, error)
+	// ListLive returns the enabled announcements whose schedule includes at,
+	// oldest first.
+	ListLive(at time.Time) ([]models.Announcement, error)
+	Dismiss(userID, announcementID uint) error
+	DismissedIDs(userID uint) ([]uint, error)
+}
+
+type announcementRepository struct {
+	db *gorm.DB
+}
+
+func NewAnnouncementRepository(db *gorm.DB) AnnouncementRepository {
+	return &announcementRepository{db: db}
+}
+
+func (r *announcementRepository) Create(announcement *models.Announcement) error {
+	return r.db.Create(announcement).Error
+}
+
+func (r *announcementRepository) Update(announcement *models.Announcement) error {
+	return r.db.Save(announcement).Error
+}
+
+func (r *announcementRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("announcement_id = ?", id).Delete(&models.AnnouncementDismissal{}).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&models.Announcement{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+func (r *announcementRepository) GetByID(id uint) (*models.Announcement, error) {
+	var announcement models.Announcement
+	err := r.db.First(&announcement, id).Error
+	return &announcement, err
+}
+
+func (r *announcementRepository) List() ([]models.Announcement, error) {
+	var announcements []models.Announcement
+	err := r.db.Order("created_at DESC").Find(&announcements).Error
+	return announcements, err
+}
+
+func (r *announcementRepository) ListLive(at time.Time) ([]models.Announcement, error) {
+	var announcements []models.Announcement
+	err := r.db.
+		Where("enabled = ?", true).
+		Where("starts_at IS NULL OR starts_at <= ?", at).
+		Where("ends_at IS NULL OR ends_at > ?", at).
+		Order("created_at ASC").
+		Find(&announcements).Error
+	return announcements, err
+}
+
+func (r *announcementRepository) Dismiss(userID, announcementID uint) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.AnnouncementDismissal{
+		UserID:         userID,
+		AnnouncementID: announcementID,
+	}).Error
+}
+
+func (r *announcementRepository) DismissedIDs(userID uint) ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&models.AnnouncementDismissal{}).
+		Where("user_id = ?", userID).
+		Pluck("announcement_id", &ids).Error
+	return ids, err
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"time"
+
+	"constructor-script-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type APIUsageRepository interface {
+	Add(subject string, day time.Time, requests, rejected int64) error
+	List(subject string, since time.Time) ([]models.APIUsage, error)
+	PruneBefore(day time.Time) (int64, error)
+}
+
+type apiUsageRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIUsageRepository(db *gorm.DB) APIUsageRepository {
+	return &apiUsageRepository{db: db}
+}
+
+// Add adds buffered counts to the usage of subject on the UTC day of day.
+func (r *apiUsageRepository) Add(subject string, day time.Time, requests, rejected int64) error {
+	day = day.UTC()
+	usage := &models.APIUsage{
+		Subject:  subject,
+		Day:      time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC),
+		Requests: requests,
+		Rejected: rejected,
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "subject"}, {Name: "day"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"requests":   gorm.Expr("api_usages.requests + ?", requests),
+			"rejected":   gorm.Expr("api_usages.rejected + ?", rejected),
+			"updated_at": time.Now(),
+		}),
+	}).Create(usage).Error
+}
+
+// List returns the usage from since onwards, newest day first. An empty subject
+// lists every subject.
+func (r *apiUsageRepository) List(subject string, since time.Time) ([]models.APIUsage, error) {
+	query := r.db.Where("day >= ?", since.UTC().Format("2006-01-02"))
+	if subject != "" {
+		query = query.Where("subject = ?", subject)
+	}
+
+	var usage []models.APIUsage
+	err := query.Order("day DESC").Order("requests DESC").Limit(1000).Find(&usage).Error
+	return usage, err
+}
+
+// PruneBefore deletes the usage of the days before day.
+func (r *apiUsageRepository) PruneBefore(day time.Time) (int64, error) {
+	result := r.db.Where("day < ?", day.UTC().Format("2006-01-02")).Delete(&models.APIUsage{})
+	return result.RowsAffected, result.Error
+}
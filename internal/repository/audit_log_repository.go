@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"time"
+
+	"constructor-script-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type AuditLogRepository interface {
+	Create(entry *models.AuditLog) error
+	GetByID(id uint) (*models.AuditLog, error)
+	List(filter models.AuditLogFilter) ([]models.AuditLog, int64, error)
+	DeleteBefore(cutoff time.Time, batchSize int) (int64, error)
+}
+
+type auditLogRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditLogRepository(db *gorm.DB) AuditLogRepository {
+	return &auditLogRepository{db: db}
+}
+
+func (r *auditLogRepository) Create(entry *models.AuditLog) error {
+	return r.db.Create(entry).Error
+}
+
+func (r *auditLogRepository) GetByID(id uint) (*models.AuditLog, error) {
+	var entry models.AuditLog
+	err := r.db.First(&entry, id).Error
+	return &entry, err
+}
+
+func (r *auditLogRepository) List(filter models.AuditLogFilter) ([]models.AuditLog, int64, error) {
+	query := r.db.Model(&models.AuditLog{})
+	if filter.UserID > 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.Resource != "" {
+		query = query.Where("resource = ?", filter.Resource)
+	}
+	if filter.Method != "" {
+		query = query.Where("method = ?", filter.Method)
+	}
+	if filter.Status > 0 {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var entries []models.AuditLog
+	offset := (filter.Page - 1) * filter.Limit
+	err := query.Order("created_at DESC, id DESC").Offset(offset).Limit(filter.Limit).Find(&entries).Error
+	return entries, total, err
+}
+
+// DeleteBefore removes up to batchSize entries older than cutoff, oldest first, so
+// pruning a long backlog does not hold one long transaction.
+func (r *auditLogRepository) DeleteBefore(cutoff time.Time, batchSize int) (int64, error) {
+	ids := r.db.Model(&models.AuditLog{}).
+		Select("id").
+		Where("created_at < ?", cutoff).
+		Order("id ASC").
+		Limit(batchSize)
+	result := r.db.Where("id IN (?)", ids).Delete(&models.AuditLog{})
+	return result.RowsAffected, result.Error
+}
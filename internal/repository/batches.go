@@ -0,0 +1,5 @@
+package repository
+
+// exportBatchSize is how many rows the Batches methods load at a time. They page
+// by primary key, so rows added during an export do not shift later batches.
+const exportBatchSize = 500
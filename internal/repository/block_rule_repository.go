@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"time"
+
+	"constructor-script-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type BlockRuleRepository interface {
+	List() ([]models.BlockRule, error)
+	// Active returns the rules that have not expired at now.
+	Active(now time.Time) ([]models.BlockRule, error)
+	Create(rule *models.BlockRule) error
+	Delete(id uint) error
+	// AddHits adds the matches counted since the last call to each rule.
+	AddHits(hits map[uint]int64, at time.Time) error
+	// DeleteExpired removes the temporary bans that ended before now.
+	DeleteExpired(now time.Time) (int64, error)
+}
+
+type blockRuleRepository struct {
+	db *gorm.DB
+}
+
+func NewBlockRuleRepository(db *gorm.DB) BlockRuleRepository {
+	return &blockRuleRepository{db: db}
+}
+
+func (r *blockRuleRepository) List() ([]models.BlockRule, error) {
+	var rules []models.BlockRule
+	err := r.db.Order("created_at DESC, id DESC").Find(&rules).Error
+	return rules, err
+}
+
+func (r *blockRuleRepository) Active(now time.Time) ([]models.BlockRule, error) {
+	var rules []models.BlockRule
+	err := r.db.Where("expires_at IS NULL OR expires_at > ?", now).Order("id").Find(&rules).Error
+	return rules, err
+}
+
+func (r *blockRuleRepository) Create(rule *models.BlockRule) error {
+	return r.db.Create(rule).Error
+}
+
+func (r *blockRuleRepository) Delete(id uint) error {
+	result := r.db.Delete(&models.BlockRule{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *blockRuleRepository) AddHits(hits map[uint]int64, at time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for id, count := range hits {
+			err := tx.Model(&models.BlockRule{}).Where("id = ?", id).UpdateColumns(map[string]interface{}{
+				"hits":        gorm.Expr("hits + ?", count),
+				"last_hit_at": at,
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *blockRuleRepository) DeleteExpired(now time.Time) (int64, error) {
+	result := r.db.Where("expires_at IS NOT NULL AND expires_at <= ?", now).Delete(&models.BlockRule{})
+	return result.RowsAffected, result.Error
+}
@@ -13,6 +13,9 @@ type CommentRepository interface {
 	GetByID(id uint) (*models.Comment, error)
 	GetByPostID(postID uint) ([]models.Comment, error)
 	GetAll() ([]models.Comment, error)
+	// Batches hands every comment, with its author and post, to fn in batches
+	// ordered by ID.
+	Batches(fn func([]models.Comment) error) error
 	Update(comment *models.Comment) error
 	Delete(id uint) error
 	GetPending() ([]models.Comment, error)
@@ -62,6 +65,32 @@ func (r *commentRepository) GetAll() ([]models.Comment, error) {
 	return comments, err
 }
 
+func (r *commentRepository) Batches(fn func([]models.Comment) error) error {
+	var lastID uint
+	for {
+		var comments []models.Comment
+		err := r.db.Preload("Author").
+			Preload("Post", func(db *gorm.DB) *gorm.DB { return db.Select("id", "title", "slug") }).
+			Where("comments.id > ?", lastID).
+			Order("comments.id").
+			Limit(exportBatchSize).
+			Find(&comments).Error
+		if err != nil {
+			return err
+		}
+		if len(comments) == 0 {
+			return nil
+		}
+		if err := fn(comments); err != nil {
+			return err
+		}
+		if len(comments) < exportBatchSize {
+			return nil
+		}
+		lastID = comments[len(comments)-1].ID
+	}
+}
+
 func (r *commentRepository) Update(comment *models.Comment) error {
 	return r.db.Save(comment).Error
 }
@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"time"
+
+	"constructor-script-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type ConsentRepository interface {
+	Create(record *models.ConsentRecord) error
+	// ListByVisitor returns the latest choices of a visitor, newest first.
+	ListByVisitor(visitorID string, limit int) ([]models.ConsentRecord, error)
+	DeleteBefore(cutoff time.Time) (int64, error)
+}
+
+type consentRepository struct {
+	db *gorm.DB
+}
+
+func NewConsentRepository(db *gorm.DB) ConsentRepository {
+	return &consentRepository{db: db}
+}
+
+func (r *consentRepository) Create(record *models.ConsentRecord) error {
+	return r.db.Create(record).Error
+}
+
+func (r *consentRepository) ListByVisitor(visitorID string, limit int) ([]models.ConsentRecord, error) {
+	var records []models.ConsentRecord
+	err := r.db.Where("visitor_id = ?", visitorID).Order("created_at DESC, id DESC").Limit(limit).Find(&records).Error
+	return records, err
+}
+
+func (r *consentRepository) DeleteBefore(cutoff time.Time) (int64, error) {
+	result := r.db.Where("created_at < ?", cutoff).Delete(&models.ConsentRecord{})
+	return result.RowsAffected, result.Error
+}
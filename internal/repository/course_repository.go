@@ -19,6 +19,9 @@ type CourseVideoRepository interface {
 	List() ([]models.CourseVideo, error)
 	Exists(id uint) (bool, error)
 	GetByIDs(ids []uint) ([]models.CourseVideo, error)
+	// ApplyMediaDetails sets the poster of every video using filename and fills in
+	// durations that are still unknown.
+	ApplyMediaDetails(filename, posterURL string, durationSeconds int) error
 }
 
 type CourseContentRepository interface {
@@ -61,6 +64,20 @@ type CoursePackageAccessRepository interface {
 	Upsert(access *models.CoursePackageAccess) error
 	GetByUserAndPackage(userID, packageID uint) (*models.CoursePackageAccess, error)
 	ListActiveByUser(userID uint) ([]models.CoursePackageAccess, error)
+	ListExpiringBetween(from, to time.Time) ([]models.CoursePackageAccess, error)
+	// BatchesByPackage hands the grants of a package to fn in batches ordered by
+	// ID.
+	BatchesByPackage(packageID uint, fn func([]models.CoursePackageGrant) error) error
+}
+
+type CourseAnnouncementRepository interface {
+	Create(announcement *models.CourseAnnouncement) error
+	Delete(id uint) error
+	GetByID(id uint) (*models.CourseAnnouncement, error)
+	ListByPackage(packageID uint) ([]models.CourseAnnouncement, error)
+	// ListForUser returns the announcements posted after since and no later than
+	// until to packages the user has access to, newest first.
+	ListForUser(userID uint, since, until time.Time, limit int) ([]models.CourseAnnouncement, error)
 }
 
 type CourseTestRepository interface {
@@ -75,6 +92,8 @@ type CourseTestRepository interface {
 	ListStructure(testIDs []uint) (map[uint][]models.CourseTestQuestion, error)
 	SaveResult(result *models.CourseTestResult) error
 	GetBestResult(testID, userID uint) (*models.CourseTestResult, int64, error)
+	// ResultBatches hands every result of a test to fn in batches ordered by ID.
+	ResultBatches(testID uint, fn func([]models.CourseTestResultEntry) error) error
 }
 
 type courseVideoRepository struct {
@@ -101,6 +120,10 @@ type courseTestRepository struct {
 	db *gorm.DB
 }
 
+type courseAnnouncementRepository struct {
+	db *gorm.DB
+}
+
 func NewCourseVideoRepository(db *gorm.DB) CourseVideoRepository {
 	return &courseVideoRepository{db: db}
 }
@@ -121,6 +144,10 @@ func NewCoursePackageAccessRepository(db *gorm.DB) CoursePackageAccessRepository
 	return &coursePackageAccessRepository{db: db}
 }
 
+func NewCourseAnnouncementRepository(db *gorm.DB) CourseAnnouncementRepository {
+	return &courseAnnouncementRepository{db: db}
+}
+
 func NewCourseTestRepository(db *gorm.DB) CourseTestRepository {
 	return &courseTestRepository{db: db}
 }
@@ -247,6 +274,23 @@ func (r *courseVideoRepository) Exists(id uint) (bool, error) {
 	return count > 0, nil
 }
 
+func (r *courseVideoRepository) ApplyMediaDetails(filename, posterURL string, durationSeconds int) error {
+	if r == nil || r.db == nil {
+		return errors.New("course video repository is not initialised")
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if posterURL != "" {
+			if err := tx.Model(&models.CourseVideo{}).Where("filename = ?", filename).Update("poster_url", posterURL).Error; err != nil {
+				return err
+			}
+		}
+		if durationSeconds > 0 {
+			return tx.Model(&models.CourseVideo{}).Where("filename = ? AND duration_seconds = 0", filename).Update("duration_seconds", durationSeconds).Error
+		}
+		return nil
+	})
+}
+
 func (r *courseContentRepository) Exists(id uint) (bool, error) {
 	if r == nil || r.db == nil {
 		return false, errors.New("course content repository is not initialised")
@@ -608,6 +652,51 @@ func (r *coursePackageAccessRepository) ListActiveByUser(userID uint) ([]models.
 	return accesses, err
 }
 
+func (r *coursePackageAccessRepository) ListExpiringBetween(from, to time.Time) ([]models.CoursePackageAccess, error) {
+	accesses := make([]models.CoursePackageAccess, 0)
+	if r == nil || r.db == nil {
+		return accesses, errors.New("course package access repository is not initialised")
+	}
+
+	err := r.db.Where("expires_at >= ? AND expires_at < ?", from, to).
+		Order("expires_at ASC").
+		Find(&accesses).Error
+
+	return accesses, err
+}
+
+func (r *coursePackageAccessRepository) BatchesByPackage(packageID uint, fn func([]models.CoursePackageGrant) error) error {
+	if r == nil || r.db == nil {
+		return errors.New("course package access repository is not initialised")
+	}
+
+	var lastID uint
+	for {
+		var grants []models.CoursePackageGrant
+		err := r.db.Table("course_package_accesses").
+			Select("course_package_accesses.*, users.username, users.email").
+			Joins("LEFT JOIN users ON users.id = course_package_accesses.user_id").
+			Where("course_package_accesses.package_id = ? AND course_package_accesses.deleted_at IS NULL", packageID).
+			Where("course_package_accesses.id > ?", lastID).
+			Order("course_package_accesses.id").
+			Limit(exportBatchSize).
+			Scan(&grants).Error
+		if err != nil {
+			return err
+		}
+		if len(grants) == 0 {
+			return nil
+		}
+		if err := fn(grants); err != nil {
+			return err
+		}
+		if len(grants) < exportBatchSize {
+			return nil
+		}
+		lastID = grants[len(grants)-1].ID
+	}
+}
+
 func uniqueOrdered(values []uint) []uint {
 	if len(values) == 0 {
 		return []uint{}
@@ -792,6 +881,38 @@ func (r *courseTestRepository) SaveResult(result *models.CourseTestResult) error
 	return r.db.Create(result).Error
 }
 
+func (r *courseTestRepository) ResultBatches(testID uint, fn func([]models.CourseTestResultEntry) error) error {
+	if r == nil || r.db == nil {
+		return errors.New("course test repository is not initialised")
+	}
+
+	var lastID uint
+	for {
+		var results []models.CourseTestResultEntry
+		err := r.db.Table("course_test_results").
+			Select("course_test_results.*, users.username, users.email").
+			Joins("LEFT JOIN users ON users.id = course_test_results.user_id").
+			Where("course_test_results.test_id = ? AND course_test_results.deleted_at IS NULL", testID).
+			Where("course_test_results.id > ?", lastID).
+			Order("course_test_results.id").
+			Limit(exportBatchSize).
+			Scan(&results).Error
+		if err != nil {
+			return err
+		}
+		if len(results) == 0 {
+			return nil
+		}
+		if err := fn(results); err != nil {
+			return err
+		}
+		if len(results) < exportBatchSize {
+			return nil
+		}
+		lastID = results[len(results)-1].ID
+	}
+}
+
 func (r *courseTestRepository) GetBestResult(testID, userID uint) (*models.CourseTestResult, int64, error) {
 	if r == nil || r.db == nil {
 		return nil, 0, errors.New("course test repository is not initialised")
@@ -826,3 +947,63 @@ func (r *courseTestRepository) GetBestResult(testID, userID uint) (*models.Cours
 
 	return &record, attempts, nil
 }
+
+func (r *courseAnnouncementRepository) Create(announcement *models.CourseAnnouncement) error {
+	if r == nil || r.db == nil {
+		return errors.New("course announcement repository is not initialised")
+	}
+	return r.db.Create(announcement).Error
+}
+
+func (r *courseAnnouncementRepository) Delete(id uint) error {
+	if r == nil || r.db == nil {
+		return errors.New("course announcement repository is not initialised")
+	}
+	result := r.db.Delete(&models.CourseAnnouncement{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *courseAnnouncementRepository) GetByID(id uint) (*models.CourseAnnouncement, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("course announcement repository is not initialised")
+	}
+	var announcement models.CourseAnnouncement
+	if err := r.db.First(&announcement, id).Error; err != nil {
+		return nil, err
+	}
+	return &announcement, nil
+}
+
+func (r *courseAnnouncementRepository) ListByPackage(packageID uint) ([]models.CourseAnnouncement, error) {
+	announcements := make([]models.CourseAnnouncement, 0)
+	if r == nil || r.db == nil {
+		return announcements, errors.New("course announcement repository is not initialised")
+	}
+	err := r.db.Where("package_id = ?", packageID).
+		Order("created_at DESC").
+		Find(&announcements).Error
+	return announcements, err
+}
+
+func (r *courseAnnouncementRepository) ListForUser(userID uint, since, until time.Time, limit int) ([]models.CourseAnnouncement, error) {
+	announcements := make([]models.CourseAnnouncement, 0)
+	if r == nil || r.db == nil {
+		return announcements, errors.New("course announcement repository is not initialised")
+	}
+
+	packages := r.db.Model(&models.CoursePackageAccess{}).
+		Select("package_id").
+		Where("user_id = ? AND (expires_at IS NULL OR expires_at > ?)", userID, until)
+	err := r.db.Where("package_id IN (?)", packages).
+		Where("created_at > ? AND created_at <= ?", since, until).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&announcements).Error
+	return announcements, err
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"constructor-script-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type DataExportRepository interface {
+	Create(export *models.DataExport) error
+	Update(export *models.DataExport) error
+	// GetForUser returns an export of the user with its archive.
+	GetForUser(id, userID uint) (*models.DataExport, error)
+	// ListByUser returns the user's exports, newest first, without archives.
+	ListByUser(userID uint, limit int) ([]models.DataExport, error)
+	DeleteExpired(now time.Time) (int64, error)
+	// CollectUserData returns the rows of the user in every table holding them,
+	// as JSON arrays keyed by table name, and the user's account.
+	CollectUserData(ctx context.Context, userID uint) (*models.User, map[string]json.RawMessage, error)
+}
+
+type dataExportRepository struct {
+	db *gorm.DB
+}
+
+func NewDataExportRepository(db *gorm.DB) DataExportRepository {
+	return &dataExportRepository{db: db}
+}
+
+func (r *dataExportRepository) Create(export *models.DataExport) error {
+	return r.db.Create(export).Error
+}
+
+func (r *dataExportRepository) Update(export *models.DataExport) error {
+	return r.db.Save(export).Error
+}
+
+func (r *dataExportRepository) GetForUser(id, userID uint) (*models.DataExport, error) {
+	var export models.DataExport
+	if err := r.db.Where("id = ? AND user_id = ?", id, userID).First(&export).Error; err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+func (r *dataExportRepository) ListByUser(userID uint, limit int) ([]models.DataExport, error) {
+	var exports []models.DataExport
+	query := r.db.Omit("archive").Where("user_id = ?", userID).Order("created_at DESC, id DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	err := query.Find(&exports).Error
+	return exports, err
+}
+
+func (r *dataExportRepository) DeleteExpired(now time.Time) (int64, error) {
+	result := r.db.Where("expires_at IS NOT NULL AND expires_at <= ?", now).Delete(&models.DataExport{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *dataExportRepository) CollectUserData(ctx context.Context, userID uint) (*models.User, map[string]json.RawMessage, error) {
+	db := r.db.WithContext(ctx)
+
+	var user models.User
+	if err := db.First(&user, userID).Error; err != nil {
+		return nil, nil, err
+	}
+
+	tables, err := userDataTables(db)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data := make(map[string]json.RawMessage, len(tables))
+	for _, table := range tables {
+		if !table.Export {
+			continue
+		}
+
+		condition, value := fmt.Sprintf("t.%s = ?", db.Statement.Quote(table.Column)), interface{}(userID)
+		if table.Column == userDataColumnEmail {
+			condition, value = fmt.Sprintf("LOWER(t.%s) = LOWER(?)", db.Statement.Quote(table.Column)), user.Email
+		}
+		var rows string
+		query := fmt.Sprintf("SELECT COALESCE(json_agg(t ORDER BY t.id), '[]'::json) FROM %s t WHERE %s",
+			db.Statement.Quote(table.Name), condition)
+		if err := db.Raw(query, value).Scan(&rows).Error; err != nil {
+			return nil, nil, fmt.Errorf("failed to load %s: %w", table.Name, err)
+		}
+		data[table.Name] = json.RawMessage(rows)
+	}
+	return &user, data, nil
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"time"
+
+	"constructor-script-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type DeliveryTokenRepository interface {
+	List() ([]models.DeliveryToken, error)
+	GetByID(id uint) (*models.DeliveryToken, error)
+	GetByHash(hash string) (*models.DeliveryToken, error)
+	Create(token *models.DeliveryToken) error
+	Update(token *models.DeliveryToken) error
+	Delete(id uint) error
+	TouchLastUsed(id uint, at time.Time) error
+}
+
+type deliveryTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewDeliveryTokenRepository(db *gorm.DB) DeliveryTokenRepository {
+	return &deliveryTokenRepository{db: db}
+}
+
+func (r *deliveryTokenRepository) List() ([]models.DeliveryToken, error) {
+	var tokens []models.DeliveryToken
+	err := r.db.Order("id ASC").Find(&tokens).Error
+	return tokens, err
+}
+
+func (r *deliveryTokenRepository) GetByID(id uint) (*models.DeliveryToken, error) {
+	var token models.DeliveryToken
+	err := r.db.First(&token, id).Error
+	return &token, err
+}
+
+func (r *deliveryTokenRepository) GetByHash(hash string) (*models.DeliveryToken, error) {
+	var token models.DeliveryToken
+	err := r.db.Where("token_hash = ?", hash).First(&token).Error
+	return &token, err
+}
+
+func (r *deliveryTokenRepository) Create(token *models.DeliveryToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *deliveryTokenRepository) Update(token *models.DeliveryToken) error {
+	return r.db.Save(token).Error
+}
+
+func (r *deliveryTokenRepository) Delete(id uint) error {
+	return r.db.Delete(&models.DeliveryToken{}, id).Error
+}
+
+// TouchLastUsed records when the token was last used without bumping updated_at.
+func (r *deliveryTokenRepository) TouchLastUsed(id uint, at time.Time) error {
+	return r.db.Model(&models.DeliveryToken{}).Where("id = ?", id).UpdateColumn("last_used_at", at).Error
+}
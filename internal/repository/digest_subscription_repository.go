@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"time"
+
+	"constructor-script-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type DigestSubscriptionRepository interface {
+	GetByUserID(userID uint) (*models.DigestSubscription, error)
+	GetByToken(token string) (*models.DigestSubscription, error)
+	Save(subscription *models.DigestSubscription) error
+	// ListDue returns the subscriptions with frequency that were never sent or
+	// last sent at or before sentBefore.
+	ListDue(frequency string, sentBefore time.Time) ([]models.DigestSubscription, error)
+	// MarkSent records when a digest went out without touching the frequency,
+	// which the user may have changed in the meantime.
+	MarkSent(id uint, at time.Time) error
+}
+
+type digestSubscriptionRepository struct {
+	db *gorm.DB
+}
+
+func NewDigestSubscriptionRepository(db *gorm.DB) DigestSubscriptionRepository {
+	return &digestSubscriptionRepository{db: db}
+}
+
+func (r *digestSubscriptionRepository) GetByUserID(userID uint) (*models.DigestSubscription, error) {
+	var subscription models.DigestSubscription
+	err := r.db.Where("user_id = ?", userID).First(&subscription).Error
+	return &subscription, err
+}
+
+func (r *digestSubscriptionRepository) GetByToken(token string) (*models.DigestSubscription, error) {
+	var subscription models.DigestSubscription
+	err := r.db.Where("unsubscribe_token = ?", token).First(&subscription).Error
+	return &subscription, err
+}
+
+func (r *digestSubscriptionRepository) Save(subscription *models.DigestSubscription) error {
+	return r.db.Save(subscription).Error
+}
+
+func (r *digestSubscriptionRepository) ListDue(frequency string, sentBefore time.Time) ([]models.DigestSubscription, error) {
+	var subscriptions []models.DigestSubscription
+	err := r.db.Where("frequency = ?", frequency).
+		Where("last_sent_at IS NULL OR last_sent_at <= ?", sentBefore).
+		Order("id ASC").
+		Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+func (r *digestSubscriptionRepository) MarkSent(id uint, at time.Time) error {
+	return r.db.Model(&models.DigestSubscription{}).Where("id = ?", id).Update("last_sent_at", at).Error
+}
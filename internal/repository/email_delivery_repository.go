@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"time"
+
+	"constructor-script-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// EmailDeliveryFilter narrows the email send log.
+type EmailDeliveryFilter struct {
+	Status    string
+	Recipient string
+	Template  string
+	Limit     int
+}
+
+type EmailDeliveryRepository interface {
+	// Create queues a delivery. It reports false, without an error, when a
+	// delivery with the same dedup key was queued before.
+	Create(delivery *models.EmailDelivery) (bool, error)
+	Update(delivery *models.EmailDelivery) error
+	GetByID(id uint) (*models.EmailDelivery, error)
+	List(filter EmailDeliveryFilter) ([]models.EmailDelivery, error)
+	ListPending() ([]models.EmailDelivery, error)
+	// LatestSent returns the newest delivery sent to recipient, or
+	// gorm.ErrRecordNotFound.
+	LatestSent(recipient string) (*models.EmailDelivery, error)
+	PruneBefore(cutoff time.Time) (int64, error)
+}
+
+type emailDeliveryRepository struct {
+	db *gorm.DB
+}
+
+func NewEmailDeliveryRepository(db *gorm.DB) EmailDeliveryRepository {
+	return &emailDeliveryRepository{db: db}
+}
+
+func (r *emailDeliveryRepository) Create(delivery *models.EmailDelivery) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "dedup_key"}},
+		DoNothing: true,
+	}).Create(delivery)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *emailDeliveryRepository) Update(delivery *models.EmailDelivery) error {
+	return r.db.Save(delivery).Error
+}
+
+func (r *emailDeliveryRepository) GetByID(id uint) (*models.EmailDelivery, error) {
+	var delivery models.EmailDelivery
+	err := r.db.First(&delivery, id).Error
+	return &delivery, err
+}
+
+func (r *emailDeliveryRepository) List(filter EmailDeliveryFilter) ([]models.EmailDelivery, error) {
+	var deliveries []models.EmailDelivery
+	query := r.db.Order("created_at DESC, id DESC")
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Recipient != "" {
+		query = query.Where("LOWER(recipient) = LOWER(?)", filter.Recipient)
+	}
+	if filter.Template != "" {
+		query = query.Where("template = ?", filter.Template)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	err := query.Find(&deliveries).Error
+	return deliveries, err
+}
+
+func (r *emailDeliveryRepository) ListPending() ([]models.EmailDelivery, error) {
+	var deliveries []models.EmailDelivery
+	err := r.db.Where("status = ?", models.EmailDeliveryPending).Order("id ASC").Find(&deliveries).Error
+	return deliveries, err
+}
+
+func (r *emailDeliveryRepository) LatestSent(recipient string) (*models.EmailDelivery, error) {
+	var delivery models.EmailDelivery
+	err := r.db.
+		Where("LOWER(recipient) = LOWER(?) AND status = ?", recipient, models.EmailDeliverySent).
+		Order("sent_at DESC, id DESC").
+		First(&delivery).Error
+	if err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// PruneBefore deletes sent and failed deliveries created before cutoff. Pending
+// ones are kept whatever their age.
+func (r *emailDeliveryRepository) PruneBefore(cutoff time.Time) (int64, error) {
+	result := r.db.
+		Where("status <> ? AND created_at < ?", models.EmailDeliveryPending, cutoff).
+		Delete(&models.EmailDelivery{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+
+	"constructor-script-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type EmailSuppressionRepository interface {
+	// Get returns the suppression of an address, or gorm.ErrRecordNotFound.
+	Get(email string) (*models.EmailSuppression, error)
+	// Suppressed reports whether the address is on the list.
+	Suppressed(email string) (bool, error)
+	// Save adds the address or updates why it is on the list.
+	Save(suppression *models.EmailSuppression) error
+	List(email string, limit int) ([]models.EmailSuppression, error)
+	Delete(id uint) error
+}
+
+type emailSuppressionRepository struct {
+	db *gorm.DB
+}
+
+func NewEmailSuppressionRepository(db *gorm.DB) EmailSuppressionRepository {
+	return &emailSuppressionRepository{db: db}
+}
+
+func (r *emailSuppressionRepository) Get(email string) (*models.EmailSuppression, error) {
+	var suppression models.EmailSuppression
+	err := r.db.Where("email = ?", strings.ToLower(strings.TrimSpace(email))).First(&suppression).Error
+	if err != nil {
+		return nil, err
+	}
+	return &suppression, nil
+}
+
+func (r *emailSuppressionRepository) Suppressed(email string) (bool, error) {
+	_, err := r.Get(email)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (r *emailSuppressionRepository) Save(suppression *models.EmailSuppression) error {
+	suppression.Email = strings.ToLower(strings.TrimSpace(suppression.Email))
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "email"}},
+		DoUpdates: clause.AssignmentColumns([]string{"reason", "detail", "updated_at"}),
+	}).Create(suppression).Error
+}
+
+func (r *emailSuppressionRepository) List(email string, limit int) ([]models.EmailSuppression, error) {
+	var suppressions []models.EmailSuppression
+	query := r.db.Order("updated_at DESC, id DESC")
+	if email = strings.TrimSpace(email); email != "" {
+		query = query.Where("email LIKE ?", "%"+strings.ToLower(email)+"%")
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	err := query.Find(&suppressions).Error
+	return suppressions, err
+}
+
+func (r *emailSuppressionRepository) Delete(id uint) error {
+	result := r.db.Delete(&models.EmailSuppression{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
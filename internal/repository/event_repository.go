@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"time"
+
+	"constructor-script-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type EventRepository interface {
+	Create(event *models.Event) error
+	Update(event *models.Event) error
+	Delete(id uint) error
+	GetByID(id uint) (*models.Event, error)
+	GetBySlug(slug string) (*models.Event, error)
+	ExistsBySlug(slug string, excludeID uint) (bool, error)
+	List() ([]models.Event, error)
+	ListPublished() ([]models.Event, error)
+	ListPublishedStartingBefore(before time.Time) ([]models.Event, error)
+}
+
+type eventRepository struct {
+	db *gorm.DB
+}
+
+func NewEventRepository(db *gorm.DB) EventRepository {
+	return &eventRepository{db: db}
+}
+
+func (r *eventRepository) Create(event *models.Event) error {
+	return r.db.Create(event).Error
+}
+
+func (r *eventRepository) Update(event *models.Event) error {
+	return r.db.Save(event).Error
+}
+
+func (r *eventRepository) Delete(id uint) error {
+	return r.db.Delete(&models.Event{}, id).Error
+}
+
+func (r *eventRepository) GetByID(id uint) (*models.Event, error) {
+	var event models.Event
+	err := r.db.First(&event, id).Error
+	return &event, err
+}
+
+func (r *eventRepository) GetBySlug(slug string) (*models.Event, error) {
+	var event models.Event
+	err := r.db.Where("slug = ?", slug).First(&event).Error
+	return &event, err
+}
+
+func (r *eventRepository) ExistsBySlug(slug string, excludeID uint) (bool, error) {
+	var count int64
+	query := r.db.Unscoped().Model(&models.Event{}).Where("slug = ?", slug)
+	if excludeID > 0 {
+		query = query.Where("id <> ?", excludeID)
+	}
+	err := query.Count(&count).Error
+	return count > 0, err
+}
+
+func (r *eventRepository) List() ([]models.Event, error) {
+	var events []models.Event
+	err := r.db.Order("starts_at DESC, id DESC").Find(&events).Error
+	return events, err
+}
+
+func (r *eventRepository) ListPublished() ([]models.Event, error) {
+	var events []models.Event
+	err := r.db.Where("published = ?", true).Order("starts_at ASC, id ASC").Find(&events).Error
+	return events, err
+}
+
+// ListPublishedStartingBefore returns published events whose first occurrence starts
+// before the given time. Recurring events are expanded by the caller.
+func (r *eventRepository) ListPublishedStartingBefore(before time.Time) ([]models.Event, error) {
+	var events []models.Event
+	err := r.db.Where("published = ? AND starts_at < ?", true, before).
+		Order("starts_at ASC, id ASC").
+		Find(&events).Error
+	return events, err
+}
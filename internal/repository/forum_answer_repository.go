@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"time"
+
 	"constructor-script-backend/internal/models"
 
 	"gorm.io/gorm"
@@ -12,6 +14,9 @@ type ForumAnswerRepository interface {
 	Delete(id uint) error
 	GetByID(id uint) (*models.ForumAnswer, error)
 	ListByQuestion(questionID uint) ([]models.ForumAnswer, error)
+	// ListRepliesForUser returns answers by others posted after since and no later
+	// than until to questions the user asked or answered, newest first.
+	ListRepliesForUser(userID uint, since, until time.Time, limit int) ([]models.ForumAnswer, error)
 }
 
 type forumAnswerRepository struct {
@@ -63,3 +68,22 @@ func (r *forumAnswerRepository) ListByQuestion(questionID uint) ([]models.ForumA
 		Find(&answers).Error
 	return answers, err
 }
+
+func (r *forumAnswerRepository) ListRepliesForUser(userID uint, since, until time.Time, limit int) ([]models.ForumAnswer, error) {
+	if r == nil || r.db == nil {
+		return nil, gorm.ErrInvalidDB
+	}
+	watched := r.db.Model(&models.ForumQuestion{}).Select("id").Where("author_id = ?", userID)
+	answered := r.db.Model(&models.ForumAnswer{}).Select("question_id").Where("author_id = ?", userID)
+
+	var answers []models.ForumAnswer
+	err := r.db.Where("author_id <> ?", userID).
+		Where("created_at > ? AND created_at <= ?", since, until).
+		Where("question_id IN (?) OR question_id IN (?)", watched, answered).
+		Preload("Question").
+		Preload("Author").
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&answers).Error
+	return answers, err
+}
@@ -15,8 +15,13 @@ type ForumQuestionRepository interface {
 	GetByID(id uint) (*models.ForumQuestion, error)
 	GetBySlug(slug string) (*models.ForumQuestion, error)
 	List(offset, limit int, search string, authorID *uint, categoryID *uint, status string) ([]models.ForumQuestion, int64, error)
+	// Batches hands the questions matching the List filters to fn in batches
+	// ordered by ID.
+	Batches(search string, authorID *uint, categoryID *uint, status string, fn func([]models.ForumQuestion) error) error
+	ListForSitemap() ([]models.ForumQuestion, error)
 	ExistsBySlug(slug string) (bool, error)
 	IncrementViews(id uint) error
+	AddViews(counts map[uint]int64) error
 }
 
 type forumQuestionRepository struct {
@@ -87,11 +92,78 @@ func (r *forumQuestionRepository) GetBySlug(slug string) (*models.ForumQuestion,
 	return &question, nil
 }
 
+// ListForSitemap loads only the columns needed to link every question.
+func (r *forumQuestionRepository) ListForSitemap() ([]models.ForumQuestion, error) {
+	if r == nil || r.db == nil {
+		return nil, gorm.ErrInvalidDB
+	}
+
+	var questions []models.ForumQuestion
+	err := r.db.Select("id", "slug", "updated_at", "created_at").
+		Order("updated_at DESC").
+		Find(&questions).Error
+	return questions, err
+}
+
 func (r *forumQuestionRepository) List(offset, limit int, search string, authorID *uint, categoryID *uint, status string) ([]models.ForumQuestion, int64, error) {
 	if r == nil || r.db == nil {
 		return nil, 0, gorm.ErrInvalidDB
 	}
 
+	query := r.filtered(search, authorID, categoryID, status)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if limit > 0 {
+		query = query.Offset(offset).Limit(limit)
+	}
+
+	var questions []models.ForumQuestion
+	err := query.
+		Preload("Author").
+		Preload("Category").
+		Order("rating DESC, created_at DESC").
+		Find(&questions).Error
+	return questions, total, err
+}
+
+func (r *forumQuestionRepository) Batches(search string, authorID *uint, categoryID *uint, status string, fn func([]models.ForumQuestion) error) error {
+	if r == nil || r.db == nil {
+		return gorm.ErrInvalidDB
+	}
+
+	var lastID uint
+	for {
+		var questions []models.ForumQuestion
+		err := r.filtered(search, authorID, categoryID, status).
+			Where("forum_questions.id > ?", lastID).
+			Preload("Author").
+			Preload("Category").
+			Order("forum_questions.id").
+			Limit(exportBatchSize).
+			Find(&questions).Error
+		if err != nil {
+			return err
+		}
+		if len(questions) == 0 {
+			return nil
+		}
+		if err := fn(questions); err != nil {
+			return err
+		}
+		if len(questions) < exportBatchSize {
+			return nil
+		}
+		lastID = questions[len(questions)-1].ID
+	}
+}
+
+// filtered selects the questions matching the list filters, with their answer
+// counts.
+func (r *forumQuestionRepository) filtered(search string, authorID *uint, categoryID *uint, status string) *gorm.DB {
 	query := r.db.Model(&models.ForumQuestion{}).
 		Select("forum_questions.*, (SELECT COUNT(*) FROM forum_answers WHERE forum_answers.question_id = forum_questions.id AND forum_answers.deleted_at IS NULL) AS answers_count")
 
@@ -110,28 +182,14 @@ func (r *forumQuestionRepository) List(offset, limit int, search string, authorI
 	}
 
 	switch strings.ToLower(strings.TrimSpace(status)) {
+	// EXISTS stops at the first answer instead of counting every answer of
+	// every question the filter looks at.
 	case "resolved", "answered":
-		query = query.Where("(SELECT COUNT(*) FROM forum_answers WHERE forum_answers.question_id = forum_questions.id AND forum_answers.deleted_at IS NULL) > 0")
+		query = query.Where("EXISTS (SELECT 1 FROM forum_answers WHERE forum_answers.question_id = forum_questions.id AND forum_answers.deleted_at IS NULL)")
 	case "unresolved", "unanswered":
-		query = query.Where("(SELECT COUNT(*) FROM forum_answers WHERE forum_answers.question_id = forum_questions.id AND forum_answers.deleted_at IS NULL) = 0")
-	}
-
-	var total int64
-	if err := query.Count(&total).Error; err != nil {
-		return nil, 0, err
-	}
-
-	if limit > 0 {
-		query = query.Offset(offset).Limit(limit)
+		query = query.Where("NOT EXISTS (SELECT 1 FROM forum_answers WHERE forum_answers.question_id = forum_questions.id AND forum_answers.deleted_at IS NULL)")
 	}
-
-	var questions []models.ForumQuestion
-	err := query.
-		Preload("Author").
-		Preload("Category").
-		Order("rating DESC, created_at DESC").
-		Find(&questions).Error
-	return questions, total, err
+	return query
 }
 
 func (r *forumQuestionRepository) ExistsBySlug(slug string) (bool, error) {
@@ -155,3 +213,21 @@ func (r *forumQuestionRepository) IncrementViews(id uint) error {
 	}
 	return r.db.Model(&models.ForumQuestion{}).Where("id = ?", id).UpdateColumn("views", gorm.Expr("views + 1")).Error
 }
+
+// AddViews adds buffered view counts to the questions in one transaction.
+func (r *forumQuestionRepository) AddViews(counts map[uint]int64) error {
+	if r == nil || r.db == nil {
+		return gorm.ErrInvalidDB
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for id, views := range counts {
+			if views <= 0 {
+				continue
+			}
+			if err := tx.Model(&models.ForumQuestion{}).Where("id = ?", id).UpdateColumn("views", gorm.Expr("views + ?", views)).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
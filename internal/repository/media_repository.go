@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"constructor-script-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type MediaRepository interface {
+	List() ([]models.MediaMetadata, error)
+	Save(metadata *models.MediaMetadata) error
+	// SaveVideo creates or replaces the extracted video details, keeping editorial fields.
+	SaveVideo(metadata *models.MediaMetadata) error
+	Rename(from, to string) error
+	Delete(filename string) error
+	// UploadReferences scans every table for links to uploaded files and returns the
+	// number of rows referencing each filename.
+	UploadReferences() (map[string]int, error)
+}
+
+// RemoteUploadRepository records files stored in object storage.
+type RemoteUploadRepository interface {
+	List() ([]models.RemoteUpload, error)
+	Create(upload *models.RemoteUpload) error
+	GetByFilename(filename string) (*models.RemoteUpload, error)
+	Delete(filename string) error
+}
+
+type mediaRepository struct {
+	db *gorm.DB
+}
+
+func NewMediaRepository(db *gorm.DB) MediaRepository {
+	return &mediaRepository{db: db}
+}
+
+func (r *mediaRepository) List() ([]models.MediaMetadata, error) {
+	var metadata []models.MediaMetadata
+	err := r.db.Order("filename ASC").Find(&metadata).Error
+	return metadata, err
+}
+
+// Save creates or replaces the metadata for metadata.Filename.
+func (r *mediaRepository) Save(metadata *models.MediaMetadata) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "filename"}},
+		DoUpdates: clause.AssignmentColumns([]string{"alt_text", "focal_x", "focal_y", "updated_at"}),
+	}).Create(metadata).Error
+}
+
+func (r *mediaRepository) SaveVideo(metadata *models.MediaMetadata) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "filename"}},
+		DoUpdates: clause.AssignmentColumns([]string{"duration_seconds", "width", "height", "poster_url", "updated_at"}),
+	}).Create(metadata).Error
+}
+
+func (r *mediaRepository) Rename(from, to string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("filename = ?", to).Delete(&models.MediaMetadata{}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.MediaMetadata{}).Where("filename = ?", from).Update("filename", to).Error
+	})
+}
+
+func (r *mediaRepository) Delete(filename string) error {
+	return r.db.Where("filename = ?", filename).Delete(&models.MediaMetadata{}).Error
+}
+
+// uploadReferencePattern matches /uploads/<file> links, including links to generated
+// variants (/uploads/variants/<file>/...) and on-demand resizes (/img/<transform>/<file>).
+var uploadReferencePattern = regexp.MustCompile(`/(?:uploads/(?:variants/)?|img/[^/\s"'<>]+/)([^/\s"'<>?#)\\]+)`)
+
+// uploadReferenceSkipTables hold filenames rather than links, so they never count as usage.
+var uploadReferenceSkipTables = map[string]struct{}{
+	"media_metadata": {},
+	"remote_uploads": {},
+}
+
+func (r *mediaRepository) UploadReferences() (map[string]int, error) {
+	var tables []string
+	if err := r.db.Raw(`SELECT table_name FROM information_schema.tables
+		WHERE table_schema = current_schema() AND table_type = 'BASE TABLE'
+		ORDER BY table_name`).Scan(&tables).Error; err != nil {
+		return nil, err
+	}
+
+	references := make(map[string]int)
+	for _, table := range tables {
+		if _, skip := uploadReferenceSkipTables[table]; skip {
+			continue
+		}
+		// Whole rows are serialised so that text, varchar and jsonb columns are all
+		// searched without listing them per model.
+		rows, err := r.db.Raw(fmt.Sprintf(`SELECT row_to_json(t)::text FROM %q t WHERE row_to_json(t)::text LIKE '%%/uploads/%%' OR row_to_json(t)::text LIKE '%%/img/%%'`, table)).Rows()
+		if err != nil {
+			return nil, fmt.Errorf("scan %s for upload references: %w", table, err)
+		}
+		for rows.Next() {
+			var row string
+			if err := rows.Scan(&row); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			seen := make(map[string]struct{})
+			for _, match := range uploadReferencePattern.FindAllStringSubmatch(row, -1) {
+				filename := strings.TrimSpace(match[1])
+				if _, ok := seen[filename]; ok || filename == "" {
+					continue
+				}
+				seen[filename] = struct{}{}
+				references[filename]++
+			}
+		}
+		if err := rows.Close(); err != nil {
+			return nil, err
+		}
+	}
+	return references, nil
+}
+
+type remoteUploadRepository struct {
+	db *gorm.DB
+}
+
+func NewRemoteUploadRepository(db *gorm.DB) RemoteUploadRepository {
+	return &remoteUploadRepository{db: db}
+}
+
+func (r *remoteUploadRepository) List() ([]models.RemoteUpload, error) {
+	var uploads []models.RemoteUpload
+	err := r.db.Order("created_at DESC").Find(&uploads).Error
+	return uploads, err
+}
+
+func (r *remoteUploadRepository) Create(upload *models.RemoteUpload) error {
+	return r.db.Create(upload).Error
+}
+
+func (r *remoteUploadRepository) GetByFilename(filename string) (*models.RemoteUpload, error) {
+	var upload models.RemoteUpload
+	if err := r.db.Where("filename = ?", filename).First(&upload).Error; err != nil {
+		return nil, err
+	}
+	return &upload, nil
+}
+
+func (r *remoteUploadRepository) Delete(filename string) error {
+	return r.db.Where("filename = ?", filename).Delete(&models.RemoteUpload{}).Error
+}
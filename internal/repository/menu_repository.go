@@ -16,6 +16,7 @@ type MenuRepository interface {
 	GetByID(id uint) (*models.MenuItem, error)
 	NextOrder(location string) (int, error)
 	DeleteAll() error
+	ReplaceAll(items []models.MenuItem) error
 }
 
 type menuRepository struct {
@@ -65,3 +66,17 @@ func (r *menuRepository) NextOrder(location string) (int, error) {
 func (r *menuRepository) DeleteAll() error {
 	return r.db.Session(&gorm.Session{AllowGlobalUpdate: true}).Unscoped().Delete(&models.MenuItem{}).Error
 }
+
+// ReplaceAll swaps every menu item for items in one transaction, so a failed write
+// leaves the old menu in place.
+func (r *menuRepository) ReplaceAll(items []models.MenuItem) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Session(&gorm.Session{AllowGlobalUpdate: true}).Unscoped().Delete(&models.MenuItem{}).Error; err != nil {
+			return err
+		}
+		if len(items) == 0 {
+			return nil
+		}
+		return tx.Create(&items).Error
+	})
+}
@@ -0,0 +1,255 @@
+package repository
+
+import (
+	"strings"
+	"time"
+
+	"constructor-script-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type NewsletterRepository interface {
+	CreateSubscriber(subscriber *models.NewsletterSubscriber) error
+	UpdateSubscriber(subscriber *models.NewsletterSubscriber) error
+	DeleteSubscriber(id uint) error
+	GetSubscriberByID(id uint) (*models.NewsletterSubscriber, error)
+	GetSubscriberByEmail(email string) (*models.NewsletterSubscriber, error)
+	GetSubscriberByConfirmToken(token string) (*models.NewsletterSubscriber, error)
+	GetSubscriberByUnsubscribeToken(token string) (*models.NewsletterSubscriber, error)
+	ListSubscribers(status, search string, offset, limit int) ([]models.NewsletterSubscriber, int64, error)
+	ListConfirmedSubscribersAfter(afterID uint, limit int) ([]models.NewsletterSubscriber, error)
+
+	CreateCampaign(campaign *models.NewsletterCampaign) error
+	UpdateCampaign(campaign *models.NewsletterCampaign) error
+	DeleteCampaign(id uint) error
+	GetCampaign(id uint) (*models.NewsletterCampaign, error)
+	ListCampaigns() ([]models.NewsletterCampaign, error)
+	ListCampaignsByStatus(status string) ([]models.NewsletterCampaign, error)
+
+	StartCampaign(campaign *models.NewsletterCampaign, deliveries []models.NewsletterDelivery) (bool, error)
+	UpdateDelivery(delivery *models.NewsletterDelivery) error
+	GetDeliveryByToken(token string) (*models.NewsletterDelivery, error)
+	ListPendingDeliveries(campaignID uint, limit int) ([]models.NewsletterDelivery, error)
+	MarkDeliveryOpened(id uint, at time.Time) error
+	RecordDeliveryClick(id uint, at time.Time) error
+	CampaignStats(campaignID uint) (*models.NewsletterCampaignStats, error)
+}
+
+type newsletterRepository struct {
+	db *gorm.DB
+}
+
+func NewNewsletterRepository(db *gorm.DB) NewsletterRepository {
+	return &newsletterRepository{db: db}
+}
+
+func (r *newsletterRepository) CreateSubscriber(subscriber *models.NewsletterSubscriber) error {
+	return r.db.Create(subscriber).Error
+}
+
+func (r *newsletterRepository) UpdateSubscriber(subscriber *models.NewsletterSubscriber) error {
+	return r.db.Save(subscriber).Error
+}
+
+// DeleteSubscriber removes the subscriber permanently so the address can sign up
+// again, and forgets its delivery history.
+func (r *newsletterRepository) DeleteSubscriber(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("subscriber_id = ?", id).Delete(&models.NewsletterDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&models.NewsletterSubscriber{}, id).Error
+	})
+}
+
+func (r *newsletterRepository) GetSubscriberByID(id uint) (*models.NewsletterSubscriber, error) {
+	var subscriber models.NewsletterSubscriber
+	err := r.db.First(&subscriber, id).Error
+	return &subscriber, err
+}
+
+func (r *newsletterRepository) GetSubscriberByEmail(email string) (*models.NewsletterSubscriber, error) {
+	var subscriber models.NewsletterSubscriber
+	err := r.db.Where("email = ?", email).First(&subscriber).Error
+	return &subscriber, err
+}
+
+func (r *newsletterRepository) GetSubscriberByConfirmToken(token string) (*models.NewsletterSubscriber, error) {
+	var subscriber models.NewsletterSubscriber
+	err := r.db.Where("confirm_token = ?", token).First(&subscriber).Error
+	return &subscriber, err
+}
+
+func (r *newsletterRepository) GetSubscriberByUnsubscribeToken(token string) (*models.NewsletterSubscriber, error) {
+	var subscriber models.NewsletterSubscriber
+	err := r.db.Where("unsubscribe_token = ?", token).First(&subscriber).Error
+	return &subscriber, err
+}
+
+func (r *newsletterRepository) ListSubscribers(status, search string, offset, limit int) ([]models.NewsletterSubscriber, int64, error) {
+	var subscribers []models.NewsletterSubscriber
+	var total int64
+
+	query := r.db.Model(&models.NewsletterSubscriber{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if search != "" {
+		pattern := "%" + strings.ToLower(search) + "%"
+		query = query.Where("LOWER(email) LIKE ? OR LOWER(name) LIKE ?", pattern, pattern)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order("created_at DESC, id DESC").Offset(offset).Limit(limit).Find(&subscribers).Error
+	return subscribers, total, err
+}
+
+func (r *newsletterRepository) ListConfirmedSubscribersAfter(afterID uint, limit int) ([]models.NewsletterSubscriber, error) {
+	var subscribers []models.NewsletterSubscriber
+	err := r.db.Where("status = ? AND id > ?", models.NewsletterSubscriberConfirmed, afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&subscribers).Error
+	return subscribers, err
+}
+
+func (r *newsletterRepository) CreateCampaign(campaign *models.NewsletterCampaign) error {
+	return r.db.Create(campaign).Error
+}
+
+func (r *newsletterRepository) UpdateCampaign(campaign *models.NewsletterCampaign) error {
+	return r.db.Save(campaign).Error
+}
+
+func (r *newsletterRepository) DeleteCampaign(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("campaign_id = ?", id).Delete(&models.NewsletterDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.NewsletterCampaign{}, id).Error
+	})
+}
+
+func (r *newsletterRepository) GetCampaign(id uint) (*models.NewsletterCampaign, error) {
+	var campaign models.NewsletterCampaign
+	err := r.db.First(&campaign, id).Error
+	return &campaign, err
+}
+
+func (r *newsletterRepository) ListCampaigns() ([]models.NewsletterCampaign, error) {
+	var campaigns []models.NewsletterCampaign
+	err := r.db.Order("created_at DESC, id DESC").Find(&campaigns).Error
+	return campaigns, err
+}
+
+func (r *newsletterRepository) ListCampaignsByStatus(status string) ([]models.NewsletterCampaign, error) {
+	var campaigns []models.NewsletterCampaign
+	err := r.db.Where("status = ?", status).Order("id ASC").Find(&campaigns).Error
+	return campaigns, err
+}
+
+// StartCampaign saves the rendered campaign as sending and queues its deliveries in
+// one transaction. It reports false, queueing nothing, when the campaign is no longer
+// a draft, so a campaign sent twice at once is queued once.
+func (r *newsletterRepository) StartCampaign(campaign *models.NewsletterCampaign, deliveries []models.NewsletterDelivery) (bool, error) {
+	started := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(campaign).
+			Where("status = ?", models.NewsletterCampaignDraft).
+			Select("status", "started_at", "rendered_subject", "rendered_html", "rendered_text", "links", "updated_at").
+			Updates(campaign)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		started = true
+		if len(deliveries) == 0 {
+			return nil
+		}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&deliveries, 500).Error
+	})
+	return started && err == nil, err
+}
+
+func (r *newsletterRepository) UpdateDelivery(delivery *models.NewsletterDelivery) error {
+	return r.db.Save(delivery).Error
+}
+
+func (r *newsletterRepository) GetDeliveryByToken(token string) (*models.NewsletterDelivery, error) {
+	var delivery models.NewsletterDelivery
+	err := r.db.Where("token = ?", token).First(&delivery).Error
+	return &delivery, err
+}
+
+func (r *newsletterRepository) ListPendingDeliveries(campaignID uint, limit int) ([]models.NewsletterDelivery, error) {
+	var deliveries []models.NewsletterDelivery
+	err := r.db.Where("campaign_id = ? AND status = ?", campaignID, models.NewsletterDeliveryPending).
+		Order("id ASC").
+		Limit(limit).
+		Find(&deliveries).Error
+	return deliveries, err
+}
+
+func (r *newsletterRepository) MarkDeliveryOpened(id uint, at time.Time) error {
+	return r.db.Model(&models.NewsletterDelivery{}).
+		Where("id = ? AND opened_at IS NULL", id).
+		Update("opened_at", at).Error
+}
+
+// RecordDeliveryClick counts a click. A click also implies the email was opened, which
+// covers clients that block the tracking pixel.
+func (r *newsletterRepository) RecordDeliveryClick(id uint, at time.Time) error {
+	return r.db.Model(&models.NewsletterDelivery{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"clicks":     gorm.Expr("clicks + 1"),
+			"clicked_at": gorm.Expr("COALESCE(clicked_at, ?)", at),
+			"opened_at":  gorm.Expr("COALESCE(opened_at, ?)", at),
+		}).Error
+}
+
+func (r *newsletterRepository) CampaignStats(campaignID uint) (*models.NewsletterCampaignStats, error) {
+	var row struct {
+		Recipients int64
+		Pending    int64
+		Sent       int64
+		Failed     int64
+		Opened     int64
+		Clicked    int64
+		Clicks     int64
+	}
+
+	err := r.db.Model(&models.NewsletterDelivery{}).
+		Select(`COUNT(*) AS recipients,
+			COUNT(*) FILTER (WHERE status = ?) AS pending,
+			COUNT(*) FILTER (WHERE status = ?) AS sent,
+			COUNT(*) FILTER (WHERE status = ?) AS failed,
+			COUNT(opened_at) AS opened,
+			COUNT(clicked_at) AS clicked,
+			COALESCE(SUM(clicks), 0) AS clicks`,
+			models.NewsletterDeliveryPending, models.NewsletterDeliverySent, models.NewsletterDeliveryFailed).
+		Where("campaign_id = ?", campaignID).
+		Scan(&row).Error
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &models.NewsletterCampaignStats{
+		Recipients: row.Recipients,
+		Pending:    row.Pending,
+		Sent:       row.Sent,
+		Failed:     row.Failed,
+		Opened:     row.Opened,
+		Clicked:    row.Clicked,
+		Clicks:     row.Clicks,
+	}
+	if row.Sent > 0 {
+		stats.OpenRate = float64(row.Opened) / float64(row.Sent)
+		stats.ClickRate = float64(row.Clicked) / float64(row.Sent)
+	}
+	return stats, nil
+}
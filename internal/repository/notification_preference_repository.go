@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"constructor-script-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type NotificationPreferenceRepository interface {
+	ListByUser(userID uint) ([]models.NotificationPreference, error)
+	// ListForUsers returns the choices the users made for one event and channel.
+	ListForUsers(userIDs []uint, event, channel string) ([]models.NotificationPreference, error)
+	// Save stores the preferences, replacing the users' earlier choices for the
+	// same event and channel.
+	Save(preferences []models.NotificationPreference) error
+}
+
+type notificationPreferenceRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationPreferenceRepository(db *gorm.DB) NotificationPreferenceRepository {
+	return &notificationPreferenceRepository{db: db}
+}
+
+func (r *notificationPreferenceRepository) ListByUser(userID uint) ([]models.NotificationPreference, error) {
+	var preferences []models.NotificationPreference
+	err := r.db.Where("user_id = ?", userID).Find(&preferences).Error
+	return preferences, err
+}
+
+func (r *notificationPreferenceRepository) ListForUsers(userIDs []uint, event, channel string) ([]models.NotificationPreference, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+	var preferences []models.NotificationPreference
+	err := r.db.Where("user_id IN ? AND event = ? AND channel = ?", userIDs, event, channel).
+		Find(&preferences).Error
+	return preferences, err
+}
+
+func (r *notificationPreferenceRepository) Save(preferences []models.NotificationPreference) error {
+	if len(preferences) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "event"}, {Name: "channel"}},
+		DoUpdates: clause.AssignmentColumns([]string{"enabled", "updated_at"}),
+	}).Create(&preferences).Error
+}
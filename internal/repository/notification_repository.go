@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"time"
+
+	"constructor-script-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// notificationInsertBatch is how many notifications CreateMany inserts per
+// statement when a message goes out to many users.
+const notificationInsertBatch = 500
+
+type NotificationRepository interface {
+	CreateMany(notifications []models.Notification) error
+	// ListByUser returns the newest notifications of the user, only the unread
+	// ones when unreadOnly is set.
+	ListByUser(userID uint, unreadOnly bool, limit int) ([]models.Notification, error)
+	CountUnread(userID uint) (int64, error)
+	// MarkRead marks a notification of the user as read. It returns
+	// gorm.ErrRecordNotFound when the user has no such notification.
+	MarkRead(userID, id uint, at time.Time) error
+	MarkAllRead(userID uint, at time.Time) error
+	// DeleteReadBefore removes the notifications read before cutoff.
+	DeleteReadBefore(cutoff time.Time) (int64, error)
+}
+
+type notificationRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) NotificationRepository {
+	return &notificationRepository{db: db}
+}
+
+func (r *notificationRepository) CreateMany(notifications []models.Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+	return r.db.CreateInBatches(notifications, notificationInsertBatch).Error
+}
+
+func (r *notificationRepository) ListByUser(userID uint, unreadOnly bool, limit int) ([]models.Notification, error) {
+	var notifications []models.Notification
+	query := r.db.Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+	err := query.Order("created_at DESC, id DESC").Limit(limit).Find(&notifications).Error
+	return notifications, err
+}
+
+func (r *notificationRepository) CountUnread(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *notificationRepository) MarkRead(userID, id uint, at time.Time) error {
+	result := r.db.Model(&models.Notification{}).
+		Where("id = ? AND user_id = ?", id, userID).
+		Update("read_at", gorm.Expr("COALESCE(read_at, ?)", at))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *notificationRepository) MarkAllRead(userID uint, at time.Time) error {
+	return r.db.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", at).Error
+}
+
+func (r *notificationRepository) DeleteReadBefore(cutoff time.Time) (int64, error) {
+	result := r.db.Where("read_at IS NOT NULL AND read_at < ?", cutoff).Delete(&models.Notification{})
+	return result.RowsAffected, result.Error
+}
@@ -17,8 +17,12 @@ type PostRepository interface {
 	GetBySlug(slug string) (*models.Post, error)
 	GetPopular(limit int) ([]models.Post, error)
 	GetRecent(limit int) ([]models.Post, error)
+	// GetPublishedBetween returns the posts that went live after from and no later
+	// than to, newest first.
+	GetPublishedBetween(from, to time.Time, limit int) ([]models.Post, error)
 	GetRelated(postID uint, categoryID uint, limit int) ([]models.Post, error)
 	IncrementViews(id uint) error
+	AddViews(date time.Time, counts map[uint]int64) error
 	GetViewStats(postID uint, start time.Time) ([]DailyCount, error)
 	GetAverageViews() (float64, error)
 	GetAverageComments() (float64, error)
@@ -27,6 +31,8 @@ type PostRepository interface {
 	ExistsBySlug(slug string) (bool, error)
 	ReassignCategory(fromCategoryID, toCategoryID uint) error
 	GetAllPublished() ([]models.Post, error)
+	GetPublishedTranslations(translationKey string) ([]models.Post, error)
+	GetTranslationSummaries() ([]models.Post, error)
 }
 
 type postRepository struct {
@@ -153,6 +159,17 @@ func (r *postRepository) GetRecent(limit int) ([]models.Post, error) {
 	return posts, err
 }
 
+func (r *postRepository) GetPublishedBetween(from, to time.Time, limit int) ([]models.Post, error) {
+	var posts []models.Post
+	err := r.db.Where("published = ?", true).
+		Where("COALESCE(posts.publish_at, posts.created_at) > ?", from).
+		Where("COALESCE(posts.publish_at, posts.created_at) <= ?", to).
+		Order("COALESCE(posts.publish_at, posts.created_at) DESC").
+		Limit(limit).
+		Find(&posts).Error
+	return posts, err
+}
+
 func (r *postRepository) GetRelated(postID uint, categoryID uint, limit int) ([]models.Post, error) {
 	var posts []models.Post
 	now := time.Now().UTC()
@@ -169,27 +186,43 @@ func (r *postRepository) GetRelated(postID uint, categoryID uint, limit int) ([]
 }
 
 func (r *postRepository) IncrementViews(id uint) error {
-	now := time.Now().UTC()
-	date := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return r.AddViews(time.Now().UTC(), map[uint]int64{id: 1})
+}
+
+// AddViews adds buffered view counts to the posts and to their statistics for the
+// day of date in one transaction. Posts deleted in the meantime are skipped.
+func (r *postRepository) AddViews(date time.Time, counts map[uint]int64) error {
+	date = date.UTC()
+	date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
 
 	return r.db.Transaction(func(tx *gorm.DB) error {
-		if err := tx.Model(&models.Post{}).
-			Where("id = ?", id).
-			UpdateColumn("views", gorm.Expr("views + ?", 1)).Error; err != nil {
-			return err
-		}
+		for id, views := range counts {
+			if views <= 0 {
+				continue
+			}
 
-		result := tx.Model(&models.PostViewStat{}).
-			Where("post_id = ? AND date = ?", id, date).
-			UpdateColumn("views", gorm.Expr("views + ?", 1))
-		if result.Error != nil {
-			return result.Error
-		}
+			result := tx.Model(&models.Post{}).
+				Where("id = ?", id).
+				UpdateColumn("views", gorm.Expr("views + ?", views))
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				continue
+			}
 
-		if result.RowsAffected == 0 {
-			stat := models.PostViewStat{PostID: id, Date: date, Views: 1}
-			if err := tx.Create(&stat).Error; err != nil {
-				return err
+			result = tx.Model(&models.PostViewStat{}).
+				Where("post_id = ? AND date = ?", id, date).
+				UpdateColumn("views", gorm.Expr("views + ?", views))
+			if result.Error != nil {
+				return result.Error
+			}
+
+			if result.RowsAffected == 0 {
+				stat := models.PostViewStat{PostID: id, Date: date, Views: views}
+				if err := tx.Create(&stat).Error; err != nil {
+					return err
+				}
 			}
 		}
 
@@ -339,10 +372,33 @@ func (r *postRepository) GetAllPublished() ([]models.Post, error) {
 	var posts []models.Post
 	now := time.Now().UTC()
 
-	err := r.db.Select("id", "slug", "updated_at", "created_at", "publish_at", "published_at").
+	err := r.db.Select("id", "slug", "title", "featured_img", "language", "translation_key", "no_index", "sitemap_exclude", "updated_at", "created_at", "publish_at", "published_at").
 		Where("published = ?", true).
 		Where("publish_at IS NULL OR publish_at <= ?", now).
 		Order("COALESCE(posts.publish_at, posts.updated_at, posts.created_at) DESC").
 		Find(&posts).Error
 	return posts, err
 }
+
+func (r *postRepository) GetPublishedTranslations(translationKey string) ([]models.Post, error) {
+	var posts []models.Post
+	now := time.Now().UTC()
+
+	err := r.db.Select("id", "slug", "language", "translation_key").
+		Where("translation_key = ?", translationKey).
+		Where("published = ?", true).
+		Where("publish_at IS NULL OR publish_at <= ?", now).
+		Order("id ASC").
+		Find(&posts).Error
+	return posts, err
+}
+
+// GetTranslationSummaries returns every post, drafts included, with only the
+// fields needed to report translation coverage.
+func (r *postRepository) GetTranslationSummaries() ([]models.Post, error) {
+	var posts []models.Post
+	err := r.db.Select("id", "title", "slug", "language", "translation_key", "published", "publish_at", "updated_at").
+		Order("id ASC").
+		Find(&posts).Error
+	return posts, err
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"time"
+
+	"constructor-script-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type ProductRepository interface {
+	Create(product *models.Product) error
+	Update(product *models.Product) error
+	Delete(id uint) error
+	GetByID(id uint) (*models.Product, error)
+	GetBySlug(slug string) (*models.Product, error)
+	ExistsBySlug(slug string, excludeID uint) (bool, error)
+	List() ([]models.Product, error)
+	ListPublished() ([]models.Product, error)
+
+	CreateOrder(order *models.ProductOrder) error
+	UpdateOrderSession(id uint, sessionID string) error
+	GetOrderByID(id uint) (*models.ProductOrder, error)
+	GetOrderBySession(sessionID string) (*models.ProductOrder, error)
+	ListOrders(productID uint) ([]models.ProductOrder, error)
+	MarkOrderPaid(id uint, paidAt time.Time) (bool, error)
+	IncrementDownloads(id uint, limit int) (bool, error)
+}
+
+type productRepository struct {
+	db *gorm.DB
+}
+
+func NewProductRepository(db *gorm.DB) ProductRepository {
+	return &productRepository{db: db}
+}
+
+func (r *productRepository) Create(product *models.Product) error {
+	return r.db.Create(product).Error
+}
+
+func (r *productRepository) Update(product *models.Product) error {
+	return r.db.Omit("ArchiveFile").Save(product).Error
+}
+
+func (r *productRepository) Delete(id uint) error {
+	return r.db.Delete(&models.Product{}, id).Error
+}
+
+func (r *productRepository) GetByID(id uint) (*models.Product, error) {
+	var product models.Product
+	err := r.db.Preload("ArchiveFile").First(&product, id).Error
+	return &product, err
+}
+
+func (r *productRepository) GetBySlug(slug string) (*models.Product, error) {
+	var product models.Product
+	err := r.db.Preload("ArchiveFile").Where("slug = ?", slug).First(&product).Error
+	return &product, err
+}
+
+func (r *productRepository) ExistsBySlug(slug string, excludeID uint) (bool, error) {
+	var count int64
+	query := r.db.Unscoped().Model(&models.Product{}).Where("slug = ?", slug)
+	if excludeID > 0 {
+		query = query.Where("id <> ?", excludeID)
+	}
+	err := query.Count(&count).Error
+	return count > 0, err
+}
+
+func (r *productRepository) List() ([]models.Product, error) {
+	var products []models.Product
+	err := r.db.Preload("ArchiveFile").Order("created_at DESC, id DESC").Find(&products).Error
+	return products, err
+}
+
+func (r *productRepository) ListPublished() ([]models.Product, error) {
+	var products []models.Product
+	err := r.db.Where("published = ?", true).Order("title ASC, id ASC").Find(&products).Error
+	return products, err
+}
+
+func (r *productRepository) CreateOrder(order *models.ProductOrder) error {
+	return r.db.Omit("Product").Create(order).Error
+}
+
+func (r *productRepository) UpdateOrderSession(id uint, sessionID string) error {
+	return r.db.Model(&models.ProductOrder{}).Where("id = ?", id).Update("session_id", sessionID).Error
+}
+
+// GetOrderByID loads the order with its product and file, including a product that has
+// since been deleted, so earlier buyers keep their downloads.
+func (r *productRepository) GetOrderByID(id uint) (*models.ProductOrder, error) {
+	var order models.ProductOrder
+	err := r.db.
+		Preload("Product", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Preload("Product.ArchiveFile").
+		First(&order, id).Error
+	return &order, err
+}
+
+func (r *productRepository) GetOrderBySession(sessionID string) (*models.ProductOrder, error) {
+	var order models.ProductOrder
+	err := r.db.Preload("Product").Where("session_id = ?", sessionID).First(&order).Error
+	return &order, err
+}
+
+func (r *productRepository) ListOrders(productID uint) ([]models.ProductOrder, error) {
+	var orders []models.ProductOrder
+	query := r.db.Order("created_at DESC, id DESC")
+	if productID > 0 {
+		query = query.Where("product_id = ?", productID)
+	}
+	err := query.Find(&orders).Error
+	return orders, err
+}
+
+// MarkOrderPaid moves a pending order to paid. It reports false when the order was
+// already paid, so fulfilment side effects run only once.
+func (r *productRepository) MarkOrderPaid(id uint, paidAt time.Time) (bool, error) {
+	result := r.db.Model(&models.ProductOrder{}).
+		Where("id = ? AND status = ?", id, models.ProductOrderStatusPending).
+		Updates(map[string]interface{}{"status": models.ProductOrderStatusPaid, "paid_at": paidAt})
+	return result.RowsAffected > 0, result.Error
+}
+
+// IncrementDownloads counts a download for a paid order. With a positive limit it
+// reports false once the order has used up its downloads.
+func (r *productRepository) IncrementDownloads(id uint, limit int) (bool, error) {
+	query := r.db.Model(&models.ProductOrder{}).Where("id = ? AND status = ?", id, models.ProductOrderStatusPaid)
+	if limit > 0 {
+		query = query.Where("downloads < ?", limit)
+	}
+	result := query.UpdateColumn("downloads", gorm.Expr("downloads + 1"))
+	return result.RowsAffected > 0, result.Error
+}
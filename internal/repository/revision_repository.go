@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"time"
+
+	"constructor-script-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type RevisionRepository interface {
+	Create(revision *models.Revision) error
+	GetByID(id uint) (*models.Revision, error)
+	// Latest returns the newest revision of an item, or gorm.ErrRecordNotFound.
+	Latest(contentType string, contentID uint) (*models.Revision, error)
+	// List returns the revisions of an item newest first, without their
+	// content.
+	List(contentType string, contentID uint, limit int) ([]models.Revision, error)
+	// Previous returns the revision saved before revision, or
+	// gorm.ErrRecordNotFound for the first one.
+	Previous(revision *models.Revision) (*models.Revision, error)
+	// KeepLatest deletes all but the newest keep revisions of an item.
+	KeepLatest(contentType string, contentID uint, keep int) (int64, error)
+	// DeleteBefore deletes revisions older than cutoff, keeping the newest
+	// revision of every item.
+	DeleteBefore(cutoff time.Time) (int64, error)
+	DeleteByContent(contentType string, contentID uint) error
+}
+
+type revisionRepository struct {
+	db *gorm.DB
+}
+
+func NewRevisionRepository(db *gorm.DB) RevisionRepository {
+	return &revisionRepository{db: db}
+}
+
+func (r *revisionRepository) Create(revision *models.Revision) error {
+	return r.db.Create(revision).Error
+}
+
+func (r *revisionRepository) GetByID(id uint) (*models.Revision, error) {
+	var revision models.Revision
+	if err := r.db.First(&revision, id).Error; err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+func (r *revisionRepository) Latest(contentType string, contentID uint) (*models.Revision, error) {
+	var revision models.Revision
+	err := r.db.Where("content_type = ? AND content_id = ?", contentType, contentID).
+		Order("id DESC").
+		First(&revision).Error
+	if err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+func (r *revisionRepository) List(contentType string, contentID uint, limit int) ([]models.Revision, error) {
+	var revisions []models.Revision
+	query := r.db.Select("id", "created_at", "content_type", "content_id", "title").
+		Where("content_type = ? AND content_id = ?", contentType, contentID).
+		Order("id DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	err := query.Find(&revisions).Error
+	return revisions, err
+}
+
+func (r *revisionRepository) Previous(revision *models.Revision) (*models.Revision, error) {
+	var previous models.Revision
+	err := r.db.Where("content_type = ? AND content_id = ? AND id < ?", revision.ContentType, revision.ContentID, revision.ID).
+		Order("id DESC").
+		First(&previous).Error
+	if err != nil {
+		return nil, err
+	}
+	return &previous, nil
+}
+
+func (r *revisionRepository) KeepLatest(contentType string, contentID uint, keep int) (int64, error) {
+	latest := r.db.Model(&models.Revision{}).Select("id").
+		Where("content_type = ? AND content_id = ?", contentType, contentID).
+		Order("id DESC").
+		Limit(keep)
+	result := r.db.Where("content_type = ? AND content_id = ? AND id NOT IN (?)", contentType, contentID, latest).
+		Delete(&models.Revision{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *revisionRepository) DeleteBefore(cutoff time.Time) (int64, error) {
+	newest := r.db.Model(&models.Revision{}).Select("MAX(id)").Group("content_type, content_id")
+	result := r.db.Where("created_at < ? AND id NOT IN (?)", cutoff, newest).Delete(&models.Revision{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *revisionRepository) DeleteByContent(contentType string, contentID uint) error {
+	return r.db.Where("content_type = ? AND content_id = ?", contentType, contentID).Delete(&models.Revision{}).Error
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"constructor-script-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SlugRedirectRepository stores the previous URLs of renamed posts and pages.
+type SlugRedirectRepository interface {
+	// Record points path at the given content, replacing any earlier target.
+	Record(contentType string, contentID uint, path string) error
+	GetByPath(path string) (*models.SlugRedirect, error)
+	DeleteByPath(path string) error
+	DeleteByContent(contentType string, contentID uint) error
+}
+
+type slugRedirectRepository struct {
+	db *gorm.DB
+}
+
+func NewSlugRedirectRepository(db *gorm.DB) SlugRedirectRepository {
+	return &slugRedirectRepository{db: db}
+}
+
+func (r *slugRedirectRepository) Record(contentType string, contentID uint, path string) error {
+	redirect := &models.SlugRedirect{Path: path, ContentType: contentType, ContentID: contentID}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "path"}},
+		DoUpdates: clause.AssignmentColumns([]string{"content_type", "content_id", "updated_at"}),
+	}).Create(redirect).Error
+}
+
+func (r *slugRedirectRepository) GetByPath(path string) (*models.SlugRedirect, error) {
+	var redirect models.SlugRedirect
+	err := r.db.First(&redirect, "path = ?", path).Error
+	return &redirect, err
+}
+
+func (r *slugRedirectRepository) DeleteByPath(path string) error {
+	return r.db.Where("path = ?", path).Delete(&models.SlugRedirect{}).Error
+}
+
+func (r *slugRedirectRepository) DeleteByContent(contentType string, contentID uint) error {
+	return r.db.Where("content_type = ? AND content_id = ?", contentType, contentID).Delete(&models.SlugRedirect{}).Error
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"constructor-script-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type SocialShareRepository interface {
+	ListAccounts() ([]models.SocialAccount, error)
+	ListActiveAccounts() ([]models.SocialAccount, error)
+	GetAccount(id uint) (*models.SocialAccount, error)
+	CreateAccount(account *models.SocialAccount) error
+	UpdateAccount(account *models.SocialAccount) error
+	DeleteAccount(id uint) error
+
+	CreateShare(share *models.SocialShare) error
+	UpdateShare(share *models.SocialShare) error
+	GetShare(id uint) (*models.SocialShare, error)
+	ExistsShare(accountID, postID uint) (bool, error)
+	ListShares(accountID uint, limit int) ([]models.SocialShare, error)
+	ListPendingShares() ([]models.SocialShare, error)
+}
+
+type socialShareRepository struct {
+	db *gorm.DB
+}
+
+func NewSocialShareRepository(db *gorm.DB) SocialShareRepository {
+	return &socialShareRepository{db: db}
+}
+
+func (r *socialShareRepository) ListAccounts() ([]models.SocialAccount, error) {
+	var accounts []models.SocialAccount
+	err := r.db.Order("id ASC").Find(&accounts).Error
+	return accounts, err
+}
+
+func (r *socialShareRepository) ListActiveAccounts() ([]models.SocialAccount, error) {
+	var accounts []models.SocialAccount
+	err := r.db.Where("active = ?", true).Order("id ASC").Find(&accounts).Error
+	return accounts, err
+}
+
+func (r *socialShareRepository) GetAccount(id uint) (*models.SocialAccount, error) {
+	var account models.SocialAccount
+	err := r.db.First(&account, id).Error
+	return &account, err
+}
+
+func (r *socialShareRepository) CreateAccount(account *models.SocialAccount) error {
+	return r.db.Create(account).Error
+}
+
+func (r *socialShareRepository) UpdateAccount(account *models.SocialAccount) error {
+	return r.db.Save(account).Error
+}
+
+func (r *socialShareRepository) DeleteAccount(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("account_id = ?", id).Delete(&models.SocialShare{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&models.SocialAccount{}, id).Error
+	})
+}
+
+func (r *socialShareRepository) CreateShare(share *models.SocialShare) error {
+	return r.db.Create(share).Error
+}
+
+func (r *socialShareRepository) UpdateShare(share *models.SocialShare) error {
+	return r.db.Save(share).Error
+}
+
+func (r *socialShareRepository) GetShare(id uint) (*models.SocialShare, error) {
+	var share models.SocialShare
+	err := r.db.First(&share, id).Error
+	return &share, err
+}
+
+func (r *socialShareRepository) ExistsShare(accountID, postID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.SocialShare{}).
+		Where("account_id = ? AND post_id = ?", accountID, postID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// ListShares returns the most recent shares, optionally limited to one account.
+func (r *socialShareRepository) ListShares(accountID uint, limit int) ([]models.SocialShare, error) {
+	var shares []models.SocialShare
+	query := r.db.Order("created_at DESC, id DESC")
+	if accountID != 0 {
+		query = query.Where("account_id = ?", accountID)
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	err := query.Find(&shares).Error
+	return shares, err
+}
+
+func (r *socialShareRepository) ListPendingShares() ([]models.SocialShare, error) {
+	var shares []models.SocialShare
+	err := r.db.Where("status = ?", models.SocialSharePending).Order("id ASC").Find(&shares).Error
+	return shares, err
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"time"
+
+	"constructor-script-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type StatusIncidentRepository interface {
+	Create(incident *models.StatusIncident) error
+	Update(incident *models.StatusIncident) error
+	Delete(id uint) error
+	GetByID(id uint) (*models.StatusIncident, error)
+	List(limit int) ([]models.StatusIncident, error)
+	ListSince(since time.Time) ([]models.StatusIncident, error)
+}
+
+type statusIncidentRepository struct {
+	db *gorm.DB
+}
+
+func NewStatusIncidentRepository(db *gorm.DB) StatusIncidentRepository {
+	return &statusIncidentRepository{db: db}
+}
+
+func (r *statusIncidentRepository) Create(incident *models.StatusIncident) error {
+	return r.db.Create(incident).Error
+}
+
+func (r *statusIncidentRepository) Update(incident *models.StatusIncident) error {
+	return r.db.Save(incident).Error
+}
+
+func (r *statusIncidentRepository) Delete(id uint) error {
+	result := r.db.Delete(&models.StatusIncident{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *statusIncidentRepository) GetByID(id uint) (*models.StatusIncident, error) {
+	var incident models.StatusIncident
+	err := r.db.First(&incident, id).Error
+	return &incident, err
+}
+
+func (r *statusIncidentRepository) List(limit int) ([]models.StatusIncident, error) {
+	var incidents []models.StatusIncident
+	err := r.db.Order("started_at DESC").Limit(limit).Find(&incidents).Error
+	return incidents, err
+}
+
+// ListSince returns the unresolved incidents and those resolved after since, newest
+// first.
+func (r *statusIncidentRepository) ListSince(since time.Time) ([]models.StatusIncident, error) {
+	var incidents []models.StatusIncident
+	err := r.db.
+		Where("resolved_at IS NULL OR resolved_at >= ?", since).
+		Order("started_at DESC").
+		Find(&incidents).Error
+	return incidents, err
+}
@@ -12,6 +12,7 @@ type TagRepository interface {
 	Delete(id uint) error
 	GetByID(id uint) (*models.Tag, error)
 	GetBySlug(slug string) (*models.Tag, error)
+	GetBySlugs(slugs []string) ([]models.Tag, error)
 	GetAll() ([]models.Tag, error)
 	GetUsed() ([]models.Tag, error)
 	ExistsByName(name string) (bool, error)
@@ -53,6 +54,15 @@ func (r *tagRepository) GetBySlug(slug string) (*models.Tag, error) {
 	return &tag, err
 }
 
+func (r *tagRepository) GetBySlugs(slugs []string) ([]models.Tag, error) {
+	if len(slugs) == 0 {
+		return []models.Tag{}, nil
+	}
+	var tags []models.Tag
+	err := r.db.Where("slug IN ?", slugs).Find(&tags).Error
+	return tags, err
+}
+
 func (r *tagRepository) GetAll() ([]models.Tag, error) {
 	var tags []models.Tag
 	err := r.db.Find(&tags).Error
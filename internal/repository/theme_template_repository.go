@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"constructor-script-backend/internal/models"
+)
+
+type ThemeTemplateRepository interface {
+	ListByTheme(theme string) ([]models.ThemeTemplateOverride, error)
+	Get(theme, name string) (*models.ThemeTemplateOverride, error)
+	Save(override *models.ThemeTemplateOverride) error
+	Delete(theme, name string) error
+}
+
+type themeTemplateRepository struct {
+	db *gorm.DB
+}
+
+func NewThemeTemplateRepository(db *gorm.DB) ThemeTemplateRepository {
+	if db == nil {
+		return nil
+	}
+	return &themeTemplateRepository{db: db}
+}
+
+func (r *themeTemplateRepository) ListByTheme(theme string) ([]models.ThemeTemplateOverride, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("theme template repository is not configured")
+	}
+
+	var overrides []models.ThemeTemplateOverride
+	err := r.db.Where("theme = ?", strings.ToLower(strings.TrimSpace(theme))).
+		Order("name ASC").
+		Find(&overrides).Error
+	return overrides, err
+}
+
+func (r *themeTemplateRepository) Get(theme, name string) (*models.ThemeTemplateOverride, error) {
+	if r == nil || r.db == nil {
+		return nil, errors.New("theme template repository is not configured")
+	}
+
+	var override models.ThemeTemplateOverride
+	err := r.db.Where("theme = ? AND name = ?", strings.ToLower(strings.TrimSpace(theme)), strings.TrimSpace(name)).
+		First(&override).Error
+	if err != nil {
+		return nil, err
+	}
+	return &override, nil
+}
+
+func (r *themeTemplateRepository) Save(override *models.ThemeTemplateOverride) error {
+	if r == nil || r.db == nil {
+		return errors.New("theme template repository is not configured")
+	}
+	if override == nil {
+		return errors.New("template override is required")
+	}
+
+	override.Theme = strings.ToLower(strings.TrimSpace(override.Theme))
+	override.Name = strings.TrimSpace(override.Name)
+	if override.Theme == "" || override.Name == "" {
+		return errors.New("theme and template name are required")
+	}
+
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "theme"}, {Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"content", "updated_at"}),
+	}).Create(override).Error
+}
+
+func (r *themeTemplateRepository) Delete(theme, name string) error {
+	if r == nil || r.db == nil {
+		return errors.New("theme template repository is not configured")
+	}
+
+	return r.db.Where("theme = ? AND name = ?", strings.ToLower(strings.TrimSpace(theme)), strings.TrimSpace(name)).
+		Delete(&models.ThemeTemplateOverride{}).Error
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"time"
+
+	"constructor-script-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type TwoFactorRepository interface {
+	// Get returns the authenticator of a user, or gorm.ErrRecordNotFound.
+	Get(userID uint) (*models.UserTwoFactor, error)
+	Save(twoFactor *models.UserTwoFactor) error
+	// Delete removes the authenticator and recovery codes of a user.
+	Delete(userID uint) error
+	ReplaceRecoveryCodes(userID uint, hashes []string) error
+	// UseRecoveryCode marks an unused code as used and reports whether there
+	// was one.
+	UseRecoveryCode(userID uint, hash string, at time.Time) (bool, error)
+	CountRecoveryCodes(userID uint) (int64, error)
+}
+
+type twoFactorRepository struct {
+	db *gorm.DB
+}
+
+func NewTwoFactorRepository(db *gorm.DB) TwoFactorRepository {
+	return &twoFactorRepository{db: db}
+}
+
+func (r *twoFactorRepository) Get(userID uint) (*models.UserTwoFactor, error) {
+	var twoFactor models.UserTwoFactor
+	if err := r.db.Where("user_id = ?", userID).First(&twoFactor).Error; err != nil {
+		return nil, err
+	}
+	return &twoFactor, nil
+}
+
+func (r *twoFactorRepository) Save(twoFactor *models.UserTwoFactor) error {
+	return r.db.Save(twoFactor).Error
+}
+
+func (r *twoFactorRepository) Delete(userID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.TwoFactorRecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&models.UserTwoFactor{}).Error
+	})
+}
+
+func (r *twoFactorRepository) ReplaceRecoveryCodes(userID uint, hashes []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.TwoFactorRecoveryCode{}).Error; err != nil {
+			return err
+		}
+		codes := make([]models.TwoFactorRecoveryCode, len(hashes))
+		for i, hash := range hashes {
+			codes[i] = models.TwoFactorRecoveryCode{UserID: userID, CodeHash: hash}
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+func (r *twoFactorRepository) UseRecoveryCode(userID uint, hash string, at time.Time) (bool, error) {
+	result := r.db.Model(&models.TwoFactorRecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hash).
+		Update("used_at", at)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *twoFactorRepository) CountRecoveryCodes(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.TwoFactorRecoveryCode{}).Where("user_id = ? AND used_at IS NULL", userID).Count(&count).Error
+	return count, err
+}
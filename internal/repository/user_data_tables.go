@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"fmt"
+
+	"constructor-script-backend/internal/models"
+	pluginbackup "constructor-script-backend/internal/plugin/backup"
+
+	"gorm.io/gorm"
+)
+
+// Columns naming the user a row belongs to, in the order they are looked for.
+const (
+	userDataColumnUser   = "user_id"
+	userDataColumnAuthor = "author_id"
+	userDataColumnEmail  = "email"
+)
+
+// userDataModel is a core model with rows of a user. Audit entries stay when
+// the account is deleted, and reset tokens, authenticator secrets and earlier
+// exports are never exported.
+type userDataModel struct {
+	model  interface{}
+	export bool
+	erase  bool
+}
+
+var coreUserDataModels = []userDataModel{
+	{&models.Post{}, true, true},
+	{&models.Comment{}, true, true},
+	{&models.AnnouncementDismissal{}, true, true},
+	{&models.Notification{}, true, true},
+	{&models.NotificationPreference{}, true, true},
+	{&models.DigestSubscription{}, true, true},
+	{&models.PasswordResetToken{}, false, true},
+	{&models.DataExport{}, false, true},
+	{&models.UserIdentity{}, true, true},
+	{&models.UserTwoFactor{}, false, true},
+	{&models.TwoFactorRecoveryCode{}, false, true},
+	{&models.AuditLog{}, true, false},
+}
+
+// communityContentModels are what users write for others to read. Deleting an
+// account anonymizes or deletes them as configured; other authored rows, such
+// as posts, always move to the placeholder account.
+var communityContentModels = []interface{}{
+	&models.Comment{},
+	&models.ForumQuestion{},
+	&models.ForumAnswer{},
+}
+
+// userDataTable is a table with rows of a user, found by Column.
+type userDataTable struct {
+	Name   string
+	Column string
+	Export bool
+	// Erase is false for tables kept when the account is deleted. Rows found by
+	// email, such as orders, are never erased.
+	Erase bool
+}
+
+// userDataTables resolves the core tables and the plugin tables registered for
+// backups that hold rows of a user. Tables of plugins that are not installed
+// are left out.
+func userDataTables(db *gorm.DB) ([]userDataTable, error) {
+	sources := append([]userDataModel(nil), coreUserDataModels...)
+	for _, plugin := range pluginbackup.Plugins() {
+		for _, model := range plugin.Models {
+			sources = append(sources, userDataModel{model: model, export: true, erase: true})
+		}
+	}
+
+	seen := make(map[string]bool, len(sources))
+	tables := make([]userDataTable, 0, len(sources))
+	for _, source := range sources {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(source.model); err != nil {
+			return nil, fmt.Errorf("failed to resolve user data table: %w", err)
+		}
+		name := stmt.Schema.Table
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		table := userDataTable{Name: name, Export: source.export, Erase: source.erase}
+		switch {
+		case stmt.Schema.LookUpField(userDataColumnUser) != nil:
+			table.Column = userDataColumnUser
+		case stmt.Schema.LookUpField(userDataColumnAuthor) != nil:
+			table.Column = userDataColumnAuthor
+		case stmt.Schema.LookUpField(userDataColumnEmail) != nil:
+			table.Column = userDataColumnEmail
+			table.Erase = false
+		default:
+			continue
+		}
+		if !db.Migrator().HasTable(name) {
+			continue
+		}
+		tables = append(tables, table)
+	}
+	return tables, nil
+}
+
+func communityContentTables(db *gorm.DB) (map[string]bool, error) {
+	tables := make(map[string]bool, len(communityContentModels))
+	for _, model := range communityContentModels {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return nil, fmt.Errorf("failed to resolve content table: %w", err)
+		}
+		tables[stmt.Schema.Table] = true
+	}
+	return tables, nil
+}
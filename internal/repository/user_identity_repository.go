@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"time"
+
+	"constructor-script-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type UserIdentityRepository interface {
+	// Find returns the identity with subject at provider, or
+	// gorm.ErrRecordNotFound.
+	Find(provider, subject string) (*models.UserIdentity, error)
+	ListByUser(userID uint) ([]models.UserIdentity, error)
+	Create(identity *models.UserIdentity) error
+	Touch(id uint, at time.Time) error
+	// DeleteForUser unlinks the user's identity at provider.
+	DeleteForUser(userID uint, provider string) error
+}
+
+type userIdentityRepository struct {
+	db *gorm.DB
+}
+
+func NewUserIdentityRepository(db *gorm.DB) UserIdentityRepository {
+	return &userIdentityRepository{db: db}
+}
+
+func (r *userIdentityRepository) Find(provider, subject string) (*models.UserIdentity, error) {
+	var identity models.UserIdentity
+	if err := r.db.Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error; err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+func (r *userIdentityRepository) ListByUser(userID uint) ([]models.UserIdentity, error) {
+	var identities []models.UserIdentity
+	err := r.db.Where("user_id = ?", userID).Order("provider").Find(&identities).Error
+	return identities, err
+}
+
+func (r *userIdentityRepository) Create(identity *models.UserIdentity) error {
+	return r.db.Create(identity).Error
+}
+
+func (r *userIdentityRepository) Touch(id uint, at time.Time) error {
+	return r.db.Model(&models.UserIdentity{}).Where("id = ?", id).Update("last_used_at", at).Error
+}
+
+func (r *userIdentityRepository) DeleteForUser(userID uint, provider string) error {
+	result := r.db.Where("user_id = ? AND provider = ?", userID, provider).Delete(&models.UserIdentity{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -9,10 +9,14 @@ import (
 type UserRepository interface {
 	Create(user *models.User) error
 	GetByID(id uint) (*models.User, error)
+	GetByIDs(ids []uint) ([]models.User, error)
 	GetByEmail(email string) (*models.User, error)
 	GetByUsername(username string) (*models.User, error)
 	GetAll() ([]models.User, error)
 	Search(query string, limit int) ([]models.User, error)
+	// Batches hands the users matching query, or all of them when it is empty,
+	// to fn in batches ordered by ID.
+	Batches(query string, fn func([]models.User) error) error
 	Update(user *models.User) error
 	Delete(id uint) error
 	Count() (int64, error)
@@ -36,6 +40,15 @@ func (r *userRepository) GetByID(id uint) (*models.User, error) {
 	return &user, err
 }
 
+func (r *userRepository) GetByIDs(ids []uint) ([]models.User, error) {
+	if len(ids) == 0 {
+		return []models.User{}, nil
+	}
+	var users []models.User
+	err := r.db.Where("id IN ?", ids).Find(&users).Error
+	return users, err
+}
+
 func (r *userRepository) GetByEmail(email string) (*models.User, error) {
 	var user models.User
 	err := r.db.Where("email = ?", email).First(&user).Error
@@ -54,6 +67,30 @@ func (r *userRepository) GetAll() ([]models.User, error) {
 	return users, err
 }
 
+func (r *userRepository) Batches(query string, fn func([]models.User) error) error {
+	var lastID uint
+	for {
+		scope := r.db.Where("id > ?", lastID)
+		if query != "" {
+			scope = scope.Where("username ILIKE ? OR email ILIKE ?", "%"+query+"%", "%"+query+"%")
+		}
+		var users []models.User
+		if err := scope.Order("id").Limit(exportBatchSize).Find(&users).Error; err != nil {
+			return err
+		}
+		if len(users) == 0 {
+			return nil
+		}
+		if err := fn(users); err != nil {
+			return err
+		}
+		if len(users) < exportBatchSize {
+			return nil
+		}
+		lastID = users[len(users)-1].ID
+	}
+}
+
 func (r *userRepository) Update(user *models.User) error {
 	return r.db.Save(user).Error
 }
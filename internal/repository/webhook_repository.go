@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"constructor-script-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type WebhookRepository interface {
+	List() ([]models.Webhook, error)
+	ListActive() ([]models.Webhook, error)
+	GetByID(id uint) (*models.Webhook, error)
+	Create(webhook *models.Webhook) error
+	Update(webhook *models.Webhook) error
+	Delete(id uint) error
+
+	CreateDelivery(delivery *models.WebhookDelivery) error
+	UpdateDelivery(delivery *models.WebhookDelivery) error
+	GetDelivery(id uint) (*models.WebhookDelivery, error)
+	ListDeliveries(webhookID uint, limit int) ([]models.WebhookDelivery, error)
+	ListPendingDeliveries() ([]models.WebhookDelivery, error)
+}
+
+type webhookRepository struct {
+	db *gorm.DB
+}
+
+func NewWebhookRepository(db *gorm.DB) WebhookRepository {
+	return &webhookRepository{db: db}
+}
+
+func (r *webhookRepository) List() ([]models.Webhook, error) {
+	var webhooks []models.Webhook
+	err := r.db.Order("id ASC").Find(&webhooks).Error
+	return webhooks, err
+}
+
+func (r *webhookRepository) ListActive() ([]models.Webhook, error) {
+	var webhooks []models.Webhook
+	err := r.db.Where("active = ?", true).Order("id ASC").Find(&webhooks).Error
+	return webhooks, err
+}
+
+func (r *webhookRepository) GetByID(id uint) (*models.Webhook, error) {
+	var webhook models.Webhook
+	err := r.db.First(&webhook, id).Error
+	return &webhook, err
+}
+
+func (r *webhookRepository) Create(webhook *models.Webhook) error {
+	return r.db.Create(webhook).Error
+}
+
+func (r *webhookRepository) Update(webhook *models.Webhook) error {
+	return r.db.Save(webhook).Error
+}
+
+func (r *webhookRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("webhook_id = ?", id).Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&models.Webhook{}, id).Error
+	})
+}
+
+func (r *webhookRepository) CreateDelivery(delivery *models.WebhookDelivery) error {
+	return r.db.Create(delivery).Error
+}
+
+func (r *webhookRepository) UpdateDelivery(delivery *models.WebhookDelivery) error {
+	return r.db.Save(delivery).Error
+}
+
+func (r *webhookRepository) GetDelivery(id uint) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	err := r.db.First(&delivery, id).Error
+	return &delivery, err
+}
+
+func (r *webhookRepository) ListDeliveries(webhookID uint, limit int) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	query := r.db.Where("webhook_id = ?", webhookID).Order("created_at DESC, id DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	err := query.Find(&deliveries).Error
+	return deliveries, err
+}
+
+func (r *webhookRepository) ListPendingDeliveries() ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	err := r.db.Where("status = ?", models.WebhookDeliveryPending).Order("id ASC").Find(&deliveries).Error
+	return deliveries, err
+}
@@ -0,0 +1,259 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	defaultGCPKMSEndpoint = "https://cloudkms.googleapis.com"
+	defaultGCPMetadataURL = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+)
+
+// readVault reads field from the secret at path, for example
+// secret/data/cms#stripe_secret_key. Both versions of the key/value engine are
+// supported.
+func (r *Resolver) readVault(ctx context.Context, reference string) (string, error) {
+	path, field, ok := strings.Cut(reference, "#")
+	path = strings.Trim(path, "/")
+	if !ok || path == "" || field == "" {
+		return "", fmt.Errorf("vault reference must look like vault://<path>#<field>")
+	}
+	if r.opts.VaultAddr == "" || r.opts.VaultToken == "" {
+		return "", fmt.Errorf("%w: set VAULT_ADDR and VAULT_TOKEN", ErrNotConfigured)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(r.opts.VaultAddr, "/")+"/v1/"+path, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", r.opts.VaultToken)
+	if r.opts.VaultNamespace != "" {
+		req.Header.Set("X-Vault-Namespace", r.opts.VaultNamespace)
+	}
+
+	var response struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	if err := r.do(req, &response); err != nil {
+		return "", err
+	}
+
+	data := response.Data
+	if nested, ok := data["data"]; ok {
+		var kv2 map[string]json.RawMessage
+		if json.Unmarshal(nested, &kv2) == nil && kv2 != nil {
+			data = kv2
+		}
+	}
+	raw, ok := data[field]
+	if !ok {
+		return "", fmt.Errorf("vault secret %s has no field %q", path, field)
+	}
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return "", fmt.Errorf("vault field %q is not a string", field)
+	}
+	return value, nil
+}
+
+// decryptAWS decrypts a ciphertext blob with AWS KMS. The blob names its key.
+func (r *Resolver) decryptAWS(ctx context.Context, ciphertext string) (string, error) {
+	if _, err := base64.StdEncoding.DecodeString(ciphertext); err != nil {
+		return "", fmt.Errorf("awskms reference must hold a base64 ciphertext")
+	}
+	if r.opts.AWSRegion == "" || r.opts.AWSAccessKey == "" || r.opts.AWSSecretKey == "" {
+		return "", fmt.Errorf("%w: set AWS_REGION, AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY", ErrNotConfigured)
+	}
+
+	endpoint := r.opts.AWSKMSEndpoint
+	if endpoint == "" {
+		endpoint = "https://kms." + r.opts.AWSRegion + ".amazonaws.com/"
+	}
+	body, err := json.Marshal(map[string]string{"CiphertextBlob": ciphertext})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "TrentService.Decrypt")
+	if r.opts.AWSSessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", r.opts.AWSSessionToken)
+	}
+	signAWSRequest(req, body, r.opts.AWSRegion, "kms", r.opts.AWSAccessKey, r.opts.AWSSecretKey, time.Now())
+
+	var response struct {
+		Plaintext string `json:"Plaintext"`
+	}
+	if err := r.do(req, &response); err != nil {
+		return "", err
+	}
+	return decodePlaintext(response.Plaintext)
+}
+
+// decryptGCP decrypts a ciphertext with a Google Cloud KMS key, given as
+// <key name>#<base64 ciphertext>. Without an access token the one of the
+// instance's service account is used.
+func (r *Resolver) decryptGCP(ctx context.Context, reference string) (string, error) {
+	key, ciphertext, ok := strings.Cut(reference, "#")
+	if !ok || !strings.HasPrefix(key, "projects/") || ciphertext == "" {
+		return "", fmt.Errorf("gcpkms reference must look like gcpkms://projects/.../cryptoKeys/<key>#<base64 ciphertext>")
+	}
+	token, err := r.gcpAccessToken(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	endpoint := r.opts.GCPKMSEndpoint
+	if endpoint == "" {
+		endpoint = defaultGCPKMSEndpoint
+	}
+	body, err := json.Marshal(map[string]string{"ciphertext": ciphertext})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(endpoint, "/")+"/v1/"+key+":decrypt", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	var response struct {
+		Plaintext string `json:"plaintext"`
+	}
+	if err := r.do(req, &response); err != nil {
+		return "", err
+	}
+	return decodePlaintext(response.Plaintext)
+}
+
+func (r *Resolver) gcpAccessToken(ctx context.Context) (string, error) {
+	if r.opts.GCPAccessToken != "" {
+		return r.opts.GCPAccessToken, nil
+	}
+
+	metadataURL := r.opts.GCPMetadataURL
+	if metadataURL == "" {
+		metadataURL = defaultGCPMetadataURL
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, metadataURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+
+	var response struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := r.do(req, &response); err != nil {
+		return "", fmt.Errorf("%w: set GCP_ACCESS_TOKEN or run on Google Cloud (%v)", ErrNotConfigured, err)
+	}
+	if response.AccessToken == "" {
+		return "", fmt.Errorf("%w: the metadata server returned no access token", ErrNotConfigured)
+	}
+	return response.AccessToken, nil
+}
+
+func (r *Resolver) do(req *http.Request, target interface{}) error {
+	resp, err := r.opts.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxSecretSize))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s", req.URL.Host, resp.Status)
+	}
+	return json.Unmarshal(body, target)
+}
+
+func decodePlaintext(encoded string) (string, error) {
+	plaintext, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode plaintext: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+// signAWSRequest adds an AWS Signature Version 4 Authorization header to req,
+// signing its host, content type, date, target and body.
+func signAWSRequest(req *http.Request, body []byte, region, service, accessKey, secretKey string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	dateStamp := now.UTC().Format("20060102")
+	credentialScope := dateStamp + "/" + region + "/" + service + "/aws4_request"
+	payloadHash := sha256.Sum256(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	headers := []string{"content-type", "host", "x-amz-date", "x-amz-target"}
+	values := map[string]string{
+		"content-type": req.Header.Get("Content-Type"),
+		"host":         req.URL.Host,
+		"x-amz-date":   amzDate,
+		"x-amz-target": req.Header.Get("X-Amz-Target"),
+	}
+	if token := req.Header.Get("X-Amz-Security-Token"); token != "" {
+		headers = append(headers, "x-amz-security-token")
+		values["x-amz-security-token"] = token
+	}
+	var canonicalHeaders strings.Builder
+	for _, name := range headers {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(values[name]) + "\n")
+	}
+	signedHeaders := strings.Join(headers, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+	hashedCanonicalRequest := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		credentialScope,
+		hex.EncodeToString(hashedCanonicalRequest[:]),
+	}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+secretKey), dateStamp)
+	for _, part := range []string{region, service, "aws4_request"} {
+		signingKey = hmacSHA256(signingKey, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+accessKey+"/"+credentialScope+", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+func canonicalQuery(query url.Values) string {
+	return strings.ReplaceAll(query.Encode(), "+", "%20")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
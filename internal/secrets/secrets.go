@@ -0,0 +1,178 @@
+// Package secrets resolves references to secrets kept outside the database and
+// the environment. A sensitive setting may hold one of these instead of the
+// secret itself:
+//
+//	file:///run/secrets/stripe_secret_key
+//	vault://secret/data/cms#stripe_secret_key
+//	awskms://<base64 ciphertext>
+//	gcpkms://projects/p/locations/l/keyRings/r/cryptoKeys/k#<base64 ciphertext>
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	schemeFile   = "file://"
+	schemeVault  = "vault://"
+	schemeAWSKMS = "awskms://"
+	schemeGCPKMS = "gcpkms://"
+
+	requestTimeout = 15 * time.Second
+	maxSecretSize  = 64 << 10
+)
+
+// ErrNotConfigured is returned for a reference to a backend without
+// credentials.
+var ErrNotConfigured = errors.New("secrets backend not configured")
+
+// Options holds the credentials of the backends. The endpoints default to the
+// public services. File references must point into FileDir; without it they
+// are refused.
+type Options struct {
+	FileDir string
+
+	VaultAddr      string
+	VaultToken     string
+	VaultNamespace string
+
+	AWSRegion       string
+	AWSAccessKey    string
+	AWSSecretKey    string
+	AWSSessionToken string
+	AWSKMSEndpoint  string
+
+	GCPAccessToken string
+	GCPKMSEndpoint string
+	GCPMetadataURL string
+
+	HTTPClient *http.Client
+}
+
+// Resolver turns references into the secrets they point to. Each reference is
+// resolved once; rotating a secret takes a restart.
+type Resolver struct {
+	opts Options
+
+	mu       sync.Mutex
+	resolved map[string]string
+}
+
+func NewResolver(opts Options) *Resolver {
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = &http.Client{Timeout: requestTimeout}
+	}
+	return &Resolver{opts: opts, resolved: make(map[string]string)}
+}
+
+// IsReference reports whether value points to a secret rather than being one.
+func IsReference(value string) bool {
+	value = strings.TrimSpace(value)
+	for _, scheme := range []string{schemeFile, schemeVault, schemeAWSKMS, schemeGCPKMS} {
+		if strings.HasPrefix(value, scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolve returns the secret value points to, or value itself when it is not a
+// reference.
+func (r *Resolver) Resolve(ctx context.Context, value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if !IsReference(value) {
+		return value, nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if secret, ok := r.resolved[value]; ok {
+		return secret, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	var (
+		secret string
+		err    error
+	)
+	switch {
+	case strings.HasPrefix(value, schemeFile):
+		secret, err = r.readFile(strings.TrimPrefix(value, schemeFile))
+	case strings.HasPrefix(value, schemeVault):
+		secret, err = r.readVault(ctx, strings.TrimPrefix(value, schemeVault))
+	case strings.HasPrefix(value, schemeAWSKMS):
+		secret, err = r.decryptAWS(ctx, strings.TrimPrefix(value, schemeAWSKMS))
+	case strings.HasPrefix(value, schemeGCPKMS):
+		secret, err = r.decryptGCP(ctx, strings.TrimPrefix(value, schemeGCPKMS))
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", describe(value), err)
+	}
+	secret = strings.TrimSpace(secret)
+	r.resolved[value] = secret
+	return secret, nil
+}
+
+// describe names a reference in errors without the ciphertext of KMS
+// references.
+func describe(reference string) string {
+	switch {
+	case strings.HasPrefix(reference, schemeAWSKMS):
+		return "AWS KMS secret"
+	case strings.HasPrefix(reference, schemeGCPKMS):
+		key, _, _ := strings.Cut(strings.TrimPrefix(reference, schemeGCPKMS), "#")
+		return "Google Cloud KMS secret of " + key
+	}
+	return reference
+}
+
+// readFile reads the secret at path, which must resolve, symbolic links
+// included, to a file inside the configured directory.
+func (r *Resolver) readFile(path string) (string, error) {
+	if path == "" {
+		return "", errors.New("file path is empty")
+	}
+	if r.opts.FileDir == "" {
+		return "", fmt.Errorf("%w: set SECRETS_FILE_DIR", ErrNotConfigured)
+	}
+	if !filepath.IsAbs(path) || filepath.Clean(path) != path {
+		return "", errors.New("file path must be absolute and clean")
+	}
+	dir, err := filepath.EvalSymlinks(r.opts.FileDir)
+	if err != nil {
+		return "", err
+	}
+	target, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", err
+	}
+	if rel, err := filepath.Rel(dir, target); err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("file is outside %s", r.opts.FileDir)
+	}
+
+	file, err := os.Open(target)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxSecretSize+1))
+	if err != nil {
+		return "", err
+	}
+	if len(data) > maxSecretSize {
+		return "", fmt.Errorf("secret is larger than %d bytes", maxSecretSize)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,180 @@
+package secrets
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+
+	"constructor-script-backend/internal/models"
+)
+
+func TestResolveBackends(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		switch {
+		case r.URL.Path == "/v1/secret/data/cms":
+			if r.Header.Get("X-Vault-Token") != "vault-token" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.Write([]byte(`{"data":{"data":{"smtp_password":"from-vault"},"metadata":{"version":3}}}`))
+		case r.URL.Path == "/aws" && r.Header.Get("X-Amz-Target") == "TrentService.Decrypt":
+			auth := r.Header.Get("Authorization")
+			if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/") || !strings.Contains(auth, "/eu-west-1/kms/aws4_request") {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			if body["CiphertextBlob"] != "c2VhbGVk" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]string{"Plaintext": base64.StdEncoding.EncodeToString([]byte("from-aws"))})
+		case r.URL.Path == "/token":
+			w.Write([]byte(`{"access_token":"gcp-token"}`))
+		case r.URL.Path == "/v1/projects/p/locations/global/keyRings/r/cryptoKeys/k:decrypt":
+			if r.Header.Get("Authorization") != "Bearer gcp-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]string{"plaintext": base64.StdEncoding.EncodeToString([]byte("from-gcp"))})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "stripe")
+	if err := os.WriteFile(path, []byte("from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	resolver := NewResolver(Options{
+		FileDir:        dir,
+		VaultAddr:      server.URL,
+		VaultToken:     "vault-token",
+		AWSRegion:      "eu-west-1",
+		AWSAccessKey:   "AKID",
+		AWSSecretKey:   "secret",
+		AWSKMSEndpoint: server.URL + "/aws",
+		GCPKMSEndpoint: server.URL,
+		GCPMetadataURL: server.URL + "/token",
+	})
+	cases := map[string]string{
+		"sk_live_plain":                         "sk_live_plain",
+		"file://" + path:                        "from-file",
+		"vault://secret/data/cms#smtp_password": "from-vault",
+		"awskms://c2VhbGVk":                     "from-aws",
+		"gcpkms://projects/p/locations/global/keyRings/r/cryptoKeys/k#c2VhbGVk": "from-gcp",
+	}
+	for reference, want := range cases {
+		got, err := resolver.Resolve(context.Background(), reference)
+		if err != nil || got != want {
+			t.Fatalf("Resolve(%q) = %q, %v; want %q", reference, got, err, want)
+		}
+	}
+
+	before := calls
+	if _, err := resolver.Resolve(context.Background(), "vault://secret/data/cms#smtp_password"); err != nil || calls != before {
+		t.Fatalf("expected a resolved reference to be reused, got %v after %d calls", err, calls-before)
+	}
+	if _, err := resolver.Resolve(context.Background(), "vault://secret/data/cms#missing"); err == nil {
+		t.Fatal("expected a missing vault field to fail")
+	}
+	if _, err := NewResolver(Options{}).Resolve(context.Background(), "vault://secret/data/cms#smtp_password"); !errors.Is(err, ErrNotConfigured) {
+		t.Fatalf("expected an unconfigured backend to be reported, got %v", err)
+	}
+}
+
+func TestResolveFileOutsideDir(t *testing.T) {
+	dir := t.TempDir()
+	outside := filepath.Join(t.TempDir(), "environ")
+	if err := os.WriteFile(outside, []byte("SECRET=1"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(dir, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	resolver := NewResolver(Options{FileDir: dir})
+	for _, reference := range []string{
+		"file://" + outside,
+		"file://" + filepath.Join(dir, "link"),
+		"file://" + dir + "/../" + filepath.Base(filepath.Dir(outside)) + "/environ",
+		"file://" + dir,
+	} {
+		if _, err := resolver.Resolve(context.Background(), reference); err == nil {
+			t.Fatalf("expected %q to be refused", reference)
+		}
+	}
+	if _, err := NewResolver(Options{}).Resolve(context.Background(), "file://"+outside); !errors.Is(err, ErrNotConfigured) {
+		t.Fatalf("expected file references to need a directory, got %v", err)
+	}
+}
+
+type memorySettingRepository struct {
+	values map[string]string
+}
+
+func (r *memorySettingRepository) Get(key string) (*models.Setting, error) {
+	value, ok := r.values[key]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &models.Setting{Key: key, Value: value}, nil
+}
+
+func (r *memorySettingRepository) Set(key, value string) error {
+	r.values[key] = value
+	return nil
+}
+
+func (r *memorySettingRepository) Delete(key string) error {
+	delete(r.values, key)
+	return nil
+}
+
+func TestSettingRepository(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "smtp")
+	if err := os.WriteFile(path, []byte("hunter2"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	stored := &memorySettingRepository{values: map[string]string{
+		"smtp.password": "file://" + path,
+		"site.footer":   "file://" + path,
+	}}
+	repo := NewSettingRepository(stored, NewResolver(Options{FileDir: dir}), []string{"smtp.password"})
+
+	if setting, err := repo.Get("smtp.password"); err != nil || setting.Value != "hunter2" {
+		t.Fatalf("expected the reference to be resolved, got %+v %v", setting, err)
+	}
+	if setting, _ := repo.Get("site.footer"); setting.Value != "file://"+path {
+		t.Fatalf("expected other settings to be read as stored, got %q", setting.Value)
+	}
+
+	if err := repo.Set("smtp.password", "hunter2"); err != nil || stored.values["smtp.password"] != "file://"+path {
+		t.Fatalf("expected saving the resolved secret to keep the reference, got %q", stored.values["smtp.password"])
+	}
+	if err := repo.Set("smtp.password", "file://"+path); err != nil {
+		t.Fatalf("expected the stored reference to be saved unchanged, got %v", err)
+	}
+	if err := repo.Set("smtp.password", "file:///proc/self/environ"); !errors.Is(err, ErrReferenceNotAllowed) || stored.values["smtp.password"] != "file://"+path {
+		t.Fatalf("expected a new reference to be refused, got %v", err)
+	}
+	if err := repo.Set("smtp.password", "changed"); err != nil || stored.values["smtp.password"] != "changed" {
+		t.Fatalf("expected a new secret to replace the reference, got %q", stored.values["smtp.password"])
+	}
+}
@@ -0,0 +1,80 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/repository"
+	"constructor-script-backend/pkg/logger"
+)
+
+// ErrReferenceNotAllowed is returned when a setting is saved with a new secret
+// reference.
+var ErrReferenceNotAllowed = errors.New("secret references can only be set in the environment")
+
+// SettingRepository resolves the references stored in credential settings, so
+// services read the secret while the settings table only keeps the reference.
+type SettingRepository struct {
+	repository.SettingRepository
+	resolver *Resolver
+	keys     map[string]bool
+}
+
+// NewSettingRepository wraps repo, resolving references in the settings named
+// by keys.
+func NewSettingRepository(repo repository.SettingRepository, resolver *Resolver, keys []string) *SettingRepository {
+	wrapped := &SettingRepository{SettingRepository: repo, resolver: resolver, keys: make(map[string]bool, len(keys))}
+	for _, key := range keys {
+		wrapped.keys[key] = true
+	}
+	return wrapped
+}
+
+func (r *SettingRepository) Get(key string) (*models.Setting, error) {
+	setting, err := r.SettingRepository.Get(key)
+	if err != nil || !r.keys[key] || !IsReference(setting.Value) {
+		return setting, err
+	}
+
+	secret, err := r.resolver.Resolve(context.Background(), setting.Value)
+	if err != nil {
+		return nil, err
+	}
+	resolved := *setting
+	resolved.Value = secret
+	return &resolved, nil
+}
+
+// Set stores value. Services that save a credential they read back unchanged
+// would replace its reference with the secret, so that case keeps the
+// reference. New references are refused: they are only taken from the
+// environment, as an administrator could otherwise read back any file or
+// secret the server can reach.
+func (r *SettingRepository) Set(key, value string) error {
+	if r.keys[key] {
+		stored, err := r.SettingRepository.Get(key)
+		if IsReference(value) && (err != nil || strings.TrimSpace(stored.Value) != strings.TrimSpace(value)) {
+			return ErrReferenceNotAllowed
+		}
+		if err == nil && IsReference(stored.Value) {
+			if secret, err := r.resolver.Resolve(context.Background(), stored.Value); err == nil && secret == strings.TrimSpace(value) {
+				return nil
+			}
+		}
+	}
+	return r.SettingRepository.Set(key, value)
+}
+
+// Preload resolves the stored references, so a broken one is reported at
+// startup rather than when the credential is first needed.
+func (r *SettingRepository) Preload() {
+	for key := range r.keys {
+		if _, err := r.Get(key); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Error(err, "Failed to resolve secret setting", map[string]interface{}{"key": key})
+		}
+	}
+}
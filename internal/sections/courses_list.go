@@ -114,9 +114,9 @@ func renderCatalogCourses(ctx RenderContext, prefix string, section models.Secti
 		return `<p class="` + emptyClass + `">No courses available yet. Check back soon!</p>`
 	}
 
-	tmpl, err := ctx.CloneTemplates()
+	tmpl, err := ctx.Templates()
 	if err != nil {
-		logger.Error(err, "Failed to clone templates for course list section", map[string]interface{}{"section_id": section.ID})
+		logger.Error(err, "Failed to load templates for course list section", map[string]interface{}{"section_id": section.ID})
 		return `<p class="` + emptyClass + `">Unable to display courses at the moment.</p>`
 	}
 
@@ -151,9 +151,9 @@ func renderOwnedCourses(ctx RenderContext, prefix string, section models.Section
 		return `<p class="` + emptyClass + `">` + template.HTMLEscapeString(emptyMsg) + `</p>`
 	}
 
-	tmpl, err := ctx.CloneTemplates()
+	tmpl, err := ctx.Templates()
 	if err != nil {
-		logger.Error(err, "Failed to clone templates for owned courses section", map[string]interface{}{"section_id": section.ID})
+		logger.Error(err, "Failed to load templates for owned courses section", map[string]interface{}{"section_id": section.ID})
 		return `<p class="` + emptyClass + `">Unable to display your courses at the moment.</p>`
 	}
 
@@ -26,6 +26,7 @@ func RegisterDefaults(reg *Registry) {
 	RegisterImage(reg)
 	RegisterImageGroup(reg)
 	RegisterFileGroup(reg)
+	RegisterEmbed(reg)
 	RegisterList(reg)
 	RegisterSearch(reg)
 	RegisterFeatures(reg)
@@ -35,6 +36,7 @@ func RegisterDefaults(reg *Registry) {
 	// Profile sections
 	RegisterProfileAccount(reg)
 	RegisterProfileSecurity(reg)
+	RegisterProfileNotifications(reg)
 	RegisterProfileCourses(reg)
 
 	// Dynamic list sections
@@ -55,10 +57,12 @@ func RegisterDefaultsWithMetadata(reg *RegistryWithMetadata) {
 	RegisterImage(reg.Registry)
 	RegisterImageGroup(reg.Registry)
 	RegisterFileGroup(reg.Registry)
+	RegisterEmbed(reg.Registry)
 	RegisterList(reg.Registry)
 	RegisterSearch(reg.Registry)
 	RegisterProfileAccount(reg.Registry)
 	RegisterProfileSecurity(reg.Registry)
+	RegisterProfileNotifications(reg.Registry)
 	RegisterProfileCourses(reg.Registry)
 	RegisterContactWithMetadata(reg)
 
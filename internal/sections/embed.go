@@ -0,0 +1,108 @@
+package sections
+
+import (
+	"fmt"
+	"html/template"
+	"strconv"
+	"strings"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/service"
+)
+
+// EmbedResolver is implemented by render contexts that can resolve external links
+// through oEmbed.
+type EmbedResolver interface {
+	ResolveEmbed(url string) (*service.Embed, bool)
+}
+
+// RegisterEmbed registers the default embed renderer on the provided registry.
+func RegisterEmbed(reg *Registry) {
+	if reg == nil {
+		return
+	}
+	reg.RegisterSafe("embed", renderEmbed)
+}
+
+// renderEmbed renders YouTube and Vimeo links as a click-to-play placeholder that only
+// loads the provider's iframe after the visitor asks for it, and Twitter/X posts as a
+// plain quote. Links that cannot be resolved fall back to a link card.
+func renderEmbed(ctx RenderContext, prefix string, elem models.SectionElement) (string, []string) {
+	content := sectionContent(elem)
+	url := strings.TrimSpace(getString(content, "url"))
+	caption := strings.TrimSpace(getString(content, "caption"))
+	if url == "" {
+		return "", nil
+	}
+
+	var embed *service.Embed
+	if resolver, ok := ctx.(EmbedResolver); ok {
+		embed, _ = resolver.ResolveEmbed(url)
+	}
+
+	block := fmt.Sprintf("%s__embed", prefix)
+	escapedURL := template.HTMLEscapeString(url)
+
+	var sb strings.Builder
+	if embed == nil {
+		sb.WriteString(`<figure class="` + block + ` ` + block + `--link">`)
+		sb.WriteString(`<a class="` + block + `-link" href="` + escapedURL + `" rel="noopener nofollow" target="_blank">` + escapedURL + `</a>`)
+		writeEmbedCaption(&sb, ctx, block, caption)
+		sb.WriteString(`</figure>`)
+		return sb.String(), nil
+	}
+
+	provider := template.HTMLEscapeString(embed.Provider)
+	title := embed.Title
+	if title == "" {
+		title = embed.ProviderName
+	}
+	escapedTitle := template.HTMLEscapeString(title)
+
+	sb.WriteString(`<figure class="` + block + ` ` + block + `--` + provider + `">`)
+
+	if embed.Type == "video" && embed.PlayerURL != "" {
+		ratio := "16 / 9"
+		if embed.Width > 0 && embed.Height > 0 {
+			ratio = strconv.Itoa(embed.Width) + " / " + strconv.Itoa(embed.Height)
+		}
+		sb.WriteString(`<div class="` + block + `-frame" style="aspect-ratio: ` + ratio + `" data-embed data-embed-src="` + template.HTMLEscapeString(embed.PlayerURL) + `" data-embed-title="` + escapedTitle + `">`)
+		if embed.ThumbnailURL != "" {
+			sb.WriteString(`<img class="` + block + `-thumbnail" src="` + template.HTMLEscapeString(embed.ThumbnailURL) + `" alt="" loading="lazy" referrerpolicy="no-referrer" />`)
+		}
+		sb.WriteString(`<button type="button" class="` + block + `-play" data-embed-play aria-label="Play video: ` + escapedTitle + `"></button>`)
+		sb.WriteString(`<p class="` + block + `-notice">Playing this video loads content from ` + template.HTMLEscapeString(embed.ProviderName) + `. <a href="` + escapedURL + `" rel="noopener nofollow" target="_blank">Watch on ` + template.HTMLEscapeString(embed.ProviderName) + `</a></p>`)
+		sb.WriteString(`</div>`)
+		if caption == "" {
+			caption = template.HTMLEscapeString(embed.Title)
+		}
+		writeEmbedCaption(&sb, ctx, block, caption)
+		sb.WriteString(`</figure>`)
+		return sb.String(), []string{"/static/js/embed.js"}
+	}
+
+	sb.WriteString(`<blockquote class="` + block + `-quote" cite="` + escapedURL + `">`)
+	for _, line := range strings.Split(embed.Text, "\n") {
+		sb.WriteString(`<p>` + template.HTMLEscapeString(line) + `</p>`)
+	}
+	sb.WriteString(`<footer class="` + block + `-source">`)
+	if embed.AuthorName != "" {
+		author := template.HTMLEscapeString(embed.AuthorName)
+		if embed.AuthorURL != "" {
+			author = `<a href="` + template.HTMLEscapeString(embed.AuthorURL) + `" rel="noopener nofollow" target="_blank">` + author + `</a>`
+		}
+		sb.WriteString(author + ` · `)
+	}
+	sb.WriteString(`<a href="` + escapedURL + `" rel="noopener nofollow" target="_blank">View on ` + template.HTMLEscapeString(embed.ProviderName) + `</a>`)
+	sb.WriteString(`</footer></blockquote>`)
+	writeEmbedCaption(&sb, ctx, block, caption)
+	sb.WriteString(`</figure>`)
+	return sb.String(), nil
+}
+
+func writeEmbedCaption(sb *strings.Builder, ctx RenderContext, block, caption string) {
+	if caption == "" {
+		return
+	}
+	sb.WriteString(`<figcaption class="` + block + `-caption">` + ctx.SanitizeHTML(caption) + `</figcaption>`)
+}
@@ -6,8 +6,24 @@ import (
 	"strings"
 
 	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/service"
 )
 
+// imageSizes is the sizes hint used for section images, which span the content column.
+const imageSizes = "(max-width: 768px) 100vw, 768px"
+
+// ResponsiveImageProvider is implemented by render contexts that know the generated
+// variants of uploaded images.
+type ResponsiveImageProvider interface {
+	ResponsiveImage(url string) (service.ResponsiveImage, bool)
+}
+
+// ImageMetadataProvider is implemented by render contexts that can look up the alt text
+// and focal point stored for an image in the media library.
+type ImageMetadataProvider interface {
+	ImageMetadata(url string) (service.ImageMetadata, bool)
+}
+
 // RegisterImage registers the default image renderer on the provided registry.
 func RegisterImage(reg *Registry) {
 	if reg == nil {
@@ -31,7 +47,7 @@ func renderImage(ctx RenderContext, prefix string, elem models.SectionElement) (
 
 	var sb strings.Builder
 	sb.WriteString(`<figure class="` + figureClass + `">`)
-	sb.WriteString(`<img class="` + imageClass + `" src="` + template.HTMLEscapeString(url) + `" alt="` + template.HTMLEscapeString(alt) + `" />`)
+	sb.WriteString(responsiveImageHTML(ctx, imageClass, url, alt))
 	if caption = strings.TrimSpace(caption); caption != "" {
 		sanitizedCaption := ctx.SanitizeHTML(caption)
 		captionClass := fmt.Sprintf("%s__image-caption", prefix)
@@ -41,3 +57,49 @@ func renderImage(ctx RenderContext, prefix string, elem models.SectionElement) (
 
 	return sb.String(), nil
 }
+
+// responsiveImageHTML renders an <img>, wrapped in a <picture> with srcsets for the
+// generated variants when the upload has any. An empty alt falls back to the media
+// library's alt text, and a stored focal point positions the image within its box.
+func responsiveImageHTML(ctx RenderContext, class, url, alt string) string {
+	var style string
+	if provider, ok := ctx.(ImageMetadataProvider); ok {
+		if metadata, ok := provider.ImageMetadata(url); ok {
+			if strings.TrimSpace(alt) == "" {
+				alt = metadata.AltText
+			}
+			if metadata.FocalPoint != nil {
+				style = ` style="object-position: ` + metadata.FocalPoint.ObjectPosition() + `"`
+			}
+		}
+	}
+
+	img := `<img class="` + class + `" src="` + template.HTMLEscapeString(url) + `" alt="` + template.HTMLEscapeString(alt) + `"` + style
+
+	provider, ok := ctx.(ResponsiveImageProvider)
+	if !ok {
+		return img + ` />`
+	}
+	responsive, ok := provider.ResponsiveImage(url)
+	if !ok {
+		return img + ` />`
+	}
+
+	sizes := ` sizes="` + imageSizes + `"`
+	if responsive.Srcset != "" {
+		img += ` srcset="` + template.HTMLEscapeString(responsive.Srcset) + `"` + sizes
+	}
+	img += ` loading="lazy" />`
+	if len(responsive.Sources) == 0 {
+		return img
+	}
+
+	var sb strings.Builder
+	sb.WriteString(`<picture>`)
+	for _, source := range responsive.Sources {
+		sb.WriteString(`<source type="` + template.HTMLEscapeString(source.Type) + `" srcset="` + template.HTMLEscapeString(source.Srcset) + `"` + sizes + ` />`)
+	}
+	sb.WriteString(img)
+	sb.WriteString(`</picture>`)
+	return sb.String()
+}
@@ -48,7 +48,7 @@ func renderImageGroup(ctx RenderContext, prefix string, elem models.SectionEleme
 			captionClass := fmt.Sprintf("%s__image-group-caption", prefix)
 
 			sb.WriteString(`<figure class="` + itemClass + `">`)
-			sb.WriteString(`<img class="` + imgClass + `" src="` + template.HTMLEscapeString(url) + `" alt="` + template.HTMLEscapeString(alt) + `" />`)
+			sb.WriteString(responsiveImageHTML(ctx, imgClass, url, alt))
 			if caption = strings.TrimSpace(caption); caption != "" {
 				sb.WriteString(`<figcaption class="` + captionClass + `">` + ctx.SanitizeHTML(caption) + `</figcaption>`)
 			}
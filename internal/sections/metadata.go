@@ -78,6 +78,20 @@ func (r *RegistryWithMetadata) RegisterWithMetadata(desc *SectionDescriptor) err
 	return nil
 }
 
+// Unregister removes both the renderer and the metadata for a section type.
+func (r *RegistryWithMetadata) Unregister(sectionType string) {
+	if r == nil {
+		return
+	}
+
+	sectionType = strings.TrimSpace(strings.ToLower(sectionType))
+	r.Registry.Unregister(sectionType)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.descriptors, sectionType)
+}
+
 // GetMetadata retrieves metadata for a section type.
 func (r *RegistryWithMetadata) GetMetadata(sectionType string) (SectionMetadata, bool) {
 	if r == nil {
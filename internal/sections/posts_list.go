@@ -86,9 +86,9 @@ func renderPostsList(ctx RenderContext, prefix string, elem models.SectionElemen
 		return `<p class="` + emptyClass + `">No posts available yet. Check back soon!</p>`, nil
 	}
 
-	tmpl, err := ctx.CloneTemplates()
+	tmpl, err := ctx.Templates()
 	if err != nil {
-		logger.Error(err, "Failed to clone templates for post list section", map[string]interface{}{"section_id": section.ID})
+		logger.Error(err, "Failed to load templates for post list section", map[string]interface{}{"section_id": section.ID})
 		return `<p class="` + emptyClass + `">Unable to display posts at the moment.</p>`, nil
 	}
 
@@ -0,0 +1,86 @@
+package sections
+
+import (
+	"html/template"
+	"strings"
+
+	"constructor-script-backend/internal/models"
+)
+
+const (
+	profileNotificationsDefaultTitle       = "Notifications"
+	profileNotificationsDefaultDescription = "Choose what we tell you about and where."
+	profileNotificationsDefaultButton      = "Save preferences"
+)
+
+// RegisterProfileNotifications registers the notification preferences form renderer.
+func RegisterProfileNotifications(reg *Registry) {
+	if reg == nil {
+		return
+	}
+
+	reg.RegisterSafe("profile_notifications", renderProfileNotifications)
+}
+
+// renderProfileNotifications renders the frame of the preferences matrix. The
+// rows are loaded from the action URL in the browser, so new notification
+// events show up without editing the page.
+func renderProfileNotifications(ctx RenderContext, prefix string, elem models.SectionElement) (string, []string) {
+	content := sectionContent(elem)
+
+	title := strings.TrimSpace(getString(content, "title"))
+	if title == "" {
+		title = profileNotificationsDefaultTitle
+	}
+
+	description := strings.TrimSpace(getString(content, "description"))
+	if description == "" {
+		description = profileNotificationsDefaultDescription
+	}
+
+	buttonLabel := strings.TrimSpace(getString(content, "button_label"))
+	if buttonLabel == "" {
+		buttonLabel = profileNotificationsDefaultButton
+	}
+
+	action := strings.TrimSpace(getString(content, "action"))
+	if action == "" {
+		action = "/api/v1/profile/notifications"
+	}
+
+	var sb strings.Builder
+	sb.WriteString(`<section class="profile-card" aria-labelledby="notifications-title">`)
+	sb.WriteString(`<header class="profile-card__header">`)
+	sb.WriteString(`<h2 id="notifications-title" class="profile-card__title">`)
+	sb.WriteString(template.HTMLEscapeString(title))
+	sb.WriteString(`</h2>`)
+	sb.WriteString(`<p class="profile-card__description">`)
+	sb.WriteString(template.HTMLEscapeString(description))
+	sb.WriteString(`</p>`)
+	sb.WriteString(`</header>`)
+
+	sb.WriteString(`<div class="profile__alert" id="profile-notifications-alert" role="alert" hidden></div>`)
+
+	sb.WriteString(`<form id="notifications-form" class="profile-form" method="post" data-action="`)
+	sb.WriteString(template.HTMLEscapeString(action))
+	sb.WriteString(`" novalidate>`)
+
+	sb.WriteString(`<table class="profile-notifications">`)
+	sb.WriteString(`<thead><tr>`)
+	sb.WriteString(`<th scope="col">Notification</th>`)
+	sb.WriteString(`<th scope="col" data-channel="email">Email</th>`)
+	sb.WriteString(`<th scope="col" data-channel="in_app">On site</th>`)
+	sb.WriteString(`</tr></thead>`)
+	sb.WriteString(`<tbody data-notification-preferences></tbody>`)
+	sb.WriteString(`</table>`)
+
+	sb.WriteString(`<button type="submit" class="button button--primary">`)
+	sb.WriteString(template.HTMLEscapeString(buttonLabel))
+	sb.WriteString(`</button>`)
+
+	sb.WriteString(`</form>`)
+
+	sb.WriteString(`</section>`)
+
+	return sb.String(), nil
+}
@@ -23,8 +23,9 @@ type ServiceProvider interface {
 type RenderContext interface {
 	// SanitizeHTML should clean potentially unsafe markup before rendering.
 	SanitizeHTML(input string) string
-	// CloneTemplates returns an isolated template instance for rendering complex sections.
-	CloneTemplates() (*template.Template, error)
+	// Templates returns the compiled theme templates for rendering complex sections.
+	// The set is shared between renders: execute templates from it, never parse into it.
+	Templates() (*template.Template, error)
 	// Services returns access to application services for advanced renderers.
 	Services() ServiceProvider
 }
@@ -103,6 +104,18 @@ func (r *Registry) Get(sectionType string) (Renderer, bool) {
 	return renderer, ok
 }
 
+// Unregister removes the renderer associated with the section type.
+func (r *Registry) Unregister(sectionType string) {
+	if r == nil {
+		return
+	}
+
+	sectionType = strings.TrimSpace(strings.ToLower(sectionType))
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.renderers, sectionType)
+}
+
 // Clone creates a copy of the registry with the same renderer mappings.
 func (r *Registry) Clone() *Registry {
 	if r == nil {
@@ -89,9 +89,9 @@ func renderSearch(ctx RenderContext, prefix string, elem models.SectionElement)
 		"Result":      nil,
 	}
 
-	tmpl, err := ctx.CloneTemplates()
+	tmpl, err := ctx.Templates()
 	if err != nil {
-		logger.Error(err, "Failed to clone templates for search section", map[string]interface{}{"element_id": elem.ID})
+		logger.Error(err, "Failed to load templates for search section", map[string]interface{}{"element_id": elem.ID})
 		return "", nil
 	}
 
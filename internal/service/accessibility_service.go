@@ -0,0 +1,174 @@
+package service
+
+import (
+	"strings"
+	"time"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/repository"
+)
+
+// ContentReference identifies the post or page an image appears on.
+type ContentReference struct {
+	Type    string `json:"type"`
+	ID      uint   `json:"id"`
+	Title   string `json:"title"`
+	Section string `json:"section,omitempty"`
+}
+
+// LibraryImageIssue is an image in the media library without alt text.
+type LibraryImageIssue struct {
+	URL      string             `json:"url"`
+	Filename string             `json:"filename"`
+	UsedIn   []ContentReference `json:"used_in"`
+}
+
+// ContentImageIssue is an image section element whose image has no alt text, neither
+// in the element nor in the media library.
+type ContentImageIssue struct {
+	URL    string           `json:"url"`
+	Source ContentReference `json:"source"`
+}
+
+// ImageAccessibilityReport lists images that screen readers cannot describe.
+type ImageAccessibilityReport struct {
+	GeneratedAt   time.Time           `json:"generated_at"`
+	LibraryImages int                 `json:"library_images"`
+	Library       []LibraryImageIssue `json:"library"`
+	Content       []ContentImageIssue `json:"content"`
+}
+
+// AccessibilityService audits site content for accessibility problems.
+type AccessibilityService struct {
+	uploads  *UploadService
+	postRepo repository.PostRepository
+	pageRepo repository.PageRepository
+}
+
+func NewAccessibilityService(uploads *UploadService, postRepo repository.PostRepository, pageRepo repository.PageRepository) *AccessibilityService {
+	return &AccessibilityService{uploads: uploads, postRepo: postRepo, pageRepo: pageRepo}
+}
+
+// ImageReport flags media library images without alt text, and image elements in posts
+// and pages that render without any alt text.
+func (s *AccessibilityService) ImageReport() (*ImageAccessibilityReport, error) {
+	if s == nil || s.uploads == nil {
+		return nil, errUploadServiceMissing
+	}
+
+	images, err := s.uploads.ListImages()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ImageAccessibilityReport{
+		GeneratedAt:   time.Now().UTC(),
+		LibraryImages: len(images),
+		Library:       []LibraryImageIssue{},
+		Content:       []ContentImageIssue{},
+	}
+
+	usages := make(map[string][]ContentReference)
+	visit := func(source ContentReference, sections models.PostSections) {
+		for _, section := range sections {
+			ref := source
+			ref.Section = section.Title
+			for _, elem := range section.Elements {
+				for _, image := range sectionElementImages(elem) {
+					filename := s.uploads.managedFilename(image.url)
+					if filename != "" {
+						usages[filename] = appendReference(usages[filename], ref)
+					}
+					if image.alt != "" {
+						continue
+					}
+					if metadata, ok := s.uploads.ImageMetadata(image.url); ok && metadata.AltText != "" {
+						continue
+					}
+					report.Content = append(report.Content, ContentImageIssue{URL: image.url, Source: ref})
+				}
+			}
+		}
+	}
+
+	if s.pageRepo != nil {
+		pages, err := s.pageRepo.GetAllAdmin()
+		if err != nil {
+			return nil, err
+		}
+		for _, page := range pages {
+			visit(ContentReference{Type: "page", ID: page.ID, Title: page.Title}, page.Sections)
+		}
+	}
+	if s.postRepo != nil {
+		posts, _, err := s.postRepo.GetAll(0, -1, nil, nil, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, post := range posts {
+			visit(ContentReference{Type: "post", ID: post.ID, Title: post.Title}, post.Sections)
+		}
+	}
+
+	for _, image := range images {
+		if image.AltText != "" {
+			continue
+		}
+		used := usages[image.Filename]
+		if used == nil {
+			used = []ContentReference{}
+		}
+		report.Library = append(report.Library, LibraryImageIssue{URL: image.URL, Filename: image.Filename, UsedIn: used})
+	}
+
+	return report, nil
+}
+
+type sectionImage struct {
+	url string
+	alt string
+}
+
+// sectionElementImages returns the images rendered by image and image_group elements.
+func sectionElementImages(elem models.SectionElement) []sectionImage {
+	content, ok := elem.Content.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	read := func(values map[string]interface{}) (sectionImage, bool) {
+		url, _ := values["url"].(string)
+		alt, _ := values["alt"].(string)
+		image := sectionImage{url: strings.TrimSpace(url), alt: strings.TrimSpace(alt)}
+		return image, image.url != ""
+	}
+
+	switch elem.Type {
+	case "image":
+		if image, ok := read(content); ok {
+			return []sectionImage{image}
+		}
+	case "image_group":
+		items, _ := content["images"].([]interface{})
+		images := make([]sectionImage, 0, len(items))
+		for _, item := range items {
+			values, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if image, ok := read(values); ok {
+				images = append(images, image)
+			}
+		}
+		return images
+	}
+	return nil
+}
+
+func appendReference(refs []ContentReference, ref ContentReference) []ContentReference {
+	for _, existing := range refs {
+		if existing == ref {
+			return refs
+		}
+	}
+	return append(refs, ref)
+}
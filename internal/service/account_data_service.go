@@ -0,0 +1,480 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"constructor-script-backend/internal/authorization"
+	"constructor-script-backend/internal/background"
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/repository"
+	"constructor-script-backend/pkg/logger"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	// SettingKeyAccountDeletion stores the account deletion settings in the
+	// settings repository.
+	SettingKeyAccountDeletion = "privacy.account_deletion"
+
+	dataExportLifetime = 7 * 24 * time.Hour
+	// dataExportStale is how long an export may stay pending before the user
+	// can ask for another, in case the instance building it went away.
+	dataExportStale        = time.Hour
+	dataExportTimeout      = 10 * time.Minute
+	dataExportListLimit    = 20
+	accountDeletionListMax = 200
+	maxDeletionReason      = 2000
+)
+
+var (
+	ErrAccountDataUnavailable  = errors.New("account data service not configured")
+	ErrDataExportInProgress    = errors.New("a data export is already being prepared")
+	ErrDataExportNotReady      = errors.New("data export is not ready")
+	ErrIncorrectPassword       = errors.New("incorrect password")
+	ErrAdminAccountDeletion    = errors.New("administrator accounts cannot be deleted this way")
+	ErrAccountDeletionPending  = errors.New("account deletion has already been requested")
+	ErrAccountDeletionReviewed = errors.New("account deletion request has already been reviewed")
+)
+
+type AccountDataValidationError struct {
+	Reason string
+}
+
+func (e *AccountDataValidationError) Error() string {
+	if e == nil {
+		return ""
+	}
+	return e.Reason
+}
+
+func accountDataValidationErrorf(format string, args ...interface{}) error {
+	return &AccountDataValidationError{Reason: fmt.Sprintf(format, args...)}
+}
+
+// AccountDataService lets users download what the site stores about them and
+// delete their account.
+type AccountDataService struct {
+	exports       repository.DataExportRepository
+	deletions     repository.AccountDeletionRepository
+	userRepo      repository.UserRepository
+	settingRepo   repository.SettingRepository
+	scheduler     *background.Scheduler
+	notifications *NotificationService
+	now           func() time.Time
+}
+
+func NewAccountDataService(
+	exports repository.DataExportRepository,
+	deletions repository.AccountDeletionRepository,
+	userRepo repository.UserRepository,
+	settingRepo repository.SettingRepository,
+	scheduler *background.Scheduler,
+) *AccountDataService {
+	return &AccountDataService{
+		exports:     exports,
+		deletions:   deletions,
+		userRepo:    userRepo,
+		settingRepo: settingRepo,
+		scheduler:   scheduler,
+		now:         func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// SetNotificationService tells users in the site when their export is ready.
+func (s *AccountDataService) SetNotificationService(notifications *NotificationService) {
+	if s != nil {
+		s.notifications = notifications
+	}
+}
+
+func defaultAccountDeletionSettings() models.AccountDeletionSettings {
+	return models.AccountDeletionSettings{ContentMode: models.AccountContentAnonymize}
+}
+
+func (s *AccountDataService) GetSettings() (models.AccountDeletionSettings, error) {
+	defaults := defaultAccountDeletionSettings()
+	if s == nil || s.settingRepo == nil {
+		return defaults, nil
+	}
+
+	stored, err := s.settingRepo.Get(SettingKeyAccountDeletion)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return defaults, nil
+		}
+		return defaults, err
+	}
+	if strings.TrimSpace(stored.Value) == "" {
+		return defaults, nil
+	}
+
+	var settings models.AccountDeletionSettings
+	if err := json.Unmarshal([]byte(stored.Value), &settings); err != nil {
+		return defaults, fmt.Errorf("failed to decode account deletion settings: %w", err)
+	}
+	if settings.ContentMode == "" {
+		settings.ContentMode = models.AccountContentAnonymize
+	}
+	return settings, nil
+}
+
+func (s *AccountDataService) UpdateSettings(req models.UpdateAccountDeletionSettingsRequest) (models.AccountDeletionSettings, error) {
+	if s == nil || s.settingRepo == nil {
+		return models.AccountDeletionSettings{}, ErrAccountDataUnavailable
+	}
+
+	settings := models.AccountDeletionSettings{
+		ContentMode:     strings.ToLower(strings.TrimSpace(req.ContentMode)),
+		RequireApproval: req.RequireApproval,
+	}
+	switch settings.ContentMode {
+	case "":
+		settings.ContentMode = models.AccountContentAnonymize
+	case models.AccountContentAnonymize, models.AccountContentDelete:
+	default:
+		return models.AccountDeletionSettings{}, accountDataValidationErrorf("content mode must be %q or %q", models.AccountContentAnonymize, models.AccountContentDelete)
+	}
+
+	encoded, err := json.Marshal(settings)
+	if err != nil {
+		return models.AccountDeletionSettings{}, err
+	}
+	if err := s.settingRepo.Set(SettingKeyAccountDeletion, string(encoded)); err != nil {
+		return models.AccountDeletionSettings{}, err
+	}
+	return settings, nil
+}
+
+// RequestExport starts building an archive of the user's data in the
+// background and returns the pending export.
+func (s *AccountDataService) RequestExport(userID uint) (*models.DataExport, error) {
+	if s == nil || s.exports == nil {
+		return nil, ErrAccountDataUnavailable
+	}
+
+	recent, err := s.exports.ListByUser(userID, dataExportListLimit)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	for _, export := range recent {
+		if export.Status == models.DataExportPending && now.Sub(export.CreatedAt) < dataExportStale {
+			return nil, ErrDataExportInProgress
+		}
+	}
+
+	export := &models.DataExport{UserID: userID, Status: models.DataExportPending}
+	if err := s.exports.Create(export); err != nil {
+		return nil, err
+	}
+
+	build := func(ctx context.Context) error {
+		return s.buildExport(ctx, export.ID, userID)
+	}
+	if s.scheduler == nil {
+		if err := build(context.Background()); err != nil {
+			return nil, err
+		}
+		return s.exports.GetForUser(export.ID, userID)
+	}
+
+	job := background.Job{
+		Name:    fmt.Sprintf("data_export:%d", export.ID),
+		Timeout: dataExportTimeout,
+		Run:     build,
+	}
+	if err := s.scheduler.Schedule(job); err != nil {
+		s.failExport(export, err)
+		return nil, err
+	}
+	return export, nil
+}
+
+func (s *AccountDataService) buildExport(ctx context.Context, id, userID uint) error {
+	export, err := s.exports.GetForUser(id, userID)
+	if err != nil {
+		return err
+	}
+
+	archive, err := s.exportArchive(ctx, userID)
+	if err != nil {
+		s.failExport(export, err)
+		return err
+	}
+
+	completedAt := s.now()
+	expiresAt := completedAt.Add(dataExportLifetime)
+	export.Status = models.DataExportReady
+	export.Error = ""
+	export.Archive = archive
+	export.Size = int64(len(archive))
+	export.CompletedAt = &completedAt
+	export.ExpiresAt = &expiresAt
+	if err := s.exports.Update(export); err != nil {
+		return err
+	}
+
+	if s.notifications != nil {
+		notification := models.Notification{
+			Kind:  models.NotificationDataExportReady,
+			Title: "Your data export is ready",
+			Body:  fmt.Sprintf("Download it before %s.", expiresAt.Format("2 January 2006")),
+			Path:  "/profile",
+		}
+		if err := s.notifications.Send([]uint{userID}, notification); err != nil {
+			logger.Warn("Failed to notify user about data export", map[string]interface{}{
+				"user_id": userID,
+				"error":   err.Error(),
+			})
+		}
+	}
+	return nil
+}
+
+// exportArchive zips the user's account and one JSON file per table holding
+// their rows.
+func (s *AccountDataService) exportArchive(ctx context.Context, userID uint) ([]byte, error) {
+	user, tables, err := s.exports.CollectUserData(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(tables))
+	for name := range tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buffer bytes.Buffer
+	archive := zip.NewWriter(&buffer)
+	write := func(name string, data []byte) error {
+		file, err := archive.Create(name)
+		if err != nil {
+			return err
+		}
+		_, err = file.Write(data)
+		return err
+	}
+
+	account, err := json.MarshalIndent(user, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := write("account.json", account); err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		var rows bytes.Buffer
+		if err := json.Indent(&rows, tables[name], "", "  "); err != nil {
+			return nil, fmt.Errorf("failed to format %s: %w", name, err)
+		}
+		if err := write("data/"+name+".json", rows.Bytes()); err != nil {
+			return nil, err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func (s *AccountDataService) failExport(export *models.DataExport, cause error) {
+	export.Status = models.DataExportFailed
+	export.Error = cause.Error()
+	if err := s.exports.Update(export); err != nil {
+		logger.Error(err, "Failed to record data export failure", map[string]interface{}{"export_id": export.ID})
+	}
+}
+
+// Exports returns the user's recent exports, newest first.
+func (s *AccountDataService) Exports(userID uint) ([]models.DataExport, error) {
+	if s == nil || s.exports == nil {
+		return nil, ErrAccountDataUnavailable
+	}
+	return s.exports.ListByUser(userID, dataExportListLimit)
+}
+
+// Export returns a finished export of the user with its archive.
+func (s *AccountDataService) Export(userID, id uint) (*models.DataExport, error) {
+	if s == nil || s.exports == nil {
+		return nil, ErrAccountDataUnavailable
+	}
+	export, err := s.exports.GetForUser(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if export.Status != models.DataExportReady {
+		return nil, ErrDataExportNotReady
+	}
+	if export.ExpiresAt != nil && !export.ExpiresAt.After(s.now()) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return export, nil
+}
+
+// PruneExports removes expired archives.
+func (s *AccountDataService) PruneExports(ctx context.Context) error {
+	if s == nil || s.exports == nil {
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	removed, err := s.exports.DeleteExpired(s.now())
+	if err != nil {
+		return err
+	}
+	if removed > 0 {
+		logger.Info("Removed expired data exports", map[string]interface{}{"count": removed})
+	}
+	return nil
+}
+
+// DeleteAccount deletes the user's account once they confirm their password.
+// When deletions need approval it files a request instead; the returned bool
+// reports whether the account is gone.
+func (s *AccountDataService) DeleteAccount(ctx context.Context, userID uint, req models.DeleteAccountRequest) (bool, *models.AccountDeletionRequest, error) {
+	if s == nil || s.deletions == nil || s.userRepo == nil {
+		return false, nil, ErrAccountDataUnavailable
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil, ErrUserNotFound
+		}
+		return false, nil, err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+		return false, nil, ErrIncorrectPassword
+	}
+	if user.Role == authorization.RoleAdmin {
+		return false, nil, ErrAdminAccountDeletion
+	}
+
+	reason := strings.TrimSpace(req.Reason)
+	if len(reason) > maxDeletionReason {
+		return false, nil, accountDataValidationErrorf("reason must be at most %d characters", maxDeletionReason)
+	}
+
+	settings, err := s.GetSettings()
+	if err != nil {
+		return false, nil, err
+	}
+
+	request := &models.AccountDeletionRequest{
+		UserID:   user.ID,
+		Username: user.Username,
+		Email:    user.Email,
+		Reason:   reason,
+		Status:   models.AccountDeletionPending,
+	}
+	if settings.RequireApproval {
+		if _, err := s.deletions.PendingForUser(user.ID); err == nil {
+			return false, nil, ErrAccountDeletionPending
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil, err
+		}
+		if err := s.deletions.Create(request); err != nil {
+			return false, nil, err
+		}
+		return false, request, nil
+	}
+
+	if err := s.deletions.EraseUser(ctx, user.ID, settings.ContentMode); err != nil {
+		return false, nil, err
+	}
+	s.completeDeletion(request, nil)
+	if err := s.deletions.Create(request); err != nil {
+		logger.Error(err, "Failed to record account deletion", map[string]interface{}{"user_id": user.ID})
+	}
+	return true, request, nil
+}
+
+// completeDeletion marks request done and forgets who it was about.
+func (s *AccountDataService) completeDeletion(request *models.AccountDeletionRequest, reviewerID *uint) {
+	now := s.now()
+	request.Status = models.AccountDeletionCompleted
+	request.Username = ""
+	request.Email = ""
+	request.Reason = ""
+	request.ReviewedBy = reviewerID
+	request.ReviewedAt = &now
+}
+
+// DeletionRequests lists deletion requests, optionally only those in status.
+func (s *AccountDataService) DeletionRequests(status string) ([]models.AccountDeletionRequest, error) {
+	if s == nil || s.deletions == nil {
+		return nil, ErrAccountDataUnavailable
+	}
+	status = strings.ToLower(strings.TrimSpace(status))
+	switch status {
+	case "", models.AccountDeletionPending, models.AccountDeletionCompleted, models.AccountDeletionRejected:
+	default:
+		return nil, accountDataValidationErrorf("unknown status %q", status)
+	}
+	return s.deletions.List(status, accountDeletionListMax)
+}
+
+// ApproveDeletion deletes the account of a pending request.
+func (s *AccountDataService) ApproveDeletion(ctx context.Context, id, adminID uint) (*models.AccountDeletionRequest, error) {
+	request, err := s.pendingDeletion(id)
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := s.GetSettings()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.deletions.EraseUser(ctx, request.UserID, settings.ContentMode); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	s.completeDeletion(request, &adminID)
+	if err := s.deletions.Update(request); err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+// RejectDeletion closes a pending request and keeps the account.
+func (s *AccountDataService) RejectDeletion(id, adminID uint) (*models.AccountDeletionRequest, error) {
+	request, err := s.pendingDeletion(id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	request.Status = models.AccountDeletionRejected
+	request.ReviewedBy = &adminID
+	request.ReviewedAt = &now
+	if err := s.deletions.Update(request); err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+func (s *AccountDataService) pendingDeletion(id uint) (*models.AccountDeletionRequest, error) {
+	if s == nil || s.deletions == nil {
+		return nil, ErrAccountDataUnavailable
+	}
+	request, err := s.deletions.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if request.Status != models.AccountDeletionPending {
+		return nil, ErrAccountDeletionReviewed
+	}
+	return request, nil
+}
@@ -0,0 +1,282 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"constructor-script-backend/internal/authorization"
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/repository"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+type memoryDataExportRepository struct {
+	exports []models.DataExport
+	user    models.User
+	tables  map[string]json.RawMessage
+}
+
+func (r *memoryDataExportRepository) Create(export *models.DataExport) error {
+	export.ID = uint(len(r.exports) + 1)
+	export.CreatedAt = time.Now().UTC()
+	r.exports = append(r.exports, *export)
+	return nil
+}
+
+func (r *memoryDataExportRepository) Update(export *models.DataExport) error {
+	r.exports[export.ID-1] = *export
+	return nil
+}
+
+func (r *memoryDataExportRepository) GetForUser(id, userID uint) (*models.DataExport, error) {
+	if id == 0 || int(id) > len(r.exports) || r.exports[id-1].UserID != userID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	export := r.exports[id-1]
+	return &export, nil
+}
+
+func (r *memoryDataExportRepository) ListByUser(userID uint, _ int) ([]models.DataExport, error) {
+	var exports []models.DataExport
+	for _, export := range r.exports {
+		if export.UserID == userID {
+			exports = append(exports, export)
+		}
+	}
+	return exports, nil
+}
+
+func (r *memoryDataExportRepository) DeleteExpired(time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (r *memoryDataExportRepository) CollectUserData(_ context.Context, userID uint) (*models.User, map[string]json.RawMessage, error) {
+	if r.user.ID != userID {
+		return nil, nil, gorm.ErrRecordNotFound
+	}
+	user := r.user
+	return &user, r.tables, nil
+}
+
+type memoryAccountDeletionRepository struct {
+	requests []models.AccountDeletionRequest
+	erased   map[uint]string
+}
+
+func (r *memoryAccountDeletionRepository) Create(request *models.AccountDeletionRequest) error {
+	request.ID = uint(len(r.requests) + 1)
+	r.requests = append(r.requests, *request)
+	return nil
+}
+
+func (r *memoryAccountDeletionRepository) Update(request *models.AccountDeletionRequest) error {
+	r.requests[request.ID-1] = *request
+	return nil
+}
+
+func (r *memoryAccountDeletionRepository) Get(id uint) (*models.AccountDeletionRequest, error) {
+	if id == 0 || int(id) > len(r.requests) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	request := r.requests[id-1]
+	return &request, nil
+}
+
+func (r *memoryAccountDeletionRepository) PendingForUser(userID uint) (*models.AccountDeletionRequest, error) {
+	for _, request := range r.requests {
+		if request.UserID == userID && request.Status == models.AccountDeletionPending {
+			return &request, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memoryAccountDeletionRepository) List(status string, _ int) ([]models.AccountDeletionRequest, error) {
+	var requests []models.AccountDeletionRequest
+	for _, request := range r.requests {
+		if status == "" || request.Status == status {
+			requests = append(requests, request)
+		}
+	}
+	return requests, nil
+}
+
+func (r *memoryAccountDeletionRepository) EraseUser(_ context.Context, userID uint, contentMode string) error {
+	if r.erased == nil {
+		r.erased = make(map[uint]string)
+	}
+	r.erased[userID] = contentMode
+	return nil
+}
+
+// memoryUserRepository serves the lookups the account tests need; the other
+// methods are not used.
+type memoryUserRepository struct {
+	repository.UserRepository
+	users map[uint]*models.User
+}
+
+func (r *memoryUserRepository) GetByID(id uint) (*models.User, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return user, nil
+}
+
+func (r *memoryUserRepository) GetByEmail(email string) (*models.User, error) {
+	for _, user := range r.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memoryUserRepository) GetByUsername(username string) (*models.User, error) {
+	for _, user := range r.users {
+		if user.Username == username {
+			return user, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memoryUserRepository) Create(user *models.User) error {
+	user.ID = uint(len(r.users) + 1)
+	r.users[user.ID] = user
+	return nil
+}
+
+func (r *memoryUserRepository) Update(user *models.User) error {
+	r.users[user.ID] = user
+	return nil
+}
+
+func newAccountDataTestService(t *testing.T) (*AccountDataService, *memoryDataExportRepository, *memoryAccountDeletionRepository) {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret-password"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	users := &memoryUserRepository{users: map[uint]*models.User{
+		1: {ID: 1, Username: "admin", Email: "admin@example.com", Password: string(hash), Role: authorization.RoleAdmin},
+		2: {ID: 2, Username: "reader", Email: "reader@example.com", Password: string(hash), Role: authorization.RoleUser},
+	}}
+	exports := &memoryDataExportRepository{
+		user: models.User{ID: 2, Username: "reader", Email: "reader@example.com"},
+		tables: map[string]json.RawMessage{
+			"comments": json.RawMessage(`[{"id":4,"content":"Nice post"}]`),
+			"orders":   json.RawMessage(`[]`),
+		},
+	}
+	deletions := &memoryAccountDeletionRepository{}
+	settings := &memorySettingRepository{values: make(map[string]string)}
+	return NewAccountDataService(exports, deletions, users, settings, nil), exports, deletions
+}
+
+func TestDataExportArchive(t *testing.T) {
+	svc, _, _ := newAccountDataTestService(t)
+
+	export, err := svc.RequestExport(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if export.Status != models.DataExportReady || export.ExpiresAt == nil {
+		t.Fatalf("expected a finished export, got %+v", export)
+	}
+
+	downloaded, err := svc.Export(2, export.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Export(1, export.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("expected another user's export to be hidden, got %v", err)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(downloaded.Archive), int64(len(downloaded.Archive)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, file := range archive.File {
+		names = append(names, file.Name)
+	}
+	want := []string{"account.json", "data/comments.json", "data/orders.json"}
+	if len(names) != len(want) {
+		t.Fatalf("expected files %v, got %v", want, names)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("expected files %v, got %v", want, names)
+		}
+	}
+}
+
+func TestDeleteAccount(t *testing.T) {
+	svc, _, deletions := newAccountDataTestService(t)
+	ctx := context.Background()
+
+	if _, _, err := svc.DeleteAccount(ctx, 2, models.DeleteAccountRequest{Password: "wrong"}); !errors.Is(err, ErrIncorrectPassword) {
+		t.Fatalf("expected a wrong password to be rejected, got %v", err)
+	}
+	if _, _, err := svc.DeleteAccount(ctx, 1, models.DeleteAccountRequest{Password: "secret-password"}); !errors.Is(err, ErrAdminAccountDeletion) {
+		t.Fatalf("expected administrators to be refused, got %v", err)
+	}
+
+	deleted, request, err := svc.DeleteAccount(ctx, 2, models.DeleteAccountRequest{Password: "secret-password"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !deleted || deletions.erased[2] != models.AccountContentAnonymize {
+		t.Fatalf("expected the account to be erased with anonymized content, got %v", deletions.erased)
+	}
+	if request.Status != models.AccountDeletionCompleted || request.Email != "" || request.Username != "" {
+		t.Fatalf("expected a completed request without personal data, got %+v", request)
+	}
+}
+
+func TestAccountDeletionApproval(t *testing.T) {
+	svc, _, deletions := newAccountDataTestService(t)
+	ctx := context.Background()
+
+	if _, err := svc.UpdateSettings(models.UpdateAccountDeletionSettingsRequest{ContentMode: "purge"}); err == nil {
+		t.Fatal("expected an unknown content mode to be rejected")
+	}
+	if _, err := svc.UpdateSettings(models.UpdateAccountDeletionSettingsRequest{ContentMode: "Delete", RequireApproval: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	req := models.DeleteAccountRequest{Password: "secret-password", Reason: "Moving on"}
+	deleted, request, err := svc.DeleteAccount(ctx, 2, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted || request.Status != models.AccountDeletionPending || len(deletions.erased) != 0 {
+		t.Fatalf("expected a pending request, got %+v", request)
+	}
+	if _, _, err := svc.DeleteAccount(ctx, 2, req); !errors.Is(err, ErrAccountDeletionPending) {
+		t.Fatalf("expected a second request to be refused, got %v", err)
+	}
+
+	approved, err := svc.ApproveDeletion(ctx, request.ID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deletions.erased[2] != models.AccountContentDelete {
+		t.Fatalf("expected content to be deleted, got %v", deletions.erased)
+	}
+	if approved.Status != models.AccountDeletionCompleted || approved.ReviewedBy == nil || *approved.ReviewedBy != 1 || approved.Email != "" {
+		t.Fatalf("unexpected approved request %+v", approved)
+	}
+	if _, err := svc.RejectDeletion(request.ID, 1); !errors.Is(err, ErrAccountDeletionReviewed) {
+		t.Fatalf("expected a reviewed request to stay closed, got %v", err)
+	}
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/pkg/logger"
+)
+
+const maxAlertRuleEmails = 20
+
+func normalizeAlertRule(rule models.AlertRule, channels map[string]bool) (models.AlertRule, error) {
+	rule.Name = strings.TrimSpace(rule.Name)
+	if rule.Name == "" || len(rule.Name) > alertNameLength {
+		return rule, fmt.Errorf("name must be 1-%d characters", alertNameLength)
+	}
+
+	rule.Alert = strings.ToLower(strings.TrimSpace(rule.Alert))
+	if !containsString(alertNames, rule.Alert) {
+		return rule, fmt.Errorf("unknown alert %q", rule.Alert)
+	}
+	if rule.PendingOnly && rule.Alert != models.AlertComments {
+		return rule, errors.New("pending_only applies to comments only")
+	}
+	if len(rule.ForumCategoryIDs) > 0 && rule.Alert != models.AlertForumQuestions {
+		return rule, errors.New("forum_category_ids applies to forum_questions only")
+	}
+	categories := make([]uint, 0, len(rule.ForumCategoryIDs))
+	for _, id := range rule.ForumCategoryIDs {
+		if id == 0 {
+			return rule, errors.New("forum_category_ids must be positive")
+		}
+		if !containsUint(categories, id) {
+			categories = append(categories, id)
+		}
+	}
+	rule.ForumCategoryIDs = categories
+
+	targets := make([]string, 0, len(rule.Channels))
+	for _, name := range rule.Channels {
+		name = strings.TrimSpace(name)
+		if !channels[name] {
+			return rule, fmt.Errorf("unknown channel %q", name)
+		}
+		if !containsString(targets, name) {
+			targets = append(targets, name)
+		}
+	}
+	rule.Channels = targets
+
+	if len(rule.Emails) > maxAlertRuleEmails {
+		return rule, fmt.Errorf("at most %d emails are allowed", maxAlertRuleEmails)
+	}
+	emails := make([]string, 0, len(rule.Emails))
+	for _, raw := range rule.Emails {
+		trimmed := strings.TrimSpace(raw)
+		address, err := mail.ParseAddress(trimmed)
+		if err != nil || address.Address != trimmed {
+			return rule, fmt.Errorf("invalid email %q", raw)
+		}
+		email := strings.ToLower(address.Address)
+		if !containsString(emails, email) {
+			emails = append(emails, email)
+		}
+	}
+	rule.Emails = emails
+
+	if len(rule.Channels) == 0 && len(rule.Emails) == 0 {
+		return rule, errors.New("at least one channel or email is required")
+	}
+	return rule, nil
+}
+
+func containsUint(values []uint, value uint) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
+
+// alertRuleMatches reports whether alert is one the rule routes.
+func alertRuleMatches(rule models.AlertRule, alert Alert) bool {
+	if rule.Alert != alert.Kind {
+		return false
+	}
+	if rule.PendingOnly && !alert.Pending {
+		return false
+	}
+	if len(rule.ForumCategoryIDs) > 0 && !containsUint(rule.ForumCategoryIDs, alert.CategoryID) {
+		return false
+	}
+	return true
+}
+
+// routeAlert returns the channels and email addresses alert goes to: the
+// targets of every rule it matches or, when it matches none, the channels
+// subscribed to its kind.
+func routeAlert(settings models.AlertSettings, alert Alert) ([]models.AlertChannel, []string) {
+	var channelNames, emails []string
+	matched := false
+	for _, rule := range settings.Rules {
+		if !alertRuleMatches(rule, alert) {
+			continue
+		}
+		matched = true
+		for _, name := range rule.Channels {
+			if !containsString(channelNames, name) {
+				channelNames = append(channelNames, name)
+			}
+		}
+		for _, email := range rule.Emails {
+			if !containsString(emails, email) {
+				emails = append(emails, email)
+			}
+		}
+	}
+
+	var channels []models.AlertChannel
+	for _, channel := range settings.Channels {
+		if matched && containsString(channelNames, channel.Name) || !matched && containsString(channel.Alerts, alert.Kind) {
+			channels = append(channels, channel)
+		}
+	}
+	return channels, emails
+}
+
+func (s *AlertService) sendEmails(emails []string, alert Alert) {
+	if s.email == nil || !s.email.Enabled() {
+		logger.Warn("Alert rule emails skipped because email is not configured", map[string]interface{}{
+			"alert": alert.Kind,
+		})
+		return
+	}
+	data := map[string]interface{}{
+		"Title": alert.Title,
+		"Text":  alert.Text,
+	}
+	for _, email := range emails {
+		if err := s.email.SendTemplate(email, EmailTemplateAdminAlert, alert.Title, data); err != nil {
+			logger.Warn("Failed to email admin alert", map[string]interface{}{
+				"alert": alert.Kind,
+				"error": err.Error(),
+			})
+		}
+	}
+}
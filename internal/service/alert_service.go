@@ -0,0 +1,502 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"constructor-script-backend/internal/events"
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/repository"
+	"constructor-script-backend/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// SettingKeyAlerts stores the admin alert channels in the settings repository.
+	SettingKeyAlerts = "integrations.alerts"
+
+	alertSettingsRefresh = 30 * time.Second
+	alertTimeout         = 10 * time.Second
+	alertResponseLimit   = 512
+	alertNameLength      = 64
+	maxAlertChannels     = 10
+	maxAlertRules        = 20
+
+	defaultErrorRateThreshold = 0.05
+	// errorRateWindow is the span the error rate is measured over, and
+	// errorRateMinRequests the traffic below which it is not judged.
+	errorRateWindow      = 5 * time.Minute
+	errorRateMinRequests = 20
+	// errorRateCooldown keeps a lasting spike from alerting every minute.
+	errorRateCooldown = 30 * time.Minute
+
+	telegramAPIURL = "https://api.telegram.org"
+)
+
+// alertEvents maps the events that raise an alert to the alert they belong to.
+var alertEvents = map[string]string{
+	events.CommentCreated:           models.AlertComments,
+	events.CheckoutCompleted:        models.AlertCheckouts,
+	events.ForumQuestionCreated:     models.AlertForumQuestions,
+	events.BackupFailed:             models.AlertBackups,
+	events.BackupVerificationFailed: models.AlertBackups,
+}
+
+var alertNames = []string{models.AlertComments, models.AlertBackups, models.AlertErrorRate, models.AlertCheckouts, models.AlertForumQuestions}
+
+// Alert is a message for the admin alert channels.
+type Alert struct {
+	Kind  string
+	Title string
+	Text  string
+	// Pending marks content awaiting moderation and CategoryID the forum
+	// category of a question, for routing rules to match on.
+	Pending    bool
+	CategoryID uint
+}
+
+type AlertValidationError struct {
+	Reason string
+}
+
+func (e *AlertValidationError) Error() string {
+	if e == nil {
+		return ""
+	}
+	return e.Reason
+}
+
+func alertValidationErrorf(format string, args ...interface{}) error {
+	return &AlertValidationError{Reason: fmt.Sprintf(format, args...)}
+}
+
+type errorRateSample struct {
+	at       time.Time
+	requests int64
+	errors   int64
+}
+
+// AlertService posts operational alerts, such as failed backups and error rate
+// spikes, to Slack, Discord and Telegram. Alerts go out from the instance that
+// saw the event; error rates are those of each instance.
+type AlertService struct {
+	settingRepo repository.SettingRepository
+	email       *EmailService
+	client      *http.Client
+	telegramAPI string
+	// requests returns the requests served and the server errors among them since
+	// the process started.
+	requests func() (int64, int64)
+
+	mu       sync.RWMutex
+	settings models.AlertSettings
+	loadedAt time.Time
+
+	rateMu        sync.Mutex
+	samples       []errorRateSample
+	lastRateAlert time.Time
+	now           func() time.Time
+}
+
+func NewAlertService(repo repository.SettingRepository, requests func() (int64, int64)) *AlertService {
+	return &AlertService{
+		settingRepo: repo,
+		client:      &http.Client{Timeout: alertTimeout},
+		telegramAPI: telegramAPIURL,
+		requests:    requests,
+		now:         time.Now,
+	}
+}
+
+// SetEmailService enables routing rules that send alerts by email.
+func (s *AlertService) SetEmailService(email *EmailService) {
+	if s == nil {
+		return
+	}
+	s.email = email
+}
+
+// AlertNames lists the alerts channels can subscribe to.
+func (s *AlertService) AlertNames() []string {
+	return append([]string(nil), alertNames...)
+}
+
+func (s *AlertService) GetSettings() (models.AlertSettings, error) {
+	defaults := models.AlertSettings{Channels: []models.AlertChannel{}, ErrorRateThreshold: defaultErrorRateThreshold, Rules: []models.AlertRule{}}
+	if s == nil || s.settingRepo == nil {
+		return defaults, nil
+	}
+
+	stored, err := s.settingRepo.Get(SettingKeyAlerts)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return defaults, nil
+		}
+		return defaults, err
+	}
+	if strings.TrimSpace(stored.Value) == "" {
+		return defaults, nil
+	}
+
+	var settings models.AlertSettings
+	if err := json.Unmarshal([]byte(stored.Value), &settings); err != nil {
+		return defaults, fmt.Errorf("failed to decode alert settings: %w", err)
+	}
+	if settings.Channels == nil {
+		settings.Channels = []models.AlertChannel{}
+	}
+	if settings.Rules == nil {
+		settings.Rules = []models.AlertRule{}
+	}
+	if settings.ErrorRateThreshold <= 0 {
+		settings.ErrorRateThreshold = defaultErrorRateThreshold
+	}
+	return settings, nil
+}
+
+func (s *AlertService) UpdateSettings(req models.UpdateAlertSettingsRequest) (models.AlertSettings, error) {
+	settings := models.AlertSettings{
+		Channels:           make([]models.AlertChannel, 0, len(req.Channels)),
+		ErrorRateThreshold: req.ErrorRateThreshold,
+		Rules:              make([]models.AlertRule, 0, len(req.Rules)),
+	}
+	if settings.ErrorRateThreshold == 0 {
+		settings.ErrorRateThreshold = defaultErrorRateThreshold
+	}
+	if settings.ErrorRateThreshold < 0 || settings.ErrorRateThreshold > 1 {
+		return models.AlertSettings{}, alertValidationErrorf("error_rate_threshold must be between 0 and 1")
+	}
+
+	if len(req.Channels) > maxAlertChannels {
+		return models.AlertSettings{}, alertValidationErrorf("at most %d alert channels are allowed", maxAlertChannels)
+	}
+	names := make(map[string]bool, len(req.Channels))
+	for index, channel := range req.Channels {
+		normalized, err := normalizeAlertChannel(channel)
+		if err != nil {
+			return models.AlertSettings{}, alertValidationErrorf("alert channel %d: %s", index+1, err.Error())
+		}
+		if names[normalized.Name] {
+			return models.AlertSettings{}, alertValidationErrorf("alert channel %d: name %q is already used", index+1, normalized.Name)
+		}
+		names[normalized.Name] = true
+		settings.Channels = append(settings.Channels, normalized)
+	}
+
+	if len(req.Rules) > maxAlertRules {
+		return models.AlertSettings{}, alertValidationErrorf("at most %d alert rules are allowed", maxAlertRules)
+	}
+	ruleNames := make(map[string]bool, len(req.Rules))
+	routed := make(map[string]bool)
+	for index, rule := range req.Rules {
+		normalized, err := normalizeAlertRule(rule, names)
+		if err != nil {
+			return models.AlertSettings{}, alertValidationErrorf("alert rule %d: %s", index+1, err.Error())
+		}
+		if ruleNames[normalized.Name] {
+			return models.AlertSettings{}, alertValidationErrorf("alert rule %d: name %q is already used", index+1, normalized.Name)
+		}
+		ruleNames[normalized.Name] = true
+		for _, channel := range normalized.Channels {
+			routed[channel] = true
+		}
+		settings.Rules = append(settings.Rules, normalized)
+	}
+	for index, channel := range settings.Channels {
+		if len(channel.Alerts) == 0 && !routed[channel.Name] {
+			return models.AlertSettings{}, alertValidationErrorf("alert channel %d: at least one alert or rule is required", index+1)
+		}
+	}
+
+	if s.settingRepo != nil {
+		payload, err := json.Marshal(settings)
+		if err != nil {
+			return settings, fmt.Errorf("failed to encode alert settings: %w", err)
+		}
+		if err := s.settingRepo.Set(SettingKeyAlerts, string(payload)); err != nil {
+			return settings, err
+		}
+	}
+
+	s.mu.Lock()
+	s.settings = settings
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+
+	return settings, nil
+}
+
+func normalizeAlertChannel(channel models.AlertChannel) (models.AlertChannel, error) {
+	channel.Name = strings.TrimSpace(channel.Name)
+	if channel.Name == "" || len(channel.Name) > alertNameLength {
+		return channel, fmt.Errorf("name must be 1-%d characters", alertNameLength)
+	}
+
+	channel.Type = strings.ToLower(strings.TrimSpace(channel.Type))
+	switch channel.Type {
+	case models.AlertChannelSlack, models.AlertChannelDiscord:
+		normalized, err := normalizeHTTPURL(channel.URL)
+		if err != nil || !strings.HasPrefix(normalized, "https://") {
+			return channel, errors.New("url must be an absolute https URL")
+		}
+		channel.URL = normalized
+		channel.BotToken, channel.ChatID = "", ""
+	case models.AlertChannelTelegram:
+		channel.BotToken = strings.TrimSpace(channel.BotToken)
+		channel.ChatID = strings.TrimSpace(channel.ChatID)
+		if channel.BotToken == "" || strings.ContainsAny(channel.BotToken, "/?# ") || channel.ChatID == "" {
+			return channel, errors.New("bot_token and chat_id are required")
+		}
+		channel.URL = ""
+	default:
+		return channel, errors.New("type must be slack, discord or telegram")
+	}
+
+	alerts := make([]string, 0, len(channel.Alerts))
+	seen := make(map[string]bool, len(channel.Alerts))
+	for _, alert := range channel.Alerts {
+		alert = strings.ToLower(strings.TrimSpace(alert))
+		if !containsString(alertNames, alert) {
+			return channel, fmt.Errorf("unknown alert %q", alert)
+		}
+		if !seen[alert] {
+			seen[alert] = true
+			alerts = append(alerts, alert)
+		}
+	}
+	channel.Alerts = alerts
+	return channel, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *AlertService) currentSettings() models.AlertSettings {
+	if s == nil {
+		return models.AlertSettings{}
+	}
+
+	s.mu.RLock()
+	settings, loadedAt := s.settings, s.loadedAt
+	s.mu.RUnlock()
+	if !loadedAt.IsZero() && time.Since(loadedAt) < alertSettingsRefresh {
+		return settings
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.loadedAt.IsZero() && time.Since(s.loadedAt) < alertSettingsRefresh {
+		return s.settings
+	}
+
+	loaded, err := s.GetSettings()
+	s.loadedAt = time.Now()
+	if err != nil {
+		logger.Error(err, "Failed to load alert settings", nil)
+		return s.settings
+	}
+	s.settings = loaded
+	return s.settings
+}
+
+// Subscribe alerts the channels about the events published on bus.
+func (s *AlertService) Subscribe(bus *events.Bus) {
+	if s == nil || bus == nil {
+		return
+	}
+	for name := range alertEvents {
+		bus.Subscribe(name, func(_ context.Context, event events.Event) {
+			if alert, ok := alertForEvent(event); ok {
+				s.Notify(alert)
+			}
+		})
+	}
+}
+
+func alertForEvent(event events.Event) (Alert, bool) {
+	payload := event.Payload
+	switch event.Name {
+	case events.CommentCreated:
+		if approved, ok := payload["approved"].(bool); ok && !approved {
+			return Alert{Kind: models.AlertComments, Title: "New comment awaiting approval", Pending: true,
+				Text: fmt.Sprintf("Comment #%v on post #%v needs moderation.", payload["id"], payload["post_id"])}, true
+		}
+		return Alert{Kind: models.AlertComments, Title: "New comment",
+			Text: fmt.Sprintf("Comment #%v was posted on post #%v.", payload["id"], payload["post_id"])}, true
+	case events.CheckoutCompleted:
+		kind := strings.ReplaceAll(fmt.Sprint(payload["kind"]), "_", " ")
+		text := fmt.Sprintf("A %s checkout was completed", kind)
+		if email, _ := payload["email"].(string); email != "" {
+			text += " by " + email
+		}
+		return Alert{Kind: models.AlertCheckouts, Title: "New checkout", Text: text + "."}, true
+	case events.ForumQuestionCreated:
+		alert := Alert{Kind: models.AlertForumQuestions, Title: "New forum question",
+			Text: fmt.Sprintf("\"%v\" was asked in the forum.", payload["title"])}
+		switch category := payload["category_id"].(type) {
+		case uint:
+			alert.CategoryID = category
+		case *uint:
+			if category != nil {
+				alert.CategoryID = *category
+			}
+		}
+		return alert, true
+	case events.BackupFailed:
+		return Alert{Kind: models.AlertBackups, Title: "Automatic backup failed",
+			Text: fmt.Sprintf("The scheduled backup could not be created: %v", payload["error"])}, true
+	case events.BackupVerificationFailed:
+		return Alert{Kind: models.AlertBackups, Title: "Backup verification failed",
+			Text: fmt.Sprintf("%v (%v) may not be restorable: %v", payload["name"], payload["location"], payload["error"])}, true
+	}
+	return Alert{}, false
+}
+
+// Notify posts alert to the targets of the rules it matches or, when it matches
+// none, to the channels subscribed to its kind, in the background. Failed posts
+// are logged only.
+func (s *AlertService) Notify(alert Alert) {
+	if s == nil {
+		return
+	}
+	channels, emails := routeAlert(s.currentSettings(), alert)
+	if len(emails) > 0 {
+		go s.sendEmails(emails, alert)
+	}
+	for _, channel := range channels {
+		go func(channel models.AlertChannel) {
+			ctx, cancel := context.WithTimeout(context.Background(), alertTimeout)
+			defer cancel()
+			if err := s.post(ctx, channel, alert); err != nil {
+				logger.Warn("Failed to post admin alert", map[string]interface{}{
+					"channel": channel.Name,
+					"type":    channel.Type,
+					"alert":   alert.Kind,
+					"error":   err.Error(),
+				})
+			}
+		}(channel)
+	}
+}
+
+// Test posts a test message to every channel and reports the result of each.
+func (s *AlertService) Test(ctx context.Context) []models.AlertDeliveryResult {
+	channels := s.currentSettings().Channels
+	results := make([]models.AlertDeliveryResult, len(channels))
+	alert := Alert{Title: "Test alert", Text: "Alerts from this site will appear here."}
+
+	var wg sync.WaitGroup
+	for index, channel := range channels {
+		results[index].Name = channel.Name
+		wg.Add(1)
+		go func(index int, channel models.AlertChannel) {
+			defer wg.Done()
+			if err := s.post(ctx, channel, alert); err != nil {
+				results[index].Error = err.Error()
+			}
+		}(index, channel)
+	}
+	wg.Wait()
+	return results
+}
+
+// CheckErrorRate alerts when the share of server errors over the last five
+// minutes reaches the threshold. It is meant to run every minute.
+func (s *AlertService) CheckErrorRate(_ context.Context) error {
+	if s == nil || s.requests == nil {
+		return nil
+	}
+	requests, serverErrors := s.requests()
+	now := s.now()
+
+	s.rateMu.Lock()
+	s.samples = append(s.samples, errorRateSample{at: now, requests: requests, errors: serverErrors})
+	// Keep the newest sample that is at least a window old as the baseline.
+	for len(s.samples) > 1 && now.Sub(s.samples[1].at) >= errorRateWindow {
+		s.samples = s.samples[1:]
+	}
+	baseline := s.samples[0]
+	cooling := !s.lastRateAlert.IsZero() && now.Sub(s.lastRateAlert) < errorRateCooldown
+	s.rateMu.Unlock()
+
+	windowRequests := requests - baseline.requests
+	windowErrors := serverErrors - baseline.errors
+	if cooling || windowRequests < errorRateMinRequests {
+		return nil
+	}
+	rate := float64(windowErrors) / float64(windowRequests)
+	if rate < s.currentSettings().ErrorRateThreshold {
+		return nil
+	}
+
+	s.rateMu.Lock()
+	s.lastRateAlert = now
+	s.rateMu.Unlock()
+
+	s.Notify(Alert{
+		Kind:  models.AlertErrorRate,
+		Title: "Error rate spike",
+		Text: fmt.Sprintf("%.1f%% of the last %d requests failed with a server error in the past %d minutes.",
+			rate*100, windowRequests, int(now.Sub(baseline.at).Round(time.Minute).Minutes())),
+	})
+	return nil
+}
+
+func (s *AlertService) post(ctx context.Context, channel models.AlertChannel, alert Alert) error {
+	endpoint := channel.URL
+	var body interface{}
+	switch channel.Type {
+	case models.AlertChannelSlack:
+		body = map[string]string{"text": "*" + alert.Title + "*\n" + alert.Text}
+	case models.AlertChannelDiscord:
+		body = map[string]string{"content": "**" + alert.Title + "**\n" + alert.Text}
+	case models.AlertChannelTelegram:
+		endpoint = s.telegramAPI + "/bot" + channel.BotToken + "/sendMessage"
+		body = map[string]string{"chat_id": channel.ChatID, "text": alert.Title + "\n" + alert.Text}
+	default:
+		return fmt.Errorf("unknown channel type %q", channel.Type)
+	}
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		// The Telegram URL holds the bot token, which must not reach the logs.
+		var urlErr *url.Error
+		if channel.Type == models.AlertChannelTelegram && errors.As(err, &urlErr) {
+			return fmt.Errorf("telegram request failed: %w", urlErr.Err)
+		}
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(response.Body, alertResponseLimit))
+		return fmt.Errorf("%s responded with status %d: %s", channel.Type, response.StatusCode, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
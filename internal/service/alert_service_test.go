@@ -0,0 +1,261 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/pkg/mail"
+)
+
+type postedAlert struct {
+	path string
+	body map[string]string
+}
+
+func newAlertServer(t *testing.T) (*httptest.Server, chan postedAlert) {
+	t.Helper()
+	posted := make(chan postedAlert, 10)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		posted <- postedAlert{path: r.URL.Path, body: body}
+	}))
+	t.Cleanup(server.Close)
+	return server, posted
+}
+
+func TestAlertSettingsValidation(t *testing.T) {
+	svc := NewAlertService(&memorySettingRepository{values: make(map[string]string)}, nil)
+
+	invalid := []models.AlertChannel{
+		{Name: "ops", Type: "email", URL: "https://example.com", Alerts: []string{"backups"}},
+		{Name: "ops", Type: "slack", URL: "http://hooks.slack.com/x", Alerts: []string{"backups"}},
+		{Name: "ops", Type: "telegram", BotToken: "123:abc", Alerts: []string{"backups"}},
+		{Name: "ops", Type: "discord", URL: "https://discord.com/api/webhooks/1", Alerts: []string{"deploys"}},
+	}
+	for _, channel := range invalid {
+		_, err := svc.UpdateSettings(models.UpdateAlertSettingsRequest{Channels: []models.AlertChannel{channel}})
+		var validationErr *AlertValidationError
+		if !errors.As(err, &validationErr) {
+			t.Fatalf("expected %+v to be rejected, got %v", channel, err)
+		}
+	}
+
+	settings, err := svc.UpdateSettings(models.UpdateAlertSettingsRequest{Channels: []models.AlertChannel{
+		{Name: " Ops ", Type: "Telegram", BotToken: "123:abc", ChatID: "-100", URL: "https://ignored", Alerts: []string{"backups", "Backups", "error_rate"}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	channel := settings.Channels[0]
+	if channel.Name != "Ops" || channel.Type != models.AlertChannelTelegram || channel.URL != "" || len(channel.Alerts) != 2 {
+		t.Fatalf("unexpected channel %+v", channel)
+	}
+	if settings.ErrorRateThreshold != defaultErrorRateThreshold {
+		t.Fatalf("expected the default threshold, got %v", settings.ErrorRateThreshold)
+	}
+}
+
+func TestAlertNotifyPostsToSubscribedChannels(t *testing.T) {
+	server, posted := newAlertServer(t)
+	svc := NewAlertService(&memorySettingRepository{values: make(map[string]string)}, nil)
+	svc.client = server.Client()
+	svc.telegramAPI = server.URL
+
+	_, err := svc.UpdateSettings(models.UpdateAlertSettingsRequest{Channels: []models.AlertChannel{
+		{Name: "slack", Type: "slack", URL: server.URL + "/slack", Alerts: []string{"backups"}},
+		{Name: "telegram", Type: "telegram", BotToken: "123:abc", ChatID: "42", Alerts: []string{"backups", "checkouts"}},
+		{Name: "discord", Type: "discord", URL: server.URL + "/discord", Alerts: []string{"comments"}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	svc.Notify(Alert{Kind: models.AlertBackups, Title: "Automatic backup failed", Text: "disk full"})
+
+	received := map[string]map[string]string{}
+	for len(received) < 2 {
+		select {
+		case alert := <-posted:
+			received[alert.path] = alert.body
+		case <-time.After(2 * time.Second):
+			t.Fatalf("expected two alerts, got %v", received)
+		}
+	}
+	if received["/slack"]["text"] != "*Automatic backup failed*\ndisk full" {
+		t.Fatalf("unexpected Slack message %v", received["/slack"])
+	}
+	if telegram := received["/bot123:abc/sendMessage"]; telegram["chat_id"] != "42" || telegram["text"] != "Automatic backup failed\ndisk full" {
+		t.Fatalf("unexpected Telegram message %v", received)
+	}
+	select {
+	case alert := <-posted:
+		t.Fatalf("expected no alert for unsubscribed channels, got %v", alert)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestCheckErrorRate(t *testing.T) {
+	server, posted := newAlertServer(t)
+	var requests, serverErrors int64
+	svc := NewAlertService(&memorySettingRepository{values: make(map[string]string)}, func() (int64, int64) {
+		return requests, serverErrors
+	})
+	svc.client = server.Client()
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	_, err := svc.UpdateSettings(models.UpdateAlertSettingsRequest{
+		Channels:           []models.AlertChannel{{Name: "ops", Type: "discord", URL: server.URL, Alerts: []string{"error_rate"}}},
+		ErrorRateThreshold: 0.1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	check := func(addRequests, addErrors int64) {
+		requests += addRequests
+		serverErrors += addErrors
+		if err := svc.CheckErrorRate(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		now = now.Add(time.Minute)
+	}
+
+	check(1000, 0)
+	check(100, 5)
+	check(100, 5)
+	select {
+	case alert := <-posted:
+		t.Fatalf("expected no alert below the threshold, got %v", alert)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	check(100, 60)
+	select {
+	case alert := <-posted:
+		if alert.body["content"] == "" {
+			t.Fatalf("unexpected alert %v", alert)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected an error rate alert")
+	}
+
+	check(100, 90)
+	select {
+	case alert := <-posted:
+		t.Fatalf("expected the cooldown to hold back repeated alerts, got %v", alert)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestAlertRuleValidation(t *testing.T) {
+	svc := NewAlertService(&memorySettingRepository{values: make(map[string]string)}, nil)
+	channels := []models.AlertChannel{{Name: "mods", Type: "slack", URL: "https://hooks.slack.com/x"}}
+
+	invalid := []models.AlertRule{
+		{Name: "r", Alert: "deploys", Emails: []string{"mods@example.com"}},
+		{Name: "r", Alert: "comments", Channels: []string{"unknown"}},
+		{Name: "r", Alert: "comments"},
+		{Name: "r", Alert: "comments", Emails: []string{"Mods <mods@example.com>"}},
+		{Name: "r", Alert: "backups", PendingOnly: true, Channels: []string{"mods"}},
+		{Name: "r", Alert: "comments", ForumCategoryIDs: []uint{3}, Channels: []string{"mods"}},
+	}
+	for _, rule := range invalid {
+		_, err := svc.UpdateSettings(models.UpdateAlertSettingsRequest{Channels: channels, Rules: []models.AlertRule{rule}})
+		var validationErr *AlertValidationError
+		if !errors.As(err, &validationErr) {
+			t.Fatalf("expected %+v to be rejected, got %v", rule, err)
+		}
+	}
+
+	// A channel without alerts must be used by a rule.
+	_, err := svc.UpdateSettings(models.UpdateAlertSettingsRequest{Channels: channels})
+	var validationErr *AlertValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected an unused channel to be rejected, got %v", err)
+	}
+
+	settings, err := svc.UpdateSettings(models.UpdateAlertSettingsRequest{Channels: channels, Rules: []models.AlertRule{
+		{Name: " moderation ", Alert: "Comments", PendingOnly: true, Channels: []string{"mods", "mods"}, Emails: []string{"Mods@Example.com"}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rule := settings.Rules[0]
+	if rule.Name != "moderation" || rule.Alert != models.AlertComments || len(rule.Channels) != 1 || rule.Emails[0] != "mods@example.com" {
+		t.Fatalf("unexpected rule %+v", rule)
+	}
+}
+
+func TestAlertRulesRouteMatchingAlerts(t *testing.T) {
+	server, posted := newAlertServer(t)
+	sent := make(chan mail.Message, 4)
+	email, _ := newQueuedEmailService(func(_ mail.Config, message mail.Message) error {
+		sent <- message
+		return nil
+	})
+	svc := NewAlertService(&memorySettingRepository{values: make(map[string]string)}, nil)
+	svc.client = server.Client()
+	svc.SetEmailService(email)
+
+	_, err := svc.UpdateSettings(models.UpdateAlertSettingsRequest{
+		Channels: []models.AlertChannel{
+			{Name: "everyone", Type: "slack", URL: server.URL + "/everyone", Alerts: []string{"comments", "forum_questions"}},
+			{Name: "support", Type: "discord", URL: server.URL + "/support"},
+		},
+		Rules: []models.AlertRule{
+			{Name: "moderation", Alert: "comments", PendingOnly: true, Emails: []string{"mods@example.com"}},
+			{Name: "support questions", Alert: "forum_questions", ForumCategoryIDs: []uint{7}, Channels: []string{"support"}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectPost := func(path string) {
+		t.Helper()
+		select {
+		case alert := <-posted:
+			if alert.path != path {
+				t.Fatalf("expected a post to %s, got %s", path, alert.path)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("expected a post to %s", path)
+		}
+		select {
+		case alert := <-posted:
+			t.Fatalf("expected a single post, also got %s", alert.path)
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+
+	svc.Notify(Alert{Kind: models.AlertForumQuestions, Title: "New forum question", CategoryID: 7})
+	expectPost("/support")
+	svc.Notify(Alert{Kind: models.AlertForumQuestions, Title: "New forum question", CategoryID: 2})
+	expectPost("/everyone")
+	svc.Notify(Alert{Kind: models.AlertComments, Title: "New comment"})
+	expectPost("/everyone")
+
+	svc.Notify(Alert{Kind: models.AlertComments, Title: "New comment awaiting approval", Text: "Comment #3 needs moderation.", Pending: true})
+	select {
+	case message := <-sent:
+		if message.To != "mods@example.com" || message.Subject != "New comment awaiting approval" || !strings.Contains(message.Text, "Comment #3") {
+			t.Fatalf("unexpected alert email %+v", message)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the moderators to be emailed")
+	}
+	select {
+	case alert := <-posted:
+		t.Fatalf("expected the routed alert to skip subscribed channels, got %s", alert.path)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,285 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"constructor-script-backend/internal/authorization"
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/repository"
+)
+
+const (
+	// announcementCacheTTL bounds how long a scheduled announcement may show
+	// after it ends, or stay hidden after it starts; edits clear the cache at once.
+	announcementCacheTTL   = 30 * time.Second
+	maxAnnouncementMessage = 500
+)
+
+var announcementStyles = map[string]bool{
+	models.AnnouncementStyleInfo:    true,
+	models.AnnouncementStyleSuccess: true,
+	models.AnnouncementStyleWarning: true,
+	models.AnnouncementStyleDanger:  true,
+}
+
+var announcementAudiences = map[string]bool{
+	models.AnnouncementAudienceAll:    true,
+	models.AnnouncementAudienceGuests: true,
+	models.AnnouncementAudienceUsers:  true,
+	models.AnnouncementAudienceAdmins: true,
+}
+
+var ErrAnnouncementNotDismissible = errors.New("announcement cannot be dismissed")
+
+type AnnouncementValidationError struct {
+	Reason string
+}
+
+func (e *AnnouncementValidationError) Error() string {
+	if e == nil {
+		return ""
+	}
+	return e.Reason
+}
+
+func announcementValidationErrorf(format string, args ...interface{}) error {
+	return &AnnouncementValidationError{Reason: fmt.Sprintf(format, args...)}
+}
+
+// AnnouncementService manages the site-wide announcement bars and decides which
+// of them a visitor sees.
+type AnnouncementService struct {
+	repo repository.AnnouncementRepository
+	now  func() time.Time
+
+	mu       sync.Mutex
+	live     []models.Announcement
+	loadedAt time.Time
+}
+
+func NewAnnouncementService(repo repository.AnnouncementRepository) *AnnouncementService {
+	return &AnnouncementService{repo: repo, now: time.Now}
+}
+
+// Live returns the enabled announcements within their schedule. The result is
+// shared and must not be modified.
+func (s *AnnouncementService) Live() ([]models.Announcement, error) {
+	if s == nil || s.repo == nil {
+		return nil, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if s.live != nil && now.Sub(s.loadedAt) < announcementCacheTTL {
+		return s.live, nil
+	}
+
+	live, err := s.repo.ListLive(now)
+	if err != nil {
+		return nil, err
+	}
+	if live == nil {
+		live = []models.Announcement{}
+	}
+	s.live = live
+	s.loadedAt = now
+	return live, nil
+}
+
+// Visible narrows the live announcements to those meant for the user, a guest
+// when user is nil, leaving out the ones the user dismissed.
+func (s *AnnouncementService) Visible(live []models.Announcement, user *models.User) ([]models.Announcement, error) {
+	if len(live) == 0 {
+		return nil, nil
+	}
+
+	var dismissed map[uint]bool
+	if user != nil && s != nil && s.repo != nil {
+		ids, err := s.repo.DismissedIDs(user.ID)
+		if err != nil {
+			return nil, err
+		}
+		dismissed = make(map[uint]bool, len(ids))
+		for _, id := range ids {
+			dismissed[id] = true
+		}
+	}
+
+	visible := make([]models.Announcement, 0, len(live))
+	for _, announcement := range live {
+		if !announcementTargets(announcement.Audience, user) {
+			continue
+		}
+		if announcement.Dismissible && dismissed[announcement.ID] {
+			continue
+		}
+		visible = append(visible, announcement)
+	}
+	return visible, nil
+}
+
+func announcementTargets(audience string, user *models.User) bool {
+	switch audience {
+	case models.AnnouncementAudienceGuests:
+		return user == nil
+	case models.AnnouncementAudienceUsers:
+		return user != nil
+	case models.AnnouncementAudienceAdmins:
+		return user != nil && user.Role == authorization.RoleAdmin
+	default:
+		return true
+	}
+}
+
+// Dismiss hides a dismissible announcement from the user for good.
+func (s *AnnouncementService) Dismiss(userID, id uint) error {
+	announcement, err := s.repo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if !announcement.Dismissible {
+		return ErrAnnouncementNotDismissible
+	}
+	return s.repo.Dismiss(userID, id)
+}
+
+func (s *AnnouncementService) List() ([]models.Announcement, error) {
+	return s.repo.List()
+}
+
+func (s *AnnouncementService) Create(req models.CreateAnnouncementRequest) (*models.Announcement, error) {
+	announcement := &models.Announcement{
+		Message:     strings.TrimSpace(req.Message),
+		Style:       strings.ToLower(strings.TrimSpace(req.Style)),
+		LinkURL:     strings.TrimSpace(req.LinkURL),
+		LinkText:    strings.TrimSpace(req.LinkText),
+		Audience:    strings.ToLower(strings.TrimSpace(req.Audience)),
+		Dismissible: true,
+		Enabled:     true,
+		StartsAt:    req.StartsAt,
+		EndsAt:      req.EndsAt,
+	}
+	if announcement.Style == "" {
+		announcement.Style = models.AnnouncementStyleInfo
+	}
+	if announcement.Audience == "" {
+		announcement.Audience = models.AnnouncementAudienceAll
+	}
+	if req.Dismissible != nil {
+		announcement.Dismissible = *req.Dismissible
+	}
+	if req.Enabled != nil {
+		announcement.Enabled = *req.Enabled
+	}
+	if err := validateAnnouncement(announcement); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(announcement); err != nil {
+		return nil, err
+	}
+	s.invalidate()
+	return announcement, nil
+}
+
+func (s *AnnouncementService) Update(id uint, req models.UpdateAnnouncementRequest) (*models.Announcement, error) {
+	announcement, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Message != nil {
+		announcement.Message = strings.TrimSpace(*req.Message)
+	}
+	if req.Style != nil {
+		announcement.Style = strings.ToLower(strings.TrimSpace(*req.Style))
+	}
+	if req.LinkURL != nil {
+		announcement.LinkURL = strings.TrimSpace(*req.LinkURL)
+	}
+	if req.LinkText != nil {
+		announcement.LinkText = strings.TrimSpace(*req.LinkText)
+	}
+	if req.Audience != nil {
+		announcement.Audience = strings.ToLower(strings.TrimSpace(*req.Audience))
+	}
+	if req.Dismissible != nil {
+		announcement.Dismissible = *req.Dismissible
+	}
+	if req.Enabled != nil {
+		announcement.Enabled = *req.Enabled
+	}
+	if req.ClearSchedule {
+		announcement.StartsAt = nil
+		announcement.EndsAt = nil
+	}
+	if req.StartsAt != nil {
+		announcement.StartsAt = req.StartsAt
+	}
+	if req.EndsAt != nil {
+		announcement.EndsAt = req.EndsAt
+	}
+	if err := validateAnnouncement(announcement); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(announcement); err != nil {
+		return nil, err
+	}
+	s.invalidate()
+	return announcement, nil
+}
+
+func (s *AnnouncementService) Delete(id uint) error {
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+	s.invalidate()
+	return nil
+}
+
+func (s *AnnouncementService) invalidate() {
+	s.mu.Lock()
+	s.live = nil
+	s.mu.Unlock()
+}
+
+func validateAnnouncement(announcement *models.Announcement) error {
+	if announcement.Message == "" {
+		return announcementValidationErrorf("message is required")
+	}
+	if len([]rune(announcement.Message)) > maxAnnouncementMessage {
+		return announcementValidationErrorf("message must be at most %d characters", maxAnnouncementMessage)
+	}
+	if !announcementStyles[announcement.Style] {
+		return announcementValidationErrorf("unknown style %q", announcement.Style)
+	}
+	if !announcementAudiences[announcement.Audience] {
+		return announcementValidationErrorf("unknown audience %q", announcement.Audience)
+	}
+	if announcement.LinkURL != "" && !isAnnouncementLink(announcement.LinkURL) {
+		return announcementValidationErrorf("link must be a site path or an http(s) URL")
+	}
+	if announcement.LinkURL == "" {
+		announcement.LinkText = ""
+	}
+	if announcement.StartsAt != nil && announcement.EndsAt != nil && !announcement.EndsAt.After(*announcement.StartsAt) {
+		return announcementValidationErrorf("ends_at must be after starts_at")
+	}
+	return nil
+}
+
+func isAnnouncementLink(raw string) bool {
+	if strings.HasPrefix(raw, "/") && !strings.HasPrefix(raw, "//") {
+		return true
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Host == "" {
+		return false
+	}
+	return parsed.Scheme == "https" || parsed.Scheme == "http"
+}
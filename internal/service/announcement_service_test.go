@@ -0,0 +1,221 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"constructor-script-backend/internal/authorization"
+	"constructor-script-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type memoryAnnouncementRepository struct {
+	announcements map[uint]models.Announcement
+	dismissed     map[uint][]uint
+	nextID        uint
+	liveQueries   int
+}
+
+func newMemoryAnnouncementRepository() *memoryAnnouncementRepository {
+	return &memoryAnnouncementRepository{
+		announcements: make(map[uint]models.Announcement),
+		dismissed:     make(map[uint][]uint),
+	}
+}
+
+func (r *memoryAnnouncementRepository) Create(announcement *models.Announcement) error {
+	r.nextID++
+	announcement.ID = r.nextID
+	r.announcements[announcement.ID] = *announcement
+	return nil
+}
+
+func (r *memoryAnnouncementRepository) Update(announcement *models.Announcement) error {
+	r.announcements[announcement.ID] = *announcement
+	return nil
+}
+
+func (r *memoryAnnouncementRepository) Delete(id uint) error {
+	if _, ok := r.announcements[id]; !ok {
+		return gorm.ErrRecordNotFound
+	}
+	delete(r.announcements, id)
+	return nil
+}
+
+func (r *memoryAnnouncementRepository) GetByID(id uint) (*models.Announcement, error) {
+	announcement, ok := r.announcements[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &announcement, nil
+}
+
+func (r *memoryAnnouncementRepository) List() ([]models.Announcement, error) {
+	var list []models.Announcement
+	for _, announcement := range r.announcements {
+		list = append(list, announcement)
+	}
+	return list, nil
+}
+
+func (r *memoryAnnouncementRepository) ListLive(at time.Time) ([]models.Announcement, error) {
+	r.liveQueries++
+	var live []models.Announcement
+	for id := uint(1); id <= r.nextID; id++ {
+		announcement, ok := r.announcements[id]
+		if !ok || !announcement.Enabled {
+			continue
+		}
+		if announcement.StartsAt != nil && announcement.StartsAt.After(at) {
+			continue
+		}
+		if announcement.EndsAt != nil && !announcement.EndsAt.After(at) {
+			continue
+		}
+		live = append(live, announcement)
+	}
+	return live, nil
+}
+
+func (r *memoryAnnouncementRepository) Dismiss(userID, announcementID uint) error {
+	r.dismissed[userID] = append(r.dismissed[userID], announcementID)
+	return nil
+}
+
+func (r *memoryAnnouncementRepository) DismissedIDs(userID uint) ([]uint, error) {
+	return r.dismissed[userID], nil
+}
+
+func TestAnnouncementValidation(t *testing.T) {
+	svc := NewAnnouncementService(newMemoryAnnouncementRepository())
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	earlier := now.Add(-time.Hour)
+
+	invalid := []models.CreateAnnouncementRequest{
+		{Message: "  "},
+		{Message: "Sale", Style: "purple"},
+		{Message: "Sale", Audience: "everyone"},
+		{Message: "Sale", LinkURL: "javascript:alert(1)"},
+		{Message: "Sale", LinkURL: "//evil.example"},
+		{Message: "Sale", StartsAt: &now, EndsAt: &earlier},
+	}
+	for _, req := range invalid {
+		_, err := svc.Create(req)
+		var validationErr *AnnouncementValidationError
+		if !errors.As(err, &validationErr) {
+			t.Fatalf("expected %+v to be rejected, got %v", req, err)
+		}
+	}
+
+	announcement, err := svc.Create(models.CreateAnnouncementRequest{Message: " Sale ends Friday ", Style: "Warning", LinkURL: "/shop", LinkText: "Shop now"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if announcement.Message != "Sale ends Friday" || announcement.Style != models.AnnouncementStyleWarning ||
+		announcement.Audience != models.AnnouncementAudienceAll || !announcement.Dismissible || !announcement.Enabled {
+		t.Fatalf("unexpected announcement %+v", announcement)
+	}
+}
+
+func TestAnnouncementScheduleAndCache(t *testing.T) {
+	repo := newMemoryAnnouncementRepository()
+	svc := NewAnnouncementService(repo)
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	startsAt := now.Add(10 * time.Second)
+	if _, err := svc.Create(models.CreateAnnouncementRequest{Message: "Maintenance starting", StartsAt: &startsAt}); err != nil {
+		t.Fatal(err)
+	}
+	live, _ := svc.Live()
+	if len(live) != 0 {
+		t.Fatalf("expected the scheduled announcement to wait, got %+v", live)
+	}
+
+	svc.Live()
+	if repo.liveQueries != 1 {
+		t.Fatalf("expected the live announcements to be cached, queried %d times", repo.liveQueries)
+	}
+
+	now = startsAt
+	if live, _ = svc.Live(); len(live) != 0 {
+		t.Fatal("expected the cache to hold until it expires")
+	}
+	now = now.Add(announcementCacheTTL)
+	if live, _ = svc.Live(); len(live) != 1 {
+		t.Fatalf("expected the announcement to start, got %+v", live)
+	}
+
+	disabled := false
+	if _, err := svc.Update(live[0].ID, models.UpdateAnnouncementRequest{Enabled: &disabled}); err != nil {
+		t.Fatal(err)
+	}
+	if live, _ = svc.Live(); len(live) != 0 {
+		t.Fatal("expected edits to take effect immediately")
+	}
+}
+
+func TestAnnouncementAudienceAndDismissal(t *testing.T) {
+	repo := newMemoryAnnouncementRepository()
+	svc := NewAnnouncementService(repo)
+	notDismissible := false
+
+	everyone, _ := svc.Create(models.CreateAnnouncementRequest{Message: "Welcome"})
+	guests, _ := svc.Create(models.CreateAnnouncementRequest{Message: "Sign up", Audience: models.AnnouncementAudienceGuests})
+	admins, _ := svc.Create(models.CreateAnnouncementRequest{Message: "Update pending", Audience: models.AnnouncementAudienceAdmins})
+	pinned, _ := svc.Create(models.CreateAnnouncementRequest{Message: "Outage", Audience: models.AnnouncementAudienceUsers, Dismissible: &notDismissible})
+
+	live, err := svc.Live()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := func(announcements []models.Announcement) []uint {
+		var result []uint
+		for _, announcement := range announcements {
+			result = append(result, announcement.ID)
+		}
+		return result
+	}
+	equal := func(got []uint, want ...uint) bool {
+		if len(got) != len(want) {
+			return false
+		}
+		for i := range got {
+			if got[i] != want[i] {
+				return false
+			}
+		}
+		return true
+	}
+
+	visible, _ := svc.Visible(live, nil)
+	if !equal(ids(visible), everyone.ID, guests.ID) {
+		t.Fatalf("unexpected announcements for guests %v", ids(visible))
+	}
+	admin := &models.User{ID: 1, Role: authorization.RoleAdmin}
+	visible, _ = svc.Visible(live, admin)
+	if !equal(ids(visible), everyone.ID, admins.ID, pinned.ID) {
+		t.Fatalf("unexpected announcements for admins %v", ids(visible))
+	}
+
+	if err := svc.Dismiss(admin.ID, pinned.ID); !errors.Is(err, ErrAnnouncementNotDismissible) {
+		t.Fatalf("expected a pinned announcement to stay, got %v", err)
+	}
+	if err := svc.Dismiss(admin.ID, 99); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("expected an unknown announcement to be rejected, got %v", err)
+	}
+	if err := svc.Dismiss(admin.ID, everyone.ID); err != nil {
+		t.Fatal(err)
+	}
+	visible, _ = svc.Visible(live, admin)
+	if !equal(ids(visible), admins.ID, pinned.ID) {
+		t.Fatalf("expected the dismissed announcement to be hidden, got %v", ids(visible))
+	}
+	visible, _ = svc.Visible(live, &models.User{ID: 2, Role: authorization.RoleUser})
+	if !equal(ids(visible), everyone.ID, pinned.ID) {
+		t.Fatalf("expected dismissals to be per user, got %v", ids(visible))
+	}
+}
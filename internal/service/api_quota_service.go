@@ -0,0 +1,342 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/repository"
+	"constructor-script-backend/pkg/cache"
+	"constructor-script-backend/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// SettingKeyAPIQuotas stores the daily API quota of signed-in users in the
+	// settings repository.
+	SettingKeyAPIQuotas = "security.api_quotas"
+
+	// apiQuotaRefresh bounds how long an instance keeps applying the quota after
+	// another instance changed it.
+	apiQuotaRefresh = 30 * time.Second
+
+	// apiUsageRetention is how long daily usage is kept.
+	apiUsageRetention = 90 * 24 * time.Hour
+
+	// Requests and rejections are buffered in these cache counter sets until
+	// FlushUsage writes them. Fields are "<YYYY-MM-DD>|<subject>".
+	apiUsageRequestCounters  = "api_usage_requests"
+	apiUsageRejectedCounters = "api_usage_rejected"
+
+	apiUsageDateLayout = "2006-01-02"
+)
+
+// APIQuotaService enforces daily request quotas per signed-in user and per
+// delivery token and keeps their usage per day. Quotas are counted in Redis when
+// it is configured, so they hold across instances; otherwise each instance counts
+// on its own.
+type APIQuotaService struct {
+	settingRepo repository.SettingRepository
+	usageRepo   repository.APIUsageRepository
+	cache       *cache.Cache
+
+	mu       sync.RWMutex
+	settings models.APIQuotaSettings
+	loadedAt time.Time
+
+	countsMu     sync.Mutex
+	countsDay    string
+	counts       map[string]int64
+	sharedFailed bool
+	prunedDay    string
+
+	now func() time.Time
+}
+
+func NewAPIQuotaService(settingRepo repository.SettingRepository, usageRepo repository.APIUsageRepository, c *cache.Cache) *APIQuotaService {
+	return &APIQuotaService{
+		settingRepo: settingRepo,
+		usageRepo:   usageRepo,
+		cache:       c,
+		counts:      make(map[string]int64),
+		now:         time.Now,
+	}
+}
+
+func (s *APIQuotaService) GetSettings() (models.APIQuotaSettings, error) {
+	var settings models.APIQuotaSettings
+	if s.settingRepo == nil {
+		return settings, nil
+	}
+
+	stored, err := s.settingRepo.Get(SettingKeyAPIQuotas)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return settings, nil
+		}
+		return settings, err
+	}
+
+	if strings.TrimSpace(stored.Value) == "" {
+		return settings, nil
+	}
+	if err := json.Unmarshal([]byte(stored.Value), &settings); err != nil {
+		return models.APIQuotaSettings{}, fmt.Errorf("failed to decode API quota settings: %w", err)
+	}
+
+	return settings, nil
+}
+
+func (s *APIQuotaService) UpdateSettings(req models.UpdateAPIQuotaSettingsRequest) (models.APIQuotaSettings, error) {
+	settings := models.APIQuotaSettings{UserRequestsPerDay: req.UserRequestsPerDay}
+
+	if s.settingRepo != nil {
+		payload, err := json.Marshal(settings)
+		if err != nil {
+			return settings, fmt.Errorf("failed to encode API quota settings: %w", err)
+		}
+		if err := s.settingRepo.Set(SettingKeyAPIQuotas, string(payload)); err != nil {
+			return settings, err
+		}
+	}
+
+	s.mu.Lock()
+	s.settings = settings
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+
+	return settings, nil
+}
+
+// UserRequestsPerDay returns the daily quota of signed-in users, zero for none.
+// When settings cannot be read, the quota loaded last stays in force.
+func (s *APIQuotaService) UserRequestsPerDay() int {
+	s.mu.RLock()
+	settings, loadedAt := s.settings, s.loadedAt
+	s.mu.RUnlock()
+	if !loadedAt.IsZero() && time.Since(loadedAt) < apiQuotaRefresh {
+		return settings.UserRequestsPerDay
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.loadedAt.IsZero() && time.Since(s.loadedAt) < apiQuotaRefresh {
+		return s.settings.UserRequestsPerDay
+	}
+
+	loaded, err := s.GetSettings()
+	s.loadedAt = time.Now()
+	if err != nil {
+		logger.Error(err, "Failed to load API quota settings", nil)
+		return s.settings.UserRequestsPerDay
+	}
+	s.settings = loaded
+	return s.settings.UserRequestsPerDay
+}
+
+// Allow counts a request of subject against a quota of limit requests per UTC
+// day and records it in the usage. A limit of zero or less only records the
+// request. When the request is refused, retryAfter is the time until midnight
+// UTC, when the quota starts over; remaining is -1 without a limit.
+func (s *APIQuotaService) Allow(subject string, limit int) (allowed bool, remaining int64, retryAfter time.Duration) {
+	now := s.now().UTC()
+	day := now.Format(apiUsageDateLayout)
+
+	allowed, remaining = true, -1
+	if limit > 0 {
+		count := s.increment(subject, day)
+		allowed = count <= int64(limit)
+		remaining = int64(limit) - count
+		if remaining < 0 {
+			remaining = 0
+		}
+	}
+
+	counters := apiUsageRequestCounters
+	if !allowed {
+		counters = apiUsageRejectedCounters
+		retryAfter = nextUTCDay(now).Sub(now)
+	}
+	if s.cache != nil {
+		if err := s.cache.AddCounter(counters, day+"|"+subject, 1); err != nil {
+			logger.Error(err, "Failed to record API usage", map[string]interface{}{"subject": subject})
+		}
+	}
+
+	return allowed, remaining, retryAfter
+}
+
+// increment counts a request of subject on day and returns the count so far.
+// When Redis fails, the instance counts on its own until it is back.
+func (s *APIQuotaService) increment(subject, day string) int64 {
+	if s.cache.Shared() {
+		count, err := s.cache.IncrementCounter("quota:"+day+":"+subject, 48*time.Hour)
+		s.countsMu.Lock()
+		failed := s.sharedFailed
+		s.sharedFailed = err != nil
+		s.countsMu.Unlock()
+		if err == nil {
+			if failed {
+				logger.Info("Shared API quota counters are available again", nil)
+			}
+			return count
+		}
+		if !failed {
+			logger.Error(err, "Failed to count API quota in the shared store, counting per instance", nil)
+		}
+	}
+
+	s.countsMu.Lock()
+	defer s.countsMu.Unlock()
+	if s.countsDay != day {
+		s.countsDay = day
+		s.counts = make(map[string]int64)
+	}
+	s.counts[subject]++
+	return s.counts[subject]
+}
+
+// FlushUsage writes the buffered usage to the database and, once a day, removes
+// the usage past its retention. Counts that could not be written are put back for
+// the next flush.
+func (s *APIQuotaService) FlushUsage(ctx context.Context) error {
+	if s == nil || s.cache == nil || s.usageRepo == nil {
+		return nil
+	}
+
+	type counts struct{ requests, rejected int64 }
+	pending := make(map[string]*counts)
+	for _, name := range []string{apiUsageRequestCounters, apiUsageRejectedCounters} {
+		buffered, err := s.cache.DrainCounters(name)
+		if err != nil {
+			return fmt.Errorf("drain API usage: %w", err)
+		}
+		for field, value := range buffered {
+			if value <= 0 {
+				continue
+			}
+			entry := pending[field]
+			if entry == nil {
+				entry = &counts{}
+				pending[field] = entry
+			}
+			if name == apiUsageRequestCounters {
+				entry.requests += value
+			} else {
+				entry.rejected += value
+			}
+		}
+	}
+
+	var firstErr error
+	for field, entry := range pending {
+		dayPart, subject, found := strings.Cut(field, "|")
+		day, err := time.Parse(apiUsageDateLayout, dayPart)
+		if !found || err != nil || subject == "" {
+			continue
+		}
+
+		err = ctx.Err()
+		if err == nil {
+			err = s.usageRepo.Add(subject, day, entry.requests, entry.rejected)
+		}
+		if err == nil {
+			continue
+		}
+		if firstErr == nil {
+			firstErr = fmt.Errorf("write API usage for %s: %w", subject, err)
+		}
+		if entry.requests > 0 {
+			s.cache.AddCounter(apiUsageRequestCounters, field, entry.requests)
+		}
+		if entry.rejected > 0 {
+			s.cache.AddCounter(apiUsageRejectedCounters, field, entry.rejected)
+		}
+	}
+	if firstErr != nil {
+		return firstErr
+	}
+
+	today := s.now().UTC().Format(apiUsageDateLayout)
+	s.countsMu.Lock()
+	prune := s.prunedDay != today
+	s.prunedDay = today
+	s.countsMu.Unlock()
+	if prune {
+		if _, err := s.usageRepo.PruneBefore(s.now().Add(-apiUsageRetention)); err != nil {
+			return fmt.Errorf("prune API usage: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Usage lists the daily usage of the last days, of every subject when subject is
+// empty. Buffered usage is written first so that the list is current.
+func (s *APIQuotaService) Usage(ctx context.Context, subject string, days int) ([]models.APIUsage, error) {
+	if s.usageRepo == nil {
+		return []models.APIUsage{}, nil
+	}
+	if err := s.FlushUsage(ctx); err != nil {
+		logger.Error(err, "Failed to flush API usage", nil)
+	}
+
+	if days <= 0 {
+		days = 1
+	}
+	today := apiUsageDay(s.now())
+	usage, err := s.usageRepo.List(subject, today.AddDate(0, 0, 1-days))
+	if err != nil {
+		return nil, err
+	}
+	if usage == nil {
+		usage = []models.APIUsage{}
+	}
+	return usage, nil
+}
+
+// Report returns the quota of subject with its usage over the last days.
+func (s *APIQuotaService) Report(ctx context.Context, subject string, limit, days int) (*models.APIUsageReport, error) {
+	usage, err := s.Usage(ctx, subject, days)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now().UTC()
+	today := apiUsageDay(now)
+	report := &models.APIUsageReport{
+		Subject:        subject,
+		RequestsPerDay: limit,
+		ResetsAt:       nextUTCDay(now),
+		Days:           usage,
+	}
+	for _, entry := range usage {
+		if entry.Day.UTC().Equal(today) {
+			report.Today += entry.Requests + entry.Rejected
+		}
+	}
+	if limit > 0 {
+		remaining := int64(limit) - report.Today
+		if remaining < 0 {
+			remaining = 0
+		}
+		report.Remaining = &remaining
+	}
+
+	return report, nil
+}
+
+func apiUsageDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func nextUTCDay(t time.Time) time.Time {
+	return apiUsageDay(t).AddDate(0, 0, 1)
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/pkg/cache"
+)
+
+type memoryAPIUsageRepository struct {
+	usage map[string]*models.APIUsage
+	fail  error
+}
+
+func (r *memoryAPIUsageRepository) Add(subject string, day time.Time, requests, rejected int64) error {
+	if r.fail != nil {
+		return r.fail
+	}
+	key := day.Format(apiUsageDateLayout) + "|" + subject
+	entry := r.usage[key]
+	if entry == nil {
+		entry = &models.APIUsage{Subject: subject, Day: apiUsageDay(day)}
+		r.usage[key] = entry
+	}
+	entry.Requests += requests
+	entry.Rejected += rejected
+	return nil
+}
+
+func (r *memoryAPIUsageRepository) List(subject string, since time.Time) ([]models.APIUsage, error) {
+	var usage []models.APIUsage
+	for _, entry := range r.usage {
+		if (subject == "" || entry.Subject == subject) && !entry.Day.Before(since) {
+			usage = append(usage, *entry)
+		}
+	}
+	return usage, nil
+}
+
+func (r *memoryAPIUsageRepository) PruneBefore(day time.Time) (int64, error) {
+	return 0, nil
+}
+
+func newTestAPIQuotaService(t *testing.T) (*APIQuotaService, *memoryAPIUsageRepository) {
+	t.Helper()
+	c, err := cache.NewCache("", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	usage := &memoryAPIUsageRepository{usage: make(map[string]*models.APIUsage)}
+	svc := NewAPIQuotaService(&memorySettingRepository{values: make(map[string]string)}, usage, c)
+	return svc, usage
+}
+
+func TestAPIQuotaServiceEnforcesDailyQuota(t *testing.T) {
+	svc, _ := newTestAPIQuotaService(t)
+	now := time.Date(2026, 3, 1, 22, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if allowed, remaining, _ := svc.Allow("user:1", 2); !allowed || remaining != int64(1-i) {
+			t.Fatalf("request %d: expected to be allowed with %d remaining, got %v %d", i+1, 1-i, allowed, remaining)
+		}
+	}
+	allowed, remaining, retryAfter := svc.Allow("user:1", 2)
+	if allowed || remaining != 0 || retryAfter != 2*time.Hour {
+		t.Fatalf("expected the third request to wait until midnight, got %v %d %s", allowed, remaining, retryAfter)
+	}
+	if allowed, _, _ := svc.Allow("user:2", 2); !allowed {
+		t.Fatal("expected other users to keep their own quota")
+	}
+
+	now = now.Add(3 * time.Hour)
+	if allowed, _, _ := svc.Allow("user:1", 2); !allowed {
+		t.Fatal("expected the quota to start over the next day")
+	}
+}
+
+func TestAPIQuotaServiceReportsFlushedUsage(t *testing.T) {
+	svc, usage := newTestAPIQuotaService(t)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	svc.Allow("token:3", 0)
+	svc.Allow("token:3", 1)
+	svc.Allow("token:3", 1)
+
+	usage.fail = errors.New("database unavailable")
+	if err := svc.FlushUsage(context.Background()); err == nil {
+		t.Fatal("expected the flush to fail")
+	}
+	usage.fail = nil
+
+	report, err := svc.Report(context.Background(), "token:3", 5, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Days) != 1 || report.Days[0].Requests != 2 || report.Days[0].Rejected != 1 {
+		t.Fatalf("expected the usage kept through the failed flush, got %+v", report.Days)
+	}
+	if report.Today != 3 || report.Remaining == nil || *report.Remaining != 2 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	if !report.ResetsAt.Equal(time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected reset time %s", report.ResetsAt)
+	}
+}
+
+func TestAPIQuotaServiceStoresSettings(t *testing.T) {
+	svc, _ := newTestAPIQuotaService(t)
+	if svc.UserRequestsPerDay() != 0 {
+		t.Fatal("expected no quota by default")
+	}
+	if _, err := svc.UpdateSettings(models.UpdateAPIQuotaSettingsRequest{UserRequestsPerDay: 500}); err != nil {
+		t.Fatal(err)
+	}
+	if svc.UserRequestsPerDay() != 500 {
+		t.Fatalf("expected the new quota, got %d", svc.UserRequestsPerDay())
+	}
+
+	reloaded := NewAPIQuotaService(svc.settingRepo, nil, nil)
+	if reloaded.UserRequestsPerDay() != 500 {
+		t.Fatal("expected the quota to be read back from settings")
+	}
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/repository"
+	"constructor-script-backend/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// SettingKeyAuditLog stores the audit log retention in the settings repository.
+	SettingKeyAuditLog = "security.audit_log"
+
+	defaultAuditLogRetentionDays = 365
+	maxAuditLogRetentionDays     = 3650
+	defaultAuditLogPageSize      = 50
+	maxAuditLogPageSize          = 200
+	auditLogPruneBatch           = 1000
+)
+
+// ErrAuditLogUnavailable is returned when the service has no repository.
+var ErrAuditLogUnavailable = errors.New("audit log is not available")
+
+// AuditLogService stores the admin audit trail and prunes it past its retention.
+type AuditLogService struct {
+	repo        repository.AuditLogRepository
+	settingRepo repository.SettingRepository
+}
+
+type AuditLogValidationError struct {
+	Reason string
+}
+
+func (e *AuditLogValidationError) Error() string {
+	if e == nil {
+		return ""
+	}
+	return e.Reason
+}
+
+func NewAuditLogService(repo repository.AuditLogRepository, settingRepo repository.SettingRepository) *AuditLogService {
+	return &AuditLogService{repo: repo, settingRepo: settingRepo}
+}
+
+// RecordAudit stores entry. A failure is logged rather than returned: the change it
+// describes has already been made.
+func (s *AuditLogService) RecordAudit(entry *models.AuditLog) {
+	if s == nil || s.repo == nil || entry == nil {
+		return
+	}
+	if err := s.repo.Create(entry); err != nil {
+		logger.Error(err, "Failed to record audit log entry", map[string]interface{}{
+			"user_id":    entry.UserID,
+			"method":     entry.Method,
+			"path":       entry.Path,
+			"request_id": entry.RequestID,
+		})
+	}
+}
+
+// List returns the entries matching filter, after bringing its page and limit into
+// range in place so the caller can report the page it got.
+func (s *AuditLogService) List(filter *models.AuditLogFilter) ([]models.AuditLog, int64, error) {
+	if s == nil || s.repo == nil {
+		return nil, 0, ErrAuditLogUnavailable
+	}
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultAuditLogPageSize
+	}
+	if filter.Limit > maxAuditLogPageSize {
+		filter.Limit = maxAuditLogPageSize
+	}
+	filter.Resource = strings.ToLower(strings.TrimSpace(filter.Resource))
+	filter.Method = strings.ToUpper(strings.TrimSpace(filter.Method))
+	return s.repo.List(*filter)
+}
+
+func (s *AuditLogService) Get(id uint) (*models.AuditLog, error) {
+	if s == nil || s.repo == nil {
+		return nil, ErrAuditLogUnavailable
+	}
+	return s.repo.GetByID(id)
+}
+
+func (s *AuditLogService) GetSettings() (models.AuditLogSettings, error) {
+	defaults := models.AuditLogSettings{RetentionDays: defaultAuditLogRetentionDays}
+	if s == nil || s.settingRepo == nil {
+		return defaults, nil
+	}
+
+	stored, err := s.settingRepo.Get(SettingKeyAuditLog)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return defaults, nil
+		}
+		return defaults, err
+	}
+	if strings.TrimSpace(stored.Value) == "" {
+		return defaults, nil
+	}
+
+	var settings models.AuditLogSettings
+	if err := json.Unmarshal([]byte(stored.Value), &settings); err != nil {
+		return defaults, fmt.Errorf("failed to decode audit log settings: %w", err)
+	}
+	return settings, nil
+}
+
+func (s *AuditLogService) UpdateSettings(req models.UpdateAuditLogSettingsRequest) (models.AuditLogSettings, error) {
+	if req.RetentionDays == nil {
+		return models.AuditLogSettings{}, &AuditLogValidationError{Reason: "retention_days is required"}
+	}
+	days := *req.RetentionDays
+	if days < 0 || days > maxAuditLogRetentionDays {
+		return models.AuditLogSettings{}, &AuditLogValidationError{
+			Reason: fmt.Sprintf("retention_days must be between 0 and %d", maxAuditLogRetentionDays),
+		}
+	}
+
+	settings := models.AuditLogSettings{RetentionDays: days}
+	if s == nil || s.settingRepo == nil {
+		return settings, nil
+	}
+
+	payload, err := json.Marshal(settings)
+	if err != nil {
+		return settings, fmt.Errorf("failed to encode audit log settings: %w", err)
+	}
+	if err := s.settingRepo.Set(SettingKeyAuditLog, string(payload)); err != nil {
+		return settings, err
+	}
+	return settings, nil
+}
+
+// Prune deletes the entries older than the retention period and returns how many
+// it removed.
+func (s *AuditLogService) Prune(ctx context.Context) (int64, error) {
+	if s == nil || s.repo == nil {
+		return 0, ErrAuditLogUnavailable
+	}
+
+	settings, err := s.GetSettings()
+	if err != nil {
+		return 0, err
+	}
+	if settings.RetentionDays <= 0 {
+		return 0, nil
+	}
+
+	cutoff := time.Now().AddDate(0, 0, -settings.RetentionDays)
+	var removed int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return removed, err
+		}
+		deleted, err := s.repo.DeleteBefore(cutoff, auditLogPruneBatch)
+		removed += deleted
+		if err != nil {
+			return removed, err
+		}
+		if deleted < auditLogPruneBatch {
+			break
+		}
+	}
+
+	if removed > 0 {
+		logger.Info("Pruned audit log", map[string]interface{}{"removed": removed, "retention_days": settings.RetentionDays})
+	}
+	return removed, nil
+}
@@ -0,0 +1,601 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+
+	"constructor-script-backend/internal/authorization"
+	"constructor-script-backend/internal/events"
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/repository"
+	"constructor-script-backend/pkg/logger"
+)
+
+const (
+	// SettingKeyOAuth stores the login providers in the settings repository.
+	SettingKeyOAuth = "auth.oauth"
+
+	oauthStateTTL       = 10 * time.Minute
+	oauthRequestTimeout = 10 * time.Second
+	maxOAuthProviders   = 10
+	maxOAuthScopes      = 20
+)
+
+var (
+	ErrOAuthUnavailable      = errors.New("login providers are not configured")
+	ErrOAuthProviderNotFound = errors.New("login provider not found")
+	ErrOAuthInvalidState     = errors.New("the login expired or was started elsewhere; please try again")
+	ErrOAuthNoAccount        = errors.New("no account is linked to this login; sign in and link it from your profile")
+	ErrOAuthIdentityTaken    = errors.New("this login is already linked to another account")
+)
+
+var (
+	oauthSlugPattern    = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,31}$`)
+	oauthUsernameUnsafe = regexp.MustCompile(`[^a-z0-9_.-]+`)
+)
+
+type OAuthValidationError struct {
+	Reason string
+}
+
+func (e *OAuthValidationError) Error() string {
+	if e == nil {
+		return ""
+	}
+	return e.Reason
+}
+
+func oauthValidationErrorf(format string, args ...interface{}) error {
+	return &OAuthValidationError{Reason: fmt.Sprintf(format, args...)}
+}
+
+// oauthState travels in a signed cookie from the start of a login to the
+// callback, so the callback only accepts codes for logins this browser began.
+type oauthState struct {
+	Provider string `json:"p"`
+	State    string `json:"s"`
+	Verifier string `json:"v"`
+	LinkUser uint   `json:"u,omitempty"`
+	Next     string `json:"n,omitempty"`
+	Expires  int64  `json:"e"`
+}
+
+// OAuthLogin is the outcome of a provider callback. Linked is set when the
+// identity was added to a signed-in user instead of signing someone in.
+type OAuthLogin struct {
+	Token  string
+	User   *models.User
+	Next   string
+	Linked bool
+	// Challenge is set instead of Token when the user still has to pass
+	// two-factor authentication.
+	Challenge *models.TwoFactorChallenge
+}
+
+// oauthProviders caches built providers, so OpenID Connect discovery runs once
+// per provider configuration.
+type oauthProviders struct {
+	mu      sync.Mutex
+	entries map[string]oauthProviderEntry
+}
+
+type oauthProviderEntry struct {
+	settings models.OAuthProviderSettings
+	provider OAuthProvider
+}
+
+// SetIdentityRepository enables signing in with OAuth providers.
+func (s *AuthService) SetIdentityRepository(repo repository.UserIdentityRepository) {
+	if s == nil {
+		return
+	}
+	s.identityRepo = repo
+}
+
+func (s *AuthService) loadOAuthSettings() (models.OAuthSettings, error) {
+	settings := models.OAuthSettings{Providers: []models.OAuthProviderSettings{}}
+	if s.settingRepo == nil {
+		return settings, nil
+	}
+
+	stored, err := s.settingRepo.Get(SettingKeyOAuth)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return settings, nil
+		}
+		return settings, err
+	}
+	if strings.TrimSpace(stored.Value) == "" {
+		return settings, nil
+	}
+	if err := json.Unmarshal([]byte(stored.Value), &settings); err != nil {
+		return settings, fmt.Errorf("failed to decode login provider settings: %w", err)
+	}
+	if settings.Providers == nil {
+		settings.Providers = []models.OAuthProviderSettings{}
+	}
+	return settings, nil
+}
+
+// GetOAuthSettings returns the providers for admins, without client secrets.
+func (s *AuthService) GetOAuthSettings() (models.OAuthSettings, error) {
+	settings, err := s.loadOAuthSettings()
+	if err != nil {
+		return settings, err
+	}
+	for i := range settings.Providers {
+		settings.Providers[i].ClientSecretSet = settings.Providers[i].ClientSecret != ""
+		settings.Providers[i].ClientSecret = ""
+	}
+	return settings, nil
+}
+
+func (s *AuthService) UpdateOAuthSettings(req models.UpdateOAuthSettingsRequest) (models.OAuthSettings, error) {
+	if s.settingRepo == nil {
+		return models.OAuthSettings{}, ErrOAuthUnavailable
+	}
+	if len(req.Providers) > maxOAuthProviders {
+		return models.OAuthSettings{}, oauthValidationErrorf("at most %d login providers can be configured", maxOAuthProviders)
+	}
+
+	current, err := s.loadOAuthSettings()
+	if err != nil {
+		return models.OAuthSettings{}, err
+	}
+	secrets := make(map[string]string, len(current.Providers))
+	for _, provider := range current.Providers {
+		secrets[provider.Slug] = provider.ClientSecret
+	}
+
+	settings := models.OAuthSettings{Providers: make([]models.OAuthProviderSettings, 0, len(req.Providers))}
+	seen := make(map[string]bool, len(req.Providers))
+	for _, provider := range req.Providers {
+		normalized, err := normalizeOAuthProvider(provider)
+		if err != nil {
+			return models.OAuthSettings{}, err
+		}
+		if seen[normalized.Slug] {
+			return models.OAuthSettings{}, oauthValidationErrorf("login provider %q is configured twice", normalized.Slug)
+		}
+		seen[normalized.Slug] = true
+
+		if normalized.ClientSecret == "" {
+			normalized.ClientSecret = secrets[normalized.Slug]
+		}
+		if normalized.Enabled && normalized.ClientSecret == "" {
+			return models.OAuthSettings{}, oauthValidationErrorf("login provider %q needs a client secret", normalized.Slug)
+		}
+		settings.Providers = append(settings.Providers, normalized)
+	}
+
+	encoded, err := json.Marshal(settings)
+	if err != nil {
+		return models.OAuthSettings{}, err
+	}
+	if err := s.settingRepo.Set(SettingKeyOAuth, string(encoded)); err != nil {
+		return models.OAuthSettings{}, err
+	}
+	return s.GetOAuthSettings()
+}
+
+func normalizeOAuthProvider(provider models.OAuthProviderSettings) (models.OAuthProviderSettings, error) {
+	provider.Slug = strings.ToLower(strings.TrimSpace(provider.Slug))
+	provider.Kind = strings.ToLower(strings.TrimSpace(provider.Kind))
+	provider.Name = strings.TrimSpace(provider.Name)
+	provider.ClientID = strings.TrimSpace(provider.ClientID)
+	provider.ClientSecret = strings.TrimSpace(provider.ClientSecret)
+	provider.ClientSecretSet = false
+	provider.Issuer = strings.TrimRight(strings.TrimSpace(provider.Issuer), "/")
+
+	if provider.Slug == "" {
+		provider.Slug = provider.Kind
+	}
+	if !oauthSlugPattern.MatchString(provider.Slug) || provider.Slug == "providers" {
+		return provider, oauthValidationErrorf("login provider slug %q must be lowercase letters, digits and dashes", provider.Slug)
+	}
+	if _, ok := oauthProviderFactory(provider.Kind); !ok {
+		return provider, oauthValidationErrorf("unknown login provider kind %q", provider.Kind)
+	}
+	if provider.Name == "" {
+		switch provider.Kind {
+		case models.OAuthProviderGoogle:
+			provider.Name = "Google"
+		case models.OAuthProviderGitHub:
+			provider.Name = "GitHub"
+		default:
+			provider.Name = provider.Slug
+		}
+	}
+	if provider.Enabled && provider.ClientID == "" {
+		return provider, oauthValidationErrorf("login provider %q needs a client ID", provider.Slug)
+	}
+
+	if provider.Kind == models.OAuthProviderOIDC {
+		issuer, err := url.Parse(provider.Issuer)
+		if err != nil || issuer.Host == "" || issuer.RawQuery != "" || issuer.Fragment != "" {
+			return provider, oauthValidationErrorf("login provider %q needs an issuer URL", provider.Slug)
+		}
+		host := issuer.Hostname()
+		if issuer.Scheme != "https" && !(issuer.Scheme == "http" && (host == "localhost" || host == "127.0.0.1")) {
+			return provider, oauthValidationErrorf("the issuer of login provider %q must use https", provider.Slug)
+		}
+	} else {
+		provider.Issuer = ""
+	}
+
+	scopes := make([]string, 0, len(provider.Scopes))
+	for _, scope := range provider.Scopes {
+		scope = strings.TrimSpace(scope)
+		if scope == "" {
+			continue
+		}
+		if strings.ContainsAny(scope, " \t\r\n\"\\") {
+			return provider, oauthValidationErrorf("invalid scope %q", scope)
+		}
+		scopes = append(scopes, scope)
+	}
+	if len(scopes) > maxOAuthScopes {
+		return provider, oauthValidationErrorf("at most %d scopes can be requested", maxOAuthScopes)
+	}
+	provider.Scopes = scopes
+	return provider, nil
+}
+
+// OAuthProviders lists the providers visitors can sign in with.
+func (s *AuthService) OAuthProviders() ([]models.OAuthProviderInfo, error) {
+	providers := []models.OAuthProviderInfo{}
+	if s.identityRepo == nil {
+		return providers, nil
+	}
+	settings, err := s.loadOAuthSettings()
+	if err != nil {
+		return nil, err
+	}
+	for _, provider := range settings.Providers {
+		if provider.Enabled {
+			providers = append(providers, models.OAuthProviderInfo{
+				Slug:     provider.Slug,
+				Kind:     provider.Kind,
+				Name:     provider.Name,
+				LoginURL: "/api/v1/auth/oauth/" + provider.Slug,
+			})
+		}
+	}
+	return providers, nil
+}
+
+func (s *AuthService) oauthProvider(slug string) (OAuthProvider, models.OAuthProviderSettings, error) {
+	if s.identityRepo == nil {
+		return nil, models.OAuthProviderSettings{}, ErrOAuthUnavailable
+	}
+	settings, err := s.loadOAuthSettings()
+	if err != nil {
+		return nil, models.OAuthProviderSettings{}, err
+	}
+
+	for _, provider := range settings.Providers {
+		if provider.Slug != slug || !provider.Enabled {
+			continue
+		}
+
+		s.oauth.mu.Lock()
+		defer s.oauth.mu.Unlock()
+		if entry, ok := s.oauth.entries[slug]; ok && sameOAuthProvider(entry.settings, provider) {
+			return entry.provider, provider, nil
+		}
+		factory, ok := oauthProviderFactory(provider.Kind)
+		if !ok {
+			return nil, provider, ErrOAuthProviderNotFound
+		}
+		client := s.httpClient
+		if client == nil {
+			client = &http.Client{Timeout: oauthRequestTimeout}
+		}
+		built, err := factory(provider, client)
+		if err != nil {
+			return nil, provider, err
+		}
+		if s.oauth.entries == nil {
+			s.oauth.entries = make(map[string]oauthProviderEntry)
+		}
+		s.oauth.entries[slug] = oauthProviderEntry{settings: provider, provider: built}
+		return built, provider, nil
+	}
+	return nil, models.OAuthProviderSettings{}, ErrOAuthProviderNotFound
+}
+
+func sameOAuthProvider(a, b models.OAuthProviderSettings) bool {
+	left, _ := json.Marshal(a)
+	right, _ := json.Marshal(b)
+	return string(left) == string(right)
+}
+
+// OAuthRedirectURI is the callback URL admins register with the provider.
+func (s *AuthService) OAuthRedirectURI(slug string) string {
+	_, baseURL := s.resolveSiteMeta()
+	return baseURL + "/api/v1/auth/oauth/" + slug + "/callback"
+}
+
+// StartOAuth begins a login with provider. It returns the provider URL to send
+// the user to and the value of the cookie that must come back with the
+// callback. With linkUserID the identity is linked to that user instead.
+func (s *AuthService) StartOAuth(ctx context.Context, slug string, linkUserID uint, next string) (string, string, error) {
+	provider, _, err := s.oauthProvider(slug)
+	if err != nil {
+		return "", "", err
+	}
+
+	state := oauthState{
+		Provider: slug,
+		State:    randomOAuthToken(),
+		Verifier: randomOAuthToken(),
+		LinkUser: linkUserID,
+		Next:     safeRedirectPath(next),
+		Expires:  time.Now().Add(oauthStateTTL).Unix(),
+	}
+	challenge := sha256.Sum256([]byte(state.Verifier))
+	authURL, err := provider.AuthURL(ctx, state.State, base64.RawURLEncoding.EncodeToString(challenge[:]), s.OAuthRedirectURI(slug))
+	if err != nil {
+		return "", "", err
+	}
+
+	cookie, err := s.signOAuthState(state)
+	if err != nil {
+		return "", "", err
+	}
+	return authURL, cookie, nil
+}
+
+// CompleteOAuth handles the provider callback: it checks state against the
+// cookie, resolves the identity and signs the user in or links the identity.
+func (s *AuthService) CompleteOAuth(ctx context.Context, slug, code, state, cookie string) (*OAuthLogin, error) {
+	saved, err := s.verifyOAuthState(cookie)
+	if err != nil || saved.Provider != slug || subtle.ConstantTimeCompare([]byte(saved.State), []byte(state)) != 1 {
+		return nil, ErrOAuthInvalidState
+	}
+	if strings.TrimSpace(code) == "" {
+		return nil, ErrOAuthInvalidState
+	}
+
+	provider, settings, err := s.oauthProvider(slug)
+	if err != nil {
+		return nil, err
+	}
+	identity, err := provider.Identity(ctx, code, saved.Verifier, s.OAuthRedirectURI(slug))
+	if err != nil {
+		return nil, err
+	}
+
+	user, linked, err := s.resolveOAuthUser(settings, identity, saved.LinkUser)
+	if err != nil {
+		return nil, err
+	}
+	result := &OAuthLogin{User: user, Next: saved.Next, Linked: linked}
+	if linked {
+		return result, nil
+	}
+	if result.Challenge, err = s.loginChallenge(user); err != nil || result.Challenge != nil {
+		return result, err
+	}
+	if result.Token, err = s.generateToken(user); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// resolveOAuthUser finds the user identity belongs to. Unknown identities are
+// linked to linkUserID when set, then to the account with the same verified
+// email, and otherwise get a new account if the provider allows sign-ups.
+func (s *AuthService) resolveOAuthUser(settings models.OAuthProviderSettings, identity *OAuthIdentity, linkUserID uint) (*models.User, bool, error) {
+	now := time.Now()
+	existing, err := s.identityRepo.Find(settings.Slug, identity.Subject)
+	switch {
+	case err == nil:
+		if linkUserID != 0 && existing.UserID != linkUserID {
+			return nil, false, ErrOAuthIdentityTaken
+		}
+		if err := s.identityRepo.Touch(existing.ID, now); err != nil {
+			logger.Warn("Failed to record login provider use", map[string]interface{}{"error": err.Error()})
+		}
+		user, err := s.userRepo.GetByID(existing.UserID)
+		return user, linkUserID != 0, err
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, false, err
+	}
+
+	var user *models.User
+	if linkUserID != 0 {
+		if user, err = s.userRepo.GetByID(linkUserID); err != nil {
+			return nil, false, err
+		}
+	} else if identity.EmailVerified && identity.Email != "" {
+		user, err = s.userRepo.GetByEmail(identity.Email)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, false, err
+		}
+	}
+	if user == nil {
+		if !settings.AllowSignup || !identity.EmailVerified || identity.Email == "" {
+			return nil, false, ErrOAuthNoAccount
+		}
+		if user, err = s.createOAuthUser(identity); err != nil {
+			return nil, false, err
+		}
+	}
+
+	link := &models.UserIdentity{
+		UserID:     user.ID,
+		Provider:   settings.Slug,
+		Subject:    identity.Subject,
+		Email:      identity.Email,
+		LastUsedAt: &now,
+	}
+	if err := s.identityRepo.Create(link); err != nil {
+		return nil, false, err
+	}
+	return user, linkUserID != 0, nil
+}
+
+// createOAuthUser registers someone who signed in with a provider. They get a
+// random password and can set their own through a password reset.
+func (s *AuthService) createOAuthUser(identity *OAuthIdentity) (*models.User, error) {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(randomOAuthToken()), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	username, err := s.availableUsername(identity)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &models.User{
+		Username: username,
+		Email:    identity.Email,
+		Password: string(hashedPassword),
+		Role:     authorization.RoleUser,
+	}
+	if err := s.userRepo.Create(user); err != nil {
+		return nil, err
+	}
+	if err := s.ensureUserAvatar(user); err != nil {
+		logger.Warn("Failed to assign placeholder avatar for new user", map[string]interface{}{
+			"user_id": user.ID,
+			"error":   err.Error(),
+		})
+	}
+
+	go s.sendWelcomeEmail(user.ID, user.Username, user.Email)
+
+	s.events.Publish(context.Background(), events.UserRegistered, map[string]interface{}{
+		"id":       user.ID,
+		"username": user.Username,
+	})
+	return user, nil
+}
+
+// availableUsername derives a free username from the identity's username, name
+// or email.
+func (s *AuthService) availableUsername(identity *OAuthIdentity) (string, error) {
+	base := ""
+	for _, candidate := range []string{identity.Username, identity.Name, strings.SplitN(identity.Email, "@", 2)[0]} {
+		candidate = strings.Trim(oauthUsernameUnsafe.ReplaceAllString(strings.ToLower(candidate), "-"), "-.")
+		if len(candidate) >= 3 {
+			base = candidate
+			break
+		}
+	}
+	if base == "" {
+		base = "user"
+	}
+	if len(base) > 40 {
+		base = base[:40]
+	}
+
+	for attempt := 0; attempt < 20; attempt++ {
+		username := base
+		if attempt > 0 {
+			username = fmt.Sprintf("%s-%d", base, attempt+1)
+		}
+		if _, err := s.userRepo.GetByUsername(username); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return username, nil
+			}
+			return "", err
+		}
+	}
+	return base + "-" + randomOAuthToken()[:8], nil
+}
+
+// UserIdentities lists the providers linked to the user.
+func (s *AuthService) UserIdentities(userID uint) ([]models.UserIdentity, error) {
+	if s.identityRepo == nil {
+		return []models.UserIdentity{}, nil
+	}
+	return s.identityRepo.ListByUser(userID)
+}
+
+func (s *AuthService) UnlinkIdentity(userID uint, provider string) error {
+	if s.identityRepo == nil {
+		return ErrOAuthUnavailable
+	}
+	return s.identityRepo.DeleteForUser(userID, strings.ToLower(strings.TrimSpace(provider)))
+}
+
+func (s *AuthService) oauthStateKey() []byte {
+	key := sha256.Sum256([]byte("oauth-state:" + s.jwtSecret))
+	return key[:]
+}
+
+func (s *AuthService) signOAuthState(state oauthState) (string, error) {
+	payload, err := json.Marshal(state)
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, s.oauthStateKey())
+	mac.Write(payload)
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil)), nil
+}
+
+func (s *AuthService) verifyOAuthState(cookie string) (*oauthState, error) {
+	encodedPayload, encodedMAC, ok := strings.Cut(cookie, ".")
+	if !ok {
+		return nil, ErrOAuthInvalidState
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return nil, ErrOAuthInvalidState
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(encodedMAC)
+	if err != nil {
+		return nil, ErrOAuthInvalidState
+	}
+	mac := hmac.New(sha256.New, s.oauthStateKey())
+	mac.Write(payload)
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return nil, ErrOAuthInvalidState
+	}
+
+	var state oauthState
+	if err := json.Unmarshal(payload, &state); err != nil {
+		return nil, ErrOAuthInvalidState
+	}
+	if time.Now().Unix() > state.Expires {
+		return nil, ErrOAuthInvalidState
+	}
+	return &state, nil
+}
+
+func randomOAuthToken() string {
+	buf := make([]byte, 32)
+	// crypto/rand.Read does not return errors since Go 1.24.
+	_, _ = rand.Read(buf)
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+// safeRedirectPath keeps next only if it is a path on this site.
+func safeRedirectPath(next string) string {
+	next = strings.TrimSpace(next)
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, "/\\") {
+		return ""
+	}
+	if parsed, err := url.Parse(next); err != nil || parsed.Host != "" || parsed.Scheme != "" {
+		return ""
+	}
+	return next
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"constructor-script-backend/internal/authorization"
+	"constructor-script-backend/internal/config"
+	"constructor-script-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type memoryUserIdentityRepository struct {
+	identities []models.UserIdentity
+}
+
+func (r *memoryUserIdentityRepository) Find(provider, subject string) (*models.UserIdentity, error) {
+	for _, identity := range r.identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			return &identity, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memoryUserIdentityRepository) ListByUser(userID uint) ([]models.UserIdentity, error) {
+	var identities []models.UserIdentity
+	for _, identity := range r.identities {
+		if identity.UserID == userID {
+			identities = append(identities, identity)
+		}
+	}
+	return identities, nil
+}
+
+func (r *memoryUserIdentityRepository) Create(identity *models.UserIdentity) error {
+	identity.ID = uint(len(r.identities) + 1)
+	r.identities = append(r.identities, *identity)
+	return nil
+}
+
+func (r *memoryUserIdentityRepository) Touch(uint, time.Time) error {
+	return nil
+}
+
+func (r *memoryUserIdentityRepository) DeleteForUser(userID uint, provider string) error {
+	for i, identity := range r.identities {
+		if identity.UserID == userID && identity.Provider == provider {
+			r.identities = append(r.identities[:i], r.identities[i+1:]...)
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
+
+// newOIDCTestServer is an OpenID Connect provider that signs in whoever the
+// code names. Tests record the PKCE challenge of each code in the returned map.
+func newOIDCTestServer(t *testing.T, users map[string]map[string]interface{}) (*httptest.Server, map[string]string) {
+	t.Helper()
+	challenges := make(map[string]string)
+	var server *httptest.Server
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 server.URL,
+			"authorization_endpoint": server.URL + "/authorize",
+			"token_endpoint":         server.URL + "/token",
+			"userinfo_endpoint":      server.URL + "/userinfo",
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		code := r.FormValue("code")
+		verifier := sha256.Sum256([]byte(r.FormValue("code_verifier")))
+		if challenges[code] != base64.RawURLEncoding.EncodeToString(verifier[:]) || r.FormValue("client_secret") != "shh" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"access_token": "token-" + code})
+	})
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		info, ok := users[r.Header.Get("Authorization")[len("Bearer token-"):]]
+		if !ok {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(info)
+	})
+	server = httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server, challenges
+}
+
+func oauthTestLogin(t *testing.T, svc *AuthService, challenges map[string]string, code string, linkUserID uint) (*OAuthLogin, error) {
+	t.Helper()
+	authURL, cookie, err := svc.StartOAuth(context.Background(), "corp", linkUserID, "/courses")
+	if err != nil {
+		t.Fatal(err)
+	}
+	target, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := target.Query().Get("redirect_uri"); got != "https://site.example/api/v1/auth/oauth/corp/callback" {
+		t.Fatalf("unexpected redirect URI %q", got)
+	}
+	challenges[code] = target.Query().Get("code_challenge")
+	return svc.CompleteOAuth(context.Background(), "corp", code, target.Query().Get("state"), cookie)
+}
+
+func TestOAuthLogin(t *testing.T) {
+	server, challenges := newOIDCTestServer(t, map[string]map[string]interface{}{
+		"reader":   {"sub": "r-1", "email": "reader@example.com", "email_verified": "true"},
+		"newcomer": {"sub": "n-1", "email": "new.comer@example.com", "email_verified": true, "name": "New Comer"},
+		"stranger": {"sub": "s-1", "email": "stranger@example.com", "email_verified": false},
+	})
+
+	users := &memoryUserRepository{users: map[uint]*models.User{
+		1: {ID: 1, Username: "reader", Email: "reader@example.com", Role: authorization.RoleUser},
+	}}
+	settings := &memorySettingRepository{values: make(map[string]string)}
+	identities := &memoryUserIdentityRepository{}
+	svc := NewAuthService(users, nil, nil, settings, nil, "jwt-secret", &config.Config{SiteURL: "https://site.example"})
+	svc.SetIdentityRepository(identities)
+
+	invalid := []models.OAuthProviderSettings{
+		{Kind: "facebook", ClientID: "id", ClientSecret: "shh"},
+		{Slug: "Corp Login", Kind: "oidc", Issuer: server.URL},
+		{Slug: "corp", Kind: "oidc", Issuer: "http://idp.example.com", ClientID: "id", ClientSecret: "shh"},
+		{Slug: "corp", Kind: "oidc", Issuer: server.URL, ClientID: "id", Enabled: true},
+	}
+	for _, provider := range invalid {
+		_, err := svc.UpdateOAuthSettings(models.UpdateOAuthSettingsRequest{Providers: []models.OAuthProviderSettings{provider}})
+		var validationErr *OAuthValidationError
+		if !errors.As(err, &validationErr) {
+			t.Fatalf("expected %+v to be rejected, got %v", provider, err)
+		}
+	}
+
+	corp := models.OAuthProviderSettings{Slug: "corp", Kind: "oidc", Issuer: server.URL + "/", ClientID: "id", ClientSecret: "shh", Enabled: true}
+	if _, err := svc.UpdateOAuthSettings(models.UpdateOAuthSettingsRequest{Providers: []models.OAuthProviderSettings{corp}}); err != nil {
+		t.Fatal(err)
+	}
+	corp.ClientSecret = ""
+	corp.AllowSignup = true
+	stored, err := svc.UpdateOAuthSettings(models.UpdateOAuthSettingsRequest{Providers: []models.OAuthProviderSettings{corp}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Providers[0].ClientSecret != "" || !stored.Providers[0].ClientSecretSet {
+		t.Fatalf("expected the secret to be kept but hidden, got %+v", stored.Providers[0])
+	}
+
+	login, err := oauthTestLogin(t, svc, challenges, "reader", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if login.User.ID != 1 || login.Token == "" || login.Next != "/courses" || len(identities.identities) != 1 {
+		t.Fatalf("expected the verified email to link the existing account, got %+v", login)
+	}
+
+	login, err = oauthTestLogin(t, svc, challenges, "newcomer", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if login.User.Username != "new-comer" || login.User.Email != "new.comer@example.com" {
+		t.Fatalf("expected a new account, got %+v", login.User)
+	}
+
+	if _, err := oauthTestLogin(t, svc, challenges, "stranger", 0); !errors.Is(err, ErrOAuthNoAccount) {
+		t.Fatalf("expected an unverified email to be refused, got %v", err)
+	}
+	if _, err := oauthTestLogin(t, svc, challenges, "reader", login.User.ID); !errors.Is(err, ErrOAuthIdentityTaken) {
+		t.Fatalf("expected a linked identity to stay with its account, got %v", err)
+	}
+
+	_, cookie, err := svc.StartOAuth(context.Background(), "corp", 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.CompleteOAuth(context.Background(), "corp", "reader", "forged", cookie); !errors.Is(err, ErrOAuthInvalidState) {
+		t.Fatalf("expected a mismatched state to be refused, got %v", err)
+	}
+	if _, err := svc.CompleteOAuth(context.Background(), "corp", "reader", "", cookie+"x"); !errors.Is(err, ErrOAuthInvalidState) {
+		t.Fatalf("expected a tampered cookie to be refused, got %v", err)
+	}
+}
+
+func TestSafeRedirectPath(t *testing.T) {
+	cases := map[string]string{
+		"/courses?page=2":      "/courses?page=2",
+		"//evil.example/":      "",
+		"/\\evil.example":      "",
+		"https://evil.example": "",
+		"courses":              "",
+	}
+	for input, want := range cases {
+		if got := safeRedirectPath(input); got != want {
+			t.Fatalf("safeRedirectPath(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
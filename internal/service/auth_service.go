@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/base64"
@@ -8,6 +9,7 @@ import (
 	"errors"
 	"fmt"
 	"mime/multipart"
+	"net/http"
 	"strings"
 	"time"
 	"unicode/utf8"
@@ -18,6 +20,7 @@ import (
 
 	"constructor-script-backend/internal/authorization"
 	"constructor-script-backend/internal/config"
+	"constructor-script-backend/internal/events"
 	"constructor-script-backend/internal/models"
 	"constructor-script-backend/internal/repository"
 	"constructor-script-backend/pkg/logger"
@@ -31,6 +34,11 @@ type AuthService struct {
 	jwtSecret     string
 	config        *config.Config
 	settingRepo   repository.SettingRepository
+	events        *events.Bus
+	identityRepo  repository.UserIdentityRepository
+	httpClient    *http.Client
+	oauth         oauthProviders
+	twoFactorRepo repository.TwoFactorRepository
 }
 
 var (
@@ -79,6 +87,14 @@ func NewAuthService(
 	}
 }
 
+// SetEventBus configures the bus used to announce account events.
+func (s *AuthService) SetEventBus(bus *events.Bus) {
+	if s == nil {
+		return
+	}
+	s.events = bus
+}
+
 func (s *AuthService) Register(req models.RegisterRequest) (*models.User, error) {
 	existingUser, err := s.userRepo.GetByEmail(req.Email)
 	if err == nil && existingUser != nil {
@@ -123,17 +139,50 @@ func (s *AuthService) Register(req models.RegisterRequest) (*models.User, error)
 		})
 	}
 
+	go s.sendWelcomeEmail(user.ID, user.Username, user.Email)
+
+	s.events.Publish(context.Background(), events.UserRegistered, map[string]interface{}{
+		"id":       user.ID,
+		"username": user.Username,
+	})
+
 	return user, nil
 }
 
-func (s *AuthService) Login(req models.LoginRequest) (string, *models.User, error) {
+func (s *AuthService) sendWelcomeEmail(userID uint, username, email string) {
+	if s.emailService == nil || !s.emailService.Enabled() {
+		return
+	}
+
+	data := map[string]interface{}{
+		"Username": username,
+	}
+	if err := s.emailService.SendTemplate(email, EmailTemplateWelcome, "Welcome", data); err != nil {
+		logger.Warn("Failed to send welcome email", map[string]interface{}{
+			"user_id": userID,
+			"error":   err.Error(),
+		})
+	}
+}
+
+// Login checks the user's password. When the user has to pass two-factor
+// authentication it returns a challenge instead of a token.
+func (s *AuthService) Login(req models.LoginRequest) (string, *models.User, *models.TwoFactorChallenge, error) {
 	user, err := s.userRepo.GetByEmail(req.Email)
 	if err != nil {
-		return "", nil, errors.New("invalid credentials")
+		return "", nil, nil, errors.New("invalid credentials")
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
-		return "", nil, errors.New("invalid credentials")
+		return "", nil, nil, errors.New("invalid credentials")
+	}
+
+	challenge, err := s.loginChallenge(user)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	if challenge != nil {
+		return "", nil, challenge, nil
 	}
 
 	if err := s.ensureUserAvatar(user); err != nil {
@@ -145,10 +194,10 @@ func (s *AuthService) Login(req models.LoginRequest) (string, *models.User, erro
 
 	token, err := s.generateToken(user)
 	if err != nil {
-		return "", nil, err
+		return "", nil, nil, err
 	}
 
-	return token, user, nil
+	return token, user, nil, nil
 }
 
 func (s *AuthService) generateToken(user *models.User) (string, error) {
@@ -186,6 +235,12 @@ func (s *AuthService) GetAllUsers(query string, limit int) ([]models.User, error
 	return s.userRepo.GetAll()
 }
 
+// UserBatches hands the users matching query, or every user, to fn in batches,
+// for exports.
+func (s *AuthService) UserBatches(query string, fn func([]models.User) error) error {
+	return s.userRepo.Batches(strings.TrimSpace(query), fn)
+}
+
 func (s *AuthService) DeleteUser(id uint) error {
 	return s.userRepo.Delete(id)
 }
@@ -471,12 +526,13 @@ func (s *AuthService) RequestPasswordReset(email string) error {
 
 	resetURL := s.buildResetURL(baseURL, token)
 	subject := fmt.Sprintf("Reset your %s password", siteName)
-	body := fmt.Sprintf(
-		"We received a request to reset your password for %s.\n\nUse the link below to set a new password. The link will expire in %d minutes.\n\n%s\n\nIf you did not request this, you can ignore this email.",
-		siteName, int(passwordResetTTL.Minutes()), resetURL,
-	)
+	data := map[string]interface{}{
+		"Username":         user.Username,
+		"ResetURL":         resetURL,
+		"ExpiresInMinutes": int(passwordResetTTL.Minutes()),
+	}
 
-	if err := s.emailService.Send(user.Email, subject, body); err != nil {
+	if err := s.emailService.SendTemplate(user.Email, EmailTemplatePasswordReset, subject, data); err != nil {
 		logger.Error(err, "Failed to send password reset email", map[string]interface{}{
 			"user_id": user.ID,
 			"email":   user.Email,
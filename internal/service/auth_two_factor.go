@@ -0,0 +1,604 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+
+	"constructor-script-backend/internal/authorization"
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/repository"
+	"constructor-script-backend/pkg/logger"
+)
+
+const (
+	// SettingKeyTwoFactor stores the roles that must use two-factor
+	// authentication in the settings repository.
+	SettingKeyTwoFactor = "auth.two_factor"
+
+	totpPeriod              = 30
+	totpDigits              = 6
+	totpSkew                = 1
+	totpSecretBytes         = 20
+	twoFactorChallengeTTL   = 5 * time.Minute
+	twoFactorMaxAttempts    = 5
+	twoFactorLockout        = 15 * time.Minute
+	twoFactorRecoveryCodes  = 10
+	twoFactorRecoveryLength = 10
+	twoFactorPurpose        = "two_factor"
+)
+
+var (
+	ErrTwoFactorUnavailable      = errors.New("two-factor authentication is not configured")
+	ErrTwoFactorInvalidChallenge = errors.New("the sign-in expired; please sign in again")
+	ErrInvalidTwoFactorCode      = errors.New("invalid authentication code")
+	ErrTwoFactorLocked           = errors.New("too many invalid codes; try again later")
+	ErrTwoFactorAlreadyEnabled   = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotEnrolled      = errors.New("two-factor authentication is not set up")
+	ErrTwoFactorRequired         = errors.New("your role requires two-factor authentication")
+)
+
+var recoveryCodeEncoding = base32.NewEncoding("abcdefghijkmnpqrstuvwxyz23456789").WithPadding(base32.NoPadding)
+
+type TwoFactorValidationError struct {
+	Reason string
+}
+
+func (e *TwoFactorValidationError) Error() string {
+	if e == nil {
+		return ""
+	}
+	return e.Reason
+}
+
+func twoFactorValidationErrorf(format string, args ...interface{}) error {
+	return &TwoFactorValidationError{Reason: fmt.Sprintf(format, args...)}
+}
+
+// SetTwoFactorRepository enables two-factor authentication.
+func (s *AuthService) SetTwoFactorRepository(repo repository.TwoFactorRepository) {
+	if s == nil {
+		return
+	}
+	s.twoFactorRepo = repo
+}
+
+func (s *AuthService) GetTwoFactorSettings() (models.TwoFactorSettings, error) {
+	settings := models.TwoFactorSettings{RequiredRoles: []authorization.UserRole{}}
+	if s.settingRepo == nil {
+		return settings, nil
+	}
+
+	stored, err := s.settingRepo.Get(SettingKeyTwoFactor)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return settings, nil
+		}
+		return settings, err
+	}
+	if strings.TrimSpace(stored.Value) == "" {
+		return settings, nil
+	}
+	if err := json.Unmarshal([]byte(stored.Value), &settings); err != nil {
+		return settings, fmt.Errorf("failed to decode two-factor settings: %w", err)
+	}
+	if settings.RequiredRoles == nil {
+		settings.RequiredRoles = []authorization.UserRole{}
+	}
+	return settings, nil
+}
+
+func (s *AuthService) UpdateTwoFactorSettings(req models.UpdateTwoFactorSettingsRequest) (models.TwoFactorSettings, error) {
+	if s.settingRepo == nil || s.twoFactorRepo == nil {
+		return models.TwoFactorSettings{}, ErrTwoFactorUnavailable
+	}
+
+	settings := models.TwoFactorSettings{RequiredRoles: []authorization.UserRole{}}
+	seen := make(map[authorization.UserRole]bool, len(req.RequiredRoles))
+	for _, value := range req.RequiredRoles {
+		role := authorization.UserRole(strings.ToLower(strings.TrimSpace(value)))
+		if !role.IsValid() {
+			return models.TwoFactorSettings{}, twoFactorValidationErrorf("unknown role %q", value)
+		}
+		if !seen[role] {
+			seen[role] = true
+			settings.RequiredRoles = append(settings.RequiredRoles, role)
+		}
+	}
+
+	encoded, err := json.Marshal(settings)
+	if err != nil {
+		return models.TwoFactorSettings{}, fmt.Errorf("failed to encode two-factor settings: %w", err)
+	}
+	if err := s.settingRepo.Set(SettingKeyTwoFactor, string(encoded)); err != nil {
+		return models.TwoFactorSettings{}, err
+	}
+	return settings, nil
+}
+
+func (s *AuthService) twoFactorRequired(user *models.User) (bool, error) {
+	settings, err := s.GetTwoFactorSettings()
+	if err != nil {
+		return false, err
+	}
+	for _, role := range settings.RequiredRoles {
+		if role == user.Role {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// loginChallenge returns the second step user must pass before signing in, or
+// nil when the password is enough.
+func (s *AuthService) loginChallenge(user *models.User) (*models.TwoFactorChallenge, error) {
+	if s.twoFactorRepo == nil {
+		return nil, nil
+	}
+	if user.TwoFactorEnabled {
+		return s.newTwoFactorChallenge(user.ID, false)
+	}
+	required, err := s.twoFactorRequired(user)
+	if err != nil || !required {
+		return nil, err
+	}
+	return s.newTwoFactorChallenge(user.ID, true)
+}
+
+// twoFactorKey signs challenge tokens. It differs from the session key so a
+// challenge can never be used as a session.
+func (s *AuthService) twoFactorKey() []byte {
+	key := sha256.Sum256([]byte("two-factor:" + s.jwtSecret))
+	return key[:]
+}
+
+func (s *AuthService) newTwoFactorChallenge(userID uint, enroll bool) (*models.TwoFactorChallenge, error) {
+	expiresAt := time.Now().Add(twoFactorChallengeTTL)
+	claims := jwt.MapClaims{
+		"sub":     strconv.FormatUint(uint64(userID), 10),
+		"purpose": twoFactorPurpose,
+		"enroll":  enroll,
+		"exp":     expiresAt.Unix(),
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.twoFactorKey())
+	if err != nil {
+		return nil, err
+	}
+	return &models.TwoFactorChallenge{
+		TwoFactorRequired:  true,
+		EnrollmentRequired: enroll,
+		ChallengeToken:     token,
+		ExpiresAt:          expiresAt.UTC(),
+	}, nil
+}
+
+func (s *AuthService) parseTwoFactorChallenge(token string) (uint, bool, error) {
+	parsed, err := jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
+		return s.twoFactorKey(), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil || !parsed.Valid {
+		return 0, false, ErrTwoFactorInvalidChallenge
+	}
+	claims, ok := parsed.Claims.(jwt.MapClaims)
+	if !ok || claims["purpose"] != twoFactorPurpose {
+		return 0, false, ErrTwoFactorInvalidChallenge
+	}
+	subject, _ := claims["sub"].(string)
+	userID, err := strconv.ParseUint(subject, 10, 32)
+	if err != nil || userID == 0 {
+		return 0, false, ErrTwoFactorInvalidChallenge
+	}
+	enroll, _ := claims["enroll"].(bool)
+	return uint(userID), enroll, nil
+}
+
+// TwoFactorStatus reports whether the user has two-factor authentication and
+// whether their role requires it.
+func (s *AuthService) TwoFactorStatus(userID uint) (*models.TwoFactorStatus, error) {
+	if s.twoFactorRepo == nil {
+		return nil, ErrTwoFactorUnavailable
+	}
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	required, err := s.twoFactorRequired(user)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &models.TwoFactorStatus{Enabled: user.TwoFactorEnabled, Required: required}
+	if !user.TwoFactorEnabled {
+		return status, nil
+	}
+	twoFactor, err := s.twoFactorRepo.Get(userID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if twoFactor != nil {
+		status.ConfirmedAt = twoFactor.ConfirmedAt
+	}
+	if status.RecoveryCodesLeft, err = s.twoFactorRepo.CountRecoveryCodes(userID); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// BeginTwoFactorEnrollment creates a new authenticator secret for the user. It
+// only protects the account once ConfirmTwoFactor accepts a code from it.
+func (s *AuthService) BeginTwoFactorEnrollment(userID uint) (*models.TwoFactorEnrollment, error) {
+	if s.twoFactorRepo == nil {
+		return nil, ErrTwoFactorUnavailable
+	}
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TwoFactorEnabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	return s.startEnrollment(user)
+}
+
+// BeginChallengeEnrollment sets up an authenticator during a login that
+// requires one. The login finishes with CompleteTwoFactorLogin.
+func (s *AuthService) BeginChallengeEnrollment(challengeToken string) (*models.TwoFactorEnrollment, error) {
+	if s.twoFactorRepo == nil {
+		return nil, ErrTwoFactorUnavailable
+	}
+	userID, enroll, err := s.parseTwoFactorChallenge(challengeToken)
+	if err != nil {
+		return nil, err
+	}
+	if !enroll {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, ErrTwoFactorInvalidChallenge
+	}
+	if user.TwoFactorEnabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	return s.startEnrollment(user)
+}
+
+func (s *AuthService) startEnrollment(user *models.User) (*models.TwoFactorEnrollment, error) {
+	secretBytes := make([]byte, totpSecretBytes)
+	if _, err := rand.Read(secretBytes); err != nil {
+		return nil, err
+	}
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(secretBytes)
+
+	twoFactor, err := s.twoFactorRepo.Get(user.ID)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		twoFactor = &models.UserTwoFactor{UserID: user.ID}
+	}
+	if twoFactor.LockedUntil != nil && time.Now().Before(*twoFactor.LockedUntil) {
+		return nil, ErrTwoFactorLocked
+	}
+	twoFactor.Secret = secret
+	twoFactor.ConfirmedAt = nil
+	twoFactor.LastUsedStep = 0
+	if err := s.twoFactorRepo.Save(twoFactor); err != nil {
+		return nil, err
+	}
+
+	siteName, _ := s.resolveSiteMeta()
+	return &models.TwoFactorEnrollment{
+		Secret:          secret,
+		ProvisioningURI: totpProvisioningURI(siteName, user.Email, secret),
+	}, nil
+}
+
+// ConfirmTwoFactor turns on two-factor authentication once the user proved
+// their app is set up, and returns their recovery codes.
+func (s *AuthService) ConfirmTwoFactor(userID uint, code string) ([]string, error) {
+	if s.twoFactorRepo == nil {
+		return nil, ErrTwoFactorUnavailable
+	}
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TwoFactorEnabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	return s.confirmEnrollment(user, code)
+}
+
+func (s *AuthService) confirmEnrollment(user *models.User, code string) ([]string, error) {
+	twoFactor, err := s.twoFactorRepo.Get(user.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTwoFactorNotEnrolled
+		}
+		return nil, err
+	}
+	if err := s.checkTwoFactorCode(twoFactor, code, false); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	twoFactor.ConfirmedAt = &now
+	if err := s.twoFactorRepo.Save(twoFactor); err != nil {
+		return nil, err
+	}
+	codes, err := s.replaceRecoveryCodes(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	user.TwoFactorEnabled = true
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// RegenerateRecoveryCodes replaces the user's recovery codes after checking a
+// code from their authenticator.
+func (s *AuthService) RegenerateRecoveryCodes(userID uint, code string) ([]string, error) {
+	if s.twoFactorRepo == nil {
+		return nil, ErrTwoFactorUnavailable
+	}
+	twoFactor, err := s.enabledTwoFactor(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkTwoFactorCode(twoFactor, code, false); err != nil {
+		return nil, err
+	}
+	return s.replaceRecoveryCodes(userID)
+}
+
+// DisableTwoFactor turns two-factor authentication off for a user who knows
+// their password, unless their role requires it.
+func (s *AuthService) DisableTwoFactor(userID uint, password string) error {
+	if s.twoFactorRepo == nil {
+		return ErrTwoFactorUnavailable
+	}
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		return ErrIncorrectPassword
+	}
+	required, err := s.twoFactorRequired(user)
+	if err != nil {
+		return err
+	}
+	if required {
+		return ErrTwoFactorRequired
+	}
+	return s.removeTwoFactor(user)
+}
+
+// ResetTwoFactor removes a user's authenticator, for admins helping someone who
+// lost theirs. Users whose role requires it set up a new one at their next
+// login.
+func (s *AuthService) ResetTwoFactor(userID uint) error {
+	if s.twoFactorRepo == nil {
+		return ErrTwoFactorUnavailable
+	}
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+	return s.removeTwoFactor(user)
+}
+
+func (s *AuthService) removeTwoFactor(user *models.User) error {
+	if err := s.twoFactorRepo.Delete(user.ID); err != nil {
+		return err
+	}
+	if !user.TwoFactorEnabled {
+		return nil
+	}
+	user.TwoFactorEnabled = false
+	return s.userRepo.Update(user)
+}
+
+// CompleteTwoFactorLogin finishes a login with a code from the user's
+// authenticator or a recovery code. For logins that set up an authenticator it
+// also returns the new recovery codes.
+func (s *AuthService) CompleteTwoFactorLogin(challengeToken, code string) (string, *models.User, []string, error) {
+	if s.twoFactorRepo == nil {
+		return "", nil, nil, ErrTwoFactorUnavailable
+	}
+	userID, enroll, err := s.parseTwoFactorChallenge(challengeToken)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return "", nil, nil, ErrTwoFactorInvalidChallenge
+	}
+
+	var recoveryCodes []string
+	if user.TwoFactorEnabled {
+		twoFactor, err := s.enabledTwoFactor(user.ID)
+		if err != nil {
+			return "", nil, nil, err
+		}
+		if err := s.checkTwoFactorCode(twoFactor, code, true); err != nil {
+			return "", nil, nil, err
+		}
+	} else {
+		if !enroll {
+			return "", nil, nil, ErrTwoFactorInvalidChallenge
+		}
+		if recoveryCodes, err = s.confirmEnrollment(user, code); err != nil {
+			return "", nil, nil, err
+		}
+	}
+
+	if err := s.ensureUserAvatar(user); err != nil {
+		logger.Warn("Failed to assign placeholder avatar during login", map[string]interface{}{
+			"user_id": user.ID,
+			"error":   err.Error(),
+		})
+	}
+	token, err := s.generateToken(user)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	return token, user, recoveryCodes, nil
+}
+
+func (s *AuthService) enabledTwoFactor(userID uint) (*models.UserTwoFactor, error) {
+	twoFactor, err := s.twoFactorRepo.Get(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTwoFactorNotEnrolled
+		}
+		return nil, err
+	}
+	if twoFactor.ConfirmedAt == nil {
+		return nil, ErrTwoFactorNotEnrolled
+	}
+	return twoFactor, nil
+}
+
+// checkTwoFactorCode accepts a current authenticator code, or an unused
+// recovery code when allowRecovery is set. Repeated failures lock the
+// authenticator for a while.
+func (s *AuthService) checkTwoFactorCode(twoFactor *models.UserTwoFactor, code string, allowRecovery bool) error {
+	now := time.Now().UTC()
+	if twoFactor.LockedUntil != nil && now.Before(*twoFactor.LockedUntil) {
+		return ErrTwoFactorLocked
+	}
+
+	code = normalizeTwoFactorCode(code)
+	accepted := false
+	if step, ok := verifyTOTP(twoFactor.Secret, code, now, twoFactor.LastUsedStep); ok {
+		twoFactor.LastUsedStep = step
+		accepted = true
+	} else if allowRecovery && len(code) == twoFactorRecoveryLength {
+		used, err := s.twoFactorRepo.UseRecoveryCode(twoFactor.UserID, hashRecoveryCode(code), now)
+		if err != nil {
+			return err
+		}
+		accepted = used
+	}
+
+	if accepted {
+		twoFactor.FailedAttempts = 0
+		twoFactor.LockedUntil = nil
+		return s.twoFactorRepo.Save(twoFactor)
+	}
+
+	twoFactor.FailedAttempts++
+	if twoFactor.FailedAttempts >= twoFactorMaxAttempts {
+		lockedUntil := now.Add(twoFactorLockout)
+		twoFactor.LockedUntil = &lockedUntil
+		twoFactor.FailedAttempts = 0
+	}
+	if err := s.twoFactorRepo.Save(twoFactor); err != nil {
+		return err
+	}
+	return ErrInvalidTwoFactorCode
+}
+
+func (s *AuthService) replaceRecoveryCodes(userID uint) ([]string, error) {
+	codes := make([]string, twoFactorRecoveryCodes)
+	hashes := make([]string, twoFactorRecoveryCodes)
+	buf := make([]byte, 8)
+	for i := range codes {
+		if _, err := rand.Read(buf); err != nil {
+			return nil, err
+		}
+		code := recoveryCodeEncoding.EncodeToString(buf)[:twoFactorRecoveryLength]
+		hashes[i] = hashRecoveryCode(code)
+		codes[i] = code[:5] + "-" + code[5:]
+	}
+	if err := s.twoFactorRepo.ReplaceRecoveryCodes(userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func hashRecoveryCode(code string) string {
+	sum := sha256.Sum256([]byte("recovery:" + code))
+	return hex.EncodeToString(sum[:])
+}
+
+// normalizeTwoFactorCode drops the spaces and dashes people type between
+// groups of digits or letters.
+func normalizeTwoFactorCode(code string) string {
+	return strings.ToLower(strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(code)))
+}
+
+func totpProvisioningURI(issuer, account, secret string) string {
+	issuer = strings.TrimSpace(strings.ReplaceAll(issuer, ":", ""))
+	label := account
+	if issuer != "" {
+		label = issuer + ":" + account
+	}
+	query := url.Values{}
+	query.Set("secret", secret)
+	if issuer != "" {
+		query.Set("issuer", issuer)
+	}
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", strconv.Itoa(totpDigits))
+	query.Set("period", strconv.Itoa(totpPeriod))
+	return "otpauth://totp/" + url.PathEscape(label) + "?" + query.Encode()
+}
+
+// totpCode computes the RFC 6238 code of secret for a time step.
+func totpCode(secret string, step int64) (string, error) {
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", err
+	}
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", totpDigits, value%1000000), nil
+}
+
+// verifyTOTP accepts a code for the current time step or the ones next to it,
+// allowing for clock drift, but never for a step at or before lastStep.
+func verifyTOTP(secret, code string, now time.Time, lastStep int64) (int64, bool) {
+	if len(code) != totpDigits {
+		return 0, false
+	}
+	current := now.Unix() / totpPeriod
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		if step <= lastStep {
+			continue
+		}
+		expected, err := totpCode(secret, step)
+		if err != nil {
+			return 0, false
+		}
+		if hmac.Equal([]byte(expected), []byte(code)) {
+			return step, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,232 @@
+package service
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+
+	"constructor-script-backend/internal/authorization"
+	"constructor-script-backend/internal/config"
+	"constructor-script-backend/internal/models"
+)
+
+type memoryTwoFactorRepository struct {
+	authenticators map[uint]models.UserTwoFactor
+	codes          map[uint]map[string]bool
+}
+
+func (r *memoryTwoFactorRepository) Get(userID uint) (*models.UserTwoFactor, error) {
+	twoFactor, ok := r.authenticators[userID]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &twoFactor, nil
+}
+
+func (r *memoryTwoFactorRepository) Save(twoFactor *models.UserTwoFactor) error {
+	r.authenticators[twoFactor.UserID] = *twoFactor
+	return nil
+}
+
+func (r *memoryTwoFactorRepository) Delete(userID uint) error {
+	delete(r.authenticators, userID)
+	delete(r.codes, userID)
+	return nil
+}
+
+func (r *memoryTwoFactorRepository) ReplaceRecoveryCodes(userID uint, hashes []string) error {
+	r.codes[userID] = make(map[string]bool, len(hashes))
+	for _, hash := range hashes {
+		r.codes[userID][hash] = false
+	}
+	return nil
+}
+
+func (r *memoryTwoFactorRepository) UseRecoveryCode(userID uint, hash string, at time.Time) (bool, error) {
+	used, ok := r.codes[userID][hash]
+	if !ok || used {
+		return false, nil
+	}
+	r.codes[userID][hash] = true
+	return true, nil
+}
+
+func (r *memoryTwoFactorRepository) CountRecoveryCodes(userID uint) (int64, error) {
+	var count int64
+	for _, used := range r.codes[userID] {
+		if !used {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func currentTOTP(t *testing.T, secret string, offset int64) string {
+	t.Helper()
+	code, err := totpCode(secret, time.Now().Unix()/totpPeriod+offset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+func TestTOTPCode(t *testing.T) {
+	// RFC 6238 test vector for SHA-1 at 59 seconds.
+	secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	code, err := totpCode(secret, 59/totpPeriod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code != "287082" {
+		t.Fatalf("expected 287082, got %s", code)
+	}
+
+	now := time.Unix(59, 0)
+	step, ok := verifyTOTP(secret, code, now, 0)
+	if !ok {
+		t.Fatal("expected the code to be accepted")
+	}
+	if _, ok := verifyTOTP(secret, code, now, step); ok {
+		t.Fatal("expected a used code to be refused")
+	}
+}
+
+func TestTwoFactorLogin(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret-password"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	users := &memoryUserRepository{users: map[uint]*models.User{
+		1: {ID: 1, Username: "admin", Email: "admin@example.com", Password: string(hash), Role: authorization.RoleAdmin},
+		2: {ID: 2, Username: "reader", Email: "reader@example.com", Password: string(hash), Role: authorization.RoleUser},
+	}}
+	settings := &memorySettingRepository{values: make(map[string]string)}
+	repo := &memoryTwoFactorRepository{authenticators: map[uint]models.UserTwoFactor{}, codes: map[uint]map[string]bool{}}
+	svc := NewAuthService(users, nil, nil, settings, nil, "jwt-secret", &config.Config{SiteURL: "https://site.example"})
+	svc.SetTwoFactorRepository(repo)
+
+	login := models.LoginRequest{Email: "reader@example.com", Password: "secret-password"}
+	if token, _, challenge, err := svc.Login(login); err != nil || token == "" || challenge != nil {
+		t.Fatalf("expected a plain login without two-factor authentication, got %v %+v", err, challenge)
+	}
+
+	enrollment, err := svc.BeginTwoFactorEnrollment(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	uri, err := url.Parse(enrollment.ProvisioningURI)
+	if err != nil || uri.Scheme != "otpauth" || uri.Query().Get("secret") != enrollment.Secret {
+		t.Fatalf("unexpected provisioning URI %q", enrollment.ProvisioningURI)
+	}
+	if _, err := svc.ConfirmTwoFactor(2, "000000"); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Fatalf("expected a wrong code to be refused, got %v", err)
+	}
+	enrollCode := currentTOTP(t, enrollment.Secret, 0)
+	codes, err := svc.ConfirmTwoFactor(2, enrollCode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != twoFactorRecoveryCodes || !users.users[2].TwoFactorEnabled {
+		t.Fatalf("expected two-factor authentication to be enabled with recovery codes, got %v", codes)
+	}
+	for hash := range repo.codes[2] {
+		if strings.Contains(strings.Join(codes, ","), hash) {
+			t.Fatal("expected recovery codes to be stored hashed")
+		}
+	}
+
+	token, _, challenge, err := svc.Login(login)
+	if err != nil || token != "" || challenge == nil || challenge.EnrollmentRequired {
+		t.Fatalf("expected a login challenge, got %q %+v %v", token, challenge, err)
+	}
+	if _, err := svc.ValidateToken(challenge.ChallengeToken); err == nil {
+		t.Fatal("expected the challenge not to work as a session token")
+	}
+	if _, _, _, err := svc.CompleteTwoFactorLogin(challenge.ChallengeToken, enrollCode); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Fatalf("expected a code used for enrollment to be refused, got %v", err)
+	}
+	token, user, _, err := svc.CompleteTwoFactorLogin(challenge.ChallengeToken, strings.ToUpper(codes[0]))
+	if err != nil || token == "" || user.ID != 2 {
+		t.Fatalf("expected a recovery code to finish the login, got %v", err)
+	}
+	if _, _, _, err := svc.CompleteTwoFactorLogin(challenge.ChallengeToken, codes[0]); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Fatalf("expected a recovery code to work once, got %v", err)
+	}
+	if left, _ := repo.CountRecoveryCodes(2); left != twoFactorRecoveryCodes-1 {
+		t.Fatalf("expected one recovery code to be used, got %d left", left)
+	}
+	if _, _, _, err := svc.CompleteTwoFactorLogin(challenge.ChallengeToken+"x", codes[1]); !errors.Is(err, ErrTwoFactorInvalidChallenge) {
+		t.Fatalf("expected a tampered challenge to be refused, got %v", err)
+	}
+
+	for i := 0; i < twoFactorMaxAttempts; i++ {
+		svc.CompleteTwoFactorLogin(challenge.ChallengeToken, "000000")
+	}
+	if _, _, _, err := svc.CompleteTwoFactorLogin(challenge.ChallengeToken, codes[1]); !errors.Is(err, ErrTwoFactorLocked) {
+		t.Fatalf("expected repeated failures to lock two-factor authentication, got %v", err)
+	}
+
+	if err := svc.DisableTwoFactor(2, "wrong"); !errors.Is(err, ErrIncorrectPassword) {
+		t.Fatalf("expected the password to be checked, got %v", err)
+	}
+	if err := svc.DisableTwoFactor(2, "secret-password"); err != nil {
+		t.Fatal(err)
+	}
+	if users.users[2].TwoFactorEnabled || len(repo.authenticators) != 0 || len(repo.codes) != 0 {
+		t.Fatal("expected the authenticator and recovery codes to be removed")
+	}
+}
+
+func TestTwoFactorRequiredRole(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret-password"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	users := &memoryUserRepository{users: map[uint]*models.User{
+		1: {ID: 1, Username: "admin", Email: "admin@example.com", Password: string(hash), Role: authorization.RoleAdmin},
+	}}
+	settings := &memorySettingRepository{values: make(map[string]string)}
+	repo := &memoryTwoFactorRepository{authenticators: map[uint]models.UserTwoFactor{}, codes: map[uint]map[string]bool{}}
+	svc := NewAuthService(users, nil, nil, settings, nil, "jwt-secret", &config.Config{SiteURL: "https://site.example"})
+	svc.SetTwoFactorRepository(repo)
+
+	var validationErr *TwoFactorValidationError
+	if _, err := svc.UpdateTwoFactorSettings(models.UpdateTwoFactorSettingsRequest{RequiredRoles: []string{"owner"}}); !errors.As(err, &validationErr) {
+		t.Fatalf("expected an unknown role to be rejected, got %v", err)
+	}
+	if _, err := svc.UpdateTwoFactorSettings(models.UpdateTwoFactorSettingsRequest{RequiredRoles: []string{"Admin", "admin"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, challenge, err := svc.Login(models.LoginRequest{Email: "admin@example.com", Password: "secret-password"})
+	if err != nil || challenge == nil || !challenge.EnrollmentRequired {
+		t.Fatalf("expected the admin to be asked to set up two-factor authentication, got %+v %v", challenge, err)
+	}
+	enrollment, err := svc.BeginChallengeEnrollment(challenge.ChallengeToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, _, codes, err := svc.CompleteTwoFactorLogin(challenge.ChallengeToken, currentTOTP(t, enrollment.Secret, 0))
+	if err != nil || token == "" || len(codes) != twoFactorRecoveryCodes {
+		t.Fatalf("expected the login to finish with recovery codes, got %v", err)
+	}
+	if _, err := svc.BeginChallengeEnrollment(challenge.ChallengeToken); !errors.Is(err, ErrTwoFactorAlreadyEnabled) {
+		t.Fatalf("expected the challenge not to replace an enabled authenticator, got %v", err)
+	}
+
+	if err := svc.DisableTwoFactor(1, "secret-password"); !errors.Is(err, ErrTwoFactorRequired) {
+		t.Fatalf("expected a required authenticator to stay on, got %v", err)
+	}
+	if err := svc.ResetTwoFactor(1); err != nil {
+		t.Fatal(err)
+	}
+	status, err := svc.TwoFactorStatus(1)
+	if err != nil || status.Enabled || !status.Required {
+		t.Fatalf("expected a reset to leave the role requirement, got %+v %v", status, err)
+	}
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// backupDumpEntry is the archive entry holding the pg_dump output, in the custom
+// format read by pg_restore.
+const backupDumpEntry = "database.dump"
+
+// BackupDumpConfig adds a pg_dump of the whole database to every archive, for
+// restores with native tooling when the logical restore does not fit.
+type BackupDumpConfig struct {
+	// Command is the pg_dump binary, looked up in PATH when it is not a path.
+	Command     string
+	DatabaseURL string
+}
+
+type backupDumper struct {
+	command  string
+	dbname   string
+	password string
+}
+
+func newBackupDumper(cfg BackupDumpConfig) (*backupDumper, error) {
+	if strings.TrimSpace(cfg.DatabaseURL) == "" {
+		return nil, fmt.Errorf("database url is required for pg_dump")
+	}
+	command := strings.TrimSpace(cfg.Command)
+	if command == "" {
+		command = "pg_dump"
+	}
+	resolved, err := exec.LookPath(command)
+	if err != nil {
+		return nil, fmt.Errorf("pg_dump not found: %w", err)
+	}
+
+	dumper := &backupDumper{command: resolved, dbname: cfg.DatabaseURL}
+
+	// Hand the password over in the environment so it does not show in the
+	// process list. Key/value connection strings are passed on unchanged.
+	if parsed, err := url.Parse(cfg.DatabaseURL); err == nil && parsed.User != nil {
+		if password, ok := parsed.User.Password(); ok {
+			dumper.password = password
+			parsed.User = url.User(parsed.User.Username())
+			dumper.dbname = parsed.String()
+		}
+	}
+
+	return dumper, nil
+}
+
+// writeTo streams a dump of the database into a new archive entry. The dump runs
+// in its own snapshot, so it may differ slightly from the logical data when the
+// site is written to meanwhile.
+func (d *backupDumper) writeTo(ctx context.Context, writer *zip.Writer, modTime time.Time) error {
+	header := &zip.FileHeader{
+		Name: backupDumpEntry,
+		// The custom format is compressed already.
+		Method: zip.Store,
+	}
+	header.SetModTime(modTime)
+	entry, err := writer.CreateHeader(header)
+	if err != nil {
+		return fmt.Errorf("failed to create database dump entry: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, d.command, "--format=custom", "--no-owner", "--no-acl", "--dbname="+d.dbname)
+	if d.password != "" {
+		cmd.Env = append(os.Environ(), "PGPASSWORD="+d.password)
+	}
+	var stderr bytes.Buffer
+	cmd.Stdout = entry
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		message := strings.TrimSpace(stderr.String())
+		if len(message) > 512 {
+			message = message[len(message)-512:]
+		}
+		return fmt.Errorf("pg_dump failed: %w: %s", err, message)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func fakePGDump(t *testing.T, script string) string {
+	t.Helper()
+	command := filepath.Join(t.TempDir(), "pg_dump")
+	if err := os.WriteFile(command, []byte("#!/bin/sh\n"+script+"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return command
+}
+
+func TestBackupDumperStreamsIntoArchive(t *testing.T) {
+	command := fakePGDump(t, `printf '%s|%s' "$PGPASSWORD" "$*"`)
+	dumper, err := newBackupDumper(BackupDumpConfig{
+		Command:     command,
+		DatabaseURL: "postgres://cms:s3cret@db:5432/cms?sslmode=disable",
+	})
+	if err != nil {
+		t.Fatalf("newBackupDumper: %v", err)
+	}
+
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	if err := dumper.writeTo(context.Background(), writer, time.Now()); err != nil {
+		t.Fatalf("writeTo: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reader.File) != 1 || reader.File[0].Name != backupDumpEntry {
+		t.Fatalf("expected a single %s entry, got %+v", backupDumpEntry, reader.File)
+	}
+	rc, err := reader.File[0].Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, _ := io.ReadAll(rc)
+	rc.Close()
+
+	password, args, _ := strings.Cut(string(output), "|")
+	if password != "s3cret" {
+		t.Fatalf("expected the password in the environment, got %q", password)
+	}
+	if strings.Contains(args, "s3cret") || !strings.Contains(args, "--format=custom") || !strings.Contains(args, "--dbname=postgres://cms@db:5432/cms?sslmode=disable") {
+		t.Fatalf("unexpected pg_dump arguments %q", args)
+	}
+}
+
+func TestBackupDumperReportsFailure(t *testing.T) {
+	command := fakePGDump(t, `echo "server version mismatch" >&2; exit 1`)
+	dumper, err := newBackupDumper(BackupDumpConfig{Command: command, DatabaseURL: "host=db dbname=cms"})
+	if err != nil {
+		t.Fatalf("newBackupDumper: %v", err)
+	}
+
+	err = dumper.writeTo(context.Background(), zip.NewWriter(io.Discard), time.Now())
+	if err == nil || !strings.Contains(err.Error(), "server version mismatch") {
+		t.Fatalf("expected the pg_dump error output, got %v", err)
+	}
+}
+
+func TestNewBackupDumperRequiresCommand(t *testing.T) {
+	_, err := newBackupDumper(BackupDumpConfig{Command: filepath.Join(t.TempDir(), "missing"), DatabaseURL: "postgres://db/cms"})
+	if err == nil {
+		t.Fatal("expected an error for a missing pg_dump")
+	}
+}
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"constructor-script-backend/pkg/logger"
+)
+
+const (
+	BackupLocationLocal = "local"
+	BackupLocationS3    = "s3"
+	BackupLocationGCS   = "gcs"
+	BackupLocationAzure = "azure"
+
+	// autoBackupDirName is the directory under the upload directory that keeps the
+	// automatic backups.
+	autoBackupDirName = "auto-backups"
+	// backupSummarySuffix names the file stored next to a local archive that keeps
+	// its summary, since encrypted archives cannot be opened to read the manifest.
+	backupSummarySuffix = ".json"
+
+	maxBackupListPages = 20
+)
+
+var ErrBackupNotFound = errors.New("backup not found")
+
+var backupFilenamePattern = regexp.MustCompile(`^backup-(\d{8}-\d{6})\.zip(\.enc)?$`)
+
+// BackupFile is a stored backup archive, kept locally or in object storage.
+type BackupFile struct {
+	Name      string         `json:"name"`
+	Location  string         `json:"location"`
+	Size      int64          `json:"size"`
+	CreatedAt time.Time      `json:"created_at"`
+	Encrypted bool           `json:"encrypted"`
+	Summary   *BackupSummary `json:"summary,omitempty"`
+}
+
+func (s *BackupService) autoBackupDir() string {
+	return filepath.Join(s.uploadDir, autoBackupDirName)
+}
+
+// ListBackups returns the automatic backups kept locally and, when object storage
+// is configured, those stored there, newest first.
+func (s *BackupService) ListBackups(ctx context.Context) ([]BackupFile, error) {
+	if s == nil {
+		return nil, fmt.Errorf("backup service not configured")
+	}
+
+	backups, err := s.listLocalBackups()
+	if err != nil {
+		return nil, err
+	}
+
+	if s.storage != nil {
+		remote, err := s.storage.List(ctx)
+		if err != nil {
+			return nil, err
+		}
+		backups = append(backups, remote...)
+	}
+
+	dir := s.autoBackupDir()
+	for i := range backups {
+		if backups[i].Summary == nil {
+			backups[i].Summary = readBackupSummary(filepath.Join(dir, backups[i].Name+backupSummarySuffix))
+		}
+	}
+
+	sort.SliceStable(backups, func(i, j int) bool {
+		return backups[i].CreatedAt.After(backups[j].CreatedAt)
+	})
+	return backups, nil
+}
+
+func (s *BackupService) listLocalBackups() ([]BackupFile, error) {
+	entries, err := os.ReadDir(s.autoBackupDir())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read automatic backup directory: %w", err)
+	}
+
+	backups := make([]BackupFile, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !backupFilenamePattern.MatchString(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read backup info for %s: %w", entry.Name(), err)
+		}
+		backups = append(backups, newBackupFile(entry.Name(), BackupLocationLocal, info.Size(), info.ModTime()))
+	}
+	return backups, nil
+}
+
+func newBackupFile(name, location string, size int64, modTime time.Time) BackupFile {
+	createdAt := modTime.UTC()
+	if match := backupFilenamePattern.FindStringSubmatch(name); match != nil {
+		if parsed, err := time.Parse("20060102-150405", match[1]); err == nil {
+			createdAt = parsed
+		}
+	}
+	return BackupFile{
+		Name:      name,
+		Location:  location,
+		Size:      size,
+		CreatedAt: createdAt,
+		Encrypted: strings.HasSuffix(name, ".enc"),
+	}
+}
+
+// latestLocalBackup returns when the newest local automatic backup was created.
+func (s *BackupService) latestLocalBackup() time.Time {
+	backups, err := s.listLocalBackups()
+	if err != nil {
+		return time.Time{}
+	}
+	var latest time.Time
+	for _, backup := range backups {
+		if backup.CreatedAt.After(latest) {
+			latest = backup.CreatedAt
+		}
+	}
+	return latest
+}
+
+// OpenBackup opens a stored archive for download. The caller closes the reader.
+func (s *BackupService) OpenBackup(ctx context.Context, location, name string) (io.ReadCloser, BackupFile, error) {
+	if s == nil {
+		return nil, BackupFile{}, fmt.Errorf("backup service not configured")
+	}
+	if !backupFilenamePattern.MatchString(name) {
+		return nil, BackupFile{}, ErrBackupNotFound
+	}
+
+	switch location {
+	case BackupLocationLocal:
+		file, err := os.Open(filepath.Join(s.autoBackupDir(), name))
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return nil, BackupFile{}, ErrBackupNotFound
+			}
+			return nil, BackupFile{}, fmt.Errorf("failed to open backup: %w", err)
+		}
+		info, err := file.Stat()
+		if err != nil {
+			file.Close()
+			return nil, BackupFile{}, fmt.Errorf("failed to inspect backup: %w", err)
+		}
+		return file, newBackupFile(name, location, info.Size(), info.ModTime()), nil
+	default:
+		if s.storage == nil || location != s.storage.Location() {
+			return nil, BackupFile{}, ErrBackupNotFound
+		}
+		return s.storage.Get(ctx, name)
+	}
+}
+
+// DeleteBackup removes a stored archive together with its summary.
+func (s *BackupService) DeleteBackup(ctx context.Context, location, name string) error {
+	if s == nil {
+		return fmt.Errorf("backup service not configured")
+	}
+	if !backupFilenamePattern.MatchString(name) {
+		return ErrBackupNotFound
+	}
+
+	switch location {
+	case BackupLocationLocal:
+		target := filepath.Join(s.autoBackupDir(), name)
+		if err := os.Remove(target); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return ErrBackupNotFound
+			}
+			return fmt.Errorf("failed to delete backup: %w", err)
+		}
+		removeBackupSummary(target)
+	default:
+		if s.storage == nil || location != s.storage.Location() {
+			return ErrBackupNotFound
+		}
+		if err := s.storage.Delete(ctx, name); err != nil {
+			return err
+		}
+	}
+
+	logger.Info("Backup deleted", map[string]interface{}{"location": location, "name": name})
+	return nil
+}
+
+func writeBackupSummary(archivePath string, summary BackupSummary) error {
+	payload, err := json.Marshal(summary)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(archivePath+backupSummarySuffix, payload, 0o644)
+}
+
+func readBackupSummary(summaryPath string) *BackupSummary {
+	payload, err := os.ReadFile(summaryPath)
+	if err != nil {
+		return nil
+	}
+	var summary BackupSummary
+	if err := json.Unmarshal(payload, &summary); err != nil {
+		return nil
+	}
+	return &summary
+}
+
+func removeBackupSummary(archivePath string) {
+	if err := os.Remove(archivePath + backupSummarySuffix); err != nil && !errors.Is(err, os.ErrNotExist) {
+		logger.Warn("Failed to remove backup summary", map[string]interface{}{"path": archivePath, "error": err.Error()})
+	}
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestListBackupsLocal(t *testing.T) {
+	uploadDir := t.TempDir()
+	svc := NewBackupService(nil, nil, BackupOptions{UploadDir: uploadDir})
+	dir := filepath.Join(uploadDir, autoBackupDirName)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	older := filepath.Join(dir, "backup-20250101-120000.zip")
+	newer := filepath.Join(dir, "backup-20250102-120000.zip.enc")
+	for _, name := range []string{older, newer, filepath.Join(dir, "notes.txt")} {
+		if err := os.WriteFile(name, []byte("archive"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writeBackupSummary(older, BackupSummary{Posts: 3}); err != nil {
+		t.Fatal(err)
+	}
+
+	backups, err := svc.ListBackups(context.Background())
+	if err != nil {
+		t.Fatalf("ListBackups: %v", err)
+	}
+	if len(backups) != 2 {
+		t.Fatalf("expected 2 backups, got %+v", backups)
+	}
+	if backups[0].Name != filepath.Base(newer) || !backups[0].Encrypted || backups[0].Summary != nil {
+		t.Fatalf("unexpected newest backup: %+v", backups[0])
+	}
+	if backups[1].Summary == nil || backups[1].Summary.Posts != 3 {
+		t.Fatalf("expected the summary of the older backup, got %+v", backups[1].Summary)
+	}
+
+	settings, err := svc.GetAutoSettings()
+	if err != nil {
+		t.Fatalf("GetAutoSettings: %v", err)
+	}
+	if settings.LastRun == nil || !settings.LastRun.Equal(backups[0].CreatedAt) {
+		t.Fatalf("expected last run from the newest archive, got %v", settings.LastRun)
+	}
+
+	if err := svc.DeleteBackup(context.Background(), BackupLocationLocal, filepath.Base(older)); err != nil {
+		t.Fatalf("DeleteBackup: %v", err)
+	}
+	if _, err := os.Stat(older + backupSummarySuffix); !os.IsNotExist(err) {
+		t.Fatalf("expected the summary to be removed, got %v", err)
+	}
+
+	for _, name := range []string{"../secret.zip", "notes.txt", filepath.Base(older)} {
+		if err := svc.DeleteBackup(context.Background(), BackupLocationLocal, name); !errors.Is(err, ErrBackupNotFound) {
+			t.Fatalf("DeleteBackup(%q): expected not found, got %v", name, err)
+		}
+	}
+}
+
+func TestBackupS3ListGetDelete(t *testing.T) {
+	deleted := ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 ") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/bucket/":
+			if r.URL.Query().Get("prefix") != "site/" {
+				t.Errorf("unexpected prefix %q", r.URL.Query().Get("prefix"))
+			}
+			io.WriteString(w, `<ListBucketResult>
+				<Contents><Key>site/backup-20250103-080000.zip</Key><Size>42</Size><LastModified>2025-01-03T08:00:05Z</LastModified></Contents>
+				<Contents><Key>site/nested/backup-20250103-090000.zip</Key><Size>1</Size><LastModified>2025-01-03T09:00:05Z</LastModified></Contents>
+				<Contents><Key>site/readme.txt</Key><Size>1</Size><LastModified>2025-01-03T09:00:05Z</LastModified></Contents>
+				<IsTruncated>false</IsTruncated>
+			</ListBucketResult>`)
+		case r.Method == http.MethodGet && r.URL.Path == "/bucket/site/backup-20250103-080000.zip":
+			io.WriteString(w, "zipdata")
+		case r.Method == http.MethodGet:
+			w.WriteHeader(http.StatusNotFound)
+		case r.Method == http.MethodDelete:
+			deleted = r.URL.Path
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	defer server.Close()
+
+	svc := NewBackupService(nil, nil, BackupOptions{
+		UploadDir: t.TempDir(),
+		S3: &BackupS3Config{
+			Endpoint:  strings.TrimPrefix(server.URL, "http://"),
+			AccessKey: "key",
+			SecretKey: "secret",
+			Bucket:    "bucket",
+			Prefix:    "site",
+		},
+	})
+	if svc.storage == nil || svc.storage.Location() != BackupLocationS3 {
+		t.Fatal("expected the object storage uploader to be configured")
+	}
+
+	backups, err := svc.ListBackups(context.Background())
+	if err != nil {
+		t.Fatalf("ListBackups: %v", err)
+	}
+	if len(backups) != 1 || backups[0].Location != BackupLocationS3 || backups[0].Size != 42 {
+		t.Fatalf("unexpected backups: %+v", backups)
+	}
+
+	reader, backup, err := svc.OpenBackup(context.Background(), BackupLocationS3, backups[0].Name)
+	if err != nil {
+		t.Fatalf("OpenBackup: %v", err)
+	}
+	data, _ := io.ReadAll(reader)
+	reader.Close()
+	if string(data) != "zipdata" || backup.Size != int64(len("zipdata")) {
+		t.Fatalf("unexpected download %q (%d bytes)", data, backup.Size)
+	}
+
+	if _, _, err := svc.OpenBackup(context.Background(), BackupLocationS3, "backup-20250101-000000.zip"); !errors.Is(err, ErrBackupNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+
+	if err := svc.DeleteBackup(context.Background(), BackupLocationS3, backups[0].Name); err != nil {
+		t.Fatalf("DeleteBackup: %v", err)
+	}
+	if deleted != "/bucket/site/backup-20250103-080000.zip" {
+		t.Fatalf("unexpected delete path %q", deleted)
+	}
+}
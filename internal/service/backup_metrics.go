@@ -0,0 +1,31 @@
+package service
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	backupsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "constructor_script",
+		Subsystem: "backup",
+		Name:      "backups_total",
+		Help:      "Backups created, by trigger (manual or auto) and result (success or failure)",
+	}, []string{"trigger", "result"})
+
+	backupLastSuccess = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "constructor_script",
+		Subsystem: "backup",
+		Name:      "last_success_timestamp",
+		Help:      "Unix timestamp of the last successful backup, by trigger",
+	}, []string{"trigger"})
+)
+
+func recordBackup(trigger string, err error) {
+	if err != nil {
+		backupsTotal.WithLabelValues(trigger, "failure").Inc()
+		return
+	}
+	backupsTotal.WithLabelValues(trigger, "success").Inc()
+	backupLastSuccess.WithLabelValues(trigger).SetToCurrentTime()
+}
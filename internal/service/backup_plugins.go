@@ -0,0 +1,172 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	pluginbackup "constructor-script-backend/internal/plugin/backup"
+	"constructor-script-backend/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// backupTable holds the rows of one plugin table as a JSON array of row objects,
+// exactly as Postgres renders them, so no column type is lost on the way back.
+type backupTable struct {
+	Table string          `json:"table"`
+	Count int             `json:"count"`
+	Rows  json.RawMessage `json:"rows"`
+}
+
+// pluginTable is a table registered by a plugin for backups.
+type pluginTable struct {
+	plugin string
+	schema *schema.Schema
+}
+
+// registeredPluginTables resolves the tables plugins registered for backups, in
+// restore order, keyed by table name.
+func registeredPluginTables(db *gorm.DB) ([]pluginTable, error) {
+	var tables []pluginTable
+	for _, plugin := range pluginbackup.Plugins() {
+		for _, model := range plugin.Models {
+			stmt := &gorm.Statement{DB: db}
+			if err := stmt.Parse(model); err != nil {
+				return nil, fmt.Errorf("failed to resolve %s backup table: %w", plugin.Slug, err)
+			}
+			tables = append(tables, pluginTable{plugin: plugin.Slug, schema: stmt.Schema})
+		}
+	}
+	return tables, nil
+}
+
+func (t pluginTable) orderBy(db *gorm.DB) string {
+	columns := make([]string, 0, len(t.schema.PrimaryFieldDBNames))
+	for _, name := range t.schema.PrimaryFieldDBNames {
+		columns = append(columns, "t."+db.Statement.Quote(name))
+	}
+	if len(columns) == 0 {
+		return ""
+	}
+	return " ORDER BY " + strings.Join(columns, ", ")
+}
+
+// snapshotPluginData copies every table registered by a plugin. Tables of purged
+// plugins no longer exist and are left out.
+func (s *BackupService) snapshotPluginData(db *gorm.DB) (map[string][]backupTable, error) {
+	tables, err := registeredPluginTables(db)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string][]backupTable)
+	for _, table := range tables {
+		name := table.schema.Table
+		if !db.Migrator().HasTable(name) {
+			continue
+		}
+
+		var row struct {
+			Rows  string
+			Count int
+		}
+		query := fmt.Sprintf(
+			"SELECT COALESCE(json_agg(t%s), '[]'::json) AS rows, COUNT(*) AS count FROM %s t",
+			table.orderBy(db), db.Statement.Quote(name),
+		)
+		if err := db.Raw(query).Scan(&row).Error; err != nil {
+			return nil, fmt.Errorf("failed to load %s: %w", name, err)
+		}
+		result[table.plugin] = append(result[table.plugin], backupTable{
+			Table: name,
+			Count: row.Count,
+			Rows:  json.RawMessage(row.Rows),
+		})
+	}
+	return result, nil
+}
+
+// restorablePluginTables checks the plugin tables in a manifest against the
+// registered ones, so an archive cannot write to arbitrary tables, and returns them
+// in restore order. Tables missing from this database are skipped with a warning.
+func (s *BackupService) restorablePluginTables(db *gorm.DB, data map[string][]backupTable) ([]pluginTable, map[string]backupTable, error) {
+	if len(data) == 0 {
+		return nil, nil, nil
+	}
+
+	registered, err := registeredPluginTables(db)
+	if err != nil {
+		return nil, nil, err
+	}
+	known := make(map[string]string, len(registered))
+	for _, table := range registered {
+		known[table.schema.Table] = table.plugin
+	}
+
+	byName := make(map[string]backupTable)
+	for plugin, tables := range data {
+		for _, table := range tables {
+			if known[table.Table] != plugin {
+				return nil, nil, fmt.Errorf("backup contains unknown %s table %q", plugin, table.Table)
+			}
+			byName[table.Table] = table
+		}
+	}
+
+	var ordered []pluginTable
+	for _, table := range registered {
+		if _, ok := byName[table.schema.Table]; !ok {
+			continue
+		}
+		if !db.Migrator().HasTable(table.schema.Table) {
+			logger.Warn("Skipping plugin table missing from the database", map[string]interface{}{
+				"plugin": table.plugin,
+				"table":  table.schema.Table,
+			})
+			continue
+		}
+		ordered = append(ordered, table)
+	}
+	return ordered, byName, nil
+}
+
+// restorePluginData inserts the plugin rows after the core content they reference
+// and moves id sequences past the restored rows.
+func (s *BackupService) restorePluginData(tx *gorm.DB, tables []pluginTable, rows map[string]backupTable) error {
+	for _, table := range tables {
+		data := rows[table.schema.Table]
+		if data.Count == 0 || len(data.Rows) == 0 {
+			continue
+		}
+
+		name := tx.Statement.Quote(table.schema.Table)
+		insert := fmt.Sprintf("INSERT INTO %s SELECT * FROM json_populate_recordset(NULL::%s, ?::json)", name, name)
+		if err := tx.Exec(insert, string(data.Rows)).Error; err != nil {
+			return fmt.Errorf("failed to restore %s: %w", table.schema.Table, err)
+		}
+
+		if field := table.schema.PrioritizedPrimaryField; field != nil && field.AutoIncrement {
+			column := tx.Statement.Quote(field.DBName)
+			reset := fmt.Sprintf("SELECT setval(pg_get_serial_sequence(?, ?), MAX(%s)) FROM %s", column, name)
+			if err := tx.Exec(reset, table.schema.Table, field.DBName).Error; err != nil {
+				return fmt.Errorf("failed to reset %s sequence: %w", table.schema.Table, err)
+			}
+		}
+	}
+	return nil
+}
+
+func countPluginRows(data map[string][]backupTable) map[string]int {
+	if len(data) == 0 {
+		return nil
+	}
+	counts := make(map[string]int, len(data))
+	for plugin, tables := range data {
+		for _, table := range tables {
+			counts[plugin] += table.Count
+		}
+	}
+	return counts
+}
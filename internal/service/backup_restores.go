@@ -0,0 +1,288 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/pkg/logger"
+)
+
+const (
+	// maxRestoreChangeKeys caps each list of a restore record, so restoring into an
+	// unrelated site does not store every id twice.
+	maxRestoreChangeKeys = 500
+	maxRestoreListLimit  = 100
+)
+
+var ErrBackupRestoreNotFound = errors.New("backup restore not found")
+
+// BackupRestoreOptions identifies the archive being restored and who restores it,
+// for the restore record.
+type BackupRestoreOptions struct {
+	Filename string
+	UserID   uint
+	Username string
+}
+
+// restoreStateTables are the core tables compared by a restore record, with the
+// columns that identify a row and tell whether it changed.
+var restoreStateTables = []struct {
+	table   string
+	key     string
+	updated string
+}{
+	{table: "users", key: "id", updated: "updated_at"},
+	{table: "categories", key: "id", updated: "updated_at"},
+	{table: "tags", key: "id", updated: "updated_at"},
+	{table: "posts", key: "id", updated: "updated_at"},
+	{table: "pages", key: "id", updated: "updated_at"},
+	{table: "comments", key: "id", updated: "updated_at"},
+	{table: "settings", key: "key", updated: "updated_at"},
+	{table: "menu_items", key: "id", updated: "updated_at"},
+	{table: "social_links", key: "id", updated: "updated_at"},
+	{table: "post_tags", key: "post_id::text || ':' || tag_id::text"},
+}
+
+// restoreState maps each table to the version of its rows: the updated_at time in
+// Unix microseconds, or the size of an upload.
+type restoreState map[string]map[string]int64
+
+// loadRestoreState reads the rows about to be replaced, soft-deleted ones
+// included, since backups carry those too.
+func (s *BackupService) loadRestoreState(tx *gorm.DB) (restoreState, error) {
+	state := make(restoreState, len(restoreStateTables)+1)
+	for _, table := range restoreStateTables {
+		version := table.updated
+		if version == "" {
+			version = "NULL::timestamptz"
+		}
+		rows, err := tx.Raw(fmt.Sprintf("SELECT %s, %s FROM %s", table.key, version, table.table)).Rows()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s before restore: %w", table.table, err)
+		}
+
+		versions := make(map[string]int64)
+		for rows.Next() {
+			var key string
+			var updated sql.NullTime
+			if err := rows.Scan(&key, &updated); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to read %s before restore: %w", table.table, err)
+			}
+			versions[key] = restoreVersion(updated.Time)
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s before restore: %w", table.table, err)
+		}
+		state[table.table] = versions
+	}
+
+	uploads, err := uploadSizes(s.uploadDir)
+	if err != nil {
+		return nil, err
+	}
+	state["uploads"] = uploads
+	return state, nil
+}
+
+// archiveRestoreState describes the rows of a backup the way loadRestoreState
+// describes the database, with the uploads extracted to uploadsDir.
+func archiveRestoreState(data backupData, uploadsDir string) (restoreState, error) {
+	state := restoreState{}
+	add := func(table, key string, updated time.Time) {
+		if state[table] == nil {
+			state[table] = make(map[string]int64)
+		}
+		state[table][key] = restoreVersion(updated)
+	}
+	id := func(value uint) string { return strconv.FormatUint(uint64(value), 10) }
+
+	for _, item := range data.Users {
+		add("users", id(item.ID), item.UpdatedAt)
+	}
+	for _, item := range data.Categories {
+		add("categories", id(item.ID), item.UpdatedAt)
+	}
+	for _, item := range data.Tags {
+		add("tags", id(item.ID), item.UpdatedAt)
+	}
+	for _, item := range data.Posts {
+		add("posts", id(item.ID), item.UpdatedAt)
+	}
+	for _, item := range data.Pages {
+		add("pages", id(item.ID), item.UpdatedAt)
+	}
+	for _, item := range data.Comments {
+		add("comments", id(item.ID), item.UpdatedAt)
+	}
+	for _, item := range data.Settings {
+		add("settings", item.Key, item.UpdatedAt)
+	}
+	for _, item := range data.MenuItems {
+		add("menu_items", id(item.ID), item.UpdatedAt)
+	}
+	for _, item := range data.SocialLinks {
+		add("social_links", id(item.ID), item.UpdatedAt)
+	}
+	for _, item := range data.PostTags {
+		add("post_tags", id(item.PostID)+":"+id(item.TagID), time.Time{})
+	}
+
+	uploads, err := uploadSizes(uploadsDir)
+	if err != nil {
+		return nil, err
+	}
+	state["uploads"] = uploads
+	return state, nil
+}
+
+func restoreVersion(updated time.Time) int64 {
+	if updated.IsZero() {
+		return 0
+	}
+	return updated.UnixMicro()
+}
+
+// uploadSizes maps the files under dir, by slash-separated relative path, to their
+// sizes. A missing dir has no files.
+func uploadSizes(dir string) (map[string]int64, error) {
+	sizes := make(map[string]int64)
+	err := filepath.WalkDir(dir, func(current string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, current)
+		if err != nil {
+			return err
+		}
+		sizes[filepath.ToSlash(rel)] = info.Size()
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list uploads: %w", err)
+	}
+	return sizes, nil
+}
+
+// diffRestoreState compares the state before a restore with the one restored.
+func diffRestoreState(before, after restoreState) models.BackupRestoreChanges {
+	tables := make(map[string]struct{}, len(before)+len(after))
+	for table := range before {
+		tables[table] = struct{}{}
+	}
+	for table := range after {
+		tables[table] = struct{}{}
+	}
+
+	changes := make(models.BackupRestoreChanges, len(tables))
+	for table := range tables {
+		previous, current := before[table], after[table]
+		entry := models.BackupRestoreTableChanges{Before: len(previous), After: len(current)}
+		for key, version := range current {
+			old, existed := previous[key]
+			switch {
+			case !existed:
+				entry.Added = append(entry.Added, key)
+			case old != version:
+				entry.Changed = append(entry.Changed, key)
+			}
+		}
+		for key := range previous {
+			if _, kept := current[key]; !kept {
+				entry.Removed = append(entry.Removed, key)
+			}
+		}
+		entry.Added = limitRestoreKeys(entry.Added, &entry.Truncated)
+		entry.Removed = limitRestoreKeys(entry.Removed, &entry.Truncated)
+		entry.Changed = limitRestoreKeys(entry.Changed, &entry.Truncated)
+		changes[table] = entry
+	}
+	return changes
+}
+
+// limitRestoreKeys sorts keys, numerically when they are ids, and cuts them at
+// maxRestoreChangeKeys.
+func limitRestoreKeys(keys []string, truncated *bool) []string {
+	sort.Slice(keys, func(i, j int) bool {
+		a, errA := strconv.ParseUint(keys[i], 10, 64)
+		b, errB := strconv.ParseUint(keys[j], 10, 64)
+		if errA == nil && errB == nil {
+			return a < b
+		}
+		return keys[i] < keys[j]
+	})
+	if len(keys) > maxRestoreChangeKeys {
+		*truncated = true
+		return keys[:maxRestoreChangeKeys]
+	}
+	return keys
+}
+
+// saveRestore stores the record of a restore that ended with restoreErr. A failure
+// is logged rather than returned, like audit log entries, since the restore itself
+// is already over.
+func (s *BackupService) saveRestore(record *models.BackupRestore, restoreErr error) {
+	record.Status = models.BackupRestoreSucceeded
+	if restoreErr != nil {
+		record.Status = models.BackupRestoreFailed
+		record.Error = restoreErr.Error()
+	}
+	if err := s.db.Create(record).Error; err != nil {
+		logger.Error(err, "Failed to record backup restore", map[string]interface{}{
+			"archive": record.Archive,
+			"user_id": record.UserID,
+			"status":  record.Status,
+		})
+	}
+}
+
+// ListRestores returns the latest restore records, newest first.
+func (s *BackupService) ListRestores(limit int) ([]models.BackupRestore, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("backup service not configured")
+	}
+	if limit <= 0 || limit > maxRestoreListLimit {
+		limit = maxRestoreListLimit
+	}
+
+	var restores []models.BackupRestore
+	err := s.db.Omit("changes").Order("created_at DESC, id DESC").Limit(limit).Find(&restores).Error
+	return restores, err
+}
+
+// GetRestore returns a restore record with the changes it made.
+func (s *BackupService) GetRestore(id uint) (*models.BackupRestore, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("backup service not configured")
+	}
+
+	var restore models.BackupRestore
+	if err := s.db.First(&restore, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBackupRestoreNotFound
+		}
+		return nil, err
+	}
+	return &restore, nil
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestDiffRestoreState(t *testing.T) {
+	updated := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	before := restoreState{
+		"posts":    {"1": restoreVersion(updated), "2": restoreVersion(updated), "10": restoreVersion(updated)},
+		"settings": {"site.name": restoreVersion(updated)},
+		"uploads":  {"a.png": 10},
+	}
+
+	uploadsDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(uploadsDir, "img"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	_ = os.WriteFile(filepath.Join(uploadsDir, "a.png"), []byte("0123456789"), 0o644)
+	_ = os.WriteFile(filepath.Join(uploadsDir, "img", "b.png"), []byte("b"), 0o644)
+
+	after, err := archiveRestoreState(backupData{
+		Posts: []backupPost{
+			{ID: 1, UpdatedAt: updated.In(time.FixedZone("CET", 3600))},
+			{ID: 2, UpdatedAt: updated.Add(time.Hour)},
+			{ID: 3, UpdatedAt: updated},
+		},
+		PostTags: []backupPostTag{{PostID: 3, TagID: 4}},
+	}, uploadsDir)
+	if err != nil {
+		t.Fatalf("archiveRestoreState: %v", err)
+	}
+
+	changes := diffRestoreState(before, after)
+
+	posts := changes["posts"]
+	if posts.Before != 3 || posts.After != 3 {
+		t.Fatalf("unexpected post counts %+v", posts)
+	}
+	if !reflect.DeepEqual(posts.Added, []string{"3"}) || !reflect.DeepEqual(posts.Changed, []string{"2"}) || !reflect.DeepEqual(posts.Removed, []string{"10"}) {
+		t.Fatalf("unexpected post changes %+v", posts)
+	}
+	if settings := changes["settings"]; settings.After != 0 || !reflect.DeepEqual(settings.Removed, []string{"site.name"}) {
+		t.Fatalf("unexpected setting changes %+v", settings)
+	}
+	if tags := changes["post_tags"]; !reflect.DeepEqual(tags.Added, []string{"3:4"}) {
+		t.Fatalf("unexpected post tag changes %+v", tags)
+	}
+	if uploads := changes["uploads"]; !reflect.DeepEqual(uploads.Added, []string{"img/b.png"}) || len(uploads.Changed) != 0 {
+		t.Fatalf("unexpected upload changes %+v", uploads)
+	}
+}
+
+func TestDiffRestoreStateTruncates(t *testing.T) {
+	after := restoreState{"comments": {}}
+	for i := 1; i <= maxRestoreChangeKeys+5; i++ {
+		after["comments"][strconv.Itoa(i)] = 1
+	}
+
+	comments := diffRestoreState(restoreState{}, after)["comments"]
+	if !comments.Truncated || len(comments.Added) != maxRestoreChangeKeys {
+		t.Fatalf("expected %d added ids and truncation, got %d (truncated=%v)", maxRestoreChangeKeys, len(comments.Added), comments.Truncated)
+	}
+	if comments.Added[0] != "1" || comments.Added[9] != "10" {
+		t.Fatalf("expected ids in numeric order, got %v", comments.Added[:10])
+	}
+	if comments.After != maxRestoreChangeKeys+5 {
+		t.Fatalf("expected the full count, got %d", comments.After)
+	}
+}
+
+func TestUploadSizesMissingDirectory(t *testing.T) {
+	sizes, err := uploadSizes(filepath.Join(t.TempDir(), "missing"))
+	if err != nil || len(sizes) != 0 {
+		t.Fatalf("expected no uploads, got %v, %v", sizes, err)
+	}
+}
@@ -15,8 +15,6 @@ import (
 	"fmt"
 	"io"
 	"io/fs"
-	"net/http"
-	"net/url"
 	"os"
 	"path"
 	"path/filepath"
@@ -28,6 +26,7 @@ import (
 	"gorm.io/gorm"
 
 	"constructor-script-backend/internal/authorization"
+	"constructor-script-backend/internal/events"
 	"constructor-script-backend/internal/models"
 	"constructor-script-backend/internal/repository"
 	"constructor-script-backend/pkg/logger"
@@ -56,7 +55,13 @@ var (
 type BackupOptions struct {
 	UploadDir     string
 	EncryptionKey []byte
-	S3            *BackupS3Config
+	// At most one of S3, GCS and Azure may be set; it receives a copy of every
+	// automatic backup.
+	S3    *BackupS3Config
+	GCS   *BackupGCSConfig
+	Azure *BackupAzureConfig
+	// Dump, when set, adds a pg_dump of the database to every archive.
+	Dump *BackupDumpConfig
 }
 
 type BackupS3Config struct {
@@ -70,12 +75,13 @@ type BackupS3Config struct {
 }
 
 type BackupService struct {
-	db         *gorm.DB
-	uploadDir  string
-	appName    string
-	settings   repository.SettingRepository
-	encryptor  *backupEncryptor
-	s3Uploader *backupS3Uploader
+	db        *gorm.DB
+	uploadDir string
+	appName   string
+	settings  repository.SettingRepository
+	encryptor *backupEncryptor
+	storage   backupStore
+	dumper    *backupDumper
 
 	autoMu        sync.Mutex
 	autoCancel    context.CancelFunc
@@ -84,6 +90,13 @@ type BackupService struct {
 	autoInterval  time.Duration
 	autoRetention int
 	autoEnabled   bool
+
+	events         *events.Bus
+	alertEmail     *EmailService
+	alertRecipient string
+
+	verifyMu         sync.Mutex
+	lastVerification *BackupVerification
 }
 
 type backupEncryptor struct {
@@ -91,17 +104,6 @@ type backupEncryptor struct {
 	macKey []byte
 }
 
-type backupS3Uploader struct {
-	endpoint   string
-	accessKey  string
-	secretKey  string
-	bucket     string
-	region     string
-	useSSL     bool
-	prefix     string
-	httpClient *http.Client
-}
-
 type BackupSummary struct {
 	SchemaVersion string    `json:"schema_version"`
 	GeneratedAt   time.Time `json:"generated_at"`
@@ -118,6 +120,12 @@ type BackupSummary struct {
 	SocialLinks   int       `json:"social_links"`
 	PostTags      int       `json:"post_tags"`
 	Uploads       int       `json:"uploads"`
+	// PluginRows counts the rows backed up for each plugin.
+	PluginRows map[string]int `json:"plugin_rows,omitempty"`
+	// DatabaseDump reports whether the archive holds a pg_dump of the database.
+	DatabaseDump bool `json:"database_dump,omitempty"`
+	// RestoreID is the models.BackupRestore record of a restore.
+	RestoreID uint `json:"restore_id,omitempty"`
 }
 
 type BackupArchive struct {
@@ -134,6 +142,8 @@ type backupManifest struct {
 	Application   string     `json:"application"`
 	Uploads       []string   `json:"uploads"`
 	Data          backupData `json:"data"`
+	// DatabaseDump names the entry holding a pg_dump of the database, if any.
+	DatabaseDump string `json:"database_dump,omitempty"`
 }
 
 type backupData struct {
@@ -147,6 +157,8 @@ type backupData struct {
 	MenuItems   []backupMenuItem   `json:"menu_items"`
 	SocialLinks []backupSocialLink `json:"social_links"`
 	PostTags    []backupPostTag    `json:"post_tags"`
+	// Plugins holds the tables registered by plugins, keyed by plugin slug.
+	Plugins map[string][]backupTable `json:"plugins,omitempty"`
 }
 
 type backupUser struct {
@@ -295,15 +307,22 @@ func NewBackupService(db *gorm.DB, settings repository.SettingRepository, option
 		}
 	}
 
-	if options.S3 != nil {
-		uploader, err := newBackupS3Uploader(*options.S3)
+	if options.Dump != nil {
+		dumper, err := newBackupDumper(*options.Dump)
 		if err != nil {
-			logger.Error(err, "Failed to configure S3 backup uploader", map[string]interface{}{"endpoint": options.S3.Endpoint})
+			logger.Error(err, "Failed to configure database dumps for backups", nil)
 		} else {
-			service.s3Uploader = uploader
+			service.dumper = dumper
 		}
 	}
 
+	storage, err := newBackupStore(options)
+	if err != nil {
+		logger.Error(err, "Failed to configure backup object storage", nil)
+	} else if storage != nil {
+		service.storage = storage
+	}
+
 	return service
 }
 
@@ -343,7 +362,15 @@ func (s *BackupService) GetAutoSettings() (models.BackupSettings, error) {
 		return models.BackupSettings{}, err
 	}
 
-	return s.autoSettingsWithRuntime(settings), nil
+	result := s.autoSettingsWithRuntime(settings)
+	// The last run is only tracked in memory; after a restart fall back to the
+	// newest archive on disk.
+	if result.LastRun == nil {
+		if latest := s.latestLocalBackup(); !latest.IsZero() {
+			result.LastRun = &latest
+		}
+	}
+	return result, nil
 }
 
 func (s *BackupService) UpdateAutoSettings(req models.UpdateBackupSettingsRequest) (models.BackupSettings, error) {
@@ -548,6 +575,13 @@ func (s *BackupService) runAutoBackupLoop(ctx context.Context, interval time.Dur
 
 			if err != nil {
 				logger.Error(err, "Failed to create automatic backup", nil)
+				if s.events != nil {
+					s.events.Publish(context.Background(), events.BackupFailed, map[string]interface{}{
+						"error":     err.Error(),
+						"failed_at": now,
+					})
+				}
+				s.sendAlert("Automatic backup failed", "The scheduled automatic backup could not be created.", "", err.Error(), now)
 			}
 
 			timer.Reset(interval)
@@ -557,7 +591,9 @@ func (s *BackupService) runAutoBackupLoop(ctx context.Context, interval time.Dur
 	}
 }
 
-func (s *BackupService) executeAutoBackup(ctx context.Context) error {
+func (s *BackupService) executeAutoBackup(ctx context.Context) (err error) {
+	defer func() { recordBackup("auto", err) }()
+
 	if s == nil {
 		return fmt.Errorf("backup service not configured")
 	}
@@ -577,7 +613,7 @@ func (s *BackupService) executeAutoBackup(ctx context.Context) error {
 	}
 	s.autoMu.Unlock()
 
-	archive, err := s.CreateArchive(ctx)
+	archive, err := s.createArchive(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to create automatic backup archive: %w", err)
 	}
@@ -587,7 +623,7 @@ func (s *BackupService) executeAutoBackup(ctx context.Context) error {
 		return fmt.Errorf("failed to prepare automatic backup archive: %w", err)
 	}
 
-	targetDir := filepath.Join(s.uploadDir, "auto-backups")
+	targetDir := s.autoBackupDir()
 	if err := os.MkdirAll(targetDir, 0o755); err != nil {
 		return fmt.Errorf("failed to prepare automatic backup directory: %w", err)
 	}
@@ -612,8 +648,12 @@ func (s *BackupService) executeAutoBackup(ctx context.Context) error {
 		logger.Warn("Failed to flush automatic backup to disk", map[string]interface{}{"path": destinationPath, "error": err.Error()})
 	}
 
-	if s.s3Uploader != nil {
-		if _, err := s.s3Uploader.Upload(ctx, archive); err != nil {
+	if err := writeBackupSummary(destinationPath, archive.Summary); err != nil {
+		logger.Warn("Failed to store automatic backup summary", map[string]interface{}{"path": destinationPath, "error": err.Error()})
+	}
+
+	if s.storage != nil {
+		if _, err := s.storage.Upload(ctx, archive); err != nil {
 			return fmt.Errorf("failed to upload automatic backup to object storage: %w", err)
 		}
 	}
@@ -627,6 +667,7 @@ func (s *BackupService) executeAutoBackup(ctx context.Context) error {
 	}
 
 	logger.Info("Automatic site backup created", map[string]interface{}{"path": destinationPath})
+	s.announceBackup("auto", archive)
 
 	return nil
 }
@@ -656,10 +697,7 @@ func (s *BackupService) cleanupAutoBackups(dir string, retention int) error {
 		}
 
 		name := entry.Name()
-		if !strings.HasPrefix(name, "backup-") {
-			continue
-		}
-		if !(strings.HasSuffix(name, ".zip") || strings.HasSuffix(name, ".zip.enc")) {
+		if !backupFilenamePattern.MatchString(name) {
 			continue
 		}
 
@@ -691,13 +729,38 @@ func (s *BackupService) cleanupAutoBackups(dir string, retention int) error {
 			}
 			continue
 		}
+		removeBackupSummary(item.path)
 		logger.Info("Old automatic backup removed", map[string]interface{}{"path": item.path})
 	}
 
 	return deletionErr
 }
 
+// CreateArchive builds a backup archive on request and counts it in the backup
+// metrics; automatic backups are counted by the scheduler job instead.
 func (s *BackupService) CreateArchive(ctx context.Context) (*BackupArchive, error) {
+	archive, err := s.createArchive(ctx)
+	recordBackup("manual", err)
+	if err == nil {
+		s.announceBackup("manual", archive)
+	}
+	return archive, err
+}
+
+// announceBackup publishes a finished backup on the event bus.
+func (s *BackupService) announceBackup(trigger string, archive *BackupArchive) {
+	if s.events == nil || archive == nil {
+		return
+	}
+	s.events.Publish(context.Background(), events.BackupCompleted, map[string]interface{}{
+		"trigger":      trigger,
+		"name":         archive.Filename,
+		"encrypted":    archive.Encrypted,
+		"generated_at": archive.Summary.GeneratedAt,
+	})
+}
+
+func (s *BackupService) createArchive(ctx context.Context) (*BackupArchive, error) {
 	if s == nil || s.db == nil {
 		return nil, fmt.Errorf("backup service not configured")
 	}
@@ -728,6 +791,15 @@ func (s *BackupService) CreateArchive(ctx context.Context) (*BackupArchive, erro
 		return nil, err
 	}
 
+	if s.dumper != nil {
+		if err := s.dumper.writeTo(ctx, writer, manifest.GeneratedAt); err != nil {
+			writer.Close()
+			tempFile.Close()
+			os.Remove(tempFile.Name())
+			return nil, err
+		}
+	}
+
 	if err := writer.Close(); err != nil {
 		tempFile.Close()
 		os.Remove(tempFile.Name())
@@ -786,6 +858,8 @@ func (s *BackupService) CreateArchive(ctx context.Context) (*BackupArchive, erro
 		SocialLinks:   len(manifest.Data.SocialLinks),
 		PostTags:      len(manifest.Data.PostTags),
 		Uploads:       len(manifest.Uploads),
+		PluginRows:    countPluginRows(manifest.Data.Plugins),
+		DatabaseDump:  manifest.DatabaseDump != "",
 	}
 
 	return &BackupArchive{
@@ -797,13 +871,23 @@ func (s *BackupService) CreateArchive(ctx context.Context) (*BackupArchive, erro
 	}, nil
 }
 
-func (s *BackupService) RestoreArchive(ctx context.Context, reader io.Reader, size int64) (BackupSummary, error) {
-	var summary BackupSummary
-
+// RestoreArchive replaces the site with the archive in reader and keeps a record
+// of the restore, whether it succeeds or not, whose id the summary carries.
+func (s *BackupService) RestoreArchive(ctx context.Context, reader io.Reader, size int64, options BackupRestoreOptions) (summary BackupSummary, err error) {
 	if s == nil || s.db == nil {
 		return summary, fmt.Errorf("backup service not configured")
 	}
 
+	record := &models.BackupRestore{
+		UserID:   options.UserID,
+		Username: options.Username,
+		Archive:  options.Filename,
+	}
+	defer func() {
+		s.saveRestore(record, err)
+		summary.RestoreID = record.ID
+	}()
+
 	spoolFile, err := os.CreateTemp("", "constructor-restore-*.zip")
 	if err != nil {
 		return summary, fmt.Errorf("failed to prepare temporary archive: %w", err)
@@ -813,10 +897,13 @@ func (s *BackupService) RestoreArchive(ctx context.Context, reader io.Reader, si
 		os.Remove(spoolFile.Name())
 	}()
 
-	written, err := io.Copy(spoolFile, reader)
+	hasher := sha256.New()
+	written, err := io.Copy(io.MultiWriter(spoolFile, hasher), reader)
 	if err != nil {
 		return summary, fmt.Errorf("failed to read backup archive: %w", err)
 	}
+	record.ArchiveSize = written
+	record.ArchiveSHA256 = hex.EncodeToString(hasher.Sum(nil))
 	if size > 0 && written != size {
 		logger.Warn("Backup archive size mismatch", map[string]interface{}{
 			"expected": size,
@@ -836,6 +923,8 @@ func (s *BackupService) RestoreArchive(ctx context.Context, reader io.Reader, si
 		return summary, fmt.Errorf("failed to inspect backup archive: %w", err)
 	}
 
+	record.Encrypted = encrypted
+
 	if encrypted {
 		if s.encryptor == nil {
 			return summary, ErrBackupEncrypted
@@ -871,6 +960,9 @@ func (s *BackupService) RestoreArchive(ctx context.Context, reader io.Reader, si
 	if err != nil {
 		return summary, err
 	}
+	generatedAt := manifest.GeneratedAt
+	record.ArchiveGeneratedAt = &generatedAt
+	record.Application = manifest.Application
 
 	if manifest.SchemaVersion != backupSchemaVersion {
 		return summary, ErrBackupVersion
@@ -892,7 +984,25 @@ func (s *BackupService) RestoreArchive(ctx context.Context, reader io.Reader, si
 		return summary, fmt.Errorf("failed to start transaction: %w", err)
 	}
 
-	if err := s.resetDatabase(tx); err != nil {
+	pluginTables, pluginRows, err := s.restorablePluginTables(tx, manifest.Data.Plugins)
+	if err != nil {
+		tx.Rollback()
+		return summary, err
+	}
+
+	before, err := s.loadRestoreState(tx)
+	if err != nil {
+		tx.Rollback()
+		return summary, err
+	}
+	after, err := archiveRestoreState(manifest.Data, tempUploadsDir)
+	if err != nil {
+		tx.Rollback()
+		return summary, err
+	}
+	changes := diffRestoreState(before, after)
+
+	if err := s.resetDatabase(tx, pluginTables); err != nil {
 		tx.Rollback()
 		return summary, err
 	}
@@ -902,9 +1012,17 @@ func (s *BackupService) RestoreArchive(ctx context.Context, reader io.Reader, si
 		return summary, err
 	}
 
+	if err := s.restorePluginData(tx, pluginTables, pluginRows); err != nil {
+		tx.Rollback()
+		return summary, err
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		return summary, fmt.Errorf("failed to commit restored data: %w", err)
 	}
+	// A failure from here on leaves the data restored, so the record keeps the
+	// changes.
+	record.Changes = changes
 
 	backupDir, err := s.stageUploads(tempUploadsDir)
 	if err != nil {
@@ -929,6 +1047,7 @@ func (s *BackupService) RestoreArchive(ctx context.Context, reader io.Reader, si
 		SocialLinks:   len(manifest.Data.SocialLinks),
 		PostTags:      len(manifest.Data.PostTags),
 		Uploads:       uploadsCount,
+		PluginRows:    countPluginRows(manifest.Data.Plugins),
 	}
 
 	if backupDir != "" {
@@ -1002,6 +1121,10 @@ func (s *BackupService) buildManifest(ctx context.Context) (backupManifest, erro
 	}
 	manifest.Uploads = uploads
 
+	if s.dumper != nil {
+		manifest.DatabaseDump = backupDumpEntry
+	}
+
 	return manifest, nil
 }
 
@@ -1196,6 +1319,12 @@ func (s *BackupService) snapshotData(ctx context.Context) (backupData, error) {
 		result.PostTags[i] = backupPostTag{PostID: link.PostID, TagID: link.TagID}
 	}
 
+	plugins, err := s.snapshotPluginData(db)
+	if err != nil {
+		return result, err
+	}
+	result.Plugins = plugins
+
 	return result, nil
 }
 
@@ -1221,13 +1350,17 @@ func (s *BackupService) listUploads() ([]string, error) {
 		if walkErr != nil {
 			return walkErr
 		}
-		if entry.IsDir() {
-			return nil
-		}
 		rel, err := filepath.Rel(uploadDir, path)
 		if err != nil {
 			return err
 		}
+		if entry.IsDir() {
+			// Earlier automatic backups are not part of the site content.
+			if filepath.ToSlash(rel) == autoBackupDirName {
+				return filepath.SkipDir
+			}
+			return nil
+		}
 		if rel == "." {
 			return nil
 		}
@@ -1401,8 +1534,15 @@ func (s *BackupService) extractUploads(reader *zip.Reader) (string, int, error)
 	return tempDir, count, nil
 }
 
-func (s *BackupService) resetDatabase(tx *gorm.DB) error {
-	stmt := "TRUNCATE TABLE post_tags, comments, posts, pages, categories, tags, menu_items, social_links, settings, users RESTART IDENTITY CASCADE"
+// resetDatabase empties the core tables and the plugin tables about to be restored.
+// CASCADE also empties plugin tables that reference users or posts, which is why
+// they have to be part of the backup.
+func (s *BackupService) resetDatabase(tx *gorm.DB, pluginTables []pluginTable) error {
+	tables := []string{"post_tags", "comments", "posts", "pages", "categories", "tags", "menu_items", "social_links", "settings", "users"}
+	for _, table := range pluginTables {
+		tables = append(tables, tx.Statement.Quote(table.schema.Table))
+	}
+	stmt := "TRUNCATE TABLE " + strings.Join(tables, ", ") + " RESTART IDENTITY CASCADE"
 	if err := tx.Exec(stmt).Error; err != nil {
 		return fmt.Errorf("failed to reset database state: %w", err)
 	}
@@ -1967,149 +2107,6 @@ func detectEncryptedArchive(file *os.File) (bool, error) {
 	return string(header) == backupEncryptionMagic, nil
 }
 
-func newBackupS3Uploader(cfg BackupS3Config) (*backupS3Uploader, error) {
-	if cfg.Endpoint == "" {
-		return nil, fmt.Errorf("s3 endpoint is required")
-	}
-	if cfg.AccessKey == "" || cfg.SecretKey == "" {
-		return nil, fmt.Errorf("s3 credentials are required")
-	}
-	if cfg.Bucket == "" {
-		return nil, fmt.Errorf("s3 bucket is required")
-	}
-
-	region := strings.TrimSpace(cfg.Region)
-	if region == "" {
-		region = "us-east-1"
-	}
-
-	uploader := &backupS3Uploader{
-		endpoint:   strings.TrimSpace(cfg.Endpoint),
-		accessKey:  cfg.AccessKey,
-		secretKey:  cfg.SecretKey,
-		bucket:     cfg.Bucket,
-		region:     region,
-		useSSL:     cfg.UseSSL,
-		prefix:     strings.Trim(cfg.Prefix, "/"),
-		httpClient: &http.Client{Timeout: 60 * time.Second},
-	}
-
-	return uploader, nil
-}
-
-func (u *backupS3Uploader) objectName(filename string) string {
-	if u == nil {
-		return filename
-	}
-	if u.prefix == "" {
-		return filename
-	}
-	return path.Join(u.prefix, filename)
-}
-
-func (u *backupS3Uploader) Upload(ctx context.Context, archive *BackupArchive) (string, error) {
-	if u == nil || archive == nil {
-		return "", nil
-	}
-
-	file := archive.File()
-	if file == nil {
-		return "", fmt.Errorf("archive file is not available")
-	}
-
-	size, err := archive.Size()
-	if err != nil {
-		return "", fmt.Errorf("failed to determine archive size: %w", err)
-	}
-
-	objectName := u.objectName(archive.Filename)
-	contentType := archive.ContentType
-	if contentType == "" {
-		contentType = "application/octet-stream"
-	}
-
-	hasher := sha256.New()
-	if _, err := io.Copy(hasher, io.NewSectionReader(file, 0, size)); err != nil {
-		return "", fmt.Errorf("failed to hash archive for upload: %w", err)
-	}
-	payloadHash := hex.EncodeToString(hasher.Sum(nil))
-
-	scheme := "https"
-	if !u.useSSL {
-		scheme = "http"
-	}
-
-	objectPath := path.Join(u.bucket, objectName)
-	if !strings.HasPrefix(objectPath, "/") {
-		objectPath = "/" + objectPath
-	}
-
-	endpointURL := url.URL{
-		Scheme: scheme,
-		Host:   u.endpoint,
-		Path:   objectPath,
-	}
-
-	bodyReader := io.NewSectionReader(file, 0, size)
-	req, err := http.NewRequestWithContext(ctx, http.MethodPut, endpointURL.String(), bodyReader)
-	if err != nil {
-		return "", fmt.Errorf("failed to build upload request: %w", err)
-	}
-	req.ContentLength = size
-	req.Header.Set("Content-Type", contentType)
-	amzDate := time.Now().UTC()
-	amzDateStr := amzDate.Format("20060102T150405Z")
-	dateStamp := amzDate.Format("20060102")
-	req.Header.Set("x-amz-content-sha256", payloadHash)
-	req.Header.Set("x-amz-date", amzDateStr)
-
-	canonicalURI := endpointURL.EscapedPath()
-	canonicalHeaders := fmt.Sprintf("host:%s\nx-amz-content-sha256:%s\nx-amz-date:%s\n", strings.ToLower(req.Host), payloadHash, amzDateStr)
-	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
-	canonicalRequest := strings.Join([]string{
-		http.MethodPut,
-		canonicalURI,
-		"",
-		canonicalHeaders,
-		signedHeaders,
-		payloadHash,
-	}, "\n")
-
-	hashedCanonicalRequest := sha256.Sum256([]byte(canonicalRequest))
-	credentialScope := fmt.Sprintf("%s/%s/s3/aws4_request", dateStamp, u.region)
-	stringToSign := strings.Join([]string{
-		"AWS4-HMAC-SHA256",
-		amzDateStr,
-		credentialScope,
-		hex.EncodeToString(hashedCanonicalRequest[:]),
-	}, "\n")
-
-	signingKey := deriveSigningKey(u.secretKey, dateStamp, u.region, "s3")
-	signature := hmacSHA256Hex(signingKey, stringToSign)
-
-	authorization := fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", u.accessKey, credentialScope, signedHeaders, signature)
-	req.Header.Set("Authorization", authorization)
-
-	resp, err := u.httpClient.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("failed to upload backup to bucket %s: %w", u.bucket, err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
-		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
-		return "", fmt.Errorf("object storage upload failed with status %s: %s", resp.Status, strings.TrimSpace(string(body)))
-	}
-
-	if err := archive.Reset(); err != nil {
-		logger.Warn("Failed to rewind archive after object storage upload", map[string]interface{}{"archive": archive.Filename, "error": err.Error()})
-	}
-
-	logger.Info("Automatic site backup uploaded", map[string]interface{}{"bucket": u.bucket, "object": objectName})
-
-	return objectName, nil
-}
-
 func deriveSigningKey(secret, date, region, service string) []byte {
 	kDate := hmacSHA256([]byte("AWS4"+secret), []byte(date))
 	kRegion := hmacSHA256(kDate, []byte(region))
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+)
+
+// backupStore keeps backup archives in object storage. Every driver stores the
+// archives under an optional prefix using the archive file name as the object
+// name, and reports them with its own location.
+type backupStore interface {
+	// Location identifies the store in BackupFile.Location and the archive routes.
+	Location() string
+	Upload(ctx context.Context, archive *BackupArchive) (string, error)
+	List(ctx context.Context) ([]BackupFile, error)
+	Get(ctx context.Context, name string) (io.ReadCloser, BackupFile, error)
+	Delete(ctx context.Context, name string) error
+}
+
+// newBackupStore builds the driver for the object storage set in options, or
+// returns nil when none is. At most one may be set.
+func newBackupStore(options BackupOptions) (backupStore, error) {
+	configured := 0
+	for _, set := range []bool{options.S3 != nil, options.GCS != nil, options.Azure != nil} {
+		if set {
+			configured++
+		}
+	}
+	if configured > 1 {
+		return nil, fmt.Errorf("only one backup object storage can be configured")
+	}
+
+	switch {
+	case options.S3 != nil:
+		return newBackupS3Store(*options.S3)
+	case options.GCS != nil:
+		return newBackupGCSStore(*options.GCS)
+	case options.Azure != nil:
+		return newBackupAzureStore(*options.Azure)
+	default:
+		return nil, nil
+	}
+}
+
+// backupObjectName returns the object name of a backup archive under prefix.
+func backupObjectName(prefix, filename string) string {
+	if prefix == "" {
+		return filename
+	}
+	return path.Join(prefix, filename)
+}
+
+// archiveUploadBody returns the file, size and content type to upload for archive.
+func archiveUploadBody(archive *BackupArchive) (*os.File, int64, string, error) {
+	file := archive.File()
+	if file == nil {
+		return nil, 0, "", fmt.Errorf("archive file is not available")
+	}
+	size, err := archive.Size()
+	if err != nil {
+		return nil, 0, "", fmt.Errorf("failed to determine archive size: %w", err)
+	}
+	contentType := archive.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	return file, size, contentType, nil
+}
+
+// checkStorageResponse returns an error carrying the start of the body when resp
+// does not have a 2xx status. It does not close the body.
+func checkStorageResponse(resp *http.Response) error {
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("object storage returned status %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// decodeStorageXML checks the status of an object storage response and, when dest
+// is set, decodes its XML body into it. It does not close the body.
+func decodeStorageXML(resp *http.Response, dest interface{}) error {
+	if err := checkStorageResponse(resp); err != nil {
+		return err
+	}
+	if dest == nil {
+		return nil
+	}
+	return xml.NewDecoder(resp.Body).Decode(dest)
+}
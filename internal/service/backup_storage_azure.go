@@ -0,0 +1,272 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"constructor-script-backend/pkg/logger"
+)
+
+const azureStorageVersion = "2021-08-06"
+
+type BackupAzureConfig struct {
+	Account   string
+	Key       string
+	Container string
+	Prefix    string
+	// Endpoint overrides https://<account>.blob.core.windows.net, for sovereign
+	// clouds and emulators.
+	Endpoint string
+}
+
+// backupAzureStore keeps backups in an Azure Blob Storage container, signing
+// requests with the storage account's Shared Key.
+type backupAzureStore struct {
+	endpoint   string
+	account    string
+	key        []byte
+	container  string
+	prefix     string
+	httpClient *http.Client
+}
+
+func newBackupAzureStore(cfg BackupAzureConfig) (*backupAzureStore, error) {
+	if cfg.Account == "" || cfg.Key == "" {
+		return nil, fmt.Errorf("azure storage account and key are required")
+	}
+	if cfg.Container == "" {
+		return nil, fmt.Errorf("azure container is required")
+	}
+
+	key, err := base64.StdEncoding.DecodeString(cfg.Key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid azure storage account key: %w", err)
+	}
+
+	endpoint := strings.TrimRight(strings.TrimSpace(cfg.Endpoint), "/")
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://%s.blob.core.windows.net", cfg.Account)
+	}
+
+	return &backupAzureStore{
+		endpoint:   endpoint,
+		account:    cfg.Account,
+		key:        key,
+		container:  cfg.Container,
+		prefix:     strings.Trim(cfg.Prefix, "/"),
+		httpClient: &http.Client{Timeout: 60 * time.Second},
+	}, nil
+}
+
+func (a *backupAzureStore) Location() string {
+	return BackupLocationAzure
+}
+
+func (a *backupAzureStore) blobPath(name string) string {
+	return "/" + url.PathEscape(a.container) + "/" + (&url.URL{Path: backupObjectName(a.prefix, name)}).EscapedPath()
+}
+
+// do sends a request signed with the account key. rawPath is appended to the
+// endpoint as is, so blob names in it must already be escaped.
+func (a *backupAzureStore) do(ctx context.Context, method, rawPath string, query url.Values, body io.Reader, size int64, header http.Header) (*http.Response, error) {
+	target := a.endpoint + rawPath
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	if body != nil {
+		req.ContentLength = size
+	}
+	req.Header.Set("x-ms-date", time.Now().UTC().Format(http.TimeFormat))
+	req.Header.Set("x-ms-version", azureStorageVersion)
+
+	mac := hmac.New(sha256.New, a.key)
+	mac.Write([]byte(a.stringToSign(req)))
+	req.Header.Set("Authorization", "SharedKey "+a.account+":"+base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+
+	return a.httpClient.Do(req)
+}
+
+// stringToSign builds the Shared Key string to sign of req, as described in
+// "Authorize with Shared Key" of the Azure Storage REST reference.
+func (a *backupAzureStore) stringToSign(req *http.Request) string {
+	contentLength := ""
+	if req.ContentLength > 0 {
+		contentLength = strconv.FormatInt(req.ContentLength, 10)
+	}
+
+	var msHeaders []string
+	for name := range req.Header {
+		if lower := strings.ToLower(name); strings.HasPrefix(lower, "x-ms-") {
+			msHeaders = append(msHeaders, lower)
+		}
+	}
+	sort.Strings(msHeaders)
+
+	var canonical strings.Builder
+	for _, name := range msHeaders {
+		canonical.WriteString(name + ":" + strings.TrimSpace(req.Header.Get(name)) + "\n")
+	}
+
+	canonical.WriteString("/" + a.account + req.URL.EscapedPath())
+	query := req.URL.Query()
+	params := make([]string, 0, len(query))
+	for name := range query {
+		params = append(params, name)
+	}
+	sort.Strings(params)
+	for _, name := range params {
+		values := append([]string(nil), query[name]...)
+		sort.Strings(values)
+		canonical.WriteString("\n" + strings.ToLower(name) + ":" + strings.Join(values, ","))
+	}
+
+	return strings.Join([]string{
+		req.Method,
+		req.Header.Get("Content-Encoding"),
+		req.Header.Get("Content-Language"),
+		contentLength,
+		req.Header.Get("Content-MD5"),
+		req.Header.Get("Content-Type"),
+		"", // Date, superseded by x-ms-date.
+		req.Header.Get("If-Modified-Since"),
+		req.Header.Get("If-Match"),
+		req.Header.Get("If-None-Match"),
+		req.Header.Get("If-Unmodified-Since"),
+		req.Header.Get("Range"),
+		canonical.String(),
+	}, "\n")
+}
+
+func (a *backupAzureStore) Upload(ctx context.Context, archive *BackupArchive) (string, error) {
+	if archive == nil {
+		return "", nil
+	}
+	file, size, contentType, err := archiveUploadBody(archive)
+	if err != nil {
+		return "", err
+	}
+
+	objectName := backupObjectName(a.prefix, archive.Filename)
+	header := http.Header{
+		"Content-Type":   {contentType},
+		"X-Ms-Blob-Type": {"BlockBlob"},
+	}
+	resp, err := a.do(ctx, http.MethodPut, a.blobPath(archive.Filename), nil, io.NewSectionReader(file, 0, size), size, header)
+	if err != nil {
+		return "", fmt.Errorf("failed to upload backup to container %s: %w", a.container, err)
+	}
+	defer resp.Body.Close()
+	if err := checkStorageResponse(resp); err != nil {
+		return "", fmt.Errorf("failed to upload backup to container %s: %w", a.container, err)
+	}
+
+	if err := archive.Reset(); err != nil {
+		logger.Warn("Failed to rewind archive after object storage upload", map[string]interface{}{"archive": archive.Filename, "error": err.Error()})
+	}
+
+	logger.Info("Automatic site backup uploaded", map[string]interface{}{"container": a.container, "object": objectName})
+
+	return objectName, nil
+}
+
+type azureBlobList struct {
+	Blobs []struct {
+		Name       string `xml:"Name"`
+		Properties struct {
+			LastModified  string `xml:"Last-Modified"`
+			ContentLength int64  `xml:"Content-Length"`
+		} `xml:"Properties"`
+	} `xml:"Blobs>Blob"`
+	NextMarker string `xml:"NextMarker"`
+}
+
+// List returns the backup archives under the configured prefix.
+func (a *backupAzureStore) List(ctx context.Context) ([]BackupFile, error) {
+	query := url.Values{"restype": {"container"}, "comp": {"list"}}
+	if a.prefix != "" {
+		query.Set("prefix", a.prefix+"/")
+	}
+
+	var backups []BackupFile
+	for page := 0; page < maxBackupListPages; page++ {
+		resp, err := a.do(ctx, http.MethodGet, "/"+url.PathEscape(a.container), query, nil, 0, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list backups in container %s: %w", a.container, err)
+		}
+
+		var result azureBlobList
+		err = decodeStorageXML(resp, &result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list backups in container %s: %w", a.container, err)
+		}
+
+		for _, blob := range result.Blobs {
+			name := path.Base(blob.Name)
+			if blob.Name != backupObjectName(a.prefix, name) || !backupFilenamePattern.MatchString(name) {
+				continue
+			}
+			modTime, _ := http.ParseTime(blob.Properties.LastModified)
+			backups = append(backups, newBackupFile(name, BackupLocationAzure, blob.Properties.ContentLength, modTime))
+		}
+
+		if result.NextMarker == "" {
+			break
+		}
+		query.Set("marker", result.NextMarker)
+	}
+	return backups, nil
+}
+
+// Get opens a backup archive stored in the container.
+func (a *backupAzureStore) Get(ctx context.Context, name string) (io.ReadCloser, BackupFile, error) {
+	resp, err := a.do(ctx, http.MethodGet, a.blobPath(name), nil, nil, 0, nil)
+	if err != nil {
+		return nil, BackupFile{}, fmt.Errorf("failed to download backup from container %s: %w", a.container, err)
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, BackupFile{}, ErrBackupNotFound
+	}
+	if err := checkStorageResponse(resp); err != nil {
+		resp.Body.Close()
+		return nil, BackupFile{}, fmt.Errorf("failed to download backup from container %s: %w", a.container, err)
+	}
+
+	modTime, _ := http.ParseTime(resp.Header.Get("Last-Modified"))
+	return resp.Body, newBackupFile(name, BackupLocationAzure, resp.ContentLength, modTime), nil
+}
+
+// Delete removes a backup archive from the container.
+func (a *backupAzureStore) Delete(ctx context.Context, name string) error {
+	resp, err := a.do(ctx, http.MethodDelete, a.blobPath(name), nil, nil, 0, nil)
+	if err != nil {
+		return fmt.Errorf("failed to delete backup from container %s: %w", a.container, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrBackupNotFound
+	}
+	if err := checkStorageResponse(resp); err != nil {
+		return fmt.Errorf("failed to delete backup from container %s: %w", a.container, err)
+	}
+	return nil
+}
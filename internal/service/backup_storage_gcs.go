@@ -0,0 +1,304 @@
+package service
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"constructor-script-backend/pkg/logger"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	gcsDefaultEndpoint = "https://storage.googleapis.com"
+	gcsDefaultTokenURI = "https://oauth2.googleapis.com/token"
+	gcsScope           = "https://www.googleapis.com/auth/devstorage.read_write"
+)
+
+type BackupGCSConfig struct {
+	Bucket string
+	Prefix string
+	// Credentials is the JSON key of the service account used to reach the bucket.
+	Credentials []byte
+	// Endpoint overrides the Cloud Storage API endpoint, for emulators.
+	Endpoint string
+}
+
+type gcsServiceAccount struct {
+	ClientEmail  string `json:"client_email"`
+	PrivateKey   string `json:"private_key"`
+	PrivateKeyID string `json:"private_key_id"`
+	TokenURI     string `json:"token_uri"`
+}
+
+// backupGCSStore keeps backups in a Google Cloud Storage bucket through the JSON
+// API, authenticating as a service account.
+type backupGCSStore struct {
+	endpoint   string
+	bucket     string
+	prefix     string
+	account    gcsServiceAccount
+	signer     *rsa.PrivateKey
+	httpClient *http.Client
+
+	tokenMu     sync.Mutex
+	token       string
+	tokenExpiry time.Time
+}
+
+func newBackupGCSStore(cfg BackupGCSConfig) (*backupGCSStore, error) {
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("gcs bucket is required")
+	}
+	if len(cfg.Credentials) == 0 {
+		return nil, fmt.Errorf("gcs service account credentials are required")
+	}
+
+	var account gcsServiceAccount
+	if err := json.Unmarshal(cfg.Credentials, &account); err != nil {
+		return nil, fmt.Errorf("invalid gcs service account credentials: %w", err)
+	}
+	if account.ClientEmail == "" || account.PrivateKey == "" {
+		return nil, fmt.Errorf("gcs service account credentials must include client_email and private_key")
+	}
+	if account.TokenURI == "" {
+		account.TokenURI = gcsDefaultTokenURI
+	}
+
+	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(account.PrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("invalid gcs service account private key: %w", err)
+	}
+
+	endpoint := strings.TrimRight(strings.TrimSpace(cfg.Endpoint), "/")
+	if endpoint == "" {
+		endpoint = gcsDefaultEndpoint
+	}
+
+	return &backupGCSStore{
+		endpoint:   endpoint,
+		bucket:     cfg.Bucket,
+		prefix:     strings.Trim(cfg.Prefix, "/"),
+		account:    account,
+		signer:     key,
+		httpClient: &http.Client{Timeout: 60 * time.Second},
+	}, nil
+}
+
+func (g *backupGCSStore) Location() string {
+	return BackupLocationGCS
+}
+
+// accessToken returns an OAuth access token for the service account, exchanging
+// a signed JWT for a new one shortly before the current one expires.
+func (g *backupGCSStore) accessToken(ctx context.Context) (string, error) {
+	g.tokenMu.Lock()
+	defer g.tokenMu.Unlock()
+
+	now := time.Now()
+	if g.token != "" && now.Add(time.Minute).Before(g.tokenExpiry) {
+		return g.token, nil
+	}
+
+	claims := jwt.MapClaims{
+		"iss":   g.account.ClientEmail,
+		"scope": gcsScope,
+		"aud":   g.account.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}
+	assertion := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	if g.account.PrivateKeyID != "" {
+		assertion.Header["kid"] = g.account.PrivateKeyID
+	}
+	signed, err := assertion.SignedString(g.signer)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign gcs token request: %w", err)
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {signed},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.account.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to build gcs token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to obtain gcs access token: %w", err)
+	}
+	defer resp.Body.Close()
+	if err := checkStorageResponse(resp); err != nil {
+		return "", fmt.Errorf("failed to obtain gcs access token: %w", err)
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("failed to decode gcs access token: %w", err)
+	}
+	if token.AccessToken == "" {
+		return "", fmt.Errorf("gcs token response did not include an access token")
+	}
+
+	g.token = token.AccessToken
+	g.tokenExpiry = now.Add(time.Duration(token.ExpiresIn) * time.Second)
+	return g.token, nil
+}
+
+// do sends an authorized request to the Cloud Storage API. rawPath is appended to
+// the endpoint as is, so object names in it must already be escaped.
+func (g *backupGCSStore) do(ctx context.Context, method, rawPath string, query url.Values, body io.Reader, size int64, contentType string) (*http.Response, error) {
+	token, err := g.accessToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	target := g.endpoint + rawPath
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if body != nil {
+		req.ContentLength = size
+		req.Header.Set("Content-Type", contentType)
+	}
+	return g.httpClient.Do(req)
+}
+
+func (g *backupGCSStore) objectPath(name string) string {
+	return "/storage/v1/b/" + url.PathEscape(g.bucket) + "/o/" + url.PathEscape(backupObjectName(g.prefix, name))
+}
+
+func (g *backupGCSStore) Upload(ctx context.Context, archive *BackupArchive) (string, error) {
+	if archive == nil {
+		return "", nil
+	}
+	file, size, contentType, err := archiveUploadBody(archive)
+	if err != nil {
+		return "", err
+	}
+
+	objectName := backupObjectName(g.prefix, archive.Filename)
+	query := url.Values{"uploadType": {"media"}, "name": {objectName}}
+	resp, err := g.do(ctx, http.MethodPost, "/upload/storage/v1/b/"+url.PathEscape(g.bucket)+"/o", query, io.NewSectionReader(file, 0, size), size, contentType)
+	if err != nil {
+		return "", fmt.Errorf("failed to upload backup to bucket %s: %w", g.bucket, err)
+	}
+	defer resp.Body.Close()
+	if err := checkStorageResponse(resp); err != nil {
+		return "", fmt.Errorf("failed to upload backup to bucket %s: %w", g.bucket, err)
+	}
+
+	if err := archive.Reset(); err != nil {
+		logger.Warn("Failed to rewind archive after object storage upload", map[string]interface{}{"archive": archive.Filename, "error": err.Error()})
+	}
+
+	logger.Info("Automatic site backup uploaded", map[string]interface{}{"bucket": g.bucket, "object": objectName})
+
+	return objectName, nil
+}
+
+type gcsObjectList struct {
+	Items []struct {
+		Name    string    `json:"name"`
+		Size    string    `json:"size"`
+		Updated time.Time `json:"updated"`
+	} `json:"items"`
+	NextPageToken string `json:"nextPageToken"`
+}
+
+// List returns the backup archives under the configured prefix.
+func (g *backupGCSStore) List(ctx context.Context) ([]BackupFile, error) {
+	query := url.Values{"fields": {"items(name,size,updated),nextPageToken"}}
+	if g.prefix != "" {
+		query.Set("prefix", g.prefix+"/")
+	}
+
+	var backups []BackupFile
+	for page := 0; page < maxBackupListPages; page++ {
+		resp, err := g.do(ctx, http.MethodGet, "/storage/v1/b/"+url.PathEscape(g.bucket)+"/o", query, nil, 0, "")
+		if err != nil {
+			return nil, fmt.Errorf("failed to list backups in bucket %s: %w", g.bucket, err)
+		}
+
+		var result gcsObjectList
+		err = checkStorageResponse(resp)
+		if err == nil {
+			err = json.NewDecoder(resp.Body).Decode(&result)
+		}
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list backups in bucket %s: %w", g.bucket, err)
+		}
+
+		for _, object := range result.Items {
+			name := path.Base(object.Name)
+			if object.Name != backupObjectName(g.prefix, name) || !backupFilenamePattern.MatchString(name) {
+				continue
+			}
+			size, _ := strconv.ParseInt(object.Size, 10, 64)
+			backups = append(backups, newBackupFile(name, BackupLocationGCS, size, object.Updated))
+		}
+
+		if result.NextPageToken == "" {
+			break
+		}
+		query.Set("pageToken", result.NextPageToken)
+	}
+	return backups, nil
+}
+
+// Get opens a backup archive stored in the bucket.
+func (g *backupGCSStore) Get(ctx context.Context, name string) (io.ReadCloser, BackupFile, error) {
+	resp, err := g.do(ctx, http.MethodGet, g.objectPath(name), url.Values{"alt": {"media"}}, nil, 0, "")
+	if err != nil {
+		return nil, BackupFile{}, fmt.Errorf("failed to download backup from bucket %s: %w", g.bucket, err)
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, BackupFile{}, ErrBackupNotFound
+	}
+	if err := checkStorageResponse(resp); err != nil {
+		resp.Body.Close()
+		return nil, BackupFile{}, fmt.Errorf("failed to download backup from bucket %s: %w", g.bucket, err)
+	}
+
+	modTime, _ := http.ParseTime(resp.Header.Get("Last-Modified"))
+	return resp.Body, newBackupFile(name, BackupLocationGCS, resp.ContentLength, modTime), nil
+}
+
+// Delete removes a backup archive from the bucket.
+func (g *backupGCSStore) Delete(ctx context.Context, name string) error {
+	resp, err := g.do(ctx, http.MethodDelete, g.objectPath(name), nil, nil, 0, "")
+	if err != nil {
+		return fmt.Errorf("failed to delete backup from bucket %s: %w", g.bucket, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrBackupNotFound
+	}
+	if err := checkStorageResponse(resp); err != nil {
+		return fmt.Errorf("failed to delete backup from bucket %s: %w", g.bucket, err)
+	}
+	return nil
+}
@@ -0,0 +1,271 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"constructor-script-backend/pkg/logger"
+)
+
+// backupS3Store keeps backups in an S3-compatible bucket, signing requests with
+// AWS Signature Version 4.
+type backupS3Store struct {
+	endpoint   string
+	accessKey  string
+	secretKey  string
+	bucket     string
+	region     string
+	useSSL     bool
+	prefix     string
+	httpClient *http.Client
+}
+
+func newBackupS3Store(cfg BackupS3Config) (*backupS3Store, error) {
+	if cfg.Endpoint == "" {
+		return nil, fmt.Errorf("s3 endpoint is required")
+	}
+	if cfg.AccessKey == "" || cfg.SecretKey == "" {
+		return nil, fmt.Errorf("s3 credentials are required")
+	}
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("s3 bucket is required")
+	}
+
+	region := strings.TrimSpace(cfg.Region)
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	store := &backupS3Store{
+		endpoint:   strings.TrimSpace(cfg.Endpoint),
+		accessKey:  cfg.AccessKey,
+		secretKey:  cfg.SecretKey,
+		bucket:     cfg.Bucket,
+		region:     region,
+		useSSL:     cfg.UseSSL,
+		prefix:     strings.Trim(cfg.Prefix, "/"),
+		httpClient: &http.Client{Timeout: 60 * time.Second},
+	}
+
+	return store, nil
+}
+
+func (u *backupS3Store) Location() string {
+	return BackupLocationS3
+}
+
+func (u *backupS3Store) objectName(filename string) string {
+	return backupObjectName(u.prefix, filename)
+}
+
+func (u *backupS3Store) Upload(ctx context.Context, archive *BackupArchive) (string, error) {
+	if u == nil || archive == nil {
+		return "", nil
+	}
+
+	file, size, contentType, err := archiveUploadBody(archive)
+	if err != nil {
+		return "", err
+	}
+
+	objectName := u.objectName(archive.Filename)
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, io.NewSectionReader(file, 0, size)); err != nil {
+		return "", fmt.Errorf("failed to hash archive for upload: %w", err)
+	}
+	payloadHash := hex.EncodeToString(hasher.Sum(nil))
+
+	req, err := u.newRequest(ctx, http.MethodPut, objectName, nil, io.NewSectionReader(file, 0, size), payloadHash)
+	if err != nil {
+		return "", fmt.Errorf("failed to build upload request: %w", err)
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := u.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to upload backup to bucket %s: %w", u.bucket, err)
+	}
+	defer resp.Body.Close()
+
+	if err := checkStorageResponse(resp); err != nil {
+		return "", fmt.Errorf("failed to upload backup to bucket %s: %w", u.bucket, err)
+	}
+
+	if err := archive.Reset(); err != nil {
+		logger.Warn("Failed to rewind archive after object storage upload", map[string]interface{}{"archive": archive.Filename, "error": err.Error()})
+	}
+
+	logger.Info("Automatic site backup uploaded", map[string]interface{}{"bucket": u.bucket, "object": objectName})
+
+	return objectName, nil
+}
+
+// emptyPayloadHash is the SHA-256 of an empty request body.
+const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// newRequest builds a request for an object in the bucket, or for the bucket itself
+// when objectName is empty, signed with AWS Signature Version 4.
+func (u *backupS3Store) newRequest(ctx context.Context, method, objectName string, query url.Values, body io.Reader, payloadHash string) (*http.Request, error) {
+	scheme := "https"
+	if !u.useSSL {
+		scheme = "http"
+	}
+
+	objectPath := path.Join(u.bucket, objectName)
+	if !strings.HasPrefix(objectPath, "/") {
+		objectPath = "/" + objectPath
+	}
+	if objectName == "" {
+		objectPath += "/"
+	}
+
+	// SigV4 wants spaces encoded as %20 rather than +.
+	canonicalQuery := strings.ReplaceAll(query.Encode(), "+", "%20")
+	endpointURL := url.URL{
+		Scheme:   scheme,
+		Host:     u.endpoint,
+		Path:     objectPath,
+		RawQuery: canonicalQuery,
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpointURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	amzDate := time.Now().UTC()
+	amzDateStr := amzDate.Format("20060102T150405Z")
+	dateStamp := amzDate.Format("20060102")
+	req.Header.Set("x-amz-content-sha256", payloadHash)
+	req.Header.Set("x-amz-date", amzDateStr)
+
+	canonicalURI := endpointURL.EscapedPath()
+	canonicalHeaders := fmt.Sprintf("host:%s\nx-amz-content-sha256:%s\nx-amz-date:%s\n", strings.ToLower(req.Host), payloadHash, amzDateStr)
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		method,
+		canonicalURI,
+		canonicalQuery,
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	hashedCanonicalRequest := sha256.Sum256([]byte(canonicalRequest))
+	credentialScope := fmt.Sprintf("%s/%s/s3/aws4_request", dateStamp, u.region)
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDateStr,
+		credentialScope,
+		hex.EncodeToString(hashedCanonicalRequest[:]),
+	}, "\n")
+
+	signingKey := deriveSigningKey(u.secretKey, dateStamp, u.region, "s3")
+	signature := hmacSHA256Hex(signingKey, stringToSign)
+
+	authorization := fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", u.accessKey, credentialScope, signedHeaders, signature)
+	req.Header.Set("Authorization", authorization)
+
+	return req, nil
+}
+
+type s3ListBucketResult struct {
+	Contents []struct {
+		Key          string    `xml:"Key"`
+		Size         int64     `xml:"Size"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// List returns the backup archives under the configured prefix.
+func (u *backupS3Store) List(ctx context.Context) ([]BackupFile, error) {
+	query := url.Values{"list-type": {"2"}}
+	if u.prefix != "" {
+		query.Set("prefix", u.prefix+"/")
+	}
+
+	var backups []BackupFile
+	for page := 0; page < maxBackupListPages; page++ {
+		req, err := u.newRequest(ctx, http.MethodGet, "", query, nil, emptyPayloadHash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build object storage list request: %w", err)
+		}
+		resp, err := u.httpClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list backups in bucket %s: %w", u.bucket, err)
+		}
+
+		var result s3ListBucketResult
+		err = decodeStorageXML(resp, &result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list backups in bucket %s: %w", u.bucket, err)
+		}
+
+		for _, object := range result.Contents {
+			name := path.Base(object.Key)
+			if object.Key != u.objectName(name) || !backupFilenamePattern.MatchString(name) {
+				continue
+			}
+			backups = append(backups, newBackupFile(name, BackupLocationS3, object.Size, object.LastModified))
+		}
+
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			break
+		}
+		query.Set("continuation-token", result.NextContinuationToken)
+	}
+	return backups, nil
+}
+
+// Get opens a backup archive stored in the bucket.
+func (u *backupS3Store) Get(ctx context.Context, name string) (io.ReadCloser, BackupFile, error) {
+	req, err := u.newRequest(ctx, http.MethodGet, u.objectName(name), nil, nil, emptyPayloadHash)
+	if err != nil {
+		return nil, BackupFile{}, fmt.Errorf("failed to build object storage download request: %w", err)
+	}
+	resp, err := u.httpClient.Do(req)
+	if err != nil {
+		return nil, BackupFile{}, fmt.Errorf("failed to download backup from bucket %s: %w", u.bucket, err)
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, BackupFile{}, ErrBackupNotFound
+	}
+	if err := decodeStorageXML(resp, nil); err != nil {
+		resp.Body.Close()
+		return nil, BackupFile{}, fmt.Errorf("failed to download backup from bucket %s: %w", u.bucket, err)
+	}
+
+	modTime, _ := http.ParseTime(resp.Header.Get("Last-Modified"))
+	return resp.Body, newBackupFile(name, BackupLocationS3, resp.ContentLength, modTime), nil
+}
+
+// Delete removes a backup archive from the bucket.
+func (u *backupS3Store) Delete(ctx context.Context, name string) error {
+	req, err := u.newRequest(ctx, http.MethodDelete, u.objectName(name), nil, nil, emptyPayloadHash)
+	if err != nil {
+		return fmt.Errorf("failed to build object storage delete request: %w", err)
+	}
+	resp, err := u.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to delete backup from bucket %s: %w", u.bucket, err)
+	}
+	defer resp.Body.Close()
+	if err := decodeStorageXML(resp, nil); err != nil {
+		return fmt.Errorf("failed to delete backup from bucket %s: %w", u.bucket, err)
+	}
+	return nil
+}
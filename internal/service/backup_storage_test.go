@@ -0,0 +1,266 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func testBackupArchive(t *testing.T, name, content string) *BackupArchive {
+	t.Helper()
+	file, err := os.Create(filepath.Join(t.TempDir(), name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { file.Close() })
+	if _, err := file.WriteString(content); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	return &BackupArchive{file: file, Filename: name, ContentType: "application/zip"}
+}
+
+func TestNewBackupStoreAllowsOneTarget(t *testing.T) {
+	store, err := newBackupStore(BackupOptions{})
+	if err != nil || store != nil {
+		t.Fatalf("expected no store without configuration, got %v, %v", store, err)
+	}
+
+	_, err = newBackupStore(BackupOptions{
+		S3:    &BackupS3Config{Endpoint: "s3.example.com", AccessKey: "key", SecretKey: "secret", Bucket: "bucket"},
+		Azure: &BackupAzureConfig{Account: "account", Key: "a2V5", Container: "backups"},
+	})
+	if err == nil {
+		t.Fatal("expected an error when several object storages are configured")
+	}
+}
+
+func TestGCSBackupStore(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tokenRequests int
+	var uploaded, deleted string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			tokenRequests++
+			_ = r.ParseForm()
+			claims := jwt.MapClaims{}
+			if _, err := jwt.ParseWithClaims(r.PostForm.Get("assertion"), claims, func(*jwt.Token) (interface{}, error) {
+				return &key.PublicKey, nil
+			}); err != nil || claims["iss"] != "backups@example.iam.gserviceaccount.com" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "token", "expires_in": 3600})
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/upload/storage/v1/b/bucket/o":
+			body, _ := io.ReadAll(r.Body)
+			uploaded = r.URL.Query().Get("name") + "=" + string(body)
+			_, _ = w.Write([]byte(`{}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/storage/v1/b/bucket/o":
+			if r.URL.Query().Get("prefix") != "site/" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write([]byte(`{"items":[
+				{"name":"site/backup-20250103-080000.zip","size":"42","updated":"2025-01-03T08:00:00Z"},
+				{"name":"site/nested/backup-20250102-080000.zip","size":"1","updated":"2025-01-02T08:00:00Z"},
+				{"name":"site/readme.txt","size":"1","updated":"2025-01-02T08:00:00Z"}
+			]}`))
+		case r.Method == http.MethodGet && r.URL.EscapedPath() == "/storage/v1/b/bucket/o/site%2Fbackup-20250103-080000.zip":
+			w.Header().Set("Last-Modified", "Fri, 03 Jan 2025 08:00:00 GMT")
+			_, _ = w.Write([]byte("zipdata"))
+		case r.Method == http.MethodDelete && r.URL.EscapedPath() == "/storage/v1/b/bucket/o/site%2Fbackup-20250103-080000.zip":
+			deleted = r.URL.EscapedPath()
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	credentials, _ := json.Marshal(map[string]string{
+		"client_email": "backups@example.iam.gserviceaccount.com",
+		"private_key":  string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})),
+		"token_uri":    server.URL + "/token",
+	})
+	svc := NewBackupService(nil, nil, BackupOptions{
+		UploadDir: t.TempDir(),
+		GCS:       &BackupGCSConfig{Bucket: "bucket", Prefix: "/site/", Credentials: credentials, Endpoint: server.URL},
+	})
+	if svc.storage == nil || svc.storage.Location() != BackupLocationGCS {
+		t.Fatal("expected the GCS store to be configured")
+	}
+
+	object, err := svc.storage.Upload(context.Background(), testBackupArchive(t, "backup-20250104-080000.zip", "new"))
+	if err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	if object != "site/backup-20250104-080000.zip" || uploaded != object+"=new" {
+		t.Fatalf("unexpected upload %q (%q)", uploaded, object)
+	}
+
+	backups, err := svc.ListBackups(context.Background())
+	if err != nil {
+		t.Fatalf("ListBackups: %v", err)
+	}
+	if len(backups) != 1 || backups[0].Location != BackupLocationGCS || backups[0].Size != 42 {
+		t.Fatalf("unexpected backups: %+v", backups)
+	}
+
+	reader, backup, err := svc.OpenBackup(context.Background(), BackupLocationGCS, backups[0].Name)
+	if err != nil {
+		t.Fatalf("OpenBackup: %v", err)
+	}
+	data, _ := io.ReadAll(reader)
+	reader.Close()
+	if string(data) != "zipdata" || backup.CreatedAt.IsZero() {
+		t.Fatalf("unexpected download %q (%+v)", data, backup)
+	}
+
+	if _, _, err := svc.OpenBackup(context.Background(), BackupLocationS3, backups[0].Name); !errors.Is(err, ErrBackupNotFound) {
+		t.Fatalf("expected another location to be not found, got %v", err)
+	}
+	if err := svc.DeleteBackup(context.Background(), BackupLocationGCS, "backup-20250101-000000.zip"); !errors.Is(err, ErrBackupNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+	if err := svc.DeleteBackup(context.Background(), BackupLocationGCS, backups[0].Name); err != nil {
+		t.Fatalf("DeleteBackup: %v", err)
+	}
+	if deleted == "" {
+		t.Fatal("expected the object to be deleted")
+	}
+	if tokenRequests != 1 {
+		t.Fatalf("expected the access token to be reused, got %d token requests", tokenRequests)
+	}
+}
+
+func TestAzureStringToSign(t *testing.T) {
+	store, err := newBackupAzureStore(BackupAzureConfig{Account: "myaccount", Key: "a2V5", Container: "backups"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "https://myaccount.blob.core.windows.net/backups?restype=container&comp=list&prefix=site%2F", nil)
+	req.Header.Set("x-ms-date", "Fri, 03 Jan 2025 08:00:00 GMT")
+	req.Header.Set("x-ms-version", azureStorageVersion)
+
+	expected := "GET\n\n\n\n\n\n\n\n\n\n\n\n" +
+		"x-ms-date:Fri, 03 Jan 2025 08:00:00 GMT\n" +
+		"x-ms-version:" + azureStorageVersion + "\n" +
+		"/myaccount/backups\ncomp:list\nprefix:site/\nrestype:container"
+	if got := store.stringToSign(req); got != expected {
+		t.Fatalf("unexpected string to sign:\n%q\nwant\n%q", got, expected)
+	}
+}
+
+func TestAzureBackupStore(t *testing.T) {
+	var uploaded, deleted string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "SharedKey devstoreaccount1:") || r.Header.Get("x-ms-date") == "" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		switch {
+		case r.Method == http.MethodPut && r.URL.Path == "/devstoreaccount1/backups/site/backup-20250104-080000.zip":
+			if r.Header.Get("x-ms-blob-type") != "BlockBlob" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			body, _ := io.ReadAll(r.Body)
+			uploaded = string(body)
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodGet && r.URL.Path == "/devstoreaccount1/backups":
+			if r.URL.Query().Get("marker") == "" {
+				_, _ = w.Write([]byte(`<?xml version="1.0" encoding="utf-8"?><EnumerationResults><Blobs>
+					<Blob><Name>site/backup-20250103-080000.zip</Name><Properties><Last-Modified>Fri, 03 Jan 2025 08:00:00 GMT</Last-Modified><Content-Length>42</Content-Length></Properties></Blob>
+				</Blobs><NextMarker>page2</NextMarker></EnumerationResults>`))
+				return
+			}
+			_, _ = w.Write([]byte(`<?xml version="1.0" encoding="utf-8"?><EnumerationResults><Blobs>
+				<Blob><Name>site/backup-20250102-080000.zip.enc</Name><Properties><Last-Modified>Thu, 02 Jan 2025 08:00:00 GMT</Last-Modified><Content-Length>7</Content-Length></Properties></Blob>
+			</Blobs><NextMarker/></EnumerationResults>`))
+		case r.Method == http.MethodGet && r.URL.Path == "/devstoreaccount1/backups/site/backup-20250103-080000.zip":
+			_, _ = w.Write([]byte("zipdata"))
+		case r.Method == http.MethodDelete && r.URL.Path == "/devstoreaccount1/backups/site/backup-20250103-080000.zip":
+			deleted = r.URL.Path
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	svc := NewBackupService(nil, nil, BackupOptions{
+		UploadDir: t.TempDir(),
+		Azure: &BackupAzureConfig{
+			Account:   "devstoreaccount1",
+			Key:       base64.StdEncoding.EncodeToString([]byte("secret")),
+			Container: "backups",
+			Prefix:    "site",
+			Endpoint:  server.URL + "/devstoreaccount1",
+		},
+	})
+	if svc.storage == nil || svc.storage.Location() != BackupLocationAzure {
+		t.Fatal("expected the Azure store to be configured")
+	}
+
+	if _, err := svc.storage.Upload(context.Background(), testBackupArchive(t, "backup-20250104-080000.zip", "new")); err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	if uploaded != "new" {
+		t.Fatalf("unexpected upload %q", uploaded)
+	}
+
+	backups, err := svc.ListBackups(context.Background())
+	if err != nil {
+		t.Fatalf("ListBackups: %v", err)
+	}
+	if len(backups) != 2 || backups[0].Size != 42 || !backups[1].Encrypted || backups[1].Location != BackupLocationAzure {
+		t.Fatalf("unexpected backups: %+v", backups)
+	}
+
+	reader, _, err := svc.OpenBackup(context.Background(), BackupLocationAzure, backups[0].Name)
+	if err != nil {
+		t.Fatalf("OpenBackup: %v", err)
+	}
+	data, _ := io.ReadAll(reader)
+	reader.Close()
+	if string(data) != "zipdata" {
+		t.Fatalf("unexpected download %q", data)
+	}
+
+	if err := svc.DeleteBackup(context.Background(), BackupLocationAzure, backups[0].Name); err != nil {
+		t.Fatalf("DeleteBackup: %v", err)
+	}
+	if deleted == "" {
+		t.Fatal("expected the blob to be deleted")
+	}
+}
@@ -0,0 +1,274 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"constructor-script-backend/internal/events"
+	"constructor-script-backend/pkg/logger"
+)
+
+var ErrNoBackups = errors.New("no backups to verify")
+
+// BackupVerification is the outcome of checking a stored archive.
+type BackupVerification struct {
+	Name      string         `json:"name,omitempty"`
+	Location  string         `json:"location,omitempty"`
+	CheckedAt time.Time      `json:"checked_at"`
+	OK        bool           `json:"ok"`
+	Error     string         `json:"error,omitempty"`
+	Summary   *BackupSummary `json:"summary,omitempty"`
+}
+
+// SetEventBus configures the bus used to announce failed backup verifications.
+func (s *BackupService) SetEventBus(bus *events.Bus) {
+	if s == nil {
+		return
+	}
+	s.events = bus
+}
+
+// SetAlertEmail sends failed automatic backups and verifications by email to
+// recipient.
+func (s *BackupService) SetAlertEmail(emailService *EmailService, recipient string) {
+	if s == nil {
+		return
+	}
+	s.alertEmail = emailService
+	s.alertRecipient = strings.TrimSpace(recipient)
+}
+
+// LastVerification returns the result of the latest verification, if any ran.
+func (s *BackupService) LastVerification() *BackupVerification {
+	if s == nil {
+		return nil
+	}
+	s.verifyMu.Lock()
+	defer s.verifyMu.Unlock()
+	if s.lastVerification == nil {
+		return nil
+	}
+	result := *s.lastVerification
+	return &result
+}
+
+// VerifyLatest re-reads the newest stored archive, local or in object storage: it
+// checks the encryption tag, the checksum of every zip entry and the manifest. A
+// failure is announced on the event bus and by email before it is returned.
+func (s *BackupService) VerifyLatest(ctx context.Context) (BackupVerification, error) {
+	if s == nil {
+		return BackupVerification{}, fmt.Errorf("backup service not configured")
+	}
+
+	result := BackupVerification{CheckedAt: time.Now().UTC()}
+	err := s.verifyLatest(ctx, &result)
+	if errors.Is(err, ErrNoBackups) {
+		return result, err
+	}
+	if err != nil {
+		result.Error = err.Error()
+	} else {
+		result.OK = true
+	}
+
+	s.verifyMu.Lock()
+	stored := result
+	s.lastVerification = &stored
+	s.verifyMu.Unlock()
+
+	if err != nil {
+		s.alertVerificationFailure(result)
+		return result, err
+	}
+	logger.Info("Backup verified", map[string]interface{}{"location": result.Location, "name": result.Name})
+	return result, nil
+}
+
+func (s *BackupService) verifyLatest(ctx context.Context, result *BackupVerification) error {
+	backups, err := s.ListBackups(ctx)
+	if err != nil {
+		return err
+	}
+	if len(backups) == 0 {
+		return ErrNoBackups
+	}
+	latest := backups[0]
+	result.Name = latest.Name
+	result.Location = latest.Location
+
+	reader, _, err := s.OpenBackup(ctx, latest.Location, latest.Name)
+	if err != nil {
+		return err
+	}
+	spool, err := os.CreateTemp("", "constructor-verify-*.zip")
+	if err != nil {
+		reader.Close()
+		return fmt.Errorf("failed to prepare temporary archive: %w", err)
+	}
+	defer func() {
+		spool.Close()
+		os.Remove(spool.Name())
+	}()
+	_, err = io.Copy(spool, reader)
+	reader.Close()
+	if err != nil {
+		return fmt.Errorf("failed to read backup: %w", err)
+	}
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to rewind backup: %w", err)
+	}
+
+	summary, err := s.verifyArchive(spool)
+	if err != nil {
+		return err
+	}
+	result.Summary = &summary
+	return nil
+}
+
+// verifyArchive checks an archive read from file and returns its summary.
+func (s *BackupService) verifyArchive(file *os.File) (BackupSummary, error) {
+	var summary BackupSummary
+
+	encrypted, err := detectEncryptedArchive(file)
+	if err != nil {
+		return summary, fmt.Errorf("failed to inspect backup archive: %w", err)
+	}
+	archiveFile := file
+	if encrypted {
+		if s.encryptor == nil {
+			return summary, ErrBackupEncrypted
+		}
+		decrypted, err := s.encryptor.DecryptFile(file)
+		if err != nil {
+			return summary, err
+		}
+		defer func() {
+			decrypted.Close()
+			os.Remove(decrypted.Name())
+		}()
+		archiveFile = decrypted
+	}
+
+	info, err := archiveFile.Stat()
+	if err != nil {
+		return summary, fmt.Errorf("failed to inspect backup archive: %w", err)
+	}
+	reader, err := zip.NewReader(archiveFile, info.Size())
+	if err != nil {
+		return summary, fmt.Errorf("failed to read archive contents: %w", err)
+	}
+
+	// Reading every entry to the end makes the zip reader check its CRC-32.
+	uploads := make(map[string]struct{})
+	for _, entry := range reader.File {
+		rc, err := entry.Open()
+		if err != nil {
+			return summary, fmt.Errorf("failed to open %s: %w", entry.Name, err)
+		}
+		_, err = io.Copy(io.Discard, rc)
+		rc.Close()
+		if err != nil {
+			return summary, fmt.Errorf("archive entry %s is corrupt: %w", entry.Name, err)
+		}
+		if strings.HasPrefix(entry.Name, "uploads/") {
+			uploads[strings.TrimPrefix(entry.Name, "uploads/")] = struct{}{}
+		}
+	}
+
+	manifest, err := s.loadManifest(reader)
+	if err != nil {
+		return summary, err
+	}
+	if manifest.SchemaVersion != backupSchemaVersion {
+		return summary, ErrBackupVersion
+	}
+	// Uploads deleted while the archive was written are listed but absent, so a gap
+	// is worth a warning rather than a failure.
+	missing := 0
+	for _, upload := range manifest.Uploads {
+		if _, ok := uploads[path.Clean(upload)]; !ok {
+			missing++
+		}
+	}
+	if missing > 0 {
+		logger.Warn("Backup archive lacks uploads listed in its manifest", map[string]interface{}{"missing": missing})
+	}
+	if manifest.DatabaseDump != "" && !hasEntry(reader, manifest.DatabaseDump) {
+		return summary, fmt.Errorf("%w: database dump %s is missing", ErrInvalidBackup, manifest.DatabaseDump)
+	}
+
+	return BackupSummary{
+		SchemaVersion: manifest.SchemaVersion,
+		GeneratedAt:   manifest.GeneratedAt,
+		Application:   manifest.Application,
+		Users:         len(manifest.Data.Users),
+		Categories:    len(manifest.Data.Categories),
+		Tags:          len(manifest.Data.Tags),
+		Posts:         len(manifest.Data.Posts),
+		Pages:         len(manifest.Data.Pages),
+		Comments:      len(manifest.Data.Comments),
+		Settings:      len(manifest.Data.Settings),
+		MenuItems:     len(manifest.Data.MenuItems),
+		SocialLinks:   len(manifest.Data.SocialLinks),
+		PostTags:      len(manifest.Data.PostTags),
+		Uploads:       len(manifest.Uploads),
+		PluginRows:    countPluginRows(manifest.Data.Plugins),
+		DatabaseDump:  manifest.DatabaseDump != "",
+	}, nil
+}
+
+func (s *BackupService) alertVerificationFailure(result BackupVerification) {
+	logger.Error(errors.New(result.Error), "Backup verification failed", map[string]interface{}{
+		"location": result.Location,
+		"name":     result.Name,
+	})
+
+	if s.events != nil {
+		s.events.Publish(context.Background(), events.BackupVerificationFailed, map[string]interface{}{
+			"name":       result.Name,
+			"location":   result.Location,
+			"error":      result.Error,
+			"checked_at": result.CheckedAt,
+		})
+	}
+
+	s.sendAlert(
+		"Backup verification failed",
+		"The latest backup failed verification and may not be restorable.",
+		fmt.Sprintf("%s (%s)", result.Name, result.Location), result.Error, result.CheckedAt,
+	)
+}
+
+// sendAlert emails a backup problem to the alert recipient, if one is set.
+func (s *BackupService) sendAlert(title, summary, archive, problem string, at time.Time) {
+	if s.alertEmail == nil || s.alertRecipient == "" || !s.alertEmail.Enabled() {
+		return
+	}
+	data := map[string]interface{}{
+		"Title":      title,
+		"Summary":    summary,
+		"Archive":    archive,
+		"Error":      problem,
+		"OccurredAt": at.Format(time.RFC1123),
+	}
+	if err := s.alertEmail.SendTemplate(s.alertRecipient, EmailTemplateBackupAlert, title, data); err != nil {
+		logger.Error(err, "Failed to send backup alert", map[string]interface{}{"to": s.alertRecipient, "alert": title})
+	}
+}
+
+func hasEntry(reader *zip.Reader, name string) bool {
+	for _, entry := range reader.File {
+		if entry.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"constructor-script-backend/internal/events"
+)
+
+func writeTestArchive(t *testing.T, path string) {
+	t.Helper()
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	writer := zip.NewWriter(file)
+	manifest, err := writer.CreateHeader(&zip.FileHeader{Name: "manifest.json", Method: zip.Store})
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest.Write([]byte(`{"schema_version":"1","application":"test","uploads":["a.png"],"data":{"posts":[{"id":1}]}}`))
+	upload, err := writer.CreateHeader(&zip.FileHeader{Name: "uploads/a.png", Method: zip.Store})
+	if err != nil {
+		t.Fatal(err)
+	}
+	upload.Write([]byte("image bytes that will be corrupted"))
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerifyLatestBackup(t *testing.T) {
+	uploadDir := t.TempDir()
+	svc := NewBackupService(nil, nil, BackupOptions{UploadDir: uploadDir})
+	bus := events.NewBus()
+	svc.SetEventBus(bus)
+	failures := make(chan events.Event, 1)
+	bus.Subscribe(events.BackupVerificationFailed, func(ctx context.Context, event events.Event) {
+		failures <- event
+	})
+
+	if _, err := svc.VerifyLatest(context.Background()); !errors.Is(err, ErrNoBackups) {
+		t.Fatalf("expected ErrNoBackups, got %v", err)
+	}
+
+	dir := filepath.Join(uploadDir, autoBackupDirName)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	archive := filepath.Join(dir, "backup-20250101-120000.zip")
+	writeTestArchive(t, archive)
+
+	result, err := svc.VerifyLatest(context.Background())
+	if err != nil {
+		t.Fatalf("VerifyLatest: %v", err)
+	}
+	if !result.OK || result.Name != filepath.Base(archive) || result.Summary == nil || result.Summary.Posts != 1 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	data, err := os.ReadFile(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	index := -1
+	for i := 0; i+5 <= len(data); i++ {
+		if string(data[i:i+5]) == "image" {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		t.Fatal("upload payload not found in archive")
+	}
+	data[index] = 'I'
+	if err := os.WriteFile(archive, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err = svc.VerifyLatest(context.Background())
+	if err == nil || result.OK {
+		t.Fatalf("expected the corrupted archive to fail, got %+v", result)
+	}
+	if last := svc.LastVerification(); last == nil || last.OK {
+		t.Fatalf("expected the failure to be kept, got %+v", last)
+	}
+
+	select {
+	case event := <-failures:
+		if event.Payload["name"] != filepath.Base(archive) {
+			t.Fatalf("unexpected event payload: %+v", event.Payload)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected a verification failure event")
+	}
+}
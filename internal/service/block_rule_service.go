@@ -0,0 +1,476 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/repository"
+	"constructor-script-backend/pkg/geoip"
+	"constructor-script-backend/pkg/logger"
+)
+
+const (
+	// blockRuleRefresh bounds how long an instance applies rules another
+	// instance changed.
+	blockRuleRefresh = 30 * time.Second
+
+	maxBlockRuleValue   = 256
+	maxBlockRuleMinutes = 365 * 24 * 60
+	// maxRateLimitStrikes bounds the addresses tracked for automatic bans.
+	maxRateLimitStrikes = 10000
+)
+
+var blockRuleCountryPattern = regexp.MustCompile(`^[A-Z]{2}$`)
+
+var ErrBlockRulesUnavailable = errors.New("block rules not configured")
+
+type BlockRuleValidationError struct {
+	Reason string
+}
+
+func (e *BlockRuleValidationError) Error() string {
+	if e == nil {
+		return ""
+	}
+	return e.Reason
+}
+
+func blockRuleValidationErrorf(format string, args ...interface{}) error {
+	return &BlockRuleValidationError{Reason: fmt.Sprintf(format, args...)}
+}
+
+type compiledBlockRule struct {
+	id        uint
+	allow     bool
+	network   *net.IPNet
+	agent     *regexp.Regexp
+	country   string
+	expiresAt *time.Time
+}
+
+func (r compiledBlockRule) matches(ip net.IP, userAgent, country string, now time.Time) bool {
+	if r.expiresAt != nil && !r.expiresAt.After(now) {
+		return false
+	}
+	switch {
+	case r.network != nil:
+		return ip != nil && r.network.Contains(ip)
+	case r.agent != nil:
+		return r.agent.MatchString(userAgent)
+	default:
+		return country != "" && r.country == country
+	}
+}
+
+type rateLimitStrikes struct {
+	count int
+	since time.Time
+}
+
+// BlockRuleService keeps the admin blocklist of addresses, ranges, user agents
+// and countries. Every request is checked against it, so the rules are held in
+// memory and matches are counted there until MaintainRules stores them.
+type BlockRuleService struct {
+	repo  repository.BlockRuleRepository
+	geoip *geoip.Reader
+
+	mu           sync.RWMutex
+	rules        []compiledBlockRule
+	hasCountries bool
+	loadedAt     time.Time
+
+	hitsMu sync.Mutex
+	hits   map[uint]int64
+
+	banMu        sync.Mutex
+	strikes      map[string]*rateLimitStrikes
+	banThreshold int
+	banWindow    time.Duration
+	banDuration  time.Duration
+
+	now func() time.Time
+}
+
+func NewBlockRuleService(repo repository.BlockRuleRepository) *BlockRuleService {
+	return &BlockRuleService{
+		repo:    repo,
+		hits:    make(map[uint]int64),
+		strikes: make(map[string]*rateLimitStrikes),
+		now:     time.Now,
+	}
+}
+
+// SetGeoIP enables country rules for requests whose country no CDN header gave.
+func (s *BlockRuleService) SetGeoIP(reader *geoip.Reader) {
+	if s == nil {
+		return
+	}
+	s.geoip = reader
+}
+
+// SetAutoBan bans an address for duration once the rate limiter rejected
+// threshold of its requests within window. A threshold of 0 disables bans.
+func (s *BlockRuleService) SetAutoBan(threshold int, window, duration time.Duration) {
+	if s == nil {
+		return
+	}
+	s.banMu.Lock()
+	defer s.banMu.Unlock()
+	s.banThreshold = threshold
+	s.banWindow = window
+	s.banDuration = duration
+}
+
+func (s *BlockRuleService) List() ([]models.BlockRule, error) {
+	if s == nil || s.repo == nil {
+		return []models.BlockRule{}, nil
+	}
+	rules, err := s.repo.List()
+	if err != nil {
+		return nil, err
+	}
+	if rules == nil {
+		rules = []models.BlockRule{}
+	}
+
+	// Include the matches not stored yet.
+	s.hitsMu.Lock()
+	for i := range rules {
+		rules[i].Hits += s.hits[rules[i].ID]
+	}
+	s.hitsMu.Unlock()
+	return rules, nil
+}
+
+func (s *BlockRuleService) Create(req models.CreateBlockRuleRequest) (*models.BlockRule, error) {
+	rule := &models.BlockRule{
+		Kind:   strings.ToLower(strings.TrimSpace(req.Kind)),
+		Action: strings.ToLower(strings.TrimSpace(req.Action)),
+		Note:   strings.TrimSpace(req.Note),
+		Source: models.BlockRuleSourceManual,
+	}
+	if rule.Action == "" {
+		rule.Action = models.BlockRuleActionBlock
+	}
+	if rule.Action != models.BlockRuleActionBlock && rule.Action != models.BlockRuleActionAllow {
+		return nil, blockRuleValidationErrorf("action must be %q or %q", models.BlockRuleActionBlock, models.BlockRuleActionAllow)
+	}
+	if len(rule.Note) > maxBlockRuleValue {
+		return nil, blockRuleValidationErrorf("note must be at most %d characters", maxBlockRuleValue)
+	}
+	if req.DurationMinutes < 0 || req.DurationMinutes > maxBlockRuleMinutes {
+		return nil, blockRuleValidationErrorf("duration_minutes must be between 0 and %d", maxBlockRuleMinutes)
+	}
+
+	value, err := normalizeBlockRuleValue(rule.Kind, req.Value)
+	if err != nil {
+		return nil, blockRuleValidationErrorf("%s", err.Error())
+	}
+	rule.Value = value
+
+	if req.DurationMinutes > 0 {
+		expiresAt := s.clock().Add(time.Duration(req.DurationMinutes) * time.Minute)
+		rule.ExpiresAt = &expiresAt
+	}
+
+	if s == nil || s.repo == nil {
+		return nil, ErrBlockRulesUnavailable
+	}
+	if err := s.repo.Create(rule); err != nil {
+		return nil, err
+	}
+	s.invalidate()
+	return rule, nil
+}
+
+func (s *BlockRuleService) Delete(id uint) error {
+	if s == nil || s.repo == nil {
+		return ErrBlockRulesUnavailable
+	}
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+	s.invalidate()
+	return nil
+}
+
+// Blocked reports whether the request is refused. country is the visitor's
+// country reported by a CDN, or "" to look it up in the GeoIP database. Allow
+// rules take precedence over block rules.
+func (s *BlockRuleService) Blocked(ip, userAgent, country string) bool {
+	rules, hasCountries := s.activeRules()
+	if len(rules) == 0 {
+		return false
+	}
+
+	parsed := net.ParseIP(strings.TrimSpace(ip))
+	if hasCountries && country == "" && parsed != nil && s.geoip != nil {
+		found, err := s.geoip.Country(parsed)
+		if err != nil {
+			logger.Warn("Failed to look up the country of an address", map[string]interface{}{"ip": ip, "error": err.Error()})
+		}
+		country = found
+	}
+
+	now := s.clock()
+	var blockedBy *compiledBlockRule
+	for i := range rules {
+		rule := &rules[i]
+		if !rule.matches(parsed, userAgent, country, now) {
+			continue
+		}
+		if rule.allow {
+			s.hit(rule.id)
+			return false
+		}
+		if blockedBy == nil {
+			blockedBy = rule
+		}
+	}
+	if blockedBy == nil {
+		return false
+	}
+	s.hit(blockedBy.id)
+	return true
+}
+
+// RecordRateLimitViolation counts a request the rate limiter rejected and bans
+// the address once it keeps going past the limit.
+func (s *BlockRuleService) RecordRateLimitViolation(ip string) {
+	if s == nil || s.repo == nil {
+		return
+	}
+	parsed := net.ParseIP(strings.TrimSpace(ip))
+	if parsed == nil {
+		return
+	}
+	ip = parsed.String()
+	now := s.clock()
+
+	s.banMu.Lock()
+	threshold, window, duration := s.banThreshold, s.banWindow, s.banDuration
+	if threshold <= 0 || duration <= 0 {
+		s.banMu.Unlock()
+		return
+	}
+	strike := s.strikes[ip]
+	if strike == nil || now.Sub(strike.since) > window {
+		if len(s.strikes) >= maxRateLimitStrikes {
+			for address, old := range s.strikes {
+				if now.Sub(old.since) > window {
+					delete(s.strikes, address)
+				}
+			}
+		}
+		if len(s.strikes) >= maxRateLimitStrikes {
+			s.banMu.Unlock()
+			return
+		}
+		strike = &rateLimitStrikes{since: now}
+		s.strikes[ip] = strike
+	}
+	strike.count++
+	reached := strike.count >= threshold
+	if reached {
+		delete(s.strikes, ip)
+	}
+	s.banMu.Unlock()
+	if !reached {
+		return
+	}
+
+	expiresAt := now.Add(duration)
+	rule := &models.BlockRule{
+		Kind:      models.BlockRuleIP,
+		Value:     ip,
+		Action:    models.BlockRuleActionBlock,
+		Source:    models.BlockRuleSourceRateLimit,
+		Note:      fmt.Sprintf("%d rate limited requests within %s", threshold, window),
+		ExpiresAt: &expiresAt,
+	}
+	if err := s.repo.Create(rule); err != nil {
+		logger.Error(err, "Failed to ban rate limited address", map[string]interface{}{"ip": ip})
+		return
+	}
+	logger.Info("Banned rate limited address", map[string]interface{}{"ip": ip, "until": expiresAt})
+	s.invalidate()
+}
+
+// MaintainRules stores the matches counted since the last run and removes the
+// temporary bans that ended.
+func (s *BlockRuleService) MaintainRules(ctx context.Context) error {
+	if s == nil || s.repo == nil {
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.hitsMu.Lock()
+	hits := s.hits
+	s.hits = make(map[uint]int64)
+	s.hitsMu.Unlock()
+	if len(hits) > 0 {
+		if err := s.repo.AddHits(hits, s.clock()); err != nil {
+			s.hitsMu.Lock()
+			for id, count := range hits {
+				s.hits[id] += count
+			}
+			s.hitsMu.Unlock()
+			return err
+		}
+	}
+
+	removed, err := s.repo.DeleteExpired(s.clock())
+	if err != nil {
+		return err
+	}
+	if removed > 0 {
+		s.invalidate()
+	}
+	return nil
+}
+
+func (s *BlockRuleService) hit(id uint) {
+	s.hitsMu.Lock()
+	s.hits[id]++
+	s.hitsMu.Unlock()
+}
+
+func (s *BlockRuleService) clock() time.Time {
+	if s == nil || s.now == nil {
+		return time.Now()
+	}
+	return s.now()
+}
+
+func (s *BlockRuleService) invalidate() {
+	s.mu.Lock()
+	s.loadedAt = time.Time{}
+	s.mu.Unlock()
+}
+
+func (s *BlockRuleService) activeRules() ([]compiledBlockRule, bool) {
+	if s == nil || s.repo == nil {
+		return nil, false
+	}
+
+	s.mu.RLock()
+	rules, hasCountries, loadedAt := s.rules, s.hasCountries, s.loadedAt
+	s.mu.RUnlock()
+	if !loadedAt.IsZero() && time.Since(loadedAt) < blockRuleRefresh {
+		return rules, hasCountries
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.loadedAt.IsZero() && time.Since(s.loadedAt) < blockRuleRefresh {
+		return s.rules, s.hasCountries
+	}
+
+	stored, err := s.repo.Active(s.clock())
+	s.loadedAt = time.Now()
+	if err != nil {
+		logger.Error(err, "Failed to load block rules", nil)
+		return s.rules, s.hasCountries
+	}
+
+	compiled := make([]compiledBlockRule, 0, len(stored))
+	hasCountries = false
+	for _, rule := range stored {
+		entry, err := compileBlockRule(rule)
+		if err != nil {
+			logger.Warn("Skipping invalid block rule", map[string]interface{}{"id": rule.ID, "error": err.Error()})
+			continue
+		}
+		if entry.country != "" {
+			hasCountries = true
+		}
+		compiled = append(compiled, entry)
+	}
+	s.rules, s.hasCountries = compiled, hasCountries
+	return s.rules, s.hasCountries
+}
+
+func compileBlockRule(rule models.BlockRule) (compiledBlockRule, error) {
+	entry := compiledBlockRule{
+		id:        rule.ID,
+		allow:     rule.Action == models.BlockRuleActionAllow,
+		expiresAt: rule.ExpiresAt,
+	}
+	switch rule.Kind {
+	case models.BlockRuleIP:
+		ip := net.ParseIP(rule.Value)
+		if ip == nil {
+			return entry, fmt.Errorf("invalid IP address %q", rule.Value)
+		}
+		bits := 8 * net.IPv6len
+		if ip.To4() != nil {
+			ip, bits = ip.To4(), 8*net.IPv4len
+		}
+		entry.network = &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}
+	case models.BlockRuleCIDR:
+		_, network, err := net.ParseCIDR(rule.Value)
+		if err != nil {
+			return entry, err
+		}
+		entry.network = network
+	case models.BlockRuleUserAgent:
+		pattern, err := regexp.Compile("(?i)" + rule.Value)
+		if err != nil {
+			return entry, err
+		}
+		entry.agent = pattern
+	case models.BlockRuleCountry:
+		entry.country = rule.Value
+	default:
+		return entry, fmt.Errorf("unknown kind %q", rule.Kind)
+	}
+	return entry, nil
+}
+
+func normalizeBlockRuleValue(kind, value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || len(value) > maxBlockRuleValue {
+		return "", fmt.Errorf("value must be 1-%d characters", maxBlockRuleValue)
+	}
+
+	switch kind {
+	case models.BlockRuleIP:
+		ip := net.ParseIP(value)
+		if ip == nil {
+			return "", fmt.Errorf("%q is not an IP address", value)
+		}
+		return ip.String(), nil
+	case models.BlockRuleCIDR:
+		_, network, err := net.ParseCIDR(value)
+		if err != nil {
+			return "", fmt.Errorf("%q is not a CIDR range", value)
+		}
+		if ones, _ := network.Mask.Size(); ones == 0 {
+			return "", fmt.Errorf("%q covers every address", value)
+		}
+		return network.String(), nil
+	case models.BlockRuleUserAgent:
+		if _, err := regexp.Compile("(?i)" + value); err != nil {
+			return "", fmt.Errorf("invalid user agent pattern: %v", err)
+		}
+		return value, nil
+	case models.BlockRuleCountry:
+		value = strings.ToUpper(value)
+		if !blockRuleCountryPattern.MatchString(value) {
+			return "", fmt.Errorf("%q is not a two-letter country code", value)
+		}
+		return value, nil
+	default:
+		return "", fmt.Errorf("kind must be one of %s, %s, %s or %s",
+			models.BlockRuleIP, models.BlockRuleCIDR, models.BlockRuleUserAgent, models.BlockRuleCountry)
+	}
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"constructor-script-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type memoryBlockRuleRepository struct {
+	rules []models.BlockRule
+	hits  map[uint]int64
+}
+
+func (r *memoryBlockRuleRepository) List() ([]models.BlockRule, error) {
+	return append([]models.BlockRule(nil), r.rules...), nil
+}
+
+func (r *memoryBlockRuleRepository) Active(now time.Time) ([]models.BlockRule, error) {
+	var active []models.BlockRule
+	for _, rule := range r.rules {
+		if rule.ExpiresAt == nil || rule.ExpiresAt.After(now) {
+			active = append(active, rule)
+		}
+	}
+	return active, nil
+}
+
+func (r *memoryBlockRuleRepository) Create(rule *models.BlockRule) error {
+	rule.ID = uint(len(r.rules) + 1)
+	r.rules = append(r.rules, *rule)
+	return nil
+}
+
+func (r *memoryBlockRuleRepository) Delete(id uint) error {
+	for i, rule := range r.rules {
+		if rule.ID == id {
+			r.rules = append(r.rules[:i], r.rules[i+1:]...)
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
+
+func (r *memoryBlockRuleRepository) AddHits(hits map[uint]int64, _ time.Time) error {
+	if r.hits == nil {
+		r.hits = make(map[uint]int64)
+	}
+	for id, count := range hits {
+		r.hits[id] += count
+	}
+	return nil
+}
+
+func (r *memoryBlockRuleRepository) DeleteExpired(now time.Time) (int64, error) {
+	kept := r.rules[:0]
+	var removed int64
+	for _, rule := range r.rules {
+		if rule.ExpiresAt != nil && !rule.ExpiresAt.After(now) {
+			removed++
+			continue
+		}
+		kept = append(kept, rule)
+	}
+	r.rules = kept
+	return removed, nil
+}
+
+func TestBlockRules(t *testing.T) {
+	repo := &memoryBlockRuleRepository{}
+	svc := NewBlockRuleService(repo)
+
+	invalid := []models.CreateBlockRuleRequest{
+		{Kind: "ip", Value: "10.0.0"},
+		{Kind: "cidr", Value: "0.0.0.0/0"},
+		{Kind: "user_agent", Value: "bot("},
+		{Kind: "country", Value: "Germany"},
+		{Kind: "asn", Value: "13335"},
+		{Kind: "ip", Value: "10.0.0.1", Action: "drop"},
+	}
+	for _, req := range invalid {
+		_, err := svc.Create(req)
+		var validationErr *BlockRuleValidationError
+		if !errors.As(err, &validationErr) {
+			t.Fatalf("expected %+v to be rejected, got %v", req, err)
+		}
+	}
+
+	for _, req := range []models.CreateBlockRuleRequest{
+		{Kind: "cidr", Value: "203.0.113.7/24"},
+		{Kind: "ip", Value: "203.0.113.10", Action: "allow"},
+		{Kind: "user_agent", Value: "badbot|scrapy"},
+		{Kind: "country", Value: "xx"},
+	} {
+		if _, err := svc.Create(req); err != nil {
+			t.Fatalf("create %+v: %v", req, err)
+		}
+	}
+	if repo.rules[0].Value != "203.0.113.0/24" || repo.rules[3].Value != "XX" {
+		t.Fatalf("expected normalized values, got %+v", repo.rules)
+	}
+
+	cases := []struct {
+		ip, agent, country string
+		blocked            bool
+	}{
+		{"203.0.113.5", "Mozilla/5.0", "", true},
+		{"203.0.113.10", "Scrapy/2.0", "XX", false},
+		{"198.51.100.1", "Mozilla/5.0 (compatible; BadBot/1.0)", "", true},
+		{"198.51.100.1", "Mozilla/5.0", "XX", true},
+		{"198.51.100.1", "Mozilla/5.0", "DE", false},
+	}
+	for _, tc := range cases {
+		if got := svc.Blocked(tc.ip, tc.agent, tc.country); got != tc.blocked {
+			t.Fatalf("Blocked(%q, %q, %q) = %v", tc.ip, tc.agent, tc.country, got)
+		}
+	}
+
+	if err := svc.MaintainRules(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if repo.hits[1] != 1 || repo.hits[2] != 1 || repo.hits[3] != 1 || repo.hits[4] != 1 {
+		t.Fatalf("unexpected hit counts %v", repo.hits)
+	}
+}
+
+func TestRateLimitViolationsBanAddress(t *testing.T) {
+	repo := &memoryBlockRuleRepository{}
+	svc := NewBlockRuleService(repo)
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	svc.SetAutoBan(3, 10*time.Minute, time.Hour)
+
+	svc.RecordRateLimitViolation("192.0.2.1")
+	svc.RecordRateLimitViolation("192.0.2.1")
+	now = now.Add(11 * time.Minute)
+	svc.RecordRateLimitViolation("192.0.2.1")
+	svc.RecordRateLimitViolation("192.0.2.1")
+	if len(repo.rules) != 0 {
+		t.Fatalf("expected strikes outside the window to be forgotten, got %+v", repo.rules)
+	}
+
+	svc.RecordRateLimitViolation("192.0.2.1")
+	if len(repo.rules) != 1 || repo.rules[0].Source != models.BlockRuleSourceRateLimit || repo.rules[0].ExpiresAt == nil {
+		t.Fatalf("expected a temporary ban, got %+v", repo.rules)
+	}
+	if !svc.Blocked("192.0.2.1", "", "") {
+		t.Fatal("expected the banned address to be blocked")
+	}
+
+	now = now.Add(time.Hour)
+	if svc.Blocked("192.0.2.1", "", "") {
+		t.Fatal("expected the ban to end")
+	}
+	if err := svc.MaintainRules(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(repo.rules) != 0 {
+		t.Fatalf("expected the expired ban to be removed, got %+v", repo.rules)
+	}
+}
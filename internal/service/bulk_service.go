@@ -0,0 +1,389 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"constructor-script-backend/internal/events"
+	"constructor-script-backend/internal/models"
+	blogservice "constructor-script-backend/plugins/blog/service"
+
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+)
+
+// Statuses of the operations of a bulk request.
+const (
+	BulkSucceeded  = "succeeded"
+	BulkFailed     = "failed"
+	BulkRolledBack = "rolled_back"
+	BulkSkipped    = "skipped"
+)
+
+// ErrBulkFailed reports that an operation failed and the batch was rolled back.
+var ErrBulkFailed = errors.New("bulk operation failed")
+
+// BulkActor is the user a bulk request runs as.
+type BulkActor struct {
+	UserID       uint
+	CanManageAll bool
+}
+
+// BulkResource is a kind of record the bulk API changes.
+type BulkResource interface {
+	// Begin returns the operations of the resource bound to tx, announcing
+	// their changes on bus.
+	Begin(tx *gorm.DB, bus *events.Bus, actor BulkActor) BulkWriter
+	// Committed drops cached copies of the records with ids once the
+	// transaction that changed them has committed.
+	Committed(ids []uint)
+}
+
+// BulkWriter applies operations to one kind of record.
+type BulkWriter interface {
+	Create(data json.RawMessage) (uint, interface{}, error)
+	Update(id uint, data json.RawMessage) (interface{}, error)
+	Delete(id uint) error
+}
+
+type BulkResult struct {
+	Index    int         `json:"index"`
+	Op       string      `json:"op"`
+	Resource string      `json:"resource"`
+	ID       uint        `json:"id,omitempty"`
+	Status   string      `json:"status"`
+	Error    string      `json:"error,omitempty"`
+	Record   interface{} `json:"record,omitempty"`
+}
+
+type BulkReport struct {
+	Committed bool         `json:"committed"`
+	DryRun    bool         `json:"dry_run,omitempty"`
+	Results   []BulkResult `json:"results"`
+}
+
+// BulkService runs batches of creates, updates and deletes in one transaction:
+// either every operation applies or none does. Events and cache invalidation
+// wait for the commit.
+type BulkService struct {
+	db     *gorm.DB
+	events *events.Bus
+
+	mu        sync.RWMutex
+	resources map[string]func() BulkResource
+}
+
+func NewBulkService(db *gorm.DB, bus *events.Bus) *BulkService {
+	return &BulkService{db: db, events: bus, resources: make(map[string]func() BulkResource)}
+}
+
+// Register makes the resource returned by provider available under name. The
+// provider returns nil while the resource is unavailable, such as posts while
+// the blog plugin is off.
+func (s *BulkService) Register(name string, provider func() BulkResource) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.resources[name] = provider
+}
+
+// Resources lists the names of the resources available now.
+func (s *BulkService) Resources() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var names []string
+	for name, provider := range s.resources {
+		if provider() != nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s *BulkService) resource(name string) BulkResource {
+	s.mu.RLock()
+	provider := s.resources[name]
+	s.mu.RUnlock()
+	if provider == nil {
+		return nil
+	}
+	return provider()
+}
+
+// Execute runs req as actor. It stops at the first failing operation and rolls
+// the batch back; the report then marks that operation failed, the ones before
+// it rolled back and the ones after it skipped, and the error is ErrBulkFailed.
+func (s *BulkService) Execute(ctx context.Context, req models.BulkRequest, actor BulkActor) (BulkReport, error) {
+	report := BulkReport{DryRun: req.DryRun, Results: make([]BulkResult, len(req.Operations))}
+	resources := make(map[string]BulkResource)
+	for i, op := range req.Operations {
+		report.Results[i] = BulkResult{Index: i, Op: op.Op, Resource: op.Resource, ID: op.ID, Status: BulkSkipped}
+		if _, ok := resources[op.Resource]; !ok {
+			resources[op.Resource] = s.resource(op.Resource)
+		}
+	}
+
+	deferred := s.events.Deferred()
+	changed := make(map[string][]uint)
+	rollback := errors.New("dry run")
+	failed := -1
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		writers := make(map[string]BulkWriter)
+		for i, op := range req.Operations {
+			result := &report.Results[i]
+
+			resource := resources[op.Resource]
+			if resource == nil {
+				result.Status, result.Error, failed = BulkFailed, fmt.Sprintf("unknown resource %q; available: %s", op.Resource, strings.Join(s.Resources(), ", ")), i
+				return ErrBulkFailed
+			}
+			writer := writers[op.Resource]
+			if writer == nil {
+				writer = resource.Begin(tx, deferred, actor)
+				writers[op.Resource] = writer
+			}
+
+			if err := applyBulkOperation(writer, op, result); err != nil {
+				result.Status, result.Error, failed = BulkFailed, err.Error(), i
+				return ErrBulkFailed
+			}
+			result.Status = BulkSucceeded
+			changed[op.Resource] = append(changed[op.Resource], result.ID)
+		}
+		if req.DryRun {
+			return rollback
+		}
+		return nil
+	})
+
+	switch {
+	case err == nil:
+		report.Committed = true
+		deferred.Flush()
+		for name, ids := range changed {
+			resources[name].Committed(ids)
+		}
+		return report, nil
+	case errors.Is(err, rollback):
+		return report, nil
+	case failed >= 0:
+		for i := 0; i < failed; i++ {
+			report.Results[i].Status = BulkRolledBack
+		}
+		return report, ErrBulkFailed
+	default:
+		return report, err
+	}
+}
+
+func applyBulkOperation(writer BulkWriter, op models.BulkOperation, result *BulkResult) error {
+	switch op.Op {
+	case models.BulkCreate:
+		if len(op.Data) == 0 {
+			return errors.New("data is required")
+		}
+		id, record, err := writer.Create(op.Data)
+		if err != nil {
+			return err
+		}
+		result.ID, result.Record = id, record
+	case models.BulkUpdate:
+		if op.ID == 0 || len(op.Data) == 0 {
+			return errors.New("id and data are required")
+		}
+		record, err := writer.Update(op.ID, op.Data)
+		if err != nil {
+			return err
+		}
+		result.Record = record
+	case models.BulkDelete:
+		if op.ID == 0 {
+			return errors.New("id is required")
+		}
+		if err := writer.Delete(op.ID); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown op %q", op.Op)
+	}
+	return nil
+}
+
+// decodeBulkData decodes data into req and checks its binding rules, as the
+// single-record endpoints do.
+func decodeBulkData(data json.RawMessage, req interface{}) error {
+	if err := json.Unmarshal(data, req); err != nil {
+		return fmt.Errorf("invalid data: %w", err)
+	}
+	if binding.Validator == nil {
+		return nil
+	}
+	if err := binding.Validator.ValidateStruct(req); err != nil {
+		return fmt.Errorf("invalid data: %w", err)
+	}
+	return nil
+}
+
+// bulkRecordError names the record an operation did not find.
+func bulkRecordError(err error, resource string, id uint) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("%s %d not found", resource, id)
+	}
+	return err
+}
+
+// BulkResource exposes pages to the bulk API.
+func (s *PageService) BulkResource() BulkResource {
+	if s == nil {
+		return nil
+	}
+	return pageBulkResource{pages: s}
+}
+
+type pageBulkResource struct {
+	pages *PageService
+}
+
+func (r pageBulkResource) Begin(tx *gorm.DB, bus *events.Bus, _ BulkActor) BulkWriter {
+	return pageBulkWriter{pages: r.pages.withTx(tx, bus)}
+}
+
+func (r pageBulkResource) Committed(ids []uint) {
+	r.pages.invalidatePages(ids)
+}
+
+type pageBulkWriter struct {
+	pages *PageService
+}
+
+func (w pageBulkWriter) Create(data json.RawMessage) (uint, interface{}, error) {
+	var req models.CreatePageRequest
+	if err := decodeBulkData(data, &req); err != nil {
+		return 0, nil, err
+	}
+	page, err := w.pages.Create(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	return page.ID, page, nil
+}
+
+func (w pageBulkWriter) Update(id uint, data json.RawMessage) (interface{}, error) {
+	var req models.UpdatePageRequest
+	if err := decodeBulkData(data, &req); err != nil {
+		return nil, err
+	}
+	page, err := w.pages.Update(id, req)
+	return page, bulkRecordError(err, "page", id)
+}
+
+func (w pageBulkWriter) Delete(id uint) error {
+	return bulkRecordError(w.pages.Delete(id), "page", id)
+}
+
+// BulkResource exposes menu items to the bulk API.
+func (s *MenuService) BulkResource() BulkResource {
+	if s == nil {
+		return nil
+	}
+	return menuBulkResource{menu: s}
+}
+
+type menuBulkResource struct {
+	menu *MenuService
+}
+
+func (r menuBulkResource) Begin(tx *gorm.DB, _ *events.Bus, _ BulkActor) BulkWriter {
+	return menuBulkWriter{menu: r.menu.withTx(tx)}
+}
+
+// Committed does nothing: menu items are not cached.
+func (menuBulkResource) Committed([]uint) {}
+
+type menuBulkWriter struct {
+	menu *MenuService
+}
+
+func (w menuBulkWriter) Create(data json.RawMessage) (uint, interface{}, error) {
+	var req models.CreateMenuItemRequest
+	if err := decodeBulkData(data, &req); err != nil {
+		return 0, nil, err
+	}
+	item, err := w.menu.Create(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	return item.ID, item, nil
+}
+
+func (w menuBulkWriter) Update(id uint, data json.RawMessage) (interface{}, error) {
+	var req models.UpdateMenuItemRequest
+	if err := decodeBulkData(data, &req); err != nil {
+		return nil, err
+	}
+	item, err := w.menu.Update(id, req)
+	return item, bulkRecordError(err, "menu item", id)
+}
+
+func (w menuBulkWriter) Delete(id uint) error {
+	if _, err := w.menu.repo.GetByID(id); err != nil {
+		return bulkRecordError(err, "menu item", id)
+	}
+	return w.menu.Delete(id)
+}
+
+// NewPostBulkResource exposes the posts of the blog plugin to the bulk API.
+func NewPostBulkResource(posts *blogservice.PostService) BulkResource {
+	if posts == nil {
+		return nil
+	}
+	return postBulkResource{posts: posts}
+}
+
+type postBulkResource struct {
+	posts *blogservice.PostService
+}
+
+func (r postBulkResource) Begin(tx *gorm.DB, bus *events.Bus, actor BulkActor) BulkWriter {
+	return postBulkWriter{posts: r.posts.WithTx(tx, bus), actor: actor}
+}
+
+func (r postBulkResource) Committed(ids []uint) {
+	r.posts.InvalidatePosts(ids)
+}
+
+type postBulkWriter struct {
+	posts *blogservice.PostService
+	actor BulkActor
+}
+
+func (w postBulkWriter) Create(data json.RawMessage) (uint, interface{}, error) {
+	var req models.CreatePostRequest
+	if err := decodeBulkData(data, &req); err != nil {
+		return 0, nil, err
+	}
+	post, err := w.posts.Create(req, w.actor.UserID)
+	if err != nil {
+		return 0, nil, err
+	}
+	return post.ID, post, nil
+}
+
+func (w postBulkWriter) Update(id uint, data json.RawMessage) (interface{}, error) {
+	var req models.UpdatePostRequest
+	if err := decodeBulkData(data, &req); err != nil {
+		return nil, err
+	}
+	post, err := w.posts.Update(id, req, w.actor.UserID, w.actor.CanManageAll)
+	return post, bulkRecordError(err, "post", id)
+}
+
+func (w postBulkWriter) Delete(id uint) error {
+	return bulkRecordError(w.posts.Delete(id, w.actor.UserID, w.actor.CanManageAll), "post", id)
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"constructor-script-backend/internal/models"
+)
+
+type recordingBulkWriter struct {
+	calls []string
+	fail  error
+}
+
+func (w *recordingBulkWriter) Create(data json.RawMessage) (uint, interface{}, error) {
+	w.calls = append(w.calls, "create "+string(data))
+	return 7, map[string]string{"title": "created"}, w.fail
+}
+
+func (w *recordingBulkWriter) Update(id uint, data json.RawMessage) (interface{}, error) {
+	w.calls = append(w.calls, "update "+string(data))
+	return nil, w.fail
+}
+
+func (w *recordingBulkWriter) Delete(id uint) error {
+	w.calls = append(w.calls, "delete")
+	return w.fail
+}
+
+func TestApplyBulkOperation(t *testing.T) {
+	writer := &recordingBulkWriter{}
+
+	var result BulkResult
+	if err := applyBulkOperation(writer, models.BulkOperation{Op: models.BulkCreate, Data: json.RawMessage(`{}`)}, &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.ID != 7 || result.Record == nil {
+		t.Fatalf("expected the created record in the result, got %+v", result)
+	}
+
+	invalid := []models.BulkOperation{
+		{Op: models.BulkCreate},
+		{Op: models.BulkUpdate, Data: json.RawMessage(`{}`)},
+		{Op: models.BulkDelete},
+		{Op: "upsert", ID: 1},
+	}
+	for _, op := range invalid {
+		if err := applyBulkOperation(writer, op, &BulkResult{}); err == nil {
+			t.Errorf("%+v: expected an error", op)
+		}
+	}
+	if len(writer.calls) != 1 {
+		t.Fatalf("expected invalid operations not to reach the writer, got %v", writer.calls)
+	}
+
+	writer.fail = errors.New("slug taken")
+	if err := applyBulkOperation(writer, models.BulkOperation{Op: models.BulkDelete, ID: 3}, &BulkResult{}); err == nil || err.Error() != "slug taken" {
+		t.Fatalf("expected the writer's error, got %v", err)
+	}
+}
+
+func TestDecodeBulkDataChecksBindingRules(t *testing.T) {
+	var req models.CreatePageRequest
+	if err := decodeBulkData(json.RawMessage(`{"title": "About"}`), &req); err != nil || req.Title != "About" {
+		t.Fatalf("decodeBulkData = %v, %+v", err, req)
+	}
+	if err := decodeBulkData(json.RawMessage(`{"description": "no title"}`), &models.CreatePageRequest{}); err == nil {
+		t.Fatal("expected a missing title to be rejected")
+	}
+	if err := decodeBulkData(json.RawMessage(`{"title": 1}`), &models.CreatePageRequest{}); err == nil || !strings.HasPrefix(err.Error(), "invalid data") {
+		t.Fatalf("expected a type error, got %v", err)
+	}
+}
+
+func TestBulkServiceResources(t *testing.T) {
+	svc := NewBulkService(nil, nil)
+	svc.Register("pages", func() BulkResource { return (&PageService{}).BulkResource() })
+	svc.Register("posts", func() BulkResource { return NewPostBulkResource(nil) })
+
+	if resources := svc.Resources(); len(resources) != 1 || resources[0] != "pages" {
+		t.Fatalf("expected only the available resources, got %v", resources)
+	}
+}
@@ -0,0 +1,340 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"constructor-script-backend/internal/background"
+)
+
+const (
+	CDNPurgeProviderBunny      = "bunny"
+	CDNPurgeProviderCloudFront = "cloudfront"
+
+	cdnPurgeJobPrefix = "cdn_purge_"
+	cdnPurgeTimeout   = time.Minute
+
+	defaultBunnyAPIURL      = "https://api.bunny.net"
+	defaultCloudFrontAPIURL = "https://cloudfront.amazonaws.com"
+)
+
+// cdnImagePath matches the upload paths served publicly from /uploads, which are the
+// only ones worth sending through the CDN; videos and documents stay behind the
+// protected asset handlers.
+const cdnImagePath = `/uploads/[^\s"'<>()\\?#,]+\.(?i:jpe?g|png|gif|webp|avif|svg|ico)`
+
+var (
+	// src="/uploads/a.png", also in JSON-encoded HTML where the quote is escaped.
+	cdnAttributePattern = regexp.MustCompile(`(?i)(\s(?:src|href|poster|content|data-src)\s*=\s*\\?["']?)(` + cdnImagePath + `)`)
+	cdnSrcsetPattern    = regexp.MustCompile(`(?i)\s(?:image)?srcset\s*=\s*(?:\\?"[^"\\]*\\?"|'[^']*')`)
+	cdnCSSURLPattern    = regexp.MustCompile(`(?i)(url\(\s*["']?)(` + cdnImagePath + `)`)
+	cdnJSONValuePattern = regexp.MustCompile(`"(` + cdnImagePath + `)"`)
+	cdnSrcsetURLPattern = regexp.MustCompile(`(^|[\s,"'=])(` + cdnImagePath + `)`)
+)
+
+// CDNConfig points public media at a CDN such as CloudFront or Bunny that pulls from
+// this site.
+type CDNConfig struct {
+	// BaseURL replaces the site origin in upload URLs, e.g. https://cdn.example.com.
+	BaseURL string
+	// PurgeProvider selects how stale copies are invalidated: "bunny", "cloudfront",
+	// or empty to let them expire.
+	PurgeProvider string
+
+	BunnyAPIKey string
+
+	CloudFrontDistributionID string
+	CloudFrontAccessKey      string
+	CloudFrontSecretKey      string
+}
+
+// CDNPurger invalidates cached copies of site paths such as /uploads/photo.jpg. A
+// trailing * purges everything under a prefix.
+type CDNPurger interface {
+	Purge(ctx context.Context, paths []string) error
+}
+
+// CDN rewrites upload URLs to the CDN host and purges files that change behind it.
+type CDN struct {
+	base   *url.URL
+	purger CDNPurger
+}
+
+func NewCDN(config CDNConfig) (*CDN, error) {
+	raw := strings.TrimRight(strings.TrimSpace(config.BaseURL), "/")
+	if raw == "" {
+		return nil, fmt.Errorf("cdn base url is required")
+	}
+	base, err := url.Parse(raw)
+	if err != nil || base.Host == "" || (base.Scheme != "https" && base.Scheme != "http") {
+		return nil, fmt.Errorf("cdn base url must be an absolute http(s) url")
+	}
+	base.RawQuery = ""
+	base.Fragment = ""
+
+	cdn := &CDN{base: base}
+	client := &http.Client{Timeout: 30 * time.Second}
+	switch strings.ToLower(strings.TrimSpace(config.PurgeProvider)) {
+	case "":
+	case CDNPurgeProviderBunny:
+		if strings.TrimSpace(config.BunnyAPIKey) == "" {
+			return nil, fmt.Errorf("bunny purging requires an api key")
+		}
+		cdn.purger = &bunnyPurger{apiURL: defaultBunnyAPIURL, apiKey: strings.TrimSpace(config.BunnyAPIKey), base: base, httpClient: client}
+	case CDNPurgeProviderCloudFront:
+		if config.CloudFrontDistributionID == "" || config.CloudFrontAccessKey == "" || config.CloudFrontSecretKey == "" {
+			return nil, fmt.Errorf("cloudfront purging requires a distribution id and credentials")
+		}
+		cdn.purger = &cloudFrontPurger{
+			apiURL:         defaultCloudFrontAPIURL,
+			distributionID: strings.TrimSpace(config.CloudFrontDistributionID),
+			accessKey:      strings.TrimSpace(config.CloudFrontAccessKey),
+			secretKey:      strings.TrimSpace(config.CloudFrontSecretKey),
+			basePath:       strings.TrimRight(base.Path, "/"),
+			httpClient:     client,
+		}
+	default:
+		return nil, fmt.Errorf("unknown cdn purge provider %q", config.PurgeProvider)
+	}
+	return cdn, nil
+}
+
+// BaseURL returns the CDN origin uploads are served from.
+func (c *CDN) BaseURL() string {
+	if c == nil {
+		return ""
+	}
+	return c.base.String()
+}
+
+// URL returns the CDN address of a public upload path and leaves anything else as is.
+func (c *CDN) URL(path string) string {
+	if c == nil || !strings.HasPrefix(path, "/uploads/") {
+		return path
+	}
+	return c.base.String() + path
+}
+
+// RewriteHTML points image uploads referenced from src, href, poster, content and
+// srcset attributes and CSS url() values at the CDN. Form values and text are left
+// alone so editors never save CDN addresses back into content.
+func (c *CDN) RewriteHTML(body []byte) []byte {
+	if c == nil || !bytes.Contains(body, []byte("/uploads/")) {
+		return body
+	}
+	body = c.rewriteMarkup(body)
+	return cdnCSSURLPattern.ReplaceAllFunc(body, c.replaceSuffix(cdnCSSURLPattern))
+}
+
+// RewriteJSON points image uploads at the CDN in JSON string values that are upload
+// paths, and inside HTML fragments such as rendered post content.
+func (c *CDN) RewriteJSON(body []byte) []byte {
+	if c == nil || !bytes.Contains(body, []byte("/uploads/")) {
+		return body
+	}
+	body = c.rewriteMarkup(body)
+	prefix := []byte(`"` + c.base.String())
+	return cdnJSONValuePattern.ReplaceAllFunc(body, func(match []byte) []byte {
+		return append(append([]byte{}, prefix...), match[1:]...)
+	})
+}
+
+func (c *CDN) rewriteMarkup(body []byte) []byte {
+	body = cdnAttributePattern.ReplaceAllFunc(body, c.replaceSuffix(cdnAttributePattern))
+	return cdnSrcsetPattern.ReplaceAllFunc(body, func(attribute []byte) []byte {
+		return cdnSrcsetURLPattern.ReplaceAllFunc(attribute, c.replaceSuffix(cdnSrcsetURLPattern))
+	})
+}
+
+// replaceSuffix returns a replacement for a pattern whose first group is kept and whose
+// second group is the upload path.
+func (c *CDN) replaceSuffix(pattern *regexp.Regexp) func([]byte) []byte {
+	base := []byte(c.base.String())
+	return func(match []byte) []byte {
+		groups := pattern.FindSubmatchIndex(match)
+		if len(groups) < 6 || groups[4] < 0 {
+			return match
+		}
+		out := make([]byte, 0, len(match)+len(base))
+		out = append(out, match[:groups[4]]...)
+		out = append(out, base...)
+		return append(out, match[groups[4]:]...)
+	}
+}
+
+// Purge invalidates paths on the CDN. It does nothing when no purge provider is set.
+func (c *CDN) Purge(ctx context.Context, paths []string) error {
+	if c == nil || c.purger == nil || len(paths) == 0 {
+		return nil
+	}
+	return c.purger.Purge(ctx, paths)
+}
+
+// SetCDN serves public uploads from cdn and purges them from it when they change.
+func (s *UploadService) SetCDN(cdn *CDN) {
+	if s == nil {
+		return
+	}
+	s.cdn = cdn
+}
+
+// CDN returns the configured CDN, or nil when uploads are served directly.
+func (s *UploadService) CDN() *CDN {
+	if s == nil {
+		return nil
+	}
+	return s.cdn
+}
+
+// purgeCDN invalidates an upload and its generated variants in the background after
+// it has been deleted, renamed or regenerated, so the CDN never serves a stale copy
+// when the name is reused.
+func (s *UploadService) purgeCDN(filename string, variantsOnly bool) {
+	if s == nil || s.cdn == nil || s.cdn.purger == nil {
+		return
+	}
+	name := cdnPurgeJobPrefix + filename
+	paths := []string{s.variantURL(filename, "*")}
+	if variantsOnly {
+		name += "_variants"
+	} else {
+		paths = append([]string{s.uploadURL(filename)}, paths...)
+	}
+	s.runBackgroundJob(background.Job{
+		Name:    name,
+		Timeout: cdnPurgeTimeout,
+		Run: func(ctx context.Context) error {
+			return s.cdn.Purge(ctx, paths)
+		},
+	}, "CDN purge", filename)
+}
+
+type bunnyPurger struct {
+	apiURL     string
+	apiKey     string
+	base       *url.URL
+	httpClient *http.Client
+}
+
+// Purge calls Bunny's purge API once per URL; it accepts a trailing * wildcard.
+func (p *bunnyPurger) Purge(ctx context.Context, paths []string) error {
+	var errs []error
+	for _, path := range paths {
+		query := url.Values{"url": {p.base.String() + path}, "async": {"true"}}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.apiURL+"/purge?"+query.Encode(), nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("AccessKey", p.apiKey)
+		if err := doCDNRequest(p.httpClient, req); err != nil {
+			errs = append(errs, fmt.Errorf("purge %s: %w", path, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+type cloudFrontPurger struct {
+	apiURL         string
+	distributionID string
+	accessKey      string
+	secretKey      string
+	basePath       string
+	httpClient     *http.Client
+	now            func() time.Time
+}
+
+type cloudFrontInvalidationBatch struct {
+	XMLName         xml.Name `xml:"http://cloudfront.amazonaws.com/doc/2020-05-31/ InvalidationBatch"`
+	CallerReference string   `xml:"CallerReference"`
+	Quantity        int      `xml:"Paths>Quantity"`
+	Items           []string `xml:"Paths>Items>Path"`
+}
+
+// Purge creates one CloudFront invalidation covering every path.
+func (p *cloudFrontPurger) Purge(ctx context.Context, paths []string) error {
+	now := time.Now().UTC()
+	if p.now != nil {
+		now = p.now().UTC()
+	}
+	batch := cloudFrontInvalidationBatch{
+		CallerReference: strconv.FormatInt(now.UnixNano(), 10),
+		Quantity:        len(paths),
+	}
+	for _, path := range paths {
+		batch.Items = append(batch.Items, p.basePath+path)
+	}
+	body, err := xml.Marshal(batch)
+	if err != nil {
+		return err
+	}
+	body = append([]byte(xml.Header), body...)
+
+	endpoint := p.apiURL + "/2020-05-31/distribution/" + url.PathEscape(p.distributionID) + "/invalidation"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/xml")
+	signAWSRequest(req, body, "cloudfront", "us-east-1", p.accessKey, p.secretKey, now)
+	return doCDNRequest(p.httpClient, req)
+}
+
+// signAWSRequest adds a SigV4 Authorization header covering the content type, host
+// and date headers and the payload.
+func signAWSRequest(req *http.Request, body []byte, service, region, accessKey, secretKey string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	dateStamp := now.Format("20060102")
+	payloadHash := sha256.Sum256(body)
+	payload := hex.EncodeToString(payloadHash[:])
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payload)
+
+	canonicalHeaders := fmt.Sprintf("content-type:%s\nhost:%s\nx-amz-content-sha256:%s\nx-amz-date:%s\n",
+		strings.TrimSpace(req.Header.Get("Content-Type")), strings.ToLower(req.URL.Host), payload, amzDate)
+	signedHeaders := "content-type;host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders,
+		signedHeaders,
+		payload,
+	}, "\n")
+
+	hashedCanonicalRequest := sha256.Sum256([]byte(canonicalRequest))
+	credentialScope := fmt.Sprintf("%s/%s/%s/aws4_request", dateStamp, region, service)
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		credentialScope,
+		hex.EncodeToString(hashedCanonicalRequest[:]),
+	}, "\n")
+	signature := hmacSHA256Hex(deriveSigningKey(secretKey, dateStamp, region, service), stringToSign)
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", accessKey, credentialScope, signedHeaders, signature))
+}
+
+func doCDNRequest(client *http.Client, req *http.Request) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("cdn api returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
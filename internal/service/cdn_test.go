@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCDNRewritesPublicUploads(t *testing.T) {
+	cdn, err := NewCDN(CDNConfig{BaseURL: "https://cdn.example.com/"})
+	if err != nil {
+		t.Fatalf("new cdn: %v", err)
+	}
+
+	html := `<img src="/uploads/photo.jpg" srcset="/uploads/variants/photo.jpg/320w.webp 320w, /uploads/variants/photo.jpg/640w.webp 640w">` +
+		`<video poster="/uploads/variants/clip.mp4/poster.jpg" src="/uploads/clip.mp4"></video>` +
+		`<div style="background-image: url('/uploads/hero.png')"></div>` +
+		`<input type="hidden" value="/uploads/avatar.png"><p>/uploads/notes.png</p>`
+	want := `<img src="https://cdn.example.com/uploads/photo.jpg" srcset="https://cdn.example.com/uploads/variants/photo.jpg/320w.webp 320w, https://cdn.example.com/uploads/variants/photo.jpg/640w.webp 640w">` +
+		`<video poster="https://cdn.example.com/uploads/variants/clip.mp4/poster.jpg" src="/uploads/clip.mp4"></video>` +
+		`<div style="background-image: url('https://cdn.example.com/uploads/hero.png')"></div>` +
+		`<input type="hidden" value="/uploads/avatar.png"><p>/uploads/notes.png</p>`
+	if got := string(cdn.RewriteHTML([]byte(html))); got != want {
+		t.Fatalf("unexpected html rewrite:\n got %s\nwant %s", got, want)
+	}
+
+	payload := `{"image":"/uploads/photo.jpg","video":"/uploads/clip.mp4","content":"<img src=\"/uploads/inline.png\">","path":"/uploads/photo.jpg/x"}`
+	wantJSON := `{"image":"https://cdn.example.com/uploads/photo.jpg","video":"/uploads/clip.mp4","content":"<img src=\"https://cdn.example.com/uploads/inline.png\">","path":"/uploads/photo.jpg/x"}`
+	if got := string(cdn.RewriteJSON([]byte(payload))); got != wantJSON {
+		t.Fatalf("unexpected json rewrite:\n got %s\nwant %s", got, wantJSON)
+	}
+}
+
+func TestCDNPurgeProviders(t *testing.T) {
+	var requests []*http.Request
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, r)
+		bodies = append(bodies, string(body))
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	bunny, err := NewCDN(CDNConfig{BaseURL: "https://cdn.example.com", PurgeProvider: "bunny", BunnyAPIKey: "key"})
+	if err != nil {
+		t.Fatalf("new bunny cdn: %v", err)
+	}
+	bunny.purger.(*bunnyPurger).apiURL = server.URL
+	if err := bunny.Purge(context.Background(), []string{"/uploads/a.jpg", "/uploads/variants/a.jpg/*"}); err != nil {
+		t.Fatalf("bunny purge: %v", err)
+	}
+	if len(requests) != 2 || requests[0].Header.Get("AccessKey") != "key" || requests[1].URL.Query().Get("url") != "https://cdn.example.com/uploads/variants/a.jpg/*" {
+		t.Fatalf("unexpected bunny requests: %+v", requests)
+	}
+
+	requests, bodies = nil, nil
+	cloudFront, err := NewCDN(CDNConfig{
+		BaseURL:                  "https://d111.cloudfront.net/site",
+		PurgeProvider:            "cloudfront",
+		CloudFrontDistributionID: "E123",
+		CloudFrontAccessKey:      "access",
+		CloudFrontSecretKey:      "secret",
+	})
+	if err != nil {
+		t.Fatalf("new cloudfront cdn: %v", err)
+	}
+	purger := cloudFront.purger.(*cloudFrontPurger)
+	purger.apiURL = server.URL
+	purger.now = func() time.Time { return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC) }
+	if err := cloudFront.Purge(context.Background(), []string{"/uploads/a.jpg"}); err != nil {
+		t.Fatalf("cloudfront purge: %v", err)
+	}
+	if len(requests) != 1 || requests[0].URL.Path != "/2020-05-31/distribution/E123/invalidation" {
+		t.Fatalf("unexpected cloudfront requests: %+v", requests)
+	}
+	if !strings.Contains(bodies[0], "<Path>/site/uploads/a.jpg</Path>") || !strings.Contains(bodies[0], "<Quantity>1</Quantity>") {
+		t.Fatalf("unexpected invalidation batch: %s", bodies[0])
+	}
+	if auth := requests[0].Header.Get("Authorization"); !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=access/20240102/us-east-1/cloudfront/aws4_request") {
+		t.Fatalf("unexpected authorization header: %s", auth)
+	}
+
+	if _, err := NewCDN(CDNConfig{BaseURL: "https://cdn.example.com", PurgeProvider: "fastly"}); err == nil {
+		t.Fatal("expected unknown purge provider to be rejected")
+	}
+}
@@ -0,0 +1,427 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/repository"
+	"constructor-script-backend/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// SettingKeyConsent stores the cookie consent settings in the settings
+	// repository.
+	SettingKeyConsent = "privacy.consent"
+
+	// ConsentCookieMaxAge is how long a visitor's choice is remembered.
+	ConsentCookieMaxAge = 365 * 24 * 60 * 60
+
+	consentSettingsRefresh = 30 * time.Second
+	consentRecordRetention = 3 * 365 * 24 * time.Hour
+	consentRecordListLimit = 50
+	maxConsentCategories   = 10
+	maxConsentMessage      = 2000
+	maxConsentDescription  = 500
+	maxConsentSnippets     = 10
+	maxConsentSnippetSize  = 20000
+	defaultConsentMessage  = "We use cookies to run this site and, with your permission, to measure visits and show ads."
+)
+
+var (
+	ErrConsentUnavailable = errors.New("consent service not configured")
+	ErrConsentDisabled    = errors.New("cookie consent is not enabled")
+
+	consentCategoryPattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,31}$`)
+	consentVisitorPattern  = regexp.MustCompile(`^[0-9a-f]{32}$`)
+)
+
+type ConsentValidationError struct {
+	Reason string
+}
+
+func (e *ConsentValidationError) Error() string {
+	if e == nil {
+		return ""
+	}
+	return e.Reason
+}
+
+func consentValidationErrorf(format string, args ...interface{}) error {
+	return &ConsentValidationError{Reason: fmt.Sprintf(format, args...)}
+}
+
+// ConsentService asks visitors which cookies they accept and remembers the
+// answer, so ads and analytics are only added to pages they agreed to.
+type ConsentService struct {
+	repo        repository.ConsentRepository
+	settingRepo repository.SettingRepository
+
+	mu       sync.RWMutex
+	settings models.ConsentSettings
+	loadedAt time.Time
+}
+
+func NewConsentService(repo repository.ConsentRepository, settingRepo repository.SettingRepository) *ConsentService {
+	return &ConsentService{repo: repo, settingRepo: settingRepo}
+}
+
+func defaultConsentCategories() []models.ConsentCategory {
+	return []models.ConsentCategory{
+		{Key: models.ConsentCategoryNecessary, Label: "Necessary", Description: "Keep you signed in and the site secure.", Required: true},
+		{Key: models.ConsentCategoryAnalytics, Label: "Analytics", Description: "Help us understand how the site is used."},
+		{Key: models.ConsentCategoryAdvertising, Label: "Advertising", Description: "Show ads, which may be personalised by our partners."},
+	}
+}
+
+func defaultConsentSettings() models.ConsentSettings {
+	return models.ConsentSettings{
+		Version:    1,
+		Message:    defaultConsentMessage,
+		Categories: defaultConsentCategories(),
+	}
+}
+
+func (s *ConsentService) GetSettings() (models.ConsentSettings, error) {
+	defaults := defaultConsentSettings()
+	if s == nil || s.settingRepo == nil {
+		return defaults, nil
+	}
+
+	stored, err := s.settingRepo.Get(SettingKeyConsent)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return defaults, nil
+		}
+		return defaults, err
+	}
+	if strings.TrimSpace(stored.Value) == "" {
+		return defaults, nil
+	}
+
+	var settings models.ConsentSettings
+	if err := json.Unmarshal([]byte(stored.Value), &settings); err != nil {
+		return defaults, fmt.Errorf("failed to decode consent settings: %w", err)
+	}
+	if settings.Version < 1 {
+		settings.Version = 1
+	}
+	if len(settings.Categories) == 0 {
+		settings.Categories = defaults.Categories
+	}
+	return settings, nil
+}
+
+func (s *ConsentService) UpdateSettings(req models.UpdateConsentSettingsRequest) (models.ConsentSettings, error) {
+	if s == nil || s.settingRepo == nil {
+		return models.ConsentSettings{}, ErrConsentUnavailable
+	}
+
+	current, err := s.GetSettings()
+	if err != nil {
+		return models.ConsentSettings{}, err
+	}
+
+	settings := models.ConsentSettings{
+		Enabled:   req.Enabled,
+		Version:   current.Version,
+		Message:   strings.TrimSpace(req.Message),
+		PolicyURL: strings.TrimSpace(req.PolicyURL),
+	}
+	if req.Renew {
+		settings.Version++
+	}
+	if settings.Message == "" {
+		settings.Message = defaultConsentMessage
+	}
+	if len(settings.Message) > maxConsentMessage {
+		return models.ConsentSettings{}, consentValidationErrorf("message must be at most %d characters", maxConsentMessage)
+	}
+	if settings.PolicyURL != "" && !validConsentPolicyURL(settings.PolicyURL) {
+		return models.ConsentSettings{}, consentValidationErrorf("policy_url must be a path or an http(s) URL")
+	}
+
+	categories, err := normalizeConsentCategories(req.Categories)
+	if err != nil {
+		return models.ConsentSettings{}, err
+	}
+	settings.Categories = categories
+
+	if len(req.AnalyticsSnippets) > maxConsentSnippets {
+		return models.ConsentSettings{}, consentValidationErrorf("at most %d analytics snippets are allowed", maxConsentSnippets)
+	}
+	for _, snippet := range req.AnalyticsSnippets {
+		snippet = strings.TrimSpace(snippet)
+		if snippet == "" {
+			continue
+		}
+		if len(snippet) > maxConsentSnippetSize {
+			return models.ConsentSettings{}, consentValidationErrorf("analytics snippets must be at most %d bytes", maxConsentSnippetSize)
+		}
+		settings.AnalyticsSnippets = append(settings.AnalyticsSnippets, snippet)
+	}
+
+	encoded, err := json.Marshal(settings)
+	if err != nil {
+		return models.ConsentSettings{}, fmt.Errorf("failed to encode consent settings: %w", err)
+	}
+	if err := s.settingRepo.Set(SettingKeyConsent, string(encoded)); err != nil {
+		return models.ConsentSettings{}, err
+	}
+
+	s.mu.Lock()
+	s.settings = settings
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+
+	return settings, nil
+}
+
+// normalizeConsentCategories checks the categories an admin saved. Admins may
+// relabel the built-in categories and add their own, but the built-in ones are
+// always offered and necessary cannot be made optional.
+func normalizeConsentCategories(input []models.ConsentCategory) ([]models.ConsentCategory, error) {
+	if len(input) == 0 {
+		return defaultConsentCategories(), nil
+	}
+	if len(input) > maxConsentCategories {
+		return nil, consentValidationErrorf("at most %d categories are allowed", maxConsentCategories)
+	}
+
+	categories := make([]models.ConsentCategory, 0, len(input)+1)
+	seen := make(map[string]bool, len(input))
+	for _, category := range input {
+		category.Key = strings.ToLower(strings.TrimSpace(category.Key))
+		category.Label = strings.TrimSpace(category.Label)
+		category.Description = strings.TrimSpace(category.Description)
+		if !consentCategoryPattern.MatchString(category.Key) {
+			return nil, consentValidationErrorf("category key %q must be lowercase letters, digits, dashes or underscores", category.Key)
+		}
+		if seen[category.Key] {
+			return nil, consentValidationErrorf("category %q is listed twice", category.Key)
+		}
+		seen[category.Key] = true
+		if category.Label == "" {
+			return nil, consentValidationErrorf("category %q needs a label", category.Key)
+		}
+		if len(category.Description) > maxConsentDescription {
+			return nil, consentValidationErrorf("category %q: description must be at most %d characters", category.Key, maxConsentDescription)
+		}
+		if category.Key == models.ConsentCategoryNecessary {
+			category.Required = true
+		}
+		categories = append(categories, category)
+	}
+
+	for i, category := range defaultConsentCategories() {
+		if seen[category.Key] {
+			continue
+		}
+		if i == 0 {
+			categories = append([]models.ConsentCategory{category}, categories...)
+			continue
+		}
+		categories = append(categories, category)
+	}
+	if len(categories) > maxConsentCategories {
+		return nil, consentValidationErrorf("at most %d categories are allowed", maxConsentCategories)
+	}
+	return categories, nil
+}
+
+func validConsentPolicyURL(value string) bool {
+	if strings.HasPrefix(value, "/") && !strings.HasPrefix(value, "//") {
+		return true
+	}
+	parsed, err := url.Parse(value)
+	return err == nil && (parsed.Scheme == "https" || parsed.Scheme == "http") && parsed.Host != ""
+}
+
+// Settings returns the settings pages are rendered with. They are reloaded
+// from the database every 30 seconds so every instance picks up changes.
+func (s *ConsentService) Settings() models.ConsentSettings {
+	if s == nil {
+		return models.ConsentSettings{}
+	}
+
+	s.mu.RLock()
+	settings, loadedAt := s.settings, s.loadedAt
+	s.mu.RUnlock()
+	if !loadedAt.IsZero() && time.Since(loadedAt) < consentSettingsRefresh {
+		return settings
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.loadedAt.IsZero() && time.Since(s.loadedAt) < consentSettingsRefresh {
+		return s.settings
+	}
+
+	loaded, err := s.GetSettings()
+	s.loadedAt = time.Now()
+	if err != nil {
+		logger.Error(err, "Failed to load consent settings", nil)
+		return s.settings
+	}
+	s.settings = loaded
+	return s.settings
+}
+
+// consentCookie is what the consent cookie holds: the visitor ID, the settings
+// version the visitor answered and the categories they accepted. It is not
+// signed; a visitor who edits it only changes their own choice.
+type consentCookie struct {
+	visitorID string
+	version   int
+	accepted  map[string]bool
+}
+
+func parseConsentCookie(value string) consentCookie {
+	parts := strings.SplitN(value, ":", 3)
+	if len(parts) != 3 || !consentVisitorPattern.MatchString(parts[0]) {
+		return consentCookie{}
+	}
+	version, err := strconv.Atoi(parts[1])
+	if err != nil || version < 1 {
+		return consentCookie{visitorID: parts[0]}
+	}
+
+	cookie := consentCookie{visitorID: parts[0], version: version, accepted: make(map[string]bool)}
+	for _, key := range strings.Split(parts[2], ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			cookie.accepted[key] = true
+		}
+	}
+	return cookie
+}
+
+// acceptedList returns the accepted categories in order, separated by commas.
+func (c consentCookie) acceptedList() string {
+	accepted := make([]string, 0, len(c.accepted))
+	for key, ok := range c.accepted {
+		if ok {
+			accepted = append(accepted, key)
+		}
+	}
+	sort.Strings(accepted)
+	return strings.Join(accepted, ",")
+}
+
+func (c consentCookie) String() string {
+	return fmt.Sprintf("%s:%d:%s", c.visitorID, c.version, c.acceptedList())
+}
+
+// State returns the consent of the visitor whose consent cookie holds
+// cookieValue. While consent is disabled every category is allowed.
+func (s *ConsentService) State(cookieValue string) models.ConsentState {
+	settings := s.Settings()
+	state := models.ConsentState{
+		Enabled:    settings.Enabled,
+		Version:    settings.Version,
+		Categories: make(map[string]bool, len(settings.Categories)),
+		Available:  settings.Categories,
+	}
+	if !settings.Enabled {
+		for _, category := range settings.Categories {
+			state.Categories[category.Key] = true
+		}
+		return state
+	}
+	state.Message = settings.Message
+	state.PolicyURL = settings.PolicyURL
+
+	cookie := parseConsentCookie(cookieValue)
+	state.Decided = cookie.version == settings.Version
+	for _, category := range settings.Categories {
+		state.Categories[category.Key] = category.Required || (state.Decided && cookie.accepted[category.Key])
+	}
+	return state
+}
+
+// AnalyticsSnippets returns the analytics snippets to add to a page for a
+// visitor in state.
+func (s *ConsentService) AnalyticsSnippets(state models.ConsentState) []string {
+	if !state.Allowed(models.ConsentCategoryAnalytics) {
+		return nil
+	}
+	return s.Settings().AnalyticsSnippets
+}
+
+// Record saves the choice of the visitor whose consent cookie holds
+// cookieValue and returns their new state with the cookie value to store.
+func (s *ConsentService) Record(cookieValue string, req models.UpdateConsentRequest) (models.ConsentState, string, error) {
+	if s == nil || s.repo == nil {
+		return models.ConsentState{}, "", ErrConsentUnavailable
+	}
+	settings := s.Settings()
+	if !settings.Enabled {
+		return models.ConsentState{}, "", ErrConsentDisabled
+	}
+
+	known := make(map[string]models.ConsentCategory, len(settings.Categories))
+	for _, category := range settings.Categories {
+		known[category.Key] = category
+	}
+	for key := range req.Categories {
+		if _, ok := known[key]; !ok {
+			return models.ConsentState{}, "", consentValidationErrorf("unknown consent category %q", key)
+		}
+	}
+
+	cookie := parseConsentCookie(cookieValue)
+	if cookie.visitorID == "" {
+		id := make([]byte, 16)
+		if _, err := rand.Read(id); err != nil {
+			return models.ConsentState{}, "", err
+		}
+		cookie.visitorID = hex.EncodeToString(id)
+	}
+	cookie.version = settings.Version
+	cookie.accepted = make(map[string]bool, len(settings.Categories))
+	for _, category := range settings.Categories {
+		if category.Required || req.Categories[category.Key] {
+			cookie.accepted[category.Key] = true
+		}
+	}
+
+	record := &models.ConsentRecord{
+		VisitorID: cookie.visitorID,
+		Version:   cookie.version,
+		Accepted:  cookie.acceptedList(),
+	}
+	if err := s.repo.Create(record); err != nil {
+		return models.ConsentState{}, "", err
+	}
+
+	value := cookie.String()
+	return s.State(value), value, nil
+}
+
+// Records returns the choices a visitor made, newest first, for answering
+// requests to prove consent.
+func (s *ConsentService) Records(visitorID string) ([]models.ConsentRecord, error) {
+	if s == nil || s.repo == nil {
+		return nil, ErrConsentUnavailable
+	}
+	return s.repo.ListByVisitor(strings.ToLower(strings.TrimSpace(visitorID)), consentRecordListLimit)
+}
+
+// PruneRecords removes choices older than three years.
+func (s *ConsentService) PruneRecords() (int64, error) {
+	if s == nil || s.repo == nil {
+		return 0, ErrConsentUnavailable
+	}
+	return s.repo.DeleteBefore(time.Now().Add(-consentRecordRetention))
+}
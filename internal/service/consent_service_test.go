@@ -0,0 +1,115 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"constructor-script-backend/internal/models"
+)
+
+type memoryConsentRepository struct {
+	records []models.ConsentRecord
+}
+
+func (r *memoryConsentRepository) Create(record *models.ConsentRecord) error {
+	record.ID = uint(len(r.records) + 1)
+	record.CreatedAt = time.Now().UTC()
+	r.records = append(r.records, *record)
+	return nil
+}
+
+func (r *memoryConsentRepository) ListByVisitor(visitorID string, limit int) ([]models.ConsentRecord, error) {
+	var records []models.ConsentRecord
+	for i := len(r.records) - 1; i >= 0 && len(records) < limit; i-- {
+		if r.records[i].VisitorID == visitorID {
+			records = append(records, r.records[i])
+		}
+	}
+	return records, nil
+}
+
+func (r *memoryConsentRepository) DeleteBefore(time.Time) (int64, error) {
+	return 0, nil
+}
+
+func TestConsentDisabledAllowsEverything(t *testing.T) {
+	svc := NewConsentService(&memoryConsentRepository{}, &memorySettingRepository{values: make(map[string]string)})
+
+	state := svc.State("")
+	if state.Enabled || !state.Allowed(models.ConsentCategoryAdvertising) || !state.Allowed(models.ConsentCategoryAnalytics) {
+		t.Fatalf("expected every category to be allowed while consent is disabled, got %+v", state)
+	}
+	if _, _, err := svc.Record("", models.UpdateConsentRequest{}); !errors.Is(err, ErrConsentDisabled) {
+		t.Fatalf("expected choices to be refused while consent is disabled, got %v", err)
+	}
+}
+
+func TestConsentRecord(t *testing.T) {
+	repo := &memoryConsentRepository{}
+	svc := NewConsentService(repo, &memorySettingRepository{values: make(map[string]string)})
+
+	if _, err := svc.UpdateSettings(models.UpdateConsentSettingsRequest{
+		Enabled:    true,
+		Categories: []models.ConsentCategory{{Key: "Bad Key", Label: "Bad"}},
+	}); err == nil {
+		t.Fatal("expected an invalid category key to be rejected")
+	}
+	settings, err := svc.UpdateSettings(models.UpdateConsentSettingsRequest{
+		Enabled:           true,
+		PolicyURL:         "/cookies",
+		Categories:        []models.ConsentCategory{{Key: models.ConsentCategoryAnalytics, Label: "Statistics"}},
+		AnalyticsSnippets: []string{"<script src=\"https://stats.example/a.js\"></script>", " "},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(settings.Categories) != 3 || settings.Categories[0].Key != models.ConsentCategoryNecessary || !settings.Categories[0].Required {
+		t.Fatalf("expected the built-in categories to be kept, got %+v", settings.Categories)
+	}
+
+	state := svc.State("")
+	if state.Decided || state.Allowed(models.ConsentCategoryAnalytics) || !state.Allowed(models.ConsentCategoryNecessary) {
+		t.Fatalf("expected only necessary cookies before a choice, got %+v", state)
+	}
+	if snippets := svc.AnalyticsSnippets(state); len(snippets) != 0 {
+		t.Fatalf("expected no analytics before consent, got %v", snippets)
+	}
+
+	if _, _, err := svc.Record("", models.UpdateConsentRequest{Categories: map[string]bool{"tracking": true}}); err == nil {
+		t.Fatal("expected an unknown category to be rejected")
+	}
+	state, cookie, err := svc.Record("", models.UpdateConsentRequest{Categories: map[string]bool{models.ConsentCategoryAnalytics: true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !state.Decided || !state.Allowed(models.ConsentCategoryAnalytics) || state.Allowed(models.ConsentCategoryAdvertising) {
+		t.Fatalf("unexpected state after consent %+v", state)
+	}
+	if snippets := svc.AnalyticsSnippets(svc.State(cookie)); len(snippets) != 1 {
+		t.Fatalf("expected the analytics snippet after consent, got %v", snippets)
+	}
+
+	visitor := strings.SplitN(cookie, ":", 2)[0]
+	if _, cookie, err = svc.Record(cookie, models.UpdateConsentRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(cookie, visitor+":") {
+		t.Fatalf("expected the visitor to keep their ID, got %q", cookie)
+	}
+	records, err := svc.Records(visitor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || records[0].Accepted != models.ConsentCategoryNecessary || records[1].Accepted != "analytics,necessary" {
+		t.Fatalf("unexpected consent records %+v", records)
+	}
+
+	if _, err := svc.UpdateSettings(models.UpdateConsentSettingsRequest{Enabled: true, Renew: true}); err != nil {
+		t.Fatal(err)
+	}
+	if state := svc.State(cookie); state.Decided || state.Version != 2 {
+		t.Fatalf("expected renewing the settings to ask again, got %+v", state)
+	}
+}
@@ -0,0 +1,290 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/repository"
+	"constructor-script-backend/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// SettingKeyContentSecurityPolicy stores the admin-managed CSP in the
+	// settings repository.
+	SettingKeyContentSecurityPolicy = "security.csp"
+
+	cspSettingsRefresh  = 30 * time.Second
+	maxCSPDirectiveSize = 50
+	maxCSPReportURI     = 2048
+)
+
+// cspDirectives are the fetch directives admins may add sources to. Directives
+// that lock the site down, such as default-src and frame-ancestors, are left to
+// the built-in policy and the configuration.
+var cspDirectives = map[string]bool{
+	"script-src":   true,
+	"style-src":    true,
+	"img-src":      true,
+	"font-src":     true,
+	"connect-src":  true,
+	"frame-src":    true,
+	"media-src":    true,
+	"child-src":    true,
+	"worker-src":   true,
+	"manifest-src": true,
+	"form-action":  true,
+}
+
+var cspKeywords = map[string]bool{
+	"'self'":             true,
+	"'unsafe-inline'":    true,
+	"'unsafe-eval'":      true,
+	"'unsafe-hashes'":    true,
+	"'wasm-unsafe-eval'": true,
+	"'strict-dynamic'":   true,
+}
+
+var (
+	cspHashSource   = regexp.MustCompile(`^'sha(256|384|512)-[A-Za-z0-9+/_-]+={0,2}'$`)
+	cspSchemeSource = regexp.MustCompile(`^[a-z][a-z0-9+.-]*:$`)
+	cspHostSource   = regexp.MustCompile(`^(?:[a-z][a-z0-9+.-]*://)?(?:\*\.)?[a-z0-9-]+(?:\.[a-z0-9-]+)*(?::(?:[0-9]{1,5}|\*))?(?:/[^\s;,'"]*)?$`)
+)
+
+type ContentSecurityPolicyValidationError struct {
+	Reason string
+}
+
+func (e *ContentSecurityPolicyValidationError) Error() string {
+	if e == nil {
+		return ""
+	}
+	return e.Reason
+}
+
+func contentSecurityPolicyValidationErrorf(format string, args ...interface{}) error {
+	return &ContentSecurityPolicyValidationError{Reason: fmt.Sprintf(format, args...)}
+}
+
+// ContentSecurityPolicyService keeps the sources, nonce strategy and report
+// endpoint admins add to the Content-Security-Policy header.
+type ContentSecurityPolicyService struct {
+	settingRepo repository.SettingRepository
+
+	mu       sync.RWMutex
+	settings models.ContentSecurityPolicySettings
+	loadedAt time.Time
+}
+
+func NewContentSecurityPolicyService(repo repository.SettingRepository) *ContentSecurityPolicyService {
+	return &ContentSecurityPolicyService{settingRepo: repo}
+}
+
+func defaultContentSecurityPolicySettings() models.ContentSecurityPolicySettings {
+	return models.ContentSecurityPolicySettings{
+		Sources:       models.ContentSecurityPolicyDirectives{},
+		NonceStrategy: models.CSPNonceNone,
+	}
+}
+
+func (s *ContentSecurityPolicyService) GetSettings() (models.ContentSecurityPolicySettings, error) {
+	defaults := defaultContentSecurityPolicySettings()
+	if s == nil || s.settingRepo == nil {
+		return defaults, nil
+	}
+
+	stored, err := s.settingRepo.Get(SettingKeyContentSecurityPolicy)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return defaults, nil
+		}
+		return defaults, err
+	}
+	if strings.TrimSpace(stored.Value) == "" {
+		return defaults, nil
+	}
+
+	var settings models.ContentSecurityPolicySettings
+	if err := json.Unmarshal([]byte(stored.Value), &settings); err != nil {
+		return defaults, fmt.Errorf("failed to decode content security policy settings: %w", err)
+	}
+	if settings.Sources == nil {
+		settings.Sources = models.ContentSecurityPolicyDirectives{}
+	}
+	if settings.NonceStrategy == "" {
+		settings.NonceStrategy = models.CSPNonceNone
+	}
+	return settings, nil
+}
+
+// ValidateSettings checks req and returns the settings it describes without
+// storing them, for previews.
+func (s *ContentSecurityPolicyService) ValidateSettings(req models.UpdateContentSecurityPolicyRequest) (models.ContentSecurityPolicySettings, error) {
+	settings := models.ContentSecurityPolicySettings{
+		Sources:    make(models.ContentSecurityPolicyDirectives, len(req.Sources)),
+		ReportOnly: req.ReportOnly,
+	}
+
+	for directive, values := range req.Sources {
+		name := strings.ToLower(strings.TrimSpace(directive))
+		if !cspDirectives[name] {
+			return models.ContentSecurityPolicySettings{}, contentSecurityPolicyValidationErrorf("sources cannot be added to %q", directive)
+		}
+		if len(values) > maxCSPDirectiveSize {
+			return models.ContentSecurityPolicySettings{}, contentSecurityPolicyValidationErrorf("%s: at most %d sources are allowed", name, maxCSPDirectiveSize)
+		}
+
+		seen := make(map[string]bool, len(values))
+		for _, value := range values {
+			source, err := normalizeCSPSource(value)
+			if err != nil {
+				return models.ContentSecurityPolicySettings{}, contentSecurityPolicyValidationErrorf("%s: %s", name, err.Error())
+			}
+			if source == "" || seen[source] {
+				continue
+			}
+			seen[source] = true
+			settings.Sources[name] = append(settings.Sources[name], source)
+		}
+	}
+
+	settings.NonceStrategy = strings.ToLower(strings.TrimSpace(req.NonceStrategy))
+	switch settings.NonceStrategy {
+	case "":
+		settings.NonceStrategy = models.CSPNonceNone
+	case models.CSPNonceNone, models.CSPNoncePerRequest:
+	default:
+		return models.ContentSecurityPolicySettings{}, contentSecurityPolicyValidationErrorf("nonce_strategy must be %q or %q", models.CSPNonceNone, models.CSPNoncePerRequest)
+	}
+
+	reportURI, err := normalizeCSPReportURI(req.ReportURI)
+	if err != nil {
+		return models.ContentSecurityPolicySettings{}, contentSecurityPolicyValidationErrorf("report_uri: %s", err.Error())
+	}
+	settings.ReportURI = reportURI
+
+	return settings, nil
+}
+
+func (s *ContentSecurityPolicyService) UpdateSettings(req models.UpdateContentSecurityPolicyRequest) (models.ContentSecurityPolicySettings, error) {
+	settings, err := s.ValidateSettings(req)
+	if err != nil {
+		return models.ContentSecurityPolicySettings{}, err
+	}
+	if s == nil {
+		return settings, nil
+	}
+
+	if s.settingRepo != nil {
+		payload, err := json.Marshal(settings)
+		if err != nil {
+			return settings, fmt.Errorf("failed to encode content security policy settings: %w", err)
+		}
+		if err := s.settingRepo.Set(SettingKeyContentSecurityPolicy, string(payload)); err != nil {
+			return settings, err
+		}
+	}
+
+	s.mu.Lock()
+	s.settings = settings
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+
+	return settings, nil
+}
+
+// ContentSecurityPolicySettings returns the settings the security headers are
+// built from. They are reloaded from the database every 30 seconds so every
+// instance picks up changes.
+func (s *ContentSecurityPolicyService) ContentSecurityPolicySettings() models.ContentSecurityPolicySettings {
+	if s == nil {
+		return models.ContentSecurityPolicySettings{}
+	}
+
+	s.mu.RLock()
+	settings, loadedAt := s.settings, s.loadedAt
+	s.mu.RUnlock()
+	if !loadedAt.IsZero() && time.Since(loadedAt) < cspSettingsRefresh {
+		return settings
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.loadedAt.IsZero() && time.Since(s.loadedAt) < cspSettingsRefresh {
+		return s.settings
+	}
+
+	loaded, err := s.GetSettings()
+	s.loadedAt = time.Now()
+	if err != nil {
+		logger.Error(err, "Failed to load content security policy settings", nil)
+		return s.settings
+	}
+	s.settings = loaded
+	return s.settings
+}
+
+// normalizeCSPSource accepts keywords, hashes, schemes and hosts. The bare
+// wildcard is refused: it would allow scripts from anywhere.
+func normalizeCSPSource(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", nil
+	}
+	if strings.ContainsAny(value, " \t\r\n;,") {
+		return "", fmt.Errorf("%q must be a single source", value)
+	}
+
+	if strings.HasPrefix(value, "'") {
+		if keyword := strings.ToLower(value); cspKeywords[keyword] {
+			return keyword, nil
+		}
+		if cspHashSource.MatchString(value) {
+			return value, nil
+		}
+		if strings.HasPrefix(strings.ToLower(value), "'nonce-") {
+			return "", errors.New("nonces are added by the nonce strategy")
+		}
+		return "", fmt.Errorf("%s is not a supported keyword", value)
+	}
+
+	lowered := strings.ToLower(value)
+	if lowered == "*" {
+		return "", errors.New("the * wildcard is not allowed; list the hosts instead")
+	}
+	if cspSchemeSource.MatchString(lowered) {
+		return lowered, nil
+	}
+	if cspHostSource.MatchString(lowered) {
+		return value, nil
+	}
+	return "", fmt.Errorf("%q is not a valid source", value)
+}
+
+// normalizeCSPReportURI accepts a path on this site or an https URL.
+func normalizeCSPReportURI(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", nil
+	}
+	if len(value) > maxCSPReportURI || strings.ContainsAny(value, " \t\r\n;,'\"") {
+		return "", errors.New("must be a path or an https URL")
+	}
+	if strings.HasPrefix(value, "/") && !strings.HasPrefix(value, "//") {
+		return value, nil
+	}
+
+	parsed, err := url.Parse(value)
+	if err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+		return "", errors.New("must be a path or an https URL")
+	}
+	return parsed.String(), nil
+}
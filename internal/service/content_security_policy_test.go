@@ -0,0 +1,61 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"constructor-script-backend/internal/models"
+)
+
+func TestContentSecurityPolicySettingsValidation(t *testing.T) {
+	svc := NewContentSecurityPolicyService(&memorySettingRepository{values: make(map[string]string)})
+
+	invalid := []models.UpdateContentSecurityPolicyRequest{
+		{Sources: models.ContentSecurityPolicyDirectives{"default-src": {"https://cdn.example.com"}}},
+		{Sources: models.ContentSecurityPolicyDirectives{"script-src": {"*"}}},
+		{Sources: models.ContentSecurityPolicyDirectives{"script-src": {"https://a.example.com; object-src *"}}},
+		{Sources: models.ContentSecurityPolicyDirectives{"script-src": {"'nonce-abc'"}}},
+		{Sources: models.ContentSecurityPolicyDirectives{"style-src": {"'unsafe-everything'"}}},
+		{NonceStrategy: "per_page"},
+		{ReportURI: "http://reports.example.com/csp"},
+		{ReportURI: "//reports.example.com/csp"},
+	}
+	for _, req := range invalid {
+		_, err := svc.UpdateSettings(req)
+		var validationErr *ContentSecurityPolicyValidationError
+		if !errors.As(err, &validationErr) {
+			t.Fatalf("expected %+v to be rejected, got %v", req, err)
+		}
+	}
+
+	settings, err := svc.UpdateSettings(models.UpdateContentSecurityPolicyRequest{
+		Sources: models.ContentSecurityPolicyDirectives{
+			" Script-Src ": {"https://*.Example.com/js/", "'SELF'", "https://*.Example.com/js/", "'sha256-abc123+/='"},
+			"img-src":      {"DATA:", " "},
+		},
+		NonceStrategy: "Per_Request",
+		ReportURI:     "/api/v1/csp-reports",
+		ReportOnly:    true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	scripts := settings.Sources["script-src"]
+	if len(scripts) != 3 || scripts[0] != "https://*.Example.com/js/" || scripts[1] != "'self'" {
+		t.Fatalf("unexpected script-src %v", scripts)
+	}
+	if images := settings.Sources["img-src"]; len(images) != 1 || images[0] != "data:" {
+		t.Fatalf("unexpected img-src %v", images)
+	}
+	if settings.NonceStrategy != models.CSPNoncePerRequest || !settings.ReportOnly {
+		t.Fatalf("unexpected settings %+v", settings)
+	}
+
+	stored, err := NewContentSecurityPolicyService(svc.settingRepo).GetSettings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.ReportURI != "/api/v1/csp-reports" || len(stored.Sources["script-src"]) != 3 {
+		t.Fatalf("expected the settings to be stored, got %+v", stored)
+	}
+}
@@ -0,0 +1,255 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/repository"
+	"constructor-script-backend/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidDeliveryToken               = errors.New("invalid delivery token")
+	ErrDeliveryTokenRepositoryUnavailable = errors.New("delivery token repository not configured")
+)
+
+const (
+	// DeliveryTokenPrefix starts every delivery token, so that leaked tokens are
+	// easy to recognise in logs and secret scanners.
+	DeliveryTokenPrefix = "cdt_"
+
+	defaultDeliveryRequestsPerMinute = 600
+	deliveryTokenDisplayLength       = len(DeliveryTokenPrefix) + 8
+	// deliveryTokenRefresh bounds how long an instance keeps accepting a token
+	// another instance revoked.
+	deliveryTokenRefresh = 30 * time.Second
+	// deliveryTokenTouchInterval spaces out the last-used writes of busy tokens.
+	deliveryTokenTouchInterval = 5 * time.Minute
+	deliveryTokenCacheLimit    = 10000
+)
+
+// DeliveryTokenService manages delivery tokens and checks them on every
+// delivery request, from memory for deliveryTokenRefresh after each lookup.
+type DeliveryTokenService struct {
+	repo repository.DeliveryTokenRepository
+
+	mu      sync.Mutex
+	lookups map[string]deliveryTokenLookup
+	touched map[uint]time.Time
+}
+
+// deliveryTokenLookup remembers a token, or that a hash matches none.
+type deliveryTokenLookup struct {
+	token    *models.DeliveryToken
+	loadedAt time.Time
+}
+
+func NewDeliveryTokenService(repo repository.DeliveryTokenRepository) *DeliveryTokenService {
+	if repo == nil {
+		return nil
+	}
+	return &DeliveryTokenService{
+		repo:    repo,
+		lookups: make(map[string]deliveryTokenLookup),
+		touched: make(map[uint]time.Time),
+	}
+}
+
+func (s *DeliveryTokenService) List() ([]models.DeliveryToken, error) {
+	if s == nil || s.repo == nil {
+		return nil, ErrDeliveryTokenRepositoryUnavailable
+	}
+	return s.repo.List()
+}
+
+func (s *DeliveryTokenService) Get(id uint) (*models.DeliveryToken, error) {
+	if s == nil || s.repo == nil {
+		return nil, ErrDeliveryTokenRepositoryUnavailable
+	}
+	return s.repo.GetByID(id)
+}
+
+// Create issues a token and returns it with its value, which is not stored and
+// cannot be shown again.
+func (s *DeliveryTokenService) Create(req models.CreateDeliveryTokenRequest) (*models.DeliveryToken, string, error) {
+	if s == nil || s.repo == nil {
+		return nil, "", ErrDeliveryTokenRepositoryUnavailable
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, "", fmt.Errorf("%w: name is required", ErrInvalidDeliveryToken)
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, "", fmt.Errorf("%w: expires_at must be in the future", ErrInvalidDeliveryToken)
+	}
+
+	value, err := generateDeliveryToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	token := &models.DeliveryToken{
+		Name:              name,
+		Prefix:            value[:deliveryTokenDisplayLength],
+		TokenHash:         hashDeliveryToken(value),
+		RequestsPerMinute: req.RequestsPerMinute,
+		RequestsPerDay:    req.RequestsPerDay,
+		Active:            true,
+		ExpiresAt:         req.ExpiresAt,
+	}
+	if token.RequestsPerMinute <= 0 {
+		token.RequestsPerMinute = defaultDeliveryRequestsPerMinute
+	}
+
+	if err := s.repo.Create(token); err != nil {
+		return nil, "", err
+	}
+	s.forget()
+	return token, value, nil
+}
+
+func (s *DeliveryTokenService) Update(id uint, req models.UpdateDeliveryTokenRequest) (*models.DeliveryToken, error) {
+	if s == nil || s.repo == nil {
+		return nil, ErrDeliveryTokenRepositoryUnavailable
+	}
+
+	token, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, fmt.Errorf("%w: name is required", ErrInvalidDeliveryToken)
+		}
+		token.Name = name
+	}
+	if req.RequestsPerMinute != nil {
+		token.RequestsPerMinute = *req.RequestsPerMinute
+	}
+	if req.RequestsPerDay != nil {
+		token.RequestsPerDay = *req.RequestsPerDay
+	}
+	if req.Active != nil {
+		token.Active = *req.Active
+	}
+	if req.ExpiresAt != nil {
+		token.ExpiresAt = req.ExpiresAt
+	}
+
+	if err := s.repo.Update(token); err != nil {
+		return nil, err
+	}
+	s.forget()
+	return token, nil
+}
+
+func (s *DeliveryTokenService) Delete(id uint) error {
+	if s == nil || s.repo == nil {
+		return ErrDeliveryTokenRepositoryUnavailable
+	}
+	if _, err := s.repo.GetByID(id); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+	s.forget()
+	return nil
+}
+
+// AuthenticateDeliveryToken returns the active, unexpired token with value.
+func (s *DeliveryTokenService) AuthenticateDeliveryToken(value string) (*models.DeliveryToken, bool) {
+	if s == nil || s.repo == nil || !strings.HasPrefix(value, DeliveryTokenPrefix) {
+		return nil, false
+	}
+
+	token := s.lookup(hashDeliveryToken(value))
+	if token == nil || !token.Active {
+		return nil, false
+	}
+	now := time.Now()
+	if token.ExpiresAt != nil && !token.ExpiresAt.After(now) {
+		return nil, false
+	}
+
+	s.touch(token.ID, now)
+	return token, true
+}
+
+func (s *DeliveryTokenService) lookup(hash string) *models.DeliveryToken {
+	s.mu.Lock()
+	cached, ok := s.lookups[hash]
+	s.mu.Unlock()
+	if ok && time.Since(cached.loadedAt) < deliveryTokenRefresh {
+		return cached.token
+	}
+
+	token, err := s.repo.GetByHash(hash)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Error(err, "Failed to load delivery token", nil)
+			// Keep serving a known token through a database hiccup.
+			return cached.token
+		}
+		token = nil
+	}
+
+	s.mu.Lock()
+	// Unknown hashes are remembered too, so guessing tokens does not reach the
+	// database on every request; the map is reset before it grows unbounded.
+	if len(s.lookups) >= deliveryTokenCacheLimit {
+		s.lookups = make(map[string]deliveryTokenLookup)
+	}
+	s.lookups[hash] = deliveryTokenLookup{token: token, loadedAt: time.Now()}
+	s.mu.Unlock()
+	return token
+}
+
+func (s *DeliveryTokenService) touch(id uint, now time.Time) {
+	s.mu.Lock()
+	last, ok := s.touched[id]
+	if ok && now.Sub(last) < deliveryTokenTouchInterval {
+		s.mu.Unlock()
+		return
+	}
+	s.touched[id] = now
+	s.mu.Unlock()
+
+	go func() {
+		if err := s.repo.TouchLastUsed(id, now.UTC()); err != nil {
+			logger.Error(err, "Failed to record delivery token use", map[string]interface{}{"token_id": id})
+		}
+	}()
+}
+
+// forget drops the remembered lookups after a change on this instance.
+func (s *DeliveryTokenService) forget() {
+	s.mu.Lock()
+	s.lookups = make(map[string]deliveryTokenLookup)
+	s.mu.Unlock()
+}
+
+func generateDeliveryToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate delivery token: %w", err)
+	}
+	return DeliveryTokenPrefix + hex.EncodeToString(buf), nil
+}
+
+func hashDeliveryToken(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"constructor-script-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type memoryDeliveryTokenRepository struct {
+	mu      sync.Mutex
+	tokens  map[uint]models.DeliveryToken
+	lookups int
+}
+
+func (r *memoryDeliveryTokenRepository) List() ([]models.DeliveryToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	tokens := make([]models.DeliveryToken, 0, len(r.tokens))
+	for _, token := range r.tokens {
+		tokens = append(tokens, token)
+	}
+	return tokens, nil
+}
+
+func (r *memoryDeliveryTokenRepository) GetByID(id uint) (*models.DeliveryToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	token, ok := r.tokens[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &token, nil
+}
+
+func (r *memoryDeliveryTokenRepository) GetByHash(hash string) (*models.DeliveryToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lookups++
+	for _, token := range r.tokens {
+		if token.TokenHash == hash {
+			return &token, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memoryDeliveryTokenRepository) Create(token *models.DeliveryToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	token.ID = uint(len(r.tokens) + 1)
+	r.tokens[token.ID] = *token
+	return nil
+}
+
+func (r *memoryDeliveryTokenRepository) Update(token *models.DeliveryToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tokens[token.ID] = *token
+	return nil
+}
+
+func (r *memoryDeliveryTokenRepository) Delete(id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.tokens, id)
+	return nil
+}
+
+func (r *memoryDeliveryTokenRepository) TouchLastUsed(id uint, at time.Time) error {
+	return nil
+}
+
+func TestDeliveryTokenServiceAuthenticates(t *testing.T) {
+	repo := &memoryDeliveryTokenRepository{tokens: make(map[uint]models.DeliveryToken)}
+	svc := NewDeliveryTokenService(repo)
+
+	token, value, err := svc.Create(models.CreateDeliveryTokenRequest{Name: " Static build "})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if !strings.HasPrefix(value, DeliveryTokenPrefix) || token.TokenHash == value || !strings.HasPrefix(value, token.Prefix) {
+		t.Fatalf("unexpected token %+v for value %q", token, value)
+	}
+	if token.Name != "Static build" || token.RequestsPerMinute != defaultDeliveryRequestsPerMinute {
+		t.Fatalf("token not normalized: %+v", token)
+	}
+
+	if found, ok := svc.AuthenticateDeliveryToken(value); !ok || found.ID != token.ID {
+		t.Fatalf("expected the token to authenticate, got %+v %v", found, ok)
+	}
+	for i := 0; i < 3; i++ {
+		if _, ok := svc.AuthenticateDeliveryToken(DeliveryTokenPrefix + "guess"); ok {
+			t.Fatal("expected an unknown token to be rejected")
+		}
+	}
+	if repo.lookups != 2 {
+		t.Fatalf("expected lookups to be remembered, got %d database lookups", repo.lookups)
+	}
+
+	inactive := false
+	if _, err := svc.Update(token.ID, models.UpdateDeliveryTokenRequest{Active: &inactive}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if _, ok := svc.AuthenticateDeliveryToken(value); ok {
+		t.Fatal("expected a deactivated token to be rejected")
+	}
+}
+
+func TestDeliveryTokenServiceRejectsExpiredTokens(t *testing.T) {
+	repo := &memoryDeliveryTokenRepository{tokens: make(map[uint]models.DeliveryToken)}
+	svc := NewDeliveryTokenService(repo)
+
+	past := time.Now().Add(-time.Hour)
+	if _, _, err := svc.Create(models.CreateDeliveryTokenRequest{Name: "old", ExpiresAt: &past}); err == nil {
+		t.Fatal("expected an expiry in the past to be rejected")
+	}
+
+	soon := time.Now().Add(time.Hour)
+	token, value, err := svc.Create(models.CreateDeliveryTokenRequest{Name: "app", ExpiresAt: &soon})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	token.ExpiresAt = &past
+	repo.tokens[token.ID] = *token
+	svc.forget()
+	if _, ok := svc.AuthenticateDeliveryToken(value); ok {
+		t.Fatal("expected an expired token to be rejected")
+	}
+}
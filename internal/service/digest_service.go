@@ -0,0 +1,289 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/repository"
+	"constructor-script-backend/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// digestSendSlack lets a digest go out slightly early so a run that starts a
+	// little sooner than the previous one does not skip a period.
+	digestSendSlack = time.Hour
+	// digestSectionLimit caps the items of a section; the digest links to the
+	// site for the rest.
+	digestSectionLimit = 10
+)
+
+var (
+	ErrInvalidDigestFrequency = errors.New("frequency must be off, daily or weekly")
+	ErrDigestUnavailable      = errors.New("digest service not configured")
+)
+
+// DigestSource contributes a section to activity digests. Plugins register one
+// for their content while they are active.
+type DigestSource interface {
+	// DigestSection returns the activity between since and until that is of
+	// interest to the user, with at most limit items.
+	DigestSection(ctx context.Context, userID uint, since, until time.Time, limit int) (models.DigestSection, error)
+}
+
+// DigestService sends users a daily or weekly email summarizing new activity on
+// the site, compiled from the registered digest sources.
+type DigestService struct {
+	repo  repository.DigestSubscriptionRepository
+	users repository.UserRepository
+	email *EmailService
+
+	mu      sync.RWMutex
+	sources map[string]DigestSource
+
+	now func() time.Time
+}
+
+func NewDigestService(repo repository.DigestSubscriptionRepository, users repository.UserRepository, email *EmailService) *DigestService {
+	return &DigestService{
+		repo:    repo,
+		users:   users,
+		email:   email,
+		sources: make(map[string]DigestSource),
+		now:     func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// SetSource registers the source for name, or removes it when source is nil.
+// Sections appear in the digest in the order of their names.
+func (s *DigestService) SetSource(name string, source DigestSource) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if source == nil {
+		delete(s.sources, name)
+		return
+	}
+	s.sources[name] = source
+}
+
+// Preferences returns the digest subscription of the user, which is off until
+// the user chooses a frequency.
+func (s *DigestService) Preferences(userID uint) (*models.DigestSubscription, error) {
+	if s == nil || s.repo == nil {
+		return nil, ErrDigestUnavailable
+	}
+
+	subscription, err := s.repo.GetByUserID(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &models.DigestSubscription{UserID: userID, Frequency: models.DigestOff}, nil
+	}
+	return subscription, err
+}
+
+// UpdatePreferences sets how often the user receives a digest.
+func (s *DigestService) UpdatePreferences(userID uint, frequency string) (*models.DigestSubscription, error) {
+	if s == nil || s.repo == nil {
+		return nil, ErrDigestUnavailable
+	}
+
+	frequency = strings.ToLower(strings.TrimSpace(frequency))
+	if _, ok := digestPeriod(frequency); !ok && frequency != models.DigestOff {
+		return nil, ErrInvalidDigestFrequency
+	}
+
+	subscription, err := s.Preferences(userID)
+	if err != nil {
+		return nil, err
+	}
+	if subscription.UnsubscribeToken == "" {
+		token, err := generateDigestToken()
+		if err != nil {
+			return nil, err
+		}
+		subscription.UnsubscribeToken = token
+	}
+	subscription.Frequency = frequency
+
+	if err := s.repo.Save(subscription); err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+// Unsubscribe turns off the digests of the subscription identified by the token
+// in the digest emails.
+func (s *DigestService) Unsubscribe(token string) error {
+	if s == nil || s.repo == nil {
+		return ErrDigestUnavailable
+	}
+
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return gorm.ErrRecordNotFound
+	}
+	subscription, err := s.repo.GetByToken(token)
+	if err != nil {
+		return err
+	}
+	if subscription.Frequency == models.DigestOff {
+		return nil
+	}
+	subscription.Frequency = models.DigestOff
+	return s.repo.Save(subscription)
+}
+
+// SendDue emails every subscriber whose daily or weekly digest is due. Users
+// without new activity get no email, but their period still starts over.
+func (s *DigestService) SendDue(ctx context.Context) (int, error) {
+	if s == nil || s.repo == nil || s.users == nil {
+		return 0, ErrDigestUnavailable
+	}
+	if s.email == nil || !s.email.Enabled() {
+		return 0, nil
+	}
+
+	now := s.now()
+	sent := 0
+	for _, frequency := range []string{models.DigestDaily, models.DigestWeekly} {
+		period, _ := digestPeriod(frequency)
+		subscriptions, err := s.repo.ListDue(frequency, now.Add(-period+digestSendSlack))
+		if err != nil {
+			return sent, fmt.Errorf("failed to list due %s digests: %w", frequency, err)
+		}
+		if len(subscriptions) == 0 {
+			continue
+		}
+
+		userIDs := make([]uint, 0, len(subscriptions))
+		for _, subscription := range subscriptions {
+			userIDs = append(userIDs, subscription.UserID)
+		}
+		users, err := s.users.GetByIDs(userIDs)
+		if err != nil {
+			return sent, fmt.Errorf("failed to load users for digests: %w", err)
+		}
+		usersByID := make(map[uint]models.User, len(users))
+		for _, user := range users {
+			usersByID[user.ID] = user
+		}
+
+		for i := range subscriptions {
+			if err := ctx.Err(); err != nil {
+				return sent, err
+			}
+
+			subscription := &subscriptions[i]
+			user, ok := usersByID[subscription.UserID]
+			if !ok || strings.TrimSpace(user.Email) == "" {
+				continue
+			}
+
+			since := now.Add(-period)
+			if subscription.LastSentAt != nil {
+				since = *subscription.LastSentAt
+			}
+
+			delivered, err := s.send(ctx, subscription, user, since, now)
+			if err != nil {
+				logger.Warn("Failed to send activity digest", map[string]interface{}{
+					"user_id":   user.ID,
+					"frequency": frequency,
+					"error":     err.Error(),
+				})
+				continue
+			}
+			if delivered {
+				sent++
+			}
+
+			if err := s.repo.MarkSent(subscription.ID, now); err != nil {
+				return sent, fmt.Errorf("failed to record digest for user %d: %w", user.ID, err)
+			}
+		}
+	}
+
+	return sent, nil
+}
+
+// send compiles and queues the digest of a single user. It reports false when
+// there was nothing to report.
+func (s *DigestService) send(ctx context.Context, subscription *models.DigestSubscription, user models.User, since, until time.Time) (bool, error) {
+	sections := s.compile(ctx, user.ID, since, until)
+	if len(sections) == 0 {
+		return false, nil
+	}
+
+	unsubscribeURL := s.email.SiteURL() + "/digest/unsubscribe/" + subscription.UnsubscribeToken
+	data := map[string]interface{}{
+		"Username":       user.Username,
+		"Frequency":      subscription.Frequency,
+		"Sections":       sections,
+		"UnsubscribeURL": unsubscribeURL,
+	}
+	// The key keeps instances running the job at the same time from sending the
+	// same digest twice.
+	key := fmt.Sprintf("digest:%d:%s:%s", user.ID, subscription.Frequency, until.Format("2006-01-02"))
+	err := s.email.SendSubscriptionTemplate(key, user.Email, EmailTemplateActivityDigest, "Your activity digest", unsubscribeURL, data)
+	return err == nil, err
+}
+
+func (s *DigestService) compile(ctx context.Context, userID uint, since, until time.Time) []models.DigestSection {
+	s.mu.RLock()
+	names := make([]string, 0, len(s.sources))
+	sources := make(map[string]DigestSource, len(s.sources))
+	for name, source := range s.sources {
+		names = append(names, name)
+		sources[name] = source
+	}
+	s.mu.RUnlock()
+	sort.Strings(names)
+
+	sections := make([]models.DigestSection, 0, len(names))
+	for _, name := range names {
+		section, err := sources[name].DigestSection(ctx, userID, since, until, digestSectionLimit)
+		if err != nil {
+			// A failing source leaves out its section rather than the whole digest.
+			logger.Warn("Failed to compile digest section", map[string]interface{}{
+				"source":  name,
+				"user_id": userID,
+				"error":   err.Error(),
+			})
+			continue
+		}
+		if len(section.Items) > 0 {
+			sections = append(sections, section)
+		}
+	}
+	return sections
+}
+
+func digestPeriod(frequency string) (time.Duration, bool) {
+	switch frequency {
+	case models.DigestDaily:
+		return 24 * time.Hour, true
+	case models.DigestWeekly:
+		return 7 * 24 * time.Hour, true
+	default:
+		return 0, false
+	}
+}
+
+func generateDigestToken() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate digest token: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/repository"
+	"constructor-script-backend/pkg/mail"
+
+	"gorm.io/gorm"
+)
+
+type memoryDigestRepository struct {
+	mu            sync.Mutex
+	subscriptions map[uint]models.DigestSubscription
+	nextID        uint
+}
+
+func (r *memoryDigestRepository) GetByUserID(userID uint) (*models.DigestSubscription, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, subscription := range r.subscriptions {
+		if subscription.UserID == userID {
+			return &subscription, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memoryDigestRepository) GetByToken(token string) (*models.DigestSubscription, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, subscription := range r.subscriptions {
+		if subscription.UnsubscribeToken == token {
+			return &subscription, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memoryDigestRepository) Save(subscription *models.DigestSubscription) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if subscription.ID == 0 {
+		r.nextID++
+		subscription.ID = r.nextID
+	}
+	r.subscriptions[subscription.ID] = *subscription
+	return nil
+}
+
+func (r *memoryDigestRepository) ListDue(frequency string, sentBefore time.Time) ([]models.DigestSubscription, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var due []models.DigestSubscription
+	for _, subscription := range r.subscriptions {
+		if subscription.Frequency == frequency && (subscription.LastSentAt == nil || !subscription.LastSentAt.After(sentBefore)) {
+			due = append(due, subscription)
+		}
+	}
+	return due, nil
+}
+
+func (r *memoryDigestRepository) MarkSent(id uint, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	subscription := r.subscriptions[id]
+	subscription.LastSentAt = &at
+	r.subscriptions[id] = subscription
+	return nil
+}
+
+type digestUserRepository struct {
+	repository.UserRepository
+	users []models.User
+}
+
+func (r digestUserRepository) GetByIDs(ids []uint) ([]models.User, error) {
+	var result []models.User
+	for _, user := range r.users {
+		for _, id := range ids {
+			if user.ID == id {
+				result = append(result, user)
+			}
+		}
+	}
+	return result, nil
+}
+
+type digestSourceFunc func(userID uint, since, until time.Time) (models.DigestSection, error)
+
+func (f digestSourceFunc) DigestSection(_ context.Context, userID uint, since, until time.Time, _ int) (models.DigestSection, error) {
+	return f(userID, since, until)
+}
+
+func TestDigestPreferencesAndUnsubscribe(t *testing.T) {
+	repo := &memoryDigestRepository{subscriptions: make(map[uint]models.DigestSubscription)}
+	svc := NewDigestService(repo, nil, nil)
+
+	subscription, err := svc.Preferences(1)
+	if err != nil || subscription.Frequency != models.DigestOff {
+		t.Fatalf("expected digests to be off by default, got %+v, %v", subscription, err)
+	}
+	if _, err := svc.UpdatePreferences(1, "hourly"); !errors.Is(err, ErrInvalidDigestFrequency) {
+		t.Fatalf("expected an invalid frequency to be rejected, got %v", err)
+	}
+
+	subscription, err = svc.UpdatePreferences(1, " Weekly ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subscription.Frequency != models.DigestWeekly || subscription.UnsubscribeToken == "" {
+		t.Fatalf("unexpected subscription %+v", subscription)
+	}
+	token := subscription.UnsubscribeToken
+
+	if err := svc.Unsubscribe("unknown"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("expected an unknown token to be rejected, got %v", err)
+	}
+	if err := svc.Unsubscribe(token); err != nil {
+		t.Fatal(err)
+	}
+	subscription, _ = svc.Preferences(1)
+	if subscription.Frequency != models.DigestOff {
+		t.Fatalf("expected unsubscribing to turn digests off, got %q", subscription.Frequency)
+	}
+
+	subscription, _ = svc.UpdatePreferences(1, models.DigestDaily)
+	if subscription.UnsubscribeToken != token {
+		t.Fatal("expected the unsubscribe token to stay the same")
+	}
+}
+
+func TestDigestSendDue(t *testing.T) {
+	sent := make(chan mail.Message, 4)
+	email, deliveries := newQueuedEmailService(func(_ mail.Config, message mail.Message) error {
+		sent <- message
+		return nil
+	})
+
+	repo := &memoryDigestRepository{subscriptions: make(map[uint]models.DigestSubscription)}
+	users := digestUserRepository{users: []models.User{
+		{ID: 1, Username: "ada", Email: "ada@example.com"},
+		{ID: 2, Username: "grace", Email: "grace@example.com"},
+	}}
+	svc := NewDigestService(repo, users, email)
+	now := time.Date(2026, 10, 15, 7, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	ada, _ := svc.UpdatePreferences(1, models.DigestDaily)
+	if _, err := svc.UpdatePreferences(2, models.DigestWeekly); err != nil {
+		t.Fatal(err)
+	}
+	lastWeek := now.Add(-3 * 24 * time.Hour)
+	repo.MarkSent(2, lastWeek)
+
+	var windows []time.Time
+	svc.SetSource("forum", digestSourceFunc(func(userID uint, since, until time.Time) (models.DigestSection, error) {
+		windows = append(windows, since, until)
+		return models.DigestSection{Title: "Replies", Items: []models.DigestItem{{Title: "How do I deploy?", Path: "/forum/deploy", Summary: "New answer from grace"}}}, nil
+	}))
+	svc.SetSource("blog", digestSourceFunc(func(uint, time.Time, time.Time) (models.DigestSection, error) {
+		return models.DigestSection{}, errors.New("database unavailable")
+	}))
+
+	count, err := svc.SendDue(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Fatalf("expected only the daily digest to be due, sent %d", count)
+	}
+	if len(windows) != 2 || !windows[0].Equal(now.Add(-24*time.Hour)) || !windows[1].Equal(now) {
+		t.Fatalf("expected a first digest to cover the last day, got %v", windows)
+	}
+
+	select {
+	case message := <-sent:
+		if message.To != "ada@example.com" || !strings.Contains(message.Text, "How do I deploy?") {
+			t.Fatalf("unexpected digest %+v", message)
+		}
+		if message.Unsubscribe != email.SiteURL()+"/digest/unsubscribe/"+ada.UnsubscribeToken || !strings.Contains(message.HTML, message.Unsubscribe) {
+			t.Fatalf("expected the digest to carry its unsubscribe link, got %q", message.Unsubscribe)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the digest to be sent")
+	}
+
+	if subscription, _ := svc.Preferences(1); subscription.LastSentAt == nil || !subscription.LastSentAt.Equal(now) {
+		t.Fatalf("expected the digest to be recorded, got %+v", subscription)
+	}
+	if subscription, _ := svc.Preferences(2); !subscription.LastSentAt.Equal(lastWeek) {
+		t.Fatal("expected the weekly digest to wait for its week")
+	}
+
+	// The next day's run picks up where the last digest ended.
+	windows = nil
+	now = now.Add(24 * time.Hour)
+	if _, err := svc.SendDue(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(windows) != 2 || !windows[0].Equal(now.Add(-24*time.Hour)) {
+		t.Fatalf("expected the digest to start at the previous one, got %v", windows)
+	}
+	if queued, _ := deliveries.List(repository.EmailDeliveryFilter{}); len(queued) != 2 {
+		t.Fatalf("expected two queued digests, got %d", len(queued))
+	}
+}
+
+func TestDigestSkipsEmptyDigests(t *testing.T) {
+	email, deliveries := newQueuedEmailService(func(mail.Config, mail.Message) error { return nil })
+	repo := &memoryDigestRepository{subscriptions: make(map[uint]models.DigestSubscription)}
+	svc := NewDigestService(repo, digestUserRepository{users: []models.User{{ID: 1, Email: "ada@example.com"}}}, email)
+	svc.SetSource("blog", digestSourceFunc(func(uint, time.Time, time.Time) (models.DigestSection, error) {
+		return models.DigestSection{Title: "New posts"}, nil
+	}))
+
+	if _, err := svc.UpdatePreferences(1, models.DigestDaily); err != nil {
+		t.Fatal(err)
+	}
+	count, err := svc.SendDue(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if queued, _ := deliveries.List(repository.EmailDeliveryFilter{}); count != 0 || len(queued) != 0 {
+		t.Fatalf("expected no email without activity, sent %d", count)
+	}
+	if subscription, _ := svc.Preferences(1); subscription.LastSentAt == nil {
+		t.Fatal("expected the period to start over")
+	}
+}
@@ -0,0 +1,488 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/repository"
+	"constructor-script-backend/pkg/logger"
+	"constructor-script-backend/pkg/utils"
+)
+
+const (
+	defaultDirectUploadExpiry = 30 * time.Minute
+	directUploadTokenType     = "direct_upload"
+	directUploadAudience      = "direct-upload"
+	s3UnsignedPayload         = "UNSIGNED-PAYLOAD"
+)
+
+var (
+	ErrDirectUploadsDisabled  = errors.New("direct uploads to object storage are not configured")
+	ErrDirectUploadCategory   = errors.New("only videos and documents can be uploaded directly to object storage")
+	ErrDirectUploadTicket     = errors.New("invalid or expired upload ticket")
+	ErrDirectUploadIncomplete = errors.New("the file has not reached object storage yet")
+)
+
+// DirectUploadConfig describes the S3-compatible bucket browsers upload to.
+type DirectUploadConfig struct {
+	Endpoint  string
+	AccessKey string
+	SecretKey string
+	Bucket    string
+	Region    string
+	UseSSL    bool
+	Prefix    string
+	// PublicURL is the base URL visitors load objects from, such as a CDN in front of
+	// the bucket. It defaults to the bucket URL on the endpoint.
+	PublicURL string
+	// Expiry limits how long a presigned upload URL stays valid.
+	Expiry time.Duration
+}
+
+// DirectUploadRequest asks for a presigned URL for one file.
+type DirectUploadRequest struct {
+	Filename    string `json:"filename" binding:"required"`
+	ContentType string `json:"content_type" binding:"required"`
+	Size        int64  `json:"size" binding:"required"`
+	Name        string `json:"name"`
+}
+
+// DirectUploadTicket tells the browser where to PUT the file. Token is passed back to
+// Complete once the upload has finished.
+type DirectUploadTicket struct {
+	UploadURL string            `json:"upload_url"`
+	Method    string            `json:"method"`
+	Headers   map[string]string `json:"headers"`
+	Filename  string            `json:"filename"`
+	URL       string            `json:"url"`
+	Token     string            `json:"token"`
+	ExpiresAt time.Time         `json:"expires_at"`
+}
+
+type directUploadClaims struct {
+	jwt.RegisteredClaims
+	Type        string `json:"typ"`
+	Key         string `json:"key"`
+	Filename    string `json:"fn"`
+	ContentType string `json:"ct"`
+	Category    string `json:"cat"`
+	Size        int64  `json:"size"`
+}
+
+// DirectUploadService lets browsers upload large files straight to object storage with
+// presigned URLs, so they never stream through the application.
+type DirectUploadService struct {
+	uploads    *UploadService
+	repo       repository.RemoteUploadRepository
+	config     DirectUploadConfig
+	signingKey []byte
+	httpClient *http.Client
+}
+
+// NewDirectUploadService signs tickets with a key derived from secret, so a ticket
+// can never pass for a session token signed with the secret itself.
+func NewDirectUploadService(uploads *UploadService, repo repository.RemoteUploadRepository, config DirectUploadConfig, secret string) (*DirectUploadService, error) {
+	config.Endpoint = strings.TrimSpace(config.Endpoint)
+	config.Bucket = strings.TrimSpace(config.Bucket)
+	if config.Endpoint == "" {
+		return nil, fmt.Errorf("s3 endpoint is required")
+	}
+	if config.AccessKey == "" || config.SecretKey == "" {
+		return nil, fmt.Errorf("s3 credentials are required")
+	}
+	if config.Bucket == "" {
+		return nil, fmt.Errorf("s3 bucket is required")
+	}
+	if strings.TrimSpace(secret) == "" {
+		return nil, fmt.Errorf("a signing secret is required")
+	}
+	if strings.TrimSpace(config.Region) == "" {
+		config.Region = "us-east-1"
+	}
+	if config.Expiry <= 0 {
+		config.Expiry = defaultDirectUploadExpiry
+	}
+	config.Prefix = strings.Trim(config.Prefix, "/")
+	config.PublicURL = strings.TrimRight(strings.TrimSpace(config.PublicURL), "/")
+
+	signingKey := sha256.Sum256([]byte("direct-upload:" + secret))
+	return &DirectUploadService{
+		uploads:    uploads,
+		repo:       repo,
+		config:     config,
+		signingKey: signingKey[:],
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// Presign validates the file against the upload limits and returns a URL the browser
+// can PUT it to. The signature covers the size and content type, so the bucket rejects
+// anything else.
+func (d *DirectUploadService) Presign(req DirectUploadRequest) (*DirectUploadTicket, error) {
+	if d == nil || d.uploads == nil {
+		return nil, ErrDirectUploadsDisabled
+	}
+	if req.Size <= 0 {
+		return nil, ErrUploadMissing
+	}
+
+	ext := strings.ToLower(filepath.Ext(req.Filename))
+	category, ok := d.uploads.detectCategory(ext)
+	if !ok {
+		return nil, ErrUnsupportedUpload
+	}
+	var (
+		maxSize      int64
+		allowedTypes []string
+	)
+	switch category {
+	case UploadCategoryVideo:
+		maxSize, allowedTypes = d.uploads.videoMaxSize, d.uploads.videoAllowedMimeTypes
+	case UploadCategoryFile:
+		maxSize, allowedTypes = d.uploads.fileMaxSize, d.uploads.fileAllowedMimeTypes
+	default:
+		// Images are sanitised and resized after upload, which needs them on disk.
+		return nil, ErrDirectUploadCategory
+	}
+	if req.Size > maxSize {
+		return nil, ErrUploadTooLarge
+	}
+
+	contentType, _, err := mime.ParseMediaType(strings.TrimSpace(req.ContentType))
+	if err != nil {
+		return nil, ErrUnsupportedUpload
+	}
+	if d.uploads.validateMimeType && len(allowedTypes) > 0 && !d.uploads.isAllowedType(contentType, allowedTypes) {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedUpload, contentType)
+	}
+
+	base := strings.TrimSpace(req.Name)
+	if base == "" {
+		base = strings.TrimSuffix(filepath.Base(req.Filename), filepath.Ext(req.Filename))
+	}
+	slug := utils.GenerateSlug(base)
+	suffix := strings.ReplaceAll(uuid.New().String(), "-", "")[:8]
+	filename := suffix + ext
+	if slug != "" {
+		filename = slug + "-" + filename
+	}
+	key := path.Join(d.config.Prefix, filename)
+
+	now := time.Now().UTC()
+	headers := map[string]string{
+		"Content-Length": strconv.FormatInt(req.Size, 10),
+		"Content-Type":   contentType,
+	}
+	uploadURL := d.presign(http.MethodPut, key, headers, now)
+
+	expiresAt := now.Add(d.config.Expiry)
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, directUploadClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Audience:  jwt.ClaimStrings{directUploadAudience},
+			ExpiresAt: jwt.NewNumericDate(expiresAt.Add(d.config.Expiry)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+		Type:        directUploadTokenType,
+		Key:         key,
+		Filename:    filename,
+		ContentType: contentType,
+		Category:    string(category),
+		Size:        req.Size,
+	}).SignedString(d.signingKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// Browsers set Content-Length themselves; only Content-Type has to be sent explicitly.
+	return &DirectUploadTicket{
+		UploadURL: uploadURL,
+		Method:    http.MethodPut,
+		Headers:   map[string]string{"Content-Type": contentType},
+		Filename:  filename,
+		URL:       d.publicURL(key),
+		Token:     token,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// Complete checks that the file described by token has arrived in the bucket and
+// registers it in the media library. Completing the same upload twice is harmless.
+func (d *DirectUploadService) Complete(ctx context.Context, token string) (UploadInfo, error) {
+	if d == nil || d.repo == nil {
+		return UploadInfo{}, ErrDirectUploadsDisabled
+	}
+
+	claims := &directUploadClaims{}
+	parsed, err := jwt.ParseWithClaims(strings.TrimSpace(token), claims, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
+		}
+		return d.signingKey, nil
+	}, jwt.WithAudience(directUploadAudience))
+	if err != nil || !parsed.Valid || claims.Type != directUploadTokenType || claims.Key == "" || claims.Filename == "" {
+		return UploadInfo{}, ErrDirectUploadTicket
+	}
+
+	if existing, err := d.repo.GetByFilename(claims.Filename); err == nil {
+		return remoteUploadInfo(*existing), nil
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return UploadInfo{}, err
+	}
+
+	size, err := d.headObject(ctx, claims.Key)
+	if err != nil {
+		return UploadInfo{}, err
+	}
+	if size != claims.Size {
+		return UploadInfo{}, fmt.Errorf("%w: expected %d bytes, found %d", ErrDirectUploadIncomplete, claims.Size, size)
+	}
+
+	record := &models.RemoteUpload{
+		Filename:    claims.Filename,
+		Key:         claims.Key,
+		URL:         d.publicURL(claims.Key),
+		Size:        size,
+		ContentType: claims.ContentType,
+		Category:    claims.Category,
+	}
+	if err := d.repo.Create(record); err != nil {
+		return UploadInfo{}, err
+	}
+
+	logger.Info("Registered direct upload", map[string]interface{}{"filename": record.Filename, "size": record.Size})
+	return remoteUploadInfo(*record), nil
+}
+
+func remoteUploadInfo(record models.RemoteUpload) UploadInfo {
+	return UploadInfo{
+		URL:      record.URL,
+		Filename: record.Filename,
+		Size:     record.Size,
+		ModTime:  record.CreatedAt,
+		Type:     record.Category,
+		Remote:   true,
+	}
+}
+
+// list returns the registered remote uploads.
+func (d *DirectUploadService) list() []UploadInfo {
+	if d == nil || d.repo == nil {
+		return nil
+	}
+	records, err := d.repo.List()
+	if err != nil {
+		logger.Error(err, "Failed to list direct uploads", nil)
+		return nil
+	}
+	uploads := make([]UploadInfo, 0, len(records))
+	for _, record := range records {
+		uploads = append(uploads, remoteUploadInfo(record))
+	}
+	return uploads
+}
+
+// lookupURL returns the remote upload served at rawURL.
+func (d *DirectUploadService) lookupURL(rawURL string) (UploadInfo, bool) {
+	if d == nil || d.repo == nil {
+		return UploadInfo{}, false
+	}
+	trimmed := strings.TrimSpace(rawURL)
+	if !strings.HasPrefix(trimmed, d.publicURL("")) {
+		return UploadInfo{}, false
+	}
+	record, err := d.repo.GetByFilename(path.Base(trimmed))
+	if err != nil || record.URL != trimmed {
+		return UploadInfo{}, false
+	}
+	return remoteUploadInfo(*record), true
+}
+
+// remove deletes a remote upload and reports whether filename was one.
+func (d *DirectUploadService) remove(ctx context.Context, filename string) (bool, error) {
+	if d == nil || d.repo == nil {
+		return false, nil
+	}
+	record, err := d.repo.GetByFilename(filename)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	resp, err := d.do(ctx, http.MethodDelete, record.Key)
+	if err != nil {
+		return true, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound && (resp.StatusCode < 200 || resp.StatusCode >= 300) {
+		return true, fmt.Errorf("object storage delete failed with status %s", resp.Status)
+	}
+	return true, d.repo.Delete(filename)
+}
+
+func (d *DirectUploadService) headObject(ctx context.Context, key string) (int64, error) {
+	resp, err := d.do(ctx, http.MethodHead, key)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return 0, ErrDirectUploadIncomplete
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		return 0, fmt.Errorf("object storage lookup failed with status %s", resp.Status)
+	}
+	return resp.ContentLength, nil
+}
+
+func (d *DirectUploadService) do(ctx context.Context, method, key string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, d.presign(method, key, nil, time.Now().UTC()), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("object storage request failed: %w", err)
+	}
+	if resp.StatusCode >= 400 && resp.StatusCode != http.StatusNotFound {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		resp.Body.Close()
+		return nil, fmt.Errorf("object storage %s failed with status %s: %s", strings.ToLower(method), resp.Status, strings.TrimSpace(string(body)))
+	}
+	return resp, nil
+}
+
+func (d *DirectUploadService) objectURL(key string) *url.URL {
+	scheme := "https"
+	if !d.config.UseSSL {
+		scheme = "http"
+	}
+	return &url.URL{Scheme: scheme, Host: d.config.Endpoint, Path: "/" + path.Join(d.config.Bucket, key)}
+}
+
+func (d *DirectUploadService) publicURL(key string) string {
+	base := d.config.PublicURL
+	if base == "" {
+		base = strings.TrimSuffix(d.objectURL("").String(), "/")
+	}
+	return base + "/" + key
+}
+
+func (d *DirectUploadService) presign(method, key string, headers map[string]string, now time.Time) string {
+	return presignS3URL(method, d.objectURL(key), headers, d.config.Region, d.config.AccessKey, d.config.SecretKey, now, d.config.Expiry)
+}
+
+// presignS3URL builds an AWS Signature Version 4 query-string signed URL. The host and
+// any headers passed in are signed; the payload is not.
+func presignS3URL(method string, target *url.URL, headers map[string]string, region, accessKey, secretKey string, now time.Time, expires time.Duration) string {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	dateStamp := now.UTC().Format("20060102")
+	credentialScope := fmt.Sprintf("%s/%s/s3/aws4_request", dateStamp, region)
+
+	signed := map[string]string{"host": target.Host}
+	for name, value := range headers {
+		signed[strings.ToLower(name)] = strings.TrimSpace(value)
+	}
+	names := make([]string, 0, len(signed))
+	for name := range signed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + signed[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	query := map[string]string{
+		"X-Amz-Algorithm":     "AWS4-HMAC-SHA256",
+		"X-Amz-Credential":    accessKey + "/" + credentialScope,
+		"X-Amz-Date":          amzDate,
+		"X-Amz-Expires":       strconv.Itoa(int(expires.Seconds())),
+		"X-Amz-SignedHeaders": signedHeaders,
+	}
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, awsURIEncode(key, true)+"="+awsURIEncode(query[key], true))
+	}
+	canonicalQuery := strings.Join(pairs, "&")
+	canonicalURI := awsURIEncode(target.Path, false)
+
+	canonicalRequest := strings.Join([]string{
+		method,
+		canonicalURI,
+		canonicalQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		s3UnsignedPayload,
+	}, "\n")
+	hashedCanonicalRequest := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		credentialScope,
+		hex.EncodeToString(hashedCanonicalRequest[:]),
+	}, "\n")
+
+	signature := hmacSHA256Hex(deriveSigningKey(secretKey, dateStamp, region, "s3"), stringToSign)
+	return target.Scheme + "://" + target.Host + canonicalURI + "?" + canonicalQuery + "&X-Amz-Signature=" + signature
+}
+
+// awsURIEncode percent-encodes everything but unreserved characters, as SigV4 requires.
+// Slashes are kept when encoding a path.
+func awsURIEncode(value string, encodeSlash bool) string {
+	var sb strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			sb.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			sb.WriteByte(c)
+		default:
+			fmt.Fprintf(&sb, "%%%02X", c)
+		}
+	}
+	return sb.String()
+}
+
+// SetDirectUploads enables uploading straight to object storage. Remote uploads are
+// listed, used and deleted like files in the upload directory.
+func (s *UploadService) SetDirectUploads(direct *DirectUploadService) {
+	if s == nil {
+		return
+	}
+	s.direct = direct
+}
+
+// DirectUploads returns the object storage upload service, or nil when it is not configured.
+func (s *UploadService) DirectUploads() *DirectUploadService {
+	if s == nil {
+		return nil
+	}
+	return s.direct
+}
@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"constructor-script-backend/internal/background"
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/repository"
+	"constructor-script-backend/pkg/logger"
+	"constructor-script-backend/pkg/mail"
+)
+
+const (
+	defaultEmailDeliveryHistory = 50
+	maxEmailDeliveryHistory     = 500
+	emailDeliveryTimeout        = 30 * time.Second
+	emailDeliveryRetention      = 30 * 24 * time.Hour
+)
+
+var ErrEmailQueueUnavailable = errors.New("email queue not configured")
+
+// SetQueue stores outgoing emails in deliveries and sends them with scheduler.
+// Without a queue, emails are sent at once and failures are only logged.
+func (s *EmailService) SetQueue(deliveries repository.EmailDeliveryRepository, scheduler *background.Scheduler) {
+	if s == nil {
+		return
+	}
+	s.deliveries = deliveries
+	s.scheduler = scheduler
+}
+
+// ResumePending queues deliveries that were still pending when the server stopped.
+func (s *EmailService) ResumePending() {
+	if s == nil || s.deliveries == nil {
+		return
+	}
+
+	deliveries, err := s.deliveries.ListPending()
+	if err != nil {
+		logger.Error(err, "Failed to load pending email deliveries", nil)
+		return
+	}
+
+	now := time.Now().UTC()
+	for _, delivery := range deliveries {
+		delay := time.Duration(0)
+		if delivery.NextAttemptAt != nil && delivery.NextAttemptAt.After(now) {
+			delay = delivery.NextAttemptAt.Sub(now)
+		}
+		s.queue(delivery.ID, delay)
+	}
+}
+
+// Deliveries returns the most recent entries of the send log.
+func (s *EmailService) Deliveries(filter repository.EmailDeliveryFilter) ([]models.EmailDelivery, error) {
+	if s == nil || s.deliveries == nil {
+		return nil, ErrEmailQueueUnavailable
+	}
+	if filter.Limit <= 0 || filter.Limit > maxEmailDeliveryHistory {
+		filter.Limit = defaultEmailDeliveryHistory
+	}
+	filter.Recipient = strings.TrimSpace(filter.Recipient)
+	return s.deliveries.List(filter)
+}
+
+// Resend queues a new delivery with the message of an earlier one.
+func (s *EmailService) Resend(id uint) (*models.EmailDelivery, error) {
+	if s == nil || s.deliveries == nil {
+		return nil, ErrEmailQueueUnavailable
+	}
+
+	original, err := s.deliveries.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery := &models.EmailDelivery{
+		Recipient:   original.Recipient,
+		Subject:     original.Subject,
+		Template:    original.Template,
+		TextBody:    original.TextBody,
+		HTMLBody:    original.HTMLBody,
+		Unsubscribe: original.Unsubscribe,
+		Status:      models.EmailDeliveryPending,
+	}
+	if _, err := s.deliveries.Create(delivery); err != nil {
+		return nil, err
+	}
+
+	s.queue(delivery.ID, 0)
+	return delivery, nil
+}
+
+// PruneDeliveries removes sent and failed deliveries older than 30 days.
+func (s *EmailService) PruneDeliveries(ctx context.Context) (int64, error) {
+	if s == nil || s.deliveries == nil {
+		return 0, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	return s.deliveries.PruneBefore(time.Now().UTC().Add(-emailDeliveryRetention))
+}
+
+// enqueue stores message and hands it to the background scheduler. A message
+// with a key that was queued before is dropped.
+func (s *EmailService) enqueue(message mail.Message, template, key string) error {
+	if s == nil {
+		return mail.ErrNotConfigured
+	}
+	if s.deliveries == nil {
+		return s.deliver(message)
+	}
+	if !s.Enabled() {
+		return mail.ErrNotConfigured
+	}
+
+	delivery := &models.EmailDelivery{
+		Recipient:   strings.TrimSpace(message.To),
+		Subject:     message.Subject,
+		Template:    template,
+		TextBody:    message.Text,
+		HTMLBody:    message.HTML,
+		Unsubscribe: message.Unsubscribe,
+		Status:      models.EmailDeliveryPending,
+	}
+	if key != "" {
+		delivery.DedupKey = &key
+	}
+
+	created, err := s.deliveries.Create(delivery)
+	if err != nil {
+		return fmt.Errorf("failed to queue email: %w", err)
+	}
+	if created {
+		s.queue(delivery.ID, 0)
+	}
+	return nil
+}
+
+// queue hands the delivery to the background scheduler once delay has elapsed. The
+// wait happens outside the scheduler so pending retries don't occupy its workers.
+func (s *EmailService) queue(deliveryID uint, delay time.Duration) {
+	if delay > 0 {
+		time.AfterFunc(delay, func() { s.queue(deliveryID, 0) })
+		return
+	}
+
+	job := background.Job{
+		Name:    fmt.Sprintf("email_delivery_%d", deliveryID),
+		Timeout: emailDeliveryTimeout,
+		Run: func(ctx context.Context) error {
+			return s.deliverQueued(ctx, deliveryID)
+		},
+	}
+
+	if s.scheduler != nil {
+		err := s.scheduler.ScheduleUnique(job)
+		if err == nil || errors.Is(err, background.ErrJobAlreadyScheduled) {
+			return
+		}
+		if !errors.Is(err, background.ErrSchedulerNotStarted) {
+			logger.Warn("Failed to schedule email delivery", map[string]interface{}{"delivery": deliveryID, "error": err.Error()})
+			return
+		}
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), job.Timeout)
+		defer cancel()
+		if err := job.Run(ctx); err != nil {
+			logger.Error(err, "Email delivery failed", map[string]interface{}{"delivery": deliveryID})
+		}
+	}()
+}
+
+func (s *EmailService) deliverQueued(_ context.Context, deliveryID uint) error {
+	delivery, err := s.deliveries.GetByID(deliveryID)
+	if err != nil {
+		return err
+	}
+	if delivery.Status != models.EmailDeliveryPending {
+		return nil
+	}
+
+	delivery.Attempts++
+	sendErr := s.deliver(mail.Message{
+		To:          delivery.Recipient,
+		Subject:     delivery.Subject,
+		Text:        delivery.TextBody,
+		HTML:        delivery.HTMLBody,
+		Unsubscribe: delivery.Unsubscribe,
+	})
+	now := time.Now().UTC()
+
+	if sendErr == nil {
+		delivery.Status = models.EmailDeliverySent
+		delivery.Error = ""
+		delivery.NextAttemptAt = nil
+		delivery.SentAt = &now
+		return s.deliveries.Update(delivery)
+	}
+
+	delivery.Error = sendErr.Error()
+	if errors.Is(sendErr, ErrRecipientSuppressed) {
+		delivery.Status = models.EmailDeliverySuppressed
+		delivery.NextAttemptAt = nil
+		return s.deliveries.Update(delivery)
+	}
+	if mail.Permanent(sendErr) || delivery.Attempts >= mail.MaxAttempts {
+		delivery.Status = models.EmailDeliveryFailed
+		delivery.NextAttemptAt = nil
+		logger.Warn("Email delivery failed permanently", map[string]interface{}{
+			"delivery": delivery.ID,
+			"template": delivery.Template,
+			"to":       delivery.Recipient,
+			"attempts": delivery.Attempts,
+			"error":    delivery.Error,
+		})
+		return s.deliveries.Update(delivery)
+	}
+
+	delay := mail.RetryDelay(delivery.Attempts)
+	next := now.Add(delay)
+	delivery.NextAttemptAt = &next
+	if err := s.deliveries.Update(delivery); err != nil {
+		return err
+	}
+
+	s.queue(delivery.ID, delay)
+	return nil
+}
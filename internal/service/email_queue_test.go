@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"constructor-script-backend/internal/config"
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/repository"
+	"constructor-script-backend/pkg/mail"
+
+	"gorm.io/gorm"
+)
+
+type memoryEmailDeliveryRepository struct {
+	mu         sync.Mutex
+	deliveries map[uint]models.EmailDelivery
+	nextID     uint
+}
+
+func newMemoryEmailDeliveryRepository() *memoryEmailDeliveryRepository {
+	return &memoryEmailDeliveryRepository{deliveries: make(map[uint]models.EmailDelivery)}
+}
+
+func (r *memoryEmailDeliveryRepository) Create(delivery *models.EmailDelivery) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if delivery.DedupKey != nil {
+		for _, existing := range r.deliveries {
+			if existing.DedupKey != nil && *existing.DedupKey == *delivery.DedupKey {
+				return false, nil
+			}
+		}
+	}
+	r.nextID++
+	delivery.ID = r.nextID
+	r.deliveries[delivery.ID] = *delivery
+	return true, nil
+}
+
+func (r *memoryEmailDeliveryRepository) Update(delivery *models.EmailDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deliveries[delivery.ID] = *delivery
+	return nil
+}
+
+func (r *memoryEmailDeliveryRepository) GetByID(id uint) (*models.EmailDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delivery, ok := r.deliveries[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &delivery, nil
+}
+
+func (r *memoryEmailDeliveryRepository) List(repository.EmailDeliveryFilter) ([]models.EmailDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	result := make([]models.EmailDelivery, 0, len(r.deliveries))
+	for _, delivery := range r.deliveries {
+		result = append(result, delivery)
+	}
+	return result, nil
+}
+
+func (r *memoryEmailDeliveryRepository) ListPending() ([]models.EmailDelivery, error) {
+	return nil, nil
+}
+
+func (r *memoryEmailDeliveryRepository) LatestSent(recipient string) (*models.EmailDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var latest *models.EmailDelivery
+	for _, delivery := range r.deliveries {
+		if strings.EqualFold(delivery.Recipient, recipient) && delivery.Status == models.EmailDeliverySent &&
+			(latest == nil || delivery.ID > latest.ID) {
+			delivery := delivery
+			latest = &delivery
+		}
+	}
+	if latest == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return latest, nil
+}
+
+func (r *memoryEmailDeliveryRepository) PruneBefore(time.Time) (int64, error) {
+	return 0, nil
+}
+
+func newQueuedEmailService(send func(mail.Config, mail.Message) error) (*EmailService, *memoryEmailDeliveryRepository) {
+	svc := NewEmailService(&config.Config{
+		EnableEmail:  true,
+		SMTPHost:     "smtp.example.com",
+		SMTPUsername: "mailer",
+		SMTPPassword: "secret",
+	}, nil, nil)
+	svc.send = send
+	repo := newMemoryEmailDeliveryRepository()
+	svc.SetQueue(repo, nil)
+	return svc, repo
+}
+
+func TestSendTemplateOnceQueuesOneDelivery(t *testing.T) {
+	sent := make(chan mail.Message, 2)
+	svc, repo := newQueuedEmailService(func(_ mail.Config, message mail.Message) error {
+		sent <- message
+		return nil
+	})
+
+	data := map[string]interface{}{"Username": "ada", "ResetURL": "https://example.com/reset", "ExpiresInMinutes": 30}
+	for i := 0; i < 2; i++ {
+		if err := svc.SendTemplateOnce("reset:1", "ada@example.com", EmailTemplatePasswordReset, "Reset", data); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	select {
+	case message := <-sent:
+		if message.To != "ada@example.com" || message.HTML == "" || message.Text == "" {
+			t.Fatalf("unexpected message %+v", message)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the queued email to be sent")
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		delivery, _ := repo.GetByID(1)
+		if delivery.Status == models.EmailDeliverySent {
+			if delivery.Template != EmailTemplatePasswordReset || delivery.SentAt == nil {
+				t.Fatalf("unexpected log entry %+v", delivery)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the delivery to be marked sent, got %q", delivery.Status)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if len(repo.deliveries) != 1 || len(sent) != 0 {
+		t.Fatalf("expected the second email with the same key to be dropped, got %d deliveries", len(repo.deliveries))
+	}
+}
+
+func TestQueuedDeliveryRetries(t *testing.T) {
+	var sendErr error
+	svc, repo := newQueuedEmailService(func(mail.Config, mail.Message) error { return sendErr })
+
+	pending := &models.EmailDelivery{Recipient: "ada@example.com", Subject: "Hello", TextBody: "Hi", Status: models.EmailDeliveryPending}
+	if _, err := repo.Create(pending); err != nil {
+		t.Fatal(err)
+	}
+
+	sendErr = errors.New("connection refused")
+	if err := svc.deliverQueued(context.Background(), pending.ID); err != nil {
+		t.Fatal(err)
+	}
+	delivery, _ := repo.GetByID(pending.ID)
+	if delivery.Status != models.EmailDeliveryPending || delivery.Attempts != 1 || delivery.NextAttemptAt == nil {
+		t.Fatalf("expected a temporary failure to be retried, got %+v", delivery)
+	}
+
+	sendErr = &textproto.Error{Code: 550, Msg: "mailbox unavailable"}
+	if err := svc.deliverQueued(context.Background(), pending.ID); err != nil {
+		t.Fatal(err)
+	}
+	delivery, _ = repo.GetByID(pending.ID)
+	if delivery.Status != models.EmailDeliveryFailed || delivery.Attempts != 2 || delivery.NextAttemptAt != nil {
+		t.Fatalf("expected a permanent failure to end the retries, got %+v", delivery)
+	}
+}
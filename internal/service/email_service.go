@@ -1,36 +1,38 @@
 package service
 
 import (
-	"crypto/tls"
-	"errors"
-	"fmt"
-	"net"
-	"net/smtp"
+	"os"
 	"strings"
 	"time"
 
+	"constructor-script-backend/internal/background"
 	"constructor-script-backend/internal/config"
 	"constructor-script-backend/internal/repository"
+	"constructor-script-backend/internal/theme"
 	"constructor-script-backend/pkg/logger"
+	"constructor-script-backend/pkg/mail"
 )
 
+// EmailService renders themed emails and sends them through pkg/mail. With a
+// queue set, Send and SendTemplate store each message and deliver it in the
+// background with retries, keeping a send log.
 type EmailService struct {
-	config      *config.Config
-	settingRepo repository.SettingRepository
+	config       *config.Config
+	settingRepo  repository.SettingRepository
+	themeManager *theme.Manager
+
+	deliveries   repository.EmailDeliveryRepository
+	suppressions repository.EmailSuppressionRepository
+	scheduler    *background.Scheduler
+	send         func(mail.Config, mail.Message) error
 }
 
-type emailConfig struct {
-	Host     string
-	Port     string
-	Username string
-	Password string
-	From     string
-}
-
-func NewEmailService(cfg *config.Config, settingRepo repository.SettingRepository) *EmailService {
+func NewEmailService(cfg *config.Config, settingRepo repository.SettingRepository, themeManager *theme.Manager) *EmailService {
 	return &EmailService{
-		config:      cfg,
-		settingRepo: settingRepo,
+		config:       cfg,
+		settingRepo:  settingRepo,
+		themeManager: themeManager,
+		send:         mail.Send,
 	}
 }
 
@@ -38,288 +40,47 @@ func (s *EmailService) Enabled() bool {
 	if s.config != nil && !s.config.EnableEmail {
 		return false
 	}
-	cfg := s.resolveConfig()
-	return cfg.Host != "" && cfg.Username != "" && cfg.Password != ""
+	return s.resolveConfig().Configured()
 }
 
+// Send queues a plain text email.
 func (s *EmailService) Send(to, subject, body string) error {
+	return s.enqueue(mail.Message{To: to, Subject: subject, Text: body}, "", "")
+}
+
+// SendHTML delivers an HTML email with a plain text alternative part at once,
+// without the queue, for callers that keep their own delivery records such as
+// newsletter campaigns.
+func (s *EmailService) SendHTML(to, subject, text, htmlBody string) error {
+	return s.deliver(mail.Message{To: to, Subject: subject, Text: text, HTML: htmlBody})
+}
+
+func (s *EmailService) deliver(message mail.Message) error {
 	if s == nil {
-		return errors.New("email service is disabled or not configured")
+		return mail.ErrNotConfigured
 	}
 
 	cfg := s.resolveConfig()
-	if s.config != nil && !s.config.EnableEmail {
-		logger.Warn("Email service disabled by config flag", map[string]interface{}{
-			"enable_email":      s.config.EnableEmail,
+	if (s.config != nil && !s.config.EnableEmail) || !cfg.Configured() {
+		logger.Warn("Email service disabled: email is turned off or SMTP is not configured", map[string]interface{}{
+			"enable_email":      s.config == nil || s.config.EnableEmail,
 			"smtp_host_set":     strings.TrimSpace(cfg.Host) != "",
-			"smtp_port_set":     strings.TrimSpace(cfg.Port) != "",
 			"smtp_username_set": strings.TrimSpace(cfg.Username) != "",
 			"smtp_password_set": strings.TrimSpace(cfg.Password) != "",
-			"smtp_from_set":     strings.TrimSpace(cfg.From) != "",
-			"to":                strings.TrimSpace(to),
-			"subject":           subject,
-		})
-		return errors.New("email service is disabled or not configured")
-	}
-
-	if strings.TrimSpace(cfg.Host) == "" || strings.TrimSpace(cfg.Username) == "" || strings.TrimSpace(cfg.Password) == "" {
-		logger.Warn("Email service disabled: missing SMTP configuration", map[string]interface{}{
-			"smtp_host_set":     strings.TrimSpace(cfg.Host) != "",
-			"smtp_port_set":     strings.TrimSpace(cfg.Port) != "",
-			"smtp_username_set": strings.TrimSpace(cfg.Username) != "",
-			"smtp_password_set": strings.TrimSpace(cfg.Password) != "",
-			"smtp_from_set":     strings.TrimSpace(cfg.From) != "",
-			"to":                strings.TrimSpace(to),
-			"subject":           subject,
-		})
-		return errors.New("email service is disabled or not configured")
-	}
-
-	start := time.Now()
-
-	addr := net.JoinHostPort(cfg.Host, cfg.Port)
-	auth := smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
-
-	var resolvedIPs []string
-	var dnsLookupErr error
-	if ips, err := net.LookupIP(cfg.Host); err == nil {
-		for i, ip := range ips {
-			if i >= 3 {
-				break
-			}
-			resolvedIPs = append(resolvedIPs, ip.String())
-		}
-	} else {
-		dnsLookupErr = err
-		resolvedIPs = []string{fmt.Sprintf("lookup_error:%s", err.Error())}
-		logger.Warn("SMTP DNS lookup failed", map[string]interface{}{
-			"smtp_host": cfg.Host,
-			"error":     err.Error(),
-		})
-	}
-
-	var builder strings.Builder
-	headers := map[string]string{
-		"From":         cfg.From,
-		"To":           strings.TrimSpace(to),
-		"Subject":      subject,
-		"MIME-Version": "1.0",
-		"Content-Type": "text/plain; charset=UTF-8",
-	}
-
-	for key, value := range headers {
-		builder.WriteString(key)
-		builder.WriteString(": ")
-		builder.WriteString(value)
-		builder.WriteString("\r\n")
-	}
-
-	builder.WriteString("\r\n")
-	builder.WriteString(body)
-
-	dialTimeout := 12 * time.Second
-	overallTimeout := 20 * time.Second
-	messageBytes := []byte(builder.String())
-	messageSize := len(messageBytes)
-	startTLSSupported := false
-	startTLSEnabled := false
-
-	startFields := map[string]interface{}{
-		"smtp_host":             cfg.Host,
-		"smtp_port":             cfg.Port,
-		"smtp_from":             cfg.From,
-		"smtp_username_set":     cfg.Username != "",
-		"smtp_password_set":     cfg.Password != "",
-		"smtp_resolved_ips":     strings.Join(resolvedIPs, ","),
-		"smtp_lookup_error":     "",
-		"smtp_address":          addr,
-		"to":                    strings.TrimSpace(to),
-		"subject":               subject,
-		"message_bytes":         messageSize,
-		"auth_mechanism":        "PLAIN",
-		"dial_timeout_ms":       dialTimeout.Milliseconds(),
-		"overall_timeout_ms":    overallTimeout.Milliseconds(),
-		"from_matches_username": strings.EqualFold(strings.TrimSpace(cfg.From), strings.TrimSpace(cfg.Username)),
-	}
-	if dnsLookupErr != nil {
-		startFields["smtp_lookup_error"] = dnsLookupErr.Error()
-	}
-	logger.Info("Starting SMTP send", startFields)
-
-	dialer := net.Dialer{Timeout: dialTimeout}
-	dialStart := time.Now()
-	conn, dialErr := dialer.Dial("tcp", addr)
-	dialDuration := time.Since(dialStart)
-	if dialErr != nil {
-		fields := map[string]interface{}{
-			"smtp_host":             cfg.Host,
-			"smtp_port":             cfg.Port,
-			"smtp_username_set":     cfg.Username != "",
-			"smtp_password_set":     cfg.Password != "",
-			"smtp_resolved_ips":     strings.Join(resolvedIPs, ","),
-			"smtp_lookup_error":     startFields["smtp_lookup_error"],
-			"dial_timeout_ms":       dialTimeout.Milliseconds(),
-			"dial_duration_ms":      dialDuration.Milliseconds(),
-			"to":                    strings.TrimSpace(to),
-			"subject":               subject,
-			"message_bytes":         messageSize,
-			"smtp_address":          addr,
-			"auth_mechanism":        "PLAIN",
-			"from_matches_username": strings.EqualFold(strings.TrimSpace(cfg.From), strings.TrimSpace(cfg.Username)),
-		}
-		logger.Error(dialErr, "SMTP dial failed", fields)
-		return fmt.Errorf("failed to dial SMTP server: %w", dialErr)
-	}
-	if err := conn.SetDeadline(time.Now().Add(overallTimeout)); err != nil {
-		logger.Warn("Failed to apply SMTP deadline", map[string]interface{}{
-			"smtp_address":     addr,
-			"overall_timeout":  overallTimeout.String(),
-			"deadline_error":   err.Error(),
-			"dial_duration_ms": dialDuration.Milliseconds(),
-		})
-	}
-
-	client, clientErr := smtp.NewClient(conn, cfg.Host)
-	if clientErr != nil {
-		_ = conn.Close()
-		logger.Error(clientErr, "Failed to create SMTP client", map[string]interface{}{
-			"smtp_host":        cfg.Host,
-			"smtp_port":        cfg.Port,
-			"smtp_address":     addr,
-			"dial_duration_ms": dialDuration.Milliseconds(),
+			"to":                strings.TrimSpace(message.To),
+			"subject":           message.Subject,
 		})
-		return fmt.Errorf("failed to create SMTP client: %w", clientErr)
+		return mail.ErrNotConfigured
 	}
-	defer client.Close()
-
-	tlsConfig := &tls.Config{ServerName: cfg.Host}
-	if ok, _ := client.Extension("STARTTLS"); ok {
-		startTLSSupported = true
-		if startTLSErr := client.StartTLS(tlsConfig); startTLSErr != nil {
-			logger.Error(startTLSErr, "SMTP STARTTLS failed", map[string]interface{}{
-				"smtp_host":        cfg.Host,
-				"smtp_port":        cfg.Port,
-				"smtp_address":     addr,
-				"dial_duration_ms": dialDuration.Milliseconds(),
-			})
-			return fmt.Errorf("failed to start TLS for SMTP: %w", startTLSErr)
-		}
-		startTLSEnabled = true
-	}
-
-	if auth != nil {
-		if authErr := client.Auth(auth); authErr != nil {
-			fields := map[string]interface{}{
-				"smtp_host":          cfg.Host,
-				"smtp_port":          cfg.Port,
-				"smtp_username_set":  cfg.Username != "",
-				"dial_duration_ms":   dialDuration.Milliseconds(),
-				"smtp_address":       addr,
-				"auth_mechanism":     "PLAIN",
-				"starttls_enabled":   startTLSEnabled,
-				"starttls_supported": startTLSSupported,
-			}
-			logger.Error(authErr, "SMTP authentication failed", fields)
-			return fmt.Errorf("smtp authentication failed: %w", authErr)
-		}
+	if s.suppressed(message.To) {
+		return ErrRecipientSuppressed
 	}
 
-	mailFrom := strings.TrimSpace(cfg.From)
-	recipient := strings.TrimSpace(to)
-
-	if err := client.Mail(mailFrom); err != nil {
-		logger.Error(err, "SMTP MAIL FROM failed", map[string]interface{}{
-			"smtp_host":        cfg.Host,
-			"smtp_port":        cfg.Port,
-			"smtp_address":     addr,
-			"from":             mailFrom,
-			"dial_duration_ms": dialDuration.Milliseconds(),
-		})
-		return fmt.Errorf("failed to set mail sender: %w", err)
-	}
-
-	if err := client.Rcpt(recipient); err != nil {
-		logger.Error(err, "SMTP RCPT TO failed", map[string]interface{}{
-			"smtp_host":        cfg.Host,
-			"smtp_port":        cfg.Port,
-			"smtp_address":     addr,
-			"recipient":        recipient,
-			"dial_duration_ms": dialDuration.Milliseconds(),
-		})
-		return fmt.Errorf("failed to set mail recipient: %w", err)
-	}
-
-	writer, dataErr := client.Data()
-	if dataErr != nil {
-		logger.Error(dataErr, "SMTP DATA command failed", map[string]interface{}{
-			"smtp_host":        cfg.Host,
-			"smtp_port":        cfg.Port,
-			"smtp_address":     addr,
-			"dial_duration_ms": dialDuration.Milliseconds(),
-		})
-		return fmt.Errorf("failed to start SMTP data transmission: %w", dataErr)
-	}
-
-	if _, err := writer.Write(messageBytes); err != nil {
-		_ = writer.Close()
-		logger.Error(err, "SMTP DATA write failed", map[string]interface{}{
-			"smtp_host":        cfg.Host,
-			"smtp_port":        cfg.Port,
-			"smtp_address":     addr,
-			"dial_duration_ms": dialDuration.Milliseconds(),
-			"message_bytes":    messageSize,
-		})
-		return fmt.Errorf("failed to write SMTP message: %w", err)
-	}
-	if err := writer.Close(); err != nil {
-		logger.Error(err, "SMTP DATA close failed", map[string]interface{}{
-			"smtp_host":        cfg.Host,
-			"smtp_port":        cfg.Port,
-			"smtp_address":     addr,
-			"dial_duration_ms": dialDuration.Milliseconds(),
-			"message_bytes":    messageSize,
-		})
-		return fmt.Errorf("failed to finalize SMTP message: %w", err)
-	}
-
-	if err := client.Quit(); err != nil {
-		logger.Warn("SMTP QUIT returned error", map[string]interface{}{
-			"smtp_host":        cfg.Host,
-			"smtp_port":        cfg.Port,
-			"smtp_address":     addr,
-			"dial_duration_ms": dialDuration.Milliseconds(),
-			"quit_error":       err.Error(),
-		})
-	}
-
-	duration := time.Since(start)
-	fields := map[string]interface{}{
-		"smtp_host":             cfg.Host,
-		"smtp_port":             cfg.Port,
-		"smtp_from":             cfg.From,
-		"smtp_username_set":     cfg.Username != "",
-		"smtp_password_set":     cfg.Password != "",
-		"smtp_resolved_ips":     strings.Join(resolvedIPs, ","),
-		"smtp_lookup_error":     startFields["smtp_lookup_error"],
-		"smtp_address":          addr,
-		"duration_ms":           duration.Milliseconds(),
-		"dial_timeout_ms":       dialTimeout.Milliseconds(),
-		"dial_duration_ms":      dialDuration.Milliseconds(),
-		"to":                    recipient,
-		"subject":               subject,
-		"message_bytes":         messageSize,
-		"auth_mechanism":        "PLAIN",
-		"from_matches_username": strings.EqualFold(strings.TrimSpace(cfg.From), strings.TrimSpace(cfg.Username)),
-		"starttls_supported":    startTLSSupported,
-		"starttls_enabled":      startTLSEnabled,
-	}
-
-	logger.Info("Email sent via SMTP", fields)
-	return nil
+	return s.send(cfg, message)
 }
 
-func (s *EmailService) resolveConfig() emailConfig {
-	result := emailConfig{Port: "587"}
+func (s *EmailService) resolveConfig() mail.Config {
+	result := mail.Config{Port: "587"}
 	if s == nil {
 		return result
 	}
@@ -372,3 +133,35 @@ func (s *EmailService) readSetting(key string) string {
 
 	return strings.TrimSpace(setting.Value)
 }
+
+// siteMeta returns the site name and base URL used when rendering email templates.
+func (s *EmailService) siteMeta() (string, string) {
+	siteName := ""
+	baseURL := ""
+	if s != nil && s.config != nil {
+		siteName = strings.TrimSpace(s.config.SiteName)
+		baseURL = strings.TrimRight(strings.TrimSpace(s.config.SiteURL), "/")
+	}
+
+	if value := s.readSetting(settingKeySiteName); value != "" {
+		siteName = value
+	}
+	if value := strings.TrimRight(s.readSetting(settingKeySiteURL), "/"); value != "" {
+		baseURL = value
+	}
+
+	if siteName == "" {
+		siteName = "Constructor Script"
+	}
+	if baseURL == "" {
+		baseURL = "http://localhost:8081"
+	}
+	return siteName, baseURL
+}
+
+func (s *EmailService) assetModTime(path string) (time.Time, error) {
+	if s == nil || s.themeManager == nil {
+		return time.Time{}, os.ErrNotExist
+	}
+	return s.themeManager.AssetModTime(path)
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/repository"
+	"constructor-script-backend/pkg/logger"
+	"constructor-script-backend/pkg/mail"
+
+	"gorm.io/gorm"
+)
+
+const maxEmailSuppressions = 500
+
+var (
+	// ErrRecipientSuppressed is returned for emails to an address on the
+	// suppression list.
+	ErrRecipientSuppressed       = errors.New("recipient is on the email suppression list")
+	ErrEmailSuppressionsDisabled = errors.New("email suppression list not configured")
+)
+
+var snsConfirmClient = &http.Client{Timeout: 10 * time.Second}
+
+// SetSuppressionList configures the addresses no email is sent to.
+func (s *EmailService) SetSuppressionList(suppressions repository.EmailSuppressionRepository) {
+	if s == nil {
+		return
+	}
+	s.suppressions = suppressions
+}
+
+// suppressed reports whether to is on the suppression list. When the list
+// cannot be read the email is sent.
+func (s *EmailService) suppressed(to string) bool {
+	if s == nil || s.suppressions == nil {
+		return false
+	}
+	suppressed, err := s.suppressions.Suppressed(to)
+	if err != nil {
+		logger.Warn("Failed to check email suppression list", map[string]interface{}{"to": to, "error": err.Error()})
+		return false
+	}
+	return suppressed
+}
+
+// HandleFeedback records the bounces and complaints a mail provider reported.
+// The newest email sent to each recipient is marked in the send log, and
+// addresses that bounced permanently or complained are suppressed. It returns
+// how many events were recorded.
+func (s *EmailService) HandleFeedback(ctx context.Context, feedback mail.Feedback) (int, error) {
+	if s == nil || s.suppressions == nil {
+		return 0, ErrEmailSuppressionsDisabled
+	}
+
+	if feedback.SubscribeURL != "" {
+		if err := confirmSNSSubscription(ctx, feedback.SubscribeURL); err != nil {
+			return 0, err
+		}
+	}
+
+	recorded := 0
+	for _, event := range feedback.Events {
+		if err := ctx.Err(); err != nil {
+			return recorded, err
+		}
+
+		status := models.EmailDeliveryBounced
+		if event.Type == mail.EventComplaint {
+			status = models.EmailDeliveryComplained
+		}
+		detail := event.Reason
+		if event.Type == mail.EventBounce && !event.Permanent {
+			detail = strings.TrimSpace("temporary bounce: " + detail)
+		}
+
+		if s.deliveries != nil {
+			delivery, err := s.deliveries.LatestSent(event.Recipient)
+			switch {
+			case err == nil:
+				delivery.Status = status
+				delivery.Error = detail
+				if err := s.deliveries.Update(delivery); err != nil {
+					return recorded, err
+				}
+			case !errors.Is(err, gorm.ErrRecordNotFound):
+				return recorded, err
+			}
+		}
+
+		if event.Permanent {
+			reason := models.EmailSuppressionBounce
+			if event.Type == mail.EventComplaint {
+				reason = models.EmailSuppressionComplaint
+			}
+			if err := s.suppressions.Save(&models.EmailSuppression{
+				Email:  event.Recipient,
+				Reason: reason,
+				Detail: detail,
+			}); err != nil {
+				return recorded, err
+			}
+			logger.Info("Suppressed email address", map[string]interface{}{"to": event.Recipient, "reason": reason})
+		}
+		recorded++
+	}
+	return recorded, nil
+}
+
+// Suppressions lists the suppressed addresses, optionally those containing
+// email.
+func (s *EmailService) Suppressions(email string) ([]models.EmailSuppression, error) {
+	if s == nil || s.suppressions == nil {
+		return nil, ErrEmailSuppressionsDisabled
+	}
+	return s.suppressions.List(email, maxEmailSuppressions)
+}
+
+// Suppression returns the suppression of an address, or nil when it may be
+// emailed.
+func (s *EmailService) Suppression(email string) (*models.EmailSuppression, error) {
+	if s == nil || s.suppressions == nil {
+		return nil, ErrEmailSuppressionsDisabled
+	}
+	suppression, err := s.suppressions.Get(email)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return suppression, err
+}
+
+// Suppress adds an address to the suppression list by hand.
+func (s *EmailService) Suppress(req models.CreateEmailSuppressionRequest) (*models.EmailSuppression, error) {
+	if s == nil || s.suppressions == nil {
+		return nil, ErrEmailSuppressionsDisabled
+	}
+	suppression := &models.EmailSuppression{
+		Email:  req.Email,
+		Reason: models.EmailSuppressionManual,
+		Detail: strings.TrimSpace(req.Detail),
+	}
+	if err := s.suppressions.Save(suppression); err != nil {
+		return nil, err
+	}
+	return s.suppressions.Get(suppression.Email)
+}
+
+// Unsuppress removes an address from the suppression list, so it is emailed
+// again.
+func (s *EmailService) Unsuppress(id uint) error {
+	if s == nil || s.suppressions == nil {
+		return ErrEmailSuppressionsDisabled
+	}
+	return s.suppressions.Delete(id)
+}
+
+// confirmSNSSubscription visits the confirmation link Amazon SNS sends when the
+// webhook is subscribed to a topic. Only SNS links are followed.
+func confirmSNSSubscription(ctx context.Context, subscribeURL string) error {
+	parsed, err := url.Parse(subscribeURL)
+	if err != nil || parsed.Scheme != "https" ||
+		!strings.HasPrefix(parsed.Hostname(), "sns.") || !strings.HasSuffix(parsed.Hostname(), ".amazonaws.com") {
+		return fmt.Errorf("refusing to confirm SNS subscription at %q", subscribeURL)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, parsed.String(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := snsConfirmClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to confirm SNS subscription: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to confirm SNS subscription: status %d", resp.StatusCode)
+	}
+	logger.Info("Confirmed SNS subscription for email feedback", map[string]interface{}{"topic_host": parsed.Hostname()})
+	return nil
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/pkg/mail"
+
+	"gorm.io/gorm"
+)
+
+type memoryEmailSuppressionRepository struct {
+	suppressions map[string]models.EmailSuppression
+}
+
+func (r *memoryEmailSuppressionRepository) Get(email string) (*models.EmailSuppression, error) {
+	suppression, ok := r.suppressions[strings.ToLower(email)]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &suppression, nil
+}
+
+func (r *memoryEmailSuppressionRepository) Suppressed(email string) (bool, error) {
+	_, ok := r.suppressions[strings.ToLower(email)]
+	return ok, nil
+}
+
+func (r *memoryEmailSuppressionRepository) Save(suppression *models.EmailSuppression) error {
+	suppression.Email = strings.ToLower(suppression.Email)
+	suppression.ID = uint(len(r.suppressions) + 1)
+	r.suppressions[suppression.Email] = *suppression
+	return nil
+}
+
+func (r *memoryEmailSuppressionRepository) List(string, int) ([]models.EmailSuppression, error) {
+	return nil, nil
+}
+
+func (r *memoryEmailSuppressionRepository) Delete(id uint) error {
+	for email, suppression := range r.suppressions {
+		if suppression.ID == id {
+			delete(r.suppressions, email)
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
+
+func TestHandleFeedbackSuppressesDeadAddresses(t *testing.T) {
+	sent := 0
+	svc, repo := newQueuedEmailService(func(mail.Config, mail.Message) error {
+		sent++
+		return nil
+	})
+	suppressions := &memoryEmailSuppressionRepository{suppressions: map[string]models.EmailSuppression{}}
+	svc.SetSuppressionList(suppressions)
+
+	for _, recipient := range []string{"gone@example.com", "busy@example.com"} {
+		delivery := &models.EmailDelivery{Recipient: recipient, Subject: "Hello", TextBody: "Hi", Status: models.EmailDeliveryPending}
+		if _, err := repo.Create(delivery); err != nil {
+			t.Fatal(err)
+		}
+		if err := svc.deliverQueued(context.Background(), delivery.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	recorded, err := svc.HandleFeedback(context.Background(), mail.Feedback{Events: []mail.Event{
+		{Type: mail.EventBounce, Recipient: "Gone@example.com", Permanent: true, Reason: "550 user unknown"},
+		{Type: mail.EventBounce, Recipient: "busy@example.com", Reason: "mailbox full"},
+	}})
+	if err != nil || recorded != 2 {
+		t.Fatalf("expected two events to be recorded, got %d, %v", recorded, err)
+	}
+
+	gone, _ := repo.GetByID(1)
+	if gone.Status != models.EmailDeliveryBounced || gone.Error != "550 user unknown" {
+		t.Fatalf("expected the send log to show the bounce, got %+v", gone)
+	}
+	busy, _ := repo.GetByID(2)
+	if busy.Status != models.EmailDeliveryBounced || !strings.HasPrefix(busy.Error, "temporary bounce") {
+		t.Fatalf("expected the send log to show the temporary bounce, got %+v", busy)
+	}
+	if suppression, _ := svc.Suppression("gone@example.com"); suppression == nil || suppression.Reason != models.EmailSuppressionBounce {
+		t.Fatalf("expected a hard bounce to suppress the address, got %+v", suppression)
+	}
+	if suppression, _ := svc.Suppression("busy@example.com"); suppression != nil {
+		t.Fatalf("expected a temporary bounce not to suppress the address, got %+v", suppression)
+	}
+
+	again := &models.EmailDelivery{Recipient: "gone@example.com", Subject: "Hello", TextBody: "Hi", Status: models.EmailDeliveryPending}
+	if _, err := repo.Create(again); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.deliverQueued(context.Background(), again.ID); err != nil {
+		t.Fatal(err)
+	}
+	delivery, _ := repo.GetByID(again.ID)
+	if delivery.Status != models.EmailDeliverySuppressed || sent != 2 {
+		t.Fatalf("expected no email to a suppressed address, got %+v after %d sends", delivery, sent)
+	}
+	if err := svc.SendHTML("gone@example.com", "News", "Hi", "<p>Hi</p>"); !errors.Is(err, ErrRecipientSuppressed) {
+		t.Fatalf("expected direct sends to be suppressed too, got %v", err)
+	}
+
+	suppression, _ := svc.Suppression("gone@example.com")
+	if err := svc.Unsuppress(suppression.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.SendHTML("gone@example.com", "News", "Hi", "<p>Hi</p>"); err != nil || sent != 3 {
+		t.Fatalf("expected the address to be emailed again, got %v", err)
+	}
+}
+
+func TestConfirmSNSSubscriptionOnlyFollowsSNS(t *testing.T) {
+	for _, link := range []string{
+		"http://sns.eu-west-1.amazonaws.com/?Action=ConfirmSubscription",
+		"https://example.com/?Action=ConfirmSubscription",
+		"https://sns.eu-west-1.amazonaws.com.example.com/",
+	} {
+		if err := confirmSNSSubscription(context.Background(), link); err == nil {
+			t.Fatalf("expected %s to be refused", link)
+		}
+	}
+}
@@ -0,0 +1,415 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html"
+	"html/template"
+	"regexp"
+	"strings"
+
+	"constructor-script-backend/pkg/mail"
+	"constructor-script-backend/pkg/utils"
+)
+
+const (
+	EmailTemplateWelcome             = "welcome"
+	EmailTemplatePasswordReset       = "password_reset"
+	EmailTemplateCommentNotification = "comment_notification"
+	EmailTemplateCourseAccessExpiry  = "course_access_expiring"
+	EmailTemplateNewsletterConfirm   = "newsletter_confirm"
+	EmailTemplateNewsletterCampaign  = "newsletter_campaign"
+	EmailTemplateProductDownload     = "product_download"
+	EmailTemplateForumAnswer         = "forum_answer_notification"
+	EmailTemplateCourseReceipt       = "course_receipt"
+	EmailTemplateBackupAlert         = "backup_alert"
+	EmailTemplateActivityDigest      = "activity_digest"
+	EmailTemplateAdminAlert          = "admin_alert"
+	EmailTemplateCourseAnnouncement  = "course_announcement"
+
+	emailLayoutTemplate  = "layout"
+	emailLayoutBlock     = "email-layout"
+	emailSubjectBlock    = "email-subject"
+	emailTextBlock       = "email-text"
+	emailTemplateRootKey = "email"
+)
+
+var ErrEmailTemplateNotFound = errors.New("email template not found")
+
+// EmailMessage is a rendered email ready to be delivered.
+type EmailMessage struct {
+	Subject string
+	HTML    string
+	Text    string
+}
+
+// builtinEmailTemplates are used whenever the active theme does not ship its own
+// templates/emails/<name>.html file.
+var builtinEmailTemplates = map[string]string{
+	emailLayoutTemplate: `{{ define "email-layout" }}<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="UTF-8">
+<meta name="viewport" content="width=device-width, initial-scale=1.0">
+<title>{{ .Subject }}</title>
+</head>
+<body style="margin:0;padding:0;background:#f4f5f7;font-family:Arial,Helvetica,sans-serif;color:#1f2933;">
+<table role="presentation" width="100%" cellpadding="0" cellspacing="0" style="background:#f4f5f7;padding:24px 0;">
+<tr><td align="center">
+<table role="presentation" width="600" cellpadding="0" cellspacing="0" style="max-width:600px;width:100%;background:#ffffff;border-radius:8px;">
+<tr><td style="padding:24px 32px;border-bottom:1px solid #e4e7eb;font-size:20px;font-weight:bold;">
+<a href="{{ .Site.URL }}" style="color:#1f2933;text-decoration:none;">{{ .Site.Name }}</a>
+</td></tr>
+<tr><td style="padding:32px;font-size:15px;line-height:1.6;">
+{{ template "email-content" . }}
+</td></tr>
+<tr><td style="padding:16px 32px;border-top:1px solid #e4e7eb;font-size:12px;color:#7b8794;">
+You are receiving this email because of your account at <a href="{{ .Site.URL }}" style="color:#7b8794;">{{ .Site.Name }}</a>.
+</td></tr>
+</table>
+</td></tr>
+</table>
+</body>
+</html>{{ end }}`,
+
+	EmailTemplateWelcome: `{{ define "email-subject" }}Welcome to {{ .Site.Name }}{{ end }}
+{{ define "email-content" }}
+<p>Hi {{ .Username }},</p>
+<p>Thanks for joining {{ .Site.Name }}. Your account is ready to use.</p>
+<p><a href="{{ absURL "/login" }}" style="display:inline-block;padding:10px 18px;background:#2563eb;color:#ffffff;border-radius:4px;text-decoration:none;">Sign in</a></p>
+{{ end }}`,
+
+	EmailTemplatePasswordReset: `{{ define "email-subject" }}Reset your {{ .Site.Name }} password{{ end }}
+{{ define "email-content" }}
+<p>We received a request to reset your password for {{ .Site.Name }}.</p>
+<p>Use the button below to set a new password. The link will expire in {{ .ExpiresInMinutes }} minutes.</p>
+<p><a href="{{ .ResetURL }}" style="display:inline-block;padding:10px 18px;background:#2563eb;color:#ffffff;border-radius:4px;text-decoration:none;">Reset password</a></p>
+<p style="font-size:13px;color:#52606d;">If the button does not work, copy this link into your browser:<br>{{ .ResetURL }}</p>
+<p>If you did not request this, you can ignore this email.</p>
+{{ end }}
+{{ define "email-text" }}We received a request to reset your password for {{ .Site.Name }}.
+
+Use the link below to set a new password. The link will expire in {{ .ExpiresInMinutes }} minutes.
+
+{{ .ResetURL }}
+
+If you did not request this, you can ignore this email.{{ end }}`,
+
+	EmailTemplateCommentNotification: `{{ define "email-subject" }}New comment on "{{ .PostTitle }}"{{ end }}
+{{ define "email-content" }}
+<p>{{ .CommenterName }} left a new comment on <strong>{{ .PostTitle }}</strong>:</p>
+<blockquote style="margin:16px 0;padding:12px 16px;border-left:3px solid #cbd2d9;background:#f9fafb;">{{ .CommentContent }}</blockquote>
+<p><a href="{{ absURL .PostPath }}" style="color:#2563eb;">View the discussion</a></p>
+{{ end }}`,
+
+	EmailTemplateCourseAccessExpiry: `{{ define "email-subject" }}Your access to "{{ .CourseTitle }}" expires soon{{ end }}
+{{ define "email-content" }}
+<p>Hi {{ .Username }},</p>
+<p>Your access to <strong>{{ .CourseTitle }}</strong> expires on {{ .ExpiresAt }}.</p>
+<p><a href="{{ absURL .CoursePath }}" style="display:inline-block;padding:10px 18px;background:#2563eb;color:#ffffff;border-radius:4px;text-decoration:none;">Continue the course</a></p>
+{{ end }}`,
+
+	EmailTemplateNewsletterConfirm: `{{ define "email-subject" }}Confirm your subscription to {{ .Site.Name }}{{ end }}
+{{ define "email-content" }}
+<p>{{ if .Name }}Hi {{ .Name }},{{ else }}Hi,{{ end }}</p>
+<p>Please confirm that you want to receive the {{ .Site.Name }} newsletter.</p>
+<p><a href="{{ .ConfirmURL }}" style="display:inline-block;padding:10px 18px;background:#2563eb;color:#ffffff;border-radius:4px;text-decoration:none;">Confirm subscription</a></p>
+<p style="font-size:13px;color:#52606d;">If you did not sign up, you can ignore this email and you will not be subscribed.</p>
+{{ end }}
+{{ define "email-text" }}Please confirm that you want to receive the {{ .Site.Name }} newsletter:
+
+{{ .ConfirmURL }}
+
+If you did not sign up, you can ignore this email and you will not be subscribed.{{ end }}`,
+
+	EmailTemplateNewsletterCampaign: `{{ define "email-subject" }}{{ .CampaignSubject }}{{ end }}
+{{ define "email-content" }}
+{{ .Content }}
+{{ if .Posts }}
+<h2 style="margin:32px 0 16px;font-size:18px;">Latest posts</h2>
+{{ range .Posts }}
+<div style="margin:0 0 20px;">
+<a href="{{ absURL .Path }}" style="font-size:16px;font-weight:bold;color:#2563eb;text-decoration:none;">{{ .Title }}</a>
+{{ if .Excerpt }}<p style="margin:6px 0 0;color:#52606d;">{{ .Excerpt }}</p>{{ end }}
+</div>
+{{ end }}
+{{ end }}
+<p style="margin-top:32px;font-size:12px;color:#7b8794;">Don't want these emails? <a href="{{ .UnsubscribeURL }}" style="color:#7b8794;">Unsubscribe</a>.</p>
+<img src="{{ .OpenURL }}" width="1" height="1" alt="" style="display:block;border:0;">
+{{ end }}`,
+
+	EmailTemplateProductDownload: `{{ define "email-subject" }}Your download of {{ .ProductTitle }} is ready{{ end }}
+{{ define "email-content" }}
+<p>Thank you for your purchase of <strong>{{ .ProductTitle }}</strong>.</p>
+<p><a href="{{ .DownloadURL }}" style="display:inline-block;padding:10px 18px;background:#2563eb;color:#ffffff;border-radius:4px;text-decoration:none;">Download now</a></p>
+<p style="font-size:13px;color:#52606d;">This link is valid for {{ .LinkHours }} hours{{ if .DownloadLimit }} and {{ .DownloadLimit }} downloads{{ end }}. If it expires, reply to this email and we will send a new one.</p>
+{{ end }}
+{{ define "email-text" }}Thank you for your purchase of {{ .ProductTitle }}.
+
+Download it here:
+{{ .DownloadURL }}
+
+This link is valid for {{ .LinkHours }} hours{{ if .DownloadLimit }} and {{ .DownloadLimit }} downloads{{ end }}.{{ end }}`,
+
+	EmailTemplateForumAnswer: `{{ define "email-subject" }}New answer to "{{ .QuestionTitle }}"{{ end }}
+{{ define "email-content" }}
+<p>Hi {{ .Username }},</p>
+<p>{{ .AnswererName }} answered your question <strong>{{ .QuestionTitle }}</strong>:</p>
+<blockquote style="margin:16px 0;padding:12px 16px;border-left:3px solid #cbd2d9;background:#f9fafb;">{{ .AnswerContent }}</blockquote>
+<p><a href="{{ absURL .QuestionPath }}" style="color:#2563eb;">View the question</a></p>
+{{ end }}
+{{ define "email-text" }}Hi {{ .Username }},
+
+{{ .AnswererName }} answered your question "{{ .QuestionTitle }}":
+
+{{ .AnswerContent }}
+
+{{ absURL .QuestionPath }}{{ end }}`,
+
+	EmailTemplateCourseReceipt: `{{ define "email-subject" }}Your receipt for {{ .CourseTitle }}{{ end }}
+{{ define "email-content" }}
+<p>Hi {{ .Username }},</p>
+<p>Thank you for your purchase. You now have access to <strong>{{ .CourseTitle }}</strong>.</p>
+<table role="presentation" cellpadding="0" cellspacing="0" style="margin:16px 0;font-size:14px;">
+<tr><td style="padding:4px 16px 4px 0;color:#52606d;">Course</td><td>{{ .CourseTitle }}</td></tr>
+{{ if .Amount }}<tr><td style="padding:4px 16px 4px 0;color:#52606d;">Amount</td><td>{{ .Amount }}</td></tr>{{ end }}
+<tr><td style="padding:4px 16px 4px 0;color:#52606d;">Date</td><td>{{ .PurchasedAt }}</td></tr>
+<tr><td style="padding:4px 16px 4px 0;color:#52606d;">Reference</td><td>{{ .Reference }}</td></tr>
+</table>
+<p><a href="{{ absURL .CoursePath }}" style="display:inline-block;padding:10px 18px;background:#2563eb;color:#ffffff;border-radius:4px;text-decoration:none;">Start the course</a></p>
+{{ end }}
+{{ define "email-text" }}Hi {{ .Username }},
+
+Thank you for your purchase. You now have access to {{ .CourseTitle }}.
+
+Course: {{ .CourseTitle }}{{ if .Amount }}
+Amount: {{ .Amount }}{{ end }}
+Date: {{ .PurchasedAt }}
+Reference: {{ .Reference }}
+
+{{ absURL .CoursePath }}{{ end }}`,
+
+	EmailTemplateBackupAlert: `{{ define "email-subject" }}{{ .Title }}{{ end }}
+{{ define "email-content" }}
+<p>{{ .Summary }}</p>
+<table role="presentation" cellpadding="0" cellspacing="0" style="margin:16px 0;font-size:14px;">
+{{ if .Archive }}<tr><td style="padding:4px 16px 4px 0;color:#52606d;">Archive</td><td>{{ .Archive }}</td></tr>{{ end }}
+<tr><td style="padding:4px 16px 4px 0;color:#52606d;">Time</td><td>{{ .OccurredAt }}</td></tr>
+<tr><td style="padding:4px 16px 4px 0;color:#52606d;">Error</td><td>{{ .Error }}</td></tr>
+</table>
+{{ end }}
+{{ define "email-text" }}{{ .Summary }}
+{{ if .Archive }}
+Archive: {{ .Archive }}{{ end }}
+Time: {{ .OccurredAt }}
+Error: {{ .Error }}{{ end }}`,
+
+	EmailTemplateCourseAnnouncement: `{{ define "email-subject" }}{{ .CourseTitle }}: {{ .Title }}{{ end }}
+{{ define "email-content" }}
+<p>Hi {{ .Username }},</p>
+<p>There is a new announcement in <strong>{{ .CourseTitle }}</strong>.</p>
+<h2 style="margin:24px 0 12px;font-size:18px;">{{ .Title }}</h2>
+{{ if .Body }}<p style="white-space:pre-line;">{{ .Body }}</p>{{ end }}
+<p><a href="{{ absURL .CoursePath }}" style="display:inline-block;padding:10px 18px;background:#2563eb;color:#ffffff;border-radius:4px;text-decoration:none;">Open the course</a></p>
+{{ end }}
+{{ define "email-text" }}Hi {{ .Username }},
+
+There is a new announcement in {{ .CourseTitle }}.
+
+{{ .Title }}
+{{ if .Body }}
+{{ .Body }}
+{{ end }}
+{{ absURL .CoursePath }}{{ end }}`,
+
+	EmailTemplateAdminAlert: `{{ define "email-subject" }}{{ .Title }}{{ end }}
+{{ define "email-content" }}
+<p><strong>{{ .Title }}</strong></p>
+<p>{{ .Text }}</p>
+{{ end }}
+{{ define "email-text" }}{{ .Title }}
+
+{{ .Text }}{{ end }}`,
+
+	EmailTemplateActivityDigest: `{{ define "email-subject" }}Your {{ .Frequency }} digest from {{ .Site.Name }}{{ end }}
+{{ define "email-content" }}
+<p>Hi {{ .Username }},</p>
+<p>Here is what happened on {{ .Site.Name }} since your last digest.</p>
+{{ range .Sections }}
+<h2 style="margin:28px 0 12px;font-size:18px;">{{ .Title }}</h2>
+{{ range .Items }}
+<div style="margin:0 0 16px;">
+<a href="{{ absURL .Path }}" style="font-size:15px;font-weight:bold;color:#2563eb;text-decoration:none;">{{ .Title }}</a>
+{{ if .Summary }}<p style="margin:4px 0 0;color:#52606d;">{{ .Summary }}</p>{{ end }}
+</div>
+{{ end }}
+{{ end }}
+<p style="margin-top:32px;font-size:12px;color:#7b8794;">You get this digest {{ .Frequency }}. <a href="{{ absURL "/profile" }}" style="color:#7b8794;">Change how often</a> or <a href="{{ .UnsubscribeURL }}" style="color:#7b8794;">unsubscribe</a>.</p>
+{{ end }}
+{{ define "email-text" }}Hi {{ .Username }},
+
+Here is what happened on {{ .Site.Name }} since your last digest.
+{{ range .Sections }}
+{{ .Title }}
+{{ range .Items }}
+- {{ .Title }}{{ if .Summary }}: {{ .Summary }}{{ end }}
+  {{ absURL .Path }}{{ end }}
+{{ end }}
+You get this digest {{ .Frequency }}. To unsubscribe, open {{ .UnsubscribeURL }}{{ end }}`,
+}
+
+var (
+	emailTagPattern        = regexp.MustCompile(`(?s)<(style|head|title)[^>]*>.*?</(style|head|title)>|<[^>]+>`)
+	emailBlankLinesPattern = regexp.MustCompile(`\n{3,}`)
+)
+
+// RenderTemplate renders a named email using the active theme's templates/emails
+// directory, falling back to the built-in layout and content for anything the theme
+// does not provide. Site metadata is injected under the "Site" key.
+func (s *EmailService) RenderTemplate(name string, data map[string]interface{}) (*EmailMessage, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || name == emailLayoutTemplate {
+		return nil, ErrEmailTemplateNotFound
+	}
+
+	layoutSource, ok := s.emailTemplateSource(emailLayoutTemplate)
+	if !ok {
+		return nil, ErrEmailTemplateNotFound
+	}
+	contentSource, ok := s.emailTemplateSource(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrEmailTemplateNotFound, name)
+	}
+
+	siteName, siteURL := s.siteMeta()
+	values := make(map[string]interface{}, len(data)+2)
+	for key, value := range data {
+		values[key] = value
+	}
+	values["Site"] = map[string]string{"Name": siteName, "URL": siteURL}
+
+	funcs := utils.GetTemplateFuncs(s.assetModTime)
+	funcs["absURL"] = func(path string) string {
+		trimmed := strings.TrimSpace(path)
+		if strings.HasPrefix(trimmed, "http://") || strings.HasPrefix(trimmed, "https://") {
+			return trimmed
+		}
+		return siteURL + "/" + strings.TrimLeft(trimmed, "/")
+	}
+
+	tmpl := template.New(emailTemplateRootKey).Funcs(funcs)
+	if _, err := tmpl.Parse(layoutSource); err != nil {
+		return nil, fmt.Errorf("failed to parse email layout: %w", err)
+	}
+	if _, err := tmpl.Parse(contentSource); err != nil {
+		return nil, fmt.Errorf("failed to parse email template %s: %w", name, err)
+	}
+
+	message := &EmailMessage{}
+	if tmpl.Lookup(emailSubjectBlock) != nil {
+		subject, err := executeEmailBlock(tmpl, emailSubjectBlock, values)
+		if err != nil {
+			return nil, err
+		}
+		message.Subject = strings.Join(strings.Fields(html.UnescapeString(subject)), " ")
+	}
+	values["Subject"] = message.Subject
+
+	body, err := executeEmailBlock(tmpl, emailLayoutBlock, values)
+	if err != nil {
+		return nil, err
+	}
+	message.HTML = body
+
+	if tmpl.Lookup(emailTextBlock) != nil {
+		text, err := executeEmailBlock(tmpl, emailTextBlock, values)
+		if err != nil {
+			return nil, err
+		}
+		message.Text = strings.TrimSpace(html.UnescapeString(text))
+	} else {
+		message.Text = htmlToPlainText(body)
+	}
+
+	return message, nil
+}
+
+// SendTemplate renders a named email and queues it as multipart HTML with a plain
+// text alternative. fallbackSubject is used when the template does not define one.
+func (s *EmailService) SendTemplate(to, name, fallbackSubject string, data map[string]interface{}) error {
+	return s.SendTemplateOnce("", to, name, fallbackSubject, data)
+}
+
+// SendTemplateOnce is SendTemplate for emails that must go out once per key, such
+// as a receipt per checkout session, even when the caller runs more than once.
+// The key is only remembered while the queue keeps the delivery.
+func (s *EmailService) SendTemplateOnce(key, to, name, fallbackSubject string, data map[string]interface{}) error {
+	return s.sendTemplate(key, to, name, fallbackSubject, "", data)
+}
+
+// SendSubscriptionTemplate is SendTemplateOnce for emails the recipient signed up
+// for. unsubscribeURL is announced to mail clients for one-click unsubscribe and
+// must accept a POST.
+func (s *EmailService) SendSubscriptionTemplate(key, to, name, fallbackSubject, unsubscribeURL string, data map[string]interface{}) error {
+	return s.sendTemplate(key, to, name, fallbackSubject, unsubscribeURL, data)
+}
+
+func (s *EmailService) sendTemplate(key, to, name, fallbackSubject, unsubscribeURL string, data map[string]interface{}) error {
+	message, err := s.RenderTemplate(name, data)
+	if err != nil {
+		return err
+	}
+
+	subject := message.Subject
+	if subject == "" {
+		subject = fallbackSubject
+	}
+
+	return s.enqueue(mail.Message{
+		To:          to,
+		Subject:     subject,
+		Text:        message.Text,
+		HTML:        message.HTML,
+		Unsubscribe: unsubscribeURL,
+	}, name, key)
+}
+
+// SiteURL returns the base URL used for absolute links in emails.
+func (s *EmailService) SiteURL() string {
+	_, siteURL := s.siteMeta()
+	return siteURL
+}
+
+func (s *EmailService) emailTemplateSource(name string) (string, bool) {
+	if s != nil && s.themeManager != nil {
+		if source, ok := s.themeManager.Active().ReadEmailTemplate(name); ok {
+			return source, true
+		}
+	}
+	source, ok := builtinEmailTemplates[name]
+	return source, ok
+}
+
+func executeEmailBlock(tmpl *template.Template, block string, data interface{}) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, block, data); err != nil {
+		return "", fmt.Errorf("failed to render email block %s: %w", block, err)
+	}
+	return buf.String(), nil
+}
+
+func htmlToPlainText(source string) string {
+	replacer := strings.NewReplacer("<br>", "\n", "<br/>", "\n", "<br />", "\n", "</p>", "\n\n", "</tr>", "\n", "</blockquote>", "\n\n")
+	text := emailTagPattern.ReplaceAllString(replacer.Replace(source), "")
+	text = html.UnescapeString(text)
+
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.Join(strings.Fields(line), " ")
+	}
+
+	text = strings.Join(lines, "\n")
+	text = emailBlankLinesPattern.ReplaceAllString(text, "\n\n")
+	return strings.TrimSpace(text)
+}
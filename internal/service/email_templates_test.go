@@ -0,0 +1,82 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"constructor-script-backend/internal/config"
+	"constructor-script-backend/internal/theme"
+)
+
+func TestRenderTemplateUsesBuiltinDefaults(t *testing.T) {
+	svc := NewEmailService(&config.Config{SiteName: "Example", SiteURL: "https://example.com/"}, nil, nil)
+
+	message, err := svc.RenderTemplate(EmailTemplatePasswordReset, map[string]interface{}{
+		"ResetURL":         "https://example.com/reset-password?token=abc",
+		"ExpiresInMinutes": 60,
+	})
+	if err != nil {
+		t.Fatalf("unexpected render error: %v", err)
+	}
+
+	if message.Subject != "Reset your Example password" {
+		t.Fatalf("unexpected subject %q", message.Subject)
+	}
+	if !strings.Contains(message.HTML, `href="https://example.com/reset-password?token=abc"`) {
+		t.Fatalf("expected reset link in html body, got %s", message.HTML)
+	}
+	if !strings.Contains(message.Text, "https://example.com/reset-password?token=abc") {
+		t.Fatalf("expected reset link in text body, got %s", message.Text)
+	}
+}
+
+func TestRenderTemplatePrefersThemeTemplates(t *testing.T) {
+	base := t.TempDir()
+	emailsDir := filepath.Join(base, "custom", "templates", "emails")
+	if err := os.MkdirAll(emailsDir, 0o755); err != nil {
+		t.Fatalf("failed to create theme templates: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(base, "custom", "static"), 0o755); err != nil {
+		t.Fatalf("failed to create theme static dir: %v", err)
+	}
+	welcome := `{{ define "email-subject" }}Hello from {{ .Site.Name }}{{ end }}{{ define "email-content" }}<p>Custom {{ upper .Username }}</p>{{ end }}`
+	if err := os.WriteFile(filepath.Join(emailsDir, "welcome.html"), []byte(welcome), 0o644); err != nil {
+		t.Fatalf("failed to write welcome template: %v", err)
+	}
+
+	manager, err := theme.NewManager(base)
+	if err != nil {
+		t.Fatalf("failed to load themes: %v", err)
+	}
+	if err := manager.Activate("custom"); err != nil {
+		t.Fatalf("failed to activate theme: %v", err)
+	}
+
+	svc := NewEmailService(&config.Config{SiteName: "Example"}, nil, manager)
+	message, err := svc.RenderTemplate(EmailTemplateWelcome, map[string]interface{}{"Username": "ada"})
+	if err != nil {
+		t.Fatalf("unexpected render error: %v", err)
+	}
+
+	if message.Subject != "Hello from Example" {
+		t.Fatalf("unexpected subject %q", message.Subject)
+	}
+	if !strings.Contains(message.HTML, "<p>Custom ADA</p>") {
+		t.Fatalf("expected theme content in html body, got %s", message.HTML)
+	}
+	if !strings.Contains(message.HTML, "<!DOCTYPE html>") {
+		t.Fatalf("expected built-in layout to wrap theme content")
+	}
+	if !strings.Contains(message.Text, "Custom ADA") || strings.Contains(message.Text, "<") {
+		t.Fatalf("unexpected text body %q", message.Text)
+	}
+}
+
+func TestRenderTemplateUnknownName(t *testing.T) {
+	svc := NewEmailService(nil, nil, nil)
+	if _, err := svc.RenderTemplate("missing", nil); err == nil {
+		t.Fatalf("expected error for unknown template")
+	}
+}
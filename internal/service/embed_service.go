@@ -0,0 +1,341 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"constructor-script-backend/pkg/cache"
+	"constructor-script-backend/pkg/logger"
+)
+
+var (
+	ErrUnsupportedEmbed = errors.New("embeds are supported for YouTube, Vimeo and Twitter/X links")
+	ErrEmbedUnavailable = errors.New("the embed provider could not resolve this link")
+)
+
+const (
+	embedCacheTTL        = 24 * time.Hour
+	embedFailureTTL      = 10 * time.Minute
+	embedRequestTimeout  = 5 * time.Second
+	embedMaxResponseSize = 1 << 20
+	embedMemoryCacheSize = 512
+	embedCacheKeyPrefix  = "embed:"
+)
+
+// Embed is the resolved preview of an external link, as rendered by the embed element.
+type Embed struct {
+	URL          string `json:"url"`
+	Provider     string `json:"provider"`
+	ProviderName string `json:"provider_name"`
+	// Type is "video" for players and "rich" for posts rendered as quotes.
+	Type         string `json:"type"`
+	Title        string `json:"title,omitempty"`
+	AuthorName   string `json:"author_name,omitempty"`
+	AuthorURL    string `json:"author_url,omitempty"`
+	ThumbnailURL string `json:"thumbnail_url,omitempty"`
+	Width        int    `json:"width,omitempty"`
+	Height       int    `json:"height,omitempty"`
+	// PlayerURL is the privacy-friendly iframe source for video embeds. It is built
+	// by the server rather than taken from the provider's markup.
+	PlayerURL string `json:"player_url,omitempty"`
+	// Text is the plain text of a rich embed, such as the body of a post.
+	Text string `json:"text,omitempty"`
+}
+
+type embedProvider struct {
+	name     string
+	label    string
+	kind     string
+	hosts    []string
+	endpoint string
+	player   func(link *url.URL, response oembedResponse) string
+}
+
+type oembedResponse struct {
+	Title        string          `json:"title"`
+	AuthorName   string          `json:"author_name"`
+	AuthorURL    string          `json:"author_url"`
+	ThumbnailURL string          `json:"thumbnail_url"`
+	Width        json.Number     `json:"width"`
+	Height       json.Number     `json:"height"`
+	HTML         string          `json:"html"`
+	VideoID      json.RawMessage `json:"video_id"`
+}
+
+var youtubeIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{6,20}$`)
+
+var embedProviders = []embedProvider{
+	{
+		name:     "youtube",
+		label:    "YouTube",
+		kind:     "video",
+		hosts:    []string{"youtube.com", "www.youtube.com", "m.youtube.com", "youtu.be"},
+		endpoint: "https://www.youtube.com/oembed",
+		player: func(link *url.URL, _ oembedResponse) string {
+			id := youtubeVideoID(link)
+			if id == "" {
+				return ""
+			}
+			return "https://www.youtube-nocookie.com/embed/" + id + "?autoplay=1"
+		},
+	},
+	{
+		name:     "vimeo",
+		label:    "Vimeo",
+		kind:     "video",
+		hosts:    []string{"vimeo.com", "www.vimeo.com", "player.vimeo.com"},
+		endpoint: "https://vimeo.com/api/oembed.json",
+		player: func(_ *url.URL, response oembedResponse) string {
+			id := strings.Trim(string(response.VideoID), `"`)
+			if _, err := strconv.ParseUint(id, 10, 64); err != nil {
+				return ""
+			}
+			return "https://player.vimeo.com/video/" + id + "?dnt=1&autoplay=1"
+		},
+	},
+	{
+		name:     "twitter",
+		label:    "X (Twitter)",
+		kind:     "rich",
+		hosts:    []string{"twitter.com", "www.twitter.com", "mobile.twitter.com", "x.com", "www.x.com"},
+		endpoint: "https://publish.twitter.com/oembed?omit_script=true&dnt=true",
+	},
+}
+
+type embedCacheEntry struct {
+	embed   *Embed
+	err     error
+	expires time.Time
+}
+
+// EmbedService resolves links to YouTube, Vimeo and Twitter/X through their oEmbed
+// endpoints. Results are cached in memory and, when enabled, in the shared cache.
+type EmbedService struct {
+	client *http.Client
+	cache  *cache.Cache
+
+	mu      sync.Mutex
+	entries map[string]embedCacheEntry
+}
+
+func NewEmbedService(cacheService *cache.Cache) *EmbedService {
+	return &EmbedService{
+		client:  &http.Client{Timeout: embedRequestTimeout},
+		cache:   cacheService,
+		entries: make(map[string]embedCacheEntry),
+	}
+}
+
+// Resolve returns the embed for rawURL, fetching it from the provider on a cache miss.
+func (s *EmbedService) Resolve(ctx context.Context, rawURL string) (*Embed, error) {
+	if s == nil {
+		return nil, ErrEmbedUnavailable
+	}
+
+	link, provider, err := matchEmbedProvider(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	normalized := link.String()
+	key := embedCacheKey(normalized)
+
+	s.mu.Lock()
+	entry, ok := s.entries[key]
+	s.mu.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		return entry.embed, entry.err
+	}
+
+	if s.cache != nil {
+		var cached Embed
+		if err := s.cache.Get(key, &cached); err == nil && cached.URL != "" {
+			s.remember(key, &cached, nil, embedCacheTTL)
+			return &cached, nil
+		}
+	}
+
+	embed, err := s.fetch(ctx, link, provider)
+	if err != nil {
+		logger.Warn("Failed to resolve embed", map[string]interface{}{"url": normalized, "error": err.Error()})
+		// Failures are remembered briefly so a broken link does not hit the provider on
+		// every page view, but are not shared, so other instances retry on their own.
+		s.remember(key, nil, ErrEmbedUnavailable, embedFailureTTL)
+		return nil, ErrEmbedUnavailable
+	}
+
+	s.remember(key, embed, nil, embedCacheTTL)
+	if s.cache != nil {
+		if err := s.cache.Set(key, embed, embedCacheTTL); err != nil {
+			logger.Debug("Failed to cache embed", map[string]interface{}{"url": normalized, "error": err.Error()})
+		}
+	}
+	return embed, nil
+}
+
+func (s *EmbedService) fetch(ctx context.Context, link *url.URL, provider embedProvider) (*Embed, error) {
+	endpoint, err := url.Parse(provider.endpoint)
+	if err != nil {
+		return nil, err
+	}
+	query := endpoint.Query()
+	query.Set("url", link.String())
+	query.Set("format", "json")
+	endpoint.RawQuery = query.Encode()
+
+	ctx, cancel := context.WithTimeout(ctx, embedRequestTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("provider responded with status %d", resp.StatusCode)
+	}
+
+	var response oembedResponse
+	decoder := json.NewDecoder(io.LimitReader(resp.Body, embedMaxResponseSize))
+	decoder.UseNumber()
+	if err := decoder.Decode(&response); err != nil {
+		return nil, fmt.Errorf("decode oembed response: %w", err)
+	}
+
+	embed := &Embed{
+		URL:          link.String(),
+		Provider:     provider.name,
+		ProviderName: provider.label,
+		Type:         provider.kind,
+		Title:        strings.TrimSpace(response.Title),
+		AuthorName:   strings.TrimSpace(response.AuthorName),
+		AuthorURL:    safeEmbedURL(response.AuthorURL),
+		ThumbnailURL: safeEmbedURL(response.ThumbnailURL),
+	}
+	if width, err := response.Width.Int64(); err == nil && width > 0 {
+		embed.Width = int(width)
+	}
+	if height, err := response.Height.Int64(); err == nil && height > 0 {
+		embed.Height = int(height)
+	}
+
+	if provider.player != nil {
+		embed.PlayerURL = provider.player(link, response)
+		if embed.PlayerURL == "" {
+			return nil, errors.New("could not determine the video id")
+		}
+	} else {
+		embed.Text = embedPlainText(response.HTML)
+	}
+	return embed, nil
+}
+
+func (s *EmbedService) remember(key string, embed *Embed, err error, ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.entries) >= embedMemoryCacheSize {
+		now := time.Now()
+		for k, entry := range s.entries {
+			if now.After(entry.expires) {
+				delete(s.entries, k)
+			}
+		}
+		if len(s.entries) >= embedMemoryCacheSize {
+			s.entries = make(map[string]embedCacheEntry)
+		}
+	}
+	s.entries[key] = embedCacheEntry{embed: embed, err: err, expires: time.Now().Add(ttl)}
+}
+
+// matchEmbedProvider parses rawURL and finds the provider that serves it.
+func matchEmbedProvider(rawURL string) (*url.URL, embedProvider, error) {
+	link, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || (link.Scheme != "https" && link.Scheme != "http") || link.Host == "" {
+		return nil, embedProvider{}, ErrUnsupportedEmbed
+	}
+	link.Scheme = "https"
+	link.Host = strings.ToLower(link.Hostname())
+	link.User = nil
+	link.Fragment = ""
+
+	for _, provider := range embedProviders {
+		for _, host := range provider.hosts {
+			if link.Host == host {
+				return link, provider, nil
+			}
+		}
+	}
+	return nil, embedProvider{}, ErrUnsupportedEmbed
+}
+
+func youtubeVideoID(link *url.URL) string {
+	var id string
+	path := strings.Trim(link.Path, "/")
+	switch {
+	case link.Host == "youtu.be":
+		id = path
+	case path == "watch":
+		id = link.Query().Get("v")
+	default:
+		for _, prefix := range []string{"shorts/", "embed/", "live/", "v/"} {
+			if strings.HasPrefix(path, prefix) {
+				id = strings.TrimPrefix(path, prefix)
+				break
+			}
+		}
+	}
+	if !youtubeIDPattern.MatchString(id) {
+		return ""
+	}
+	return id
+}
+
+func safeEmbedURL(raw string) string {
+	parsed, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+		return ""
+	}
+	return parsed.String()
+}
+
+var (
+	embedBreakPattern = regexp.MustCompile(`(?i)<br\s*/?>|</p>`)
+	embedTagPattern   = regexp.MustCompile(`<[^>]*>`)
+	embedSpacePattern = regexp.MustCompile(`[ \t]+`)
+)
+
+// embedPlainText reduces provider markup to its text, so none of it reaches the page.
+func embedPlainText(markup string) string {
+	text := embedBreakPattern.ReplaceAllString(markup, "\n")
+	text = html.UnescapeString(embedTagPattern.ReplaceAllString(text, ""))
+	lines := strings.Split(text, "\n")
+	cleaned := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if line = strings.TrimSpace(embedSpacePattern.ReplaceAllString(line, " ")); line != "" {
+			cleaned = append(cleaned, line)
+		}
+	}
+	return strings.Join(cleaned, "\n")
+}
+
+func embedCacheKey(normalizedURL string) string {
+	sum := sha256.Sum256([]byte(normalizedURL))
+	return embedCacheKeyPrefix + hex.EncodeToString(sum[:16])
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMatchEmbedProvider(t *testing.T) {
+	cases := map[string]string{
+		"https://www.youtube.com/watch?v=dQw4w9WgXcQ": "youtube",
+		"http://youtu.be/dQw4w9WgXcQ":                 "youtube",
+		"https://vimeo.com/76979871":                  "vimeo",
+		"https://x.com/golang/status/1":               "twitter",
+	}
+	for raw, want := range cases {
+		link, provider, err := matchEmbedProvider(raw)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", raw, err)
+		}
+		if provider.name != want || link.Scheme != "https" {
+			t.Fatalf("%s: got provider %q and scheme %q", raw, provider.name, link.Scheme)
+		}
+	}
+
+	for _, raw := range []string{"https://example.com/video", "javascript:alert(1)", "ftp://youtube.com/x"} {
+		if _, _, err := matchEmbedProvider(raw); !errors.Is(err, ErrUnsupportedEmbed) {
+			t.Fatalf("%s: expected ErrUnsupportedEmbed, got %v", raw, err)
+		}
+	}
+}
+
+func TestYouTubeVideoID(t *testing.T) {
+	cases := map[string]string{
+		"https://www.youtube.com/watch?v=dQw4w9WgXcQ&t=10": "dQw4w9WgXcQ",
+		"https://youtu.be/dQw4w9WgXcQ":                     "dQw4w9WgXcQ",
+		"https://www.youtube.com/shorts/dQw4w9WgXcQ":       "dQw4w9WgXcQ",
+		"https://www.youtube.com/watch?v=bad\"id":          "",
+		"https://www.youtube.com/channel/abc":              "",
+	}
+	for raw, want := range cases {
+		link, _, err := matchEmbedProvider(raw)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", raw, err)
+		}
+		if got := youtubeVideoID(link); got != want {
+			t.Fatalf("%s: expected %q, got %q", raw, want, got)
+		}
+	}
+}
+
+func TestEmbedPlainTextDropsMarkup(t *testing.T) {
+	markup := `<blockquote class="twitter-tweet"><p lang="en">Hello <a href="https://t.co/x">world</a> &amp; friends<br>second line</p>&mdash; Gopher (@golang) <a href="https://x.com">May 1</a></blockquote><script src="x.js"></script>`
+	want := "Hello world & friends\nsecond line\n— Gopher (@golang) May 1"
+	if got := embedPlainText(markup); got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
@@ -182,6 +182,20 @@ func (s *FontService) Delete(id string) error {
 	return nil
 }
 
+// Replace stores fonts in place of the configured font assets, keeping their
+// IDs and order.
+func (s *FontService) Replace(fonts []models.FontAsset) ([]models.FontAsset, error) {
+	for _, font := range fonts {
+		if strings.TrimSpace(font.Snippet) == "" {
+			return nil, ErrInvalidFontSnippet
+		}
+	}
+	if err := s.save(cloneFonts(fonts)); err != nil {
+		return nil, err
+	}
+	return s.List()
+}
+
 // Reorder updates the display order for the provided font assets.
 func (s *FontService) Reorder(orders []models.FontAssetOrder) error {
 	if len(orders) == 0 {
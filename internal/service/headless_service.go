@@ -0,0 +1,354 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"constructor-script-backend/internal/background"
+	"constructor-script-backend/internal/events"
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/repository"
+	"constructor-script-backend/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// SettingKeyHeadless stores the headless mode and build hooks in the settings
+	// repository.
+	SettingKeyHeadless = "site.headless"
+
+	// headlessSettingsRefresh bounds how long an instance keeps serving pages after
+	// another instance turned headless mode on.
+	headlessSettingsRefresh = 30 * time.Second
+
+	buildHookJob = "build_hooks"
+	// buildHookDelay collects the changes of a bulk edit into one build.
+	buildHookDelay       = 30 * time.Second
+	buildHookTimeout     = 15 * time.Second
+	buildHookNameLength  = 64
+	buildHookStatusLimit = 512
+	maxBuildHooks        = 10
+)
+
+// buildHookEvents are the content changes that trigger the build hooks.
+var buildHookEvents = []string{
+	events.PostPublished,
+	events.PostUpdated,
+	events.PostDeleted,
+	events.PageCreated,
+	events.PageUpdated,
+	events.PageDeleted,
+}
+
+// HeadlessService keeps the headless mode, read by every page request and so held
+// in memory, and posts to the build hooks a short while after content changes.
+type HeadlessService struct {
+	settingRepo repository.SettingRepository
+	scheduler   *background.Scheduler
+	client      *http.Client
+
+	mu       sync.RWMutex
+	settings models.HeadlessSettings
+	loadedAt time.Time
+
+	buildMu  sync.Mutex
+	pending  []string
+	statuses map[string]models.BuildHookStatus
+}
+
+type HeadlessValidationError struct {
+	Reason string
+}
+
+func (e *HeadlessValidationError) Error() string {
+	if e == nil {
+		return ""
+	}
+	return e.Reason
+}
+
+func headlessValidationErrorf(format string, args ...interface{}) error {
+	return &HeadlessValidationError{Reason: fmt.Sprintf(format, args...)}
+}
+
+// buildHookBody is posted to build hooks. Netlify passes it to the build as
+// INCOMING_HOOK_BODY; other providers ignore it.
+type buildHookBody struct {
+	Trigger string   `json:"trigger"`
+	Events  []string `json:"events,omitempty"`
+}
+
+func NewHeadlessService(repo repository.SettingRepository, scheduler *background.Scheduler) *HeadlessService {
+	return &HeadlessService{
+		settingRepo: repo,
+		scheduler:   scheduler,
+		client:      &http.Client{Timeout: buildHookTimeout},
+		statuses:    make(map[string]models.BuildHookStatus),
+	}
+}
+
+func (s *HeadlessService) GetSettings() (models.HeadlessSettings, error) {
+	defaults := models.HeadlessSettings{BuildHooks: []models.BuildHook{}}
+	if s == nil || s.settingRepo == nil {
+		return defaults, nil
+	}
+
+	stored, err := s.settingRepo.Get(SettingKeyHeadless)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return defaults, nil
+		}
+		return defaults, err
+	}
+	if strings.TrimSpace(stored.Value) == "" {
+		return defaults, nil
+	}
+
+	var settings models.HeadlessSettings
+	if err := json.Unmarshal([]byte(stored.Value), &settings); err != nil {
+		return defaults, fmt.Errorf("failed to decode headless settings: %w", err)
+	}
+	if settings.BuildHooks == nil {
+		settings.BuildHooks = []models.BuildHook{}
+	}
+	return settings, nil
+}
+
+func (s *HeadlessService) UpdateSettings(req models.UpdateHeadlessSettingsRequest) (models.HeadlessSettings, error) {
+	settings := models.HeadlessSettings{Enabled: req.Enabled, BuildHooks: make([]models.BuildHook, 0, len(req.BuildHooks))}
+
+	if frontend := strings.TrimSpace(req.FrontendURL); frontend != "" {
+		normalized, err := normalizeHTTPURL(frontend)
+		if err != nil {
+			return models.HeadlessSettings{}, headlessValidationErrorf("frontend_url must be an absolute http(s) URL")
+		}
+		settings.FrontendURL = strings.TrimSuffix(normalized, "/")
+	}
+
+	if len(req.BuildHooks) > maxBuildHooks {
+		return models.HeadlessSettings{}, headlessValidationErrorf("at most %d build hooks are allowed", maxBuildHooks)
+	}
+	names := make(map[string]bool, len(req.BuildHooks))
+	for index, hook := range req.BuildHooks {
+		hook.Name = strings.TrimSpace(hook.Name)
+		if hook.Name == "" || len(hook.Name) > buildHookNameLength {
+			return models.HeadlessSettings{}, headlessValidationErrorf("build hook %d: name must be 1-%d characters", index+1, buildHookNameLength)
+		}
+		if names[hook.Name] {
+			return models.HeadlessSettings{}, headlessValidationErrorf("build hook %d: name %q is already used", index+1, hook.Name)
+		}
+		names[hook.Name] = true
+
+		normalized, err := normalizeHTTPURL(hook.URL)
+		if err != nil {
+			return models.HeadlessSettings{}, headlessValidationErrorf("build hook %d: url must be an absolute http(s) URL", index+1)
+		}
+		hook.URL = normalized
+		settings.BuildHooks = append(settings.BuildHooks, hook)
+	}
+
+	if s.settingRepo != nil {
+		payload, err := json.Marshal(settings)
+		if err != nil {
+			return settings, fmt.Errorf("failed to encode headless settings: %w", err)
+		}
+		if err := s.settingRepo.Set(SettingKeyHeadless, string(payload)); err != nil {
+			return settings, err
+		}
+	}
+
+	s.mu.Lock()
+	s.settings = settings
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+
+	return settings, nil
+}
+
+func normalizeHTTPURL(value string) (string, error) {
+	parsed, err := url.Parse(strings.TrimSpace(value))
+	if err != nil || parsed.Host == "" || (parsed.Scheme != "https" && parsed.Scheme != "http") {
+		return "", errors.New("invalid url")
+	}
+	return parsed.String(), nil
+}
+
+// HeadlessMode reports whether the public pages are off and where their visitors
+// go instead, for HeadlessMiddleware. When settings cannot be read, the settings
+// loaded last stay in force.
+func (s *HeadlessService) HeadlessMode() (bool, string) {
+	settings := s.currentSettings()
+	return settings.Enabled, settings.FrontendURL
+}
+
+func (s *HeadlessService) currentSettings() models.HeadlessSettings {
+	if s == nil {
+		return models.HeadlessSettings{}
+	}
+
+	s.mu.RLock()
+	settings, loadedAt := s.settings, s.loadedAt
+	s.mu.RUnlock()
+	if !loadedAt.IsZero() && time.Since(loadedAt) < headlessSettingsRefresh {
+		return settings
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.loadedAt.IsZero() && time.Since(s.loadedAt) < headlessSettingsRefresh {
+		return s.settings
+	}
+
+	loaded, err := s.GetSettings()
+	s.loadedAt = time.Now()
+	if err != nil {
+		logger.Error(err, "Failed to load headless settings", nil)
+		return s.settings
+	}
+	s.settings = loaded
+	return s.settings
+}
+
+// BuildHookStatuses reports the last delivery to each configured build hook.
+func (s *HeadlessService) BuildHookStatuses() []models.BuildHookStatus {
+	settings := s.currentSettings()
+
+	s.buildMu.Lock()
+	defer s.buildMu.Unlock()
+	statuses := make([]models.BuildHookStatus, 0, len(settings.BuildHooks))
+	for _, hook := range settings.BuildHooks {
+		status, ok := s.statuses[hook.Name]
+		if !ok {
+			status = models.BuildHookStatus{Name: hook.Name}
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// Subscribe triggers the build hooks after the content events published on bus.
+func (s *HeadlessService) Subscribe(bus *events.Bus) {
+	if s == nil || bus == nil {
+		return
+	}
+	for _, name := range buildHookEvents {
+		bus.Subscribe(name, func(_ context.Context, event events.Event) {
+			s.scheduleBuild(event.Name)
+		})
+	}
+}
+
+func (s *HeadlessService) scheduleBuild(event string) {
+	if len(s.currentSettings().BuildHooks) == 0 {
+		return
+	}
+
+	s.buildMu.Lock()
+	s.pending = appendUnique(s.pending, event)
+	s.buildMu.Unlock()
+	if s.scheduler == nil {
+		return
+	}
+
+	err := s.scheduler.ScheduleUnique(background.Job{
+		Name:    buildHookJob,
+		Delay:   buildHookDelay,
+		Timeout: time.Duration(maxBuildHooks) * buildHookTimeout,
+		Run: func(ctx context.Context) error {
+			s.runBuildHooks(ctx, "content_changed")
+			return nil
+		},
+	})
+	if err != nil && !errors.Is(err, background.ErrJobAlreadyScheduled) {
+		logger.Error(err, "Failed to schedule build hooks", nil)
+	}
+}
+
+// TriggerBuild posts to every build hook now and returns the results.
+func (s *HeadlessService) TriggerBuild(ctx context.Context) []models.BuildHookStatus {
+	s.runBuildHooks(ctx, "manual")
+	return s.BuildHookStatuses()
+}
+
+func (s *HeadlessService) runBuildHooks(ctx context.Context, trigger string) {
+	s.buildMu.Lock()
+	body := buildHookBody{Trigger: trigger, Events: s.pending}
+	s.pending = nil
+	s.buildMu.Unlock()
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		logger.Error(err, "Failed to encode build hook body", nil)
+		return
+	}
+
+	for _, hook := range s.currentSettings().BuildHooks {
+		status := s.postBuildHook(ctx, hook, payload)
+
+		s.buildMu.Lock()
+		s.statuses[hook.Name] = status
+		s.buildMu.Unlock()
+
+		if status.LastError != "" {
+			logger.Warn("Build hook failed", map[string]interface{}{"hook": hook.Name, "error": status.LastError})
+		}
+	}
+
+	// Changes made while the hooks ran were not scheduled, since the job was
+	// still active; they get a build of their own.
+	s.buildMu.Lock()
+	missed := len(s.pending) > 0
+	s.buildMu.Unlock()
+	if missed && trigger != "manual" {
+		time.AfterFunc(buildHookDelay, func() { s.scheduleBuild("") })
+	}
+}
+
+func (s *HeadlessService) postBuildHook(ctx context.Context, hook models.BuildHook, payload []byte) models.BuildHookStatus {
+	now := time.Now().UTC()
+	status := models.BuildHookStatus{Name: hook.Name, LastTriggered: &now}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(payload))
+	if err != nil {
+		status.LastError = err.Error()
+		return status
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "constructor-script-build-hooks")
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		status.LastError = err.Error()
+		return status
+	}
+	defer response.Body.Close()
+
+	status.LastStatus = response.StatusCode
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(response.Body, buildHookStatusLimit))
+		status.LastError = fmt.Sprintf("hook responded with status %d: %s", response.StatusCode, strings.TrimSpace(string(message)))
+	}
+	return status
+}
+
+func appendUnique(values []string, value string) []string {
+	if value == "" {
+		return values
+	}
+	for _, existing := range values {
+		if existing == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"constructor-script-backend/internal/events"
+	"constructor-script-backend/internal/models"
+)
+
+func TestHeadlessServiceStoresSettings(t *testing.T) {
+	repo := &memorySettingRepository{values: make(map[string]string)}
+	svc := NewHeadlessService(repo, nil)
+
+	settings, err := svc.UpdateSettings(models.UpdateHeadlessSettingsRequest{
+		Enabled:     true,
+		FrontendURL: "https://www.example.com/",
+		BuildHooks:  []models.BuildHook{{Name: " netlify ", URL: "https://api.netlify.com/build_hooks/abc"}},
+	})
+	if err != nil {
+		t.Fatalf("UpdateSettings: %v", err)
+	}
+	if settings.FrontendURL != "https://www.example.com" || settings.BuildHooks[0].Name != "netlify" {
+		t.Fatalf("settings not normalized: %+v", settings)
+	}
+
+	reloaded := NewHeadlessService(repo, nil)
+	if enabled, frontend := reloaded.HeadlessMode(); !enabled || frontend != "https://www.example.com" {
+		t.Fatalf("HeadlessMode() = %v, %q", enabled, frontend)
+	}
+}
+
+func TestHeadlessServiceRejectsInvalidSettings(t *testing.T) {
+	svc := NewHeadlessService(&memorySettingRepository{values: make(map[string]string)}, nil)
+	hook := models.BuildHook{Name: "vercel", URL: "https://api.vercel.com/v1/deploy"}
+
+	cases := map[string]models.UpdateHeadlessSettingsRequest{
+		"relative frontend": {FrontendURL: "/app"},
+		"ftp hook":          {BuildHooks: []models.BuildHook{{Name: "a", URL: "ftp://example.com"}}},
+		"unnamed hook":      {BuildHooks: []models.BuildHook{{URL: "https://example.com"}}},
+		"duplicate hook":    {BuildHooks: []models.BuildHook{hook, hook}},
+	}
+	for name, req := range cases {
+		_, err := svc.UpdateSettings(req)
+		var validationErr *HeadlessValidationError
+		if !errors.As(err, &validationErr) {
+			t.Errorf("%s: got %v, want a validation error", name, err)
+		}
+	}
+}
+
+func TestHeadlessServiceTriggerBuildRecordsStatuses(t *testing.T) {
+	var received buildHookBody
+	ok := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer ok.Close()
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad token", http.StatusUnauthorized)
+	}))
+	defer failing.Close()
+
+	svc := NewHeadlessService(&memorySettingRepository{values: make(map[string]string)}, nil)
+	_, err := svc.UpdateSettings(models.UpdateHeadlessSettingsRequest{BuildHooks: []models.BuildHook{
+		{Name: "ok", URL: ok.URL},
+		{Name: "failing", URL: failing.URL},
+	}})
+	if err != nil {
+		t.Fatalf("UpdateSettings: %v", err)
+	}
+
+	// Without a scheduler the event is only recorded for the next build.
+	svc.scheduleBuild(events.PostPublished)
+	statuses := svc.TriggerBuild(context.Background())
+
+	if received.Trigger != "manual" || len(received.Events) != 1 || received.Events[0] != events.PostPublished {
+		t.Fatalf("unexpected hook body %+v", received)
+	}
+	if len(statuses) != 2 || statuses[0].LastStatus != http.StatusCreated || statuses[0].LastError != "" {
+		t.Fatalf("unexpected status of the working hook %+v", statuses)
+	}
+	if statuses[1].LastStatus != http.StatusUnauthorized || statuses[1].LastError == "" || statuses[1].LastTriggered == nil {
+		t.Fatalf("unexpected status of the failing hook %+v", statuses[1])
+	}
+}
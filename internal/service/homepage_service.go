@@ -1,6 +1,7 @@
 package service
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"sort"
@@ -10,6 +11,7 @@ import (
 
 	"constructor-script-backend/internal/models"
 	"constructor-script-backend/internal/repository"
+	"constructor-script-backend/pkg/lang"
 
 	"gorm.io/gorm"
 )
@@ -19,7 +21,10 @@ var (
 	ErrHomepagePageScheduled    = errors.New("scheduled pages cannot be set as the homepage until published")
 )
 
-const settingKeySiteHomepage = "site.homepage_page_id"
+const (
+	settingKeySiteHomepage          = "site.homepage_page_id"
+	settingKeySiteLanguageHomepages = "site.homepage_pages"
+)
 
 type HomepageService struct {
 	settingRepo repository.SettingRepository
@@ -78,29 +83,110 @@ func (s *HomepageService) SetHomepage(pageID uint) (*models.HomepagePage, error)
 		return nil, errors.New("setting repository not configured")
 	}
 
-	page, err := s.pageRepo.GetByID(pageID)
+	page, err := s.homepageCandidate(pageID)
 	if err != nil {
 		return nil, err
 	}
 
-	if !page.Published {
-		return nil, ErrHomepagePageNotPublished
+	if err := s.settingRepo.Set(settingKeySiteHomepage, strconv.FormatUint(uint64(pageID), 10)); err != nil {
+		return nil, err
 	}
-	if page.PublishAt != nil {
-		now := time.Now().UTC()
-		if page.PublishAt.After(now) {
-			return nil, ErrHomepagePageScheduled
-		}
+
+	info := toHomepagePage(page)
+	return &info, nil
+}
+
+// SetLanguageHomepage makes pageID the homepage for visitors reading language.
+// Languages without their own homepage use the one set by SetHomepage.
+func (s *HomepageService) SetLanguageHomepage(language string, pageID uint) (*models.HomepagePage, error) {
+	if s == nil || s.pageRepo == nil {
+		return nil, errors.New("page repository not configured")
+	}
+	if s.settingRepo == nil {
+		return nil, errors.New("setting repository not configured")
 	}
 
-	if err := s.settingRepo.Set(settingKeySiteHomepage, strconv.FormatUint(uint64(pageID), 10)); err != nil {
+	code, err := lang.Normalize(language)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := s.homepageCandidate(pageID)
+	if err != nil {
+		return nil, err
+	}
+
+	selections, err := s.languageHomepageIDs()
+	if err != nil {
+		return nil, err
+	}
+	selections[code] = pageID
+	if err := s.storeLanguageHomepageIDs(selections); err != nil {
 		return nil, err
 	}
 
 	info := toHomepagePage(page)
+	info.Language = code
 	return &info, nil
 }
 
+// ClearLanguageHomepage removes the homepage of language, so it falls back to
+// the site-wide one.
+func (s *HomepageService) ClearLanguageHomepage(language string) error {
+	if s == nil || s.settingRepo == nil {
+		return errors.New("setting repository not configured")
+	}
+
+	code, err := lang.Normalize(language)
+	if err != nil {
+		return err
+	}
+
+	selections, err := s.languageHomepageIDs()
+	if err != nil {
+		return err
+	}
+	if _, ok := selections[code]; !ok {
+		return nil
+	}
+	delete(selections, code)
+	return s.storeLanguageHomepageIDs(selections)
+}
+
+// ListLanguageSelections returns the homepages chosen per language, ordered by
+// language code. Selections whose page was deleted are left out.
+func (s *HomepageService) ListLanguageSelections() ([]models.HomepagePage, error) {
+	if s == nil || s.pageRepo == nil {
+		return nil, errors.New("page repository not configured")
+	}
+
+	selections, err := s.languageHomepageIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, 0, len(selections))
+	for code := range selections {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	result := make([]models.HomepagePage, 0, len(codes))
+	for _, code := range codes {
+		page, err := s.pageRepo.GetByID(selections[code])
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		info := toHomepagePage(page)
+		info.Language = code
+		result = append(result, info)
+	}
+	return result, nil
+}
+
 func (s *HomepageService) ClearHomepage() error {
 	if s == nil || s.settingRepo == nil {
 		return errors.New("setting repository not configured")
@@ -116,23 +202,114 @@ func (s *HomepageService) GetActiveHomepage() (*models.Page, error) {
 	if err != nil {
 		return nil, err
 	}
-	if page == nil {
+	if !isLivePage(page) {
 		return nil, nil
 	}
 
+	return page, nil
+}
+
+// GetActiveHomepageForLanguage returns the published homepage chosen for the
+// best match of language, falling back to the site-wide homepage.
+func (s *HomepageService) GetActiveHomepageForLanguage(language string) (*models.Page, error) {
+	if language == "" {
+		return s.GetActiveHomepage()
+	}
+	if s == nil || s.pageRepo == nil {
+		return nil, errors.New("page repository not configured")
+	}
+
+	selections, err := s.languageHomepageIDs()
+	if err != nil {
+		return nil, err
+	}
+	if len(selections) > 0 {
+		codes := make([]string, 0, len(selections))
+		for code := range selections {
+			codes = append(codes, code)
+		}
+		sort.Strings(codes)
+
+		if match := lang.Match(language, codes); match != "" {
+			page, err := s.pageRepo.GetByID(selections[match])
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, err
+			}
+			if err == nil && isLivePage(page) {
+				return page, nil
+			}
+		}
+	}
+
+	return s.GetActiveHomepage()
+}
+
+// homepageCandidate loads pageID and checks that it can be shown as a homepage.
+func (s *HomepageService) homepageCandidate(pageID uint) (*models.Page, error) {
+	page, err := s.pageRepo.GetByID(pageID)
+	if err != nil {
+		return nil, err
+	}
+
 	if !page.Published {
-		return nil, nil
+		return nil, ErrHomepagePageNotPublished
 	}
 	if page.PublishAt != nil {
 		now := time.Now().UTC()
 		if page.PublishAt.After(now) {
-			return nil, nil
+			return nil, ErrHomepagePageScheduled
 		}
 	}
 
 	return page, nil
 }
 
+func (s *HomepageService) languageHomepageIDs() (map[string]uint, error) {
+	if s == nil || s.settingRepo == nil {
+		return nil, errors.New("setting repository not configured")
+	}
+
+	selections := make(map[string]uint)
+	setting, err := s.settingRepo.Get(settingKeySiteLanguageHomepages)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return selections, nil
+		}
+		return nil, err
+	}
+
+	value := strings.TrimSpace(setting.Value)
+	if value == "" {
+		return selections, nil
+	}
+	if err := json.Unmarshal([]byte(value), &selections); err != nil {
+		return nil, fmt.Errorf("invalid language homepage setting value: %w", err)
+	}
+	return selections, nil
+}
+
+func (s *HomepageService) storeLanguageHomepageIDs(selections map[string]uint) error {
+	if len(selections) == 0 {
+		if err := s.settingRepo.Delete(settingKeySiteLanguageHomepages); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		return nil
+	}
+
+	encoded, err := json.Marshal(selections)
+	if err != nil {
+		return err
+	}
+	return s.settingRepo.Set(settingKeySiteLanguageHomepages, string(encoded))
+}
+
+func isLivePage(page *models.Page) bool {
+	if page == nil || !page.Published {
+		return false
+	}
+	return page.PublishAt == nil || !page.PublishAt.After(time.Now().UTC())
+}
+
 func (s *HomepageService) getStoredPage() (*models.Page, error) {
 	if s == nil || s.settingRepo == nil {
 		return nil, errors.New("setting repository not configured")
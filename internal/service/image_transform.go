@@ -0,0 +1,500 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"constructor-script-backend/pkg/media"
+)
+
+var (
+	ErrInvalidImageTransform = errors.New("invalid image transform")
+	ErrImageTooLarge         = errors.New("requested image dimensions exceed the allowed maximum")
+	ErrImageFormatDisabled   = errors.New("image format is not available")
+	ErrImageSignatureInvalid = errors.New("image transform signature is missing or invalid")
+)
+
+const (
+	defaultImageTransformMaxDimension = 2560
+	// Sources above this pixel count are refused before decoding to bound memory use.
+	maxImageTransformSourcePixels = 50_000_000
+)
+
+// ImageTransform describes an on-demand resize, parsed from a path segment such as
+// "w=640,h=360,fit=cover,f=webp,s=<signature>".
+type ImageTransform struct {
+	Width   int
+	Height  int
+	Fit     media.ImageFit
+	Format  media.ImageFormat
+	Quality int
+	// Signature authorizes the transform for one upload. It is not part of the
+	// canonical form.
+	Signature string
+}
+
+// String returns the canonical form of the transform, used for cache keys and
+// signatures.
+func (t ImageTransform) String() string {
+	parts := make([]string, 0, 5)
+	if t.Width > 0 {
+		parts = append(parts, "w="+strconv.Itoa(t.Width))
+	}
+	if t.Height > 0 {
+		parts = append(parts, "h="+strconv.Itoa(t.Height))
+	}
+	if t.Fit != "" {
+		parts = append(parts, "fit="+string(t.Fit))
+	}
+	if t.Format != "" {
+		parts = append(parts, "f="+string(t.Format))
+	}
+	if t.Quality > 0 {
+		parts = append(parts, "q="+strconv.Itoa(t.Quality))
+	}
+	return strings.Join(parts, ",")
+}
+
+// ImageCache stores transformed images. The disk cache is the default; object storage
+// can be plugged in by implementing the same two methods.
+type ImageCache interface {
+	// Open returns the cached image for key, or os.ErrNotExist.
+	Open(key string) (io.ReadSeekCloser, error)
+	// Store saves the file at path under key. The file may be moved.
+	Store(key, path string) error
+}
+
+// DiskImageCache keeps transformed images in a directory, sharded by key prefix.
+// With a size limit, the least recently used images are removed once the cache
+// outgrows it.
+type DiskImageCache struct {
+	dir      string
+	maxBytes int64
+
+	mu    sync.Mutex
+	size  int64
+	known bool
+}
+
+// NewDiskImageCache returns a cache in dir holding up to maxBytes, or without
+// limit when maxBytes is not positive.
+func NewDiskImageCache(dir string, maxBytes int64) *DiskImageCache {
+	return &DiskImageCache{dir: dir, maxBytes: maxBytes}
+}
+
+func (c *DiskImageCache) Open(key string) (io.ReadSeekCloser, error) {
+	path := c.path(key)
+	file, err := os.Open(path)
+	if err == nil && c.maxBytes > 0 {
+		// The modification time records the last use for eviction.
+		now := time.Now()
+		os.Chtimes(path, now, now)
+	}
+	return file, err
+}
+
+func (c *DiskImageCache) Store(key, path string) error {
+	target := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(path, target); err != nil {
+		return err
+	}
+	if c.maxBytes <= 0 {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.known {
+		// The first store counts what earlier runs left, this image included.
+		c.known = true
+		return c.evict()
+	}
+	c.size += info.Size()
+	if c.size <= c.maxBytes {
+		return nil
+	}
+	return c.evict()
+}
+
+// evict recounts the cache and, when it is over its limit, removes the least
+// recently used images until it is back to nine tenths of it.
+func (c *DiskImageCache) evict() error {
+	type entry struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+	var entries []entry
+	var total int64
+	err := filepath.WalkDir(c.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || strings.HasPrefix(d.Name(), ".tmp-") {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		entries = append(entries, entry{path: path, size: info.Size(), modTime: info.ModTime()})
+		total += info.Size()
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if total > c.maxBytes {
+		sort.Slice(entries, func(i, j int) bool { return entries[i].modTime.Before(entries[j].modTime) })
+		target := c.maxBytes / 10 * 9
+		for _, e := range entries {
+			if total <= target {
+				break
+			}
+			if err := os.Remove(e.path); err == nil || errors.Is(err, os.ErrNotExist) {
+				total -= e.size
+			}
+		}
+	}
+	c.size = total
+	return nil
+}
+
+func (c *DiskImageCache) path(key string) string {
+	return filepath.Join(c.dir, key[:2], key)
+}
+
+// ImageTransformConfig sets the limits of the on-demand resizer.
+type ImageTransformConfig struct {
+	MaxDimension int
+	Quality      int
+	// Concurrency bounds the images rendered at once; it defaults to the
+	// number of CPUs.
+	Concurrency int
+	// CacheMaxBytes bounds the disk cache; zero leaves it unbounded.
+	CacheMaxBytes int64
+	// SigningSecret, when set, makes every transform require a signature for
+	// its upload, so only the URLs the site hands out are rendered.
+	SigningSecret string
+}
+
+// ImageTransformService resizes uploaded images on request and caches the results.
+type ImageTransformService struct {
+	uploadDir    string
+	scratchDir   string
+	cache        ImageCache
+	maxDimension int
+	quality      int
+	signingKey   []byte
+	renders      chan struct{}
+
+	locks sync.Map
+}
+
+func NewImageTransformService(uploadDir, cacheDir string, cfg ImageTransformConfig) *ImageTransformService {
+	if cfg.MaxDimension <= 0 {
+		cfg.MaxDimension = defaultImageTransformMaxDimension
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = runtime.NumCPU()
+	}
+	svc := &ImageTransformService{
+		uploadDir:    uploadDir,
+		scratchDir:   cacheDir,
+		cache:        NewDiskImageCache(cacheDir, cfg.CacheMaxBytes),
+		maxDimension: cfg.MaxDimension,
+		quality:      cfg.Quality,
+		renders:      make(chan struct{}, cfg.Concurrency),
+	}
+	if cfg.SigningSecret != "" {
+		key := sha256.Sum256([]byte("image-transform:" + cfg.SigningSecret))
+		svc.signingKey = key[:]
+	}
+	return svc
+}
+
+// SetCache replaces the cache backend.
+func (s *ImageTransformService) SetCache(cache ImageCache) {
+	if s == nil || cache == nil {
+		return
+	}
+	s.cache = cache
+}
+
+// ParseTransform parses and validates a transform segment. Keys are w (width),
+// h (height), fit (contain or cover), f (jpeg, png, webp or avif), q (quality)
+// and s (signature).
+func (s *ImageTransformService) ParseTransform(raw string) (ImageTransform, error) {
+	var transform ImageTransform
+
+	for _, part := range strings.Split(strings.TrimSpace(raw), ",") {
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return ImageTransform{}, fmt.Errorf("%w: %q", ErrInvalidImageTransform, part)
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+
+		if key == "s" {
+			// Signatures are case sensitive.
+			transform.Signature = strings.TrimSpace(value)
+			continue
+		}
+		value = strings.ToLower(value)
+
+		switch key {
+		case "w", "width", "h", "height", "q", "quality":
+			number, err := strconv.Atoi(value)
+			if err != nil || number <= 0 {
+				return ImageTransform{}, fmt.Errorf("%w: %s must be a positive integer", ErrInvalidImageTransform, key)
+			}
+			switch key[0] {
+			case 'w':
+				transform.Width = number
+			case 'h':
+				transform.Height = number
+			default:
+				if number > 100 {
+					return ImageTransform{}, fmt.Errorf("%w: quality must be between 1 and 100", ErrInvalidImageTransform)
+				}
+				transform.Quality = number
+			}
+		case "fit":
+			switch fit := media.ImageFit(value); fit {
+			case media.ImageFitContain, media.ImageFitCover:
+				transform.Fit = fit
+			default:
+				return ImageTransform{}, fmt.Errorf("%w: unknown fit %q", ErrInvalidImageTransform, value)
+			}
+		case "f", "format":
+			format, ok := media.ImageFormatFromExtension(value)
+			if !ok {
+				return ImageTransform{}, fmt.Errorf("%w: unknown format %q", ErrInvalidImageTransform, value)
+			}
+			transform.Format = format
+		default:
+			return ImageTransform{}, fmt.Errorf("%w: unknown option %q", ErrInvalidImageTransform, key)
+		}
+	}
+
+	if transform.Width == 0 && transform.Height == 0 {
+		return ImageTransform{}, fmt.Errorf("%w: width or height is required", ErrInvalidImageTransform)
+	}
+	if transform.Width > s.maxDimension || transform.Height > s.maxDimension {
+		return ImageTransform{}, ErrImageTooLarge
+	}
+	if transform.Fit == "" {
+		transform.Fit = media.ImageFitContain
+	}
+	if transform.Format != "" && !media.EncoderAvailable(transform.Format) {
+		return ImageTransform{}, fmt.Errorf("%w: %s", ErrImageFormatDisabled, transform.Format)
+	}
+
+	return transform, nil
+}
+
+// URL returns the /img URL rendering the upload at uploadURL with transform,
+// signed when signatures are required. Other URLs and invalid transforms
+// return uploadURL unchanged.
+func (s *ImageTransformService) URL(uploadURL, transform string) string {
+	trimmed := strings.TrimSpace(uploadURL)
+	if s == nil || !strings.HasPrefix(trimmed, "/uploads/") {
+		return uploadURL
+	}
+	parsed, err := s.ParseTransform(transform)
+	if err != nil {
+		return uploadURL
+	}
+	uploadPath := strings.TrimPrefix(trimmed, "/uploads/")
+	segment := parsed.String()
+	if s.signingKey != nil {
+		segment += ",s=" + s.sign(uploadPath, parsed)
+	}
+	return "/img/" + segment + "/" + uploadPath
+}
+
+func (s *ImageTransformService) sign(uploadPath string, transform ImageTransform) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte(transform.String() + "|" + filepath.Clean("/"+strings.TrimSpace(uploadPath))))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:16])
+}
+
+func (s *ImageTransformService) verify(uploadPath string, transform ImageTransform) bool {
+	if s.signingKey == nil {
+		return true
+	}
+	return hmac.Equal([]byte(transform.Signature), []byte(s.sign(uploadPath, transform)))
+}
+
+// TransformedImage is a cached, transformed image ready to be served.
+type TransformedImage struct {
+	Content     io.ReadSeekCloser
+	ContentType string
+	// ETag identifies the source version and transform.
+	ETag string
+}
+
+// Transform returns the image at uploadPath (relative to the upload directory) with
+// transform applied, generating and caching it on first use.
+func (s *ImageTransformService) Transform(ctx context.Context, uploadPath string, transform ImageTransform) (*TransformedImage, error) {
+	if s == nil {
+		return nil, errUploadServiceMissing
+	}
+	if !s.verify(uploadPath, transform) {
+		return nil, ErrImageSignatureInvalid
+	}
+
+	sourcePath, err := s.resolveSource(uploadPath)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(sourcePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrUploadNotFound
+		}
+		return nil, err
+	}
+
+	ext := strings.ToLower(filepath.Ext(sourcePath))
+	sourceFormat, ok := media.ImageFormatFromExtension(ext)
+	if !ok {
+		if ext != ".gif" {
+			return nil, ErrUnsupportedUpload
+		}
+		// Only the first frame survives a resize, so GIFs are re-encoded as PNG.
+		sourceFormat = media.ImageFormatPNG
+	}
+	format := transform.Format
+	if format == "" {
+		format = sourceFormat
+		if !media.EncoderAvailable(format) {
+			format = media.ImageFormatJPEG
+		}
+	}
+
+	// The source size and modification time are part of the key, so replacing an
+	// upload never serves a stale rendition.
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%d|%d|%s|%s", uploadPath, info.Size(), info.ModTime().UnixNano(), transform, format)))
+	key := hex.EncodeToString(sum[:]) + format.Extension()
+	result := &TransformedImage{ContentType: format.MIMEType(), ETag: `"` + hex.EncodeToString(sum[:8]) + `"`}
+
+	if content, err := s.cache.Open(key); err == nil {
+		result.Content = content
+		return result, nil
+	}
+
+	// One request renders a given key; concurrent requests wait and reuse the result.
+	lock, _ := s.locks.LoadOrStore(key, &sync.Mutex{})
+	mu := lock.(*sync.Mutex)
+	mu.Lock()
+	defer func() {
+		mu.Unlock()
+		s.locks.Delete(key)
+	}()
+
+	if content, err := s.cache.Open(key); err == nil {
+		result.Content = content
+		return result, nil
+	}
+
+	// Rendering is CPU and memory heavy, so only a few run at once.
+	select {
+	case s.renders <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	err = s.render(ctx, sourcePath, key, transform, format)
+	<-s.renders
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := s.cache.Open(key)
+	if err != nil {
+		return nil, err
+	}
+	result.Content = content
+	return result, nil
+}
+
+func (s *ImageTransformService) render(ctx context.Context, sourcePath, key string, transform ImageTransform, format media.ImageFormat) error {
+	file, err := os.Open(sourcePath)
+	if err != nil {
+		return err
+	}
+	config, _, err := image.DecodeConfig(file)
+	file.Close()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnsupportedUpload, err)
+	}
+	if config.Width*config.Height > maxImageTransformSourcePixels {
+		return ErrImageTooLarge
+	}
+
+	source, err := media.DecodeImageFile(sourcePath)
+	if err != nil {
+		return err
+	}
+	resized := media.ResizeToBox(source, transform.Width, transform.Height, transform.Fit)
+
+	if err := os.MkdirAll(s.scratchDir, 0755); err != nil {
+		return err
+	}
+	scratch := filepath.Join(s.scratchDir, ".tmp-"+key)
+	quality := transform.Quality
+	if quality == 0 {
+		quality = s.quality
+	}
+	if err := media.EncodeImageFile(ctx, scratch, resized, format, quality); err != nil {
+		os.Remove(scratch)
+		return err
+	}
+	if err := s.cache.Store(key, scratch); err != nil {
+		os.Remove(scratch)
+		return err
+	}
+	return nil
+}
+
+// resolveSource maps a path below /uploads to a file in the upload directory, refusing
+// anything that would escape it.
+func (s *ImageTransformService) resolveSource(uploadPath string) (string, error) {
+	cleaned := filepath.Clean("/" + strings.TrimSpace(uploadPath))
+	if cleaned == "/" || strings.Contains(cleaned, "..") {
+		return "", ErrUploadNotFound
+	}
+
+	root, err := filepath.Abs(s.uploadDir)
+	if err != nil {
+		return "", err
+	}
+	target := filepath.Join(root, cleaned)
+	if !strings.HasPrefix(target, root+string(filepath.Separator)) {
+		return "", ErrUploadNotFound
+	}
+	return target, nil
+}
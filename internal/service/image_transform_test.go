@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseImageTransform(t *testing.T) {
+	svc := NewImageTransformService(t.TempDir(), t.TempDir(), ImageTransformConfig{MaxDimension: 1000, Quality: 80})
+
+	transform, err := svc.ParseTransform("h=200,W=300,fit=cover,f=png")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := transform.String(); got != "w=300,h=200,fit=cover,f=png" {
+		t.Fatalf("unexpected canonical transform %q", got)
+	}
+
+	for raw, want := range map[string]error{
+		"w=1200":       ErrImageTooLarge,
+		"w=0":          ErrInvalidImageTransform,
+		"fit=cover":    ErrInvalidImageTransform,
+		"w=100,fit=xx": ErrInvalidImageTransform,
+		"w=100,rot=90": ErrInvalidImageTransform,
+		"w=100,q=101":  ErrInvalidImageTransform,
+	} {
+		if _, err := svc.ParseTransform(raw); !errors.Is(err, want) {
+			t.Errorf("%s: expected %v, got %v", raw, want, err)
+		}
+	}
+}
+
+func TestImageTransformCoverAndCache(t *testing.T) {
+	uploadDir := t.TempDir()
+	cacheDir := t.TempDir()
+	svc := NewImageTransformService(uploadDir, cacheDir, ImageTransformConfig{MaxDimension: 1000, Quality: 80})
+
+	file, err := os.Create(filepath.Join(uploadDir, "wide.png"))
+	if err != nil {
+		t.Fatalf("create source: %v", err)
+	}
+	if err := png.Encode(file, image.NewRGBA(image.Rect(0, 0, 800, 200))); err != nil {
+		t.Fatalf("encode source: %v", err)
+	}
+	file.Close()
+
+	transform, err := svc.ParseTransform("w=100,h=100,fit=cover")
+	if err != nil {
+		t.Fatalf("parse transform: %v", err)
+	}
+
+	first, err := svc.Transform(context.Background(), "wide.png", transform)
+	if err != nil {
+		t.Fatalf("transform: %v", err)
+	}
+	config, _, err := image.DecodeConfig(first.Content)
+	first.Content.Close()
+	if err != nil || config.Width != 100 || config.Height != 100 {
+		t.Fatalf("unexpected output %dx%d (err %v)", config.Width, config.Height, err)
+	}
+	if first.ContentType != "image/png" {
+		t.Fatalf("unexpected content type %q", first.ContentType)
+	}
+
+	second, err := svc.Transform(context.Background(), "wide.png", transform)
+	if err != nil {
+		t.Fatalf("cached transform: %v", err)
+	}
+	second.Content.Close()
+	if second.ETag != first.ETag {
+		t.Fatalf("expected cached result to keep its etag")
+	}
+
+	outside := filepath.Join(filepath.Dir(uploadDir), "outside.png")
+	if err := os.Rename(filepath.Join(uploadDir, "wide.png"), outside); err != nil {
+		t.Fatalf("move source: %v", err)
+	}
+	defer os.Remove(outside)
+	if _, err := svc.Transform(context.Background(), "../outside.png", transform); !errors.Is(err, ErrUploadNotFound) {
+		t.Fatalf("expected paths outside the upload directory to be unreachable, got %v", err)
+	}
+}
+
+func TestImageTransformSignature(t *testing.T) {
+	uploadDir := t.TempDir()
+	svc := NewImageTransformService(uploadDir, t.TempDir(), ImageTransformConfig{MaxDimension: 1000, SigningSecret: "secret"})
+
+	file, err := os.Create(filepath.Join(uploadDir, "photo.png"))
+	if err != nil {
+		t.Fatalf("create source: %v", err)
+	}
+	if err := png.Encode(file, image.NewRGBA(image.Rect(0, 0, 400, 200))); err != nil {
+		t.Fatalf("encode source: %v", err)
+	}
+	file.Close()
+
+	url := svc.URL("/uploads/photo.png", "h=50,w=100")
+	segment, path, ok := strings.Cut(strings.TrimPrefix(url, "/img/"), "/")
+	if !ok || path != "photo.png" || !strings.HasPrefix(segment, "w=100,h=50,fit=contain,s=") {
+		t.Fatalf("unexpected signed URL %q", url)
+	}
+	transform, err := svc.ParseTransform(segment)
+	if err != nil {
+		t.Fatalf("parse transform: %v", err)
+	}
+	image, err := svc.Transform(context.Background(), path, transform)
+	if err != nil {
+		t.Fatalf("signed transform: %v", err)
+	}
+	image.Content.Close()
+
+	for _, raw := range []string{"w=100,h=50", "w=101,h=50,s=" + transform.Signature, "w=100,h=50,s=" + strings.ToUpper(transform.Signature)} {
+		forged, err := svc.ParseTransform(raw)
+		if err != nil {
+			t.Fatalf("parse %q: %v", raw, err)
+		}
+		if _, err := svc.Transform(context.Background(), path, forged); !errors.Is(err, ErrImageSignatureInvalid) {
+			t.Fatalf("%q: expected an invalid signature, got %v", raw, err)
+		}
+	}
+	if _, err := svc.Transform(context.Background(), "other.png", transform); !errors.Is(err, ErrImageSignatureInvalid) {
+		t.Fatalf("expected a signature to be bound to its upload, got %v", err)
+	}
+}
+
+func TestDiskImageCacheEviction(t *testing.T) {
+	dir := t.TempDir()
+	cache := NewDiskImageCache(dir, 250)
+
+	store := func(key string, age time.Duration) {
+		scratch := filepath.Join(dir, ".tmp-"+key)
+		if err := os.WriteFile(scratch, make([]byte, 100), 0644); err != nil {
+			t.Fatal(err)
+		}
+		modTime := time.Now().Add(-age)
+		os.Chtimes(scratch, modTime, modTime)
+		if err := cache.Store(key, scratch); err != nil {
+			t.Fatalf("store %s: %v", key, err)
+		}
+	}
+	store("aa-old", 3*time.Hour)
+	store("bb-used", 2*time.Hour)
+	store("cc-new", time.Hour)
+	if _, err := os.Stat(filepath.Join(dir, "aa", "aa-old")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected the least recently used image to be evicted, got %v", err)
+	}
+
+	used, err := cache.Open("bb-used")
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	used.Close()
+	store("dd-newest", 0)
+	for key, kept := range map[string]bool{"bb-used": true, "cc-new": false, "dd-newest": true} {
+		_, err := os.Stat(filepath.Join(dir, key[:2], key))
+		if (err == nil) != kept {
+			t.Fatalf("%s: expected kept=%v, got %v", key, kept, err)
+		}
+	}
+}
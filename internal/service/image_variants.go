@@ -0,0 +1,379 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"constructor-script-backend/internal/background"
+	"constructor-script-backend/pkg/logger"
+	"constructor-script-backend/pkg/media"
+)
+
+const (
+	imageVariantsDir      = "variants"
+	imageVariantManifest  = "manifest.json"
+	imageVariantJobPrefix = "image_variants_"
+	imageVariantTimeout   = 2 * time.Minute
+)
+
+// ImageVariantConfig controls the resized copies generated for uploaded images.
+type ImageVariantConfig struct {
+	// Widths lists the target widths in pixels. Widths at or above the source width
+	// are skipped, so images are never upscaled. An empty list disables variants.
+	Widths []int
+	// Formats lists additional encodings written next to the original format, in
+	// order of preference. Formats without an encoder on the host are skipped.
+	Formats []media.ImageFormat
+	// Quality applies to lossy encodings.
+	Quality int
+}
+
+// ImageVariant describes one generated copy of an uploaded image.
+type ImageVariant struct {
+	URL    string `json:"url"`
+	Width  int    `json:"width"`
+	Format string `json:"format"`
+}
+
+// ImageSource is a srcset for one alternative format, for use in a <picture> source.
+type ImageSource struct {
+	Type   string
+	Srcset string
+}
+
+// ResponsiveImage bundles the srcset for the original format with the sources for
+// any additional formats, best format first.
+type ResponsiveImage struct {
+	Srcset  string
+	Sources []ImageSource
+}
+
+type imageVariantSet struct {
+	Width    int            `json:"width"`
+	Height   int            `json:"height"`
+	Format   string         `json:"format"`
+	Variants []ImageVariant `json:"variants"`
+}
+
+// ConfigureImageVariants sets the sizes and formats generated for new image uploads.
+func (s *UploadService) ConfigureImageVariants(config ImageVariantConfig) {
+	if s == nil {
+		return
+	}
+
+	widths := make([]int, 0, len(config.Widths))
+	seen := make(map[int]struct{}, len(config.Widths))
+	for _, width := range config.Widths {
+		if width <= 0 {
+			continue
+		}
+		if _, ok := seen[width]; ok {
+			continue
+		}
+		seen[width] = struct{}{}
+		widths = append(widths, width)
+	}
+	sort.Ints(widths)
+	config.Widths = widths
+
+	s.variantMu.Lock()
+	s.variantConfig = config
+	s.variantMu.Unlock()
+}
+
+// SetScheduler lets the service generate image variants on the background scheduler.
+func (s *UploadService) SetScheduler(scheduler *background.Scheduler) {
+	if s == nil {
+		return
+	}
+	s.scheduler = scheduler
+}
+
+// queueImageVariants generates variants for filename outside the request. Without a
+// running scheduler the work still happens, on its own goroutine.
+func (s *UploadService) queueImageVariants(filename string) {
+	s.variantMu.RLock()
+	enabled := len(s.variantConfig.Widths) > 0
+	s.variantMu.RUnlock()
+	if !enabled {
+		return
+	}
+	if _, ok := media.ImageFormatFromExtension(filepath.Ext(filename)); !ok {
+		return
+	}
+
+	s.runBackgroundJob(background.Job{
+		Name:    imageVariantJobPrefix + filename,
+		Timeout: imageVariantTimeout,
+		Run: func(ctx context.Context) error {
+			return s.GenerateImageVariants(ctx, filename)
+		},
+	}, "image variant generation", filename)
+}
+
+// GenerateImageVariants (re)builds the resized copies of an uploaded image. Files that
+// cannot be resized, such as SVG and GIF, are left alone.
+func (s *UploadService) GenerateImageVariants(ctx context.Context, filename string) error {
+	if s == nil {
+		return errUploadServiceMissing
+	}
+
+	filename = filepath.Base(strings.TrimSpace(filename))
+	if filename == "" || filename == "." || filename == ".." {
+		return ErrUploadNotFound
+	}
+	format, ok := media.ImageFormatFromExtension(filepath.Ext(filename))
+	if !ok {
+		return nil
+	}
+
+	s.variantMu.RLock()
+	config := s.variantConfig
+	s.variantMu.RUnlock()
+	if len(config.Widths) == 0 {
+		return nil
+	}
+
+	source, err := media.DecodeImageFile(filepath.Join(s.uploadDir, filename))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return ErrUploadNotFound
+		}
+		return err
+	}
+	bounds := source.Bounds()
+
+	dir := s.variantDir(filename)
+	if _, err := os.Stat(dir); err == nil {
+		// Regenerating overwrites files the CDN may already have cached.
+		defer s.purgeCDN(filename, true)
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	formats := make([]media.ImageFormat, 0, len(config.Formats))
+	for _, extra := range config.Formats {
+		if extra == format {
+			continue
+		}
+		if !media.EncoderAvailable(extra) {
+			s.warnMissingEncoder(extra)
+			continue
+		}
+		formats = append(formats, extra)
+	}
+
+	set := imageVariantSet{Width: bounds.Dx(), Height: bounds.Dy(), Format: string(format)}
+	write := func(width int, target media.ImageFormat) error {
+		name := strconv.Itoa(width) + "w" + target.Extension()
+		if err := media.EncodeImageFile(ctx, filepath.Join(dir, name), media.ResizeToWidth(source, width), target, config.Quality); err != nil {
+			return fmt.Errorf("write %s variant at %dpx: %w", target, width, err)
+		}
+		set.Variants = append(set.Variants, ImageVariant{
+			URL:    s.variantURL(filename, name),
+			Width:  width,
+			Format: string(target),
+		})
+		return nil
+	}
+
+	for _, width := range config.Widths {
+		if width >= bounds.Dx() {
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if media.EncoderAvailable(format) {
+			if err := write(width, format); err != nil {
+				return err
+			}
+		}
+		for _, extra := range formats {
+			if err := write(width, extra); err != nil {
+				return err
+			}
+		}
+	}
+
+	// The original already covers its own width; other formats need a full-size copy.
+	for _, extra := range formats {
+		if err := write(bounds.Dx(), extra); err != nil {
+			return err
+		}
+	}
+
+	payload, err := json.Marshal(set)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, imageVariantManifest), payload, 0644); err != nil {
+		return err
+	}
+
+	s.variantMu.Lock()
+	if s.variantCache == nil {
+		s.variantCache = make(map[string]*imageVariantSet)
+	}
+	s.variantCache[filename] = &set
+	s.variantMu.Unlock()
+
+	return nil
+}
+
+// ImageVariants returns the generated variants for an upload URL, narrowest first.
+func (s *UploadService) ImageVariants(url string) []ImageVariant {
+	_, set := s.loadImageVariants(url)
+	if set == nil {
+		return nil
+	}
+
+	variants := append([]ImageVariant(nil), set.Variants...)
+	sort.SliceStable(variants, func(i, j int) bool { return variants[i].Width < variants[j].Width })
+	return variants
+}
+
+// ImageSrcset returns a srcset attribute value for url in the given format. An empty
+// format means the format of the original upload. It returns "" when no variants exist.
+func (s *UploadService) ImageSrcset(url string, format string) string {
+	filename, set := s.loadImageVariants(url)
+	if set == nil {
+		return ""
+	}
+	return set.srcset(s.uploadURL(filename), format)
+}
+
+// ResponsiveImage returns the srcsets needed to render url as a <picture>, and false
+// when the image has no variants.
+func (s *UploadService) ResponsiveImage(url string) (ResponsiveImage, bool) {
+	filename, set := s.loadImageVariants(url)
+	if set == nil {
+		return ResponsiveImage{}, false
+	}
+
+	result := ResponsiveImage{Srcset: set.srcset(s.uploadURL(filename), "")}
+
+	s.variantMu.RLock()
+	formats := append([]media.ImageFormat(nil), s.variantConfig.Formats...)
+	s.variantMu.RUnlock()
+
+	for _, format := range formats {
+		if string(format) == set.Format {
+			continue
+		}
+		if srcset := set.srcset("", string(format)); srcset != "" {
+			result.Sources = append(result.Sources, ImageSource{Type: format.MIMEType(), Srcset: srcset})
+		}
+	}
+
+	if result.Srcset == "" && len(result.Sources) == 0 {
+		return ResponsiveImage{}, false
+	}
+	return result, true
+}
+
+func (set *imageVariantSet) srcset(originalURL string, format string) string {
+	if format == "" {
+		format = set.Format
+	}
+
+	variants := make([]ImageVariant, 0, len(set.Variants)+1)
+	for _, variant := range set.Variants {
+		if variant.Format == format {
+			variants = append(variants, variant)
+		}
+	}
+	if format == set.Format && originalURL != "" && len(variants) > 0 {
+		variants = append(variants, ImageVariant{URL: originalURL, Width: set.Width, Format: format})
+	}
+	if len(variants) == 0 {
+		return ""
+	}
+
+	sort.SliceStable(variants, func(i, j int) bool { return variants[i].Width < variants[j].Width })
+	parts := make([]string, 0, len(variants))
+	for _, variant := range variants {
+		parts = append(parts, variant.URL+" "+strconv.Itoa(variant.Width)+"w")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// loadImageVariants resolves a managed image URL to its filename and variant manifest.
+// Manifests are cached once read; generation and deletion keep the cache current.
+func (s *UploadService) loadImageVariants(url string) (string, *imageVariantSet) {
+	filename := s.managedFilename(url)
+	if filename == "" {
+		return "", nil
+	}
+	if _, ok := media.ImageFormatFromExtension(filepath.Ext(filename)); !ok {
+		return "", nil
+	}
+
+	s.variantMu.RLock()
+	cached := s.variantCache[filename]
+	s.variantMu.RUnlock()
+	if cached != nil {
+		return filename, cached
+	}
+
+	payload, err := os.ReadFile(filepath.Join(s.variantDir(filename), imageVariantManifest))
+	if err != nil {
+		return "", nil
+	}
+	var set imageVariantSet
+	if err := json.Unmarshal(payload, &set); err != nil {
+		logger.Warn("Ignoring unreadable image variant manifest", map[string]interface{}{"filename": filename, "error": err.Error()})
+		return "", nil
+	}
+
+	s.variantMu.Lock()
+	if s.variantCache == nil {
+		s.variantCache = make(map[string]*imageVariantSet)
+	}
+	s.variantCache[filename] = &set
+	s.variantMu.Unlock()
+
+	return filename, &set
+}
+
+// removeImageVariants deletes the generated variants of filename, if any.
+func (s *UploadService) removeImageVariants(filename string) {
+	s.variantMu.Lock()
+	delete(s.variantCache, filename)
+	s.variantMu.Unlock()
+
+	if err := os.RemoveAll(s.variantDir(filename)); err != nil {
+		logger.Warn("Failed to remove image variants", map[string]interface{}{"filename": filename, "error": err.Error()})
+	}
+}
+
+func (s *UploadService) warnMissingEncoder(format media.ImageFormat) {
+	if _, loaded := s.variantWarned.LoadOrStore(format, struct{}{}); loaded {
+		return
+	}
+	logger.Warn("Image encoder not installed; skipping variants in this format", map[string]interface{}{"format": string(format)})
+}
+
+func (s *UploadService) variantDir(filename string) string {
+	return filepath.Join(s.uploadDir, imageVariantsDir, filename)
+}
+
+func (s *UploadService) variantURL(filename, name string) string {
+	return "/uploads/" + imageVariantsDir + "/" + filename + "/" + name
+}
+
+func (s *UploadService) uploadURL(filename string) string {
+	return "/uploads/" + filename
+}
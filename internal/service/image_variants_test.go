@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGenerateImageVariantsSkipsUpscaling(t *testing.T) {
+	uploadDir := t.TempDir()
+	svc := NewUploadService(uploadDir)
+	svc.ConfigureImageVariants(ImageVariantConfig{Widths: []int{1600, 200, 400, 400}})
+
+	img := image.NewRGBA(image.Rect(0, 0, 800, 400))
+	for x := 0; x < 800; x++ {
+		img.Set(x, x%400, color.RGBA{R: 200, A: 255})
+	}
+	file, err := os.Create(filepath.Join(uploadDir, "banner.png"))
+	if err != nil {
+		t.Fatalf("create source: %v", err)
+	}
+	if err := png.Encode(file, img); err != nil {
+		t.Fatalf("encode source: %v", err)
+	}
+	file.Close()
+
+	if err := svc.GenerateImageVariants(context.Background(), "banner.png"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	variants := svc.ImageVariants("/uploads/banner.png")
+	if len(variants) != 2 || variants[0].Width != 200 || variants[1].Width != 400 {
+		t.Fatalf("unexpected variants: %+v", variants)
+	}
+
+	resized, err := os.Open(filepath.Join(uploadDir, "variants", "banner.png", "200w.png"))
+	if err != nil {
+		t.Fatalf("expected variant file: %v", err)
+	}
+	config, _, err := image.DecodeConfig(resized)
+	resized.Close()
+	if err != nil || config.Width != 200 || config.Height != 100 {
+		t.Fatalf("unexpected variant size %dx%d (err %v)", config.Width, config.Height, err)
+	}
+
+	want := "/uploads/variants/banner.png/200w.png 200w, /uploads/variants/banner.png/400w.png 400w, /uploads/banner.png 800w"
+	if got := svc.ImageSrcset("/uploads/banner.png", ""); got != want {
+		t.Fatalf("unexpected srcset:\n got %q\nwant %q", got, want)
+	}
+
+	uploads, err := svc.ListUploads()
+	if err != nil {
+		t.Fatalf("list uploads: %v", err)
+	}
+	if len(uploads) != 1 || len(uploads[0].Variants) != 2 {
+		t.Fatalf("expected variants in listing, got %+v", uploads)
+	}
+
+	if err := svc.DeleteUpload("/uploads/banner.png"); err != nil {
+		t.Fatalf("delete upload: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(uploadDir, "variants", "banner.png")); !os.IsNotExist(err) {
+		t.Fatalf("expected variants to be removed, got %v", err)
+	}
+	if got := svc.ImageSrcset("/uploads/banner.png", ""); got != "" {
+		t.Fatalf("expected no srcset after delete, got %q", got)
+	}
+}
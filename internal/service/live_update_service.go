@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"constructor-script-backend/internal/events"
+)
+
+const (
+	// liveUpdateHistory is how many updates are kept for clients that reconnect
+	// with the ID of the last update they saw.
+	liveUpdateHistory = 100
+	// liveUpdateBuffer is how many updates a listener may fall behind before it
+	// is dropped; its client reconnects and catches up from the history.
+	liveUpdateBuffer = 32
+)
+
+// liveUpdateEvents are the events pushed to the admin dashboard.
+var liveUpdateEvents = []string{
+	events.CommentCreated,
+	events.ForumQuestionCreated,
+	events.CheckoutCompleted,
+	events.BackupCompleted,
+	events.BackupVerificationFailed,
+}
+
+// LiveUpdate is an event numbered for the admin live updates stream.
+type LiveUpdate struct {
+	ID    uint64
+	Event events.Event
+}
+
+// LiveUpdateService fans the events the admin dashboard shows out to the open
+// live update streams, so the dashboard does not have to poll. Updates are only
+// seen by streams connected to the instance that published the event.
+type LiveUpdateService struct {
+	mu        sync.Mutex
+	nextID    uint64
+	history   []LiveUpdate
+	listeners map[chan LiveUpdate]struct{}
+}
+
+func NewLiveUpdateService() *LiveUpdateService {
+	return &LiveUpdateService{
+		// IDs continue from the clock, so an ID from before a restart is lower
+		// than every ID after it and the client is sent the whole history.
+		nextID:    uint64(time.Now().UnixNano()),
+		listeners: make(map[chan LiveUpdate]struct{}),
+	}
+}
+
+// Subscribe pushes the dashboard events published on bus to the listeners.
+func (s *LiveUpdateService) Subscribe(bus *events.Bus) {
+	if s == nil || bus == nil {
+		return
+	}
+	for _, name := range liveUpdateEvents {
+		bus.Subscribe(name, func(_ context.Context, event events.Event) {
+			s.publish(event)
+		})
+	}
+}
+
+// EventNames lists the events sent on the stream.
+func (s *LiveUpdateService) EventNames() []string {
+	return append([]string(nil), liveUpdateEvents...)
+}
+
+func (s *LiveUpdateService) publish(event events.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID++
+	update := LiveUpdate{ID: s.nextID, Event: event}
+	if len(s.history) >= liveUpdateHistory {
+		s.history = append(s.history[:0], s.history[1:]...)
+	}
+	s.history = append(s.history, update)
+
+	for listener := range s.listeners {
+		select {
+		case listener <- update:
+		default:
+			delete(s.listeners, listener)
+			close(listener)
+		}
+	}
+}
+
+// Listen registers a listener and returns the updates after lastID still in the
+// history, the channel of later updates and a function that removes the
+// listener. A lastID of zero skips the history. The channel is closed when the
+// listener falls too far behind.
+func (s *LiveUpdateService) Listen(lastID uint64) ([]LiveUpdate, <-chan LiveUpdate, func()) {
+	listener := make(chan LiveUpdate, liveUpdateBuffer)
+	if s == nil {
+		close(listener)
+		return nil, listener, func() {}
+	}
+
+	s.mu.Lock()
+	var missed []LiveUpdate
+	if lastID > 0 {
+		for _, update := range s.history {
+			if update.ID > lastID {
+				missed = append(missed, update)
+			}
+		}
+	}
+	s.listeners[listener] = struct{}{}
+	s.mu.Unlock()
+
+	return missed, listener, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if _, ok := s.listeners[listener]; ok {
+			delete(s.listeners, listener)
+			close(listener)
+		}
+	}
+}
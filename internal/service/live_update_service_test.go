@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"constructor-script-backend/internal/events"
+)
+
+func TestLiveUpdateServiceDeliversDashboardEvents(t *testing.T) {
+	bus := events.NewBus()
+	svc := NewLiveUpdateService()
+	svc.Subscribe(bus)
+
+	_, updates, stop := svc.Listen(0)
+	defer stop()
+
+	bus.Publish(context.Background(), events.PostCreated, map[string]interface{}{"id": 1})
+	bus.Publish(context.Background(), events.CommentCreated, map[string]interface{}{"id": 2})
+
+	select {
+	case update := <-updates:
+		if update.Event.Name != events.CommentCreated || update.ID == 0 {
+			t.Fatalf("unexpected update %+v", update)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the comment to be pushed")
+	}
+
+	select {
+	case update := <-updates:
+		t.Fatalf("expected events outside the dashboard to be skipped, got %+v", update)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestLiveUpdateServiceReplaysMissedUpdates(t *testing.T) {
+	svc := NewLiveUpdateService()
+	svc.publish(events.Event{Name: events.CommentCreated})
+	_, first, stop := svc.Listen(0)
+	stop()
+	if _, ok := <-first; ok {
+		t.Fatal("expected stop to close the listener")
+	}
+
+	svc.publish(events.Event{Name: events.CheckoutCompleted})
+	svc.publish(events.Event{Name: events.BackupCompleted})
+
+	missed, _, stop := svc.Listen(svc.history[0].ID)
+	defer stop()
+	if len(missed) != 2 || missed[0].Event.Name != events.CheckoutCompleted || missed[1].Event.Name != events.BackupCompleted {
+		t.Fatalf("unexpected missed updates %+v", missed)
+	}
+	if missed[0].ID >= missed[1].ID {
+		t.Fatalf("expected increasing IDs, got %d and %d", missed[0].ID, missed[1].ID)
+	}
+
+	// An ID from before a restart is lower than every ID since.
+	if missed, _, stop := svc.Listen(1); len(missed) != 3 {
+		t.Fatalf("expected the whole history for an old ID, got %d updates", len(missed))
+	} else {
+		stop()
+	}
+}
+
+func TestLiveUpdateServiceDropsSlowListeners(t *testing.T) {
+	svc := NewLiveUpdateService()
+	_, updates, stop := svc.Listen(0)
+	defer stop()
+
+	for i := 0; i <= liveUpdateBuffer; i++ {
+		svc.publish(events.Event{Name: events.CommentCreated})
+	}
+
+	received := 0
+	for range updates {
+		received++
+	}
+	if received != liveUpdateBuffer {
+		t.Fatalf("expected %d buffered updates before the listener was dropped, got %d", liveUpdateBuffer, received)
+	}
+	if len(svc.listeners) != 0 {
+		t.Fatalf("expected the slow listener to be removed, got %d", len(svc.listeners))
+	}
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"constructor-script-backend/pkg/lang"
+)
+
+// Machine translation providers.
+const (
+	MachineTranslationDeepL  = "deepl"
+	MachineTranslationOpenAI = "openai"
+	MachineTranslationGoogle = "google"
+)
+
+// maxMachineTranslationChars caps the source text sent in one suggestion so a
+// single click cannot run up a large provider bill.
+const maxMachineTranslationChars = 50000
+
+var (
+	// ErrMachineTranslationNotConfigured is returned when no provider is set up.
+	ErrMachineTranslationNotConfigured = errors.New("machine translation provider is not configured")
+	// ErrInvalidTranslationRequest wraps problems with the suggestion request itself.
+	ErrInvalidTranslationRequest = errors.New("invalid translation request")
+	// ErrMachineTranslationFailed wraps errors reported by the provider.
+	ErrMachineTranslationFailed = errors.New("machine translation failed")
+)
+
+// MachineTranslationRequest is one batch of texts sent to a provider. When
+// SourceLanguage is empty the provider detects it. HTML texts keep their markup.
+type MachineTranslationRequest struct {
+	SourceLanguage string
+	TargetLanguage string
+	Texts          []string
+	HTML           bool
+}
+
+// MachineTranslator translates a batch of texts, returning them in request order.
+type MachineTranslator interface {
+	Translate(ctx context.Context, request MachineTranslationRequest) ([]string, error)
+}
+
+// TranslationSuggestionRequest asks for a draft translation of named fields,
+// e.g. "title" and "content". Fields named "content" are treated as HTML.
+type TranslationSuggestionRequest struct {
+	SourceLanguage string            `json:"source_language"`
+	TargetLanguage string            `json:"target_language"`
+	Fields         map[string]string `json:"fields"`
+}
+
+// TranslationSuggestion is a draft for an editor to review. It is never saved.
+type TranslationSuggestion struct {
+	Provider       string            `json:"provider"`
+	SourceLanguage string            `json:"source_language,omitempty"`
+	TargetLanguage string            `json:"target_language"`
+	Fields         map[string]string `json:"fields"`
+	Draft          bool              `json:"draft"`
+}
+
+// MachineTranslationService drafts translations through the configured provider.
+type MachineTranslationService struct {
+	provider   string
+	translator MachineTranslator
+}
+
+// NewMachineTranslationService returns nil when translator is nil, which the
+// handler reports as the feature being unavailable.
+func NewMachineTranslationService(provider string, translator MachineTranslator) *MachineTranslationService {
+	if translator == nil {
+		return nil
+	}
+	return &MachineTranslationService{provider: provider, translator: translator}
+}
+
+// Provider names the configured provider.
+func (s *MachineTranslationService) Provider() string {
+	if s == nil {
+		return ""
+	}
+	return s.provider
+}
+
+// Suggest translates the request fields and returns them as a draft.
+func (s *MachineTranslationService) Suggest(ctx context.Context, req TranslationSuggestionRequest) (*TranslationSuggestion, error) {
+	if s == nil || s.translator == nil {
+		return nil, ErrMachineTranslationNotConfigured
+	}
+
+	target, err := lang.Normalize(req.TargetLanguage)
+	if err != nil {
+		return nil, fmt.Errorf("%w: target_language is not a valid language code", ErrInvalidTranslationRequest)
+	}
+	source, err := lang.NormalizeOptional(req.SourceLanguage)
+	if err != nil {
+		return nil, fmt.Errorf("%w: source_language is not a valid language code", ErrInvalidTranslationRequest)
+	}
+	if source == target {
+		return nil, fmt.Errorf("%w: source and target language are the same", ErrInvalidTranslationRequest)
+	}
+
+	var plain, markup []string
+	total := 0
+	for name, value := range req.Fields {
+		if strings.TrimSpace(value) == "" {
+			continue
+		}
+		total += utf8.RuneCountInString(value)
+		if name == "content" {
+			markup = append(markup, name)
+		} else {
+			plain = append(plain, name)
+		}
+	}
+	if len(plain)+len(markup) == 0 {
+		return nil, fmt.Errorf("%w: nothing to translate", ErrInvalidTranslationRequest)
+	}
+	if total > maxMachineTranslationChars {
+		return nil, fmt.Errorf("%w: content exceeds %d characters", ErrInvalidTranslationRequest, maxMachineTranslationChars)
+	}
+
+	suggestion := &TranslationSuggestion{
+		Provider:       s.provider,
+		SourceLanguage: source,
+		TargetLanguage: target,
+		Fields:         make(map[string]string, len(plain)+len(markup)),
+		Draft:          true,
+	}
+
+	for _, batch := range []struct {
+		names []string
+		html  bool
+	}{{plain, false}, {markup, true}} {
+		if len(batch.names) == 0 {
+			continue
+		}
+		sort.Strings(batch.names)
+
+		texts := make([]string, len(batch.names))
+		for i, name := range batch.names {
+			texts[i] = req.Fields[name]
+		}
+
+		translated, err := s.translator.Translate(ctx, MachineTranslationRequest{
+			SourceLanguage: source,
+			TargetLanguage: target,
+			Texts:          texts,
+			HTML:           batch.html,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(translated) != len(texts) {
+			return nil, fmt.Errorf("%w: %s returned %d translations for %d texts", ErrMachineTranslationFailed, s.provider, len(translated), len(texts))
+		}
+		for i, name := range batch.names {
+			suggestion.Fields[name] = translated[i]
+		}
+	}
+
+	return suggestion, nil
+}
@@ -0,0 +1,282 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	defaultDeepLEndpoint     = "https://api.deepl.com/v2/translate"
+	defaultDeepLFreeEndpoint = "https://api-free.deepl.com/v2/translate"
+	defaultOpenAIChatURL     = "https://api.openai.com/v1/chat/completions"
+	defaultOpenAIChatModel   = "gpt-4o-mini"
+	defaultGoogleTranslate   = "https://translation.googleapis.com/language/translate/v2"
+)
+
+// MachineTranslatorOptions tunes the provider client. Empty values use the
+// provider defaults.
+type MachineTranslatorOptions struct {
+	Model      string
+	Endpoint   string
+	HTTPClient *http.Client
+}
+
+// NewMachineTranslator builds the client for provider. It returns nil without an
+// error when provider is empty, meaning machine translation is switched off.
+func NewMachineTranslator(provider, apiKey string, opts MachineTranslatorOptions) (MachineTranslator, error) {
+	provider = strings.ToLower(strings.TrimSpace(provider))
+	if provider == "" {
+		return nil, nil
+	}
+
+	apiKey = strings.TrimSpace(apiKey)
+	if apiKey == "" {
+		return nil, fmt.Errorf("%s api key is required for machine translation", provider)
+	}
+
+	client := opts.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 2 * time.Minute}
+	}
+	endpoint := strings.TrimSpace(opts.Endpoint)
+
+	switch provider {
+	case MachineTranslationDeepL:
+		if endpoint == "" {
+			// DeepL API Free keys end in ":fx" and only work on the free host.
+			endpoint = defaultDeepLEndpoint
+			if strings.HasSuffix(apiKey, ":fx") {
+				endpoint = defaultDeepLFreeEndpoint
+			}
+		}
+		return &deepLTranslator{apiKey: apiKey, endpoint: endpoint, client: client}, nil
+	case MachineTranslationOpenAI:
+		if endpoint == "" {
+			endpoint = defaultOpenAIChatURL
+		}
+		model := strings.TrimSpace(opts.Model)
+		if model == "" {
+			model = defaultOpenAIChatModel
+		}
+		return &openAITranslator{apiKey: apiKey, endpoint: endpoint, model: model, client: client}, nil
+	case MachineTranslationGoogle:
+		if endpoint == "" {
+			endpoint = defaultGoogleTranslate
+		}
+		return &googleTranslator{apiKey: apiKey, endpoint: endpoint, client: client}, nil
+	default:
+		return nil, fmt.Errorf("unsupported machine translation provider %q", provider)
+	}
+}
+
+// postTranslationJSON sends payload to endpoint and decodes the JSON response into
+// target, reporting non-2xx responses with their body.
+func postTranslationJSON(ctx context.Context, client *http.Client, provider, endpoint string, headers map[string]string, payload, target interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("%s: failed to encode request: %w", provider, err)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%s: failed to build request: %w", provider, err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		request.Header.Set(key, value)
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return fmt.Errorf("%w: %s request failed: %v", ErrMachineTranslationFailed, provider, err)
+	}
+	defer response.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(response.Body, 8<<20))
+	if err != nil {
+		return fmt.Errorf("%w: %s: failed to read response: %v", ErrMachineTranslationFailed, provider, err)
+	}
+
+	if response.StatusCode >= http.StatusMultipleChoices {
+		message := strings.TrimSpace(string(data))
+		if len(message) > 500 {
+			message = message[:500]
+		}
+		if message == "" {
+			message = response.Status
+		}
+		return fmt.Errorf("%w: %s returned status %s: %s", ErrMachineTranslationFailed, provider, response.Status, message)
+	}
+
+	if err := json.Unmarshal(data, target); err != nil {
+		return fmt.Errorf("%w: %s: invalid response: %v", ErrMachineTranslationFailed, provider, err)
+	}
+	return nil
+}
+
+type deepLTranslator struct {
+	apiKey   string
+	endpoint string
+	client   *http.Client
+}
+
+// deepLLanguage maps a site language code to DeepL's. DeepL only accepts base
+// languages as source, and wants a variant for English and Portuguese targets.
+func deepLLanguage(code string, target bool) string {
+	upper := strings.ToUpper(code)
+	base, _, _ := strings.Cut(upper, "-")
+	if !target {
+		return base
+	}
+	switch upper {
+	case "EN":
+		return "EN-US"
+	case "PT":
+		return "PT-PT"
+	}
+	return upper
+}
+
+func (t *deepLTranslator) Translate(ctx context.Context, request MachineTranslationRequest) ([]string, error) {
+	payload := map[string]interface{}{
+		"text":        request.Texts,
+		"target_lang": deepLLanguage(request.TargetLanguage, true),
+	}
+	if request.SourceLanguage != "" {
+		payload["source_lang"] = deepLLanguage(request.SourceLanguage, false)
+	}
+	if request.HTML {
+		payload["tag_handling"] = "html"
+	}
+
+	var response struct {
+		Translations []struct {
+			Text string `json:"text"`
+		} `json:"translations"`
+	}
+	headers := map[string]string{"Authorization": "DeepL-Auth-Key " + t.apiKey}
+	if err := postTranslationJSON(ctx, t.client, MachineTranslationDeepL, t.endpoint, headers, payload, &response); err != nil {
+		return nil, err
+	}
+
+	texts := make([]string, len(response.Translations))
+	for i, translation := range response.Translations {
+		texts[i] = translation.Text
+	}
+	return texts, nil
+}
+
+type openAITranslator struct {
+	apiKey   string
+	endpoint string
+	model    string
+	client   *http.Client
+}
+
+func (t *openAITranslator) Translate(ctx context.Context, request MachineTranslationRequest) ([]string, error) {
+	source := request.SourceLanguage
+	if source == "" {
+		source = "the detected source language"
+	}
+	instructions := fmt.Sprintf(
+		"Translate website content from %s to %s. The user message is a JSON array of texts. "+
+			"Reply with a JSON object {\"translations\": [...]} holding exactly one translated string per text, in the same order. "+
+			"Keep URLs, placeholders and line breaks unchanged.",
+		source, request.TargetLanguage,
+	)
+	if request.HTML {
+		instructions += " The texts are HTML: translate only the text and keep every tag and attribute as it is."
+	}
+
+	input, err := json.Marshal(request.Texts)
+	if err != nil {
+		return nil, fmt.Errorf("openai: failed to encode texts: %w", err)
+	}
+
+	payload := map[string]interface{}{
+		"model": t.model,
+		"messages": []map[string]string{
+			{"role": "system", "content": instructions},
+			{"role": "user", "content": string(input)},
+		},
+		"response_format": map[string]string{"type": "json_object"},
+		"temperature":     0,
+	}
+
+	var response struct {
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+	}
+	headers := map[string]string{"Authorization": "Bearer " + t.apiKey}
+	if err := postTranslationJSON(ctx, t.client, MachineTranslationOpenAI, t.endpoint, headers, payload, &response); err != nil {
+		return nil, err
+	}
+	if len(response.Choices) == 0 {
+		return nil, fmt.Errorf("%w: openai returned no choices", ErrMachineTranslationFailed)
+	}
+
+	var output struct {
+		Translations []string `json:"translations"`
+	}
+	if err := json.Unmarshal([]byte(response.Choices[0].Message.Content), &output); err != nil {
+		return nil, fmt.Errorf("%w: openai returned malformed translations: %v", ErrMachineTranslationFailed, err)
+	}
+	return output.Translations, nil
+}
+
+type googleTranslator struct {
+	apiKey   string
+	endpoint string
+	client   *http.Client
+}
+
+func (t *googleTranslator) Translate(ctx context.Context, request MachineTranslationRequest) ([]string, error) {
+	endpoint, err := url.Parse(t.endpoint)
+	if err != nil {
+		return nil, errors.New("google: invalid endpoint")
+	}
+	query := endpoint.Query()
+	query.Set("key", t.apiKey)
+	endpoint.RawQuery = query.Encode()
+
+	format := "text"
+	if request.HTML {
+		format = "html"
+	}
+	payload := map[string]interface{}{
+		"q":      request.Texts,
+		"target": request.TargetLanguage,
+		"format": format,
+	}
+	if request.SourceLanguage != "" {
+		payload["source"] = request.SourceLanguage
+	}
+
+	var response struct {
+		Data struct {
+			Translations []struct {
+				TranslatedText string `json:"translatedText"`
+			} `json:"translations"`
+		} `json:"data"`
+	}
+	if err := postTranslationJSON(ctx, t.client, MachineTranslationGoogle, endpoint.String(), nil, payload, &response); err != nil {
+		return nil, err
+	}
+
+	texts := make([]string, len(response.Data.Translations))
+	for i, translation := range response.Data.Translations {
+		texts[i] = translation.TranslatedText
+	}
+	return texts, nil
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type recordingTranslator struct {
+	requests []MachineTranslationRequest
+}
+
+func (t *recordingTranslator) Translate(_ context.Context, request MachineTranslationRequest) ([]string, error) {
+	t.requests = append(t.requests, request)
+	texts := make([]string, len(request.Texts))
+	for i, text := range request.Texts {
+		texts[i] = "[" + request.TargetLanguage + "] " + text
+	}
+	return texts, nil
+}
+
+func TestMachineTranslationSuggest(t *testing.T) {
+	translator := &recordingTranslator{}
+	svc := NewMachineTranslationService(MachineTranslationDeepL, translator)
+
+	suggestion, err := svc.Suggest(context.Background(), TranslationSuggestionRequest{
+		SourceLanguage: "en",
+		TargetLanguage: "ES",
+		Fields: map[string]string{
+			"title":   "Hello",
+			"excerpt": "",
+			"content": "<p>World</p>",
+		},
+	})
+	if err != nil {
+		t.Fatalf("suggest: %v", err)
+	}
+
+	if !suggestion.Draft || suggestion.TargetLanguage != "es" {
+		t.Fatalf("unexpected suggestion %+v", suggestion)
+	}
+	if suggestion.Fields["title"] != "[es] Hello" || suggestion.Fields["content"] != "[es] <p>World</p>" {
+		t.Fatalf("unexpected fields %v", suggestion.Fields)
+	}
+	if _, ok := suggestion.Fields["excerpt"]; ok {
+		t.Fatal("empty fields must not be sent for translation")
+	}
+	if len(translator.requests) != 2 || translator.requests[0].HTML || !translator.requests[1].HTML {
+		t.Fatalf("expected a plain and an HTML batch, got %+v", translator.requests)
+	}
+}
+
+func TestMachineTranslationSuggestValidation(t *testing.T) {
+	svc := NewMachineTranslationService(MachineTranslationDeepL, &recordingTranslator{})
+
+	cases := []TranslationSuggestionRequest{
+		{TargetLanguage: "", Fields: map[string]string{"title": "Hello"}},
+		{SourceLanguage: "es", TargetLanguage: "es", Fields: map[string]string{"title": "Hola"}},
+		{TargetLanguage: "es", Fields: map[string]string{"title": " "}},
+		{TargetLanguage: "es", Fields: map[string]string{"content": strings.Repeat("a", maxMachineTranslationChars+1)}},
+	}
+	for i, req := range cases {
+		if _, err := svc.Suggest(context.Background(), req); !errors.Is(err, ErrInvalidTranslationRequest) {
+			t.Fatalf("case %d: expected invalid request, got %v", i, err)
+		}
+	}
+
+	var unconfigured *MachineTranslationService
+	if _, err := unconfigured.Suggest(context.Background(), TranslationSuggestionRequest{}); !errors.Is(err, ErrMachineTranslationNotConfigured) {
+		t.Fatalf("expected not configured error, got %v", err)
+	}
+}
+
+func TestMachineTranslatorProviders(t *testing.T) {
+	var received map[string]interface{}
+	var authorization, query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		query = r.URL.RawQuery
+		received = nil
+		_ = json.NewDecoder(r.Body).Decode(&received)
+
+		switch r.URL.Path {
+		case "/deepl":
+			_, _ = w.Write([]byte(`{"translations":[{"text":"Hola"}]}`))
+		case "/openai":
+			_, _ = w.Write([]byte(`{"choices":[{"message":{"content":"{\"translations\":[\"Hola\"]}"}}]}`))
+		case "/google":
+			_, _ = w.Write([]byte(`{"data":{"translations":[{"translatedText":"Hola"}]}}`))
+		default:
+			http.Error(w, "quota exceeded", http.StatusTooManyRequests)
+		}
+	}))
+	defer server.Close()
+
+	request := MachineTranslationRequest{SourceLanguage: "en", TargetLanguage: "es", Texts: []string{"Hello"}}
+
+	cases := []struct {
+		provider string
+		path     string
+		check    func(t *testing.T)
+	}{
+		{MachineTranslationDeepL, "/deepl", func(t *testing.T) {
+			if authorization != "DeepL-Auth-Key secret" || received["target_lang"] != "ES" || received["source_lang"] != "EN" {
+				t.Fatalf("unexpected DeepL request %q %v", authorization, received)
+			}
+		}},
+		{MachineTranslationOpenAI, "/openai", func(t *testing.T) {
+			if authorization != "Bearer secret" || received["model"] != defaultOpenAIChatModel {
+				t.Fatalf("unexpected OpenAI request %q %v", authorization, received)
+			}
+		}},
+		{MachineTranslationGoogle, "/google", func(t *testing.T) {
+			if query != "key=secret" || received["target"] != "es" || received["format"] != "text" {
+				t.Fatalf("unexpected Google request %q %v", query, received)
+			}
+		}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.provider, func(t *testing.T) {
+			translator, err := NewMachineTranslator(tc.provider, "secret", MachineTranslatorOptions{Endpoint: server.URL + tc.path})
+			if err != nil {
+				t.Fatalf("new translator: %v", err)
+			}
+			texts, err := translator.Translate(context.Background(), request)
+			if err != nil {
+				t.Fatalf("translate: %v", err)
+			}
+			if len(texts) != 1 || texts[0] != "Hola" {
+				t.Fatalf("unexpected translations %v", texts)
+			}
+			tc.check(t)
+		})
+	}
+
+	translator, _ := NewMachineTranslator(MachineTranslationDeepL, "secret", MachineTranslatorOptions{Endpoint: server.URL + "/limited"})
+	if _, err := translator.Translate(context.Background(), request); !errors.Is(err, ErrMachineTranslationFailed) {
+		t.Fatalf("expected provider failure, got %v", err)
+	}
+
+	if translator, err := NewMachineTranslator("", "", MachineTranslatorOptions{}); translator != nil || err != nil {
+		t.Fatalf("empty provider must disable machine translation, got %v, %v", translator, err)
+	}
+	if _, err := NewMachineTranslator("babelfish", "secret", MachineTranslatorOptions{}); err == nil {
+		t.Fatal("expected an error for an unknown provider")
+	}
+}
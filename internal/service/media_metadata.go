@@ -0,0 +1,257 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/repository"
+	"constructor-script-backend/pkg/logger"
+)
+
+const (
+	maxAltTextLength = 500
+	// Metadata is cached in memory and reloaded periodically so that edits made on
+	// another instance show up without a restart.
+	mediaMetadataTTL = 5 * time.Minute
+)
+
+var (
+	ErrInvalidFocalPoint        = errors.New("focal point coordinates must be between 0 and 1")
+	ErrAltTextTooLong           = fmt.Errorf("alt text must be at most %d characters", maxAltTextLength)
+	ErrMediaMetadataUnavailable = errors.New("media metadata storage is not configured")
+)
+
+// FocalPoint locates the subject of an image as fractions of its width (X) and
+// height (Y), where 0.5, 0.5 is the centre.
+type FocalPoint struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// ObjectPosition formats the focal point as a CSS object-position value.
+func (p FocalPoint) ObjectPosition() string {
+	return fmt.Sprintf("%.4g%% %.4g%%", p.X*100, p.Y*100)
+}
+
+// ImageMetadata is the alt text and focal point stored for an image in the media library.
+type ImageMetadata struct {
+	AltText    string      `json:"alt_text"`
+	FocalPoint *FocalPoint `json:"focal_point,omitempty"`
+}
+
+// ImageMetadataUpdate changes the stored metadata of an image. Nil fields are left as
+// they are; ClearFocalPoint removes the focal point.
+type ImageMetadataUpdate struct {
+	AltText         *string     `json:"alt_text"`
+	FocalPoint      *FocalPoint `json:"focal_point"`
+	ClearFocalPoint bool        `json:"clear_focal_point"`
+}
+
+// SetMetadataRepository enables alt text and focal points for the media library.
+func (s *UploadService) SetMetadataRepository(repo repository.MediaRepository) {
+	if s == nil {
+		return
+	}
+	s.metadataMu.Lock()
+	s.metadataRepo = repo
+	s.metadataCache = nil
+	s.metadataMu.Unlock()
+}
+
+// ImageMetadata returns the stored metadata for an upload URL, and false when there is none.
+func (s *UploadService) ImageMetadata(url string) (ImageMetadata, bool) {
+	filename := s.managedFilename(url)
+	if filename == "" {
+		return ImageMetadata{}, false
+	}
+	record, ok := s.loadMediaMetadata()[filename]
+	if !ok {
+		return ImageMetadata{}, false
+	}
+	return imageMetadataFromRecord(record), true
+}
+
+// UpdateImageMetadata stores alt text and a focal point for an uploaded image.
+func (s *UploadService) UpdateImageMetadata(target string, update ImageMetadataUpdate) (UploadInfo, error) {
+	if s == nil {
+		return UploadInfo{}, errUploadServiceMissing
+	}
+	s.metadataMu.RLock()
+	repo := s.metadataRepo
+	s.metadataMu.RUnlock()
+	if repo == nil {
+		return UploadInfo{}, ErrMediaMetadataUnavailable
+	}
+
+	filename := filepath.Base(strings.TrimSpace(target))
+	if filename == "" || filename == "." || filename == ".." {
+		return UploadInfo{}, ErrUploadNotFound
+	}
+	category, ok := s.detectCategory(strings.ToLower(filepath.Ext(filename)))
+	if !ok || category != UploadCategoryImage {
+		return UploadInfo{}, ErrUploadNotFound
+	}
+	stat, err := os.Stat(filepath.Join(s.uploadDir, filename))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return UploadInfo{}, ErrUploadNotFound
+		}
+		return UploadInfo{}, err
+	}
+
+	stored := s.loadMediaMetadata()[filename]
+	current := imageMetadataFromRecord(stored)
+	if update.AltText != nil {
+		alt := strings.TrimSpace(*update.AltText)
+		if utf8.RuneCountInString(alt) > maxAltTextLength {
+			return UploadInfo{}, ErrAltTextTooLong
+		}
+		current.AltText = alt
+	}
+	switch {
+	case update.ClearFocalPoint:
+		current.FocalPoint = nil
+	case update.FocalPoint != nil:
+		point := *update.FocalPoint
+		if point.X < 0 || point.X > 1 || point.Y < 0 || point.Y > 1 {
+			return UploadInfo{}, ErrInvalidFocalPoint
+		}
+		current.FocalPoint = &point
+	}
+
+	record := stored
+	record.Filename = filename
+	record.AltText = current.AltText
+	record.FocalX, record.FocalY = nil, nil
+	if current.FocalPoint != nil {
+		record.FocalX = &current.FocalPoint.X
+		record.FocalY = &current.FocalPoint.Y
+	}
+	if err := repo.Save(&record); err != nil {
+		return UploadInfo{}, err
+	}
+	s.cacheMediaMetadata(record)
+
+	info := UploadInfo{
+		URL:      s.uploadURL(filename),
+		Filename: filename,
+		Size:     stat.Size(),
+		ModTime:  stat.ModTime(),
+		Type:     string(category),
+		Variants: s.ImageVariants(s.uploadURL(filename)),
+	}
+	info.applyMetadata(current)
+	return info, nil
+}
+
+func (info *UploadInfo) applyMetadata(metadata ImageMetadata) {
+	info.AltText = metadata.AltText
+	info.FocalPoint = metadata.FocalPoint
+}
+
+func imageMetadataFromRecord(record models.MediaMetadata) ImageMetadata {
+	metadata := ImageMetadata{AltText: record.AltText}
+	if record.FocalX != nil && record.FocalY != nil {
+		metadata.FocalPoint = &FocalPoint{X: *record.FocalX, Y: *record.FocalY}
+	}
+	return metadata
+}
+
+// cacheMediaMetadata updates the cached copy of a record that was just saved.
+func (s *UploadService) cacheMediaMetadata(record models.MediaMetadata) {
+	s.metadataMu.Lock()
+	if s.metadataCache != nil {
+		s.metadataCache[record.Filename] = record
+	}
+	s.metadataMu.Unlock()
+}
+
+// loadMediaMetadata returns all stored metadata keyed by filename. The whole table is
+// read at once: it has one small row per upload, and pages look up many images.
+func (s *UploadService) loadMediaMetadata() map[string]models.MediaMetadata {
+	if s == nil {
+		return nil
+	}
+
+	s.metadataMu.RLock()
+	repo, cache, loadedAt := s.metadataRepo, s.metadataCache, s.metadataLoadedAt
+	s.metadataMu.RUnlock()
+	if repo == nil {
+		return nil
+	}
+	if cache != nil && time.Since(loadedAt) < mediaMetadataTTL {
+		return cache
+	}
+
+	records, err := repo.List()
+	if err != nil {
+		logger.Error(err, "Failed to load media metadata", nil)
+		return cache
+	}
+	loaded := make(map[string]models.MediaMetadata, len(records))
+	for _, record := range records {
+		loaded[record.Filename] = record
+	}
+
+	s.metadataMu.Lock()
+	s.metadataCache = loaded
+	s.metadataLoadedAt = time.Now()
+	s.metadataMu.Unlock()
+	return loaded
+}
+
+// renameMediaMetadata moves stored metadata along with a renamed upload.
+func (s *UploadService) renameMediaMetadata(from, to string) {
+	s.metadataMu.Lock()
+	repo := s.metadataRepo
+	if record, ok := s.metadataCache[from]; ok {
+		delete(s.metadataCache, from)
+		record.Filename = to
+		s.metadataCache[to] = record
+	}
+	s.metadataMu.Unlock()
+
+	if repo == nil {
+		return
+	}
+	if err := repo.Rename(from, to); err != nil {
+		logger.Error(err, "Failed to move media metadata to renamed upload", map[string]interface{}{"from": from, "to": to})
+	}
+}
+
+// removeMediaMetadata deletes stored metadata for a deleted upload.
+func (s *UploadService) removeMediaMetadata(filename string) {
+	s.metadataMu.Lock()
+	repo := s.metadataRepo
+	delete(s.metadataCache, filename)
+	s.metadataMu.Unlock()
+
+	if repo == nil {
+		return
+	}
+	if err := repo.Delete(filename); err != nil {
+		logger.Error(err, "Failed to remove media metadata", map[string]interface{}{"filename": filename})
+	}
+}
+
+// managedFilename returns the filename of a top-level upload URL, or "".
+func (s *UploadService) managedFilename(url string) string {
+	if s == nil || !s.IsManagedURL(url) {
+		return ""
+	}
+	path := strings.TrimSpace(url)
+	if index := strings.IndexAny(path, "?#"); index >= 0 {
+		path = path[:index]
+	}
+	filename := strings.TrimPrefix(path, "/uploads/")
+	if filename == "" || strings.Contains(filename, "/") {
+		return ""
+	}
+	return filename
+}
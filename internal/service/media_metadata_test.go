@@ -0,0 +1,143 @@
+package service
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"constructor-script-backend/internal/models"
+)
+
+type memoryMediaRepository struct {
+	records    map[string]models.MediaMetadata
+	references map[string]int
+}
+
+func (r *memoryMediaRepository) List() ([]models.MediaMetadata, error) {
+	records := make([]models.MediaMetadata, 0, len(r.records))
+	for _, record := range r.records {
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func (r *memoryMediaRepository) Save(metadata *models.MediaMetadata) error {
+	record := r.records[metadata.Filename]
+	record.Filename = metadata.Filename
+	record.AltText, record.FocalX, record.FocalY = metadata.AltText, metadata.FocalX, metadata.FocalY
+	r.records[metadata.Filename] = record
+	return nil
+}
+
+func (r *memoryMediaRepository) SaveVideo(metadata *models.MediaMetadata) error {
+	record := r.records[metadata.Filename]
+	record.Filename = metadata.Filename
+	record.DurationSeconds, record.Width, record.Height = metadata.DurationSeconds, metadata.Width, metadata.Height
+	record.PosterURL = metadata.PosterURL
+	r.records[metadata.Filename] = record
+	return nil
+}
+
+func (r *memoryMediaRepository) Rename(from, to string) error {
+	if record, ok := r.records[from]; ok {
+		delete(r.records, from)
+		record.Filename = to
+		r.records[to] = record
+	}
+	return nil
+}
+
+func (r *memoryMediaRepository) Delete(filename string) error {
+	delete(r.records, filename)
+	return nil
+}
+
+func (r *memoryMediaRepository) UploadReferences() (map[string]int, error) {
+	return r.references, nil
+}
+
+func TestImageMetadataFollowsUploadLifecycle(t *testing.T) {
+	uploadDir := t.TempDir()
+	repo := &memoryMediaRepository{records: map[string]models.MediaMetadata{}}
+	svc := NewUploadService(uploadDir)
+	svc.SetMetadataRepository(repo)
+
+	if err := os.WriteFile(filepath.Join(uploadDir, "team.png"), []byte("png"), 0o644); err != nil {
+		t.Fatalf("write upload: %v", err)
+	}
+
+	alt := "  The team at the summit  "
+	if _, err := svc.UpdateImageMetadata("team.png", ImageMetadataUpdate{FocalPoint: &FocalPoint{X: 1.5, Y: 0}}); !errors.Is(err, ErrInvalidFocalPoint) {
+		t.Fatalf("expected ErrInvalidFocalPoint, got %v", err)
+	}
+	info, err := svc.UpdateImageMetadata("/uploads/team.png", ImageMetadataUpdate{AltText: &alt, FocalPoint: &FocalPoint{X: 0.25, Y: 0.75}})
+	if err != nil {
+		t.Fatalf("update metadata: %v", err)
+	}
+	if info.AltText != "The team at the summit" || info.FocalPoint == nil || info.FocalPoint.ObjectPosition() != "25% 75%" {
+		t.Fatalf("unexpected upload info: %+v", info)
+	}
+
+	if _, err := svc.RenameUpload("team.png", "summit"); err != nil {
+		t.Fatalf("rename: %v", err)
+	}
+	if _, ok := svc.ImageMetadata("/uploads/team.png"); ok {
+		t.Fatal("expected metadata to move with the renamed upload")
+	}
+	metadata, ok := svc.ImageMetadata("/uploads/summit.png")
+	if !ok || metadata.AltText != "The team at the summit" {
+		t.Fatalf("unexpected metadata after rename: %+v", metadata)
+	}
+
+	if err := svc.DeleteUpload("summit.png"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if len(repo.records) != 0 {
+		t.Fatalf("expected metadata to be removed, got %+v", repo.records)
+	}
+}
+
+func TestVideoMetadataIsListedAndCleanedUp(t *testing.T) {
+	uploadDir := t.TempDir()
+	repo := &memoryMediaRepository{records: map[string]models.MediaMetadata{}}
+	svc := NewUploadService(uploadDir)
+	svc.SetMetadataRepository(repo)
+
+	if err := os.WriteFile(filepath.Join(uploadDir, "lesson.mp4"), []byte("mp4"), 0o644); err != nil {
+		t.Fatalf("write upload: %v", err)
+	}
+	poster := filepath.Join(svc.variantDir("lesson.mp4"), videoPosterName)
+	if err := os.MkdirAll(filepath.Dir(poster), 0o755); err != nil {
+		t.Fatalf("create poster dir: %v", err)
+	}
+	if err := os.WriteFile(poster, []byte("jpg"), 0o644); err != nil {
+		t.Fatalf("write poster: %v", err)
+	}
+
+	posterURL := svc.variantURL("lesson.mp4", videoPosterName)
+	svc.saveVideoMetadata("lesson.mp4", VideoMetadata{DurationSeconds: 95, Width: 1920, Height: 1080, PosterURL: posterURL})
+
+	metadata, ok := svc.VideoMetadata("/uploads/lesson.mp4")
+	if !ok || metadata.DurationSeconds != 95 || metadata.PosterURL != "/uploads/variants/lesson.mp4/poster.jpg" {
+		t.Fatalf("unexpected video metadata: %+v", metadata)
+	}
+
+	uploads, err := svc.ListUploads()
+	if err != nil {
+		t.Fatalf("list uploads: %v", err)
+	}
+	if len(uploads) != 1 || uploads[0].PosterURL != posterURL || uploads[0].DurationSeconds != 95 {
+		t.Fatalf("expected listing to include poster and duration, got %+v", uploads)
+	}
+
+	if err := svc.DeleteUpload("lesson.mp4"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := os.Stat(poster); !os.IsNotExist(err) {
+		t.Fatalf("expected poster to be removed with the video, got %v", err)
+	}
+	if len(repo.records) != 0 {
+		t.Fatalf("expected metadata to be removed, got %+v", repo.records)
+	}
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 	"unicode"
 
@@ -9,10 +10,14 @@ import (
 
 	"constructor-script-backend/internal/models"
 	"constructor-script-backend/internal/repository"
+	"constructor-script-backend/pkg/lang"
 )
 
 const defaultMenuLocation = "header"
 
+// ErrInvalidMenuItem is returned by ReplaceAll for an item that fails validation.
+var ErrInvalidMenuItem = errors.New("invalid menu item")
+
 type MenuService struct {
 	repo repository.MenuRepository
 }
@@ -24,6 +29,11 @@ func NewMenuService(repo repository.MenuRepository) *MenuService {
 	return &MenuService{repo: repo}
 }
 
+// withTx returns a copy of s that writes through tx.
+func (s *MenuService) withTx(tx *gorm.DB) *MenuService {
+	return &MenuService{repo: repository.NewMenuRepository(tx)}
+}
+
 func (s *MenuService) List() ([]models.MenuItem, error) {
 	if s == nil || s.repo == nil {
 		return nil, errors.New("menu repository not configured")
@@ -39,11 +49,53 @@ func (s *MenuService) ListPublic() ([]models.MenuItem, error) {
 	return s.List()
 }
 
+// ListForLanguage returns the menu set shown to visitors reading language.
+func (s *MenuService) ListForLanguage(language string) ([]models.MenuItem, error) {
+	items, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	return models.MenuItemsForLanguage(items, language), nil
+}
+
 func (s *MenuService) Create(req models.CreateMenuItemRequest) (*models.MenuItem, error) {
 	if s == nil || s.repo == nil {
 		return nil, errors.New("menu repository not configured")
 	}
 
+	item, err := s.newItem(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(item); err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+// ReplaceAll validates every request before swapping the whole menu for them in
+// one transaction.
+func (s *MenuService) ReplaceAll(reqs []models.CreateMenuItemRequest) error {
+	if s == nil || s.repo == nil {
+		return errors.New("menu repository not configured")
+	}
+
+	items := make([]models.MenuItem, 0, len(reqs))
+	for i, req := range reqs {
+		if req.Order == nil {
+			return fmt.Errorf("%w %d: order is required", ErrInvalidMenuItem, i+1)
+		}
+		item, err := s.newItem(req)
+		if err != nil {
+			return fmt.Errorf("%w %d: %v", ErrInvalidMenuItem, i+1, err)
+		}
+		items = append(items, *item)
+	}
+	return s.repo.ReplaceAll(items)
+}
+
+func (s *MenuService) newItem(req models.CreateMenuItemRequest) (*models.MenuItem, error) {
 	title := strings.TrimSpace(req.Title)
 	url := strings.TrimSpace(req.URL)
 	location := normalizeMenuLocation(req.Location)
@@ -55,6 +107,16 @@ func (s *MenuService) Create(req models.CreateMenuItemRequest) (*models.MenuItem
 		return nil, errors.New("url is required")
 	}
 
+	translations, err := req.Translations.Normalize()
+	if err != nil {
+		return nil, errors.New("translations must be keyed by language code")
+	}
+
+	language, err := lang.NormalizeOptional(req.Language)
+	if err != nil {
+		return nil, errors.New("language must be a valid language code")
+	}
+
 	order := 0
 	if req.Order != nil {
 		order = *req.Order
@@ -67,18 +129,15 @@ func (s *MenuService) Create(req models.CreateMenuItemRequest) (*models.MenuItem
 	}
 
 	item := &models.MenuItem{
-		Title:    title,
-		Label:    title,
-		URL:      url,
-		Location: location,
-		Order:    order,
+		Title:        title,
+		Label:        title,
+		URL:          url,
+		Location:     location,
+		Order:        order,
+		Translations: translations,
+		Language:     language,
 	}
 	item.EnsureTextFields()
-
-	if err := s.repo.Create(item); err != nil {
-		return nil, err
-	}
-
 	return item, nil
 }
 
@@ -105,6 +164,22 @@ func (s *MenuService) Update(id uint, req models.UpdateMenuItemRequest) (*models
 		return nil, errors.New("url is required")
 	}
 
+	if req.Translations != nil {
+		translations, err := req.Translations.Normalize()
+		if err != nil {
+			return nil, errors.New("translations must be keyed by language code")
+		}
+		item.Translations = translations
+	}
+
+	if req.Language != nil {
+		language, err := lang.NormalizeOptional(*req.Language)
+		if err != nil {
+			return nil, errors.New("language must be a valid language code")
+		}
+		item.Language = language
+	}
+
 	item.Title = title
 	item.Label = title
 	item.URL = url
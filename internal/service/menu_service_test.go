@@ -0,0 +1,41 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/repository"
+)
+
+type memoryMenuRepository struct {
+	repository.MenuRepository
+	items []models.MenuItem
+}
+
+func (r *memoryMenuRepository) ReplaceAll(items []models.MenuItem) error {
+	r.items = items
+	return nil
+}
+
+func TestMenuReplaceAllValidatesFirst(t *testing.T) {
+	existing := []models.MenuItem{{Title: "Home", URL: "/"}}
+	repo := &memoryMenuRepository{items: existing}
+	svc := NewMenuService(repo)
+	order := 1
+
+	err := svc.ReplaceAll([]models.CreateMenuItemRequest{
+		{Title: "Blog", URL: "/blog", Order: &order},
+		{Title: "Broken", Order: &order},
+	})
+	if !errors.Is(err, ErrInvalidMenuItem) || len(repo.items) != 1 || repo.items[0].Title != "Home" {
+		t.Fatalf("expected an invalid item to leave the menu alone, got %v and %+v", err, repo.items)
+	}
+
+	if err := svc.ReplaceAll([]models.CreateMenuItemRequest{{Title: " Blog ", URL: "/blog", Order: &order}}); err != nil {
+		t.Fatalf("replace: %v", err)
+	}
+	if len(repo.items) != 1 || repo.items[0].Title != "Blog" || repo.items[0].Location != defaultMenuLocation {
+		t.Fatalf("unexpected menu %+v", repo.items)
+	}
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"fmt"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/pkg/logger"
+)
+
+// notificationEvent describes an event users can get notifications for, the
+// channels it is sent on and whether each is on until the user decides.
+type notificationEvent struct {
+	Event    string
+	Label    string
+	Defaults map[string]bool
+}
+
+var notificationEvents = []notificationEvent{
+	{
+		Event: models.NotificationCourseAnnouncement,
+		Label: "Course announcements",
+		Defaults: map[string]bool{
+			models.NotificationChannelInApp: true,
+			models.NotificationChannelEmail: true,
+		},
+	},
+	{
+		Event:    models.NotificationCourseAccessExpiring,
+		Label:    "Course access about to expire",
+		Defaults: map[string]bool{models.NotificationChannelEmail: true},
+	},
+	{
+		Event:    models.NotificationForumAnswer,
+		Label:    "Answers to my forum questions",
+		Defaults: map[string]bool{models.NotificationChannelEmail: true},
+	},
+	{
+		Event:    models.NotificationPostComment,
+		Label:    "Comments on my posts",
+		Defaults: map[string]bool{models.NotificationChannelEmail: true},
+	},
+}
+
+func findNotificationEvent(event string) (notificationEvent, bool) {
+	for _, definition := range notificationEvents {
+		if definition.Event == event {
+			return definition, true
+		}
+	}
+	return notificationEvent{}, false
+}
+
+type NotificationPreferenceValidationError struct {
+	Reason string
+}
+
+func (e *NotificationPreferenceValidationError) Error() string {
+	if e == nil {
+		return ""
+	}
+	return e.Reason
+}
+
+func notificationPreferenceValidationErrorf(format string, args ...interface{}) error {
+	return &NotificationPreferenceValidationError{Reason: fmt.Sprintf(format, args...)}
+}
+
+// Preferences returns the user's preferences matrix, one entry per event.
+func (s *NotificationService) Preferences(userID uint) ([]models.NotificationEventPreferences, error) {
+	if s == nil || s.preferences == nil {
+		return nil, ErrNotificationsUnavailable
+	}
+
+	stored, err := s.preferences.ListByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	chosen := make(map[[2]string]bool, len(stored))
+	for _, preference := range stored {
+		chosen[[2]string{preference.Event, preference.Channel}] = preference.Enabled
+	}
+
+	matrix := make([]models.NotificationEventPreferences, 0, len(notificationEvents))
+	for _, definition := range notificationEvents {
+		channels := make(map[string]bool, len(definition.Defaults))
+		for channel, enabled := range definition.Defaults {
+			if value, ok := chosen[[2]string{definition.Event, channel}]; ok {
+				enabled = value
+			}
+			channels[channel] = enabled
+		}
+		matrix = append(matrix, models.NotificationEventPreferences{
+			Event:    definition.Event,
+			Label:    definition.Label,
+			Channels: channels,
+		})
+	}
+	return matrix, nil
+}
+
+// UpdatePreferences stores the cells of the matrix given in req and returns the
+// whole matrix.
+func (s *NotificationService) UpdatePreferences(userID uint, req models.UpdateNotificationPreferencesRequest) ([]models.NotificationEventPreferences, error) {
+	if s == nil || s.preferences == nil {
+		return nil, ErrNotificationsUnavailable
+	}
+
+	var preferences []models.NotificationPreference
+	for event, channels := range req.Preferences {
+		definition, ok := findNotificationEvent(event)
+		if !ok {
+			return nil, notificationPreferenceValidationErrorf("unknown notification event %q", event)
+		}
+		for channel, enabled := range channels {
+			if _, ok := definition.Defaults[channel]; !ok {
+				return nil, notificationPreferenceValidationErrorf("%s notifications are not sent by %s", event, channel)
+			}
+			preferences = append(preferences, models.NotificationPreference{
+				UserID:  userID,
+				Event:   event,
+				Channel: channel,
+				Enabled: enabled,
+			})
+		}
+	}
+
+	if err := s.preferences.Save(preferences); err != nil {
+		return nil, err
+	}
+	return s.Preferences(userID)
+}
+
+// Allows reports whether the user wants notifications of the event on the
+// channel. Events without preferences are always allowed. When the preferences
+// cannot be read nothing is sent, so a user who opted out is never contacted.
+func (s *NotificationService) Allows(userID uint, event, channel string) bool {
+	allowed, err := s.AllowedUsers([]uint{userID}, event, channel)
+	if err != nil {
+		logger.Warn("Failed to load notification preferences", map[string]interface{}{
+			"user_id": userID,
+			"event":   event,
+			"channel": channel,
+			"error":   err.Error(),
+		})
+		return false
+	}
+	return len(allowed) == 1
+}
+
+// AllowedUsers returns the users, in the given order, who want notifications of
+// the event on the channel.
+func (s *NotificationService) AllowedUsers(userIDs []uint, event, channel string) ([]uint, error) {
+	definition, ok := findNotificationEvent(event)
+	if !ok {
+		return userIDs, nil
+	}
+	enabledByDefault, ok := definition.Defaults[channel]
+	if !ok || s == nil || s.preferences == nil || len(userIDs) == 0 {
+		return userIDs, nil
+	}
+
+	stored, err := s.preferences.ListForUsers(userIDs, event, channel)
+	if err != nil {
+		return nil, err
+	}
+	chosen := make(map[uint]bool, len(stored))
+	for _, preference := range stored {
+		chosen[preference.UserID] = preference.Enabled
+	}
+
+	allowed := make([]uint, 0, len(userIDs))
+	for _, userID := range userIDs {
+		enabled, ok := chosen[userID]
+		if !ok {
+			enabled = enabledByDefault
+		}
+		if enabled {
+			allowed = append(allowed, userID)
+		}
+	}
+	return allowed, nil
+}
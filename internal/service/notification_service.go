@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/repository"
+)
+
+const (
+	notificationListLimit = 50
+	// notificationRetention is how long read notifications are kept. Unread ones
+	// stay until the user reads them.
+	notificationRetention = 90 * 24 * time.Hour
+)
+
+var ErrNotificationsUnavailable = errors.New("notification service not configured")
+
+// NotificationList is a page of a user's in-app notifications.
+type NotificationList struct {
+	Notifications []models.Notification `json:"notifications"`
+	Unread        int64                 `json:"unread"`
+}
+
+// NotificationService keeps the in-app notifications users see on the site.
+// It also keeps which notifications each user wants, and on which channels.
+type NotificationService struct {
+	repo        repository.NotificationRepository
+	preferences repository.NotificationPreferenceRepository
+	now         func() time.Time
+}
+
+func NewNotificationService(repo repository.NotificationRepository, preferences repository.NotificationPreferenceRepository) *NotificationService {
+	return &NotificationService{
+		repo:        repo,
+		preferences: preferences,
+		now:         func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Send delivers a copy of notification to each of the users who have not
+// turned off in-app notifications of its kind.
+func (s *NotificationService) Send(userIDs []uint, notification models.Notification) error {
+	if s == nil || s.repo == nil {
+		return ErrNotificationsUnavailable
+	}
+
+	notification.Title = strings.TrimSpace(notification.Title)
+	if notification.Kind == "" || notification.Title == "" {
+		return errors.New("notification kind and title are required")
+	}
+
+	userIDs, err := s.AllowedUsers(userIDs, notification.Kind, models.NotificationChannelInApp)
+	if err != nil {
+		return err
+	}
+
+	createdAt := s.now()
+	seen := make(map[uint]bool, len(userIDs))
+	notifications := make([]models.Notification, 0, len(userIDs))
+	for _, userID := range userIDs {
+		if userID == 0 || seen[userID] {
+			continue
+		}
+		seen[userID] = true
+		entry := notification
+		entry.ID = 0
+		entry.UserID = userID
+		entry.CreatedAt = createdAt
+		entry.ReadAt = nil
+		notifications = append(notifications, entry)
+	}
+	return s.repo.CreateMany(notifications)
+}
+
+// List returns the newest notifications of the user and how many are unread.
+func (s *NotificationService) List(userID uint, unreadOnly bool) (*NotificationList, error) {
+	if s == nil || s.repo == nil {
+		return nil, ErrNotificationsUnavailable
+	}
+
+	notifications, err := s.repo.ListByUser(userID, unreadOnly, notificationListLimit)
+	if err != nil {
+		return nil, err
+	}
+	if notifications == nil {
+		notifications = []models.Notification{}
+	}
+	unread, err := s.repo.CountUnread(userID)
+	if err != nil {
+		return nil, err
+	}
+	return &NotificationList{Notifications: notifications, Unread: unread}, nil
+}
+
+func (s *NotificationService) MarkRead(userID, id uint) error {
+	if s == nil || s.repo == nil {
+		return ErrNotificationsUnavailable
+	}
+	return s.repo.MarkRead(userID, id, s.now())
+}
+
+func (s *NotificationService) MarkAllRead(userID uint) error {
+	if s == nil || s.repo == nil {
+		return ErrNotificationsUnavailable
+	}
+	return s.repo.MarkAllRead(userID, s.now())
+}
+
+// Prune removes the notifications that were read more than 90 days ago.
+func (s *NotificationService) Prune(ctx context.Context) (int64, error) {
+	if s == nil || s.repo == nil {
+		return 0, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	return s.repo.DeleteReadBefore(s.now().Add(-notificationRetention))
+}
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"constructor-script-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type memoryNotificationRepository struct {
+	notifications []models.Notification
+}
+
+func (r *memoryNotificationRepository) CreateMany(notifications []models.Notification) error {
+	for _, notification := range notifications {
+		notification.ID = uint(len(r.notifications) + 1)
+		r.notifications = append(r.notifications, notification)
+	}
+	return nil
+}
+
+func (r *memoryNotificationRepository) ListByUser(userID uint, unreadOnly bool, limit int) ([]models.Notification, error) {
+	var result []models.Notification
+	for _, notification := range r.notifications {
+		if notification.UserID == userID && (!unreadOnly || notification.ReadAt == nil) {
+			result = append(result, notification)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID > result[j].ID })
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (r *memoryNotificationRepository) CountUnread(userID uint) (int64, error) {
+	var count int64
+	for _, notification := range r.notifications {
+		if notification.UserID == userID && notification.ReadAt == nil {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *memoryNotificationRepository) MarkRead(userID, id uint, at time.Time) error {
+	for i := range r.notifications {
+		if r.notifications[i].ID == id && r.notifications[i].UserID == userID {
+			if r.notifications[i].ReadAt == nil {
+				r.notifications[i].ReadAt = &at
+			}
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
+
+func (r *memoryNotificationRepository) MarkAllRead(userID uint, at time.Time) error {
+	for i := range r.notifications {
+		if r.notifications[i].UserID == userID && r.notifications[i].ReadAt == nil {
+			r.notifications[i].ReadAt = &at
+		}
+	}
+	return nil
+}
+
+func (r *memoryNotificationRepository) DeleteReadBefore(cutoff time.Time) (int64, error) {
+	kept := r.notifications[:0]
+	var deleted int64
+	for _, notification := range r.notifications {
+		if notification.ReadAt != nil && notification.ReadAt.Before(cutoff) {
+			deleted++
+			continue
+		}
+		kept = append(kept, notification)
+	}
+	r.notifications = kept
+	return deleted, nil
+}
+
+func TestNotificationSendAndRead(t *testing.T) {
+	repo := &memoryNotificationRepository{}
+	svc := NewNotificationService(repo, nil)
+
+	if err := svc.Send([]uint{1}, models.Notification{Kind: models.NotificationCourseAnnouncement}); err == nil {
+		t.Fatal("expected a notification without a title to be rejected")
+	}
+	notification := models.Notification{Kind: models.NotificationCourseAnnouncement, Title: "Go: Live session moved", Path: "/courses/go"}
+	if err := svc.Send([]uint{1, 2, 1, 0}, notification); err != nil {
+		t.Fatal(err)
+	}
+	if len(repo.notifications) != 2 {
+		t.Fatalf("expected one notification per user, got %d", len(repo.notifications))
+	}
+
+	list, err := svc.List(1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list.Unread != 1 || len(list.Notifications) != 1 || list.Notifications[0].Path != "/courses/go" {
+		t.Fatalf("unexpected notifications %+v", list)
+	}
+
+	if err := svc.MarkRead(2, list.Notifications[0].ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("expected users to only read their own notifications, got %v", err)
+	}
+	if err := svc.MarkRead(1, list.Notifications[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	if list, _ = svc.List(1, true); list.Unread != 0 || len(list.Notifications) != 0 {
+		t.Fatalf("expected no unread notifications, got %+v", list)
+	}
+
+	if err := svc.MarkAllRead(2); err != nil {
+		t.Fatal(err)
+	}
+	svc.now = func() time.Time { return time.Now().UTC().Add(notificationRetention + time.Hour) }
+	if deleted, err := svc.Prune(context.Background()); err != nil || deleted != 2 {
+		t.Fatalf("expected old read notifications to be pruned, got %d, %v", deleted, err)
+	}
+}
+
+type memoryNotificationPreferenceRepository struct {
+	preferences map[[3]interface{}]bool
+}
+
+func (r *memoryNotificationPreferenceRepository) ListByUser(userID uint) ([]models.NotificationPreference, error) {
+	var result []models.NotificationPreference
+	for key, enabled := range r.preferences {
+		if key[0] == userID {
+			result = append(result, models.NotificationPreference{UserID: userID, Event: key[1].(string), Channel: key[2].(string), Enabled: enabled})
+		}
+	}
+	return result, nil
+}
+
+func (r *memoryNotificationPreferenceRepository) ListForUsers(userIDs []uint, event, channel string) ([]models.NotificationPreference, error) {
+	var result []models.NotificationPreference
+	for _, userID := range userIDs {
+		if enabled, ok := r.preferences[[3]interface{}{userID, event, channel}]; ok {
+			result = append(result, models.NotificationPreference{UserID: userID, Event: event, Channel: channel, Enabled: enabled})
+		}
+	}
+	return result, nil
+}
+
+func (r *memoryNotificationPreferenceRepository) Save(preferences []models.NotificationPreference) error {
+	if r.preferences == nil {
+		r.preferences = map[[3]interface{}]bool{}
+	}
+	for _, preference := range preferences {
+		r.preferences[[3]interface{}{preference.UserID, preference.Event, preference.Channel}] = preference.Enabled
+	}
+	return nil
+}
+
+func TestNotificationPreferences(t *testing.T) {
+	repo := &memoryNotificationRepository{}
+	svc := NewNotificationService(repo, &memoryNotificationPreferenceRepository{})
+
+	matrix, err := svc.Preferences(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matrix) != len(notificationEvents) || !matrix[0].Channels[models.NotificationChannelInApp] || !matrix[0].Channels[models.NotificationChannelEmail] {
+		t.Fatalf("expected every channel on by default, got %+v", matrix)
+	}
+
+	var validationErr *NotificationPreferenceValidationError
+	_, err = svc.UpdatePreferences(1, models.UpdateNotificationPreferencesRequest{
+		Preferences: map[string]map[string]bool{models.NotificationForumAnswer: {models.NotificationChannelInApp: false}},
+	})
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected a channel the event is not sent on to be rejected, got %v", err)
+	}
+	_, err = svc.UpdatePreferences(1, models.UpdateNotificationPreferencesRequest{
+		Preferences: map[string]map[string]bool{"unknown": {models.NotificationChannelEmail: false}},
+	})
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected an unknown event to be rejected, got %v", err)
+	}
+
+	matrix, err = svc.UpdatePreferences(1, models.UpdateNotificationPreferencesRequest{
+		Preferences: map[string]map[string]bool{
+			models.NotificationCourseAnnouncement: {models.NotificationChannelInApp: false},
+			models.NotificationForumAnswer:        {models.NotificationChannelEmail: false},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if matrix[0].Channels[models.NotificationChannelInApp] || !matrix[0].Channels[models.NotificationChannelEmail] {
+		t.Fatalf("expected only in-app announcements to be off, got %+v", matrix[0])
+	}
+
+	if svc.Allows(1, models.NotificationForumAnswer, models.NotificationChannelEmail) {
+		t.Fatal("expected forum answer emails to be off for user 1")
+	}
+	if !svc.Allows(2, models.NotificationForumAnswer, models.NotificationChannelEmail) {
+		t.Fatal("expected forum answer emails to be on by default")
+	}
+
+	notification := models.Notification{Kind: models.NotificationCourseAnnouncement, Title: "Go: Live session moved"}
+	if err := svc.Send([]uint{1, 2}, notification); err != nil {
+		t.Fatal(err)
+	}
+	if len(repo.notifications) != 1 || repo.notifications[0].UserID != 2 {
+		t.Fatalf("expected only user 2 to be notified, got %+v", repo.notifications)
+	}
+}
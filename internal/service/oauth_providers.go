@@ -0,0 +1,340 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"constructor-script-backend/internal/models"
+)
+
+const (
+	googleIssuer          = "https://accounts.google.com"
+	gitHubAuthorizeURL    = "https://github.com/login/oauth/authorize"
+	gitHubTokenURL        = "https://github.com/login/oauth/access_token"
+	gitHubAPIURL          = "https://api.github.com"
+	maxOAuthResponseBytes = 1 << 20
+)
+
+// OAuthIdentity is the account a provider says the user signed in with.
+type OAuthIdentity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Username      string
+}
+
+// OAuthProvider signs users in through an OAuth 2.0 authorization code flow
+// with PKCE.
+type OAuthProvider interface {
+	// AuthURL returns where the user is sent to sign in.
+	AuthURL(ctx context.Context, state, challenge, redirectURI string) (string, error)
+	// Identity exchanges the code the provider sent back for the user's
+	// identity.
+	Identity(ctx context.Context, code, verifier, redirectURI string) (*OAuthIdentity, error)
+}
+
+// OAuthProviderFactory builds a provider from the settings an admin saved.
+type OAuthProviderFactory func(settings models.OAuthProviderSettings, client *http.Client) (OAuthProvider, error)
+
+var (
+	oauthFactoriesMu sync.RWMutex
+	oauthFactories   = map[string]OAuthProviderFactory{
+		models.OAuthProviderGoogle: newGoogleProvider,
+		models.OAuthProviderGitHub: newGitHubProvider,
+		models.OAuthProviderOIDC:   newOIDCProvider,
+	}
+)
+
+// RegisterOAuthProviderKind adds a kind of provider admins can configure, or
+// replaces the built-in one of the same kind.
+func RegisterOAuthProviderKind(kind string, factory OAuthProviderFactory) {
+	oauthFactoriesMu.Lock()
+	defer oauthFactoriesMu.Unlock()
+	oauthFactories[strings.ToLower(strings.TrimSpace(kind))] = factory
+}
+
+func oauthProviderFactory(kind string) (OAuthProviderFactory, bool) {
+	oauthFactoriesMu.RLock()
+	defer oauthFactoriesMu.RUnlock()
+	factory, ok := oauthFactories[kind]
+	return factory, ok
+}
+
+// oauthClient holds what every provider needs for the authorization code
+// flow.
+type oauthClient struct {
+	http         *http.Client
+	clientID     string
+	clientSecret string
+	scopes       []string
+}
+
+func newOAuthClient(settings models.OAuthProviderSettings, client *http.Client, defaultScopes ...string) oauthClient {
+	scopes := settings.Scopes
+	if len(scopes) == 0 {
+		scopes = defaultScopes
+	}
+	return oauthClient{http: client, clientID: settings.ClientID, clientSecret: settings.ClientSecret, scopes: scopes}
+}
+
+func (c oauthClient) authURL(endpoint, state, challenge, redirectURI string) (string, error) {
+	target, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+	query := target.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", c.clientID)
+	query.Set("redirect_uri", redirectURI)
+	query.Set("scope", strings.Join(c.scopes, " "))
+	query.Set("state", state)
+	query.Set("code_challenge", challenge)
+	query.Set("code_challenge_method", "S256")
+	target.RawQuery = query.Encode()
+	return target.String(), nil
+}
+
+// exchange trades code for an access token at endpoint.
+func (c oauthClient) exchange(ctx context.Context, endpoint, code, verifier, redirectURI string) (string, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {redirectURI},
+		"client_id":     {c.clientID},
+		"client_secret": {c.clientSecret},
+		"code_verifier": {verifier},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var token struct {
+		AccessToken      string `json:"access_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := c.do(req, &token); err != nil {
+		return "", fmt.Errorf("token exchange failed: %w", err)
+	}
+	if token.Error != "" {
+		return "", fmt.Errorf("token exchange failed: %s %s", token.Error, token.ErrorDescription)
+	}
+	if token.AccessToken == "" {
+		return "", errors.New("token exchange failed: no access token returned")
+	}
+	return token.AccessToken, nil
+}
+
+func (c oauthClient) get(ctx context.Context, endpoint, accessToken string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+	return c.do(req, out)
+}
+
+func (c oauthClient) do(req *http.Request, out interface{}) error {
+	req.Header.Set("Accept", "application/json")
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxOAuthResponseBytes))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		// Token endpoints report errors as JSON with a 400 status.
+		if json.Unmarshal(body, out) == nil && resp.StatusCode == http.StatusBadRequest {
+			return nil
+		}
+		return fmt.Errorf("%s returned status %d", req.URL.Host, resp.StatusCode)
+	}
+	return json.Unmarshal(body, out)
+}
+
+// oidcProvider signs users in with any OpenID Connect provider, finding its
+// endpoints through discovery.
+type oidcProvider struct {
+	client oauthClient
+	issuer string
+
+	mu        sync.Mutex
+	discovery *oidcDiscovery
+}
+
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+}
+
+func newOIDCProvider(settings models.OAuthProviderSettings, client *http.Client) (OAuthProvider, error) {
+	if settings.Issuer == "" {
+		return nil, errors.New("OpenID Connect providers need an issuer")
+	}
+	return &oidcProvider{
+		client: newOAuthClient(settings, client, "openid", "email", "profile"),
+		issuer: strings.TrimRight(settings.Issuer, "/"),
+	}, nil
+}
+
+func newGoogleProvider(settings models.OAuthProviderSettings, client *http.Client) (OAuthProvider, error) {
+	settings.Issuer = googleIssuer
+	return newOIDCProvider(settings, client)
+}
+
+func (p *oidcProvider) discover(ctx context.Context) (*oidcDiscovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	var discovery oidcDiscovery
+	if err := p.client.get(ctx, p.issuer+"/.well-known/openid-configuration", "", &discovery); err != nil {
+		return nil, fmt.Errorf("OpenID Connect discovery failed: %w", err)
+	}
+	if strings.TrimRight(discovery.Issuer, "/") != p.issuer {
+		return nil, fmt.Errorf("OpenID Connect discovery returned issuer %q, expected %q", discovery.Issuer, p.issuer)
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.UserinfoEndpoint == "" {
+		return nil, errors.New("OpenID Connect discovery is missing endpoints")
+	}
+	p.discovery = &discovery
+	return p.discovery, nil
+}
+
+func (p *oidcProvider) AuthURL(ctx context.Context, state, challenge, redirectURI string) (string, error) {
+	discovery, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	return p.client.authURL(discovery.AuthorizationEndpoint, state, challenge, redirectURI)
+}
+
+// Identity reads the user from the userinfo endpoint, which is trusted
+// because it is reached over TLS with the token just issued for this login.
+func (p *oidcProvider) Identity(ctx context.Context, code, verifier, redirectURI string) (*OAuthIdentity, error) {
+	discovery, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	accessToken, err := p.client.exchange(ctx, discovery.TokenEndpoint, code, verifier, redirectURI)
+	if err != nil {
+		return nil, err
+	}
+
+	var info struct {
+		Subject           string      `json:"sub"`
+		Email             string      `json:"email"`
+		EmailVerified     interface{} `json:"email_verified"`
+		Name              string      `json:"name"`
+		PreferredUsername string      `json:"preferred_username"`
+	}
+	if err := p.client.get(ctx, discovery.UserinfoEndpoint, accessToken, &info); err != nil {
+		return nil, fmt.Errorf("failed to load user info: %w", err)
+	}
+	if info.Subject == "" {
+		return nil, errors.New("user info has no subject")
+	}
+
+	// Some providers send email_verified as a string.
+	verified := false
+	switch value := info.EmailVerified.(type) {
+	case bool:
+		verified = value
+	case string:
+		verified, _ = strconv.ParseBool(value)
+	}
+	return &OAuthIdentity{
+		Subject:       info.Subject,
+		Email:         info.Email,
+		EmailVerified: verified,
+		Name:          info.Name,
+		Username:      info.PreferredUsername,
+	}, nil
+}
+
+// gitHubProvider signs users in with GitHub, which supports OAuth but not
+// OpenID Connect.
+type gitHubProvider struct {
+	client       oauthClient
+	authorizeURL string
+	tokenURL     string
+	apiURL       string
+}
+
+func newGitHubProvider(settings models.OAuthProviderSettings, client *http.Client) (OAuthProvider, error) {
+	return &gitHubProvider{
+		client:       newOAuthClient(settings, client, "read:user", "user:email"),
+		authorizeURL: gitHubAuthorizeURL,
+		tokenURL:     gitHubTokenURL,
+		apiURL:       gitHubAPIURL,
+	}, nil
+}
+
+func (p *gitHubProvider) AuthURL(_ context.Context, state, challenge, redirectURI string) (string, error) {
+	return p.client.authURL(p.authorizeURL, state, challenge, redirectURI)
+}
+
+func (p *gitHubProvider) Identity(ctx context.Context, code, verifier, redirectURI string) (*OAuthIdentity, error) {
+	accessToken, err := p.client.exchange(ctx, p.tokenURL, code, verifier, redirectURI)
+	if err != nil {
+		return nil, err
+	}
+
+	var user struct {
+		ID    int64  `json:"id"`
+		Login string `json:"login"`
+		Name  string `json:"name"`
+	}
+	if err := p.client.get(ctx, p.apiURL+"/user", accessToken, &user); err != nil {
+		return nil, fmt.Errorf("failed to load GitHub user: %w", err)
+	}
+	if user.ID == 0 {
+		return nil, errors.New("GitHub returned no user")
+	}
+
+	// The profile email may be hidden or unverified; the primary verified
+	// address is the one to trust.
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := p.client.get(ctx, p.apiURL+"/user/emails", accessToken, &emails); err != nil {
+		return nil, fmt.Errorf("failed to load GitHub emails: %w", err)
+	}
+
+	identity := &OAuthIdentity{
+		Subject:  strconv.FormatInt(user.ID, 10),
+		Name:     user.Name,
+		Username: user.Login,
+	}
+	for _, email := range emails {
+		if email.Primary && email.Verified {
+			identity.Email = email.Email
+			identity.EmailVerified = true
+			break
+		}
+	}
+	return identity, nil
+}
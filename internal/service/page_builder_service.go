@@ -38,6 +38,7 @@ func (s *PageService) DuplicatePage(pageID uint) (*models.Page, error) {
 	if err := s.pageRepo.Create(duplicate); err != nil {
 		return nil, err
 	}
+	s.recordRevision(duplicate)
 
 	return duplicate, nil
 }
@@ -69,6 +70,7 @@ func (s *PageService) ReorderSections(pageID uint, sectionIDs []string) (*models
 	if err := s.pageRepo.Update(page); err != nil {
 		return nil, err
 	}
+	s.recordRevision(page)
 
 	return page, nil
 }
@@ -111,6 +113,7 @@ func (s *PageService) AddSection(pageID uint, req models.AddSectionRequest) (*mo
 	if err := s.pageRepo.Update(page); err != nil {
 		return nil, err
 	}
+	s.recordRevision(page)
 
 	return page, nil
 }
@@ -174,6 +177,7 @@ func (s *PageService) UpdateSection(pageID uint, sectionID string, req models.Up
 	if err := s.pageRepo.Update(page); err != nil {
 		return nil, err
 	}
+	s.recordRevision(page)
 
 	return page, nil
 }
@@ -202,6 +206,7 @@ func (s *PageService) DeleteSection(pageID uint, sectionID string) (*models.Page
 	if err := s.pageRepo.Update(page); err != nil {
 		return nil, err
 	}
+	s.recordRevision(page)
 
 	return page, nil
 }
@@ -254,6 +259,7 @@ func (s *PageService) DuplicateSection(pageID uint, sectionID string) (*models.P
 	if err := s.pageRepo.Update(page); err != nil {
 		return nil, err
 	}
+	s.recordRevision(page)
 
 	return page, nil
 }
@@ -363,6 +369,7 @@ func (s *PageService) CreateFromTemplate(templateID, title, slug string) (*model
 	if err := s.pageRepo.Create(page); err != nil {
 		return nil, err
 	}
+	s.recordRevision(page)
 
 	return page, nil
 }
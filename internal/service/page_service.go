@@ -1,27 +1,37 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"path"
+	"regexp"
 	"strings"
 	"time"
 
 	"constructor-script-backend/internal/constants"
+	"constructor-script-backend/internal/events"
 	"constructor-script-backend/internal/models"
 	"constructor-script-backend/internal/repository"
 	"constructor-script-backend/internal/theme"
 	"constructor-script-backend/pkg/cache"
+	"constructor-script-backend/pkg/lang"
+	"constructor-script-backend/pkg/logger"
 	"constructor-script-backend/pkg/utils"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+var pageLayoutPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]{0,63}$`)
+
 type PageService struct {
-	pageRepo repository.PageRepository
-	cache    *cache.Cache
-	themes   *theme.Manager
+	pageRepo  repository.PageRepository
+	redirects repository.SlugRedirectRepository
+	revisions *RevisionService
+	cache     *cache.Cache
+	themes    *theme.Manager
+	events    *events.Bus
 }
 
 func normalizePagePath(value string) (string, error) {
@@ -87,6 +97,148 @@ func NewPageService(pageRepo repository.PageRepository, cacheService *cache.Cach
 	}
 }
 
+// SetEventBus configures the bus used to announce page changes.
+func (s *PageService) SetEventBus(bus *events.Bus) {
+	if s == nil {
+		return
+	}
+	s.events = bus
+}
+
+// withTx returns a copy of s that writes through tx and announces its changes on
+// bus. The copy leaves the cache alone, since readers could cache rows the
+// transaction has not committed yet; see invalidatePages.
+func (s *PageService) withTx(tx *gorm.DB, bus *events.Bus) *PageService {
+	scoped := *s
+	scoped.pageRepo = repository.NewPageRepository(tx)
+	if s.redirects != nil {
+		scoped.redirects = repository.NewSlugRedirectRepository(tx)
+	}
+	scoped.revisions = s.revisions.WithTx(tx)
+	scoped.cache = nil
+	scoped.events = bus
+	return &scoped
+}
+
+// invalidatePages drops the cached copies of the pages with ids.
+func (s *PageService) invalidatePages(ids []uint) {
+	if s == nil || s.cache == nil {
+		return
+	}
+	for _, id := range ids {
+		s.cache.InvalidatePage(id)
+	}
+	s.cache.Delete("pages:all")
+	s.cache.DeletePattern("page:path:*")
+}
+
+// SetSlugRedirects configures where the previous URLs of renamed pages are kept.
+func (s *PageService) SetSlugRedirects(redirects repository.SlugRedirectRepository) {
+	if s == nil {
+		return
+	}
+	s.redirects = redirects
+}
+
+// SetRevisions configures where the history of pages is kept. The service
+// restores page revisions through s.
+func (s *PageService) SetRevisions(revisions *RevisionService) {
+	if s == nil {
+		return
+	}
+	s.revisions = revisions
+	revisions.SetRestorer(models.RevisionContentPage, s)
+}
+
+// recordRevision saves the current content of page to its history.
+func (s *PageService) recordRevision(page *models.Page) {
+	if s == nil || s.revisions == nil || page == nil {
+		return
+	}
+	err := s.revisions.Record(models.Revision{
+		ContentType: models.RevisionContentPage,
+		ContentID:   page.ID,
+		Title:       page.Title,
+		Description: page.Description,
+		Content:     page.Content,
+		Sections:    page.Sections,
+	})
+	if err != nil {
+		logger.Error(err, "Failed to record page revision", map[string]interface{}{"page_id": page.ID})
+	}
+}
+
+// RestoreRevision writes the content of a page revision back to the page.
+func (s *PageService) RestoreRevision(revision *models.Revision) error {
+	sections := []models.Section(revision.Sections)
+	if sections == nil {
+		sections = []models.Section{}
+	}
+	_, err := s.Update(revision.ContentID, models.UpdatePageRequest{
+		Title:       &revision.Title,
+		Description: &revision.Description,
+		Content:     &revision.Content,
+		Sections:    &sections,
+	})
+	return err
+}
+
+// recordSlugRedirects remembers the URLs a page was reachable under before a
+// rename, and releases any redirect now claimed by the page's current URLs.
+func (s *PageService) recordSlugRedirects(page *models.Page, previousSlug, previousPath string) {
+	if s == nil || s.redirects == nil || page == nil {
+		return
+	}
+
+	var previous []string
+	if previousPath != "" && previousPath != "/" && previousPath != page.Path {
+		previous = append(previous, previousPath)
+	}
+	if previousSlug != "" && previousSlug != page.Slug {
+		previous = append(previous, "/page/"+previousSlug)
+	}
+	for _, path := range previous {
+		if err := s.redirects.Record(models.SlugRedirectPage, page.ID, path); err != nil {
+			logger.Error(err, "Failed to record page redirect", map[string]interface{}{"page_id": page.ID, "path": path})
+		}
+	}
+
+	for _, path := range []string{page.Path, "/page/" + page.Slug} {
+		if err := s.redirects.DeleteByPath(path); err != nil {
+			logger.Error(err, "Failed to release page redirect", map[string]interface{}{"page_id": page.ID, "path": path})
+		}
+	}
+}
+
+// ResolveSlugRedirect returns the published page that used to live at path.
+func (s *PageService) ResolveSlugRedirect(path string) (*models.Page, bool) {
+	if s == nil || s.redirects == nil {
+		return nil, false
+	}
+	redirect, err := s.redirects.GetByPath(path)
+	if err != nil || redirect.ContentType != models.SlugRedirectPage {
+		return nil, false
+	}
+	page, err := s.GetByID(redirect.ContentID)
+	if err != nil || page.Path == "" || page.Path == path {
+		return nil, false
+	}
+	return page, true
+}
+
+func (s *PageService) publishPageEvent(name string, page *models.Page) {
+	if s == nil || page == nil {
+		return
+	}
+	s.events.Publish(context.Background(), name, map[string]interface{}{
+		"id":        page.ID,
+		"title":     page.Title,
+		"slug":      page.Slug,
+		"path":      page.Path,
+		"published": page.Published,
+	})
+}
+
 func (s *PageService) Create(req models.CreatePageRequest) (*models.Page, error) {
 	if strings.TrimSpace(req.Title) == "" {
 		return nil, errors.New("page title is required")
@@ -132,6 +284,16 @@ func (s *PageService) Create(req models.CreatePageRequest) (*models.Page, error)
 		return nil, fmt.Errorf("failed to prepare sections: %w", err)
 	}
 
+	pageTheme, layout, err := s.resolvePageTheme(req.Theme, req.Layout)
+	if err != nil {
+		return nil, err
+	}
+
+	language, err := lang.NormalizeOptional(req.Language)
+	if err != nil {
+		return nil, err
+	}
+
 	page := &models.Page{
 		Title:       strings.TrimSpace(req.Title),
 		Slug:        slug,
@@ -142,8 +304,17 @@ func (s *PageService) Create(req models.CreatePageRequest) (*models.Page, error)
 		Content:     strings.TrimSpace(req.Content),
 		Sections:    sections,
 		Template:    s.getTemplate(req.Template),
+		Theme:       pageTheme,
+		Layout:      layout,
 		HideHeader:  req.HideHeader,
 		Order:       req.Order,
+
+		Language:       language,
+		TranslationKey: utils.GenerateSlug(req.TranslationKey),
+
+		NoIndex:        req.NoIndex,
+		NoFollow:       req.NoFollow,
+		SitemapExclude: req.SitemapExclude,
 	}
 
 	now := time.Now().UTC()
@@ -157,6 +328,10 @@ func (s *PageService) Create(req models.CreatePageRequest) (*models.Page, error)
 		s.cache.Delete("pages:all")
 	}
 
+	s.recordSlugRedirects(page, "", "")
+	s.recordRevision(page)
+	s.publishPageEvent(events.PageCreated, page)
+
 	return s.pageRepo.GetByID(page.ID)
 }
 
@@ -322,6 +497,23 @@ func (s *PageService) Update(id uint, req models.UpdatePageRequest) (*models.Pag
 	if req.Template != nil {
 		page.Template = s.getTemplate(*req.Template)
 	}
+	if req.Theme != nil || req.Layout != nil {
+		themeValue := page.Theme
+		if req.Theme != nil {
+			themeValue = *req.Theme
+		}
+		layoutValue := page.Layout
+		if req.Layout != nil {
+			layoutValue = *req.Layout
+		}
+
+		pageTheme, layout, err := s.resolvePageTheme(themeValue, layoutValue)
+		if err != nil {
+			return nil, err
+		}
+		page.Theme = pageTheme
+		page.Layout = layout
+	}
 
 	publishAtCandidate := req.PublishAt.Or(page.PublishAt)
 	now := time.Now().UTC()
@@ -335,6 +527,25 @@ func (s *PageService) Update(id uint, req models.UpdatePageRequest) (*models.Pag
 	if req.Content != nil {
 		page.Content = strings.TrimSpace(*req.Content)
 	}
+	if req.Language != nil {
+		language, err := lang.NormalizeOptional(*req.Language)
+		if err != nil {
+			return nil, err
+		}
+		page.Language = language
+	}
+	if req.TranslationKey != nil {
+		page.TranslationKey = utils.GenerateSlug(*req.TranslationKey)
+	}
+	if req.NoIndex != nil {
+		page.NoIndex = *req.NoIndex
+	}
+	if req.NoFollow != nil {
+		page.NoFollow = *req.NoFollow
+	}
+	if req.SitemapExclude != nil {
+		page.SitemapExclude = *req.SitemapExclude
+	}
 
 	if req.Sections != nil {
 		sections, err := s.prepareSections(*req.Sections)
@@ -384,6 +595,10 @@ func (s *PageService) Update(id uint, req models.UpdatePageRequest) (*models.Pag
 		}
 	}
 
+	s.recordSlugRedirects(page, originalSlug, originalPath)
+	s.recordRevision(page)
+	s.publishPageEvent(events.PageUpdated, page)
+
 	return s.pageRepo.GetByID(page.ID)
 }
 
@@ -425,6 +640,7 @@ func (s *PageService) UpdateAllSectionPadding(padding int) (int, int, int, error
 		if err := s.pageRepo.Update(page); err != nil {
 			return pagesUpdated, sectionsUpdated, normalized, fmt.Errorf("failed to update page %d: %w", page.ID, err)
 		}
+		s.recordRevision(page)
 
 		pagesUpdated++
 		if s.cache != nil {
@@ -460,22 +676,24 @@ func (s *PageService) Delete(id uint) error {
 		}
 	}
 
+	if s.redirects != nil {
+		if err := s.redirects.DeleteByContent(models.SlugRedirectPage, page.ID); err != nil {
+			logger.Error(err, "Failed to delete page redirects", map[string]interface{}{"page_id": page.ID})
+		}
+	}
+	if err := s.revisions.DeleteContent(models.RevisionContentPage, page.ID); err != nil {
+		logger.Error(err, "Failed to delete page revisions", map[string]interface{}{"page_id": page.ID})
+	}
+
+	s.publishPageEvent(events.PageDeleted, page)
+
 	return nil
 }
 
 func (s *PageService) GetByID(id uint) (*models.Page, error) {
-	if s.cache != nil {
-		var page models.Page
-		cacheKey := fmt.Sprintf("page:%d", id)
-		if err := s.cache.Get(cacheKey, &page); err == nil {
-			if !page.Published {
-				return nil, gorm.ErrRecordNotFound
-			}
-			return &page, nil
-		}
-	}
-
-	page, err := s.pageRepo.GetByID(id)
+	page, err := s.loadPage(fmt.Sprintf("page:%d", id), func() (*models.Page, error) {
+		return s.pageRepo.GetByID(id)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -484,32 +702,35 @@ func (s *PageService) GetByID(id uint) (*models.Page, error) {
 		return nil, gorm.ErrRecordNotFound
 	}
 
-	if s.cache != nil {
-		s.cachePage(page)
-	}
-
 	return page, nil
 }
 
 func (s *PageService) GetBySlug(slug string) (*models.Page, error) {
-	if s.cache != nil {
-		var page models.Page
-		cacheKey := fmt.Sprintf("page:slug:%s", slug)
-		if err := s.cache.Get(cacheKey, &page); err == nil {
-			return &page, nil
-		}
+	return s.loadPage(fmt.Sprintf("page:slug:%s", slug), func() (*models.Page, error) {
+		return s.pageRepo.GetBySlug(slug)
+	})
+}
+
+// loadPage reads a page from the cache, or through fetch when it is missing. Callers
+// arriving while fetch runs share its result instead of querying again.
+func (s *PageService) loadPage(cacheKey string, fetch func() (*models.Page, error)) (*models.Page, error) {
+	if s.cache == nil {
+		return fetch()
 	}
 
-	page, err := s.pageRepo.GetBySlug(slug)
+	var page models.Page
+	err := s.cache.Load(cacheKey, &page, func() (interface{}, error) {
+		loaded, err := fetch()
+		if err != nil {
+			return nil, err
+		}
+		s.cachePage(loaded)
+		return loaded, nil
+	})
 	if err != nil {
 		return nil, err
 	}
-
-	if s.cache != nil {
-		s.cachePage(page)
-	}
-
-	return page, nil
+	return &page, nil
 }
 
 func (s *PageService) GetByPath(requestedPath string) (*models.Page, error) {
@@ -521,24 +742,9 @@ func (s *PageService) GetByPath(requestedPath string) (*models.Page, error) {
 		normalizedPath = "/"
 	}
 
-	if s.cache != nil {
-		var page models.Page
-		cacheKey := fmt.Sprintf("page:path:%s", normalizedPath)
-		if err := s.cache.Get(cacheKey, &page); err == nil {
-			return &page, nil
-		}
-	}
-
-	page, err := s.pageRepo.GetByPath(normalizedPath)
-	if err != nil {
-		return nil, err
-	}
-
-	if s.cache != nil {
-		s.cachePage(page)
-	}
-
-	return page, nil
+	return s.loadPage(fmt.Sprintf("page:path:%s", normalizedPath), func() (*models.Page, error) {
+		return s.pageRepo.GetByPath(normalizedPath)
+	})
 }
 
 func (s *PageService) GetByPathAny(requestedPath string) (*models.Page, error) {
@@ -591,6 +797,27 @@ func (s *PageService) GetAll() ([]models.Page, error) {
 	return pages, nil
 }
 
+// GetTranslations returns the published pages that share translationKey.
+func (s *PageService) GetTranslations(translationKey string) ([]models.Page, error) {
+	translationKey = strings.TrimSpace(translationKey)
+	if translationKey == "" {
+		return nil, nil
+	}
+
+	pages, err := s.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	translations := make([]models.Page, 0, 2)
+	for _, page := range pages {
+		if page.TranslationKey == translationKey {
+			translations = append(translations, page)
+		}
+	}
+	return translations, nil
+}
+
 func (s *PageService) GetAllAdmin() ([]models.Page, error) {
 	return s.pageRepo.GetAllAdmin()
 }
@@ -738,6 +965,38 @@ func (s *PageService) prepareSectionElements(
 	return prepareSectionElements(elements, definitions, allowed)
 }
 
+// resolvePageTheme validates the theme and layout selected for a page. An empty theme
+// keeps the site default; the layout must be a template shipped by the resolved theme.
+func (s *PageService) resolvePageTheme(themeSlug, layout string) (string, string, error) {
+	themeSlug = strings.ToLower(strings.TrimSpace(themeSlug))
+	layout = strings.TrimSuffix(strings.TrimSpace(layout), ".html")
+
+	var target *theme.Theme
+	if themeSlug != "" {
+		if s.themes == nil {
+			return "", "", errors.New("themes are not configured")
+		}
+		resolved, ok := s.themes.Resolve(themeSlug)
+		if !ok {
+			return "", "", fmt.Errorf("theme %q is not installed", themeSlug)
+		}
+		target = resolved
+	} else if s.themes != nil {
+		target = s.themes.Active()
+	}
+
+	if layout != "" {
+		if !pageLayoutPattern.MatchString(layout) {
+			return "", "", fmt.Errorf("invalid page layout %q", layout)
+		}
+		if target != nil && !target.HasTemplate(layout+".html") {
+			return "", "", fmt.Errorf("layout %q is not available in the selected theme", layout)
+		}
+	}
+
+	return themeSlug, layout, nil
+}
+
 func (s *PageService) getTemplate(template string) string {
 	if template == "" {
 		return "page"
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"testing"
+
+	"constructor-script-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type memorySlugRedirects struct {
+	entries map[string]models.SlugRedirect
+}
+
+func (m *memorySlugRedirects) Record(contentType string, contentID uint, path string) error {
+	m.entries[path] = models.SlugRedirect{Path: path, ContentType: contentType, ContentID: contentID}
+	return nil
+}
+
+func (m *memorySlugRedirects) GetByPath(path string) (*models.SlugRedirect, error) {
+	entry, ok := m.entries[path]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &entry, nil
+}
+
+func (m *memorySlugRedirects) DeleteByPath(path string) error {
+	delete(m.entries, path)
+	return nil
+}
+
+func (m *memorySlugRedirects) DeleteByContent(contentType string, contentID uint) error {
+	for path, entry := range m.entries {
+		if entry.ContentType == contentType && entry.ContentID == contentID {
+			delete(m.entries, path)
+		}
+	}
+	return nil
+}
+
+func TestRecordPageSlugRedirects(t *testing.T) {
+	redirects := &memorySlugRedirects{entries: map[string]models.SlugRedirect{}}
+	svc := &PageService{}
+	svc.SetSlugRedirects(redirects)
+
+	page := &models.Page{ID: 7, Slug: "team", Path: "/company/team"}
+	svc.recordSlugRedirects(page, "about", "/about")
+	for _, path := range []string{"/about", "/page/about"} {
+		if entry, ok := redirects.entries[path]; !ok || entry.ContentID != 7 || entry.ContentType != models.SlugRedirectPage {
+			t.Fatalf("expected %s to redirect to page 7, got %+v", path, redirects.entries)
+		}
+	}
+
+	// Moving the page back releases the redirect for the URL it reclaims.
+	page.Slug, page.Path = "about", "/about"
+	svc.recordSlugRedirects(page, "team", "/company/team")
+	if _, ok := redirects.entries["/about"]; ok {
+		t.Fatalf("expected /about to be released, got %+v", redirects.entries)
+	}
+	if _, ok := redirects.entries["/company/team"]; !ok {
+		t.Fatalf("expected /company/team to be recorded, got %+v", redirects.entries)
+	}
+
+	// The homepage path is never turned into a redirect.
+	page.Path = "/home"
+	svc.recordSlugRedirects(page, "about", "/")
+	if _, ok := redirects.entries["/"]; ok {
+		t.Fatal("expected the root path to be left alone")
+	}
+}
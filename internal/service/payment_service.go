@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"constructor-script-backend/internal/config"
+	"constructor-script-backend/internal/events"
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/payments"
+	"constructor-script-backend/internal/payments/stripe"
+	"constructor-script-backend/pkg/logger"
+)
+
+// stripeWebhookTolerance is how old a signed webhook may be before it is rejected.
+const stripeWebhookTolerance = 5 * time.Minute
+
+var (
+	// ErrPaymentWebhookNotConfigured is returned when no webhook signing secret is set.
+	ErrPaymentWebhookNotConfigured = errors.New("payment webhook is not configured")
+	// ErrInvalidPaymentWebhook is returned for webhooks that fail verification or decoding.
+	ErrInvalidPaymentWebhook = errors.New("invalid payment webhook")
+)
+
+// PaymentSettings are the resolved payment provider credentials and currency.
+type PaymentSettings struct {
+	SecretKey      string
+	PublishableKey string
+	WebhookSecret  string
+	Currency       string
+}
+
+// PaymentService is the shared payments module used by every feature that sells
+// something. It resolves the Stripe credentials from the environment and the site
+// settings, and routes paid checkout sessions to the feature that created them.
+type PaymentService struct {
+	config       *config.Config
+	setup        *SetupService
+	fulfillments *payments.Fulfillments
+}
+
+func NewPaymentService(cfg *config.Config, setup *SetupService) *PaymentService {
+	return &PaymentService{
+		config:       cfg,
+		setup:        setup,
+		fulfillments: payments.NewFulfillments(),
+	}
+}
+
+// Settings returns the current credentials. Values stored in the site settings take
+// precedence over the environment; keys that do not look like Stripe keys are dropped.
+func (s *PaymentService) Settings() PaymentSettings {
+	var settings PaymentSettings
+	if s == nil {
+		return settings
+	}
+
+	if s.config != nil {
+		settings = PaymentSettings{
+			SecretKey:      strings.TrimSpace(s.config.StripeSecretKey),
+			PublishableKey: strings.TrimSpace(s.config.StripePublishableKey),
+			WebhookSecret:  strings.TrimSpace(s.config.StripeWebhookSecret),
+			Currency:       strings.TrimSpace(s.config.CourseCheckoutCurrency),
+		}
+	}
+
+	if s.setup != nil {
+		defaults := models.SiteSettings{
+			StripeSecretKey:        settings.SecretKey,
+			StripePublishableKey:   settings.PublishableKey,
+			StripeWebhookSecret:    settings.WebhookSecret,
+			CourseCheckoutCurrency: settings.Currency,
+		}
+		if site, err := s.setup.GetSiteSettings(defaults); err != nil {
+			logger.Error(err, "Failed to load site settings for payments", nil)
+		} else {
+			if key := strings.TrimSpace(site.StripeSecretKey); key != "" {
+				settings.SecretKey = key
+			}
+			if key := strings.TrimSpace(site.StripePublishableKey); key != "" {
+				settings.PublishableKey = key
+			}
+			if key := strings.TrimSpace(site.StripeWebhookSecret); key != "" {
+				settings.WebhookSecret = key
+			}
+			if currency := strings.TrimSpace(site.CourseCheckoutCurrency); currency != "" {
+				settings.Currency = currency
+			}
+		}
+	}
+	settings.Currency = strings.ToLower(settings.Currency)
+
+	if settings.SecretKey != "" && !stripe.IsSecretKey(settings.SecretKey) {
+		logger.Warn("Invalid Stripe secret key provided; checkout disabled", nil)
+		settings.SecretKey = ""
+	}
+	if settings.PublishableKey != "" && !stripe.IsPublishableKey(settings.PublishableKey) {
+		logger.Warn("Invalid Stripe publishable key provided; ignoring value", nil)
+		settings.PublishableKey = ""
+	}
+	if settings.WebhookSecret != "" && !stripe.IsWebhookSecret(settings.WebhookSecret) {
+		logger.Warn("Invalid Stripe webhook secret provided; ignoring value", nil)
+		settings.WebhookSecret = ""
+	}
+
+	return settings
+}
+
+// Provider returns a payment provider for the current credentials, or nil when no
+// secret key is configured.
+func (s *PaymentService) Provider() payments.Provider {
+	settings := s.Settings()
+	if settings.SecretKey == "" {
+		logger.Debug("Stripe secret key not provided; checkout remains disabled", nil)
+		return nil
+	}
+
+	provider, err := stripe.NewProvider(settings.SecretKey)
+	if err != nil {
+		logger.Error(err, "Failed to initialise Stripe provider", nil)
+		return nil
+	}
+	return provider
+}
+
+// NewCheckout builds a checkout using the current provider and currency. Features
+// supply their own redirect URLs.
+func (s *PaymentService) NewCheckout(successURL, cancelURL string) *payments.Checkout {
+	return payments.NewCheckout(s.Provider(), payments.CheckoutConfig{
+		SuccessURL: successURL,
+		CancelURL:  cancelURL,
+		Currency:   s.Settings().Currency,
+	})
+}
+
+// Fulfillments exposes the registry features use to deliver paid purchases.
+func (s *PaymentService) Fulfillments() *payments.Fulfillments {
+	if s == nil {
+		return nil
+	}
+	return s.fulfillments
+}
+
+// SetEventBus announces fulfilled checkout sessions on bus.
+func (s *PaymentService) SetEventBus(bus *events.Bus) {
+	if s == nil {
+		return
+	}
+	if bus == nil {
+		s.fulfillments.OnCompleted(nil)
+		return
+	}
+	s.fulfillments.OnCompleted(func(ctx context.Context, session *payments.SessionDetails) {
+		bus.Publish(ctx, events.CheckoutCompleted, map[string]interface{}{
+			"session_id": session.ID,
+			"kind":       session.Metadata[payments.MetadataKind],
+			"email":      session.CustomerEmail,
+		})
+	})
+}
+
+// HandleWebhook verifies a Stripe webhook and fulfils the checkout session it
+// completes. Events other than completed, paid sessions are ignored.
+func (s *PaymentService) HandleWebhook(ctx context.Context, payload []byte, signature string) error {
+	if s == nil {
+		return payments.ErrCheckoutDisabled
+	}
+
+	secret := s.Settings().WebhookSecret
+	if secret == "" {
+		return ErrPaymentWebhookNotConfigured
+	}
+
+	event, err := stripe.ParseWebhookEvent(payload, signature, secret, stripeWebhookTolerance)
+	if err != nil {
+		return errors.Join(ErrInvalidPaymentWebhook, err)
+	}
+	if !event.Completed() || !payments.IsPaid(&event.Session) {
+		logger.Info("Ignored payment webhook event", map[string]interface{}{
+			"event_type": event.Type,
+			"session_id": event.Session.ID,
+		})
+		return nil
+	}
+
+	err = s.fulfillments.Fulfill(ctx, &event.Session)
+	if errors.Is(err, payments.ErrUnhandledKind) {
+		logger.Warn("Paid checkout session has no fulfiller", map[string]interface{}{
+			"session_id": event.Session.ID,
+			"kind":       event.Session.Metadata[payments.MetadataKind],
+		})
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/plugin"
+	"constructor-script-backend/internal/version"
+)
+
+const pluginDownloadTimeout = 2 * time.Minute
+
+// pluginMarketplaceFeed is the document served at the configured marketplace URL.
+type pluginMarketplaceFeed struct {
+	Plugins []pluginMarketplaceEntry `json:"plugins"`
+}
+
+type pluginMarketplaceEntry struct {
+	Slug        string `json:"slug"`
+	Name        string `json:"name"`
+	Version     string `json:"version"`
+	Requires    string `json:"requires,omitempty"`
+	DownloadURL string `json:"download_url"`
+	SHA256      string `json:"sha256"`
+	Signature   string `json:"signature,omitempty"`
+}
+
+// SetRemoteSources configures the marketplace feed and the Ed25519 public key used to
+// verify remote plugin archives. The signature covers the SHA-256 digest of the archive.
+func (s *PluginService) SetRemoteSources(marketplaceURL, signingPublicKey string) error {
+	if s == nil {
+		return nil
+	}
+
+	s.marketplaceURL = strings.TrimSpace(marketplaceURL)
+	s.signingKey = nil
+	s.remoteErr = nil
+
+	encoded := strings.TrimSpace(signingPublicKey)
+	if encoded == "" {
+		return nil
+	}
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		s.remoteErr = fmt.Errorf("plugin signing key must be a base64 encoded Ed25519 public key")
+		return s.remoteErr
+	}
+	s.signingKey = ed25519.PublicKey(key)
+	return nil
+}
+
+// InstallRemote downloads a plugin archive from a URL or from the marketplace feed,
+// verifies its checksum and signature, and installs it.
+func (s *PluginService) InstallRemote(ctx context.Context, req models.InstallPluginRequest) (models.PluginInfo, error) {
+	if s == nil || s.manager == nil {
+		return models.PluginInfo{}, ErrPluginManagerUnavailable
+	}
+	if s.repo == nil {
+		return models.PluginInfo{}, ErrPluginRepositoryUnavailable
+	}
+	if s.remoteErr != nil {
+		return models.PluginInfo{}, fmt.Errorf("remote plugin installation is disabled: %w", s.remoteErr)
+	}
+
+	source := strings.TrimSpace(req.URL)
+	checksum := strings.ToLower(strings.TrimSpace(req.SHA256))
+	signature := strings.TrimSpace(req.Signature)
+
+	if slug := strings.ToLower(strings.TrimSpace(req.Slug)); slug != "" {
+		entry, err := s.findMarketplaceEntry(ctx, slug)
+		if err != nil {
+			return models.PluginInfo{}, err
+		}
+		if entry.Requires != "" {
+			ok, err := plugin.SatisfiesConstraint(version.Version, entry.Requires)
+			if err != nil || !ok {
+				return models.PluginInfo{}, fmt.Errorf("%w: %s requires core %s, running %s", ErrPluginIncompatible, slug, entry.Requires, version.Version)
+			}
+		}
+		source = entry.DownloadURL
+		checksum = strings.ToLower(strings.TrimSpace(entry.SHA256))
+		signature = strings.TrimSpace(entry.Signature)
+	}
+
+	if source == "" {
+		return models.PluginInfo{}, fmt.Errorf("%w: url or marketplace slug is required", ErrInvalidPluginPackage)
+	}
+	if checksum == "" {
+		return models.PluginInfo{}, fmt.Errorf("%w: sha256 checksum is required for remote packages", ErrInvalidPluginPackage)
+	}
+	if s.signingKey != nil && signature == "" {
+		return models.PluginInfo{}, fmt.Errorf("%w: package signature is required", ErrInvalidPluginPackage)
+	}
+
+	tempFile, written, digest, err := s.downloadPluginArchive(ctx, source)
+	if err != nil {
+		return models.PluginInfo{}, err
+	}
+	defer func() {
+		tempFile.Close()
+		os.Remove(tempFile.Name())
+	}()
+
+	if err := verifyPluginArchive(digest, checksum, signature, s.signingKey); err != nil {
+		return models.PluginInfo{}, err
+	}
+
+	filename := path.Base(source)
+	if parsed, err := url.Parse(source); err == nil {
+		filename = path.Base(parsed.Path)
+	}
+
+	return s.installArchive(tempFile, written, filename)
+}
+
+func (s *PluginService) findMarketplaceEntry(ctx context.Context, slug string) (pluginMarketplaceEntry, error) {
+	if s.marketplaceURL == "" {
+		return pluginMarketplaceEntry{}, fmt.Errorf("%w: plugin marketplace is not configured", ErrInvalidPluginPackage)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, s.marketplaceURL, nil)
+	if err != nil {
+		return pluginMarketplaceEntry{}, err
+	}
+	request.Header.Set("Accept", "application/json")
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	response, err := client.Do(request)
+	if err != nil {
+		return pluginMarketplaceEntry{}, fmt.Errorf("failed to fetch plugin marketplace: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return pluginMarketplaceEntry{}, fmt.Errorf("plugin marketplace responded with status %d", response.StatusCode)
+	}
+
+	var feed pluginMarketplaceFeed
+	if err := json.NewDecoder(io.LimitReader(response.Body, 5*1024*1024)).Decode(&feed); err != nil {
+		return pluginMarketplaceEntry{}, fmt.Errorf("failed to decode plugin marketplace: %w", err)
+	}
+
+	for _, entry := range feed.Plugins {
+		if strings.EqualFold(strings.TrimSpace(entry.Slug), slug) {
+			return entry, nil
+		}
+	}
+	return pluginMarketplaceEntry{}, fmt.Errorf("%w: %s is not listed in the marketplace", ErrPluginNotFound, slug)
+}
+
+func (s *PluginService) downloadPluginArchive(ctx context.Context, source string) (*os.File, int64, []byte, error) {
+	parsed, err := url.Parse(source)
+	if err != nil || parsed.Host == "" || (parsed.Scheme != "https" && parsed.Scheme != "http") {
+		return nil, 0, nil, fmt.Errorf("%w: invalid download url", ErrInvalidPluginPackage)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, pluginDownloadTimeout)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, parsed.String(), nil)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("failed to download plugin: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, 0, nil, fmt.Errorf("plugin download responded with status %d", response.StatusCode)
+	}
+
+	tempFile, err := os.CreateTemp("", "plugin-*.zip")
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+
+	hasher := sha256.New()
+	reader := io.Reader(response.Body)
+	if s.maxBytes > 0 {
+		reader = io.LimitReader(response.Body, s.maxBytes+1)
+	}
+
+	written, err := io.Copy(io.MultiWriter(tempFile, hasher), reader)
+	if err == nil && s.maxBytes > 0 && written > s.maxBytes {
+		err = fmt.Errorf("plugin package exceeds maximum size of %d bytes", s.maxBytes)
+	}
+	if err != nil {
+		tempFile.Close()
+		os.Remove(tempFile.Name())
+		return nil, 0, nil, fmt.Errorf("failed to store plugin archive: %w", err)
+	}
+
+	return tempFile, written, hasher.Sum(nil), nil
+}
+
+func verifyPluginArchive(digest []byte, checksum, signature string, key ed25519.PublicKey) error {
+	expected, err := hex.DecodeString(checksum)
+	if err != nil || len(expected) != sha256.Size {
+		return fmt.Errorf("%w: invalid sha256 checksum", ErrInvalidPluginPackage)
+	}
+	if subtle.ConstantTimeCompare(expected, digest) != 1 {
+		return fmt.Errorf("%w: checksum mismatch", ErrInvalidPluginPackage)
+	}
+
+	if key == nil {
+		return nil
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("%w: invalid signature encoding", ErrInvalidPluginPackage)
+	}
+	if !ed25519.Verify(key, digest, decoded) {
+		return fmt.Errorf("%w: signature verification failed", ErrInvalidPluginPackage)
+	}
+	return nil
+}
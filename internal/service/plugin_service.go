@@ -2,6 +2,8 @@ package service
 
 import (
 	"archive/zip"
+	"context"
+	"crypto/ed25519"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -16,10 +18,13 @@ import (
 
 	"gorm.io/gorm"
 
+	"constructor-script-backend/internal/events"
 	"constructor-script-backend/internal/models"
 	"constructor-script-backend/internal/plugin"
 	pluginruntime "constructor-script-backend/internal/plugin/runtime"
 	"constructor-script-backend/internal/repository"
+	"constructor-script-backend/internal/version"
+	"constructor-script-backend/pkg/logger"
 	"constructor-script-backend/pkg/utils"
 )
 
@@ -28,30 +33,67 @@ var (
 	ErrPluginManagerUnavailable    = errors.New("plugin manager is not configured")
 	ErrPluginNotFound              = errors.New("plugin not found")
 	ErrInvalidPluginPackage        = errors.New("invalid plugin package")
+	ErrPluginIncompatible          = errors.New("plugin is not compatible")
 )
 
 type PluginService struct {
-	mu       sync.Mutex
-	repo     repository.PluginRepository
-	manager  *plugin.Manager
-	maxBytes int64
-	runtime  *pluginruntime.Runtime
+	mu          sync.Mutex
+	repo        repository.PluginRepository
+	settingRepo repository.SettingRepository
+	manager     *plugin.Manager
+	maxBytes    int64
+	runtime     *pluginruntime.Runtime
+
+	marketplaceURL string
+	signingKey     ed25519.PublicKey
+	remoteErr      error
+
+	externalFeature func(*plugin.Plugin) pluginruntime.Feature
+	installSchema   func(slug string) error
+	events          *events.Bus
 }
 
 const defaultMaxPluginSize = 50 * 1024 * 1024 // 50MB
 
-func NewPluginService(repo repository.PluginRepository, manager *plugin.Manager, runtime *pluginruntime.Runtime) *PluginService {
+func NewPluginService(repo repository.PluginRepository, settingRepo repository.SettingRepository, manager *plugin.Manager, runtime *pluginruntime.Runtime) *PluginService {
 	if repo == nil || manager == nil {
 		return nil
 	}
 	return &PluginService{
-		repo:     repo,
-		manager:  manager,
-		maxBytes: defaultMaxPluginSize,
-		runtime:  runtime,
+		repo:        repo,
+		settingRepo: settingRepo,
+		manager:     manager,
+		maxBytes:    defaultMaxPluginSize,
+		runtime:     runtime,
 	}
 }
 
+// SetExternalFeatureFactory configures how runtime features are created for plugins
+// that run out of process, so they can be activated right after installation.
+func (s *PluginService) SetExternalFeatureFactory(factory func(*plugin.Plugin) pluginruntime.Feature) {
+	if s == nil {
+		return
+	}
+	s.externalFeature = factory
+}
+
+// SetSchemaInstaller configures how the tables and migrations of a plugin are
+// brought up to date when it is activated.
+func (s *PluginService) SetSchemaInstaller(install func(slug string) error) {
+	if s == nil {
+		return
+	}
+	s.installSchema = install
+}
+
+// SetEventBus configures the bus used to announce plugin lifecycle events.
+func (s *PluginService) SetEventBus(bus *events.Bus) {
+	if s == nil {
+		return
+	}
+	s.events = bus
+}
+
 // ApplyRuntimeState synchronises the runtime feature registry with the stored plugin states.
 func (s *PluginService) ApplyRuntimeState() error {
 	if s == nil || s.repo == nil {
@@ -126,6 +168,7 @@ func (s *PluginService) List() ([]models.PluginInfo, error) {
 			Installed:    exists,
 			Active:       exists && record.Active,
 			MissingFiles: false,
+			HasSettings:  len(entry.Metadata.Settings) > 0,
 		}
 
 		if exists {
@@ -217,6 +260,12 @@ func (s *PluginService) Install(file io.Reader, size int64, filename string) (mo
 		return models.PluginInfo{}, fmt.Errorf("plugin package exceeds maximum size of %d bytes", limit)
 	}
 
+	return s.installArchive(tempFile, written, filename)
+}
+
+// installArchive validates and extracts a plugin archive that has already been
+// written to disk.
+func (s *PluginService) installArchive(tempFile *os.File, written int64, filename string) (models.PluginInfo, error) {
 	if _, err := tempFile.Seek(0, io.SeekStart); err != nil {
 		return models.PluginInfo{}, fmt.Errorf("failed to rewind temporary file: %w", err)
 	}
@@ -235,6 +284,10 @@ func (s *PluginService) Install(file io.Reader, size int64, filename string) (mo
 		return models.PluginInfo{}, err
 	}
 
+	if err := s.checkCompatibility(manifest); err != nil {
+		return models.PluginInfo{}, err
+	}
+
 	slug := strings.ToLower(strings.TrimSpace(manifest.Slug))
 	if slug == "" {
 		slug = utils.GenerateSlug(manifest.Name)
@@ -382,15 +435,25 @@ func (s *PluginService) Activate(slug string) (models.PluginInfo, error) {
 	}
 	record.Metadata["activated_at"] = now.Format(time.RFC3339)
 
+	if s.installSchema != nil {
+		if err := s.installSchema(cleaned); err != nil {
+			return models.PluginInfo{}, err
+		}
+	}
+
 	if err := s.repo.Save(record); err != nil {
 		return models.PluginInfo{}, err
 	}
 
 	if s.runtime != nil {
+		if entry.Metadata.IsExternal() && s.externalFeature != nil && !s.runtime.Active(cleaned) {
+			s.runtime.Register(cleaned, s.externalFeature(entry))
+		}
 		if err := s.runtime.Activate(cleaned); err != nil {
 			return models.PluginInfo{}, err
 		}
 	}
+	s.events.Publish(context.Background(), events.PluginActivated, map[string]interface{}{"slug": cleaned, "version": record.Version})
 
 	installedAt := record.InstalledAt
 	info := models.PluginInfo{
@@ -410,7 +473,9 @@ func (s *PluginService) Activate(slug string) (models.PluginInfo, error) {
 	return info, nil
 }
 
-func (s *PluginService) Deactivate(slug string) (models.PluginInfo, error) {
+// Deactivate disables a plugin. When purge is set the plugin's data (tables created by
+// the plugin and its settings) is removed as well.
+func (s *PluginService) Deactivate(slug string, purge bool) (models.PluginInfo, error) {
 	if s == nil {
 		return models.PluginInfo{}, ErrPluginManagerUnavailable
 	}
@@ -468,6 +533,13 @@ func (s *PluginService) Deactivate(slug string) (models.PluginInfo, error) {
 			return models.PluginInfo{}, err
 		}
 	}
+	s.events.Publish(context.Background(), events.PluginDeactivated, map[string]interface{}{"slug": cleaned, "version": record.Version})
+
+	if purge {
+		if err := s.purgeData(cleaned); err != nil {
+			return models.PluginInfo{}, err
+		}
+	}
 
 	installedAt := record.InstalledAt
 	info := models.PluginInfo{
@@ -487,7 +559,9 @@ func (s *PluginService) Deactivate(slug string) (models.PluginInfo, error) {
 	return info, nil
 }
 
-func (s *PluginService) Delete(slug string) (models.PluginInfo, error) {
+// Delete removes a plugin from disk. When purge is set the plugin's data is removed
+// too; otherwise tables and settings are kept so a reinstall picks them up again.
+func (s *PluginService) Delete(slug string, purge bool) (models.PluginInfo, error) {
 	if s == nil {
 		return models.PluginInfo{}, ErrPluginManagerUnavailable
 	}
@@ -538,6 +612,12 @@ func (s *PluginService) Delete(slug string) (models.PluginInfo, error) {
 			}
 		}
 
+		if purge {
+			if err := s.purgeData(cleaned); err != nil {
+				return models.PluginInfo{}, err
+			}
+		}
+
 		// Clean up runtime memory by unregistering the feature
 		if s.runtime != nil {
 			if err := s.runtime.Unregister(cleaned); err != nil {
@@ -598,6 +678,24 @@ func (s *PluginService) Delete(slug string) (models.PluginInfo, error) {
 	return info, nil
 }
 
+// purgeData runs the plugin's uninstall hook and removes its stored settings. The
+// plugin must already be inactive.
+func (s *PluginService) purgeData(slug string) error {
+	if s.runtime != nil {
+		if err := s.runtime.Uninstall(slug); err != nil {
+			return fmt.Errorf("failed to purge plugin data: %w", err)
+		}
+	}
+	if s.settingRepo != nil {
+		if err := s.settingRepo.Delete(pluginSettingsKey(slug)); err != nil {
+			return fmt.Errorf("failed to remove plugin settings: %w", err)
+		}
+	}
+
+	logger.Info("Purged plugin data", map[string]interface{}{"plugin": slug})
+	return nil
+}
+
 func extractManifest(reader *zip.Reader) (plugin.Metadata, string, error) {
 	var manifestFile *zip.File
 	var manifestPrefix string
@@ -712,9 +810,48 @@ func resolveTargetPath(name, destDir, prefix string) (string, bool) {
 	return targetPath, false
 }
 
+// checkCompatibility verifies the core version constraint and the plugin
+// dependencies declared by a manifest.
+func (s *PluginService) checkCompatibility(manifest plugin.Metadata) error {
+	if constraint := strings.TrimSpace(manifest.Requires); constraint != "" {
+		ok, err := plugin.SatisfiesConstraint(version.Version, constraint)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidPluginPackage, err)
+		}
+		if !ok {
+			return fmt.Errorf("%w: requires core %s, running %s", ErrPluginIncompatible, constraint, version.Version)
+		}
+	}
+
+	for slug, constraint := range manifest.Dependencies {
+		dependency, ok := s.manager.Resolve(slug)
+		if !ok {
+			return fmt.Errorf("%w: missing dependency %s", ErrPluginIncompatible, slug)
+		}
+		if strings.TrimSpace(constraint) == "" {
+			continue
+		}
+		ok, err := plugin.SatisfiesConstraint(dependency.Metadata.Version, constraint)
+		if err != nil {
+			return fmt.Errorf("%w: dependency %s: %v", ErrPluginIncompatible, slug, err)
+		}
+		if !ok {
+			return fmt.Errorf("%w: dependency %s %s required, %s installed", ErrPluginIncompatible, slug, constraint, dependency.Metadata.Version)
+		}
+	}
+
+	return nil
+}
+
 func validateManifest(manifest plugin.Metadata) error {
 	if strings.TrimSpace(manifest.Name) == "" {
 		return fmt.Errorf("%w: plugin name is required", ErrInvalidPluginPackage)
 	}
+	if err := plugin.ValidateSettingDefinitions(manifest.Settings); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPluginPackage, err)
+	}
+	if err := manifest.Runtime.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPluginPackage, err)
+	}
 	return nil
 }
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"constructor-script-backend/internal/plugin"
+)
+
+var ErrInvalidPluginSettings = errors.New("invalid plugin settings")
+
+// settingKeyPluginSettingsFormat namespaces the stored values of each plugin so
+// plugins never write to site-wide setting keys.
+const settingKeyPluginSettingsFormat = "plugins.%s.settings"
+
+// PluginSettings combines the schema declared in a plugin manifest with the
+// effective values (stored values merged over the declared defaults).
+type PluginSettings struct {
+	Slug   string                     `json:"slug"`
+	Schema []plugin.SettingDefinition `json:"schema"`
+	Values map[string]interface{}     `json:"values"`
+}
+
+// GetSettings returns the settings schema and values of a plugin.
+func (s *PluginService) GetSettings(slug string) (PluginSettings, error) {
+	entry, err := s.resolveSettingsPlugin(slug)
+	if err != nil {
+		return PluginSettings{}, err
+	}
+
+	values, err := s.pluginSettingValues(entry)
+	if err != nil {
+		return PluginSettings{}, err
+	}
+
+	return PluginSettings{
+		Slug:   entry.Slug,
+		Schema: append([]plugin.SettingDefinition{}, entry.Metadata.Settings...),
+		Values: values,
+	}, nil
+}
+
+// Settings returns the effective setting values of a plugin keyed by setting key.
+// Plugins use it to read their own configuration.
+func (s *PluginService) Settings(slug string) (map[string]interface{}, error) {
+	entry, err := s.resolveSettingsPlugin(slug)
+	if err != nil {
+		return nil, err
+	}
+	return s.pluginSettingValues(entry)
+}
+
+// UpdateSettings validates the submitted values against the plugin schema and stores
+// them. Keys that are not part of the submission keep their current value.
+func (s *PluginService) UpdateSettings(slug string, values map[string]interface{}) (PluginSettings, error) {
+	entry, err := s.resolveSettingsPlugin(slug)
+	if err != nil {
+		return PluginSettings{}, err
+	}
+	if s.settingRepo == nil {
+		return PluginSettings{}, errors.New("settings repository not configured")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, err := s.storedPluginSettings(entry.Slug)
+	if err != nil {
+		return PluginSettings{}, err
+	}
+
+	for key, value := range values {
+		definition, ok := entry.Metadata.SettingDefinition(key)
+		if !ok {
+			return PluginSettings{}, fmt.Errorf("%w: unknown setting %s", ErrInvalidPluginSettings, key)
+		}
+		normalized, err := definition.Normalize(value)
+		if err != nil {
+			return PluginSettings{}, fmt.Errorf("%w: %v", ErrInvalidPluginSettings, err)
+		}
+		if normalized == nil {
+			delete(stored, definition.Key)
+			continue
+		}
+		stored[definition.Key] = normalized
+	}
+
+	payload, err := json.Marshal(stored)
+	if err != nil {
+		return PluginSettings{}, err
+	}
+	if err := s.settingRepo.Set(pluginSettingsKey(entry.Slug), string(payload)); err != nil {
+		return PluginSettings{}, err
+	}
+
+	return PluginSettings{
+		Slug:   entry.Slug,
+		Schema: append([]plugin.SettingDefinition{}, entry.Metadata.Settings...),
+		Values: mergePluginSettingDefaults(entry.Metadata.Settings, stored),
+	}, nil
+}
+
+func (s *PluginService) resolveSettingsPlugin(slug string) (*plugin.Plugin, error) {
+	if s == nil || s.manager == nil {
+		return nil, ErrPluginManagerUnavailable
+	}
+
+	cleaned := strings.ToLower(strings.TrimSpace(slug))
+	entry, ok := s.manager.Resolve(cleaned)
+	if !ok || entry == nil {
+		return nil, fmt.Errorf("%w: %s", ErrPluginNotFound, cleaned)
+	}
+	return entry, nil
+}
+
+func (s *PluginService) pluginSettingValues(entry *plugin.Plugin) (map[string]interface{}, error) {
+	stored, err := s.storedPluginSettings(entry.Slug)
+	if err != nil {
+		return nil, err
+	}
+	return mergePluginSettingDefaults(entry.Metadata.Settings, stored), nil
+}
+
+func (s *PluginService) storedPluginSettings(slug string) (map[string]interface{}, error) {
+	result := make(map[string]interface{})
+	if s.settingRepo == nil {
+		return result, nil
+	}
+
+	setting, err := s.settingRepo.Get(pluginSettingsKey(slug))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return result, nil
+		}
+		return nil, err
+	}
+
+	if strings.TrimSpace(setting.Value) == "" {
+		return result, nil
+	}
+	if err := json.Unmarshal([]byte(setting.Value), &result); err != nil {
+		return nil, fmt.Errorf("failed to decode plugin settings: %w", err)
+	}
+	return result, nil
+}
+
+func mergePluginSettingDefaults(definitions []plugin.SettingDefinition, stored map[string]interface{}) map[string]interface{} {
+	values := make(map[string]interface{}, len(definitions))
+	for _, definition := range definitions {
+		if value, ok := stored[definition.Key]; ok {
+			values[definition.Key] = value
+			continue
+		}
+		values[definition.Key] = definition.Default
+	}
+	return values
+}
+
+func pluginSettingsKey(slug string) string {
+	return fmt.Sprintf(settingKeyPluginSettingsFormat, strings.ToLower(strings.TrimSpace(slug)))
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/repository"
+	"constructor-script-backend/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// SettingKeyRateLimits stores the route rate limit policies in the settings repository.
+	SettingKeyRateLimits = "security.rate_limits"
+
+	// rateLimitPolicyRefresh bounds how long an instance keeps applying policies
+	// after another instance changed them.
+	rateLimitPolicyRefresh = 30 * time.Second
+
+	maxRateLimitPolicies      = 50
+	maxRateLimitWindowSeconds = 24 * 60 * 60
+)
+
+var rateLimitPolicyNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+var rateLimitPolicyMethods = map[string]bool{
+	"GET":    true,
+	"HEAD":   true,
+	"POST":   true,
+	"PUT":    true,
+	"PATCH":  true,
+	"DELETE": true,
+}
+
+// RateLimitService keeps the route rate limit policies. Every request reads them,
+// so they are held in memory and reloaded from settings every rateLimitPolicyRefresh.
+type RateLimitService struct {
+	settingRepo repository.SettingRepository
+
+	mu       sync.RWMutex
+	policies []models.RateLimitPolicy
+	loadedAt time.Time
+}
+
+type RateLimitValidationError struct {
+	Reason string
+}
+
+func (e *RateLimitValidationError) Error() string {
+	if e == nil {
+		return ""
+	}
+	return e.Reason
+}
+
+func rateLimitValidationErrorf(format string, args ...interface{}) error {
+	return &RateLimitValidationError{Reason: fmt.Sprintf(format, args...)}
+}
+
+func NewRateLimitService(repo repository.SettingRepository) *RateLimitService {
+	return &RateLimitService{settingRepo: repo}
+}
+
+func (s *RateLimitService) GetSettings() (models.RateLimitSettings, error) {
+	defaults := models.RateLimitSettings{Policies: []models.RateLimitPolicy{}}
+	if s.settingRepo == nil {
+		return defaults, nil
+	}
+
+	stored, err := s.settingRepo.Get(SettingKeyRateLimits)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return defaults, nil
+		}
+		return defaults, err
+	}
+
+	if strings.TrimSpace(stored.Value) == "" {
+		return defaults, nil
+	}
+
+	var settings models.RateLimitSettings
+	if err := json.Unmarshal([]byte(stored.Value), &settings); err != nil {
+		return defaults, fmt.Errorf("failed to decode rate limit settings: %w", err)
+	}
+	if settings.Policies == nil {
+		settings.Policies = []models.RateLimitPolicy{}
+	}
+
+	return settings, nil
+}
+
+func (s *RateLimitService) UpdateSettings(req models.UpdateRateLimitSettingsRequest) (models.RateLimitSettings, error) {
+	if len(req.Policies) > maxRateLimitPolicies {
+		return models.RateLimitSettings{}, rateLimitValidationErrorf("at most %d rate limit policies are allowed", maxRateLimitPolicies)
+	}
+
+	settings := models.RateLimitSettings{Policies: make([]models.RateLimitPolicy, 0, len(req.Policies))}
+	names := make(map[string]bool, len(req.Policies))
+	for index, policy := range req.Policies {
+		normalized, err := normalizeRateLimitPolicy(policy)
+		if err != nil {
+			return models.RateLimitSettings{}, rateLimitValidationErrorf("policy %d: %s", index+1, err.Error())
+		}
+		if names[normalized.Name] {
+			return models.RateLimitSettings{}, rateLimitValidationErrorf("policy %d: name %q is already used", index+1, normalized.Name)
+		}
+		names[normalized.Name] = true
+		settings.Policies = append(settings.Policies, normalized)
+	}
+
+	if s.settingRepo != nil {
+		payload, err := json.Marshal(settings)
+		if err != nil {
+			return settings, fmt.Errorf("failed to encode rate limit settings: %w", err)
+		}
+		if err := s.settingRepo.Set(SettingKeyRateLimits, string(payload)); err != nil {
+			return settings, err
+		}
+	}
+
+	s.mu.Lock()
+	s.policies = settings.Policies
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+
+	return settings, nil
+}
+
+func normalizeRateLimitPolicy(policy models.RateLimitPolicy) (models.RateLimitPolicy, error) {
+	policy.Name = strings.ToLower(strings.TrimSpace(policy.Name))
+	if !rateLimitPolicyNamePattern.MatchString(policy.Name) {
+		return policy, errors.New("name must be 1-64 lowercase letters, digits, dashes or underscores")
+	}
+
+	policy.PathPrefix = strings.TrimSpace(policy.PathPrefix)
+	if !strings.HasPrefix(policy.PathPrefix, "/") {
+		return policy, errors.New("path prefix must start with /")
+	}
+
+	methods := make([]string, 0, len(policy.Methods))
+	seen := make(map[string]bool, len(policy.Methods))
+	for _, method := range policy.Methods {
+		method = strings.ToUpper(strings.TrimSpace(method))
+		if method == "" || seen[method] {
+			continue
+		}
+		if !rateLimitPolicyMethods[method] {
+			return policy, fmt.Errorf("unsupported method %s", method)
+		}
+		seen[method] = true
+		methods = append(methods, method)
+	}
+	policy.Methods = methods
+	if len(policy.Methods) == 0 {
+		policy.Methods = nil
+	}
+
+	if policy.Requests <= 0 {
+		return policy, errors.New("requests must be greater than zero")
+	}
+	if policy.WindowSeconds <= 0 || policy.WindowSeconds > maxRateLimitWindowSeconds {
+		return policy, fmt.Errorf("window must be between 1 and %d seconds", maxRateLimitWindowSeconds)
+	}
+
+	return policy, nil
+}
+
+// RateLimitPolicies returns the configured policies for RateLimitPolicyMiddleware.
+// When settings cannot be read, the policies loaded last stay in force.
+func (s *RateLimitService) RateLimitPolicies() []models.RateLimitPolicy {
+	s.mu.RLock()
+	policies, loadedAt := s.policies, s.loadedAt
+	s.mu.RUnlock()
+	if !loadedAt.IsZero() && time.Since(loadedAt) < rateLimitPolicyRefresh {
+		return policies
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.loadedAt.IsZero() && time.Since(s.loadedAt) < rateLimitPolicyRefresh {
+		return s.policies
+	}
+
+	settings, err := s.GetSettings()
+	s.loadedAt = time.Now()
+	if err != nil {
+		logger.Error(err, "Failed to load rate limit policies", nil)
+		return s.policies
+	}
+	s.policies = settings.Policies
+	return s.policies
+}
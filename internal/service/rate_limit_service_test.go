@@ -0,0 +1,83 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"constructor-script-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type memorySettingRepository struct {
+	values map[string]string
+}
+
+func (r *memorySettingRepository) Get(key string) (*models.Setting, error) {
+	value, ok := r.values[key]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &models.Setting{Key: key, Value: value}, nil
+}
+
+func (r *memorySettingRepository) Set(key, value string) error {
+	r.values[key] = value
+	return nil
+}
+
+func (r *memorySettingRepository) Delete(key string) error {
+	delete(r.values, key)
+	return nil
+}
+
+func TestRateLimitServiceNormalizesAndStoresPolicies(t *testing.T) {
+	repo := &memorySettingRepository{values: make(map[string]string)}
+	svc := NewRateLimitService(repo)
+
+	settings, err := svc.UpdateSettings(models.UpdateRateLimitSettingsRequest{Policies: []models.RateLimitPolicy{{
+		Name:          " Login ",
+		PathPrefix:    "/api/v1/auth/login",
+		Methods:       []string{"post", "POST", ""},
+		Requests:      5,
+		WindowSeconds: 300,
+	}}})
+	if err != nil {
+		t.Fatalf("UpdateSettings: %v", err)
+	}
+	policy := settings.Policies[0]
+	if policy.Name != "login" || len(policy.Methods) != 1 || policy.Methods[0] != "POST" {
+		t.Fatalf("policy not normalized: %+v", policy)
+	}
+
+	reloaded := NewRateLimitService(repo)
+	if policies := reloaded.RateLimitPolicies(); len(policies) != 1 || policies[0].PathPrefix != "/api/v1/auth/login" {
+		t.Fatalf("stored policies = %+v", policies)
+	}
+}
+
+func TestRateLimitServiceRejectsInvalidPolicies(t *testing.T) {
+	svc := NewRateLimitService(&memorySettingRepository{values: make(map[string]string)})
+
+	cases := map[string]models.RateLimitPolicy{
+		"relative path":  {Name: "a", PathPrefix: "api", Requests: 1, WindowSeconds: 60},
+		"no requests":    {Name: "a", PathPrefix: "/api", WindowSeconds: 60},
+		"long window":    {Name: "a", PathPrefix: "/api", Requests: 1, WindowSeconds: 90000},
+		"unknown method": {Name: "a", PathPrefix: "/api", Methods: []string{"TRACE"}, Requests: 1, WindowSeconds: 60},
+		"bad name":       {Name: "a b", PathPrefix: "/api", Requests: 1, WindowSeconds: 60},
+	}
+	for name, policy := range cases {
+		_, err := svc.UpdateSettings(models.UpdateRateLimitSettingsRequest{Policies: []models.RateLimitPolicy{policy}})
+		var validationErr *RateLimitValidationError
+		if !errors.As(err, &validationErr) {
+			t.Errorf("%s: got %v, want a validation error", name, err)
+		}
+	}
+
+	duplicate := models.RateLimitPolicy{Name: "a", PathPrefix: "/api", Requests: 1, WindowSeconds: 60}
+	_, err := svc.UpdateSettings(models.UpdateRateLimitSettingsRequest{Policies: []models.RateLimitPolicy{duplicate, duplicate}})
+	var validationErr *RateLimitValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("duplicate names: got %v, want a validation error", err)
+	}
+}
@@ -0,0 +1,372 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/repository"
+)
+
+const (
+	// SettingKeyRevisions stores how much revision history is kept in the
+	// settings repository.
+	SettingKeyRevisions = "content.revisions"
+
+	DefaultRevisionsPerItem = 50
+	maxRevisionsPerItem     = 1000
+	maxRevisionAgeDays      = 3650
+
+	// revisionDiffMaxCells bounds the work of a line diff. Larger fields are
+	// shown as fully replaced.
+	revisionDiffMaxCells = 4_000_000
+)
+
+var (
+	ErrRevisionsUnavailable  = errors.New("revision history is not configured")
+	ErrRevisionNotRestorable = errors.New("revisions of this content cannot be restored")
+)
+
+type RevisionValidationError struct {
+	Reason string
+}
+
+func (e *RevisionValidationError) Error() string {
+	if e == nil {
+		return ""
+	}
+	return e.Reason
+}
+
+func revisionValidationErrorf(format string, args ...interface{}) error {
+	return &RevisionValidationError{Reason: fmt.Sprintf(format, args...)}
+}
+
+// RevisionRestorer writes a revision back to the post or page it was taken
+// from. Content services register one for their content type.
+type RevisionRestorer interface {
+	RestoreRevision(revision *models.Revision) error
+}
+
+// RevisionService keeps the history of posts and pages. Content services
+// record a revision whenever they save, and admins list, compare and restore
+// them.
+type RevisionService struct {
+	repo        repository.RevisionRepository
+	settingRepo repository.SettingRepository
+
+	mu        sync.RWMutex
+	restorers map[string]RevisionRestorer
+}
+
+func NewRevisionService(repo repository.RevisionRepository, settingRepo repository.SettingRepository) *RevisionService {
+	return &RevisionService{
+		repo:        repo,
+		settingRepo: settingRepo,
+		restorers:   make(map[string]RevisionRestorer),
+	}
+}
+
+// WithTx returns a service that records revisions through tx. It cannot
+// restore revisions.
+func (s *RevisionService) WithTx(tx *gorm.DB) *RevisionService {
+	if s == nil {
+		return nil
+	}
+	return &RevisionService{
+		repo:        repository.NewRevisionRepository(tx),
+		settingRepo: s.settingRepo,
+		restorers:   make(map[string]RevisionRestorer),
+	}
+}
+
+// SetRestorer registers the restorer for contentType, or removes it when
+// restorer is nil.
+func (s *RevisionService) SetRestorer(contentType string, restorer RevisionRestorer) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if restorer == nil {
+		delete(s.restorers, contentType)
+		return
+	}
+	s.restorers[contentType] = restorer
+}
+
+func (s *RevisionService) GetSettings() (models.RevisionSettings, error) {
+	settings := models.RevisionSettings{MaxPerItem: DefaultRevisionsPerItem}
+	if s == nil || s.settingRepo == nil {
+		return settings, nil
+	}
+
+	stored, err := s.settingRepo.Get(SettingKeyRevisions)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return settings, nil
+		}
+		return settings, err
+	}
+	if strings.TrimSpace(stored.Value) == "" {
+		return settings, nil
+	}
+	if err := json.Unmarshal([]byte(stored.Value), &settings); err != nil {
+		return settings, fmt.Errorf("failed to decode revision settings: %w", err)
+	}
+	return settings, nil
+}
+
+func (s *RevisionService) UpdateSettings(req models.RevisionSettings) (models.RevisionSettings, error) {
+	if s == nil || s.settingRepo == nil {
+		return models.RevisionSettings{}, ErrRevisionsUnavailable
+	}
+	if req.MaxPerItem < 0 || req.MaxPerItem > maxRevisionsPerItem {
+		return models.RevisionSettings{}, revisionValidationErrorf("max_per_item must be between 0 and %d", maxRevisionsPerItem)
+	}
+	if req.MaxAgeDays < 0 || req.MaxAgeDays > maxRevisionAgeDays {
+		return models.RevisionSettings{}, revisionValidationErrorf("max_age_days must be between 0 and %d", maxRevisionAgeDays)
+	}
+
+	encoded, err := json.Marshal(req)
+	if err != nil {
+		return models.RevisionSettings{}, fmt.Errorf("failed to encode revision settings: %w", err)
+	}
+	if err := s.settingRepo.Set(SettingKeyRevisions, string(encoded)); err != nil {
+		return models.RevisionSettings{}, err
+	}
+	return req, nil
+}
+
+// Record saves snapshot as the newest revision of an item, unless it matches
+// the current newest one, and drops revisions beyond the configured limit.
+func (s *RevisionService) Record(snapshot models.Revision) error {
+	if s == nil || s.repo == nil {
+		return nil
+	}
+
+	latest, err := s.repo.Latest(snapshot.ContentType, snapshot.ContentID)
+	switch {
+	case err == nil:
+		if sameRevisionContent(latest, &snapshot) {
+			return nil
+		}
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return err
+	}
+
+	snapshot.ID = 0
+	snapshot.CreatedAt = time.Time{}
+	if err := s.repo.Create(&snapshot); err != nil {
+		return err
+	}
+
+	settings, err := s.GetSettings()
+	if err != nil {
+		return err
+	}
+	if settings.MaxPerItem > 0 {
+		if _, err := s.repo.KeepLatest(snapshot.ContentType, snapshot.ContentID, settings.MaxPerItem); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sameRevisionContent(a, b *models.Revision) bool {
+	if a.Title != b.Title || a.Description != b.Description || a.Excerpt != b.Excerpt || a.Content != b.Content {
+		return false
+	}
+	return revisionSectionsJSON(a.Sections) == revisionSectionsJSON(b.Sections)
+}
+
+// List returns the revisions of an item newest first, without their content.
+func (s *RevisionService) List(contentType string, contentID uint) ([]models.Revision, error) {
+	if s == nil || s.repo == nil {
+		return nil, ErrRevisionsUnavailable
+	}
+	revisions, err := s.repo.List(contentType, contentID, maxRevisionsPerItem)
+	if err != nil {
+		return nil, err
+	}
+	if revisions == nil {
+		revisions = []models.Revision{}
+	}
+	return revisions, nil
+}
+
+func (s *RevisionService) Get(id uint) (*models.Revision, error) {
+	if s == nil || s.repo == nil {
+		return nil, ErrRevisionsUnavailable
+	}
+	return s.repo.GetByID(id)
+}
+
+// Diff compares revision id with compareID, or with the revision saved before
+// it when compareID is zero.
+func (s *RevisionService) Diff(id, compareID uint) (*models.RevisionDiff, error) {
+	to, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var from *models.Revision
+	if compareID != 0 {
+		if from, err = s.repo.GetByID(compareID); err != nil {
+			return nil, err
+		}
+		if from.ContentType != to.ContentType || from.ContentID != to.ContentID {
+			return nil, revisionValidationErrorf("revisions %d and %d belong to different content", compareID, id)
+		}
+	} else if from, err = s.repo.Previous(to); err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		from = nil
+	}
+
+	base := models.Revision{}
+	if from != nil {
+		base = *from
+	}
+	diff := &models.RevisionDiff{From: from, To: *to, Changes: []models.RevisionFieldDiff{}}
+	fields := []struct {
+		name     string
+		old, new string
+	}{
+		{"title", base.Title, to.Title},
+		{"description", base.Description, to.Description},
+		{"excerpt", base.Excerpt, to.Excerpt},
+		{"content", base.Content, to.Content},
+		{"sections", revisionSectionsJSON(base.Sections), revisionSectionsJSON(to.Sections)},
+	}
+	for _, field := range fields {
+		if field.old == field.new {
+			continue
+		}
+		diff.Changes = append(diff.Changes, models.RevisionFieldDiff{Field: field.name, Lines: diffLines(field.old, field.new)})
+	}
+	return diff, nil
+}
+
+// Restore writes revision id back to its post or page. The restore is saved
+// as a new revision.
+func (s *RevisionService) Restore(id uint) (*models.Revision, error) {
+	revision, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	restorer := s.restorers[revision.ContentType]
+	s.mu.RUnlock()
+	if restorer == nil {
+		return nil, ErrRevisionNotRestorable
+	}
+	if err := restorer.RestoreRevision(revision); err != nil {
+		return nil, err
+	}
+	return revision, nil
+}
+
+// Prune deletes revisions older than the configured age. The newest revision
+// of every item is kept.
+func (s *RevisionService) Prune() (int64, error) {
+	if s == nil || s.repo == nil {
+		return 0, nil
+	}
+	settings, err := s.GetSettings()
+	if err != nil || settings.MaxAgeDays <= 0 {
+		return 0, err
+	}
+	return s.repo.DeleteBefore(time.Now().UTC().AddDate(0, 0, -settings.MaxAgeDays))
+}
+
+// DeleteContent removes the history of a deleted item.
+func (s *RevisionService) DeleteContent(contentType string, contentID uint) error {
+	if s == nil || s.repo == nil {
+		return nil
+	}
+	return s.repo.DeleteByContent(contentType, contentID)
+}
+
+func revisionSectionsJSON(sections models.PostSections) string {
+	if len(sections) == 0 {
+		return ""
+	}
+	encoded, err := json.MarshalIndent(sections, "", "  ")
+	if err != nil {
+		return ""
+	}
+	return string(encoded)
+}
+
+// diffLines returns the line diff of a and b, using their longest common
+// subsequence.
+func diffLines(a, b string) []models.DiffLine {
+	var oldLines, newLines []string
+	if a != "" {
+		oldLines = strings.Split(a, "\n")
+	}
+	if b != "" {
+		newLines = strings.Split(b, "\n")
+	}
+
+	n, m := len(oldLines), len(newLines)
+	if n*m > revisionDiffMaxCells {
+		lines := make([]models.DiffLine, 0, n+m)
+		for _, line := range oldLines {
+			lines = append(lines, models.DiffLine{Op: models.DiffDelete, Text: line})
+		}
+		for _, line := range newLines {
+			lines = append(lines, models.DiffLine{Op: models.DiffInsert, Text: line})
+		}
+		return lines
+	}
+
+	// common[i][j] is the length of the common subsequence of oldLines[i:]
+	// and newLines[j:].
+	common := make([][]int, n+1)
+	for i := range common {
+		common[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if oldLines[i] == newLines[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else {
+				common[i][j] = max(common[i+1][j], common[i][j+1])
+			}
+		}
+	}
+
+	lines := make([]models.DiffLine, 0, max(n, m))
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case oldLines[i] == newLines[j]:
+			lines = append(lines, models.DiffLine{Op: models.DiffEqual, Text: oldLines[i]})
+			i++
+			j++
+		case common[i+1][j] >= common[i][j+1]:
+			lines = append(lines, models.DiffLine{Op: models.DiffDelete, Text: oldLines[i]})
+			i++
+		default:
+			lines = append(lines, models.DiffLine{Op: models.DiffInsert, Text: newLines[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		lines = append(lines, models.DiffLine{Op: models.DiffDelete, Text: oldLines[i]})
+	}
+	for ; j < m; j++ {
+		lines = append(lines, models.DiffLine{Op: models.DiffInsert, Text: newLines[j]})
+	}
+	return lines
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"constructor-script-backend/internal/models"
+)
+
+type memoryRevisionRepository struct {
+	revisions []models.Revision
+}
+
+func (r *memoryRevisionRepository) Create(revision *models.Revision) error {
+	revision.ID = uint(len(r.revisions) + 1)
+	if len(r.revisions) > 0 {
+		revision.ID = r.revisions[len(r.revisions)-1].ID + 1
+	}
+	revision.CreatedAt = time.Now().UTC()
+	r.revisions = append(r.revisions, *revision)
+	return nil
+}
+
+func (r *memoryRevisionRepository) GetByID(id uint) (*models.Revision, error) {
+	for i := range r.revisions {
+		if r.revisions[i].ID == id {
+			revision := r.revisions[i]
+			return &revision, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memoryRevisionRepository) Latest(contentType string, contentID uint) (*models.Revision, error) {
+	list, _ := r.List(contentType, contentID, 1)
+	if len(list) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return r.GetByID(list[0].ID)
+}
+
+func (r *memoryRevisionRepository) List(contentType string, contentID uint, limit int) ([]models.Revision, error) {
+	var revisions []models.Revision
+	for i := len(r.revisions) - 1; i >= 0 && (limit <= 0 || len(revisions) < limit); i-- {
+		if r.revisions[i].ContentType == contentType && r.revisions[i].ContentID == contentID {
+			revisions = append(revisions, models.Revision{ID: r.revisions[i].ID, ContentType: contentType, ContentID: contentID, Title: r.revisions[i].Title})
+		}
+	}
+	return revisions, nil
+}
+
+func (r *memoryRevisionRepository) Previous(revision *models.Revision) (*models.Revision, error) {
+	for i := len(r.revisions) - 1; i >= 0; i-- {
+		candidate := r.revisions[i]
+		if candidate.ContentType == revision.ContentType && candidate.ContentID == revision.ContentID && candidate.ID < revision.ID {
+			return &candidate, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memoryRevisionRepository) KeepLatest(contentType string, contentID uint, keep int) (int64, error) {
+	kept, _ := r.List(contentType, contentID, keep)
+	keepIDs := make(map[uint]bool, len(kept))
+	for _, revision := range kept {
+		keepIDs[revision.ID] = true
+	}
+	var deleted int64
+	remaining := r.revisions[:0]
+	for _, revision := range r.revisions {
+		if revision.ContentType == contentType && revision.ContentID == contentID && !keepIDs[revision.ID] {
+			deleted++
+			continue
+		}
+		remaining = append(remaining, revision)
+	}
+	r.revisions = remaining
+	return deleted, nil
+}
+
+func (r *memoryRevisionRepository) DeleteBefore(time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (r *memoryRevisionRepository) DeleteByContent(contentType string, contentID uint) error {
+	_, err := r.KeepLatest(contentType, contentID, 0)
+	return err
+}
+
+type recordingRestorer struct {
+	restored []uint
+}
+
+func (r *recordingRestorer) RestoreRevision(revision *models.Revision) error {
+	r.restored = append(r.restored, revision.ID)
+	return nil
+}
+
+func TestRevisionHistory(t *testing.T) {
+	repo := &memoryRevisionRepository{}
+	svc := NewRevisionService(repo, &memorySettingRepository{values: make(map[string]string)})
+
+	if _, err := svc.UpdateSettings(models.RevisionSettings{MaxPerItem: -1}); err == nil {
+		t.Fatal("expected a negative limit to be rejected")
+	}
+	if _, err := svc.UpdateSettings(models.RevisionSettings{MaxPerItem: 3}); err != nil {
+		t.Fatal(err)
+	}
+
+	page := models.Revision{ContentType: models.RevisionContentPage, ContentID: 7, Title: "About", Content: "one\ntwo"}
+	for _, content := range []string{"one\ntwo", "one\ntwo", "one\nthree", "zero\none\nthree", "one"} {
+		page.Content = content
+		if err := svc.Record(page); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := svc.Record(models.Revision{ContentType: models.RevisionContentPost, ContentID: 7, Title: "Post"}); err != nil {
+		t.Fatal(err)
+	}
+
+	revisions, err := svc.List(models.RevisionContentPage, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revisions) != 3 || revisions[0].ID != 4 || revisions[2].ID != 2 {
+		t.Fatalf("expected unchanged saves to be skipped and the oldest revision pruned, got %+v", revisions)
+	}
+
+	diff, err := svc.Diff(3, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []models.DiffLine{
+		{Op: models.DiffInsert, Text: "zero"},
+		{Op: models.DiffEqual, Text: "one"},
+		{Op: models.DiffEqual, Text: "three"},
+	}
+	if diff.From == nil || diff.From.ID != 2 || len(diff.Changes) != 1 || !reflect.DeepEqual(diff.Changes[0].Lines, want) {
+		t.Fatalf("unexpected diff %+v", diff)
+	}
+	if diff, err = svc.Diff(2, 0); err != nil || diff.From != nil || len(diff.Changes) != 2 {
+		t.Fatalf("expected the oldest kept revision to be compared with nothing, got %+v %v", diff, err)
+	}
+	var validationErr *RevisionValidationError
+	if _, err := svc.Diff(4, 5); !errors.As(err, &validationErr) {
+		t.Fatalf("expected revisions of different content not to be compared, got %v", err)
+	}
+
+	if _, err := svc.Restore(2); !errors.Is(err, ErrRevisionNotRestorable) {
+		t.Fatalf("expected a restore without a restorer to fail, got %v", err)
+	}
+	restorer := &recordingRestorer{}
+	svc.SetRestorer(models.RevisionContentPage, restorer)
+	if _, err := svc.Restore(2); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(restorer.restored, []uint{2}) {
+		t.Fatalf("expected revision 2 to be restored, got %v", restorer.restored)
+	}
+}
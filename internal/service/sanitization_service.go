@@ -0,0 +1,249 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/microcosm-cc/bluemonday"
+	"gorm.io/gorm"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/repository"
+	"constructor-script-backend/pkg/logger"
+)
+
+const (
+	// SettingKeySanitization stores the sanitization policies in the settings
+	// repository.
+	SettingKeySanitization = "content.sanitization"
+
+	sanitizationSettingsRefresh = 30 * time.Second
+)
+
+var ErrSanitizationUnavailable = errors.New("sanitization settings not configured")
+
+type SanitizationValidationError struct {
+	Reason string
+}
+
+func (e *SanitizationValidationError) Error() string {
+	if e == nil {
+		return ""
+	}
+	return e.Reason
+}
+
+func sanitizationValidationErrorf(format string, args ...interface{}) error {
+	return &SanitizationValidationError{Reason: fmt.Sprintf(format, args...)}
+}
+
+// DefaultSanitizationSettings keeps the markup pages and sections have always
+// allowed, limits comments to basic formatting and shows forum posts as typed.
+func DefaultSanitizationSettings() models.SanitizationSettings {
+	rich := models.SanitizationPolicy{Level: models.SanitizeLevelRich, AllowImages: true, AllowClasses: true, AllowStyles: true}
+	return models.SanitizationSettings{Policies: map[string]models.SanitizationPolicy{
+		models.SanitizeComments:         {Level: models.SanitizeLevelBasic},
+		models.SanitizeForum:            {Level: models.SanitizeLevelText},
+		models.SanitizePageContent:      rich,
+		models.SanitizeSectionParagraph: rich,
+	}}
+}
+
+// SanitizationService cleans the markup users and editors save before it is
+// rendered, with a policy for every context it appears in. Stored content is
+// left as written, so changing a policy applies to existing content too.
+type SanitizationService struct {
+	settingRepo repository.SettingRepository
+
+	mu       sync.RWMutex
+	policies map[string]*bluemonday.Policy
+	loadedAt time.Time
+}
+
+func NewSanitizationService(settingRepo repository.SettingRepository) *SanitizationService {
+	return &SanitizationService{settingRepo: settingRepo}
+}
+
+func (s *SanitizationService) GetSettings() (models.SanitizationSettings, error) {
+	settings := DefaultSanitizationSettings()
+	if s == nil || s.settingRepo == nil {
+		return settings, nil
+	}
+
+	stored, err := s.settingRepo.Get(SettingKeySanitization)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return settings, nil
+		}
+		return settings, err
+	}
+	if strings.TrimSpace(stored.Value) == "" {
+		return settings, nil
+	}
+
+	var saved models.SanitizationSettings
+	if err := json.Unmarshal([]byte(stored.Value), &saved); err != nil {
+		return settings, fmt.Errorf("failed to decode sanitization settings: %w", err)
+	}
+	for context, policy := range saved.Policies {
+		if _, known := settings.Policies[context]; known && validSanitizeLevel(policy.Level) {
+			settings.Policies[context] = policy
+		}
+	}
+	return settings, nil
+}
+
+// UpdateSettings saves the policies in req. Contexts left out keep their
+// current policy.
+func (s *SanitizationService) UpdateSettings(req models.SanitizationSettings) (models.SanitizationSettings, error) {
+	if s == nil || s.settingRepo == nil {
+		return models.SanitizationSettings{}, ErrSanitizationUnavailable
+	}
+
+	settings, err := s.GetSettings()
+	if err != nil {
+		return models.SanitizationSettings{}, err
+	}
+	for context, policy := range req.Policies {
+		if _, known := settings.Policies[context]; !known {
+			return models.SanitizationSettings{}, sanitizationValidationErrorf("unknown sanitization context %q", context)
+		}
+		policy.Level = strings.ToLower(strings.TrimSpace(policy.Level))
+		if !validSanitizeLevel(policy.Level) {
+			return models.SanitizationSettings{}, sanitizationValidationErrorf("level of %s must be text, basic or rich", context)
+		}
+		settings.Policies[context] = policy
+	}
+
+	encoded, err := json.Marshal(settings)
+	if err != nil {
+		return models.SanitizationSettings{}, fmt.Errorf("failed to encode sanitization settings: %w", err)
+	}
+	if err := s.settingRepo.Set(SettingKeySanitization, string(encoded)); err != nil {
+		return models.SanitizationSettings{}, err
+	}
+
+	s.mu.Lock()
+	s.policies = buildSanitizationPolicies(settings)
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+
+	return settings, nil
+}
+
+// Sanitize returns input as safe HTML under the policy of context. Unknown
+// contexts get the text level.
+func (s *SanitizationService) Sanitize(context, input string) string {
+	if input == "" {
+		return ""
+	}
+	policy := s.policy(context)
+	if policy == nil {
+		return template.HTMLEscapeString(input)
+	}
+	return policy.Sanitize(input)
+}
+
+// policy returns the compiled policy of context, or nil for the text level.
+// Policies are reloaded from the database every 30 seconds so every instance
+// picks up changes.
+func (s *SanitizationService) policy(context string) *bluemonday.Policy {
+	if s == nil {
+		return defaultSanitizationPolicies[context]
+	}
+
+	s.mu.RLock()
+	policies, loadedAt := s.policies, s.loadedAt
+	s.mu.RUnlock()
+	if !loadedAt.IsZero() && time.Since(loadedAt) < sanitizationSettingsRefresh {
+		return policies[context]
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.loadedAt.IsZero() && time.Since(s.loadedAt) < sanitizationSettingsRefresh {
+		return s.policies[context]
+	}
+
+	settings, err := s.GetSettings()
+	s.loadedAt = time.Now()
+	if err != nil {
+		logger.Error(err, "Failed to load sanitization settings", nil)
+		if s.policies == nil {
+			s.policies = defaultSanitizationPolicies
+		}
+		return s.policies[context]
+	}
+	s.policies = buildSanitizationPolicies(settings)
+	return s.policies[context]
+}
+
+var defaultSanitizationPolicies = buildSanitizationPolicies(DefaultSanitizationSettings())
+
+func validSanitizeLevel(level string) bool {
+	switch level {
+	case models.SanitizeLevelText, models.SanitizeLevelBasic, models.SanitizeLevelRich:
+		return true
+	}
+	return false
+}
+
+func buildSanitizationPolicies(settings models.SanitizationSettings) map[string]*bluemonday.Policy {
+	policies := make(map[string]*bluemonday.Policy, len(settings.Policies))
+	for context, policy := range settings.Policies {
+		if compiled := newSanitizationPolicy(policy); compiled != nil {
+			policies[context] = compiled
+		}
+	}
+	return policies
+}
+
+// newSanitizationPolicy compiles settings into a bluemonday policy. The text
+// level needs none: its input is escaped instead.
+func newSanitizationPolicy(settings models.SanitizationPolicy) *bluemonday.Policy {
+	var policy *bluemonday.Policy
+	switch settings.Level {
+	case models.SanitizeLevelBasic:
+		policy = bluemonday.NewPolicy()
+		policy.AllowStandardURLs()
+		policy.RequireNoFollowOnLinks(true)
+		policy.AllowAttrs("href").OnElements("a")
+		policy.AllowElements("b", "blockquote", "br", "code", "em", "i", "p", "pre", "s", "strong", "u")
+		policy.AllowLists()
+	case models.SanitizeLevelRich:
+		if settings.AllowImages {
+			policy = bluemonday.UGCPolicy()
+			break
+		}
+		// UGCPolicy without images, which bluemonday cannot take back.
+		policy = bluemonday.NewPolicy()
+		policy.AllowStandardAttributes()
+		policy.AllowStandardURLs()
+		policy.AllowAttrs("href").OnElements("a")
+		policy.AllowAttrs("cite").OnElements("blockquote", "q")
+		policy.AllowElements("article", "aside", "figure", "figcaption", "section", "summary", "details",
+			"h1", "h2", "h3", "h4", "h5", "h6", "hgroup", "br", "div", "hr", "p", "span", "wbr",
+			"abbr", "acronym", "cite", "code", "dfn", "em", "mark", "s", "samp", "strong", "sub", "sup", "var",
+			"b", "i", "pre", "small", "strike", "tt", "u", "del", "ins")
+		policy.AllowLists()
+		policy.AllowTables()
+	default:
+		return nil
+	}
+
+	if settings.AllowImages && settings.Level != models.SanitizeLevelRich {
+		policy.AllowImages()
+	}
+	if settings.AllowClasses {
+		policy.AllowAttrs("class", "id").Globally()
+	}
+	if settings.AllowStyles {
+		policy.AllowAttrs("style").OnElements("span", "div", "p")
+	}
+	return policy
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"constructor-script-backend/internal/models"
+)
+
+func TestSanitizationPolicies(t *testing.T) {
+	svc := NewSanitizationService(&memorySettingRepository{values: make(map[string]string)})
+	input := `<p class="lead" onclick="steal()">Hi <img src="/a.png"><script>alert(1)</script><a href="javascript:alert(1)">x</a></p>`
+
+	page := svc.Sanitize(models.SanitizePageContent, input)
+	if !strings.Contains(page, `class="lead"`) || !strings.Contains(page, "<img") || strings.Contains(page, "script") || strings.Contains(page, "onclick") {
+		t.Fatalf("unexpected page content %q", page)
+	}
+	if comment := svc.Sanitize(models.SanitizeComments, input); strings.Contains(comment, "<img") || strings.Contains(comment, "class=") || !strings.Contains(comment, "<p>") {
+		t.Fatalf("expected comments to keep basic formatting only, got %q", comment)
+	}
+	if forum := svc.Sanitize(models.SanitizeForum, "a <b>b</b>"); forum != "a &lt;b&gt;b&lt;/b&gt;" {
+		t.Fatalf("expected forum posts to be escaped, got %q", forum)
+	}
+	if unknown := svc.Sanitize("signature", "<b>b</b>"); unknown != "&lt;b&gt;b&lt;/b&gt;" {
+		t.Fatalf("expected unknown contexts to be escaped, got %q", unknown)
+	}
+
+	var validationErr *SanitizationValidationError
+	if _, err := svc.UpdateSettings(models.SanitizationSettings{Policies: map[string]models.SanitizationPolicy{"signature": {Level: models.SanitizeLevelText}}}); !errors.As(err, &validationErr) {
+		t.Fatalf("expected an unknown context to be rejected, got %v", err)
+	}
+	if _, err := svc.UpdateSettings(models.SanitizationSettings{Policies: map[string]models.SanitizationPolicy{models.SanitizeForum: {Level: "html"}}}); !errors.As(err, &validationErr) {
+		t.Fatalf("expected an unknown level to be rejected, got %v", err)
+	}
+
+	settings, err := svc.UpdateSettings(models.SanitizationSettings{Policies: map[string]models.SanitizationPolicy{
+		models.SanitizePageContent: {Level: "Rich"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if settings.Policies[models.SanitizeForum].Level != models.SanitizeLevelText {
+		t.Fatalf("expected contexts left out to keep their policy, got %+v", settings.Policies)
+	}
+	if page := svc.Sanitize(models.SanitizePageContent, input); strings.Contains(page, "<img") || strings.Contains(page, "class=") {
+		t.Fatalf("expected images and classes to be removed, got %q", page)
+	}
+
+	reloaded, err := NewSanitizationService(svc.settingRepo).GetSettings()
+	if err != nil || reloaded.Policies[models.SanitizePageContent].AllowImages {
+		t.Fatalf("expected the policies to be stored, got %+v %v", reloaded, err)
+	}
+}
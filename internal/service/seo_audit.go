@@ -0,0 +1,352 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Title lengths outside this range tend to be truncated or ignored in search results.
+const (
+	seoTitleMinLength = 10
+	seoTitleMaxLength = 60
+)
+
+// SEO audit issue severities.
+const (
+	SEOSeverityError   = "error"
+	SEOSeverityWarning = "warning"
+)
+
+// SEOAuditIssue is one problem found on a rendered page.
+type SEOAuditIssue struct {
+	Code     string `json:"code"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// SEOAuditReport summarises the search-relevant markup of a rendered page.
+type SEOAuditReport struct {
+	Path             string          `json:"path"`
+	Status           int             `json:"status"`
+	GeneratedAt      time.Time       `json:"generated_at"`
+	Title            string          `json:"title"`
+	TitleLength      int             `json:"title_length"`
+	MetaDescription  string          `json:"meta_description"`
+	Headings         []string        `json:"h1"`
+	ImagesWithoutAlt []string        `json:"images_without_alt"`
+	StructuredData   int             `json:"structured_data_blocks"`
+	Issues           []SEOAuditIssue `json:"issues"`
+}
+
+func (r *SEOAuditReport) addIssue(code, severity, message string) {
+	r.Issues = append(r.Issues, SEOAuditIssue{Code: code, Severity: severity, Message: message})
+}
+
+// AuditHTML inspects a rendered HTML document for common SEO problems: missing
+// meta description, missing or duplicate H1s, images without alt attributes, title
+// length and invalid structured data.
+func AuditHTML(path string, status int, document io.Reader) (*SEOAuditReport, error) {
+	root, err := html.Parse(document)
+	if err != nil {
+		return nil, fmt.Errorf("parse document: %w", err)
+	}
+
+	report := &SEOAuditReport{
+		Path:             path,
+		Status:           status,
+		GeneratedAt:      time.Now().UTC(),
+		Headings:         []string{},
+		ImagesWithoutAlt: []string{},
+		Issues:           []SEOAuditIssue{},
+	}
+
+	var structuredData []string
+	var visit func(node *html.Node)
+	visit = func(node *html.Node) {
+		if node.Type == html.ElementNode {
+			switch node.DataAtom {
+			case atom.Title:
+				if report.Title == "" {
+					report.Title = strings.TrimSpace(nodeText(node))
+				}
+			case atom.Meta:
+				if strings.EqualFold(nodeAttr(node, "name"), "description") {
+					report.MetaDescription = strings.TrimSpace(nodeAttr(node, "content"))
+				}
+			case atom.H1:
+				report.Headings = append(report.Headings, strings.Join(strings.Fields(nodeText(node)), " "))
+			case atom.Img:
+				if _, ok := lookupAttr(node, "alt"); !ok {
+					report.ImagesWithoutAlt = append(report.ImagesWithoutAlt, nodeAttr(node, "src"))
+				}
+			case atom.Script:
+				if strings.EqualFold(strings.TrimSpace(nodeAttr(node, "type")), "application/ld+json") {
+					structuredData = append(structuredData, nodeText(node))
+				}
+			}
+		}
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			visit(child)
+		}
+	}
+	visit(root)
+
+	report.TitleLength = utf8.RuneCountInString(report.Title)
+	report.StructuredData = len(structuredData)
+
+	if status >= 400 {
+		report.addIssue("status", SEOSeverityError, fmt.Sprintf("Page responded with status %d", status))
+	}
+
+	switch {
+	case report.Title == "":
+		report.addIssue("title_missing", SEOSeverityError, "Page has no title")
+	case report.TitleLength < seoTitleMinLength:
+		report.addIssue("title_short", SEOSeverityWarning, fmt.Sprintf("Title is %d characters; aim for at least %d", report.TitleLength, seoTitleMinLength))
+	case report.TitleLength > seoTitleMaxLength:
+		report.addIssue("title_long", SEOSeverityWarning, fmt.Sprintf("Title is %d characters; search engines usually show about %d", report.TitleLength, seoTitleMaxLength))
+	}
+
+	if report.MetaDescription == "" {
+		report.addIssue("meta_description_missing", SEOSeverityError, "Page has no meta description")
+	}
+
+	switch len(report.Headings) {
+	case 0:
+		report.addIssue("h1_missing", SEOSeverityWarning, "Page has no H1 heading")
+	case 1:
+	default:
+		report.addIssue("h1_duplicate", SEOSeverityWarning, fmt.Sprintf("Page has %d H1 headings; use exactly one", len(report.Headings)))
+	}
+
+	for _, src := range report.ImagesWithoutAlt {
+		report.addIssue("image_alt_missing", SEOSeverityWarning, fmt.Sprintf("Image %q has no alt attribute", src))
+	}
+
+	for index, block := range structuredData {
+		problems, warnings := InspectStructuredData(block)
+		for _, problem := range problems {
+			report.addIssue("structured_data_invalid", SEOSeverityError, fmt.Sprintf("Structured data block %d: %s", index+1, problem))
+		}
+		for _, warning := range warnings {
+			report.addIssue("structured_data_incomplete", SEOSeverityWarning, fmt.Sprintf("Structured data block %d: %s", index+1, warning))
+		}
+	}
+
+	return report, nil
+}
+
+// structuredDataRequired lists the properties search engines need before a type is
+// eligible for rich results.
+var structuredDataRequired = map[string][]string{
+	"Article":        {"headline"},
+	"BlogPosting":    {"headline"},
+	"NewsArticle":    {"headline"},
+	"BreadcrumbList": {"itemListElement"},
+	"Course":         {"name", "description"},
+	"Event":          {"name", "startDate"},
+	"Organization":   {"name"},
+	"Product":        {"name"},
+	"QAPage":         {"mainEntity"},
+	"WebSite":        {"name", "url"},
+}
+
+// structuredDataRecommended lists properties that are not strictly required but
+// that search engines use to build richer results.
+var structuredDataRecommended = map[string][]string{
+	"Article":      {"image", "datePublished", "author"},
+	"BlogPosting":  {"image", "datePublished", "author"},
+	"NewsArticle":  {"image", "datePublished", "author"},
+	"Course":       {"provider"},
+	"Event":        {"location"},
+	"Organization": {"url", "logo"},
+	"Product":      {"image", "offers"},
+}
+
+// StructuredDataResult is the outcome of validating one JSON-LD block.
+type StructuredDataResult struct {
+	Block    int      `json:"block"`
+	Types    []string `json:"types"`
+	Errors   []string `json:"errors"`
+	Warnings []string `json:"warnings"`
+}
+
+// ValidateStructuredDocument validates every JSON-LD block in a rendered HTML
+// document.
+func ValidateStructuredDocument(document io.Reader) ([]StructuredDataResult, error) {
+	root, err := html.Parse(document)
+	if err != nil {
+		return nil, fmt.Errorf("parse document: %w", err)
+	}
+
+	var blocks []string
+	var visit func(node *html.Node)
+	visit = func(node *html.Node) {
+		if node.Type == html.ElementNode && node.DataAtom == atom.Script &&
+			strings.EqualFold(strings.TrimSpace(nodeAttr(node, "type")), "application/ld+json") {
+			blocks = append(blocks, nodeText(node))
+		}
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			visit(child)
+		}
+	}
+	visit(root)
+
+	results := make([]StructuredDataResult, 0, len(blocks))
+	for index, block := range blocks {
+		problems, warnings := InspectStructuredData(block)
+		result := StructuredDataResult{Block: index + 1, Types: []string{}, Errors: []string{}, Warnings: []string{}}
+		result.Errors = append(result.Errors, problems...)
+		result.Warnings = append(result.Warnings, warnings...)
+		nodes, _ := structuredDataNodes(block)
+		for _, node := range nodes {
+			result.Types = append(result.Types, structuredDataTypes(node["@type"])...)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// ValidateStructuredData checks a JSON-LD block for syntax errors, a schema.org
+// context, a type on every node and the required properties of known types.
+func ValidateStructuredData(raw string) []string {
+	problems, _ := InspectStructuredData(raw)
+	return problems
+}
+
+// InspectStructuredData validates a JSON-LD block like ValidateStructuredData and
+// additionally returns warnings for missing recommended properties.
+func InspectStructuredData(raw string) ([]string, []string) {
+	nodes, err := structuredDataNodes(raw)
+	if err != nil {
+		return []string{err.Error()}, nil
+	}
+
+	var problems, warnings []string
+	for _, node := range nodes {
+		context, _ := node["@context"].(string)
+		if !strings.Contains(context, "schema.org") {
+			problems = append(problems, "missing schema.org @context")
+		}
+
+		types := structuredDataTypes(node["@type"])
+		if len(types) == 0 {
+			problems = append(problems, "missing @type")
+			continue
+		}
+		for _, typ := range types {
+			for _, property := range structuredDataRequired[typ] {
+				if isEmptyStructuredValue(node[property]) {
+					problems = append(problems, fmt.Sprintf("%s is missing required property %q", typ, property))
+				}
+			}
+			for _, property := range structuredDataRecommended[typ] {
+				if isEmptyStructuredValue(node[property]) {
+					warnings = append(warnings, fmt.Sprintf("%s is missing recommended property %q", typ, property))
+				}
+			}
+		}
+	}
+	return problems, warnings
+}
+
+// structuredDataNodes decodes a JSON-LD block into its top-level nodes. Nodes of an
+// @graph inherit the graph's @context.
+func structuredDataNodes(raw string) ([]map[string]interface{}, error) {
+	var document interface{}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(raw)), &document); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %v", err)
+	}
+
+	var nodes []map[string]interface{}
+	switch value := document.(type) {
+	case map[string]interface{}:
+		if graph, ok := value["@graph"].([]interface{}); ok {
+			for _, item := range graph {
+				if node, ok := item.(map[string]interface{}); ok {
+					if _, hasContext := node["@context"]; !hasContext {
+						node["@context"] = value["@context"]
+					}
+					nodes = append(nodes, node)
+				}
+			}
+		} else {
+			nodes = append(nodes, value)
+		}
+	case []interface{}:
+		for _, item := range value {
+			if node, ok := item.(map[string]interface{}); ok {
+				nodes = append(nodes, node)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("expected a JSON object or array")
+	}
+	return nodes, nil
+}
+
+func structuredDataTypes(value interface{}) []string {
+	switch typed := value.(type) {
+	case string:
+		if strings.TrimSpace(typed) != "" {
+			return []string{strings.TrimSpace(typed)}
+		}
+	case []interface{}:
+		var types []string
+		for _, item := range typed {
+			if s, ok := item.(string); ok && strings.TrimSpace(s) != "" {
+				types = append(types, strings.TrimSpace(s))
+			}
+		}
+		return types
+	}
+	return nil
+}
+
+func isEmptyStructuredValue(value interface{}) bool {
+	switch typed := value.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(typed) == ""
+	case []interface{}:
+		return len(typed) == 0
+	}
+	return false
+}
+
+func lookupAttr(node *html.Node, name string) (string, bool) {
+	for _, attr := range node.Attr {
+		if strings.EqualFold(attr.Key, name) {
+			return attr.Val, true
+		}
+	}
+	return "", false
+}
+
+func nodeAttr(node *html.Node, name string) string {
+	value, _ := lookupAttr(node, name)
+	return value
+}
+
+func nodeText(node *html.Node) string {
+	var builder strings.Builder
+	var collect func(*html.Node)
+	collect = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			builder.WriteString(n.Data)
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			collect(child)
+		}
+	}
+	collect(node)
+	return builder.String()
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"strings"
+	"testing"
+)
+
+func auditIssueCodes(report *SEOAuditReport) map[string]int {
+	codes := make(map[string]int)
+	for _, issue := range report.Issues {
+		codes[issue.Code]++
+	}
+	return codes
+}
+
+func TestAuditHTMLReportsProblems(t *testing.T) {
+	document := `<!doctype html><html><head><title>Hi</title>
+<script type="application/ld+json">{"@context":"https://schema.org","@type":"Article"}</script>
+<script type="application/ld+json">{not json</script>
+</head><body><h1>One</h1><h1>Two</h1><img src="/a.jpg"><img src="/b.jpg" alt=""></body></html>`
+
+	report, err := AuditHTML("/about", 200, strings.NewReader(document))
+	if err != nil {
+		t.Fatalf("audit: %v", err)
+	}
+
+	codes := auditIssueCodes(report)
+	for _, code := range []string{"title_short", "meta_description_missing", "h1_duplicate"} {
+		if codes[code] != 1 {
+			t.Fatalf("expected issue %q, got %+v", code, report.Issues)
+		}
+	}
+	if codes["image_alt_missing"] != 1 || report.ImagesWithoutAlt[0] != "/a.jpg" {
+		t.Fatalf("expected only /a.jpg to lack alt, got %v", report.ImagesWithoutAlt)
+	}
+	if codes["structured_data_invalid"] != 2 || report.StructuredData != 2 {
+		t.Fatalf("expected two structured data errors, got %+v", report.Issues)
+	}
+}
+
+func TestAuditHTMLCleanPage(t *testing.T) {
+	document := `<html><head><title>A well sized page title</title>
+<meta name="description" content="About the site">
+<script type="application/ld+json">{"@context":"https://schema.org","@graph":[{"@type":"WebSite","name":"Site","url":"https://example.com"}]}</script>
+</head><body><h1>About</h1><img src="/a.jpg" alt="Team photo"></body></html>`
+
+	report, err := AuditHTML("/about", 200, strings.NewReader(document))
+	if err != nil {
+		t.Fatalf("audit: %v", err)
+	}
+	if len(report.Issues) != 0 {
+		t.Fatalf("expected no issues, got %+v", report.Issues)
+	}
+	if report.MetaDescription != "About the site" || report.TitleLength != 23 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+}
+
+func TestValidateStructuredData(t *testing.T) {
+	if problems := ValidateStructuredData(`{"@context":"https://schema.org","@type":"Course","name":"Go"}`); len(problems) != 1 ||
+		!strings.Contains(problems[0], `"description"`) {
+		t.Fatalf("expected missing description, got %v", problems)
+	}
+	if problems := ValidateStructuredData(`{"@type":"Thing"}`); len(problems) != 1 || !strings.Contains(problems[0], "@context") {
+		t.Fatalf("expected missing context, got %v", problems)
+	}
+	if problems := ValidateStructuredData(`"text"`); len(problems) != 1 {
+		t.Fatalf("expected a shape error, got %v", problems)
+	}
+}
+
+func TestValidateStructuredDocument(t *testing.T) {
+	document := `<html><head>
+<script type="application/ld+json">{"@context":"https://schema.org","@type":"Organization","name":"Site","url":"https://example.com","logo":"https://example.com/logo.png"}</script>
+<script type="application/ld+json">{"@context":"https://schema.org","@type":["Course","Product"],"name":"Go","description":"Learn Go"}</script>
+<script type="application/ld+json">{"@context":"https://schema.org","@type":"Event",</script>
+</head><body></body></html>`
+
+	results, err := ValidateStructuredDocument(strings.NewReader(document))
+	if err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected three blocks, got %+v", results)
+	}
+	if len(results[0].Errors) != 0 || len(results[0].Warnings) != 0 {
+		t.Fatalf("expected a clean organization, got %+v", results[0])
+	}
+	if len(results[1].Types) != 2 || len(results[1].Errors) != 0 || len(results[1].Warnings) != 3 {
+		t.Fatalf("expected provider, image and offers warnings, got %+v", results[1])
+	}
+	if len(results[2].Errors) != 1 || !strings.Contains(results[2].Errors[0], "invalid JSON") {
+		t.Fatalf("expected a syntax error, got %+v", results[2])
+	}
+}
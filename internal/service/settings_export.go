@@ -0,0 +1,405 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+var ErrInvalidSettingsDocument = errors.New("invalid settings document")
+
+// SettingsImportError reports the section of a settings document that could not
+// be imported. Sections before it were imported already.
+type SettingsImportError struct {
+	Section string
+	// Invalid marks errors caused by the document rather than the server.
+	Invalid bool
+	Err     error
+}
+
+func (e *SettingsImportError) Error() string {
+	if e == nil {
+		return ""
+	}
+	return e.Section + ": " + e.Err.Error()
+}
+
+func (e *SettingsImportError) Unwrap() error {
+	if e == nil {
+		return nil
+	}
+	return e.Err
+}
+
+// SettingsExportService reads and writes the configuration of the site as a
+// models.SettingsDocument, through the services that own each section so that
+// imports are validated as edits in the admin are.
+type SettingsExportService struct {
+	db          *gorm.DB
+	setup       *SetupService
+	advertising *AdvertisingService
+	fonts       *FontService
+	socialLinks *SocialLinkService
+	menus       *MenuService
+	backup      *BackupService
+	plugins     *PluginService
+}
+
+// SettingsExportServices are the services that own the sections of a settings
+// document. Sections whose service is nil are neither exported nor imported.
+type SettingsExportServices struct {
+	Setup       *SetupService
+	Advertising *AdvertisingService
+	Fonts       *FontService
+	SocialLinks *SocialLinkService
+	Menus       *MenuService
+	Backup      *BackupService
+	Plugins     *PluginService
+}
+
+func NewSettingsExportService(db *gorm.DB, services SettingsExportServices) *SettingsExportService {
+	return &SettingsExportService{
+		db:          db,
+		setup:       services.Setup,
+		advertising: services.Advertising,
+		fonts:       services.Fonts,
+		socialLinks: services.SocialLinks,
+		menus:       services.Menus,
+		backup:      services.Backup,
+		plugins:     services.Plugins,
+	}
+}
+
+// Export returns the current settings. defaults are the site settings in force
+// where none are stored.
+func (s *SettingsExportService) Export(defaults models.SiteSettings) (*models.SettingsDocument, error) {
+	now := time.Now().UTC()
+	doc := &models.SettingsDocument{
+		Format:      models.SettingsDocumentFormat,
+		ExportedAt:  &now,
+		Application: backupApplication,
+	}
+
+	if s.setup != nil {
+		site, err := s.setup.GetSiteSettings(defaults)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load site settings: %w", err)
+		}
+		doc.Site = exportSiteSettings(site)
+	}
+
+	if s.advertising != nil {
+		advertising, err := s.advertising.GetSettings()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load advertising settings: %w", err)
+		}
+		doc.Advertising = &models.UpdateAdvertisingSettingsRequest{
+			Enabled:   advertising.Enabled,
+			Provider:  advertising.Provider,
+			GoogleAds: advertising.GoogleAds,
+		}
+	}
+
+	if s.fonts != nil {
+		fonts, err := s.fonts.List()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load fonts: %w", err)
+		}
+		doc.Fonts = &fonts
+	}
+
+	if s.socialLinks != nil {
+		links, err := s.socialLinks.List()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load social links: %w", err)
+		}
+		exported := make([]models.CreateSocialLinkRequest, 0, len(links))
+		for _, link := range links {
+			order := link.Order
+			exported = append(exported, models.CreateSocialLinkRequest{Name: link.Name, URL: link.URL, Icon: link.Icon, Order: &order})
+		}
+		doc.SocialLinks = &exported
+	}
+
+	if s.menus != nil {
+		items, err := s.menus.List()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load menu items: %w", err)
+		}
+		exported := make([]models.CreateMenuItemRequest, 0, len(items))
+		for _, item := range items {
+			order := item.Order
+			exported = append(exported, models.CreateMenuItemRequest{
+				Title:        item.Title,
+				URL:          item.URL,
+				Location:     item.Location,
+				Order:        &order,
+				Translations: item.Translations,
+				Language:     item.Language,
+			})
+		}
+		doc.MenuItems = &exported
+	}
+
+	if s.backup != nil {
+		backup, err := s.backup.GetAutoSettings()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load backup settings: %w", err)
+		}
+		doc.Backup = &models.UpdateBackupSettingsRequest{
+			Enabled:         backup.Enabled,
+			IntervalHours:   backup.IntervalHours,
+			RetentionCopies: backup.RetentionCopies,
+		}
+	}
+
+	if s.plugins != nil {
+		plugins, err := s.plugins.List()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load plugins: %w", err)
+		}
+		for _, info := range plugins {
+			if !info.Installed || !info.HasSettings {
+				continue
+			}
+			values, err := s.plugins.Settings(info.Slug)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load settings of plugin %s: %w", info.Slug, err)
+			}
+			if doc.Plugins == nil {
+				doc.Plugins = make(map[string]map[string]interface{})
+			}
+			doc.Plugins[info.Slug] = values
+		}
+	}
+
+	return doc, nil
+}
+
+func exportSiteSettings(site models.SiteSettings) *models.SiteSettingsExport {
+	subtitles := site.Subtitles
+	return &models.SiteSettingsExport{
+		Name:                     site.Name,
+		Description:              site.Description,
+		Favicon:                  site.Favicon,
+		Logo:                     site.Logo,
+		FooterText:               site.FooterText,
+		UnusedTagRetentionHours:  site.UnusedTagRetentionHours,
+		DefaultLanguage:          site.DefaultLanguage,
+		SupportedLanguages:       site.SupportedLanguages,
+		CourseCheckoutSuccessURL: site.CourseCheckoutSuccessURL,
+		CourseCheckoutCancelURL:  site.CourseCheckoutCancelURL,
+		CourseCheckoutCurrency:   site.CourseCheckoutCurrency,
+		ColorScheme:              site.ColorScheme,
+		AllowColorSchemeToggle:   site.AllowColorSchemeToggle,
+		Subtitles: models.UpdateSubtitleSettingsRequest{
+			Enabled:       subtitles.Enabled,
+			Provider:      subtitles.Provider,
+			PreferredName: subtitles.PreferredName,
+			Language:      subtitles.Language,
+			Prompt:        subtitles.Prompt,
+			Temperature:   subtitles.Temperature,
+			OpenAIModel:   subtitles.OpenAIModel,
+		},
+		Translations: site.Translations,
+	}
+}
+
+// Import replaces the sections present in doc, in document order. Plugins are
+// checked before anything is written; menus and social links are replaced in one
+// transaction. A failure stops the import with a *SettingsImportError.
+func (s *SettingsExportService) Import(doc models.SettingsDocument, defaults models.SiteSettings) (models.SettingsImportResult, error) {
+	result := models.SettingsImportResult{Imported: []string{}}
+	if doc.Format != models.SettingsDocumentFormat {
+		return result, &SettingsImportError{
+			Section: "format",
+			Invalid: true,
+			Err:     fmt.Errorf("%w: unsupported format %d, expected %d", ErrInvalidSettingsDocument, doc.Format, models.SettingsDocumentFormat),
+		}
+	}
+
+	slugs := make([]string, 0, len(doc.Plugins))
+	for slug := range doc.Plugins {
+		slugs = append(slugs, slug)
+	}
+	sort.Strings(slugs)
+	if len(slugs) > 0 {
+		if s.plugins == nil {
+			return result, settingsImportError("plugins", errSettingsSectionUnavailable)
+		}
+		for _, slug := range slugs {
+			if _, err := s.plugins.GetSettings(slug); err != nil {
+				return result, settingsImportError("plugins", err)
+			}
+		}
+	}
+
+	steps := []struct {
+		section string
+		present bool
+		apply   func() error
+	}{
+		{"site", doc.Site != nil, func() error { return s.importSite(*doc.Site, defaults) }},
+		{"advertising", doc.Advertising != nil, func() error {
+			if s.advertising == nil {
+				return errSettingsSectionUnavailable
+			}
+			_, err := s.advertising.UpdateSettings(*doc.Advertising)
+			return err
+		}},
+		{"fonts", doc.Fonts != nil, func() error {
+			if s.fonts == nil {
+				return errSettingsSectionUnavailable
+			}
+			_, err := s.fonts.Replace(*doc.Fonts)
+			return err
+		}},
+		{"navigation", doc.SocialLinks != nil || doc.MenuItems != nil, func() error {
+			return s.importNavigation(doc.SocialLinks, doc.MenuItems)
+		}},
+		{"backup", doc.Backup != nil, func() error {
+			if s.backup == nil {
+				return errSettingsSectionUnavailable
+			}
+			_, err := s.backup.UpdateAutoSettings(*doc.Backup)
+			return err
+		}},
+	}
+
+	for _, step := range steps {
+		if !step.present {
+			continue
+		}
+		if err := step.apply(); err != nil {
+			return result, settingsImportError(step.section, err)
+		}
+		switch step.section {
+		case "navigation":
+			if doc.SocialLinks != nil {
+				result.Imported = append(result.Imported, "social_links")
+			}
+			if doc.MenuItems != nil {
+				result.Imported = append(result.Imported, "menu_items")
+			}
+		default:
+			result.Imported = append(result.Imported, step.section)
+		}
+	}
+
+	for _, slug := range slugs {
+		if _, err := s.plugins.UpdateSettings(slug, doc.Plugins[slug]); err != nil {
+			return result, settingsImportError("plugins."+slug, err)
+		}
+	}
+	if len(slugs) > 0 {
+		result.Imported = append(result.Imported, "plugins")
+	}
+
+	return result, nil
+}
+
+func (s *SettingsExportService) importSite(site models.SiteSettingsExport, defaults models.SiteSettings) error {
+	if s.setup == nil {
+		return errSettingsSectionUnavailable
+	}
+	current, err := s.setup.GetSiteSettings(defaults)
+	if err != nil {
+		return err
+	}
+
+	subtitles := site.Subtitles
+	// The API key is not exported; an empty one keeps the stored key.
+	subtitles.OpenAIAPIKey = ""
+	translations := site.Translations
+	allowToggle := site.AllowColorSchemeToggle
+
+	// The URL and the Stripe keys stay those of this instance: empty Stripe keys
+	// keep the stored ones.
+	return s.setup.UpdateSiteSettings(models.UpdateSiteSettingsRequest{
+		Name:                     site.Name,
+		Description:              site.Description,
+		URL:                      current.URL,
+		Favicon:                  site.Favicon,
+		Logo:                     site.Logo,
+		FooterText:               site.FooterText,
+		UnusedTagRetentionHours:  site.UnusedTagRetentionHours,
+		DefaultLanguage:          site.DefaultLanguage,
+		SupportedLanguages:       site.SupportedLanguages,
+		CourseCheckoutSuccessURL: site.CourseCheckoutSuccessURL,
+		CourseCheckoutCancelURL:  site.CourseCheckoutCancelURL,
+		CourseCheckoutCurrency:   site.CourseCheckoutCurrency,
+		ColorScheme:              site.ColorScheme,
+		AllowColorSchemeToggle:   &allowToggle,
+		Subtitles:                &subtitles,
+		Translations:             &translations,
+	}, defaults)
+}
+
+// importNavigation replaces the social links and menu items given, together.
+func (s *SettingsExportService) importNavigation(links *[]models.CreateSocialLinkRequest, items *[]models.CreateMenuItemRequest) error {
+	if (links != nil && s.socialLinks == nil) || (items != nil && s.menus == nil) {
+		return errSettingsSectionUnavailable
+	}
+
+	replace := func(socialLinks *SocialLinkService, menus *MenuService) error {
+		if links != nil {
+			existing, err := socialLinks.List()
+			if err != nil {
+				return err
+			}
+			for _, link := range existing {
+				if err := socialLinks.Delete(link.ID); err != nil {
+					return err
+				}
+			}
+			for _, link := range *links {
+				if _, err := socialLinks.Create(link); err != nil {
+					return err
+				}
+			}
+		}
+		if items != nil {
+			if err := menus.DeleteAll(); err != nil {
+				return err
+			}
+			for _, item := range *items {
+				if _, err := menus.Create(item); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	if s.db == nil {
+		return replace(s.socialLinks, s.menus)
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var menus *MenuService
+		if s.menus != nil {
+			menus = s.menus.withTx(tx)
+		}
+		return replace(NewSocialLinkService(repository.NewSocialLinkRepository(tx)), menus)
+	})
+}
+
+var errSettingsSectionUnavailable = errors.New("section not supported by this instance")
+
+func settingsImportError(section string, err error) error {
+	var setupErr *ValidationError
+	var advertisingErr *AdvertisingValidationError
+	invalid := errors.As(err, &setupErr) ||
+		errors.As(err, &advertisingErr) ||
+		errors.Is(err, ErrInvalidBackupSettings) ||
+		errors.Is(err, ErrInvalidPluginSettings) ||
+		errors.Is(err, ErrPluginNotFound) ||
+		errors.Is(err, ErrInvalidFontSnippet) ||
+		errors.Is(err, errSettingsSectionUnavailable)
+	return &SettingsImportError{Section: section, Invalid: invalid, Err: err}
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"constructor-script-backend/internal/models"
+
+	"github.com/gin-gonic/gin/binding"
+)
+
+func TestSettingsExportRoundTrip(t *testing.T) {
+	source := &memorySettingRepository{values: make(map[string]string)}
+	sourceFonts := NewFontService(source)
+	if _, err := sourceFonts.Create(models.CreateFontAssetRequest{Name: "Inter", Snippet: `<link href="https://fonts.example.com/inter.css" rel="stylesheet">`}); err != nil {
+		t.Fatalf("Create font: %v", err)
+	}
+	sourceAds := NewAdvertisingService(source)
+	if _, err := sourceAds.UpdateSettings(models.UpdateAdvertisingSettingsRequest{}); err != nil {
+		t.Fatalf("UpdateSettings: %v", err)
+	}
+
+	exporter := NewSettingsExportService(nil, SettingsExportServices{Advertising: sourceAds, Fonts: sourceFonts})
+	doc, err := exporter.Export(models.SiteSettings{})
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	if doc.Format != models.SettingsDocumentFormat || doc.Site != nil || doc.Fonts == nil || len(*doc.Fonts) != 2 {
+		t.Fatalf("unexpected document %+v", doc)
+	}
+
+	target := &memorySettingRepository{values: make(map[string]string)}
+	targetFonts := NewFontService(target)
+	importer := NewSettingsExportService(nil, SettingsExportServices{Advertising: NewAdvertisingService(target), Fonts: targetFonts})
+	result, err := importer.Import(*doc, models.SiteSettings{})
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if len(result.Imported) != 2 || result.Imported[0] != "advertising" || result.Imported[1] != "fonts" {
+		t.Fatalf("unexpected imported sections %v", result.Imported)
+	}
+
+	fonts, err := targetFonts.List()
+	if err != nil || len(fonts) != 2 || fonts[1].Name != "Inter" || fonts[1].ID != (*doc.Fonts)[1].ID {
+		t.Fatalf("fonts not imported: %+v %v", fonts, err)
+	}
+}
+
+func TestSettingsImportRejectsInvalidDocuments(t *testing.T) {
+	importer := NewSettingsExportService(nil, SettingsExportServices{})
+
+	_, err := importer.Import(models.SettingsDocument{Format: 99}, models.SiteSettings{})
+	var importErr *SettingsImportError
+	if !errors.As(err, &importErr) || !importErr.Invalid || !errors.Is(err, ErrInvalidSettingsDocument) {
+		t.Fatalf("expected an unsupported format to be rejected, got %v", err)
+	}
+
+	fonts := []models.FontAsset{}
+	_, err = importer.Import(models.SettingsDocument{Format: models.SettingsDocumentFormat, Fonts: &fonts}, models.SiteSettings{})
+	if !errors.As(err, &importErr) || !importErr.Invalid || importErr.Section != "fonts" {
+		t.Fatalf("expected a section this instance lacks to be rejected, got %v", err)
+	}
+
+	items := []models.CreateMenuItemRequest{{Title: "Home"}}
+	doc := models.SettingsDocument{Format: models.SettingsDocumentFormat, MenuItems: &items}
+	if err := binding.Validator.ValidateStruct(&doc); err == nil {
+		t.Fatal("expected a menu item without a URL to fail validation")
+	}
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"mime/multipart"
@@ -20,6 +21,8 @@ import (
 	"constructor-script-backend/internal/authorization"
 	"constructor-script-backend/internal/models"
 	"constructor-script-backend/internal/repository"
+	"constructor-script-backend/internal/secrets"
+	"constructor-script-backend/internal/theme"
 	"constructor-script-backend/pkg/lang"
 	"constructor-script-backend/pkg/logger"
 	blogservice "constructor-script-backend/plugins/blog/service"
@@ -77,6 +80,7 @@ type SetupService struct {
 	settingRepo   repository.SettingRepository
 	uploadService *UploadService
 	language      *languageservice.LanguageService
+	themes        *theme.Manager
 	db            *gorm.DB
 }
 
@@ -105,6 +109,15 @@ func (s *SetupService) SetLanguageService(languageService *languageservice.Langu
 	s.language = languageService
 }
 
+// SetThemeManager sets the theme manager used to validate the site color scheme
+// against the variants of the active theme.
+func (s *SetupService) SetThemeManager(manager *theme.Manager) {
+	if s == nil {
+		return
+	}
+	s.themes = manager
+}
+
 func (s *SetupService) IsSetupComplete() (bool, error) {
 	if s.userRepo == nil {
 		return true, nil
@@ -225,6 +238,17 @@ func (s *SetupService) saveSiteSettings(req models.SetupRequest, defaults models
 	return nil
 }
 
+// SiteURL returns the site URL configured in site settings, or fallback when none is stored.
+func (s *SetupService) SiteURL(fallback string) string {
+	if s == nil {
+		return fallback
+	}
+	if value, err := s.getSettingValue(settingKeySiteURL); err == nil && strings.TrimSpace(value) != "" {
+		return strings.TrimSpace(value)
+	}
+	return fallback
+}
+
 func (s *SetupService) GetSiteSettings(defaults models.SiteSettings) (models.SiteSettings, error) {
 	if s.settingRepo == nil {
 		return defaults, nil
@@ -294,6 +318,19 @@ func (s *SetupService) GetSiteSettings(defaults models.SiteSettings) (models.Sit
 		result.FooterText = strings.TrimSpace(value)
 	}
 
+	if value, getErr := s.getSettingValue(settingKeySiteTranslations); getErr != nil {
+		if !errors.Is(getErr, gorm.ErrRecordNotFound) {
+			err = getErr
+		}
+	} else if value != "" {
+		var translations models.SiteTranslations
+		if parseErr := json.Unmarshal([]byte(value), &translations); parseErr == nil {
+			result.Translations = translations
+		} else {
+			err = parseErr
+		}
+	}
+
 	if value, getErr := s.getSettingValue(settingKeyTagRetentionHours); getErr != nil {
 		if !errors.Is(getErr, gorm.ErrRecordNotFound) {
 			err = getErr
@@ -354,6 +391,26 @@ func (s *SetupService) GetSiteSettings(defaults models.SiteSettings) (models.Sit
 		result.CourseCheckoutCurrency = strings.ToLower(strings.TrimSpace(value))
 	}
 
+	if value, getErr := s.getSettingValue(settingKeySiteColorScheme); getErr != nil {
+		if !errors.Is(getErr, gorm.ErrRecordNotFound) {
+			err = getErr
+		}
+	} else if scheme := theme.NormalizeColorScheme(value); scheme != "" {
+		result.ColorScheme = scheme
+	}
+
+	if value, getErr := s.getSettingValue(settingKeySiteColorSchemeToggle); getErr != nil {
+		if !errors.Is(getErr, gorm.ErrRecordNotFound) {
+			err = getErr
+		}
+	} else if value != "" {
+		if allowed, parseErr := strconv.ParseBool(value); parseErr == nil {
+			result.AllowColorSchemeToggle = allowed
+		} else {
+			err = parseErr
+		}
+	}
+
 	defaultLang, supported, langErr := s.resolveSiteLanguages(result.DefaultLanguage, result.SupportedLanguages)
 	if langErr != nil {
 		err = errors.Join(err, langErr)
@@ -512,6 +569,9 @@ func (s *SetupService) updateSubtitleSettings(req models.UpdateSubtitleSettingsR
 	}
 
 	requestedKey := strings.TrimSpace(req.OpenAIAPIKey)
+	if err := checkCredentialInput("subtitles.openai_api_key", requestedKey); err != nil {
+		return err
+	}
 	finalKey := requestedKey
 	if finalKey == "" {
 		finalKey = currentKey
@@ -728,6 +788,16 @@ func (s *SetupService) UpdateSiteSettings(req models.UpdateSiteSettingsRequest,
 	currentStripePublishable := s.currentSettingValue(settingKeyStripePublishableKey, defaults.StripePublishableKey)
 	currentStripeWebhook := s.currentSettingValue(settingKeyStripeWebhookSecret, defaults.StripeWebhookSecret)
 
+	for field, input := range map[string]string{
+		"stripe_secret_key":      req.StripeSecretKey,
+		"stripe_publishable_key": req.StripePublishableKey,
+		"stripe_webhook_secret":  req.StripeWebhookSecret,
+	} {
+		if err := checkCredentialInput(field, input); err != nil {
+			return err
+		}
+	}
+
 	stripeSecret, updateStripeSecret := normalizeCredentialInput(req.StripeSecretKey, currentStripeSecret)
 	stripePublish, updateStripePublish := normalizeCredentialInput(req.StripePublishableKey, currentStripePublishable)
 	stripeWebhook, updateStripeWebhook := normalizeCredentialInput(req.StripeWebhookSecret, currentStripeWebhook)
@@ -761,6 +831,25 @@ func (s *SetupService) UpdateSiteSettings(req models.UpdateSiteSettingsRequest,
 		}
 	}
 
+	colorScheme := ""
+	if trimmed := strings.TrimSpace(req.ColorScheme); trimmed != "" {
+		colorScheme = theme.NormalizeColorScheme(trimmed)
+		if colorScheme == "" {
+			return &ValidationError{
+				Field:   "color_scheme",
+				Message: "invalid color scheme: use light, dark, system, or a variant declared by the theme",
+			}
+		}
+		if s.themes != nil {
+			if active := s.themes.Active(); active != nil && !active.SupportsColorScheme(colorScheme) {
+				return &ValidationError{
+					Field:   "color_scheme",
+					Message: fmt.Sprintf("color scheme %q is not available in the active theme: use one of %s", colorScheme, strings.Join(availableColorSchemes(active), ", ")),
+				}
+			}
+		}
+	}
+
 	updates := map[string]string{
 		settingKeySiteName:                 strings.TrimSpace(req.Name),
 		settingKeySiteDescription:          strings.TrimSpace(req.Description),
@@ -772,6 +861,19 @@ func (s *SetupService) UpdateSiteSettings(req models.UpdateSiteSettingsRequest,
 		settingKeyCourseCheckoutSuccessURL: successURL,
 		settingKeyCourseCheckoutCancelURL:  cancelURL,
 		settingKeyCourseCheckoutCurrency:   currency,
+		settingKeySiteColorScheme:          colorScheme,
+	}
+
+	if req.AllowColorSchemeToggle != nil {
+		updates[settingKeySiteColorSchemeToggle] = strconv.FormatBool(*req.AllowColorSchemeToggle)
+	}
+
+	if req.Translations != nil {
+		encoded, err := encodeSiteTranslations(*req.Translations)
+		if err != nil {
+			return err
+		}
+		updates[settingKeySiteTranslations] = encoded
 	}
 
 	if updateStripeSecret {
@@ -820,6 +922,66 @@ func (s *SetupService) UpdateSiteSettings(req models.UpdateSiteSettingsRequest,
 	return nil
 }
 
+// availableColorSchemes lists the schemes an administrator can pick for the theme.
+func availableColorSchemes(active *theme.Theme) []string {
+	schemes := active.ColorSchemes()
+	if active.SupportsColorScheme(theme.ColorSchemeSystem) {
+		schemes = append(schemes, theme.ColorSchemeSystem)
+	}
+	return schemes
+}
+
+// encodeSiteTranslations normalizes the language codes of per-language site
+// identity and drops empty entries. An empty result clears the setting.
+// UpdateSiteTranslations replaces the translated site name, description and
+// footer text without touching the other site settings.
+func (s *SetupService) UpdateSiteTranslations(translations models.SiteTranslations) error {
+	if s.settingRepo == nil {
+		return errors.New("setting repository not configured")
+	}
+
+	encoded, err := encodeSiteTranslations(translations)
+	if err != nil {
+		return err
+	}
+	if encoded == "" {
+		if err := s.settingRepo.Delete(settingKeySiteTranslations); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		return nil
+	}
+	return s.settingRepo.Set(settingKeySiteTranslations, encoded)
+}
+
+func encodeSiteTranslations(translations models.SiteTranslations) (string, error) {
+	normalized := make(models.SiteTranslations, len(translations))
+	for code, translation := range translations {
+		key, err := lang.Normalize(code)
+		if err != nil {
+			return "", &ValidationError{Field: "translations", Message: fmt.Sprintf("%q is not a valid language code", code)}
+		}
+		translation.Name = strings.TrimSpace(translation.Name)
+		translation.Description = strings.TrimSpace(translation.Description)
+		translation.FooterText = strings.TrimSpace(translation.FooterText)
+		if len(translation.FooterText) > 500 {
+			return "", &ValidationError{Field: "translations", Message: "footer text must be at most 500 characters"}
+		}
+		if translation == (models.SiteTranslation{}) {
+			continue
+		}
+		normalized[key] = translation
+	}
+	if len(normalized) == 0 {
+		return "", nil
+	}
+
+	encoded, err := json.Marshal(normalized)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
 func normalizeCredentialInput(input, current string) (string, bool) {
 	trimmedInput := strings.TrimSpace(input)
 	currentValue := strings.TrimSpace(current)
@@ -836,6 +998,16 @@ func normalizeCredentialInput(input, current string) (string, bool) {
 	}
 }
 
+// checkCredentialInput refuses a secret reference submitted as a credential.
+// References are resolved on the server, so one could read any file or secret
+// the server can reach; only the operator sets them, in the environment.
+func checkCredentialInput(field, input string) error {
+	if secrets.IsReference(input) {
+		return &ValidationError{Field: field, Message: "secret references can only be set in the environment"}
+	}
+	return nil
+}
+
 func (s *SetupService) currentSettingValue(key string, fallback string) string {
 	value := strings.TrimSpace(fallback)
 
@@ -1004,6 +1176,9 @@ func (s *SetupService) UpdateEmailSettings(req models.UpdateEmailSettingsRequest
 		return &ValidationError{Field: "username", Message: "is required"}
 	}
 
+	if err := checkCredentialInput("password", req.Password); err != nil {
+		return err
+	}
 	resolvedPassword, updatePassword := normalizeCredentialInput(req.Password, currentPassword)
 	trimmedPassword := strings.TrimSpace(resolvedPassword)
 	passwordSet := trimmedPassword != ""
@@ -1120,6 +1295,9 @@ func (s *SetupService) TestEmailSettings(req models.UpdateEmailSettingsRequest,
 		return nil, &ValidationError{Field: "username", Message: "is required"}
 	}
 
+	if err := checkCredentialInput("password", req.Password); err != nil {
+		return nil, err
+	}
 	resolvedPassword, _ := normalizeCredentialInput(req.Password, currentPassword)
 	trimmedPassword := strings.TrimSpace(resolvedPassword)
 	if trimmedPassword == "" {
@@ -1363,6 +1541,9 @@ const (
 	settingKeySiteLogo                 = "site.logo"
 	settingKeySiteContactEmail         = "site.contact_email"
 	settingKeySiteFooterText           = "site.footer_text"
+	settingKeySiteColorScheme          = "site.color_scheme"
+	settingKeySiteColorSchemeToggle    = "site.color_scheme_toggle"
+	settingKeySiteTranslations         = "site.translations"
 	settingKeyTagRetentionHours        = blogservice.SettingKeyTagRetentionHours
 	settingKeySiteDefaultLanguage      = "site.default_language"
 	settingKeySiteSupportedLanguages   = "site.supported_languages"
@@ -1387,6 +1568,19 @@ const (
 	settingKeySMTPFrom                 = "smtp.from"
 )
 
+// SecretSettingKeys lists the settings that hold credentials. Each may store a
+// reference to a secret kept elsewhere instead of the secret itself. The
+// Stripe publishable key is not a secret and is shown unmasked, so it is not
+// one of them.
+func SecretSettingKeys() []string {
+	return []string{
+		settingKeyStripeSecretKey,
+		settingKeyStripeWebhookSecret,
+		settingKeySubtitlesOpenAIAPIKey,
+		settingKeySMTPPassword,
+	}
+}
+
 // GetSetupProgress retrieves the current setup progress
 func (s *SetupService) GetSetupProgress() (*models.SetupProgress, error) {
 	if s.db == nil {
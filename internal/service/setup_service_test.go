@@ -0,0 +1,46 @@
+package service
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/theme"
+)
+
+func TestUpdateSiteSettingsColorScheme(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "sepia")
+	for _, sub := range []string{"templates", "static"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	metadata := `{"name":"Sepia","color_schemes":{"light":{"label":"Light"},"sepia":{"label":"Sepia"}}}`
+	if err := os.WriteFile(filepath.Join(dir, "theme.json"), []byte(metadata), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	manager, err := theme.NewManager(filepath.Dir(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.Activate("sepia"); err != nil {
+		t.Fatal(err)
+	}
+
+	settings := &memorySettingRepository{values: make(map[string]string)}
+	svc := NewSetupService(nil, settings, nil, nil)
+	svc.SetThemeManager(manager)
+
+	var validationErr *ValidationError
+	for _, scheme := range []string{"dark", "system"} {
+		err := svc.UpdateSiteSettings(models.UpdateSiteSettingsRequest{Name: "Site", ColorScheme: scheme}, models.SiteSettings{})
+		if !errors.As(err, &validationErr) || validationErr.Field != "color_scheme" {
+			t.Fatalf("expected %q to be refused for a theme without it, got %v", scheme, err)
+		}
+	}
+	if _, ok := settings.values[settingKeySiteColorScheme]; ok {
+		t.Fatal("expected a refused scheme not to be saved")
+	}
+}
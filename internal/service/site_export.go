@@ -0,0 +1,232 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"constructor-script-backend/internal/models"
+)
+
+const (
+	siteExportFormat = "1"
+	siteExportKind   = "site-export"
+	siteExportEntry  = "export.json"
+)
+
+var ErrInvalidSiteExport = errors.New("invalid site export bundle")
+
+// siteExportExcludedSettings lists settings that belong to one instance, such as
+// credentials and setup state, and are never exported. Entries ending in a dot
+// are prefixes.
+var siteExportExcludedSettings = []string{
+	"payments.",
+	"smtp.",
+	"security.",
+	"setup.",
+	SettingKeyBackupAuto,
+	settingKeyThemeInitializedBase,
+	"site.url",
+	SettingKeyHeadless,
+}
+
+// siteExport is the export.json of a site export: the content, settings, theme
+// customizations and plugin data of a site, keyed by the ids of the source
+// instance, which the importer maps to new ids.
+type siteExport struct {
+	Format      string    `json:"format"`
+	Kind        string    `json:"kind"`
+	ExportedAt  time.Time `json:"exported_at"`
+	Application string    `json:"application"`
+	// Authors identifies the source users by username and email only; accounts
+	// and passwords are not exported.
+	Authors        []siteExportAuthor   `json:"authors"`
+	Data           backupData           `json:"data"`
+	ThemeOverrides []siteExportTemplate `json:"theme_overrides"`
+	Uploads        []siteExportUpload   `json:"uploads"`
+}
+
+type siteExportAuthor struct {
+	ID       uint   `json:"id"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+}
+
+type siteExportTemplate struct {
+	Theme   string `json:"theme"`
+	Name    string `json:"name"`
+	Content string `json:"content"`
+}
+
+// siteExportUpload lists an upload stored under uploads/ in the bundle.
+type siteExportUpload struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+func exportableSetting(key string) bool {
+	for _, excluded := range siteExportExcludedSettings {
+		if key == excluded || (strings.HasSuffix(excluded, ".") && strings.HasPrefix(key, excluded)) {
+			return false
+		}
+	}
+	return true
+}
+
+// ExportSite builds a portable bundle of the site for ImportSite on another
+// instance. Unlike backups it leaves out user accounts and instance settings, and
+// it is never encrypted, since the target does not share the encryption key.
+func (s *BackupService) ExportSite(ctx context.Context) (*BackupArchive, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("backup service not configured")
+	}
+
+	data, err := s.snapshotData(ctx)
+	if err != nil {
+		return nil, err
+	}
+	export := siteExport{
+		Format:      siteExportFormat,
+		Kind:        siteExportKind,
+		ExportedAt:  time.Now().UTC(),
+		Application: s.appName,
+	}
+	for _, user := range data.Users {
+		export.Authors = append(export.Authors, siteExportAuthor{ID: user.ID, Username: user.Username, Email: user.Email})
+	}
+	data.Users = nil
+	settings := data.Settings[:0]
+	for _, setting := range data.Settings {
+		if exportableSetting(setting.Key) {
+			settings = append(settings, setting)
+		}
+	}
+	data.Settings = settings
+	export.Data = data
+
+	var overrides []models.ThemeTemplateOverride
+	if err := s.db.WithContext(ctx).Order("theme ASC, name ASC").Find(&overrides).Error; err != nil {
+		return nil, fmt.Errorf("failed to load theme overrides: %w", err)
+	}
+	for _, override := range overrides {
+		export.ThemeOverrides = append(export.ThemeOverrides, siteExportTemplate{Theme: override.Theme, Name: override.Name, Content: override.Content})
+	}
+
+	uploads, err := s.listUploads()
+	if err != nil {
+		return nil, err
+	}
+
+	tempFile, err := os.CreateTemp("", "constructor-site-export-*.zip")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary export: %w", err)
+	}
+	fail := func(err error) (*BackupArchive, error) {
+		tempFile.Close()
+		os.Remove(tempFile.Name())
+		return nil, err
+	}
+
+	writer := zip.NewWriter(tempFile)
+	if export.Uploads, err = s.writeSiteUploads(writer, uploads); err != nil {
+		writer.Close()
+		return fail(err)
+	}
+
+	header := &zip.FileHeader{Name: siteExportEntry, Method: zip.Deflate}
+	header.SetModTime(export.ExportedAt)
+	entry, err := writer.CreateHeader(header)
+	if err != nil {
+		writer.Close()
+		return fail(fmt.Errorf("failed to create export entry: %w", err))
+	}
+	encoder := json.NewEncoder(entry)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(export); err != nil {
+		writer.Close()
+		return fail(fmt.Errorf("failed to encode site export: %w", err))
+	}
+
+	if err := writer.Close(); err != nil {
+		return fail(fmt.Errorf("failed to finalise site export: %w", err))
+	}
+	if _, err := tempFile.Seek(0, io.SeekStart); err != nil {
+		return fail(fmt.Errorf("failed to rewind site export: %w", err))
+	}
+
+	return &BackupArchive{
+		file:        tempFile,
+		Filename:    fmt.Sprintf("site-export-%s.zip", export.ExportedAt.Format("20060102-150405")),
+		ContentType: "application/zip",
+		Summary: BackupSummary{
+			SchemaVersion: export.Format,
+			GeneratedAt:   export.ExportedAt,
+			Application:   export.Application,
+			Users:         len(export.Authors),
+			Categories:    len(data.Categories),
+			Tags:          len(data.Tags),
+			Posts:         len(data.Posts),
+			Pages:         len(data.Pages),
+			Comments:      len(data.Comments),
+			Settings:      len(data.Settings),
+			MenuItems:     len(data.MenuItems),
+			SocialLinks:   len(data.SocialLinks),
+			PostTags:      len(data.PostTags),
+			Uploads:       len(export.Uploads),
+			PluginRows:    countPluginRows(data.Plugins),
+		},
+	}, nil
+}
+
+// writeSiteUploads stores the uploads under uploads/ and returns their manifest.
+func (s *BackupService) writeSiteUploads(writer *zip.Writer, uploads []string) ([]siteExportUpload, error) {
+	manifest := make([]siteExportUpload, 0, len(uploads))
+	for _, rel := range uploads {
+		absPath := filepath.Join(s.uploadDir, filepath.FromSlash(rel))
+		file, err := os.Open(absPath)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to open upload file: %w", err)
+		}
+
+		info, err := file.Stat()
+		if err != nil || !info.Mode().IsRegular() {
+			file.Close()
+			continue
+		}
+		header, err := zip.FileInfoHeader(info)
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to prepare archive header: %w", err)
+		}
+		header.Name = path.Join("uploads", rel)
+		header.Method = zip.Deflate
+		entry, err := writer.CreateHeader(header)
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to create archive entry for upload: %w", err)
+		}
+
+		hasher := sha256.New()
+		size, err := io.Copy(io.MultiWriter(entry, hasher), file)
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to write upload file to archive: %w", err)
+		}
+		manifest = append(manifest, siteExportUpload{Path: rel, Size: size, SHA256: hex.EncodeToString(hasher.Sum(nil))})
+	}
+	return manifest, nil
+}
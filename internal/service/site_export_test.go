@@ -0,0 +1,171 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExportableSetting(t *testing.T) {
+	cases := map[string]bool{
+		"site.name":                    true,
+		"site.theme":                   true,
+		"site.url":                     false,
+		"smtp.password":                false,
+		"payments.stripe.secret_key":   false,
+		"site.backup.auto":             false,
+		"site.theme.initialized.blank": false,
+		"security.rate_limits":         false,
+	}
+	for key, want := range cases {
+		if got := exportableSetting(key); got != want {
+			t.Errorf("exportableSetting(%q) = %v, want %v", key, got, want)
+		}
+	}
+}
+
+func newTestImporter(options SiteImportOptions) *siteImporter {
+	return &siteImporter{
+		options: options,
+		report:  &SiteImportReport{Imported: map[string]int{}, Reused: map[string]int{}, Skipped: map[string]int{}},
+		authors: map[uint]uint{},
+		pages:   map[uint]uint{},
+	}
+}
+
+func TestSiteImportMapsHomepageSettings(t *testing.T) {
+	importer := newTestImporter(SiteImportOptions{})
+	importer.pages[3] = 40
+	importer.pages[4] = 41
+
+	if value, ok := importer.settingValue(settingKeySiteHomepage, "3"); !ok || value != "40" {
+		t.Fatalf("unexpected homepage mapping %q, %v", value, ok)
+	}
+	if _, ok := importer.settingValue(settingKeySiteHomepage, "9"); ok {
+		t.Fatal("expected a homepage that was not imported to be skipped")
+	}
+
+	value, ok := importer.settingValue(settingKeySiteLanguageHomepages, `{"en":3,"de":4,"fr":9}`)
+	if !ok {
+		t.Fatal("expected the language homepages to be kept")
+	}
+	var selections map[string]uint
+	if err := json.Unmarshal([]byte(value), &selections); err != nil {
+		t.Fatal(err)
+	}
+	if len(selections) != 2 || selections["en"] != 40 || selections["de"] != 41 {
+		t.Fatalf("unexpected language homepages %v", selections)
+	}
+}
+
+func TestSiteImportMapsPluginUsers(t *testing.T) {
+	importer := newTestImporter(SiteImportOptions{FallbackAuthorID: 1})
+	importer.authors[7] = 70
+
+	table := backupTable{
+		Table: "forum_answers",
+		Count: 3,
+		Rows:  json.RawMessage(`[{"id":1,"author_id":7,"user_id":7},{"id":2,"author_id":8},{"id":3,"user_id":8}]`),
+	}
+	mapped, err := importer.mapPluginRows(table)
+	if err != nil {
+		t.Fatalf("mapPluginRows: %v", err)
+	}
+
+	var rows []map[string]int
+	if err := json.Unmarshal(mapped.Rows, &rows); err != nil {
+		t.Fatal(err)
+	}
+	if mapped.Count != 2 || len(rows) != 2 {
+		t.Fatalf("expected the row of an unknown user to be dropped, got %s", mapped.Rows)
+	}
+	if rows[0]["author_id"] != 70 || rows[0]["user_id"] != 70 || rows[1]["author_id"] != 1 {
+		t.Fatalf("unexpected mapped rows %v", rows)
+	}
+	if importer.report.Skipped["plugin_rows"] != 1 {
+		t.Fatalf("expected one skipped row, got %v", importer.report.Skipped)
+	}
+}
+
+func TestSiteExportUploadsRoundTrip(t *testing.T) {
+	source := NewBackupService(nil, nil, BackupOptions{UploadDir: t.TempDir()})
+	for name, content := range map[string]string{"a.png": "alpha", "img/b.png": "beta", "img/c.png": "gamma"} {
+		target := filepath.Join(source.uploadDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(target, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	uploads, err := source.listUploads()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	manifest, err := source.writeSiteUploads(writer, uploads)
+	if err != nil {
+		t.Fatalf("writeSiteUploads: %v", err)
+	}
+	entry, _ := writer.Create(siteExportEntry)
+	_ = json.NewEncoder(entry).Encode(siteExport{Format: siteExportFormat, Kind: siteExportKind, Uploads: manifest})
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	export, err := loadSiteExport(reader)
+	if err != nil {
+		t.Fatalf("loadSiteExport: %v", err)
+	}
+
+	target := NewBackupService(nil, nil, BackupOptions{UploadDir: t.TempDir()})
+	if err := os.MkdirAll(filepath.Join(target.uploadDir, "img"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	_ = os.WriteFile(filepath.Join(target.uploadDir, "img", "b.png"), []byte("beta"), 0o644)
+	_ = os.WriteFile(filepath.Join(target.uploadDir, "img", "c.png"), []byte("other"), 0o644)
+
+	report := SiteImportReport{Imported: map[string]int{}, Reused: map[string]int{}, Skipped: map[string]int{}}
+	if err := target.importSiteUploads(reader, export.Uploads, &report); err != nil {
+		t.Fatalf("importSiteUploads: %v", err)
+	}
+	if report.Imported["uploads"] != 1 || report.Reused["uploads"] != 1 {
+		t.Fatalf("unexpected upload report %+v", report)
+	}
+	if len(report.UploadConflicts) != 1 || report.UploadConflicts[0] != "img/c.png" {
+		t.Fatalf("expected img/c.png to conflict, got %v", report.UploadConflicts)
+	}
+	if data, _ := os.ReadFile(filepath.Join(target.uploadDir, "img", "c.png")); string(data) != "other" {
+		t.Fatalf("expected the existing upload to be kept, got %q", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(target.uploadDir, "a.png")); string(data) != "alpha" {
+		t.Fatalf("expected a.png to be imported, got %q", data)
+	}
+
+	bad := []siteExportUpload{{Path: "../escape.png"}}
+	if err := target.importSiteUploads(reader, bad, &report); !errors.Is(err, ErrInvalidSiteExport) {
+		t.Fatalf("expected an invalid path to be rejected, got %v", err)
+	}
+}
+
+func TestLoadSiteExportRejectsBackups(t *testing.T) {
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	entry, _ := writer.Create("manifest.json")
+	_, _ = entry.Write([]byte(`{"schema_version":"1"}`))
+	_ = writer.Close()
+
+	reader, _ := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if _, err := loadSiteExport(reader); !errors.Is(err, ErrInvalidSiteExport) {
+		t.Fatalf("expected a backup archive to be rejected, got %v", err)
+	}
+}
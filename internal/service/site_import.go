@@ -0,0 +1,759 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// SiteImportOptions controls how ImportSite merges a bundle into this site.
+type SiteImportOptions struct {
+	// FallbackAuthorID owns the imported posts and comments whose author has no
+	// account here, matched by email or username.
+	FallbackAuthorID uint
+	// OverwriteSettings replaces settings and theme overrides that exist here;
+	// otherwise they are kept and only missing ones are added.
+	OverwriteSettings bool
+}
+
+// SiteImportRename records a slug or path changed to avoid one already in use.
+type SiteImportRename struct {
+	Kind string `json:"kind"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// SiteImportReport describes what ImportSite added, reused and left out.
+type SiteImportReport struct {
+	ExportedAt  time.Time `json:"exported_at"`
+	ImportedAt  time.Time `json:"imported_at"`
+	Application string    `json:"application"`
+	// Imported counts the records created, by kind.
+	Imported map[string]int `json:"imported"`
+	// Reused counts the records matched to existing ones, such as categories with
+	// the same slug, and Skipped those left out, such as existing settings.
+	Reused  map[string]int     `json:"reused"`
+	Skipped map[string]int     `json:"skipped"`
+	Renamed []SiteImportRename `json:"renamed,omitempty"`
+	// UnmatchedAuthors lists the source authors given to FallbackAuthorID.
+	UnmatchedAuthors []string `json:"unmatched_authors,omitempty"`
+	// SkippedPlugins lists plugins whose tables already hold data here.
+	SkippedPlugins []string `json:"skipped_plugins,omitempty"`
+	// UploadConflicts lists uploads kept because a different file has their path.
+	UploadConflicts []string `json:"upload_conflicts,omitempty"`
+}
+
+type siteImporter struct {
+	tx      *gorm.DB
+	options SiteImportOptions
+	report  *SiteImportReport
+
+	authors    map[uint]uint
+	categories map[uint]uint
+	tags       map[uint]uint
+	posts      map[uint]uint
+	pages      map[uint]uint
+	comments   map[uint]uint
+}
+
+// ImportSite merges a bundle made by ExportSite into this site without removing
+// anything. Records get new ids and references are mapped to them; categories
+// and tags are reused by slug, authors are matched by email or username, and
+// posts and pages whose slug or path is taken are renamed.
+func (s *BackupService) ImportSite(ctx context.Context, reader io.Reader, options SiteImportOptions) (SiteImportReport, error) {
+	report := SiteImportReport{
+		Imported: make(map[string]int),
+		Reused:   make(map[string]int),
+		Skipped:  make(map[string]int),
+	}
+	if s == nil || s.db == nil {
+		return report, fmt.Errorf("backup service not configured")
+	}
+
+	spoolFile, err := os.CreateTemp("", "constructor-site-import-*.zip")
+	if err != nil {
+		return report, fmt.Errorf("failed to prepare temporary bundle: %w", err)
+	}
+	defer func() {
+		spoolFile.Close()
+		os.Remove(spoolFile.Name())
+	}()
+	size, err := io.Copy(spoolFile, reader)
+	if err != nil {
+		return report, fmt.Errorf("failed to read site export: %w", err)
+	}
+	zipReader, err := zip.NewReader(spoolFile, size)
+	if err != nil {
+		return report, fmt.Errorf("%w: %v", ErrInvalidSiteExport, err)
+	}
+
+	export, err := loadSiteExport(zipReader)
+	if err != nil {
+		return report, err
+	}
+	report.ExportedAt = export.ExportedAt
+	report.Application = export.Application
+
+	tx := s.db.WithContext(ctx).Begin()
+	if err := tx.Error; err != nil {
+		return report, fmt.Errorf("failed to start transaction: %w", err)
+	}
+
+	pluginTables, pluginRows, err := s.restorablePluginTables(tx, export.Data.Plugins)
+	if err != nil {
+		tx.Rollback()
+		return report, err
+	}
+
+	importer := &siteImporter{
+		tx:         tx,
+		options:    options,
+		report:     &report,
+		authors:    make(map[uint]uint),
+		categories: make(map[uint]uint),
+		tags:       make(map[uint]uint),
+		posts:      make(map[uint]uint),
+		pages:      make(map[uint]uint),
+		comments:   make(map[uint]uint),
+	}
+	steps := []func(*siteExport) error{
+		importer.importAuthors,
+		importer.importCategories,
+		importer.importTags,
+		importer.importPosts,
+		importer.importPages,
+		importer.importComments,
+		importer.importMenu,
+		importer.importSettings,
+		importer.importThemeOverrides,
+	}
+	for _, step := range steps {
+		if err := step(export); err != nil {
+			tx.Rollback()
+			return report, err
+		}
+	}
+	if err := importer.importPlugins(s, pluginTables, pluginRows); err != nil {
+		tx.Rollback()
+		return report, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return report, fmt.Errorf("failed to commit imported data: %w", err)
+	}
+
+	if err := s.importSiteUploads(zipReader, export.Uploads, &report); err != nil {
+		return report, err
+	}
+
+	report.ImportedAt = time.Now().UTC()
+	logger.Info("Site export imported", map[string]interface{}{
+		"imported": report.Imported,
+		"reused":   report.Reused,
+		"skipped":  report.Skipped,
+		"renamed":  len(report.Renamed),
+	})
+	return report, nil
+}
+
+func loadSiteExport(reader *zip.Reader) (*siteExport, error) {
+	for _, file := range reader.File {
+		if file.Name != siteExportEntry {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to open site export: %w", err)
+		}
+		defer rc.Close()
+
+		var export siteExport
+		if err := json.NewDecoder(rc).Decode(&export); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidSiteExport, err)
+		}
+		if export.Kind != siteExportKind {
+			return nil, ErrInvalidSiteExport
+		}
+		if export.Format != siteExportFormat {
+			return nil, ErrBackupVersion
+		}
+		return &export, nil
+	}
+	return nil, fmt.Errorf("%w: %s is missing", ErrInvalidSiteExport, siteExportEntry)
+}
+
+// uniqueValue returns value, or value with the first free "-n" suffix when column
+// already holds it. Soft-deleted rows count, as unique indexes cover them.
+func (im *siteImporter) uniqueValue(model interface{}, column, value string) (string, error) {
+	candidate := value
+	for n := 2; ; n++ {
+		var count int64
+		if err := im.tx.Unscoped().Model(model).Where(column+" = ?", candidate).Count(&count).Error; err != nil {
+			return "", fmt.Errorf("failed to check %s: %w", column, err)
+		}
+		if count == 0 {
+			return candidate, nil
+		}
+		candidate = value + "-" + strconv.Itoa(n)
+	}
+}
+
+func (im *siteImporter) renamed(kind, from, to string) {
+	if from != to {
+		im.report.Renamed = append(im.report.Renamed, SiteImportRename{Kind: kind, From: from, To: to})
+	}
+}
+
+func (im *siteImporter) importAuthors(export *siteExport) error {
+	for _, author := range export.Authors {
+		var user models.User
+		err := im.tx.Where("LOWER(email) = ?", strings.ToLower(author.Email)).First(&user).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			err = im.tx.Where("username = ?", author.Username).First(&user).Error
+		}
+		switch {
+		case err == nil:
+			im.authors[author.ID] = user.ID
+			im.report.Reused["authors"]++
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			im.report.UnmatchedAuthors = append(im.report.UnmatchedAuthors, author.Username)
+		default:
+			return fmt.Errorf("failed to match author %s: %w", author.Username, err)
+		}
+	}
+	return nil
+}
+
+// author maps a source author, falling back to FallbackAuthorID.
+func (im *siteImporter) author(id uint) (uint, error) {
+	if mapped, ok := im.authors[id]; ok {
+		return mapped, nil
+	}
+	if im.options.FallbackAuthorID == 0 {
+		return 0, fmt.Errorf("%w: author %d has no account and no fallback author is set", ErrInvalidSiteExport, id)
+	}
+	return im.options.FallbackAuthorID, nil
+}
+
+func (im *siteImporter) importCategories(export *siteExport) error {
+	for _, item := range export.Data.Categories {
+		var existing models.Category
+		err := im.tx.Where("slug = ?", item.Slug).First(&existing).Error
+		if err == nil {
+			im.categories[item.ID] = existing.ID
+			im.report.Reused["categories"]++
+			continue
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("failed to match category %s: %w", item.Slug, err)
+		}
+
+		category := models.Category{
+			CreatedAt:   item.CreatedAt,
+			UpdatedAt:   item.UpdatedAt,
+			Description: item.Description,
+			Order:       item.Order,
+		}
+		if category.Slug, err = im.uniqueValue(&models.Category{}, "slug", item.Slug); err != nil {
+			return err
+		}
+		if category.Name, err = im.uniqueValue(&models.Category{}, "name", item.Name); err != nil {
+			return err
+		}
+		if err := im.tx.Create(&category).Error; err != nil {
+			return fmt.Errorf("failed to import category %s: %w", item.Slug, err)
+		}
+		im.renamed("category", item.Slug, category.Slug)
+		im.categories[item.ID] = category.ID
+		im.report.Imported["categories"]++
+	}
+	return nil
+}
+
+func (im *siteImporter) importTags(export *siteExport) error {
+	for _, item := range export.Data.Tags {
+		var existing models.Tag
+		err := im.tx.Where("slug = ?", item.Slug).First(&existing).Error
+		if err == nil {
+			im.tags[item.ID] = existing.ID
+			im.report.Reused["tags"]++
+			continue
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("failed to match tag %s: %w", item.Slug, err)
+		}
+
+		tag := models.Tag{
+			CreatedAt:   item.CreatedAt,
+			UpdatedAt:   item.UpdatedAt,
+			UnusedSince: normalizeTimePtr(item.UnusedSince),
+		}
+		if tag.Slug, err = im.uniqueValue(&models.Tag{}, "slug", item.Slug); err != nil {
+			return err
+		}
+		if tag.Name, err = im.uniqueValue(&models.Tag{}, "name", item.Name); err != nil {
+			return err
+		}
+		if err := im.tx.Create(&tag).Error; err != nil {
+			return fmt.Errorf("failed to import tag %s: %w", item.Slug, err)
+		}
+		im.renamed("tag", item.Slug, tag.Slug)
+		im.tags[item.ID] = tag.ID
+		im.report.Imported["tags"]++
+	}
+	return nil
+}
+
+func (im *siteImporter) importPosts(export *siteExport) error {
+	for _, item := range export.Data.Posts {
+		authorID, err := im.author(item.AuthorID)
+		if err != nil {
+			return err
+		}
+		categoryID, ok := im.categories[item.CategoryID]
+		if !ok {
+			return fmt.Errorf("%w: post %s refers to a missing category", ErrInvalidSiteExport, item.Slug)
+		}
+		slug, err := im.uniqueValue(&models.Post{}, "slug", item.Slug)
+		if err != nil {
+			return err
+		}
+
+		post := models.Post{
+			CreatedAt:   item.CreatedAt,
+			UpdatedAt:   item.UpdatedAt,
+			Title:       item.Title,
+			Slug:        slug,
+			Description: item.Description,
+			Content:     item.Content,
+			Excerpt:     item.Excerpt,
+			FeaturedImg: item.FeaturedImg,
+			Published:   item.Published,
+			PublishAt:   normalizeTimePtr(item.PublishAt),
+			PublishedAt: normalizeTimePtr(item.PublishedAt),
+			Views:       item.Views,
+			Sections:    item.Sections,
+			Template:    item.Template,
+			AuthorID:    authorID,
+			CategoryID:  categoryID,
+		}
+		if err := im.tx.Create(&post).Error; err != nil {
+			return fmt.Errorf("failed to import post %s: %w", item.Slug, err)
+		}
+		im.renamed("post", item.Slug, slug)
+		im.posts[item.ID] = post.ID
+		im.report.Imported["posts"]++
+	}
+
+	for _, item := range export.Data.PostTags {
+		postID, postOK := im.posts[item.PostID]
+		tagID, tagOK := im.tags[item.TagID]
+		if !postOK || !tagOK {
+			continue
+		}
+		if err := im.tx.Table("post_tags").Create(&postTagRow{PostID: postID, TagID: tagID}).Error; err != nil {
+			return fmt.Errorf("failed to import post tags: %w", err)
+		}
+		im.report.Imported["post_tags"]++
+	}
+	return nil
+}
+
+func (im *siteImporter) importPages(export *siteExport) error {
+	for _, item := range export.Data.Pages {
+		slug, err := im.uniqueValue(&models.Page{}, "slug", item.Slug)
+		if err != nil {
+			return err
+		}
+		pagePath, err := im.uniqueValue(&models.Page{}, "path", item.Path)
+		if err != nil {
+			return err
+		}
+
+		page := models.Page{
+			CreatedAt:   item.CreatedAt,
+			UpdatedAt:   item.UpdatedAt,
+			Title:       item.Title,
+			Slug:        slug,
+			Path:        pagePath,
+			Description: item.Description,
+			FeaturedImg: item.FeaturedImg,
+			Published:   item.Published,
+			PublishAt:   normalizeTimePtr(item.PublishAt),
+			PublishedAt: normalizeTimePtr(item.PublishedAt),
+			Content:     item.Content,
+			Sections:    item.Sections,
+			Template:    item.Template,
+			HideHeader:  item.HideHeader,
+			Order:       item.Order,
+		}
+		if err := im.tx.Create(&page).Error; err != nil {
+			return fmt.Errorf("failed to import page %s: %w", item.Slug, err)
+		}
+		im.renamed("page", item.Slug, slug)
+		im.renamed("page path", item.Path, pagePath)
+		im.pages[item.ID] = page.ID
+		im.report.Imported["pages"]++
+	}
+	return nil
+}
+
+// importComments keeps the source order, in which replies follow their parent.
+func (im *siteImporter) importComments(export *siteExport) error {
+	for _, item := range export.Data.Comments {
+		postID, ok := im.posts[item.PostID]
+		if !ok {
+			im.report.Skipped["comments"]++
+			continue
+		}
+		authorID, err := im.author(item.AuthorID)
+		if err != nil {
+			return err
+		}
+
+		comment := models.Comment{
+			CreatedAt: item.CreatedAt,
+			UpdatedAt: item.UpdatedAt,
+			Content:   item.Content,
+			Approved:  item.Approved,
+			PostID:    postID,
+			AuthorID:  authorID,
+		}
+		if item.ParentID != nil {
+			if parentID, ok := im.comments[*item.ParentID]; ok {
+				comment.ParentID = &parentID
+			}
+		}
+		if err := im.tx.Create(&comment).Error; err != nil {
+			return fmt.Errorf("failed to import comment: %w", err)
+		}
+		im.comments[item.ID] = comment.ID
+		im.report.Imported["comments"]++
+	}
+	return nil
+}
+
+// importMenu adds the menu items and social links whose URL is not linked yet.
+func (im *siteImporter) importMenu(export *siteExport) error {
+	for _, item := range export.Data.MenuItems {
+		var count int64
+		if err := im.tx.Model(&models.MenuItem{}).Where("location = ? AND url = ?", item.Location, item.URL).Count(&count).Error; err != nil {
+			return fmt.Errorf("failed to match menu item: %w", err)
+		}
+		if count > 0 {
+			im.report.Skipped["menu_items"]++
+			continue
+		}
+		menuItem := models.MenuItem{
+			CreatedAt: item.CreatedAt,
+			UpdatedAt: item.UpdatedAt,
+			Title:     item.Title,
+			Label:     item.Label,
+			URL:       item.URL,
+			Location:  item.Location,
+			Order:     item.Order,
+		}
+		if err := im.tx.Create(&menuItem).Error; err != nil {
+			return fmt.Errorf("failed to import menu item: %w", err)
+		}
+		im.report.Imported["menu_items"]++
+	}
+
+	for _, item := range export.Data.SocialLinks {
+		var count int64
+		if err := im.tx.Model(&models.SocialLink{}).Where("url = ?", item.URL).Count(&count).Error; err != nil {
+			return fmt.Errorf("failed to match social link: %w", err)
+		}
+		if count > 0 {
+			im.report.Skipped["social_links"]++
+			continue
+		}
+		link := models.SocialLink{
+			CreatedAt: item.CreatedAt,
+			UpdatedAt: item.UpdatedAt,
+			Name:      item.Name,
+			URL:       item.URL,
+			Order:     item.Order,
+		}
+		if err := im.tx.Create(&link).Error; err != nil {
+			return fmt.Errorf("failed to import social link: %w", err)
+		}
+		im.report.Imported["social_links"]++
+	}
+	return nil
+}
+
+func (im *siteImporter) importSettings(export *siteExport) error {
+	for _, item := range export.Data.Settings {
+		if !exportableSetting(item.Key) {
+			continue
+		}
+		value, ok := im.settingValue(item.Key, item.Value)
+		if !ok {
+			im.report.Skipped["settings"]++
+			continue
+		}
+
+		var existing models.Setting
+		err := im.tx.Where("key = ?", item.Key).First(&existing).Error
+		switch {
+		case err == nil && !im.options.OverwriteSettings:
+			im.report.Skipped["settings"]++
+			continue
+		case err == nil:
+			err = im.tx.Model(&existing).Update("value", value).Error
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			err = im.tx.Create(&models.Setting{Key: item.Key, Value: value}).Error
+		default:
+			return fmt.Errorf("failed to match setting %s: %w", item.Key, err)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to import setting %s: %w", item.Key, err)
+		}
+		im.report.Imported["settings"]++
+	}
+	return nil
+}
+
+// settingValue maps the page ids held by the homepage settings. It reports false
+// when a page they name was not imported.
+func (im *siteImporter) settingValue(key, value string) (string, bool) {
+	switch key {
+	case settingKeySiteHomepage:
+		id, err := strconv.ParseUint(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			return "", false
+		}
+		mapped, ok := im.pages[uint(id)]
+		return strconv.FormatUint(uint64(mapped), 10), ok
+	case settingKeySiteLanguageHomepages:
+		var selections map[string]uint
+		if err := json.Unmarshal([]byte(value), &selections); err != nil {
+			return "", false
+		}
+		for language, id := range selections {
+			mapped, ok := im.pages[id]
+			if !ok {
+				delete(selections, language)
+				continue
+			}
+			selections[language] = mapped
+		}
+		encoded, err := json.Marshal(selections)
+		return string(encoded), err == nil && len(selections) > 0
+	default:
+		return value, true
+	}
+}
+
+func (im *siteImporter) importThemeOverrides(export *siteExport) error {
+	for _, item := range export.ThemeOverrides {
+		var existing models.ThemeTemplateOverride
+		err := im.tx.Where("theme = ? AND name = ?", item.Theme, item.Name).First(&existing).Error
+		switch {
+		case err == nil && !im.options.OverwriteSettings:
+			im.report.Skipped["theme_overrides"]++
+			continue
+		case err == nil:
+			existing.Content = item.Content
+			if err := im.tx.Save(&existing).Error; err != nil {
+				return fmt.Errorf("failed to import theme override %s/%s: %w", item.Theme, item.Name, err)
+			}
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			override := models.ThemeTemplateOverride{Theme: item.Theme, Name: item.Name, Content: item.Content}
+			if err := im.tx.Create(&override).Error; err != nil {
+				return fmt.Errorf("failed to import theme override %s/%s: %w", item.Theme, item.Name, err)
+			}
+		default:
+			return fmt.Errorf("failed to match theme override %s/%s: %w", item.Theme, item.Name, err)
+		}
+		im.report.Imported["theme_overrides"]++
+	}
+	return nil
+}
+
+// importPlugins copies the data of each plugin whose tables are all empty here,
+// keeping its own ids. author_id columns are mapped like post authors; rows
+// whose user_id has no account here, such as votes, are dropped.
+func (im *siteImporter) importPlugins(s *BackupService, tables []pluginTable, rows map[string]backupTable) error {
+	byPlugin := make(map[string][]pluginTable)
+	var order []string
+	for _, table := range tables {
+		if _, ok := byPlugin[table.plugin]; !ok {
+			order = append(order, table.plugin)
+		}
+		byPlugin[table.plugin] = append(byPlugin[table.plugin], table)
+	}
+
+	for _, plugin := range order {
+		empty := true
+		for _, table := range byPlugin[plugin] {
+			var count int64
+			if err := im.tx.Table(table.schema.Table).Count(&count).Error; err != nil {
+				return fmt.Errorf("failed to inspect %s: %w", table.schema.Table, err)
+			}
+			if count > 0 {
+				empty = false
+				break
+			}
+		}
+		if !empty {
+			im.report.SkippedPlugins = append(im.report.SkippedPlugins, plugin)
+			continue
+		}
+
+		mapped := make(map[string]backupTable, len(byPlugin[plugin]))
+		for _, table := range byPlugin[plugin] {
+			data, err := im.mapPluginRows(rows[table.schema.Table])
+			if err != nil {
+				return err
+			}
+			mapped[table.schema.Table] = data
+			im.report.Imported["plugin_rows"] += data.Count
+		}
+		if err := s.restorePluginData(im.tx, byPlugin[plugin], mapped); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (im *siteImporter) mapPluginRows(table backupTable) (backupTable, error) {
+	if table.Count == 0 || len(table.Rows) == 0 {
+		return table, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(table.Rows))
+	decoder.UseNumber()
+	var rows []map[string]interface{}
+	if err := decoder.Decode(&rows); err != nil {
+		return table, fmt.Errorf("%w: %s rows: %v", ErrInvalidSiteExport, table.Table, err)
+	}
+
+	kept := rows[:0]
+	for _, row := range rows {
+		keep := true
+		for column, value := range row {
+			if column != "author_id" && column != "user_id" {
+				continue
+			}
+			number, ok := value.(json.Number)
+			if !ok {
+				continue
+			}
+			id, err := strconv.ParseUint(number.String(), 10, 64)
+			if err != nil {
+				continue
+			}
+			if mapped, ok := im.authors[uint(id)]; ok {
+				row[column] = mapped
+			} else if column == "author_id" && im.options.FallbackAuthorID != 0 {
+				row[column] = im.options.FallbackAuthorID
+			} else {
+				keep = false
+			}
+		}
+		if keep {
+			kept = append(kept, row)
+		} else {
+			im.report.Skipped["plugin_rows"]++
+		}
+	}
+
+	encoded, err := json.Marshal(kept)
+	if err != nil {
+		return table, fmt.Errorf("failed to encode %s rows: %w", table.Table, err)
+	}
+	return backupTable{Table: table.Table, Count: len(kept), Rows: encoded}, nil
+}
+
+// importSiteUploads writes the uploads missing here. An upload whose path holds
+// a different file is left as it is and reported.
+func (s *BackupService) importSiteUploads(reader *zip.Reader, uploads []siteExportUpload, report *SiteImportReport) error {
+	entries := make(map[string]*zip.File, len(reader.File))
+	for _, file := range reader.File {
+		entries[file.Name] = file
+	}
+
+	for _, upload := range uploads {
+		rel := path.Clean(upload.Path)
+		if rel == "." || path.IsAbs(rel) || rel == ".." || strings.HasPrefix(rel, "../") || strings.HasPrefix(rel, autoBackupDirName+"/") {
+			return fmt.Errorf("%w: invalid upload path %s", ErrInvalidSiteExport, upload.Path)
+		}
+		entry, ok := entries[path.Join("uploads", rel)]
+		if !ok {
+			report.Skipped["uploads"]++
+			continue
+		}
+
+		target := filepath.Join(s.uploadDir, filepath.FromSlash(rel))
+		if existing, err := fileSHA256(target); err == nil {
+			if existing == upload.SHA256 {
+				report.Reused["uploads"]++
+			} else {
+				report.UploadConflicts = append(report.UploadConflicts, rel)
+			}
+			continue
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to inspect upload %s: %w", rel, err)
+		}
+
+		if err := writeZipEntry(entry, target); err != nil {
+			return err
+		}
+		report.Imported["uploads"]++
+	}
+	return nil
+}
+
+func fileSHA256(name string) (string, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+func writeZipEntry(entry *zip.File, target string) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return fmt.Errorf("failed to prepare upload destination: %w", err)
+	}
+	rc, err := entry.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open upload from bundle: %w", err)
+	}
+	defer rc.Close()
+
+	dst, err := os.OpenFile(target, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to create upload file: %w", err)
+	}
+	if _, err := io.Copy(dst, rc); err != nil {
+		dst.Close()
+		os.Remove(target)
+		return fmt.Errorf("failed to write upload file: %w", err)
+	}
+	return dst.Close()
+}
@@ -0,0 +1,605 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"constructor-script-backend/internal/background"
+	"constructor-script-backend/internal/events"
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/repository"
+	"constructor-script-backend/pkg/logger"
+)
+
+var (
+	ErrInvalidSocialAccount             = errors.New("invalid social account")
+	ErrSocialShareRepositoryUnavailable = errors.New("social share repository not configured")
+)
+
+const (
+	defaultSocialShareHistory = 50
+	socialShareMaxAttempts    = 5
+	socialShareRequestTimeout = 15 * time.Second
+	socialShareResponseLimit  = 2048
+)
+
+// socialNetworkLimits is the maximum message length each network accepts.
+var socialNetworkLimits = map[string]int{
+	models.SocialNetworkMastodon: 500,
+	models.SocialNetworkTwitter:  280,
+	models.SocialNetworkLinkedIn: 3000,
+	models.SocialNetworkTelegram: 4096,
+}
+
+// socialNetworkTemplates are used for accounts without their own template.
+var socialNetworkTemplates = map[string]string{
+	models.SocialNetworkMastodon: "{{.Title}}\n\n{{.Excerpt}}\n\n{{.URL}}{{if .Hashtags}}\n\n{{.Hashtags}}{{end}}",
+	models.SocialNetworkTwitter:  "{{.Title}} {{.URL}}{{if .Hashtags}} {{.Hashtags}}{{end}}",
+	models.SocialNetworkLinkedIn: "{{.Title}}\n\n{{.Excerpt}}\n\n{{.URL}}",
+	models.SocialNetworkTelegram: "{{.Title}}\n\n{{.Excerpt}}\n\n{{.URL}}",
+}
+
+// SocialShareData is the data available to share message templates.
+type SocialShareData struct {
+	Title    string
+	Excerpt  string
+	URL      string
+	Hashtags string
+}
+
+// socialNetworkEndpoints holds the API base URLs of the networks with a fixed host.
+type socialNetworkEndpoints struct {
+	twitter  string
+	linkedIn string
+	telegram string
+}
+
+// SocialShareService shares newly published posts to the configured social
+// accounts. Every share is logged and retried with the same backoff as webhook
+// deliveries.
+type SocialShareService struct {
+	repo      repository.SocialShareRepository
+	posts     repository.PostRepository
+	scheduler *background.Scheduler
+	client    *http.Client
+	siteURL   func() string
+	endpoints socialNetworkEndpoints
+}
+
+func NewSocialShareService(repo repository.SocialShareRepository, posts repository.PostRepository, scheduler *background.Scheduler, siteURL func() string) *SocialShareService {
+	if repo == nil {
+		return nil
+	}
+	return &SocialShareService{
+		repo:      repo,
+		posts:     posts,
+		scheduler: scheduler,
+		client:    &http.Client{Timeout: socialShareRequestTimeout},
+		siteURL:   siteURL,
+		endpoints: socialNetworkEndpoints{
+			twitter:  "https://api.twitter.com",
+			linkedIn: "https://api.linkedin.com",
+			telegram: "https://api.telegram.org",
+		},
+	}
+}
+
+// Subscribe starts sharing posts as they are published.
+func (s *SocialShareService) Subscribe(bus *events.Bus) {
+	if s == nil || bus == nil {
+		return
+	}
+	bus.Subscribe(events.PostPublished, s.handleEvent)
+}
+
+// ResumePending queues shares that were still pending when the server stopped.
+func (s *SocialShareService) ResumePending() {
+	if s == nil {
+		return
+	}
+
+	shares, err := s.repo.ListPendingShares()
+	if err != nil {
+		logger.Error(err, "Failed to load pending social shares", nil)
+		return
+	}
+
+	now := time.Now().UTC()
+	for _, share := range shares {
+		delay := time.Duration(0)
+		if share.NextAttemptAt != nil && share.NextAttemptAt.After(now) {
+			delay = share.NextAttemptAt.Sub(now)
+		}
+		s.queue(share.ID, delay)
+	}
+}
+
+// Networks lists the supported networks with their default message templates.
+func (s *SocialShareService) Networks() map[string]string {
+	networks := make(map[string]string, len(socialNetworkTemplates))
+	for network, tmpl := range socialNetworkTemplates {
+		networks[network] = tmpl
+	}
+	return networks
+}
+
+func (s *SocialShareService) ListAccounts() ([]models.SocialAccount, error) {
+	if s == nil || s.repo == nil {
+		return nil, ErrSocialShareRepositoryUnavailable
+	}
+	return s.repo.ListAccounts()
+}
+
+func (s *SocialShareService) CreateAccount(req models.CreateSocialAccountRequest) (*models.SocialAccount, error) {
+	if s == nil || s.repo == nil {
+		return nil, ErrSocialShareRepositoryUnavailable
+	}
+
+	active := true
+	if req.Active != nil {
+		active = *req.Active
+	}
+
+	account := &models.SocialAccount{
+		Name:        strings.TrimSpace(req.Name),
+		Network:     strings.ToLower(strings.TrimSpace(req.Network)),
+		InstanceURL: strings.TrimSpace(req.InstanceURL),
+		Target:      strings.TrimSpace(req.Target),
+		AccessToken: strings.TrimSpace(req.AccessToken),
+		Template:    req.Template,
+		Active:      active,
+	}
+	if err := validateSocialAccount(account); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreateAccount(account); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+func (s *SocialShareService) UpdateAccount(id uint, req models.UpdateSocialAccountRequest) (*models.SocialAccount, error) {
+	if s == nil || s.repo == nil {
+		return nil, ErrSocialShareRepositoryUnavailable
+	}
+
+	account, err := s.repo.GetAccount(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		account.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.InstanceURL != nil {
+		account.InstanceURL = strings.TrimSpace(*req.InstanceURL)
+	}
+	if req.Target != nil {
+		account.Target = strings.TrimSpace(*req.Target)
+	}
+	if req.AccessToken != nil && strings.TrimSpace(*req.AccessToken) != "" {
+		account.AccessToken = strings.TrimSpace(*req.AccessToken)
+	}
+	if req.Template != nil {
+		account.Template = *req.Template
+	}
+	if req.Active != nil {
+		account.Active = *req.Active
+	}
+	if err := validateSocialAccount(account); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateAccount(account); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+func (s *SocialShareService) DeleteAccount(id uint) error {
+	if s == nil || s.repo == nil {
+		return ErrSocialShareRepositoryUnavailable
+	}
+	if _, err := s.repo.GetAccount(id); err != nil {
+		return err
+	}
+	return s.repo.DeleteAccount(id)
+}
+
+// Shares returns the send log, newest first. An accountID of zero returns the
+// shares of every account.
+func (s *SocialShareService) Shares(accountID uint, limit int) ([]models.SocialShare, error) {
+	if s == nil || s.repo == nil {
+		return nil, ErrSocialShareRepositoryUnavailable
+	}
+	if limit <= 0 || limit > 500 {
+		limit = defaultSocialShareHistory
+	}
+	return s.repo.ListShares(accountID, limit)
+}
+
+// SharePost queues a post for an account regardless of whether it was shared
+// before, for example to retry a failed share or to share an older post.
+func (s *SocialShareService) SharePost(accountID, postID uint) (*models.SocialShare, error) {
+	if s == nil || s.repo == nil {
+		return nil, ErrSocialShareRepositoryUnavailable
+	}
+
+	account, err := s.repo.GetAccount(accountID)
+	if err != nil {
+		return nil, err
+	}
+	post, err := s.loadPost(postID)
+	if err != nil {
+		return nil, err
+	}
+	if !post.Published {
+		return nil, fmt.Errorf("%w: post is not published", ErrInvalidSocialAccount)
+	}
+
+	return s.createShare(account, post)
+}
+
+// RenderMessage renders the share message of post for account, trimmed to the
+// network's length limit.
+func (s *SocialShareService) RenderMessage(account *models.SocialAccount, post *models.Post) (string, error) {
+	source := account.Template
+	if strings.TrimSpace(source) == "" {
+		source = socialNetworkTemplates[account.Network]
+	}
+	tmpl, err := template.New("share").Parse(source)
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid template: %v", ErrInvalidSocialAccount, err)
+	}
+
+	data := SocialShareData{
+		Title:    strings.TrimSpace(post.Title),
+		Excerpt:  strings.TrimSpace(post.Excerpt),
+		URL:      s.postURL(post),
+		Hashtags: socialHashtags(post.Tags),
+	}
+	if data.Excerpt == "" {
+		data.Excerpt = strings.TrimSpace(post.Description)
+	}
+
+	limit := socialNetworkLimits[account.Network]
+	render := func() (string, error) {
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return "", fmt.Errorf("%w: template failed: %v", ErrInvalidSocialAccount, err)
+		}
+		return strings.TrimSpace(buf.String()), nil
+	}
+
+	message, err := render()
+	if err != nil || limit <= 0 || utf8.RuneCountInString(message) <= limit {
+		return message, err
+	}
+
+	// Drop the optional parts before cutting into the title or link.
+	data.Excerpt, data.Hashtags = "", ""
+	if message, err = render(); err != nil {
+		return "", err
+	}
+	if utf8.RuneCountInString(message) > limit {
+		message = string([]rune(message)[:limit-1]) + "…"
+	}
+	return message, nil
+}
+
+func (s *SocialShareService) handleEvent(_ context.Context, event events.Event) {
+	postID, ok := eventUint(event.Payload["id"])
+	if !ok {
+		return
+	}
+
+	accounts, err := s.repo.ListActiveAccounts()
+	if err != nil {
+		logger.Error(err, "Failed to load social accounts", map[string]interface{}{"post_id": postID})
+		return
+	}
+	if len(accounts) == 0 {
+		return
+	}
+
+	post, err := s.loadPost(postID)
+	if err != nil || !post.Published {
+		return
+	}
+
+	for i := range accounts {
+		account := &accounts[i]
+		// A post that is unpublished and published again is only shared once.
+		if exists, err := s.repo.ExistsShare(account.ID, post.ID); err != nil || exists {
+			continue
+		}
+		if _, err := s.createShare(account, post); err != nil {
+			logger.Error(err, "Failed to queue social share", map[string]interface{}{"account": account.ID, "post_id": post.ID})
+		}
+	}
+}
+
+func (s *SocialShareService) createShare(account *models.SocialAccount, post *models.Post) (*models.SocialShare, error) {
+	message, err := s.RenderMessage(account, post)
+	if err != nil {
+		return nil, err
+	}
+
+	share := &models.SocialShare{
+		AccountID: account.ID,
+		Network:   account.Network,
+		PostID:    post.ID,
+		Message:   message,
+		Status:    models.SocialSharePending,
+	}
+	if err := s.repo.CreateShare(share); err != nil {
+		return nil, err
+	}
+
+	s.queue(share.ID, 0)
+	return share, nil
+}
+
+func (s *SocialShareService) loadPost(id uint) (*models.Post, error) {
+	if s.posts == nil {
+		return nil, fmt.Errorf("%w: posts are not available", ErrInvalidSocialAccount)
+	}
+	return s.posts.GetByID(id)
+}
+
+func (s *SocialShareService) postURL(post *models.Post) string {
+	base := ""
+	if s.siteURL != nil {
+		base = strings.TrimRight(strings.TrimSpace(s.siteURL()), "/")
+	}
+	return base + "/blog/post/" + post.Slug
+}
+
+// queue hands the share to the background scheduler once delay has elapsed.
+func (s *SocialShareService) queue(shareID uint, delay time.Duration) {
+	if delay > 0 {
+		time.AfterFunc(delay, func() { s.queue(shareID, 0) })
+		return
+	}
+
+	job := background.Job{
+		Name:    fmt.Sprintf("social_share_%d", shareID),
+		Timeout: socialShareRequestTimeout + 5*time.Second,
+		Run: func(ctx context.Context) error {
+			return s.deliver(ctx, shareID)
+		},
+	}
+
+	if s.scheduler != nil {
+		err := s.scheduler.ScheduleUnique(job)
+		if err == nil || errors.Is(err, background.ErrJobAlreadyScheduled) {
+			return
+		}
+		if !errors.Is(err, background.ErrSchedulerNotStarted) {
+			logger.Warn("Failed to schedule social share", map[string]interface{}{"share": shareID, "error": err.Error()})
+			return
+		}
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), job.Timeout)
+		defer cancel()
+		if err := job.Run(ctx); err != nil {
+			logger.Error(err, "Social share failed", map[string]interface{}{"share": shareID})
+		}
+	}()
+}
+
+func (s *SocialShareService) deliver(ctx context.Context, shareID uint) error {
+	share, err := s.repo.GetShare(shareID)
+	if err != nil {
+		return err
+	}
+	if share.Status != models.SocialSharePending {
+		return nil
+	}
+
+	account, err := s.repo.GetAccount(share.AccountID)
+	if err != nil || !account.Active {
+		share.Status = models.SocialShareFailed
+		share.Error = "social account is deleted or inactive"
+		share.NextAttemptAt = nil
+		return s.repo.UpdateShare(share)
+	}
+
+	share.Attempts++
+	status, body, sendErr := s.send(ctx, account, share.Message)
+	share.ResponseStatus = status
+	share.ResponseBody = body
+	now := time.Now().UTC()
+
+	if sendErr == nil {
+		share.Status = models.SocialShareSent
+		share.Error = ""
+		share.NextAttemptAt = nil
+		share.SentAt = &now
+		return s.repo.UpdateShare(share)
+	}
+
+	share.Error = sendErr.Error()
+	// Client errors (bad token, rejected message) won't succeed on retry.
+	if share.Attempts >= socialShareMaxAttempts || (status >= 400 && status < 500 && status != http.StatusTooManyRequests) {
+		share.Status = models.SocialShareFailed
+		share.NextAttemptAt = nil
+		logger.Warn("Social share failed permanently", map[string]interface{}{
+			"account": account.ID,
+			"share":   share.ID,
+			"post_id": share.PostID,
+			"error":   share.Error,
+		})
+		return s.repo.UpdateShare(share)
+	}
+
+	delay := webhookRetryDelay(share.Attempts)
+	next := now.Add(delay)
+	share.NextAttemptAt = &next
+	if err := s.repo.UpdateShare(share); err != nil {
+		return err
+	}
+
+	s.queue(share.ID, delay)
+	return nil
+}
+
+func (s *SocialShareService) send(ctx context.Context, account *models.SocialAccount, message string) (int, string, error) {
+	var (
+		request *http.Request
+		err     error
+	)
+
+	switch account.Network {
+	case models.SocialNetworkMastodon:
+		form := url.Values{"status": {message}, "visibility": {"public"}}
+		request, err = http.NewRequestWithContext(ctx, http.MethodPost,
+			strings.TrimRight(account.InstanceURL, "/")+"/api/v1/statuses", strings.NewReader(form.Encode()))
+		if err == nil {
+			request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			request.Header.Set("Authorization", "Bearer "+account.AccessToken)
+		}
+	case models.SocialNetworkTwitter:
+		request, err = newSocialJSONRequest(ctx, s.endpoints.twitter+"/2/tweets", map[string]interface{}{"text": message})
+		if err == nil {
+			request.Header.Set("Authorization", "Bearer "+account.AccessToken)
+		}
+	case models.SocialNetworkLinkedIn:
+		request, err = newSocialJSONRequest(ctx, s.endpoints.linkedIn+"/v2/ugcPosts", map[string]interface{}{
+			"author":         account.Target,
+			"lifecycleState": "PUBLISHED",
+			"specificContent": map[string]interface{}{
+				"com.linkedin.ugc.ShareContent": map[string]interface{}{
+					"shareCommentary":    map[string]string{"text": message},
+					"shareMediaCategory": "NONE",
+				},
+			},
+			"visibility": map[string]string{"com.linkedin.ugc.MemberNetworkVisibility": "PUBLIC"},
+		})
+		if err == nil {
+			request.Header.Set("Authorization", "Bearer "+account.AccessToken)
+			request.Header.Set("X-Restli-Protocol-Version", "2.0.0")
+		}
+	case models.SocialNetworkTelegram:
+		request, err = newSocialJSONRequest(ctx, s.endpoints.telegram+"/bot"+account.AccessToken+"/sendMessage", map[string]interface{}{
+			"chat_id": account.Target,
+			"text":    message,
+		})
+	default:
+		return 0, "", fmt.Errorf("unsupported network %q", account.Network)
+	}
+	if err != nil {
+		return 0, "", err
+	}
+	request.Header.Set("User-Agent", "constructor-script-social")
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		// The Telegram token is part of the URL; keep it out of the log.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			return 0, "", urlErr.Err
+		}
+		return 0, "", err
+	}
+	defer response.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(response.Body, socialShareResponseLimit))
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return response.StatusCode, string(body), fmt.Errorf("%s responded with status %d", account.Network, response.StatusCode)
+	}
+	return response.StatusCode, string(body), nil
+}
+
+func newSocialJSONRequest(ctx context.Context, endpoint string, payload interface{}) (*http.Request, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	return request, nil
+}
+
+func validateSocialAccount(account *models.SocialAccount) error {
+	if _, ok := socialNetworkTemplates[account.Network]; !ok {
+		return fmt.Errorf("%w: unsupported network %q", ErrInvalidSocialAccount, account.Network)
+	}
+	if account.AccessToken == "" {
+		return fmt.Errorf("%w: access token is required", ErrInvalidSocialAccount)
+	}
+
+	switch account.Network {
+	case models.SocialNetworkMastodon:
+		parsed, err := url.Parse(account.InstanceURL)
+		if err != nil || parsed.Host == "" || (parsed.Scheme != "https" && parsed.Scheme != "http") {
+			return fmt.Errorf("%w: mastodon accounts need an absolute instance URL", ErrInvalidSocialAccount)
+		}
+		account.InstanceURL = strings.TrimRight(parsed.String(), "/")
+	case models.SocialNetworkLinkedIn:
+		if !strings.HasPrefix(account.Target, "urn:li:") {
+			return fmt.Errorf("%w: linkedin accounts need an author URN such as urn:li:organization:123", ErrInvalidSocialAccount)
+		}
+	case models.SocialNetworkTelegram:
+		if account.Target == "" {
+			return fmt.Errorf("%w: telegram accounts need a chat or channel ID", ErrInvalidSocialAccount)
+		}
+	}
+
+	if strings.TrimSpace(account.Template) != "" {
+		if _, err := template.New("share").Parse(account.Template); err != nil {
+			return fmt.Errorf("%w: invalid template: %v", ErrInvalidSocialAccount, err)
+		}
+	}
+	return nil
+}
+
+// socialHashtags turns post tags into space separated hashtags.
+func socialHashtags(tags []models.Tag) string {
+	hashtags := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		var builder strings.Builder
+		for _, r := range tag.Name {
+			if r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) {
+				builder.WriteRune(r)
+			}
+		}
+		if builder.Len() > 0 {
+			hashtags = append(hashtags, "#"+builder.String())
+		}
+	}
+	return strings.Join(hashtags, " ")
+}
+
+func eventUint(value interface{}) (uint, bool) {
+	switch typed := value.(type) {
+	case uint:
+		return typed, typed != 0
+	case int:
+		return uint(typed), typed > 0
+	case float64:
+		return uint(typed), typed > 0
+	case string:
+		parsed, err := strconv.ParseUint(typed, 10, 64)
+		return uint(parsed), err == nil && parsed != 0
+	}
+	return 0, false
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"constructor-script-backend/internal/models"
+)
+
+func TestSocialShareRenderMessage(t *testing.T) {
+	svc := &SocialShareService{siteURL: func() string { return "https://example.com/" }}
+	post := &models.Post{
+		Title:   "Release notes",
+		Slug:    "release-notes",
+		Excerpt: "What changed this week.",
+		Tags:    []models.Tag{{Name: "Go"}, {Name: "open source"}},
+	}
+
+	message, err := svc.RenderMessage(&models.SocialAccount{Network: models.SocialNetworkTwitter}, post)
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if message != "Release notes https://example.com/blog/post/release-notes #Go #opensource" {
+		t.Fatalf("unexpected message %q", message)
+	}
+
+	custom := &models.SocialAccount{Network: models.SocialNetworkTelegram, Template: "New: {{.Title}} ({{.URL}})"}
+	if message, _ := svc.RenderMessage(custom, post); message != "New: Release notes (https://example.com/blog/post/release-notes)" {
+		t.Fatalf("unexpected custom message %q", message)
+	}
+}
+
+func TestSocialShareRenderMessageRespectsLimit(t *testing.T) {
+	svc := &SocialShareService{siteURL: func() string { return "https://example.com" }}
+	post := &models.Post{Title: "Short", Slug: "short", Excerpt: strings.Repeat("long excerpt ", 60)}
+
+	message, err := svc.RenderMessage(&models.SocialAccount{Network: models.SocialNetworkMastodon}, post)
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if strings.Contains(message, "long excerpt") || !strings.HasSuffix(message, "/blog/post/short") {
+		t.Fatalf("expected the excerpt to be dropped, got %q", message)
+	}
+
+	post.Title = strings.Repeat("a", 400)
+	message, _ = svc.RenderMessage(&models.SocialAccount{Network: models.SocialNetworkTwitter}, post)
+	if utf8.RuneCountInString(message) != 280 || !strings.HasSuffix(message, "…") {
+		t.Fatalf("expected a truncated message, got %d runes", utf8.RuneCountInString(message))
+	}
+}
+
+func TestSocialShareSend(t *testing.T) {
+	var gotPath, gotAuth, gotBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotAuth = r.URL.Path, r.Header.Get("Authorization")
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	svc := &SocialShareService{client: server.Client(), endpoints: socialNetworkEndpoints{telegram: server.URL}}
+
+	mastodon := &models.SocialAccount{Network: models.SocialNetworkMastodon, InstanceURL: server.URL, AccessToken: "token"}
+	if _, _, err := svc.send(context.Background(), mastodon, "hello"); err != nil {
+		t.Fatalf("mastodon: %v", err)
+	}
+	if gotPath != "/api/v1/statuses" || gotAuth != "Bearer token" || !strings.Contains(gotBody, "status=hello") {
+		t.Fatalf("unexpected mastodon request %s %q %q", gotPath, gotAuth, gotBody)
+	}
+
+	telegram := &models.SocialAccount{Network: models.SocialNetworkTelegram, Target: "@channel", AccessToken: "123:abc"}
+	if _, _, err := svc.send(context.Background(), telegram, "hello"); err != nil {
+		t.Fatalf("telegram: %v", err)
+	}
+	if gotPath != "/bot123:abc/sendMessage" || !strings.Contains(gotBody, `"chat_id":"@channel"`) {
+		t.Fatalf("unexpected telegram request %s %q", gotPath, gotBody)
+	}
+}
+
+func TestValidateSocialAccount(t *testing.T) {
+	cases := []struct {
+		account models.SocialAccount
+		valid   bool
+	}{
+		{models.SocialAccount{Network: "myspace", AccessToken: "t"}, false},
+		{models.SocialAccount{Network: models.SocialNetworkTwitter}, false},
+		{models.SocialAccount{Network: models.SocialNetworkTwitter, AccessToken: "t"}, true},
+		{models.SocialAccount{Network: models.SocialNetworkMastodon, AccessToken: "t"}, false},
+		{models.SocialAccount{Network: models.SocialNetworkMastodon, AccessToken: "t", InstanceURL: "https://mastodon.social/"}, true},
+		{models.SocialAccount{Network: models.SocialNetworkLinkedIn, AccessToken: "t", Target: "123"}, false},
+		{models.SocialAccount{Network: models.SocialNetworkTelegram, AccessToken: "t", Target: "@news"}, true},
+		{models.SocialAccount{Network: models.SocialNetworkTelegram, AccessToken: "t", Target: "@news", Template: "{{.Title"}, false},
+	}
+	for index, tc := range cases {
+		account := tc.account
+		if err := validateSocialAccount(&account); (err == nil) != tc.valid {
+			t.Fatalf("case %d: expected valid=%v, got %v", index, tc.valid, err)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/pkg/cache"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultStatsDays = 30
+	maxStatsDays     = 90
+	statsDayLayout   = "2006-01-02"
+)
+
+// RequestCounter reports the requests served and the server errors among them on
+// the UTC day containing day.
+type RequestCounter func(day time.Time) (requests, serverErrors int64)
+
+// StatsService computes the admin dashboard figures. Content, signups, views, forum
+// activity and revenue come from the database; cache and error rates come from the
+// counters this process keeps.
+type StatsService struct {
+	db       *gorm.DB
+	cache    *cache.Cache
+	requests RequestCounter
+	now      func() time.Time
+}
+
+func NewStatsService(db *gorm.DB, cacheService *cache.Cache, requests RequestCounter) *StatsService {
+	return &StatsService{db: db, cache: cacheService, requests: requests, now: time.Now}
+}
+
+// Dashboard returns the totals and the activity trend over the last days days,
+// today included. days is brought into 1..90, defaulting to 30.
+func (s *StatsService) Dashboard(days int) (*models.DashboardStatistics, error) {
+	if s == nil || s.db == nil {
+		return nil, errors.New("stats service not configured")
+	}
+	if days <= 0 {
+		days = defaultStatsDays
+	}
+	if days > maxStatsDays {
+		days = maxStatsDays
+	}
+
+	now := s.now().UTC()
+	dashboard := &models.DashboardStatistics{}
+
+	totals, err := s.totals(now)
+	if err != nil {
+		return nil, err
+	}
+	dashboard.Statistics = totals
+
+	if err := s.db.Model(&models.Post{}).
+		Select("id, title, views").
+		Where("published = ? AND (publish_at IS NULL OR publish_at <= ?)", true, now).
+		Order("views DESC").
+		Limit(5).
+		Scan(&dashboard.PopularPosts).Error; err != nil {
+		return nil, fmt.Errorf("failed to load popular posts: %w", err)
+	}
+
+	if err := s.db.Model(&models.User{}).
+		Select("id, username, email, role").
+		Order("created_at DESC").
+		Limit(5).
+		Scan(&dashboard.RecentUsers).Error; err != nil {
+		return nil, fmt.Errorf("failed to load recent users: %w", err)
+	}
+
+	trend, err := s.activityTrend(now, days)
+	if err != nil {
+		return nil, err
+	}
+	dashboard.ActivityTrend = trend
+
+	return dashboard, nil
+}
+
+func (s *StatsService) totals(now time.Time) (models.StatisticsTotals, error) {
+	var totals models.StatisticsTotals
+	dayAgo := now.Add(-24 * time.Hour)
+	weekAgo := now.AddDate(0, 0, -7)
+	published := "published = ? AND (publish_at IS NULL OR publish_at <= ?)"
+	publishedAt := "COALESCE(published_at, publish_at, created_at) >= ?"
+
+	queries := []struct {
+		name  string
+		query *gorm.DB
+		dest  *int64
+	}{
+		{"posts", s.db.Model(&models.Post{}), &totals.TotalPosts},
+		{"published posts", s.db.Model(&models.Post{}).Where(published, true, now), &totals.PublishedPosts},
+		{"users", s.db.Model(&models.User{}), &totals.TotalUsers},
+		{"categories", s.db.Model(&models.Category{}), &totals.TotalCategories},
+		{"comments", s.db.Model(&models.Comment{}), &totals.TotalComments},
+		{"tags", s.db.Model(&models.Tag{}), &totals.TotalTags},
+		{"recent posts", s.db.Model(&models.Post{}).Where(publishedAt, dayAgo), &totals.PostsLast24Hours},
+		{"weekly posts", s.db.Model(&models.Post{}).Where(publishedAt, weekAgo), &totals.PostsLast7Days},
+		{"recent comments", s.db.Model(&models.Comment{}).Where("created_at >= ?", dayAgo), &totals.CommentsLast24Hours},
+		{"weekly comments", s.db.Model(&models.Comment{}).Where("created_at >= ?", weekAgo), &totals.CommentsLast7Days},
+		{"weekly users", s.db.Model(&models.User{}).Where("created_at >= ?", weekAgo), &totals.UsersLast7Days},
+	}
+	for _, q := range queries {
+		if err := q.query.Count(q.dest).Error; err != nil {
+			return totals, fmt.Errorf("failed to count %s: %w", q.name, err)
+		}
+	}
+
+	if err := s.db.Model(&models.Post{}).Select("COALESCE(SUM(views), 0)").Scan(&totals.TotalViews).Error; err != nil {
+		return totals, fmt.Errorf("failed to sum post views: %w", err)
+	}
+	// Plugin tables are missing while the plugin is not active.
+	if !s.db.Migrator().HasTable(&models.ProductOrder{}) {
+		return totals, nil
+	}
+	if err := s.db.Model(&models.ProductOrder{}).
+		Where("status = ?", models.ProductOrderStatusPaid).
+		Select("COALESCE(SUM(amount_cents), 0)").
+		Scan(&totals.TotalRevenueCents).Error; err != nil {
+		return totals, fmt.Errorf("failed to sum revenue: %w", err)
+	}
+
+	return totals, nil
+}
+
+type dailyValue struct {
+	Period time.Time
+	Count  int64
+}
+
+// dailySeries runs a query grouped by day and returns its values by date.
+func dailySeries(query *gorm.DB, selectExpr string) (map[string]int64, error) {
+	var rows []dailyValue
+	if err := query.Select(selectExpr).Group("period").Order("period").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	values := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		values[row.Period.UTC().Format(statsDayLayout)] = row.Count
+	}
+	return values, nil
+}
+
+func (s *StatsService) activityTrend(now time.Time, days int) ([]models.ActivityPoint, error) {
+	startOfToday := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	windowStart := startOfToday.AddDate(0, 0, -(days - 1))
+	postDate := "COALESCE(published_at, publish_at, created_at)"
+
+	series := []struct {
+		name       string
+		query      *gorm.DB
+		selectExpr string
+	}{
+		{"posts", s.db.Model(&models.Post{}).Where(postDate+" >= ?", windowStart),
+			"DATE_TRUNC('day', " + postDate + ") AS period, COUNT(*) AS count"},
+		{"comments", s.db.Model(&models.Comment{}).Where("created_at >= ?", windowStart),
+			"DATE_TRUNC('day', created_at) AS period, COUNT(*) AS count"},
+		{"views", s.db.Model(&models.PostViewStat{}).Where("date >= ?", windowStart),
+			"date AS period, COALESCE(SUM(views), 0) AS count"},
+		{"signups", s.db.Model(&models.User{}).Where("created_at >= ?", windowStart),
+			"DATE_TRUNC('day', created_at) AS period, COUNT(*) AS count"},
+		{"forum questions", s.db.Model(&models.ForumQuestion{}).Where("created_at >= ?", windowStart),
+			"DATE_TRUNC('day', created_at) AS period, COUNT(*) AS count"},
+		{"forum answers", s.db.Model(&models.ForumAnswer{}).Where("created_at >= ?", windowStart),
+			"DATE_TRUNC('day', created_at) AS period, COUNT(*) AS count"},
+		{"checkouts", s.db.Model(&models.ProductOrder{}).Where("status = ? AND paid_at >= ?", models.ProductOrderStatusPaid, windowStart),
+			"DATE_TRUNC('day', paid_at) AS period, COUNT(*) AS count"},
+		{"revenue", s.db.Model(&models.ProductOrder{}).Where("status = ? AND paid_at >= ?", models.ProductOrderStatusPaid, windowStart),
+			"DATE_TRUNC('day', paid_at) AS period, COALESCE(SUM(amount_cents), 0) AS count"},
+	}
+	values := make(map[string]map[string]int64, len(series))
+	for _, q := range series {
+		if q.query.Statement.Model != nil && !s.db.Migrator().HasTable(q.query.Statement.Model) {
+			continue
+		}
+		daily, err := dailySeries(q.query, q.selectExpr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load daily %s: %w", q.name, err)
+		}
+		values[q.name] = daily
+	}
+
+	trend := make([]models.ActivityPoint, 0, days)
+	for day := 0; day < days; day++ {
+		point := windowStart.AddDate(0, 0, day)
+		key := point.Format(statsDayLayout)
+		entry := models.ActivityPoint{
+			Period:         point,
+			Posts:          values["posts"][key],
+			Comments:       values["comments"][key],
+			Views:          values["views"][key],
+			Users:          values["signups"][key],
+			ForumQuestions: values["forum questions"][key],
+			ForumAnswers:   values["forum answers"][key],
+			Checkouts:      values["checkouts"][key],
+			RevenueCents:   values["revenue"][key],
+		}
+
+		entry.CacheHits, entry.CacheMisses = s.cache.DailyHits(point)
+		entry.CacheHitRate = ratio(entry.CacheHits, entry.CacheHits+entry.CacheMisses)
+		if s.requests != nil {
+			entry.Requests, entry.ServerErrors = s.requests(point)
+			entry.ErrorRate = ratio(entry.ServerErrors, entry.Requests)
+		}
+
+		trend = append(trend, entry)
+	}
+	return trend, nil
+}
+
+func ratio(part, whole int64) *float64 {
+	if whole <= 0 {
+		return nil
+	}
+	value := float64(part) / float64(whole)
+	return &value
+}
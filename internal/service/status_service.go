@@ -0,0 +1,262 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/repository"
+)
+
+const (
+	// statusCheckTTL bounds how often the public status page runs the health
+	// checks, since anyone can request it.
+	statusCheckTTL     = 15 * time.Second
+	statusCheckTimeout = 3 * time.Second
+	// statusRecentWindow is how long resolved incidents stay on the status page.
+	statusRecentWindow = 14 * 24 * time.Hour
+	maxStatusIncidents = 200
+)
+
+var incidentStatuses = map[string]bool{
+	models.IncidentStatusInvestigating: true,
+	models.IncidentStatusIdentified:    true,
+	models.IncidentStatusMonitoring:    true,
+	models.IncidentStatusResolved:      true,
+}
+
+var incidentImpacts = map[string]bool{
+	models.IncidentImpactMinor:       true,
+	models.IncidentImpactMajor:       true,
+	models.IncidentImpactMaintenance: true,
+}
+
+// HealthCheck reports whether a component works. A nil error means operational.
+type HealthCheck func(ctx context.Context) error
+
+type statusComponent struct {
+	name  string
+	check HealthCheck
+}
+
+// StatusService backs the public status page: component health from the checks
+// registered with AddComponent, and incidents that admins post and resolve.
+type StatusService struct {
+	repo repository.StatusIncidentRepository
+	now  func() time.Time
+
+	components []statusComponent
+
+	mu        sync.Mutex
+	health    []models.ComponentHealth
+	checkedAt time.Time
+}
+
+type StatusValidationError struct {
+	Reason string
+}
+
+func (e *StatusValidationError) Error() string {
+	if e == nil {
+		return ""
+	}
+	return e.Reason
+}
+
+func statusValidationErrorf(format string, args ...interface{}) error {
+	return &StatusValidationError{Reason: fmt.Sprintf(format, args...)}
+}
+
+func NewStatusService(repo repository.StatusIncidentRepository) *StatusService {
+	return &StatusService{repo: repo, now: time.Now}
+}
+
+// AddComponent registers a component shown on the status page. Call it during
+// start-up, before the service serves requests.
+func (s *StatusService) AddComponent(name string, check HealthCheck) {
+	if s == nil || check == nil {
+		return
+	}
+	s.components = append(s.components, statusComponent{name: name, check: check})
+}
+
+// Current returns the overall status, component health and the incidents to show.
+func (s *StatusService) Current(ctx context.Context) (*models.SiteStatus, error) {
+	if s == nil || s.repo == nil {
+		return nil, fmt.Errorf("status service not configured")
+	}
+
+	now := s.now()
+	incidents, err := s.repo.ListSince(now.Add(-statusRecentWindow))
+	if err != nil {
+		return nil, err
+	}
+
+	health, checkedAt := s.componentHealth(ctx, now)
+	status := &models.SiteStatus{
+		CheckedAt:  checkedAt,
+		Components: health,
+		Active:     []models.StatusIncident{},
+		Upcoming:   []models.StatusIncident{},
+		Recent:     []models.StatusIncident{},
+	}
+	for _, incident := range incidents {
+		switch {
+		case incident.ResolvedAt != nil:
+			status.Recent = append(status.Recent, incident)
+		case incident.StartedAt.After(now):
+			status.Upcoming = append(status.Upcoming, incident)
+		default:
+			status.Active = append(status.Active, incident)
+		}
+	}
+	status.Status = overallStatus(health, status.Active)
+	return status, nil
+}
+
+func (s *StatusService) componentHealth(ctx context.Context, now time.Time) ([]models.ComponentHealth, time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.health != nil && now.Sub(s.checkedAt) < statusCheckTTL {
+		return s.health, s.checkedAt
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, statusCheckTimeout)
+	defer cancel()
+
+	health := make([]models.ComponentHealth, len(s.components))
+	var wg sync.WaitGroup
+	for index, component := range s.components {
+		wg.Add(1)
+		go func(index int, component statusComponent) {
+			defer wg.Done()
+			state := models.ComponentOperational
+			if err := component.check(ctx); err != nil {
+				state = models.ComponentOutage
+			}
+			health[index] = models.ComponentHealth{Name: component.name, Status: state}
+		}(index, component)
+	}
+	wg.Wait()
+
+	s.health = health
+	s.checkedAt = now
+	return health, now
+}
+
+// overallStatus is the worst of the component states and the active incidents.
+// An active maintenance window is reported as such unless something is down.
+func overallStatus(health []models.ComponentHealth, active []models.StatusIncident) string {
+	status := models.ComponentOperational
+	for _, component := range health {
+		if component.Status == models.ComponentOutage {
+			return models.ComponentOutage
+		}
+	}
+	for _, incident := range active {
+		switch incident.Impact {
+		case models.IncidentImpactMajor:
+			return models.ComponentOutage
+		case models.IncidentImpactMaintenance:
+			status = models.ComponentMaintenance
+		case models.IncidentImpactMinor:
+			if status == models.ComponentOperational {
+				status = models.ComponentDegraded
+			}
+		}
+	}
+	return status
+}
+
+func (s *StatusService) ListIncidents() ([]models.StatusIncident, error) {
+	return s.repo.List(maxStatusIncidents)
+}
+
+func (s *StatusService) CreateIncident(req models.CreateStatusIncidentRequest) (*models.StatusIncident, error) {
+	incident := &models.StatusIncident{
+		Title:     strings.TrimSpace(req.Title),
+		Message:   strings.TrimSpace(req.Message),
+		Status:    strings.TrimSpace(req.Status),
+		Impact:    strings.TrimSpace(req.Impact),
+		StartedAt: s.now(),
+	}
+	if incident.Status == "" {
+		incident.Status = models.IncidentStatusInvestigating
+	}
+	if incident.Impact == "" {
+		incident.Impact = models.IncidentImpactMinor
+	}
+	if req.StartedAt != nil {
+		incident.StartedAt = *req.StartedAt
+	}
+	if err := s.prepareIncident(incident); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(incident); err != nil {
+		return nil, err
+	}
+	return incident, nil
+}
+
+func (s *StatusService) UpdateIncident(id uint, req models.UpdateStatusIncidentRequest) (*models.StatusIncident, error) {
+	incident, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Title != nil {
+		incident.Title = strings.TrimSpace(*req.Title)
+	}
+	if req.Message != nil {
+		incident.Message = strings.TrimSpace(*req.Message)
+	}
+	if req.Status != nil {
+		incident.Status = strings.TrimSpace(*req.Status)
+	}
+	if req.Impact != nil {
+		incident.Impact = strings.TrimSpace(*req.Impact)
+	}
+	if req.StartedAt != nil {
+		incident.StartedAt = *req.StartedAt
+	}
+	if err := s.prepareIncident(incident); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(incident); err != nil {
+		return nil, err
+	}
+	return incident, nil
+}
+
+func (s *StatusService) DeleteIncident(id uint) error {
+	return s.repo.Delete(id)
+}
+
+// prepareIncident validates the incident and keeps ResolvedAt in step with Status.
+func (s *StatusService) prepareIncident(incident *models.StatusIncident) error {
+	if incident.Title == "" {
+		return statusValidationErrorf("title is required")
+	}
+	if len(incident.Title) > 200 {
+		return statusValidationErrorf("title must be at most 200 characters")
+	}
+	if !incidentStatuses[incident.Status] {
+		return statusValidationErrorf("unknown status %q", incident.Status)
+	}
+	if !incidentImpacts[incident.Impact] {
+		return statusValidationErrorf("unknown impact %q", incident.Impact)
+	}
+
+	if incident.Status == models.IncidentStatusResolved {
+		if incident.ResolvedAt == nil {
+			resolvedAt := s.now()
+			incident.ResolvedAt = &resolvedAt
+		}
+	} else {
+		incident.ResolvedAt = nil
+	}
+	return nil
+}
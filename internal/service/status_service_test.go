@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"constructor-script-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type memoryStatusIncidentRepo struct {
+	incidents []models.StatusIncident
+}
+
+func (r *memoryStatusIncidentRepo) Create(incident *models.StatusIncident) error {
+	incident.ID = uint(len(r.incidents) + 1)
+	r.incidents = append(r.incidents, *incident)
+	return nil
+}
+
+func (r *memoryStatusIncidentRepo) Update(incident *models.StatusIncident) error {
+	for i := range r.incidents {
+		if r.incidents[i].ID == incident.ID {
+			r.incidents[i] = *incident
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
+
+func (r *memoryStatusIncidentRepo) Delete(id uint) error {
+	return nil
+}
+
+func (r *memoryStatusIncidentRepo) GetByID(id uint) (*models.StatusIncident, error) {
+	for _, incident := range r.incidents {
+		if incident.ID == id {
+			found := incident
+			return &found, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memoryStatusIncidentRepo) List(limit int) ([]models.StatusIncident, error) {
+	return r.incidents, nil
+}
+
+func (r *memoryStatusIncidentRepo) ListSince(since time.Time) ([]models.StatusIncident, error) {
+	var incidents []models.StatusIncident
+	for _, incident := range r.incidents {
+		if incident.ResolvedAt == nil || !incident.ResolvedAt.Before(since) {
+			incidents = append(incidents, incident)
+		}
+	}
+	return incidents, nil
+}
+
+func TestStatusServiceCurrent(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	repo := &memoryStatusIncidentRepo{}
+	svc := NewStatusService(repo)
+	svc.now = func() time.Time { return now }
+	svc.AddComponent("Database", func(ctx context.Context) error { return nil })
+
+	status, err := svc.Current(context.Background())
+	if err != nil {
+		t.Fatalf("Current: %v", err)
+	}
+	if status.Status != models.ComponentOperational {
+		t.Fatalf("expected operational with no incidents, got %q", status.Status)
+	}
+
+	maintenance, err := svc.CreateIncident(models.CreateStatusIncidentRequest{
+		Title:  "Database upgrade",
+		Status: models.IncidentStatusIdentified,
+		Impact: models.IncidentImpactMaintenance,
+	})
+	if err != nil {
+		t.Fatalf("CreateIncident: %v", err)
+	}
+	later := now.Add(48 * time.Hour)
+	if _, err := svc.CreateIncident(models.CreateStatusIncidentRequest{
+		Title:     "Storage migration",
+		Impact:    models.IncidentImpactMaintenance,
+		StartedAt: &later,
+	}); err != nil {
+		t.Fatalf("CreateIncident: %v", err)
+	}
+
+	status, err = svc.Current(context.Background())
+	if err != nil {
+		t.Fatalf("Current: %v", err)
+	}
+	if status.Status != models.ComponentMaintenance {
+		t.Fatalf("expected maintenance, got %q", status.Status)
+	}
+	if len(status.Active) != 1 || len(status.Upcoming) != 1 || len(status.Recent) != 0 {
+		t.Fatalf("unexpected grouping: %d active, %d upcoming, %d recent", len(status.Active), len(status.Upcoming), len(status.Recent))
+	}
+
+	resolved := models.IncidentStatusResolved
+	updated, err := svc.UpdateIncident(maintenance.ID, models.UpdateStatusIncidentRequest{Status: &resolved})
+	if err != nil {
+		t.Fatalf("UpdateIncident: %v", err)
+	}
+	if updated.ResolvedAt == nil || !updated.ResolvedAt.Equal(now) {
+		t.Fatalf("expected resolved_at to be stamped, got %v", updated.ResolvedAt)
+	}
+
+	status, err = svc.Current(context.Background())
+	if err != nil {
+		t.Fatalf("Current: %v", err)
+	}
+	if status.Status != models.ComponentOperational || len(status.Recent) != 1 {
+		t.Fatalf("expected operational with one recent incident, got %q and %d", status.Status, len(status.Recent))
+	}
+}
+
+func TestStatusServiceComponentOutage(t *testing.T) {
+	svc := NewStatusService(&memoryStatusIncidentRepo{})
+	svc.AddComponent("Database", func(ctx context.Context) error { return errors.New("down") })
+	svc.AddComponent("Cache", func(ctx context.Context) error { return nil })
+
+	status, err := svc.Current(context.Background())
+	if err != nil {
+		t.Fatalf("Current: %v", err)
+	}
+	if status.Status != models.ComponentOutage {
+		t.Fatalf("expected outage, got %q", status.Status)
+	}
+	if status.Components[0].Status != models.ComponentOutage || status.Components[1].Status != models.ComponentOperational {
+		t.Fatalf("unexpected component health: %+v", status.Components)
+	}
+}
+
+func TestStatusServiceRejectsUnknownImpact(t *testing.T) {
+	svc := NewStatusService(&memoryStatusIncidentRepo{})
+	_, err := svc.CreateIncident(models.CreateStatusIncidentRequest{Title: "Outage", Impact: "apocalyptic"})
+	var validationErr *StatusValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+}
@@ -3,6 +3,9 @@ package service
 import (
 	"errors"
 	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
@@ -13,11 +16,15 @@ import (
 	"constructor-script-backend/internal/repository"
 	"constructor-script-backend/internal/theme"
 	"constructor-script-backend/pkg/logger"
+	"constructor-script-backend/pkg/utils"
 )
 
 var (
 	ErrThemeManagerUnavailable = errors.New("theme manager is not configured")
 	ErrThemeNotFound           = errors.New("theme not found")
+	ErrThemeTemplateNotFound   = errors.New("theme template not found")
+	ErrInvalidThemeTemplate    = errors.New("invalid theme template")
+	ErrThemeTemplatesNotStored = errors.New("template overrides are not available")
 )
 
 const (
@@ -29,13 +36,15 @@ type ThemeService struct {
 	mu sync.Mutex
 
 	settingRepo  repository.SettingRepository
+	templateRepo repository.ThemeTemplateRepository
 	manager      *theme.Manager
 	defaultTheme string
 }
 
-func NewThemeService(settingRepo repository.SettingRepository, manager *theme.Manager, defaultTheme string) *ThemeService {
+func NewThemeService(settingRepo repository.SettingRepository, templateRepo repository.ThemeTemplateRepository, manager *theme.Manager, defaultTheme string) *ThemeService {
 	return &ThemeService{
 		settingRepo:  settingRepo,
+		templateRepo: templateRepo,
 		manager:      manager,
 		defaultTheme: strings.ToLower(strings.TrimSpace(defaultTheme)),
 	}
@@ -54,16 +63,7 @@ func (s *ThemeService) List() ([]models.ThemeInfo, error) {
 
 	results := make([]models.ThemeInfo, 0, len(themes))
 	for _, t := range themes {
-		info := models.ThemeInfo{
-			Slug:         t.Slug,
-			Name:         t.Metadata.Name,
-			Description:  t.Metadata.Description,
-			Version:      t.Metadata.Version,
-			Author:       t.Metadata.Author,
-			PreviewImage: t.Metadata.PreviewImage,
-			Active:       t.Slug == activeSlug,
-		}
-		results = append(results, info)
+		results = append(results, themeInfo(t, t.Slug == activeSlug))
 	}
 
 	return results, nil
@@ -119,17 +119,7 @@ func (s *ThemeService) Activate(slug string) (models.ThemeInfo, bool, error) {
 		}
 	}
 
-	info := models.ThemeInfo{
-		Slug:         themeCandidate.Slug,
-		Name:         themeCandidate.Metadata.Name,
-		Description:  themeCandidate.Metadata.Description,
-		Version:      themeCandidate.Metadata.Version,
-		Author:       themeCandidate.Metadata.Author,
-		PreviewImage: themeCandidate.Metadata.PreviewImage,
-		Active:       true,
-	}
-
-	return info, needsInitialization, nil
+	return themeInfo(themeCandidate, true), needsInitialization, nil
 }
 
 func (s *ThemeService) Active() (models.ThemeInfo, error) {
@@ -140,16 +130,21 @@ func (s *ThemeService) Active() (models.ThemeInfo, error) {
 	if active == nil {
 		return models.ThemeInfo{}, fmt.Errorf("%w: %s", ErrThemeNotFound, "")
 	}
-	info := models.ThemeInfo{
-		Slug:         active.Slug,
-		Name:         active.Metadata.Name,
-		Description:  active.Metadata.Description,
-		Version:      active.Metadata.Version,
-		Author:       active.Metadata.Author,
-		PreviewImage: active.Metadata.PreviewImage,
-		Active:       true,
+	return themeInfo(active, true), nil
+}
+
+func themeInfo(t *theme.Theme, active bool) models.ThemeInfo {
+	return models.ThemeInfo{
+		Slug:               t.Slug,
+		Name:               t.Metadata.Name,
+		Description:        t.Metadata.Description,
+		Version:            t.Metadata.Version,
+		Author:             t.Metadata.Author,
+		PreviewImage:       t.Metadata.PreviewImage,
+		ColorSchemes:       t.ColorSchemes(),
+		DefaultColorScheme: t.DefaultColorScheme(),
+		Active:             active,
 	}
-	return info, nil
 }
 
 func (s *ThemeService) ActiveTheme() (*theme.Theme, error) {
@@ -217,3 +212,227 @@ func (s *ThemeService) MarkInitialized(slug string) error {
 	key := settingKeyThemeInitializedBase + cleaned
 	return s.settingRepo.Set(key, time.Now().UTC().Format(time.RFC3339))
 }
+
+// ListTemplates returns the templates shipped by a theme together with their override state.
+func (s *ThemeService) ListTemplates(slug string) ([]models.ThemeTemplateInfo, error) {
+	themeValue, err := s.resolveTheme(slug)
+	if err != nil {
+		return nil, err
+	}
+
+	names, err := themeValue.TemplateNames()
+	if err != nil {
+		return nil, err
+	}
+
+	overrides, err := s.overridesByName(themeValue.Slug)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]models.ThemeTemplateInfo, 0, len(names))
+	for _, name := range names {
+		info := models.ThemeTemplateInfo{Name: name}
+		if override, ok := overrides[name]; ok {
+			updatedAt := override.UpdatedAt
+			info.Overridden = true
+			info.UpdatedAt = &updatedAt
+		}
+		results = append(results, info)
+	}
+
+	return results, nil
+}
+
+// GetTemplate returns the effective source of a theme template and the original file contents.
+func (s *ThemeService) GetTemplate(slug, name string) (*models.ThemeTemplateDetail, error) {
+	themeValue, err := s.resolveTheme(slug)
+	if err != nil {
+		return nil, err
+	}
+
+	original, err := s.readThemeTemplate(themeValue, name)
+	if err != nil {
+		return nil, err
+	}
+
+	detail := &models.ThemeTemplateDetail{
+		Theme:    themeValue.Slug,
+		Name:     name,
+		Content:  original,
+		Original: original,
+	}
+
+	if s.templateRepo == nil {
+		return detail, nil
+	}
+
+	override, err := s.templateRepo.Get(themeValue.Slug, name)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return detail, nil
+		}
+		return nil, err
+	}
+
+	updatedAt := override.UpdatedAt
+	detail.Content = override.Content
+	detail.Overridden = true
+	detail.UpdatedAt = &updatedAt
+	return detail, nil
+}
+
+// SaveTemplateOverride validates the provided template source against the rest of the
+// theme and stores it as a database override.
+func (s *ThemeService) SaveTemplateOverride(slug, name, content string) (*models.ThemeTemplateDetail, error) {
+	if s.templateRepo == nil {
+		return nil, ErrThemeTemplatesNotStored
+	}
+
+	themeValue, err := s.resolveTheme(slug)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.readThemeTemplate(themeValue, name); err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(content) == "" {
+		return nil, fmt.Errorf("%w: template content is required", ErrInvalidThemeTemplate)
+	}
+
+	if err := s.validateTemplate(themeValue, name, content); err != nil {
+		return nil, err
+	}
+
+	override := &models.ThemeTemplateOverride{
+		Theme:   themeValue.Slug,
+		Name:    name,
+		Content: content,
+	}
+	if err := s.templateRepo.Save(override); err != nil {
+		return nil, err
+	}
+
+	return s.GetTemplate(themeValue.Slug, name)
+}
+
+// DeleteTemplateOverride removes a stored override so the theme file is used again.
+func (s *ThemeService) DeleteTemplateOverride(slug, name string) error {
+	if s.templateRepo == nil {
+		return ErrThemeTemplatesNotStored
+	}
+
+	themeValue, err := s.resolveTheme(slug)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.readThemeTemplate(themeValue, name); err != nil {
+		return err
+	}
+
+	return s.templateRepo.Delete(themeValue.Slug, name)
+}
+
+// TemplateOverrides returns the stored template sources for a theme keyed by template name.
+func (s *ThemeService) TemplateOverrides(slug string) (map[string]string, error) {
+	if s == nil || s.templateRepo == nil {
+		return nil, nil
+	}
+
+	overrides, err := s.overridesByName(slug)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]string, len(overrides))
+	for name, override := range overrides {
+		result[name] = override.Content
+	}
+	return result, nil
+}
+
+func (s *ThemeService) resolveTheme(slug string) (*theme.Theme, error) {
+	if s.manager == nil {
+		return nil, ErrThemeManagerUnavailable
+	}
+
+	cleaned := strings.ToLower(strings.TrimSpace(slug))
+	if cleaned == "" {
+		return s.ActiveTheme()
+	}
+
+	themeValue, ok := s.manager.Resolve(cleaned)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrThemeNotFound, cleaned)
+	}
+	return themeValue, nil
+}
+
+func (s *ThemeService) readThemeTemplate(themeValue *theme.Theme, name string) (string, error) {
+	if !themeValue.HasTemplate(name) {
+		return "", fmt.Errorf("%w: %s", ErrThemeTemplateNotFound, name)
+	}
+
+	source, err := themeValue.ReadTemplate(name)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("%w: %s", ErrThemeTemplateNotFound, name)
+		}
+		return "", err
+	}
+	return source, nil
+}
+
+func (s *ThemeService) overridesByName(slug string) (map[string]models.ThemeTemplateOverride, error) {
+	result := make(map[string]models.ThemeTemplateOverride)
+	if s.templateRepo == nil {
+		return result, nil
+	}
+
+	overrides, err := s.templateRepo.ListByTheme(slug)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, override := range overrides {
+		result[override.Name] = override
+	}
+	return result, nil
+}
+
+// validateTemplate parses the full template set of the theme with the candidate source
+// in place so that broken overrides are rejected before they reach visitors.
+func (s *ThemeService) validateTemplate(themeValue *theme.Theme, name, content string) error {
+	tmpl := template.New("").Funcs(utils.GetTemplateFuncs(themeValue.AssetModTime))
+	tmpl, err := tmpl.ParseGlob(filepath.Join(themeValue.TemplatesDir, "*.html"))
+	if err != nil {
+		return err
+	}
+
+	overrides, err := s.overridesByName(themeValue.Slug)
+	if err != nil {
+		return err
+	}
+
+	for overrideName, override := range overrides {
+		if overrideName == name {
+			continue
+		}
+		if _, err := tmpl.New(overrideName).Parse(override.Content); err != nil {
+			logger.Warn("Stored template override no longer parses", map[string]interface{}{
+				"theme":    themeValue.Slug,
+				"template": overrideName,
+				"error":    err.Error(),
+			})
+		}
+	}
+
+	if _, err := tmpl.New(name).Parse(content); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidThemeTemplate, err)
+	}
+
+	return nil
+}
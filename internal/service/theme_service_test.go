@@ -0,0 +1,203 @@
+package service
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/repository"
+	"constructor-script-backend/internal/theme"
+)
+
+type memoryThemeTemplateRepository struct {
+	repository.ThemeTemplateRepository
+	overrides map[string]models.ThemeTemplateOverride
+}
+
+func newMemoryThemeTemplateRepository() *memoryThemeTemplateRepository {
+	return &memoryThemeTemplateRepository{overrides: make(map[string]models.ThemeTemplateOverride)}
+}
+
+func (r *memoryThemeTemplateRepository) ListByTheme(theme string) ([]models.ThemeTemplateOverride, error) {
+	var result []models.ThemeTemplateOverride
+	for _, override := range r.overrides {
+		if override.Theme == theme {
+			result = append(result, override)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+func (r *memoryThemeTemplateRepository) Get(theme, name string) (*models.ThemeTemplateOverride, error) {
+	override, ok := r.overrides[theme+"/"+name]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &override, nil
+}
+
+func (r *memoryThemeTemplateRepository) Save(override *models.ThemeTemplateOverride) error {
+	override.UpdatedAt = time.Now()
+	r.overrides[override.Theme+"/"+override.Name] = *override
+	return nil
+}
+
+func (r *memoryThemeTemplateRepository) Delete(theme, name string) error {
+	delete(r.overrides, theme+"/"+name)
+	return nil
+}
+
+// newTemplateTestThemes creates two themes sharing template names so overrides of one
+// can be told apart from the other.
+func newTemplateTestThemes(t *testing.T) *theme.Manager {
+	t.Helper()
+	root := t.TempDir()
+	for _, slug := range []string{"alpha", "beta"} {
+		dir := filepath.Join(root, slug)
+		for _, sub := range []string{"templates", "static"} {
+			if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+				t.Fatal(err)
+			}
+		}
+		files := map[string]string{
+			"theme.json":               `{"name":"` + slug + `"}`,
+			"templates/layout.html":    `<main>{{template "partial" .}}</main>`,
+			"templates/partial.html":   `{{define "partial"}}` + slug + ` partial{{end}}`,
+			"templates/not-found.html": `<p>not found</p>`,
+		}
+		for name, contents := range files {
+			if err := os.WriteFile(filepath.Join(dir, filepath.FromSlash(name)), []byte(contents), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	manager, err := theme.NewManager(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.Activate("alpha"); err != nil {
+		t.Fatal(err)
+	}
+	return manager
+}
+
+func TestSaveTemplateOverride(t *testing.T) {
+	repo := newMemoryThemeTemplateRepository()
+	svc := NewThemeService(nil, repo, newTemplateTestThemes(t), "alpha")
+
+	detail, err := svc.SaveTemplateOverride("alpha", "layout.html", `<main class="custom">{{template "partial" .}}</main>`)
+	if err != nil {
+		t.Fatalf("save override: %v", err)
+	}
+	if !detail.Overridden || detail.Content != `<main class="custom">{{template "partial" .}}</main>` {
+		t.Fatalf("expected the override to be returned, got %+v", detail)
+	}
+	if detail.Original != `<main>{{template "partial" .}}</main>` {
+		t.Fatalf("expected the theme file as the original, got %q", detail.Original)
+	}
+
+	overrides, err := svc.TemplateOverrides("alpha")
+	if err != nil {
+		t.Fatalf("template overrides: %v", err)
+	}
+	if overrides["layout.html"] != detail.Content {
+		t.Fatalf("expected the stored override to be served, got %v", overrides)
+	}
+}
+
+func TestSaveTemplateOverrideRejectsInvalidTemplates(t *testing.T) {
+	cases := []struct {
+		name     string
+		template string
+		content  string
+		want     error
+	}{
+		{"unterminated action", "layout.html", `<main>{{ if .Title }</main>`, ErrInvalidThemeTemplate},
+		{"unknown function", "layout.html", `<main>{{ missingFunc . }}</main>`, ErrInvalidThemeTemplate},
+		{"unclosed block", "partial.html", `{{define "partial"}}{{ range . }}{{end}}`, ErrInvalidThemeTemplate},
+		{"empty content", "layout.html", "   ", ErrInvalidThemeTemplate},
+		{"template outside the theme", "missing.html", `<p>hi</p>`, ErrThemeTemplateNotFound},
+		{"path traversal", "../beta/templates/layout.html", `<p>hi</p>`, ErrThemeTemplateNotFound},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := newMemoryThemeTemplateRepository()
+			svc := NewThemeService(nil, repo, newTemplateTestThemes(t), "alpha")
+
+			if _, err := svc.SaveTemplateOverride("alpha", tc.template, tc.content); !errors.Is(err, tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, err)
+			}
+			if len(repo.overrides) != 0 {
+				t.Fatalf("expected nothing to be stored, got %v", repo.overrides)
+			}
+		})
+	}
+}
+
+func TestDeleteTemplateOverrideFallsBackToThemeFile(t *testing.T) {
+	repo := newMemoryThemeTemplateRepository()
+	svc := NewThemeService(nil, repo, newTemplateTestThemes(t), "alpha")
+
+	if _, err := svc.SaveTemplateOverride("alpha", "not-found.html", `<p>gone</p>`); err != nil {
+		t.Fatalf("save override: %v", err)
+	}
+	if err := svc.DeleteTemplateOverride("alpha", "not-found.html"); err != nil {
+		t.Fatalf("delete override: %v", err)
+	}
+
+	detail, err := svc.GetTemplate("alpha", "not-found.html")
+	if err != nil {
+		t.Fatalf("get template: %v", err)
+	}
+	if detail.Overridden || detail.Content != `<p>not found</p>` {
+		t.Fatalf("expected the theme file after deleting the override, got %+v", detail)
+	}
+	if overrides, _ := svc.TemplateOverrides("alpha"); len(overrides) != 0 {
+		t.Fatalf("expected no overrides to remain, got %v", overrides)
+	}
+}
+
+func TestTemplateOverridesAreScopedPerTheme(t *testing.T) {
+	repo := newMemoryThemeTemplateRepository()
+	svc := NewThemeService(nil, repo, newTemplateTestThemes(t), "alpha")
+
+	if _, err := svc.SaveTemplateOverride("beta", "partial.html", `{{define "partial"}}custom beta{{end}}`); err != nil {
+		t.Fatalf("save override: %v", err)
+	}
+
+	alpha, err := svc.GetTemplate("alpha", "partial.html")
+	if err != nil {
+		t.Fatalf("get template: %v", err)
+	}
+	if alpha.Overridden || alpha.Content != `{{define "partial"}}alpha partial{{end}}` {
+		t.Fatalf("expected the alpha theme to keep its own template, got %+v", alpha)
+	}
+	beta, err := svc.GetTemplate("beta", "partial.html")
+	if err != nil {
+		t.Fatalf("get template: %v", err)
+	}
+	if !beta.Overridden || beta.Content != `{{define "partial"}}custom beta{{end}}` {
+		t.Fatalf("expected the beta override, got %+v", beta)
+	}
+
+	if overrides, _ := svc.TemplateOverrides("alpha"); len(overrides) != 0 {
+		t.Fatalf("expected no alpha overrides, got %v", overrides)
+	}
+	templates, err := svc.ListTemplates("beta")
+	if err != nil {
+		t.Fatalf("list templates: %v", err)
+	}
+	for _, info := range templates {
+		if info.Overridden != (info.Name == "partial.html") {
+			t.Fatalf("unexpected override state for %s: %+v", info.Name, info)
+		}
+	}
+}
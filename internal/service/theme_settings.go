@@ -0,0 +1,173 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"constructor-script-backend/internal/models"
+)
+
+const themeSettingsBundleVersion = 1
+
+var ErrInvalidThemeSettings = errors.New("invalid theme settings bundle")
+
+// themeSettingKeys lists the customizer settings that travel with a theme export.
+// Credentials and install specific values (site URL, SMTP, payments) are never included.
+var themeSettingKeys = []string{
+	settingKeySiteLogo,
+	settingKeySiteFavicon,
+	settingKeySiteFooterText,
+	settingKeySiteColorScheme,
+	settingKeySiteColorSchemeToggle,
+	settingKeySiteFonts,
+}
+
+// ExportSettings collects the settings associated with the active theme so they can
+// be imported on another install.
+func (s *ThemeService) ExportSettings(menuService *MenuService) (*models.ThemeSettingsBundle, error) {
+	active, err := s.ActiveTheme()
+	if err != nil {
+		return nil, err
+	}
+
+	bundle := &models.ThemeSettingsBundle{
+		Version:    themeSettingsBundleVersion,
+		Theme:      active.Slug,
+		ExportedAt: time.Now().UTC(),
+		Settings:   make(map[string]string),
+	}
+
+	if s.settingRepo != nil {
+		for _, key := range themeSettingKeys {
+			setting, err := s.settingRepo.Get(key)
+			if err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					continue
+				}
+				return nil, err
+			}
+			bundle.Settings[key] = setting.Value
+		}
+
+		needsInitialization, err := s.RequiresInitialization(active.Slug)
+		if err != nil {
+			return nil, err
+		}
+		bundle.Initialized = !needsInitialization
+	}
+
+	templates, err := s.TemplateOverrides(active.Slug)
+	if err != nil {
+		return nil, err
+	}
+	if len(templates) > 0 {
+		bundle.Templates = templates
+	}
+
+	if menuService != nil {
+		items, err := menuService.List()
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			bundle.Menu = append(bundle.Menu, models.ThemeSettingsMenu{
+				Title:    item.Title,
+				URL:      item.URL,
+				Location: item.Location,
+				Order:    item.Order,
+			})
+		}
+	}
+
+	return bundle, nil
+}
+
+// ImportSettings applies an exported bundle: the theme must be installed, template
+// overrides and menu items are validated before anything is written, the menu is
+// replaced when the bundle carries one and the theme is activated last. The boolean reports whether the
+// theme's default content still has to be applied.
+func (s *ThemeService) ImportSettings(bundle models.ThemeSettingsBundle, menuService *MenuService) (models.ThemeInfo, bool, error) {
+	if bundle.Version > themeSettingsBundleVersion {
+		return models.ThemeInfo{}, false, fmt.Errorf("%w: unsupported version %d", ErrInvalidThemeSettings, bundle.Version)
+	}
+
+	themeValue, err := s.resolveTheme(bundle.Theme)
+	if err != nil {
+		return models.ThemeInfo{}, false, err
+	}
+
+	allowed := make(map[string]struct{}, len(themeSettingKeys))
+	for _, key := range themeSettingKeys {
+		allowed[key] = struct{}{}
+	}
+	for key := range bundle.Settings {
+		if _, ok := allowed[key]; !ok {
+			return models.ThemeInfo{}, false, fmt.Errorf("%w: setting %s cannot be imported", ErrInvalidThemeSettings, key)
+		}
+	}
+
+	for name, content := range bundle.Templates {
+		if _, err := s.readThemeTemplate(themeValue, name); err != nil {
+			return models.ThemeInfo{}, false, err
+		}
+		if strings.TrimSpace(content) == "" {
+			return models.ThemeInfo{}, false, fmt.Errorf("%w: template %s is empty", ErrInvalidThemeTemplate, name)
+		}
+		if err := s.validateTemplate(themeValue, name, content); err != nil {
+			return models.ThemeInfo{}, false, err
+		}
+	}
+
+	if len(bundle.Settings) > 0 && s.settingRepo == nil {
+		return models.ThemeInfo{}, false, errors.New("settings repository not configured")
+	}
+
+	// The menu goes first: ReplaceAll validates every item before it removes the
+	// current menu, and swaps it in one transaction, so a bad bundle changes nothing.
+	if len(bundle.Menu) > 0 && menuService != nil {
+		items := make([]models.CreateMenuItemRequest, 0, len(bundle.Menu))
+		for _, item := range bundle.Menu {
+			order := item.Order
+			items = append(items, models.CreateMenuItemRequest{
+				Title:    strings.TrimSpace(item.Title),
+				URL:      strings.TrimSpace(item.URL),
+				Location: item.Location,
+				Order:    &order,
+			})
+		}
+		if err := menuService.ReplaceAll(items); err != nil {
+			if errors.Is(err, ErrInvalidMenuItem) {
+				err = fmt.Errorf("%w: %v", ErrInvalidThemeSettings, err)
+			}
+			return models.ThemeInfo{}, false, err
+		}
+	}
+
+	for _, key := range themeSettingKeys {
+		value, ok := bundle.Settings[key]
+		if !ok {
+			continue
+		}
+		if err := s.settingRepo.Set(key, value); err != nil {
+			return models.ThemeInfo{}, false, err
+		}
+	}
+
+	for name, content := range bundle.Templates {
+		if _, err := s.SaveTemplateOverride(themeValue.Slug, name, content); err != nil {
+			return models.ThemeInfo{}, false, err
+		}
+	}
+
+	if bundle.Initialized {
+		if err := s.MarkInitialized(themeValue.Slug); err != nil {
+			return models.ThemeInfo{}, false, err
+		}
+	}
+
+	return s.Activate(themeValue.Slug)
+}
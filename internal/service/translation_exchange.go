@@ -0,0 +1,440 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Translation exchange formats understood by translation agencies and CAT tools.
+const (
+	TranslationFormatXLIFF = "xliff"
+	TranslationFormatPO    = "po"
+)
+
+// Reasons an imported unit is held back instead of applied.
+const (
+	TranslationConflictSourceChanged = "source_changed"
+	TranslationConflictTargetChanged = "target_changed"
+)
+
+const (
+	xliffNamespace    = "urn:oasis:names:tc:xliff:document:1.2"
+	poDateLayout      = "2006-01-02 15:04-0700"
+	translationOrigin = "constructor-script"
+)
+
+// ErrInvalidTranslationFile wraps problems reading an imported translation file.
+var ErrInvalidTranslationFile = errors.New("invalid translation file")
+
+// TranslationUnit is one translatable string. IDs look like "post:12:title",
+// "menu:3:title", "site:name" or "theme:header.sign_in".
+type TranslationUnit struct {
+	ID     string `json:"id"`
+	Source string `json:"source"`
+	Target string `json:"target,omitempty"`
+	Note   string `json:"note,omitempty"`
+}
+
+// TranslationFile is the content of an XLIFF or PO file. ExportedAt lets an import
+// notice translations edited on the site after the file was handed out.
+type TranslationFile struct {
+	SourceLanguage string
+	TargetLanguage string
+	ExportedAt     time.Time
+	Units          []TranslationUnit
+}
+
+// TranslationTarget is the current state of a unit on the site.
+type TranslationTarget struct {
+	Source    string
+	Target    string
+	UpdatedAt time.Time
+}
+
+// TranslationConflict is an imported unit that was not applied because the site
+// changed since the export.
+type TranslationConflict struct {
+	ID       string `json:"id"`
+	Reason   string `json:"reason"`
+	Source   string `json:"source"`
+	Current  string `json:"current,omitempty"`
+	Imported string `json:"imported"`
+}
+
+// TranslationImportPlan sorts the units of an imported file into the ones to
+// apply and the ones to report back.
+type TranslationImportPlan struct {
+	Apply        []TranslationUnit     `json:"-"`
+	Conflicts    []TranslationConflict `json:"conflicts"`
+	Unchanged    []string              `json:"unchanged"`
+	Untranslated []string              `json:"untranslated"`
+	Unknown      []string              `json:"unknown"`
+}
+
+// DetectTranslationFormat guesses the format of an uploaded file from its name,
+// then from its content.
+func DetectTranslationFormat(name string, data []byte) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".xlf", ".xliff", ".xml":
+		return TranslationFormatXLIFF
+	case ".po", ".pot":
+		return TranslationFormatPO
+	}
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("<")) {
+		return TranslationFormatXLIFF
+	}
+	return TranslationFormatPO
+}
+
+// EncodeTranslationFile writes file in format.
+func EncodeTranslationFile(format string, file TranslationFile) ([]byte, error) {
+	switch format {
+	case TranslationFormatXLIFF:
+		return encodeXLIFF(file)
+	case TranslationFormatPO:
+		return encodePO(file), nil
+	default:
+		return nil, fmt.Errorf("unsupported translation format %q", format)
+	}
+}
+
+// DecodeTranslationFile reads a file written in format.
+func DecodeTranslationFile(format string, data []byte) (*TranslationFile, error) {
+	switch format {
+	case TranslationFormatXLIFF:
+		return decodeXLIFF(data)
+	case TranslationFormatPO:
+		return decodePO(data)
+	default:
+		return nil, fmt.Errorf("unsupported translation format %q", format)
+	}
+}
+
+// PlanTranslationImport compares the units of file with the site. A unit
+// conflicts when its source text no longer matches the site, or when the site
+// translation was edited after the export. force applies conflicting units anyway.
+func PlanTranslationImport(file TranslationFile, current map[string]TranslationTarget, force bool) TranslationImportPlan {
+	plan := TranslationImportPlan{
+		Conflicts:    []TranslationConflict{},
+		Unchanged:    []string{},
+		Untranslated: []string{},
+		Unknown:      []string{},
+	}
+
+	for _, unit := range file.Units {
+		state, ok := current[unit.ID]
+		switch {
+		case !ok:
+			plan.Unknown = append(plan.Unknown, unit.ID)
+			continue
+		case strings.TrimSpace(unit.Target) == "":
+			plan.Untranslated = append(plan.Untranslated, unit.ID)
+			continue
+		case unit.Target == state.Target:
+			plan.Unchanged = append(plan.Unchanged, unit.ID)
+			continue
+		}
+
+		reason := ""
+		if strings.TrimSpace(unit.Source) != strings.TrimSpace(state.Source) {
+			reason = TranslationConflictSourceChanged
+		} else if state.Target != "" && !file.ExportedAt.IsZero() && state.UpdatedAt.After(file.ExportedAt) {
+			reason = TranslationConflictTargetChanged
+		}
+
+		if reason != "" && !force {
+			plan.Conflicts = append(plan.Conflicts, TranslationConflict{
+				ID:       unit.ID,
+				Reason:   reason,
+				Source:   state.Source,
+				Current:  state.Target,
+				Imported: unit.Target,
+			})
+			continue
+		}
+		plan.Apply = append(plan.Apply, unit)
+	}
+
+	return plan
+}
+
+type xliffDocument struct {
+	XMLName xml.Name  `xml:"xliff"`
+	Xmlns   string    `xml:"xmlns,attr,omitempty"`
+	Version string    `xml:"version,attr"`
+	File    xliffFile `xml:"file"`
+}
+
+type xliffFile struct {
+	Original       string      `xml:"original,attr"`
+	SourceLanguage string      `xml:"source-language,attr"`
+	TargetLanguage string      `xml:"target-language,attr,omitempty"`
+	Datatype       string      `xml:"datatype,attr"`
+	Date           string      `xml:"date,attr,omitempty"`
+	Units          []xliffUnit `xml:"body>trans-unit"`
+}
+
+type xliffUnit struct {
+	ID     string `xml:"id,attr"`
+	Source string `xml:"source"`
+	Target string `xml:"target"`
+	Note   string `xml:"note,omitempty"`
+}
+
+func encodeXLIFF(file TranslationFile) ([]byte, error) {
+	document := xliffDocument{
+		Xmlns:   xliffNamespace,
+		Version: "1.2",
+		File: xliffFile{
+			Original:       translationOrigin,
+			SourceLanguage: file.SourceLanguage,
+			TargetLanguage: file.TargetLanguage,
+			Datatype:       "plaintext",
+			Units:          make([]xliffUnit, len(file.Units)),
+		},
+	}
+	if !file.ExportedAt.IsZero() {
+		document.File.Date = file.ExportedAt.UTC().Format(time.RFC3339)
+	}
+	for i, unit := range file.Units {
+		document.File.Units[i] = xliffUnit{ID: unit.ID, Source: unit.Source, Target: unit.Target, Note: unit.Note}
+	}
+
+	output, err := xml.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(output, '\n')...), nil
+}
+
+func decodeXLIFF(data []byte) (*TranslationFile, error) {
+	var document xliffDocument
+	if err := xml.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTranslationFile, err)
+	}
+	if document.Version != "" && !strings.HasPrefix(document.Version, "1.") {
+		return nil, fmt.Errorf("%w: XLIFF %s is not supported, export as XLIFF 1.2", ErrInvalidTranslationFile, document.Version)
+	}
+
+	file := &TranslationFile{
+		SourceLanguage: document.File.SourceLanguage,
+		TargetLanguage: document.File.TargetLanguage,
+		Units:          make([]TranslationUnit, 0, len(document.File.Units)),
+	}
+	if date := strings.TrimSpace(document.File.Date); date != "" {
+		if parsed, err := time.Parse(time.RFC3339, date); err == nil {
+			file.ExportedAt = parsed
+		}
+	}
+	for _, unit := range document.File.Units {
+		if unit.ID == "" {
+			continue
+		}
+		file.Units = append(file.Units, TranslationUnit{ID: unit.ID, Source: unit.Source, Target: unit.Target, Note: unit.Note})
+	}
+	return file, nil
+}
+
+func encodePO(file TranslationFile) []byte {
+	var buffer bytes.Buffer
+
+	header := []string{
+		"Content-Type: text/plain; charset=UTF-8",
+		"Content-Transfer-Encoding: 8bit",
+		"Language: " + file.TargetLanguage,
+		"X-Source-Language: " + file.SourceLanguage,
+		"X-Generator: " + translationOrigin,
+	}
+	if !file.ExportedAt.IsZero() {
+		// POT-Creation-Date only has minutes; X-Exported-At keeps the exact time
+		// conflict detection compares against.
+		header = append(header,
+			"POT-Creation-Date: "+file.ExportedAt.UTC().Format(poDateLayout),
+			"X-Exported-At: "+file.ExportedAt.UTC().Format(time.RFC3339),
+		)
+	}
+	buffer.WriteString("msgid \"\"\nmsgstr \"\"\n")
+	for _, line := range header {
+		buffer.WriteString(quotePO(line + "\n"))
+		buffer.WriteByte('\n')
+	}
+
+	for _, unit := range file.Units {
+		buffer.WriteByte('\n')
+		for _, line := range strings.Split(unit.Note, "\n") {
+			if line != "" {
+				buffer.WriteString("#. " + line + "\n")
+			}
+		}
+		writePOString(&buffer, "msgctxt", unit.ID)
+		writePOString(&buffer, "msgid", unit.Source)
+		writePOString(&buffer, "msgstr", unit.Target)
+	}
+
+	return buffer.Bytes()
+}
+
+// writePOString writes keyword and value, breaking multi-line values after each
+// newline the way gettext tools do.
+func writePOString(buffer *bytes.Buffer, keyword, value string) {
+	if !strings.Contains(value, "\n") || value == "\n" {
+		buffer.WriteString(keyword + " " + quotePO(value) + "\n")
+		return
+	}
+	buffer.WriteString(keyword + " \"\"\n")
+	for _, line := range strings.SplitAfter(value, "\n") {
+		if line != "" {
+			buffer.WriteString(quotePO(line) + "\n")
+		}
+	}
+}
+
+func quotePO(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`, "\r", `\r`)
+	return `"` + replacer.Replace(value) + `"`
+}
+
+func unquotePO(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if len(value) < 2 || value[0] != '"' || value[len(value)-1] != '"' {
+		return "", fmt.Errorf("expected a quoted string, got %s", value)
+	}
+	return strconv.Unquote(value)
+}
+
+type poEntry struct {
+	context  string
+	id       string
+	str      string
+	comments []string
+	fuzzy    bool
+	hasID    bool
+}
+
+func decodePO(data []byte) (*TranslationFile, error) {
+	file := &TranslationFile{}
+	var entries []poEntry
+	var entry poEntry
+	var field *string
+
+	flush := func() {
+		if entry.hasID {
+			entries = append(entries, entry)
+		}
+		entry = poEntry{}
+		field = nil
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 16<<20)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if lineNumber == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+
+		switch {
+		case line == "":
+			flush()
+		case strings.HasPrefix(line, "#."):
+			entry.comments = append(entry.comments, strings.TrimSpace(strings.TrimPrefix(line, "#.")))
+		case strings.HasPrefix(line, "#,"):
+			entry.fuzzy = entry.fuzzy || strings.Contains(line, "fuzzy")
+		case strings.HasPrefix(line, "#"):
+			// Translator comments, references and obsolete entries are not imported.
+		case strings.HasPrefix(line, `"`):
+			if field == nil {
+				return nil, fmt.Errorf("%w: line %d: string without a keyword", ErrInvalidTranslationFile, lineNumber)
+			}
+			value, err := unquotePO(line)
+			if err != nil {
+				return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidTranslationFile, lineNumber, err)
+			}
+			*field += value
+		default:
+			keyword, rest, _ := strings.Cut(line, " ")
+			if (keyword == "msgctxt" || keyword == "msgid") && entry.hasID && field == &entry.str {
+				// A new entry started without a blank line in between.
+				flush()
+			}
+			value, err := unquotePO(rest)
+			if err != nil {
+				return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidTranslationFile, lineNumber, err)
+			}
+			switch {
+			case keyword == "msgctxt":
+				entry.context, field = value, &entry.context
+			case keyword == "msgid":
+				entry.id, field, entry.hasID = value, &entry.id, true
+			case keyword == "msgstr" || keyword == "msgstr[0]":
+				entry.str, field = value, &entry.str
+			case strings.HasPrefix(keyword, "msgid_plural"), strings.HasPrefix(keyword, "msgstr["):
+				// Plural forms are not exported, so there is nothing to map them to.
+				var ignored string
+				field = &ignored
+			default:
+				return nil, fmt.Errorf("%w: line %d: unknown keyword %q", ErrInvalidTranslationFile, lineNumber, keyword)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTranslationFile, err)
+	}
+	flush()
+
+	for _, item := range entries {
+		if item.context == "" && item.id == "" {
+			parsePOHeader(file, item.str)
+			continue
+		}
+		if item.context == "" {
+			continue
+		}
+		target := item.str
+		if item.fuzzy {
+			// Fuzzy entries are guesses the translator has not confirmed yet.
+			target = ""
+		}
+		file.Units = append(file.Units, TranslationUnit{
+			ID:     item.context,
+			Source: item.id,
+			Target: target,
+			Note:   strings.Join(item.comments, "\n"),
+		})
+	}
+
+	return file, nil
+}
+
+func parsePOHeader(file *TranslationFile, header string) {
+	for _, line := range strings.Split(header, "\n") {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(name) {
+		case "Language":
+			file.TargetLanguage = value
+		case "X-Source-Language":
+			file.SourceLanguage = value
+		case "POT-Creation-Date":
+			if parsed, err := time.Parse(poDateLayout, value); err == nil && file.ExportedAt.IsZero() {
+				file.ExportedAt = parsed
+			}
+		case "X-Exported-At":
+			if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+				file.ExportedAt = parsed
+			}
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTranslationFileRoundTrip(t *testing.T) {
+	exported := time.Date(2026, 3, 5, 14, 30, 15, 0, time.UTC)
+	file := TranslationFile{
+		SourceLanguage: "en",
+		TargetLanguage: "es",
+		ExportedAt:     exported,
+		Units: []TranslationUnit{
+			{ID: "post:12:title", Source: `Say "hello"`, Target: `Di "hola"`, Note: "Post title"},
+			{ID: "post:12:content", Source: "<p>First</p>\n<p>Second & last</p>", Target: ""},
+			{ID: "theme:header.sign_in", Source: "Sign in", Target: "Iniciar sesión"},
+		},
+	}
+
+	for _, format := range []string{TranslationFormatXLIFF, TranslationFormatPO} {
+		data, err := EncodeTranslationFile(format, file)
+		if err != nil {
+			t.Fatalf("%s: encode: %v", format, err)
+		}
+		if detected := DetectTranslationFormat("", data); detected != format {
+			t.Fatalf("%s: detected as %s", format, detected)
+		}
+
+		decoded, err := DecodeTranslationFile(format, data)
+		if err != nil {
+			t.Fatalf("%s: decode: %v", format, err)
+		}
+		if decoded.SourceLanguage != "en" || decoded.TargetLanguage != "es" {
+			t.Fatalf("%s: unexpected languages %q -> %q", format, decoded.SourceLanguage, decoded.TargetLanguage)
+		}
+		if !decoded.ExportedAt.Equal(exported) {
+			t.Fatalf("%s: expected export time %v, got %v", format, exported, decoded.ExportedAt)
+		}
+		if len(decoded.Units) != len(file.Units) {
+			t.Fatalf("%s: expected %d units, got %d", format, len(file.Units), len(decoded.Units))
+		}
+		for i, unit := range decoded.Units {
+			if unit != file.Units[i] {
+				t.Fatalf("%s: unit %d: expected %+v, got %+v", format, i, file.Units[i], unit)
+			}
+		}
+	}
+}
+
+func TestDecodePOSkipsFuzzyEntries(t *testing.T) {
+	data := strings.Join([]string{
+		`msgid ""`,
+		`msgstr ""`,
+		`"Language: de\n"`,
+		``,
+		`#, fuzzy`,
+		`msgctxt "site:name"`,
+		`msgid "Example"`,
+		`msgstr "Beispiel"`,
+		`msgctxt "menu:4:title"`,
+		`msgid "Blog"`,
+		`msgstr "Blog DE"`,
+	}, "\n")
+
+	file, err := DecodeTranslationFile(TranslationFormatPO, []byte(data))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if file.TargetLanguage != "de" || len(file.Units) != 2 {
+		t.Fatalf("unexpected file %+v", file)
+	}
+	if file.Units[0].Target != "" {
+		t.Fatalf("fuzzy translation must not be imported, got %q", file.Units[0].Target)
+	}
+	if file.Units[1].ID != "menu:4:title" || file.Units[1].Target != "Blog DE" {
+		t.Fatalf("unexpected unit %+v", file.Units[1])
+	}
+}
+
+func TestPlanTranslationImport(t *testing.T) {
+	exported := time.Date(2026, 3, 5, 12, 0, 0, 0, time.UTC)
+	file := TranslationFile{
+		ExportedAt: exported,
+		Units: []TranslationUnit{
+			{ID: "a", Source: "One", Target: "Uno"},
+			{ID: "b", Source: "Two", Target: "Dos"},
+			{ID: "c", Source: "Three", Target: "Tres"},
+			{ID: "d", Source: "Four", Target: ""},
+			{ID: "e", Source: "Five", Target: "Cinco"},
+			{ID: "f", Source: "Six", Target: "Seis"},
+		},
+	}
+	current := map[string]TranslationTarget{
+		"a": {Source: "One"},
+		"b": {Source: "Two, edited"},
+		"c": {Source: "Three", Target: "Tres!", UpdatedAt: exported.Add(time.Hour)},
+		"d": {Source: "Four"},
+		"e": {Source: "Five", Target: "Cinco"},
+	}
+
+	plan := PlanTranslationImport(file, current, false)
+	if len(plan.Apply) != 1 || plan.Apply[0].ID != "a" {
+		t.Fatalf("expected only a to apply, got %+v", plan.Apply)
+	}
+	if len(plan.Conflicts) != 2 || plan.Conflicts[0].Reason != TranslationConflictSourceChanged || plan.Conflicts[1].Reason != TranslationConflictTargetChanged {
+		t.Fatalf("unexpected conflicts %+v", plan.Conflicts)
+	}
+	if len(plan.Untranslated) != 1 || len(plan.Unchanged) != 1 || len(plan.Unknown) != 1 {
+		t.Fatalf("unexpected plan %+v", plan)
+	}
+
+	forced := PlanTranslationImport(file, current, true)
+	if len(forced.Apply) != 3 || len(forced.Conflicts) != 0 {
+		t.Fatalf("force must apply conflicting units, got %+v", forced)
+	}
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Content types covered by the translation status report.
+const (
+	TranslationContentPage = "page"
+	TranslationContentPost = "post"
+)
+
+// TranslationItem is one post or page as seen by the translation status report.
+// An empty Language means the site default language.
+type TranslationItem struct {
+	Type           string    `json:"type"`
+	ID             uint      `json:"id"`
+	Title          string    `json:"title"`
+	Path           string    `json:"path"`
+	Language       string    `json:"language"`
+	TranslationKey string    `json:"translation_key,omitempty"`
+	Published      bool      `json:"published"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// TranslationGroupStatus describes one piece of content across languages. Source is
+// the default-language version, if there is one; translations edited before it
+// are reported as outdated.
+type TranslationGroupStatus struct {
+	Type         string                     `json:"type"`
+	Key          string                     `json:"key"`
+	Source       *TranslationItem           `json:"source,omitempty"`
+	Translations map[string]TranslationItem `json:"translations"`
+	Missing      []string                   `json:"missing"`
+	Outdated     []string                   `json:"outdated"`
+}
+
+// TranslationLanguageStatus sums up the coverage of one language.
+type TranslationLanguageStatus struct {
+	Language   string  `json:"language"`
+	Translated int     `json:"translated"`
+	Missing    int     `json:"missing"`
+	Outdated   int     `json:"outdated"`
+	Coverage   float64 `json:"coverage"`
+}
+
+// TranslationStatusReport is the admin translation dashboard.
+type TranslationStatusReport struct {
+	DefaultLanguage string                      `json:"default_language"`
+	Languages       []TranslationLanguageStatus `json:"languages"`
+	Groups          []TranslationGroupStatus    `json:"groups"`
+}
+
+// BuildTranslationStatus groups items by translation key and reports, for every
+// supported language, which content is translated, missing or outdated. Content
+// without a translation key forms a group of its own.
+func BuildTranslationStatus(defaultLanguage string, supported []string, items []TranslationItem) TranslationStatusReport {
+	report := TranslationStatusReport{
+		DefaultLanguage: defaultLanguage,
+		Languages:       make([]TranslationLanguageStatus, 0, len(supported)),
+		Groups:          []TranslationGroupStatus{},
+	}
+
+	index := make(map[string]int)
+	for _, item := range items {
+		if strings.TrimSpace(item.Language) == "" {
+			item.Language = defaultLanguage
+		}
+		key := item.TranslationKey
+		if key == "" {
+			key = fmt.Sprintf("#%d", item.ID)
+		}
+
+		groupKey := item.Type + "\x00" + key
+		position, exists := index[groupKey]
+		if !exists {
+			position = len(report.Groups)
+			index[groupKey] = position
+			report.Groups = append(report.Groups, TranslationGroupStatus{
+				Type:         item.Type,
+				Key:          key,
+				Translations: make(map[string]TranslationItem),
+				Missing:      []string{},
+				Outdated:     []string{},
+			})
+		}
+
+		group := &report.Groups[position]
+		// Keep the first item per language; duplicates are a content mistake the
+		// editor sees in the translations list anyway.
+		if _, taken := group.Translations[item.Language]; !taken {
+			group.Translations[item.Language] = item
+		}
+	}
+
+	totals := make(map[string]*TranslationLanguageStatus, len(supported))
+	for _, code := range supported {
+		report.Languages = append(report.Languages, TranslationLanguageStatus{Language: code})
+	}
+	for i := range report.Languages {
+		totals[report.Languages[i].Language] = &report.Languages[i]
+	}
+
+	for i := range report.Groups {
+		group := &report.Groups[i]
+		if source, ok := group.Translations[defaultLanguage]; ok {
+			group.Source = &source
+		}
+		for _, code := range supported {
+			total := totals[code]
+			translation, ok := group.Translations[code]
+			switch {
+			case !ok:
+				group.Missing = append(group.Missing, code)
+				total.Missing++
+			case group.Source != nil && code != defaultLanguage && translation.UpdatedAt.Before(group.Source.UpdatedAt):
+				group.Outdated = append(group.Outdated, code)
+				total.Outdated++
+				total.Translated++
+			default:
+				total.Translated++
+			}
+		}
+	}
+
+	for i := range report.Languages {
+		if len(report.Groups) > 0 {
+			report.Languages[i].Coverage = float64(report.Languages[i].Translated) / float64(len(report.Groups))
+		}
+	}
+
+	// Content with the most missing translations comes first.
+	sort.SliceStable(report.Groups, func(i, j int) bool {
+		return len(report.Groups[i].Missing) > len(report.Groups[j].Missing)
+	})
+
+	return report
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBuildTranslationStatus(t *testing.T) {
+	now := time.Now()
+	items := []TranslationItem{
+		{Type: TranslationContentPost, ID: 1, Language: "", TranslationKey: "hello", UpdatedAt: now},
+		{Type: TranslationContentPost, ID: 2, Language: "es", TranslationKey: "hello", UpdatedAt: now.Add(-time.Hour)},
+		{Type: TranslationContentPost, ID: 3, Language: "", UpdatedAt: now},
+		{Type: TranslationContentPage, ID: 1, Language: "es", TranslationKey: "hello", UpdatedAt: now},
+	}
+
+	report := BuildTranslationStatus("en", []string{"en", "es"}, items)
+	if len(report.Groups) != 3 {
+		t.Fatalf("expected three groups, got %+v", report.Groups)
+	}
+
+	byKey := make(map[string]TranslationGroupStatus)
+	for _, group := range report.Groups {
+		byKey[group.Type+":"+group.Key] = group
+	}
+	if group := byKey["post:hello"]; len(group.Missing) != 0 || len(group.Outdated) != 1 || group.Outdated[0] != "es" {
+		t.Fatalf("expected an outdated spanish post, got %+v", group)
+	}
+	if group := byKey["post:#3"]; len(group.Missing) != 1 || group.Missing[0] != "es" {
+		t.Fatalf("expected the untranslated post to miss spanish, got %+v", group)
+	}
+	if group := byKey["page:hello"]; group.Source != nil || len(group.Missing) != 1 || group.Missing[0] != "en" {
+		t.Fatalf("expected the page to miss its default version, got %+v", group)
+	}
+
+	spanish := report.Languages[1]
+	if spanish.Language != "es" || spanish.Translated != 2 || spanish.Missing != 1 || spanish.Outdated != 1 {
+		t.Fatalf("unexpected spanish totals %+v", spanish)
+	}
+}
@@ -0,0 +1,263 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"constructor-script-backend/internal/repository"
+	"constructor-script-backend/pkg/logger"
+)
+
+// UploadGCMode decides what happens to orphaned uploads.
+type UploadGCMode string
+
+const (
+	// UploadGCModeQuarantine moves orphans out of the upload directory so they can be
+	// restored, and purges them once they have sat in quarantine for a grace period.
+	UploadGCModeQuarantine UploadGCMode = "quarantine"
+	// UploadGCModeDelete removes orphans immediately.
+	UploadGCModeDelete UploadGCMode = "delete"
+
+	defaultUploadGCGracePeriod = 30 * 24 * time.Hour
+	uploadQuarantineDateLayout = "20060102"
+)
+
+var (
+	ErrUploadGCRunning     = errors.New("upload garbage collection is already running")
+	errUploadGCUnavailable = errors.New("upload garbage collection is not configured")
+)
+
+// UploadGCConfig controls upload garbage collection.
+type UploadGCConfig struct {
+	// GracePeriod protects recent uploads that may not be linked from content yet.
+	GracePeriod   time.Duration
+	Mode          UploadGCMode
+	QuarantineDir string
+}
+
+// OrphanedUpload is an upload that no content references.
+type OrphanedUpload struct {
+	URL      string    `json:"url"`
+	Filename string    `json:"filename"`
+	Size     int64     `json:"size"`
+	ModTime  time.Time `json:"mod_time"`
+	Type     string    `json:"type"`
+}
+
+// UploadGCResult reports one garbage collection pass.
+type UploadGCResult struct {
+	DryRun         bool             `json:"dry_run"`
+	Mode           UploadGCMode     `json:"mode"`
+	GracePeriod    string           `json:"grace_period"`
+	StartedAt      time.Time        `json:"started_at"`
+	Scanned        int              `json:"scanned"`
+	Referenced     int              `json:"referenced"`
+	WithinGrace    int              `json:"within_grace"`
+	Orphans        []OrphanedUpload `json:"orphans"`
+	ReclaimedBytes int64            `json:"reclaimed_bytes"`
+	Collected      int              `json:"collected"`
+	Purged         int              `json:"purged"`
+	Errors         []string         `json:"errors,omitempty"`
+}
+
+// UploadGarbageCollector finds uploads that no content references and deletes or
+// quarantines them once they are older than the grace period.
+type UploadGarbageCollector struct {
+	uploads *UploadService
+	media   repository.MediaRepository
+	config  UploadGCConfig
+
+	running sync.Mutex
+}
+
+func NewUploadGarbageCollector(uploads *UploadService, media repository.MediaRepository, config UploadGCConfig) *UploadGarbageCollector {
+	if config.GracePeriod <= 0 {
+		config.GracePeriod = defaultUploadGCGracePeriod
+	}
+	if config.Mode != UploadGCModeDelete {
+		config.Mode = UploadGCModeQuarantine
+	}
+	return &UploadGarbageCollector{uploads: uploads, media: media, config: config}
+}
+
+// Run performs a collection pass. With dryRun set it only reports what would be collected.
+func (c *UploadGarbageCollector) Run(ctx context.Context, dryRun bool) (*UploadGCResult, error) {
+	if c == nil || c.uploads == nil || c.media == nil {
+		return nil, errUploadGCUnavailable
+	}
+	if !c.running.TryLock() {
+		return nil, ErrUploadGCRunning
+	}
+	defer c.running.Unlock()
+
+	now := time.Now().UTC()
+	result := &UploadGCResult{
+		DryRun:      dryRun,
+		Mode:        c.config.Mode,
+		GracePeriod: c.config.GracePeriod.String(),
+		StartedAt:   now,
+		Orphans:     []OrphanedUpload{},
+	}
+
+	// Uploads are listed before references are collected, so a file uploaded and
+	// linked during the scan is either missing from the list or within the grace period.
+	uploads, err := c.uploads.ListUploads()
+	if err != nil {
+		return nil, err
+	}
+	references, err := c.media.UploadReferences()
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := now.Add(-c.config.GracePeriod)
+	for _, upload := range uploads {
+		if upload.Remote {
+			continue
+		}
+		result.Scanned++
+		switch {
+		case references[upload.Filename] > 0:
+			result.Referenced++
+		case upload.ModTime.After(cutoff):
+			result.WithinGrace++
+		default:
+			result.Orphans = append(result.Orphans, OrphanedUpload{
+				URL:      upload.URL,
+				Filename: upload.Filename,
+				Size:     upload.Size,
+				ModTime:  upload.ModTime,
+				Type:     upload.Type,
+			})
+			result.ReclaimedBytes += upload.Size
+		}
+	}
+	sort.Slice(result.Orphans, func(i, j int) bool { return result.Orphans[i].ModTime.Before(result.Orphans[j].ModTime) })
+
+	if dryRun {
+		return result, nil
+	}
+
+	for _, orphan := range result.Orphans {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		var err error
+		if c.config.Mode == UploadGCModeDelete {
+			err = c.uploads.DeleteUpload(orphan.Filename)
+		} else {
+			err = c.uploads.quarantineUpload(orphan.Filename, filepath.Join(c.config.QuarantineDir, now.Format(uploadQuarantineDateLayout)))
+		}
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", orphan.Filename, err))
+			continue
+		}
+		result.Collected++
+	}
+
+	if c.config.Mode == UploadGCModeQuarantine {
+		purged, err := c.purgeQuarantine(cutoff)
+		result.Purged = purged
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("purge quarantine: %v", err))
+		}
+	}
+
+	logger.Info("Upload garbage collection finished", map[string]interface{}{
+		"mode":      string(c.config.Mode),
+		"scanned":   result.Scanned,
+		"collected": result.Collected,
+		"purged":    result.Purged,
+		"bytes":     result.ReclaimedBytes,
+		"errors":    len(result.Errors),
+	})
+	return result, nil
+}
+
+// purgeQuarantine deletes quarantine folders older than cutoff and returns the number
+// of files removed.
+func (c *UploadGarbageCollector) purgeQuarantine(cutoff time.Time) (int, error) {
+	entries, err := os.ReadDir(c.config.QuarantineDir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	purged := 0
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		day, err := time.Parse(uploadQuarantineDateLayout, entry.Name())
+		if err != nil || !day.Before(cutoff) {
+			continue
+		}
+		dir := filepath.Join(c.config.QuarantineDir, entry.Name())
+		files, _ := os.ReadDir(dir)
+		if err := os.RemoveAll(dir); err != nil {
+			return purged, err
+		}
+		purged += len(files)
+	}
+	return purged, nil
+}
+
+// quarantineUpload moves an upload into dir and drops its generated variants. Stored
+// alt text and focal points are kept so a restored file gets them back.
+func (s *UploadService) quarantineUpload(filename, dir string) error {
+	filename = filepath.Base(strings.TrimSpace(filename))
+	if filename == "" || filename == "." || filename == ".." {
+		return ErrUploadNotFound
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	source := filepath.Join(s.uploadDir, filename)
+	target := filepath.Join(dir, filename)
+	if err := os.Rename(source, target); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return ErrUploadNotFound
+		}
+		// Rename fails across filesystems; fall back to copy and delete.
+		if copyErr := copyFile(source, target); copyErr != nil {
+			return copyErr
+		}
+		if err := os.Remove(source); err != nil {
+			return err
+		}
+	}
+
+	s.removeImageVariants(filename)
+	s.purgeCDN(filename, false)
+	return nil
+}
+
+func copyFile(source, target string) error {
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(target)
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestUploadGarbageCollectorQuarantinesOrphans(t *testing.T) {
+	uploadDir := t.TempDir()
+	quarantineDir := t.TempDir()
+	svc := NewUploadService(uploadDir)
+
+	old := time.Now().Add(-60 * 24 * time.Hour)
+	for _, name := range []string{"linked.png", "orphan.pdf", "fresh.png"} {
+		path := filepath.Join(uploadDir, name)
+		if err := os.WriteFile(path, []byte(name), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+		if name != "fresh.png" {
+			if err := os.Chtimes(path, old, old); err != nil {
+				t.Fatalf("chtimes %s: %v", name, err)
+			}
+		}
+	}
+
+	repo := &memoryMediaRepository{references: map[string]int{"linked.png": 2}}
+	collector := NewUploadGarbageCollector(svc, repo, UploadGCConfig{
+		GracePeriod:   7 * 24 * time.Hour,
+		QuarantineDir: quarantineDir,
+	})
+
+	preview, err := collector.Run(context.Background(), true)
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if preview.Scanned != 3 || preview.Referenced != 1 || preview.WithinGrace != 1 {
+		t.Fatalf("unexpected dry run counts: %+v", preview)
+	}
+	if len(preview.Orphans) != 1 || preview.Orphans[0].Filename != "orphan.pdf" {
+		t.Fatalf("unexpected orphans: %+v", preview.Orphans)
+	}
+	if _, err := os.Stat(filepath.Join(uploadDir, "orphan.pdf")); err != nil {
+		t.Fatalf("dry run must not move files: %v", err)
+	}
+
+	result, err := collector.Run(context.Background(), false)
+	if err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+	if result.Collected != 1 || len(result.Errors) != 0 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if _, err := os.Stat(filepath.Join(uploadDir, "orphan.pdf")); !os.IsNotExist(err) {
+		t.Fatalf("expected orphan to leave the upload directory, got %v", err)
+	}
+	quarantined := filepath.Join(quarantineDir, result.StartedAt.Format(uploadQuarantineDateLayout), "orphan.pdf")
+	if _, err := os.Stat(quarantined); err != nil {
+		t.Fatalf("expected orphan in quarantine: %v", err)
+	}
+}
@@ -12,10 +12,14 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 
+	"constructor-script-backend/internal/background"
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/repository"
 	"constructor-script-backend/pkg/logger"
 	"constructor-script-backend/pkg/media"
 	"constructor-script-backend/pkg/utils"
@@ -36,6 +40,22 @@ type UploadService struct {
 	subtitleManager       *SubtitleManager
 	subtitleConfig        SubtitleGenerationConfig
 	validateMimeType      bool
+	sanitizeImages        bool
+	scheduler             *background.Scheduler
+
+	variantMu     sync.RWMutex
+	variantConfig ImageVariantConfig
+	variantCache  map[string]*imageVariantSet
+	variantWarned sync.Map
+
+	metadataMu       sync.RWMutex
+	metadataRepo     repository.MediaRepository
+	metadataCache    map[string]models.MediaMetadata
+	metadataLoadedAt time.Time
+
+	video  videoProcessing
+	direct *DirectUploadService
+	cdn    *CDN
 }
 
 type UploadInfo struct {
@@ -44,6 +64,16 @@ type UploadInfo struct {
 	Size     int64     `json:"size"`
 	ModTime  time.Time `json:"mod_time"`
 	Type     string    `json:"type"`
+	// Variants lists the resized copies of an image, once they have been generated.
+	Variants []ImageVariant `json:"variants,omitempty"`
+	// AltText and FocalPoint are set from the media library for images.
+	AltText    string      `json:"alt_text,omitempty"`
+	FocalPoint *FocalPoint `json:"focal_point,omitempty"`
+	// DurationSeconds and PosterURL are set for videos once they have been processed.
+	DurationSeconds int    `json:"duration_seconds,omitempty"`
+	PosterURL       string `json:"poster_url,omitempty"`
+	// Remote is set for files stored in object storage rather than the upload directory.
+	Remote bool `json:"remote,omitempty"`
 }
 
 // SubtitleGenerationConfig captures the defaults applied when the upload service
@@ -120,9 +150,19 @@ func NewUploadService(uploadDir string) *UploadService {
 			"text/vtt",
 		},
 		validateMimeType: true,
+		sanitizeImages:   true,
 	}
 }
 
+// SetImageSanitization controls whether uploaded photos are stripped of EXIF, GPS and
+// other metadata and whether SVG uploads are cleaned of scripts. It is on by default.
+func (s *UploadService) SetImageSanitization(enabled bool) {
+	if s == nil {
+		return
+	}
+	s.sanitizeImages = enabled
+}
+
 // SetSubtitleGenerator attaches a subtitle generator that will run for each uploaded video.
 func (s *UploadService) SetSubtitleGenerator(generator SubtitleGenerator) {
 	if s == nil {
@@ -230,6 +270,15 @@ func (s *UploadService) UseExistingVideo(ctx context.Context, source string, pre
 		return VideoUploadResult{}, ErrUploadMissing
 	}
 
+	// Videos uploaded straight to object storage are used as they are; they are never
+	// downloaded, so there is no duration or subtitle generation for them.
+	if info, ok := s.direct.lookupURL(trimmed); ok {
+		if info.Type != string(UploadCategoryVideo) {
+			return VideoUploadResult{}, ErrUnsupportedUpload
+		}
+		return VideoUploadResult{Video: info}, nil
+	}
+
 	if parsed, err := url.Parse(trimmed); err == nil && parsed.Path != "" {
 		trimmed = parsed.Path
 	}
@@ -286,6 +335,11 @@ func (s *UploadService) UseExistingVideo(ctx context.Context, source string, pre
 	} else {
 		result.Duration = duration
 	}
+	if metadata, ok := s.VideoMetadata(result.Video.URL); ok && metadata.PosterURL != "" {
+		result.Video.applyVideoMetadata(metadata)
+	} else {
+		s.queueVideoProcessing(filename)
+	}
 
 	if s.subtitleManager != nil {
 		request := SubtitleGenerationRequest{
@@ -378,6 +432,10 @@ func (s *UploadService) DeleteUpload(current string) error {
 		return ErrUploadNotFound
 	}
 
+	if removed, err := s.direct.remove(context.Background(), filename); removed || err != nil {
+		return err
+	}
+
 	ext := strings.ToLower(filepath.Ext(filename))
 	if _, ok := s.detectCategory(ext); !ok {
 		return ErrUploadNotFound
@@ -405,6 +463,10 @@ func (s *UploadService) DeleteUpload(current string) error {
 		return err
 	}
 
+	s.removeImageVariants(filename)
+	s.removeMediaMetadata(filename)
+	s.purgeCDN(filename, false)
+
 	return nil
 }
 
@@ -614,6 +676,19 @@ func (s *UploadService) RenameUpload(current string, newName string) (UploadInfo
 		return UploadInfo{}, err
 	}
 
+	switch category {
+	case UploadCategoryImage:
+		s.removeImageVariants(filename)
+		s.queueImageVariants(newFilename)
+		s.renameMediaMetadata(filename, newFilename)
+	case UploadCategoryVideo:
+		// The poster frame lives under the old name; extract it again for the new one.
+		s.removeImageVariants(filename)
+		s.renameMediaMetadata(filename, newFilename)
+		s.queueVideoProcessing(newFilename)
+	}
+	s.purgeCDN(filename, false)
+
 	info, err := os.Stat(newAbs)
 	if err != nil {
 		return UploadInfo{}, err
@@ -657,6 +732,7 @@ func (s *UploadService) ListUploads() ([]UploadInfo, error) {
 	}
 
 	uploads := make([]UploadInfo, 0, len(entries))
+	metadata := s.loadMediaMetadata()
 
 	for _, entry := range entries {
 		if entry.IsDir() {
@@ -675,15 +751,25 @@ func (s *UploadService) ListUploads() ([]UploadInfo, error) {
 			continue
 		}
 
-		uploads = append(uploads, UploadInfo{
+		upload := UploadInfo{
 			URL:      "/uploads/" + name,
 			Filename: name,
 			Size:     info.Size(),
 			ModTime:  info.ModTime(),
 			Type:     string(category),
-		})
+		}
+		switch category {
+		case UploadCategoryImage:
+			upload.Variants = s.ImageVariants(upload.URL)
+			upload.applyMetadata(imageMetadataFromRecord(metadata[name]))
+		case UploadCategoryVideo:
+			upload.applyVideoMetadata(videoMetadataFromRecord(metadata[name]))
+		}
+		uploads = append(uploads, upload)
 	}
 
+	uploads = append(uploads, s.direct.list()...)
+
 	sort.Slice(uploads, func(i, j int) bool {
 		return uploads[i].ModTime.After(uploads[j].ModTime)
 	})
@@ -722,8 +808,22 @@ func (s *UploadService) uploadImage(file *multipart.FileHeader, preferredName st
 		return UploadInfo{}, err
 	}
 
-	info, _, err := s.persistUpload(file, preferredName, ext, s.maxSize, UploadCategoryImage)
-	return info, err
+	info, filePath, err := s.persistUpload(file, preferredName, ext, s.maxSize, UploadCategoryImage)
+	if err != nil {
+		return info, err
+	}
+
+	if s.sanitizeImages {
+		size, err := sanitizeImageFile(filePath, ext)
+		if err != nil {
+			os.Remove(filePath)
+			return UploadInfo{}, fmt.Errorf("%w: %v", ErrUnsupportedUpload, err)
+		}
+		info.Size = size
+	}
+
+	s.queueImageVariants(info.Filename)
+	return info, nil
 }
 
 func (s *UploadService) uploadVideo(ctx context.Context, file *multipart.FileHeader, preferredName string) (VideoUploadResult, error) {
@@ -761,6 +861,7 @@ func (s *UploadService) uploadVideo(ctx context.Context, file *multipart.FileHea
 		Video:    upload,
 		Duration: duration,
 	}
+	s.queueVideoProcessing(upload.Filename)
 
 	if s.subtitleManager != nil {
 		request := SubtitleGenerationRequest{
@@ -925,3 +1026,31 @@ func (s *UploadService) detectCategory(ext string) (UploadCategory, bool) {
 		return "", false
 	}
 }
+
+// sanitizeImageFile strips metadata from a stored photo, or scripts from an SVG, in
+// place and returns the resulting file size.
+func sanitizeImageFile(path, ext string) (int64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+
+	var cleaned []byte
+	if ext == ".svg" {
+		cleaned, err = media.SanitizeSVG(data)
+	} else if format, ok := media.ImageFormatFromExtension(ext); ok {
+		cleaned, err = media.StripImageMetadata(data, format)
+	} else {
+		return int64(len(data)), nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if !bytes.Equal(cleaned, data) {
+		if err := os.WriteFile(path, cleaned, 0644); err != nil {
+			return 0, err
+		}
+	}
+	return int64(len(cleaned)), nil
+}
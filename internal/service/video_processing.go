@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"constructor-script-backend/internal/background"
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/pkg/logger"
+	"constructor-script-backend/pkg/media"
+)
+
+const (
+	videoPosterName           = "poster.jpg"
+	videoProcessingJobPrefix  = "video_processing_"
+	videoProcessingJobTimeout = 5 * time.Minute
+)
+
+// VideoMetadata is what background processing extracts from an uploaded video.
+type VideoMetadata struct {
+	DurationSeconds int    `json:"duration_seconds"`
+	Width           int    `json:"width,omitempty"`
+	Height          int    `json:"height,omitempty"`
+	PosterURL       string `json:"poster_url,omitempty"`
+}
+
+// VideoProcessedFunc is called after a video's duration and poster frame have been
+// extracted. filename is the upload's name in the upload directory.
+type VideoProcessedFunc func(filename string, metadata VideoMetadata)
+
+type videoProcessing struct {
+	mu        sync.RWMutex
+	enabled   bool
+	warned    bool
+	nextID    uint64
+	listeners map[uint64]VideoProcessedFunc
+}
+
+// SetVideoProcessing enables extracting the duration and a poster frame from uploaded
+// videos with ffprobe and ffmpeg. Without those tools on PATH processing is skipped.
+func (s *UploadService) SetVideoProcessing(enabled bool) {
+	if s == nil {
+		return
+	}
+	s.video.mu.Lock()
+	s.video.enabled = enabled
+	s.video.mu.Unlock()
+}
+
+// OnVideoProcessed registers fn to run whenever a video finishes processing and
+// returns a function that removes it.
+func (s *UploadService) OnVideoProcessed(fn VideoProcessedFunc) func() {
+	if s == nil || fn == nil {
+		return func() {}
+	}
+	s.video.mu.Lock()
+	if s.video.listeners == nil {
+		s.video.listeners = make(map[uint64]VideoProcessedFunc)
+	}
+	s.video.nextID++
+	id := s.video.nextID
+	s.video.listeners[id] = fn
+	s.video.mu.Unlock()
+
+	return func() {
+		s.video.mu.Lock()
+		delete(s.video.listeners, id)
+		s.video.mu.Unlock()
+	}
+}
+
+// VideoMetadata returns the extracted details of an uploaded video, and false when
+// the video has not been processed.
+func (s *UploadService) VideoMetadata(url string) (VideoMetadata, bool) {
+	filename := s.managedFilename(url)
+	if filename == "" {
+		return VideoMetadata{}, false
+	}
+	record, ok := s.loadMediaMetadata()[filename]
+	if !ok || (record.DurationSeconds == 0 && record.PosterURL == "") {
+		return VideoMetadata{}, false
+	}
+	return videoMetadataFromRecord(record), true
+}
+
+func videoMetadataFromRecord(record models.MediaMetadata) VideoMetadata {
+	return VideoMetadata{
+		DurationSeconds: record.DurationSeconds,
+		Width:           record.Width,
+		Height:          record.Height,
+		PosterURL:       record.PosterURL,
+	}
+}
+
+func (info *UploadInfo) applyVideoMetadata(metadata VideoMetadata) {
+	info.DurationSeconds = metadata.DurationSeconds
+	info.PosterURL = metadata.PosterURL
+}
+
+// queueVideoProcessing extracts the duration and poster frame of filename outside
+// the request.
+func (s *UploadService) queueVideoProcessing(filename string) {
+	s.video.mu.Lock()
+	enabled := s.video.enabled
+	if enabled && !media.VideoToolsAvailable() {
+		if !s.video.warned {
+			s.video.warned = true
+			logger.Warn("ffprobe/ffmpeg not installed; uploaded videos will have no poster frame", nil)
+		}
+		enabled = false
+	}
+	s.video.mu.Unlock()
+	if !enabled {
+		return
+	}
+
+	s.runBackgroundJob(background.Job{
+		Name:    videoProcessingJobPrefix + filename,
+		Timeout: videoProcessingJobTimeout,
+		Run: func(ctx context.Context) error {
+			_, err := s.ProcessVideo(ctx, filename)
+			return err
+		},
+	}, "video processing", filename)
+}
+
+// ProcessVideo probes an uploaded video, writes its poster frame next to the image
+// variants, stores the result in the media library and notifies listeners.
+func (s *UploadService) ProcessVideo(ctx context.Context, filename string) (VideoMetadata, error) {
+	if s == nil {
+		return VideoMetadata{}, errUploadServiceMissing
+	}
+	filename = filepath.Base(strings.TrimSpace(filename))
+	if filename == "" || filename == "." || filename == ".." {
+		return VideoMetadata{}, ErrUploadNotFound
+	}
+	category, ok := s.detectCategory(strings.ToLower(filepath.Ext(filename)))
+	if !ok || category != UploadCategoryVideo {
+		return VideoMetadata{}, ErrUnsupportedUpload
+	}
+	source := filepath.Join(s.uploadDir, filename)
+	if _, err := os.Stat(source); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return VideoMetadata{}, ErrUploadNotFound
+		}
+		return VideoMetadata{}, err
+	}
+
+	probe, err := media.ProbeVideo(ctx, source)
+	if err != nil {
+		return VideoMetadata{}, err
+	}
+	metadata := VideoMetadata{
+		DurationSeconds: durationSeconds(probe.Duration),
+		Width:           probe.Width,
+		Height:          probe.Height,
+	}
+
+	poster := filepath.Join(s.variantDir(filename), videoPosterName)
+	if err := media.ExtractPosterFrame(ctx, source, poster, media.PosterOffset(probe.Duration)); err != nil {
+		// A missing poster is cosmetic; the duration is still worth keeping.
+		logger.Warn("Failed to extract video poster frame", map[string]interface{}{"filename": filename, "error": err.Error()})
+	} else {
+		metadata.PosterURL = s.variantURL(filename, videoPosterName)
+	}
+
+	s.saveVideoMetadata(filename, metadata)
+
+	s.video.mu.RLock()
+	listeners := make([]VideoProcessedFunc, 0, len(s.video.listeners))
+	for _, fn := range s.video.listeners {
+		listeners = append(listeners, fn)
+	}
+	s.video.mu.RUnlock()
+	for _, fn := range listeners {
+		fn(filename, metadata)
+	}
+
+	return metadata, nil
+}
+
+func (s *UploadService) saveVideoMetadata(filename string, metadata VideoMetadata) {
+	s.metadataMu.RLock()
+	repo := s.metadataRepo
+	s.metadataMu.RUnlock()
+	if repo == nil {
+		return
+	}
+
+	record, ok := s.loadMediaMetadata()[filename]
+	if !ok {
+		record = models.MediaMetadata{Filename: filename}
+	}
+	record.DurationSeconds = metadata.DurationSeconds
+	record.Width = metadata.Width
+	record.Height = metadata.Height
+	record.PosterURL = metadata.PosterURL
+	if err := repo.SaveVideo(&record); err != nil {
+		logger.Error(err, "Failed to store video metadata", map[string]interface{}{"filename": filename})
+		return
+	}
+	s.cacheMediaMetadata(record)
+}
+
+// runBackgroundJob runs job on the scheduler, or on its own goroutine when the
+// scheduler is not running.
+func (s *UploadService) runBackgroundJob(job background.Job, description, filename string) {
+	if s.scheduler != nil {
+		err := s.scheduler.ScheduleUnique(job)
+		if err == nil || errors.Is(err, background.ErrJobAlreadyScheduled) {
+			return
+		}
+		if !errors.Is(err, background.ErrSchedulerNotStarted) {
+			logger.Warn("Failed to schedule "+description, map[string]interface{}{"filename": filename, "error": err.Error()})
+			return
+		}
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), job.Timeout)
+		defer cancel()
+		if err := job.Run(ctx); err != nil {
+			logger.Error(err, "Background "+description+" failed", map[string]interface{}{"filename": filename})
+		}
+	}()
+}
+
+// durationSeconds rounds d to whole seconds, reporting at least one second for any
+// non-zero duration.
+func durationSeconds(d time.Duration) int {
+	seconds := int(math.Round(d.Seconds()))
+	if seconds <= 0 && d > 0 {
+		seconds = 1
+	}
+	return seconds
+}
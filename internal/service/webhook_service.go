@@ -0,0 +1,496 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"constructor-script-backend/internal/background"
+	"constructor-script-backend/internal/events"
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/repository"
+	"constructor-script-backend/pkg/logger"
+)
+
+var (
+	ErrInvalidWebhook               = errors.New("invalid webhook")
+	ErrWebhookRepositoryUnavailable = errors.New("webhook repository not configured")
+)
+
+var webhookEventPattern = regexp.MustCompile(`^[a-z0-9_]+(\.[a-z0-9_]+)*$`)
+
+const (
+	defaultWebhookDeliveryHistory = 50
+	webhookMaxAttempts            = 8
+	webhookRetryBaseDelay         = 30 * time.Second
+	webhookRetryMaxDelay          = time.Hour
+	webhookRequestTimeout         = 15 * time.Second
+	webhookResponseLimit          = 2048
+	webhookSignatureHeader        = "X-Webhook-Signature"
+)
+
+// WebhookService stores webhook endpoints and delivers bus events to them. Each
+// delivery is signed with the endpoint secret and retried with exponential backoff.
+type WebhookService struct {
+	repo      repository.WebhookRepository
+	scheduler *background.Scheduler
+	client    *http.Client
+}
+
+// WebhookDeliveryEnvelope is the JSON body posted to webhook endpoints.
+type WebhookDeliveryEnvelope struct {
+	Event      string                 `json:"event"`
+	OccurredAt time.Time              `json:"occurred_at"`
+	Data       map[string]interface{} `json:"data,omitempty"`
+}
+
+func NewWebhookService(repo repository.WebhookRepository, scheduler *background.Scheduler) *WebhookService {
+	if repo == nil {
+		return nil
+	}
+	return &WebhookService{
+		repo:      repo,
+		scheduler: scheduler,
+		client:    &http.Client{Timeout: webhookRequestTimeout},
+	}
+}
+
+// Subscribe starts delivering every event published on bus to the matching webhooks.
+func (s *WebhookService) Subscribe(bus *events.Bus) {
+	if s == nil || bus == nil {
+		return
+	}
+	bus.Subscribe(events.Wildcard, s.handleEvent)
+}
+
+// ResumePending queues deliveries that were still pending when the server stopped.
+func (s *WebhookService) ResumePending() {
+	if s == nil {
+		return
+	}
+
+	deliveries, err := s.repo.ListPendingDeliveries()
+	if err != nil {
+		logger.Error(err, "Failed to load pending webhook deliveries", nil)
+		return
+	}
+
+	now := time.Now().UTC()
+	for _, delivery := range deliveries {
+		delay := time.Duration(0)
+		if delivery.NextAttemptAt != nil && delivery.NextAttemptAt.After(now) {
+			delay = delivery.NextAttemptAt.Sub(now)
+		}
+		s.queue(delivery.ID, delay)
+	}
+}
+
+// EventNames lists the events webhooks can subscribe to.
+func (s *WebhookService) EventNames() []string {
+	return append([]string{events.Wildcard}, events.Names()...)
+}
+
+func (s *WebhookService) List() ([]models.Webhook, error) {
+	if s == nil || s.repo == nil {
+		return nil, ErrWebhookRepositoryUnavailable
+	}
+	return s.repo.List()
+}
+
+func (s *WebhookService) GetByID(id uint) (*models.Webhook, error) {
+	if s == nil || s.repo == nil {
+		return nil, ErrWebhookRepositoryUnavailable
+	}
+	return s.repo.GetByID(id)
+}
+
+// Create registers a webhook and returns it together with its signing secret. The
+// secret is only returned here and by RotateSecret.
+func (s *WebhookService) Create(req models.CreateWebhookRequest) (*models.Webhook, string, error) {
+	if s == nil || s.repo == nil {
+		return nil, "", ErrWebhookRepositoryUnavailable
+	}
+
+	endpoint, err := normalizeWebhookURL(req.URL)
+	if err != nil {
+		return nil, "", err
+	}
+	subscribed, err := normalizeWebhookEvents(req.Events)
+	if err != nil {
+		return nil, "", err
+	}
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		return nil, "", err
+	}
+
+	active := true
+	if req.Active != nil {
+		active = *req.Active
+	}
+
+	webhook := &models.Webhook{
+		Name:   strings.TrimSpace(req.Name),
+		URL:    endpoint,
+		Secret: secret,
+		Events: subscribed,
+		Active: active,
+	}
+
+	if err := s.repo.Create(webhook); err != nil {
+		return nil, "", err
+	}
+	return webhook, secret, nil
+}
+
+func (s *WebhookService) Update(id uint, req models.UpdateWebhookRequest) (*models.Webhook, error) {
+	if s == nil || s.repo == nil {
+		return nil, ErrWebhookRepositoryUnavailable
+	}
+
+	webhook, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		webhook.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.URL != nil {
+		endpoint, err := normalizeWebhookURL(*req.URL)
+		if err != nil {
+			return nil, err
+		}
+		webhook.URL = endpoint
+	}
+	if req.Events != nil {
+		subscribed, err := normalizeWebhookEvents(*req.Events)
+		if err != nil {
+			return nil, err
+		}
+		webhook.Events = subscribed
+	}
+	if req.Active != nil {
+		webhook.Active = *req.Active
+	}
+
+	if err := s.repo.Update(webhook); err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+// RotateSecret replaces the signing secret of a webhook and returns the new value.
+func (s *WebhookService) RotateSecret(id uint) (*models.Webhook, string, error) {
+	if s == nil || s.repo == nil {
+		return nil, "", ErrWebhookRepositoryUnavailable
+	}
+
+	webhook, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, "", err
+	}
+
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		return nil, "", err
+	}
+	webhook.Secret = secret
+
+	if err := s.repo.Update(webhook); err != nil {
+		return nil, "", err
+	}
+	return webhook, secret, nil
+}
+
+func (s *WebhookService) Delete(id uint) error {
+	if s == nil || s.repo == nil {
+		return ErrWebhookRepositoryUnavailable
+	}
+	if _, err := s.repo.GetByID(id); err != nil {
+		return err
+	}
+	return s.repo.Delete(id)
+}
+
+// Deliveries returns the most recent deliveries of a webhook.
+func (s *WebhookService) Deliveries(webhookID uint, limit int) ([]models.WebhookDelivery, error) {
+	if s == nil || s.repo == nil {
+		return nil, ErrWebhookRepositoryUnavailable
+	}
+	if _, err := s.repo.GetByID(webhookID); err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > 500 {
+		limit = defaultWebhookDeliveryHistory
+	}
+	return s.repo.ListDeliveries(webhookID, limit)
+}
+
+// Redeliver queues a new delivery with the payload of an earlier one.
+func (s *WebhookService) Redeliver(webhookID, deliveryID uint) (*models.WebhookDelivery, error) {
+	if s == nil || s.repo == nil {
+		return nil, ErrWebhookRepositoryUnavailable
+	}
+
+	original, err := s.repo.GetDelivery(deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if original.WebhookID != webhookID {
+		return nil, fmt.Errorf("%w: delivery does not belong to webhook", ErrInvalidWebhook)
+	}
+
+	delivery := &models.WebhookDelivery{
+		WebhookID: original.WebhookID,
+		Event:     original.Event,
+		Payload:   original.Payload,
+		Status:    models.WebhookDeliveryPending,
+	}
+	if err := s.repo.CreateDelivery(delivery); err != nil {
+		return nil, err
+	}
+
+	s.queue(delivery.ID, 0)
+	return delivery, nil
+}
+
+func (s *WebhookService) handleEvent(_ context.Context, event events.Event) {
+	webhooks, err := s.repo.ListActive()
+	if err != nil {
+		logger.Error(err, "Failed to load webhooks", map[string]interface{}{"event": event.Name})
+		return
+	}
+
+	var payload []byte
+	for _, webhook := range webhooks {
+		if !webhookSubscribed(webhook.Events, event.Name) {
+			continue
+		}
+
+		if payload == nil {
+			payload, err = json.Marshal(WebhookDeliveryEnvelope{
+				Event:      event.Name,
+				OccurredAt: event.OccurredAt,
+				Data:       event.Payload,
+			})
+			if err != nil {
+				logger.Error(err, "Failed to encode webhook payload", map[string]interface{}{"event": event.Name})
+				return
+			}
+		}
+
+		delivery := &models.WebhookDelivery{
+			WebhookID: webhook.ID,
+			Event:     event.Name,
+			Payload:   string(payload),
+			Status:    models.WebhookDeliveryPending,
+		}
+		if err := s.repo.CreateDelivery(delivery); err != nil {
+			logger.Error(err, "Failed to record webhook delivery", map[string]interface{}{"webhook": webhook.ID, "event": event.Name})
+			continue
+		}
+		s.queue(delivery.ID, 0)
+	}
+}
+
+// queue hands the delivery to the background scheduler once delay has elapsed. The
+// wait happens outside the scheduler so pending retries don't occupy its workers.
+func (s *WebhookService) queue(deliveryID uint, delay time.Duration) {
+	if delay > 0 {
+		time.AfterFunc(delay, func() { s.queue(deliveryID, 0) })
+		return
+	}
+
+	job := background.Job{
+		Name:    fmt.Sprintf("webhook_delivery_%d", deliveryID),
+		Timeout: webhookRequestTimeout + 5*time.Second,
+		Run: func(ctx context.Context) error {
+			return s.deliver(ctx, deliveryID)
+		},
+	}
+
+	if s.scheduler != nil {
+		err := s.scheduler.ScheduleUnique(job)
+		if err == nil || errors.Is(err, background.ErrJobAlreadyScheduled) {
+			return
+		}
+		if !errors.Is(err, background.ErrSchedulerNotStarted) {
+			logger.Warn("Failed to schedule webhook delivery", map[string]interface{}{"delivery": deliveryID, "error": err.Error()})
+			return
+		}
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), job.Timeout)
+		defer cancel()
+		if err := job.Run(ctx); err != nil {
+			logger.Error(err, "Webhook delivery failed", map[string]interface{}{"delivery": deliveryID})
+		}
+	}()
+}
+
+func (s *WebhookService) deliver(ctx context.Context, deliveryID uint) error {
+	delivery, err := s.repo.GetDelivery(deliveryID)
+	if err != nil {
+		return err
+	}
+	if delivery.Status != models.WebhookDeliveryPending {
+		return nil
+	}
+
+	webhook, err := s.repo.GetByID(delivery.WebhookID)
+	if err != nil || !webhook.Active {
+		delivery.Status = models.WebhookDeliveryFailed
+		delivery.Error = "webhook is deleted or inactive"
+		delivery.NextAttemptAt = nil
+		return s.repo.UpdateDelivery(delivery)
+	}
+
+	delivery.Attempts++
+	status, body, sendErr := s.send(ctx, webhook, delivery)
+	delivery.ResponseStatus = status
+	delivery.ResponseBody = body
+	now := time.Now().UTC()
+
+	if sendErr == nil {
+		delivery.Status = models.WebhookDeliverySucceeded
+		delivery.Error = ""
+		delivery.NextAttemptAt = nil
+		delivery.DeliveredAt = &now
+		return s.repo.UpdateDelivery(delivery)
+	}
+
+	delivery.Error = sendErr.Error()
+	if delivery.Attempts >= webhookMaxAttempts {
+		delivery.Status = models.WebhookDeliveryFailed
+		delivery.NextAttemptAt = nil
+		logger.Warn("Webhook delivery failed permanently", map[string]interface{}{
+			"webhook":  webhook.ID,
+			"delivery": delivery.ID,
+			"event":    delivery.Event,
+			"error":    delivery.Error,
+		})
+		return s.repo.UpdateDelivery(delivery)
+	}
+
+	delay := webhookRetryDelay(delivery.Attempts)
+	next := now.Add(delay)
+	delivery.NextAttemptAt = &next
+	if err := s.repo.UpdateDelivery(delivery); err != nil {
+		return err
+	}
+
+	s.queue(delivery.ID, delay)
+	return nil
+}
+
+func (s *WebhookService) send(ctx context.Context, webhook *models.Webhook, delivery *models.WebhookDelivery) (int, string, error) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader([]byte(delivery.Payload)))
+	if err != nil {
+		return 0, "", err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "constructor-script-webhooks")
+	request.Header.Set("X-Webhook-Event", delivery.Event)
+	request.Header.Set("X-Webhook-Delivery", strconv.FormatUint(uint64(delivery.ID), 10))
+	request.Header.Set("X-Webhook-Timestamp", timestamp)
+	request.Header.Set(webhookSignatureHeader, SignWebhookPayload(webhook.Secret, timestamp, []byte(delivery.Payload)))
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		return 0, "", err
+	}
+	defer response.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(response.Body, webhookResponseLimit))
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return response.StatusCode, string(body), fmt.Errorf("endpoint responded with status %d", response.StatusCode)
+	}
+	return response.StatusCode, string(body), nil
+}
+
+// SignWebhookPayload returns the signature header value for a delivery: the hex
+// HMAC-SHA256 of "<timestamp>.<body>" keyed with the webhook secret.
+func SignWebhookPayload(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func webhookRetryDelay(attempts int) time.Duration {
+	delay := webhookRetryBaseDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= webhookRetryMaxDelay {
+			return webhookRetryMaxDelay
+		}
+	}
+	return delay
+}
+
+func webhookSubscribed(subscribed []string, name string) bool {
+	for _, candidate := range subscribed {
+		if candidate == events.Wildcard || candidate == name {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeWebhookURL(value string) (string, error) {
+	trimmed := strings.TrimSpace(value)
+	parsed, err := url.Parse(trimmed)
+	if err != nil || parsed.Host == "" || (parsed.Scheme != "https" && parsed.Scheme != "http") {
+		return "", fmt.Errorf("%w: url must be an absolute http(s) URL", ErrInvalidWebhook)
+	}
+	return parsed.String(), nil
+}
+
+func normalizeWebhookEvents(values []string) (models.StringList, error) {
+	seen := make(map[string]struct{}, len(values))
+	result := make(models.StringList, 0, len(values))
+	for _, value := range values {
+		name := strings.ToLower(strings.TrimSpace(value))
+		if name == "" {
+			continue
+		}
+		if name != events.Wildcard && !webhookEventPattern.MatchString(name) {
+			return nil, fmt.Errorf("%w: invalid event name %q", ErrInvalidWebhook, value)
+		}
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		result = append(result, name)
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("%w: at least one event is required", ErrInvalidWebhook)
+	}
+	return result, nil
+}
+
+func generateWebhookSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
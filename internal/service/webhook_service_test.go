@@ -0,0 +1,52 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSignWebhookPayload(t *testing.T) {
+	// Reference value: printf '1700000000.{"event":"page.created"}' | openssl dgst -sha256 -hmac secret
+	got := SignWebhookPayload("secret", "1700000000", []byte(`{"event":"page.created"}`))
+	want := "sha256=92874cace106af3544d62647866b04bf77b1d124b5802f92188c74a418011e82"
+	if got != want {
+		t.Fatalf("expected %s, got %s", want, got)
+	}
+	if other := SignWebhookPayload("other", "1700000000", []byte(`{"event":"page.created"}`)); other == got {
+		t.Fatalf("signature must depend on the secret")
+	}
+	if other := SignWebhookPayload("secret", "1700000001", []byte(`{"event":"page.created"}`)); other == got {
+		t.Fatalf("signature must depend on the timestamp")
+	}
+}
+
+func TestWebhookRetryDelay(t *testing.T) {
+	cases := map[int]time.Duration{
+		1:  30 * time.Second,
+		2:  time.Minute,
+		3:  2 * time.Minute,
+		12: time.Hour,
+	}
+	for attempts, want := range cases {
+		if got := webhookRetryDelay(attempts); got != want {
+			t.Fatalf("attempt %d: expected %s, got %s", attempts, want, got)
+		}
+	}
+}
+
+func TestNormalizeWebhookEvents(t *testing.T) {
+	got, err := normalizeWebhookEvents([]string{" Page.Created ", "page.created", "*"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 2 || got[0] != "page.created" || got[1] != "*" {
+		t.Fatalf("unexpected events: %v", got)
+	}
+
+	if _, err := normalizeWebhookEvents([]string{"bad event"}); err == nil {
+		t.Fatalf("expected invalid event name to be rejected")
+	}
+	if _, err := normalizeWebhookEvents(nil); err == nil {
+		t.Fatalf("expected empty subscription to be rejected")
+	}
+}
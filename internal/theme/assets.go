@@ -0,0 +1,276 @@
+package theme
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const fingerprintLength = 10
+
+// cssImportPattern matches the URL of an @import rule in plain or url() form.
+var cssImportPattern = regexp.MustCompile(`(@import\s*(?:url\(\s*)?["']?)([^"'()\s;]+)`)
+
+// AssetPipeline holds content-hashed names for the static files of a theme. CSS and
+// JavaScript are minified once at load time and served from memory.
+type AssetPipeline struct {
+	fingerprinted map[string]string
+	entries       map[string]*pipelineAsset
+}
+
+type pipelineAsset struct {
+	sourcePath string
+	name       string
+	modTime    time.Time
+	content    []byte
+}
+
+func buildAssetPipeline(staticDir string, minify bool) (*AssetPipeline, error) {
+	pipeline := &AssetPipeline{
+		fingerprinted: make(map[string]string),
+		entries:       make(map[string]*pipelineAsset),
+	}
+	assets := make(map[string]*pipelineAsset)
+	contents := make(map[string][]byte)
+
+	err := filepath.WalkDir(staticDir, func(fullPath string, entry fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if entry.IsDir() {
+			return nil
+		}
+
+		relative, err := filepath.Rel(staticDir, fullPath)
+		if err != nil {
+			return err
+		}
+		relative = filepath.ToSlash(relative)
+
+		data, err := os.ReadFile(fullPath)
+		if err != nil {
+			return err
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		asset := &pipelineAsset{
+			sourcePath: fullPath,
+			name:       path.Base(relative),
+			modTime:    info.ModTime(),
+		}
+
+		if minify {
+			switch strings.ToLower(path.Ext(relative)) {
+			case ".css":
+				data = []byte(minifyCSS(string(data)))
+				asset.content = data
+			case ".js":
+				if !strings.HasSuffix(strings.ToLower(relative), ".min.js") {
+					data = []byte(minifyJS(string(data)))
+					asset.content = data
+				}
+			}
+		}
+
+		assets[relative] = asset
+		contents[relative] = data
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return pipeline, nil
+		}
+		return nil, err
+	}
+
+	names := make([]string, 0, len(assets))
+	for relative := range assets {
+		names = append(names, relative)
+	}
+	sort.Strings(names)
+	for _, relative := range names {
+		pipeline.fingerprint(relative, assets, contents, make(map[string]bool))
+	}
+
+	return pipeline, nil
+}
+
+// fingerprint hashes an asset once its dependencies are hashed. Stylesheets have
+// their @import URLs rewritten to the fingerprinted names first, so changing an
+// imported file also changes the name of every stylesheet importing it.
+func (p *AssetPipeline) fingerprint(relative string, assets map[string]*pipelineAsset, contents map[string][]byte, visiting map[string]bool) string {
+	if hashed, ok := p.fingerprinted[relative]; ok {
+		return hashed
+	}
+
+	asset := assets[relative]
+	data := contents[relative]
+
+	if strings.EqualFold(path.Ext(relative), ".css") {
+		visiting[relative] = true
+		rewritten := cssImportPattern.ReplaceAllStringFunc(string(data), func(match string) string {
+			parts := cssImportPattern.FindStringSubmatch(match)
+			target, ok := importTarget(relative, parts[2])
+			// Imports closing a cycle are left as they are.
+			if !ok || assets[target] == nil || visiting[target] {
+				return match
+			}
+			return parts[1] + fingerprintURL(parts[2], p.fingerprint(target, assets, contents, visiting))
+		})
+		delete(visiting, relative)
+
+		if rewritten != string(data) {
+			data = []byte(rewritten)
+			asset.content = data
+		}
+	}
+
+	sum := sha256.Sum256(data)
+	hashed := fingerprintPath(relative, hex.EncodeToString(sum[:])[:fingerprintLength])
+
+	p.fingerprinted[relative] = hashed
+	p.entries[hashed] = asset
+	return hashed
+}
+
+// importTarget resolves an @import URL of the stylesheet at relative to a path
+// relative to the static directory. External and data URLs are not resolved.
+func importTarget(relative, rawURL string) (string, bool) {
+	if idx := strings.IndexAny(rawURL, "?#"); idx >= 0 {
+		rawURL = rawURL[:idx]
+	}
+	if rawURL == "" || strings.HasPrefix(rawURL, "//") || strings.Contains(rawURL, ":") {
+		return "", false
+	}
+	if strings.HasPrefix(rawURL, "/") {
+		return staticRelativePath(rawURL)
+	}
+	target := path.Clean(path.Join(path.Dir(relative), rawURL))
+	if target == ".." || strings.HasPrefix(target, "../") {
+		return "", false
+	}
+	return target, true
+}
+
+// fingerprintURL replaces the file name of rawURL with the one of hashed, keeping
+// its directory part and dropping any query string.
+func fingerprintURL(rawURL, hashed string) string {
+	if idx := strings.IndexAny(rawURL, "?#"); idx >= 0 {
+		rawURL = rawURL[:idx]
+	}
+	return rawURL[:strings.LastIndex(rawURL, "/")+1] + path.Base(hashed)
+}
+
+func fingerprintPath(relative, hash string) string {
+	ext := path.Ext(relative)
+	base := strings.TrimSuffix(relative, ext)
+	return base + "." + hash + ext
+}
+
+// Resolve maps a public /static path to its fingerprinted equivalent.
+func (p *AssetPipeline) Resolve(publicPath string) (string, bool) {
+	if p == nil {
+		return "", false
+	}
+
+	relative, ok := staticRelativePath(publicPath)
+	if !ok {
+		return "", false
+	}
+
+	hashed, ok := p.fingerprinted[relative]
+	if !ok {
+		return "", false
+	}
+	return "/static/" + hashed, true
+}
+
+// IsFingerprinted reports whether the path (relative to /static) is a hashed asset name.
+func (p *AssetPipeline) IsFingerprinted(name string) bool {
+	if p == nil {
+		return false
+	}
+	_, ok := p.entries[strings.TrimPrefix(path.Clean("/"+name), "/")]
+	return ok
+}
+
+// Open serves a fingerprinted asset, returning fs.ErrNotExist for unknown names.
+func (p *AssetPipeline) Open(name string) (http.File, error) {
+	if p == nil {
+		return nil, fs.ErrNotExist
+	}
+
+	asset, ok := p.entries[strings.TrimPrefix(path.Clean("/"+name), "/")]
+	if !ok {
+		return nil, fs.ErrNotExist
+	}
+
+	if asset.content == nil {
+		return os.Open(asset.sourcePath)
+	}
+
+	return &memoryFile{
+		Reader: bytes.NewReader(asset.content),
+		info: memoryFileInfo{
+			name:    asset.name,
+			size:    int64(len(asset.content)),
+			modTime: asset.modTime,
+		},
+	}, nil
+}
+
+func staticRelativePath(publicPath string) (string, bool) {
+	cleaned := strings.TrimSpace(publicPath)
+	if idx := strings.IndexAny(cleaned, "?#"); idx >= 0 {
+		cleaned = cleaned[:idx]
+	}
+	cleaned = strings.TrimPrefix(cleaned, "./")
+	cleaned = strings.TrimPrefix(cleaned, "/")
+	if !strings.HasPrefix(cleaned, "static/") {
+		return "", false
+	}
+	return strings.TrimPrefix(path.Clean("/"+strings.TrimPrefix(cleaned, "static/")), "/"), true
+}
+
+type memoryFile struct {
+	*bytes.Reader
+	info memoryFileInfo
+}
+
+func (f *memoryFile) Close() error {
+	return nil
+}
+
+func (f *memoryFile) Readdir(int) ([]fs.FileInfo, error) {
+	return nil, errors.New("not a directory")
+}
+
+func (f *memoryFile) Stat() (fs.FileInfo, error) {
+	return f.info, nil
+}
+
+type memoryFileInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+}
+
+func (i memoryFileInfo) Name() string       { return i.name }
+func (i memoryFileInfo) Size() int64        { return i.size }
+func (i memoryFileInfo) Mode() fs.FileMode  { return 0o444 }
+func (i memoryFileInfo) ModTime() time.Time { return i.modTime }
+func (i memoryFileInfo) IsDir() bool        { return false }
+func (i memoryFileInfo) Sys() interface{}   { return nil }
@@ -0,0 +1,121 @@
+package theme
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMinifyCSSPreservesStrings(t *testing.T) {
+	source := "/* header */\n.a  {\n  content: \"a  b\";\n  color: red;\n}\n"
+
+	got := minifyCSS(source)
+	want := `.a{content:"a  b";color:red}`
+	if got != want {
+		t.Fatalf("unexpected minified css: got %q, want %q", got, want)
+	}
+}
+
+func TestMinifyJSKeepsTemplateLiterals(t *testing.T) {
+	source := "function f() {\n    const s = `line one\n    line two`;\n\n    return s;\n}\n"
+
+	got := minifyJS(source)
+	want := "function f() {\nconst s = `line one\n    line two`;\nreturn s;\n}"
+	if got != want {
+		t.Fatalf("unexpected minified js: got %q, want %q", got, want)
+	}
+}
+
+func TestAssetPipelineResolvesFingerprintedPaths(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "css"), 0o755); err != nil {
+		t.Fatalf("failed to create css dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "css", "main.css"), []byte("body {\n  margin: 0;\n}\n"), 0o644); err != nil {
+		t.Fatalf("failed to write css: %v", err)
+	}
+
+	pipeline, err := buildAssetPipeline(dir, true)
+	if err != nil {
+		t.Fatalf("failed to build pipeline: %v", err)
+	}
+
+	resolved, ok := pipeline.Resolve("/static/css/main.css?v=1")
+	if !ok {
+		t.Fatalf("expected css asset to be fingerprinted")
+	}
+	if !strings.HasPrefix(resolved, "/static/css/main.") || !strings.HasSuffix(resolved, ".css") {
+		t.Fatalf("unexpected fingerprinted path %q", resolved)
+	}
+
+	name := strings.TrimPrefix(resolved, "/static/")
+	if !pipeline.IsFingerprinted(name) {
+		t.Fatalf("expected %q to be reported as fingerprinted", name)
+	}
+
+	file, err := pipeline.Open(name)
+	if err != nil {
+		t.Fatalf("failed to open fingerprinted asset: %v", err)
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		t.Fatalf("failed to read asset: %v", err)
+	}
+	if string(content) != "body{margin:0}" {
+		t.Fatalf("unexpected asset content %q", content)
+	}
+
+	if _, ok := pipeline.Resolve("/static/css/missing.css"); ok {
+		t.Fatalf("expected unknown asset to be unresolved")
+	}
+}
+
+func TestAssetPipelineFingerprintsImportedStylesheets(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "css", "parts"), 0o755); err != nil {
+		t.Fatalf("failed to create css dir: %v", err)
+	}
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, "css", name), []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	write("main.css", "@import url(\"parts/base.css?v=2\");\n@import 'https://fonts.example/font.css';\nbody { margin: 0; }\n")
+	write("parts/base.css", "@import \"/static/css/main.css\";\nh1 { color: red; }\n")
+
+	for _, minify := range []bool{true, false} {
+		pipeline, err := buildAssetPipeline(dir, minify)
+		if err != nil {
+			t.Fatalf("failed to build pipeline: %v", err)
+		}
+		main, _ := pipeline.Resolve("/static/css/main.css")
+		base, _ := pipeline.Resolve("/static/css/parts/base.css")
+
+		file, err := pipeline.Open(strings.TrimPrefix(main, "/static/"))
+		if err != nil {
+			t.Fatalf("failed to open %q: %v", main, err)
+		}
+		content, _ := io.ReadAll(file)
+		file.Close()
+
+		if !strings.Contains(string(content), "parts/"+filepath.Base(base)+`"`) {
+			t.Fatalf("expected the import to use %q, got %q", base, content)
+		}
+		if !strings.Contains(string(content), "https://fonts.example/font.css") {
+			t.Fatalf("expected external imports to be kept, got %q", content)
+		}
+	}
+
+	pipeline, _ := buildAssetPipeline(dir, true)
+	before, _ := pipeline.Resolve("/static/css/main.css")
+	write("parts/base.css", "h1 { color: blue; }\n")
+	pipeline, _ = buildAssetPipeline(dir, true)
+	after, _ := pipeline.Resolve("/static/css/main.css")
+	if before == after {
+		t.Fatalf("expected changing an imported stylesheet to change %q", before)
+	}
+}
@@ -0,0 +1,161 @@
+package theme
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	ColorSchemeLight  = "light"
+	ColorSchemeDark   = "dark"
+	ColorSchemeSystem = "system"
+)
+
+var (
+	colorSchemeNamePattern   = regexp.MustCompile(`^[a-z][a-z0-9-]{0,31}$`)
+	colorSchemeVarPattern    = regexp.MustCompile(`^--[a-zA-Z0-9-]{1,64}$`)
+	colorSchemeUnsafeContent = regexp.MustCompile(`[;{}<>\\]`)
+)
+
+// ColorSchemeVariant describes a palette variant declared in theme.json. Variables are
+// emitted as CSS custom properties scoped to the matching data-theme attribute.
+type ColorSchemeVariant struct {
+	Label     string            `json:"label"`
+	Variables map[string]string `json:"variables,omitempty"`
+}
+
+// NormalizeColorScheme lowercases and trims a scheme name, returning an empty string
+// when the value is not a valid identifier.
+func NormalizeColorScheme(value string) string {
+	cleaned := strings.ToLower(strings.TrimSpace(value))
+	if cleaned == ColorSchemeSystem || colorSchemeNamePattern.MatchString(cleaned) {
+		return cleaned
+	}
+	return ""
+}
+
+// ColorSchemes returns the palette variants supported by the theme. Themes that do not
+// declare any variants are assumed to ship light and dark styles.
+func (t *Theme) ColorSchemes() []string {
+	if t == nil || len(t.Metadata.ColorSchemes) == 0 {
+		return []string{ColorSchemeLight, ColorSchemeDark}
+	}
+
+	names := make([]string, 0, len(t.Metadata.ColorSchemes))
+	for name := range t.Metadata.ColorSchemes {
+		if normalized := NormalizeColorScheme(name); normalized != "" && normalized != ColorSchemeSystem {
+			names = append(names, normalized)
+		}
+	}
+
+	sort.Slice(names, func(i, j int) bool {
+		return colorSchemeRank(names[i]) < colorSchemeRank(names[j]) ||
+			(colorSchemeRank(names[i]) == colorSchemeRank(names[j]) && names[i] < names[j])
+	})
+	return names
+}
+
+// SupportsColorScheme reports whether the scheme can be applied to this theme. The
+// "system" scheme is supported whenever both light and dark variants exist.
+func (t *Theme) SupportsColorScheme(scheme string) bool {
+	normalized := NormalizeColorScheme(scheme)
+	if normalized == "" {
+		return false
+	}
+
+	available := t.ColorSchemes()
+	if normalized == ColorSchemeSystem {
+		return containsScheme(available, ColorSchemeLight) && containsScheme(available, ColorSchemeDark)
+	}
+	return containsScheme(available, normalized)
+}
+
+// DefaultColorScheme returns the scheme declared by the theme, falling back to the
+// visitor's system preference when both light and dark variants are available.
+func (t *Theme) DefaultColorScheme() string {
+	if t != nil {
+		if declared := NormalizeColorScheme(t.Metadata.DefaultColorScheme); declared != "" && t.SupportsColorScheme(declared) {
+			return declared
+		}
+	}
+
+	if t.SupportsColorScheme(ColorSchemeSystem) {
+		return ColorSchemeSystem
+	}
+
+	if available := t.ColorSchemes(); len(available) > 0 {
+		return available[0]
+	}
+	return ColorSchemeLight
+}
+
+// ColorSchemeCSS renders the custom properties declared for each palette variant.
+func (t *Theme) ColorSchemeCSS() string {
+	if t == nil || len(t.Metadata.ColorSchemes) == 0 {
+		return ""
+	}
+
+	var builder strings.Builder
+	for _, name := range t.ColorSchemes() {
+		variant, ok := t.lookupColorScheme(name)
+		if !ok || len(variant.Variables) == 0 {
+			continue
+		}
+
+		keys := make([]string, 0, len(variant.Variables))
+		for key := range variant.Variables {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		declarations := make([]string, 0, len(keys))
+		for _, key := range keys {
+			value := strings.TrimSpace(variant.Variables[key])
+			if !colorSchemeVarPattern.MatchString(key) || value == "" || colorSchemeUnsafeContent.MatchString(value) {
+				continue
+			}
+			declarations = append(declarations, key+": "+value+";")
+		}
+		if len(declarations) == 0 {
+			continue
+		}
+
+		builder.WriteString(`:root[data-theme="`)
+		builder.WriteString(name)
+		builder.WriteString(`"] {`)
+		builder.WriteString(strings.Join(declarations, " "))
+		builder.WriteString("}\n")
+	}
+
+	return builder.String()
+}
+
+func (t *Theme) lookupColorScheme(name string) (ColorSchemeVariant, bool) {
+	for key, variant := range t.Metadata.ColorSchemes {
+		if NormalizeColorScheme(key) == name {
+			return variant, true
+		}
+	}
+	return ColorSchemeVariant{}, false
+}
+
+func colorSchemeRank(name string) int {
+	switch name {
+	case ColorSchemeLight:
+		return 0
+	case ColorSchemeDark:
+		return 1
+	default:
+		return 2
+	}
+}
+
+func containsScheme(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
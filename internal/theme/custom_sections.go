@@ -0,0 +1,98 @@
+package theme
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// CustomSections returns the section definitions that the theme renders from its own
+// template files, ordered the same way as the builder palette.
+func (t *Theme) CustomSections() []SectionDefinition {
+	if t == nil {
+		return nil
+	}
+
+	result := make([]SectionDefinition, 0)
+	for _, definition := range t.sections {
+		if strings.TrimSpace(definition.Template) == "" {
+			continue
+		}
+		result = append(result, definition)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Order == result[j].Order {
+			return result[i].Type < result[j].Type
+		}
+		return result[i].Order < result[j].Order
+	})
+	return result
+}
+
+// ReadSectionTemplate returns the source of the template file declared by a custom
+// section definition.
+func (t *Theme) ReadSectionTemplate(definition SectionDefinition) (string, error) {
+	if t == nil {
+		return "", os.ErrNotExist
+	}
+
+	relative, err := cleanSectionTemplatePath(definition.Template)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := os.ReadFile(filepath.Join(t.TemplatesDir, filepath.FromSlash(relative)))
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// SectionTemplateName returns the name under which a custom section template is
+// registered in the page template set.
+func SectionTemplateName(definition SectionDefinition) string {
+	relative, err := cleanSectionTemplatePath(definition.Template)
+	if err != nil {
+		return ""
+	}
+	return relative
+}
+
+func validateSectionTemplates(templatesDir string, definitions map[string]SectionDefinition) error {
+	for sectionType, definition := range definitions {
+		if strings.TrimSpace(definition.Template) == "" {
+			continue
+		}
+
+		relative, err := cleanSectionTemplatePath(definition.Template)
+		if err != nil {
+			return fmt.Errorf("section %s: %w", sectionType, err)
+		}
+
+		info, err := os.Stat(filepath.Join(templatesDir, filepath.FromSlash(relative)))
+		if err != nil || info.IsDir() {
+			return fmt.Errorf("section %s: template %s not found", sectionType, relative)
+		}
+	}
+	return nil
+}
+
+func cleanSectionTemplatePath(value string) (string, error) {
+	trimmed := strings.TrimSpace(filepath.ToSlash(value))
+	if trimmed == "" {
+		return "", fmt.Errorf("section template path is empty")
+	}
+
+	cleaned := path.Clean(trimmed)
+	if path.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", fmt.Errorf("section template %s must be inside the templates directory", value)
+	}
+	if !strings.HasSuffix(cleaned, ".html") {
+		return "", fmt.Errorf("section template %s must be an .html file", value)
+	}
+	return cleaned, nil
+}
@@ -0,0 +1,82 @@
+package theme
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTestTheme(t *testing.T, manifest string, files map[string]string) string {
+	t.Helper()
+
+	base := t.TempDir()
+	themePath := filepath.Join(base, "custom")
+	for _, dir := range []string{"templates", "static"} {
+		if err := os.MkdirAll(filepath.Join(themePath, dir), 0o755); err != nil {
+			t.Fatalf("failed to create %s: %v", dir, err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(themePath, "theme.json"), []byte(manifest), 0o644); err != nil {
+		t.Fatalf("failed to write manifest: %v", err)
+	}
+	for name, content := range files {
+		full := filepath.Join(themePath, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatalf("failed to create dir for %s: %v", name, err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	return themePath
+}
+
+func TestLoadThemeRegistersManifestSections(t *testing.T) {
+	manifest := `{"sections": [{"type": "Testimonials", "label": "Testimonials", "template": "sections/testimonials.html", "settings": {"quote": {"label": "Quote", "type": "text"}}}]}`
+	themePath := writeTestTheme(t, manifest, map[string]string{
+		"templates/sections/testimonials.html": `<blockquote>{{ index .Settings "quote" }}</blockquote>`,
+	})
+
+	loaded, err := loadTheme(themePath, "custom")
+	if err != nil {
+		t.Fatalf("failed to load theme: %v", err)
+	}
+
+	custom := loaded.CustomSections()
+	if len(custom) != 1 || custom[0].Type != "testimonials" {
+		t.Fatalf("expected testimonials custom section, got %+v", custom)
+	}
+	if _, ok := loaded.SectionDefinitions()["testimonials"].Settings["quote"]; !ok {
+		t.Fatalf("expected section settings to be exposed as builder definitions")
+	}
+	if name := SectionTemplateName(custom[0]); name != "sections/testimonials.html" {
+		t.Fatalf("unexpected template name %q", name)
+	}
+
+	source, err := loaded.ReadSectionTemplate(custom[0])
+	if err != nil {
+		t.Fatalf("failed to read section template: %v", err)
+	}
+	if !strings.Contains(source, "<blockquote>") {
+		t.Fatalf("unexpected section template source %q", source)
+	}
+}
+
+func TestLoadThemeRejectsMissingSectionTemplate(t *testing.T) {
+	manifest := `{"sections": [{"type": "pricing", "template": "sections/pricing.html"}]}`
+	themePath := writeTestTheme(t, manifest, nil)
+
+	if _, err := loadTheme(themePath, "custom"); err == nil {
+		t.Fatalf("expected error for missing section template")
+	}
+}
+
+func TestLoadThemeRejectsSectionTemplateOutsideTemplates(t *testing.T) {
+	manifest := `{"sections": [{"type": "pricing", "template": "../theme.json"}]}`
+	themePath := writeTestTheme(t, manifest, nil)
+
+	if _, err := loadTheme(themePath, "custom"); err == nil {
+		t.Fatalf("expected error for template outside the templates directory")
+	}
+}
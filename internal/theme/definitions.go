@@ -13,7 +13,9 @@ import (
 )
 
 // SectionDefinition describes a section type that can be used by the content builder
-// and validated by the backend.
+// and validated by the backend. Template is a path relative to the theme templates
+// directory; sections that declare one are rendered from that file instead of a
+// built-in Go renderer.
 type SectionDefinition struct {
 	Type                string                              `json:"type"`
 	Label               string                              `json:"label,omitempty"`
@@ -23,6 +25,8 @@ type SectionDefinition struct {
 	SupportsElements    *bool                               `json:"supports_elements,omitempty"`
 	SupportsHeaderImage *bool                               `json:"supports_header_image,omitempty"`
 	Settings            map[string]SectionSettingDefinition `json:"settings,omitempty"`
+	Template            string                              `json:"template,omitempty"`
+	Scripts             []string                            `json:"scripts,omitempty"`
 }
 
 // AllowedElementSet returns the normalised set of element types permitted for the section.
@@ -164,6 +168,12 @@ func mergeSectionDefinition(base, override SectionDefinition) SectionDefinition
 	if override.AllowedElements != nil {
 		result.AllowedElements = normaliseElementTypes(override.AllowedElements)
 	}
+	if override.Template != "" {
+		result.Template = override.Template
+	}
+	if override.Scripts != nil {
+		result.Scripts = append([]string(nil), override.Scripts...)
+	}
 
 	if len(override.Settings) > 0 {
 		if result.Settings == nil {
@@ -482,7 +492,7 @@ func defaultSectionDefinitions() map[string]SectionDefinition {
 			Label:            "Standard section",
 			Order:            0,
 			Description:      "Flexible content area for combining paragraphs, media, and lists.",
-			AllowedElements:  normaliseElementTypes([]string{"paragraph", "image", "image_group", "list", "file_group", "search"}),
+			AllowedElements:  normaliseElementTypes([]string{"paragraph", "image", "image_group", "embed", "list", "file_group", "search"}),
 			SupportsElements: &standardSupports,
 		},
 		"features": {
@@ -552,7 +562,7 @@ func defaultSectionDefinitions() map[string]SectionDefinition {
 			Label:            "Grid section",
 			Order:            15,
 			Description:      "Displays content blocks in a responsive grid layout.",
-			AllowedElements:  normaliseElementTypes([]string{"paragraph", "image", "image_group", "list", "file_group"}),
+			AllowedElements:  normaliseElementTypes([]string{"paragraph", "image", "image_group", "embed", "list", "file_group"}),
 			SupportsElements: &standardSupports,
 		},
 		"file_list": {
@@ -693,6 +703,12 @@ func defaultElementDefinitions() map[string]ElementDefinition {
 			Order:       30,
 			Description: "Collection of related images displayed together.",
 		},
+		"embed": {
+			Type:        "embed",
+			Label:       "Embed",
+			Order:       35,
+			Description: "YouTube, Vimeo or X (Twitter) link shown as a privacy-friendly preview.",
+		},
 		"list": {
 			Type:        "list",
 			Label:       "List",
@@ -723,6 +739,12 @@ func defaultElementDefinitions() map[string]ElementDefinition {
 			Order:       70,
 			Description: "Password update form for the profile page.",
 		},
+		"profile_notifications": {
+			Type:        "profile_notifications",
+			Label:       "Notification preferences",
+			Order:       80,
+			Description: "Lets users choose which notifications they get by email and on the site.",
+		},
 	}
 }
 
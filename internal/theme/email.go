@@ -0,0 +1,37 @@
+package theme
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const emailTemplatesDir = "emails"
+
+// EmailTemplatesPath returns the directory holding the theme's HTML email layouts.
+func (t *Theme) EmailTemplatesPath() string {
+	if t == nil {
+		return ""
+	}
+	return filepath.Join(t.TemplatesDir, emailTemplatesDir)
+}
+
+// ReadEmailTemplate returns the source of templates/emails/<name>.html. The boolean is
+// false when the theme does not ship the template so callers can fall back to the
+// built-in defaults.
+func (t *Theme) ReadEmailTemplate(name string) (string, bool) {
+	if t == nil {
+		return "", false
+	}
+
+	cleaned := strings.TrimSuffix(strings.TrimSpace(name), ".html")
+	if cleaned == "" || cleaned != filepath.Base(cleaned) {
+		return "", false
+	}
+
+	data, err := os.ReadFile(filepath.Join(t.EmailTemplatesPath(), cleaned+".html"))
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
@@ -25,6 +25,12 @@ func NewFileSystem(manager *Manager, siteDir string) http.FileSystem {
 }
 
 func (f *FileSystem) Open(name string) (http.File, error) {
+	if manager := f.manager.Load(); manager != nil {
+		if theme := manager.Active(); theme != nil && theme.pipeline.IsFingerprinted(name) {
+			return theme.pipeline.Open(name)
+		}
+	}
+
 	if f.siteFS != nil {
 		file, err := f.siteFS.Open(name)
 		if err == nil {
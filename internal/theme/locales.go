@@ -0,0 +1,186 @@
+package theme
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"constructor-script-backend/pkg/lang"
+)
+
+// fallbackLocale is the language theme strings fall back to when neither the
+// requested nor the site default language has a locale file.
+const fallbackLocale = "en"
+
+// Strings holds the UI strings of a theme keyed like "header.sign_in". Templates
+// read them with {{ .T.Get "header.sign_in" }}.
+type Strings map[string]string
+
+// Get returns the string stored under key, or the key itself so a missing string
+// shows up on the page instead of rendering blank.
+func (s Strings) Get(key string) string {
+	if value, ok := s[key]; ok && value != "" {
+		return value
+	}
+	return key
+}
+
+// loadLocales reads locales/<language>.json from the theme directory. A theme
+// without locale files has no strings.
+func loadLocales(themePath string) (map[string]Strings, error) {
+	entries, err := os.ReadDir(filepath.Join(themePath, "locales"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	locales := make(map[string]Strings)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.EqualFold(filepath.Ext(name), ".json") {
+			continue
+		}
+
+		code, err := lang.Normalize(strings.TrimSuffix(name, filepath.Ext(name)))
+		if err != nil {
+			return nil, fmt.Errorf("locale %s: %w", name, err)
+		}
+
+		data, err := os.ReadFile(filepath.Join(themePath, "locales", name))
+		if err != nil {
+			return nil, err
+		}
+
+		var values Strings
+		if err := json.Unmarshal(data, &values); err != nil {
+			return nil, fmt.Errorf("locale %s: %w", name, err)
+		}
+		locales[code] = values
+	}
+
+	return locales, nil
+}
+
+// Locales lists the languages the theme ships strings for.
+func (t *Theme) Locales() []string {
+	if t == nil {
+		return nil
+	}
+	t.localesMu.RLock()
+	defer t.localesMu.RUnlock()
+	return t.localeCodes()
+}
+
+func (t *Theme) localeCodes() []string {
+	codes := make([]string, 0, len(t.locales))
+	for code := range t.locales {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// Strings returns the theme strings for language. Keys the language lacks are
+// taken from the site default language, then from English.
+func (t *Theme) Strings(language, defaultLanguage string) Strings {
+	result := make(Strings)
+	if t == nil {
+		return result
+	}
+	t.localesMu.RLock()
+	defer t.localesMu.RUnlock()
+	if len(t.locales) == 0 {
+		return result
+	}
+
+	available := t.localeCodes()
+	for _, code := range []string{fallbackLocale, defaultLanguage, language} {
+		if code == "" {
+			continue
+		}
+		match := lang.Match(code, available)
+		if match == "" {
+			continue
+		}
+		for key, value := range t.locales[match] {
+			if value != "" {
+				result[key] = value
+			}
+		}
+	}
+
+	return result
+}
+
+// LocaleStrings returns a copy of the strings the locale file of language holds,
+// without falling back to other languages.
+func (t *Theme) LocaleStrings(language string) Strings {
+	result := make(Strings)
+	if t == nil {
+		return result
+	}
+	t.localesMu.RLock()
+	defer t.localesMu.RUnlock()
+	for key, value := range t.locales[language] {
+		result[key] = value
+	}
+	return result
+}
+
+// SaveStrings merges values into locales/<language>.json and serves them right
+// away. Blank values are ignored.
+func (t *Theme) SaveStrings(language string, values Strings) error {
+	if t == nil {
+		return errors.New("theme not loaded")
+	}
+	code, err := lang.Normalize(language)
+	if err != nil {
+		return err
+	}
+
+	t.localesMu.Lock()
+	defer t.localesMu.Unlock()
+
+	merged := make(Strings, len(t.locales[code])+len(values))
+	for key, value := range t.locales[code] {
+		merged[key] = value
+	}
+	for key, value := range values {
+		if strings.TrimSpace(value) != "" {
+			merged[key] = value
+		}
+	}
+
+	data, err := json.MarshalIndent(merged, "", "    ")
+	if err != nil {
+		return err
+	}
+	dir := filepath.Join(t.Path, "locales")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, code+".json"), append(data, '\n'), 0o644); err != nil {
+		return err
+	}
+
+	if t.locales == nil {
+		t.locales = make(map[string]Strings)
+	}
+	t.locales[code] = merged
+	return nil
+}
+
+// RTLStylesheets lists the stylesheets the theme loads on right-to-left pages,
+// as declared by "rtl_stylesheets" in theme.json.
+func (t *Theme) RTLStylesheets() []string {
+	if t == nil {
+		return nil
+	}
+	return t.Metadata.RTLStylesheets
+}
@@ -0,0 +1,69 @@
+package theme
+
+import "testing"
+
+func TestThemeStringsFallBack(t *testing.T) {
+	themePath := writeTestTheme(t, `{}`, map[string]string{
+		"locales/en.json": `{"header.sign_in": "Sign in", "header.profile": "Profile"}`,
+		"locales/es.json": `{"header.sign_in": "Iniciar sesión"}`,
+	})
+
+	loaded, err := loadTheme(themePath, "custom")
+	if err != nil {
+		t.Fatalf("load theme: %v", err)
+	}
+
+	texts := loaded.Strings("es-MX", "en")
+	if got := texts.Get("header.sign_in"); got != "Iniciar sesión" {
+		t.Fatalf("expected Spanish string, got %q", got)
+	}
+	if got := texts.Get("header.profile"); got != "Profile" {
+		t.Fatalf("expected English fallback, got %q", got)
+	}
+	if got := texts.Get("header.missing"); got != "header.missing" {
+		t.Fatalf("expected key for missing string, got %q", got)
+	}
+
+	if got := loaded.Strings("fr", "en").Get("header.sign_in"); got != "Sign in" {
+		t.Fatalf("expected default language for unknown locale, got %q", got)
+	}
+}
+
+func TestThemeStringsRejectInvalidLocale(t *testing.T) {
+	themePath := writeTestTheme(t, `{}`, map[string]string{
+		"locales/not a language.json": `{}`,
+	})
+
+	if _, err := loadTheme(themePath, "custom"); err == nil {
+		t.Fatal("expected an error for an invalid locale file name")
+	}
+}
+
+func TestThemeSaveStrings(t *testing.T) {
+	themePath := writeTestTheme(t, `{}`, map[string]string{
+		"locales/en.json": `{"header.sign_in": "Sign in", "header.profile": "Profile"}`,
+	})
+
+	loaded, err := loadTheme(themePath, "custom")
+	if err != nil {
+		t.Fatalf("load theme: %v", err)
+	}
+	if err := loaded.SaveStrings("de", Strings{"header.sign_in": "Anmelden", "header.profile": " "}); err != nil {
+		t.Fatalf("save strings: %v", err)
+	}
+
+	if got := loaded.Strings("de", "en").Get("header.sign_in"); got != "Anmelden" {
+		t.Fatalf("expected saved string to be served, got %q", got)
+	}
+	if _, ok := loaded.LocaleStrings("de")["header.profile"]; ok {
+		t.Fatal("blank values must not be saved")
+	}
+
+	reloaded, err := loadTheme(themePath, "custom")
+	if err != nil {
+		t.Fatalf("reload theme: %v", err)
+	}
+	if got := reloaded.LocaleStrings("de")["header.sign_in"]; got != "Anmelden" {
+		t.Fatalf("expected saved string on disk, got %q", got)
+	}
+}
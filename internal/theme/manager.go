@@ -3,6 +3,7 @@ package theme
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
@@ -14,14 +15,18 @@ import (
 )
 
 type Metadata struct {
-	Name                  string `json:"name"`
-	Description           string `json:"description"`
-	Version               string `json:"version"`
-	Author                string `json:"author"`
-	PreviewImage          string `json:"preview_image"`
-	DefaultLogo           string `json:"default_logo"`
-	DefaultFavicon        string `json:"default_favicon"`
-	DefaultSectionPadding *int   `json:"default_section_padding,omitempty"`
+	Name                  string                        `json:"name"`
+	Description           string                        `json:"description"`
+	Version               string                        `json:"version"`
+	Author                string                        `json:"author"`
+	PreviewImage          string                        `json:"preview_image"`
+	DefaultLogo           string                        `json:"default_logo"`
+	DefaultFavicon        string                        `json:"default_favicon"`
+	DefaultSectionPadding *int                          `json:"default_section_padding,omitempty"`
+	ColorSchemes          map[string]ColorSchemeVariant `json:"color_schemes,omitempty"`
+	DefaultColorScheme    string                        `json:"default_color_scheme,omitempty"`
+	Sections              []SectionDefinition           `json:"sections,omitempty"`
+	RTLStylesheets        []string                      `json:"rtl_stylesheets,omitempty"`
 }
 
 type Theme struct {
@@ -33,7 +38,10 @@ type Theme struct {
 	Metadata     Metadata
 	sections     map[string]SectionDefinition
 	elements     map[string]ElementDefinition
+	locales      map[string]Strings
+	localesMu    sync.RWMutex
 	assets       BuilderAssets
+	pipeline     *AssetPipeline
 }
 
 type Manager struct {
@@ -137,6 +145,63 @@ func (m *Manager) Resolve(slug string) (*Theme, bool) {
 	return theme, ok
 }
 
+// EnableAssetPipeline fingerprints (and optionally minifies) the static assets of every
+// loaded theme so templates can reference long-lived, content-hashed URLs.
+func (m *Manager) EnableAssetPipeline(minify bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for slug, theme := range m.themes {
+		pipeline, err := buildAssetPipeline(theme.StaticDir, minify)
+		if err != nil {
+			return fmt.Errorf("failed to build asset pipeline for theme %s: %w", slug, err)
+		}
+		theme.pipeline = pipeline
+	}
+
+	return nil
+}
+
+// AssetURL returns the fingerprinted URL for a static asset of the active theme.
+func (m *Manager) AssetURL(path string) (string, bool) {
+	m.mu.RLock()
+	theme := m.active
+	m.mu.RUnlock()
+
+	if theme == nil {
+		return "", false
+	}
+	return theme.pipeline.Resolve(path)
+}
+
+// IsFingerprintedAsset reports whether a path relative to /static is a content-hashed
+// asset of the active theme and therefore safe to cache indefinitely.
+func (m *Manager) IsFingerprintedAsset(name string) bool {
+	m.mu.RLock()
+	theme := m.active
+	m.mu.RUnlock()
+
+	if theme == nil {
+		return false
+	}
+	return theme.pipeline.IsFingerprinted(name)
+}
+
+// IsThemeAssetFingerprinted reports whether "<slug>/<path>" names a content-hashed
+// asset of an installed theme served under ThemeAssetsPrefix.
+func (m *Manager) IsThemeAssetFingerprinted(name string) bool {
+	slug, rest, found := strings.Cut(strings.TrimPrefix(name, "/"), "/")
+	if !found {
+		return false
+	}
+
+	theme, ok := m.Resolve(slug)
+	if !ok {
+		return false
+	}
+	return theme.IsFingerprintedAsset(rest)
+}
+
 func (m *Manager) AssetModTime(path string) (time.Time, error) {
 	m.mu.RLock()
 	theme := m.active
@@ -176,6 +241,12 @@ func loadTheme(themePath, slug string) (*Theme, error) {
 	if err != nil {
 		return nil, err
 	}
+	applySectionDefinitions(sectionDefinitions, metadata.Sections)
+
+	locales, err := loadLocales(themePath)
+	if err != nil {
+		return nil, fmt.Errorf("theme %s: %w", slug, err)
+	}
 
 	theme := &Theme{
 		Slug:         slugValue,
@@ -186,6 +257,7 @@ func loadTheme(themePath, slug string) (*Theme, error) {
 		Metadata:     metadata,
 		sections:     sectionDefinitions,
 		elements:     elementDefinitions,
+		locales:      locales,
 		assets:       discoverBuilderAssets(filepath.Join(themePath, "static")),
 	}
 
@@ -197,6 +269,10 @@ func loadTheme(themePath, slug string) (*Theme, error) {
 		return nil, errors.New("theme missing static directory: " + slug)
 	}
 
+	if err := validateSectionTemplates(theme.TemplatesDir, sectionDefinitions); err != nil {
+		return nil, fmt.Errorf("theme %s: %w", slug, err)
+	}
+
 	return theme, nil
 }
 
@@ -287,6 +363,34 @@ func (t *Theme) TemplateNames() ([]string, error) {
 	return names, nil
 }
 
+// HasTemplate reports whether the theme ships a template file with the given name.
+func (t *Theme) HasTemplate(name string) bool {
+	names, err := t.TemplateNames()
+	if err != nil {
+		return false
+	}
+	for _, candidate := range names {
+		if candidate == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadTemplate returns the on-disk source of a single theme template.
+func (t *Theme) ReadTemplate(name string) (string, error) {
+	cleaned := strings.TrimSpace(name)
+	if cleaned == "" || cleaned != filepath.Base(cleaned) || !strings.HasSuffix(cleaned, ".html") {
+		return "", os.ErrNotExist
+	}
+
+	data, err := os.ReadFile(filepath.Join(t.TemplatesDir, cleaned))
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
 func (t *Theme) TemplatesPath() string {
 	return t.TemplatesDir
 }
@@ -0,0 +1,158 @@
+package theme
+
+import (
+	"strings"
+)
+
+// minifyCSS removes comments and collapses insignificant whitespace. String literals
+// and url() arguments are copied verbatim.
+func minifyCSS(source string) string {
+	var out strings.Builder
+	out.Grow(len(source))
+
+	pendingSpace := false
+	writeSpace := func() {
+		if !pendingSpace {
+			return
+		}
+		pendingSpace = false
+		if out.Len() == 0 {
+			return
+		}
+		last := out.String()[out.Len()-1]
+		if strings.IndexByte("{};:,>(", last) >= 0 {
+			return
+		}
+		out.WriteByte(' ')
+	}
+
+	for i := 0; i < len(source); i++ {
+		ch := source[i]
+
+		switch {
+		case ch == '/' && i+1 < len(source) && source[i+1] == '*':
+			end := strings.Index(source[i+2:], "*/")
+			if end < 0 {
+				return out.String()
+			}
+			i += end + 3
+			pendingSpace = true
+		case ch == '"' || ch == '\'':
+			writeSpace()
+			end := scanQuoted(source, i)
+			out.WriteString(source[i:end])
+			i = end - 1
+		case ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r' || ch == '\f':
+			pendingSpace = true
+		case strings.IndexByte("{};,>)", ch) >= 0:
+			pendingSpace = false
+			if ch == '}' {
+				trimTrailingSemicolon(&out)
+			}
+			out.WriteByte(ch)
+		default:
+			writeSpace()
+			out.WriteByte(ch)
+		}
+	}
+
+	return strings.TrimSpace(out.String())
+}
+
+// minifyJS performs a conservative whitespace pass: indentation, trailing whitespace
+// and blank lines are removed outside of template literals while line breaks are
+// preserved so automatic semicolon insertion keeps working. Comments are left in
+// place because distinguishing them from regular expression literals requires a
+// full parser.
+func minifyJS(source string) string {
+	lines := strings.Split(strings.ReplaceAll(source, "\r\n", "\n"), "\n")
+	out := make([]string, 0, len(lines))
+
+	inTemplate := false
+	inBlockComment := false
+	for _, line := range lines {
+		current := line
+		if !inTemplate {
+			current = strings.TrimSpace(current)
+			if current == "" {
+				continue
+			}
+		} else {
+			current = strings.TrimRight(current, " \t")
+		}
+
+		out = append(out, current)
+		inTemplate, inBlockComment = scanJSLine(current, inTemplate, inBlockComment)
+	}
+
+	return strings.Join(out, "\n")
+}
+
+// scanJSLine tracks whether a template literal or block comment remains open at the
+// end of the line.
+func scanJSLine(line string, inTemplate, inBlockComment bool) (bool, bool) {
+	for i := 0; i < len(line); i++ {
+		ch := line[i]
+
+		if inBlockComment {
+			if ch == '*' && i+1 < len(line) && line[i+1] == '/' {
+				inBlockComment = false
+				i++
+			}
+			continue
+		}
+
+		if inTemplate {
+			switch ch {
+			case '\\':
+				i++
+			case '`':
+				inTemplate = false
+			}
+			continue
+		}
+
+		switch ch {
+		case '/':
+			if i+1 < len(line) {
+				if line[i+1] == '/' {
+					return inTemplate, inBlockComment
+				}
+				if line[i+1] == '*' {
+					inBlockComment = true
+					i++
+				}
+			}
+		case '"', '\'':
+			i = scanQuoted(line, i) - 1
+		case '`':
+			inTemplate = true
+		}
+	}
+
+	return inTemplate, inBlockComment
+}
+
+// scanQuoted returns the index just past the closing quote that matches source[start].
+func scanQuoted(source string, start int) int {
+	quote := source[start]
+	for i := start + 1; i < len(source); i++ {
+		switch source[i] {
+		case '\\':
+			i++
+		case quote:
+			return i + 1
+		case '\n':
+			return i
+		}
+	}
+	return len(source)
+}
+
+func trimTrailingSemicolon(out *strings.Builder) {
+	current := out.String()
+	if strings.HasSuffix(current, ";") {
+		out.Reset()
+		out.WriteString(strings.TrimSuffix(current, ";"))
+	}
+}
@@ -0,0 +1,68 @@
+package theme
+
+import (
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ThemeAssetsPrefix is the public URL prefix for static files of installed themes
+// that are not currently active. Pages assigned to another theme load their assets
+// from /theme-assets/<slug>/...
+const ThemeAssetsPrefix = "/theme-assets/"
+
+// AssetURL maps a /static path to this theme's own asset URL. The boolean is false when
+// the theme does not ship the file so callers can fall back to the site-wide static
+// directory.
+func (t *Theme) AssetURL(publicPath string) (string, bool) {
+	if t == nil {
+		return "", false
+	}
+
+	if hashed, ok := t.pipeline.Resolve(publicPath); ok {
+		return ThemeAssetsPrefix + t.Slug + "/" + strings.TrimPrefix(hashed, "/static/"), true
+	}
+
+	relative, ok := staticRelativePath(publicPath)
+	if !ok || relative == "" || relative == "." {
+		return "", false
+	}
+
+	info, err := os.Stat(filepath.Join(t.StaticDir, filepath.FromSlash(relative)))
+	if err != nil || info.IsDir() {
+		return "", false
+	}
+
+	return fmt.Sprintf("%s%s/%s?v=%d", ThemeAssetsPrefix, t.Slug, relative, info.ModTime().Unix()), true
+}
+
+// StaticFS serves the theme's static directory, including fingerprinted assets.
+func (t *Theme) StaticFS() http.FileSystem {
+	return themeStaticFS{theme: t}
+}
+
+// IsFingerprintedAsset reports whether name (relative to the theme static root) is a
+// content-hashed asset.
+func (t *Theme) IsFingerprintedAsset(name string) bool {
+	if t == nil {
+		return false
+	}
+	return t.pipeline.IsFingerprinted(name)
+}
+
+type themeStaticFS struct {
+	theme *Theme
+}
+
+func (f themeStaticFS) Open(name string) (http.File, error) {
+	if f.theme == nil {
+		return nil, fs.ErrNotExist
+	}
+	if f.theme.pipeline.IsFingerprinted(name) {
+		return f.theme.pipeline.Open(name)
+	}
+	return http.Dir(f.theme.StaticDir).Open(name)
+}
@@ -0,0 +1,7 @@
+// Package version exposes the version of the running CMS build. Release builds set
+// it with -ldflags "-X constructor-script-backend/internal/version.Version=<version>".
+package version
+
+// Version is the semantic version of the core. Plugins declare compatibility with it
+// through the "requires" field of their manifest.
+var Version = "1.0.0"
@@ -0,0 +1,124 @@
+// Package apierror defines the error envelope of the API and its codes.
+//
+// Every error response under /api carries the envelope:
+//
+//	{"error": "Post not found", "code": "not_found", "request_id": "…"}
+//
+// error stays a human-readable message, as it always was, so existing clients
+// keep working; code is the stable, machine-readable part. Invalid request bodies
+// add details, one entry per rejected field.
+package apierror
+
+import (
+	"net/http"
+	"sort"
+)
+
+// Code identifies a kind of error independently of its message.
+type Code string
+
+const (
+	CodeBadRequest         Code = "bad_request"
+	CodeValidationFailed   Code = "validation_failed"
+	CodeUnauthorized       Code = "unauthorized"
+	CodeForbidden          Code = "forbidden"
+	CodeNotFound           Code = "not_found"
+	CodeMethodNotAllowed   Code = "method_not_allowed"
+	CodeConflict           Code = "conflict"
+	CodeGone               Code = "gone"
+	CodePreconditionFailed Code = "precondition_failed"
+	CodePayloadTooLarge    Code = "payload_too_large"
+	CodeUnsupportedMedia   Code = "unsupported_media_type"
+	CodeUnprocessable      Code = "unprocessable"
+	CodeRateLimited        Code = "rate_limited"
+	CodeInternal           Code = "internal_error"
+	CodeNotImplemented     Code = "not_implemented"
+	CodeUnavailable        Code = "service_unavailable"
+	CodeTimeout            Code = "timeout"
+)
+
+// Definition documents a code.
+type Definition struct {
+	Code        Code   `json:"code"`
+	Status      int    `json:"status"`
+	Description string `json:"description"`
+}
+
+// registry is the documented list of codes. A handler may use a code with a
+// status other than the one listed; the status is the usual one.
+var registry = []Definition{
+	{CodeBadRequest, http.StatusBadRequest, "The request is malformed, such as a body that is not valid JSON or a query parameter of the wrong type."},
+	{CodeValidationFailed, http.StatusBadRequest, "The request body was read but some fields were rejected; details lists them."},
+	{CodeUnauthorized, http.StatusUnauthorized, "The request needs a signed-in user and carries no valid token."},
+	{CodeForbidden, http.StatusForbidden, "The user lacks the permission the route requires, or the CSRF token is missing."},
+	{CodeNotFound, http.StatusNotFound, "The route or the record it names does not exist."},
+	{CodeMethodNotAllowed, http.StatusMethodNotAllowed, "The route does not accept the method."},
+	{CodeConflict, http.StatusConflict, "The request conflicts with the current state, such as a slug that is already taken."},
+	{CodeGone, http.StatusGone, "The record existed but was removed or has expired."},
+	{CodePreconditionFailed, http.StatusPreconditionFailed, "A conditional request did not match the current version."},
+	{CodePayloadTooLarge, http.StatusRequestEntityTooLarge, "The body or upload exceeds the configured limit."},
+	{CodeUnsupportedMedia, http.StatusUnsupportedMediaType, "The body or upload has a type the route does not accept."},
+	{CodeUnprocessable, http.StatusUnprocessableEntity, "The request is well-formed but cannot be carried out."},
+	{CodeRateLimited, http.StatusTooManyRequests, "Too many requests; retry after the time given in Retry-After."},
+	{CodeInternal, http.StatusInternalServerError, "The server failed; the request_id identifies the failure in the logs."},
+	{CodeNotImplemented, http.StatusNotImplemented, "The feature behind the route is disabled or not configured."},
+	{CodeUnavailable, http.StatusServiceUnavailable, "A dependency is down or the site is in maintenance; retry later."},
+	{CodeTimeout, http.StatusGatewayTimeout, "The request took longer than the server allows."},
+}
+
+// Registry returns the documented codes, sorted by status.
+func Registry() []Definition {
+	definitions := append([]Definition(nil), registry...)
+	sort.SliceStable(definitions, func(i, j int) bool { return definitions[i].Status < definitions[j].Status })
+	return definitions
+}
+
+// Codes returns the documented codes in the order of Registry.
+func Codes() []string {
+	definitions := Registry()
+	codes := make([]string, len(definitions))
+	for i, definition := range definitions {
+		codes[i] = string(definition.Code)
+	}
+	return codes
+}
+
+// CodeForStatus returns the code of responses that do not name one.
+func CodeForStatus(status int) Code {
+	if status == http.StatusBadRequest {
+		return CodeBadRequest
+	}
+	for _, definition := range registry {
+		if definition.Status == status {
+			return definition.Code
+		}
+	}
+	switch {
+	case status == http.StatusRequestTimeout:
+		return CodeTimeout
+	case status >= http.StatusInternalServerError:
+		return CodeInternal
+	default:
+		return CodeBadRequest
+	}
+}
+
+// Envelope is the body of an error response.
+type Envelope struct {
+	Error     string       `json:"error"`
+	Code      Code         `json:"code"`
+	Details   []FieldError `json:"details,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
+}
+
+// FieldError describes one rejected field of a request body.
+type FieldError struct {
+	// Field is the path of the field as it appears in the JSON body, such as
+	// "title" or "build_hooks[1].url".
+	Field string `json:"field"`
+	// Rule is the validation rule that failed, such as "required" or "max".
+	Rule string `json:"rule"`
+	// Param is the argument of the rule, such as 255 for max=255.
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,86 @@
+package apierror
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"constructor-script-backend/pkg/validator"
+
+	"github.com/gin-gonic/gin"
+)
+
+type bindRequest struct {
+	Title string `json:"title" binding:"required,max=5"`
+	Count int    `json:"count" binding:"min=1"`
+}
+
+func bindBody(t *testing.T, body string) (int, Envelope) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	var req bindRequest
+	err := c.ShouldBindJSON(&req)
+	if err == nil {
+		t.Fatalf("expected %q to fail binding", body)
+	}
+	return FromBindError(err)
+}
+
+func TestFromBindErrorListsFields(t *testing.T) {
+	validator.Init()
+
+	status, envelope := bindBody(t, `{"title": "too long", "count": 0}`)
+	if status != http.StatusBadRequest || envelope.Code != CodeValidationFailed || len(envelope.Details) != 2 {
+		t.Fatalf("unexpected envelope %d %+v", status, envelope)
+	}
+	title := envelope.Details[0]
+	if title.Field != "title" || title.Rule != "max" || title.Param != "5" || title.Message != "must be at most 5 characters long" {
+		t.Fatalf("unexpected field error %+v", title)
+	}
+	if envelope.Details[1].Field != "count" || envelope.Details[1].Message != "must be at least 1" {
+		t.Fatalf("unexpected field error %+v", envelope.Details[1])
+	}
+
+	_, envelope = bindBody(t, `{"count": 2}`)
+	if envelope.Error != "title is required" {
+		t.Fatalf("unexpected message %q", envelope.Error)
+	}
+}
+
+func TestFromBindErrorMalformedBodies(t *testing.T) {
+	validator.Init()
+
+	cases := map[string]Code{
+		`{"title": `:              CodeBadRequest,
+		`{"title": 1}`:            CodeValidationFailed,
+		`["not", "an", "object"]`: CodeBadRequest,
+		``:                        CodeBadRequest,
+	}
+	for body, want := range cases {
+		if _, envelope := bindBody(t, body); envelope.Code != want {
+			t.Errorf("%q: got %+v, want code %s", body, envelope, want)
+		}
+	}
+}
+
+func TestCodeForStatus(t *testing.T) {
+	cases := map[int]Code{
+		http.StatusBadRequest:          CodeBadRequest,
+		http.StatusNotFound:            CodeNotFound,
+		http.StatusTooManyRequests:     CodeRateLimited,
+		http.StatusBadGateway:          CodeInternal,
+		http.StatusRequestTimeout:      CodeTimeout,
+		http.StatusExpectationFailed:   CodeBadRequest,
+		http.StatusInternalServerError: CodeInternal,
+	}
+	for status, want := range cases {
+		if got := CodeForStatus(status); got != want {
+			t.Errorf("CodeForStatus(%d) = %s, want %s", status, got, want)
+		}
+	}
+}
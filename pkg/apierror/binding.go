@@ -0,0 +1,112 @@
+package apierror
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// BindError answers a request whose body or query failed to bind, with the
+// rejected fields in details.
+func BindError(c *gin.Context, err error) {
+	status, envelope := FromBindError(err)
+	c.AbortWithStatusJSON(status, envelope)
+}
+
+// FromBindError converts an error of gin's binding into a status and envelope.
+func FromBindError(err error) (int, Envelope) {
+	var validationErrors validator.ValidationErrors
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	var maxBytesErr *http.MaxBytesError
+
+	switch {
+	case errors.As(err, &validationErrors):
+		details := make([]FieldError, 0, len(validationErrors))
+		for _, fieldErr := range validationErrors {
+			details = append(details, fieldError(fieldErr))
+		}
+		return http.StatusBadRequest, Envelope{Error: validationMessage(details), Code: CodeValidationFailed, Details: details}
+	case errors.As(err, &typeErr) && typeErr.Field == "":
+		return http.StatusBadRequest, Envelope{Error: "Request body must be a JSON object", Code: CodeBadRequest}
+	case errors.As(err, &typeErr):
+		detail := FieldError{Field: typeErr.Field, Rule: "type", Param: typeErr.Type.String(), Message: "must be of type " + typeErr.Type.String()}
+		return http.StatusBadRequest, Envelope{Error: validationMessage([]FieldError{detail}), Code: CodeValidationFailed, Details: []FieldError{detail}}
+	case errors.As(err, &maxBytesErr):
+		return http.StatusRequestEntityTooLarge, Envelope{Error: "Request body is too large", Code: CodePayloadTooLarge}
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		return http.StatusBadRequest, Envelope{Error: "Request body is not valid JSON", Code: CodeBadRequest}
+	case errors.Is(err, io.EOF):
+		return http.StatusBadRequest, Envelope{Error: "Request body is empty", Code: CodeBadRequest}
+	default:
+		return http.StatusBadRequest, Envelope{Error: err.Error(), Code: CodeBadRequest}
+	}
+}
+
+func validationMessage(details []FieldError) string {
+	if len(details) == 1 {
+		return details[0].Field + " " + details[0].Message
+	}
+	return fmt.Sprintf("%d fields are invalid", len(details))
+}
+
+// fieldError describes err with the JSON name of the field, which pkg/validator
+// registers as the name the validator reports.
+func fieldError(err validator.FieldError) FieldError {
+	field := err.Namespace()
+	if i := strings.Index(field, "."); i >= 0 {
+		field = field[i+1:]
+	}
+	return FieldError{Field: field, Rule: err.Tag(), Param: err.Param(), Message: ruleMessage(err)}
+}
+
+func ruleMessage(err validator.FieldError) string {
+	param := err.Param()
+	switch err.Tag() {
+	case "required", "required_if", "required_unless", "required_with", "required_without":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "url", "http_url":
+		return "must be a valid URL"
+	case "oneof":
+		return "must be one of: " + strings.Join(strings.Fields(param), ", ")
+	case "min", "gte":
+		if isSized(err) {
+			return "must be at least " + param + " characters long"
+		}
+		return "must be at least " + param
+	case "max", "lte":
+		if isSized(err) {
+			return "must be at most " + param + " characters long"
+		}
+		return "must be at most " + param
+	case "len":
+		return "must be exactly " + param + " characters long"
+	case "gt":
+		return "must be greater than " + param
+	case "lt":
+		return "must be less than " + param
+	case "username":
+		return "must be 3-30 letters, digits or underscores"
+	case "slug":
+		return "must contain only lowercase letters, digits and hyphens"
+	case "no_html":
+		return "must not contain HTML"
+	default:
+		return "failed the " + err.Tag() + " rule"
+	}
+}
+
+// isSized reports whether the min and max rules of err count characters or
+// items rather than compare a number.
+func isSized(err validator.FieldError) bool {
+	kind := err.Kind().String()
+	return kind == "string" || kind == "slice" || kind == "map" || kind == "array"
+}
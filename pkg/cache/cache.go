@@ -4,24 +4,57 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/go-redis/redis/v8"
+	"golang.org/x/sync/singleflight"
+
+	"constructor-script-backend/pkg/utils"
 )
 
 const (
 	// defaultOperationTimeout is the timeout for individual Redis operations
 	defaultOperationTimeout = 5 * time.Second
+
+	// hitStatsDays is how many days of hit and miss counts DailyHits can report.
+	hitStatsDays = 90
 )
 
+// Cache stores JSON-encoded values in Redis, with a bounded in-process tier in
+// front of it. Either tier may be missing: without Redis the process-local tier
+// is the only one, and a Cache with neither stores nothing.
 type Cache struct {
-	client  *redis.Client
-	enabled bool
+	client *redis.Client
+	local  *memoryStore
+	group  singleflight.Group
+
+	countersMu sync.Mutex
+	counters   map[string]map[string]int64
+
+	hitStats *utils.DailyCounter
+}
+
+// MemoryOptions sizes the in-process tier. Entries <= 0 disables it. TTL caps how
+// long an entry may be served locally, since invalidations made by other instances
+// never reach it; zero leaves entries with the expiration they were stored with.
+type MemoryOptions struct {
+	Entries int
+	TTL     time.Duration
 }
 
 func NewCache(addr string, enable bool) (*Cache, error) {
-	if !enable {
-		return &Cache{enabled: false}, nil
+	return NewLayeredCache(addr, enable, MemoryOptions{})
+}
+
+// NewLayeredCache connects to Redis when enableRedis is set and puts an in-process
+// LRU tier sized by memory in front of it.
+func NewLayeredCache(addr string, enableRedis bool, memory MemoryOptions) (*Cache, error) {
+	if !enableRedis {
+		return &Cache{
+			local:    newMemoryStore(memory.Entries, memory.TTL),
+			hitStats: utils.NewDailyCounter(hitStatsDays),
+		}, nil
 	}
 
 	client := redis.NewClient(&redis.Options{
@@ -44,50 +77,124 @@ func NewCache(addr string, enable bool) (*Cache, error) {
 	}
 
 	return &Cache{
-		client:  client,
-		enabled: true,
+		client:   client,
+		local:    newMemoryStore(memory.Entries, memory.TTL),
+		hitStats: utils.NewDailyCounter(hitStatsDays),
 	}, nil
 }
 
+func (c *Cache) enabled() bool {
+	return c.client != nil || c.local != nil
+}
+
 // operationContext creates a context with timeout for Redis operations
 func (c *Cache) operationContext() (context.Context, context.CancelFunc) {
 	return context.WithTimeout(context.Background(), defaultOperationTimeout)
 }
 
 func (c *Cache) Set(key string, value interface{}, expiration time.Duration) error {
-	if !c.enabled {
+	if !c.enabled() {
+		return nil
+	}
+
+	jsonData, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return c.setEncoded(key, jsonData, expiration)
+}
+
+func (c *Cache) setEncoded(key string, data []byte, expiration time.Duration) error {
+	if c.local != nil {
+		c.local.set(key, data, expiration)
+	}
+	if c.client == nil {
 		return nil
 	}
 
 	ctx, cancel := c.operationContext()
 	defer cancel()
 
-	jsonData, err := json.Marshal(value)
+	return c.client.Set(ctx, key, data, expiration).Err()
+}
+
+func (c *Cache) Get(key string, dest interface{}) error {
+	data, err := c.getEncoded(key)
 	if err != nil {
 		return err
 	}
-	return c.client.Set(ctx, key, jsonData, expiration).Err()
+	return json.Unmarshal(data, dest)
 }
 
-func (c *Cache) Get(key string, dest interface{}) error {
-	if !c.enabled {
-		return fmt.Errorf("cache disabled")
+func (c *Cache) getEncoded(key string) ([]byte, error) {
+	if !c.enabled() {
+		return nil, fmt.Errorf("cache disabled")
+	}
+	if c.local != nil {
+		if data, ok := c.local.get(key); ok {
+			c.hitStats.Add("hits", 1)
+			return data, nil
+		}
+	}
+	if c.client == nil {
+		c.hitStats.Add("misses", 1)
+		return nil, fmt.Errorf("key not found")
 	}
 
 	ctx, cancel := c.operationContext()
 	defer cancel()
 
-	val, err := c.client.Get(ctx, key).Result()
+	val, err := c.client.Get(ctx, key).Bytes()
 	if err == redis.Nil {
-		return fmt.Errorf("key not found")
+		c.hitStats.Add("misses", 1)
+		return nil, fmt.Errorf("key not found")
 	} else if err != nil {
+		return nil, err
+	}
+	c.hitStats.Add("hits", 1)
+	if c.local != nil {
+		// The Redis expiration is not known here; the local TTL cap applies.
+		c.local.set(key, val, 0)
+	}
+	return val, nil
+}
+
+// DailyHits returns the lookups this process answered from the cache, and those it
+// did not, on the UTC day containing day.
+func (c *Cache) DailyHits(day time.Time) (hits, misses int64) {
+	if c == nil {
+		return 0, 0
+	}
+	return c.hitStats.Count("hits", day), c.hitStats.Count("misses", day)
+}
+
+// Load reads key into dest. On a miss, load runs once for all concurrent callers
+// asking for the same key, so a hot entry expiring does not send every request to
+// the database, and its result is decoded into dest. load is responsible for
+// caching what it fetched, often under several keys at once.
+func (c *Cache) Load(key string, dest interface{}, load func() (interface{}, error)) error {
+	if err := c.Get(key, dest); err == nil {
+		return nil
+	}
+
+	data, err, _ := c.group.Do(key, func() (interface{}, error) {
+		value, err := load()
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(value)
+	})
+	if err != nil {
 		return err
 	}
-	return json.Unmarshal([]byte(val), dest)
+	return json.Unmarshal(data.([]byte), dest)
 }
 
 func (c *Cache) Delete(key string) error {
-	if !c.enabled {
+	if c.local != nil {
+		c.local.delete(key)
+	}
+	if c.client == nil {
 		return nil
 	}
 
@@ -98,7 +205,10 @@ func (c *Cache) Delete(key string) error {
 }
 
 func (c *Cache) DeletePattern(pattern string) error {
-	if !c.enabled {
+	if c.local != nil {
+		c.local.deletePattern(pattern)
+	}
+	if c.client == nil {
 		return nil
 	}
 
@@ -115,7 +225,12 @@ func (c *Cache) DeletePattern(pattern string) error {
 }
 
 func (c *Cache) Exists(key string) (bool, error) {
-	if !c.enabled {
+	if c.local != nil {
+		if _, ok := c.local.get(key); ok {
+			return true, nil
+		}
+	}
+	if c.client == nil {
 		return false, nil
 	}
 
@@ -126,8 +241,10 @@ func (c *Cache) Exists(key string) (bool, error) {
 	return val > 0, err
 }
 
+// Increment counts in Redis only; a process-local counter would split across
+// instances.
 func (c *Cache) Increment(key string) (int64, error) {
-	if !c.enabled {
+	if c.client == nil {
 		return 0, nil
 	}
 
@@ -137,8 +254,27 @@ func (c *Cache) Increment(key string) (int64, error) {
 	return c.client.Incr(ctx, key).Result()
 }
 
+// SetIfAbsent stores value under key in Redis with one SET NX PX, unless the key
+// already exists, and reports whether it was stored. Like Increment it skips the
+// process-local tier, which other instances cannot see.
+func (c *Cache) SetIfAbsent(key string, value interface{}, expiration time.Duration) (bool, error) {
+	if c.client == nil {
+		return false, ErrSharedStoreUnavailable
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return false, err
+	}
+
+	ctx, cancel := c.operationContext()
+	defer cancel()
+
+	return c.client.SetNX(ctx, key, data, expiration).Result()
+}
+
 func (c *Cache) Expire(key string, expiration time.Duration) error {
-	if !c.enabled {
+	if c.client == nil {
 		return nil
 	}
 
@@ -149,7 +285,10 @@ func (c *Cache) Expire(key string, expiration time.Duration) error {
 }
 
 func (c *Cache) FlushAll() error {
-	if !c.enabled {
+	if c.local != nil {
+		c.local.clear()
+	}
+	if c.client == nil {
 		return nil
 	}
 
@@ -164,8 +303,21 @@ func (c *Cache) Clear() {
 	_ = c.FlushAll()
 }
 
+// UsesRedis reports whether the cache is backed by Redis.
+func (c *Cache) UsesRedis() bool {
+	return c != nil && c.client != nil
+}
+
+// Ping checks that Redis answers. It succeeds when the cache has no Redis tier.
+func (c *Cache) Ping(ctx context.Context) error {
+	if c == nil || c.client == nil {
+		return nil
+	}
+	return c.client.Ping(ctx).Err()
+}
+
 func (c *Cache) Close() error {
-	if !c.enabled {
+	if c.client == nil {
 		return nil
 	}
 	return c.client.Close()
@@ -212,7 +364,7 @@ func (c *Cache) IncrementViews(postID uint) (int64, error) {
 }
 
 func (c *Cache) GetViews(postID uint) (int64, error) {
-	if !c.enabled {
+	if c.client == nil {
 		return 0, nil
 	}
 
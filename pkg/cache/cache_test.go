@@ -0,0 +1,126 @@
+package cache
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMemoryTierEvictsLeastRecentlyUsed(t *testing.T) {
+	c, err := NewLayeredCache("", false, MemoryOptions{Entries: 2})
+	if err != nil {
+		t.Fatalf("new cache: %v", err)
+	}
+
+	c.Set("a", 1, time.Hour)
+	c.Set("b", 2, time.Hour)
+	var value int
+	if err := c.Get("a", &value); err != nil || value != 1 {
+		t.Fatalf("expected a=1, got %d (%v)", value, err)
+	}
+	c.Set("c", 3, time.Hour)
+
+	if err := c.Get("b", &value); err == nil {
+		t.Fatal("expected b to be evicted")
+	}
+	if err := c.Get("a", &value); err != nil {
+		t.Fatalf("recently read a must survive: %v", err)
+	}
+}
+
+func TestMemoryTierExpiresAndDeletesPatterns(t *testing.T) {
+	c, _ := NewLayeredCache("", false, MemoryOptions{Entries: 10, TTL: 20 * time.Millisecond})
+	c.Set("page:path:/about/team", "team", time.Hour)
+	c.Set("page:7", "seven", time.Hour)
+	c.Set("posts:list", "posts", time.Hour)
+
+	c.DeletePattern("page:path:*")
+	var value string
+	if err := c.Get("page:path:/about/team", &value); err == nil {
+		t.Fatal("expected the pattern to match keys containing slashes")
+	}
+	if err := c.Get("posts:list", &value); err != nil || value != "posts" {
+		t.Fatalf("unrelated key removed: %q (%v)", value, err)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if err := c.Get("page:7", &value); err == nil {
+		t.Fatal("expected the TTL cap to expire the entry")
+	}
+}
+
+func TestDisabledCacheStoresNothing(t *testing.T) {
+	c, _ := NewCache("", false)
+	c.Set("a", 1, time.Hour)
+	var value int
+	if err := c.Get("a", &value); err == nil {
+		t.Fatal("expected a disabled cache to miss")
+	}
+}
+
+func TestLoadSharesConcurrentMisses(t *testing.T) {
+	c, _ := NewLayeredCache("", false, MemoryOptions{Entries: 10})
+
+	var calls int32
+	release := make(chan struct{})
+	load := func() (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		c.Set("hot", "value", time.Hour)
+		return "value", nil
+	}
+
+	var wg sync.WaitGroup
+	results := make([]string, 8)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := c.Load("hot", &results[i], load); err != nil {
+				t.Errorf("load: %v", err)
+			}
+		}(i)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if calls != 1 {
+		t.Fatalf("expected one load, got %d", calls)
+	}
+	for _, result := range results {
+		if result != "value" {
+			t.Fatalf("unexpected result %q", result)
+		}
+	}
+
+	failing := errors.New("boom")
+	var value string
+	if err := c.Load("missing", &value, func() (interface{}, error) { return nil, failing }); !errors.Is(err, failing) {
+		t.Fatalf("expected load error, got %v", err)
+	}
+}
+
+func TestCountersAccumulateUntilDrained(t *testing.T) {
+	c, _ := NewCache("", false)
+	c.AddCounter("views", "1", 1)
+	c.AddCounter("views", "1", 2)
+	c.AddCounter("views", "2", 1)
+	c.AddCounter("other", "1", 5)
+
+	counts, err := c.DrainCounters("views")
+	if err != nil {
+		t.Fatalf("drain: %v", err)
+	}
+	if len(counts) != 2 || counts["1"] != 3 || counts["2"] != 1 {
+		t.Fatalf("unexpected counts %v", counts)
+	}
+	if counts, _ := c.DrainCounters("views"); len(counts) != 0 {
+		t.Fatalf("expected the drain to reset the set, got %v", counts)
+	}
+	if counts, _ := c.DrainCounters("other"); counts["1"] != 5 {
+		t.Fatalf("other set must be untouched, got %v", counts)
+	}
+}
@@ -0,0 +1,71 @@
+package cache
+
+import (
+	"strconv"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const counterKeyPrefix = "counters:"
+
+// AddCounter adds delta to field of the counter set name. Counters accumulate in a
+// Redis hash shared by every instance, or in process memory when Redis is disabled,
+// until DrainCounters hands them to whoever persists them.
+func (c *Cache) AddCounter(name, field string, delta int64) error {
+	if c.client == nil {
+		c.countersMu.Lock()
+		defer c.countersMu.Unlock()
+
+		if c.counters == nil {
+			c.counters = make(map[string]map[string]int64)
+		}
+		set := c.counters[name]
+		if set == nil {
+			set = make(map[string]int64)
+			c.counters[name] = set
+		}
+		set[field] += delta
+		return nil
+	}
+
+	ctx, cancel := c.operationContext()
+	defer cancel()
+
+	return c.client.HIncrBy(ctx, counterKeyPrefix+name, field, delta).Err()
+}
+
+// DrainCounters returns the counter set name and resets it in one step, so
+// increments made while the caller persists the result are kept for the next drain.
+func (c *Cache) DrainCounters(name string) (map[string]int64, error) {
+	if c.client == nil {
+		c.countersMu.Lock()
+		defer c.countersMu.Unlock()
+
+		set := c.counters[name]
+		delete(c.counters, name)
+		return set, nil
+	}
+
+	ctx, cancel := c.operationContext()
+	defer cancel()
+
+	key := counterKeyPrefix + name
+	var values *redis.StringStringMapCmd
+	if _, err := c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		values = pipe.HGetAll(ctx, key)
+		pipe.Del(ctx, key)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(values.Val()))
+	for field, value := range values.Val() {
+		count, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			continue
+		}
+		counts[field] = count
+	}
+	return counts, nil
+}
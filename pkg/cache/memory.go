@@ -0,0 +1,148 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// memoryStore is a bounded least-recently-used map of encoded values. It sits in
+// front of Redis, or replaces it when Redis is disabled.
+type memoryStore struct {
+	mu       sync.Mutex
+	capacity int
+	maxTTL   time.Duration
+	order    *list.List
+	entries  map[string]*list.Element
+}
+
+type memoryEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// newMemoryStore keeps up to capacity entries. A positive maxTTL caps how long an
+// entry lives regardless of the expiration it was stored with, which bounds how
+// stale one instance can be after another instance invalidated the shared Redis.
+func newMemoryStore(capacity int, maxTTL time.Duration) *memoryStore {
+	if capacity <= 0 {
+		return nil
+	}
+	return &memoryStore{
+		capacity: capacity,
+		maxTTL:   maxTTL,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element, capacity),
+	}
+}
+
+func (m *memoryStore) get(key string) ([]byte, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	element, ok := m.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*memoryEntry)
+	if !entry.expires.IsZero() && time.Now().After(entry.expires) {
+		m.removeElement(element)
+		return nil, false
+	}
+	m.order.MoveToFront(element)
+	return entry.value, true
+}
+
+func (m *memoryStore) set(key string, value []byte, expiration time.Duration) {
+	if m.maxTTL > 0 && (expiration <= 0 || expiration > m.maxTTL) {
+		expiration = m.maxTTL
+	}
+	var expires time.Time
+	if expiration > 0 {
+		expires = time.Now().Add(expiration)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if element, ok := m.entries[key]; ok {
+		entry := element.Value.(*memoryEntry)
+		entry.value = value
+		entry.expires = expires
+		m.order.MoveToFront(element)
+		return
+	}
+
+	m.entries[key] = m.order.PushFront(&memoryEntry{key: key, value: value, expires: expires})
+	for m.order.Len() > m.capacity {
+		m.removeElement(m.order.Back())
+	}
+}
+
+func (m *memoryStore) delete(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if element, ok := m.entries[key]; ok {
+		m.removeElement(element)
+	}
+}
+
+// deletePattern removes the keys matching a Redis-style glob such as "posts:*".
+func (m *memoryStore) deletePattern(pattern string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for key, element := range m.entries {
+		if matchPattern(pattern, key) {
+			m.removeElement(element)
+		}
+	}
+}
+
+func (m *memoryStore) clear() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.order.Init()
+	m.entries = make(map[string]*list.Element, m.capacity)
+}
+
+func (m *memoryStore) removeElement(element *list.Element) {
+	m.order.Remove(element)
+	delete(m.entries, element.Value.(*memoryEntry).key)
+}
+
+// matchPattern implements the * and ? wildcards of Redis SCAN MATCH. Unlike
+// path.Match, * also matches "/", which page path keys contain.
+func matchPattern(pattern, key string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			for len(pattern) > 0 && pattern[0] == '*' {
+				pattern = pattern[1:]
+			}
+			if pattern == "" {
+				return true
+			}
+			for i := 0; i <= len(key); i++ {
+				if matchPattern(pattern, key[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if key == "" {
+				return false
+			}
+		default:
+			if key == "" || key[0] != pattern[0] {
+				return false
+			}
+		}
+		pattern = pattern[1:]
+		key = key[1:]
+	}
+	return key == ""
+}
@@ -0,0 +1,123 @@
+package cache
+
+import (
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const rateLimitKeyPrefix = "ratelimit:"
+
+// ErrSharedStoreUnavailable is returned by operations that only make sense across
+// instances when the cache has no Redis client.
+var ErrSharedStoreUnavailable = errors.New("cache: shared store unavailable")
+
+// slidingWindowScript records a request in a sorted set scored by its time in
+// milliseconds, after dropping the entries older than the window. Time is read from
+// the Redis server so that instances with skewed clocks share one window. A request over
+// the limit is not recorded, so rejected clients do not extend their own wait.
+// It returns 1 and 0 when allowed, or 0 and the milliseconds until the oldest
+// entry leaves the window.
+var slidingWindowScript = redis.NewScript(`
+local key = KEYS[1]
+local window = tonumber(ARGV[1])
+local limit = tonumber(ARGV[2])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+redis.call('ZREMRANGEBYSCORE', key, '-inf', now - window)
+if redis.call('ZCARD', key) >= limit then
+	local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+	local wait = window
+	if oldest[2] then
+		wait = tonumber(oldest[2]) + window - now
+	end
+	return {0, wait}
+end
+
+redis.call('ZADD', key, now, ARGV[3])
+redis.call('PEXPIRE', key, window)
+return {1, 0}
+`)
+
+// Members are made unique across instances by the start time of the process and
+// within it by a sequence number.
+var (
+	rateLimitInstance = strconv.FormatInt(time.Now().UnixNano(), 36)
+	rateLimitSequence atomic.Uint64
+)
+
+// Shared reports whether the cache is backed by Redis, and so visible to every
+// instance of the application.
+func (c *Cache) Shared() bool {
+	return c != nil && c.client != nil
+}
+
+// AllowInWindow records a request against key and reports whether fewer than limit
+// requests were recorded in the preceding window. The count is kept in Redis, so it
+// holds across instances; when the request is refused, retryAfter is how long until
+// the oldest request leaves the window.
+func (c *Cache) AllowInWindow(key string, limit int, window time.Duration) (allowed bool, retryAfter time.Duration, err error) {
+	if !c.Shared() {
+		return false, 0, ErrSharedStoreUnavailable
+	}
+	if limit <= 0 || window <= 0 {
+		return true, 0, nil
+	}
+
+	ctx, cancel := c.operationContext()
+	defer cancel()
+
+	member := rateLimitInstance + "-" + strconv.FormatUint(rateLimitSequence.Add(1), 36)
+
+	result, err := slidingWindowScript.Run(ctx, c.client,
+		[]string{rateLimitKeyPrefix + key},
+		window.Milliseconds(), limit, member,
+	).Slice()
+	if err != nil {
+		return false, 0, err
+	}
+	if len(result) != 2 {
+		return false, 0, errors.New("cache: unexpected rate limit script result")
+	}
+
+	allowedFlag, _ := result[0].(int64)
+	waitMillis, _ := result[1].(int64)
+	if allowedFlag == 1 {
+		return true, 0, nil
+	}
+	if waitMillis < 0 {
+		waitMillis = 0
+	}
+	return false, time.Duration(waitMillis) * time.Millisecond, nil
+}
+
+// incrementKeyPrefix keeps the expiring counters apart from the counter sets of
+// AddCounter.
+const incrementKeyPrefix = "count:"
+
+// incrementScript adds one to a counter and starts its expiry on first use, so a
+// counter always disappears ttl after it was created.
+var incrementScript = redis.NewScript(`
+local count = redis.call('INCR', KEYS[1])
+if count == 1 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+return count
+`)
+
+// IncrementCounter adds one to the counter key, shared by every instance, and
+// returns its new value. The counter expires ttl after its first increment.
+func (c *Cache) IncrementCounter(key string, ttl time.Duration) (int64, error) {
+	if !c.Shared() {
+		return 0, ErrSharedStoreUnavailable
+	}
+
+	ctx, cancel := c.operationContext()
+	defer cancel()
+
+	return incrementScript.Run(ctx, c.client, []string{incrementKeyPrefix + key}, ttl.Milliseconds()).Int64()
+}
@@ -0,0 +1,375 @@
+// Package geoip looks up the country of an IP address in a MaxMind DB file,
+// such as GeoLite2-Country.mmdb or DB-IP's country database.
+package geoip
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"os"
+	"strings"
+)
+
+var metadataMarker = []byte("\xab\xcd\xefMaxMind.com")
+
+const (
+	// metadataSearchSize is how far from the end of the file the metadata may
+	// start.
+	metadataSearchSize = 128 * 1024
+	dataSeparatorSize  = 16
+	maxDecodeDepth     = 32
+)
+
+// ErrInvalidDatabase is returned for files that are not MaxMind databases.
+var ErrInvalidDatabase = errors.New("invalid MaxMind database")
+
+// Reader holds a database in memory. It is safe for concurrent use.
+type Reader struct {
+	buffer     []byte
+	data       []byte
+	nodeCount  uint
+	recordSize uint
+	ipVersion  uint
+	ipv4Start  uint
+}
+
+// Open reads the database at path.
+func Open(path string) (*Reader, error) {
+	buffer, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return New(buffer)
+}
+
+// New reads a database from buffer.
+func New(buffer []byte) (*Reader, error) {
+	start := 0
+	if len(buffer) > metadataSearchSize {
+		start = len(buffer) - metadataSearchSize
+	}
+	index := bytes.LastIndex(buffer[start:], metadataMarker)
+	if index < 0 {
+		return nil, ErrInvalidDatabase
+	}
+	metadataStart := start + index + len(metadataMarker)
+
+	decoded, _, err := (&decoder{data: buffer[metadataStart:]}).decode(0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDatabase, err)
+	}
+	metadata, ok := decoded.(map[string]interface{})
+	if !ok {
+		return nil, ErrInvalidDatabase
+	}
+
+	reader := &Reader{
+		buffer:     buffer,
+		nodeCount:  metadataUint(metadata, "node_count"),
+		recordSize: metadataUint(metadata, "record_size"),
+		ipVersion:  metadataUint(metadata, "ip_version"),
+	}
+	switch reader.recordSize {
+	case 24, 28, 32:
+	default:
+		return nil, fmt.Errorf("%w: unsupported record size %d", ErrInvalidDatabase, reader.recordSize)
+	}
+	treeSize := reader.nodeCount * reader.recordSize / 4
+	if reader.nodeCount == 0 || treeSize+dataSeparatorSize > uint(metadataStart) {
+		return nil, ErrInvalidDatabase
+	}
+	reader.data = buffer[treeSize+dataSeparatorSize : metadataStart-len(metadataMarker)]
+
+	// IPv4 addresses live under ::/96 of IPv6 databases.
+	if reader.ipVersion == 6 {
+		node := uint(0)
+		for i := 0; i < 96 && node < reader.nodeCount; i++ {
+			if node, err = reader.readNode(node, 0); err != nil {
+				return nil, err
+			}
+		}
+		reader.ipv4Start = node
+	}
+	return reader, nil
+}
+
+// Country returns the ISO 3166-1 alpha-2 code of the country ip is in, or ""
+// when the database does not know the address.
+func (r *Reader) Country(ip net.IP) (string, error) {
+	record, err := r.lookup(ip)
+	if err != nil || record == nil {
+		return "", err
+	}
+	for _, key := range []string{"country", "registered_country"} {
+		if country, ok := record[key].(map[string]interface{}); ok {
+			if code, ok := country["iso_code"].(string); ok && code != "" {
+				return strings.ToUpper(code), nil
+			}
+		}
+	}
+	return "", nil
+}
+
+func (r *Reader) lookup(ip net.IP) (map[string]interface{}, error) {
+	if r == nil {
+		return nil, nil
+	}
+	bits := ip.To4()
+	node := uint(0)
+	if bits != nil {
+		if r.ipVersion == 6 {
+			node = r.ipv4Start
+		}
+	} else {
+		if bits = ip.To16(); bits == nil {
+			return nil, fmt.Errorf("invalid IP address %q", ip)
+		}
+		if r.ipVersion == 4 {
+			return nil, nil
+		}
+	}
+
+	for i := 0; i < len(bits)*8 && node < r.nodeCount; i++ {
+		bit := uint(bits[i/8]>>(7-uint(i%8))) & 1
+		next, err := r.readNode(node, bit)
+		if err != nil {
+			return nil, err
+		}
+		node = next
+	}
+	if node <= r.nodeCount {
+		return nil, nil
+	}
+
+	offset := node - r.nodeCount - dataSeparatorSize
+	if offset >= uint(len(r.data)) {
+		return nil, ErrInvalidDatabase
+	}
+	decoded, _, err := (&decoder{data: r.data}).decode(offset, 0)
+	if err != nil {
+		return nil, err
+	}
+	record, _ := decoded.(map[string]interface{})
+	return record, nil
+}
+
+func (r *Reader) readNode(node, bit uint) (uint, error) {
+	size := r.recordSize / 4
+	offset := node * size
+	if offset+size > uint(len(r.buffer)) {
+		return 0, ErrInvalidDatabase
+	}
+	b := r.buffer[offset : offset+size]
+
+	switch r.recordSize {
+	case 24:
+		if bit == 0 {
+			return uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2]), nil
+		}
+		return uint(b[3])<<16 | uint(b[4])<<8 | uint(b[5]), nil
+	case 28:
+		if bit == 0 {
+			return uint(b[3]&0xF0)<<20 | uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2]), nil
+		}
+		return uint(b[3]&0x0F)<<24 | uint(b[4])<<16 | uint(b[5])<<8 | uint(b[6]), nil
+	default:
+		if bit == 0 {
+			return uint(binary.BigEndian.Uint32(b[:4])), nil
+		}
+		return uint(binary.BigEndian.Uint32(b[4:])), nil
+	}
+}
+
+func metadataUint(metadata map[string]interface{}, key string) uint {
+	if value, ok := metadata[key].(uint64); ok {
+		return uint(value)
+	}
+	return 0
+}
+
+// Data types of the MaxMind DB format.
+const (
+	typeExtended = iota
+	typePointer
+	typeString
+	typeDouble
+	typeBytes
+	typeUint16
+	typeUint32
+	typeMap
+	typeInt32
+	typeUint64
+	typeUint128
+	typeArray
+	typeContainer
+	typeEndMarker
+	typeBool
+	typeFloat
+)
+
+type decoder struct {
+	data []byte
+}
+
+// decode returns the value at offset and the offset after it.
+func (d *decoder) decode(offset uint, depth int) (interface{}, uint, error) {
+	if depth > maxDecodeDepth {
+		return nil, 0, errors.New("data nested too deeply")
+	}
+	if offset >= uint(len(d.data)) {
+		return nil, 0, errors.New("unexpected end of data")
+	}
+	control := d.data[offset]
+	offset++
+
+	kind := uint(control >> 5)
+	if kind == typePointer {
+		pointer, next, err := d.pointer(control, offset)
+		if err != nil {
+			return nil, 0, err
+		}
+		value, _, err := d.decode(pointer, depth+1)
+		return value, next, err
+	}
+	if kind == typeExtended {
+		if offset >= uint(len(d.data)) {
+			return nil, 0, errors.New("unexpected end of data")
+		}
+		kind = 7 + uint(d.data[offset])
+		offset++
+	}
+
+	size, offset, err := d.size(control, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	switch kind {
+	case typeMap:
+		result := make(map[string]interface{}, size)
+		for i := uint(0); i < size; i++ {
+			key, next, err := d.decode(offset, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			name, ok := key.(string)
+			if !ok {
+				return nil, 0, errors.New("map key is not a string")
+			}
+			value, next, err := d.decode(next, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			result[name] = value
+			offset = next
+		}
+		return result, offset, nil
+	case typeArray:
+		result := make([]interface{}, 0, size)
+		for i := uint(0); i < size; i++ {
+			value, next, err := d.decode(offset, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			result = append(result, value)
+			offset = next
+		}
+		return result, offset, nil
+	case typeBool:
+		return size != 0, offset, nil
+	}
+
+	if offset+size > uint(len(d.data)) {
+		return nil, 0, errors.New("unexpected end of data")
+	}
+	raw := d.data[offset : offset+size]
+	offset += size
+
+	switch kind {
+	case typeString:
+		return string(raw), offset, nil
+	case typeBytes:
+		return append([]byte(nil), raw...), offset, nil
+	case typeDouble:
+		if size != 8 {
+			return nil, 0, errors.New("invalid double")
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(raw)), offset, nil
+	case typeFloat:
+		if size != 4 {
+			return nil, 0, errors.New("invalid float")
+		}
+		return math.Float32frombits(binary.BigEndian.Uint32(raw)), offset, nil
+	case typeUint16, typeUint32, typeUint64:
+		if size > 8 {
+			return nil, 0, errors.New("invalid unsigned integer")
+		}
+		var value uint64
+		for _, b := range raw {
+			value = value<<8 | uint64(b)
+		}
+		return value, offset, nil
+	case typeInt32:
+		if size > 4 {
+			return nil, 0, errors.New("invalid integer")
+		}
+		var value uint32
+		for _, b := range raw {
+			value = value<<8 | uint32(b)
+		}
+		return int64(int32(value)), offset, nil
+	case typeUint128, typeContainer, typeEndMarker:
+		// Not needed for country lookups.
+		return nil, offset, nil
+	default:
+		return nil, 0, fmt.Errorf("unknown data type %d", kind)
+	}
+}
+
+func (d *decoder) size(control byte, offset uint) (uint, uint, error) {
+	size := uint(control & 0x1f)
+	if size < 29 {
+		return size, offset, nil
+	}
+	extra := size - 28
+	if offset+extra > uint(len(d.data)) {
+		return 0, 0, errors.New("unexpected end of data")
+	}
+	var value uint
+	for _, b := range d.data[offset : offset+extra] {
+		value = value<<8 | uint(b)
+	}
+	switch size {
+	case 29:
+		value += 29
+	case 30:
+		value += 285
+	default:
+		value += 65821
+	}
+	return value, offset + extra, nil
+}
+
+func (d *decoder) pointer(control byte, offset uint) (uint, uint, error) {
+	length := uint((control>>3)&0x3) + 1
+	if offset+length > uint(len(d.data)) {
+		return 0, 0, errors.New("unexpected end of data")
+	}
+	var value uint
+	if length < 4 {
+		value = uint(control & 0x7)
+	}
+	for _, b := range d.data[offset : offset+length] {
+		value = value<<8 | uint(b)
+	}
+	switch length {
+	case 2:
+		value += 2048
+	case 3:
+		value += 526336
+	}
+	return value, offset + length, nil
+}
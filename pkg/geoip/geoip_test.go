@@ -0,0 +1,92 @@
+package geoip
+
+import (
+	"net"
+	"testing"
+)
+
+// encodeString and encodeUint write the short forms of the MaxMind DB types.
+func encodeString(value string) []byte {
+	return append([]byte{byte(typeString<<5 | len(value))}, value...)
+}
+
+func encodeUint(kind int, value uint32, size int) []byte {
+	out := []byte{byte(size)}
+	if kind <= typeMap {
+		out[0] |= byte(kind << 5)
+	} else {
+		out = append(out, byte(kind-7))
+	}
+	for i := size - 1; i >= 0; i-- {
+		out = append(out, byte(value>>(8*uint(i))))
+	}
+	return out
+}
+
+func encodeMap(pairs ...[]byte) []byte {
+	out := []byte{byte(typeMap<<5 | len(pairs)/2)}
+	for _, pair := range pairs {
+		out = append(out, pair...)
+	}
+	return out
+}
+
+// testDatabase maps 1.0.0.0/8 to Germany in an IPv4 tree with 24-bit records.
+func testDatabase() []byte {
+	const nodeCount = 8
+	var tree []byte
+	record := func(value uint32) []byte {
+		return []byte{byte(value >> 16), byte(value >> 8), byte(value)}
+	}
+	for node := uint32(0); node < nodeCount; node++ {
+		left, right := node+1, uint32(nodeCount)
+		if node == nodeCount-1 {
+			left, right = nodeCount, nodeCount+dataSeparatorSize
+		}
+		tree = append(tree, record(left)...)
+		tree = append(tree, record(right)...)
+	}
+
+	data := encodeMap(
+		encodeString("country"),
+		encodeMap(encodeString("iso_code"), encodeString("de")),
+	)
+	metadata := encodeMap(
+		encodeString("node_count"), encodeUint(typeUint32, nodeCount, 1),
+		encodeString("record_size"), encodeUint(typeUint16, 24, 1),
+		encodeString("ip_version"), encodeUint(typeUint16, 4, 1),
+	)
+
+	buffer := append(tree, make([]byte, dataSeparatorSize)...)
+	buffer = append(buffer, data...)
+	buffer = append(buffer, metadataMarker...)
+	return append(buffer, metadata...)
+}
+
+func TestCountry(t *testing.T) {
+	reader, err := New(testDatabase())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]string{
+		"1.2.3.4":     "DE",
+		"1.255.0.1":   "DE",
+		"2.0.0.1":     "",
+		"0.0.0.1":     "",
+		"2001:db8::1": "",
+	}
+	for ip, want := range cases {
+		got, err := reader.Country(net.ParseIP(ip))
+		if err != nil {
+			t.Fatalf("%s: %v", ip, err)
+		}
+		if got != want {
+			t.Fatalf("%s: expected %q, got %q", ip, want, got)
+		}
+	}
+
+	if _, err := New([]byte("not a database")); err == nil {
+		t.Fatal("expected an error for an invalid database")
+	}
+}
@@ -6,6 +6,9 @@ import (
 	"fmt"
 	"strings"
 	"unicode"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/language/display"
 )
 
 // Default represents the fallback language code used when no explicit language
@@ -57,6 +60,15 @@ func Normalize(code string) (string, error) {
 	return language + "-" + region, nil
 }
 
+// NormalizeOptional behaves like Normalize but accepts an empty code, which callers
+// treat as "the site default language".
+func NormalizeOptional(code string) (string, error) {
+	if strings.TrimSpace(code) == "" {
+		return "", nil
+	}
+	return Normalize(code)
+}
+
 // NormalizeList normalises a slice of language codes, removing duplicates while
 // preserving the order of first occurrence. Empty entries are ignored. If any
 // code fails validation the returned slice will be empty alongside the error.
@@ -151,3 +163,46 @@ func DecodeList(value string) ([]string, error) {
 	}
 	return codes, nil
 }
+
+// rtlLanguages lists the languages written right to left.
+var rtlLanguages = map[string]struct{}{
+	"ar":  {},
+	"ckb": {},
+	"dv":  {},
+	"fa":  {},
+	"he":  {},
+	"ps":  {},
+	"sd":  {},
+	"ug":  {},
+	"ur":  {},
+	"yi":  {},
+}
+
+// IsRTL reports whether code names a right-to-left language. Regional variants
+// such as "ar-EG" follow their base language.
+func IsRTL(code string) bool {
+	base, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(code)), "-")
+	_, ok := rtlLanguages[base]
+	return ok
+}
+
+// Direction returns the HTML dir value for code: "rtl" or "ltr".
+func Direction(code string) string {
+	if IsRTL(code) {
+		return "rtl"
+	}
+	return "ltr"
+}
+
+// DisplayName returns the name of the language in itself, e.g. "español" for
+// "es", as shown in language switchers. Unknown codes are returned unchanged.
+func DisplayName(code string) string {
+	tag, err := language.Parse(strings.TrimSpace(code))
+	if err != nil {
+		return code
+	}
+	if name := display.Self.Name(tag); name != "" {
+		return name
+	}
+	return code
+}
@@ -0,0 +1,32 @@
+package lang
+
+import "testing"
+
+func TestDirection(t *testing.T) {
+	cases := map[string]string{
+		"ar":    "rtl",
+		"he":    "rtl",
+		"fa-IR": "rtl",
+		" AR ":  "rtl",
+		"en":    "ltr",
+		"es-MX": "ltr",
+		"":      "ltr",
+	}
+	for code, expected := range cases {
+		if got := Direction(code); got != expected {
+			t.Fatalf("%q: expected %s, got %s", code, expected, got)
+		}
+	}
+}
+
+func TestDisplayName(t *testing.T) {
+	if got := DisplayName("es"); got != "español" {
+		t.Fatalf("expected español, got %q", got)
+	}
+	if got := DisplayName("de"); got != "Deutsch" {
+		t.Fatalf("expected Deutsch, got %q", got)
+	}
+	if got := DisplayName("not a code"); got != "not a code" {
+		t.Fatalf("expected the code back, got %q", got)
+	}
+}
@@ -0,0 +1,74 @@
+package lang
+
+import (
+	"context"
+	"strings"
+)
+
+type pathLanguageKey struct{}
+
+// WithPathLanguage records the language taken from a URL prefix such as "/es/" so
+// handlers serving the unprefixed route know which translation was asked for.
+func WithPathLanguage(ctx context.Context, code string) context.Context {
+	return context.WithValue(ctx, pathLanguageKey{}, code)
+}
+
+// PathLanguage returns the language recorded by WithPathLanguage, or "" when the
+// request URL had no language prefix.
+func PathLanguage(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	code, _ := ctx.Value(pathLanguageKey{}).(string)
+	return code
+}
+
+// SplitPathPrefix splits a leading language segment off path, turning "/es/blog"
+// into ("es", "/blog"). Only supported languages other than the default are
+// recognised, because default-language content is served without a prefix. When
+// path has no such prefix the code is empty and path is returned unchanged.
+func SplitPathPrefix(path, defaultCode string, supported []string) (string, string) {
+	trimmed := strings.TrimPrefix(path, "/")
+	segment, rest, _ := strings.Cut(trimmed, "/")
+
+	code, err := Normalize(segment)
+	if err != nil || code == defaultCode {
+		return "", path
+	}
+	for _, candidate := range supported {
+		if candidate == code {
+			return code, "/" + rest
+		}
+	}
+	return "", path
+}
+
+// LocalizePath prefixes path with the language code, unless code is empty or the
+// default language.
+func LocalizePath(path, code, defaultCode string) string {
+	code = strings.TrimSpace(code)
+	if code == "" || code == defaultCode {
+		return path
+	}
+	if path == "" || path == "/" {
+		return "/" + code
+	}
+	return "/" + code + "/" + strings.TrimPrefix(path, "/")
+}
+
+// Match picks the entry of available that best serves the requested code: an
+// exact match first, then one sharing its base language ("es-MX" and "es").
+func Match(code string, available []string) string {
+	for _, candidate := range available {
+		if candidate == code {
+			return candidate
+		}
+	}
+	base, _, _ := strings.Cut(code, "-")
+	for _, candidate := range available {
+		if candidateBase, _, _ := strings.Cut(candidate, "-"); candidateBase == base {
+			return candidate
+		}
+	}
+	return ""
+}
@@ -0,0 +1,55 @@
+package lang
+
+import "testing"
+
+func TestSplitPathPrefix(t *testing.T) {
+	supported := []string{"en", "es", "pt-BR"}
+	cases := []struct {
+		path, code, rest string
+	}{
+		{"/es/blog/post/hola", "es", "/blog/post/hola"},
+		{"/es", "es", "/"},
+		{"/pt-br/sobre", "pt-BR", "/sobre"},
+		{"/en/about", "", "/en/about"},
+		{"/fr/about", "", "/fr/about"},
+		{"/estate", "", "/estate"},
+		{"/", "", "/"},
+	}
+	for _, tc := range cases {
+		code, rest := SplitPathPrefix(tc.path, "en", supported)
+		if code != tc.code || rest != tc.rest {
+			t.Fatalf("%s: expected (%q, %q), got (%q, %q)", tc.path, tc.code, tc.rest, code, rest)
+		}
+	}
+}
+
+func TestLocalizePath(t *testing.T) {
+	if got := LocalizePath("/blog/post/hola", "es", "en"); got != "/es/blog/post/hola" {
+		t.Fatalf("unexpected path %q", got)
+	}
+	if got := LocalizePath("/", "es", "en"); got != "/es" {
+		t.Fatalf("unexpected root path %q", got)
+	}
+	if got := LocalizePath("/about", "en", "en"); got != "/about" {
+		t.Fatalf("default language must not be prefixed, got %q", got)
+	}
+	if got := LocalizePath("/about", "", "en"); got != "/about" {
+		t.Fatalf("empty language must not be prefixed, got %q", got)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	available := []string{"en", "es", "pt-BR"}
+	if got := Match("es", available); got != "es" {
+		t.Fatalf("expected exact match, got %q", got)
+	}
+	if got := Match("es-MX", available); got != "es" {
+		t.Fatalf("expected base language match, got %q", got)
+	}
+	if got := Match("pt", available); got != "pt-BR" {
+		t.Fatalf("expected regional match, got %q", got)
+	}
+	if got := Match("fr", available); got != "" {
+		t.Fatalf("expected no match, got %q", got)
+	}
+}
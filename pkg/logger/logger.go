@@ -11,6 +11,7 @@ import (
 	"runtime/debug"
 	"strconv"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -19,6 +20,7 @@ import (
 	"github.com/rs/zerolog/log"
 	"gorm.io/gorm"
 	gormlogger "gorm.io/gorm/logger"
+	"gorm.io/gorm/utils"
 )
 
 type Format string
@@ -38,10 +40,11 @@ type Config struct {
 	Level            zerolog.Level
 	Format           Format
 	Output           io.Writer
-	outputFile       *os.File
+	outputCloser     io.Closer
 	EnableCaller     bool
 	EnableStackTrace bool
 	AdditionalFields map[string]interface{}
+	Reporting        ReportingConfig
 }
 
 var (
@@ -117,7 +120,13 @@ func ConfigFromEnv() (Config, error) {
 		EnableStackTrace: enableStackTrace,
 	}
 
+	reporting, reportingErr := reportingConfigFromEnv()
+	cfg.Reporting = reporting
+
 	var cfgErr error
+	if reportingErr != nil {
+		cfgErr = errors.Join(cfgErr, reportingErr)
+	}
 	if levelErr != nil {
 		cfgErr = errors.Join(cfgErr, levelErr)
 	}
@@ -125,13 +134,18 @@ func ConfigFromEnv() (Config, error) {
 		cfgErr = errors.Join(cfgErr, formatErr)
 	}
 
-	// If LOG_FILE is set, open the file for append and use it as output.
+	// If LOG_FILE is set, append to it, rotating it by size and age, and use it as
+	// output.
 	if filePath := strings.TrimSpace(os.Getenv("LOG_FILE")); filePath != "" {
-		f, err := os.OpenFile(filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		rotation, rotationErr := rotationConfigFromEnv(filePath)
+		if rotationErr != nil {
+			cfgErr = errors.Join(cfgErr, rotationErr)
+		}
+		f, err := OpenRotatingFile(rotation)
 		if err != nil {
-			cfgErr = errors.Join(cfgErr, fmt.Errorf("open log file: %w", err))
+			cfgErr = errors.Join(cfgErr, err)
 		} else {
-			cfg.outputFile = f
+			cfg.outputCloser = f
 			teeStdout := true
 			if val, ok, err := lookupEnvBool("LOG_FILE_TEE_STDOUT"); err != nil {
 				cfgErr = errors.Join(cfgErr, err)
@@ -159,6 +173,46 @@ func InitWithConfig(cfg Config) error {
 	return nil
 }
 
+// SetLevel changes the minimum level logged, without a restart.
+func SetLevel(level zerolog.Level) error {
+	return reconfigure(func(cfg *Config) { cfg.Level = level })
+}
+
+// SetFormat switches between JSON and console output, without a restart. The log
+// file and its rotation are kept.
+func SetFormat(format Format) error {
+	if format != FormatJSON && format != FormatConsole {
+		return fmt.Errorf("invalid log format %q", format)
+	}
+	return reconfigure(func(cfg *Config) { cfg.Format = format })
+}
+
+// CurrentFormat returns the output format in use.
+func CurrentFormat() Format {
+	if v := configValue.Load(); v != nil {
+		if cfg, ok := v.(Config); ok && cfg.Format != "" {
+			return cfg.Format
+		}
+	}
+	return FormatJSON
+}
+
+var reconfigureMu sync.Mutex
+
+func reconfigure(change func(*Config)) error {
+	reconfigureMu.Lock()
+	defer reconfigureMu.Unlock()
+
+	var cfg Config
+	if v := configValue.Load(); v != nil {
+		if current, ok := v.(Config); ok {
+			cfg = cloneConfig(current)
+		}
+	}
+	change(&cfg)
+	return InitWithConfig(cfg)
+}
+
 func Level() zerolog.Level {
 	if value := levelValue.Load(); value != nil {
 		if level, ok := value.(zerolog.Level); ok {
@@ -188,6 +242,7 @@ func Error(err error, msg string, fields map[string]interface{}) {
 	event := errorEvent(logger.Error(), err)
 	event = withFields(event, fields)
 	event.Msg(msg)
+	reportError(err, msg, fields)
 }
 
 func Warn(msg string, fields map[string]interface{}) {
@@ -216,9 +271,11 @@ func InfoContext(ctx context.Context, msg string, fields map[string]interface{})
 
 func ErrorContext(ctx context.Context, err error, msg string, fields map[string]interface{}) {
 	logger := FromContext(ctx)
+	merged := mergeContextFields(ctx, fields)
 	event := errorEvent(logger.Error(), err)
-	event = withFields(event, mergeContextFields(ctx, fields))
+	event = withFields(event, merged)
 	event.Msg(msg)
+	reportError(err, msg, merged)
 }
 
 func WarnContext(ctx context.Context, msg string, fields map[string]interface{}) {
@@ -407,18 +464,28 @@ func GinRecovery(withStack bool) gin.HandlerFunc {
 
 type GormLogger struct {
 	SlowThreshold time.Duration
+	SlowQueries   *SlowQueryLog
 }
 
 type GormLoggerOption func(*GormLogger)
 
+// WithSlowThreshold sets the duration above which statements are logged as slow;
+// zero disables slow query logging.
 func WithSlowThreshold(duration time.Duration) GormLoggerOption {
 	return func(gl *GormLogger) {
-		if duration > 0 {
+		if duration >= 0 {
 			gl.SlowThreshold = duration
 		}
 	}
 }
 
+// WithSlowQueryLog aggregates the statements slower than the threshold into log.
+func WithSlowQueryLog(log *SlowQueryLog) GormLoggerOption {
+	return func(gl *GormLogger) {
+		gl.SlowQueries = log
+	}
+}
+
 func NewGormLogger(opts ...GormLoggerOption) gormlogger.Interface {
 	gl := &GormLogger{
 		SlowThreshold: 200 * time.Millisecond,
@@ -471,7 +538,10 @@ func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (stri
 	case err != nil:
 		errorEvent(logger.Error(), err).Fields(fields).Msg("Database query error")
 	case l.SlowThreshold > 0 && elapsed > l.SlowThreshold:
+		callSite := utils.FileWithLineNum()
 		fields["threshold_ms"] = l.SlowThreshold.Milliseconds()
+		fields["call_site"] = strings.TrimPrefix(callSite, sourceRoot)
+		l.SlowQueries.Record(sql, callSite, elapsed)
 		logger.Warn().Fields(fields).Msg("Slow SQL query")
 	default:
 		logger.Debug().Fields(fields).Msg("Database query")
@@ -521,6 +591,10 @@ func applyConfig(cfg Config) error {
 
 	stackTraceEnabled.Store(cfg.EnableStackTrace)
 
+	if err := configureReporting(cfg); err != nil {
+		return err
+	}
+
 	storeLogger(logger)
 	zerolog.SetGlobalLevel(cfg.Level)
 	levelValue.Store(cfg.Level)
@@ -659,6 +733,22 @@ func resolveLogLevel(value string) (zerolog.Level, error) {
 	return level, nil
 }
 
+// ParseLevel parses a level name such as "debug" or "warn".
+func ParseLevel(value string) (zerolog.Level, error) {
+	if strings.TrimSpace(value) == "" {
+		return zerolog.InfoLevel, errors.New("log level is required")
+	}
+	return resolveLogLevel(value)
+}
+
+// ParseFormat parses "json" or "console".
+func ParseFormat(value string) (Format, error) {
+	if strings.TrimSpace(value) == "" {
+		return FormatJSON, errors.New("log format is required")
+	}
+	return resolveLogFormat(value)
+}
+
 func resolveLogFormat(value string) (Format, error) {
 	trimmed := strings.ToLower(strings.TrimSpace(value))
 
@@ -674,6 +764,51 @@ func resolveLogFormat(value string) (Format, error) {
 	}
 }
 
+func rotationConfigFromEnv(path string) (RotationConfig, error) {
+	cfg := RotationConfig{
+		Path:       path,
+		MaxSizeMB:  defaultLogMaxSizeMB,
+		MaxBackups: defaultLogMaxBackups,
+		MaxAgeDays: defaultLogMaxAgeDays,
+		Compress:   true,
+	}
+
+	var errs error
+	for key, target := range map[string]*int{
+		"LOG_FILE_MAX_SIZE_MB":  &cfg.MaxSizeMB,
+		"LOG_FILE_MAX_BACKUPS":  &cfg.MaxBackups,
+		"LOG_FILE_MAX_AGE_DAYS": &cfg.MaxAgeDays,
+	} {
+		raw := strings.TrimSpace(os.Getenv(key))
+		if raw == "" {
+			continue
+		}
+		value, err := strconv.Atoi(raw)
+		if err != nil || value < 0 {
+			errs = errors.Join(errs, fmt.Errorf("invalid non-negative integer %q for %s", raw, key))
+			continue
+		}
+		*target = value
+	}
+
+	if raw := strings.TrimSpace(os.Getenv("LOG_FILE_ROTATE_INTERVAL")); raw != "" {
+		interval, err := time.ParseDuration(raw)
+		if err != nil || interval < 0 {
+			errs = errors.Join(errs, fmt.Errorf("invalid duration %q for LOG_FILE_ROTATE_INTERVAL", raw))
+		} else {
+			cfg.Interval = interval
+		}
+	}
+
+	if val, ok, err := lookupEnvBool("LOG_FILE_COMPRESS"); err != nil {
+		errs = errors.Join(errs, err)
+	} else if ok {
+		cfg.Compress = val
+	}
+
+	return cfg, errs
+}
+
 func lookupEnvBool(key string) (bool, bool, error) {
 	raw, ok := os.LookupEnv(key)
 	if !ok {
@@ -705,13 +840,16 @@ func errorEvent(event *zerolog.Event, err error) *zerolog.Event {
 	return event.Err(err)
 }
 
-// Close closes any file used by the logger's output. It is safe to call
-// multiple times. Standard outputs (stdout/stderr) will not be closed.
+// Close sends the error reports still queued and closes any file used by the
+// logger's output. It is safe to call multiple times. Standard outputs
+// (stdout/stderr) will not be closed.
 func Close() error {
+	FlushErrorReports(reportSendTimeout)
+
 	if v := configValue.Load(); v != nil {
 		if cfg, ok := v.(Config); ok {
-			if cfg.outputFile != nil {
-				return cfg.outputFile.Close()
+			if cfg.outputCloser != nil {
+				return cfg.outputCloser.Close()
 			}
 			if cfg.Output != nil {
 				if f, ok := cfg.Output.(*os.File); ok {
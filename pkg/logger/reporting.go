@@ -0,0 +1,533 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	mathrand "math/rand"
+	"net/http"
+	"net/url"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	reportQueueSize     = 100
+	reportClientName    = "constructor-script/1.0"
+	reportSendTimeout   = 5 * time.Second
+	reportMaxFieldValue = 1024
+)
+
+// ReportingConfig sends errors to a Sentry-compatible service. Reporting is off
+// while DSN is empty.
+type ReportingConfig struct {
+	DSN string
+	// SampleRate is the fraction of errors reported, above 0 and up to 1. Zero
+	// reports every error.
+	SampleRate float64
+	// ScrubFields are extra field names, matched case-insensitively as substrings,
+	// whose values are never sent, in addition to passwords, tokens and the like.
+	ScrubFields []string
+	// SendPII includes the client IP, user agent, email and username. Without it
+	// only the user ID identifies who hit the error.
+	SendPII bool
+	// Transport overrides the HTTP client, for tests.
+	Transport http.RoundTripper
+}
+
+var defaultScrubFields = []string{"password", "secret", "token", "authorization", "cookie", "api_key", "apikey", "private_key", "credential", "dsn"}
+
+// piiFields are dropped from reports unless SendPII is set.
+var piiFields = map[string]bool{
+	"client_ip":  true,
+	"ip":         true,
+	"user_agent": true,
+	"email":      true,
+	"username":   true,
+}
+
+// reportFieldsOmitted are logged but not reported: the stack is sent as a
+// structured stacktrace, and the rest become tags or the request.
+var reportFieldsOmitted = map[string]bool{
+	"stack":       true,
+	"request_id":  true,
+	"route":       true,
+	"http_method": true,
+	"http_path":   true,
+	"host":        true,
+	"user_id":     true,
+}
+
+var reporterValue atomic.Pointer[errorReporter]
+
+type errorReporter struct {
+	endpoint    string
+	auth        string
+	dsn         string
+	sampleRate  float64
+	scrubFields []string
+	sendPII     bool
+	client      *http.Client
+
+	service     string
+	environment string
+	release     string
+	serverName  string
+
+	queue   chan []byte
+	pending atomic.Int64
+	done    chan struct{}
+}
+
+func reportingConfigFromEnv() (ReportingConfig, error) {
+	cfg := ReportingConfig{
+		DSN:        strings.TrimSpace(os.Getenv("SENTRY_DSN")),
+		SampleRate: 1,
+	}
+
+	var cfgErr error
+	if raw := strings.TrimSpace(os.Getenv("SENTRY_SAMPLE_RATE")); raw != "" {
+		rate, err := strconv.ParseFloat(raw, 64)
+		if err != nil || rate <= 0 || rate > 1 {
+			cfgErr = errors.Join(cfgErr, fmt.Errorf("invalid SENTRY_SAMPLE_RATE %q", raw))
+		} else {
+			cfg.SampleRate = rate
+		}
+	}
+
+	if raw := os.Getenv("SENTRY_SCRUB_FIELDS"); strings.TrimSpace(raw) != "" {
+		for _, field := range strings.Split(raw, ",") {
+			if field = strings.TrimSpace(field); field != "" {
+				cfg.ScrubFields = append(cfg.ScrubFields, field)
+			}
+		}
+	}
+
+	if val, ok, err := lookupEnvBool("SENTRY_SEND_PII"); err != nil {
+		cfgErr = errors.Join(cfgErr, err)
+	} else if ok {
+		cfg.SendPII = val
+	}
+
+	return cfg, cfgErr
+}
+
+// configureReporting replaces the active reporter, flushing the old one.
+func configureReporting(cfg Config) error {
+	var next *errorReporter
+	if strings.TrimSpace(cfg.Reporting.DSN) != "" {
+		reporter, err := newErrorReporter(cfg)
+		if err != nil {
+			return err
+		}
+		next = reporter
+	}
+
+	if previous := reporterValue.Swap(next); previous != nil {
+		previous.close(reportSendTimeout)
+	}
+	return nil
+}
+
+func newErrorReporter(cfg Config) (*errorReporter, error) {
+	parsed, err := url.Parse(strings.TrimSpace(cfg.Reporting.DSN))
+	if err != nil || parsed.User == nil || parsed.Host == "" {
+		return nil, errors.New("invalid SENTRY_DSN")
+	}
+	publicKey := parsed.User.Username()
+	path := strings.TrimSuffix(parsed.Path, "/")
+	slash := strings.LastIndex(path, "/")
+	projectID := path[slash+1:]
+	if publicKey == "" || projectID == "" {
+		return nil, errors.New("invalid SENTRY_DSN: missing key or project")
+	}
+
+	sampleRate := cfg.Reporting.SampleRate
+	if sampleRate <= 0 || sampleRate > 1 || math.IsNaN(sampleRate) {
+		sampleRate = 1
+	}
+
+	hostname, _ := os.Hostname()
+	reporter := &errorReporter{
+		endpoint:    fmt.Sprintf("%s://%s%s/api/%s/envelope/", parsed.Scheme, parsed.Host, path[:slash], projectID),
+		auth:        fmt.Sprintf("Sentry sentry_version=7, sentry_key=%s, sentry_client=%s", publicKey, reportClientName),
+		dsn:         parsed.String(),
+		sampleRate:  sampleRate,
+		scrubFields: append(append([]string{}, defaultScrubFields...), cfg.Reporting.ScrubFields...),
+		sendPII:     cfg.Reporting.SendPII,
+		client:      &http.Client{Timeout: reportSendTimeout, Transport: cfg.Reporting.Transport},
+		service:     cfg.Service,
+		environment: cfg.Environment,
+		release:     cfg.Version,
+		serverName:  hostname,
+		queue:       make(chan []byte, reportQueueSize),
+		done:        make(chan struct{}),
+	}
+	for i := range reporter.scrubFields {
+		reporter.scrubFields[i] = strings.ToLower(reporter.scrubFields[i])
+	}
+
+	go reporter.run()
+	return reporter, nil
+}
+
+// reportError sends err to the configured service, if any. Reports are queued and
+// sent in the background; when the queue is full they are dropped rather than
+// slowing the caller.
+func reportError(err error, msg string, fields map[string]interface{}) {
+	reporter := reporterValue.Load()
+	if reporter == nil {
+		return
+	}
+	if reporter.sampleRate < 1 && mathrand.Float64() >= reporter.sampleRate {
+		return
+	}
+
+	// Skip runtime.Callers, captureStacktrace, reportError and the logging function.
+	stacktrace := captureStacktrace(4)
+	envelope, encodeErr := reporter.envelope(reporter.event(err, msg, fields, stacktrace))
+	if encodeErr != nil {
+		return
+	}
+
+	reporter.pending.Add(1)
+	select {
+	case reporter.queue <- envelope:
+	default:
+		reporter.pending.Add(-1)
+	}
+}
+
+// FlushErrorReports waits up to timeout for queued error reports to be sent.
+func FlushErrorReports(timeout time.Duration) bool {
+	reporter := reporterValue.Load()
+	if reporter == nil {
+		return true
+	}
+	return reporter.flush(timeout)
+}
+
+func (r *errorReporter) run() {
+	for {
+		select {
+		case <-r.done:
+			return
+		case envelope := <-r.queue:
+			r.send(envelope)
+			r.pending.Add(-1)
+		}
+	}
+}
+
+func (r *errorReporter) send(envelope []byte) {
+	ctx, cancel := context.WithTimeout(context.Background(), reportSendTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.endpoint, bytes.NewReader(envelope))
+	if err != nil {
+		return
+	}
+	req.Header.Set("Content-Type", "application/x-sentry-envelope")
+	req.Header.Set("X-Sentry-Auth", r.auth)
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		// Logging through Error would report this failure too.
+		Warn("Failed to send error report", map[string]interface{}{"error": err.Error()})
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		Warn("Error report rejected", map[string]interface{}{"status": resp.StatusCode})
+	}
+}
+
+func (r *errorReporter) flush(timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for r.pending.Load() > 0 {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return true
+}
+
+func (r *errorReporter) close(timeout time.Duration) {
+	r.flush(timeout)
+	close(r.done)
+}
+
+type reportEvent struct {
+	EventID     string                 `json:"event_id"`
+	Timestamp   string                 `json:"timestamp"`
+	Level       string                 `json:"level"`
+	Platform    string                 `json:"platform"`
+	Logger      string                 `json:"logger,omitempty"`
+	Environment string                 `json:"environment,omitempty"`
+	Release     string                 `json:"release,omitempty"`
+	ServerName  string                 `json:"server_name,omitempty"`
+	Transaction string                 `json:"transaction,omitempty"`
+	Message     *reportMessage         `json:"message,omitempty"`
+	Exception   *reportExceptions      `json:"exception,omitempty"`
+	Tags        map[string]string      `json:"tags,omitempty"`
+	User        map[string]string      `json:"user,omitempty"`
+	Request     map[string]string      `json:"request,omitempty"`
+	Extra       map[string]interface{} `json:"extra,omitempty"`
+}
+
+type reportMessage struct {
+	Formatted string `json:"formatted"`
+}
+
+type reportExceptions struct {
+	Values []reportException `json:"values"`
+}
+
+type reportException struct {
+	Type       string            `json:"type"`
+	Value      string            `json:"value"`
+	Stacktrace *reportStacktrace `json:"stacktrace,omitempty"`
+}
+
+type reportStacktrace struct {
+	Frames []reportFrame `json:"frames"`
+}
+
+type reportFrame struct {
+	Function string `json:"function"`
+	Module   string `json:"module,omitempty"`
+	Filename string `json:"filename"`
+	AbsPath  string `json:"abs_path"`
+	Lineno   int    `json:"lineno"`
+	InApp    bool   `json:"in_app"`
+}
+
+func (r *errorReporter) envelope(event reportEvent) ([]byte, error) {
+	payload, encodeErr := json.Marshal(event)
+	if encodeErr != nil {
+		return nil, encodeErr
+	}
+	header, encodeErr := json.Marshal(map[string]string{
+		"event_id": event.EventID,
+		"sent_at":  event.Timestamp,
+		"dsn":      r.dsn,
+	})
+	if encodeErr != nil {
+		return nil, encodeErr
+	}
+	item, encodeErr := json.Marshal(map[string]interface{}{"type": "event", "length": len(payload)})
+	if encodeErr != nil {
+		return nil, encodeErr
+	}
+
+	var buf bytes.Buffer
+	buf.Write(header)
+	buf.WriteByte('\n')
+	buf.Write(item)
+	buf.WriteByte('\n')
+	buf.Write(payload)
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+func (r *errorReporter) event(err error, msg string, fields map[string]interface{}, stacktrace *reportStacktrace) reportEvent {
+	event := reportEvent{
+		EventID:     newEventID(),
+		Timestamp:   time.Now().UTC().Format(time.RFC3339Nano),
+		Level:       "error",
+		Platform:    "go",
+		Logger:      r.service,
+		Environment: r.environment,
+		Release:     r.release,
+		ServerName:  r.serverName,
+		Message:     &reportMessage{Formatted: msg},
+		Tags:        make(map[string]string),
+	}
+
+	if _, panicked := fields["panic"]; panicked {
+		event.Level = "fatal"
+	}
+
+	exception := reportException{Type: "error", Value: msg, Stacktrace: stacktrace}
+	if err != nil {
+		exception.Type = fmt.Sprintf("%T", unwrapRoot(err))
+		exception.Value = err.Error()
+	}
+	event.Exception = &reportExceptions{Values: []reportException{exception}}
+
+	if value := fieldString(fields, "request_id"); value != "" {
+		event.Tags["request_id"] = value
+	}
+	if value := fieldString(fields, "route"); value != "" {
+		event.Tags["route"] = value
+		event.Transaction = value
+	}
+	method := fieldString(fields, "http_method")
+	if method == "" {
+		method = fieldString(fields, "method")
+	}
+	if method != "" {
+		event.Tags["http_method"] = method
+	}
+	if path := fieldString(fields, "http_path"); path != "" {
+		event.Request = map[string]string{"method": method, "url": path}
+		if host := fieldString(fields, "host"); host != "" {
+			event.Request["url"] = "//" + host + path
+		}
+	}
+
+	if userID := fieldString(fields, "user_id"); userID != "" {
+		event.User = map[string]string{"id": userID}
+		if r.sendPII {
+			if value := fieldString(fields, "username"); value != "" {
+				event.User["username"] = value
+			}
+			if value := fieldString(fields, "email"); value != "" {
+				event.User["email"] = value
+			}
+		}
+	}
+	if r.sendPII {
+		if ip := fieldString(fields, "client_ip"); ip != "" {
+			if event.User == nil {
+				event.User = make(map[string]string)
+			}
+			event.User["ip_address"] = ip
+		}
+	}
+
+	extra := make(map[string]interface{}, len(fields))
+	for key, value := range fields {
+		if reportFieldsOmitted[key] {
+			continue
+		}
+		if !r.sendPII && piiFields[key] {
+			continue
+		}
+		if r.scrubbed(key) {
+			extra[key] = "[Filtered]"
+			continue
+		}
+		extra[key] = truncateReportValue(value)
+	}
+	if len(extra) > 0 {
+		event.Extra = extra
+	}
+
+	return event
+}
+
+func (r *errorReporter) scrubbed(key string) bool {
+	key = strings.ToLower(key)
+	for _, field := range r.scrubFields {
+		if field != "" && strings.Contains(key, field) {
+			return true
+		}
+	}
+	return false
+}
+
+func truncateReportValue(value interface{}) interface{} {
+	var text string
+	switch typed := value.(type) {
+	case string:
+		text = typed
+	case error:
+		text = typed.Error()
+	case fmt.Stringer:
+		text = typed.String()
+	default:
+		return value
+	}
+	if len(text) > reportMaxFieldValue {
+		return strings.ToValidUTF8(text[:reportMaxFieldValue], "") + "…"
+	}
+	return text
+}
+
+func fieldString(fields map[string]interface{}, key string) string {
+	value, ok := fields[key]
+	if !ok || value == nil {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
+
+func unwrapRoot(err error) error {
+	for {
+		next := errors.Unwrap(err)
+		if next == nil {
+			return err
+		}
+		err = next
+	}
+}
+
+// captureStacktrace records the stack above the skip innermost frames. Sentry lists
+// frames oldest first.
+func captureStacktrace(skip int) *reportStacktrace {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(skip, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var collected []reportFrame
+	for {
+		frame, more := frames.Next()
+		module, function := splitFunctionName(frame.Function)
+		collected = append(collected, reportFrame{
+			Function: function,
+			Module:   module,
+			Filename: shortFilename(frame.File),
+			AbsPath:  frame.File,
+			Lineno:   frame.Line,
+			InApp:    strings.HasPrefix(frame.Function, "constructor-script-backend/"),
+		})
+		if !more {
+			break
+		}
+	}
+	if len(collected) == 0 {
+		return nil
+	}
+
+	for i, j := 0, len(collected)-1; i < j; i, j = i+1, j-1 {
+		collected[i], collected[j] = collected[j], collected[i]
+	}
+	return &reportStacktrace{Frames: collected}
+}
+
+func splitFunctionName(name string) (string, string) {
+	slash := strings.LastIndex(name, "/")
+	dot := strings.Index(name[slash+1:], ".")
+	if dot < 0 {
+		return "", name
+	}
+	return name[:slash+1+dot], name[slash+1+dot+1:]
+}
+
+func shortFilename(path string) string {
+	if index := strings.LastIndex(path, "/"); index >= 0 {
+		if parent := strings.LastIndex(path[:index], "/"); parent >= 0 {
+			return path[parent+1:]
+		}
+	}
+	return path
+}
+
+func newEventID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,109 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+type captureTransport struct {
+	mu       sync.Mutex
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (t *captureTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, _ := io.ReadAll(req.Body)
+	t.mu.Lock()
+	t.requests = append(t.requests, req)
+	t.bodies = append(t.bodies, body)
+	t.mu.Unlock()
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(nil)), Header: make(http.Header)}, nil
+}
+
+func TestErrorContextIsReportedWithRequestContext(t *testing.T) {
+	transport := &captureTransport{}
+	if err := InitWithConfig(Config{
+		Service:     "test",
+		Environment: "test",
+		Output:      io.Discard,
+		Reporting: ReportingConfig{
+			DSN:         "https://public@errors.example.com/prefix/42",
+			ScrubFields: []string{"card"},
+			Transport:   transport,
+		},
+	}); err != nil {
+		t.Fatalf("InitWithConfig: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = InitWithConfig(Config{Output: io.Discard})
+	})
+
+	ctx := ContextWithFields(context.Background(), map[string]interface{}{
+		"request_id":  "req-9",
+		"route":       "/api/v1/posts/:id",
+		"http_method": "PUT",
+		"client_ip":   "192.0.2.1",
+		"user_id":     uint(5),
+	})
+	ErrorContext(ctx, errors.New("boom"), "Failed to save post", map[string]interface{}{
+		"password":    "hunter2",
+		"card_number": "4111",
+		"post_id":     7,
+	})
+
+	if !FlushErrorReports(time.Second) {
+		t.Fatal("report was not sent")
+	}
+	if len(transport.requests) != 1 {
+		t.Fatalf("sent %d reports, want 1", len(transport.requests))
+	}
+
+	req := transport.requests[0]
+	if got := req.URL.String(); got != "https://errors.example.com/prefix/api/42/envelope/" {
+		t.Errorf("endpoint = %s", got)
+	}
+	if auth := req.Header.Get("X-Sentry-Auth"); !bytes.Contains([]byte(auth), []byte("sentry_key=public")) {
+		t.Errorf("X-Sentry-Auth = %q", auth)
+	}
+
+	lines := bytes.Split(bytes.TrimSpace(transport.bodies[0]), []byte("\n"))
+	if len(lines) != 3 {
+		t.Fatalf("envelope has %d lines, want 3", len(lines))
+	}
+	var event reportEvent
+	if err := json.Unmarshal(lines[2], &event); err != nil {
+		t.Fatalf("decode event: %v", err)
+	}
+
+	if event.Tags["request_id"] != "req-9" || event.Tags["route"] != "/api/v1/posts/:id" || event.Tags["http_method"] != "PUT" {
+		t.Errorf("tags = %v", event.Tags)
+	}
+	if event.User["id"] != "5" || event.User["ip_address"] != "" {
+		t.Errorf("user = %v", event.User)
+	}
+	if event.Extra["password"] != "[Filtered]" || event.Extra["card_number"] != "[Filtered]" {
+		t.Errorf("secrets not scrubbed: %v", event.Extra)
+	}
+	if _, ok := event.Extra["client_ip"]; ok {
+		t.Errorf("client IP sent without SendPII: %v", event.Extra)
+	}
+	if event.Extra["post_id"] != float64(7) {
+		t.Errorf("extra = %v", event.Extra)
+	}
+
+	exception := event.Exception.Values[0]
+	if exception.Value != "boom" || exception.Stacktrace == nil {
+		t.Fatalf("exception = %+v", exception)
+	}
+	frames := exception.Stacktrace.Frames
+	if last := frames[len(frames)-1]; last.Function != "TestErrorContextIsReportedWithRequestContext" {
+		t.Errorf("innermost frame = %s, want the caller of ErrorContext", last.Function)
+	}
+}
@@ -0,0 +1,285 @@
+package logger
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultLogMaxSizeMB   = 100
+	defaultLogMaxBackups  = 10
+	defaultLogMaxAgeDays  = 30
+	rotatedTimeLayout     = "20060102T150405.000"
+	compressedLogSuffix   = ".gz"
+	rotatedFilePermission = 0o644
+)
+
+// RotationConfig controls how RotatingFile rolls over and cleans up log files.
+type RotationConfig struct {
+	// Path is the active log file. Rotated files are written next to it as
+	// <name>-<timestamp><ext>, gzipped when Compress is set.
+	Path string
+	// MaxSizeMB rotates the file once it grows past this size; zero disables
+	// size-based rotation.
+	MaxSizeMB int
+	// Interval rotates the file once it has been open this long; zero disables
+	// time-based rotation.
+	Interval time.Duration
+	// MaxBackups and MaxAgeDays bound the rotated files kept; zero keeps them
+	// regardless of count or age respectively.
+	MaxBackups int
+	MaxAgeDays int
+	Compress   bool
+}
+
+// RotatingFile is an io.WriteCloser appending to a log file and rotating it by size
+// and age. Rotated files are compressed and pruned in the background.
+type RotatingFile struct {
+	cfg RotationConfig
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	openedAt time.Time
+	closed   bool
+
+	cleanup sync.Mutex
+	pending sync.WaitGroup
+	now     func() time.Time
+}
+
+// OpenRotatingFile opens, or creates, the log file at cfg.Path for appending.
+func OpenRotatingFile(cfg RotationConfig) (*RotatingFile, error) {
+	cfg.Path = strings.TrimSpace(cfg.Path)
+	if cfg.Path == "" {
+		return nil, errors.New("log file path is required")
+	}
+	if cfg.MaxSizeMB < 0 || cfg.MaxBackups < 0 || cfg.MaxAgeDays < 0 || cfg.Interval < 0 {
+		return nil, errors.New("log rotation limits must not be negative")
+	}
+
+	r := &RotatingFile{cfg: cfg, now: time.Now}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *RotatingFile) open() error {
+	if dir := filepath.Dir(r.cfg.Path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("create log directory: %w", err)
+		}
+	}
+	file, err := os.OpenFile(r.cfg.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, rotatedFilePermission)
+	if err != nil {
+		return fmt.Errorf("open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("stat log file: %w", err)
+	}
+
+	r.file = file
+	r.size = info.Size()
+	r.openedAt = r.now()
+	if r.size > 0 {
+		// Age an existing file from its last write, so restarting the process does
+		// not keep postponing a time-based rotation forever.
+		r.openedAt = info.ModTime()
+	}
+	return nil
+}
+
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return 0, os.ErrClosed
+	}
+	if r.shouldRotate(int64(len(p))) {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+func (r *RotatingFile) shouldRotate(incoming int64) bool {
+	if r.size == 0 {
+		return false
+	}
+	if r.cfg.MaxSizeMB > 0 && r.size+incoming > int64(r.cfg.MaxSizeMB)*1024*1024 {
+		return true
+	}
+	return r.cfg.Interval > 0 && r.now().Sub(r.openedAt) >= r.cfg.Interval
+}
+
+// Rotate closes the current file, moves it aside and starts a new one.
+func (r *RotatingFile) Rotate() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return os.ErrClosed
+	}
+	return r.rotate()
+}
+
+func (r *RotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return fmt.Errorf("close log file: %w", err)
+	}
+
+	rotated := r.rotatedName(r.now())
+	if err := os.Rename(r.cfg.Path, rotated); err != nil && !errors.Is(err, os.ErrNotExist) {
+		// Keep logging to the current file rather than losing entries.
+		if reopenErr := r.open(); reopenErr != nil {
+			return reopenErr
+		}
+		return fmt.Errorf("rotate log file: %w", err)
+	}
+	if err := r.open(); err != nil {
+		return err
+	}
+
+	r.pending.Add(1)
+	go r.cleanupRotated(rotated)
+	return nil
+}
+
+func (r *RotatingFile) rotatedName(at time.Time) string {
+	ext := filepath.Ext(r.cfg.Path)
+	base := strings.TrimSuffix(r.cfg.Path, ext)
+	return base + "-" + at.UTC().Format(rotatedTimeLayout) + ext
+}
+
+// cleanupRotated compresses the file just rotated and removes the rotated files past
+// the retention limits. Runs are serialized so two rotations never race on a file.
+func (r *RotatingFile) cleanupRotated(rotated string) {
+	defer r.pending.Done()
+	r.cleanup.Lock()
+	defer r.cleanup.Unlock()
+
+	if r.cfg.Compress {
+		if err := compressLogFile(rotated); err != nil {
+			fmt.Fprintf(os.Stderr, "logger: compress %s: %v\n", rotated, err)
+		}
+	}
+	if err := r.prune(); err != nil {
+		fmt.Fprintf(os.Stderr, "logger: prune rotated logs: %v\n", err)
+	}
+}
+
+type rotatedFile struct {
+	path string
+	at   time.Time
+}
+
+// rotatedFiles lists the rotated copies of the log file, newest first.
+func (r *RotatingFile) rotatedFiles() ([]rotatedFile, error) {
+	dir := filepath.Dir(r.cfg.Path)
+	ext := filepath.Ext(r.cfg.Path)
+	prefix := strings.TrimSuffix(filepath.Base(r.cfg.Path), ext) + "-"
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []rotatedFile
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		stamp := strings.TrimSuffix(strings.TrimSuffix(name, compressedLogSuffix), ext)
+		at, err := time.Parse(rotatedTimeLayout, strings.TrimPrefix(stamp, prefix))
+		if err != nil {
+			continue
+		}
+		files = append(files, rotatedFile{path: filepath.Join(dir, name), at: at})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].at.After(files[j].at) })
+	return files, nil
+}
+
+func (r *RotatingFile) prune() error {
+	if r.cfg.MaxBackups == 0 && r.cfg.MaxAgeDays == 0 {
+		return nil
+	}
+	files, err := r.rotatedFiles()
+	if err != nil {
+		return err
+	}
+
+	cutoff := r.now().AddDate(0, 0, -r.cfg.MaxAgeDays)
+	var errs error
+	for index, file := range files {
+		expired := r.cfg.MaxAgeDays > 0 && file.at.Before(cutoff)
+		surplus := r.cfg.MaxBackups > 0 && index >= r.cfg.MaxBackups
+		if !expired && !surplus {
+			continue
+		}
+		if err := os.Remove(file.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = errors.Join(errs, err)
+		}
+	}
+	return errs
+}
+
+func compressLogFile(path string) error {
+	source, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	defer source.Close()
+
+	target := path + compressedLogSuffix
+	destination, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, rotatedFilePermission)
+	if err != nil {
+		return err
+	}
+
+	writer := gzip.NewWriter(destination)
+	_, copyErr := io.Copy(writer, source)
+	closeErr := errors.Join(writer.Close(), destination.Close())
+	if err := errors.Join(copyErr, closeErr); err != nil {
+		os.Remove(target)
+		return err
+	}
+
+	source.Close()
+	return os.Remove(path)
+}
+
+// Close closes the active file after waiting for pending compression. It is safe to
+// call more than once.
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil
+	}
+	r.closed = true
+	err := r.file.Close()
+	r.mu.Unlock()
+
+	r.pending.Wait()
+	return err
+}
@@ -0,0 +1,137 @@
+package logger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRotatingFileRotatesCompressesAndPrunes(t *testing.T) {
+	dir := t.TempDir()
+	clock := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	file, err := OpenRotatingFile(RotationConfig{
+		Path:       filepath.Join(dir, "app.log"),
+		Interval:   time.Hour,
+		MaxBackups: 2,
+		Compress:   true,
+	})
+	if err != nil {
+		t.Fatalf("OpenRotatingFile: %v", err)
+	}
+	var clockMu sync.Mutex
+	file.now = func() time.Time {
+		clockMu.Lock()
+		defer clockMu.Unlock()
+		return clock
+	}
+	file.openedAt = clock
+
+	for index := 0; index < 4; index++ {
+		if _, err := file.Write([]byte("entry " + string(rune('a'+index)) + "\n")); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+		clockMu.Lock()
+		clock = clock.Add(time.Hour)
+		clockMu.Unlock()
+	}
+	if err := file.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+	// Each write after the first rotates; the oldest copy, holding entry a, is pruned.
+	want := []string{"app-20260101T020000.000.log.gz", "app-20260101T030000.000.log.gz", "app.log"}
+	if strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Fatalf("files = %v, want %v", names, want)
+	}
+
+	if got := readGzip(t, filepath.Join(dir, want[1])); got != "entry c\n" {
+		t.Errorf("newest rotated file = %q", got)
+	}
+	if current, _ := os.ReadFile(filepath.Join(dir, "app.log")); string(current) != "entry d\n" {
+		t.Errorf("active file = %q", current)
+	}
+}
+
+func TestRotatingFileRotatesBySize(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	file, err := OpenRotatingFile(RotationConfig{Path: path, MaxSizeMB: 1})
+	if err != nil {
+		t.Fatalf("OpenRotatingFile: %v", err)
+	}
+	defer file.Close()
+
+	chunk := bytes.Repeat([]byte("x"), 600*1024)
+	for index := 0; index < 2; index++ {
+		if _, err := file.Write(chunk); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size() != int64(len(chunk)) {
+		t.Errorf("active file size = %d, want %d", info.Size(), len(chunk))
+	}
+	if rotated, _ := file.rotatedFiles(); len(rotated) != 1 {
+		t.Errorf("rotated files = %d, want 1", len(rotated))
+	}
+}
+
+func TestSetFormatSwitchesOutputAtRuntime(t *testing.T) {
+	var output bytes.Buffer
+	if err := InitWithConfig(Config{Output: &output, Format: FormatConsole}); err != nil {
+		t.Fatalf("InitWithConfig: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = InitWithConfig(Config{Output: io.Discard})
+	})
+
+	if err := SetFormat(FormatJSON); err != nil {
+		t.Fatalf("SetFormat: %v", err)
+	}
+	Info("switched", nil)
+	if !strings.HasPrefix(strings.TrimSpace(output.String()), "{") {
+		t.Errorf("output is not JSON: %q", output.String())
+	}
+	if CurrentFormat() != FormatJSON {
+		t.Errorf("CurrentFormat = %q", CurrentFormat())
+	}
+}
+
+func readGzip(t *testing.T, path string) string {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
@@ -0,0 +1,184 @@
+package logger
+
+import (
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultSlowQueryEntries = 200
+
+var (
+	sqlStringLiteral = regexp.MustCompile(`'(?:[^']|'')*'`)
+	sqlNumber        = regexp.MustCompile(`\b\d+(?:\.\d+)?\b`)
+	sqlPlaceholder   = regexp.MustCompile(`\$\d+`)
+	sqlValueList     = regexp.MustCompile(`\(\s*\?(?:\s*,\s*\?)*\s*\)`)
+	sqlValueRows     = regexp.MustCompile(`\(\?\)(?:\s*,\s*\(\?\))+`)
+	sqlWhitespace    = regexp.MustCompile(`\s+`)
+)
+
+// sourceRoot is the module directory, trimmed from call sites so they read as
+// repository paths.
+var sourceRoot = func() string {
+	_, file, _, ok := runtime.Caller(0)
+	if !ok {
+		return ""
+	}
+	return filepath.Dir(filepath.Dir(filepath.Dir(file))) + string(filepath.Separator)
+}()
+
+// NormalizeSQL replaces the literals, placeholders and value lists in a statement
+// with ?, so statements differing only in their arguments aggregate together.
+func NormalizeSQL(sql string) string {
+	normalized := sqlStringLiteral.ReplaceAllString(sql, "?")
+	normalized = sqlPlaceholder.ReplaceAllString(normalized, "?")
+	normalized = sqlNumber.ReplaceAllString(normalized, "?")
+	normalized = sqlValueList.ReplaceAllString(normalized, "(?)")
+	normalized = sqlValueRows.ReplaceAllString(normalized, "(?)")
+	normalized = sqlWhitespace.ReplaceAllString(normalized, " ")
+	return strings.TrimSpace(normalized)
+}
+
+// SlowQueryStat aggregates the slow executions of one normalized statement from one
+// call site.
+type SlowQueryStat struct {
+	Query     string    `json:"query"`
+	CallSite  string    `json:"call_site"`
+	Count     int64     `json:"count"`
+	TotalMs   float64   `json:"total_ms"`
+	AverageMs float64   `json:"average_ms"`
+	MaxMs     float64   `json:"max_ms"`
+	LastMs    float64   `json:"last_ms"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// SlowQueryLog keeps the statements GormLogger found slower than its threshold,
+// grouped by normalized SQL and call site. It holds a bounded number of groups and
+// evicts the least recently seen one when full.
+type SlowQueryLog struct {
+	mu         sync.Mutex
+	maxEntries int
+	since      time.Time
+	seq        uint64
+	entries    map[string]*slowQueryEntry
+}
+
+type slowQueryEntry struct {
+	SlowQueryStat
+	seq uint64
+}
+
+// NewSlowQueryLog creates a log holding at most maxEntries statement groups; zero or
+// less uses the default of 200.
+func NewSlowQueryLog(maxEntries int) *SlowQueryLog {
+	if maxEntries <= 0 {
+		maxEntries = defaultSlowQueryEntries
+	}
+	return &SlowQueryLog{
+		maxEntries: maxEntries,
+		since:      time.Now().UTC(),
+		entries:    make(map[string]*slowQueryEntry),
+	}
+}
+
+// Record adds one slow execution of sql, called from callSite.
+func (l *SlowQueryLog) Record(sql, callSite string, duration time.Duration) {
+	if l == nil {
+		return
+	}
+	query := NormalizeSQL(sql)
+	callSite = strings.TrimPrefix(callSite, sourceRoot)
+	key := callSite + "\x00" + query
+	now := time.Now().UTC()
+	ms := float64(duration) / float64(time.Millisecond)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	stat, ok := l.entries[key]
+	if !ok {
+		if len(l.entries) >= l.maxEntries {
+			l.evictOldest()
+		}
+		stat = &slowQueryEntry{SlowQueryStat: SlowQueryStat{Query: query, CallSite: callSite, FirstSeen: now}}
+		l.entries[key] = stat
+	}
+	l.seq++
+	stat.seq = l.seq
+	stat.Count++
+	stat.TotalMs += ms
+	stat.LastMs = ms
+	stat.LastSeen = now
+	if ms > stat.MaxMs {
+		stat.MaxMs = ms
+	}
+	stat.AverageMs = stat.TotalMs / float64(stat.Count)
+}
+
+func (l *SlowQueryLog) evictOldest() {
+	var oldestKey string
+	var oldest uint64
+	for key, stat := range l.entries {
+		if oldestKey == "" || stat.seq < oldest {
+			oldestKey, oldest = key, stat.seq
+		}
+	}
+	delete(l.entries, oldestKey)
+}
+
+// Since returns when the log started collecting.
+func (l *SlowQueryLog) Since() time.Time {
+	if l == nil {
+		return time.Time{}
+	}
+	return l.since
+}
+
+// Report returns up to limit statement groups ordered by sortBy: "total" (the
+// default), "max", "average", "count" or "recent". A limit of zero or less returns
+// them all.
+func (l *SlowQueryLog) Report(sortBy string, limit int) []SlowQueryStat {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	stats := make([]SlowQueryStat, 0, len(l.entries))
+	for _, stat := range l.entries {
+		stats = append(stats, stat.SlowQueryStat)
+	}
+	l.mu.Unlock()
+
+	var less func(a, b SlowQueryStat) bool
+	switch sortBy {
+	case "max":
+		less = func(a, b SlowQueryStat) bool { return a.MaxMs > b.MaxMs }
+	case "average":
+		less = func(a, b SlowQueryStat) bool { return a.AverageMs > b.AverageMs }
+	case "count":
+		less = func(a, b SlowQueryStat) bool { return a.Count > b.Count }
+	case "recent":
+		less = func(a, b SlowQueryStat) bool { return a.LastSeen.After(b.LastSeen) }
+	default:
+		less = func(a, b SlowQueryStat) bool { return a.TotalMs > b.TotalMs }
+	}
+	sort.SliceStable(stats, func(i, j int) bool {
+		if less(stats[i], stats[j]) {
+			return true
+		}
+		if less(stats[j], stats[i]) {
+			return false
+		}
+		return stats[i].Query < stats[j].Query
+	})
+
+	if limit > 0 && len(stats) > limit {
+		stats = stats[:limit]
+	}
+	return stats
+}
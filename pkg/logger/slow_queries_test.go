@@ -0,0 +1,52 @@
+package logger
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNormalizeSQLReplacesLiterals(t *testing.T) {
+	cases := map[string]string{
+		`SELECT * FROM "posts" WHERE slug = 'it''s-here' AND id = 42 LIMIT 1`:       `SELECT * FROM "posts" WHERE slug = ? AND id = ? LIMIT ?`,
+		`SELECT * FROM "tags" WHERE id IN (1, 2,3)`:                                 `SELECT * FROM "tags" WHERE id IN (?)`,
+		"INSERT INTO \"post_tags\" (\"post_id\",\"tag_id\") VALUES (1,2),(1,3)\n\t": `INSERT INTO "post_tags" ("post_id","tag_id") VALUES (?)`,
+		`UPDATE "users" SET "name"=$1 WHERE "id" = $2`:                              `UPDATE "users" SET "name"=? WHERE "id" = ?`,
+	}
+	for sql, want := range cases {
+		if got := NormalizeSQL(sql); got != want {
+			t.Errorf("NormalizeSQL(%q) = %q, want %q", sql, got, want)
+		}
+	}
+}
+
+func TestSlowQueryLogAggregatesByStatementAndCallSite(t *testing.T) {
+	log := NewSlowQueryLog(2)
+	callSite := sourceRoot + "internal/repository/post_repository.go:40"
+
+	log.Record(`SELECT * FROM "posts" WHERE id = 1`, callSite, 300*time.Millisecond)
+	log.Record(`SELECT * FROM "posts" WHERE id = 2`, callSite, 500*time.Millisecond)
+	log.Record(`SELECT count(*) FROM "posts"`, callSite, 700*time.Millisecond)
+
+	report := log.Report("total", 0)
+	if len(report) != 2 {
+		t.Fatalf("report has %d entries, want 2", len(report))
+	}
+	top := report[0]
+	if top.Query != `SELECT * FROM "posts" WHERE id = ?` || top.CallSite != "internal/repository/post_repository.go:40" {
+		t.Fatalf("top entry = %+v", top)
+	}
+	if top.Count != 2 || top.TotalMs != 800 || top.MaxMs != 500 || top.AverageMs != 400 {
+		t.Errorf("top entry stats = %+v", top)
+	}
+	if byMax := log.Report("max", 1); len(byMax) != 1 || byMax[0].MaxMs != 700 {
+		t.Errorf("report by max = %+v", byMax)
+	}
+
+	// A third group evicts the least recently seen one.
+	log.Record(`DELETE FROM "sessions" WHERE expires_at < '2024-01-01'`, callSite, time.Second)
+	for _, stat := range log.Report("total", 0) {
+		if stat.Query == `SELECT * FROM "posts" WHERE id = ?` {
+			t.Errorf("least recently seen entry was not evicted")
+		}
+	}
+}
@@ -0,0 +1,267 @@
+package mail
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Kinds of delivery feedback.
+const (
+	EventBounce    = "bounce"
+	EventComplaint = "complaint"
+)
+
+// ErrUnknownFeedback is returned for a webhook payload in no known format.
+var ErrUnknownFeedback = errors.New("unrecognised mail feedback payload")
+
+// Event is a bounce or complaint reported by the mail provider for one
+// recipient. A permanent bounce means the address does not exist; complaints
+// are always permanent.
+type Event struct {
+	Type      string
+	Recipient string
+	Permanent bool
+	Reason    string
+}
+
+// Feedback is what a provider webhook reported. SubscribeURL is set when
+// Amazon SNS asks to confirm the subscription instead.
+type Feedback struct {
+	Events       []Event
+	SubscribeURL string
+}
+
+// ParseFeedback reads the bounce and complaint webhooks of Amazon SES (through
+// SNS), SendGrid, Mailgun and Postmark. It also accepts a plain format for other
+// providers: {"type": "bounce", "email": "...", "permanent": true, "reason": "..."}
+// or an array of such objects. Delivery and open events are ignored.
+func ParseFeedback(body []byte) (Feedback, error) {
+	body = bytes.TrimSpace(body)
+	var objects []json.RawMessage
+	if len(body) > 0 && body[0] == '[' {
+		if err := json.Unmarshal(body, &objects); err != nil {
+			return Feedback{}, fmt.Errorf("invalid mail feedback payload: %w", err)
+		}
+	} else {
+		objects = []json.RawMessage{body}
+	}
+
+	var feedback Feedback
+	for _, object := range objects {
+		var keys map[string]json.RawMessage
+		if err := json.Unmarshal(object, &keys); err != nil {
+			return Feedback{}, fmt.Errorf("invalid mail feedback payload: %w", err)
+		}
+
+		var err error
+		switch {
+		case has(keys, "Type") && (has(keys, "Message") || has(keys, "SubscribeURL")):
+			err = parseSNS(object, &feedback)
+		case has(keys, "notificationType") || has(keys, "eventType"):
+			err = parseSES(object, &feedback)
+		case has(keys, "event-data"):
+			err = parseMailgun(object, &feedback)
+		case has(keys, "RecordType"):
+			err = parsePostmark(object, &feedback)
+		case has(keys, "event"):
+			err = parseSendGrid(object, &feedback)
+		case has(keys, "type"):
+			err = parsePlain(object, &feedback)
+		default:
+			err = ErrUnknownFeedback
+		}
+		if err != nil {
+			return Feedback{}, err
+		}
+	}
+	return feedback, nil
+}
+
+func has(keys map[string]json.RawMessage, key string) bool {
+	_, ok := keys[key]
+	return ok
+}
+
+func (f *Feedback) add(kind, recipient string, permanent bool, reason string) {
+	recipient = strings.TrimSpace(recipient)
+	if recipient == "" {
+		return
+	}
+	f.Events = append(f.Events, Event{
+		Type:      kind,
+		Recipient: recipient,
+		Permanent: permanent || kind == EventComplaint,
+		Reason:    strings.TrimSpace(reason),
+	})
+}
+
+func parseSNS(object []byte, feedback *Feedback) error {
+	var envelope struct {
+		Type         string
+		Message      string
+		SubscribeURL string
+	}
+	if err := json.Unmarshal(object, &envelope); err != nil {
+		return fmt.Errorf("invalid SNS message: %w", err)
+	}
+	switch envelope.Type {
+	case "SubscriptionConfirmation":
+		feedback.SubscribeURL = envelope.SubscribeURL
+		return nil
+	case "Notification":
+		return parseSES([]byte(envelope.Message), feedback)
+	default:
+		return nil
+	}
+}
+
+func parseSES(object []byte, feedback *Feedback) error {
+	var message struct {
+		NotificationType string `json:"notificationType"`
+		EventType        string `json:"eventType"`
+		Bounce           struct {
+			BounceType        string `json:"bounceType"`
+			BouncedRecipients []struct {
+				EmailAddress   string `json:"emailAddress"`
+				DiagnosticCode string `json:"diagnosticCode"`
+			} `json:"bouncedRecipients"`
+		} `json:"bounce"`
+		Complaint struct {
+			FeedbackType         string `json:"complaintFeedbackType"`
+			ComplainedRecipients []struct {
+				EmailAddress string `json:"emailAddress"`
+			} `json:"complainedRecipients"`
+		} `json:"complaint"`
+	}
+	if err := json.Unmarshal(object, &message); err != nil {
+		return fmt.Errorf("invalid SES notification: %w", err)
+	}
+
+	kind := message.NotificationType
+	if kind == "" {
+		kind = message.EventType
+	}
+	switch kind {
+	case "Bounce":
+		permanent := message.Bounce.BounceType == "Permanent"
+		for _, recipient := range message.Bounce.BouncedRecipients {
+			feedback.add(EventBounce, recipient.EmailAddress, permanent, recipient.DiagnosticCode)
+		}
+	case "Complaint":
+		for _, recipient := range message.Complaint.ComplainedRecipients {
+			feedback.add(EventComplaint, recipient.EmailAddress, true, message.Complaint.FeedbackType)
+		}
+	}
+	return nil
+}
+
+func parseMailgun(object []byte, feedback *Feedback) error {
+	var payload struct {
+		EventData struct {
+			Event          string `json:"event"`
+			Severity       string `json:"severity"`
+			Recipient      string `json:"recipient"`
+			Reason         string `json:"reason"`
+			DeliveryStatus struct {
+				Description string `json:"description"`
+				Message     string `json:"message"`
+			} `json:"delivery-status"`
+		} `json:"event-data"`
+	}
+	if err := json.Unmarshal(object, &payload); err != nil {
+		return fmt.Errorf("invalid Mailgun event: %w", err)
+	}
+
+	data := payload.EventData
+	switch data.Event {
+	case "failed":
+		reason := data.DeliveryStatus.Description
+		if reason == "" {
+			reason = data.DeliveryStatus.Message
+		}
+		if reason == "" {
+			reason = data.Reason
+		}
+		feedback.add(EventBounce, data.Recipient, data.Severity == "permanent", reason)
+	case "complained":
+		feedback.add(EventComplaint, data.Recipient, true, "")
+	}
+	return nil
+}
+
+func parsePostmark(object []byte, feedback *Feedback) error {
+	var payload struct {
+		RecordType  string
+		Type        string
+		Email       string
+		Description string
+		Details     string
+	}
+	if err := json.Unmarshal(object, &payload); err != nil {
+		return fmt.Errorf("invalid Postmark webhook: %w", err)
+	}
+
+	switch payload.RecordType {
+	case "Bounce":
+		permanent := payload.Type == "HardBounce" || payload.Type == "BadEmailAddress"
+		reason := payload.Details
+		if reason == "" {
+			reason = payload.Description
+		}
+		feedback.add(EventBounce, payload.Email, permanent, reason)
+	case "SpamComplaint":
+		feedback.add(EventComplaint, payload.Email, true, payload.Description)
+	}
+	return nil
+}
+
+func parseSendGrid(object []byte, feedback *Feedback) error {
+	var event struct {
+		Event  string `json:"event"`
+		Type   string `json:"type"`
+		Email  string `json:"email"`
+		Reason string `json:"reason"`
+	}
+	if err := json.Unmarshal(object, &event); err != nil {
+		return fmt.Errorf("invalid SendGrid event: %w", err)
+	}
+
+	switch event.Event {
+	case "bounce":
+		// SendGrid reports rejections it expects to clear up as "blocked".
+		feedback.add(EventBounce, event.Email, event.Type != "blocked", event.Reason)
+	case "spamreport":
+		feedback.add(EventComplaint, event.Email, true, "")
+	}
+	return nil
+}
+
+func parsePlain(object []byte, feedback *Feedback) error {
+	var event struct {
+		Type      string `json:"type"`
+		Email     string `json:"email"`
+		Recipient string `json:"recipient"`
+		Permanent bool   `json:"permanent"`
+		Reason    string `json:"reason"`
+	}
+	if err := json.Unmarshal(object, &event); err != nil {
+		return fmt.Errorf("invalid mail feedback event: %w", err)
+	}
+
+	recipient := event.Email
+	if recipient == "" {
+		recipient = event.Recipient
+	}
+	switch strings.ToLower(event.Type) {
+	case EventBounce:
+		feedback.add(EventBounce, recipient, event.Permanent, event.Reason)
+	case EventComplaint:
+		feedback.add(EventComplaint, recipient, true, event.Reason)
+	default:
+		return fmt.Errorf("%w: unknown type %q", ErrUnknownFeedback, event.Type)
+	}
+	return nil
+}
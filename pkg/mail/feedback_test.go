@@ -0,0 +1,80 @@
+package mail
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseFeedbackProviders(t *testing.T) {
+	sesMessage, _ := json.Marshal(map[string]interface{}{
+		"notificationType": "Bounce",
+		"bounce": map[string]interface{}{
+			"bounceType": "Permanent",
+			"bouncedRecipients": []map[string]string{
+				{"emailAddress": "gone@example.com", "diagnosticCode": "550 5.1.1 user unknown"},
+			},
+		},
+	})
+	sns, _ := json.Marshal(map[string]string{"Type": "Notification", "Message": string(sesMessage)})
+
+	cases := []struct {
+		name string
+		body string
+		want []Event
+	}{
+		{
+			name: "ses through sns",
+			body: string(sns),
+			want: []Event{{Type: EventBounce, Recipient: "gone@example.com", Permanent: true, Reason: "550 5.1.1 user unknown"}},
+		},
+		{
+			name: "sendgrid",
+			body: `[{"event":"delivered","email":"ok@example.com"},{"event":"bounce","type":"blocked","email":"busy@example.com","reason":"try later"},{"event":"spamreport","email":"angry@example.com"}]`,
+			want: []Event{
+				{Type: EventBounce, Recipient: "busy@example.com", Reason: "try later"},
+				{Type: EventComplaint, Recipient: "angry@example.com", Permanent: true},
+			},
+		},
+		{
+			name: "mailgun",
+			body: `{"signature":{},"event-data":{"event":"failed","severity":"permanent","recipient":"gone@example.com","delivery-status":{"description":"No such mailbox"}}}`,
+			want: []Event{{Type: EventBounce, Recipient: "gone@example.com", Permanent: true, Reason: "No such mailbox"}},
+		},
+		{
+			name: "postmark",
+			body: `{"RecordType":"SpamComplaint","Email":"angry@example.com"}`,
+			want: []Event{{Type: EventComplaint, Recipient: "angry@example.com", Permanent: true}},
+		},
+		{
+			name: "plain",
+			body: `{"type":"bounce","email":"gone@example.com","permanent":true}`,
+			want: []Event{{Type: EventBounce, Recipient: "gone@example.com", Permanent: true}},
+		},
+	}
+
+	for _, tc := range cases {
+		feedback, err := ParseFeedback([]byte(tc.body))
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if !reflect.DeepEqual(feedback.Events, tc.want) {
+			t.Fatalf("%s: expected %+v, got %+v", tc.name, tc.want, feedback.Events)
+		}
+	}
+}
+
+func TestParseFeedbackSubscriptionAndErrors(t *testing.T) {
+	feedback, err := ParseFeedback([]byte(`{"Type":"SubscriptionConfirmation","SubscribeURL":"https://sns.eu-west-1.amazonaws.com/?Action=ConfirmSubscription"}`))
+	if err != nil || feedback.SubscribeURL == "" || len(feedback.Events) != 0 {
+		t.Fatalf("expected a subscription confirmation, got %+v, %v", feedback, err)
+	}
+
+	if _, err := ParseFeedback([]byte(`{"hello":"world"}`)); !errors.Is(err, ErrUnknownFeedback) {
+		t.Fatalf("expected ErrUnknownFeedback, got %v", err)
+	}
+	if _, err := ParseFeedback([]byte(`not json`)); err == nil {
+		t.Fatal("expected invalid JSON to be rejected")
+	}
+}
@@ -0,0 +1,254 @@
+// Package mail composes email messages and delivers them over SMTP.
+package mail
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"constructor-script-backend/pkg/logger"
+)
+
+const (
+	dialTimeout    = 12 * time.Second
+	overallTimeout = 20 * time.Second
+
+	// MaxAttempts is how often a queued message is tried before it is given up.
+	MaxAttempts = 6
+
+	retryBaseDelay = time.Minute
+	retryMaxDelay  = 2 * time.Hour
+)
+
+// ErrNotConfigured is returned when email is disabled or the SMTP settings are
+// incomplete.
+var ErrNotConfigured = errors.New("email service is disabled or not configured")
+
+// Config holds the SMTP server and sender address.
+type Config struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// Configured reports whether the server and credentials are set.
+func (c Config) Configured() bool {
+	return strings.TrimSpace(c.Host) != "" && strings.TrimSpace(c.Username) != "" && strings.TrimSpace(c.Password) != ""
+}
+
+// Message is an email to a single recipient. A message with both bodies is sent
+// as multipart/alternative; one with only Text is sent as plain text.
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+	// Unsubscribe is an HTTPS URL that unsubscribes the recipient when it receives
+	// a POST. It is announced to mail clients for one-click unsubscribe (RFC 8058).
+	Unsubscribe string
+}
+
+// Compose returns the message as sent over the wire, headers included.
+func (m Message) Compose(from string) ([]byte, error) {
+	contentType, body, err := m.body()
+	if err != nil {
+		return nil, err
+	}
+
+	var builder bytes.Buffer
+	type header struct{ key, value string }
+	headers := []header{
+		{"From", strings.TrimSpace(from)},
+		{"To", strings.TrimSpace(m.To)},
+		{"Subject", mime.QEncoding.Encode("utf-8", m.Subject)},
+		{"Date", time.Now().Format(time.RFC1123Z)},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", contentType},
+	}
+	if unsubscribe := strings.TrimSpace(m.Unsubscribe); unsubscribe != "" {
+		headers = append(headers,
+			header{"List-Unsubscribe", "<" + unsubscribe + ">"},
+			header{"List-Unsubscribe-Post", "List-Unsubscribe=One-Click"},
+		)
+	}
+	for _, h := range headers {
+		builder.WriteString(h.key)
+		builder.WriteString(": ")
+		builder.WriteString(h.value)
+		builder.WriteString("\r\n")
+	}
+	builder.WriteString("\r\n")
+	builder.Write(body)
+	return builder.Bytes(), nil
+}
+
+func (m Message) body() (string, []byte, error) {
+	if m.HTML == "" {
+		return "text/plain; charset=UTF-8", []byte(m.Text), nil
+	}
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	parts := []struct {
+		contentType string
+		content     string
+	}{
+		{contentType: "text/plain; charset=UTF-8", content: m.Text},
+		{contentType: "text/html; charset=UTF-8", content: m.HTML},
+	}
+	for _, part := range parts {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", part.contentType)
+		header.Set("Content-Transfer-Encoding", "quoted-printable")
+
+		partWriter, err := writer.CreatePart(header)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to build email part: %w", err)
+		}
+		encoder := quotedprintable.NewWriter(partWriter)
+		if _, err := encoder.Write([]byte(part.content)); err != nil {
+			return "", nil, fmt.Errorf("failed to encode email part: %w", err)
+		}
+		if err := encoder.Close(); err != nil {
+			return "", nil, fmt.Errorf("failed to encode email part: %w", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return "", nil, fmt.Errorf("failed to finalize email body: %w", err)
+	}
+
+	return "multipart/alternative; boundary=" + writer.Boundary(), body.Bytes(), nil
+}
+
+// Send delivers msg through the SMTP server of cfg, upgrading the connection with
+// STARTTLS when the server offers it.
+func Send(cfg Config, msg Message) error {
+	if !cfg.Configured() {
+		return ErrNotConfigured
+	}
+
+	messageBytes, err := msg.Compose(cfg.From)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	addr := net.JoinHostPort(cfg.Host, cfg.Port)
+	recipient := strings.TrimSpace(msg.To)
+	fields := map[string]interface{}{
+		"smtp_host":          cfg.Host,
+		"smtp_port":          cfg.Port,
+		"smtp_from":          cfg.From,
+		"smtp_address":       addr,
+		"to":                 recipient,
+		"subject":            msg.Subject,
+		"message_bytes":      len(messageBytes),
+		"auth_mechanism":     "PLAIN",
+		"dial_timeout_ms":    dialTimeout.Milliseconds(),
+		"overall_timeout_ms": overallTimeout.Milliseconds(),
+	}
+	logger.Info("Starting SMTP send", fields)
+
+	dialer := net.Dialer{Timeout: dialTimeout}
+	conn, err := dialer.Dial("tcp", addr)
+	fields["dial_duration_ms"] = time.Since(start).Milliseconds()
+	if err != nil {
+		logger.Error(err, "SMTP dial failed", fields)
+		return fmt.Errorf("failed to dial SMTP server: %w", err)
+	}
+	if err := conn.SetDeadline(time.Now().Add(overallTimeout)); err != nil {
+		logger.Warn("Failed to apply SMTP deadline", map[string]interface{}{
+			"smtp_address":   addr,
+			"deadline_error": err.Error(),
+		})
+	}
+
+	client, err := smtp.NewClient(conn, cfg.Host)
+	if err != nil {
+		_ = conn.Close()
+		logger.Error(err, "Failed to create SMTP client", fields)
+		return fmt.Errorf("failed to create SMTP client: %w", err)
+	}
+	defer client.Close()
+
+	startTLSEnabled := false
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: cfg.Host}); err != nil {
+			logger.Error(err, "SMTP STARTTLS failed", fields)
+			return fmt.Errorf("failed to start TLS for SMTP: %w", err)
+		}
+		startTLSEnabled = true
+	}
+	fields["starttls_enabled"] = startTLSEnabled
+
+	if err := client.Auth(smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)); err != nil {
+		logger.Error(err, "SMTP authentication failed", fields)
+		return fmt.Errorf("smtp authentication failed: %w", err)
+	}
+	if err := client.Mail(strings.TrimSpace(cfg.From)); err != nil {
+		logger.Error(err, "SMTP MAIL FROM failed", fields)
+		return fmt.Errorf("failed to set mail sender: %w", err)
+	}
+	if err := client.Rcpt(recipient); err != nil {
+		logger.Error(err, "SMTP RCPT TO failed", fields)
+		return fmt.Errorf("failed to set mail recipient: %w", err)
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		logger.Error(err, "SMTP DATA command failed", fields)
+		return fmt.Errorf("failed to start SMTP data transmission: %w", err)
+	}
+	if _, err := writer.Write(messageBytes); err != nil {
+		_ = writer.Close()
+		logger.Error(err, "SMTP DATA write failed", fields)
+		return fmt.Errorf("failed to write SMTP message: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		logger.Error(err, "SMTP DATA close failed", fields)
+		return fmt.Errorf("failed to finalize SMTP message: %w", err)
+	}
+
+	if err := client.Quit(); err != nil {
+		logger.Warn("SMTP QUIT returned error", map[string]interface{}{
+			"smtp_address": addr,
+			"quit_error":   err.Error(),
+		})
+	}
+
+	fields["duration_ms"] = time.Since(start).Milliseconds()
+	logger.Info("Email sent via SMTP", fields)
+	return nil
+}
+
+// Permanent reports whether err is a permanent SMTP failure (a 5xx reply, such
+// as an unknown recipient or rejected credentials) that retrying cannot fix.
+func Permanent(err error) bool {
+	var reply *textproto.Error
+	return errors.As(err, &reply) && reply.Code >= 500 && reply.Code < 600
+}
+
+// RetryDelay returns how long to wait before trying a message again after the
+// given number of failed attempts: a minute, doubling up to two hours.
+func RetryDelay(attempts int) time.Duration {
+	delay := retryBaseDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= retryMaxDelay {
+			return retryMaxDelay
+		}
+	}
+	return delay
+}
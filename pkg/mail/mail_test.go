@@ -0,0 +1,68 @@
+package mail
+
+import (
+	"errors"
+	"fmt"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestComposeMultipart(t *testing.T) {
+	raw, err := Message{To: "reader@example.com", Subject: "Grüße", Text: "Hello", HTML: "<p>Hello</p>"}.Compose("site@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := string(raw)
+	for _, want := range []string{
+		"From: site@example.com\r\n",
+		"To: reader@example.com\r\n",
+		"Subject: =?utf-8?q?Gr=C3=BC=C3=9Fe?=\r\n",
+		"Content-Type: multipart/alternative; boundary=",
+		"Content-Type: text/html; charset=UTF-8",
+	} {
+		if !strings.Contains(message, want) {
+			t.Fatalf("expected %q in\n%s", want, message)
+		}
+	}
+
+	raw, err = Message{To: "reader@example.com", Subject: "Plain", Text: "Hello"}.Compose("site@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(raw), "Content-Type: text/plain; charset=UTF-8\r\n\r\nHello") {
+		t.Fatalf("expected a plain text message, got\n%s", raw)
+	}
+	if strings.Contains(string(raw), "List-Unsubscribe") {
+		t.Fatalf("expected no unsubscribe headers without a URL, got\n%s", raw)
+	}
+
+	raw, err = Message{To: "reader@example.com", Subject: "Digest", Text: "Hello", Unsubscribe: "https://example.com/digest/unsubscribe/abc"}.Compose("site@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"List-Unsubscribe: <https://example.com/digest/unsubscribe/abc>\r\n",
+		"List-Unsubscribe-Post: List-Unsubscribe=One-Click\r\n",
+	} {
+		if !strings.Contains(string(raw), want) {
+			t.Fatalf("expected %q in\n%s", want, raw)
+		}
+	}
+}
+
+func TestPermanent(t *testing.T) {
+	if !Permanent(fmt.Errorf("failed to set mail recipient: %w", &textproto.Error{Code: 550, Msg: "no such user"})) {
+		t.Fatal("expected a 5xx reply to be permanent")
+	}
+	if Permanent(&textproto.Error{Code: 451, Msg: "try again later"}) || Permanent(errors.New("connection refused")) {
+		t.Fatal("expected temporary failures to be retried")
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	if RetryDelay(1) != time.Minute || RetryDelay(3) != 4*time.Minute || RetryDelay(20) != 2*time.Hour {
+		t.Fatalf("unexpected delays %v %v %v", RetryDelay(1), RetryDelay(3), RetryDelay(20))
+	}
+}
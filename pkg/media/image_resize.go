@@ -0,0 +1,259 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	_ "image/gif"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// ImageFormat names an encoding that image variants can be written in.
+type ImageFormat string
+
+const (
+	ImageFormatJPEG ImageFormat = "jpeg"
+	ImageFormatPNG  ImageFormat = "png"
+	ImageFormatWebP ImageFormat = "webp"
+	ImageFormatAVIF ImageFormat = "avif"
+)
+
+// ErrEncoderUnavailable is returned when a format needs an external encoder that is not
+// installed. The standard library has no WebP or AVIF encoder, so those formats are
+// written with cwebp and avifenc when they are on PATH.
+var ErrEncoderUnavailable = errors.New("image encoder unavailable")
+
+var externalEncoders = map[ImageFormat]string{
+	ImageFormatWebP: "cwebp",
+	ImageFormatAVIF: "avifenc",
+}
+
+// Extension returns the file extension used for the format, including the dot.
+func (f ImageFormat) Extension() string {
+	switch f {
+	case ImageFormatJPEG:
+		return ".jpg"
+	case ImageFormatPNG:
+		return ".png"
+	case ImageFormatWebP:
+		return ".webp"
+	case ImageFormatAVIF:
+		return ".avif"
+	default:
+		return ""
+	}
+}
+
+// MIMEType returns the content type browsers expect for the format.
+func (f ImageFormat) MIMEType() string {
+	if f == "" {
+		return ""
+	}
+	return "image/" + string(f)
+}
+
+// ImageFormatFromExtension maps a file extension to a format variants can be
+// written in. GIF, SVG and ICO files are not resized and report false.
+func ImageFormatFromExtension(ext string) (ImageFormat, bool) {
+	switch strings.ToLower(strings.TrimPrefix(ext, ".")) {
+	case "jpg", "jpeg":
+		return ImageFormatJPEG, true
+	case "png":
+		return ImageFormatPNG, true
+	case "webp":
+		return ImageFormatWebP, true
+	case "avif":
+		return ImageFormatAVIF, true
+	default:
+		return "", false
+	}
+}
+
+// EncoderAvailable reports whether images can be written in the format on this host.
+func EncoderAvailable(format ImageFormat) bool {
+	switch format {
+	case ImageFormatJPEG, ImageFormatPNG:
+		return true
+	}
+	command, ok := externalEncoders[format]
+	if !ok {
+		return false
+	}
+	_, err := exec.LookPath(command)
+	return err == nil
+}
+
+// DecodeImageFile decodes a JPEG, PNG, GIF or WebP file.
+func DecodeImageFile(path string) (image.Image, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("decode image: %w", err)
+	}
+	return img, nil
+}
+
+// ResizeToWidth scales img to width, keeping its aspect ratio. Images that are already
+// narrower are returned unchanged.
+func ResizeToWidth(img image.Image, width int) image.Image {
+	bounds := img.Bounds()
+	if width <= 0 || bounds.Dx() <= width {
+		return img
+	}
+
+	height := bounds.Dy() * width / bounds.Dx()
+	if height < 1 {
+		height = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Over, nil)
+	return dst
+}
+
+// ImageFit controls how an image is scaled into a target box.
+type ImageFit string
+
+const (
+	// ImageFitContain scales the image to fit inside the box, keeping its aspect ratio.
+	ImageFitContain ImageFit = "contain"
+	// ImageFitCover scales the image to fill the box and crops the overflow around the centre.
+	ImageFitCover ImageFit = "cover"
+)
+
+// ResizeToBox scales img into a width x height box. A zero dimension is derived from
+// the other one. Images are never upscaled.
+func ResizeToBox(img image.Image, width, height int, fit ImageFit) image.Image {
+	bounds := img.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+	if srcW == 0 || srcH == 0 {
+		return img
+	}
+	if height <= 0 {
+		return ResizeToWidth(img, width)
+	}
+	if width <= 0 {
+		width = srcW * height / srcH
+		if width < 1 {
+			width = 1
+		}
+		return ResizeToWidth(img, width)
+	}
+	if width > srcW {
+		height = height * srcW / width
+		width = srcW
+	}
+	if height > srcH {
+		width = width * srcH / height
+		height = srcH
+	}
+	if width < 1 || height < 1 {
+		return img
+	}
+
+	if fit != ImageFitCover {
+		// Contain: the limiting side decides the scale.
+		if srcW*height > srcH*width {
+			return ResizeToWidth(img, width)
+		}
+		return ResizeToWidth(img, max(1, srcW*height/srcH))
+	}
+
+	// Cover: crop the source to the target aspect ratio, then scale.
+	crop := bounds
+	if srcW*height > srcH*width {
+		cropW := srcH * width / height
+		crop.Min.X += (srcW - cropW) / 2
+		crop.Max.X = crop.Min.X + cropW
+	} else {
+		cropH := srcW * height / width
+		crop.Min.Y += (srcH - cropH) / 2
+		crop.Max.Y = crop.Min.Y + cropH
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, crop, draw.Over, nil)
+	return dst
+}
+
+// EncodeImageFile writes img to path in the requested format. quality applies to lossy
+// formats and is clamped to 1-100.
+func EncodeImageFile(ctx context.Context, path string, img image.Image, format ImageFormat, quality int) error {
+	if quality <= 0 || quality > 100 {
+		quality = 80
+	}
+
+	switch format {
+	case ImageFormatJPEG:
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+			return err
+		}
+		return os.WriteFile(path, buf.Bytes(), 0644)
+	case ImageFormatPNG:
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, img); err != nil {
+			return err
+		}
+		return os.WriteFile(path, buf.Bytes(), 0644)
+	case ImageFormatWebP, ImageFormatAVIF:
+		return encodeExternal(ctx, path, img, format, quality)
+	default:
+		return fmt.Errorf("unsupported image format %q", format)
+	}
+}
+
+// encodeExternal hands a lossless PNG intermediate to the format's command line encoder.
+func encodeExternal(ctx context.Context, path string, img image.Image, format ImageFormat, quality int) error {
+	command, err := exec.LookPath(externalEncoders[format])
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrEncoderUnavailable, format)
+	}
+
+	source, err := os.CreateTemp(filepath.Dir(path), ".variant-*.png")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(source.Name())
+
+	if err := png.Encode(source, img); err != nil {
+		source.Close()
+		return err
+	}
+	if err := source.Close(); err != nil {
+		return err
+	}
+
+	q := strconv.Itoa(quality)
+	var args []string
+	switch format {
+	case ImageFormatWebP:
+		args = []string{"-quiet", "-q", q, source.Name(), "-o", path}
+	case ImageFormatAVIF:
+		args = []string{"--speed", "6", "-q", q, source.Name(), path}
+	}
+
+	output, err := exec.CommandContext(ctx, command, args...).CombinedOutput()
+	if err != nil {
+		os.Remove(path)
+		return fmt.Errorf("%s failed: %w: %s", filepath.Base(command), err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
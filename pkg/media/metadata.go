@@ -0,0 +1,256 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+// ErrMalformedImage is returned when an image's container structure cannot be parsed.
+var ErrMalformedImage = errors.New("malformed image")
+
+// StripImageMetadata removes EXIF, XMP and text metadata from JPEG, PNG and WebP data
+// without re-encoding the pixels. Colour profiles are kept, and so is the JPEG
+// orientation, since dropping it would display photos rotated. Other formats are
+// returned unchanged.
+func StripImageMetadata(data []byte, format ImageFormat) ([]byte, error) {
+	switch format {
+	case ImageFormatJPEG:
+		return stripJPEGMetadata(data)
+	case ImageFormatPNG:
+		return stripPNGMetadata(data)
+	case ImageFormatWebP:
+		return stripWebPMetadata(data)
+	default:
+		return data, nil
+	}
+}
+
+const (
+	jpegMarkerSOI  = 0xD8
+	jpegMarkerSOS  = 0xDA
+	jpegMarkerEOI  = 0xD9
+	jpegMarkerAPP0 = 0xE0
+	jpegMarkerAPP1 = 0xE1
+	jpegMarkerAPP2 = 0xE2
+	jpegMarkerAPPF = 0xEF
+	jpegMarkerCOM  = 0xFE
+)
+
+func stripJPEGMetadata(data []byte) ([]byte, error) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != jpegMarkerSOI {
+		return nil, ErrMalformedImage
+	}
+
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(data[:2])
+
+	orientation := 0
+	pos := 2
+	for pos < len(data) {
+		if data[pos] != 0xFF {
+			return nil, ErrMalformedImage
+		}
+		// Markers may be preceded by any number of 0xFF fill bytes.
+		for pos < len(data) && data[pos] == 0xFF {
+			pos++
+		}
+		if pos >= len(data) {
+			return nil, ErrMalformedImage
+		}
+		marker := data[pos]
+		pos++
+
+		if marker == jpegMarkerEOI || (marker >= 0xD0 && marker <= 0xD7) || marker == 0x01 {
+			out.Write([]byte{0xFF, marker})
+			continue
+		}
+		if pos+2 > len(data) {
+			return nil, ErrMalformedImage
+		}
+		length := int(binary.BigEndian.Uint16(data[pos : pos+2]))
+		if length < 2 || pos+length > len(data) {
+			return nil, ErrMalformedImage
+		}
+		segment := data[pos : pos+length]
+
+		if marker == jpegMarkerSOS {
+			if orientation > 1 {
+				out.Write(orientationSegment(orientation))
+			}
+			// Entropy-coded data and everything after it is copied as is.
+			out.Write([]byte{0xFF, marker})
+			out.Write(data[pos:])
+			return out.Bytes(), nil
+		}
+
+		keep := true
+		switch {
+		case marker == jpegMarkerAPP1:
+			if value := exifOrientation(segment[2:]); value > 0 {
+				orientation = value
+			}
+			keep = false
+		case marker == jpegMarkerCOM:
+			keep = false
+		case marker > jpegMarkerAPP0 && marker <= jpegMarkerAPPF && marker != jpegMarkerAPP2:
+			// APP3-APP15 carry vendor and IPTC data; APP0 (JFIF) and APP2 (ICC) stay.
+			keep = false
+		case marker == jpegMarkerAPP2 && !bytes.HasPrefix(segment[2:], []byte("ICC_PROFILE\x00")):
+			keep = false
+		}
+
+		if keep {
+			out.Write([]byte{0xFF, marker})
+			out.Write(segment)
+		}
+		pos += length
+	}
+
+	return nil, ErrMalformedImage
+}
+
+// exifOrientation reads the orientation tag from an APP1 payload, returning 0 when
+// the payload is not EXIF or has no orientation.
+func exifOrientation(payload []byte) int {
+	if !bytes.HasPrefix(payload, []byte("Exif\x00\x00")) {
+		return 0
+	}
+	tiff := payload[6:]
+	if len(tiff) < 8 {
+		return 0
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+
+	offset := int(order.Uint32(tiff[4:8]))
+	if offset < 8 || offset+2 > len(tiff) {
+		return 0
+	}
+	count := int(order.Uint16(tiff[offset : offset+2]))
+	for i := 0; i < count; i++ {
+		entry := offset + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 0
+		}
+		if order.Uint16(tiff[entry:entry+2]) == 0x0112 {
+			value := int(order.Uint16(tiff[entry+8 : entry+10]))
+			if value >= 1 && value <= 8 {
+				return value
+			}
+			return 0
+		}
+	}
+	return 0
+}
+
+// orientationSegment builds a minimal APP1 EXIF segment carrying only the orientation.
+func orientationSegment(orientation int) []byte {
+	payload := []byte("Exif\x00\x00")
+	payload = append(payload, 'M', 'M', 0x00, 0x2A, 0x00, 0x00, 0x00, 0x08)
+	payload = append(payload, 0x00, 0x01)             // one IFD0 entry
+	payload = append(payload, 0x01, 0x12, 0x00, 0x03) // Orientation, SHORT
+	payload = append(payload, 0x00, 0x00, 0x00, 0x01) // count 1
+	payload = append(payload, 0x00, byte(orientation), 0x00, 0x00)
+	payload = append(payload, 0x00, 0x00, 0x00, 0x00) // no next IFD
+
+	segment := []byte{0xFF, jpegMarkerAPP1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	return append(segment, payload...)
+}
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// pngMetadataChunks lists ancillary chunks that carry EXIF, XMP or free text.
+var pngMetadataChunks = map[string]struct{}{
+	"eXIf": {},
+	"tEXt": {},
+	"zTXt": {},
+	"iTXt": {},
+	"tIME": {},
+}
+
+func stripPNGMetadata(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, pngSignature) {
+		return nil, ErrMalformedImage
+	}
+
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(pngSignature)
+
+	pos := len(pngSignature)
+	for pos < len(data) {
+		if pos+8 > len(data) {
+			return nil, ErrMalformedImage
+		}
+		length := int(binary.BigEndian.Uint32(data[pos : pos+4]))
+		end := pos + 12 + length
+		if length < 0 || end > len(data) {
+			return nil, ErrMalformedImage
+		}
+		kind := string(data[pos+4 : pos+8])
+		if _, drop := pngMetadataChunks[kind]; !drop {
+			out.Write(data[pos:end])
+		}
+		pos = end
+		if kind == "IEND" {
+			return out.Bytes(), nil
+		}
+	}
+
+	return nil, ErrMalformedImage
+}
+
+const (
+	webpFlagXMP  = 0x04
+	webpFlagEXIF = 0x08
+)
+
+func stripWebPMetadata(data []byte) ([]byte, error) {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, ErrMalformedImage
+	}
+
+	body := bytes.NewBuffer(make([]byte, 0, len(data)))
+	body.WriteString("WEBP")
+
+	pos := 12
+	for pos+8 <= len(data) {
+		kind := string(data[pos : pos+4])
+		size := int(binary.LittleEndian.Uint32(data[pos+4 : pos+8]))
+		end := pos + 8 + size + size%2
+		if end == len(data)+1 && size%2 == 1 {
+			// Some encoders omit the padding byte after an odd-sized final chunk.
+			end--
+		}
+		if size < 0 || end > len(data) {
+			return nil, ErrMalformedImage
+		}
+
+		switch kind {
+		case "EXIF", "XMP ":
+		case "VP8X":
+			chunk := append([]byte(nil), data[pos:end]...)
+			if len(chunk) > 8 {
+				chunk[8] &^= webpFlagEXIF | webpFlagXMP
+			}
+			body.Write(chunk)
+		default:
+			body.Write(data[pos:end])
+		}
+		pos = end
+	}
+
+	out := make([]byte, 8, body.Len()+8)
+	copy(out, "RIFF")
+	binary.LittleEndian.PutUint32(out[4:8], uint32(body.Len()))
+	return append(out, body.Bytes()...), nil
+}
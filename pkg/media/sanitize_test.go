@@ -0,0 +1,119 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func TestStripJPEGMetadataKeepsOrientation(t *testing.T) {
+	var encoded bytes.Buffer
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	if err := jpeg.Encode(&encoded, img, nil); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+
+	// Insert an EXIF segment with orientation 6 and a GPS-looking payload, plus a comment.
+	exif := orientationSegment(6)
+	exif = append(exif[:len(exif):len(exif)], []byte("GPSLatitude")...)
+	binary.BigEndian.PutUint16(exif[2:4], uint16(len(exif)-2))
+	comment := []byte{0xFF, jpegMarkerCOM, 0x00, 0x07, 's', 'e', 'c', 'r', 'e'}
+	data := append([]byte{}, encoded.Bytes()[:2]...)
+	data = append(data, exif...)
+	data = append(data, comment...)
+	data = append(data, encoded.Bytes()[2:]...)
+
+	stripped, err := StripImageMetadata(data, ImageFormatJPEG)
+	if err != nil {
+		t.Fatalf("strip: %v", err)
+	}
+	if bytes.Contains(stripped, []byte("GPSLatitude")) || bytes.Contains(stripped, []byte("secre")) {
+		t.Fatal("expected metadata to be removed")
+	}
+	if !bytes.Contains(stripped, []byte("Exif\x00\x00")) {
+		t.Fatal("expected orientation segment to be kept")
+	}
+	if _, err := jpeg.Decode(bytes.NewReader(stripped)); err != nil {
+		t.Fatalf("stripped jpeg does not decode: %v", err)
+	}
+}
+
+func TestStripPNGMetadata(t *testing.T) {
+	var encoded bytes.Buffer
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	img.Set(0, 0, color.RGBA{R: 255, A: 255})
+	if err := png.Encode(&encoded, img); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+
+	raw := encoded.Bytes()
+	data := append([]byte{}, raw[:len(pngSignature)+25]...) // signature + IHDR
+	data = append(data, pngChunk("tEXt", []byte("Author\x00Jane"))...)
+	data = append(data, pngChunk("eXIf", []byte("MM\x00\x2a"))...)
+	data = append(data, raw[len(pngSignature)+25:]...)
+
+	stripped, err := StripImageMetadata(data, ImageFormatPNG)
+	if err != nil {
+		t.Fatalf("strip: %v", err)
+	}
+	if !bytes.Equal(stripped, raw) {
+		t.Fatal("expected metadata chunks to be removed and the rest unchanged")
+	}
+}
+
+func TestStripImageMetadataRejectsMalformed(t *testing.T) {
+	if _, err := StripImageMetadata([]byte("not an image"), ImageFormatJPEG); err != ErrMalformedImage {
+		t.Fatalf("expected ErrMalformedImage, got %v", err)
+	}
+}
+
+func TestSanitizeSVG(t *testing.T) {
+	input := `<?xml version="1.0"?>
+<!DOCTYPE svg [<!ENTITY x "y">]>
+<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" onload="alert(1)">
+  <script>alert(2)</script>
+  <a xlink:href="java&#9;script:alert(3)"><circle r="4" onclick="alert(4)"/></a>
+  <a href="#shape"><rect width="1" height="1" style="fill:red"/></a>
+  <set attributeName="href" to="javascript:alert(5)"/>
+  <foreignObject><div>hi</div></foreignObject>
+  <image href="data:text/html,boom"/>
+  <image href="data:image/png;base64,AAAA"/>
+</svg>`
+
+	out, err := SanitizeSVG([]byte(input))
+	if err != nil {
+		t.Fatalf("sanitize: %v", err)
+	}
+	result := string(out)
+
+	for _, forbidden := range []string{"alert", "script", "onload", "onclick", "DOCTYPE", "foreignObject", "text/html"} {
+		if strings.Contains(result, forbidden) {
+			t.Fatalf("expected %q to be removed, got %s", forbidden, result)
+		}
+	}
+	for _, kept := range []string{`href="#shape"`, `style="fill:red"`, `xmlns:xlink=`, "data:image/png"} {
+		if !strings.Contains(result, kept) {
+			t.Fatalf("expected %q to be kept, got %s", kept, result)
+		}
+	}
+}
+
+func TestSanitizeSVGRejectsNonSVG(t *testing.T) {
+	if _, err := SanitizeSVG([]byte(`<html><body/></html>`)); err == nil {
+		t.Fatal("expected an error for a non-svg document")
+	}
+}
+
+func pngChunk(kind string, payload []byte) []byte {
+	chunk := make([]byte, 8, 12+len(payload))
+	binary.BigEndian.PutUint32(chunk[:4], uint32(len(payload)))
+	copy(chunk[4:8], kind)
+	chunk = append(chunk, payload...)
+	return binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+}
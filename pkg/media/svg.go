@@ -0,0 +1,190 @@
+package media
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrInvalidSVG is returned when an SVG document cannot be parsed.
+var ErrInvalidSVG = errors.New("invalid svg")
+
+// svgForbiddenElements are dropped together with their content.
+var svgForbiddenElements = map[string]struct{}{
+	"script":        {},
+	"foreignobject": {},
+	"iframe":        {},
+	"embed":         {},
+	"object":        {},
+	"handler":       {},
+	"listener":      {},
+}
+
+// svgAnimationElements can rewrite attributes at runtime; they are dropped when they
+// target links or event handlers.
+var svgAnimationElements = map[string]struct{}{
+	"set":              {},
+	"animate":          {},
+	"animatetransform": {},
+	"animatemotion":    {},
+}
+
+// SanitizeSVG removes scripts, event handler attributes, embedded documents and
+// javascript: or data: links from an SVG document, so an uploaded SVG opened directly
+// cannot run code in the site's origin. DOCTYPE declarations are dropped to rule out
+// entity expansion.
+func SanitizeSVG(data []byte) ([]byte, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = true
+
+	var out bytes.Buffer
+	skipDepth := 0
+	sawRoot := false
+
+	for {
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidSVG, err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if skipDepth > 0 {
+				skipDepth++
+				continue
+			}
+			local := strings.ToLower(t.Name.Local)
+			if !sawRoot {
+				if local != "svg" {
+					return nil, fmt.Errorf("%w: root element is %s", ErrInvalidSVG, t.Name.Local)
+				}
+				sawRoot = true
+			}
+			if _, forbidden := svgForbiddenElements[local]; forbidden || unsafeAnimation(local, t.Attr) {
+				skipDepth = 1
+				continue
+			}
+
+			out.WriteByte('<')
+			out.WriteString(rawName(t.Name))
+			for _, attr := range t.Attr {
+				if !safeSVGAttribute(attr) {
+					continue
+				}
+				out.WriteByte(' ')
+				out.WriteString(rawName(attr.Name))
+				out.WriteString(`="`)
+				xml.EscapeText(&out, []byte(attr.Value))
+				out.WriteByte('"')
+			}
+			out.WriteByte('>')
+		case xml.EndElement:
+			if skipDepth > 0 {
+				skipDepth--
+				continue
+			}
+			out.WriteString("</")
+			out.WriteString(rawName(t.Name))
+			out.WriteByte('>')
+		case xml.CharData:
+			if skipDepth > 0 {
+				continue
+			}
+			xml.EscapeText(&out, t)
+		case xml.Comment:
+			// Comments are dropped; they serve no purpose in a served image.
+		case xml.ProcInst:
+			if skipDepth > 0 || !strings.EqualFold(t.Target, "xml") {
+				continue
+			}
+			out.WriteString("<?xml ")
+			out.Write(t.Inst)
+			out.WriteString("?>")
+		case xml.Directive:
+			// DOCTYPE and entity declarations are dropped.
+		}
+	}
+
+	if !sawRoot {
+		return nil, fmt.Errorf("%w: no svg element", ErrInvalidSVG)
+	}
+	return out.Bytes(), nil
+}
+
+func rawName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
+
+func safeSVGAttribute(attr xml.Attr) bool {
+	local := strings.ToLower(attr.Name.Local)
+	if strings.HasPrefix(local, "on") {
+		return false
+	}
+
+	value := normalizeSVGValue(attr.Value)
+	switch local {
+	case "href", "src", "action", "formaction":
+		return safeSVGLink(value)
+	case "style":
+		return !strings.Contains(value, "javascript:") && !strings.Contains(value, "expression(")
+	}
+	return !strings.Contains(value, "javascript:")
+}
+
+// safeSVGLink allows fragment, relative and http(s) links, and inline raster images.
+func safeSVGLink(value string) bool {
+	if strings.HasPrefix(value, "data:") {
+		for _, allowed := range []string{"data:image/png", "data:image/jpeg", "data:image/gif", "data:image/webp"} {
+			if strings.HasPrefix(value, allowed) {
+				return true
+			}
+		}
+		return false
+	}
+	scheme, _, found := strings.Cut(value, ":")
+	if !found || strings.ContainsAny(scheme, "/?#") {
+		return true
+	}
+	return scheme == "http" || scheme == "https"
+}
+
+func unsafeAnimation(local string, attrs []xml.Attr) bool {
+	if _, ok := svgAnimationElements[local]; !ok {
+		return false
+	}
+	for _, attr := range attrs {
+		if strings.ToLower(attr.Name.Local) != "attributename" {
+			continue
+		}
+		target := strings.ToLower(strings.TrimSpace(attr.Value))
+		if i := strings.IndexByte(target, ':'); i >= 0 {
+			target = target[i+1:]
+		}
+		if target == "href" || strings.HasPrefix(target, "on") {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeSVGValue lowercases a value and removes whitespace and control characters
+// that browsers ignore inside URL schemes, such as "java\tscript:".
+func normalizeSVGValue(value string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(value) {
+		if r <= ' ' {
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
@@ -0,0 +1,139 @@
+package media
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrVideoToolsUnavailable is returned when ffprobe or ffmpeg is not on PATH.
+var ErrVideoToolsUnavailable = errors.New("ffprobe/ffmpeg unavailable")
+
+// posterMaxWidth keeps poster frames small enough for listings and player previews.
+const posterMaxWidth = 1280
+
+// VideoProbe holds what ffprobe reports about a video file.
+type VideoProbe struct {
+	Duration time.Duration
+	Width    int
+	Height   int
+}
+
+// VideoToolsAvailable reports whether ffprobe and ffmpeg are installed.
+func VideoToolsAvailable() bool {
+	for _, command := range []string{"ffprobe", "ffmpeg"} {
+		if _, err := exec.LookPath(command); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// ProbeVideo reads the duration and frame size of any container ffprobe understands.
+func ProbeVideo(ctx context.Context, path string) (VideoProbe, error) {
+	command, err := exec.LookPath("ffprobe")
+	if err != nil {
+		return VideoProbe{}, ErrVideoToolsUnavailable
+	}
+
+	output, err := exec.CommandContext(ctx, command,
+		"-v", "error",
+		"-select_streams", "v:0",
+		"-show_entries", "format=duration:stream=width,height,duration",
+		"-of", "json",
+		path,
+	).Output()
+	if err != nil {
+		return VideoProbe{}, fmt.Errorf("ffprobe failed: %w", err)
+	}
+	return parseVideoProbe(output)
+}
+
+func parseVideoProbe(output []byte) (VideoProbe, error) {
+	var payload struct {
+		Streams []struct {
+			Width    int    `json:"width"`
+			Height   int    `json:"height"`
+			Duration string `json:"duration"`
+		} `json:"streams"`
+		Format struct {
+			Duration string `json:"duration"`
+		} `json:"format"`
+	}
+	if err := json.Unmarshal(output, &payload); err != nil {
+		return VideoProbe{}, fmt.Errorf("parse ffprobe output: %w", err)
+	}
+	if len(payload.Streams) == 0 {
+		return VideoProbe{}, errors.New("no video stream found")
+	}
+
+	stream := payload.Streams[0]
+	probe := VideoProbe{Width: stream.Width, Height: stream.Height}
+	// The container duration covers every stream; fall back to the video stream for
+	// formats that only report it there.
+	for _, value := range []string{payload.Format.Duration, stream.Duration} {
+		seconds, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err == nil && seconds > 0 {
+			probe.Duration = time.Duration(seconds * float64(time.Second))
+			break
+		}
+	}
+	return probe, nil
+}
+
+// ExtractPosterFrame writes a JPEG of the frame at offset to target, scaled down to
+// at most posterMaxWidth pixels wide.
+func ExtractPosterFrame(ctx context.Context, source, target string, offset time.Duration) error {
+	command, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		return ErrVideoToolsUnavailable
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+
+	// Write to a temporary name so readers never see a partial poster.
+	temp := target + ".tmp.jpg"
+	output, err := exec.CommandContext(ctx, command,
+		"-hide_banner", "-loglevel", "error", "-y",
+		"-ss", strconv.FormatFloat(offset.Seconds(), 'f', 3, 64),
+		"-i", source,
+		"-frames:v", "1",
+		"-vf", fmt.Sprintf("scale='min(%d,iw)':-2", posterMaxWidth),
+		"-q:v", "3",
+		temp,
+	).CombinedOutput()
+	if err != nil {
+		os.Remove(temp)
+		return fmt.Errorf("ffmpeg failed: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	if stat, err := os.Stat(temp); err != nil || stat.Size() == 0 {
+		os.Remove(temp)
+		return errors.New("ffmpeg produced no poster frame")
+	}
+	return os.Rename(temp, target)
+}
+
+// PosterOffset picks the frame used as a poster: a little way in, so fades from black
+// and title cards are skipped, but never past the end of short clips.
+func PosterOffset(duration time.Duration) time.Duration {
+	switch {
+	case duration <= 0:
+		return 0
+	case duration < 10*time.Second:
+		return duration / 2
+	default:
+		offset := duration / 10
+		if offset > 30*time.Second {
+			offset = 30 * time.Second
+		}
+		return offset
+	}
+}
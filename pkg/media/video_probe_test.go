@@ -0,0 +1,41 @@
+package media
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseVideoProbe(t *testing.T) {
+	output := []byte(`{"streams":[{"width":1280,"height":720,"duration":"61.000000"}],"format":{"duration":"61.523000"}}`)
+	probe, err := parseVideoProbe(output)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if probe.Width != 1280 || probe.Height != 720 || probe.Duration != 61523*time.Millisecond {
+		t.Fatalf("unexpected probe: %+v", probe)
+	}
+
+	// WebM files often report the duration only on the container or only on the stream.
+	probe, err = parseVideoProbe([]byte(`{"streams":[{"width":640,"height":360,"duration":"12.5"}],"format":{"duration":"N/A"}}`))
+	if err != nil || probe.Duration != 12500*time.Millisecond {
+		t.Fatalf("expected stream duration fallback, got %+v, %v", probe, err)
+	}
+
+	if _, err := parseVideoProbe([]byte(`{"streams":[],"format":{"duration":"3.0"}}`)); err == nil {
+		t.Fatal("expected an error for files without a video stream")
+	}
+}
+
+func TestPosterOffset(t *testing.T) {
+	cases := map[time.Duration]time.Duration{
+		0:                0,
+		4 * time.Second:  2 * time.Second,
+		60 * time.Second: 6 * time.Second,
+		time.Hour:        30 * time.Second,
+	}
+	for duration, want := range cases {
+		if got := PosterOffset(duration); got != want {
+			t.Errorf("PosterOffset(%s) = %s, want %s", duration, got, want)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"sync"
+	"time"
+)
+
+const secondsPerDay = 24 * 60 * 60
+
+// DailyCounter counts named events per UTC day in memory, keeping the last retain
+// days. It suits figures that are not stored anywhere, such as cache hits, and
+// starts over when the process restarts.
+type DailyCounter struct {
+	mu     sync.Mutex
+	retain int64
+	days   map[int64]map[string]int64
+	now    func() time.Time
+}
+
+func NewDailyCounter(retainDays int) *DailyCounter {
+	if retainDays < 1 {
+		retainDays = 1
+	}
+	return &DailyCounter{
+		retain: int64(retainDays),
+		days:   make(map[int64]map[string]int64),
+		now:    time.Now,
+	}
+}
+
+// Add adds delta to today's count of name.
+func (d *DailyCounter) Add(name string, delta int64) {
+	if d == nil {
+		return
+	}
+	day := dayNumber(d.now())
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	counts, ok := d.days[day]
+	if !ok {
+		for old := range d.days {
+			if old <= day-d.retain {
+				delete(d.days, old)
+			}
+		}
+		counts = make(map[string]int64)
+		d.days[day] = counts
+	}
+	counts[name] += delta
+}
+
+// Count returns the count of name on the UTC day containing day.
+func (d *DailyCounter) Count(name string, day time.Time) int64 {
+	if d == nil {
+		return 0
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.days[dayNumber(day)][name]
+}
+
+func dayNumber(t time.Time) int64 {
+	unix := t.Unix()
+	if unix < 0 {
+		return (unix - secondsPerDay + 1) / secondsPerDay
+	}
+	return unix / secondsPerDay
+}
@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDailyCounterCountsPerDayAndForgetsOldDays(t *testing.T) {
+	clock := time.Date(2026, 3, 1, 23, 30, 0, 0, time.UTC)
+	counter := NewDailyCounter(2)
+	counter.now = func() time.Time { return clock }
+
+	counter.Add("hits", 2)
+	clock = clock.Add(time.Hour)
+	counter.Add("hits", 1)
+	counter.Add("misses", 1)
+
+	first := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	second := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	if got := counter.Count("hits", first); got != 2 {
+		t.Errorf("first day hits = %d, want 2", got)
+	}
+	if got := counter.Count("hits", second); got != 1 {
+		t.Errorf("second day hits = %d, want 1", got)
+	}
+
+	clock = clock.AddDate(0, 0, 1)
+	counter.Add("hits", 1)
+	if got := counter.Count("hits", first); got != 0 {
+		t.Errorf("day past retention still counted %d", got)
+	}
+	if got := counter.Count("misses", second); got != 1 {
+		t.Errorf("second day misses = %d, want 1", got)
+	}
+}
@@ -0,0 +1,258 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// localeFormat describes how dates and relative times read in one language.
+// Date patterns use {d}, {dd}, {MM}, {MMMM}, {EEEE}, {yyyy}, {HH} and {mm}.
+type localeFormat struct {
+	months   [12]string
+	weekdays [7]string // Sunday first, like time.Weekday
+	patterns map[string]string
+	justNow  string
+	ago      string
+	units    map[string][3]string // one, few, many
+	plural   func(n int) int
+}
+
+func pluralOneOther(n int) int {
+	if n == 1 {
+		return 0
+	}
+	return 2
+}
+
+func pluralFrench(n int) int {
+	if n == 0 || n == 1 {
+		return 0
+	}
+	return 2
+}
+
+func pluralSlavic(n int) int {
+	switch {
+	case n%10 == 1 && n%100 != 11:
+		return 0
+	case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+		return 1
+	default:
+		return 2
+	}
+}
+
+var localeFormats = map[string]localeFormat{
+	"en": {
+		months:   [12]string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+		weekdays: [7]string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+		patterns: map[string]string{
+			"short":    "{MM}/{dd}/{yyyy}",
+			"medium":   "{MMMM} {dd}, {yyyy}",
+			"long":     "{EEEE}, {MMMM} {dd}, {yyyy}",
+			"time":     "{HH}:{mm}",
+			"datetime": "{MM}/{dd}/{yyyy} {HH}:{mm}",
+		},
+		justNow: "just now",
+		ago:     "%s ago",
+		units: map[string][3]string{
+			"minute": {"minute", "minutes", "minutes"},
+			"hour":   {"hour", "hours", "hours"},
+			"day":    {"day", "days", "days"},
+			"month":  {"month", "months", "months"},
+			"year":   {"year", "years", "years"},
+		},
+		plural: pluralOneOther,
+	},
+	"es": {
+		months:   [12]string{"enero", "febrero", "marzo", "abril", "mayo", "junio", "julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre"},
+		weekdays: [7]string{"domingo", "lunes", "martes", "miércoles", "jueves", "viernes", "sábado"},
+		patterns: map[string]string{
+			"short":    "{dd}/{MM}/{yyyy}",
+			"medium":   "{d} de {MMMM} de {yyyy}",
+			"long":     "{EEEE}, {d} de {MMMM} de {yyyy}",
+			"time":     "{HH}:{mm}",
+			"datetime": "{dd}/{MM}/{yyyy} {HH}:{mm}",
+		},
+		justNow: "ahora mismo",
+		ago:     "hace %s",
+		units: map[string][3]string{
+			"minute": {"minuto", "minutos", "minutos"},
+			"hour":   {"hora", "horas", "horas"},
+			"day":    {"día", "días", "días"},
+			"month":  {"mes", "meses", "meses"},
+			"year":   {"año", "años", "años"},
+		},
+		plural: pluralOneOther,
+	},
+	"fr": {
+		months:   [12]string{"janvier", "février", "mars", "avril", "mai", "juin", "juillet", "août", "septembre", "octobre", "novembre", "décembre"},
+		weekdays: [7]string{"dimanche", "lundi", "mardi", "mercredi", "jeudi", "vendredi", "samedi"},
+		patterns: map[string]string{
+			"short":    "{dd}/{MM}/{yyyy}",
+			"medium":   "{d} {MMMM} {yyyy}",
+			"long":     "{EEEE} {d} {MMMM} {yyyy}",
+			"time":     "{HH}:{mm}",
+			"datetime": "{dd}/{MM}/{yyyy} {HH}:{mm}",
+		},
+		justNow: "à l'instant",
+		ago:     "il y a %s",
+		units: map[string][3]string{
+			"minute": {"minute", "minutes", "minutes"},
+			"hour":   {"heure", "heures", "heures"},
+			"day":    {"jour", "jours", "jours"},
+			"month":  {"mois", "mois", "mois"},
+			"year":   {"an", "ans", "ans"},
+		},
+		plural: pluralFrench,
+	},
+	"de": {
+		months:   [12]string{"Januar", "Februar", "März", "April", "Mai", "Juni", "Juli", "August", "September", "Oktober", "November", "Dezember"},
+		weekdays: [7]string{"Sonntag", "Montag", "Dienstag", "Mittwoch", "Donnerstag", "Freitag", "Samstag"},
+		patterns: map[string]string{
+			"short":    "{dd}.{MM}.{yyyy}",
+			"medium":   "{d}. {MMMM} {yyyy}",
+			"long":     "{EEEE}, {d}. {MMMM} {yyyy}",
+			"time":     "{HH}:{mm}",
+			"datetime": "{dd}.{MM}.{yyyy} {HH}:{mm}",
+		},
+		justNow: "gerade eben",
+		ago:     "vor %s",
+		units: map[string][3]string{
+			"minute": {"Minute", "Minuten", "Minuten"},
+			"hour":   {"Stunde", "Stunden", "Stunden"},
+			"day":    {"Tag", "Tagen", "Tagen"},
+			"month":  {"Monat", "Monaten", "Monaten"},
+			"year":   {"Jahr", "Jahren", "Jahren"},
+		},
+		plural: pluralOneOther,
+	},
+	"pt": {
+		months:   [12]string{"janeiro", "fevereiro", "março", "abril", "maio", "junho", "julho", "agosto", "setembro", "outubro", "novembro", "dezembro"},
+		weekdays: [7]string{"domingo", "segunda-feira", "terça-feira", "quarta-feira", "quinta-feira", "sexta-feira", "sábado"},
+		patterns: map[string]string{
+			"short":    "{dd}/{MM}/{yyyy}",
+			"medium":   "{d} de {MMMM} de {yyyy}",
+			"long":     "{EEEE}, {d} de {MMMM} de {yyyy}",
+			"time":     "{HH}:{mm}",
+			"datetime": "{dd}/{MM}/{yyyy} {HH}:{mm}",
+		},
+		justNow: "agora mesmo",
+		ago:     "há %s",
+		units: map[string][3]string{
+			"minute": {"minuto", "minutos", "minutos"},
+			"hour":   {"hora", "horas", "horas"},
+			"day":    {"dia", "dias", "dias"},
+			"month":  {"mês", "meses", "meses"},
+			"year":   {"ano", "anos", "anos"},
+		},
+		plural: pluralOneOther,
+	},
+	"ru": {
+		// Months are in the genitive case used in dates ("5 марта").
+		months:   [12]string{"января", "февраля", "марта", "апреля", "мая", "июня", "июля", "августа", "сентября", "октября", "ноября", "декабря"},
+		weekdays: [7]string{"воскресенье", "понедельник", "вторник", "среда", "четверг", "пятница", "суббота"},
+		patterns: map[string]string{
+			"short":    "{dd}.{MM}.{yyyy}",
+			"medium":   "{d} {MMMM} {yyyy} г.",
+			"long":     "{EEEE}, {d} {MMMM} {yyyy} г.",
+			"time":     "{HH}:{mm}",
+			"datetime": "{dd}.{MM}.{yyyy} {HH}:{mm}",
+		},
+		justNow: "только что",
+		ago:     "%s назад",
+		units: map[string][3]string{
+			"minute": {"минуту", "минуты", "минут"},
+			"hour":   {"час", "часа", "часов"},
+			"day":    {"день", "дня", "дней"},
+			"month":  {"месяц", "месяца", "месяцев"},
+			"year":   {"год", "года", "лет"},
+		},
+		plural: pluralSlavic,
+	},
+}
+
+// formatFor returns the formats of code, falling back from "es-MX" to "es" and
+// then to English.
+func formatFor(code string) localeFormat {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if format, ok := localeFormats[code]; ok {
+		return format
+	}
+	base, _, _ := strings.Cut(code, "-")
+	if format, ok := localeFormats[base]; ok {
+		return format
+	}
+	return localeFormats["en"]
+}
+
+// FormatLocalDate formats t in the given language. format is one of short,
+// medium, long, time, datetime or iso; anything else is used as a Go layout.
+func FormatLocalDate(t time.Time, format, code string) string {
+	if format == "iso" {
+		return t.Format(time.RFC3339)
+	}
+	locale := formatFor(code)
+	pattern, ok := locale.patterns[format]
+	if !ok {
+		return t.Format(format)
+	}
+
+	replacer := strings.NewReplacer(
+		"{dd}", fmt.Sprintf("%02d", t.Day()),
+		"{d}", fmt.Sprintf("%d", t.Day()),
+		"{MMMM}", locale.months[t.Month()-1],
+		"{MM}", fmt.Sprintf("%02d", int(t.Month())),
+		"{EEEE}", locale.weekdays[t.Weekday()],
+		"{yyyy}", fmt.Sprintf("%04d", t.Year()),
+		"{HH}", fmt.Sprintf("%02d", t.Hour()),
+		"{mm}", fmt.Sprintf("%02d", t.Minute()),
+	)
+	return replacer.Replace(pattern)
+}
+
+// FormatRelativeTime describes how long ago t was relative to now, e.g. "3 days
+// ago" or "hace 3 días". Times in the future read as "just now".
+func FormatRelativeTime(t, now time.Time, code string) string {
+	locale := formatFor(code)
+	duration := now.Sub(t)
+
+	var count int
+	var unit string
+	switch {
+	case duration < time.Minute:
+		return locale.justNow
+	case duration < time.Hour:
+		count, unit = int(duration.Minutes()), "minute"
+	case duration < 24*time.Hour:
+		count, unit = int(duration.Hours()), "hour"
+	default:
+		days := int(duration.Hours() / 24)
+		months := days / 30
+		switch {
+		case days < 30:
+			count, unit = days, "day"
+		case months < 12:
+			count, unit = months, "month"
+		default:
+			count, unit = months/12, "year"
+		}
+	}
+
+	forms := locale.units[unit]
+	return fmt.Sprintf(locale.ago, fmt.Sprintf("%d %s", count, forms[locale.plural(count)]))
+}
+
+// FormatLocalNumber formats a number with the digit grouping and decimal
+// separator of the given language.
+func FormatLocalNumber(value interface{}, code string) string {
+	tag, err := language.Parse(strings.TrimSpace(code))
+	if err != nil {
+		tag = language.English
+	}
+	return message.NewPrinter(tag).Sprint(value)
+}
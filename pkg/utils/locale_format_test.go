@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatLocalDate(t *testing.T) {
+	date := time.Date(2025, time.March, 5, 14, 7, 0, 0, time.UTC)
+
+	cases := []struct {
+		code, format, expected string
+	}{
+		{"en", "medium", "March 05, 2025"},
+		{"en", "short", "03/05/2025"},
+		{"es-MX", "long", "miércoles, 5 de marzo de 2025"},
+		{"de", "medium", "5. März 2025"},
+		{"ru", "medium", "5 марта 2025 г."},
+		{"fr", "datetime", "05/03/2025 14:07"},
+		{"xx", "medium", "March 05, 2025"},
+		{"es", "2006-01-02", "2025-03-05"},
+	}
+	for _, tc := range cases {
+		if got := FormatLocalDate(date, tc.format, tc.code); got != tc.expected {
+			t.Errorf("%s/%s: expected %q, got %q", tc.code, tc.format, tc.expected, got)
+		}
+	}
+}
+
+func TestFormatRelativeTime(t *testing.T) {
+	now := time.Date(2025, time.March, 5, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		code     string
+		ago      time.Duration
+		expected string
+	}{
+		{"en", 30 * time.Second, "just now"},
+		{"en", time.Minute, "1 minute ago"},
+		{"en", 3 * time.Hour, "3 hours ago"},
+		{"es", 2 * 24 * time.Hour, "hace 2 días"},
+		{"ru", 22 * 24 * time.Hour, "22 дня назад"},
+		{"ru", 5 * time.Minute, "5 минут назад"},
+		{"ru", 21 * time.Minute, "21 минуту назад"},
+		{"fr", 400 * 24 * time.Hour, "il y a 1 an"},
+		{"de", -time.Hour, "gerade eben"},
+	}
+	for _, tc := range cases {
+		if got := FormatRelativeTime(now.Add(-tc.ago), now, tc.code); got != tc.expected {
+			t.Errorf("%s/%s: expected %q, got %q", tc.code, tc.ago, tc.expected, got)
+		}
+	}
+}
+
+func TestFormatLocalNumber(t *testing.T) {
+	if got := FormatLocalNumber(1234567, "en"); got != "1,234,567" {
+		t.Errorf("unexpected English number %q", got)
+	}
+	if got := FormatLocalNumber(1234567, "de"); got != "1.234.567" {
+		t.Errorf("unexpected German number %q", got)
+	}
+	if got := FormatLocalNumber(42, ""); got != "42" {
+		t.Errorf("unexpected number without locale %q", got)
+	}
+}
+
+func TestLocalTemplateFuncsAcceptMissingValues(t *testing.T) {
+	funcs := GetTemplateFuncs(nil)
+	localDate, ok := funcs["localDate"].(func(interface{}, interface{}, string) string)
+	if !ok {
+		t.Fatalf("localDate func has unexpected signature")
+	}
+
+	date := time.Date(2025, time.March, 5, 0, 0, 0, 0, time.UTC)
+	if got := localDate(nil, &date, "short"); got != "03/05/2025" {
+		t.Errorf("expected English date for missing locale, got %q", got)
+	}
+	var missing *time.Time
+	if got := localDate("es", missing, "short"); got != "" {
+		t.Errorf("expected empty output for nil time, got %q", got)
+	}
+}
@@ -14,7 +14,16 @@ import (
 
 type AssetModTimeFunc func(path string) (time.Time, error)
 
+// AssetURLFunc resolves a static asset path to a fingerprinted URL when one is available.
+type AssetURLFunc func(path string) (string, bool)
+
 func GetTemplateFuncs(assetModTime AssetModTimeFunc) template.FuncMap {
+	return GetTemplateFuncsWithAssets(nil, assetModTime)
+}
+
+// GetTemplateFuncsWithAssets returns the template helpers with the asset function
+// preferring content-hashed URLs and falling back to modification-time versioning.
+func GetTemplateFuncsWithAssets(assetURL AssetURLFunc, assetModTime AssetModTimeFunc) template.FuncMap {
 	return template.FuncMap{
 		"add": func(a, b int) int { return a + b },
 		"sub": func(a, b int) int { return a - b },
@@ -67,42 +76,30 @@ func GetTemplateFuncs(assetModTime AssetModTimeFunc) template.FuncMap {
 		},
 
 		"formatDate": func(t time.Time, format string) string {
-			layouts := map[string]string{
-				"short":    "01/02/2006",
-				"medium":   "January 02, 2006",
-				"long":     "Monday, January 02, 2006",
-				"time":     "15:04",
-				"datetime": "01/02/2006 15:04",
-				"iso":      time.RFC3339,
-			}
-			if layout, ok := layouts[format]; ok {
-				return t.Format(layout)
-			}
-			return t.Format(format)
+			return FormatLocalDate(t, format, "en")
 		},
 		"timeAgo": func(t time.Time) string {
-			duration := time.Since(t)
-			if duration.Hours() < 1 {
-				minutes := int(duration.Minutes())
-				if minutes < 1 {
-					return "just now"
-				}
-				return formatPlural(minutes, "minute", "minutes", "minutes") + " ago"
-			}
-			if duration.Hours() < 24 {
-				hours := int(duration.Hours())
-				return formatPlural(hours, "hour", "hours", "hours") + " ago"
-			}
-			days := int(duration.Hours() / 24)
-			if days < 30 {
-				return formatPlural(days, "day", "days", "days") + " ago"
+			return FormatRelativeTime(t, time.Now(), "en")
+		},
+		// The local* helpers take the page locale first, e.g.
+		// {{ localDate $.Locale .CreatedAt "medium" }}. A missing locale means
+		// English, and nil times render as an empty string.
+		"localDate": func(code, value interface{}, format string) string {
+			t, ok := templateTime(value)
+			if !ok {
+				return ""
 			}
-			months := days / 30
-			if months < 12 {
-				return formatPlural(months, "month", "months", "months") + " ago"
+			return FormatLocalDate(t, format, templateLocale(code))
+		},
+		"localTimeAgo": func(code, value interface{}) string {
+			t, ok := templateTime(value)
+			if !ok {
+				return ""
 			}
-			years := months / 12
-			return formatPlural(years, "year", "years", "years") + " ago"
+			return FormatRelativeTime(t, time.Now(), templateLocale(code))
+		},
+		"localNumber": func(code, value interface{}) string {
+			return FormatLocalNumber(value, templateLocale(code))
 		},
 
 		"slice": func(items interface{}, start, end int) interface{} { return items },
@@ -116,6 +113,26 @@ func GetTemplateFuncs(assetModTime AssetModTimeFunc) template.FuncMap {
 			return value
 		},
 
+		// srcset and imageSources are replaced by the template handler, which knows the
+		// generated image variants. These defaults keep templates parsing elsewhere.
+		"srcset":       func(url string, format ...string) template.Srcset { return "" },
+		"imageSources": func(url string) []interface{} { return nil },
+		// resizedImage points an upload URL at the on-demand resizer, for example
+		// {{ resizedImage .Image "w=640,fit=cover,h=360" }}. Other URLs pass through.
+		// The template handler replaces it with one that signs the URL.
+		"resizedImage": func(url, transform string) string {
+			trimmed := strings.TrimSpace(url)
+			transform = strings.TrimSpace(transform)
+			if transform == "" || !strings.HasPrefix(trimmed, "/uploads/") {
+				return url
+			}
+			return "/img/" + transform + "/" + strings.TrimPrefix(trimmed, "/uploads/")
+		},
+
+		// sanitize is replaced by the template handler, which knows the
+		// configured sanitization policies. The default escapes the markup.
+		"sanitize": func(context, s string) template.HTML { return template.HTML(template.HTMLEscapeString(s)) },
+
 		"safe":    func(s string) template.HTML { return template.HTML(s) },
 		"safeURL": func(s string) template.URL { return template.URL(s) },
 		"safeJS":  func(s string) template.JS { return template.JS(s) },
@@ -143,6 +160,11 @@ func GetTemplateFuncs(assetModTime AssetModTimeFunc) template.FuncMap {
 			if strings.HasPrefix(lowerPath, "http://") || strings.HasPrefix(lowerPath, "https://") || strings.HasPrefix(path, "//") {
 				return path
 			}
+			if assetURL != nil {
+				if resolved, ok := assetURL(path); ok {
+					return resolved
+				}
+			}
 			version := int64(0)
 			if assetModTime != nil {
 				if modTime, err := assetModTime(path); err == nil {
@@ -251,19 +273,27 @@ func isEmpty(value interface{}) bool {
 	return reflect.DeepEqual(value, zero.Interface())
 }
 
-func formatPlural(n int, one, few, many string) string {
-	if n == 1 {
-		return formatNumber(n, one)
+// templateLocale reads a locale passed from template data, which may be absent.
+func templateLocale(value interface{}) string {
+	if code, ok := value.(string); ok {
+		return code
 	}
-	return formatNumber(n, many)
-}
-
-func formatNumber(n int, word string) string {
-	return strings.TrimSpace(strings.Join([]string{intToString(n), word}, " "))
+	return ""
 }
 
-func intToString(n int) string {
-	return fmt.Sprintf("%d", n)
+// templateTime accepts the time.Time and *time.Time values models expose.
+func templateTime(value interface{}) (time.Time, bool) {
+	switch t := value.(type) {
+	case time.Time:
+		return t, !t.IsZero()
+	case *time.Time:
+		if t == nil || t.IsZero() {
+			return time.Time{}, false
+		}
+		return *t, true
+	default:
+		return time.Time{}, false
+	}
 }
 
 func NormalizePath(value string) string {
@@ -3,6 +3,7 @@ package validator
 import (
 	"bytes"
 	"mime"
+	"reflect"
 	"regexp"
 	"strings"
 
@@ -29,11 +30,28 @@ func Init() {
 }
 
 func registerCustomValidations(v *validator.Validate) {
+	v.RegisterTagNameFunc(jsonFieldName)
 	v.RegisterValidation("username", validateUsername)
 	v.RegisterValidation("slug", validateSlug)
 	v.RegisterValidation("no_html", validateNoHTML)
 }
 
+// jsonFieldName names fields in validation errors as they appear in request
+// bodies, for the details of API errors.
+func jsonFieldName(field reflect.StructField) string {
+	name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		name = strings.SplitN(field.Tag.Get("form"), ",", 2)[0]
+	}
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
 func Validate(s interface{}) error {
 	return validate.Struct(s)
 }
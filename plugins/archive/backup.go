@@ -0,0 +1,10 @@
+package archive
+
+import (
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/plugin/backup"
+)
+
+func init() {
+	backup.Register("archive", &models.ArchiveDirectory{}, &models.ArchiveFile{})
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"constructor-script-backend/internal/models"
+	"constructor-script-backend/pkg/apierror"
 	archiveservice "constructor-script-backend/plugins/archive/service"
 )
 
@@ -136,7 +137,7 @@ func (h *DirectoryHandler) Create(c *gin.Context) {
 
 	var req models.CreateArchiveDirectoryRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.BindError(c, err)
 		return
 	}
 
@@ -169,7 +170,7 @@ func (h *DirectoryHandler) Update(c *gin.Context) {
 
 	var req models.UpdateArchiveDirectoryRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.BindError(c, err)
 		return
 	}
 
@@ -9,6 +9,7 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"constructor-script-backend/internal/models"
+	"constructor-script-backend/pkg/apierror"
 	archiveservice "constructor-script-backend/plugins/archive/service"
 )
 
@@ -97,7 +98,7 @@ func (h *FileHandler) Create(c *gin.Context) {
 
 	var req models.CreateArchiveFileRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.BindError(c, err)
 		return
 	}
 
@@ -128,7 +129,7 @@ func (h *FileHandler) Update(c *gin.Context) {
 
 	var req models.UpdateArchiveFileRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.BindError(c, err)
 		return
 	}
 
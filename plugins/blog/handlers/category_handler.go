@@ -7,6 +7,7 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"constructor-script-backend/internal/models"
+	"constructor-script-backend/pkg/apierror"
 	blogservice "constructor-script-backend/plugins/blog/service"
 )
 
@@ -41,7 +42,7 @@ func (h *CategoryHandler) Create(c *gin.Context) {
 
 	var req models.CreateCategoryRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.BindError(c, err)
 		return
 	}
 
@@ -101,7 +102,7 @@ func (h *CategoryHandler) Update(c *gin.Context) {
 
 	var req models.CreateCategoryRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.BindError(c, err)
 		return
 	}
 
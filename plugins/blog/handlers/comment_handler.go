@@ -10,8 +10,10 @@ import (
 	"gorm.io/gorm"
 
 	"constructor-script-backend/internal/authorization"
+	"constructor-script-backend/internal/csvexport"
 	"constructor-script-backend/internal/models"
 	coreservice "constructor-script-backend/internal/service"
+	"constructor-script-backend/pkg/apierror"
 	blogservice "constructor-script-backend/plugins/blog/service"
 )
 
@@ -58,7 +60,7 @@ func (h *CommentHandler) Create(c *gin.Context) {
 
 	var req models.CreateCommentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.BindError(c, err)
 		return
 	}
 
@@ -127,6 +129,11 @@ func (h *CommentHandler) GetAll(c *gin.Context) {
 		return
 	}
 
+	if csvexport.Requested(c) {
+		h.exportComments(c)
+		return
+	}
+
 	comments, err := h.commentService.GetAll()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -136,6 +143,21 @@ func (h *CommentHandler) GetAll(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"comments": comments})
 }
 
+func (h *CommentHandler) exportComments(c *gin.Context) {
+	export := csvexport.New(c, "comments", "id", "created_at", "post_id", "post_title", "author_id", "author", "parent_id", "approved", "content")
+	err := h.commentService.Batches(func(comments []models.Comment) error {
+		for _, comment := range comments {
+			if err := export.Row(comment.ID, comment.CreatedAt, comment.PostID, comment.Post.Title, comment.AuthorID, comment.Author.Username, comment.ParentID, comment.Approved, comment.Content); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err := export.Finish(err); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
 func (h *CommentHandler) Update(c *gin.Context) {
 	if !h.ensureService(c) {
 		return
@@ -149,7 +171,7 @@ func (h *CommentHandler) Update(c *gin.Context) {
 
 	var req models.UpdateCommentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.BindError(c, err)
 		return
 	}
 
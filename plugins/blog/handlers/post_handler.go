@@ -4,12 +4,16 @@ import (
 	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 
 	"constructor-script-backend/internal/authorization"
+	"constructor-script-backend/internal/fieldset"
+	"constructor-script-backend/internal/middleware"
 	"constructor-script-backend/internal/models"
+	"constructor-script-backend/pkg/apierror"
 	blogservice "constructor-script-backend/plugins/blog/service"
 )
 
@@ -44,7 +48,7 @@ func (h *PostHandler) Create(c *gin.Context) {
 
 	var req models.CreatePostRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.BindError(c, err)
 		return
 	}
 
@@ -58,11 +62,20 @@ func (h *PostHandler) Create(c *gin.Context) {
 	c.JSON(http.StatusCreated, gin.H{"post": post})
 }
 
+// postFields is what ?fields= and ?include= select from on post lists.
+var postFields = fieldset.NewSchema(models.Post{}, "author", "category", "tags", "comments", "sections")
+
 func (h *PostHandler) GetAll(c *gin.Context) {
 	if !h.ensureService(c) {
 		return
 	}
 
+	selection, err := fieldset.FromQuery(c, postFields)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
 
@@ -91,8 +104,14 @@ func (h *PostHandler) GetAll(c *gin.Context) {
 		return
 	}
 
+	items, err := selection.Apply(posts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"posts": posts,
+		"posts": items,
 		"total": total,
 		"page":  page,
 		"limit": limit,
@@ -111,11 +130,12 @@ func (h *PostHandler) GetByID(c *gin.Context) {
 	}
 
 	post, err := h.postService.GetByID(uint(id))
-	if err != nil {
+	if err != nil || (middleware.IsDeliveryRequest(c) && !postIsLive(post)) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "post not found"})
 		return
 	}
 
+	setPostValidators(c, post)
 	c.JSON(http.StatusOK, gin.H{"post": post})
 }
 
@@ -132,7 +152,7 @@ func (h *PostHandler) Update(c *gin.Context) {
 
 	var req models.UpdatePostRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.BindError(c, err)
 		return
 	}
 
@@ -190,14 +210,21 @@ func (h *PostHandler) GetBySlug(c *gin.Context) {
 	slug := c.Param("slug")
 
 	post, err := h.postService.GetBySlug(slug)
-	if err != nil {
+	if err != nil || (middleware.IsDeliveryRequest(c) && !postIsLive(post)) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "post not found"})
 		return
 	}
 
+	setPostValidators(c, post)
 	c.JSON(http.StatusOK, gin.H{"post": post})
 }
 
+// postIsLive reports whether post is published and its publication time has
+// come; the detail routes also serve drafts, which delivery tokens may not read.
+func postIsLive(post *models.Post) bool {
+	return post.Published && (post.PublishAt == nil || !post.PublishAt.After(time.Now()))
+}
+
 func (h *PostHandler) GetAllTags(c *gin.Context) {
 	if !h.ensureService(c) {
 		return
@@ -270,6 +297,12 @@ func (h *PostHandler) GetPostsByTag(c *gin.Context) {
 		return
 	}
 
+	selection, err := fieldset.FromQuery(c, postFields)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	slug := c.Param("slug")
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
@@ -284,8 +317,14 @@ func (h *PostHandler) GetPostsByTag(c *gin.Context) {
 		return
 	}
 
+	items, err := selection.Apply(posts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"posts": posts,
+		"posts": items,
 		"total": total,
 		"page":  page,
 		"limit": limit,
@@ -297,6 +336,12 @@ func (h *PostHandler) GetAllAdmin(c *gin.Context) {
 		return
 	}
 
+	selection, err := fieldset.FromQuery(c, postFields)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
 
@@ -306,8 +351,14 @@ func (h *PostHandler) GetAllAdmin(c *gin.Context) {
 		return
 	}
 
+	items, err := selection.Apply(posts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"posts": posts,
+		"posts": items,
 		"total": total,
 		"page":  page,
 		"limit": limit,
@@ -351,3 +402,15 @@ func (h *PostHandler) UnpublishPost(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "post unpublished successfully"})
 }
+
+// setPostValidators lets clients revalidate a post. The view counter goes up on
+// every read, so it is left out of the ETag.
+func setPostValidators(c *gin.Context, post *models.Post) {
+	if post == nil {
+		return
+	}
+	content := *post
+	content.Views = 0
+	middleware.SetContentETag(c, content)
+	middleware.SetLastModified(c, post.UpdatedAt)
+}
@@ -0,0 +1,67 @@
+package blog
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/plugin/migrations"
+)
+
+func init() {
+	migrations.Register("blog",
+		migrations.Migration{
+			Version:     1,
+			Description: "drop legacy comment foreign keys without cascade",
+			Stage:       migrations.StagePreSchema,
+			Up:          dropLegacyCommentConstraints,
+		},
+		migrations.Migration{
+			Version:     2,
+			Description: "backfill post publish dates",
+			// Imports, seeds and direct database writes can still publish posts
+			// without dates, so the backfill runs at every startup.
+			Repeatable: true,
+			Up:         backfillPostPublishDates,
+		},
+	)
+}
+
+func dropLegacyCommentConstraints(tx *gorm.DB) error {
+	if !tx.Migrator().HasTable(&models.Comment{}) {
+		return nil
+	}
+
+	legacyConstraints := []string{
+		"fk_users_comments",
+		"fk_posts_comments",
+		"fk_comments_parent_id",
+	}
+	for _, constraint := range legacyConstraints {
+		query := fmt.Sprintf(`ALTER TABLE "comments" DROP CONSTRAINT IF EXISTS "%s"`, constraint)
+		if err := tx.Exec(query).Error; err != nil {
+			return fmt.Errorf("failed to drop legacy comment constraint %s: %w", constraint, err)
+		}
+	}
+	return nil
+}
+
+func backfillPostPublishDates(tx *gorm.DB) error {
+	if err := tx.Exec(`
+                UPDATE posts
+                SET publish_at = COALESCE(publish_at, created_at)
+                WHERE publish_at IS NULL AND published = TRUE
+        `).Error; err != nil {
+		return fmt.Errorf("failed to backfill post publish_at: %w", err)
+	}
+
+	if err := tx.Exec(`
+                UPDATE posts
+                SET published_at = COALESCE(publish_at, created_at)
+                WHERE published_at IS NULL AND published = TRUE
+        `).Error; err != nil {
+		return fmt.Errorf("failed to backfill post published_at: %w", err)
+	}
+	return nil
+}
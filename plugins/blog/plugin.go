@@ -1,17 +1,28 @@
 package blog
 
 import (
+	"context"
 	"fmt"
+	"time"
 
+	"constructor-script-backend/internal/background"
+	"constructor-script-backend/internal/models"
 	"constructor-script-backend/internal/plugin/host"
 	"constructor-script-backend/internal/plugin/registry"
 	pluginruntime "constructor-script-backend/internal/plugin/runtime"
+	"constructor-script-backend/internal/service"
+	"constructor-script-backend/pkg/logger"
 	blogapi "constructor-script-backend/plugins/blog/api"
 	bloghandlers "constructor-script-backend/plugins/blog/handlers"
 	blogseed "constructor-script-backend/plugins/blog/seed"
 	blogservice "constructor-script-backend/plugins/blog/service"
 )
 
+const (
+	viewFlushJob      = "blog.flush_post_views"
+	viewFlushSchedule = "@every 1m"
+)
+
 func init() {
 	registry.Register("blog", NewFeature)
 }
@@ -62,15 +73,25 @@ func (f *Feature) Activate() error {
 		services.Set(blogapi.ServicePost, postSvc)
 	}
 
+	postSvc.SetEventBus(f.host.Events())
+	postSvc.SetSlugRedirects(repos.SlugRedirect())
+	if revisions := f.host.CoreServices().Revision(); revisions != nil {
+		postSvc.SetRevisions(revisions)
+		revisions.SetRestorer(models.RevisionContentPost, postSvc)
+	}
+
 	var commentSvc *blogservice.CommentService
 	if value, ok := services.Get(blogapi.ServiceComment).(*blogservice.CommentService); ok {
 		commentSvc = value
 	}
 	if commentSvc == nil {
-		commentSvc = blogservice.NewCommentService(repos.Comment())
+		commentSvc = blogservice.NewCommentService(repos.Comment(), repos.Post(), commentMailer(f.host.CoreServices().Email()))
 		services.Set(blogapi.ServiceComment, commentSvc)
 	}
 
+	commentSvc.SetEventBus(f.host.Events())
+	commentSvc.SetNotificationPreferences(commentPreferences(f.host.CoreServices().Notification()))
+
 	var searchSvc *blogservice.SearchService
 	if value, ok := services.Get(blogapi.ServiceSearch).(*blogservice.SearchService); ok {
 		searchSvc = value
@@ -136,11 +157,26 @@ func (f *Feature) Activate() error {
 	if themeHandler := f.host.ThemeHandler(); themeHandler != nil {
 		themeHandler.SetPostService(postSvc)
 	}
+	if digest := f.host.CoreServices().Digest(); digest != nil {
+		digest.SetSource("blog", postSvc)
+	}
 
 	if categorySvc != nil {
 		blogseed.EnsureDefaultCategory(categorySvc)
 	}
 
+	if scheduler := f.host.Scheduler(); scheduler != nil {
+		err := scheduler.ScheduleRecurring(background.RecurringJob{
+			Name:     viewFlushJob,
+			Schedule: viewFlushSchedule,
+			Timeout:  time.Minute,
+			Run:      postSvc.FlushViews,
+		})
+		if err != nil {
+			logger.Error(err, "Failed to schedule post view flushing", nil)
+		}
+	}
+
 	return nil
 }
 
@@ -172,8 +208,23 @@ func (f *Feature) Deactivate() error {
 	if themeHandler := f.host.ThemeHandler(); themeHandler != nil {
 		themeHandler.SetPostService(nil)
 	}
+	if digest := f.host.CoreServices().Digest(); digest != nil {
+		digest.SetSource("blog", nil)
+	}
+	if revisions := f.host.CoreServices().Revision(); revisions != nil {
+		revisions.SetRestorer(models.RevisionContentPost, nil)
+	}
+
+	if scheduler := f.host.Scheduler(); scheduler != nil {
+		scheduler.Unschedule(viewFlushJob)
+	}
 
 	services := f.host.Services(blogapi.Namespace)
+	if postSvc, _ := services.Get(blogapi.ServicePost).(*blogservice.PostService); postSvc != nil {
+		if err := postSvc.FlushViews(context.Background()); err != nil {
+			logger.Error(err, "Failed to flush post views", nil)
+		}
+	}
 	services.Set(blogapi.ServicePost, nil)
 	services.Set(blogapi.ServiceCategory, nil)
 	services.Set(blogapi.ServiceComment, nil)
@@ -181,3 +232,18 @@ func (f *Feature) Deactivate() error {
 
 	return nil
 }
+
+// commentMailer avoids storing a typed nil pointer inside the mailer interface.
+func commentMailer(email *service.EmailService) blogservice.CommentMailer {
+	if email == nil {
+		return nil
+	}
+	return email
+}
+
+func commentPreferences(notifications *service.NotificationService) blogservice.CommentNotificationPreferences {
+	if notifications == nil {
+		return nil
+	}
+	return notifications
+}
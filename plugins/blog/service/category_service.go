@@ -80,6 +80,10 @@ func (s *CategoryService) Create(req models.CreateCategoryRequest) (*models.Cate
 		Name:        req.Name,
 		Slug:        slug,
 		Description: req.Description,
+
+		NoIndex:        req.NoIndex,
+		NoFollow:       req.NoFollow,
+		SitemapExclude: req.SitemapExclude,
 	}
 
 	if err := s.categoryRepo.Create(category); err != nil {
@@ -192,6 +196,9 @@ func (s *CategoryService) Update(id uint, req models.CreateCategoryRequest) (*mo
 	category.Name = req.Name
 	category.Slug = utils.GenerateSlug(req.Name)
 	category.Description = req.Description
+	category.NoIndex = req.NoIndex
+	category.NoFollow = req.NoFollow
+	category.SitemapExclude = req.SitemapExclude
 
 	if err := s.categoryRepo.Update(category); err != nil {
 		return nil, err
@@ -1,17 +1,60 @@
 package blogservice
 
 import (
+	"constructor-script-backend/internal/events"
 	"constructor-script-backend/internal/models"
 	"constructor-script-backend/internal/repository"
+	"constructor-script-backend/pkg/logger"
+	"context"
 	"errors"
+	"fmt"
 )
 
+// commentNotificationTemplate matches the email template name registered by the core
+// email service.
+const commentNotificationTemplate = "comment_notification"
+
+// CommentMailer sends themed transactional emails.
+type CommentMailer interface {
+	Enabled() bool
+	SendTemplate(to, name, fallbackSubject string, data map[string]interface{}) error
+}
+
+// CommentNotificationPreferences tells whether a user wants an email.
+type CommentNotificationPreferences interface {
+	Allows(userID uint, event, channel string) bool
+}
+
 type CommentService struct {
-	commentRepo repository.CommentRepository
+	commentRepo  repository.CommentRepository
+	postRepo     repository.PostRepository
+	emailService CommentMailer
+	preferences  CommentNotificationPreferences
+	events       *events.Bus
 }
 
-func NewCommentService(commentRepo repository.CommentRepository) *CommentService {
-	return &CommentService{commentRepo: commentRepo}
+func NewCommentService(commentRepo repository.CommentRepository, postRepo repository.PostRepository, emailService CommentMailer) *CommentService {
+	return &CommentService{
+		commentRepo:  commentRepo,
+		postRepo:     postRepo,
+		emailService: emailService,
+	}
+}
+
+// SetEventBus configures the bus used to announce new comments.
+func (s *CommentService) SetEventBus(bus *events.Bus) {
+	if s == nil {
+		return
+	}
+	s.events = bus
+}
+
+// SetNotificationPreferences configures which post authors want comment emails.
+func (s *CommentService) SetNotificationPreferences(preferences CommentNotificationPreferences) {
+	if s == nil {
+		return
+	}
+	s.preferences = preferences
 }
 
 func (s *CommentService) Create(postID, authorID uint, req models.CreateCommentRequest) (*models.Comment, error) {
@@ -27,7 +70,62 @@ func (s *CommentService) Create(postID, authorID uint, req models.CreateCommentR
 		return nil, err
 	}
 
-	return s.commentRepo.GetByID(comment.ID)
+	created, err := s.commentRepo.GetByID(comment.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	go s.notifyPostAuthor(*created)
+
+	s.events.Publish(context.Background(), events.CommentCreated, map[string]interface{}{
+		"id":        created.ID,
+		"post_id":   created.PostID,
+		"author_id": created.AuthorID,
+		"parent_id": created.ParentID,
+		"approved":  created.Approved,
+	})
+
+	return created, nil
+}
+
+// notifyPostAuthor emails the post author about a new comment using the theme's
+// comment_notification template.
+func (s *CommentService) notifyPostAuthor(comment models.Comment) {
+	if s.postRepo == nil || s.emailService == nil || !s.emailService.Enabled() {
+		return
+	}
+
+	post, err := s.postRepo.GetByID(comment.PostID)
+	if err != nil || post == nil {
+		return
+	}
+	if post.AuthorID == comment.AuthorID || post.Author.Email == "" {
+		return
+	}
+	if s.preferences != nil && !s.preferences.Allows(post.AuthorID, models.NotificationPostComment, models.NotificationChannelEmail) {
+		return
+	}
+
+	postPath := fmt.Sprintf("/blog/post/%s", post.Slug)
+	if post.Slug == "" {
+		postPath = fmt.Sprintf("/blog/post/%d", post.ID)
+	}
+
+	data := map[string]interface{}{
+		"Username":       post.Author.Username,
+		"PostTitle":      post.Title,
+		"PostPath":       postPath,
+		"CommenterName":  comment.Author.Username,
+		"CommentContent": comment.Content,
+	}
+	subject := fmt.Sprintf("New comment on \"%s\"", post.Title)
+	if err := s.emailService.SendTemplate(post.Author.Email, commentNotificationTemplate, subject, data); err != nil {
+		logger.Warn("Failed to send comment notification", map[string]interface{}{
+			"comment_id": comment.ID,
+			"post_id":    post.ID,
+			"error":      err.Error(),
+		})
+	}
 }
 
 func (s *CommentService) GetByPostID(postID uint) ([]models.Comment, error) {
@@ -38,6 +136,11 @@ func (s *CommentService) GetAll() ([]models.Comment, error) {
 	return s.commentRepo.GetAll()
 }
 
+// Batches hands every comment to fn in batches, for exports.
+func (s *CommentService) Batches(fn func([]models.Comment) error) error {
+	return s.commentRepo.Batches(fn)
+}
+
 func (s *CommentService) Update(id, userID uint, canModerate bool, req models.UpdateCommentRequest) (*models.Comment, error) {
 	comment, err := s.commentRepo.GetByID(id)
 	if err != nil {
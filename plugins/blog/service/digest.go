@@ -0,0 +1,40 @@
+package blogservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"constructor-script-backend/internal/models"
+)
+
+// DigestSection lists the posts published between since and until for activity
+// digests. It is the same for every user.
+func (s *PostService) DigestSection(ctx context.Context, _ uint, since, until time.Time, limit int) (models.DigestSection, error) {
+	section := models.DigestSection{Title: "New posts"}
+	if s == nil || s.postRepo == nil {
+		return section, errors.New("post service is not configured")
+	}
+	if err := ctx.Err(); err != nil {
+		return section, err
+	}
+
+	posts, err := s.postRepo.GetPublishedBetween(since, until, limit)
+	if err != nil {
+		return section, fmt.Errorf("failed to load new posts: %w", err)
+	}
+	for _, post := range posts {
+		summary := strings.TrimSpace(post.Excerpt)
+		if summary == "" {
+			summary = strings.TrimSpace(post.Description)
+		}
+		section.Items = append(section.Items, models.DigestItem{
+			Title:   post.Title,
+			Path:    postPath(post.Slug),
+			Summary: summary,
+		})
+	}
+	return section, nil
+}
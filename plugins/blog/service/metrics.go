@@ -0,0 +1,13 @@
+package blogservice
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var postsPublished = promauto.NewCounter(prometheus.CounterOpts{
+	Namespace: "constructor_script",
+	Subsystem: "blog",
+	Name:      "posts_published_total",
+	Help:      "Posts that became published, whether immediately, on edit or on schedule",
+})
@@ -11,10 +11,12 @@ import (
 
 	"constructor-script-backend/internal/background"
 	"constructor-script-backend/internal/constants"
+	"constructor-script-backend/internal/events"
 	"constructor-script-backend/internal/models"
 	"constructor-script-backend/internal/repository"
 	"constructor-script-backend/internal/theme"
 	"constructor-script-backend/pkg/cache"
+	"constructor-script-backend/pkg/lang"
 	"constructor-script-backend/pkg/logger"
 	"constructor-script-backend/pkg/utils"
 
@@ -31,6 +33,16 @@ type PostService struct {
 	settingRepo  repository.SettingRepository
 	scheduler    *background.Scheduler
 	themes       *theme.Manager
+	events       *events.Bus
+	redirects    repository.SlugRedirectRepository
+	revisions    RevisionRecorder
+}
+
+// RevisionRecorder keeps the history of posts. The core revision service
+// implements it.
+type RevisionRecorder interface {
+	Record(snapshot models.Revision) error
+	DeleteContent(contentType string, contentID uint) error
 }
 
 const (
@@ -163,6 +175,149 @@ func NewPostService(
 	}
 }
 
+// SetEventBus configures the bus used to announce post changes.
+func (s *PostService) SetEventBus(bus *events.Bus) {
+	if s == nil {
+		return
+	}
+	s.events = bus
+}
+
+// WithTx returns a copy of s that writes through tx and announces its changes on
+// bus, for changes made as part of a larger transaction. The copy leaves the
+// cache alone, since readers could cache rows the transaction has not committed
+// yet; call InvalidatePosts once it has.
+func (s *PostService) WithTx(tx *gorm.DB, bus *events.Bus) *PostService {
+	scoped := *s
+	scoped.postRepo = repository.NewPostRepository(tx)
+	scoped.tagRepo = repository.NewTagRepository(tx)
+	scoped.categoryRepo = repository.NewCategoryRepository(tx)
+	scoped.commentRepo = repository.NewCommentRepository(tx)
+	if s.redirects != nil {
+		scoped.redirects = repository.NewSlugRedirectRepository(tx)
+	}
+	scoped.cache = nil
+	scoped.events = bus
+	return &scoped
+}
+
+// InvalidatePosts drops the cached copies of the posts with ids and of the post
+// lists and tags.
+func (s *PostService) InvalidatePosts(ids []uint) {
+	if s == nil || s.cache == nil {
+		return
+	}
+	for _, id := range ids {
+		s.cache.InvalidatePost(id)
+	}
+	s.cache.InvalidatePostsCache()
+	s.invalidateTagCaches()
+}
+
+// SetSlugRedirects configures where the previous URLs of renamed posts are kept.
+func (s *PostService) SetSlugRedirects(redirects repository.SlugRedirectRepository) {
+	if s == nil {
+		return
+	}
+	s.redirects = redirects
+}
+
+// SetRevisions configures where the history of posts is kept. Revisions are
+// recorded outside the transactions of WithTx.
+func (s *PostService) SetRevisions(revisions RevisionRecorder) {
+	if s == nil {
+		return
+	}
+	s.revisions = revisions
+}
+
+// recordRevision saves the current content of post to its history.
+func (s *PostService) recordRevision(post *models.Post) {
+	if s == nil || s.revisions == nil || post == nil {
+		return
+	}
+	err := s.revisions.Record(models.Revision{
+		ContentType: models.RevisionContentPost,
+		ContentID:   post.ID,
+		Title:       post.Title,
+		Description: post.Description,
+		Excerpt:     post.Excerpt,
+		Content:     post.Content,
+		Sections:    post.Sections,
+	})
+	if err != nil {
+		logger.Error(err, "Failed to record post revision", map[string]interface{}{"post_id": post.ID})
+	}
+}
+
+// RestoreRevision writes the content of a post revision back to the post.
+func (s *PostService) RestoreRevision(revision *models.Revision) error {
+	sections := []models.Section(revision.Sections)
+	if sections == nil {
+		sections = []models.Section{}
+	}
+	_, err := s.Update(revision.ContentID, models.UpdatePostRequest{
+		Title:       &revision.Title,
+		Description: &revision.Description,
+		Excerpt:     &revision.Excerpt,
+		Content:     &revision.Content,
+		Sections:    &sections,
+	}, 0, true)
+	return err
+}
+
+func postPath(slug string) string {
+	return fmt.Sprintf("/blog/post/%s", slug)
+}
+
+// recordSlugRedirect remembers the URL a post was published under before a rename
+// and releases any redirect now claimed by its current URL.
+func (s *PostService) recordSlugRedirect(post *models.Post, previousSlug string) {
+	if s == nil || s.redirects == nil || post == nil || post.Slug == "" {
+		return
+	}
+	if previousSlug != "" && previousSlug != post.Slug {
+		if err := s.redirects.Record(models.SlugRedirectPost, post.ID, postPath(previousSlug)); err != nil {
+			logger.Error(err, "Failed to record post redirect", map[string]interface{}{"post_id": post.ID, "slug": previousSlug})
+		}
+	}
+	if err := s.redirects.DeleteByPath(postPath(post.Slug)); err != nil {
+		logger.Error(err, "Failed to release post redirect", map[string]interface{}{"post_id": post.ID, "slug": post.Slug})
+	}
+}
+
+// ResolveSlugRedirect returns the published post that used to be served under slug.
+func (s *PostService) ResolveSlugRedirect(slug string) (*models.Post, bool) {
+	if s == nil || s.redirects == nil {
+		return nil, false
+	}
+	redirect, err := s.redirects.GetByPath(postPath(slug))
+	if err != nil || redirect.ContentType != models.SlugRedirectPost {
+		return nil, false
+	}
+	post, err := s.postRepo.GetByID(redirect.ContentID)
+	if err != nil || !post.Published || post.Slug == "" || post.Slug == slug {
+		return nil, false
+	}
+	return post, true
+}
+
+func (s *PostService) publishPostEvent(name string, post *models.Post) {
+	if s == nil || post == nil {
+		return
+	}
+	if name == events.PostPublished {
+		postsPublished.Inc()
+	}
+	s.events.Publish(context.Background(), name, map[string]interface{}{
+		"id":        post.ID,
+		"title":     post.Title,
+		"slug":      post.Slug,
+		"author_id": post.AuthorID,
+		"published": post.Published,
+	})
+}
+
 func (s *PostService) Create(req models.CreatePostRequest, authorID uint) (*models.Post, error) {
 	if req.Title == "" {
 		return nil, errors.New("post title is required")
@@ -188,6 +343,11 @@ func (s *PostService) Create(req models.CreatePostRequest, authorID uint) (*mode
 		content = s.generateContentFromSections(sections)
 	}
 
+	language, err := lang.NormalizeOptional(req.Language)
+	if err != nil {
+		return nil, err
+	}
+
 	categoryID := req.CategoryID
 	if categoryID == 0 && s.categoryRepo != nil {
 		defaultCategory, err := s.categoryRepo.GetBySlug(defaultCategorySlug)
@@ -209,6 +369,13 @@ func (s *PostService) Create(req models.CreatePostRequest, authorID uint) (*mode
 		CategoryID:  categoryID,
 		Sections:    sections,
 		Template:    s.getTemplate(req.Template),
+
+		Language:       language,
+		TranslationKey: utils.GenerateSlug(req.TranslationKey),
+
+		NoIndex:        req.NoIndex,
+		NoFollow:       req.NoFollow,
+		SitemapExclude: req.SitemapExclude,
 	}
 
 	now := time.Now().UTC()
@@ -235,6 +402,13 @@ func (s *PostService) Create(req models.CreatePostRequest, authorID uint) (*mode
 		s.cache.InvalidatePostsCache()
 	}
 
+	s.recordSlugRedirect(post, "")
+	s.recordRevision(post)
+	s.publishPostEvent(events.PostCreated, post)
+	if post.Published {
+		s.publishPostEvent(events.PostPublished, post)
+	}
+
 	return s.postRepo.GetByID(post.ID)
 }
 
@@ -259,6 +433,8 @@ func (s *PostService) Update(id uint, req models.UpdatePostRequest, userID uint,
 	if !canManageAll && post.AuthorID != userID {
 		return nil, errors.New("unauthorized")
 	}
+	wasPublished := post.Published
+	previousSlug := post.Slug
 
 	if req.Title != nil {
 		post.Title = *req.Title
@@ -285,6 +461,25 @@ func (s *PostService) Update(id uint, req models.UpdatePostRequest, userID uint,
 	if req.Template != nil {
 		post.Template = s.getTemplate(*req.Template)
 	}
+	if req.Language != nil {
+		language, err := lang.NormalizeOptional(*req.Language)
+		if err != nil {
+			return nil, err
+		}
+		post.Language = language
+	}
+	if req.TranslationKey != nil {
+		post.TranslationKey = utils.GenerateSlug(*req.TranslationKey)
+	}
+	if req.NoIndex != nil {
+		post.NoIndex = *req.NoIndex
+	}
+	if req.NoFollow != nil {
+		post.NoFollow = *req.NoFollow
+	}
+	if req.SitemapExclude != nil {
+		post.SitemapExclude = *req.SitemapExclude
+	}
 
 	publishAtCandidate := req.PublishAt.Or(post.PublishAt)
 	now := time.Now().UTC()
@@ -324,6 +519,13 @@ func (s *PostService) Update(id uint, req models.UpdatePostRequest, userID uint,
 		s.cache.InvalidatePostsCache()
 	}
 
+	s.recordSlugRedirect(post, previousSlug)
+	s.recordRevision(post)
+	s.publishPostEvent(events.PostUpdated, post)
+	if post.Published && !wasPublished {
+		s.publishPostEvent(events.PostPublished, post)
+	}
+
 	return s.postRepo.GetByID(post.ID)
 }
 
@@ -496,7 +698,8 @@ func (s *PostService) getTemplate(template string) string {
 }
 
 func (s *PostService) getOrCreateTags(tagNames []string) ([]models.Tag, error) {
-	var tags []models.Tag
+	names := make([]string, 0, len(tagNames))
+	slugs := make([]string, 0, len(tagNames))
 	seen := make(map[string]struct{})
 
 	for _, name := range tagNames {
@@ -510,26 +713,39 @@ func (s *PostService) getOrCreateTags(tagNames []string) ([]models.Tag, error) {
 			continue
 		}
 		seen[slug] = struct{}{}
+		names = append(names, name)
+		slugs = append(slugs, slug)
+	}
+	if len(slugs) == 0 {
+		return nil, nil
+	}
 
-		tag, err := s.tagRepo.GetBySlug(slug)
-
-		if err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				tag = &models.Tag{
-					Name: name,
-					Slug: slug,
-				}
-				if err := s.tagRepo.Create(tag); err != nil {
-					return nil, err
-				}
+	// One query for the tags that exist; only new tags cost a query each.
+	existing, err := s.tagRepo.GetBySlugs(slugs)
+	if err != nil {
+		return nil, err
+	}
+	bySlug := make(map[string]models.Tag, len(existing))
+	for _, tag := range existing {
+		bySlug[tag.Slug] = tag
+	}
 
-				s.handleTagChanges()
-			} else {
+	tags := make([]models.Tag, 0, len(slugs))
+	for i, slug := range slugs {
+		tag, ok := bySlug[slug]
+		if !ok {
+			tag = models.Tag{
+				Name: names[i],
+				Slug: slug,
+			}
+			if err := s.tagRepo.Create(&tag); err != nil {
 				return nil, err
 			}
+
+			s.handleTagChanges()
 		}
 
-		tags = append(tags, *tag)
+		tags = append(tags, tag)
 	}
 
 	return tags, nil
@@ -555,20 +771,26 @@ func (s *PostService) Delete(id uint, userID uint, canManageAll bool) error {
 		s.cache.InvalidatePostsCache()
 	}
 
+	if s.redirects != nil {
+		if err := s.redirects.DeleteByContent(models.SlugRedirectPost, post.ID); err != nil {
+			logger.Error(err, "Failed to delete post redirects", map[string]interface{}{"post_id": post.ID})
+		}
+	}
+	if s.revisions != nil {
+		if err := s.revisions.DeleteContent(models.RevisionContentPost, post.ID); err != nil {
+			logger.Error(err, "Failed to delete post revisions", map[string]interface{}{"post_id": post.ID})
+		}
+	}
+
+	s.publishPostEvent(events.PostDeleted, post)
+
 	return nil
 }
 
 func (s *PostService) GetByID(id uint) (*models.Post, error) {
-
-	if s.cache != nil {
-		var post models.Post
-		if err := s.cache.GetCachedPost(id, &post); err == nil {
-			s.trackPostView(&post)
-			return &post, nil
-		}
-	}
-
-	post, err := s.postRepo.GetByID(id)
+	post, err := s.loadPost(fmt.Sprintf("post:%d", id), func() (*models.Post, error) {
+		return s.postRepo.GetByID(id)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -579,17 +801,9 @@ func (s *PostService) GetByID(id uint) (*models.Post, error) {
 }
 
 func (s *PostService) GetBySlug(slug string) (*models.Post, error) {
-
-	if s.cache != nil {
-		var post models.Post
-		cacheKey := fmt.Sprintf("post:slug:%s", slug)
-		if err := s.cache.Get(cacheKey, &post); err == nil {
-			s.trackPostView(&post)
-			return &post, nil
-		}
-	}
-
-	post, err := s.postRepo.GetBySlug(slug)
+	post, err := s.loadPost(fmt.Sprintf("post:slug:%s", slug), func() (*models.Post, error) {
+		return s.postRepo.GetBySlug(slug)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -599,6 +813,24 @@ func (s *PostService) GetBySlug(slug string) (*models.Post, error) {
 	return post, nil
 }
 
+// loadPost reads a post from the cache, or through fetch when it is missing.
+// Readers arriving while fetch runs share its result instead of querying again;
+// trackPostView caches published posts afterwards.
+func (s *PostService) loadPost(cacheKey string, fetch func() (*models.Post, error)) (*models.Post, error) {
+	if s.cache == nil {
+		return fetch()
+	}
+
+	var post models.Post
+	err := s.cache.Load(cacheKey, &post, func() (interface{}, error) {
+		return fetch()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &post, nil
+}
+
 // GetByIdentifier fetches a post by slug or numeric ID without tracking views.
 func (s *PostService) GetByIdentifier(identifier string) (*models.Post, error) {
 	if s == nil || s.postRepo == nil {
@@ -630,12 +862,7 @@ func (s *PostService) trackPostView(post *models.Post) {
 	}
 
 	post.Views++
-
-	go func(postID uint) {
-		if err := s.postRepo.IncrementViews(postID); err != nil {
-			logger.Error(err, "Failed to increment post views", map[string]interface{}{"post_id": postID})
-		}
-	}(post.ID)
+	s.recordView(post.ID, now)
 
 	if s.cache != nil {
 		s.cache.CachePost(post.ID, post)
@@ -696,6 +923,28 @@ func (s *PostService) ListPublishedForSitemap() ([]models.Post, error) {
 	return s.postRepo.GetAllPublished()
 }
 
+// GetTranslations returns the published posts that share translationKey.
+func (s *PostService) GetTranslations(translationKey string) ([]models.Post, error) {
+	translationKey = strings.TrimSpace(translationKey)
+	if translationKey == "" {
+		return nil, nil
+	}
+	if s.postRepo == nil {
+		return nil, errors.New("post repository not configured")
+	}
+
+	return s.postRepo.GetPublishedTranslations(translationKey)
+}
+
+// ListTranslationSummaries returns all posts, drafts included, for the translation
+// status report.
+func (s *PostService) ListTranslationSummaries() ([]models.Post, error) {
+	if s.postRepo == nil {
+		return nil, errors.New("post repository not configured")
+	}
+	return s.postRepo.GetTranslationSummaries()
+}
+
 func (s *PostService) GetAllAdmin(page, limit int) ([]models.Post, int64, error) {
 	offset := (page - 1) * limit
 	return s.postRepo.GetAll(offset, limit, nil, nil, nil, nil)
@@ -956,6 +1205,8 @@ func (s *PostService) PublishPost(postID uint) error {
 		s.cache.InvalidatePostsCache()
 	}
 
+	s.publishPostEvent(events.PostPublished, post)
+
 	return nil
 }
 
@@ -0,0 +1,97 @@
+package blogservice
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"constructor-script-backend/pkg/logger"
+)
+
+// postViewCounters is the cache counter set buffering post views. Fields are
+// "<post id>:<YYYY-MM-DD>" so that a flush after midnight still credits each view
+// to the day it happened.
+const postViewCounters = "post_views"
+
+const postViewDateLayout = "2006-01-02"
+
+// recordView buffers one view of postID. Without a cache there is nothing to buffer
+// into and the view is written straight away.
+func (s *PostService) recordView(postID uint, viewedAt time.Time) {
+	if s.cache != nil {
+		field := fmt.Sprintf("%d:%s", postID, viewedAt.UTC().Format(postViewDateLayout))
+		err := s.cache.AddCounter(postViewCounters, field, 1)
+		if err == nil {
+			return
+		}
+		logger.Error(err, "Failed to buffer post view", map[string]interface{}{"post_id": postID})
+	}
+
+	go func() {
+		if err := s.postRepo.IncrementViews(postID); err != nil {
+			logger.Error(err, "Failed to increment post views", map[string]interface{}{"post_id": postID})
+		}
+	}()
+}
+
+// FlushViews writes the buffered post views to the database, one transaction per
+// day. Counts that could not be written are put back for the next flush.
+func (s *PostService) FlushViews(ctx context.Context) error {
+	if s == nil || s.cache == nil || s.postRepo == nil {
+		return nil
+	}
+
+	buffered, err := s.cache.DrainCounters(postViewCounters)
+	if err != nil {
+		return fmt.Errorf("drain post views: %w", err)
+	}
+
+	byDay := make(map[string]map[uint]int64)
+	for field, views := range buffered {
+		id, day, ok := parsePostViewField(field)
+		if !ok || views <= 0 {
+			continue
+		}
+		if byDay[day] == nil {
+			byDay[day] = make(map[uint]int64)
+		}
+		byDay[day][id] += views
+	}
+
+	var firstErr error
+	for day, counts := range byDay {
+		date, _ := time.Parse(postViewDateLayout, day)
+		err := ctx.Err()
+		if err == nil {
+			err = s.postRepo.AddViews(date, counts)
+		}
+		if err == nil {
+			continue
+		}
+		if firstErr == nil {
+			firstErr = fmt.Errorf("write post views for %s: %w", day, err)
+		}
+		for id, views := range counts {
+			s.cache.AddCounter(postViewCounters, fmt.Sprintf("%d:%s", id, day), views)
+		}
+	}
+
+	return firstErr
+}
+
+func parsePostViewField(field string) (uint, string, bool) {
+	idPart, day, found := strings.Cut(field, ":")
+	if !found {
+		return 0, "", false
+	}
+	id, err := strconv.ParseUint(idPart, 10, 64)
+	if err != nil || id == 0 {
+		return 0, "", false
+	}
+	if _, err := time.Parse(postViewDateLayout, day); err != nil {
+		return 0, "", false
+	}
+	return uint(id), day, true
+}
@@ -0,0 +1,61 @@
+package blogservice
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"constructor-script-backend/internal/repository"
+	"constructor-script-backend/pkg/cache"
+)
+
+type viewRecordingPostRepository struct {
+	repository.PostRepository
+	fail  bool
+	calls map[string]map[uint]int64
+}
+
+func (r *viewRecordingPostRepository) AddViews(date time.Time, counts map[uint]int64) error {
+	if r.fail {
+		return errors.New("database unavailable")
+	}
+	if r.calls == nil {
+		r.calls = make(map[string]map[uint]int64)
+	}
+	r.calls[date.Format(postViewDateLayout)] = counts
+	return nil
+}
+
+func TestFlushViewsWritesBufferedViewsPerDay(t *testing.T) {
+	c, _ := cache.NewCache("", false)
+	repo := &viewRecordingPostRepository{fail: true}
+	svc := &PostService{postRepo: repo, cache: c}
+
+	yesterday := time.Date(2026, 3, 4, 23, 59, 0, 0, time.UTC)
+	today := yesterday.Add(2 * time.Minute)
+	svc.recordView(7, yesterday)
+	svc.recordView(7, today)
+	svc.recordView(7, today)
+	svc.recordView(9, today)
+
+	if err := svc.FlushViews(context.Background()); err == nil {
+		t.Fatal("expected the database error to be reported")
+	}
+
+	repo.fail = false
+	if err := svc.FlushViews(context.Background()); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	if got := repo.calls["2026-03-04"]; len(got) != 1 || got[7] != 1 {
+		t.Fatalf("unexpected views for the first day: %v", got)
+	}
+	if got := repo.calls["2026-03-05"]; len(got) != 2 || got[7] != 2 || got[9] != 1 {
+		t.Fatalf("unexpected views for the second day: %v", got)
+	}
+
+	repo.calls = nil
+	if err := svc.FlushViews(context.Background()); err != nil || len(repo.calls) != 0 {
+		t.Fatalf("expected nothing left to flush, got %v (%v)", repo.calls, err)
+	}
+}
@@ -0,0 +1,24 @@
+package courses
+
+import (
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/plugin/backup"
+)
+
+func init() {
+	backup.Register("courses",
+		&models.CourseVideo{},
+		&models.CourseTopic{},
+		&models.CourseContent{},
+		&models.CoursePackage{},
+		&models.CourseTopicVideo{},
+		&models.CoursePackageTopic{},
+		&models.CoursePackageAccess{},
+		&models.CourseAnnouncement{},
+		&models.CourseTest{},
+		&models.CourseTestQuestion{},
+		&models.CourseTestQuestionOption{},
+		&models.CourseTopicStep{},
+		&models.CourseTestResult{},
+	)
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 
@@ -95,6 +96,8 @@ func (h *AssetHandler) Serve(c *gin.Context) {
 			return
 		}
 		targetURL = video.FileURL
+	case courseservice.AssetTypePoster:
+		targetURL = video.PosterURL
 	case courseservice.AssetTypeAttachment:
 		if claims.AttachmentIndex == nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "attachment reference missing"})
@@ -113,7 +116,7 @@ func (h *AssetHandler) Serve(c *gin.Context) {
 		return
 	}
 
-	filename := h.uploadFilename(targetURL)
+	filename := h.uploadPath(targetURL)
 	if filename == "" {
 		c.JSON(http.StatusNotFound, gin.H{"error": "asset unavailable"})
 		return
@@ -132,7 +135,7 @@ func (h *AssetHandler) Serve(c *gin.Context) {
 	if strings.ToLower(strings.TrimSpace(claims.Type)) == courseservice.AssetTypeAttachment {
 		disposition := sanitizeDispositionName(downloadName)
 		if disposition == "" {
-			disposition = filename
+			disposition = filepath.Base(filename)
 		}
 		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", disposition))
 	}
@@ -140,7 +143,9 @@ func (h *AssetHandler) Serve(c *gin.Context) {
 	c.File(filePath)
 }
 
-func (h *AssetHandler) uploadFilename(raw string) string {
+// uploadPath returns the path of an upload URL relative to the upload directory.
+// Generated files such as video posters live in subdirectories.
+func (h *AssetHandler) uploadPath(raw string) string {
 	trimmed := strings.TrimSpace(raw)
 	if trimmed == "" {
 		return ""
@@ -158,12 +163,12 @@ func (h *AssetHandler) uploadFilename(raw string) string {
 		return ""
 	}
 
-	filename := filepath.Base(trimmed)
-	if filename == "" || filename == "." || filename == ".." {
+	relative := path.Clean(strings.TrimPrefix(trimmed, "/uploads/"))
+	if relative == "" || relative == "." || relative == ".." || strings.HasPrefix(relative, "../") || strings.HasPrefix(relative, "/") {
 		return ""
 	}
 
-	return filename
+	return filepath.FromSlash(relative)
 }
 
 func (h *AssetHandler) resolveFilePath(filename string) (string, error) {
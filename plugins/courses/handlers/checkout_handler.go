@@ -1,10 +1,8 @@
 package handlers
 
 import (
-	"encoding/json"
 	"errors"
 	"net/http"
-	"strconv"
 	"strings"
 	"time"
 
@@ -12,7 +10,9 @@ import (
 	"gorm.io/gorm"
 
 	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/payments"
 	"constructor-script-backend/internal/payments/stripe"
+	"constructor-script-backend/pkg/apierror"
 	"constructor-script-backend/pkg/logger"
 	courseservice "constructor-script-backend/plugins/courses/service"
 )
@@ -22,6 +22,7 @@ type CheckoutHandler struct {
 	service        *courseservice.CheckoutService
 	packageService *courseservice.PackageService
 	webhookSecret  string
+	receiptMailer  courseservice.ReceiptMailer
 }
 
 // NewCheckoutHandler constructs a handler instance.
@@ -53,6 +54,22 @@ func (h *CheckoutHandler) SetWebhookSecret(secret string) {
 	h.webhookSecret = strings.TrimSpace(secret)
 }
 
+// SetReceiptMailer updates the mailer used to send purchase receipts.
+func (h *CheckoutHandler) SetReceiptMailer(mailer courseservice.ReceiptMailer) {
+	if h == nil {
+		return
+	}
+	h.receiptMailer = mailer
+}
+
+// sendReceipt emails the buyer a receipt for a fulfilled session. Failures are
+// logged only, since access has been granted.
+func (h *CheckoutHandler) sendReceipt(session *payments.SessionDetails) {
+	if err := h.packageService.SendPurchaseReceipt(h.receiptMailer, session, h.service.Config().Currency); err != nil {
+		logger.Warn("Failed to send course receipt", map[string]interface{}{"session_id": session.ID, "error": err.Error()})
+	}
+}
+
 func (h *CheckoutHandler) ensureService(c *gin.Context) bool {
 	if h == nil || h.service == nil {
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "course checkout service unavailable"})
@@ -167,21 +184,6 @@ func (h *CheckoutHandler) writeError(c *gin.Context, err error) {
 	}
 }
 
-type stripeCheckoutSession struct {
-	ID            string            `json:"id"`
-	Status        string            `json:"status"`
-	PaymentStatus string            `json:"payment_status"`
-	Metadata      map[string]string `json:"metadata"`
-	CustomerEmail string            `json:"customer_email"`
-}
-
-type stripeWebhookEvent struct {
-	Type string `json:"type"`
-	Data struct {
-		Object stripeCheckoutSession `json:"object"`
-	} `json:"data"`
-}
-
 type verifyCheckoutRequest struct {
 	SessionID string `json:"session_id" binding:"required"`
 }
@@ -242,28 +244,18 @@ func (h *CheckoutHandler) HandleWebhook(c *gin.Context) {
 		return
 	}
 
-	if err := stripe.VerifyWebhookSignature(payload, signature, secret, 5*time.Minute); err != nil {
-		logger.Warn("Invalid Stripe webhook signature", map[string]interface{}{
-			"request_id": baseFields["request_id"],
-			"error":      err.Error(),
-			"webhook":    baseFields["webhook"],
-		})
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid webhook signature"})
-		return
-	}
-
-	var event stripeWebhookEvent
-	if err := json.Unmarshal(payload, &event); err != nil {
-		logger.Warn("Invalid Stripe webhook payload", map[string]interface{}{
+	event, err := stripe.ParseWebhookEvent(payload, signature, secret, 5*time.Minute)
+	if err != nil {
+		logger.Warn("Invalid Stripe webhook", map[string]interface{}{
 			"request_id": baseFields["request_id"],
 			"error":      err.Error(),
 			"webhook":    baseFields["webhook"],
 		})
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid webhook payload"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid webhook"})
 		return
 	}
 
-	if !strings.EqualFold(event.Type, "checkout.session.completed") {
+	if !event.Completed() {
 		logger.Info("Ignored Stripe webhook event", map[string]interface{}{
 			"request_id": baseFields["request_id"],
 			"event_type": event.Type,
@@ -273,7 +265,7 @@ func (h *CheckoutHandler) HandleWebhook(c *gin.Context) {
 		return
 	}
 
-	session := event.Data.Object
+	session := event.Session
 	logger.Info("Stripe checkout session parsed", map[string]interface{}{
 		"request_id":     baseFields["request_id"],
 		"event_type":     event.Type,
@@ -284,45 +276,26 @@ func (h *CheckoutHandler) HandleWebhook(c *gin.Context) {
 		"webhook":        baseFields["webhook"],
 	})
 
-	if strings.ToLower(strings.TrimSpace(session.PaymentStatus)) != "paid" && strings.ToLower(strings.TrimSpace(session.Status)) != "complete" {
+	if !payments.IsPaid(&session) {
 		logger.Info("Checkout session not paid yet", map[string]interface{}{
 			"request_id":     baseFields["request_id"],
-			"event_type":     event.Type,
 			"session_id":     session.ID,
 			"payment_status": session.PaymentStatus,
 			"session_status": session.Status,
-			"customer_email": session.CustomerEmail,
 			"webhook":        baseFields["webhook"],
 		})
 		c.Status(http.StatusOK)
 		return
 	}
 
-	metadata := session.Metadata
-	if len(metadata) == 0 {
-		logger.Warn("Stripe checkout session missing metadata", map[string]interface{}{
-			"request_id": baseFields["request_id"],
-			"event_type": event.Type,
-			"session_id": session.ID,
-			"webhook":    baseFields["webhook"],
-		})
-		c.Status(http.StatusOK)
-		return
-	}
-
-	packageID := parseUint(metadata["course_package_id"])
-	if packageID == 0 {
-		packageID = parseUint(metadata["package_id"])
-	}
-	userID := parseUint(metadata["user_id"])
-
+	packageID, userID := courseservice.PurchaseFromSession(&session)
 	if packageID == 0 || userID == 0 {
 		logger.Warn("Checkout webhook missing identifiers", map[string]interface{}{
 			"request_id": baseFields["request_id"],
 			"session_id": session.ID,
 			"package_id": packageID,
 			"user_id":    userID,
-			"metadata":   metadata,
+			"metadata":   session.Metadata,
 			"webhook":    baseFields["webhook"],
 		})
 		c.Status(http.StatusOK)
@@ -349,6 +322,8 @@ func (h *CheckoutHandler) HandleWebhook(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to grant course access"})
 		return
 	}
+	payments.RecordCheckoutCompleted(&session)
+	h.sendReceipt(&session)
 
 	logger.Info("Granted course access after Stripe checkout", map[string]interface{}{
 		"request_id": baseFields["request_id"],
@@ -385,7 +360,7 @@ func (h *CheckoutHandler) VerifySession(c *gin.Context) {
 
 	var req verifyCheckoutRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.BindError(c, err)
 		return
 	}
 	sessionID := strings.TrimSpace(req.SessionID)
@@ -412,25 +387,19 @@ func (h *CheckoutHandler) VerifySession(c *gin.Context) {
 		return
 	}
 
-	if !strings.EqualFold(strings.TrimSpace(session.PaymentStatus), "paid") && !strings.EqualFold(strings.TrimSpace(session.Status), "complete") {
+	if !payments.IsPaid(session) {
 		c.JSON(http.StatusAccepted, gin.H{"status": "pending"})
 		return
 	}
 
-	metadata := session.Metadata
-	packageID := parseUint(metadata["course_package_id"])
-	if packageID == 0 {
-		packageID = parseUint(metadata["package_id"])
-	}
-	metaUserID := parseUint(metadata["user_id"])
-
+	packageID, metaUserID := courseservice.PurchaseFromSession(session)
 	if packageID == 0 || metaUserID == 0 {
 		logger.Warn("Checkout verification missing identifiers", map[string]interface{}{
 			"request_id": baseFields["request_id"],
 			"session_id": session.ID,
 			"package_id": packageID,
 			"user_id":    metaUserID,
-			"metadata":   metadata,
+			"metadata":   session.Metadata,
 		})
 		c.JSON(http.StatusBadRequest, gin.H{"error": "checkout session missing identifiers"})
 		return
@@ -452,6 +421,8 @@ func (h *CheckoutHandler) VerifySession(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to grant course access"})
 		return
 	}
+	payments.RecordCheckoutCompleted(session)
+	h.sendReceipt(session)
 
 	logger.Info("Granted course access after checkout verification", map[string]interface{}{
 		"request_id": baseFields["request_id"],
@@ -486,15 +457,3 @@ func logContextFields(c *gin.Context) map[string]interface{} {
 	fields["request_id"] = strings.TrimSpace(c.GetString("request_id"))
 	return fields
 }
-
-func parseUint(value string) uint {
-	trimmed := strings.TrimSpace(value)
-	if trimmed == "" {
-		return 0
-	}
-	id, err := strconv.ParseUint(trimmed, 10, 64)
-	if err != nil || id == 0 {
-		return 0
-	}
-	return uint(id)
-}
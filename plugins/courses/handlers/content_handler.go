@@ -8,6 +8,7 @@ import (
 	"gorm.io/gorm"
 
 	"constructor-script-backend/internal/models"
+	"constructor-script-backend/pkg/apierror"
 	courseservice "constructor-script-backend/plugins/courses/service"
 )
 
@@ -41,7 +42,7 @@ func (h *ContentHandler) Create(c *gin.Context) {
 
 	var req models.CreateCourseContentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.BindError(c, err)
 		return
 	}
 
@@ -66,7 +67,7 @@ func (h *ContentHandler) Update(c *gin.Context) {
 
 	var req models.UpdateCourseContentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.BindError(c, err)
 		return
 	}
 
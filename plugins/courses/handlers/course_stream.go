@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"constructor-script-backend/internal/models"
+)
+
+// topicsSuffix ends an encoded package with no topics. Topics is the last field of
+// models.CoursePackage, so the topics can be written in its place one at a time.
+var topicsSuffix = []byte(`,"topics":[]}`)
+
+// writeUserCourse writes {"course": course} encoding one topic at a time, so that a
+// large curriculum is never held in memory as a single document and the client
+// starts receiving it while later topics are still being encoded.
+func writeUserCourse(c *gin.Context, course *models.UserCoursePackage) {
+	pkg := course.Package
+	pkg.Topics = []models.CourseTopic{}
+	head, err := json.Marshal(pkg)
+	if err != nil || !bytes.HasSuffix(head, topicsSuffix) {
+		c.JSON(http.StatusOK, gin.H{"course": course})
+		return
+	}
+	access, err := json.Marshal(course.Access)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	announcements, err := json.Marshal(course.Announcements)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.Status(http.StatusOK)
+
+	w := c.Writer
+	w.WriteString(`{"course":{"package":`)
+	w.Write(head[:len(head)-len("]}")])
+	for index := range course.Package.Topics {
+		if index > 0 {
+			w.WriteString(",")
+		}
+		topic, err := json.Marshal(course.Package.Topics[index])
+		if err != nil {
+			// The status is already sent; cut the document short so the client
+			// fails to parse it rather than receiving a course with topics missing.
+			c.Error(err)
+			return
+		}
+		w.Write(topic)
+		w.Flush()
+	}
+	w.WriteString(`]},"access":`)
+	w.Write(access)
+	w.WriteString(`,"announcements":`)
+	w.Write(announcements)
+	w.WriteString("}}")
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"constructor-script-backend/internal/models"
+)
+
+func TestWriteUserCourseMatchesEncodedCourse(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	expires := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	course := &models.UserCoursePackage{
+		Package: models.CoursePackage{
+			ID:    3,
+			Title: `Go "in depth"`,
+			Slug:  "go",
+			Topics: []models.CourseTopic{
+				{ID: 1, Title: "Basics", Steps: []models.CourseTopicStep{
+					{ID: 10, StepType: models.CourseTopicStepTypeVideo, Video: &models.CourseVideo{ID: 4, Title: "Intro", SectionsHTML: "<p>hi</p>"}},
+				}},
+				{ID: 2, Title: "Topics", Steps: []models.CourseTopicStep{}},
+			},
+		},
+		Access: models.CoursePackageAccess{UserID: 9, PackageID: 3, ExpiresAt: &expires},
+	}
+
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	writeUserCourse(c, course)
+
+	var streamed, expected interface{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &streamed); err != nil {
+		t.Fatalf("streamed body is not valid JSON: %v\n%s", err, recorder.Body.String())
+	}
+	encoded, _ := json.Marshal(gin.H{"course": course})
+	json.Unmarshal(encoded, &expected)
+	if !reflect.DeepEqual(streamed, expected) {
+		t.Fatalf("streamed course differs:\n%s\n%s", recorder.Body.String(), encoded)
+	}
+}
@@ -8,7 +8,10 @@ import (
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 
+	"constructor-script-backend/internal/csvexport"
+	"constructor-script-backend/internal/fieldset"
 	"constructor-script-backend/internal/models"
+	"constructor-script-backend/pkg/apierror"
 	courseservice "constructor-script-backend/plugins/courses/service"
 )
 
@@ -51,7 +54,7 @@ func (h *PackageHandler) Create(c *gin.Context) {
 
 	var req models.CreateCoursePackageRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.BindError(c, err)
 		return
 	}
 
@@ -76,7 +79,7 @@ func (h *PackageHandler) Update(c *gin.Context) {
 
 	var req models.UpdateCoursePackageRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.BindError(c, err)
 		return
 	}
 
@@ -101,7 +104,7 @@ func (h *PackageHandler) UpdateTopics(c *gin.Context) {
 
 	var req models.ReorderCoursePackageTopicsRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.BindError(c, err)
 		return
 	}
 
@@ -126,7 +129,7 @@ func (h *PackageHandler) GrantToUser(c *gin.Context) {
 
 	var req models.GrantCoursePackageRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.BindError(c, err)
 		return
 	}
 
@@ -141,6 +144,47 @@ func (h *PackageHandler) GrantToUser(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"access": access})
 }
 
+// ListGrants returns the access grants of a package with the accounts they were
+// granted to, or streams them as CSV with ?format=csv.
+func (h *PackageHandler) ListGrants(c *gin.Context) {
+	if !h.ensureService(c) {
+		return
+	}
+
+	packageID, ok := parseUintParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if csvexport.Requested(c) {
+		export := csvexport.New(c, "course-grants", "id", "granted_at", "user_id", "username", "email", "granted_by", "expires_at")
+		err := h.service.GrantBatches(packageID, func(grants []models.CoursePackageGrant) error {
+			for _, grant := range grants {
+				if err := export.Row(grant.ID, grant.CreatedAt, grant.UserID, grant.Username, grant.Email, grant.GrantedBy, grant.ExpiresAt); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err := export.Finish(err); err != nil {
+			h.writeError(c, err)
+		}
+		return
+	}
+
+	grants := make([]models.CoursePackageGrant, 0)
+	err := h.service.GrantBatches(packageID, func(batch []models.CoursePackageGrant) error {
+		grants = append(grants, batch...)
+		return nil
+	})
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"grants": grants})
+}
+
 func (h *PackageHandler) Delete(c *gin.Context) {
 	if !h.ensureService(c) {
 		return
@@ -159,6 +203,71 @@ func (h *PackageHandler) Delete(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
+// ListAnnouncements returns the announcements of a package, newest first.
+func (h *PackageHandler) ListAnnouncements(c *gin.Context) {
+	if !h.ensureService(c) {
+		return
+	}
+
+	packageID, ok := parseUintParam(c, "id")
+	if !ok {
+		return
+	}
+
+	announcements, err := h.service.ListAnnouncements(packageID)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"announcements": announcements})
+}
+
+// CreateAnnouncement posts an announcement to the learners of a package. It
+// reaches them through their activity digests.
+func (h *PackageHandler) CreateAnnouncement(c *gin.Context) {
+	if !h.ensureService(c) {
+		return
+	}
+
+	packageID, ok := parseUintParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.CreateCourseAnnouncementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BindError(c, err)
+		return
+	}
+
+	announcement, err := h.service.CreateAnnouncement(packageID, c.GetUint("user_id"), req)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"announcement": announcement})
+}
+
+func (h *PackageHandler) DeleteAnnouncement(c *gin.Context) {
+	if !h.ensureService(c) {
+		return
+	}
+
+	id, ok := parseUintParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteAnnouncement(id); err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
 func (h *PackageHandler) Get(c *gin.Context) {
 	if !h.ensureService(c) {
 		return
@@ -211,21 +320,37 @@ func (h *PackageHandler) GetForUser(c *gin.Context) {
 		course = h.protection.ProtectCourseForUser(course, userID)
 	}
 
-	c.JSON(http.StatusOK, gin.H{"course": course})
+	c.Header("Cache-Control", "private, no-store")
+	writeUserCourse(c, course)
 }
 
+// packageFields is what ?fields= and ?include= select from on package lists.
+var packageFields = fieldset.NewSchema(models.CoursePackage{}, "topics")
+
 func (h *PackageHandler) List(c *gin.Context) {
 	if !h.ensureService(c) {
 		return
 	}
 
+	selection, err := fieldset.FromQuery(c, packageFields)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	pkgs, err := h.service.List()
 	if err != nil {
 		h.writeError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"packages": pkgs})
+	items, err := selection.Apply(pkgs)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"packages": items})
 }
 
 func (h *PackageHandler) writeError(c *gin.Context, err error) {
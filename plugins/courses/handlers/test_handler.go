@@ -7,7 +7,9 @@ import (
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 
+	"constructor-script-backend/internal/csvexport"
 	"constructor-script-backend/internal/models"
+	"constructor-script-backend/pkg/apierror"
 	courseservice "constructor-script-backend/plugins/courses/service"
 )
 
@@ -41,7 +43,7 @@ func (h *TestHandler) Create(c *gin.Context) {
 
 	var req models.CreateCourseTestRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.BindError(c, err)
 		return
 	}
 
@@ -66,7 +68,7 @@ func (h *TestHandler) Update(c *gin.Context) {
 
 	var req models.UpdateCourseTestRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.BindError(c, err)
 		return
 	}
 
@@ -130,6 +132,47 @@ func (h *TestHandler) List(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"tests": tests})
 }
 
+// ListResults returns every submitted result of a test, or streams them as CSV
+// with ?format=csv.
+func (h *TestHandler) ListResults(c *gin.Context) {
+	if !h.ensureService(c) {
+		return
+	}
+
+	id, ok := parseUintParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if csvexport.Requested(c) {
+		export := csvexport.New(c, "course-test-results", "id", "submitted_at", "user_id", "username", "email", "score", "max_score")
+		err := h.service.ResultBatches(id, func(results []models.CourseTestResultEntry) error {
+			for _, result := range results {
+				if err := export.Row(result.ID, result.CreatedAt, result.UserID, result.Username, result.Email, result.Score, result.MaxScore); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err := export.Finish(err); err != nil {
+			h.writeError(c, err)
+		}
+		return
+	}
+
+	results := make([]models.CourseTestResultEntry, 0)
+	err := h.service.ResultBatches(id, func(batch []models.CourseTestResultEntry) error {
+		results = append(results, batch...)
+		return nil
+	})
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"results": results})
+}
+
 func (h *TestHandler) Submit(c *gin.Context) {
 	if !h.ensureService(c) {
 		return
@@ -148,7 +191,7 @@ func (h *TestHandler) Submit(c *gin.Context) {
 
 	var req models.SubmitCourseTestRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.BindError(c, err)
 		return
 	}
 
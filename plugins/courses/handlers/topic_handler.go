@@ -10,6 +10,7 @@ import (
 	"gorm.io/gorm"
 
 	"constructor-script-backend/internal/models"
+	"constructor-script-backend/pkg/apierror"
 	courseservice "constructor-script-backend/plugins/courses/service"
 )
 
@@ -43,7 +44,7 @@ func (h *TopicHandler) Create(c *gin.Context) {
 
 	var req models.CreateCourseTopicRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.BindError(c, err)
 		return
 	}
 
@@ -68,7 +69,7 @@ func (h *TopicHandler) Update(c *gin.Context) {
 
 	var req models.UpdateCourseTopicRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.BindError(c, err)
 		return
 	}
 
@@ -93,7 +94,7 @@ func (h *TopicHandler) UpdateVideos(c *gin.Context) {
 
 	var req models.ReorderCourseTopicVideosRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.BindError(c, err)
 		return
 	}
 
@@ -118,7 +119,7 @@ func (h *TopicHandler) UpdateSteps(c *gin.Context) {
 
 	var req models.UpdateCourseTopicStepsRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.BindError(c, err)
 		return
 	}
 
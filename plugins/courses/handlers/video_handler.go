@@ -9,6 +9,7 @@ import (
 	"gorm.io/gorm"
 
 	"constructor-script-backend/internal/models"
+	"constructor-script-backend/pkg/apierror"
 	courseservice "constructor-script-backend/plugins/courses/service"
 )
 
@@ -42,7 +43,7 @@ func (h *VideoHandler) Create(c *gin.Context) {
 
 	var req models.CreateCourseVideoRequest
 	if err := c.ShouldBind(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.BindError(c, err)
 		return
 	}
 
@@ -83,7 +84,7 @@ func (h *VideoHandler) Update(c *gin.Context) {
 
 	var req models.UpdateCourseVideoRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.BindError(c, err)
 		return
 	}
 
@@ -108,7 +109,7 @@ func (h *VideoHandler) UpdateSubtitle(c *gin.Context) {
 
 	var req models.UpdateCourseVideoSubtitleRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.BindError(c, err)
 		return
 	}
 
@@ -0,0 +1,251 @@
+package courses
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/plugin/migrations"
+	"constructor-script-backend/pkg/utils"
+)
+
+func init() {
+	migrations.RegisterTables("courses",
+		&models.CourseVideo{},
+		&models.CourseTopic{},
+		&models.CourseContent{},
+		&models.CoursePackage{},
+		&models.CourseTopicVideo{},
+		&models.CoursePackageTopic{},
+		&models.CoursePackageAccess{},
+		&models.CourseAnnouncement{},
+		&models.CourseTest{},
+		&models.CourseTestQuestion{},
+		&models.CourseTestQuestionOption{},
+		&models.CourseTopicStep{},
+		&models.CourseTestResult{},
+	)
+	migrations.Register("courses",
+		migrations.Migration{
+			Version:     1,
+			Description: "add test and content references to topic steps",
+			Stage:       migrations.StagePreSchema,
+			Up:          addTopicStepReferences,
+		},
+		migrations.Migration{
+			Version:     2,
+			Description: "backfill unique topic and package slugs",
+			Stage:       migrations.StagePreSchema,
+			Up:          ensureCourseSlugs,
+		},
+		migrations.Migration{
+			Version:     3,
+			Description: "index topic step references",
+			Up:          indexTopicStepReferences,
+			Down: func(tx *gorm.DB) error {
+				if err := tx.Exec("DROP INDEX IF EXISTS idx_course_topic_steps_test_id").Error; err != nil {
+					return err
+				}
+				return tx.Exec("DROP INDEX IF EXISTS idx_course_topic_steps_content_id").Error
+			},
+		},
+		migrations.Migration{
+			Version:     4,
+			Description: "convert legacy topic videos to steps",
+			Up:          migrateTopicVideosToSteps,
+		},
+	)
+}
+
+func addTopicStepReferences(tx *gorm.DB) error {
+	if !tx.Migrator().HasTable(&models.CourseTopicStep{}) {
+		return nil
+	}
+	if err := tx.Exec("ALTER TABLE course_topic_steps ADD COLUMN IF NOT EXISTS test_id bigint").Error; err != nil {
+		return fmt.Errorf("failed to ensure course topic step test reference: %w", err)
+	}
+	if err := tx.Exec("ALTER TABLE course_topic_steps ADD COLUMN IF NOT EXISTS content_id bigint").Error; err != nil {
+		return fmt.Errorf("failed to ensure course topic step content reference: %w", err)
+	}
+	return nil
+}
+
+func indexTopicStepReferences(tx *gorm.DB) error {
+	if !tx.Migrator().HasTable(&models.CourseTopicStep{}) {
+		return nil
+	}
+	if err := tx.Exec("CREATE INDEX IF NOT EXISTS idx_course_topic_steps_test_id ON course_topic_steps(test_id)").Error; err != nil {
+		return fmt.Errorf("failed to ensure course topic step test index: %w", err)
+	}
+	if err := tx.Exec("CREATE INDEX IF NOT EXISTS idx_course_topic_steps_content_id ON course_topic_steps(content_id)").Error; err != nil {
+		return fmt.Errorf("failed to ensure course topic step content index: %w", err)
+	}
+	return nil
+}
+
+func ensureCourseSlugs(tx *gorm.DB) error {
+	migrator := tx.Migrator()
+
+	if migrator.HasTable(&models.CourseTopic{}) {
+		if !migrator.HasColumn(&models.CourseTopic{}, "slug") {
+			if err := tx.Exec("ALTER TABLE course_topics ADD COLUMN slug text").Error; err != nil {
+				return fmt.Errorf("failed to add course topic slug column: %w", err)
+			}
+		}
+		if err := ensureSlugsForTable(tx, "course_topics", "topic", "idx_course_topics_slug"); err != nil {
+			return fmt.Errorf("failed to ensure course topic slugs: %w", err)
+		}
+	}
+
+	if migrator.HasTable(&models.CoursePackage{}) {
+		if !migrator.HasColumn(&models.CoursePackage{}, "slug") {
+			if err := tx.Exec("ALTER TABLE course_packages ADD COLUMN slug text").Error; err != nil {
+				return fmt.Errorf("failed to add course package slug column: %w", err)
+			}
+		}
+		if err := ensureSlugsForTable(tx, "course_packages", "package", "idx_course_packages_slug"); err != nil {
+			return fmt.Errorf("failed to ensure course package slugs: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func ensureSlugsForTable(tx *gorm.DB, tableName, fallbackPrefix, indexName string) error {
+	type slugRow struct {
+		ID        uint
+		Title     string
+		Slug      sql.NullString
+		DeletedAt gorm.DeletedAt
+	}
+
+	var rows []slugRow
+	if err := tx.Table(tableName).Select("id, title, slug, deleted_at").Order("id ASC").Find(&rows).Error; err != nil {
+		return fmt.Errorf("failed to load %s rows for slug backfill: %w", tableName, err)
+	}
+
+	existing := make(map[string]struct{}, len(rows))
+
+	ensureSlug := func(row slugRow, enforceUnique bool) error {
+		base := strings.TrimSpace(strings.ToLower(row.Slug.String))
+		if base == "" {
+			generated := utils.GenerateSlug(row.Title)
+			base = strings.TrimSpace(strings.ToLower(generated))
+		}
+
+		if base == "" {
+			base = fmt.Sprintf("%s-%d", fallbackPrefix, row.ID)
+		}
+
+		candidate := base
+		if enforceUnique {
+			suffix := 0
+			for {
+				var attempt string
+				switch suffix {
+				case 0:
+					attempt = base
+				case 1:
+					attempt = fmt.Sprintf("%s-%d", base, row.ID)
+				default:
+					attempt = fmt.Sprintf("%s-%d-%d", base, row.ID, suffix)
+				}
+
+				if _, exists := existing[attempt]; !exists {
+					candidate = attempt
+					break
+				}
+
+				suffix++
+			}
+		}
+
+		current := strings.TrimSpace(row.Slug.String)
+		currentLower := strings.TrimSpace(strings.ToLower(row.Slug.String))
+		if !row.Slug.Valid || currentLower != candidate || current != candidate || row.Slug.String != current {
+			if err := tx.Exec(fmt.Sprintf("UPDATE %s SET slug = ? WHERE id = ?", tableName), candidate, row.ID).Error; err != nil {
+				return fmt.Errorf("failed to update %s slug for id %d: %w", tableName, row.ID, err)
+			}
+			current = candidate
+		}
+
+		if enforceUnique {
+			existing[current] = struct{}{}
+		}
+
+		return nil
+	}
+
+	for _, row := range rows {
+		if row.DeletedAt.Valid {
+			continue
+		}
+		if err := ensureSlug(row, true); err != nil {
+			return err
+		}
+	}
+
+	for _, row := range rows {
+		if !row.DeletedAt.Valid {
+			continue
+		}
+		if err := ensureSlug(row, false); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Exec(fmt.Sprintf("ALTER TABLE %s ALTER COLUMN slug SET NOT NULL", tableName)).Error; err != nil {
+		return fmt.Errorf("failed to enforce NOT NULL on %s slug column: %w", tableName, err)
+	}
+
+	if err := tx.Exec(fmt.Sprintf("DROP INDEX IF EXISTS %s", indexName)).Error; err != nil {
+		return fmt.Errorf("failed to drop legacy %s slug index: %w", tableName, err)
+	}
+
+	createStmt := fmt.Sprintf("CREATE UNIQUE INDEX %s ON %s (slug) WHERE deleted_at IS NULL", indexName, tableName)
+	if err := tx.Exec(createStmt).Error; err != nil {
+		return fmt.Errorf("failed to ensure unique index for %s slug column: %w", tableName, err)
+	}
+
+	return nil
+}
+
+func migrateTopicVideosToSteps(tx *gorm.DB) error {
+	migrator := tx.Migrator()
+	if !migrator.HasTable(&models.CourseTopicVideo{}) || !migrator.HasTable(&models.CourseTopicStep{}) {
+		return nil
+	}
+
+	var stepCount int64
+	if err := tx.Model(&models.CourseTopicStep{}).Count(&stepCount).Error; err != nil {
+		return fmt.Errorf("failed to count course topic steps: %w", err)
+	}
+	if stepCount > 0 {
+		return nil
+	}
+
+	var links []models.CourseTopicVideo
+	if err := tx.Order("topic_id ASC, position ASC").Find(&links).Error; err != nil {
+		return fmt.Errorf("failed to load legacy topic videos: %w", err)
+	}
+
+	for _, link := range links {
+		videoID := link.VideoID
+		step := models.CourseTopicStep{
+			CreatedAt: link.CreatedAt,
+			UpdatedAt: link.UpdatedAt,
+			TopicID:   link.TopicID,
+			StepType:  models.CourseTopicStepTypeVideo,
+			Position:  link.Position,
+			VideoID:   &videoID,
+		}
+		if err := tx.Create(&step).Error; err != nil {
+			return fmt.Errorf("failed to migrate topic videos to steps: %w", err)
+		}
+	}
+
+	return nil
+}
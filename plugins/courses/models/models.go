@@ -67,6 +67,8 @@ type CourseVideo struct {
 	FileURL         string `gorm:"not null" json:"file_url"`
 	Filename        string `gorm:"not null" json:"filename"`
 	DurationSeconds int    `gorm:"not null" json:"duration_seconds"`
+	// PosterURL is a frame extracted from the video after upload, empty until then.
+	PosterURL string `json:"poster_url"`
 
 	Sections     Sections         `gorm:"type:jsonb" json:"sections"`
 	Attachments  VideoAttachments `gorm:"type:jsonb" json:"attachments"`
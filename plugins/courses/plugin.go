@@ -1,12 +1,14 @@
 package courses
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
 
+	"constructor-script-backend/internal/background"
 	"constructor-script-backend/internal/models"
-	"constructor-script-backend/internal/payments/stripe"
+	"constructor-script-backend/internal/payments"
 	"constructor-script-backend/internal/plugin/host"
 	"constructor-script-backend/internal/plugin/registry"
 	pluginruntime "constructor-script-backend/internal/plugin/runtime"
@@ -20,8 +22,16 @@ func init() {
 	registry.Register("courses", NewFeature)
 }
 
+const (
+	expiryReminderJob      = "courses.access_expiry_reminders"
+	expiryReminderSchedule = "0 8 * * *"
+	expiryReminderLead     = 72 * time.Hour
+)
+
 type Feature struct {
 	host host.Host
+
+	stopVideoUpdates func()
 }
 
 func NewFeature(h host.Host) (pluginruntime.Feature, error) {
@@ -73,6 +83,10 @@ func (f *Feature) Activate() error {
 		videoService.SetUploadService(uploadService)
 		videoService.SetThemeManager(f.host.ThemeManager())
 	}
+	if f.stopVideoUpdates != nil {
+		f.stopVideoUpdates()
+	}
+	f.stopVideoUpdates = uploadService.OnVideoProcessed(videoService.ApplyVideoMetadata)
 
 	var testService *courseservice.TestService
 	if value, ok := services.Get(courseapi.ServiceTest).(*courseservice.TestService); ok {
@@ -117,62 +131,63 @@ func (f *Feature) Activate() error {
 	} else {
 		packageService.SetRepositories(packageRepo, topicRepo, videoRepo, testRepo, contentRepo, accessRepo, userRepo)
 	}
+	packageService.SetAnnouncementRepository(repos.CourseAnnouncement())
+	var (
+		announcementNotifier courseservice.AnnouncementNotifier
+		announcementMailer   courseservice.AnnouncementMailer
+	)
+	if notifications := coreServices.Notification(); notifications != nil {
+		announcementNotifier = notifications
+	}
+	if email := coreServices.Email(); email != nil {
+		announcementMailer = email
+	}
+	packageService.SetAnnouncementDelivery(announcementNotifier, announcementMailer)
+	if notifications := coreServices.Notification(); notifications != nil {
+		packageService.SetNotificationPreferences(notifications)
+	} else {
+		packageService.SetNotificationPreferences(nil)
+	}
 
 	cfg := f.host.Config()
 	checkoutConfig := courseservice.CheckoutConfig{}
 	var (
-		checkoutProvider *stripe.Provider
-		stripeSecret     string
-		stripePublish    string
+		checkoutProvider payments.Provider
 		stripeWebhook    string
 		materialProtect  *courseservice.MaterialProtection
 		uploadDir        string
 	)
 	if cfg != nil {
-		checkoutConfig = courseservice.CheckoutConfig{
-			SuccessURL: cfg.CourseCheckoutSuccessURL,
-			CancelURL:  cfg.CourseCheckoutCancelURL,
-			Currency:   cfg.CourseCheckoutCurrency,
-		}
-		stripeSecret = strings.TrimSpace(cfg.StripeSecretKey)
-		stripePublish = strings.TrimSpace(cfg.StripePublishableKey)
-		stripeWebhook = strings.TrimSpace(cfg.StripeWebhookSecret)
+		checkoutConfig.SuccessURL = cfg.CourseCheckoutSuccessURL
+		checkoutConfig.CancelURL = cfg.CourseCheckoutCancelURL
 		materialProtect = courseservice.NewMaterialProtection(cfg.JWTSecret)
 		uploadDir = strings.TrimSpace(cfg.UploadDir)
+	}
+
+	paymentService := coreServices.Payments()
+	if paymentService != nil {
+		settings := paymentService.Settings()
+		checkoutProvider = paymentService.Provider()
+		stripeWebhook = settings.WebhookSecret
+		checkoutConfig.Currency = settings.Currency
 	} else {
-		logger.Debug("Configuration unavailable; course checkout remains disabled", map[string]interface{}{"feature": "courses"})
+		logger.Debug("Payment service unavailable; course checkout remains disabled", map[string]interface{}{"feature": "courses"})
 	}
 
 	if setupService := coreServices.Setup(); setupService != nil {
 		defaults := models.SiteSettings{
-			StripeSecretKey:          stripeSecret,
-			StripePublishableKey:     stripePublish,
-			StripeWebhookSecret:      stripeWebhook,
 			CourseCheckoutSuccessURL: checkoutConfig.SuccessURL,
 			CourseCheckoutCancelURL:  checkoutConfig.CancelURL,
-			CourseCheckoutCurrency:   checkoutConfig.Currency,
 		}
 		if settings, err := setupService.GetSiteSettings(defaults); err != nil {
 			logger.Error(err, "Failed to load site settings for checkout", map[string]interface{}{"feature": "courses"})
 		} else {
-			if key := strings.TrimSpace(settings.StripeSecretKey); key != "" {
-				stripeSecret = key
-			}
-			if key := strings.TrimSpace(settings.StripePublishableKey); key != "" {
-				stripePublish = key
-			}
-			if key := strings.TrimSpace(settings.StripeWebhookSecret); key != "" {
-				stripeWebhook = key
-			}
 			if url := strings.TrimSpace(settings.CourseCheckoutSuccessURL); url != "" {
 				checkoutConfig.SuccessURL = url
 			}
 			if url := strings.TrimSpace(settings.CourseCheckoutCancelURL); url != "" {
 				checkoutConfig.CancelURL = url
 			}
-			if currency := strings.TrimSpace(settings.CourseCheckoutCurrency); currency != "" {
-				checkoutConfig.Currency = strings.ToLower(currency)
-			}
 		}
 	}
 
@@ -180,30 +195,6 @@ func (f *Feature) Activate() error {
 		materialProtect.SetTokenTTL(time.Duration(cfg.CourseAssetTokenTTLMinutes) * time.Minute)
 	}
 
-	if stripeSecret != "" && !stripe.IsSecretKey(stripeSecret) {
-		logger.Warn("Invalid Stripe secret key provided; course checkout disabled", map[string]interface{}{"feature": "courses"})
-		stripeSecret = ""
-	}
-	if stripePublish != "" && !stripe.IsPublishableKey(stripePublish) {
-		logger.Warn("Invalid Stripe publishable key provided; ignoring value", map[string]interface{}{"feature": "courses"})
-		stripePublish = ""
-	}
-	if stripeWebhook != "" && !stripe.IsWebhookSecret(stripeWebhook) {
-		logger.Warn("Invalid Stripe webhook secret provided; ignoring value", map[string]interface{}{"feature": "courses"})
-		stripeWebhook = ""
-	}
-
-	if stripeSecret != "" {
-		provider, err := stripe.NewProvider(stripeSecret)
-		if err != nil {
-			logger.Error(err, "Failed to initialise Stripe provider", map[string]interface{}{"feature": "courses"})
-		} else {
-			checkoutProvider = provider
-		}
-	} else {
-		logger.Debug("Stripe secret key not provided; course checkout remains disabled", map[string]interface{}{"feature": "courses"})
-	}
-
 	var checkoutService *courseservice.CheckoutService
 	if value, ok := services.Get(courseapi.ServiceCheckout).(*courseservice.CheckoutService); ok {
 		checkoutService = value
@@ -215,6 +206,13 @@ func (f *Feature) Activate() error {
 		checkoutService.SetDependencies(packageRepo, checkoutProvider)
 		checkoutService.SetConfig(checkoutConfig)
 	}
+	if pluginService := coreServices.Plugins(); pluginService != nil {
+		checkoutService.SetSettingsSource(func() (map[string]interface{}, error) {
+			return pluginService.Settings(courseapi.Namespace)
+		})
+	} else {
+		checkoutService.SetSettingsSource(nil)
+	}
 
 	if handler, ok := handlers.Get(courseapi.HandlerVideo).(*coursehandlers.VideoHandler); handler == nil || !ok {
 		handlers.Set(courseapi.HandlerVideo, coursehandlers.NewVideoHandler(videoService))
@@ -249,15 +247,22 @@ func (f *Feature) Activate() error {
 		handler.SetMaterialProtection(materialProtect)
 	}
 
+	var receiptMailer courseservice.ReceiptMailer
+	if email := coreServices.Email(); email != nil {
+		receiptMailer = email
+	}
+
 	if handler, ok := handlers.Get(courseapi.HandlerCheckout).(*coursehandlers.CheckoutHandler); handler == nil || !ok {
 		handler = coursehandlers.NewCheckoutHandler(checkoutService)
 		handler.SetPackageService(packageService)
 		handler.SetWebhookSecret(stripeWebhook)
+		handler.SetReceiptMailer(receiptMailer)
 		handlers.Set(courseapi.HandlerCheckout, handler)
 	} else {
 		handler.SetService(checkoutService)
 		handler.SetPackageService(packageService)
 		handler.SetWebhookSecret(stripeWebhook)
+		handler.SetReceiptMailer(receiptMailer)
 	}
 
 	if handler, ok := handlers.Get(courseapi.HandlerAsset).(*coursehandlers.AssetHandler); handler == nil || !ok {
@@ -266,17 +271,63 @@ func (f *Feature) Activate() error {
 		handler.SetDependencies(packageService, materialProtect, uploadDir)
 	}
 
+	if paymentService != nil {
+		paymentService.Fulfillments().Register(courseservice.CheckoutKind, func(ctx context.Context, session *payments.SessionDetails) error {
+			packageID, userID := courseservice.PurchaseFromSession(session)
+			if packageID == 0 || userID == 0 {
+				return fmt.Errorf("checkout session %s is missing course identifiers", session.ID)
+			}
+			if _, err := packageService.GrantToUser(packageID, models.GrantCoursePackageRequest{UserID: userID}, 0); err != nil {
+				return err
+			}
+			if err := packageService.SendPurchaseReceipt(receiptMailer, session, checkoutService.Config().Currency); err != nil {
+				logger.Warn("Failed to send course receipt", map[string]interface{}{"session_id": session.ID, "error": err.Error()})
+			}
+			return nil
+		})
+	}
+
 	if templateHandler := f.host.TemplateHandler(); templateHandler != nil {
 		templateHandler.SetCoursePackageService(packageService)
 		templateHandler.SetCourseCheckoutService(checkoutService)
 		templateHandler.SetCourseMaterialProtection(materialProtect)
 	}
 
+	if seoHandler := f.host.SEOHandler(); seoHandler != nil {
+		seoHandler.SetCoursePackageService(packageService)
+	}
+
 	if authHandler := f.host.AuthHandler(); authHandler != nil {
 		authHandler.SetCoursePackageService(packageService)
 		authHandler.SetCourseMaterialProtection(materialProtect)
 	}
 
+	if digest := coreServices.Digest(); digest != nil {
+		digest.SetSource("courses", packageService)
+	}
+
+	if scheduler := f.host.Scheduler(); scheduler != nil {
+		mailer := coreServices.Email()
+		err := scheduler.ScheduleRecurring(background.RecurringJob{
+			Name:     expiryReminderJob,
+			Schedule: expiryReminderSchedule,
+			Timeout:  10 * time.Minute,
+			Run: func(ctx context.Context) error {
+				if mailer == nil {
+					return nil
+				}
+				sent, err := packageService.SendExpiryReminders(ctx, mailer, time.Now().UTC(), expiryReminderLead, 24*time.Hour)
+				if sent > 0 {
+					logger.Info("Sent course access expiry reminders", map[string]interface{}{"count": sent})
+				}
+				return err
+			},
+		})
+		if err != nil {
+			logger.Error(err, "Failed to schedule course access expiry reminders", nil)
+		}
+	}
+
 	return nil
 }
 
@@ -285,6 +336,17 @@ func (f *Feature) Deactivate() error {
 		return nil
 	}
 
+	if scheduler := f.host.Scheduler(); scheduler != nil {
+		scheduler.Unschedule(expiryReminderJob)
+	}
+	if f.stopVideoUpdates != nil {
+		f.stopVideoUpdates()
+		f.stopVideoUpdates = nil
+	}
+	if paymentService := f.host.CoreServices().Payments(); paymentService != nil {
+		paymentService.Fulfillments().Unregister(courseservice.CheckoutKind)
+	}
+
 	handlers := f.host.Handlers(courseapi.Namespace)
 	if handler, _ := handlers.Get(courseapi.HandlerVideo).(*coursehandlers.VideoHandler); handler != nil {
 		handler.SetService(nil)
@@ -303,6 +365,7 @@ func (f *Feature) Deactivate() error {
 		handler.SetService(nil)
 		handler.SetPackageService(nil)
 		handler.SetWebhookSecret("")
+		handler.SetReceiptMailer(nil)
 	}
 	if handler, _ := handlers.Get(courseapi.HandlerAsset).(*coursehandlers.AssetHandler); handler != nil {
 		handler.SetDependencies(nil, nil, "")
@@ -321,10 +384,18 @@ func (f *Feature) Deactivate() error {
 		templateHandler.SetCourseMaterialProtection(nil)
 	}
 
+	if seoHandler := f.host.SEOHandler(); seoHandler != nil {
+		seoHandler.SetCoursePackageService(nil)
+	}
+
 	if authHandler := f.host.AuthHandler(); authHandler != nil {
 		authHandler.SetCoursePackageService(nil)
 		authHandler.SetCourseMaterialProtection(nil)
 	}
 
+	if digest := f.host.CoreServices().Digest(); digest != nil {
+		digest.SetSource("courses", nil)
+	}
+
 	return nil
 }
@@ -0,0 +1,315 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/repository"
+	"constructor-script-backend/internal/service"
+	"constructor-script-backend/pkg/logger"
+)
+
+const (
+	// announcementSummaryLength is how much of an announcement a digest or
+	// notification quotes.
+	announcementSummaryLength = 280
+	// playerAnnouncementLimit caps the announcements in the course player.
+	playerAnnouncementLimit      = 20
+	announcementBroadcastTimeout = 30 * time.Minute
+)
+
+// AnnouncementNotifier delivers in-app notifications.
+type AnnouncementNotifier interface {
+	Send(userIDs []uint, notification models.Notification) error
+}
+
+// AnnouncementMailer emails announcements, once per learner.
+type AnnouncementMailer interface {
+	Enabled() bool
+	SendTemplateOnce(key, to, name, fallbackSubject string, data map[string]interface{}) error
+}
+
+// NotificationPreferences tells which learners want a notification on a
+// channel.
+type NotificationPreferences interface {
+	AllowedUsers(userIDs []uint, event, channel string) ([]uint, error)
+}
+
+// SetAnnouncementRepository configures where package announcements are kept.
+func (s *PackageService) SetAnnouncementRepository(repo repository.CourseAnnouncementRepository) {
+	if s == nil {
+		return
+	}
+	s.announcementRepo = repo
+}
+
+// SetAnnouncementDelivery configures how new announcements reach learners.
+// Either may be nil.
+func (s *PackageService) SetAnnouncementDelivery(notifier AnnouncementNotifier, mailer AnnouncementMailer) {
+	if s == nil {
+		return
+	}
+	s.announcementNotifier = notifier
+	s.announcementMailer = mailer
+}
+
+// SetNotificationPreferences configures which learners want course emails.
+func (s *PackageService) SetNotificationPreferences(preferences NotificationPreferences) {
+	if s == nil {
+		return
+	}
+	s.preferences = preferences
+}
+
+// allowedUsers returns the set of users who want event notifications on the
+// channel. Without preferences configured everyone does.
+func (s *PackageService) allowedUsers(userIDs []uint, event, channel string) (map[uint]bool, error) {
+	allowed := userIDs
+	if s.preferences != nil {
+		var err error
+		if allowed, err = s.preferences.AllowedUsers(userIDs, event, channel); err != nil {
+			return nil, fmt.Errorf("failed to load notification preferences: %w", err)
+		}
+	}
+	set := make(map[uint]bool, len(allowed))
+	for _, userID := range allowed {
+		set[userID] = true
+	}
+	return set, nil
+}
+
+// ListAnnouncements returns the announcements of a package, newest first.
+func (s *PackageService) ListAnnouncements(packageID uint) ([]models.CourseAnnouncement, error) {
+	if s == nil || s.packageRepo == nil || s.announcementRepo == nil {
+		return nil, errors.New("course announcements are not configured")
+	}
+
+	exists, err := s.packageRepo.Exists(packageID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return s.announcementRepo.ListByPackage(packageID)
+}
+
+// CreateAnnouncement posts an announcement to everyone with access to the
+// package. Learners are notified in the background and by email when
+// req.SendEmail is set.
+func (s *PackageService) CreateAnnouncement(packageID, authorID uint, req models.CreateCourseAnnouncementRequest) (*models.CourseAnnouncement, error) {
+	if s == nil || s.packageRepo == nil || s.announcementRepo == nil {
+		return nil, errors.New("course announcements are not configured")
+	}
+
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		return nil, newValidationError("announcement title is required")
+	}
+
+	exists, err := s.packageRepo.Exists(packageID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	announcement := &models.CourseAnnouncement{
+		PackageID: packageID,
+		AuthorID:  authorID,
+		Title:     title,
+		Body:      strings.TrimSpace(req.Body),
+		Emailed:   req.SendEmail,
+	}
+	if err := s.announcementRepo.Create(announcement); err != nil {
+		return nil, err
+	}
+
+	go func(announcement models.CourseAnnouncement) {
+		ctx, cancel := context.WithTimeout(context.Background(), announcementBroadcastTimeout)
+		defer cancel()
+		if _, err := s.BroadcastAnnouncement(ctx, announcement); err != nil {
+			logger.Error(err, "Failed to deliver course announcement", map[string]interface{}{
+				"announcement_id": announcement.ID,
+				"package_id":      announcement.PackageID,
+			})
+		}
+	}(*announcement)
+
+	return announcement, nil
+}
+
+// BroadcastAnnouncement notifies everyone with current access to the package of
+// the announcement, and emails them when the announcement is to be emailed. It
+// returns how many learners were reached.
+func (s *PackageService) BroadcastAnnouncement(ctx context.Context, announcement models.CourseAnnouncement) (int, error) {
+	if s == nil || s.packageRepo == nil || s.accessRepo == nil {
+		return 0, errors.New("course package service is not configured")
+	}
+	mailer := s.announcementMailer
+	if !announcement.Emailed || mailer == nil || !mailer.Enabled() {
+		mailer = nil
+	}
+	if s.announcementNotifier == nil && mailer == nil {
+		return 0, nil
+	}
+
+	pkg, err := s.packageRepo.GetByID(announcement.PackageID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load course package: %w", err)
+	}
+	coursePath := "/courses/" + pkg.Slug
+	notification := models.Notification{
+		Kind:  models.NotificationCourseAnnouncement,
+		Title: pkg.Title + ": " + announcement.Title,
+		Body:  truncateRunes(announcement.Body, announcementSummaryLength),
+		Path:  coursePath,
+	}
+
+	now := time.Now()
+	reached := 0
+	err = s.accessRepo.BatchesByPackage(announcement.PackageID, func(grants []models.CoursePackageGrant) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		userIDs := make([]uint, 0, len(grants))
+		active := make([]models.CoursePackageGrant, 0, len(grants))
+		for _, grant := range grants {
+			if grant.ExpiresAt != nil && !grant.ExpiresAt.After(now) {
+				continue
+			}
+			userIDs = append(userIDs, grant.UserID)
+			active = append(active, grant)
+		}
+		reached += len(active)
+
+		if s.announcementNotifier != nil {
+			if err := s.announcementNotifier.Send(userIDs, notification); err != nil {
+				return fmt.Errorf("failed to notify learners: %w", err)
+			}
+		}
+		if mailer == nil {
+			return nil
+		}
+		emailable, err := s.allowedUsers(userIDs, models.NotificationCourseAnnouncement, models.NotificationChannelEmail)
+		if err != nil {
+			return err
+		}
+		for _, grant := range active {
+			if strings.TrimSpace(grant.Email) == "" || !emailable[grant.UserID] {
+				continue
+			}
+			data := map[string]interface{}{
+				"Username":    grant.Username,
+				"CourseTitle": pkg.Title,
+				"CoursePath":  coursePath,
+				"Title":       announcement.Title,
+				"Body":        announcement.Body,
+			}
+			key := fmt.Sprintf("course-announcement:%d:%d", announcement.ID, grant.UserID)
+			if err := mailer.SendTemplateOnce(key, grant.Email, service.EmailTemplateCourseAnnouncement, notification.Title, data); err != nil {
+				logger.Warn("Failed to email course announcement", map[string]interface{}{
+					"announcement_id": announcement.ID,
+					"user_id":         grant.UserID,
+					"error":           err.Error(),
+				})
+			}
+		}
+		return nil
+	})
+	return reached, err
+}
+
+// playerAnnouncements returns the newest announcements of a package for the
+// course player. Without announcements configured the list is empty.
+func (s *PackageService) playerAnnouncements(packageID uint) ([]models.CourseAnnouncement, error) {
+	if s.announcementRepo == nil {
+		return []models.CourseAnnouncement{}, nil
+	}
+	announcements, err := s.announcementRepo.ListByPackage(packageID)
+	if err != nil {
+		return nil, err
+	}
+	if len(announcements) > playerAnnouncementLimit {
+		announcements = announcements[:playerAnnouncementLimit]
+	}
+	if announcements == nil {
+		announcements = []models.CourseAnnouncement{}
+	}
+	return announcements, nil
+}
+
+// DeleteAnnouncement removes an announcement. Digests already sent keep it.
+func (s *PackageService) DeleteAnnouncement(id uint) error {
+	if s == nil || s.announcementRepo == nil {
+		return errors.New("course announcements are not configured")
+	}
+	return s.announcementRepo.Delete(id)
+}
+
+// DigestSection lists the announcements posted between since and until to the
+// packages the user has access to.
+func (s *PackageService) DigestSection(ctx context.Context, userID uint, since, until time.Time, limit int) (models.DigestSection, error) {
+	section := models.DigestSection{Title: "Course announcements"}
+	if s == nil || s.packageRepo == nil || s.announcementRepo == nil {
+		return section, errors.New("course announcements are not configured")
+	}
+	if err := ctx.Err(); err != nil {
+		return section, err
+	}
+
+	announcements, err := s.announcementRepo.ListForUser(userID, since, until, limit)
+	if err != nil {
+		return section, fmt.Errorf("failed to load course announcements: %w", err)
+	}
+	if len(announcements) == 0 {
+		return section, nil
+	}
+
+	packageIDs := make([]uint, 0, len(announcements))
+	for _, announcement := range announcements {
+		packageIDs = append(packageIDs, announcement.PackageID)
+	}
+	packages, err := s.packageRepo.GetByIDs(uniqueOrdered(packageIDs))
+	if err != nil {
+		return section, fmt.Errorf("failed to load course packages for announcements: %w", err)
+	}
+	packagesByID := make(map[uint]models.CoursePackage, len(packages))
+	for _, pkg := range packages {
+		packagesByID[pkg.ID] = pkg
+	}
+
+	for _, announcement := range announcements {
+		pkg, ok := packagesByID[announcement.PackageID]
+		if !ok {
+			continue
+		}
+		summary := pkg.Title
+		if body := truncateRunes(announcement.Body, announcementSummaryLength); body != "" {
+			summary += ": " + body
+		}
+		section.Items = append(section.Items, models.DigestItem{
+			Title:   announcement.Title,
+			Path:    "/courses/" + pkg.Slug,
+			Summary: summary,
+		})
+	}
+	return section, nil
+}
+
+func truncateRunes(value string, limit int) string {
+	value = strings.Join(strings.Fields(value), " ")
+	runes := []rune(value)
+	if len(runes) <= limit {
+		return value
+	}
+	return strings.TrimSpace(string(runes[:limit])) + "…"
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"constructor-script-backend/internal/models"
+)
+
+type recordingNotifier struct {
+	userIDs      []uint
+	notification models.Notification
+}
+
+func (n *recordingNotifier) Send(userIDs []uint, notification models.Notification) error {
+	n.userIDs = append(n.userIDs, userIDs...)
+	n.notification = notification
+	return nil
+}
+
+type recordingMailer struct {
+	keys []string
+	to   []string
+}
+
+func (m *recordingMailer) Enabled() bool { return true }
+
+func (m *recordingMailer) SendTemplateOnce(key, to, name, fallbackSubject string, data map[string]interface{}) error {
+	m.keys = append(m.keys, key)
+	m.to = append(m.to, to)
+	return nil
+}
+
+type optOutPreferences map[uint]bool
+
+func (p optOutPreferences) AllowedUsers(userIDs []uint, event, channel string) ([]uint, error) {
+	allowed := make([]uint, 0, len(userIDs))
+	for _, userID := range userIDs {
+		if !p[userID] {
+			allowed = append(allowed, userID)
+		}
+	}
+	return allowed, nil
+}
+
+func TestBroadcastAnnouncementSkipsExpiredAccess(t *testing.T) {
+	pkg := &models.CoursePackage{ID: 7, Title: "Advanced Go", Slug: "advanced-go"}
+	expired := time.Now().Add(-time.Hour)
+	notifier := &recordingNotifier{}
+	mailer := &recordingMailer{}
+
+	svc := &PackageService{
+		packageRepo: &mockPackageRepo{pkg: pkg},
+		accessRepo: &mockAccessRepo{
+			list: []models.CoursePackageAccess{
+				{UserID: 3, PackageID: 7},
+				{UserID: 4, PackageID: 7, ExpiresAt: &expired},
+				{UserID: 5, PackageID: 7},
+				{UserID: 6, PackageID: 8},
+			},
+			emails: map[uint]string{3: "ann@example.com", 4: "bob@example.com"},
+		},
+	}
+	svc.SetAnnouncementDelivery(notifier, mailer)
+
+	announcement := models.CourseAnnouncement{ID: 11, PackageID: 7, Title: "Live session moved", Body: "Now on Friday."}
+	reached, err := svc.BroadcastAnnouncement(context.Background(), announcement)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if reached != 2 || len(notifier.userIDs) != 2 || notifier.userIDs[0] != 3 || notifier.userIDs[1] != 5 {
+		t.Fatalf("expected learners 3 and 5 to be notified, got %v (reached %d)", notifier.userIDs, reached)
+	}
+	if notifier.notification.Title != "Advanced Go: Live session moved" || notifier.notification.Path != "/courses/advanced-go" {
+		t.Fatalf("unexpected notification %+v", notifier.notification)
+	}
+	if len(mailer.to) != 0 {
+		t.Fatalf("expected no emails without send_email, got %v", mailer.to)
+	}
+
+	announcement.Emailed = true
+	if _, err := svc.BroadcastAnnouncement(context.Background(), announcement); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(mailer.to) != 1 || mailer.to[0] != "ann@example.com" || mailer.keys[0] != "course-announcement:11:3" {
+		t.Fatalf("expected one email to the active learner, got %v %v", mailer.to, mailer.keys)
+	}
+
+	svc.SetNotificationPreferences(optOutPreferences{3: true})
+	mailer.to, mailer.keys = nil, nil
+	if _, err := svc.BroadcastAnnouncement(context.Background(), announcement); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(mailer.to) != 0 {
+		t.Fatalf("expected no email to a learner who opted out, got %v", mailer.to)
+	}
+}
@@ -7,27 +7,24 @@ import (
 	"strconv"
 	"strings"
 
-	"gorm.io/gorm"
-
 	"constructor-script-backend/internal/models"
 	"constructor-script-backend/internal/payments"
 	"constructor-script-backend/internal/repository"
 	"constructor-script-backend/pkg/logger"
 )
 
+// CheckoutKind identifies course package purchases in checkout session metadata.
+const CheckoutKind = "course_package"
+
 var (
 	// ErrCheckoutDisabled is returned when the checkout flow is not configured.
-	ErrCheckoutDisabled = errors.New("course checkout is disabled")
+	ErrCheckoutDisabled = payments.ErrCheckoutDisabled
 	// ErrInvalidPackagePrice indicates that the course price cannot be processed by Stripe.
 	ErrInvalidPackagePrice = errors.New("course package price must be greater than zero")
 )
 
 // CheckoutConfig defines configuration required to create checkout sessions.
-type CheckoutConfig struct {
-	SuccessURL string
-	CancelURL  string
-	Currency   string
-}
+type CheckoutConfig = payments.CheckoutConfig
 
 // CheckoutSession wraps the information returned by the payment provider.
 type CheckoutSession struct {
@@ -35,18 +32,21 @@ type CheckoutSession struct {
 	URL string
 }
 
-// CheckoutService coordinates checkout session creation for course packages.
+// SettingsSource returns the current values of the courses plugin settings.
+type SettingsSource func() (map[string]interface{}, error)
+
+// CheckoutService coordinates checkout session creation for course packages on top of
+// the shared payments checkout.
 type CheckoutService struct {
 	packageRepo repository.CoursePackageRepository
-	provider    payments.Provider
-	config      CheckoutConfig
+	checkout    *payments.Checkout
+	settings    SettingsSource
 }
 
 // NewCheckoutService constructs a checkout service instance.
 func NewCheckoutService(repo repository.CoursePackageRepository, provider payments.Provider, cfg CheckoutConfig) *CheckoutService {
-	service := &CheckoutService{}
-	service.SetConfig(cfg)
-	service.SetDependencies(repo, provider)
+	service := &CheckoutService{checkout: payments.NewCheckout(provider, cfg)}
+	service.packageRepo = repo
 	return service
 }
 
@@ -56,7 +56,7 @@ func (s *CheckoutService) SetDependencies(repo repository.CoursePackageRepositor
 		return
 	}
 	s.packageRepo = repo
-	s.provider = provider
+	s.checkout.SetProvider(provider)
 }
 
 // SetConfig updates the checkout configuration used by the service.
@@ -64,7 +64,17 @@ func (s *CheckoutService) SetConfig(cfg CheckoutConfig) {
 	if s == nil {
 		return
 	}
-	s.config = normalizeCheckoutConfig(cfg)
+	s.checkout.SetConfig(cfg)
+}
+
+// SetSettingsSource configures where the plugin settings overriding the checkout
+// redirects and currency are read from. They are read for every checkout, so
+// changes apply without reactivating the plugin.
+func (s *CheckoutService) SetSettingsSource(source SettingsSource) {
+	if s == nil {
+		return
+	}
+	s.settings = source
 }
 
 // Enabled reports whether the checkout flow is ready for use.
@@ -72,21 +82,48 @@ func (s *CheckoutService) Enabled() bool {
 	if s == nil {
 		return false
 	}
-	cfg := s.config
-	return s.packageRepo != nil && s.provider != nil && cfg.SuccessURL != "" && cfg.CancelURL != "" && cfg.Currency != ""
+	cfg := s.Config()
+	return s.packageRepo != nil && s.checkout.Enabled() && cfg.SuccessURL != "" && cfg.CancelURL != ""
 }
 
-// Config returns a copy of the current checkout configuration.
+// Config returns a copy of the current checkout configuration with the plugin
+// settings applied.
 func (s *CheckoutService) Config() CheckoutConfig {
 	if s == nil {
 		return CheckoutConfig{}
 	}
-	return s.config
+	cfg := s.checkout.Config()
+	if s.settings == nil {
+		return cfg
+	}
+	values, err := s.settings()
+	if err != nil {
+		logger.Error(err, "Failed to load plugin settings for checkout", map[string]interface{}{"feature": "courses"})
+		return cfg
+	}
+	return applyCheckoutSettings(cfg, values)
+}
+
+func applyCheckoutSettings(cfg CheckoutConfig, values map[string]interface{}) CheckoutConfig {
+	if url, ok := values["checkout_success_url"].(string); ok && strings.TrimSpace(url) != "" {
+		cfg.SuccessURL = strings.TrimSpace(url)
+	}
+	if url, ok := values["checkout_cancel_url"].(string); ok && strings.TrimSpace(url) != "" {
+		cfg.CancelURL = strings.TrimSpace(url)
+	}
+	if currency, ok := values["checkout_currency"].(string); ok && strings.TrimSpace(currency) != "" {
+		cfg.Currency = strings.ToLower(strings.TrimSpace(currency))
+	}
+	return cfg
 }
 
 // CreateCheckoutSession generates a checkout session for the requested course package.
 func (s *CheckoutService) CreateCheckoutSession(ctx context.Context, req models.CourseCheckoutRequest) (*CheckoutSession, error) {
-	if s == nil || !s.Enabled() {
+	if s == nil {
+		return nil, ErrCheckoutDisabled
+	}
+	cfg := s.Config()
+	if s.packageRepo == nil || !s.checkout.Enabled() || cfg.SuccessURL == "" || cfg.CancelURL == "" {
 		return nil, ErrCheckoutDisabled
 	}
 
@@ -116,41 +153,30 @@ func (s *CheckoutService) CreateCheckoutSession(ctx context.Context, req models.
 		return nil, ErrInvalidPackagePrice
 	}
 
-	currency := s.config.Currency
-	if currency == "" {
-		return nil, ErrCheckoutDisabled
-	}
-
-	params := payments.CheckoutParams{
-		Mode:       payments.ModePayment,
-		SuccessURL: ensureSessionIDPlaceholder(s.config.SuccessURL),
-		CancelURL:  s.config.CancelURL,
+	session, err := s.checkout.CreateSession(ctx, payments.Order{
+		CustomerEmail: req.CustomerEmail,
+		SuccessURL:    cfg.SuccessURL,
+		CancelURL:     cfg.CancelURL,
 		Metadata: map[string]string{
+			payments.MetadataKind:  CheckoutKind,
 			"course_package_id":    strconv.FormatUint(uint64(pkg.ID), 10),
 			"course_package_title": pkg.Title,
 			"user_id":              strconv.FormatUint(uint64(req.UserID), 10),
 		},
-		LineItems: []payments.LineItem{
+		Items: []payments.LineItem{
 			{
 				Name:        pkg.Title,
-				Description: truncateDescription(pkg.Description),
+				Description: pkg.Description,
 				AmountCents: priceCents,
+				Currency:    cfg.Currency,
 				Quantity:    1,
-				Currency:    currency,
 			},
 		},
-	}
-
-	if email := strings.TrimSpace(req.CustomerEmail); email != "" {
-		params.CustomerEmail = email
-	}
-
-	if ctx == nil {
-		ctx = context.Background()
-	}
-
-	session, err := s.provider.CreateCheckoutSession(ctx, params)
+	})
 	if err != nil {
+		if errors.Is(err, payments.ErrInvalidAmount) {
+			return nil, ErrInvalidPackagePrice
+		}
 		logger.Error(err, "Failed to create checkout session with provider", map[string]interface{}{
 			"package_id": req.PackageID,
 			"user_id":    req.UserID,
@@ -169,49 +195,30 @@ func (s *CheckoutService) CreateCheckoutSession(ctx context.Context, req models.
 
 // RetrieveSession fetches an existing checkout session from the payment provider.
 func (s *CheckoutService) RetrieveSession(ctx context.Context, sessionID string) (*payments.SessionDetails, error) {
-	if s == nil || s.provider == nil {
+	if s == nil {
 		return nil, ErrCheckoutDisabled
 	}
-	if ctx == nil {
-		ctx = context.Background()
-	}
-	return s.provider.GetCheckoutSession(ctx, sessionID)
-}
-
-func normalizeCheckoutConfig(cfg CheckoutConfig) CheckoutConfig {
-	return CheckoutConfig{
-		SuccessURL: strings.TrimSpace(cfg.SuccessURL),
-		CancelURL:  strings.TrimSpace(cfg.CancelURL),
-		Currency:   strings.ToLower(strings.TrimSpace(cfg.Currency)),
-	}
+	return s.checkout.RetrieveSession(ctx, sessionID)
 }
 
-func truncateDescription(value string) string {
-	trimmed := strings.TrimSpace(value)
-	if trimmed == "" {
-		return ""
+// PurchaseFromSession extracts the course package and buyer from checkout session
+// metadata. Zero values mean the session does not describe a course purchase.
+func PurchaseFromSession(session *payments.SessionDetails) (packageID, userID uint) {
+	if session == nil {
+		return 0, 0
 	}
-	if len([]rune(trimmed)) <= 500 {
-		return trimmed
+	metadata := session.Metadata
+	packageID = parseMetadataID(metadata["course_package_id"])
+	if packageID == 0 {
+		packageID = parseMetadataID(metadata["package_id"])
 	}
-	runes := []rune(trimmed)
-	return string(runes[:500])
+	return packageID, parseMetadataID(metadata["user_id"])
 }
 
-func ensureSessionIDPlaceholder(successURL string) string {
-	url := strings.TrimSpace(successURL)
-	if url == "" {
-		return url
-	}
-	if strings.Contains(url, "{CHECKOUT_SESSION_ID}") {
-		return url
-	}
-	separator := "?"
-	if strings.Contains(url, "?") {
-		separator = "&"
+func parseMetadataID(value string) uint {
+	id, err := strconv.ParseUint(strings.TrimSpace(value), 10, 64)
+	if err != nil {
+		return 0
 	}
-	return url + separator + "session_id={CHECKOUT_SESSION_ID}"
+	return uint(id)
 }
-
-// Ensure the service satisfies gorm.ErrRecordNotFound propagation expectations.
-var _ = gorm.ErrRecordNotFound
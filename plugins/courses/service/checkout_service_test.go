@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/payments"
+)
+
+type recordingPaymentProvider struct {
+	params []payments.CheckoutParams
+}
+
+func (p *recordingPaymentProvider) CreateCheckoutSession(_ context.Context, params payments.CheckoutParams) (*payments.Session, error) {
+	p.params = append(p.params, params)
+	return &payments.Session{ID: "cs_test", URL: "https://pay.example/cs_test"}, nil
+}
+
+func (p *recordingPaymentProvider) GetCheckoutSession(context.Context, string) (*payments.SessionDetails, error) {
+	return nil, nil
+}
+
+func TestCheckoutUsesUpdatedPluginSettings(t *testing.T) {
+	repo := &mockPackageRepo{pkg: &models.CoursePackage{ID: 1, Title: "Go", PriceCents: 1000}}
+	provider := &recordingPaymentProvider{}
+	service := NewCheckoutService(repo, provider, CheckoutConfig{
+		SuccessURL: "https://example.com/courses/success",
+		CancelURL:  "https://example.com/courses/cancel",
+		Currency:   "usd",
+	})
+
+	settings := map[string]interface{}{}
+	service.SetSettingsSource(func() (map[string]interface{}, error) {
+		return settings, nil
+	})
+
+	request := models.CourseCheckoutRequest{PackageID: 1, UserID: 7}
+	if _, err := service.CreateCheckoutSession(context.Background(), request); err != nil {
+		t.Fatalf("create checkout session: %v", err)
+	}
+
+	// An admin saves new settings while the plugin stays active.
+	settings["checkout_success_url"] = "https://example.com/thanks"
+	settings["checkout_currency"] = "EUR"
+
+	if _, err := service.CreateCheckoutSession(context.Background(), request); err != nil {
+		t.Fatalf("create checkout session: %v", err)
+	}
+
+	if len(provider.params) != 2 {
+		t.Fatalf("expected two checkout sessions, got %d", len(provider.params))
+	}
+	before, after := provider.params[0], provider.params[1]
+	if !strings.HasPrefix(before.SuccessURL, "https://example.com/courses/success") || before.LineItems[0].Currency != "usd" {
+		t.Fatalf("expected the configured checkout first, got %s in %s", before.SuccessURL, before.LineItems[0].Currency)
+	}
+	if !strings.HasPrefix(after.SuccessURL, "https://example.com/thanks") {
+		t.Fatalf("expected the updated success URL, got %s", after.SuccessURL)
+	}
+	if after.CancelURL != "https://example.com/courses/cancel" {
+		t.Fatalf("expected the configured cancel URL to remain, got %s", after.CancelURL)
+	}
+	if after.LineItems[0].Currency != "eur" {
+		t.Fatalf("expected the updated currency, got %s", after.LineItems[0].Currency)
+	}
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/service"
+	"constructor-script-backend/pkg/logger"
+)
+
+// ReminderMailer sends themed transactional emails.
+type ReminderMailer interface {
+	Enabled() bool
+	SendTemplate(to, name, fallbackSubject string, data map[string]interface{}) error
+}
+
+// SendExpiryReminders emails users whose package access expires between now+lead and
+// now+lead+window. Running it once per window reminds every user exactly once.
+func (s *PackageService) SendExpiryReminders(ctx context.Context, mailer ReminderMailer, now time.Time, lead, window time.Duration) (int, error) {
+	if s == nil || s.accessRepo == nil || s.packageRepo == nil || s.userRepo == nil {
+		return 0, errors.New("course package service is not configured")
+	}
+	if mailer == nil || !mailer.Enabled() {
+		return 0, nil
+	}
+
+	from := now.Add(lead)
+	accesses, err := s.accessRepo.ListExpiringBetween(from, from.Add(window))
+	if err != nil {
+		return 0, fmt.Errorf("failed to list expiring course access: %w", err)
+	}
+
+	// Load every user and package up front rather than two queries per reminder.
+	userIDs := make([]uint, 0, len(accesses))
+	packageIDs := make([]uint, 0, len(accesses))
+	for _, access := range accesses {
+		userIDs = append(userIDs, access.UserID)
+		packageIDs = append(packageIDs, access.PackageID)
+	}
+	userIDs = uniqueOrdered(userIDs)
+	emailable, err := s.allowedUsers(userIDs, models.NotificationCourseAccessExpiring, models.NotificationChannelEmail)
+	if err != nil {
+		return 0, err
+	}
+	users, err := s.userRepo.GetByIDs(userIDs)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load users for course access reminders: %w", err)
+	}
+	packages, err := s.packageRepo.GetByIDs(uniqueOrdered(packageIDs))
+	if err != nil {
+		return 0, fmt.Errorf("failed to load course packages for access reminders: %w", err)
+	}
+	usersByID := make(map[uint]models.User, len(users))
+	for _, user := range users {
+		usersByID[user.ID] = user
+	}
+	packagesByID := make(map[uint]models.CoursePackage, len(packages))
+	for _, pkg := range packages {
+		packagesByID[pkg.ID] = pkg
+	}
+
+	sent := 0
+	for _, access := range accesses {
+		if err := ctx.Err(); err != nil {
+			return sent, err
+		}
+
+		user, ok := usersByID[access.UserID]
+		if !ok || user.Email == "" || !emailable[user.ID] {
+			continue
+		}
+		pkg, ok := packagesByID[access.PackageID]
+		if !ok {
+			continue
+		}
+
+		data := map[string]interface{}{
+			"Username":    user.Username,
+			"CourseTitle": pkg.Title,
+			"CoursePath":  "/courses/" + pkg.Slug,
+			"ExpiresAt":   access.ExpiresAt.Format("January 2, 2006"),
+		}
+		if err := mailer.SendTemplate(user.Email, service.EmailTemplateCourseAccessExpiry, "Your course access expires soon", data); err != nil {
+			logger.Warn("Failed to send course access expiry reminder", map[string]interface{}{
+				"user_id":    user.ID,
+				"package_id": pkg.ID,
+				"error":      err.Error(),
+			})
+			continue
+		}
+		sent++
+	}
+
+	return sent, nil
+}
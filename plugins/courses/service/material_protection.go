@@ -15,6 +15,7 @@ import (
 const (
 	AssetTypeVideo      = "video"
 	AssetTypeAttachment = "attachment"
+	AssetTypePoster     = "poster"
 
 	defaultMaterialTokenTTL = 10 * time.Minute
 	courseAssetBasePath     = "/api/v1/courses/assets/"
@@ -89,6 +90,15 @@ func (p *MaterialProtection) protectVideoAssets(userID, packageID uint, video *m
 		}
 	}
 
+	// Poster frames are stored under the video's filename, so they are signed too.
+	if p.isManagedUpload(video.PosterURL) {
+		if signed, err := p.signPosterURL(userID, packageID, video.ID); err == nil && signed != "" {
+			video.PosterURL = signed
+		} else {
+			video.PosterURL = ""
+		}
+	}
+
 	// Avoid leaking the raw filename to clients.
 	video.Filename = ""
 
@@ -121,6 +131,24 @@ func (p *MaterialProtection) signVideoURL(userID, packageID, videoID uint) (stri
 	return courseAssetBasePath + url.PathEscape(token), nil
 }
 
+func (p *MaterialProtection) signPosterURL(userID, packageID, videoID uint) (string, error) {
+	if !p.Enabled() || userID == 0 || packageID == 0 || videoID == 0 {
+		return "", errors.New("material protection not configured")
+	}
+
+	token, err := p.buildToken(AssetTokenClaims{
+		UserID:    userID,
+		PackageID: packageID,
+		VideoID:   videoID,
+		Type:      AssetTypePoster,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return courseAssetBasePath + url.PathEscape(token), nil
+}
+
 func (p *MaterialProtection) signAttachmentURL(userID, packageID, videoID uint, attachmentIndex int) (string, error) {
 	if !p.Enabled() || userID == 0 || packageID == 0 || videoID == 0 || attachmentIndex < 0 {
 		return "", errors.New("material protection not configured")
@@ -195,6 +223,10 @@ func (p *MaterialProtection) isManagedUpload(raw string) bool {
 	if err != nil || parsed == nil {
 		return false
 	}
+	// Absolute URLs point at object storage or a CDN, not the upload directory.
+	if parsed.Host != "" {
+		return false
+	}
 
 	path := parsed.Path
 	if path == "" {
@@ -21,6 +21,11 @@ type PackageService struct {
 	contentRepo repository.CourseContentRepository
 	accessRepo  repository.CoursePackageAccessRepository
 	userRepo    repository.UserRepository
+
+	announcementRepo     repository.CourseAnnouncementRepository
+	announcementNotifier AnnouncementNotifier
+	announcementMailer   AnnouncementMailer
+	preferences          NotificationPreferences
 }
 
 func NewPackageService(
@@ -245,6 +250,14 @@ func (s *PackageService) List() ([]models.CoursePackage, error) {
 	return packages, nil
 }
 
+// ListForSitemap returns every package without loading its topics.
+func (s *PackageService) ListForSitemap() ([]models.CoursePackage, error) {
+	if s == nil || s.packageRepo == nil {
+		return nil, errors.New("course package repository is not configured")
+	}
+	return s.packageRepo.List()
+}
+
 func (s *PackageService) UpdateTopics(packageID uint, topicIDs []uint) (*models.CoursePackage, error) {
 	if s == nil || s.packageRepo == nil {
 		return nil, errors.New("course package repository is not configured")
@@ -265,6 +278,21 @@ func (s *PackageService) UpdateTopics(packageID uint, topicIDs []uint) (*models.
 	return s.GetByID(packageID)
 }
 
+// GrantBatches hands the access grants of a package to fn in batches, for admins.
+func (s *PackageService) GrantBatches(packageID uint, fn func([]models.CoursePackageGrant) error) error {
+	if s == nil || s.packageRepo == nil || s.accessRepo == nil {
+		return errors.New("course package service is not fully configured")
+	}
+	exists, err := s.packageRepo.Exists(packageID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return gorm.ErrRecordNotFound
+	}
+	return s.accessRepo.BatchesByPackage(packageID, fn)
+}
+
 func (s *PackageService) GrantToUser(packageID uint, req models.GrantCoursePackageRequest, grantedBy uint) (*models.CoursePackageAccess, error) {
 	if s == nil || s.packageRepo == nil || s.accessRepo == nil || s.userRepo == nil {
 		return nil, errors.New("course package service is not fully configured")
@@ -417,9 +445,15 @@ func (s *PackageService) buildUserCourse(pkg *models.CoursePackage, userID uint)
 		return nil, err
 	}
 
+	announcements, err := s.playerAnnouncements(pkg.ID)
+	if err != nil {
+		return nil, err
+	}
+
 	result := models.UserCoursePackage{
-		Package: *prepared,
-		Access:  *access,
+		Package:       *prepared,
+		Access:        *access,
+		Announcements: announcements,
 	}
 	return &result, nil
 }
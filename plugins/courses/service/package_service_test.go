@@ -119,6 +119,7 @@ type mockAccessRepo struct {
 	list      []models.CoursePackageAccess
 	listErr   error
 	accessMap map[[2]uint]*models.CoursePackageAccess
+	emails    map[uint]string
 }
 
 func (m *mockTopicRepo) Create(topic *models.CourseTopic) error { return nil }
@@ -186,6 +187,9 @@ func (m *mockVideoRepo) Exists(id uint) (bool, error) {
 	_, ok := m.videos[id]
 	return ok, nil
 }
+func (m *mockVideoRepo) ApplyMediaDetails(filename, posterURL string, durationSeconds int) error {
+	return nil
+}
 func (m *mockVideoRepo) GetByIDs(ids []uint) ([]models.CourseVideo, error) {
 	result := make([]models.CourseVideo, 0, len(ids))
 	for _, id := range ids {
@@ -282,6 +286,9 @@ func (m *mockTestRepo) SaveResult(result *models.CourseTestResult) error { retur
 func (m *mockTestRepo) GetBestResult(testID, userID uint) (*models.CourseTestResult, int64, error) {
 	return nil, 0, nil
 }
+func (m *mockTestRepo) ResultBatches(testID uint, fn func([]models.CourseTestResultEntry) error) error {
+	return nil
+}
 
 func (m *mockAccessRepo) Upsert(access *models.CoursePackageAccess) error { return nil }
 
@@ -314,6 +321,35 @@ func (m *mockAccessRepo) ListActiveByUser(userID uint) ([]models.CoursePackageAc
 	return result, nil
 }
 
+func (m *mockAccessRepo) BatchesByPackage(packageID uint, fn func([]models.CoursePackageGrant) error) error {
+	if m.listErr != nil {
+		return m.listErr
+	}
+	var grants []models.CoursePackageGrant
+	for _, access := range m.list {
+		if access.PackageID == packageID {
+			grants = append(grants, models.CoursePackageGrant{CoursePackageAccess: access, Email: m.emails[access.UserID]})
+		}
+	}
+	if len(grants) == 0 {
+		return nil
+	}
+	return fn(grants)
+}
+
+func (m *mockAccessRepo) ListExpiringBetween(from, to time.Time) ([]models.CoursePackageAccess, error) {
+	if m.listErr != nil {
+		return nil, m.listErr
+	}
+	result := make([]models.CoursePackageAccess, 0, len(m.list))
+	for _, access := range m.list {
+		if access.ExpiresAt != nil && !access.ExpiresAt.Before(from) && access.ExpiresAt.Before(to) {
+			result = append(result, access)
+		}
+	}
+	return result, nil
+}
+
 func TestPackageServiceGetForUser(t *testing.T) {
 	pkg := &models.CoursePackage{ID: 7, Title: "Advanced Go", Slug: "advanced-go", Description: "Deep dive"}
 	access := &models.CoursePackageAccess{UserID: 3, PackageID: 7, CreatedAt: time.Now()}
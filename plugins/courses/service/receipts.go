@@ -0,0 +1,71 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"constructor-script-backend/internal/payments"
+	"constructor-script-backend/internal/service"
+)
+
+// ReceiptMailer sends themed transactional emails once per key.
+type ReceiptMailer interface {
+	Enabled() bool
+	SendTemplateOnce(key, to, name, fallbackSubject string, data map[string]interface{}) error
+}
+
+// SendPurchaseReceipt emails the buyer of a paid course checkout session a
+// receipt in currency. Fulfilment is retried by design and may run on several
+// instances, so receipts are keyed by the session and sent once.
+func (s *PackageService) SendPurchaseReceipt(mailer ReceiptMailer, session *payments.SessionDetails, currency string) error {
+	if s == nil || s.packageRepo == nil || s.userRepo == nil {
+		return errors.New("course package service is not configured")
+	}
+	if mailer == nil || !mailer.Enabled() || session == nil {
+		return nil
+	}
+
+	packageID, userID := PurchaseFromSession(session)
+	if packageID == 0 || userID == 0 {
+		return fmt.Errorf("checkout session %s is missing course identifiers", session.ID)
+	}
+	pkg, err := s.packageRepo.GetByID(packageID)
+	if err != nil {
+		return fmt.Errorf("failed to load course package for receipt: %w", err)
+	}
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return fmt.Errorf("failed to load user for receipt: %w", err)
+	}
+
+	recipient := user.Email
+	if recipient == "" {
+		recipient = strings.TrimSpace(session.CustomerEmail)
+	}
+	if recipient == "" {
+		return nil
+	}
+
+	data := map[string]interface{}{
+		"Username":    user.Username,
+		"CourseTitle": pkg.Title,
+		"CoursePath":  "/courses/" + pkg.Slug,
+		"Amount":      formatReceiptAmount(pkg.EffectivePriceCents(), currency),
+		"PurchasedAt": time.Now().UTC().Format("January 2, 2006"),
+		"Reference":   session.ID,
+	}
+	return mailer.SendTemplateOnce("course_receipt:"+session.ID, recipient, service.EmailTemplateCourseReceipt, "Your course receipt", data)
+}
+
+func formatReceiptAmount(cents int64, currency string) string {
+	if cents <= 0 {
+		return ""
+	}
+	amount := fmt.Sprintf("%d.%02d", cents/100, cents%100)
+	if currency = strings.ToUpper(strings.TrimSpace(currency)); currency != "" {
+		amount += " " + currency
+	}
+	return amount
+}
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"strings"
 
+	"gorm.io/gorm"
+
 	"constructor-script-backend/internal/models"
 	"constructor-script-backend/internal/repository"
 )
@@ -136,6 +138,21 @@ func (s *TestService) Exists(id uint) (bool, error) {
 	return s.testRepo.Exists(id)
 }
 
+// ResultBatches hands every result of a test to fn in batches, for admins.
+func (s *TestService) ResultBatches(testID uint, fn func([]models.CourseTestResultEntry) error) error {
+	if s == nil || s.testRepo == nil {
+		return errors.New("course test repository is not configured")
+	}
+	exists, err := s.testRepo.Exists(testID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return gorm.ErrRecordNotFound
+	}
+	return s.testRepo.ResultBatches(testID, fn)
+}
+
 func (s *TestService) Submit(testID uint, userID uint, req models.SubmitCourseTestRequest) (*models.CourseTestSubmissionResult, error) {
 	if s == nil || s.testRepo == nil {
 		return nil, errors.New("course test repository is not configured")
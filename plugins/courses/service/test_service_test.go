@@ -41,6 +41,19 @@ func (m *mockCourseTestRepository) SaveResult(result *models.CourseTestResult) e
 	return nil
 }
 
+func (m *mockCourseTestRepository) ResultBatches(testID uint, fn func([]models.CourseTestResultEntry) error) error {
+	var results []models.CourseTestResultEntry
+	for _, result := range m.saved {
+		if result != nil && result.TestID == testID {
+			results = append(results, models.CourseTestResultEntry{CourseTestResult: *result})
+		}
+	}
+	if len(results) == 0 {
+		return nil
+	}
+	return fn(results)
+}
+
 func (m *mockCourseTestRepository) GetBestResult(testID, userID uint) (*models.CourseTestResult, int64, error) {
 	var attempts int64
 	var best *models.CourseTestResult
@@ -15,6 +15,7 @@ import (
 	"constructor-script-backend/internal/repository"
 	"constructor-script-backend/internal/service"
 	"constructor-script-backend/internal/theme"
+	"constructor-script-backend/pkg/logger"
 )
 
 type VideoService struct {
@@ -90,6 +91,9 @@ func (s *VideoService) Create(ctx context.Context, req models.CreateCourseVideoR
 	if seconds <= 0 && duration > 0 {
 		seconds = 1
 	}
+	if seconds == 0 {
+		seconds = result.Video.DurationSeconds
+	}
 
 	sections, err := service.PrepareSections(req.Sections, s.themes, service.PrepareSectionsOptions{NormaliseSpacing: true})
 	if err != nil {
@@ -115,6 +119,7 @@ func (s *VideoService) Create(ctx context.Context, req models.CreateCourseVideoR
 		FileURL:         url,
 		Filename:        filename,
 		DurationSeconds: seconds,
+		PosterURL:       result.Video.PosterURL,
 		Sections:        sections,
 		Attachments:     attachments,
 	}
@@ -124,9 +129,33 @@ func (s *VideoService) Create(ctx context.Context, req models.CreateCourseVideoR
 		return nil, err
 	}
 
+	// Processing runs in the background and may have finished before the row existed.
+	if video.PosterURL == "" {
+		if metadata, ok := s.uploadService.VideoMetadata(url); ok {
+			s.ApplyVideoMetadata(filename, metadata)
+			if metadata.PosterURL != "" {
+				video.PosterURL = metadata.PosterURL
+			}
+			if video.DurationSeconds == 0 {
+				video.DurationSeconds = metadata.DurationSeconds
+			}
+		}
+	}
+
 	return &video, nil
 }
 
+// ApplyVideoMetadata stores the poster frame and duration extracted from an uploaded
+// video on every course video that uses it. It is registered with the upload service.
+func (s *VideoService) ApplyVideoMetadata(filename string, metadata service.VideoMetadata) {
+	if s == nil || s.videoRepo == nil {
+		return
+	}
+	if err := s.videoRepo.ApplyMediaDetails(filename, metadata.PosterURL, metadata.DurationSeconds); err != nil {
+		logger.Error(err, "Failed to store course video poster", map[string]interface{}{"filename": filename})
+	}
+}
+
 func (s *VideoService) Update(id uint, req models.UpdateCourseVideoRequest) (*models.CourseVideo, error) {
 	if s == nil || s.videoRepo == nil {
 		return nil, errors.New("course video repository is not configured")
@@ -42,6 +42,9 @@ func (r *stubCourseVideoRepo) List() ([]models.CourseVideo, error) {
 	return []models.CourseVideo{}, nil
 }
 func (r *stubCourseVideoRepo) Exists(id uint) (bool, error) { return r.video.ID == id, nil }
+func (r *stubCourseVideoRepo) ApplyMediaDetails(filename, posterURL string, durationSeconds int) error {
+	return nil
+}
 func (r *stubCourseVideoRepo) GetByIDs(ids []uint) ([]models.CourseVideo, error) {
 	return []models.CourseVideo{}, nil
 }
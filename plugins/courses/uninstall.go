@@ -0,0 +1,46 @@
+package courses
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/plugin/migrations"
+)
+
+// Uninstall drops the course tables. It runs only when an administrator removes the
+// plugin with purge enabled. Uploaded media stays in the upload directory because it
+// may be referenced from pages.
+func (f *Feature) Uninstall() error {
+	if f == nil || f.host == nil {
+		return fmt.Errorf("feature host is not configured")
+	}
+
+	db := f.host.Database()
+	if db == nil {
+		return fmt.Errorf("database is not configured")
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		// Children first so foreign keys never block the drop.
+		if err := tx.Migrator().DropTable(
+			&models.CourseTestResult{},
+			&models.CourseTopicStep{},
+			&models.CourseTestQuestionOption{},
+			&models.CourseTestQuestion{},
+			&models.CourseTest{},
+			&models.CourseAnnouncement{},
+			&models.CoursePackageAccess{},
+			&models.CoursePackageTopic{},
+			&models.CourseTopicVideo{},
+			&models.CoursePackage{},
+			&models.CourseContent{},
+			&models.CourseTopic{},
+			&models.CourseVideo{},
+		); err != nil {
+			return fmt.Errorf("failed to drop course tables: %w", err)
+		}
+		return migrations.Forget(tx, "courses")
+	})
+}
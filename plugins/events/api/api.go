@@ -0,0 +1,15 @@
+package api
+
+const Namespace = "events"
+
+const (
+	ServiceEvent = "event"
+)
+
+const (
+	HandlerEvent  = "event"
+	HandlerPublic = "public"
+)
+
+// SectionUpcomingEvents is the page builder section type registered by the plugin.
+const SectionUpcomingEvents = "upcoming_events"
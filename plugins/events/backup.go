@@ -0,0 +1,10 @@
+package events
+
+import (
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/plugin/backup"
+)
+
+func init() {
+	backup.Register("events", &models.Event{})
+}
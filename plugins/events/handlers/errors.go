@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	eventservice "constructor-script-backend/plugins/events/service"
+)
+
+func eventErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, eventservice.ErrEventNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, eventservice.ErrInvalidEvent):
+		return http.StatusBadRequest
+	case errors.Is(err, eventservice.ErrSlugConflict):
+		return http.StatusConflict
+	case errors.Is(err, eventservice.ErrRepositoryNotReady):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/pkg/apierror"
+	eventservice "constructor-script-backend/plugins/events/service"
+)
+
+type EventHandler struct {
+	service *eventservice.EventService
+}
+
+func NewEventHandler(service *eventservice.EventService) *EventHandler {
+	return &EventHandler{service: service}
+}
+
+func (h *EventHandler) SetService(service *eventservice.EventService) {
+	if h == nil {
+		return
+	}
+	h.service = service
+}
+
+func (h *EventHandler) ensureService(c *gin.Context) bool {
+	if h == nil || h.service == nil {
+		if c != nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "events plugin is not active"})
+		}
+		return false
+	}
+	return true
+}
+
+func (h *EventHandler) List(c *gin.Context) {
+	if !h.ensureService(c) {
+		return
+	}
+
+	events, err := h.service.List()
+	if err != nil {
+		c.JSON(eventErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"events": events})
+}
+
+func (h *EventHandler) Get(c *gin.Context) {
+	if !h.ensureService(c) {
+		return
+	}
+
+	id, ok := parseEventID(c)
+	if !ok {
+		return
+	}
+
+	event, err := h.service.GetByID(id)
+	if err != nil {
+		c.JSON(eventErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"event": event})
+}
+
+func (h *EventHandler) Create(c *gin.Context) {
+	if !h.ensureService(c) {
+		return
+	}
+
+	var req models.CreateEventRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BindError(c, err)
+		return
+	}
+
+	event, err := h.service.Create(req)
+	if err != nil {
+		c.JSON(eventErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"event": event})
+}
+
+func (h *EventHandler) Update(c *gin.Context) {
+	if !h.ensureService(c) {
+		return
+	}
+
+	id, ok := parseEventID(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateEventRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BindError(c, err)
+		return
+	}
+
+	event, err := h.service.Update(id, req)
+	if err != nil {
+		c.JSON(eventErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"event": event})
+}
+
+func (h *EventHandler) Delete(c *gin.Context) {
+	if !h.ensureService(c) {
+		return
+	}
+
+	id, ok := parseEventID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(id); err != nil {
+		c.JSON(eventErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "event deleted"})
+}
+
+func parseEventID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid event id"})
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"constructor-script-backend/internal/models"
+	eventservice "constructor-script-backend/plugins/events/service"
+)
+
+const (
+	defaultOccurrenceLimit = 20
+	maxOccurrenceLimit     = 200
+	maxOccurrenceWindow    = 366 * 24 * time.Hour
+)
+
+// SiteSettingsProvider resolves the site name and URL used in the iCal feed.
+type SiteSettingsProvider interface {
+	GetSiteSettings(defaults models.SiteSettings) (models.SiteSettings, error)
+}
+
+type PublicHandler struct {
+	service  *eventservice.EventService
+	site     SiteSettingsProvider
+	defaults models.SiteSettings
+}
+
+func NewPublicHandler(service *eventservice.EventService, site SiteSettingsProvider, defaults models.SiteSettings) *PublicHandler {
+	return &PublicHandler{service: service, site: site, defaults: defaults}
+}
+
+func (h *PublicHandler) SetService(service *eventservice.EventService) {
+	if h == nil {
+		return
+	}
+	h.service = service
+}
+
+func (h *PublicHandler) SetSite(site SiteSettingsProvider, defaults models.SiteSettings) {
+	if h == nil {
+		return
+	}
+	h.site = site
+	h.defaults = defaults
+}
+
+func (h *PublicHandler) ensureService(c *gin.Context) bool {
+	if h == nil || h.service == nil {
+		if c != nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "events plugin is not active"})
+		}
+		return false
+	}
+	return true
+}
+
+// Occurrences lists event occurrences between from and to (RFC 3339). Without a range
+// the upcoming occurrences are returned.
+func (h *PublicHandler) Occurrences(c *gin.Context) {
+	if !h.ensureService(c) {
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultOccurrenceLimit)))
+	if err != nil || limit < 1 {
+		limit = defaultOccurrenceLimit
+	}
+	if limit > maxOccurrenceLimit {
+		limit = maxOccurrenceLimit
+	}
+
+	rawFrom, rawTo := strings.TrimSpace(c.Query("from")), strings.TrimSpace(c.Query("to"))
+	if rawFrom == "" && rawTo == "" {
+		occurrences, err := h.service.Upcoming(limit)
+		if err != nil {
+			c.JSON(eventErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"occurrences": occurrences})
+		return
+	}
+
+	from, err := time.Parse(time.RFC3339, rawFrom)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must be an RFC 3339 timestamp"})
+		return
+	}
+	to, err := time.Parse(time.RFC3339, rawTo)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "to must be an RFC 3339 timestamp"})
+		return
+	}
+	if !to.After(from) || to.Sub(from) > maxOccurrenceWindow {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "the range must be positive and at most one year long"})
+		return
+	}
+
+	occurrences, err := h.service.Occurrences(from, to, limit)
+	if err != nil {
+		c.JSON(eventErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"occurrences": occurrences})
+}
+
+func (h *PublicHandler) GetBySlug(c *gin.Context) {
+	if !h.ensureService(c) {
+		return
+	}
+
+	event, err := h.service.GetBySlug(c.Param("slug"), false)
+	if err != nil {
+		c.JSON(eventErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	now := time.Now().UTC()
+	upcoming := eventservice.ExpandEvent(event, now, now.Add(maxOccurrenceWindow), 10)
+	c.JSON(http.StatusOK, gin.H{"event": event, "upcoming": upcoming})
+}
+
+// Feed serves every published event as an iCalendar subscription.
+func (h *PublicHandler) Feed(c *gin.Context) {
+	if !h.ensureService(c) {
+		return
+	}
+
+	events, err := h.service.ListPublished()
+	if err != nil {
+		c.JSON(eventErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	h.writeCalendar(c, events, "events.ics")
+}
+
+// EventFeed serves a single published event as an iCalendar file.
+func (h *PublicHandler) EventFeed(c *gin.Context) {
+	if !h.ensureService(c) {
+		return
+	}
+
+	event, err := h.service.GetBySlug(c.Param("slug"), false)
+	if err != nil {
+		c.JSON(eventErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	h.writeCalendar(c, []models.Event{*event}, event.Slug+".ics")
+}
+
+func (h *PublicHandler) writeCalendar(c *gin.Context, events []models.Event, filename string) {
+	settings := h.defaults
+	if h.site != nil {
+		if resolved, err := h.site.GetSiteSettings(h.defaults); err == nil {
+			settings = resolved
+		}
+	}
+
+	body := eventservice.BuildCalendar(events, eventservice.CalendarOptions{
+		Name:    settings.Name,
+		BaseURL: settings.URL,
+	})
+
+	c.Header("Content-Disposition", `inline; filename="`+filename+`"`)
+	c.Header("Cache-Control", "public, max-age=300")
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", []byte(body))
+}
@@ -0,0 +1,12 @@
+package events
+
+import (
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/plugin/migrations"
+)
+
+func init() {
+	migrations.RegisterTables("events",
+		&models.Event{},
+	)
+}
@@ -0,0 +1,119 @@
+package events
+
+import (
+	"fmt"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/plugin/host"
+	"constructor-script-backend/internal/plugin/registry"
+	pluginruntime "constructor-script-backend/internal/plugin/runtime"
+	eventsapi "constructor-script-backend/plugins/events/api"
+	eventhandlers "constructor-script-backend/plugins/events/handlers"
+	eventsections "constructor-script-backend/plugins/events/sections"
+	eventservice "constructor-script-backend/plugins/events/service"
+)
+
+func init() {
+	registry.Register("events", NewFeature)
+}
+
+type Feature struct {
+	host host.Host
+}
+
+func NewFeature(h host.Host) (pluginruntime.Feature, error) {
+	if h == nil {
+		return nil, fmt.Errorf("host is required")
+	}
+	return &Feature{host: h}, nil
+}
+
+func (f *Feature) Activate() error {
+	if f == nil || f.host == nil {
+		return fmt.Errorf("feature host is not configured")
+	}
+
+	repos := f.host.Repositories()
+	if repos == nil {
+		return fmt.Errorf("repository access is not configured")
+	}
+	repo := repos.Event()
+	if repo == nil {
+		return fmt.Errorf("event repository is not configured")
+	}
+
+	servicesRegistry := f.host.Services(eventsapi.Namespace)
+	handlersRegistry := f.host.Handlers(eventsapi.Namespace)
+
+	var eventService *eventservice.EventService
+	if existing, ok := servicesRegistry.Get(eventsapi.ServiceEvent).(*eventservice.EventService); ok {
+		eventService = existing
+	}
+	if eventService == nil {
+		eventService = eventservice.NewEventService(repo)
+	} else {
+		eventService.SetRepository(repo)
+	}
+	servicesRegistry.Set(eventsapi.ServiceEvent, eventService)
+
+	var site eventhandlers.SiteSettingsProvider
+	if setup := f.host.CoreServices().Setup(); setup != nil {
+		site = setup
+	}
+	var defaults models.SiteSettings
+	if cfg := f.host.Config(); cfg != nil {
+		defaults = models.SiteSettings{Name: cfg.SiteName, URL: cfg.SiteURL}
+	}
+
+	if handler, ok := handlersRegistry.Get(eventsapi.HandlerEvent).(*eventhandlers.EventHandler); ok {
+		handler.SetService(eventService)
+	} else {
+		handlersRegistry.Set(eventsapi.HandlerEvent, eventhandlers.NewEventHandler(eventService))
+	}
+
+	if handler, ok := handlersRegistry.Get(eventsapi.HandlerPublic).(*eventhandlers.PublicHandler); ok {
+		handler.SetService(eventService)
+		handler.SetSite(site, defaults)
+	} else {
+		handlersRegistry.Set(eventsapi.HandlerPublic, eventhandlers.NewPublicHandler(eventService, site, defaults))
+	}
+
+	if templateHandler := f.host.TemplateHandler(); templateHandler != nil {
+		templateHandler.SetEventService(eventService)
+		templateHandler.UnregisterSection(eventsapi.SectionUpcomingEvents)
+		descriptor := eventsections.UpcomingEvents(func() *eventservice.EventService {
+			service, _ := f.host.Services(eventsapi.Namespace).Get(eventsapi.ServiceEvent).(*eventservice.EventService)
+			return service
+		})
+		if err := templateHandler.RegisterSectionWithMetadata(descriptor); err != nil {
+			return fmt.Errorf("failed to register upcoming events section: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Deactivate detaches the service from the handlers, which answer with 503 until the
+// plugin is activated again, and removes the upcoming events section.
+func (f *Feature) Deactivate() error {
+	if f == nil || f.host == nil {
+		return nil
+	}
+
+	handlersRegistry := f.host.Handlers(eventsapi.Namespace)
+	if handler, _ := handlersRegistry.Get(eventsapi.HandlerEvent).(*eventhandlers.EventHandler); handler != nil {
+		handler.SetService(nil)
+	}
+	if handler, _ := handlersRegistry.Get(eventsapi.HandlerPublic).(*eventhandlers.PublicHandler); handler != nil {
+		handler.SetService(nil)
+	}
+
+	f.host.Services(eventsapi.Namespace).Set(eventsapi.ServiceEvent, nil)
+
+	if templateHandler := f.host.TemplateHandler(); templateHandler != nil {
+		templateHandler.SetEventService(nil)
+		templateHandler.UnregisterSection(eventsapi.SectionUpcomingEvents)
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package sections
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+
+	"constructor-script-backend/internal/models"
+	coresections "constructor-script-backend/internal/sections"
+	"constructor-script-backend/pkg/logger"
+	eventsapi "constructor-script-backend/plugins/events/api"
+	eventservice "constructor-script-backend/plugins/events/service"
+)
+
+const (
+	defaultUpcomingLimit = 3
+	maxUpcomingLimit     = 12
+)
+
+// UpcomingEvents describes the page builder section listing the next event
+// occurrences. The renderer reads the service on every render so it follows
+// plugin reactivation.
+func UpcomingEvents(service func() *eventservice.EventService) *coresections.SectionDescriptor {
+	return &coresections.SectionDescriptor{
+		Renderer: func(ctx coresections.RenderContext, prefix string, elem models.SectionElement) (string, []string) {
+			return renderUpcomingEvents(service(), prefix, elem)
+		},
+		Metadata: coresections.SectionMetadata{
+			Type:        eventsapi.SectionUpcomingEvents,
+			Name:        "Upcoming Events",
+			Description: "Lists the next scheduled events with a link to the calendar",
+			Category:    "content",
+			Icon:        "calendar",
+			Schema: map[string]interface{}{
+				"limit": map[string]interface{}{
+					"type":    "number",
+					"default": defaultUpcomingLimit,
+					"min":     1,
+					"max":     maxUpcomingLimit,
+				},
+			},
+		},
+	}
+}
+
+func renderUpcomingEvents(service *eventservice.EventService, prefix string, elem models.SectionElement) (string, []string) {
+	var section models.Section
+	switch value := elem.Content.(type) {
+	case models.Section:
+		section = value
+	case *models.Section:
+		if value == nil {
+			return "", nil
+		}
+		section = *value
+	default:
+		return "", nil
+	}
+
+	emptyClass := fmt.Sprintf("%s__events-empty content__empty", prefix)
+	if service == nil {
+		return `<p class="` + emptyClass + `">Events are not available right now.</p>`, nil
+	}
+
+	limit := section.Limit
+	if limit <= 0 {
+		limit = defaultUpcomingLimit
+	}
+	if limit > maxUpcomingLimit {
+		limit = maxUpcomingLimit
+	}
+
+	occurrences, err := service.Upcoming(limit)
+	if err != nil {
+		logger.Error(err, "Failed to load upcoming events for section", map[string]interface{}{"section_id": section.ID})
+		return `<p class="` + emptyClass + `">Unable to load events at the moment. Please try again later.</p>`, nil
+	}
+	if len(occurrences) == 0 {
+		return `<p class="` + emptyClass + `">No upcoming events are scheduled.</p>`, nil
+	}
+
+	listClass := fmt.Sprintf("%s__events events-upcoming__list", prefix)
+	itemClass := fmt.Sprintf("%s__event events-upcoming__item", prefix)
+
+	var sb strings.Builder
+	sb.WriteString(`<ul class="` + listClass + `">`)
+	for _, occurrence := range occurrences {
+		event := occurrence.Event
+		if event == nil {
+			continue
+		}
+		sb.WriteString(`<li class="` + itemClass + `"><article class="event-card">`)
+		sb.WriteString(`<time class="event-card__date" datetime="` + occurrence.StartsAt.Format("2006-01-02T15:04:05Z07:00") + `">`)
+		if event.AllDay {
+			sb.WriteString(template.HTMLEscapeString(occurrence.StartsAt.Format("Monday, January 2, 2006")))
+		} else {
+			sb.WriteString(template.HTMLEscapeString(occurrence.StartsAt.Format("Monday, January 2, 2006 15:04")))
+		}
+		sb.WriteString(`</time>`)
+		sb.WriteString(`<h3 class="event-card__title"><a href="/events/` + template.HTMLEscapeString(event.Slug) + `">`)
+		sb.WriteString(template.HTMLEscapeString(event.Title))
+		sb.WriteString(`</a></h3>`)
+		if event.Location != "" {
+			sb.WriteString(`<p class="event-card__meta">` + template.HTMLEscapeString(event.Location) + `</p>`)
+		}
+		sb.WriteString(`</article></li>`)
+	}
+	sb.WriteString(`</ul>`)
+	sb.WriteString(`<a class="` + prefix + `__events-more" href="/events">See all events</a>`)
+
+	return sb.String(), nil
+}
@@ -0,0 +1,10 @@
+package service
+
+import "errors"
+
+var (
+	ErrEventNotFound      = errors.New("event not found")
+	ErrInvalidEvent       = errors.New("invalid event")
+	ErrSlugConflict       = errors.New("slug already in use")
+	ErrRepositoryNotReady = errors.New("event repository is not configured")
+)
@@ -0,0 +1,299 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/repository"
+	"constructor-script-backend/pkg/utils"
+)
+
+// upcomingHorizon is how far ahead upcoming occurrences are looked up.
+const upcomingHorizon = 2 * 366 * 24 * time.Hour
+
+type EventService struct {
+	repo repository.EventRepository
+}
+
+func NewEventService(repo repository.EventRepository) *EventService {
+	return &EventService{repo: repo}
+}
+
+func (s *EventService) SetRepository(repo repository.EventRepository) {
+	if s == nil {
+		return
+	}
+	s.repo = repo
+}
+
+func (s *EventService) List() ([]models.Event, error) {
+	if s == nil || s.repo == nil {
+		return nil, ErrRepositoryNotReady
+	}
+	return s.repo.List()
+}
+
+// ListPublished returns every published event, used for the iCal feed.
+func (s *EventService) ListPublished() ([]models.Event, error) {
+	if s == nil || s.repo == nil {
+		return nil, ErrRepositoryNotReady
+	}
+	return s.repo.ListPublished()
+}
+
+func (s *EventService) GetByID(id uint) (*models.Event, error) {
+	if s == nil || s.repo == nil {
+		return nil, ErrRepositoryNotReady
+	}
+
+	event, err := s.repo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrEventNotFound
+		}
+		return nil, err
+	}
+	return event, nil
+}
+
+// GetBySlug returns the event with slug. Unpublished events are reported as missing
+// unless includeUnpublished is set.
+func (s *EventService) GetBySlug(slug string, includeUnpublished bool) (*models.Event, error) {
+	if s == nil || s.repo == nil {
+		return nil, ErrRepositoryNotReady
+	}
+
+	event, err := s.repo.GetBySlug(strings.TrimSpace(slug))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrEventNotFound
+		}
+		return nil, err
+	}
+	if !event.Published && !includeUnpublished {
+		return nil, ErrEventNotFound
+	}
+	return event, nil
+}
+
+func (s *EventService) Create(req models.CreateEventRequest) (*models.Event, error) {
+	if s == nil || s.repo == nil {
+		return nil, ErrRepositoryNotReady
+	}
+
+	event := &models.Event{
+		Title:       strings.TrimSpace(req.Title),
+		Summary:     strings.TrimSpace(req.Summary),
+		Description: strings.TrimSpace(req.Description),
+		Location:    strings.TrimSpace(req.Location),
+		URL:         strings.TrimSpace(req.URL),
+		Image:       strings.TrimSpace(req.Image),
+		StartsAt:    req.StartsAt,
+		EndsAt:      req.EndsAt,
+		AllDay:      req.AllDay,
+		Timezone:    strings.TrimSpace(req.Timezone),
+		Recurrence:  req.Recurrence,
+	}
+	if req.Published != nil {
+		event.Published = *req.Published
+	}
+
+	if err := s.prepare(event, req.Slug); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(event); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+func (s *EventService) Update(id uint, req models.UpdateEventRequest) (*models.Event, error) {
+	event, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	slug := event.Slug
+	if req.Title != nil {
+		event.Title = strings.TrimSpace(*req.Title)
+	}
+	if req.Slug != nil {
+		slug = *req.Slug
+	}
+	if req.Summary != nil {
+		event.Summary = strings.TrimSpace(*req.Summary)
+	}
+	if req.Description != nil {
+		event.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.Location != nil {
+		event.Location = strings.TrimSpace(*req.Location)
+	}
+	if req.URL != nil {
+		event.URL = strings.TrimSpace(*req.URL)
+	}
+	if req.Image != nil {
+		event.Image = strings.TrimSpace(*req.Image)
+	}
+	if req.StartsAt != nil {
+		event.StartsAt = *req.StartsAt
+	}
+	if req.EndsAt != nil {
+		event.EndsAt = *req.EndsAt
+	}
+	if req.AllDay != nil {
+		event.AllDay = *req.AllDay
+	}
+	if req.Timezone != nil {
+		event.Timezone = strings.TrimSpace(*req.Timezone)
+	}
+	if req.Recurrence != nil {
+		event.Recurrence = *req.Recurrence
+	}
+	if req.Published != nil {
+		event.Published = *req.Published
+	}
+
+	if err := s.prepare(event, slug); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(event); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+func (s *EventService) Delete(id uint) error {
+	if _, err := s.GetByID(id); err != nil {
+		return err
+	}
+	return s.repo.Delete(id)
+}
+
+// Occurrences expands the published events into the occurrences overlapping
+// [from, to), ordered by start time. At most limit occurrences are returned when limit
+// is positive.
+func (s *EventService) Occurrences(from, to time.Time, limit int) ([]models.EventOccurrence, error) {
+	if s == nil || s.repo == nil {
+		return nil, ErrRepositoryNotReady
+	}
+
+	events, err := s.repo.ListPublishedStartingBefore(to)
+	if err != nil {
+		return nil, err
+	}
+
+	var occurrences []models.EventOccurrence
+	for i := range events {
+		occurrences = append(occurrences, ExpandEvent(&events[i], from, to, limit)...)
+	}
+
+	sort.SliceStable(occurrences, func(i, j int) bool {
+		return occurrences[i].StartsAt.Before(occurrences[j].StartsAt)
+	})
+	if limit > 0 && len(occurrences) > limit {
+		occurrences = occurrences[:limit]
+	}
+	return occurrences, nil
+}
+
+// Upcoming returns the next occurrences of published events, including those that
+// are in progress.
+func (s *EventService) Upcoming(limit int) ([]models.EventOccurrence, error) {
+	now := time.Now().UTC()
+	return s.Occurrences(now, now.Add(upcomingHorizon), limit)
+}
+
+// ExpandEvent returns the occurrences of event overlapping [from, to), in the event's
+// time zone.
+func ExpandEvent(event *models.Event, from, to time.Time, limit int) []models.EventOccurrence {
+	if event == nil {
+		return nil
+	}
+
+	recurrence, err := ParseRecurrence(event.Recurrence)
+	if err != nil {
+		// Stored rules are validated on save; fall back to the first occurrence.
+		recurrence = nil
+	}
+
+	loc := EventLocation(event)
+	duration := event.EndsAt.Sub(event.StartsAt)
+	starts := recurrence.Starts(event.StartsAt.In(loc), duration, from, to, limit)
+
+	occurrences := make([]models.EventOccurrence, 0, len(starts))
+	for _, start := range starts {
+		occurrences = append(occurrences, models.EventOccurrence{
+			Event:    event,
+			StartsAt: start,
+			EndsAt:   start.Add(duration),
+		})
+	}
+	return occurrences
+}
+
+// EventLocation returns the time zone the event is scheduled in, defaulting to UTC.
+func EventLocation(event *models.Event) *time.Location {
+	if event != nil && event.Timezone != "" {
+		if loc, err := time.LoadLocation(event.Timezone); err == nil {
+			return loc
+		}
+	}
+	return time.UTC
+}
+
+func (s *EventService) prepare(event *models.Event, requestedSlug string) error {
+	if event.Title == "" {
+		return fmt.Errorf("%w: title is required", ErrInvalidEvent)
+	}
+	if event.StartsAt.IsZero() || event.EndsAt.IsZero() {
+		return fmt.Errorf("%w: start and end are required", ErrInvalidEvent)
+	}
+	if event.EndsAt.Before(event.StartsAt) {
+		return fmt.Errorf("%w: end must not be before start", ErrInvalidEvent)
+	}
+	if event.Timezone != "" {
+		if _, err := time.LoadLocation(event.Timezone); err != nil {
+			return fmt.Errorf("%w: unknown time zone %q", ErrInvalidEvent, event.Timezone)
+		}
+	}
+	if event.URL != "" {
+		parsed, err := url.Parse(event.URL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("%w: url must be an absolute http(s) URL", ErrInvalidEvent)
+		}
+	}
+
+	recurrence, err := ParseRecurrence(event.Recurrence)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidEvent, err)
+	}
+	event.Recurrence = recurrence.String()
+
+	event.StartsAt = event.StartsAt.UTC()
+	event.EndsAt = event.EndsAt.UTC()
+
+	slug := utils.GenerateSlug(strings.TrimSpace(requestedSlug))
+	if slug == "" {
+		slug = utils.GenerateSlug(event.Title)
+	}
+	if slug == "" {
+		return fmt.Errorf("%w: slug is required", ErrInvalidEvent)
+	}
+	exists, err := s.repo.ExistsBySlug(slug, event.ID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrSlugConflict
+	}
+	event.Slug = slug
+	return nil
+}
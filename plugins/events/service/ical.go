@@ -0,0 +1,131 @@
+package service
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"constructor-script-backend/internal/models"
+)
+
+// CalendarOptions describes the site an iCalendar feed is published by.
+type CalendarOptions struct {
+	Name    string
+	BaseURL string
+}
+
+const (
+	icalDateTimeUTC = "20060102T150405Z"
+	icalDateTime    = "20060102T150405"
+	icalDate        = "20060102"
+)
+
+var icalTextEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+
+// BuildCalendar renders events as an iCalendar (RFC 5545) document. Recurring events
+// are exported with their RRULE so calendar clients expand them themselves.
+func BuildCalendar(events []models.Event, opts CalendarOptions) string {
+	baseURL := strings.TrimRight(strings.TrimSpace(opts.BaseURL), "/")
+	host := "localhost"
+	if parsed, err := url.Parse(baseURL); err == nil && parsed.Hostname() != "" {
+		host = parsed.Hostname()
+	}
+
+	var b strings.Builder
+	writeLine := func(line string) {
+		b.WriteString(foldICalLine(line))
+		b.WriteString("\r\n")
+	}
+
+	writeLine("BEGIN:VCALENDAR")
+	writeLine("VERSION:2.0")
+	writeLine("PRODID:-//Constructor Script//Events//EN")
+	writeLine("CALSCALE:GREGORIAN")
+	writeLine("METHOD:PUBLISH")
+	if name := strings.TrimSpace(opts.Name); name != "" {
+		writeLine("X-WR-CALNAME:" + escapeICalText(name))
+	}
+
+	for _, event := range events {
+		loc := EventLocation(&event)
+		start := event.StartsAt.In(loc)
+		end := event.EndsAt.In(loc)
+
+		writeLine("BEGIN:VEVENT")
+		writeLine("UID:event-" + strconv.FormatUint(uint64(event.ID), 10) + "@" + host)
+		writeLine("DTSTAMP:" + event.UpdatedAt.UTC().Format(icalDateTimeUTC))
+		writeLine("LAST-MODIFIED:" + event.UpdatedAt.UTC().Format(icalDateTimeUTC))
+
+		switch {
+		case event.AllDay:
+			// DTEND is exclusive for all-day events.
+			endDate := time.Date(end.Year(), end.Month(), end.Day()+1, 0, 0, 0, 0, loc)
+			writeLine("DTSTART;VALUE=DATE:" + start.Format(icalDate))
+			writeLine("DTEND;VALUE=DATE:" + endDate.Format(icalDate))
+		case loc != time.UTC:
+			writeLine("DTSTART;TZID=" + loc.String() + ":" + start.Format(icalDateTime))
+			writeLine("DTEND;TZID=" + loc.String() + ":" + end.Format(icalDateTime))
+		default:
+			writeLine("DTSTART:" + start.Format(icalDateTimeUTC))
+			writeLine("DTEND:" + end.Format(icalDateTimeUTC))
+		}
+
+		if event.Recurrence != "" {
+			writeLine("RRULE:" + event.Recurrence)
+		}
+		writeLine("SUMMARY:" + escapeICalText(event.Title))
+
+		description := strings.TrimSpace(strings.Join(nonEmpty(event.Summary, event.Description), "\n\n"))
+		if description != "" {
+			writeLine("DESCRIPTION:" + escapeICalText(description))
+		}
+		if event.Location != "" {
+			writeLine("LOCATION:" + escapeICalText(event.Location))
+		}
+		if baseURL != "" {
+			writeLine("URL:" + baseURL + "/events/" + event.Slug)
+		}
+		writeLine("END:VEVENT")
+	}
+
+	writeLine("END:VCALENDAR")
+	return b.String()
+}
+
+func escapeICalText(value string) string {
+	return icalTextEscaper.Replace(value)
+}
+
+// foldICalLine splits content lines longer than 75 octets, as RFC 5545 requires,
+// without breaking UTF-8 sequences.
+func foldICalLine(line string) string {
+	const limit = 75
+	if len(line) <= limit {
+		return line
+	}
+
+	var b strings.Builder
+	width := 0
+	for _, r := range line {
+		size := len(string(r))
+		if width+size > limit {
+			b.WriteString("\r\n ")
+			// The leading space of a continuation line counts towards its length.
+			width = 1
+		}
+		b.WriteRune(r)
+		width += size
+	}
+	return b.String()
+}
+
+func nonEmpty(values ...string) []string {
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if trimmed := strings.TrimSpace(value); trimmed != "" {
+			result = append(result, trimmed)
+		}
+	}
+	return result
+}
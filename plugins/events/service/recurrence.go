@@ -0,0 +1,250 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Recurrence frequencies supported from the iCalendar RRULE grammar.
+const (
+	FrequencyDaily   = "DAILY"
+	FrequencyWeekly  = "WEEKLY"
+	FrequencyMonthly = "MONTHLY"
+	FrequencyYearly  = "YEARLY"
+)
+
+// maxRecurrencePeriods bounds rule expansion so a far-away window cannot loop forever.
+const maxRecurrencePeriods = 20000
+
+var recurrenceDays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// Recurrence is the subset of RRULE understood by the events plugin: FREQ, INTERVAL,
+// COUNT, UNTIL and, for weekly rules, BYDAY with plain day codes.
+type Recurrence struct {
+	Frequency string
+	Interval  int
+	Count     int
+	Until     time.Time
+	ByDay     []time.Weekday
+}
+
+// ParseRecurrence parses an RRULE value. An empty rule yields nil.
+func ParseRecurrence(rule string) (*Recurrence, error) {
+	trimmed := strings.TrimSpace(rule)
+	trimmed = strings.TrimPrefix(strings.ToUpper(trimmed), "RRULE:")
+	if trimmed == "" {
+		return nil, nil
+	}
+
+	recurrence := &Recurrence{Interval: 1}
+	for _, part := range strings.Split(trimmed, ";") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid recurrence part %q", part)
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "FREQ":
+			switch value {
+			case FrequencyDaily, FrequencyWeekly, FrequencyMonthly, FrequencyYearly:
+				recurrence.Frequency = value
+			default:
+				return nil, fmt.Errorf("unsupported recurrence frequency %q", value)
+			}
+		case "INTERVAL":
+			interval, err := strconv.Atoi(value)
+			if err != nil || interval < 1 {
+				return nil, fmt.Errorf("invalid recurrence interval %q", value)
+			}
+			recurrence.Interval = interval
+		case "COUNT":
+			count, err := strconv.Atoi(value)
+			if err != nil || count < 1 {
+				return nil, fmt.Errorf("invalid recurrence count %q", value)
+			}
+			recurrence.Count = count
+		case "UNTIL":
+			until, err := parseRecurrenceUntil(value)
+			if err != nil {
+				return nil, err
+			}
+			recurrence.Until = until
+		case "BYDAY":
+			for _, code := range strings.Split(value, ",") {
+				day, ok := recurrenceDays[strings.TrimSpace(code)]
+				if !ok {
+					return nil, fmt.Errorf("unsupported recurrence day %q", code)
+				}
+				recurrence.ByDay = append(recurrence.ByDay, day)
+			}
+		case "WKST":
+			if value != "MO" {
+				return nil, fmt.Errorf("only WKST=MO is supported")
+			}
+		default:
+			return nil, fmt.Errorf("unsupported recurrence part %q", key)
+		}
+	}
+
+	if recurrence.Frequency == "" {
+		return nil, fmt.Errorf("recurrence FREQ is required")
+	}
+	if recurrence.Count > 0 && !recurrence.Until.IsZero() {
+		return nil, fmt.Errorf("recurrence cannot set both COUNT and UNTIL")
+	}
+	if len(recurrence.ByDay) > 0 && recurrence.Frequency != FrequencyWeekly {
+		return nil, fmt.Errorf("BYDAY is only supported for weekly recurrence")
+	}
+	sort.Slice(recurrence.ByDay, func(i, j int) bool {
+		return mondayOffset(recurrence.ByDay[i]) < mondayOffset(recurrence.ByDay[j])
+	})
+
+	return recurrence, nil
+}
+
+func parseRecurrenceUntil(value string) (time.Time, error) {
+	layouts := []string{"20060102T150405Z", "20060102T150405", "20060102"}
+	for _, layout := range layouts {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			if layout == "20060102" {
+				// A date-only UNTIL includes the whole day.
+				parsed = parsed.Add(24*time.Hour - time.Second)
+			}
+			return parsed.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid recurrence UNTIL %q", value)
+}
+
+// String returns the canonical RRULE value.
+func (r *Recurrence) String() string {
+	if r == nil {
+		return ""
+	}
+
+	parts := []string{"FREQ=" + r.Frequency}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	if len(r.ByDay) > 0 {
+		codes := make([]string, 0, len(r.ByDay))
+		for _, day := range r.ByDay {
+			for code, value := range recurrenceDays {
+				if value == day {
+					codes = append(codes, code)
+					break
+				}
+			}
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Starts returns the start times of occurrences that overlap [from, to), given the
+// first occurrence and the duration of each occurrence. Occurrences are generated in
+// start's location so they keep their wall-clock time across DST changes. At most
+// limit starts are returned when limit is positive.
+func (r *Recurrence) Starts(start time.Time, duration time.Duration, from, to time.Time, limit int) []time.Time {
+	var starts []time.Time
+	overlaps := func(candidate time.Time) bool {
+		return candidate.Before(to) && candidate.Add(duration).After(from)
+	}
+
+	if r == nil {
+		if overlaps(start) {
+			starts = append(starts, start)
+		}
+		return starts
+	}
+
+	generated := 0
+	for period := 0; period < maxRecurrencePeriods; period++ {
+		for _, candidate := range r.periodCandidates(start, period) {
+			if candidate.Before(start) {
+				continue
+			}
+			if !r.Until.IsZero() && candidate.After(r.Until) {
+				return starts
+			}
+			generated++
+			if r.Count > 0 && generated > r.Count {
+				return starts
+			}
+			if !candidate.Before(to) {
+				return starts
+			}
+			if overlaps(candidate) {
+				starts = append(starts, candidate)
+				if limit > 0 && len(starts) >= limit {
+					return starts
+				}
+			}
+		}
+	}
+	return starts
+}
+
+// periodCandidates lists the occurrence starts within the period-th interval after
+// start, in chronological order. Dates that do not exist, such as February 30, are
+// skipped as RFC 5545 requires.
+func (r *Recurrence) periodCandidates(start time.Time, period int) []time.Time {
+	step := period * r.Interval
+	year, month, day := start.Date()
+	hour, minute, second := start.Clock()
+	loc := start.Location()
+
+	switch r.Frequency {
+	case FrequencyDaily:
+		return []time.Time{time.Date(year, month, day+step, hour, minute, second, 0, loc)}
+	case FrequencyWeekly:
+		if len(r.ByDay) == 0 {
+			return []time.Time{time.Date(year, month, day+7*step, hour, minute, second, 0, loc)}
+		}
+		weekStart := day - mondayOffset(start.Weekday()) + 7*step
+		candidates := make([]time.Time, 0, len(r.ByDay))
+		for _, weekday := range r.ByDay {
+			candidates = append(candidates, time.Date(year, month, weekStart+mondayOffset(weekday), hour, minute, second, 0, loc))
+		}
+		return candidates
+	case FrequencyMonthly:
+		candidate := time.Date(year, month+time.Month(step), day, hour, minute, second, 0, loc)
+		if candidate.Day() != day {
+			return nil
+		}
+		return []time.Time{candidate}
+	case FrequencyYearly:
+		candidate := time.Date(year+step, month, day, hour, minute, second, 0, loc)
+		if candidate.Day() != day {
+			return nil
+		}
+		return []time.Time{candidate}
+	}
+	return nil
+}
+
+func mondayOffset(day time.Weekday) int {
+	return (int(day) + 6) % 7
+}
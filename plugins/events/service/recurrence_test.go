@@ -0,0 +1,97 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"constructor-script-backend/internal/models"
+)
+
+func TestRecurrenceWeeklyByDayKeepsWallClockAcrossDST(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+
+	rule, err := ParseRecurrence("RRULE:FREQ=WEEKLY;BYDAY=WE,MO;COUNT=4")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := rule.String(); got != "FREQ=WEEKLY;COUNT=4;BYDAY=MO,WE" {
+		t.Fatalf("unexpected canonical rule %q", got)
+	}
+
+	// Monday before the switch to summer time on 2024-03-31.
+	start := time.Date(2024, time.March, 25, 18, 0, 0, 0, loc)
+	starts := rule.Starts(start, time.Hour, start, start.AddDate(0, 1, 0), 0)
+
+	want := []string{"2024-03-25 18:00", "2024-03-27 18:00", "2024-04-01 18:00", "2024-04-03 18:00"}
+	if len(starts) != len(want) {
+		t.Fatalf("expected %d occurrences, got %v", len(want), starts)
+	}
+	for i, occurrence := range starts {
+		if got := occurrence.Format("2006-01-02 15:04"); got != want[i] {
+			t.Fatalf("occurrence %d: expected %s, got %s", i, want[i], got)
+		}
+	}
+}
+
+func TestRecurrenceMonthlySkipsMissingDays(t *testing.T) {
+	rule, err := ParseRecurrence("FREQ=MONTHLY;UNTIL=20240531")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	start := time.Date(2024, time.January, 31, 9, 0, 0, 0, time.UTC)
+	starts := rule.Starts(start, time.Hour, start, start.AddDate(1, 0, 0), 0)
+
+	want := []string{"2024-01-31", "2024-03-31", "2024-05-31"}
+	if len(starts) != len(want) {
+		t.Fatalf("expected %d occurrences, got %v", len(want), starts)
+	}
+	for i, occurrence := range starts {
+		if got := occurrence.Format("2006-01-02"); got != want[i] {
+			t.Fatalf("occurrence %d: expected %s, got %s", i, want[i], got)
+		}
+	}
+}
+
+func TestParseRecurrenceRejectsUnsupportedRules(t *testing.T) {
+	for _, rule := range []string{"FREQ=HOURLY", "COUNT=3", "FREQ=DAILY;BYDAY=MO", "FREQ=DAILY;COUNT=2;UNTIL=20240101"} {
+		if _, err := ParseRecurrence(rule); err == nil {
+			t.Fatalf("expected %q to be rejected", rule)
+		}
+	}
+}
+
+func TestBuildCalendarFoldsAndEscapes(t *testing.T) {
+	event := models.Event{
+		ID:         7,
+		Title:      "Meetup; planning, and more",
+		Slug:       "meetup",
+		Location:   strings.Repeat("Long street name ", 6),
+		StartsAt:   time.Date(2024, time.May, 1, 17, 0, 0, 0, time.UTC),
+		EndsAt:     time.Date(2024, time.May, 1, 19, 0, 0, 0, time.UTC),
+		Recurrence: "FREQ=WEEKLY",
+	}
+
+	calendar := BuildCalendar([]models.Event{event}, CalendarOptions{Name: "Site", BaseURL: "https://example.com/"})
+
+	for _, line := range strings.Split(strings.TrimSuffix(calendar, "\r\n"), "\r\n") {
+		if len(line) > 75 {
+			t.Fatalf("line exceeds 75 octets: %q", line)
+		}
+	}
+	for _, expected := range []string{
+		"UID:event-7@example.com",
+		"DTSTART:20240501T170000Z",
+		"RRULE:FREQ=WEEKLY",
+		`SUMMARY:Meetup\; planning\, and more`,
+		"URL:https://example.com/events/meetup",
+	} {
+		if !strings.Contains(calendar, expected+"\r\n") {
+			t.Fatalf("calendar is missing %q:\n%s", expected, calendar)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package events
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/plugin/migrations"
+)
+
+// Uninstall drops the events table. It runs only when an administrator removes the
+// plugin with purge enabled.
+func (f *Feature) Uninstall() error {
+	if f == nil || f.host == nil {
+		return fmt.Errorf("feature host is not configured")
+	}
+
+	db := f.host.Database()
+	if db == nil {
+		return fmt.Errorf("database is not configured")
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Migrator().DropTable(&models.Event{}); err != nil {
+			return fmt.Errorf("failed to drop events table: %w", err)
+		}
+		return migrations.Forget(tx, "events")
+	})
+}
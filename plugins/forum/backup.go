@@ -0,0 +1,16 @@
+package forum
+
+import (
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/plugin/backup"
+)
+
+func init() {
+	backup.Register("forum",
+		&models.ForumCategory{},
+		&models.ForumQuestion{},
+		&models.ForumAnswer{},
+		&models.ForumQuestionVote{},
+		&models.ForumAnswerVote{},
+	)
+}
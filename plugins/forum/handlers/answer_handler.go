@@ -9,6 +9,7 @@ import (
 
 	"constructor-script-backend/internal/authorization"
 	"constructor-script-backend/internal/models"
+	"constructor-script-backend/pkg/apierror"
 	forumservice "constructor-script-backend/plugins/forum/service"
 )
 
@@ -46,7 +47,7 @@ func (h *AnswerHandler) Create(c *gin.Context) {
 	}
 	var req models.CreateForumAnswerRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.BindError(c, err)
 		return
 	}
 	authorID := c.GetUint("user_id")
@@ -74,7 +75,7 @@ func (h *AnswerHandler) Update(c *gin.Context) {
 	}
 	var req models.UpdateForumAnswerRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.BindError(c, err)
 		return
 	}
 	userID := c.GetUint("user_id")
@@ -134,7 +135,7 @@ func (h *AnswerHandler) Vote(c *gin.Context) {
 	}
 	var req models.ForumVoteRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.BindError(c, err)
 		return
 	}
 	userID := c.GetUint("user_id")
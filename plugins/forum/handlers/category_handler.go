@@ -9,6 +9,7 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"constructor-script-backend/internal/models"
+	"constructor-script-backend/pkg/apierror"
 	forumservice "constructor-script-backend/plugins/forum/service"
 )
 
@@ -80,7 +81,7 @@ func (h *CategoryHandler) Create(c *gin.Context) {
 	}
 	var req models.CreateForumCategoryRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.BindError(c, err)
 		return
 	}
 	category, err := h.service.Create(req)
@@ -107,7 +108,7 @@ func (h *CategoryHandler) Update(c *gin.Context) {
 	}
 	var req models.UpdateForumCategoryRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.BindError(c, err)
 		return
 	}
 	category, err := h.service.Update(uint(id), req)
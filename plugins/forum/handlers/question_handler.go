@@ -9,7 +9,11 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"constructor-script-backend/internal/authorization"
+	"constructor-script-backend/internal/csvexport"
+	"constructor-script-backend/internal/fieldset"
+	"constructor-script-backend/internal/middleware"
 	"constructor-script-backend/internal/models"
+	"constructor-script-backend/pkg/apierror"
 	forumservice "constructor-script-backend/plugins/forum/service"
 )
 
@@ -36,14 +40,76 @@ func (h *QuestionHandler) ensureService(c *gin.Context) bool {
 	return true
 }
 
+// questionFields is what ?fields= and ?include= select from on question lists.
+var questionFields = fieldset.NewSchema(models.ForumQuestion{}, "author", "category", "answers")
+
 func (h *QuestionHandler) List(c *gin.Context) {
 	if !h.ensureService(c) {
 		return
 	}
+	selection, err := fieldset.FromQuery(c, questionFields)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
-	search := c.Query("search")
+	options := questionListOptions(c)
+
+	questions, total, err := h.service.List(page, limit, options)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	items, err := selection.Apply(questions)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"questions": items,
+		"total":     total,
+		"page":      page,
+		"limit":     limit,
+	})
+}
+
+// ListAdmin serves the question list to admins, who can also stream every
+// question matching the filters as CSV with ?format=csv.
+func (h *QuestionHandler) ListAdmin(c *gin.Context) {
+	if !csvexport.Requested(c) {
+		h.List(c)
+		return
+	}
+	if !h.ensureService(c) {
+		return
+	}
 
+	options := questionListOptions(c)
+	options.Status = c.Query("status")
+
+	export := csvexport.New(c, "forum-questions", "id", "created_at", "title", "slug", "author_id", "author", "category", "rating", "views", "answers")
+	err := h.service.Batches(options, func(questions []models.ForumQuestion) error {
+		for _, question := range questions {
+			category := ""
+			if question.Category != nil {
+				category = question.Category.Name
+			}
+			if err := export.Row(question.ID, question.CreatedAt, question.Title, question.Slug, question.AuthorID, question.Author.Username, category, question.Rating, question.Views, question.AnswersCount); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err := export.Finish(err); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func questionListOptions(c *gin.Context) forumservice.QuestionListOptions {
 	var authorID *uint
 	if authorParam := c.Query("author_id"); authorParam != "" {
 		if parsed, err := strconv.ParseUint(authorParam, 10, 64); err == nil {
@@ -60,25 +126,12 @@ func (h *QuestionHandler) List(c *gin.Context) {
 		}
 	}
 
-	options := forumservice.QuestionListOptions{
-		Search:       search,
+	return forumservice.QuestionListOptions{
+		Search:       c.Query("search"),
 		AuthorID:     authorID,
 		CategoryID:   categoryID,
 		CategorySlug: strings.TrimSpace(c.Query("category")),
 	}
-
-	questions, total, err := h.service.List(page, limit, options)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"questions": questions,
-		"total":     total,
-		"page":      page,
-		"limit":     limit,
-	})
 }
 
 func (h *QuestionHandler) GetByID(c *gin.Context) {
@@ -110,6 +163,7 @@ func (h *QuestionHandler) GetByID(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	setQuestionValidators(c, question)
 	c.JSON(http.StatusOK, gin.H{"question": question})
 }
 
@@ -119,7 +173,7 @@ func (h *QuestionHandler) Create(c *gin.Context) {
 	}
 	var req models.CreateForumQuestionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.BindError(c, err)
 		return
 	}
 	authorID := c.GetUint("user_id")
@@ -147,7 +201,7 @@ func (h *QuestionHandler) Update(c *gin.Context) {
 	}
 	var req models.UpdateForumQuestionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.BindError(c, err)
 		return
 	}
 	userID := c.GetUint("user_id")
@@ -231,7 +285,7 @@ func (h *QuestionHandler) Vote(c *gin.Context) {
 	}
 	var req models.ForumVoteRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.BindError(c, err)
 		return
 	}
 	userID := c.GetUint("user_id")
@@ -249,3 +303,23 @@ func (h *QuestionHandler) Vote(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, gin.H{"rating": rating})
 }
+
+// setQuestionValidators lets clients revalidate a question and its answers. The
+// view counter goes up on every read, so it is left out of the ETag, and answers
+// count as changes to the question.
+func setQuestionValidators(c *gin.Context, question *models.ForumQuestion) {
+	if question == nil {
+		return
+	}
+	content := *question
+	content.Views = 0
+	middleware.SetContentETag(c, content)
+
+	modified := question.UpdatedAt
+	for _, answer := range question.Answers {
+		if answer.UpdatedAt.After(modified) {
+			modified = answer.UpdatedAt
+		}
+	}
+	middleware.SetLastModified(c, modified)
+}
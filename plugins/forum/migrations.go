@@ -0,0 +1,45 @@
+package forum
+
+import (
+	"gorm.io/gorm"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/plugin/migrations"
+	"constructor-script-backend/pkg/logger"
+)
+
+func init() {
+	migrations.RegisterTables("forum",
+		&models.ForumCategory{},
+		&models.ForumQuestion{},
+		&models.ForumAnswer{},
+		&models.ForumQuestionVote{},
+		&models.ForumAnswerVote{},
+	)
+	migrations.Register("forum", migrations.Migration{
+		Version:     1,
+		Description: "drop legacy forum category indexes",
+		Stage:       migrations.StagePreSchema,
+		Up:          dropLegacyCategoryIndexes,
+	})
+}
+
+func dropLegacyCategoryIndexes(tx *gorm.DB) error {
+	migrator := tx.Migrator()
+	if !migrator.HasTable(&models.ForumCategory{}) {
+		return nil
+	}
+
+	indexes := []string{"idx_forum_categories_name", "idx_forum_categories_slug"}
+	for _, indexName := range indexes {
+		if migrator.HasIndex(&models.ForumCategory{}, indexName) {
+			if err := migrator.DropIndex(&models.ForumCategory{}, indexName); err != nil {
+				logger.Warn("Failed to drop legacy forum category index", map[string]interface{}{
+					"index": indexName,
+					"error": err.Error(),
+				})
+			}
+		}
+	}
+	return nil
+}
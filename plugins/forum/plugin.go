@@ -1,16 +1,25 @@
 package forum
 
 import (
+	"context"
 	"fmt"
+	"time"
 
+	"constructor-script-backend/internal/background"
 	"constructor-script-backend/internal/plugin/host"
 	"constructor-script-backend/internal/plugin/registry"
 	pluginruntime "constructor-script-backend/internal/plugin/runtime"
+	"constructor-script-backend/pkg/logger"
 	forumapi "constructor-script-backend/plugins/forum/api"
 	forumhandlers "constructor-script-backend/plugins/forum/handlers"
 	forumservice "constructor-script-backend/plugins/forum/service"
 )
 
+const (
+	viewFlushJob      = "forum.flush_question_views"
+	viewFlushSchedule = "@every 1m"
+)
+
 func init() {
 	registry.Register("forum", NewFeature)
 }
@@ -49,6 +58,8 @@ func (f *Feature) Activate() error {
 	} else {
 		questionSvc.SetRepositories(repos.ForumQuestion(), repos.ForumCategory(), repos.ForumQuestionVote())
 	}
+	questionSvc.SetViewBuffer(f.host.Cache())
+	questionSvc.SetEventBus(f.host.Events())
 
 	if value, ok := services.Get(forumapi.ServiceCategory).(*forumservice.CategoryService); ok {
 		categorySvc = value
@@ -70,6 +81,16 @@ func (f *Feature) Activate() error {
 	} else {
 		answerSvc.SetRepositories(repos.ForumAnswer(), repos.ForumQuestion(), repos.ForumAnswerVote())
 	}
+	if email := f.host.CoreServices().Email(); email != nil {
+		answerSvc.SetMailer(email)
+	} else {
+		answerSvc.SetMailer(nil)
+	}
+	if notifications := f.host.CoreServices().Notification(); notifications != nil {
+		answerSvc.SetNotificationPreferences(notifications)
+	} else {
+		answerSvc.SetNotificationPreferences(nil)
+	}
 
 	handlers := f.host.Handlers(forumapi.Namespace)
 
@@ -110,6 +131,26 @@ func (f *Feature) Activate() error {
 		templateHandler.SetForumServices(questionSvc, answerSvc, categorySvc)
 	}
 
+	if seoHandler := f.host.SEOHandler(); seoHandler != nil {
+		seoHandler.SetForumService(questionSvc)
+	}
+
+	if digest := f.host.CoreServices().Digest(); digest != nil {
+		digest.SetSource("forum", answerSvc)
+	}
+
+	if scheduler := f.host.Scheduler(); scheduler != nil {
+		err := scheduler.ScheduleRecurring(background.RecurringJob{
+			Name:     viewFlushJob,
+			Schedule: viewFlushSchedule,
+			Timeout:  time.Minute,
+			Run:      questionSvc.FlushViews,
+		})
+		if err != nil {
+			logger.Error(err, "Failed to schedule forum question view flushing", nil)
+		}
+	}
+
 	return nil
 }
 
@@ -129,7 +170,16 @@ func (f *Feature) Deactivate() error {
 		answerHandler.SetService(nil)
 	}
 
+	if scheduler := f.host.Scheduler(); scheduler != nil {
+		scheduler.Unschedule(viewFlushJob)
+	}
+
 	services := f.host.Services(forumapi.Namespace)
+	if questionSvc, _ := services.Get(forumapi.ServiceQuestion).(*forumservice.QuestionService); questionSvc != nil {
+		if err := questionSvc.FlushViews(context.Background()); err != nil {
+			logger.Error(err, "Failed to flush forum question views", nil)
+		}
+	}
 	services.Set(forumapi.ServiceQuestion, nil)
 	services.Set(forumapi.ServiceCategory, nil)
 	services.Set(forumapi.ServiceAnswer, nil)
@@ -138,5 +188,13 @@ func (f *Feature) Deactivate() error {
 		templateHandler.SetForumServices(nil, nil, nil)
 	}
 
+	if seoHandler := f.host.SEOHandler(); seoHandler != nil {
+		seoHandler.SetForumService(nil)
+	}
+
+	if digest := f.host.CoreServices().Digest(); digest != nil {
+		digest.SetSource("forum", nil)
+	}
+
 	return nil
 }
@@ -9,12 +9,27 @@ import (
 
 	"constructor-script-backend/internal/models"
 	"constructor-script-backend/internal/repository"
+	"constructor-script-backend/internal/service"
+	"constructor-script-backend/pkg/logger"
 )
 
+// AnswerMailer sends themed transactional emails.
+type AnswerMailer interface {
+	Enabled() bool
+	SendTemplate(to, name, fallbackSubject string, data map[string]interface{}) error
+}
+
+// AnswerNotificationPreferences tells whether a user wants an email.
+type AnswerNotificationPreferences interface {
+	Allows(userID uint, event, channel string) bool
+}
+
 type AnswerService struct {
 	answerRepo   repository.ForumAnswerRepository
 	questionRepo repository.ForumQuestionRepository
 	voteRepo     repository.ForumAnswerVoteRepository
+	mailer       AnswerMailer
+	preferences  AnswerNotificationPreferences
 }
 
 func NewAnswerService(answerRepo repository.ForumAnswerRepository, questionRepo repository.ForumQuestionRepository, voteRepo repository.ForumAnswerVoteRepository) *AnswerService {
@@ -32,11 +47,28 @@ func (s *AnswerService) SetRepositories(answerRepo repository.ForumAnswerReposit
 	s.voteRepo = voteRepo
 }
 
+// SetMailer configures the mailer used to tell question authors about new answers.
+func (s *AnswerService) SetMailer(mailer AnswerMailer) {
+	if s == nil {
+		return
+	}
+	s.mailer = mailer
+}
+
+// SetNotificationPreferences configures which question authors want answer emails.
+func (s *AnswerService) SetNotificationPreferences(preferences AnswerNotificationPreferences) {
+	if s == nil {
+		return
+	}
+	s.preferences = preferences
+}
+
 func (s *AnswerService) Create(questionID, authorID uint, req models.CreateForumAnswerRequest) (*models.ForumAnswer, error) {
 	if s == nil || s.answerRepo == nil || s.questionRepo == nil {
 		return nil, errors.New("answer service not configured")
 	}
-	if _, err := s.questionRepo.GetByID(questionID); err != nil {
+	question, err := s.questionRepo.GetByID(questionID)
+	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, ErrQuestionNotFound
 		}
@@ -57,7 +89,48 @@ func (s *AnswerService) Create(questionID, authorID uint, req models.CreateForum
 	if err := s.answerRepo.Create(answer); err != nil {
 		return nil, fmt.Errorf("failed to create answer: %w", err)
 	}
-	return s.answerRepo.GetByID(answer.ID)
+
+	created, err := s.answerRepo.GetByID(answer.ID)
+	if err != nil {
+		return nil, err
+	}
+	go s.notifyQuestionAuthor(*question, *created)
+	return created, nil
+}
+
+// notifyQuestionAuthor emails the question author about a new answer using the
+// theme's forum_answer_notification template.
+func (s *AnswerService) notifyQuestionAuthor(question models.ForumQuestion, answer models.ForumAnswer) {
+	if s.mailer == nil || !s.mailer.Enabled() {
+		return
+	}
+	if question.AuthorID == answer.AuthorID || question.Author.Email == "" {
+		return
+	}
+	if s.preferences != nil && !s.preferences.Allows(question.AuthorID, models.NotificationForumAnswer, models.NotificationChannelEmail) {
+		return
+	}
+
+	questionPath := fmt.Sprintf("/forum/%s", question.Slug)
+	if question.Slug == "" {
+		questionPath = fmt.Sprintf("/forum/%d", question.ID)
+	}
+
+	data := map[string]interface{}{
+		"Username":      question.Author.Username,
+		"QuestionTitle": question.Title,
+		"QuestionPath":  questionPath,
+		"AnswererName":  answer.Author.Username,
+		"AnswerContent": answer.Content,
+	}
+	subject := fmt.Sprintf("New answer to \"%s\"", question.Title)
+	if err := s.mailer.SendTemplate(question.Author.Email, service.EmailTemplateForumAnswer, subject, data); err != nil {
+		logger.Warn("Failed to send forum answer notification", map[string]interface{}{
+			"answer_id":   answer.ID,
+			"question_id": question.ID,
+			"error":       err.Error(),
+		})
+	}
 }
 
 func (s *AnswerService) Update(id uint, req models.UpdateForumAnswerRequest, userID uint, canManageAll bool) (*models.ForumAnswer, error) {
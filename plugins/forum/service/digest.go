@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"constructor-script-backend/internal/models"
+)
+
+// digestAnswerLimit bounds the answers read for one digest; they are grouped
+// by question before the section limit applies.
+const digestAnswerLimit = 200
+
+// DigestSection lists the questions the user asked or answered that got new
+// answers from others between since and until.
+func (s *AnswerService) DigestSection(ctx context.Context, userID uint, since, until time.Time, limit int) (models.DigestSection, error) {
+	section := models.DigestSection{Title: "Replies in your forum threads"}
+	if s == nil || s.answerRepo == nil {
+		return section, errors.New("answer service not configured")
+	}
+	if err := ctx.Err(); err != nil {
+		return section, err
+	}
+
+	answers, err := s.answerRepo.ListRepliesForUser(userID, since, until, digestAnswerLimit)
+	if err != nil {
+		return section, fmt.Errorf("failed to load forum replies: %w", err)
+	}
+
+	// Answers arrive newest first, so each question is listed with its latest reply.
+	counts := make(map[uint]int)
+	var latest []models.ForumAnswer
+	for _, answer := range answers {
+		if answer.Question.ID == 0 {
+			continue
+		}
+		if counts[answer.QuestionID] == 0 {
+			latest = append(latest, answer)
+		}
+		counts[answer.QuestionID]++
+	}
+
+	for _, answer := range latest {
+		if len(section.Items) >= limit {
+			break
+		}
+		question := answer.Question
+		path := fmt.Sprintf("/forum/%s", question.Slug)
+		if question.Slug == "" {
+			path = fmt.Sprintf("/forum/%d", question.ID)
+		}
+		summary := fmt.Sprintf("New answer from %s", answer.Author.Username)
+		if count := counts[question.ID]; count > 1 {
+			summary = fmt.Sprintf("%d new answers, the latest from %s", count, answer.Author.Username)
+		}
+		section.Items = append(section.Items, models.DigestItem{Title: question.Title, Path: path, Summary: summary})
+	}
+	return section, nil
+}
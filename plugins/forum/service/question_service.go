@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strings"
@@ -8,8 +9,10 @@ import (
 
 	"gorm.io/gorm"
 
+	"constructor-script-backend/internal/events"
 	"constructor-script-backend/internal/models"
 	"constructor-script-backend/internal/repository"
+	"constructor-script-backend/pkg/cache"
 	"constructor-script-backend/pkg/utils"
 )
 
@@ -17,6 +20,8 @@ type QuestionService struct {
 	questionRepo repository.ForumQuestionRepository
 	categoryRepo repository.ForumCategoryRepository
 	voteRepo     repository.ForumQuestionVoteRepository
+	viewBuffer   *cache.Cache
+	events       *events.Bus
 }
 
 func NewQuestionService(
@@ -42,6 +47,14 @@ func (s *QuestionService) SetRepositories(
 	s.voteRepo = voteRepo
 }
 
+// SetEventBus configures the bus used to announce new questions.
+func (s *QuestionService) SetEventBus(bus *events.Bus) {
+	if s == nil {
+		return
+	}
+	s.events = bus
+}
+
 type QuestionListOptions struct {
 	Search       string
 	AuthorID     *uint
@@ -85,6 +98,41 @@ func (s *QuestionService) List(page, limit int, opts QuestionListOptions) ([]mod
 	return s.questionRepo.List(offset, limit, search, opts.AuthorID, categoryID, status)
 }
 
+// Batches hands the questions matching opts to fn in batches, for exports.
+func (s *QuestionService) Batches(opts QuestionListOptions, fn func([]models.ForumQuestion) error) error {
+	if s == nil || s.questionRepo == nil {
+		return errors.New("question repository not configured")
+	}
+
+	categoryID := opts.CategoryID
+	if categoryID == nil {
+		if slug := strings.TrimSpace(opts.CategorySlug); slug != "" {
+			if s.categoryRepo == nil {
+				return errors.New("category repository not configured")
+			}
+			category, err := s.categoryRepo.GetBySlug(slug)
+			if err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return nil
+				}
+				return err
+			}
+			categoryID = &category.ID
+		}
+	}
+
+	search := strings.TrimSpace(opts.Search)
+	status := strings.TrimSpace(strings.ToLower(opts.Status))
+	return s.questionRepo.Batches(search, opts.AuthorID, categoryID, status, fn)
+}
+
+func (s *QuestionService) ListForSitemap() ([]models.ForumQuestion, error) {
+	if s == nil || s.questionRepo == nil {
+		return nil, errors.New("question repository not configured")
+	}
+	return s.questionRepo.ListForSitemap()
+}
+
 func (s *QuestionService) GetByID(id uint) (*models.ForumQuestion, error) {
 	if s == nil || s.questionRepo == nil {
 		return nil, errors.New("question repository not configured")
@@ -96,8 +144,8 @@ func (s *QuestionService) GetByID(id uint) (*models.ForumQuestion, error) {
 		}
 		return nil, err
 	}
-	if err := s.questionRepo.IncrementViews(id); err != nil {
-		return nil, fmt.Errorf("failed to update question views: %w", err)
+	if err := s.recordView(id); err != nil {
+		return nil, err
 	}
 	question.Views++
 	return question, nil
@@ -128,8 +176,8 @@ func (s *QuestionService) GetBySlug(slug string) (*models.ForumQuestion, error)
 		}
 		return nil, err
 	}
-	if err := s.questionRepo.IncrementViews(question.ID); err != nil {
-		return nil, fmt.Errorf("failed to update question views: %w", err)
+	if err := s.recordView(question.ID); err != nil {
+		return nil, err
 	}
 	question.Views++
 	return question, nil
@@ -169,6 +217,15 @@ func (s *QuestionService) Create(req models.CreateForumQuestionRequest, authorID
 	if err := s.questionRepo.Create(question); err != nil {
 		return nil, fmt.Errorf("failed to create question: %w", err)
 	}
+
+	s.events.Publish(context.Background(), events.ForumQuestionCreated, map[string]interface{}{
+		"id":          question.ID,
+		"slug":        question.Slug,
+		"title":       question.Title,
+		"author_id":   question.AuthorID,
+		"category_id": question.CategoryID,
+	})
+
 	return s.questionRepo.GetByID(question.ID)
 }
 
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"constructor-script-backend/pkg/cache"
+)
+
+// questionViewCounters is the cache counter set buffering question views, keyed by
+// question ID.
+const questionViewCounters = "forum_question_views"
+
+// SetViewBuffer makes question views accumulate in c until FlushViews writes them,
+// instead of updating the question on every view. A nil cache restores direct writes.
+func (s *QuestionService) SetViewBuffer(c *cache.Cache) {
+	if s == nil {
+		return
+	}
+	s.viewBuffer = c
+}
+
+func (s *QuestionService) recordView(id uint) error {
+	if s.viewBuffer != nil {
+		if err := s.viewBuffer.AddCounter(questionViewCounters, strconv.FormatUint(uint64(id), 10), 1); err == nil {
+			return nil
+		}
+	}
+	if err := s.questionRepo.IncrementViews(id); err != nil {
+		return fmt.Errorf("failed to update question views: %w", err)
+	}
+	return nil
+}
+
+// FlushViews writes the buffered question views to the database. Counts that could
+// not be written are put back for the next flush.
+func (s *QuestionService) FlushViews(ctx context.Context) error {
+	if s == nil || s.viewBuffer == nil || s.questionRepo == nil {
+		return nil
+	}
+
+	buffered, err := s.viewBuffer.DrainCounters(questionViewCounters)
+	if err != nil {
+		return fmt.Errorf("drain question views: %w", err)
+	}
+
+	counts := make(map[uint]int64, len(buffered))
+	for field, views := range buffered {
+		id, err := strconv.ParseUint(field, 10, 64)
+		if err != nil || id == 0 || views <= 0 {
+			continue
+		}
+		counts[uint(id)] += views
+	}
+	if len(counts) == 0 {
+		return nil
+	}
+
+	err = ctx.Err()
+	if err == nil {
+		err = s.questionRepo.AddViews(counts)
+	}
+	if err != nil {
+		for id, views := range counts {
+			s.viewBuffer.AddCounter(questionViewCounters, strconv.FormatUint(uint64(id), 10), views)
+		}
+		return fmt.Errorf("write question views: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package forum
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/plugin/migrations"
+)
+
+// Uninstall drops the forum tables. It runs only when an administrator removes the
+// plugin with purge enabled.
+func (f *Feature) Uninstall() error {
+	if f == nil || f.host == nil {
+		return fmt.Errorf("feature host is not configured")
+	}
+
+	db := f.host.Database()
+	if db == nil {
+		return fmt.Errorf("database is not configured")
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		// Children first so foreign keys never block the drop.
+		if err := tx.Migrator().DropTable(
+			&models.ForumAnswerVote{},
+			&models.ForumQuestionVote{},
+			&models.ForumAnswer{},
+			&models.ForumQuestion{},
+			&models.ForumCategory{},
+		); err != nil {
+			return fmt.Errorf("failed to drop forum tables: %w", err)
+		}
+		return migrations.Forget(tx, "forum")
+	})
+}
@@ -0,0 +1,14 @@
+package api
+
+const Namespace = "newsletter"
+
+const (
+	ServiceSubscriber = "subscriber"
+	ServiceCampaign   = "campaign"
+)
+
+const (
+	HandlerSubscriber = "subscriber"
+	HandlerCampaign   = "campaign"
+	HandlerPublic     = "public"
+)
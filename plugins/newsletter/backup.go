@@ -0,0 +1,14 @@
+package newsletter
+
+import (
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/plugin/backup"
+)
+
+func init() {
+	backup.Register("newsletter",
+		&models.NewsletterSubscriber{},
+		&models.NewsletterCampaign{},
+		&models.NewsletterDelivery{},
+	)
+}
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/pkg/apierror"
+	newsletterservice "constructor-script-backend/plugins/newsletter/service"
+)
+
+type CampaignHandler struct {
+	service *newsletterservice.CampaignService
+}
+
+func NewCampaignHandler(service *newsletterservice.CampaignService) *CampaignHandler {
+	return &CampaignHandler{service: service}
+}
+
+func (h *CampaignHandler) SetService(service *newsletterservice.CampaignService) {
+	if h == nil {
+		return
+	}
+	h.service = service
+}
+
+func (h *CampaignHandler) ensureService(c *gin.Context) bool {
+	if h == nil || h.service == nil {
+		if c != nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "newsletter plugin is not active"})
+		}
+		return false
+	}
+	return true
+}
+
+func (h *CampaignHandler) List(c *gin.Context) {
+	if !h.ensureService(c) {
+		return
+	}
+
+	campaigns, err := h.service.List()
+	if err != nil {
+		c.JSON(newsletterErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"campaigns": campaigns})
+}
+
+func (h *CampaignHandler) Get(c *gin.Context) {
+	if !h.ensureService(c) {
+		return
+	}
+
+	id, ok := parseCampaignID(c)
+	if !ok {
+		return
+	}
+
+	campaign, err := h.service.GetByID(id)
+	if err != nil {
+		c.JSON(newsletterErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"campaign": campaign})
+}
+
+func (h *CampaignHandler) Create(c *gin.Context) {
+	if !h.ensureService(c) {
+		return
+	}
+
+	var req models.CreateNewsletterCampaignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BindError(c, err)
+		return
+	}
+
+	campaign, err := h.service.Create(req)
+	if err != nil {
+		c.JSON(newsletterErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"campaign": campaign})
+}
+
+func (h *CampaignHandler) Update(c *gin.Context) {
+	if !h.ensureService(c) {
+		return
+	}
+
+	id, ok := parseCampaignID(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateNewsletterCampaignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BindError(c, err)
+		return
+	}
+
+	campaign, err := h.service.Update(id, req)
+	if err != nil {
+		c.JSON(newsletterErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"campaign": campaign})
+}
+
+func (h *CampaignHandler) Delete(c *gin.Context) {
+	if !h.ensureService(c) {
+		return
+	}
+
+	id, ok := parseCampaignID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(id); err != nil {
+		c.JSON(newsletterErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "campaign deleted"})
+}
+
+func (h *CampaignHandler) Preview(c *gin.Context) {
+	if !h.ensureService(c) {
+		return
+	}
+
+	id, ok := parseCampaignID(c)
+	if !ok {
+		return
+	}
+
+	message, err := h.service.Preview(id)
+	if err != nil {
+		c.JSON(newsletterErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"subject": message.Subject, "html": message.HTML, "text": message.Text})
+}
+
+func (h *CampaignHandler) Send(c *gin.Context) {
+	if !h.ensureService(c) {
+		return
+	}
+
+	id, ok := parseCampaignID(c)
+	if !ok {
+		return
+	}
+
+	campaign, err := h.service.Send(id)
+	if err != nil {
+		c.JSON(newsletterErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"campaign": campaign})
+}
+
+func parseCampaignID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid campaign id"})
+		return 0, false
+	}
+	return uint(id), true
+}
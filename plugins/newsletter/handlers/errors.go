@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	newsletterservice "constructor-script-backend/plugins/newsletter/service"
+)
+
+func newsletterErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, newsletterservice.ErrSubscriberNotFound),
+		errors.Is(err, newsletterservice.ErrCampaignNotFound),
+		errors.Is(err, newsletterservice.ErrTrackedLinkNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, newsletterservice.ErrInvalidEmail),
+		errors.Is(err, newsletterservice.ErrInvalidStatus),
+		errors.Is(err, newsletterservice.ErrInvalidCampaign),
+		errors.Is(err, newsletterservice.ErrInvalidToken):
+		return http.StatusBadRequest
+	case errors.Is(err, newsletterservice.ErrCampaignNotEditable),
+		errors.Is(err, newsletterservice.ErrNoRecipients):
+		return http.StatusConflict
+	case errors.Is(err, newsletterservice.ErrEmailUnavailable),
+		errors.Is(err, newsletterservice.ErrRepositoryNotReady):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
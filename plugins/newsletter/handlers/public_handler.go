@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"constructor-script-backend/internal/models"
+	newsletterservice "constructor-script-backend/plugins/newsletter/service"
+)
+
+// transparentGIF is the 1x1 image served by the open tracking endpoint.
+var transparentGIF = []byte{
+	0x47, 0x49, 0x46, 0x38, 0x39, 0x61, 0x01, 0x00, 0x01, 0x00, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0xff, 0xff, 0xff, 0x21, 0xf9, 0x04, 0x01, 0x00, 0x00, 0x00, 0x00, 0x2c, 0x00, 0x00, 0x00, 0x00,
+	0x01, 0x00, 0x01, 0x00, 0x00, 0x02, 0x02, 0x44, 0x01, 0x00, 0x3b,
+}
+
+type PublicHandler struct {
+	subscribers *newsletterservice.SubscriberService
+	campaigns   *newsletterservice.CampaignService
+}
+
+func NewPublicHandler(subscribers *newsletterservice.SubscriberService, campaigns *newsletterservice.CampaignService) *PublicHandler {
+	return &PublicHandler{subscribers: subscribers, campaigns: campaigns}
+}
+
+func (h *PublicHandler) SetServices(subscribers *newsletterservice.SubscriberService, campaigns *newsletterservice.CampaignService) {
+	if h == nil {
+		return
+	}
+	h.subscribers = subscribers
+	h.campaigns = campaigns
+}
+
+// Subscribe accepts JSON or a regular form post, so themes can embed a plain HTML form.
+func (h *PublicHandler) Subscribe(c *gin.Context) {
+	if h == nil || h.subscribers == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "newsletter plugin is not active"})
+		return
+	}
+
+	var req models.NewsletterSubscribeRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "email is required"})
+		return
+	}
+
+	if _, err := h.subscribers.Subscribe(req, "form"); err != nil {
+		c.JSON(newsletterErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Check your inbox to confirm your subscription."})
+}
+
+func (h *PublicHandler) Confirm(c *gin.Context) {
+	if h == nil || h.subscribers == nil {
+		c.Redirect(http.StatusSeeOther, "/")
+		return
+	}
+
+	if _, err := h.subscribers.Confirm(c.Param("token")); err != nil {
+		c.Redirect(http.StatusSeeOther, "/?newsletter=invalid")
+		return
+	}
+	c.Redirect(http.StatusSeeOther, "/?newsletter=confirmed")
+}
+
+// Unsubscribe handles both the link in campaign emails (GET) and one-click
+// unsubscribe requests from mail clients (POST).
+func (h *PublicHandler) Unsubscribe(c *gin.Context) {
+	if h == nil || h.subscribers == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "newsletter plugin is not active"})
+		return
+	}
+
+	err := h.subscribers.Unsubscribe(c.Param("token"))
+	if c.Request.Method == http.MethodPost {
+		if err != nil {
+			c.JSON(newsletterErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "unsubscribed"})
+		return
+	}
+
+	if err != nil {
+		c.Redirect(http.StatusSeeOther, "/?newsletter=invalid")
+		return
+	}
+	c.Redirect(http.StatusSeeOther, "/?newsletter=unsubscribed")
+}
+
+// Open serves the tracking pixel. The image is returned even when tracking fails so
+// mail clients never show a broken image.
+func (h *PublicHandler) Open(c *gin.Context) {
+	if h != nil && h.campaigns != nil {
+		h.campaigns.RecordOpen(strings.TrimSuffix(c.Param("token"), ".gif"))
+	}
+
+	c.Header("Cache-Control", "no-store, no-cache, must-revalidate, private")
+	c.Data(http.StatusOK, "image/gif", transparentGIF)
+}
+
+func (h *PublicHandler) Click(c *gin.Context) {
+	if h == nil || h.campaigns == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "newsletter plugin is not active"})
+		return
+	}
+
+	index, err := strconv.Atoi(c.Param("index"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "link not found"})
+		return
+	}
+
+	target, err := h.campaigns.ResolveClick(c.Param("token"), index)
+	if err != nil {
+		c.JSON(newsletterErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Redirect(http.StatusFound, target)
+}
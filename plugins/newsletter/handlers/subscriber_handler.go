@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/pkg/apierror"
+	newsletterservice "constructor-script-backend/plugins/newsletter/service"
+)
+
+type SubscriberHandler struct {
+	service *newsletterservice.SubscriberService
+}
+
+func NewSubscriberHandler(service *newsletterservice.SubscriberService) *SubscriberHandler {
+	return &SubscriberHandler{service: service}
+}
+
+func (h *SubscriberHandler) SetService(service *newsletterservice.SubscriberService) {
+	if h == nil {
+		return
+	}
+	h.service = service
+}
+
+func (h *SubscriberHandler) ensureService(c *gin.Context) bool {
+	if h == nil || h.service == nil {
+		if c != nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "newsletter plugin is not active"})
+		}
+		return false
+	}
+	return true
+}
+
+func (h *SubscriberHandler) List(c *gin.Context) {
+	if !h.ensureService(c) {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+
+	subscribers, total, err := h.service.List(c.Query("status"), c.Query("search"), page, limit)
+	if err != nil {
+		c.JSON(newsletterErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"subscribers": subscribers, "total": total})
+}
+
+// Create adds an address on behalf of a visitor. It goes through the same double
+// opt-in as the public form.
+func (h *SubscriberHandler) Create(c *gin.Context) {
+	if !h.ensureService(c) {
+		return
+	}
+
+	var req models.NewsletterSubscribeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BindError(c, err)
+		return
+	}
+
+	subscriber, err := h.service.Subscribe(req, "admin")
+	if err != nil {
+		c.JSON(newsletterErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"subscriber": subscriber})
+}
+
+func (h *SubscriberHandler) Update(c *gin.Context) {
+	if !h.ensureService(c) {
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid subscriber id"})
+		return
+	}
+
+	var req models.UpdateNewsletterSubscriberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BindError(c, err)
+		return
+	}
+
+	subscriber, err := h.service.Update(uint(id), req)
+	if err != nil {
+		c.JSON(newsletterErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"subscriber": subscriber})
+}
+
+func (h *SubscriberHandler) Delete(c *gin.Context) {
+	if !h.ensureService(c) {
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid subscriber id"})
+		return
+	}
+
+	if err := h.service.Delete(uint(id)); err != nil {
+		c.JSON(newsletterErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "subscriber deleted"})
+}
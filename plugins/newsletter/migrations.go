@@ -0,0 +1,14 @@
+package newsletter
+
+import (
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/plugin/migrations"
+)
+
+func init() {
+	migrations.RegisterTables("newsletter",
+		&models.NewsletterSubscriber{},
+		&models.NewsletterCampaign{},
+		&models.NewsletterDelivery{},
+	)
+}
@@ -0,0 +1,129 @@
+package newsletter
+
+import (
+	"fmt"
+
+	"constructor-script-backend/internal/plugin/host"
+	"constructor-script-backend/internal/plugin/registry"
+	pluginruntime "constructor-script-backend/internal/plugin/runtime"
+	newsletterapi "constructor-script-backend/plugins/newsletter/api"
+	newsletterhandlers "constructor-script-backend/plugins/newsletter/handlers"
+	newsletterservice "constructor-script-backend/plugins/newsletter/service"
+)
+
+func init() {
+	registry.Register("newsletter", NewFeature)
+}
+
+type Feature struct {
+	host host.Host
+}
+
+func NewFeature(h host.Host) (pluginruntime.Feature, error) {
+	if h == nil {
+		return nil, fmt.Errorf("host is required")
+	}
+	return &Feature{host: h}, nil
+}
+
+func (f *Feature) Activate() error {
+	if f == nil || f.host == nil {
+		return fmt.Errorf("feature host is not configured")
+	}
+
+	repos := f.host.Repositories()
+	if repos == nil {
+		return fmt.Errorf("repository access is not configured")
+	}
+	repo := repos.Newsletter()
+	if repo == nil {
+		return fmt.Errorf("newsletter repository is not configured")
+	}
+
+	coreServices := f.host.CoreServices()
+	var mailer newsletterservice.Mailer
+	if email := coreServices.Email(); email != nil {
+		mailer = email
+	}
+	var settings newsletterservice.SettingsSource
+	if plugins := coreServices.Plugins(); plugins != nil {
+		settings = plugins
+	}
+
+	servicesRegistry := f.host.Services(newsletterapi.Namespace)
+	handlersRegistry := f.host.Handlers(newsletterapi.Namespace)
+
+	var subscriberService *newsletterservice.SubscriberService
+	if existing, ok := servicesRegistry.Get(newsletterapi.ServiceSubscriber).(*newsletterservice.SubscriberService); ok {
+		subscriberService = existing
+	}
+	if subscriberService == nil {
+		subscriberService = newsletterservice.NewSubscriberService(repo, mailer)
+	} else {
+		subscriberService.SetRepository(repo)
+		subscriberService.SetMailer(mailer)
+	}
+	servicesRegistry.Set(newsletterapi.ServiceSubscriber, subscriberService)
+
+	var campaignService *newsletterservice.CampaignService
+	if existing, ok := servicesRegistry.Get(newsletterapi.ServiceCampaign).(*newsletterservice.CampaignService); ok {
+		campaignService = existing
+	}
+	if campaignService == nil {
+		campaignService = newsletterservice.NewCampaignService(repo, repos.Post(), mailer, f.host.Scheduler(), settings)
+	} else {
+		campaignService.SetDependencies(repo, repos.Post(), mailer, f.host.Scheduler(), settings)
+	}
+	servicesRegistry.Set(newsletterapi.ServiceCampaign, campaignService)
+
+	if handler, ok := handlersRegistry.Get(newsletterapi.HandlerSubscriber).(*newsletterhandlers.SubscriberHandler); ok {
+		handler.SetService(subscriberService)
+	} else {
+		handlersRegistry.Set(newsletterapi.HandlerSubscriber, newsletterhandlers.NewSubscriberHandler(subscriberService))
+	}
+
+	if handler, ok := handlersRegistry.Get(newsletterapi.HandlerCampaign).(*newsletterhandlers.CampaignHandler); ok {
+		handler.SetService(campaignService)
+	} else {
+		handlersRegistry.Set(newsletterapi.HandlerCampaign, newsletterhandlers.NewCampaignHandler(campaignService))
+	}
+
+	if handler, ok := handlersRegistry.Get(newsletterapi.HandlerPublic).(*newsletterhandlers.PublicHandler); ok {
+		handler.SetServices(subscriberService, campaignService)
+	} else {
+		handlersRegistry.Set(newsletterapi.HandlerPublic, newsletterhandlers.NewPublicHandler(subscriberService, campaignService))
+	}
+
+	campaignService.ResumeSending()
+
+	return nil
+}
+
+// Deactivate detaches the services from the handlers, which keep answering with 503
+// until the plugin is activated again. Campaigns that are being sent pause and resume
+// on the next activation.
+func (f *Feature) Deactivate() error {
+	if f == nil || f.host == nil {
+		return nil
+	}
+
+	handlersRegistry := f.host.Handlers(newsletterapi.Namespace)
+	if handler, _ := handlersRegistry.Get(newsletterapi.HandlerSubscriber).(*newsletterhandlers.SubscriberHandler); handler != nil {
+		handler.SetService(nil)
+	}
+	if handler, _ := handlersRegistry.Get(newsletterapi.HandlerCampaign).(*newsletterhandlers.CampaignHandler); handler != nil {
+		handler.SetService(nil)
+	}
+	if handler, _ := handlersRegistry.Get(newsletterapi.HandlerPublic).(*newsletterhandlers.PublicHandler); handler != nil {
+		handler.SetServices(nil, nil)
+	}
+
+	servicesRegistry := f.host.Services(newsletterapi.Namespace)
+	if campaignService, _ := servicesRegistry.Get(newsletterapi.ServiceCampaign).(*newsletterservice.CampaignService); campaignService != nil {
+		campaignService.SetDependencies(nil, nil, nil, nil, nil)
+	}
+	servicesRegistry.Set(newsletterapi.ServiceSubscriber, nil)
+	servicesRegistry.Set(newsletterapi.ServiceCampaign, nil)
+
+	return nil
+}
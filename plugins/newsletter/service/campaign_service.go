@@ -0,0 +1,546 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"html/template"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"constructor-script-backend/internal/background"
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/repository"
+	"constructor-script-backend/internal/service"
+	"constructor-script-backend/pkg/logger"
+)
+
+const (
+	defaultBatchSize  = 50
+	defaultBatchDelay = 10 * time.Second
+	minBatchDelay     = time.Second
+	pausedRetryDelay  = 5 * time.Minute
+	maxRecentPosts    = 10
+
+	// Campaigns are rendered once with these placeholders, which are swapped for the
+	// recipient's tokens when each email is sent.
+	deliveryTokenPlaceholder    = "NEWSLETTERDELIVERYTOKEN"
+	unsubscribeTokenPlaceholder = "NEWSLETTERUNSUBSCRIBETOKEN"
+
+	settingBatchSize  = "batch_size"
+	settingBatchDelay = "batch_delay_seconds"
+)
+
+var trackedLinkPattern = regexp.MustCompile(`href="(https?://[^"]+)"`)
+
+// SettingsSource reads the plugin settings, so batching changes apply to the next batch
+// without reactivating the plugin.
+type SettingsSource interface {
+	Settings(slug string) (map[string]interface{}, error)
+}
+
+type CampaignService struct {
+	repo      repository.NewsletterRepository
+	postRepo  repository.PostRepository
+	mailer    Mailer
+	scheduler *background.Scheduler
+	settings  SettingsSource
+}
+
+func NewCampaignService(repo repository.NewsletterRepository, postRepo repository.PostRepository, mailer Mailer, scheduler *background.Scheduler, settings SettingsSource) *CampaignService {
+	return &CampaignService{
+		repo:      repo,
+		postRepo:  postRepo,
+		mailer:    mailer,
+		scheduler: scheduler,
+		settings:  settings,
+	}
+}
+
+func (s *CampaignService) SetDependencies(repo repository.NewsletterRepository, postRepo repository.PostRepository, mailer Mailer, scheduler *background.Scheduler, settings SettingsSource) {
+	if s == nil {
+		return
+	}
+	s.repo = repo
+	s.postRepo = postRepo
+	s.mailer = mailer
+	s.scheduler = scheduler
+	s.settings = settings
+}
+
+func (s *CampaignService) List() ([]models.NewsletterCampaign, error) {
+	if s == nil || s.repo == nil {
+		return nil, ErrRepositoryNotReady
+	}
+
+	campaigns, err := s.repo.ListCampaigns()
+	if err != nil {
+		return nil, err
+	}
+	for i := range campaigns {
+		if campaigns[i].Status == models.NewsletterCampaignDraft {
+			continue
+		}
+		stats, err := s.repo.CampaignStats(campaigns[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		campaigns[i].Stats = stats
+	}
+	return campaigns, nil
+}
+
+func (s *CampaignService) GetByID(id uint) (*models.NewsletterCampaign, error) {
+	if s == nil || s.repo == nil {
+		return nil, ErrRepositoryNotReady
+	}
+
+	campaign, err := s.repo.GetCampaign(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCampaignNotFound
+		}
+		return nil, err
+	}
+
+	stats, err := s.repo.CampaignStats(campaign.ID)
+	if err != nil {
+		return nil, err
+	}
+	campaign.Stats = stats
+	return campaign, nil
+}
+
+func (s *CampaignService) Create(req models.CreateNewsletterCampaignRequest) (*models.NewsletterCampaign, error) {
+	if s == nil || s.repo == nil {
+		return nil, ErrRepositoryNotReady
+	}
+
+	campaign := &models.NewsletterCampaign{
+		Subject:     strings.TrimSpace(req.Subject),
+		Content:     req.Content,
+		RecentPosts: req.RecentPosts,
+		Status:      models.NewsletterCampaignDraft,
+	}
+	if err := validateCampaign(campaign); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateCampaign(campaign); err != nil {
+		return nil, err
+	}
+	return campaign, nil
+}
+
+func (s *CampaignService) Update(id uint, req models.UpdateNewsletterCampaignRequest) (*models.NewsletterCampaign, error) {
+	campaign, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if campaign.Status != models.NewsletterCampaignDraft {
+		return nil, ErrCampaignNotEditable
+	}
+
+	if req.Subject != nil {
+		campaign.Subject = strings.TrimSpace(*req.Subject)
+	}
+	if req.Content != nil {
+		campaign.Content = *req.Content
+	}
+	if req.RecentPosts != nil {
+		campaign.RecentPosts = *req.RecentPosts
+	}
+	if err := validateCampaign(campaign); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateCampaign(campaign); err != nil {
+		return nil, err
+	}
+	return campaign, nil
+}
+
+// Delete removes a campaign and its delivery log. Campaigns that are still being sent
+// cannot be deleted.
+func (s *CampaignService) Delete(id uint) error {
+	campaign, err := s.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if campaign.Status == models.NewsletterCampaignSending {
+		return ErrCampaignNotEditable
+	}
+	return s.repo.DeleteCampaign(id)
+}
+
+// Preview renders the campaign as a recipient would see it, without tracking tokens.
+func (s *CampaignService) Preview(id uint) (*service.EmailMessage, error) {
+	campaign, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if campaign.Status == models.NewsletterCampaignDraft {
+		if err := s.render(campaign); err != nil {
+			return nil, err
+		}
+	}
+
+	replacer := strings.NewReplacer(deliveryTokenPlaceholder, "preview", unsubscribeTokenPlaceholder, "preview")
+	return &service.EmailMessage{
+		Subject: campaign.RenderedSubject,
+		HTML:    replacer.Replace(campaign.RenderedHTML),
+		Text:    replacer.Replace(campaign.RenderedText),
+	}, nil
+}
+
+// Send freezes the rendered campaign, queues one delivery per confirmed subscriber and
+// starts sending them in batches.
+func (s *CampaignService) Send(id uint) (*models.NewsletterCampaign, error) {
+	campaign, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if campaign.Status != models.NewsletterCampaignDraft {
+		return nil, ErrCampaignNotEditable
+	}
+	if s.mailer == nil || !s.mailer.Enabled() {
+		return nil, ErrEmailUnavailable
+	}
+
+	if err := s.render(campaign); err != nil {
+		return nil, err
+	}
+
+	var deliveries []models.NewsletterDelivery
+	var afterID uint
+	for {
+		subscribers, err := s.repo.ListConfirmedSubscribersAfter(afterID, 500)
+		if err != nil {
+			return nil, err
+		}
+		if len(subscribers) == 0 {
+			break
+		}
+
+		for _, subscriber := range subscribers {
+			token, err := generateToken()
+			if err != nil {
+				return nil, err
+			}
+			deliveries = append(deliveries, models.NewsletterDelivery{
+				CampaignID:   campaign.ID,
+				SubscriberID: subscriber.ID,
+				Email:        subscriber.Email,
+				Token:        token,
+				Status:       models.NewsletterDeliveryPending,
+			})
+		}
+		afterID = subscribers[len(subscribers)-1].ID
+	}
+	if len(deliveries) == 0 {
+		return nil, ErrNoRecipients
+	}
+
+	// The campaign only leaves the draft state together with its deliveries, and
+	// only once, however many sends race for it.
+	now := time.Now().UTC()
+	campaign.Status = models.NewsletterCampaignSending
+	campaign.StartedAt = &now
+	started, err := s.repo.StartCampaign(campaign, deliveries)
+	if err != nil {
+		return nil, err
+	}
+	if !started {
+		return nil, ErrCampaignNotEditable
+	}
+
+	s.scheduleBatch(campaign.ID, 0)
+	return s.GetByID(campaign.ID)
+}
+
+// ResumeSending restarts campaigns that were interrupted by a restart.
+func (s *CampaignService) ResumeSending() {
+	if s == nil || s.repo == nil {
+		return
+	}
+
+	campaigns, err := s.repo.ListCampaignsByStatus(models.NewsletterCampaignSending)
+	if err != nil {
+		logger.Error(err, "Failed to load newsletter campaigns in progress", nil)
+		return
+	}
+	for _, campaign := range campaigns {
+		s.scheduleBatch(campaign.ID, 0)
+	}
+}
+
+// RecordOpen marks the delivery identified by token as opened.
+func (s *CampaignService) RecordOpen(token string) {
+	if s == nil || s.repo == nil {
+		return
+	}
+	delivery, err := s.repo.GetDeliveryByToken(strings.TrimSpace(token))
+	if err != nil {
+		return
+	}
+	if err := s.repo.MarkDeliveryOpened(delivery.ID, time.Now().UTC()); err != nil {
+		logger.Warn("Failed to record newsletter open", map[string]interface{}{"delivery_id": delivery.ID, "error": err.Error()})
+	}
+}
+
+// ResolveClick records a click on the index-th tracked link of the delivery's campaign
+// and returns the original URL. Only URLs that were part of the campaign can be
+// resolved, so the redirect cannot be abused as an open redirect.
+func (s *CampaignService) ResolveClick(token string, index int) (string, error) {
+	if s == nil || s.repo == nil {
+		return "", ErrRepositoryNotReady
+	}
+
+	delivery, err := s.repo.GetDeliveryByToken(strings.TrimSpace(token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", ErrTrackedLinkNotFound
+		}
+		return "", err
+	}
+	campaign, err := s.repo.GetCampaign(delivery.CampaignID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", ErrTrackedLinkNotFound
+		}
+		return "", err
+	}
+	if index < 0 || index >= len(campaign.Links) {
+		return "", ErrTrackedLinkNotFound
+	}
+
+	if err := s.repo.RecordDeliveryClick(delivery.ID, time.Now().UTC()); err != nil {
+		logger.Warn("Failed to record newsletter click", map[string]interface{}{"delivery_id": delivery.ID, "error": err.Error()})
+	}
+	return campaign.Links[index], nil
+}
+
+type campaignPost struct {
+	Title   string
+	Excerpt string
+	Path    string
+}
+
+// render builds the campaign email once, with placeholders for the per-recipient
+// tokens, and rewrites its links to the click tracking endpoint.
+func (s *CampaignService) render(campaign *models.NewsletterCampaign) error {
+	if s.mailer == nil {
+		return ErrEmailUnavailable
+	}
+
+	var posts []campaignPost
+	if campaign.RecentPosts > 0 && s.postRepo != nil {
+		recent, err := s.postRepo.GetRecent(campaign.RecentPosts)
+		if err != nil {
+			return fmt.Errorf("failed to load recent posts: %w", err)
+		}
+		for _, post := range recent {
+			excerpt := strings.TrimSpace(post.Excerpt)
+			if excerpt == "" {
+				excerpt = strings.TrimSpace(post.Description)
+			}
+			posts = append(posts, campaignPost{
+				Title:   post.Title,
+				Excerpt: excerpt,
+				Path:    "/blog/post/" + post.Slug,
+			})
+		}
+	}
+
+	siteURL := s.mailer.SiteURL()
+	unsubscribeURL := siteURL + "/newsletter/unsubscribe/" + unsubscribeTokenPlaceholder
+	message, err := s.mailer.RenderTemplate(service.EmailTemplateNewsletterCampaign, map[string]interface{}{
+		"CampaignSubject": campaign.Subject,
+		"Content":         template.HTML(campaign.Content),
+		"Posts":           posts,
+		"UnsubscribeURL":  unsubscribeURL,
+		"OpenURL":         siteURL + "/newsletter/o/" + deliveryTokenPlaceholder + ".gif",
+	})
+	if err != nil {
+		return err
+	}
+
+	body, links := trackLinks(message.HTML, siteURL+"/newsletter/c/"+deliveryTokenPlaceholder+"/")
+
+	campaign.RenderedSubject = message.Subject
+	if campaign.RenderedSubject == "" {
+		campaign.RenderedSubject = campaign.Subject
+	}
+	campaign.RenderedHTML = body
+	campaign.RenderedText = message.Text + "\n\nUnsubscribe: " + unsubscribeURL
+	campaign.Links = links
+	return nil
+}
+
+// trackLinks points every absolute link in body at prefix+<index> and returns the
+// original URLs by index. Repeated URLs share an index; links that already carry a
+// recipient placeholder, such as the unsubscribe link, are left alone.
+func trackLinks(body, prefix string) (string, models.StringList) {
+	links := models.StringList{}
+	indexes := make(map[string]int)
+
+	rewritten := trackedLinkPattern.ReplaceAllStringFunc(body, func(match string) string {
+		raw := trackedLinkPattern.FindStringSubmatch(match)[1]
+		if strings.Contains(raw, deliveryTokenPlaceholder) || strings.Contains(raw, unsubscribeTokenPlaceholder) {
+			return match
+		}
+
+		target := html.UnescapeString(raw)
+		index, ok := indexes[target]
+		if !ok {
+			index = len(links)
+			indexes[target] = index
+			links = append(links, target)
+		}
+		return `href="` + prefix + strconv.Itoa(index) + `"`
+	})
+
+	return rewritten, links
+}
+
+func (s *CampaignService) scheduleBatch(campaignID uint, delay time.Duration) {
+	run := func(ctx context.Context) error {
+		return s.sendBatch(ctx, campaignID)
+	}
+
+	queue := func() {
+		if s.scheduler == nil {
+			go func() {
+				if err := run(context.Background()); err != nil {
+					logger.Error(err, "Failed to send newsletter batch", map[string]interface{}{"campaign_id": campaignID})
+				}
+			}()
+			return
+		}
+		err := s.scheduler.ScheduleUnique(background.Job{
+			Name:    fmt.Sprintf("newsletter_campaign_%d", campaignID),
+			Timeout: 10 * time.Minute,
+			Run:     run,
+		})
+		if err != nil && !errors.Is(err, background.ErrJobAlreadyScheduled) {
+			logger.Error(err, "Failed to queue newsletter batch", map[string]interface{}{"campaign_id": campaignID})
+		}
+	}
+
+	if delay <= 0 {
+		queue()
+		return
+	}
+	time.AfterFunc(delay, queue)
+}
+
+// sendBatch sends the next batch of pending deliveries and queues the following batch
+// after the configured delay. The campaign is marked sent once nothing is pending.
+func (s *CampaignService) sendBatch(ctx context.Context, campaignID uint) error {
+	if s.repo == nil {
+		return ErrRepositoryNotReady
+	}
+
+	campaign, err := s.repo.GetCampaign(campaignID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if campaign.Status != models.NewsletterCampaignSending {
+		return nil
+	}
+
+	size, delay := s.batching()
+	deliveries, err := s.repo.ListPendingDeliveries(campaign.ID, size)
+	if err != nil {
+		return err
+	}
+	if len(deliveries) == 0 {
+		now := time.Now().UTC()
+		campaign.Status = models.NewsletterCampaignSent
+		campaign.SentAt = &now
+		return s.repo.UpdateCampaign(campaign)
+	}
+	if s.mailer == nil || !s.mailer.Enabled() {
+		// Leave the deliveries pending and check again later.
+		logger.Warn("Newsletter sending paused: email is not configured", map[string]interface{}{"campaign_id": campaign.ID})
+		s.scheduleBatch(campaign.ID, pausedRetryDelay)
+		return nil
+	}
+
+	for i := range deliveries {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		delivery := &deliveries[i]
+		subscriber, err := s.repo.GetSubscriberByID(delivery.SubscriberID)
+		if err != nil || subscriber.Status != models.NewsletterSubscriberConfirmed {
+			// Unsubscribed or deleted while the campaign was going out.
+			delivery.Status = models.NewsletterDeliveryFailed
+			delivery.Error = "subscriber is no longer confirmed"
+		} else {
+			replacer := strings.NewReplacer(deliveryTokenPlaceholder, delivery.Token, unsubscribeTokenPlaceholder, subscriber.UnsubscribeToken)
+			sendErr := s.mailer.SendHTML(delivery.Email, campaign.RenderedSubject, replacer.Replace(campaign.RenderedText), replacer.Replace(campaign.RenderedHTML))
+			if sendErr != nil {
+				delivery.Status = models.NewsletterDeliveryFailed
+				delivery.Error = sendErr.Error()
+			} else {
+				now := time.Now().UTC()
+				delivery.Status = models.NewsletterDeliverySent
+				delivery.Error = ""
+				delivery.SentAt = &now
+			}
+		}
+
+		if err := s.repo.UpdateDelivery(delivery); err != nil {
+			return err
+		}
+	}
+
+	s.scheduleBatch(campaign.ID, delay)
+	return nil
+}
+
+func (s *CampaignService) batching() (int, time.Duration) {
+	size, delay := defaultBatchSize, defaultBatchDelay
+	if s.settings == nil {
+		return size, delay
+	}
+
+	values, err := s.settings.Settings("newsletter")
+	if err != nil {
+		return size, delay
+	}
+	if value, ok := values[settingBatchSize].(float64); ok && value >= 1 {
+		size = int(value)
+	}
+	if value, ok := values[settingBatchDelay].(float64); ok && value >= 0 {
+		delay = time.Duration(value * float64(time.Second))
+	}
+	// The next batch is queued while the current job is still running, so it must
+	// not fire before that job has finished.
+	if delay < minBatchDelay {
+		delay = minBatchDelay
+	}
+	return size, delay
+}
+
+func validateCampaign(campaign *models.NewsletterCampaign) error {
+	if campaign.Subject == "" {
+		return fmt.Errorf("%w: subject is required", ErrInvalidCampaign)
+	}
+	if campaign.RecentPosts < 0 || campaign.RecentPosts > maxRecentPosts {
+		return fmt.Errorf("%w: recent_posts must be between 0 and %d", ErrInvalidCampaign, maxRecentPosts)
+	}
+	if strings.TrimSpace(campaign.Content) == "" && campaign.RecentPosts == 0 {
+		return fmt.Errorf("%w: content or recent posts are required", ErrInvalidCampaign)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTrackLinksRewritesAbsoluteLinks(t *testing.T) {
+	body := `<a href="https://example.com/a?x=1&amp;y=2">A</a>` +
+		`<a href="/relative">R</a>` +
+		`<a href="https://example.com/b">B</a>` +
+		`<a href="https://example.com/a?x=1&amp;y=2">A again</a>` +
+		`<a href="https://site.test/newsletter/unsubscribe/` + unsubscribeTokenPlaceholder + `">Unsubscribe</a>`
+
+	rewritten, links := trackLinks(body, "https://site.test/newsletter/c/TOKEN/")
+
+	if len(links) != 2 {
+		t.Fatalf("expected 2 tracked links, got %v", links)
+	}
+	if links[0] != "https://example.com/a?x=1&y=2" || links[1] != "https://example.com/b" {
+		t.Fatalf("unexpected links: %v", links)
+	}
+	if strings.Count(rewritten, `href="https://site.test/newsletter/c/TOKEN/0"`) != 2 {
+		t.Fatalf("repeated URL should share an index: %s", rewritten)
+	}
+	if !strings.Contains(rewritten, `href="https://site.test/newsletter/c/TOKEN/1"`) {
+		t.Fatalf("expected second link to be tracked: %s", rewritten)
+	}
+	if !strings.Contains(rewritten, `href="/relative"`) {
+		t.Fatalf("relative links should be left alone: %s", rewritten)
+	}
+	if !strings.Contains(rewritten, unsubscribeTokenPlaceholder) {
+		t.Fatalf("unsubscribe link should not be tracked: %s", rewritten)
+	}
+}
+
+func TestNormalizeEmail(t *testing.T) {
+	email, err := normalizeEmail("  Reader@Example.COM ")
+	if err != nil || email != "reader@example.com" {
+		t.Fatalf("unexpected result %q, %v", email, err)
+	}
+
+	for _, invalid := range []string{"", "not-an-email", "Name <reader@example.com>"} {
+		if _, err := normalizeEmail(invalid); err == nil {
+			t.Fatalf("expected %q to be rejected", invalid)
+		}
+	}
+}
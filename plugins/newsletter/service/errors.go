@@ -0,0 +1,17 @@
+package service
+
+import "errors"
+
+var (
+	ErrSubscriberNotFound  = errors.New("subscriber not found")
+	ErrCampaignNotFound    = errors.New("campaign not found")
+	ErrInvalidEmail        = errors.New("invalid email address")
+	ErrInvalidStatus       = errors.New("invalid subscriber status")
+	ErrInvalidCampaign     = errors.New("invalid campaign")
+	ErrInvalidToken        = errors.New("invalid or expired link")
+	ErrCampaignNotEditable = errors.New("campaign has already been sent")
+	ErrNoRecipients        = errors.New("campaign has no confirmed recipients")
+	ErrEmailUnavailable    = errors.New("email delivery is not configured")
+	ErrRepositoryNotReady  = errors.New("newsletter repository is not configured")
+	ErrTrackedLinkNotFound = errors.New("tracked link not found")
+)
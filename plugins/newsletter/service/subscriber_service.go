@@ -0,0 +1,310 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/repository"
+	"constructor-script-backend/internal/service"
+	"constructor-script-backend/pkg/logger"
+)
+
+const (
+	// confirmTokenTTL bounds how long an opt-in link stays valid.
+	confirmTokenTTL = 7 * 24 * time.Hour
+	// confirmResendInterval keeps repeated sign-ups from mailing an address over
+	// and over.
+	confirmResendInterval = 10 * time.Minute
+)
+
+// Mailer is the part of the core email service used by the newsletter.
+type Mailer interface {
+	Enabled() bool
+	SiteURL() string
+	SendTemplate(to, name, fallbackSubject string, data map[string]interface{}) error
+	RenderTemplate(name string, data map[string]interface{}) (*service.EmailMessage, error)
+	SendHTML(to, subject, text, htmlBody string) error
+}
+
+type SubscriberService struct {
+	repo   repository.NewsletterRepository
+	mailer Mailer
+}
+
+func NewSubscriberService(repo repository.NewsletterRepository, mailer Mailer) *SubscriberService {
+	return &SubscriberService{repo: repo, mailer: mailer}
+}
+
+func (s *SubscriberService) SetRepository(repo repository.NewsletterRepository) {
+	if s == nil {
+		return
+	}
+	s.repo = repo
+}
+
+func (s *SubscriberService) SetMailer(mailer Mailer) {
+	if s == nil {
+		return
+	}
+	s.mailer = mailer
+}
+
+// Subscribe starts the double opt-in for email. Already confirmed addresses, and
+// pending ones mailed in the last confirmResendInterval, are left untouched and the
+// result is the same as for a new address, so the endpoint does not reveal who is on
+// the list.
+func (s *SubscriberService) Subscribe(req models.NewsletterSubscribeRequest, source string) (*models.NewsletterSubscriber, error) {
+	if s == nil || s.repo == nil {
+		return nil, ErrRepositoryNotReady
+	}
+	if s.mailer == nil || !s.mailer.Enabled() {
+		return nil, ErrEmailUnavailable
+	}
+
+	email, err := normalizeEmail(req.Email)
+	if err != nil {
+		return nil, err
+	}
+	name := strings.TrimSpace(req.Name)
+
+	subscriber, err := s.repo.GetSubscriberByEmail(email)
+	switch {
+	case err == nil:
+		if subscriber.Status == models.NewsletterSubscriberConfirmed {
+			return subscriber, nil
+		}
+		if subscriber.Status == models.NewsletterSubscriberPending && subscriber.ConfirmSentAt != nil &&
+			time.Since(*subscriber.ConfirmSentAt) < confirmResendInterval {
+			return subscriber, nil
+		}
+		if name != "" {
+			subscriber.Name = name
+		}
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		unsubscribeToken, err := generateToken()
+		if err != nil {
+			return nil, err
+		}
+		subscriber = &models.NewsletterSubscriber{
+			Email:            email,
+			Name:             name,
+			Source:           strings.TrimSpace(source),
+			UnsubscribeToken: unsubscribeToken,
+		}
+	default:
+		return nil, err
+	}
+
+	confirmToken, err := generateToken()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	subscriber.Status = models.NewsletterSubscriberPending
+	subscriber.ConfirmToken = confirmToken
+	subscriber.ConfirmSentAt = &now
+	subscriber.UnsubscribedAt = nil
+
+	if subscriber.ID == 0 {
+		err = s.repo.CreateSubscriber(subscriber)
+	} else {
+		err = s.repo.UpdateSubscriber(subscriber)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	data := map[string]interface{}{
+		"Name":       subscriber.Name,
+		"ConfirmURL": s.mailer.SiteURL() + "/newsletter/confirm/" + confirmToken,
+	}
+	if err := s.mailer.SendTemplate(subscriber.Email, service.EmailTemplateNewsletterConfirm, "Confirm your subscription", data); err != nil {
+		logger.Error(err, "Failed to send newsletter confirmation", map[string]interface{}{"subscriber_id": subscriber.ID})
+		return nil, fmt.Errorf("failed to send confirmation email: %w", err)
+	}
+
+	return subscriber, nil
+}
+
+// Confirm completes the opt-in started by Subscribe.
+func (s *SubscriberService) Confirm(token string) (*models.NewsletterSubscriber, error) {
+	if s == nil || s.repo == nil {
+		return nil, ErrRepositoryNotReady
+	}
+
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return nil, ErrInvalidToken
+	}
+
+	subscriber, err := s.repo.GetSubscriberByConfirmToken(token)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+	if subscriber.ConfirmSentAt == nil || time.Since(*subscriber.ConfirmSentAt) > confirmTokenTTL {
+		return nil, ErrInvalidToken
+	}
+
+	now := time.Now().UTC()
+	subscriber.Status = models.NewsletterSubscriberConfirmed
+	subscriber.ConfirmToken = ""
+	subscriber.ConfirmedAt = &now
+	subscriber.UnsubscribedAt = nil
+	if err := s.repo.UpdateSubscriber(subscriber); err != nil {
+		return nil, err
+	}
+	return subscriber, nil
+}
+
+// Unsubscribe removes the subscriber identified by the token in campaign emails from
+// future sends. Repeating it is harmless.
+func (s *SubscriberService) Unsubscribe(token string) error {
+	if s == nil || s.repo == nil {
+		return ErrRepositoryNotReady
+	}
+
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return ErrInvalidToken
+	}
+
+	subscriber, err := s.repo.GetSubscriberByUnsubscribeToken(token)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidToken
+		}
+		return err
+	}
+	if subscriber.Status == models.NewsletterSubscriberUnsubscribed {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	subscriber.Status = models.NewsletterSubscriberUnsubscribed
+	subscriber.ConfirmToken = ""
+	subscriber.UnsubscribedAt = &now
+	return s.repo.UpdateSubscriber(subscriber)
+}
+
+func (s *SubscriberService) List(status, search string, page, limit int) ([]models.NewsletterSubscriber, int64, error) {
+	if s == nil || s.repo == nil {
+		return nil, 0, ErrRepositoryNotReady
+	}
+
+	status = strings.TrimSpace(status)
+	if status != "" && !validSubscriberStatus(status) {
+		return nil, 0, ErrInvalidStatus
+	}
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 200 {
+		limit = 50
+	}
+
+	return s.repo.ListSubscribers(status, strings.TrimSpace(search), (page-1)*limit, limit)
+}
+
+func (s *SubscriberService) GetByID(id uint) (*models.NewsletterSubscriber, error) {
+	if s == nil || s.repo == nil {
+		return nil, ErrRepositoryNotReady
+	}
+
+	subscriber, err := s.repo.GetSubscriberByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSubscriberNotFound
+		}
+		return nil, err
+	}
+	return subscriber, nil
+}
+
+// Update lets administrators rename a subscriber or change their status. Moving an
+// address to confirmed is only allowed for addresses that confirmed before, so the
+// opt-in cannot be skipped from the admin panel.
+func (s *SubscriberService) Update(id uint, req models.UpdateNewsletterSubscriberRequest) (*models.NewsletterSubscriber, error) {
+	subscriber, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		subscriber.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Status != nil {
+		status := strings.TrimSpace(*req.Status)
+		if !validSubscriberStatus(status) {
+			return nil, ErrInvalidStatus
+		}
+		now := time.Now().UTC()
+		switch status {
+		case models.NewsletterSubscriberConfirmed:
+			if subscriber.ConfirmedAt == nil {
+				return nil, ErrInvalidStatus
+			}
+			subscriber.UnsubscribedAt = nil
+		case models.NewsletterSubscriberUnsubscribed:
+			if subscriber.Status != status {
+				subscriber.UnsubscribedAt = &now
+			}
+			subscriber.ConfirmToken = ""
+		case models.NewsletterSubscriberPending:
+			if subscriber.Status != status {
+				return nil, ErrInvalidStatus
+			}
+		}
+		subscriber.Status = status
+	}
+
+	if err := s.repo.UpdateSubscriber(subscriber); err != nil {
+		return nil, err
+	}
+	return subscriber, nil
+}
+
+func (s *SubscriberService) Delete(id uint) error {
+	if _, err := s.GetByID(id); err != nil {
+		return err
+	}
+	return s.repo.DeleteSubscriber(id)
+}
+
+func validSubscriberStatus(status string) bool {
+	switch status {
+	case models.NewsletterSubscriberPending, models.NewsletterSubscriberConfirmed, models.NewsletterSubscriberUnsubscribed:
+		return true
+	}
+	return false
+}
+
+func normalizeEmail(raw string) (string, error) {
+	trimmed := strings.TrimSpace(raw)
+	if trimmed == "" || len(trimmed) > 254 {
+		return "", ErrInvalidEmail
+	}
+	address, err := mail.ParseAddress(trimmed)
+	if err != nil || address.Address != trimmed {
+		return "", ErrInvalidEmail
+	}
+	return strings.ToLower(address.Address), nil
+}
+
+func generateToken() (string, error) {
+	bytes := make([]byte, 24)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/repository"
+	"constructor-script-backend/internal/service"
+)
+
+type memoryNewsletterRepository struct {
+	repository.NewsletterRepository
+	subscribers map[string]*models.NewsletterSubscriber
+}
+
+func (r *memoryNewsletterRepository) GetSubscriberByEmail(email string) (*models.NewsletterSubscriber, error) {
+	subscriber, ok := r.subscribers[email]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *subscriber
+	return &copied, nil
+}
+
+func (r *memoryNewsletterRepository) CreateSubscriber(subscriber *models.NewsletterSubscriber) error {
+	subscriber.ID = uint(len(r.subscribers) + 1)
+	return r.UpdateSubscriber(subscriber)
+}
+
+func (r *memoryNewsletterRepository) UpdateSubscriber(subscriber *models.NewsletterSubscriber) error {
+	copied := *subscriber
+	r.subscribers[subscriber.Email] = &copied
+	return nil
+}
+
+type countingMailer struct {
+	sent int
+}
+
+func (m *countingMailer) Enabled() bool   { return true }
+func (m *countingMailer) SiteURL() string { return "https://site.test" }
+func (m *countingMailer) SendTemplate(to, name, fallbackSubject string, data map[string]interface{}) error {
+	m.sent++
+	return nil
+}
+func (m *countingMailer) RenderTemplate(name string, data map[string]interface{}) (*service.EmailMessage, error) {
+	return &service.EmailMessage{}, nil
+}
+func (m *countingMailer) SendHTML(to, subject, text, htmlBody string) error { return nil }
+
+func TestSubscribeThrottlesConfirmationEmails(t *testing.T) {
+	repo := &memoryNewsletterRepository{subscribers: map[string]*models.NewsletterSubscriber{}}
+	mailer := &countingMailer{}
+	svc := NewSubscriberService(repo, mailer)
+	req := models.NewsletterSubscribeRequest{Email: "reader@example.com"}
+
+	first, err := svc.Subscribe(req, "form")
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	again, err := svc.Subscribe(req, "form")
+	if err != nil || mailer.sent != 1 || again.ConfirmToken != first.ConfirmToken {
+		t.Fatalf("expected a repeated sign-up to send nothing, got %d emails, %v", mailer.sent, err)
+	}
+
+	earlier := time.Now().Add(-confirmResendInterval - time.Minute)
+	repo.subscribers["reader@example.com"].ConfirmSentAt = &earlier
+	later, err := svc.Subscribe(req, "form")
+	if err != nil || mailer.sent != 2 || later.ConfirmToken == first.ConfirmToken {
+		t.Fatalf("expected the confirmation to be sent again after the interval, got %d emails, %v", mailer.sent, err)
+	}
+}
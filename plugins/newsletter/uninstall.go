@@ -0,0 +1,34 @@
+package newsletter
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/plugin/migrations"
+)
+
+// Uninstall drops the subscriber list, campaigns and delivery log. It runs only when
+// an administrator removes the plugin with purge enabled.
+func (f *Feature) Uninstall() error {
+	if f == nil || f.host == nil {
+		return fmt.Errorf("feature host is not configured")
+	}
+
+	db := f.host.Database()
+	if db == nil {
+		return fmt.Errorf("database is not configured")
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Migrator().DropTable(
+			&models.NewsletterDelivery{},
+			&models.NewsletterCampaign{},
+			&models.NewsletterSubscriber{},
+		); err != nil {
+			return fmt.Errorf("failed to drop newsletter tables: %w", err)
+		}
+		return migrations.Forget(tx, "newsletter")
+	})
+}
@@ -0,0 +1,15 @@
+package api
+
+const (
+	// Namespace is the registry namespace used by the products plugin.
+	Namespace = "products"
+
+	ServiceProduct = "product"
+	ServiceOrder   = "order"
+
+	HandlerProduct = "product"
+	HandlerPublic  = "public"
+
+	// FulfillmentKind identifies product purchases in checkout session metadata.
+	FulfillmentKind = "product"
+)
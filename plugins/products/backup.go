@@ -0,0 +1,10 @@
+package products
+
+import (
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/plugin/backup"
+)
+
+func init() {
+	backup.Register("products", &models.Product{}, &models.ProductOrder{})
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"constructor-script-backend/internal/payments"
+	productservice "constructor-script-backend/plugins/products/service"
+)
+
+func productErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, productservice.ErrProductNotFound),
+		errors.Is(err, productservice.ErrOrderNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, productservice.ErrInvalidProduct),
+		errors.Is(err, productservice.ErrFileNotFound):
+		return http.StatusBadRequest
+	case errors.Is(err, productservice.ErrSlugConflict),
+		errors.Is(err, productservice.ErrOrderNotPaid):
+		return http.StatusConflict
+	case errors.Is(err, productservice.ErrInvalidDownloadLink):
+		return http.StatusForbidden
+	case errors.Is(err, productservice.ErrDownloadLimit):
+		return http.StatusGone
+	case errors.Is(err, payments.ErrInvalidAmount):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, productservice.ErrRepositoryNotReady),
+		errors.Is(err, productservice.ErrDownloadsDisabled),
+		errors.Is(err, payments.ErrCheckoutDisabled):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/pkg/apierror"
+	productservice "constructor-script-backend/plugins/products/service"
+)
+
+// ProductHandler serves the admin API for products and their orders.
+type ProductHandler struct {
+	service *productservice.ProductService
+	orders  *productservice.OrderService
+}
+
+func NewProductHandler(service *productservice.ProductService, orders *productservice.OrderService) *ProductHandler {
+	return &ProductHandler{service: service, orders: orders}
+}
+
+func (h *ProductHandler) SetServices(service *productservice.ProductService, orders *productservice.OrderService) {
+	if h == nil {
+		return
+	}
+	h.service = service
+	h.orders = orders
+}
+
+func (h *ProductHandler) ensureService(c *gin.Context) bool {
+	if h == nil || h.service == nil || h.orders == nil {
+		if c != nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "products plugin is not active"})
+		}
+		return false
+	}
+	return true
+}
+
+func (h *ProductHandler) List(c *gin.Context) {
+	if !h.ensureService(c) {
+		return
+	}
+
+	products, err := h.service.List()
+	if err != nil {
+		c.JSON(productErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"products": products})
+}
+
+func (h *ProductHandler) Get(c *gin.Context) {
+	if !h.ensureService(c) {
+		return
+	}
+
+	id, ok := parseProductID(c, "id")
+	if !ok {
+		return
+	}
+
+	product, err := h.service.GetByID(id)
+	if err != nil {
+		c.JSON(productErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"product": product})
+}
+
+func (h *ProductHandler) Create(c *gin.Context) {
+	if !h.ensureService(c) {
+		return
+	}
+
+	var req models.CreateProductRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BindError(c, err)
+		return
+	}
+
+	product, err := h.service.Create(req)
+	if err != nil {
+		c.JSON(productErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"product": product})
+}
+
+func (h *ProductHandler) Update(c *gin.Context) {
+	if !h.ensureService(c) {
+		return
+	}
+
+	id, ok := parseProductID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.UpdateProductRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BindError(c, err)
+		return
+	}
+
+	product, err := h.service.Update(id, req)
+	if err != nil {
+		c.JSON(productErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"product": product})
+}
+
+func (h *ProductHandler) Delete(c *gin.Context) {
+	if !h.ensureService(c) {
+		return
+	}
+
+	id, ok := parseProductID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(id); err != nil {
+		c.JSON(productErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "product deleted"})
+}
+
+// ListOrders lists orders, optionally filtered with ?product_id=.
+func (h *ProductHandler) ListOrders(c *gin.Context) {
+	if !h.ensureService(c) {
+		return
+	}
+
+	var productID uint
+	if raw := c.Query("product_id"); raw != "" {
+		value, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid product id"})
+			return
+		}
+		productID = uint(value)
+	}
+
+	orders, err := h.orders.ListOrders(productID)
+	if err != nil {
+		c.JSON(productErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"orders": orders})
+}
+
+// ResendDownload emails a new download link for a paid order.
+func (h *ProductHandler) ResendDownload(c *gin.Context) {
+	if !h.ensureService(c) {
+		return
+	}
+
+	id, ok := parseProductID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.orders.ResendDownload(id); err != nil {
+		c.JSON(productErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "download link sent"})
+}
+
+func parseProductID(c *gin.Context, param string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(param), 10, 64)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return 0, false
+	}
+	return uint(id), true
+}
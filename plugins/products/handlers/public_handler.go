@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/pkg/apierror"
+	"constructor-script-backend/pkg/logger"
+	productservice "constructor-script-backend/plugins/products/service"
+)
+
+// PublicHandler lists products, starts checkouts and serves paid downloads.
+type PublicHandler struct {
+	service   *productservice.ProductService
+	orders    *productservice.OrderService
+	uploadDir string
+}
+
+func NewPublicHandler(service *productservice.ProductService, orders *productservice.OrderService, uploadDir string) *PublicHandler {
+	return &PublicHandler{service: service, orders: orders, uploadDir: uploadDir}
+}
+
+func (h *PublicHandler) SetDependencies(service *productservice.ProductService, orders *productservice.OrderService, uploadDir string) {
+	if h == nil {
+		return
+	}
+	h.service = service
+	h.orders = orders
+	h.uploadDir = uploadDir
+}
+
+func (h *PublicHandler) ensureService(c *gin.Context) bool {
+	if h == nil || h.service == nil || h.orders == nil {
+		if c != nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "products plugin is not active"})
+		}
+		return false
+	}
+	return true
+}
+
+func (h *PublicHandler) List(c *gin.Context) {
+	if !h.ensureService(c) {
+		return
+	}
+
+	products, err := h.service.ListPublished()
+	if err != nil {
+		c.JSON(productErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"products": products, "checkout_enabled": h.orders.CheckoutEnabled()})
+}
+
+func (h *PublicHandler) GetBySlug(c *gin.Context) {
+	if !h.ensureService(c) {
+		return
+	}
+
+	product, err := h.service.GetBySlug(c.Param("slug"), false)
+	if err != nil {
+		c.JSON(productErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	// The file reference is only handed out through signed links.
+	product.ArchiveFile = nil
+	c.JSON(http.StatusOK, gin.H{"product": product, "checkout_enabled": h.orders.CheckoutEnabled()})
+}
+
+// Checkout starts a payment for the product; the buyer's email receives the download.
+func (h *PublicHandler) Checkout(c *gin.Context) {
+	if !h.ensureService(c) {
+		return
+	}
+
+	var req models.ProductCheckoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BindError(c, err)
+		return
+	}
+
+	product, err := h.service.GetBySlug(c.Param("slug"), false)
+	if err != nil {
+		c.JSON(productErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	session, err := h.orders.StartCheckout(c.Request.Context(), product, req.Email)
+	if err != nil {
+		status := productErrorStatus(err)
+		if status == http.StatusInternalServerError {
+			logger.Error(err, "Failed to start product checkout", map[string]interface{}{"product_id": product.ID})
+			status = http.StatusBadGateway
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, session)
+}
+
+// Verify reports whether the order behind a checkout session is paid and, if so,
+// returns a download link. The success page polls it until the payment settles.
+func (h *PublicHandler) Verify(c *gin.Context) {
+	if !h.ensureService(c) {
+		return
+	}
+
+	var req models.VerifyProductCheckoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.BindError(c, err)
+		return
+	}
+
+	order, downloadURL, err := h.orders.Verify(c.Request.Context(), req.SessionID)
+	if err != nil {
+		c.JSON(productErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	if downloadURL == "" {
+		c.JSON(http.StatusAccepted, gin.H{"status": order.Status})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": order.Status, "download_url": downloadURL})
+}
+
+// Download streams the product file for a valid signed link.
+func (h *PublicHandler) Download(c *gin.Context) {
+	if !h.ensureService(c) {
+		return
+	}
+
+	file, err := h.orders.OpenDownload(c.Param("token"))
+	if err != nil {
+		c.JSON(productErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	filePath, err := h.resolveUpload(file.FileURL)
+	if err != nil {
+		logger.Error(err, "Product file is not available", map[string]interface{}{"archive_file_id": file.ID})
+		c.JSON(http.StatusNotFound, gin.H{"error": "file unavailable"})
+		return
+	}
+
+	name := strings.NewReplacer(`"`, "", "\r", "", "\n", "").Replace(strings.TrimSpace(file.Name))
+	if name == "" {
+		name = filepath.Base(filePath)
+	} else if filepath.Ext(name) == "" {
+		name += filepath.Ext(filePath)
+	}
+
+	c.Header("Cache-Control", "private, no-store")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", name))
+	c.File(filePath)
+}
+
+// resolveUpload maps an /uploads/ URL to a file inside the upload directory.
+func (h *PublicHandler) resolveUpload(raw string) (string, error) {
+	trimmed := strings.TrimSpace(raw)
+	if parsed, err := url.Parse(trimmed); err == nil && parsed.Path != "" {
+		trimmed = parsed.Path
+	}
+	if !strings.HasPrefix(trimmed, "/uploads/") {
+		return "", errors.New("product file is not an upload")
+	}
+
+	filename := filepath.Base(trimmed)
+	if filename == "" || filename == "." || filename == ".." || filename == "/" {
+		return "", errors.New("invalid upload filename")
+	}
+
+	uploadDir := strings.TrimSpace(h.uploadDir)
+	if uploadDir == "" {
+		uploadDir = "./uploads"
+	}
+	root, err := filepath.Abs(uploadDir)
+	if err != nil {
+		return "", err
+	}
+	target := filepath.Join(root, filename)
+	if _, err := os.Stat(target); err != nil {
+		return "", err
+	}
+	return target, nil
+}
@@ -0,0 +1,13 @@
+package products
+
+import (
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/plugin/migrations"
+)
+
+func init() {
+	migrations.RegisterTables("products",
+		&models.Product{},
+		&models.ProductOrder{},
+	)
+}
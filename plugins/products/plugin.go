@@ -0,0 +1,156 @@
+package products
+
+import (
+	"fmt"
+	"strings"
+
+	"constructor-script-backend/internal/plugin/host"
+	"constructor-script-backend/internal/plugin/registry"
+	pluginruntime "constructor-script-backend/internal/plugin/runtime"
+	"constructor-script-backend/pkg/logger"
+	productapi "constructor-script-backend/plugins/products/api"
+	producthandlers "constructor-script-backend/plugins/products/handlers"
+	productservice "constructor-script-backend/plugins/products/service"
+)
+
+func init() {
+	registry.Register("products", NewFeature)
+}
+
+type Feature struct {
+	host host.Host
+}
+
+func NewFeature(h host.Host) (pluginruntime.Feature, error) {
+	if h == nil {
+		return nil, fmt.Errorf("host is required")
+	}
+	return &Feature{host: h}, nil
+}
+
+func (f *Feature) Activate() error {
+	if f == nil || f.host == nil {
+		return fmt.Errorf("feature host is not configured")
+	}
+
+	repos := f.host.Repositories()
+	if repos == nil {
+		return fmt.Errorf("repository access is not configured")
+	}
+	productRepo := repos.Product()
+	if productRepo == nil {
+		return fmt.Errorf("product repository is not configured")
+	}
+	fileRepo := repos.ArchiveFile()
+	if fileRepo == nil {
+		return fmt.Errorf("archive file repository is not configured")
+	}
+
+	coreServices := f.host.CoreServices()
+	if coreServices == nil {
+		return fmt.Errorf("core services are not configured")
+	}
+	paymentService := coreServices.Payments()
+	if paymentService == nil {
+		return fmt.Errorf("payment service is not configured")
+	}
+
+	var (
+		siteURL    string
+		signingKey string
+		uploadDir  string
+	)
+	if cfg := f.host.Config(); cfg != nil {
+		siteURL = strings.TrimRight(strings.TrimSpace(cfg.SiteURL), "/")
+		signingKey = cfg.JWTSecret
+		uploadDir = strings.TrimSpace(cfg.UploadDir)
+	}
+
+	successURL := siteURL + "/products/checkout/success"
+	cancelURL := siteURL + "/products/checkout/cancel"
+	var settings productservice.SettingsSource
+	if pluginService := coreServices.Plugins(); pluginService != nil {
+		settings = pluginService
+		if values, err := pluginService.Settings(productapi.Namespace); err != nil {
+			logger.Error(err, "Failed to load plugin settings for checkout", map[string]interface{}{"feature": "products"})
+		} else {
+			if url, ok := values["checkout_success_url"].(string); ok && strings.TrimSpace(url) != "" {
+				successURL = strings.TrimSpace(url)
+			}
+			if url, ok := values["checkout_cancel_url"].(string); ok && strings.TrimSpace(url) != "" {
+				cancelURL = strings.TrimSpace(url)
+			}
+		}
+	}
+
+	var mailer productservice.Mailer
+	if emailService := coreServices.Email(); emailService != nil {
+		mailer = emailService
+	}
+
+	checkout := paymentService.NewCheckout(successURL, cancelURL)
+	signer := productservice.NewDownloadSigner(signingKey)
+
+	servicesRegistry := f.host.Services(productapi.Namespace)
+	handlersRegistry := f.host.Handlers(productapi.Namespace)
+
+	productService, _ := servicesRegistry.Get(productapi.ServiceProduct).(*productservice.ProductService)
+	if productService == nil {
+		productService = productservice.NewProductService(productRepo, fileRepo)
+	} else {
+		productService.SetRepositories(productRepo, fileRepo)
+	}
+	servicesRegistry.Set(productapi.ServiceProduct, productService)
+
+	orderService, _ := servicesRegistry.Get(productapi.ServiceOrder).(*productservice.OrderService)
+	if orderService == nil {
+		orderService = productservice.NewOrderService(productRepo, checkout, signer, mailer, settings, siteURL)
+	} else {
+		orderService.SetDependencies(productRepo, checkout, signer, mailer, settings, siteURL)
+	}
+	servicesRegistry.Set(productapi.ServiceOrder, orderService)
+
+	if handler, ok := handlersRegistry.Get(productapi.HandlerProduct).(*producthandlers.ProductHandler); ok && handler != nil {
+		handler.SetServices(productService, orderService)
+	} else {
+		handlersRegistry.Set(productapi.HandlerProduct, producthandlers.NewProductHandler(productService, orderService))
+	}
+
+	if handler, ok := handlersRegistry.Get(productapi.HandlerPublic).(*producthandlers.PublicHandler); ok && handler != nil {
+		handler.SetDependencies(productService, orderService, uploadDir)
+	} else {
+		handlersRegistry.Set(productapi.HandlerPublic, producthandlers.NewPublicHandler(productService, orderService, uploadDir))
+	}
+
+	paymentService.Fulfillments().Register(productapi.FulfillmentKind, orderService.Fulfill)
+
+	return nil
+}
+
+// Deactivate stops fulfilling product payments and detaches the services, so the
+// handlers answer with 503 until the plugin is activated again.
+func (f *Feature) Deactivate() error {
+	if f == nil || f.host == nil {
+		return nil
+	}
+
+	if coreServices := f.host.CoreServices(); coreServices != nil {
+		if paymentService := coreServices.Payments(); paymentService != nil {
+			paymentService.Fulfillments().Unregister(productapi.FulfillmentKind)
+		}
+	}
+
+	handlersRegistry := f.host.Handlers(productapi.Namespace)
+	if handler, _ := handlersRegistry.Get(productapi.HandlerProduct).(*producthandlers.ProductHandler); handler != nil {
+		handler.SetServices(nil, nil)
+	}
+	if handler, _ := handlersRegistry.Get(productapi.HandlerPublic).(*producthandlers.PublicHandler); handler != nil {
+		handler.SetDependencies(nil, nil, "")
+	}
+
+	servicesRegistry := f.host.Services(productapi.Namespace)
+	servicesRegistry.Set(productapi.ServiceProduct, nil)
+	servicesRegistry.Set(productapi.ServiceOrder, nil)
+
+	return nil
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDownloadSignerRoundTrip(t *testing.T) {
+	signer := NewDownloadSigner("secret")
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	token := signer.Sign(42, now.Add(time.Hour))
+	orderID, err := signer.Verify(token, now)
+	if err != nil {
+		t.Fatalf("expected token to verify, got %v", err)
+	}
+	if orderID != 42 {
+		t.Fatalf("expected order 42, got %d", orderID)
+	}
+
+	if _, err := signer.Verify(token, now.Add(2*time.Hour)); !errors.Is(err, ErrInvalidDownloadLink) {
+		t.Fatalf("expected expired token to be rejected, got %v", err)
+	}
+}
+
+func TestDownloadSignerRejectsTampering(t *testing.T) {
+	signer := NewDownloadSigner("secret")
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	token := signer.Sign(42, now.Add(time.Hour))
+
+	forged := "43" + token[2:]
+	if _, err := signer.Verify(forged, now); !errors.Is(err, ErrInvalidDownloadLink) {
+		t.Fatalf("expected forged order id to be rejected, got %v", err)
+	}
+
+	other := NewDownloadSigner("other")
+	if _, err := other.Verify(token, now); !errors.Is(err, ErrInvalidDownloadLink) {
+		t.Fatalf("expected token from another key to be rejected, got %v", err)
+	}
+
+	var disabled *DownloadSigner
+	if _, err := disabled.Verify(token, now); !errors.Is(err, ErrDownloadsDisabled) {
+		t.Fatalf("expected disabled signer error, got %v", err)
+	}
+}
@@ -0,0 +1,16 @@
+package service
+
+import "errors"
+
+var (
+	ErrProductNotFound     = errors.New("product not found")
+	ErrOrderNotFound       = errors.New("order not found")
+	ErrInvalidProduct      = errors.New("invalid product")
+	ErrSlugConflict        = errors.New("a product with this slug already exists")
+	ErrFileNotFound        = errors.New("archive file not found")
+	ErrOrderNotPaid        = errors.New("order has not been paid")
+	ErrInvalidDownloadLink = errors.New("invalid or expired download link")
+	ErrDownloadLimit       = errors.New("download limit reached for this order")
+	ErrDownloadsDisabled   = errors.New("download links are not configured")
+	ErrRepositoryNotReady  = errors.New("product repository is not configured")
+)
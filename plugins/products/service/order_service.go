@@ -0,0 +1,323 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/payments"
+	"constructor-script-backend/internal/repository"
+	"constructor-script-backend/internal/service"
+	"constructor-script-backend/pkg/logger"
+	productapi "constructor-script-backend/plugins/products/api"
+)
+
+const (
+	metadataOrderID = "product_order_id"
+
+	settingLinkHours     = "download_link_hours"
+	settingDownloadLimit = "download_limit"
+
+	defaultLinkTTL       = 72 * time.Hour
+	defaultDownloadLimit = 5
+
+	downloadPathPrefix = "/products/download/"
+)
+
+// Mailer is the part of the core email service used to deliver download links.
+type Mailer interface {
+	Enabled() bool
+	SendTemplate(to, name, fallbackSubject string, data map[string]interface{}) error
+}
+
+// SettingsSource reads the plugin settings, so link lifetime and download limits apply
+// without reactivating the plugin.
+type SettingsSource interface {
+	Settings(slug string) (map[string]interface{}, error)
+}
+
+// OrderService sells products through the shared payments checkout and delivers the
+// files once the payment is confirmed.
+type OrderService struct {
+	repo     repository.ProductRepository
+	checkout *payments.Checkout
+	signer   *DownloadSigner
+	mailer   Mailer
+	settings SettingsSource
+	siteURL  string
+}
+
+func NewOrderService(repo repository.ProductRepository, checkout *payments.Checkout, signer *DownloadSigner, mailer Mailer, settings SettingsSource, siteURL string) *OrderService {
+	service := &OrderService{}
+	service.SetDependencies(repo, checkout, signer, mailer, settings, siteURL)
+	return service
+}
+
+func (s *OrderService) SetDependencies(repo repository.ProductRepository, checkout *payments.Checkout, signer *DownloadSigner, mailer Mailer, settings SettingsSource, siteURL string) {
+	if s == nil {
+		return
+	}
+	s.repo = repo
+	s.checkout = checkout
+	s.signer = signer
+	s.mailer = mailer
+	s.settings = settings
+	s.siteURL = strings.TrimRight(strings.TrimSpace(siteURL), "/")
+}
+
+// CheckoutEnabled reports whether products can currently be bought.
+func (s *OrderService) CheckoutEnabled() bool {
+	return s != nil && s.repo != nil && s.signer != nil && s.checkout.Enabled()
+}
+
+func (s *OrderService) ListOrders(productID uint) ([]models.ProductOrder, error) {
+	if s == nil || s.repo == nil {
+		return nil, ErrRepositoryNotReady
+	}
+	return s.repo.ListOrders(productID)
+}
+
+// StartCheckout records a pending order for the published product and opens a
+// checkout session for it.
+func (s *OrderService) StartCheckout(ctx context.Context, product *models.Product, email string) (*models.ProductCheckoutSession, error) {
+	if !s.CheckoutEnabled() {
+		return nil, payments.ErrCheckoutDisabled
+	}
+	if product == nil || !product.Published {
+		return nil, ErrProductNotFound
+	}
+	address, err := mail.ParseAddress(strings.TrimSpace(email))
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid email address", ErrInvalidProduct)
+	}
+
+	order := &models.ProductOrder{
+		ProductID:   product.ID,
+		Email:       strings.ToLower(address.Address),
+		Status:      models.ProductOrderStatusPending,
+		AmountCents: product.PriceCents,
+	}
+	if err := s.repo.CreateOrder(order); err != nil {
+		return nil, err
+	}
+
+	session, err := s.checkout.CreateSession(ctx, payments.Order{
+		CustomerEmail: order.Email,
+		Metadata: map[string]string{
+			payments.MetadataKind: productapi.FulfillmentKind,
+			metadataOrderID:       strconv.FormatUint(uint64(order.ID), 10),
+			"product_id":          strconv.FormatUint(uint64(product.ID), 10),
+		},
+		Items: []payments.LineItem{{
+			Name:        product.Title,
+			Description: product.Summary,
+			AmountCents: product.PriceCents,
+			Quantity:    1,
+		}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateOrderSession(order.ID, session.ID); err != nil {
+		return nil, err
+	}
+
+	return &models.ProductCheckoutSession{
+		OrderID:     order.ID,
+		SessionID:   session.ID,
+		CheckoutURL: session.URL,
+	}, nil
+}
+
+// Fulfill marks the order behind a paid checkout session as paid and emails the
+// download link. It is registered with the payments module and safe to call again
+// for the same session.
+func (s *OrderService) Fulfill(ctx context.Context, session *payments.SessionDetails) error {
+	if s == nil || s.repo == nil {
+		return ErrRepositoryNotReady
+	}
+	if !payments.IsPaid(session) {
+		return ErrOrderNotPaid
+	}
+
+	orderID, err := strconv.ParseUint(strings.TrimSpace(session.Metadata[metadataOrderID]), 10, 64)
+	if err != nil || orderID == 0 {
+		return fmt.Errorf("checkout session %s is missing the product order", session.ID)
+	}
+
+	order, err := s.repo.GetOrderByID(uint(orderID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrOrderNotFound
+		}
+		return err
+	}
+	if order.SessionID != "" && order.SessionID != session.ID {
+		return fmt.Errorf("checkout session %s does not belong to order %d", session.ID, order.ID)
+	}
+
+	paid, err := s.repo.MarkOrderPaid(order.ID, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	if !paid {
+		return nil
+	}
+	order.Status = models.ProductOrderStatusPaid
+
+	logger.Info("Product order paid", map[string]interface{}{"order_id": order.ID, "product_id": order.ProductID})
+	s.sendDownloadEmail(order)
+	return nil
+}
+
+// Verify reports the state of the order behind a checkout session, for the page the
+// customer lands on after paying. When the webhook has not arrived yet the session is
+// checked with the provider directly. The download URL is empty until the order is paid.
+func (s *OrderService) Verify(ctx context.Context, sessionID string) (*models.ProductOrder, string, error) {
+	if s == nil || s.repo == nil {
+		return nil, "", ErrRepositoryNotReady
+	}
+
+	sessionID = strings.TrimSpace(sessionID)
+	order, err := s.repo.GetOrderBySession(sessionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, "", ErrOrderNotFound
+		}
+		return nil, "", err
+	}
+
+	if order.Status != models.ProductOrderStatusPaid {
+		session, err := s.checkout.RetrieveSession(ctx, sessionID)
+		if err != nil {
+			return nil, "", err
+		}
+		if !payments.IsPaid(session) {
+			return order, "", nil
+		}
+		if err := s.Fulfill(ctx, session); err != nil {
+			return nil, "", err
+		}
+		order.Status = models.ProductOrderStatusPaid
+	}
+
+	return order, s.DownloadURL(order), nil
+}
+
+// DownloadURL returns a fresh signed link for a paid order.
+func (s *OrderService) DownloadURL(order *models.ProductOrder) string {
+	if s == nil || s.signer == nil || order == nil || order.Status != models.ProductOrderStatusPaid {
+		return ""
+	}
+	ttl, _ := s.downloadPolicy()
+	return s.siteURL + downloadPathPrefix + s.signer.Sign(order.ID, time.Now().Add(ttl))
+}
+
+// OpenDownload validates a download token, counts the download and returns the file
+// to serve.
+func (s *OrderService) OpenDownload(token string) (*models.ArchiveFile, error) {
+	if s == nil || s.repo == nil {
+		return nil, ErrRepositoryNotReady
+	}
+
+	orderID, err := s.signer.Verify(token, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := s.repo.GetOrderByID(orderID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidDownloadLink
+		}
+		return nil, err
+	}
+	if order.Status != models.ProductOrderStatusPaid {
+		return nil, ErrOrderNotPaid
+	}
+	if order.Product == nil || order.Product.ArchiveFile == nil {
+		return nil, ErrFileNotFound
+	}
+
+	_, limit := s.downloadPolicy()
+	counted, err := s.repo.IncrementDownloads(order.ID, limit)
+	if err != nil {
+		return nil, err
+	}
+	if !counted {
+		return nil, ErrDownloadLimit
+	}
+	return order.Product.ArchiveFile, nil
+}
+
+// ResendDownload emails a fresh download link for a paid order.
+func (s *OrderService) ResendDownload(orderID uint) error {
+	if s == nil || s.repo == nil {
+		return ErrRepositoryNotReady
+	}
+	order, err := s.repo.GetOrderByID(orderID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrOrderNotFound
+		}
+		return err
+	}
+	if order.Status != models.ProductOrderStatusPaid {
+		return ErrOrderNotPaid
+	}
+	if s.signer == nil {
+		return ErrDownloadsDisabled
+	}
+	s.sendDownloadEmail(order)
+	return nil
+}
+
+func (s *OrderService) sendDownloadEmail(order *models.ProductOrder) {
+	if s.mailer == nil || !s.mailer.Enabled() {
+		logger.Warn("Email is not configured; product download link not sent", map[string]interface{}{"order_id": order.ID})
+		return
+	}
+
+	title := ""
+	if order.Product != nil {
+		title = order.Product.Title
+	}
+	ttl, limit := s.downloadPolicy()
+
+	err := s.mailer.SendTemplate(order.Email, service.EmailTemplateProductDownload, "Your download is ready", map[string]interface{}{
+		"ProductTitle":  title,
+		"DownloadURL":   s.DownloadURL(order),
+		"LinkHours":     int(ttl / time.Hour),
+		"DownloadLimit": limit,
+	})
+	if err != nil {
+		logger.Error(err, "Failed to send product download email", map[string]interface{}{"order_id": order.ID})
+	}
+}
+
+func (s *OrderService) downloadPolicy() (time.Duration, int) {
+	ttl, limit := defaultLinkTTL, defaultDownloadLimit
+	if s.settings == nil {
+		return ttl, limit
+	}
+
+	values, err := s.settings.Settings(productapi.Namespace)
+	if err != nil {
+		return ttl, limit
+	}
+	if value, ok := values[settingLinkHours].(float64); ok && value >= 1 {
+		ttl = time.Duration(value * float64(time.Hour))
+	}
+	if value, ok := values[settingDownloadLimit].(float64); ok && value >= 0 {
+		limit = int(value)
+	}
+	return ttl, limit
+}
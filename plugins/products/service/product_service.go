@@ -0,0 +1,189 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/repository"
+	"constructor-script-backend/pkg/utils"
+)
+
+type ProductService struct {
+	repo  repository.ProductRepository
+	files repository.ArchiveFileRepository
+}
+
+func NewProductService(repo repository.ProductRepository, files repository.ArchiveFileRepository) *ProductService {
+	return &ProductService{repo: repo, files: files}
+}
+
+func (s *ProductService) SetRepositories(repo repository.ProductRepository, files repository.ArchiveFileRepository) {
+	if s == nil {
+		return
+	}
+	s.repo = repo
+	s.files = files
+}
+
+func (s *ProductService) List() ([]models.Product, error) {
+	if s == nil || s.repo == nil {
+		return nil, ErrRepositoryNotReady
+	}
+	return s.repo.List()
+}
+
+func (s *ProductService) ListPublished() ([]models.Product, error) {
+	if s == nil || s.repo == nil {
+		return nil, ErrRepositoryNotReady
+	}
+	return s.repo.ListPublished()
+}
+
+func (s *ProductService) GetByID(id uint) (*models.Product, error) {
+	if s == nil || s.repo == nil {
+		return nil, ErrRepositoryNotReady
+	}
+	product, err := s.repo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrProductNotFound
+		}
+		return nil, err
+	}
+	return product, nil
+}
+
+// GetBySlug returns the product with slug. Unpublished products are reported as
+// missing unless includeUnpublished is set.
+func (s *ProductService) GetBySlug(slug string, includeUnpublished bool) (*models.Product, error) {
+	if s == nil || s.repo == nil {
+		return nil, ErrRepositoryNotReady
+	}
+	product, err := s.repo.GetBySlug(strings.TrimSpace(slug))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrProductNotFound
+		}
+		return nil, err
+	}
+	if !product.Published && !includeUnpublished {
+		return nil, ErrProductNotFound
+	}
+	return product, nil
+}
+
+func (s *ProductService) Create(req models.CreateProductRequest) (*models.Product, error) {
+	if s == nil || s.repo == nil {
+		return nil, ErrRepositoryNotReady
+	}
+
+	product := &models.Product{
+		Title:         strings.TrimSpace(req.Title),
+		Summary:       strings.TrimSpace(req.Summary),
+		Description:   strings.TrimSpace(req.Description),
+		Image:         strings.TrimSpace(req.Image),
+		PriceCents:    req.PriceCents,
+		ArchiveFileID: req.ArchiveFileID,
+	}
+	if req.Published != nil {
+		product.Published = *req.Published
+	}
+
+	if err := s.prepare(product, req.Slug); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(product); err != nil {
+		return nil, err
+	}
+	return s.GetByID(product.ID)
+}
+
+func (s *ProductService) Update(id uint, req models.UpdateProductRequest) (*models.Product, error) {
+	product, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	slug := product.Slug
+	if req.Title != nil {
+		product.Title = strings.TrimSpace(*req.Title)
+	}
+	if req.Slug != nil {
+		slug = *req.Slug
+	}
+	if req.Summary != nil {
+		product.Summary = strings.TrimSpace(*req.Summary)
+	}
+	if req.Description != nil {
+		product.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.Image != nil {
+		product.Image = strings.TrimSpace(*req.Image)
+	}
+	if req.PriceCents != nil {
+		product.PriceCents = *req.PriceCents
+	}
+	if req.ArchiveFileID != nil {
+		product.ArchiveFileID = *req.ArchiveFileID
+		product.ArchiveFile = nil
+	}
+	if req.Published != nil {
+		product.Published = *req.Published
+	}
+
+	if err := s.prepare(product, slug); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(product); err != nil {
+		return nil, err
+	}
+	return s.GetByID(product.ID)
+}
+
+func (s *ProductService) Delete(id uint) error {
+	if _, err := s.GetByID(id); err != nil {
+		return err
+	}
+	return s.repo.Delete(id)
+}
+
+func (s *ProductService) prepare(product *models.Product, requestedSlug string) error {
+	if product.Title == "" {
+		return fmt.Errorf("%w: title is required", ErrInvalidProduct)
+	}
+	if product.PriceCents <= 0 {
+		return fmt.Errorf("%w: price must be greater than zero", ErrInvalidProduct)
+	}
+	if product.ArchiveFileID == 0 {
+		return fmt.Errorf("%w: an archive file is required", ErrInvalidProduct)
+	}
+	if s.files != nil {
+		if _, err := s.files.GetByID(product.ArchiveFileID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrFileNotFound
+			}
+			return err
+		}
+	}
+
+	slug := utils.GenerateSlug(strings.TrimSpace(requestedSlug))
+	if slug == "" {
+		slug = utils.GenerateSlug(product.Title)
+	}
+	if slug == "" {
+		return fmt.Errorf("%w: slug is required", ErrInvalidProduct)
+	}
+	exists, err := s.repo.ExistsBySlug(slug, product.ID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrSlugConflict
+	}
+	product.Slug = slug
+	return nil
+}
@@ -0,0 +1,29 @@
+package products
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"constructor-script-backend/internal/models"
+	"constructor-script-backend/internal/plugin/migrations"
+)
+
+// Uninstall drops the product and order tables. Uploaded files stay in the archive.
+func (f *Feature) Uninstall() error {
+	if f == nil || f.host == nil {
+		return fmt.Errorf("feature host is not configured")
+	}
+
+	db := f.host.Database()
+	if db == nil {
+		return fmt.Errorf("database is not configured")
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Migrator().DropTable(&models.ProductOrder{}, &models.Product{}); err != nil {
+			return fmt.Errorf("failed to drop product tables: %w", err)
+		}
+		return migrations.Forget(tx, "products")
+	})
+}
@@ -123,6 +123,8 @@
             upload: root.dataset.endpointUpload,
             uploadRename: root.dataset.endpointUploadRename,
             uploadDelete: root.dataset.endpointUploadDelete,
+            uploadDirect: root.dataset.endpointUploadDirect,
+            uploadDirectComplete: root.dataset.endpointUploadDirectComplete,
             uploads: root.dataset.endpointUploads,
             themes: root.dataset.endpointThemes,
             plugins: root.dataset.endpointPlugins,
@@ -202,6 +204,23 @@
         const focusFirstField = (form) => uiManager.focusFirstField(form);
         const bringFormIntoView = (form) => uiManager.bringFormIntoView(form);
 
+        const robotsFieldNames = ['no_index', 'no_follow', 'sitemap_exclude'];
+        const readRobotsFields = (form) =>
+            Object.fromEntries(
+                robotsFieldNames.map((name) => [
+                    name,
+                    Boolean(form?.querySelector(`input[name="${name}"]`)?.checked),
+                ])
+            );
+        const fillRobotsFields = (form, item) => {
+            robotsFieldNames.forEach((name) => {
+                const field = form?.querySelector(`input[name="${name}"]`);
+                if (field) {
+                    field.checked = Boolean(item?.[name]);
+                }
+            });
+        };
+
         const updateBackupSummary = (message) => {
             if (!backupSummary) {
                 return;
@@ -1225,6 +1244,38 @@
                     return { identifier, url: uploadUrl, filename: uploadFilename };
                 };
 
+                // Videos and documents go straight to object storage when it is
+                // configured; the server only signs the request and registers the file.
+                const isDirectUploadCandidate = (file) =>
+                    Boolean(endpoints.uploadDirect && endpoints.uploadDirectComplete) &&
+                    !(typeof file.type === 'string' && file.type.startsWith('image/'));
+
+                const uploadFileDirect = async (file, preferredName) => {
+                    const { ticket } = await apiRequest(endpoints.uploadDirect, {
+                        method: 'POST',
+                        headers: { 'Content-Type': 'application/json' },
+                        body: JSON.stringify({
+                            filename: file.name,
+                            content_type: file.type || 'application/octet-stream',
+                            size: file.size,
+                            name: preferredName,
+                        }),
+                    });
+                    const response = await fetch(ticket.upload_url, {
+                        method: ticket.method || 'PUT',
+                        headers: ticket.headers || {},
+                        body: file,
+                    });
+                    if (!response.ok) {
+                        throw new Error(`Upload to storage failed (${response.status}).`);
+                    }
+                    return apiRequest(endpoints.uploadDirectComplete, {
+                        method: 'POST',
+                        headers: { 'Content-Type': 'application/json' },
+                        body: JSON.stringify({ token: ticket.token }),
+                    });
+                };
+
                 const uploadFile = endpoints.upload
                     ? async (file, options = {}) => {
                           if (!file) {
@@ -1233,6 +1284,17 @@
                           if (!endpoints.upload) {
                               throw new Error('Upload endpoint is not configured.');
                           }
+                          if (isDirectUploadCandidate(file)) {
+                              try {
+                                  return await uploadFileDirect(
+                                      file,
+                                      typeof options.name === 'string' ? options.name.trim() : ''
+                                  );
+                              } catch (error) {
+                                  handleRequestError(error);
+                                  throw error;
+                              }
+                          }
                           const formData = new FormData();
                           formData.append('file', file);
                           const preferredName =
@@ -7209,13 +7271,14 @@
                 revokeCourseVideoPreviewUrl();
                 return;
             }
-            const { objectUrl = false } = options;
+            const { objectUrl = false, poster = '' } = options;
             try {
                 courseVideoPreview.pause();
             } catch (error) {
                 /* no-op */
             }
             courseVideoPreview.removeAttribute('src');
+            courseVideoPreview.removeAttribute('poster');
             if (typeof courseVideoPreview.load === 'function') {
                 courseVideoPreview.load();
             }
@@ -7229,6 +7292,9 @@
             if (objectUrl) {
                 courseVideoPreviewObjectUrl = safeSource;
             }
+            if (poster) {
+                courseVideoPreview.poster = poster;
+            }
             courseVideoPreview.src = safeSource;
             if (typeof courseVideoPreview.load === 'function') {
                 courseVideoPreview.load();
@@ -7393,7 +7459,9 @@
             setCourseVideoDetailsAvailability();
             setCourseVideoSubmitState({ loading: false, disabled: false });
             setCourseVideoDurationValue(duration);
-            setCourseVideoPreviewSource(source);
+            setCourseVideoPreviewSource(source, {
+                poster: normaliseString(video?.poster_url ?? video?.posterUrl ?? video?.PosterURL ?? ''),
+            });
             highlightRow(tables.courseVideos, id);
             if (scroll) {
                 bringFormIntoView(courseVideoForm);
@@ -8819,6 +8887,17 @@
                 postTagsInput.value = extractTagNames(post).join(', ');
             }
             postForm.dataset.published = String(Boolean(post.published));
+            const postLanguageField = postForm.querySelector('input[name="language"]');
+            if (postLanguageField) {
+                postLanguageField.value = post.language || '';
+            }
+            const postTranslationKeyField = postForm.querySelector(
+                'input[name="translation_key"]'
+            );
+            if (postTranslationKeyField) {
+                postTranslationKeyField.value = post.translation_key || '';
+            }
+            fillRobotsFields(postForm, post);
             if (postPublishAtInput) {
                 const publishAt = extractDateValue(
                     post,
@@ -8971,6 +9050,26 @@
                     page.hide_header ?? page.HideHeader ?? false;
                 hideHeaderField.checked = Boolean(hideHeaderValue);
             }
+            const pageThemeField = pageForm.querySelector('select[name="theme"]');
+            if (pageThemeField) {
+                pageThemeField.dataset.value = page.theme || '';
+                pageThemeField.value = page.theme || '';
+            }
+            const pageLayoutField = pageForm.querySelector('input[name="layout"]');
+            if (pageLayoutField) {
+                pageLayoutField.value = page.layout || '';
+            }
+            const pageLanguageField = pageForm.querySelector('input[name="language"]');
+            if (pageLanguageField) {
+                pageLanguageField.value = page.language || '';
+            }
+            const pageTranslationKeyField = pageForm.querySelector(
+                'input[name="translation_key"]'
+            );
+            if (pageTranslationKeyField) {
+                pageTranslationKeyField.value = page.translation_key || '';
+            }
+            fillRobotsFields(pageForm, page);
             if (pagePublishButton) {
                 pagePublishButton.textContent = 'Update & publish';
             }
@@ -9044,6 +9143,11 @@
             if (hideHeaderField) {
                 hideHeaderField.checked = false;
             }
+            const pageThemeField = pageForm.querySelector('select[name="theme"]');
+            if (pageThemeField) {
+                pageThemeField.dataset.value = '';
+                pageThemeField.value = '';
+            }
             if (pageSectionsManager) {
                 pageSectionsManager.reset();
             }
@@ -9069,6 +9173,7 @@
             }
             categoryForm.name.value = category.name || '';
             categoryForm.description.value = category.description || '';
+            fillRobotsFields(categoryForm, category);
             if (categorySubmitButton) {
                 categorySubmitButton.textContent = 'Update category';
             }
@@ -10468,6 +10573,17 @@
                 actions.appendChild(activateButton);
             }
 
+            if (plugin?.has_settings && slug && !plugin?.missing_files) {
+                const settingsButton = document.createElement('button');
+                settingsButton.type = 'button';
+                settingsButton.className = 'admin-plugins__settings';
+                settingsButton.dataset.role = 'plugin-settings';
+                settingsButton.dataset.pluginName = pluginName;
+                settingsButton.dataset.pluginSlug = slug;
+                settingsButton.textContent = 'Settings';
+                actions.appendChild(settingsButton);
+            }
+
             const deleteButton = document.createElement('button');
             deleteButton.type = 'button';
             deleteButton.className = 'admin-plugins__delete';
@@ -10537,7 +10653,157 @@
             }, 500);
         };
 
+        const pluginEndpoint = (slug, suffix = '') => {
+            const base = endpoints.plugins.endsWith('/')
+                ? endpoints.plugins.slice(0, -1)
+                : endpoints.plugins;
+            return `${base}/${encodeURIComponent(slug)}${suffix}`;
+        };
+
+        const createPluginSettingField = (definition, value) => {
+            const key = normaliseString(definition?.key ?? '');
+            const field = document.createElement('label');
+            field.className = 'admin-form__field';
+
+            const label = document.createElement('span');
+            label.className = 'admin-form__label';
+            label.textContent = normaliseString(definition?.label ?? '') || key;
+            field.appendChild(label);
+
+            let input;
+            switch (definition?.type) {
+                case 'textarea':
+                    input = document.createElement('textarea');
+                    input.rows = 4;
+                    input.value = value ?? '';
+                    break;
+                case 'boolean':
+                    input = document.createElement('input');
+                    input.type = 'checkbox';
+                    input.checked = value === true;
+                    break;
+                case 'select':
+                    input = document.createElement('select');
+                    (Array.isArray(definition.options) ? definition.options : []).forEach((option) => {
+                        const optionEl = document.createElement('option');
+                        optionEl.value = option;
+                        optionEl.textContent = option;
+                        input.appendChild(optionEl);
+                    });
+                    input.value = value ?? '';
+                    break;
+                case 'number':
+                    input = document.createElement('input');
+                    input.type = 'number';
+                    input.step = 'any';
+                    input.value = value ?? '';
+                    break;
+                default:
+                    input = document.createElement('input');
+                    input.type = definition?.type === 'url' ? 'url' : 'text';
+                    input.value = value ?? '';
+            }
+            input.name = key;
+            input.dataset.settingType = definition?.type || 'text';
+            input.required = Boolean(definition?.required) && definition?.type !== 'boolean';
+            field.appendChild(input);
+
+            const description = normaliseString(definition?.description ?? '');
+            if (description) {
+                const hint = document.createElement('span');
+                hint.className = 'admin-form__hint';
+                hint.textContent = description;
+                field.appendChild(hint);
+            }
+            return field;
+        };
+
+        const togglePluginSettings = async (button) => {
+            const item = button.closest('[data-plugin-item]');
+            if (!item) {
+                return;
+            }
+            const existing = item.querySelector('[data-role="plugin-settings-form"]');
+            if (existing) {
+                existing.remove();
+                return;
+            }
+
+            const slug = normaliseString(button.dataset.pluginSlug ?? '');
+            try {
+                const payload = await apiRequest(pluginEndpoint(slug, '/settings'));
+                const schema = Array.isArray(payload?.settings?.schema) ? payload.settings.schema : [];
+                const values = payload?.settings?.values ?? {};
+
+                const form = document.createElement('form');
+                form.className = 'admin-form admin-plugins__settings-form';
+                form.dataset.role = 'plugin-settings-form';
+                form.dataset.pluginSlug = slug;
+                form.dataset.pluginName = button.dataset.pluginName || slug;
+                schema.forEach((definition) => {
+                    form.appendChild(createPluginSettingField(definition, values[definition.key]));
+                });
+
+                const submit = document.createElement('button');
+                submit.type = 'submit';
+                submit.className = 'admin-form__submit';
+                submit.textContent = 'Save settings';
+                form.appendChild(submit);
+                item.appendChild(form);
+            } catch (error) {
+                handleRequestError(error);
+            }
+        };
+
+        const handlePluginSettingsSubmit = async (event) => {
+            const form = event.target?.closest('[data-role="plugin-settings-form"]');
+            if (!form || !pluginList?.contains(form)) {
+                return;
+            }
+            event.preventDefault();
+
+            const values = {};
+            form.querySelectorAll('[name]').forEach((input) => {
+                switch (input.dataset.settingType) {
+                    case 'boolean':
+                        values[input.name] = input.checked;
+                        break;
+                    case 'number':
+                        values[input.name] = input.value === '' ? null : Number(input.value);
+                        break;
+                    default:
+                        values[input.name] = input.value;
+                }
+            });
+
+            const pluginName = form.dataset.pluginName;
+            try {
+                await apiRequest(pluginEndpoint(form.dataset.pluginSlug, '/settings'), {
+                    method: 'PUT',
+                    headers: {
+                        'Content-Type': 'application/json',
+                    },
+                    body: JSON.stringify({ values }),
+                });
+                form.remove();
+                showAlert(`Settings for "${pluginName}" saved.`, 'success');
+            } catch (error) {
+                handleRequestError(error);
+            }
+        };
+
         const handlePluginListClick = async (event) => {
+            const settingsButton = event.target?.closest('[data-role="plugin-settings"]');
+            if (settingsButton && pluginList?.contains(settingsButton)) {
+                event.preventDefault();
+                if (!endpoints.plugins) {
+                    showAlert('Plugin settings are not available in this environment.', 'error');
+                    return;
+                }
+                await togglePluginSettings(settingsButton);
+                return;
+            }
+
             const activateButton = event.target?.closest('[data-role="plugin-activate"]');
             if (activateButton && pluginList?.contains(activateButton)) {
                 event.preventDefault();
@@ -10645,11 +10911,14 @@
                 if (!confirmed) {
                     return;
                 }
+                const purge = window.confirm(
+                    `Also remove all data stored by "${pluginName}"? Choose Cancel to keep its data for a later reinstall.`,
+                );
 
                 const base = endpoints.plugins.endsWith('/')
                     ? endpoints.plugins.slice(0, -1)
                     : endpoints.plugins;
-                const url = `${base}/${encodeURIComponent(slug)}`;
+                const url = `${base}/${encodeURIComponent(slug)}${purge ? '?purge=true' : ''}`;
 
                 const originalText = deleteButton.textContent;
                 deleteButton.disabled = true;
@@ -10850,6 +11119,29 @@
             themeList.appendChild(fragment);
         };
 
+        const renderPageThemeOptions = () => {
+            const select = pageForm?.querySelector('[data-role="page-theme-select"]');
+            if (!select) {
+                return;
+            }
+            const current = select.value || select.dataset.value || '';
+            const themes = Array.isArray(state.themes) ? state.themes : [];
+            select.innerHTML = '';
+            const defaultOption = document.createElement('option');
+            defaultOption.value = '';
+            defaultOption.textContent = 'Site default';
+            select.appendChild(defaultOption);
+            themes.forEach((theme) => {
+                const option = document.createElement('option');
+                option.value = theme.slug;
+                option.textContent = theme.active
+                    ? `${theme.name || theme.slug} (active)`
+                    : theme.name || theme.slug;
+                select.appendChild(option);
+            });
+            select.value = current || '';
+        };
+
         const loadThemes = async () => {
             if (!endpoints.themes) {
                 return;
@@ -10859,6 +11151,7 @@
                 const themes = Array.isArray(payload?.themes) ? payload.themes : [];
                 state.themes = themes;
                 renderThemeList();
+                renderPageThemeOptions();
             } catch (error) {
                 handleRequestError(error);
             }
@@ -13408,6 +13701,10 @@
                 featured_img: featuredImg,
                 content,
                 published,
+                language: postForm.querySelector('input[name="language"]')?.value.trim() ?? '',
+                translation_key:
+                    postForm.querySelector('input[name="translation_key"]')?.value.trim() ?? '',
+                ...readRobotsFields(postForm),
             };
             if (postPublishAtInput) {
                 const rawPublishAt = postPublishAtInput.value.trim();
@@ -13535,6 +13832,12 @@
                 order: Number.isNaN(orderValue) ? 0 : orderValue,
                 published,
                 hide_header: Boolean(hideHeaderField?.checked),
+                theme: pageForm.querySelector('select[name="theme"]')?.value ?? '',
+                layout: pageForm.querySelector('input[name="layout"]')?.value.trim() ?? '',
+                language: pageForm.querySelector('input[name="language"]')?.value.trim() ?? '',
+                translation_key:
+                    pageForm.querySelector('input[name="translation_key"]')?.value.trim() ?? '',
+                ...readRobotsFields(pageForm),
             };
             if (pagePublishAtInput) {
                 const rawPublishAt = pagePublishAtInput.value.trim();
@@ -13680,7 +13983,7 @@
                 return;
             }
             const description = categoryForm.description.value.trim();
-            const payload = { name, description };
+            const payload = { name, description, ...readRobotsFields(categoryForm) };
             disableForm(categoryForm, true);
             clearAlert();
             try {
@@ -14618,6 +14921,7 @@
         logoUploadButton?.addEventListener('click', handleLogoUploadClick);
         logoUploadInput?.addEventListener('change', handleLogoFileChange);
         pluginList?.addEventListener('click', handlePluginListClick);
+        pluginList?.addEventListener('submit', handlePluginSettingsSubmit);
         pluginInstallForm?.addEventListener('submit', handlePluginInstallSubmit);
         themeList?.addEventListener('click', handleThemeListClick);
         socialForm?.addEventListener('submit', handleSocialFormSubmit);
@@ -0,0 +1,158 @@
+(() => {
+    const utils = window.AdminUtils;
+    const registry = window.AdminElementRegistry;
+    if (!utils || !registry) {
+        return;
+    }
+
+    const { createElement, normaliseString, randomId } = utils;
+    const resolveEndpoint = '/api/v1/admin/embeds/resolve';
+
+    const resolveEmbed = async (url) => {
+        const app = window.App || {};
+        if (typeof app.apiRequest === 'function') {
+            return app.apiRequest(resolveEndpoint, {
+                method: 'POST',
+                body: JSON.stringify({ url }),
+            });
+        }
+        const response = await fetch(resolveEndpoint, {
+            method: 'POST',
+            credentials: 'include',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ url }),
+        });
+        const payload = await response.json().catch(() => null);
+        if (!response.ok) {
+            throw new Error(payload?.error || 'Request failed');
+        }
+        return payload;
+    };
+
+    const showPreview = async (previewNode, url) => {
+        previewNode.textContent = '';
+        previewNode.classList.remove('admin-builder__note--error');
+        if (!url) {
+            return;
+        }
+        previewNode.textContent = 'Looking up link…';
+        try {
+            const payload = await resolveEmbed(url);
+            const embed = payload?.embed || {};
+            const parts = [embed.provider_name, embed.title || embed.author_name].filter(Boolean);
+            previewNode.textContent = parts.join(' · ') || 'Link resolved';
+        } catch (error) {
+            previewNode.textContent = error?.message || 'This link could not be embedded.';
+            previewNode.classList.add('admin-builder__note--error');
+        }
+    };
+
+    registry.register('embed', {
+        label: 'Embed',
+        addLabel: 'Add embed',
+        order: 35,
+        initialFocusSelector: '[data-field="embed-url"]',
+        create: () => ({
+            clientId: randomId(),
+            id: '',
+            type: 'embed',
+            content: {
+                url: '',
+                caption: '',
+            },
+        }),
+        fromRaw: ({ id, rawContent }) => ({
+            clientId: randomId(),
+            id,
+            type: 'embed',
+            content: {
+                url: normaliseString(rawContent.url ?? rawContent.URL ?? ''),
+                caption: normaliseString(rawContent.caption ?? rawContent.Caption ?? ''),
+            },
+        }),
+        renderEditor: (elementNode, element) => {
+            const urlField = createElement('label', {
+                className: 'admin-builder__field',
+            });
+            urlField.append(
+                createElement('span', {
+                    className: 'admin-builder__label',
+                    textContent: 'Link',
+                })
+            );
+            const urlInput = createElement('input', {
+                className: 'admin-builder__input',
+            });
+            urlInput.type = 'url';
+            urlInput.placeholder = 'https://www.youtube.com/watch?v=…';
+            urlInput.value = element.content?.url || '';
+            urlInput.dataset.field = 'embed-url';
+            urlField.append(urlInput);
+            elementNode.append(urlField);
+
+            const preview = createElement('p', {
+                className: 'admin-builder__note',
+            });
+            preview.setAttribute('aria-live', 'polite');
+            elementNode.append(preview);
+            urlInput.addEventListener('change', () => {
+                showPreview(preview, urlInput.value.trim());
+            });
+            if (urlInput.value) {
+                showPreview(preview, urlInput.value.trim());
+            }
+
+            elementNode.append(
+                createElement('p', {
+                    className: 'admin-builder__note',
+                    textContent:
+                        'Paste a YouTube, Vimeo or X (Twitter) link. Videos load from the provider only after a visitor presses play.',
+                })
+            );
+
+            const captionField = createElement('label', {
+                className: 'admin-builder__field',
+            });
+            captionField.append(
+                createElement('span', {
+                    className: 'admin-builder__label',
+                    textContent: 'Caption',
+                })
+            );
+            const captionInput = createElement('textarea', {
+                className: 'admin-builder__textarea',
+            });
+            captionInput.placeholder = 'Defaults to the video title';
+            captionInput.value = element.content?.caption || '';
+            captionInput.dataset.field = 'embed-caption';
+            captionField.append(captionInput);
+            elementNode.append(captionField);
+        },
+        updateField: (element, field, value) => {
+            if (field === 'embed-url') {
+                element.content.url = value;
+                return true;
+            }
+            if (field === 'embed-caption') {
+                element.content.caption = value;
+                return true;
+            }
+            return false;
+        },
+        hasContent: (element) => Boolean(element.content?.url?.trim()),
+        sanitise: (element, index) => {
+            const payload = {
+                url: element.content.url.trim(),
+            };
+            if (element.content.caption && element.content.caption.trim()) {
+                payload.caption = element.content.caption.trim();
+            }
+            return {
+                id: element.id || '',
+                type: 'embed',
+                order: index + 1,
+                content: payload,
+            };
+        },
+    });
+})();
@@ -0,0 +1,161 @@
+(() => {
+    const utils = window.AdminUtils;
+    const registry = window.AdminElementRegistry;
+    if (!utils || !registry) {
+        return;
+    }
+
+    const { createElement, normaliseString, randomId } = utils;
+
+    const defaultTitle = 'Notifications';
+    const defaultDescription =
+        'Choose what we tell you about and where.';
+    const defaultButton = 'Save preferences';
+
+    const normaliseContent = (content = {}) => ({
+        title: normaliseString(
+            content.title ?? content.Title ?? defaultTitle
+        ) || defaultTitle,
+        description: normaliseString(
+            content.description ?? content.Description ?? defaultDescription
+        ) || defaultDescription,
+        buttonLabel:
+            normaliseString(
+                content.button_label ??
+                    content.buttonLabel ??
+                    content.ButtonLabel ??
+                    defaultButton
+            ) || defaultButton,
+    });
+
+    registry.register('profile_notifications', {
+        label: 'Notification preferences',
+        addLabel: 'Add notification preferences',
+        order: 80,
+        create: () => ({
+            clientId: randomId(),
+            id: '',
+            type: 'profile_notifications',
+            content: {
+                title: defaultTitle,
+                description: defaultDescription,
+                button_label: defaultButton,
+            },
+        }),
+        fromRaw: ({ id, rawContent }) => ({
+            clientId: randomId(),
+            id,
+            type: 'profile_notifications',
+            content: {
+                title: normaliseString(
+                    rawContent?.title ?? rawContent?.Title ?? defaultTitle
+                ) || defaultTitle,
+                description: normaliseString(
+                    rawContent?.description ??
+                        rawContent?.Description ??
+                        defaultDescription
+                ) || defaultDescription,
+                button_label:
+                    normaliseString(
+                        rawContent?.button_label ??
+                            rawContent?.buttonLabel ??
+                            rawContent?.ButtonLabel ??
+                            defaultButton
+                    ) || defaultButton,
+            },
+        }),
+        renderEditor: (elementNode, element) => {
+            const titleField = createElement('label', {
+                className: 'admin-builder__field',
+            });
+            titleField.append(
+                createElement('span', {
+                    className: 'admin-builder__label',
+                    textContent: 'Heading',
+                })
+            );
+            const titleInput = createElement('input', {
+                className: 'admin-builder__input',
+                type: 'text',
+                value: element.content?.title || defaultTitle,
+            });
+            titleInput.dataset.field = 'profile-notifications-title';
+            titleField.append(titleInput);
+
+            const descriptionField = createElement('label', {
+                className: 'admin-builder__field',
+            });
+            descriptionField.append(
+                createElement('span', {
+                    className: 'admin-builder__label',
+                    textContent: 'Description',
+                })
+            );
+            const descriptionInput = createElement('textarea', {
+                className: 'admin-builder__textarea',
+            });
+            descriptionInput.dataset.field = 'profile-notifications-description';
+            descriptionInput.placeholder =
+                'Explain which notifications users can choose…';
+            descriptionInput.value =
+                element.content?.description || defaultDescription;
+            descriptionField.append(descriptionInput);
+
+            const buttonField = createElement('label', {
+                className: 'admin-builder__field',
+            });
+            buttonField.append(
+                createElement('span', {
+                    className: 'admin-builder__label',
+                    textContent: 'Button label',
+                })
+            );
+            const buttonInput = createElement('input', {
+                className: 'admin-builder__input',
+                type: 'text',
+            });
+            buttonInput.dataset.field = 'profile-notifications-button';
+            buttonInput.value =
+                element.content?.button_label || defaultButton;
+            buttonField.append(buttonInput);
+
+            elementNode.append(titleField, descriptionField, buttonField);
+        },
+        updateField: (element, field, value) => {
+            if (!element.content) {
+                element.content = {};
+            }
+            switch (field) {
+                case 'profile-notifications-title':
+                    element.content.title = value;
+                    return true;
+                case 'profile-notifications-description':
+                    element.content.description = value;
+                    return true;
+                case 'profile-notifications-button':
+                    element.content.button_label = value;
+                    return true;
+                default:
+                    return false;
+            }
+        },
+        hasContent: () => true,
+        sanitise: (element, index) => {
+            const content = normaliseContent(element.content);
+            return {
+                id: element.id || '',
+                type: 'profile_notifications',
+                order: index + 1,
+                content: {
+                    title: content.title,
+                    description: content.description,
+                    button_label: content.buttonLabel,
+                },
+            };
+        },
+        preview: (element, parts) => {
+            const content = normaliseContent(element.content);
+            parts.push(`${content.title} – ${content.buttonLabel}`);
+        },
+    });
+})();
@@ -589,6 +589,15 @@
                 return image;
             }
 
+            const poster = upload.poster_url || upload.PosterURL || '';
+            if (type === 'video' && poster) {
+                const image = document.createElement('img');
+                image.className = 'admin-media-library__thumb admin-media-library__thumb--video';
+                image.alt = this.getUploadFilename(upload) || 'Uploaded video';
+                image.src = poster;
+                return image;
+            }
+
             const placeholder = document.createElement('div');
             placeholder.className = 'admin-media-library__thumb admin-media-library__thumb--file';
             if (type === 'video') {
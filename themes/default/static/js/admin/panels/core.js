@@ -350,6 +350,28 @@
                                     Add tags individually or reuse existing ones from suggestions.
                                 </small>
                             </label>
+                            <label class="admin-form__label">
+                                Language
+                                <input type="text" name="language" class="admin-form__input" placeholder="Site default" maxlength="16" />
+                                <small class="admin-card__description admin-form__hint">Language code of this post, for example en or de. Leave blank for the site default.</small>
+                            </label>
+                            <label class="admin-form__label">
+                                Translation group
+                                <input type="text" name="translation_key" class="admin-form__input" maxlength="100" />
+                                <small class="admin-card__description admin-form__hint">Give translations of the same post the same group to link them with hreflang tags.</small>
+                            </label>
+                            <label class="admin-form__checkbox checkbox">
+                                <input type="checkbox" name="no_index" class="checkbox__input" />
+                                <span class="checkbox__label">Hide from search engines (noindex)</span>
+                            </label>
+                            <label class="admin-form__checkbox checkbox">
+                                <input type="checkbox" name="no_follow" class="checkbox__input" />
+                                <span class="checkbox__label">Don't follow links on this post (nofollow)</span>
+                            </label>
+                            <label class="admin-form__checkbox checkbox">
+                                <input type="checkbox" name="sitemap_exclude" class="checkbox__input" />
+                                <span class="checkbox__label">Exclude from sitemap</span>
+                            </label>
                             <label class="admin-form__label">
                                 Publish at
                                 <input
@@ -558,6 +580,40 @@
                                 <input type="checkbox" name="hide_header" class="checkbox__input" />
                                 <span class="checkbox__label">Hide page header</span>
                             </label>
+                            <label class="admin-form__label">
+                                Theme
+                                <select name="theme" class="admin-form__input" data-role="page-theme-select">
+                                    <option value="">Site default</option>
+                                </select>
+                                <small class="admin-card__description admin-form__hint">Render this page with another installed theme, for example for a landing page.</small>
+                            </label>
+                            <label class="admin-form__label">
+                                Layout
+                                <input type="text" name="layout" class="admin-form__input" placeholder="base" />
+                                <small class="admin-card__description admin-form__hint">Optional layout template from the selected theme. Leave blank for the default layout.</small>
+                            </label>
+                            <label class="admin-form__label">
+                                Language
+                                <input type="text" name="language" class="admin-form__input" placeholder="Site default" maxlength="16" />
+                                <small class="admin-card__description admin-form__hint">Language code of this page, for example en or de. Leave blank for the site default.</small>
+                            </label>
+                            <label class="admin-form__label">
+                                Translation group
+                                <input type="text" name="translation_key" class="admin-form__input" maxlength="100" />
+                                <small class="admin-card__description admin-form__hint">Give translations of the same page the same group to link them with hreflang tags.</small>
+                            </label>
+                            <label class="admin-form__checkbox checkbox">
+                                <input type="checkbox" name="no_index" class="checkbox__input" />
+                                <span class="checkbox__label">Hide from search engines (noindex)</span>
+                            </label>
+                            <label class="admin-form__checkbox checkbox">
+                                <input type="checkbox" name="no_follow" class="checkbox__input" />
+                                <span class="checkbox__label">Don't follow links on this page (nofollow)</span>
+                            </label>
+                            <label class="admin-form__checkbox checkbox">
+                                <input type="checkbox" name="sitemap_exclude" class="checkbox__input" />
+                                <span class="checkbox__label">Exclude from sitemap</span>
+                            </label>
                             <label class="admin-form__label">
                                 Publish at
                                 <input
@@ -675,6 +731,18 @@
                                 Description
                                 <textarea name="description" rows="3" class="admin-form__input"></textarea>
                             </label>
+                            <label class="admin-form__checkbox checkbox">
+                                <input type="checkbox" name="no_index" class="checkbox__input" />
+                                <span class="checkbox__label">Hide from search engines (noindex)</span>
+                            </label>
+                            <label class="admin-form__checkbox checkbox">
+                                <input type="checkbox" name="no_follow" class="checkbox__input" />
+                                <span class="checkbox__label">Don't follow links on this category (nofollow)</span>
+                            </label>
+                            <label class="admin-form__checkbox checkbox">
+                                <input type="checkbox" name="sitemap_exclude" class="checkbox__input" />
+                                <span class="checkbox__label">Exclude from sitemap</span>
+                            </label>
                             <div class="admin-form__actions">
                                 <button type="submit" class="admin-form__submit" data-role="category-submit">
                                     Create category
@@ -147,7 +147,7 @@
         supportsHeaderImage: true,
         description:
             'Flexible content area for combining paragraphs, media, and lists.',
-        allowedElements: ['paragraph', 'image', 'image_group', 'list', 'file_group', 'search'],
+        allowedElements: ['paragraph', 'image', 'image_group', 'embed', 'list', 'file_group', 'search'],
         settings: {
             image_alt: {
                 label: 'Side image alt text',
@@ -162,7 +162,7 @@
         supportsElements: true,
         description:
             'Displays content blocks in a responsive grid. Add at least two elements for a balanced layout.',
-        allowedElements: ['paragraph', 'image', 'image_group', 'list', 'file_group'],
+        allowedElements: ['paragraph', 'image', 'image_group', 'embed', 'list', 'file_group'],
     });
     ensureRegistered('features', {
         label: 'Features',
@@ -0,0 +1,77 @@
+(() => {
+    const container = document.querySelector("[data-announcements]");
+    if (!container) {
+        return;
+    }
+
+    // Guests keep their dismissals in the browser; signed-in users have them
+    // stored on the server so they stay hidden on every device.
+    const STORAGE_KEY = "dismissed_announcements";
+    const authenticated = document.body.dataset.authenticated === "true";
+
+    const readDismissed = () => {
+        try {
+            const stored = JSON.parse(window.localStorage.getItem(STORAGE_KEY) || "[]");
+            return Array.isArray(stored) ? stored.map(String) : [];
+        } catch (error) {
+            return [];
+        }
+    };
+
+    const writeDismissed = (ids) => {
+        try {
+            window.localStorage.setItem(STORAGE_KEY, JSON.stringify(ids));
+        } catch (error) {
+            // Storage may be unavailable in private browsing; the bar simply returns.
+        }
+    };
+
+    const hide = (announcement) => {
+        announcement.hidden = true;
+        if (!container.querySelector("[data-announcement]:not([hidden])")) {
+            container.hidden = true;
+        }
+    };
+
+    const dismissed = authenticated ? [] : readDismissed();
+    const current = [];
+    container.querySelectorAll("[data-announcement]").forEach((announcement) => {
+        const id = announcement.dataset.announcement;
+        current.push(id);
+        if (announcement.hasAttribute("data-announcement-dismissible") && dismissed.includes(id)) {
+            hide(announcement);
+        }
+    });
+    if (!authenticated && dismissed.length > 0) {
+        // Forget announcements that are no longer shown.
+        writeDismissed(dismissed.filter((id) => current.includes(id)));
+    }
+
+    container.addEventListener("click", (event) => {
+        const button = event.target.closest("[data-announcement-dismiss]");
+        if (!button) {
+            return;
+        }
+        const announcement = button.closest("[data-announcement]");
+        if (!announcement) {
+            return;
+        }
+
+        const id = announcement.dataset.announcement;
+        hide(announcement);
+
+        const app = window.App || {};
+        if (authenticated && typeof app.apiRequest === "function") {
+            app.apiRequest(`/api/v1/announcements/${encodeURIComponent(id)}/dismiss`, {
+                method: "POST",
+            }).catch(() => {});
+            return;
+        }
+
+        const ids = readDismissed();
+        if (!ids.includes(id)) {
+            ids.push(id);
+            writeDismissed(ids);
+        }
+    });
+})();
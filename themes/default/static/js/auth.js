@@ -473,6 +473,89 @@
         }
     };
 
+    // The challenge of a login waiting for a two-factor code. Logins through
+    // an OAuth provider keep it in a cookie instead, so it stays empty.
+    let loginChallenge = { token: "", remember: false };
+
+    const finishLogin = (payload, remember, alertId, redirectTarget) => {
+        if (!payload || !payload.token) {
+            throw new Error("Unable to sign in. Please try again.");
+        }
+
+        Auth.setToken(payload.token, remember);
+        if (payload.csrf_token) {
+            writeCookie(CSRF_COOKIE_NAME, payload.csrf_token, TOKEN_TTL_SECONDS);
+        }
+        updateNavVisibility(true);
+
+        const codes = Array.isArray(payload.recovery_codes) ? payload.recovery_codes : [];
+        const recovery = document.getElementById("login-recovery-codes");
+        if (codes.length && recovery) {
+            // The codes are shown once, so the user moves on when they saved them.
+            const list = recovery.querySelector("[data-recovery-codes]");
+            list.textContent = "";
+            codes.forEach((code) => {
+                const item = document.createElement("li");
+                item.textContent = code;
+                list.appendChild(item);
+            });
+            recovery.querySelector("[data-recovery-continue]").href = redirectTarget;
+            recovery.hidden = false;
+            const twoFactorForm = document.getElementById("login-two-factor-form");
+            if (twoFactorForm) {
+                twoFactorForm.hidden = true;
+            }
+            setAlert(alertId, "Two-factor authentication is on. Save your recovery codes.", "success");
+            return;
+        }
+
+        setAlert(alertId, "Signed in successfully. Redirecting…", "success");
+        window.setTimeout(() => {
+            window.location.href = redirectTarget;
+        }, 800);
+    };
+
+    const showTwoFactorStep = async (enroll) => {
+        const form = document.getElementById("login-two-factor-form");
+        if (!form) {
+            return;
+        }
+        const loginForm = document.getElementById("login-form");
+        if (loginForm) {
+            loginForm.hidden = true;
+        }
+        document.querySelectorAll("[data-login-providers]").forEach((element) => {
+            element.hidden = true;
+        });
+        form.hidden = false;
+        form.code.value = "";
+        form.code.focus();
+
+        const enrollment = form.querySelector("[data-two-factor-enrollment]");
+        if (!enrollment) {
+            return;
+        }
+        enrollment.hidden = !enroll;
+        if (!enroll) {
+            return;
+        }
+
+        toggleFormDisabled(form, true);
+        try {
+            const payload = await apiRequest("/api/v1/login/2fa/enroll", {
+                method: "POST",
+                body: JSON.stringify({ challenge_token: loginChallenge.token }),
+            });
+            enrollment.querySelector("[data-two-factor-secret]").textContent = payload.secret || "";
+            const link = enrollment.querySelector("[data-two-factor-uri]");
+            link.href = payload.provisioning_uri || "#";
+        } catch (error) {
+            setAlert("login-alert", error.message, "error");
+        } finally {
+            toggleFormDisabled(form, false);
+        }
+    };
+
     const handleLogin = async (event) => {
         event.preventDefault();
         const form = event.currentTarget;
@@ -499,21 +582,41 @@
                 }
             );
 
-            if (!payload || !payload.token) {
-                throw new Error("Unable to sign in. Please try again.");
+            if (payload && payload.two_factor_required) {
+                loginChallenge = { token: payload.challenge_token || "", remember };
+                toggleFormDisabled(form, false);
+                await showTwoFactorStep(Boolean(payload.enrollment_required));
+                return;
             }
 
-            Auth.setToken(payload.token, remember);
-            if (payload.csrf_token) {
-                writeCookie(CSRF_COOKIE_NAME, payload.csrf_token, TOKEN_TTL_SECONDS);
-            }
-            updateNavVisibility(true);
-            setAlert(alertId, "Signed in successfully. Redirecting…", "success");
+            finishLogin(payload, remember, alertId, form.dataset.redirect || "/profile");
+        } catch (error) {
+            setAlert(alertId, error.message, "error");
+        } finally {
+            toggleFormDisabled(form, false);
+        }
+    };
 
-            const redirectTarget = form.dataset.redirect || "/profile";
-            window.setTimeout(() => {
-                window.location.href = redirectTarget;
-            }, 800);
+    const handleTwoFactorLogin = async (event) => {
+        event.preventDefault();
+        const form = event.currentTarget;
+        const alertId = "login-alert";
+        setAlert(alertId, "");
+
+        const code = form.code.value.trim();
+        if (!code) {
+            setAlert(alertId, "Enter the code from your authenticator app.", "error");
+            return;
+        }
+
+        toggleFormDisabled(form, true);
+
+        try {
+            const payload = await apiRequest("/api/v1/login/2fa", {
+                method: "POST",
+                body: JSON.stringify({ challenge_token: loginChallenge.token, code }),
+            });
+            finishLogin(payload, loginChallenge.remember, alertId, form.dataset.redirect || "/profile");
         } catch (error) {
             setAlert(alertId, error.message, "error");
         } finally {
@@ -775,6 +878,94 @@
         }
     };
 
+    const notificationChannels = ["email", "in_app"];
+
+    const renderNotificationPreferences = (form, preferences) => {
+        const body = form.querySelector("[data-notification-preferences]");
+        if (!body) {
+            return;
+        }
+        body.innerHTML = "";
+
+        (preferences || []).forEach((preference) => {
+            const row = document.createElement("tr");
+            const label = document.createElement("th");
+            label.scope = "row";
+            label.textContent = preference.label || preference.event;
+            row.appendChild(label);
+
+            notificationChannels.forEach((channel) => {
+                const cell = document.createElement("td");
+                const channels = preference.channels || {};
+                if (Object.prototype.hasOwnProperty.call(channels, channel)) {
+                    const checkbox = document.createElement("input");
+                    checkbox.type = "checkbox";
+                    checkbox.checked = Boolean(channels[channel]);
+                    checkbox.dataset.event = preference.event;
+                    checkbox.dataset.channel = channel;
+                    checkbox.setAttribute(
+                        "aria-label",
+                        `${label.textContent} (${channel === "email" ? "email" : "on site"})`
+                    );
+                    cell.appendChild(checkbox);
+                } else {
+                    cell.textContent = "—";
+                }
+                row.appendChild(cell);
+            });
+
+            body.appendChild(row);
+        });
+    };
+
+    const loadNotificationPreferences = async (form) => {
+        const alertId = "profile-notifications-alert";
+        try {
+            const payload = await apiRequest(form.dataset.action);
+            renderNotificationPreferences(form, payload && payload.preferences);
+        } catch (error) {
+            if (error.status === 401) {
+                return;
+            }
+            setAlert(alertId, error.message, "error");
+        }
+    };
+
+    const handleNotificationPreferencesUpdate = async (event) => {
+        event.preventDefault();
+        const form = event.currentTarget;
+        const alertId = "profile-notifications-alert";
+        setAlert(alertId, "");
+
+        const preferences = {};
+        form.querySelectorAll("input[type=checkbox][data-event]").forEach((checkbox) => {
+            const { event: name, channel } = checkbox.dataset;
+            preferences[name] = preferences[name] || {};
+            preferences[name][channel] = checkbox.checked;
+        });
+
+        toggleFormDisabled(form, true);
+
+        try {
+            const payload = await apiRequest(form.dataset.action, {
+                method: "PUT",
+                body: JSON.stringify({ preferences }),
+            });
+            renderNotificationPreferences(form, payload && payload.preferences);
+            setAlert(alertId, "Notification preferences saved.", "success");
+        } catch (error) {
+            if (error.status === 401) {
+                Auth.clearToken();
+                updateNavVisibility(false);
+                window.location.href = "/login?redirect=/profile";
+                return;
+            }
+            setAlert(alertId, error.message, "error");
+        } finally {
+            toggleFormDisabled(form, false);
+        }
+    };
+
     const renderUserCourses = (courses) => {
         const container =
             document.getElementById("profile-courses") ||
@@ -1089,6 +1280,15 @@
             loginForm.addEventListener("submit", handleLogin);
         }
 
+        const twoFactorForm = document.getElementById("login-two-factor-form");
+        if (twoFactorForm) {
+            twoFactorForm.addEventListener("submit", handleTwoFactorLogin);
+            const step = twoFactorForm.dataset.step;
+            if (step === "verify" || step === "enroll") {
+                showTwoFactorStep(step === "enroll");
+            }
+        }
+
         const registerForm = document.getElementById("register-form");
         if (registerForm) {
             registerForm.addEventListener("submit", handleRegister);
@@ -1115,6 +1315,7 @@
 
         const profileForm = document.getElementById("profile-form");
         const passwordForm = document.getElementById("password-form");
+        const notificationsForm = document.getElementById("notifications-form");
         const profilePage = document.querySelector('[data-page="profile"], .page-view--profile');
         avatarInput = document.querySelector("[data-avatar-input]");
         avatarPreview = document.querySelector("[data-avatar-preview]");
@@ -1173,5 +1374,10 @@
         if (passwordForm) {
             passwordForm.addEventListener("submit", handlePasswordUpdate);
         }
+
+        if (notificationsForm) {
+            notificationsForm.addEventListener("submit", handleNotificationPreferencesUpdate);
+            loadNotificationPreferences(notificationsForm);
+        }
     });
 })();
@@ -56,7 +56,7 @@
 
         return {
             iso: date.toISOString(),
-            label: date.toLocaleString(undefined, {
+            label: date.toLocaleString(document.documentElement.lang || undefined, {
                 dateStyle: "medium",
                 timeStyle: "short",
             }),
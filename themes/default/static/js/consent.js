@@ -0,0 +1,124 @@
+(() => {
+    const banner = document.querySelector("[data-consent-banner]");
+    if (!banner) {
+        return;
+    }
+
+    const ENDPOINT = "/api/v1/consent";
+    const form = banner.querySelector("[data-consent-form]");
+    const categories = banner.querySelector("[data-consent-categories]");
+    const saveButton = banner.querySelector("[data-consent-save]");
+    const customizeButton = banner.querySelector("[data-consent-customize]");
+    const errorMessage = banner.querySelector("[data-consent-error]");
+    const inputs = Array.from(banner.querySelectorAll("[data-consent-categories] input[type='checkbox']"));
+
+    const readChoice = () => {
+        const choice = {};
+        inputs.forEach((input) => {
+            choice[input.name] = input.checked;
+        });
+        return choice;
+    };
+
+    // The page was rendered for this choice; ads and analytics only change
+    // after a reload.
+    const rendered = readChoice();
+    let state = {
+        enabled: true,
+        decided: banner.hidden,
+        version: Number(banner.dataset.consentVersion) || 0,
+        categories: Object.assign({}, rendered),
+    };
+
+    const request = (options) => {
+        const app = window.App || {};
+        if (typeof app.apiRequest === "function") {
+            return app.apiRequest(ENDPOINT, options);
+        }
+        return fetch(ENDPOINT, {
+            credentials: "same-origin",
+            headers: { "Content-Type": "application/json" },
+            ...options,
+        }).then((response) => {
+            if (!response.ok) {
+                throw new Error("Request failed");
+            }
+            return response.json();
+        });
+    };
+
+    const showCategories = () => {
+        categories.hidden = false;
+        saveButton.hidden = false;
+        customizeButton.hidden = true;
+    };
+
+    const open = () => {
+        errorMessage.hidden = true;
+        banner.hidden = false;
+        showCategories();
+        const first = inputs.find((input) => !input.disabled);
+        if (first) {
+            first.focus();
+        }
+    };
+
+    const save = (choice) => {
+        errorMessage.hidden = true;
+        request({ method: "PUT", body: JSON.stringify({ categories: choice }) })
+            .then((payload) => {
+                state = (payload && payload.consent) || state;
+                document.dispatchEvent(new CustomEvent("consent:change", { detail: state }));
+
+                const changed = Object.keys(state.categories || {}).some(
+                    (key) => Boolean(state.categories[key]) !== Boolean(rendered[key])
+                );
+                if (changed) {
+                    window.location.reload();
+                    return;
+                }
+                banner.hidden = true;
+            })
+            .catch(() => {
+                errorMessage.hidden = false;
+            });
+    };
+
+    const setAll = (checked) => {
+        inputs.forEach((input) => {
+            if (!input.disabled) {
+                input.checked = checked;
+            }
+        });
+        save(readChoice());
+    };
+
+    banner.querySelector("[data-consent-accept]").addEventListener("click", () => setAll(true));
+    banner.querySelector("[data-consent-reject]").addEventListener("click", () => setAll(false));
+    customizeButton.addEventListener("click", showCategories);
+    form.addEventListener("submit", (event) => {
+        event.preventDefault();
+        save(readChoice());
+    });
+
+    document.addEventListener("click", (event) => {
+        if (event.target.closest("[data-consent-open]")) {
+            open();
+        }
+    });
+
+    // Themes and plugins read the visitor's consent through App.consent and
+    // listen for "consent:change" to load scripts once it is given.
+    window.App = Object.assign(window.App || {}, {
+        consent: {
+            state: () => state,
+            allowed: (category) => Boolean(state.categories && state.categories[category]),
+            refresh: () =>
+                request({ method: "GET" }).then((payload) => {
+                    state = (payload && payload.consent) || state;
+                    return state;
+                }),
+            open,
+        },
+    });
+})();
@@ -417,6 +417,7 @@
 
         const dataset = root.dataset || {};
         const endpoint = dataset.courseEndpoint || "";
+        const topicEndpoint = (dataset.courseTopicEndpoint || "").replace(/\/$/, "");
         const testEndpointBase = (dataset.courseTestEndpoint || "/api/v1/courses/tests").replace(/\/$/, "");
 
         const elements = {
@@ -711,6 +712,9 @@
                 videoEl.addEventListener("contextmenu", (event) => {
                     event.preventDefault();
                 });
+                if (video?.poster_url) {
+                    videoEl.poster = video.poster_url;
+                }
                 videoEl.src = video.file_url;
                 if (video?.filename) {
                     videoEl.setAttribute("title", video.filename);
@@ -1302,7 +1306,50 @@
             }
         };
 
-        const selectStep = (topicIndex, stepIndex) => {
+        const loadScripts = (scripts) => {
+            if (!Array.isArray(scripts)) {
+                return;
+            }
+            scripts.forEach((src) => {
+                if (!src || document.querySelector(`script[src="${CSS.escape(src)}"]`)) {
+                    return;
+                }
+                const script = document.createElement("script");
+                script.src = src;
+                script.defer = true;
+                document.body.appendChild(script);
+            });
+        };
+
+        // Topics other than the first arrive as outlines; their lessons are fetched
+        // the first time one of them is opened.
+        const topicRequests = new Map();
+        const loadTopic = (topicIndex) => {
+            const topics = state.course.package.topics;
+            const topic = topics[topicIndex];
+            if (!topic?.outline || !topicEndpoint) {
+                return Promise.resolve(topic);
+            }
+            if (!topicRequests.has(topic.id)) {
+                const request = apiRequest(`${topicEndpoint}/${encodeURIComponent(topic.id)}`, { method: "GET" })
+                    .then((payload) => {
+                        if (!payload?.topic) {
+                            throw new Error("Lesson details are not available right now.");
+                        }
+                        topics[topicIndex] = payload.topic;
+                        loadScripts(payload.scripts);
+                        renderTopics();
+                        return payload.topic;
+                    })
+                    .finally(() => {
+                        topicRequests.delete(topic.id);
+                    });
+                topicRequests.set(topic.id, request);
+            }
+            return topicRequests.get(topic.id);
+        };
+
+        const selectStep = async (topicIndex, stepIndex) => {
             if (!state.course || !state.course.package) {
                 return;
             }
@@ -1315,10 +1362,32 @@
             }
 
             showError("");
-            setPlaceholder(null);
             state.topicIndex = topicIndex;
             state.stepIndex = stepIndex;
             updateActiveButtons();
+
+            if (topics[topicIndex].outline) {
+                if (elements.content) {
+                    elements.content.innerHTML = "";
+                }
+                setPlaceholder("Loading lesson...");
+                try {
+                    await loadTopic(topicIndex);
+                } catch (error) {
+                    if (error && error.status === 401) {
+                        redirectToLogin();
+                        return;
+                    }
+                    setPlaceholder(null);
+                    showError(error?.message || "Failed to load the lesson.");
+                    return;
+                }
+                if (state.topicIndex !== topicIndex || state.stepIndex !== stepIndex) {
+                    return;
+                }
+            }
+
+            setPlaceholder(null);
             renderStep(topicIndex, stepIndex);
         };
 
@@ -0,0 +1,24 @@
+(function () {
+    // Embedded players are loaded only when the visitor asks for them, so no third-party
+    // requests are made while the page is being read.
+    const activate = (frame) => {
+        const src = frame.getAttribute('data-embed-src');
+        if (!src || frame.querySelector('iframe')) return;
+
+        const iframe = document.createElement('iframe');
+        iframe.src = src;
+        iframe.title = frame.getAttribute('data-embed-title') || 'Embedded video';
+        iframe.allow = 'autoplay; encrypted-media; fullscreen; picture-in-picture';
+        iframe.allowFullscreen = true;
+        iframe.referrerPolicy = 'strict-origin-when-cross-origin';
+
+        frame.replaceChildren(iframe);
+        frame.classList.add('is-active');
+    };
+
+    document.querySelectorAll('[data-embed]').forEach((frame) => {
+        const button = frame.querySelector('[data-embed-play]');
+        if (!button) return;
+        button.addEventListener('click', () => activate(frame));
+    });
+})();
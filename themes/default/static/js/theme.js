@@ -1,6 +1,8 @@
 (() => {
     const storageKey = "theme";
     const root = document.documentElement;
+    const defaultScheme = root.getAttribute("data-theme-default") || "system";
+    const allowToggle = root.getAttribute("data-theme-toggle-enabled") !== "false";
     const toggle = document.querySelector("[data-theme-toggle]");
     const label = toggle ? toggle.querySelector("[data-theme-toggle-label]") : null;
     const icon = toggle ? toggle.querySelector(".header__theme-icon") : null;
@@ -10,6 +12,9 @@
             : null;
 
     const getStoredTheme = () => {
+        if (!allowToggle) {
+            return null;
+        }
         try {
             const storedTheme = localStorage.getItem(storageKey);
             if (storedTheme === "light" || storedTheme === "dark") {
@@ -25,12 +30,18 @@
         if (!toggle) {
             return;
         }
+        const text = toggle.dataset;
         if (label) {
-            label.textContent = theme === "dark" ? "Light mode" : "Dark mode";
+            label.textContent =
+                theme === "dark"
+                    ? text.labelLight || "Light mode"
+                    : text.labelDark || "Dark mode";
         }
         toggle.setAttribute(
             "aria-label",
-            theme === "dark" ? "Switch to light mode" : "Switch to dark mode",
+            theme === "dark"
+                ? text.ariaLight || "Switch to light mode"
+                : text.ariaDark || "Switch to dark mode",
         );
         if (icon) {
             icon.textContent = theme === "dark" ? "☀️" : "🌙";
@@ -67,7 +78,9 @@
 
     const storedTheme = getStoredTheme();
     const prefersDark = mediaQuery ? mediaQuery.matches : false;
-    const preferredTheme = storedTheme ?? (prefersDark ? "dark" : "light");
+    const systemTheme = prefersDark ? "dark" : "light";
+    const preferredTheme =
+        storedTheme ?? (defaultScheme === "system" ? systemTheme : defaultScheme);
     const initialTheme = root.getAttribute("data-theme");
 
     if (initialTheme !== preferredTheme) {
@@ -85,7 +98,7 @@
 
     if (mediaQuery) {
         const handleMediaChange = (event) => {
-            if (getStoredTheme()) {
+            if (getStoredTheme() || defaultScheme !== "system") {
                 return;
             }
             applyTheme(event.matches ? "dark" : "light", { persist: false });